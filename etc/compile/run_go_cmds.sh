@@ -20,13 +20,15 @@ cd "$(dirname "${0}")/../.."
 BINARY="${1}"
 LD_FLAGS="${2}"
 PROFILE="${3}"
+# GOARCH is the architecture to build for (amd64 or arm64)
+GOARCH="${GOARCH:-amd64}"
 
 # Ubuntu mounts an in-memory filesystem at /dev/shm (rather than /tmp) so use
 # that parent for performance
 TMP="$(mktemp -t docker_build_${BINARY}.XXXXXXXX)"
 rm -rf "${TMP}" # in case a directory was left behind by a prior failed build
 mkdir -p "${TMP}"
-CGO_ENABLED=0 GOOS=linux go build \
+CGO_ENABLED=0 GOOS=linux GOARCH="${GOARCH}" go build \
   -installsuffix netgo \
   -tags netgo \
   -o "${TMP}/${BINARY}" \
@@ -41,9 +43,17 @@ if [[ -z "${PROFILE}" ]]; then
         cp ./etc/worker/* "${TMP}/"
     fi
     cp /etc/ssl/certs/ca-certificates.crt "${TMP}/ca-certificates.crt"
-    docker build ${DOCKER_BUILD_FLAGS} -t "pachyderm_${BINARY}" "${TMP}"
-    docker tag "pachyderm_${BINARY}:latest" "pachyderm/${BINARY}:latest"
-    docker tag "pachyderm_${BINARY}:latest" "pachyderm/${BINARY}:local"
+    if [[ "${GOARCH}" = "amd64" ]]; then
+        docker build ${DOCKER_BUILD_FLAGS} -t "pachyderm_${BINARY}" "${TMP}"
+        docker tag "pachyderm_${BINARY}:latest" "pachyderm/${BINARY}:latest"
+        docker tag "pachyderm_${BINARY}:latest" "pachyderm/${BINARY}:local"
+        docker tag "pachyderm_${BINARY}:latest" "pachyderm/${BINARY}:local-amd64"
+    else
+        # The Dockerfiles don't have any RUN steps, so images for other
+        # architectures can be built without emulation
+        docker build ${DOCKER_BUILD_FLAGS} --platform "linux/${GOARCH}" -t "pachyderm_${BINARY}:${GOARCH}" "${TMP}"
+        docker tag "pachyderm_${BINARY}:${GOARCH}" "pachyderm/${BINARY}:local-${GOARCH}"
+    fi
 else
     cd "${TMP}"
     tar cf - "${BINARY}"
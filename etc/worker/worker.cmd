@@ -0,0 +1,4 @@
+@echo off
+rem Copies the worker binary into the pach-bin volume, from which the user
+rem container runs it (the Windows equivalent of worker.sh)
+copy /y C:\pach\worker.exe C:\pach-bin\
@@ -71,6 +71,10 @@ var (
 	// ErrBadToken is returned by the Auth API if the caller's token is corrupted
 	// or has expired.
 	ErrBadToken = status.Error(codes.Unauthenticated, "provided auth token is corrupted or has expired (try logging in again)")
+
+	// ErrOIDCNotConfigured is returned by GetOIDCLogin if the cluster's auth
+	// config doesn't include an OIDC ID provider
+	ErrOIDCNotConfigured = status.Error(codes.FailedPrecondition, "no OIDC ID provider is configured")
 )
 
 // IsErrNotActivated checks if an error is a ErrNotActivated
@@ -120,6 +124,14 @@ func IsErrBadToken(err error) bool {
 	return strings.Contains(err.Error(), status.Convert(ErrBadToken).Message())
 }
 
+// IsErrOIDCNotConfigured returns true if 'err' is a ErrOIDCNotConfigured
+func IsErrOIDCNotConfigured(err error) bool {
+	if err == nil {
+		return false
+	}
+	return strings.Contains(err.Error(), status.Convert(ErrOIDCNotConfigured).Message())
+}
+
 // ErrNotAuthorized is returned if the user is not authorized to perform
 // a certain operation. Either
 // 1) the operation is a user operation, in which case 'Repo' and/or 'Required'
@@ -132,6 +144,7 @@ type ErrNotAuthorized struct {
 
 	Repo     string // Repo that the user is attempting to access
 	Required Scope  // Caller needs 'Required'-level access to 'Repo'
+	Path     string // Path in 'Repo' that the user is attempting to read, if any
 
 	// Group 2:
 	// AdminOp indicates an operation that the caller couldn't perform because
@@ -152,6 +165,9 @@ func (e *ErrNotAuthorized) Error() string {
 	if e.Repo != "" {
 		msg += " on the repo " + e.Repo
 	}
+	if e.Path != "" {
+		msg += " at path " + e.Path
+	}
 	if e.Required != Scope_NONE {
 		msg += ", must have at least " + e.Required.String() + " access"
 	}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
@@ -88,7 +89,37 @@ type APIClient struct {
 	// The trusted CAs, for authenticating a pachd server over TLS
 	caCerts *x509.CertPool
 
-	// clientConn is a cached grpc connection to 'addr'
+	// transportCreds, if set, are used to connect to pachd instead of caCerts
+	// (see WithTransportCredentials)
+	transportCreds credentials.TransportCredentials
+
+	// poolSize is the number of connections to each pachd replica at 'addr'
+	// (see WithConnectionPool), or 0 if the client has a single connection
+	poolSize int
+
+	// unaryInterceptors and streamInterceptors are added to the client's
+	// connection after any tracing interceptors (see WithInterceptors)
+	unaryInterceptors  []grpc.UnaryClientInterceptor
+	streamInterceptors []grpc.StreamClientInterceptor
+
+	// proxyURL, if set, is the HTTP proxy that the client's connection is
+	// tunneled through (see WithProxy)
+	proxyURL *url.URL
+
+	// tlsServerName, if set, is the name that pachd's TLS certificate is
+	// verified against (see WithTLSServerName)
+	tlsServerName string
+
+	// keepaliveTime is how often the client pings pachd over an idle
+	// connection (see WithKeepalive)
+	keepaliveTime time.Duration
+
+	// reconnectTimeout, if set, is how long RPCs that can't reach pachd are
+	// retried for (see WithReconnect)
+	reconnectTimeout time.Duration
+
+	// clientConn is a cached grpc connection to 'addr'. APIClients created
+	// from this one (e.g. by WithCtx) share it.
 	clientConn *grpc.ClientConn
 
 	// healthClient is a cached healthcheck client connected to 'addr'
@@ -135,6 +166,14 @@ type clientSettings struct {
 	maxConcurrentStreams int
 	dialTimeout          time.Duration
 	caCerts              *x509.CertPool
+	transportCreds       credentials.TransportCredentials
+	poolSize             int
+	unaryInterceptors    []grpc.UnaryClientInterceptor
+	streamInterceptors   []grpc.StreamClientInterceptor
+	proxyURL             *url.URL
+	tlsServerName        string
+	keepaliveTime        time.Duration
+	reconnectTimeout     time.Duration
 }
 
 // NewFromAddress constructs a new APIClient for the server at addr.
@@ -147,6 +186,7 @@ func NewFromAddress(addr string, options ...Option) (*APIClient, error) {
 	settings := clientSettings{
 		maxConcurrentStreams: DefaultMaxConcurrentStreams,
 		dialTimeout:          DefaultDialTimeout,
+		keepaliveTime:        DefaultKeepaliveTime,
 	}
 	for _, option := range options {
 		if err := option(&settings); err != nil {
@@ -154,9 +194,19 @@ func NewFromAddress(addr string, options ...Option) (*APIClient, error) {
 		}
 	}
 	c := &APIClient{
-		addr:    addr,
-		caCerts: settings.caCerts,
-		limiter: limit.New(settings.maxConcurrentStreams),
+		addr:           addr,
+		caCerts:        settings.caCerts,
+		transportCreds: settings.transportCreds,
+		limiter:        limit.New(settings.maxConcurrentStreams),
+		poolSize:       settings.poolSize,
+
+		unaryInterceptors:  settings.unaryInterceptors,
+		streamInterceptors: settings.streamInterceptors,
+
+		proxyURL:         settings.proxyURL,
+		tlsServerName:    settings.tlsServerName,
+		keepaliveTime:    settings.keepaliveTime,
+		reconnectTimeout: settings.reconnectTimeout,
 	}
 	if err := c.connect(settings.dialTimeout); err != nil {
 		return nil, err
@@ -223,6 +273,16 @@ func WithSystemCAs(settings *clientSettings) error {
 	return nil
 }
 
+// WithTransportCredentials instructs the New* functions to connect to pachd
+// with 'creds' (e.g. the mutual TLS credentials that pachd and workers use to
+// connect to each other), which takes precedence over any trusted CAs
+func WithTransportCredentials(creds credentials.TransportCredentials) Option {
+	return func(settings *clientSettings) error {
+		settings.transportCreds = creds
+		return nil
+	}
+}
+
 // WithDialTimeout instructs the New* functions to use 't' as the deadline to
 // connect to pachd
 func WithDialTimeout(t time.Duration) Option {
@@ -232,6 +292,21 @@ func WithDialTimeout(t time.Duration) Option {
 	}
 }
 
+// WithInterceptors instructs the New* functions to send the client's RPCs
+// through 'unary' and 'stream' (either of which may be nil), e.g. to serve
+// some of them from a cache
+func WithInterceptors(unary grpc.UnaryClientInterceptor, stream grpc.StreamClientInterceptor) Option {
+	return func(settings *clientSettings) error {
+		if unary != nil {
+			settings.unaryInterceptors = append(settings.unaryInterceptors, unary)
+		}
+		if stream != nil {
+			settings.streamInterceptors = append(settings.streamInterceptors, stream)
+		}
+		return nil
+	}
+}
+
 // WithAdditionalPachdCert instructs the New* functions to additionally trust
 // the signed cert mounted in Pachd's cert volume. This is used by Pachd
 // when connecting to itself (if no cert is present, the clients cert pool
@@ -327,6 +402,9 @@ func getUserMachineAddrAndOpts(context *config.Context) (*grpcutil.PachdAddress,
 		if context.ServerCAs != "" && !pachdAddress.Secured {
 			return nil, nil, errors.New("must set pachd_address to grpcs://... if server_cas is set")
 		}
+		if context.TLSServerName != "" && !pachdAddress.Secured {
+			return nil, nil, errors.New("must set pachd_address to grpcs://... if tls_server_name is set")
+		}
 
 		if pachdAddress.Secured {
 			options = append(options, WithSystemCAs)
@@ -410,7 +488,29 @@ func NewOnUserMachine(prefix string, options ...Option) (*APIClient, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not get active context: %v", err)
 	}
+	return newOnUserMachine(cfg, context, prefix, options...)
+}
 
+// NewOnUserMachineForContext is like NewOnUserMachine, but connects to the
+// cluster referenced by the context named 'contextName' rather than the active
+// context. This is used by commands that talk to more than one cluster at a
+// time (e.g. 'pachctl create mirror').
+func NewOnUserMachineForContext(contextName string, prefix string, options ...Option) (*APIClient, error) {
+	cfg, err := config.Read(false)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config: %v", err)
+	}
+	if cfg.V2 == nil {
+		return nil, fmt.Errorf("cannot get context %q from non-v2 config", contextName)
+	}
+	context, ok := cfg.V2.Contexts[contextName]
+	if !ok || context == nil {
+		return nil, fmt.Errorf("context %q does not exist", contextName)
+	}
+	return newOnUserMachine(cfg, context, prefix, options...)
+}
+
+func newOnUserMachine(cfg *config.Config, context *config.Context, prefix string, options ...Option) (*APIClient, error) {
 	// create new pachctl client
 	pachdAddress, cfgOptions, err := getUserMachineAddrAndOpts(context)
 	if err != nil {
@@ -442,6 +542,12 @@ func NewOnUserMachine(prefix string, options ...Option) (*APIClient, error) {
 		}
 	}
 
+	connOptions, err := ContextOptions(context)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection settings in context: %v", err)
+	}
+	cfgOptions = append(cfgOptions, connOptions...)
+
 	client, err := NewFromAddress(pachdAddress.Hostname(), append(options, cfgOptions...)...)
 	if err != nil {
 		return nil, fmt.Errorf("could not connect to pachd at %q: %v", pachdAddress.Qualified(), err)
@@ -562,15 +668,17 @@ func DefaultDialOptions() []grpc.DialOption {
 
 func (c *APIClient) connect(timeout time.Duration) error {
 	keepaliveOpt := grpc.WithKeepaliveParams(keepalive.ClientParameters{
-		Time:                20 * time.Second, // if 20s since last msg (any kind), ping
+		Time:                c.keepaliveTime,  // if this long since last msg (any kind), ping
 		Timeout:             20 * time.Second, // if no response to ping for 20s, reset
 		PermitWithoutStream: true,             // send ping even if no active RPCs
 	})
 	dialOptions := append(DefaultDialOptions(), keepaliveOpt)
-	if c.caCerts == nil {
+	if c.transportCreds != nil {
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(c.transportCreds))
+	} else if c.caCerts == nil {
 		dialOptions = append(dialOptions, grpc.WithInsecure())
 	} else {
-		tlsCreds := credentials.NewClientTLSFromCert(c.caCerts, "")
+		tlsCreds := credentials.NewClientTLSFromCert(c.caCerts, c.tlsServerName)
 		dialOptions = append(dialOptions, grpc.WithTransportCredentials(tlsCreds))
 	}
 	if tracing.IsActive() {
@@ -579,9 +687,32 @@ func (c *APIClient) connect(timeout time.Duration) error {
 			grpc.WithStreamInterceptor(tracing.StreamClientInterceptor()),
 		)
 	}
+	if c.proxyURL != nil {
+		dialOptions = append(dialOptions, grpc.WithContextDialer(proxyDialer(c.proxyURL)))
+	}
+	if c.reconnectTimeout > 0 {
+		// Retry RPCs before any other interceptors see them, so that they
+		// don't see each attempt as a separate RPC
+		unary, stream := reconnectInterceptors(c.reconnectTimeout)
+		dialOptions = append(dialOptions,
+			grpc.WithChainUnaryInterceptor(unary),
+			grpc.WithChainStreamInterceptor(stream),
+		)
+	}
+	if len(c.unaryInterceptors) > 0 {
+		dialOptions = append(dialOptions, grpc.WithChainUnaryInterceptor(c.unaryInterceptors...))
+	}
+	if len(c.streamInterceptors) > 0 {
+		dialOptions = append(dialOptions, grpc.WithChainStreamInterceptor(c.streamInterceptors...))
+	}
+	target := c.addr
+	if c.poolSize > 0 {
+		target = poolTarget(c.addr, c.poolSize)
+		dialOptions = append(dialOptions, grpc.WithDefaultServiceConfig(poolServiceConfig))
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
-	clientConn, err := grpc.DialContext(ctx, c.addr, dialOptions...)
+	clientConn, err := grpc.DialContext(ctx, target, dialOptions...)
 	if err != nil {
 		return err
 	}
@@ -652,6 +783,16 @@ func (c *APIClient) WithCtx(ctx context.Context) *APIClient {
 	return &result
 }
 
+// WithAuthToken returns a new APIClient that authenticates as 'token', and
+// shares this client's connections. Unlike SetAuthToken, it's safe to call
+// while other goroutines are using this client, so a single client (e.g. one
+// created with WithConnectionPool) can make calls on behalf of many users.
+func (c *APIClient) WithAuthToken(token string) *APIClient {
+	result := *c // copy c
+	result.authenticationToken = token
+	return &result
+}
+
 // SetAuthToken sets the authentication token that will be used for all
 // API calls for this client.
 func (c *APIClient) SetAuthToken(token string) {
@@ -0,0 +1,227 @@
+package client
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/config"
+	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+)
+
+const (
+	// DefaultKeepaliveTime is how often clients ping pachd over an idle
+	// connection, unless overridden by WithKeepalive()
+	DefaultKeepaliveTime = 20 * time.Second
+
+	// MinKeepaliveTime is the most often that pachd lets clients ping it
+	// (pachd closes the connections of clients that ping it more often)
+	MinKeepaliveTime = 5 * time.Second
+)
+
+// WithProxy instructs the New* functions to tunnel the client's connection
+// to pachd through the HTTP proxy at 'proxyURL' (with HTTP CONNECT), rather
+// than the proxy in the HTTPS_PROXY environment variable, if any.
+func WithProxy(proxyURL string) Option {
+	return func(settings *clientSettings) error {
+		u, err := parseProxyURL(proxyURL)
+		if err != nil {
+			return err
+		}
+		settings.proxyURL = u
+		return nil
+	}
+}
+
+// WithTLSServerName instructs the New* functions to verify pachd's TLS
+// certificate against 'name', rather than the host that the client connects
+// to. This is useful if pachd is reached through a load balancer or an
+// ingress whose address isn't in pachd's certificate.
+func WithTLSServerName(name string) Option {
+	return func(settings *clientSettings) error {
+		settings.tlsServerName = name
+		return nil
+	}
+}
+
+// WithKeepalive instructs the New* functions to create a client that pings
+// pachd every 't' while its connection is idle, so that proxies and load
+// balancers don't close it.
+func WithKeepalive(t time.Duration) Option {
+	return func(settings *clientSettings) error {
+		if t < MinKeepaliveTime {
+			return fmt.Errorf("keepalive must be at least %v, but was %v", MinKeepaliveTime, t)
+		}
+		settings.keepaliveTime = t
+		return nil
+	}
+}
+
+// WithReconnect instructs the New* functions to create a client that retries
+// RPCs that fail because pachd is unreachable, for up to 'timeout', while
+// grpc reconnects to pachd in the background. Streaming RPCs are only retried
+// if they fail before their stream is opened, as the client can't replay
+// what was already sent on a broken stream.
+func WithReconnect(timeout time.Duration) Option {
+	return func(settings *clientSettings) error {
+		if timeout <= 0 {
+			return fmt.Errorf("reconnect timeout must be positive, but was %v", timeout)
+		}
+		settings.reconnectTimeout = timeout
+		return nil
+	}
+}
+
+// ContextOptions returns the options that configure a client's connection to
+// the cluster of 'context' (its proxy, keepalive, etc). It returns an error
+// if any of the context's connection settings are invalid.
+func ContextOptions(context *config.Context) ([]Option, error) {
+	var options []Option
+	if context.ProxyURL != "" {
+		options = append(options, WithProxy(context.ProxyURL))
+	}
+	if context.TLSServerName != "" {
+		options = append(options, WithTLSServerName(context.TLSServerName))
+	}
+	if context.KeepaliveSeconds != 0 {
+		options = append(options, WithKeepalive(time.Duration(context.KeepaliveSeconds)*time.Second))
+	}
+	if context.ReconnectTimeoutSeconds != 0 {
+		options = append(options, WithReconnect(time.Duration(context.ReconnectTimeoutSeconds)*time.Second))
+	}
+	// Validate the options now, rather than when the client is created
+	var settings clientSettings
+	for _, option := range options {
+		if err := option(&settings); err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url %q: %v", proxyURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("proxy url %q must be an http or https url", proxyURL)
+	}
+	return u, nil
+}
+
+// proxyDialer returns a dialer that opens connections through the HTTP proxy
+// at 'proxyURL', with HTTP CONNECT
+func proxyDialer(proxyURL *url.URL) func(context.Context, string) (net.Conn, error) {
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		proxyAddr := proxyURL.Host
+		if proxyURL.Port() == "" {
+			port := "80"
+			if proxyURL.Scheme == "https" {
+				port = "443"
+			}
+			proxyAddr = net.JoinHostPort(proxyURL.Hostname(), port)
+		}
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", proxyAddr)
+		if err != nil {
+			return nil, err
+		}
+		if proxyURL.Scheme == "https" {
+			conn = tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname()})
+		}
+		tunnel, err := connectThroughProxy(ctx, conn, addr, proxyURL)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("could not connect to %s through proxy %s: %v", addr, proxyURL.Host, err)
+		}
+		return tunnel, nil
+	}
+}
+
+// connectThroughProxy asks the proxy at the other end of 'conn' to tunnel it
+// to 'addr'
+func connectThroughProxy(ctx context.Context, conn net.Conn, addr string, proxyURL *url.URL) (net.Conn, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Host: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if user := proxyURL.User; user != nil {
+		password, _ := user.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+
+			base64.StdEncoding.EncodeToString([]byte(user.Username()+":"+password)))
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("proxy returned %s", resp.Status)
+	}
+	// The proxy may have sent some of the tunneled data along with its
+	// response, so reads go through 'r'
+	return &bufferedConn{Conn: conn, r: r}, nil
+}
+
+type bufferedConn struct {
+	net.Conn
+	r io.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// reconnectInterceptors return interceptors that retry RPCs that fail because
+// pachd is unreachable, for up to 'timeout'
+func reconnectInterceptors(timeout time.Duration) (grpc.UnaryClientInterceptor, grpc.StreamClientInterceptor) {
+	unary := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return retryUnavailable(ctx, timeout, func() error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+	}
+	stream := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		var clientStream grpc.ClientStream
+		err := retryUnavailable(ctx, timeout, func() error {
+			var err error
+			clientStream, err = streamer(ctx, desc, cc, method, opts...)
+			return err
+		})
+		return clientStream, err
+	}
+	return unary, stream
+}
+
+// retryUnavailable calls 'f' until it succeeds, fails with an error other
+// than codes.Unavailable, or 'timeout' elapses
+func retryUnavailable(ctx context.Context, timeout time.Duration, f func() error) error {
+	b := backoff.NewExponentialBackOff()
+	b.MaxElapsedTime = timeout
+	return backoff.RetryNotify(f, backoff.WithContext(b, ctx), func(err error, d time.Duration) error {
+		if status.Code(err) != codes.Unavailable {
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/pachyderm/pachyderm/src/client/health"
+	"github.com/pachyderm/pachyderm/src/client/pkg/config"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	healthserver "github.com/pachyderm/pachyderm/src/server/health"
+)
+
+// servePachd serves a healthy pachd on 'lis', returning its server
+func servePachd(lis net.Listener) *grpc.Server {
+	server := grpc.NewServer()
+	healthServer := healthserver.NewHealthServer()
+	health.RegisterHealthServer(server, healthServer)
+	healthServer.Ready()
+	go server.Serve(lis)
+	return server
+}
+
+// connectProxy is an HTTP proxy that tunnels CONNECT requests
+type connectProxy struct {
+	tunnels int64
+	auth    string
+}
+
+func (p *connectProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodConnect {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if p.auth != "" && r.Header.Get("Proxy-Authorization") != p.auth {
+		w.WriteHeader(http.StatusProxyAuthRequired)
+		return
+	}
+	backend, err := net.Dial("tcp", r.Host)
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		backend.Close()
+		return
+	}
+	conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+	atomic.AddInt64(&p.tunnels, 1)
+	go func() {
+		defer conn.Close()
+		defer backend.Close()
+		go io.Copy(backend, conn)
+		io.Copy(conn, backend)
+	}()
+}
+
+func TestProxy(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer servePachd(lis).Stop()
+	proxy := &connectProxy{auth: "Basic dXNlcjpwYXNzd29yZA=="} // user:password
+	proxyServer := httptest.NewServer(proxy)
+	defer proxyServer.Close()
+
+	proxyURL := "http://user:password@" + proxyServer.Listener.Addr().String()
+	c, err := NewFromAddress(lis.Addr().String(), WithProxy(proxyURL), WithDialTimeout(10*time.Second))
+	require.NoError(t, err)
+	defer c.Close()
+	require.NoError(t, c.Health())
+	require.Equal(t, int64(1), atomic.LoadInt64(&proxy.tunnels))
+
+	// Proxies that refuse the tunnel fail the connection
+	badURL := "http://user:wrong@" + proxyServer.Listener.Addr().String()
+	_, err = NewFromAddress(lis.Addr().String(), WithProxy(badURL), WithDialTimeout(time.Second))
+	require.YesError(t, err)
+}
+
+func TestReconnect(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	server := servePachd(lis)
+
+	c, err := NewFromAddress(addr, WithReconnect(30*time.Second), WithDialTimeout(10*time.Second))
+	require.NoError(t, err)
+	defer c.Close()
+	require.NoError(t, c.Health())
+
+	// Restart pachd. RPCs sent while it's down wait for it to come back.
+	server.Stop()
+	restarted := make(chan *grpc.Server, 1)
+	go func() {
+		time.Sleep(time.Second)
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			close(restarted)
+			return
+		}
+		restarted <- servePachd(lis)
+	}()
+	require.NoError(t, c.Health())
+	if server, ok := <-restarted; ok {
+		server.Stop()
+	}
+}
+
+func TestContextOptions(t *testing.T) {
+	options, err := ContextOptions(&config.Context{})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(options))
+	options, err = ContextOptions(&config.Context{
+		ProxyURL:                "http://proxy:3128",
+		TLSServerName:           "pachd.example.com",
+		KeepaliveSeconds:        30,
+		ReconnectTimeoutSeconds: 60,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 4, len(options))
+
+	_, err = ContextOptions(&config.Context{ProxyURL: "proxy:3128"})
+	require.YesError(t, err)
+	_, err = ContextOptions(&config.Context{KeepaliveSeconds: 1})
+	require.YesError(t, err)
+	_, err = ContextOptions(&config.Context{ReconnectTimeoutSeconds: -1})
+	require.YesError(t, err)
+}
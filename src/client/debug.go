@@ -7,6 +7,7 @@ import (
 	"github.com/gogo/protobuf/types"
 	"github.com/pachyderm/pachyderm/src/client/debug"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/client/version"
 )
 
 // Dump writes debug information from the server to w.
@@ -18,6 +19,21 @@ func (c APIClient) Dump(w io.Writer) error {
 	return grpcutil.ScrubGRPC(grpcutil.WriteFromStreamingBytesClient(goroClient, w))
 }
 
+// DumpArchive writes a gzipped tar archive of the information needed to
+// diagnose the cluster to w: pachd's and workers' goroutines and heap
+// profiles, the most recent 'logLines' lines of each container's logs (or
+// 1000, if it's zero), pipeline specs, jobs, etcd's health and Pachyderm's
+// kubernetes objects.
+func (c APIClient) DumpArchive(logLines int64, w io.Writer) error {
+	archiveClient, err := c.DebugClient.DumpArchive(c.Ctx(), &debug.DumpArchiveRequest{
+		LogLines: logLines,
+	})
+	if err != nil {
+		return c.scrubFeatureGRPC(version.FeatureDumpArchive, err)
+	}
+	return c.scrubFeatureGRPC(version.FeatureDumpArchive, grpcutil.WriteFromStreamingBytesClient(archiveClient, w))
+}
+
 // Profile writes a pprof profile for pachd to w.
 func (c APIClient) Profile(profile string, duration time.Duration, w io.Writer) error {
 	var d *types.Duration
@@ -42,3 +58,13 @@ func (c APIClient) Binary(w io.Writer) error {
 	}
 	return grpcutil.ScrubGRPC(grpcutil.WriteFromStreamingBytesClient(binaryClient, w))
 }
+
+// Masters returns the pods that hold (and are waiting for) the locks that
+// elect the PPS master and pipelines' masters.
+func (c APIClient) Masters() ([]*debug.MasterLease, error) {
+	resp, err := c.DebugClient.Masters(c.Ctx(), &debug.MastersRequest{})
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return resp.Masters, nil
+}
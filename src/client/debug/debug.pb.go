@@ -77,6 +77,57 @@ func (m *DumpRequest) GetRecursed() bool {
 	return false
 }
 
+// DumpArchiveRequest is a request for an archive of the information needed
+// to diagnose a cluster (see DumpArchive)
+type DumpArchiveRequest struct {
+	// log_lines is the number of most recent lines of each container's logs
+	// to include. If it's zero, the most recent 1000 lines are included.
+	LogLines             int64    `protobuf:"varint,1,opt,name=log_lines,json=logLines,proto3" json:"log_lines,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DumpArchiveRequest) Reset()         { *m = DumpArchiveRequest{} }
+func (m *DumpArchiveRequest) String() string { return proto.CompactTextString(m) }
+func (*DumpArchiveRequest) ProtoMessage()    {}
+func (*DumpArchiveRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6d15a320d0127c22, []int{1}
+}
+func (m *DumpArchiveRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DumpArchiveRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DumpArchiveRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DumpArchiveRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DumpArchiveRequest.Merge(m, src)
+}
+func (m *DumpArchiveRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *DumpArchiveRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DumpArchiveRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DumpArchiveRequest proto.InternalMessageInfo
+
+func (m *DumpArchiveRequest) GetLogLines() int64 {
+	if m != nil {
+		return m.LogLines
+	}
+	return 0
+}
+
 type ProfileRequest struct {
 	Profile              string          `protobuf:"bytes,1,opt,name=profile,proto3" json:"profile,omitempty"`
 	Duration             *types.Duration `protobuf:"bytes,2,opt,name=duration,proto3" json:"duration,omitempty"`
@@ -89,7 +140,7 @@ func (m *ProfileRequest) Reset()         { *m = ProfileRequest{} }
 func (m *ProfileRequest) String() string { return proto.CompactTextString(m) }
 func (*ProfileRequest) ProtoMessage()    {}
 func (*ProfileRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_6d15a320d0127c22, []int{1}
+	return fileDescriptor_6d15a320d0127c22, []int{2}
 }
 func (m *ProfileRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -142,7 +193,7 @@ func (m *BinaryRequest) Reset()         { *m = BinaryRequest{} }
 func (m *BinaryRequest) String() string { return proto.CompactTextString(m) }
 func (*BinaryRequest) ProtoMessage()    {}
 func (*BinaryRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_6d15a320d0127c22, []int{2}
+	return fileDescriptor_6d15a320d0127c22, []int{3}
 }
 func (m *BinaryRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -171,35 +222,226 @@ func (m *BinaryRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_BinaryRequest proto.InternalMessageInfo
 
+type MastersRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MastersRequest) Reset()         { *m = MastersRequest{} }
+func (m *MastersRequest) String() string { return proto.CompactTextString(m) }
+func (*MastersRequest) ProtoMessage()    {}
+func (*MastersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6d15a320d0127c22, []int{4}
+}
+func (m *MastersRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MastersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MastersRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MastersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MastersRequest.Merge(m, src)
+}
+func (m *MastersRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *MastersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MastersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MastersRequest proto.InternalMessageInfo
+
+// MasterLease describes a lock that one process holds to run a master, and
+// the processes waiting to take over if it fails
+type MasterLease struct {
+	// name identifies the master: "pps" for the PPS master, which runs in
+	// pachd, or "pipeline/<pipeline>" for a pipeline's master, which runs in
+	// one of its workers
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// holder is the name of the pod that holds the lock, and so runs the master
+	Holder string `protobuf:"bytes,2,opt,name=holder,proto3" json:"holder,omitempty"`
+	// lease_id is the ID of the holder's etcd lease
+	LeaseId int64 `protobuf:"varint,3,opt,name=lease_id,json=leaseId,proto3" json:"lease_id,omitempty"`
+	// ttl is the time until the holder's lease expires, unless the holder
+	// renews it. If it expires, the first standby takes over the master.
+	Ttl *types.Duration `protobuf:"bytes,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// standbys are the names of the pods waiting for the lock, in the order in
+	// which they'll acquire it
+	Standbys             []string `protobuf:"bytes,5,rep,name=standbys,proto3" json:"standbys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MasterLease) Reset()         { *m = MasterLease{} }
+func (m *MasterLease) String() string { return proto.CompactTextString(m) }
+func (*MasterLease) ProtoMessage()    {}
+func (*MasterLease) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6d15a320d0127c22, []int{5}
+}
+func (m *MasterLease) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MasterLease) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MasterLease.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MasterLease) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MasterLease.Merge(m, src)
+}
+func (m *MasterLease) XXX_Size() int {
+	return m.Size()
+}
+func (m *MasterLease) XXX_DiscardUnknown() {
+	xxx_messageInfo_MasterLease.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MasterLease proto.InternalMessageInfo
+
+func (m *MasterLease) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *MasterLease) GetHolder() string {
+	if m != nil {
+		return m.Holder
+	}
+	return ""
+}
+
+func (m *MasterLease) GetLeaseId() int64 {
+	if m != nil {
+		return m.LeaseId
+	}
+	return 0
+}
+
+func (m *MasterLease) GetTtl() *types.Duration {
+	if m != nil {
+		return m.Ttl
+	}
+	return nil
+}
+
+func (m *MasterLease) GetStandbys() []string {
+	if m != nil {
+		return m.Standbys
+	}
+	return nil
+}
+
+type MastersResponse struct {
+	Masters              []*MasterLease `protobuf:"bytes,1,rep,name=masters,proto3" json:"masters,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *MastersResponse) Reset()         { *m = MastersResponse{} }
+func (m *MastersResponse) String() string { return proto.CompactTextString(m) }
+func (*MastersResponse) ProtoMessage()    {}
+func (*MastersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6d15a320d0127c22, []int{6}
+}
+func (m *MastersResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MastersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MastersResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MastersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MastersResponse.Merge(m, src)
+}
+func (m *MastersResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *MastersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_MastersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MastersResponse proto.InternalMessageInfo
+
+func (m *MastersResponse) GetMasters() []*MasterLease {
+	if m != nil {
+		return m.Masters
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*DumpRequest)(nil), "debug.DumpRequest")
+	proto.RegisterType((*DumpArchiveRequest)(nil), "debug.DumpArchiveRequest")
 	proto.RegisterType((*ProfileRequest)(nil), "debug.ProfileRequest")
 	proto.RegisterType((*BinaryRequest)(nil), "debug.BinaryRequest")
+	proto.RegisterType((*MastersRequest)(nil), "debug.MastersRequest")
+	proto.RegisterType((*MasterLease)(nil), "debug.MasterLease")
+	proto.RegisterType((*MastersResponse)(nil), "debug.MastersResponse")
 }
 
 func init() { proto.RegisterFile("client/debug/debug.proto", fileDescriptor_6d15a320d0127c22) }
 
 var fileDescriptor_6d15a320d0127c22 = []byte{
-	// 302 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x91, 0xcf, 0x4a, 0xf4, 0x30,
-	0x14, 0xc5, 0xbf, 0x7c, 0x38, 0x7f, 0xcc, 0xa0, 0x42, 0x50, 0xa8, 0x15, 0xca, 0xd0, 0xd5, 0xb8,
-	0x49, 0x64, 0xc4, 0x95, 0x88, 0x58, 0xfa, 0x00, 0xd2, 0x85, 0x0b, 0x77, 0x69, 0x7b, 0xa7, 0x53,
-	0xe8, 0x34, 0x31, 0x4d, 0x90, 0xbe, 0xe1, 0x2c, 0x7d, 0x04, 0xe9, 0x93, 0xc8, 0x34, 0xed, 0xd0,
-	0x61, 0x16, 0xb3, 0x29, 0x3d, 0xf7, 0x9e, 0x9c, 0xfb, 0xbb, 0x09, 0x76, 0x92, 0x22, 0x87, 0x52,
-	0xb3, 0x14, 0x62, 0x93, 0xd9, 0x2f, 0x95, 0x4a, 0x68, 0x41, 0x46, 0xad, 0x70, 0xbd, 0x4c, 0x88,
-	0xac, 0x00, 0xd6, 0x16, 0x63, 0xb3, 0x62, 0xdf, 0x8a, 0x4b, 0x09, 0xaa, 0xb2, 0xb6, 0xe3, 0x7e,
-	0x6a, 0x14, 0xd7, 0xb9, 0x28, 0x6d, 0xdf, 0xbf, 0xc7, 0xb3, 0xd0, 0x6c, 0x64, 0x04, 0x5f, 0x06,
-	0x2a, 0x4d, 0x5c, 0x3c, 0x55, 0x90, 0x18, 0x55, 0x41, 0xea, 0xa0, 0x39, 0x5a, 0x4c, 0xa3, 0xbd,
-	0xf6, 0x39, 0xbe, 0x7c, 0x57, 0x62, 0x95, 0x17, 0xd0, 0xbb, 0x1d, 0x3c, 0x91, 0xb6, 0xd2, 0x9a,
-	0xcf, 0xa3, 0x5e, 0x92, 0x27, 0x3c, 0xed, 0x07, 0x39, 0xff, 0xe7, 0x68, 0x31, 0x5b, 0xde, 0x52,
-	0x4b, 0x42, 0x7b, 0x12, 0x1a, 0x76, 0x86, 0x68, 0x6f, 0xf5, 0xaf, 0xf0, 0x45, 0x90, 0x97, 0x5c,
-	0xd5, 0xdd, 0x84, 0xe5, 0x16, 0xe1, 0x51, 0xb8, 0x5b, 0x94, 0x3c, 0xe3, 0xb3, 0x1d, 0x28, 0x21,
-	0xd4, 0xde, 0xc2, 0x80, 0xda, 0xbd, 0x3b, 0xca, 0x0e, 0x6a, 0x0d, 0xd5, 0x07, 0x2f, 0x0c, 0xf8,
-	0xff, 0x1e, 0x10, 0x79, 0xc3, 0x93, 0x0e, 0x9d, 0xdc, 0x74, 0xe7, 0x0f, 0x57, 0x39, 0x1d, 0xf1,
-	0x8a, 0xc7, 0x16, 0x8d, 0x5c, 0x77, 0x09, 0x07, 0xa4, 0x27, 0x03, 0x82, 0x97, 0x6d, 0xe3, 0xa1,
-	0x9f, 0xc6, 0x43, 0xbf, 0x8d, 0x87, 0x3e, 0x59, 0x96, 0xeb, 0xb5, 0x89, 0x69, 0x22, 0x36, 0x4c,
-	0xf2, 0x64, 0x5d, 0xa7, 0xa0, 0x86, 0x7f, 0x95, 0x4a, 0xd8, 0xf0, 0xed, 0xe3, 0x71, 0x9b, 0xfb,
-	0xf8, 0x17, 0x00, 0x00, 0xff, 0xff, 0x9f, 0xb2, 0x86, 0x16, 0x12, 0x02, 0x00, 0x00,
+	// 475 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x53, 0x41, 0x8b, 0xd3, 0x40,
+	0x18, 0xdd, 0x6c, 0xda, 0x26, 0xfd, 0x82, 0xbb, 0x32, 0xe8, 0x92, 0x76, 0xa1, 0x94, 0x9c, 0x2a,
+	0x4a, 0xa2, 0x15, 0x2f, 0x8a, 0x2c, 0x5b, 0x7a, 0x59, 0x58, 0x41, 0x72, 0xf0, 0xe0, 0x65, 0x99,
+	0x24, 0xdf, 0xa6, 0x81, 0x69, 0x26, 0xce, 0x4c, 0x94, 0xfe, 0x18, 0xff, 0x8f, 0x37, 0xfd, 0x09,
+	0xd2, 0x5f, 0x22, 0xc9, 0x24, 0x21, 0xb5, 0x87, 0x5e, 0xc2, 0xbc, 0x37, 0x6f, 0xde, 0xbc, 0x99,
+	0x37, 0x01, 0x37, 0x66, 0x19, 0xe6, 0x2a, 0x48, 0x30, 0x2a, 0x53, 0xfd, 0xf5, 0x0b, 0xc1, 0x15,
+	0x27, 0xc3, 0x1a, 0x4c, 0x67, 0x29, 0xe7, 0x29, 0xc3, 0xa0, 0x26, 0xa3, 0xf2, 0x31, 0xf8, 0x21,
+	0x68, 0x51, 0xa0, 0x90, 0x5a, 0x76, 0x3c, 0x9f, 0x94, 0x82, 0xaa, 0x8c, 0xe7, 0x7a, 0xde, 0x7b,
+	0x01, 0xce, 0xba, 0xdc, 0x16, 0x21, 0x7e, 0x2b, 0x51, 0x2a, 0x32, 0x05, 0x5b, 0x60, 0x5c, 0x0a,
+	0x89, 0x89, 0x6b, 0xcc, 0x8d, 0x85, 0x1d, 0x76, 0xd8, 0x7b, 0x03, 0xa4, 0x92, 0xde, 0x8a, 0x78,
+	0x93, 0x7d, 0xc7, 0x76, 0xc5, 0x35, 0x8c, 0x19, 0x4f, 0x1f, 0x58, 0x96, 0xa3, 0xac, 0x97, 0x98,
+	0xa1, 0xcd, 0x78, 0x7a, 0x5f, 0x61, 0x8f, 0xc2, 0xc5, 0x67, 0xc1, 0x1f, 0x33, 0xd6, 0xc9, 0x5d,
+	0xb0, 0x0a, 0xcd, 0xd4, 0xe2, 0x71, 0xd8, 0x42, 0xf2, 0x0e, 0xec, 0x36, 0x9b, 0x7b, 0x3e, 0x37,
+	0x16, 0xce, 0x72, 0xe2, 0xeb, 0xf0, 0x7e, 0x1b, 0xde, 0x5f, 0x37, 0x82, 0xb0, 0x93, 0x7a, 0x97,
+	0xf0, 0x64, 0x95, 0xe5, 0x54, 0xec, 0x9a, 0x1d, 0xbc, 0xa7, 0x70, 0xf1, 0x89, 0x4a, 0x85, 0x42,
+	0xb6, 0xcc, 0x4f, 0x03, 0x1c, 0x4d, 0xdd, 0x23, 0x95, 0x48, 0x08, 0x0c, 0x72, 0xba, 0x6d, 0x03,
+	0xd4, 0x63, 0x72, 0x05, 0xa3, 0x0d, 0x67, 0x09, 0x8a, 0x7a, 0xef, 0x71, 0xd8, 0x20, 0x32, 0x01,
+	0x9b, 0x55, 0x8b, 0x1e, 0xb2, 0xc4, 0x35, 0xeb, 0xd3, 0x59, 0x35, 0xbe, 0x4b, 0xc8, 0x4b, 0x30,
+	0x95, 0x62, 0xee, 0xe0, 0x54, 0xd6, 0x4a, 0x55, 0x5d, 0xac, 0x54, 0x34, 0x4f, 0xa2, 0x9d, 0x74,
+	0x87, 0x73, 0x73, 0x31, 0x0e, 0x3b, 0xec, 0xdd, 0xc0, 0x65, 0x97, 0x58, 0x16, 0x3c, 0x97, 0x48,
+	0x5e, 0x81, 0xb5, 0xd5, 0x94, 0x6b, 0xcc, 0xcd, 0x85, 0xb3, 0x24, 0xbe, 0x2e, 0xbf, 0x77, 0x8e,
+	0xb0, 0x95, 0x2c, 0x7f, 0x9f, 0xc3, 0x70, 0x5d, 0x4d, 0x93, 0x0f, 0x30, 0xa8, 0x3a, 0x22, 0xad,
+	0xbc, 0xd7, 0xed, 0xf4, 0xfa, 0x28, 0xe2, 0x6a, 0xa7, 0x50, 0x7e, 0xa1, 0xac, 0x44, 0xef, 0xec,
+	0xb5, 0x41, 0xee, 0xc0, 0xe9, 0x15, 0x4c, 0x26, 0x3d, 0x8f, 0xc3, 0xd2, 0x4f, 0x5b, 0xdd, 0x82,
+	0xd5, 0x14, 0x4f, 0x9e, 0x37, 0x36, 0x87, 0x0f, 0xe1, 0xb4, 0xc5, 0x0d, 0x8c, 0x74, 0xb1, 0xe4,
+	0x59, 0xe3, 0x70, 0xd0, 0xf3, 0x69, 0x83, 0xf7, 0x60, 0x35, 0xd7, 0xda, 0x65, 0x38, 0x7c, 0x18,
+	0xd3, 0xab, 0xff, 0x69, 0x7d, 0xfb, 0xde, 0xd9, 0xea, 0xe3, 0xaf, 0xfd, 0xcc, 0xf8, 0xb3, 0x9f,
+	0x19, 0x7f, 0xf7, 0x33, 0xe3, 0x6b, 0x90, 0x66, 0x6a, 0x53, 0x46, 0x7e, 0xcc, 0xb7, 0x41, 0x41,
+	0xe3, 0xcd, 0x2e, 0x41, 0xd1, 0x1f, 0x49, 0x11, 0x07, 0xfd, 0x1f, 0x35, 0x1a, 0xd5, 0x99, 0xde,
+	0xfe, 0x1b, 0x00, 0x41, 0x60, 0xb1, 0x20, 0xbf, 0x03, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -215,8 +457,16 @@ const _ = grpc.SupportPackageIsVersion4
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type DebugClient interface {
 	Dump(ctx context.Context, in *DumpRequest, opts ...grpc.CallOption) (Debug_DumpClient, error)
+	// DumpArchive returns a gzipped tar archive of pachd's and workers'
+	// goroutines and heap profiles, recent logs, pipeline specs, jobs, etcd's
+	// health and the cluster's kubernetes objects, with the values of
+	// environment variables redacted. Only pachd serves it.
+	DumpArchive(ctx context.Context, in *DumpArchiveRequest, opts ...grpc.CallOption) (Debug_DumpArchiveClient, error)
 	Profile(ctx context.Context, in *ProfileRequest, opts ...grpc.CallOption) (Debug_ProfileClient, error)
 	Binary(ctx context.Context, in *BinaryRequest, opts ...grpc.CallOption) (Debug_BinaryClient, error)
+	// Masters returns the processes that hold (and are waiting for) the locks
+	// that elect the PPS master and pipelines' masters
+	Masters(ctx context.Context, in *MastersRequest, opts ...grpc.CallOption) (*MastersResponse, error)
 }
 
 type debugClient struct {
@@ -259,8 +509,40 @@ func (x *debugDumpClient) Recv() (*types.BytesValue, error) {
 	return m, nil
 }
 
+func (c *debugClient) DumpArchive(ctx context.Context, in *DumpArchiveRequest, opts ...grpc.CallOption) (Debug_DumpArchiveClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Debug_serviceDesc.Streams[1], "/debug.Debug/DumpArchive", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &debugDumpArchiveClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Debug_DumpArchiveClient interface {
+	Recv() (*types.BytesValue, error)
+	grpc.ClientStream
+}
+
+type debugDumpArchiveClient struct {
+	grpc.ClientStream
+}
+
+func (x *debugDumpArchiveClient) Recv() (*types.BytesValue, error) {
+	m := new(types.BytesValue)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *debugClient) Profile(ctx context.Context, in *ProfileRequest, opts ...grpc.CallOption) (Debug_ProfileClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Debug_serviceDesc.Streams[1], "/debug.Debug/Profile", opts...)
+	stream, err := c.cc.NewStream(ctx, &_Debug_serviceDesc.Streams[2], "/debug.Debug/Profile", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -292,7 +574,7 @@ func (x *debugProfileClient) Recv() (*types.BytesValue, error) {
 }
 
 func (c *debugClient) Binary(ctx context.Context, in *BinaryRequest, opts ...grpc.CallOption) (Debug_BinaryClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Debug_serviceDesc.Streams[2], "/debug.Debug/Binary", opts...)
+	stream, err := c.cc.NewStream(ctx, &_Debug_serviceDesc.Streams[3], "/debug.Debug/Binary", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -323,11 +605,28 @@ func (x *debugBinaryClient) Recv() (*types.BytesValue, error) {
 	return m, nil
 }
 
+func (c *debugClient) Masters(ctx context.Context, in *MastersRequest, opts ...grpc.CallOption) (*MastersResponse, error) {
+	out := new(MastersResponse)
+	err := c.cc.Invoke(ctx, "/debug.Debug/Masters", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DebugServer is the server API for Debug service.
 type DebugServer interface {
 	Dump(*DumpRequest, Debug_DumpServer) error
+	// DumpArchive returns a gzipped tar archive of pachd's and workers'
+	// goroutines and heap profiles, recent logs, pipeline specs, jobs, etcd's
+	// health and the cluster's kubernetes objects, with the values of
+	// environment variables redacted. Only pachd serves it.
+	DumpArchive(*DumpArchiveRequest, Debug_DumpArchiveServer) error
 	Profile(*ProfileRequest, Debug_ProfileServer) error
 	Binary(*BinaryRequest, Debug_BinaryServer) error
+	// Masters returns the processes that hold (and are waiting for) the locks
+	// that elect the PPS master and pipelines' masters
+	Masters(context.Context, *MastersRequest) (*MastersResponse, error)
 }
 
 // UnimplementedDebugServer can be embedded to have forward compatible implementations.
@@ -337,12 +636,18 @@ type UnimplementedDebugServer struct {
 func (*UnimplementedDebugServer) Dump(req *DumpRequest, srv Debug_DumpServer) error {
 	return status.Errorf(codes.Unimplemented, "method Dump not implemented")
 }
+func (*UnimplementedDebugServer) DumpArchive(req *DumpArchiveRequest, srv Debug_DumpArchiveServer) error {
+	return status.Errorf(codes.Unimplemented, "method DumpArchive not implemented")
+}
 func (*UnimplementedDebugServer) Profile(req *ProfileRequest, srv Debug_ProfileServer) error {
 	return status.Errorf(codes.Unimplemented, "method Profile not implemented")
 }
 func (*UnimplementedDebugServer) Binary(req *BinaryRequest, srv Debug_BinaryServer) error {
 	return status.Errorf(codes.Unimplemented, "method Binary not implemented")
 }
+func (*UnimplementedDebugServer) Masters(ctx context.Context, req *MastersRequest) (*MastersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Masters not implemented")
+}
 
 func RegisterDebugServer(s *grpc.Server, srv DebugServer) {
 	s.RegisterService(&_Debug_serviceDesc, srv)
@@ -369,6 +674,27 @@ func (x *debugDumpServer) Send(m *types.BytesValue) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Debug_DumpArchive_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DumpArchiveRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DebugServer).DumpArchive(m, &debugDumpArchiveServer{stream})
+}
+
+type Debug_DumpArchiveServer interface {
+	Send(*types.BytesValue) error
+	grpc.ServerStream
+}
+
+type debugDumpArchiveServer struct {
+	grpc.ServerStream
+}
+
+func (x *debugDumpArchiveServer) Send(m *types.BytesValue) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _Debug_Profile_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(ProfileRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -411,16 +737,44 @@ func (x *debugBinaryServer) Send(m *types.BytesValue) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Debug_Masters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MastersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebugServer).Masters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/debug.Debug/Masters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebugServer).Masters(ctx, req.(*MastersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Debug_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "debug.Debug",
 	HandlerType: (*DebugServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Masters",
+			Handler:    _Debug_Masters_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "Dump",
 			Handler:       _Debug_Dump_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "DumpArchive",
+			Handler:       _Debug_DumpArchive_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "Profile",
 			Handler:       _Debug_Profile_Handler,
@@ -472,6 +826,38 @@ func (m *DumpRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *DumpArchiveRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DumpArchiveRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DumpArchiveRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.LogLines != 0 {
+		i = encodeVarintDebug(dAtA, i, uint64(m.LogLines))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *ProfileRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -545,39 +931,189 @@ func (m *BinaryRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func encodeVarintDebug(dAtA []byte, offset int, v uint64) int {
-	offset -= sovDebug(v)
-	base := offset
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *MastersRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
-	dAtA[offset] = uint8(v)
-	return base
+	return dAtA[:n], nil
 }
-func (m *DumpRequest) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+
+func (m *MastersRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *MastersRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
-	if m.Recursed {
-		n += 2
-	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return len(dAtA) - i, nil
 }
 
-func (m *ProfileRequest) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	l = len(m.Profile)
+func (m *MasterLease) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MasterLease) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *MasterLease) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Standbys) > 0 {
+		for iNdEx := len(m.Standbys) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Standbys[iNdEx])
+			copy(dAtA[i:], m.Standbys[iNdEx])
+			i = encodeVarintDebug(dAtA, i, uint64(len(m.Standbys[iNdEx])))
+			i--
+			dAtA[i] = 0x2a
+		}
+	}
+	if m.Ttl != nil {
+		{
+			size, err := m.Ttl.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintDebug(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x22
+	}
+	if m.LeaseId != 0 {
+		i = encodeVarintDebug(dAtA, i, uint64(m.LeaseId))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Holder) > 0 {
+		i -= len(m.Holder)
+		copy(dAtA[i:], m.Holder)
+		i = encodeVarintDebug(dAtA, i, uint64(len(m.Holder)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintDebug(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *MastersResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MastersResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *MastersResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Masters) > 0 {
+		for iNdEx := len(m.Masters) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Masters[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintDebug(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintDebug(dAtA []byte, offset int, v uint64) int {
+	offset -= sovDebug(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *DumpRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Recursed {
+		n += 2
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *DumpArchiveRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.LogLines != 0 {
+		n += 1 + sovDebug(uint64(m.LogLines))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ProfileRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Profile)
 	if l > 0 {
 		n += 1 + l + sovDebug(uint64(l))
 	}
@@ -603,6 +1139,69 @@ func (m *BinaryRequest) Size() (n int) {
 	return n
 }
 
+func (m *MastersRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *MasterLease) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovDebug(uint64(l))
+	}
+	l = len(m.Holder)
+	if l > 0 {
+		n += 1 + l + sovDebug(uint64(l))
+	}
+	if m.LeaseId != 0 {
+		n += 1 + sovDebug(uint64(m.LeaseId))
+	}
+	if m.Ttl != nil {
+		l = m.Ttl.Size()
+		n += 1 + l + sovDebug(uint64(l))
+	}
+	if len(m.Standbys) > 0 {
+		for _, s := range m.Standbys {
+			l = len(s)
+			n += 1 + l + sovDebug(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *MastersResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Masters) > 0 {
+		for _, e := range m.Masters {
+			l = e.Size()
+			n += 1 + l + sovDebug(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
 func sovDebug(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -683,6 +1282,79 @@ func (m *DumpRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *DumpArchiveRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDebug
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DumpArchiveRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DumpArchiveRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LogLines", wireType)
+			}
+			m.LogLines = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDebug
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.LogLines |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDebug(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthDebug
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthDebug
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *ProfileRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -859,6 +1531,353 @@ func (m *BinaryRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *MastersRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDebug
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MastersRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MastersRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDebug(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthDebug
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthDebug
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MasterLease) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDebug
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MasterLease: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MasterLease: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDebug
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDebug
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDebug
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Holder", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDebug
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDebug
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDebug
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Holder = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaseId", wireType)
+			}
+			m.LeaseId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDebug
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.LeaseId |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ttl", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDebug
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthDebug
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthDebug
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Ttl == nil {
+				m.Ttl = &types.Duration{}
+			}
+			if err := m.Ttl.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Standbys", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDebug
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDebug
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDebug
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Standbys = append(m.Standbys, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDebug(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthDebug
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthDebug
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MastersResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDebug
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MastersResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MastersResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Masters", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDebug
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthDebug
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthDebug
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Masters = append(m.Masters, &MasterLease{})
+			if err := m.Masters[len(m.Masters)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDebug(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthDebug
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthDebug
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipDebug(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"io"
 	"sync"
+	"time"
 
 	"github.com/gogo/protobuf/types"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errcode"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/client/version"
 	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
 )
 
@@ -119,6 +122,36 @@ func (c APIClient) InspectRepo(repoName string) (*pfs.RepoInfo, error) {
 	return resp, nil
 }
 
+// GetRepoStorageUsage reports the logical size of all of the commits in a
+// repo, and the physical size of the (deduplicated) data that they reference.
+func (c APIClient) GetRepoStorageUsage(repoName string) (*pfs.StorageUsage, error) {
+	resp, err := c.PfsAPIClient.GetStorageUsage(
+		c.Ctx(),
+		&pfs.GetStorageUsageRequest{
+			Repo: NewRepo(repoName),
+		},
+	)
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureStorageUsage, err)
+	}
+	return resp, nil
+}
+
+// GetCommitStorageUsage reports the logical size of a commit, and the
+// physical size of the (deduplicated) data that it references.
+func (c APIClient) GetCommitStorageUsage(repoName string, commitID string) (*pfs.StorageUsage, error) {
+	resp, err := c.PfsAPIClient.GetStorageUsage(
+		c.Ctx(),
+		&pfs.GetStorageUsageRequest{
+			Commit: NewCommit(repoName, commitID),
+		},
+	)
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureStorageUsage, err)
+	}
+	return resp, nil
+}
+
 // ListRepo returns info about all Repos.
 // provenance specifies a set of provenance repos, only repos which have ALL of
 // the specified repos as provenance will be returned unless provenance is nil
@@ -180,6 +213,24 @@ func (c APIClient) StartCommit(repoName string, branch string) (*pfs.Commit, err
 	return commit, nil
 }
 
+// StartCommitWithMetadata is the same as StartCommit except that the commit
+// carries 'metadata' (e.g. the system or batch that its data came from),
+// which is passed to the jobs that process it.
+func (c APIClient) StartCommitWithMetadata(repoName string, branch string, metadata map[string]string) (*pfs.Commit, error) {
+	commit, err := c.PfsAPIClient.StartCommit(
+		c.Ctx(),
+		&pfs.StartCommitRequest{
+			Parent:   NewCommit(repoName, ""),
+			Branch:   branch,
+			Metadata: metadata,
+		},
+	)
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return commit, nil
+}
+
 // BuildCommit builds a commit in a single call from an existing HashTree that
 // has already been written to the object store. Note this is a more advanced
 // pattern for creating commits that's mostly used internally.
@@ -336,6 +387,35 @@ func (c APIClient) ListCommitByRepo(repoName string) ([]*pfs.CommitInfo, error)
 	return c.ListCommit(repoName, "", "", 0)
 }
 
+// WatchCommit calls f with every commit in the repo 'repoName' (only those
+// made on 'branch', if it's set), and then again with each commit whenever it
+// changes. It returns when f returns an error (errutil.ErrBreak causes
+// WatchCommit to return nil) or c's context is cancelled.
+func (c APIClient) WatchCommit(repoName string, branch string, f func(*pfs.CommitInfo) error) error {
+	req := &pfs.ListCommitRequest{Repo: NewRepo(repoName)}
+	if branch != "" {
+		req.To = NewCommit(repoName, branch)
+	}
+	stream, err := c.PfsAPIClient.WatchCommit(c.Ctx(), req)
+	if err != nil {
+		return c.scrubFeatureGRPC(version.FeaturePFSWatch, err)
+	}
+	for {
+		ci, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return grpcutil.ScrubGRPC(err)
+		}
+		if err := f(ci); err != nil {
+			if err == errutil.ErrBreak {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
 // CreateBranch creates a new branch
 func (c APIClient) CreateBranch(repoName string, branch string, commit string, provenance []*pfs.Branch) error {
 	var head *pfs.Commit
@@ -378,6 +458,31 @@ func (c APIClient) ListBranch(repoName string) ([]*pfs.BranchInfo, error) {
 	return branchInfos.BranchInfo, nil
 }
 
+// WatchBranch calls f with every branch in the repo 'repoName', and then again
+// with each branch whenever it changes. It returns when f returns an error
+// (errutil.ErrBreak causes WatchBranch to return nil) or c's context is
+// cancelled.
+func (c APIClient) WatchBranch(repoName string, f func(*pfs.BranchInfo) error) error {
+	stream, err := c.PfsAPIClient.WatchBranch(c.Ctx(), &pfs.ListBranchRequest{Repo: NewRepo(repoName)})
+	if err != nil {
+		return c.scrubFeatureGRPC(version.FeaturePFSWatch, err)
+	}
+	for {
+		bi, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return grpcutil.ScrubGRPC(err)
+		}
+		if err := f(bi); err != nil {
+			if err == errutil.ErrBreak {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
 // SetBranch sets a commit and its ancestors as a branch.
 // SetBranch is deprecated in favor of CreateBranch.
 func (c APIClient) SetBranch(repoName string, commit string, branch string) error {
@@ -398,6 +503,116 @@ func (c APIClient) DeleteBranch(repoName string, branch string, force bool) erro
 	return grpcutil.ScrubGRPC(err)
 }
 
+// SetBranchRetention sets the retention policy of a branch. Garbage
+// collection deletes the ancestors of the branch's head that are neither among
+// its last 'history' commits nor younger than 'maxAge'. Zero values of
+// 'history' and 'maxAge' don't retain anything, and passing both removes the
+// branch's retention policy.
+func (c APIClient) SetBranchRetention(repoName string, branch string, history int64, maxAge time.Duration) error {
+	var retention *pfs.RetentionPolicy
+	if history != 0 || maxAge != 0 {
+		retention = &pfs.RetentionPolicy{History: history}
+		if maxAge != 0 {
+			retention.MaxAge = types.DurationProto(maxAge)
+		}
+	}
+	_, err := c.PfsAPIClient.SetBranchRetention(
+		c.Ctx(),
+		&pfs.SetBranchRetentionRequest{
+			Branch:    NewBranch(repoName, branch),
+			Retention: retention,
+		},
+	)
+	if err != nil {
+		return c.scrubFeatureGRPC(version.FeatureBranchRetention, err)
+	}
+	return nil
+}
+
+// SetBranchTrigger sets the trigger of a branch, which moves the branch to the
+// head of 'trigger.Branch' when a commit is finished there and the trigger's
+// conditions are met. Passing a nil trigger removes the branch's trigger.
+func (c APIClient) SetBranchTrigger(repoName string, branch string, trigger *pfs.Trigger) error {
+	_, err := c.PfsAPIClient.SetBranchTrigger(
+		c.Ctx(),
+		&pfs.SetBranchTriggerRequest{
+			Branch:  NewBranch(repoName, branch),
+			Trigger: trigger,
+		},
+	)
+	if err != nil {
+		return c.scrubFeatureGRPC(version.FeatureBranchTriggers, err)
+	}
+	return nil
+}
+
+// CreateModelVersion registers repoName@commitID, which must be finished, as
+// a new version of the model in repoName. If 'stage' is set, the version is
+// also labelled with it (moving the label from the version that had it).
+// Registering a commit that's already a version returns that version.
+func (c APIClient) CreateModelVersion(repoName string, commitID string, description string, stage string) (*pfs.ModelVersion, error) {
+	modelVersion, err := c.PfsAPIClient.CreateModelVersion(
+		c.Ctx(),
+		&pfs.CreateModelVersionRequest{
+			Commit:      NewCommit(repoName, commitID),
+			Description: description,
+			Stage:       stage,
+		},
+	)
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureModels, err)
+	}
+	return modelVersion, nil
+}
+
+// PromoteModel labels version 'modelVersion' of the model in repoName with
+// 'stage', atomically moving the label from the version that had it.
+func (c APIClient) PromoteModel(repoName string, modelVersion uint64, stage string) (*pfs.ModelVersion, error) {
+	result, err := c.PfsAPIClient.PromoteModel(
+		c.Ctx(),
+		&pfs.PromoteModelRequest{
+			Repo:    NewRepo(repoName),
+			Version: modelVersion,
+			Stage:   stage,
+		},
+	)
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureModels, err)
+	}
+	return result, nil
+}
+
+// InspectModel returns the versions of the model in repoName, and the stages
+// that they're labelled with.
+func (c APIClient) InspectModel(repoName string) (*pfs.ModelInfo, error) {
+	modelInfo, err := c.PfsAPIClient.InspectModel(
+		c.Ctx(),
+		&pfs.InspectModelRequest{
+			Repo: NewRepo(repoName),
+		},
+	)
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureModels, err)
+	}
+	return modelInfo, nil
+}
+
+// ResolveModel returns the version of the model in repoName that's labelled
+// with 'stage'. Serving pipelines use this to find the commit to serve.
+func (c APIClient) ResolveModel(repoName string, stage string) (*pfs.ModelVersion, error) {
+	modelVersion, err := c.PfsAPIClient.ResolveModel(
+		c.Ctx(),
+		&pfs.ResolveModelRequest{
+			Repo:  NewRepo(repoName),
+			Stage: stage,
+		},
+	)
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureModels, err)
+	}
+	return modelVersion, nil
+}
+
 // DeleteCommit deletes a commit.
 func (c APIClient) DeleteCommit(repoName string, commitID string) error {
 	_, err := c.PfsAPIClient.DeleteCommit(
@@ -931,6 +1146,7 @@ type putFileClient struct {
 	c      pfs.API_PutFileClient
 	mu     sync.Mutex
 	oneoff bool // indicates a one time use putFileClient
+	mode   errcode.BulkMode
 }
 
 // NewPutFileClient returns a new client for putting files into pfs in a single request.
@@ -942,6 +1158,18 @@ func (c APIClient) NewPutFileClient() (PutFileClient, error) {
 	return &putFileClient{c: pfc}, nil
 }
 
+// NewPutFileClientWithMode is like NewPutFileClient, but lets callers choose
+// what happens when some of the files can't be put. With
+// errcode.BulkMode_BEST_EFFORT, the files that can be put are, and Close
+// returns an *errcode.BulkError describing the files that failed.
+func (c APIClient) NewPutFileClientWithMode(mode errcode.BulkMode) (PutFileClient, error) {
+	pfc, err := c.PfsAPIClient.PutFile(c.Ctx())
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return &putFileClient{c: pfc, mode: mode}, nil
+}
+
 func (c APIClient) newOneoffPutFileClient() (PutFileClient, error) {
 	pfc, err := c.PfsAPIClient.PutFile(c.Ctx())
 	if err != nil {
@@ -1034,6 +1262,7 @@ func (c *putFileClient) PutFileURL(repoName string, commitID string, path string
 		Url:            url,
 		Recursive:      recursive,
 		OverwriteIndex: overwriteIndex,
+		Mode:           c.mode,
 	}); err != nil {
 		return grpcutil.ScrubGRPC(err)
 	}
@@ -1043,8 +1272,11 @@ func (c *putFileClient) PutFileURL(repoName string, commitID string, path string
 // Close must be called after you're done using a putFileClient.
 // Further requests will throw errors.
 func (c *putFileClient) Close() error {
-	_, err := c.c.CloseAndRecv()
-	return grpcutil.ScrubGRPC(err)
+	response, err := c.c.CloseAndRecv()
+	if err != nil {
+		return grpcutil.ScrubGRPC(err)
+	}
+	return response.Err()
 }
 
 // PutFileWriter writes a file to PFS.
@@ -1162,25 +1394,35 @@ func (c APIClient) GetFileReader(repoName string, commitID string, path string,
 }
 
 // GetFileReadSeeker returns a reader for the contents of a file at a specific
-// Commit that permits Seeking to different points in the file.
+// Commit that permits Seeking to different points in the file. The file's
+// content is only requested when it's read, starting from the current offset,
+// so seeking doesn't download any data. The returned reader also implements
+// io.ReaderAt (see GetFileReaderAt).
 func (c APIClient) GetFileReadSeeker(repoName string, commitID string, path string) (io.ReadSeeker, error) {
 	fileInfo, err := c.InspectFile(repoName, commitID, path)
 	if err != nil {
 		return nil, err
 	}
-	reader, err := c.GetFileReader(repoName, commitID, path, 0, 0)
-	if err != nil {
-		return nil, err
-	}
 	return &getFileReadSeeker{
-		Reader: reader,
-		file:   NewFile(repoName, commitID, path),
-		offset: 0,
-		size:   int64(fileInfo.SizeBytes),
-		c:      c,
+		file: NewFile(repoName, commitID, path),
+		size: int64(fileInfo.SizeBytes),
+		c:    c,
 	}, nil
 }
 
+// GetFileReaderAt returns an io.ReaderAt for the contents of a file at a
+// specific Commit, along with the file's size. Each ReadAt call only
+// downloads the range of the file that it reads, so readers that read a
+// file's footer and then parts of its body (e.g. Parquet readers) don't need
+// to download the whole file. ReadAt may be called concurrently.
+func (c APIClient) GetFileReaderAt(repoName string, commitID string, path string) (io.ReaderAt, int64, error) {
+	r, err := c.GetFileReadSeeker(repoName, commitID, path)
+	if err != nil {
+		return nil, 0, err
+	}
+	return r.(*getFileReadSeeker), r.(*getFileReadSeeker).size, nil
+}
+
 func (c APIClient) getFile(repoName string, commitID string, path string, offset int64,
 	size int64) (pfs.API_GetFileClient, error) {
 	return c.PfsAPIClient.GetFile(
@@ -1345,6 +1587,43 @@ func (c APIClient) DiffFile(newRepoName, newCommitID, newPath, oldRepoName,
 	return resp.NewFiles, resp.OldFiles, nil
 }
 
+// DiffFileF is like DiffFile, but streams the differences, calling f with
+// each changed file's old and new versions. If detectRenames is set, deleted
+// files whose contents match an added file are reported as renames.
+func (c APIClient) DiffFileF(newRepoName, newCommitID, newPath, oldRepoName,
+	oldCommitID, oldPath string, shallow bool, detectRenames bool, f func(*pfs.FileDiff) error) error {
+	var oldFile *pfs.File
+	if oldRepoName != "" {
+		oldFile = NewFile(oldRepoName, oldCommitID, oldPath)
+	}
+	diffClient, err := c.PfsAPIClient.DiffFileStream(
+		c.Ctx(),
+		&pfs.DiffFileRequest{
+			NewFile:       NewFile(newRepoName, newCommitID, newPath),
+			OldFile:       oldFile,
+			Shallow:       shallow,
+			DetectRenames: detectRenames,
+		},
+	)
+	if err != nil {
+		return c.scrubFeatureGRPC(version.FeatureDiffFileStream, err)
+	}
+	for {
+		fileDiff, err := diffClient.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return grpcutil.ScrubGRPC(err)
+		}
+		if err := f(fileDiff); err != nil {
+			if err == errutil.ErrBreak {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
 // WalkFn is the type of the function called for each file in Walk.
 // Returning a non-nil error from WalkFn will result in Walk aborting and
 // returning said error.
@@ -1391,6 +1670,25 @@ func (c APIClient) DeleteFile(repoName string, commitID string, path string) err
 	return grpcutil.ScrubGRPC(err)
 }
 
+// DeleteFiles deletes several files, which may be in different commits, in
+// one request. With errcode.BulkMode_ATOMIC, either all of the files are
+// deleted or none are. With errcode.BulkMode_BEST_EFFORT, the files that can
+// be deleted are, and the response reports which files couldn't be (its Err
+// method returns them as a single error).
+func (c APIClient) DeleteFiles(mode errcode.BulkMode, files ...*pfs.File) (*errcode.BulkResponse, error) {
+	response, err := c.PfsAPIClient.DeleteFiles(
+		c.Ctx(),
+		&pfs.DeleteFilesRequest{
+			Files: files,
+			Mode:  mode,
+		},
+	)
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureBulkResults, err)
+	}
+	return response, nil
+}
+
 type putFileWriteCloser struct {
 	request *pfs.PutFileRequest
 	sent    bool
@@ -1457,6 +1755,7 @@ func (c *putFileClient) newPutFileWriteCloser(repoName string, commitID string,
 			TargetFileBytes:  targetFileBytes,
 			HeaderRecords:    headerRecords,
 			OverwriteIndex:   overwriteIndex,
+			Mode:             c.mode,
 		},
 		c: c,
 	}, nil
@@ -1682,43 +1981,89 @@ func (w *putObjectSplitWriteCloser) Close() error {
 }
 
 type getFileReadSeeker struct {
-	io.Reader
 	file   *pfs.File
 	offset int64
 	size   int64
 	c      APIClient
+
+	// reader reads the file from 'offset', and is nil until the file is read
+	// (and after each Seek). cancel ends the GetFile call that reader reads.
+	reader io.Reader
+	cancel context.CancelFunc
 }
 
-func (r *getFileReadSeeker) Seek(offset int64, whence int) (int64, error) {
-	getFileReader := func(offset int64) (io.Reader, error) {
-		return r.c.GetFileReader(r.file.Commit.Repo.Name, r.file.Commit.ID, r.file.Path, offset, 0)
+func (r *getFileReadSeeker) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
 	}
-	switch whence {
-	case io.SeekStart:
-		reader, err := getFileReader(offset)
+	if r.reader == nil {
+		ctx, cancel := context.WithCancel(r.c.Ctx())
+		reader, err := r.c.WithCtx(ctx).GetFileReader(r.file.Commit.Repo.Name, r.file.Commit.ID, r.file.Path, r.offset, 0)
 		if err != nil {
-			return r.offset, err
+			cancel()
+			return 0, err
 		}
-		r.offset = offset
-		r.Reader = reader
+		r.reader, r.cancel = reader, cancel
+	}
+	n, err := r.reader.Read(p)
+	r.offset += int64(n)
+	if err != nil {
+		r.cancel()
+		r.reader, r.cancel = nil, nil
+	}
+	return n, err
+}
+
+func (r *getFileReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
 	case io.SeekCurrent:
-		reader, err := getFileReader(r.offset + offset)
-		if err != nil {
-			return r.offset, err
-		}
-		r.offset += offset
-		r.Reader = reader
+		offset += r.offset
 	case io.SeekEnd:
-		reader, err := getFileReader(r.size - offset)
-		if err != nil {
-			return r.offset, err
-		}
-		r.offset = r.size - offset
-		r.Reader = reader
+		offset += r.size
+	default:
+		return r.offset, fmt.Errorf("invalid whence: %d", whence)
+	}
+	if offset < 0 {
+		return r.offset, fmt.Errorf("cannot seek to negative offset %d", offset)
 	}
+	if offset != r.offset && r.reader != nil {
+		r.cancel()
+		r.reader, r.cancel = nil, nil
+	}
+	r.offset = offset
 	return r.offset, nil
 }
 
+// ReadAt reads len(p) bytes of the file, starting at 'offset'. It doesn't
+// affect the offset used by Read and Seek.
+func (r *getFileReadSeeker) ReadAt(p []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, fmt.Errorf("cannot read at negative offset %d", offset)
+	}
+	if offset >= r.size {
+		return 0, io.EOF
+	}
+	size := int64(len(p))
+	if offset+size > r.size {
+		size = r.size - offset
+	}
+	ctx, cancel := context.WithCancel(r.c.Ctx())
+	defer cancel()
+	reader, err := r.c.WithCtx(ctx).GetFileReader(r.file.Commit.Repo.Name, r.file.Commit.ID, r.file.Path, offset, size)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(reader, p[:size])
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	if err == nil && size < int64(len(p)) {
+		err = io.EOF
+	}
+	return n, err
+}
+
 type putBlockWriteCloser struct {
 	request *pfs.PutBlockRequest
 	client  pfs.ObjectAPI_PutBlockClient
@@ -312,10 +312,25 @@ type Context struct {
 	// A unique ID for the cluster deployment. At client initialization time,
 	// we ensure this is the same as what the cluster reports back, to prevent
 	// us from connecting to the wrong cluster.
-	ClusterDeploymentID  string   `protobuf:"bytes,11,opt,name=cluster_deployment_id,json=clusterDeploymentId,proto3" json:"cluster_deployment_id,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	ClusterDeploymentID string `protobuf:"bytes,11,opt,name=cluster_deployment_id,json=clusterDeploymentId,proto3" json:"cluster_deployment_id,omitempty"`
+	// An HTTP proxy (http://[user:password@]host:port) that connections to
+	// pachd_address are tunneled through with HTTP CONNECT. If unset, the
+	// standard HTTPS_PROXY and NO_PROXY environment variables are respected.
+	ProxyURL string `protobuf:"bytes,12,opt,name=proxy_url,json=proxyUrl,proto3" json:"proxy_url,omitempty"`
+	// The name that pachd's TLS certificate is verified against, if it isn't
+	// pachd_address's host (e.g. if pachd is behind a load balancer whose
+	// address isn't in pachd's certificate).
+	TLSServerName string `protobuf:"bytes,13,opt,name=tls_server_name,json=tlsServerName,proto3" json:"tls_server_name,omitempty"`
+	// How often, in seconds, pachctl pings pachd over an idle connection, so
+	// that ingresses and load balancers don't drop it. If unset, pachctl
+	// pings pachd every 20 seconds.
+	KeepaliveSeconds int64 `protobuf:"varint,14,opt,name=keepalive_seconds,json=keepaliveSeconds,proto3" json:"keepalive_seconds,omitempty"`
+	// If set, requests that can't reach pachd are retried for up to this many
+	// seconds while pachctl reconnects, rather than failing immediately.
+	ReconnectTimeoutSeconds int64    `protobuf:"varint,15,opt,name=reconnect_timeout_seconds,json=reconnectTimeoutSeconds,proto3" json:"reconnect_timeout_seconds,omitempty"`
+	XXX_NoUnkeyedLiteral    struct{} `json:"-"`
+	XXX_unrecognized        []byte   `json:"-"`
+	XXX_sizecache           int32    `json:"-"`
 }
 
 func (m *Context) Reset()         { *m = Context{} }
@@ -421,6 +436,34 @@ func (m *Context) GetClusterDeploymentID() string {
 	return ""
 }
 
+func (m *Context) GetProxyURL() string {
+	if m != nil {
+		return m.ProxyURL
+	}
+	return ""
+}
+
+func (m *Context) GetTLSServerName() string {
+	if m != nil {
+		return m.TLSServerName
+	}
+	return ""
+}
+
+func (m *Context) GetKeepaliveSeconds() int64 {
+	if m != nil {
+		return m.KeepaliveSeconds
+	}
+	return 0
+}
+
+func (m *Context) GetReconnectTimeoutSeconds() int64 {
+	if m != nil {
+		return m.ReconnectTimeoutSeconds
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterEnum("config.ContextSource", ContextSource_name, ContextSource_value)
 	proto.RegisterType((*Config)(nil), "config.Config")
@@ -434,50 +477,56 @@ func init() {
 func init() { proto.RegisterFile("client/pkg/config/config.proto", fileDescriptor_60f651abce1dcdf3) }
 
 var fileDescriptor_60f651abce1dcdf3 = []byte{
-	// 679 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x54, 0xcd, 0x6e, 0xda, 0x4a,
-	0x18, 0xbd, 0x06, 0x02, 0xf6, 0x07, 0x24, 0xdc, 0x21, 0xd1, 0xb5, 0x72, 0x2b, 0x92, 0x12, 0x45,
-	0x8a, 0xaa, 0x06, 0x84, 0xdb, 0x45, 0x95, 0x4d, 0x15, 0x20, 0x69, 0x69, 0x53, 0x12, 0x39, 0x3f,
-	0x8b, 0x6e, 0x2c, 0xc7, 0x1e, 0xc0, 0x0a, 0xf6, 0xb8, 0x33, 0x03, 0x0d, 0x8f, 0xd0, 0xc7, 0xe8,
-	0x23, 0xf4, 0x2d, 0xba, 0xec, 0x13, 0x44, 0x15, 0x4f, 0x52, 0xcd, 0x8c, 0x21, 0xe4, 0xa7, 0x52,
-	0x57, 0x5d, 0x31, 0x3e, 0xe7, 0x7c, 0x7f, 0xf3, 0x1d, 0x06, 0x2a, 0xde, 0x30, 0xc0, 0x11, 0xaf,
-	0xc7, 0x57, 0xfd, 0xba, 0x47, 0xa2, 0x5e, 0x30, 0xfb, 0xa9, 0xc5, 0x94, 0x70, 0x82, 0xb2, 0xea,
-	0x6b, 0x7d, 0xb5, 0x4f, 0xfa, 0x44, 0x42, 0x75, 0x71, 0x52, 0x6c, 0xf5, 0x13, 0x64, 0x5b, 0x92,
-	0x47, 0x5b, 0x90, 0x1b, 0x31, 0x4c, 0x9d, 0xc0, 0x37, 0xb5, 0x4d, 0x6d, 0xc7, 0x68, 0xc2, 0xf4,
-	0x66, 0x23, 0x7b, 0xce, 0x30, 0xed, 0xb4, 0xed, 0xac, 0xa0, 0x3a, 0x3e, 0xda, 0x84, 0xd4, 0xb8,
-	0x61, 0xa6, 0x36, 0xb5, 0x9d, 0xbc, 0x55, 0xaa, 0x25, 0x75, 0x54, 0x82, 0x8b, 0x86, 0x9d, 0x1a,
-	0x37, 0xa4, 0xc2, 0x32, 0xd3, 0x8f, 0x2a, 0x2c, 0x3b, 0x35, 0xb6, 0xaa, 0xdf, 0x34, 0xd0, 0x67,
-	0x21, 0x68, 0x0b, 0x8a, 0xb1, 0xeb, 0x0d, 0x7c, 0xc7, 0xf5, 0x7d, 0x8a, 0x19, 0x93, 0xb9, 0x0d,
-	0xbb, 0x20, 0xc1, 0x7d, 0x85, 0xa1, 0xe7, 0x00, 0x0c, 0xd3, 0x31, 0xa6, 0x8e, 0xe7, 0x32, 0x99,
-	0xdb, 0x68, 0x16, 0xa7, 0x37, 0x1b, 0xc6, 0xa9, 0x44, 0x5b, 0xfb, 0xcc, 0x36, 0x94, 0xa0, 0xe5,
-	0x32, 0x91, 0x92, 0x61, 0xc6, 0x02, 0x12, 0x39, 0x9c, 0x5c, 0xe1, 0x48, 0x8d, 0x63, 0x17, 0x12,
-	0xf0, 0x4c, 0x60, 0x68, 0x17, 0x90, 0xeb, 0xf1, 0x60, 0x8c, 0x1d, 0x4e, 0xdd, 0x88, 0x89, 0x33,
-	0x89, 0xcc, 0x8c, 0x54, 0xfe, 0xab, 0x98, 0xb3, 0x5b, 0xa2, 0xfa, 0x25, 0x35, 0xef, 0xd9, 0x42,
-	0xdb, 0xb0, 0x9c, 0xc4, 0x7a, 0x24, 0xe2, 0xf8, 0x9a, 0x27, 0x15, 0x8a, 0x0a, 0x6d, 0x29, 0x10,
-	0xed, 0x81, 0x9e, 0xf0, 0x62, 0xaa, 0xf4, 0x4e, 0xde, 0xaa, 0xdc, 0xbf, 0x8f, 0x5a, 0xa2, 0x65,
-	0x07, 0x11, 0xa7, 0x13, 0x7b, 0xae, 0x47, 0x26, 0xe4, 0x42, 0xcc, 0x69, 0xe0, 0xa9, 0x71, 0x75,
-	0x7b, 0xf6, 0x89, 0x2c, 0x58, 0x0b, 0xdd, 0x6b, 0x87, 0x0d, 0xf0, 0x70, 0xe8, 0x78, 0x24, 0x8c,
-	0x87, 0x58, 0x74, 0xc8, 0x64, 0xef, 0x69, 0xbb, 0x1c, 0xba, 0xd7, 0xa7, 0x82, 0x6b, 0xdd, 0x52,
-	0xeb, 0x47, 0x50, 0xbc, 0x53, 0x08, 0x95, 0x20, 0x7d, 0x85, 0x27, 0x49, 0xdb, 0xe2, 0x88, 0xb6,
-	0x61, 0x69, 0xec, 0x0e, 0x47, 0x38, 0xd9, 0xed, 0xca, 0x42, 0xa7, 0x22, 0xce, 0x56, 0xec, 0x5e,
-	0xea, 0x95, 0x56, 0xfd, 0x9a, 0x81, 0xdc, 0x6c, 0xc6, 0x5d, 0xc8, 0x32, 0x32, 0xa2, 0x1e, 0x96,
-	0xb9, 0x96, 0xad, 0xb5, 0x7b, 0x71, 0xa7, 0x92, 0xb4, 0x13, 0xd1, 0x5f, 0xd9, 0x76, 0xe6, 0x8f,
-	0xb7, 0xbd, 0xf4, 0x9b, 0x6d, 0xa3, 0xa7, 0x50, 0xf0, 0x86, 0x23, 0xc6, 0x31, 0x75, 0x22, 0x37,
-	0xc4, 0x66, 0x56, 0x0a, 0xf3, 0x09, 0xd6, 0x75, 0x43, 0x8c, 0xfe, 0x07, 0xc3, 0x1d, 0xf1, 0x81,
-	0x13, 0x44, 0x3d, 0x62, 0xe6, 0x24, 0xaf, 0x0b, 0xa0, 0x13, 0xf5, 0x08, 0x7a, 0x02, 0x86, 0x88,
-	0x63, 0xb1, 0xeb, 0x61, 0x53, 0x97, 0xe4, 0x2d, 0x80, 0x8e, 0x60, 0x25, 0x26, 0x94, 0x3b, 0x3d,
-	0x42, 0x3f, 0xbb, 0xd4, 0xc7, 0x94, 0x99, 0x20, 0xed, 0xb1, 0x75, 0xef, 0xf2, 0x6a, 0x27, 0x84,
-	0xf2, 0xc3, 0xb9, 0x4a, 0x79, 0x64, 0x39, 0xbe, 0x03, 0xa2, 0xf7, 0xb0, 0x36, 0xeb, 0xd5, 0xc7,
-	0xf1, 0x90, 0x4c, 0x42, 0x1c, 0x71, 0xf1, 0x27, 0xce, 0xcb, 0x8b, 0xfb, 0x6f, 0x7a, 0xb3, 0x51,
-	0x6e, 0x29, 0x41, 0x7b, 0xce, 0x77, 0xda, 0x76, 0xd9, 0x7b, 0x00, 0xfa, 0xeb, 0xfb, 0x50, 0x7e,
-	0xa4, 0xe6, 0x23, 0x76, 0x59, 0x5d, 0xb4, 0x4b, 0x71, 0xc1, 0x1d, 0xef, 0x32, 0xba, 0x51, 0x82,
-	0x67, 0xaf, 0xe7, 0x8e, 0x53, 0x0e, 0x40, 0x3a, 0x64, 0xba, 0xc7, 0xdd, 0x83, 0xd2, 0x3f, 0xa8,
-	0x08, 0x46, 0xeb, 0xb8, 0x7b, 0xd8, 0x79, 0xe3, 0x5c, 0x34, 0x4a, 0x1a, 0xca, 0x41, 0xfa, 0xed,
-	0x79, 0xb3, 0x94, 0x42, 0x05, 0xd0, 0x3b, 0x1f, 0x4e, 0x8e, 0xed, 0xb3, 0x83, 0x76, 0x29, 0xdd,
-	0x6c, 0x7e, 0x9f, 0x56, 0xb4, 0x1f, 0xd3, 0x8a, 0xf6, 0x73, 0x5a, 0xd1, 0x3e, 0xbe, 0xec, 0x07,
-	0x7c, 0x30, 0xba, 0xac, 0x79, 0x24, 0xac, 0x0b, 0xaf, 0x4c, 0x7c, 0x4c, 0x17, 0x4f, 0x8c, 0x7a,
-	0xf5, 0x07, 0xcf, 0xe0, 0x65, 0x56, 0x3e, 0x71, 0x2f, 0x7e, 0x05, 0x00, 0x00, 0xff, 0xff, 0x16,
-	0x1c, 0xdd, 0x64, 0x22, 0x05, 0x00, 0x00,
+	// 784 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x54, 0xdd, 0x4e, 0xe3, 0x46,
+	0x14, 0xae, 0x13, 0x48, 0xec, 0x43, 0x0c, 0x61, 0x58, 0xb4, 0x2e, 0xad, 0x12, 0x0a, 0x5a, 0x89,
+	0xfe, 0x6c, 0x22, 0xd2, 0x5e, 0xb4, 0xdc, 0x54, 0x24, 0xb0, 0x6d, 0x5a, 0x0a, 0x68, 0x12, 0xf6,
+	0xa2, 0x37, 0x96, 0x77, 0x3c, 0x80, 0x85, 0xed, 0x71, 0x67, 0xc6, 0x29, 0x79, 0x84, 0x3e, 0x4e,
+	0xdf, 0xa2, 0x97, 0x7d, 0x82, 0xa8, 0xf2, 0x13, 0xf4, 0x11, 0xaa, 0x99, 0x71, 0x42, 0x60, 0xa9,
+	0xd4, 0xab, 0x5e, 0xe5, 0xcc, 0xf7, 0x73, 0xe6, 0xcc, 0x39, 0x27, 0x86, 0x16, 0x89, 0x23, 0x9a,
+	0xca, 0x6e, 0x76, 0x77, 0xd3, 0x25, 0x2c, 0xbd, 0x8e, 0xe6, 0x3f, 0x9d, 0x8c, 0x33, 0xc9, 0x50,
+	0xcd, 0x9c, 0x76, 0x5e, 0xdc, 0xb0, 0x1b, 0xa6, 0xa1, 0xae, 0x8a, 0x0c, 0xbb, 0xf7, 0x0b, 0xd4,
+	0x06, 0x9a, 0x47, 0xfb, 0x50, 0xcf, 0x05, 0xe5, 0x7e, 0x14, 0x7a, 0xd6, 0xae, 0x75, 0xe0, 0xf4,
+	0xa1, 0x98, 0xb5, 0x6b, 0x57, 0x82, 0xf2, 0xe1, 0x09, 0xae, 0x29, 0x6a, 0x18, 0xa2, 0x5d, 0xa8,
+	0x4c, 0x0e, 0xbd, 0xca, 0xae, 0x75, 0xb0, 0xd6, 0x6b, 0x76, 0xca, 0x7b, 0x4c, 0x82, 0xb7, 0x87,
+	0xb8, 0x32, 0x39, 0xd4, 0x8a, 0x9e, 0x57, 0x7d, 0x56, 0xd1, 0xc3, 0x95, 0x49, 0x6f, 0xef, 0x77,
+	0x0b, 0xec, 0xb9, 0x05, 0xed, 0x83, 0x9b, 0x05, 0xe4, 0x36, 0xf4, 0x83, 0x30, 0xe4, 0x54, 0x08,
+	0x9d, 0xdb, 0xc1, 0x0d, 0x0d, 0x1e, 0x1b, 0x0c, 0x7d, 0x01, 0x20, 0x28, 0x9f, 0x50, 0xee, 0x93,
+	0x40, 0xe8, 0xdc, 0x4e, 0xdf, 0x2d, 0x66, 0x6d, 0x67, 0xa4, 0xd1, 0xc1, 0xb1, 0xc0, 0x8e, 0x11,
+	0x0c, 0x02, 0xa1, 0x52, 0x0a, 0x2a, 0x44, 0xc4, 0x52, 0x5f, 0xb2, 0x3b, 0x9a, 0x9a, 0xe7, 0xe0,
+	0x46, 0x09, 0x8e, 0x15, 0x86, 0x5e, 0x03, 0x0a, 0x88, 0x8c, 0x26, 0xd4, 0x97, 0x3c, 0x48, 0x85,
+	0x8a, 0x59, 0xea, 0xad, 0x68, 0xe5, 0xa6, 0x61, 0xc6, 0x0f, 0xc4, 0xde, 0x6f, 0x95, 0x45, 0xcd,
+	0x3d, 0xf4, 0x0a, 0xd6, 0x4b, 0x2f, 0x61, 0xa9, 0xa4, 0xf7, 0xb2, 0xbc, 0xc1, 0x35, 0xe8, 0xc0,
+	0x80, 0xe8, 0x08, 0xec, 0x92, 0x57, 0xaf, 0xaa, 0x1e, 0xac, 0xf5, 0x5a, 0x4f, 0xfb, 0xd1, 0x29,
+	0xb5, 0xe2, 0x34, 0x95, 0x7c, 0x8a, 0x17, 0x7a, 0xe4, 0x41, 0x3d, 0xa1, 0x92, 0x47, 0xc4, 0x3c,
+	0xd7, 0xc6, 0xf3, 0x23, 0xea, 0xc1, 0x76, 0x12, 0xdc, 0xfb, 0xe2, 0x96, 0xc6, 0xb1, 0x4f, 0x58,
+	0x92, 0xc5, 0x54, 0x55, 0x28, 0x74, 0xed, 0x55, 0xbc, 0x95, 0x04, 0xf7, 0x23, 0xc5, 0x0d, 0x1e,
+	0xa8, 0x9d, 0x33, 0x70, 0x1f, 0x5d, 0x84, 0x9a, 0x50, 0xbd, 0xa3, 0xd3, 0xb2, 0x6c, 0x15, 0xa2,
+	0x57, 0xb0, 0x3a, 0x09, 0xe2, 0x9c, 0x96, 0xb3, 0xdd, 0x58, 0xaa, 0x54, 0xf9, 0xb0, 0x61, 0x8f,
+	0x2a, 0x5f, 0x5b, 0x7b, 0x7f, 0xaf, 0x42, 0x7d, 0xfe, 0xc6, 0xd7, 0x50, 0x13, 0x2c, 0xe7, 0x84,
+	0xea, 0x5c, 0xeb, 0xbd, 0xed, 0x27, 0xbe, 0x91, 0x26, 0x71, 0x29, 0xfa, 0x5f, 0xa6, 0xbd, 0xf2,
+	0x9f, 0xa7, 0xbd, 0xfa, 0x2f, 0xd3, 0x46, 0x9f, 0x40, 0x83, 0xc4, 0xb9, 0x90, 0x94, 0xfb, 0x69,
+	0x90, 0x50, 0xaf, 0xa6, 0x85, 0x6b, 0x25, 0x76, 0x1e, 0x24, 0x14, 0x7d, 0x04, 0x4e, 0x90, 0xcb,
+	0x5b, 0x3f, 0x4a, 0xaf, 0x99, 0x57, 0xd7, 0xbc, 0xad, 0x80, 0x61, 0x7a, 0xcd, 0xd0, 0xc7, 0xe0,
+	0x28, 0x9f, 0xc8, 0x02, 0x42, 0x3d, 0x5b, 0x93, 0x0f, 0x00, 0x3a, 0x83, 0x8d, 0x8c, 0x71, 0xe9,
+	0x5f, 0x33, 0xfe, 0x6b, 0xc0, 0x43, 0xca, 0x85, 0x07, 0x7a, 0x3d, 0xf6, 0x9f, 0x34, 0xaf, 0x73,
+	0xc9, 0xb8, 0x7c, 0xb3, 0x50, 0x99, 0x1d, 0x59, 0xcf, 0x1e, 0x81, 0xe8, 0x47, 0xd8, 0x9e, 0xd7,
+	0x1a, 0xd2, 0x2c, 0x66, 0xd3, 0x84, 0xa6, 0x52, 0xfd, 0x89, 0xd7, 0x74, 0xe3, 0x5e, 0x16, 0xb3,
+	0xf6, 0xd6, 0xc0, 0x08, 0x4e, 0x16, 0xfc, 0xf0, 0x04, 0x6f, 0x91, 0xf7, 0xc0, 0x10, 0x7d, 0x0a,
+	0x4e, 0xc6, 0xd9, 0xfd, 0xd4, 0xcf, 0x79, 0xec, 0x35, 0x74, 0x82, 0x46, 0x31, 0x6b, 0xdb, 0x97,
+	0x0a, 0xbc, 0xc2, 0x67, 0xd8, 0xd6, 0xf4, 0x15, 0x8f, 0xd1, 0x37, 0xb0, 0x21, 0x63, 0xe1, 0x97,
+	0x93, 0xd2, 0x6d, 0x72, 0xb5, 0x61, 0xb3, 0x98, 0xb5, 0xdd, 0xf1, 0xd9, 0xc8, 0x4c, 0x4b, 0x35,
+	0x0b, 0xbb, 0x32, 0x16, 0x0f, 0x47, 0xf4, 0x39, 0x6c, 0xde, 0x51, 0x9a, 0x05, 0xb1, 0x1a, 0x88,
+	0xa0, 0x84, 0xa5, 0xa1, 0xf0, 0xd6, 0xf5, 0xfa, 0x36, 0x17, 0xc4, 0xc8, 0xe0, 0xe8, 0x08, 0x3e,
+	0xe4, 0x2a, 0x4c, 0x29, 0x91, 0xbe, 0x8c, 0x12, 0xca, 0x72, 0xb9, 0x30, 0x6d, 0x68, 0xd3, 0xcb,
+	0x85, 0x60, 0x6c, 0xf8, 0xd2, 0xbb, 0x73, 0x0c, 0x5b, 0xcf, 0xb4, 0xf0, 0x99, 0xed, 0x7f, 0xb1,
+	0xbc, 0xfd, 0xee, 0xd2, 0xb2, 0xff, 0xb0, 0x62, 0x3b, 0x4d, 0xf8, 0xec, 0x5b, 0x70, 0x1f, 0x2d,
+	0x34, 0xb2, 0x61, 0xe5, 0xfc, 0xe2, 0xfc, 0xb4, 0xf9, 0x01, 0x72, 0xc1, 0x19, 0x5c, 0x9c, 0xbf,
+	0x19, 0x7e, 0xe7, 0xbf, 0x3d, 0x6c, 0x5a, 0xa8, 0x0e, 0xd5, 0xef, 0xaf, 0xfa, 0xcd, 0x0a, 0x6a,
+	0x80, 0x3d, 0xfc, 0xe9, 0xf2, 0x02, 0x8f, 0x4f, 0x4f, 0x9a, 0xd5, 0x7e, 0xff, 0x8f, 0xa2, 0x65,
+	0xfd, 0x59, 0xb4, 0xac, 0xbf, 0x8a, 0x96, 0xf5, 0xf3, 0x57, 0x37, 0x91, 0xbc, 0xcd, 0xdf, 0x75,
+	0x08, 0x4b, 0xba, 0x6a, 0xf5, 0xa7, 0x21, 0xe5, 0xcb, 0x91, 0xe0, 0xa4, 0xfb, 0xde, 0x57, 0xfd,
+	0x5d, 0x4d, 0x7f, 0xb1, 0xbf, 0xfc, 0x67, 0x00, 0xef, 0xd6, 0x92, 0x59, 0xf1, 0x05, 0x00, 0x00,
 }
 
 func (m *Config) Marshal() (dAtA []byte, err error) {
@@ -692,6 +741,30 @@ func (m *Context) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.ReconnectTimeoutSeconds != 0 {
+		i = encodeVarintConfig(dAtA, i, uint64(m.ReconnectTimeoutSeconds))
+		i--
+		dAtA[i] = 0x78
+	}
+	if m.KeepaliveSeconds != 0 {
+		i = encodeVarintConfig(dAtA, i, uint64(m.KeepaliveSeconds))
+		i--
+		dAtA[i] = 0x70
+	}
+	if len(m.TLSServerName) > 0 {
+		i -= len(m.TLSServerName)
+		copy(dAtA[i:], m.TLSServerName)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.TLSServerName)))
+		i--
+		dAtA[i] = 0x6a
+	}
+	if len(m.ProxyURL) > 0 {
+		i -= len(m.ProxyURL)
+		copy(dAtA[i:], m.ProxyURL)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.ProxyURL)))
+		i--
+		dAtA[i] = 0x62
+	}
 	if len(m.ClusterDeploymentID) > 0 {
 		i -= len(m.ClusterDeploymentID)
 		copy(dAtA[i:], m.ClusterDeploymentID)
@@ -920,6 +993,20 @@ func (m *Context) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovConfig(uint64(l))
 	}
+	l = len(m.ProxyURL)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	l = len(m.TLSServerName)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
+	if m.KeepaliveSeconds != 0 {
+		n += 1 + sovConfig(uint64(m.KeepaliveSeconds))
+	}
+	if m.ReconnectTimeoutSeconds != 0 {
+		n += 1 + sovConfig(uint64(m.ReconnectTimeoutSeconds))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1943,6 +2030,108 @@ func (m *Context) Unmarshal(dAtA []byte) error {
 			}
 			m.ClusterDeploymentID = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProxyURL", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProxyURL = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TLSServerName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TLSServerName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 14:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field KeepaliveSeconds", wireType)
+			}
+			m.KeepaliveSeconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.KeepaliveSeconds |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 15:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReconnectTimeoutSeconds", wireType)
+			}
+			m.ReconnectTimeoutSeconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ReconnectTimeoutSeconds |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipConfig(dAtA[iNdEx:])
@@ -1,7 +1,9 @@
 package discovery
 
 import (
+	"crypto/tls"
 	"fmt"
+	"net/http"
 )
 
 // ErrCancelled is returned when an action is cancelled by the user
@@ -38,3 +40,11 @@ type Client interface {
 func NewEtcdClient(addresses ...string) Client {
 	return newEtcdClient(addresses...)
 }
+
+// NewEtcdTLSClient creates an etcdClient with the given addresses, which
+// connects to them with 'tlsConfig'.
+func NewEtcdTLSClient(tlsConfig *tls.Config, addresses ...string) Client {
+	client := newEtcdClient(addresses...)
+	client.client.SetTransport(&http.Transport{TLSClientConfig: tlsConfig})
+	return client
+}
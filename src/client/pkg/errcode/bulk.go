@@ -0,0 +1,80 @@
+package errcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewItemResult returns the result of the item 'item' of a bulk request,
+// which failed with 'err' (or succeeded, if 'err' is nil)
+func NewItemResult(item string, err error) *ItemResult {
+	result := &ItemResult{Item: item}
+	if err == nil {
+		return result
+	}
+	e := FromError(err)
+	result.Error = e.Message
+	result.Info = &ErrorInfo{
+		Code:         int32(e.Code),
+		Reason:       e.Reason,
+		ResourceType: e.ResourceType,
+		ResourceName: e.ResourceName,
+	}
+	return result
+}
+
+// Err returns the error that the item failed with, or nil if it succeeded
+func (r *ItemResult) Err() error {
+	if r.Error == "" {
+		return nil
+	}
+	e := New(Unknown, r.Error)
+	if r.Info != nil {
+		e.Code = Code(r.Info.Code)
+		e.Reason = r.Info.Reason
+		e.ResourceType = r.Info.ResourceType
+		e.ResourceName = r.Info.ResourceName
+	}
+	return e
+}
+
+// Failed returns the results of the items that failed
+func (r *BulkResponse) Failed() []*ItemResult {
+	var failed []*ItemResult
+	for _, result := range r.Results {
+		if result.Error != "" {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Err returns a *BulkError if any of the items in 'r' failed, and nil
+// otherwise
+func (r *BulkResponse) Err() error {
+	if r == nil {
+		return nil
+	}
+	if failed := r.Failed(); len(failed) > 0 {
+		return &BulkError{Failed: failed, Total: len(r.Results)}
+	}
+	return nil
+}
+
+// BulkError is returned by clients when some of the items of a best-effort
+// bulk request failed (the rest succeeded)
+type BulkError struct {
+	// Failed are the results of the items that failed
+	Failed []*ItemResult
+	// Total is the number of items in the request
+	Total int
+}
+
+func (e *BulkError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d items failed:", len(e.Failed), e.Total)
+	for _, result := range e.Failed {
+		fmt.Fprintf(&b, "\n  %s: %s", result.Item, result.Error)
+	}
+	return b.String()
+}
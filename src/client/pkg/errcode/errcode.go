@@ -0,0 +1,245 @@
+// Package errcode classifies the errors returned by Pachyderm's APIs, so that
+// callers can check what kind of error they got without matching its message.
+//
+// Errors are sent to clients as gRPC statuses: the Code determines the
+// status's gRPC code, and an ErrorInfo with the rest of the error is attached
+// to the status's details.
+package errcode
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Code is the kind of an error
+type Code int
+
+const (
+	// Unknown is the code of errors that aren't one of the kinds below
+	Unknown Code = iota
+	// NotFound means that the resource an operation is about doesn't exist
+	NotFound
+	// Conflict means that an operation conflicts with the state of a
+	// resource (e.g. the resource already exists, or a commit is finished)
+	Conflict
+	// Unauthenticated means that the caller isn't signed in, or their
+	// credentials are invalid
+	Unauthenticated
+	// QuotaExceeded means that an operation would put a resource over its
+	// quota
+	QuotaExceeded
+	// Validation means that a request is malformed or invalid
+	Validation
+)
+
+var codeNames = map[Code]string{
+	Unknown:         "Unknown",
+	NotFound:        "NotFound",
+	Conflict:        "Conflict",
+	Unauthenticated: "Unauthenticated",
+	QuotaExceeded:   "QuotaExceeded",
+	Validation:      "Validation",
+}
+
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Code(%d)", int(c))
+}
+
+// grpcCode returns the gRPC code that 'c' is sent as
+func (c Code) grpcCode() codes.Code {
+	switch c {
+	case NotFound:
+		return codes.NotFound
+	case Conflict:
+		return codes.AlreadyExists
+	case Unauthenticated:
+		return codes.Unauthenticated
+	case QuotaExceeded:
+		return codes.ResourceExhausted
+	case Validation:
+		return codes.InvalidArgument
+	default:
+		return codes.Unknown
+	}
+}
+
+// fromGRPCCode returns the Code of errors sent with the gRPC code 'c'
+func fromGRPCCode(c codes.Code) Code {
+	switch c {
+	case codes.NotFound:
+		return NotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return Conflict
+	case codes.Unauthenticated:
+		return Unauthenticated
+	case codes.ResourceExhausted:
+		return QuotaExceeded
+	case codes.InvalidArgument:
+		return Validation
+	default:
+		return Unknown
+	}
+}
+
+// Error is an error with a Code, and optionally the resource that it's about.
+type Error struct {
+	Code Code
+	// Reason distinguishes errors with the same code and resource type
+	Reason string
+	// ResourceType is the type of the resource that the error is about (e.g.
+	// "commit"), and ResourceName identifies it
+	ResourceType string
+	ResourceName string
+	Message      string
+}
+
+// New returns an error with 'code' and 'message'
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Errorf returns an error with 'code' and a formatted message
+func Errorf(code Code, format string, a ...interface{}) *Error {
+	return New(code, fmt.Sprintf(format, a...))
+}
+
+// Wrap returns an error with 'code' and the message of 'err' (or nil if 'err'
+// is nil)
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return New(code, Message(err))
+}
+
+// WithResource sets the resource that 'e' is about, and returns 'e'
+func (e *Error) WithResource(resourceType, resourceName string) *Error {
+	e.ResourceType = resourceType
+	e.ResourceName = resourceName
+	return e
+}
+
+// WithReason sets the reason for 'e', and returns 'e'
+func (e *Error) WithReason(reason string) *Error {
+	e.Reason = reason
+	return e
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// GRPCStatus returns the status that 'e' is sent to clients as. gRPC servers
+// use it in place of the error's message.
+func (e *Error) GRPCStatus() *status.Status {
+	s := status.New(e.Code.grpcCode(), e.Message)
+	withDetails, err := s.WithDetails(&ErrorInfo{
+		Code:         int32(e.Code),
+		Reason:       e.Reason,
+		ResourceType: e.ResourceType,
+		ResourceName: e.ResourceName,
+	})
+	if err != nil {
+		return s
+	}
+	return withDetails
+}
+
+// statusError is implemented by errors that carry a gRPC status (both errors
+// returned by gRPC clients and the errors in this package)
+type statusError interface {
+	GRPCStatus() *status.Status
+}
+
+// FromError returns the Error in (or sent as) 'err'. It returns nil if 'err'
+// is nil, and an Error with code Unknown if 'err' doesn't have a code. Errors
+// from gRPC servers that don't attach an ErrorInfo (e.g. older versions of
+// pachd) get the Code that corresponds to their gRPC code.
+func FromError(err error) *Error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *Error:
+		return e
+	case statusError:
+		s := e.GRPCStatus()
+		result := New(fromGRPCCode(s.Code()), s.Message())
+		for _, d := range s.Details() {
+			if info, ok := d.(*ErrorInfo); ok {
+				result.Code = Code(info.Code)
+				result.Reason = info.Reason
+				result.ResourceType = info.ResourceType
+				result.ResourceName = info.ResourceName
+			}
+		}
+		return result
+	default:
+		return New(Unknown, err.Error())
+	}
+}
+
+// Message returns the message of 'err' without the code that gRPC adds to
+// the messages of the errors it returns
+func Message(err error) string {
+	if e, ok := err.(statusError); ok {
+		return e.GRPCStatus().Message()
+	}
+	return err.Error()
+}
+
+// Of returns the Code of 'err' (Unknown if 'err' is nil or has no code)
+func Of(err error) Code {
+	if err == nil {
+		return Unknown
+	}
+	return FromError(err).Code
+}
+
+// Is returns true if 'err' has 'code' and, if 'resourceType' is set, is about
+// a resource of that type
+func Is(err error, code Code, resourceType string) bool {
+	if err == nil {
+		return false
+	}
+	e := FromError(err)
+	return e.Code == code && (resourceType == "" || e.ResourceType == resourceType)
+}
+
+// HasReason returns true if 'err' has 'code' and 'reason'
+func HasReason(err error, code Code, reason string) bool {
+	if err == nil {
+		return false
+	}
+	e := FromError(err)
+	return e.Code == code && e.Reason == reason
+}
+
+// IsNotFound returns true if 'err' has the code NotFound
+func IsNotFound(err error) bool {
+	return Is(err, NotFound, "")
+}
+
+// IsConflict returns true if 'err' has the code Conflict
+func IsConflict(err error) bool {
+	return Is(err, Conflict, "")
+}
+
+// IsUnauthenticated returns true if 'err' has the code Unauthenticated
+func IsUnauthenticated(err error) bool {
+	return Is(err, Unauthenticated, "")
+}
+
+// IsQuotaExceeded returns true if 'err' has the code QuotaExceeded
+func IsQuotaExceeded(err error) bool {
+	return Is(err, QuotaExceeded, "")
+}
+
+// IsValidation returns true if 'err' has the code Validation
+func IsValidation(err error) bool {
+	return Is(err, Validation, "")
+}
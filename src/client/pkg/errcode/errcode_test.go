@@ -0,0 +1,62 @@
+package errcode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sent returns 'err' as it's returned to clients by gRPC
+func sent(err error) error {
+	return status.ErrorProto(status.Convert(err).Proto())
+}
+
+func TestFromError(t *testing.T) {
+	require.Nil(t, FromError(nil))
+	require.Equal(t, Unknown, Of(errors.New("plain error")))
+
+	err := sent(Errorf(Conflict, "branch %s is busy", "master").WithResource("branches", "master").WithReason("BUSY"))
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+	e := FromError(err)
+	require.Equal(t, Conflict, e.Code)
+	require.Equal(t, "BUSY", e.Reason)
+	require.Equal(t, "branches", e.ResourceType)
+	require.Equal(t, "master", e.ResourceName)
+	require.Equal(t, "branch master is busy", e.Message)
+	require.Equal(t, "branch master is busy", Message(err))
+	require.True(t, Is(err, Conflict, "branches"))
+	require.False(t, Is(err, Conflict, "repos"))
+
+	// Errors without an ErrorInfo get the code of their gRPC code
+	require.True(t, IsUnauthenticated(sent(status.Error(codes.Unauthenticated, "not signed in"))))
+	require.True(t, IsNotFound(sent(status.Error(codes.NotFound, "not found"))))
+	require.Equal(t, Unknown, Of(sent(status.Error(codes.Unavailable, "unavailable"))))
+
+	require.True(t, IsValidation(Wrap(Validation, errors.New("invalid"))))
+	require.NoError(t, Wrap(Validation, nil))
+}
+
+func TestBulkResponse(t *testing.T) {
+	response := &BulkResponse{Results: []*ItemResult{
+		NewItemResult("/a", nil),
+		NewItemResult("/b", sent(Errorf(NotFound, "file /b not found").WithResource("files", "/b"))),
+		NewItemResult("/c", errors.New("disk full")),
+	}}
+	require.Equal(t, 2, len(response.Failed()))
+	require.NoError(t, response.Results[0].Err())
+	require.True(t, Is(response.Results[1].Err(), NotFound, "files"))
+	require.Equal(t, Unknown, Of(response.Results[2].Err()))
+
+	err := response.Err()
+	bulkErr, ok := err.(*BulkError)
+	require.True(t, ok)
+	require.Equal(t, 3, bulkErr.Total)
+	require.Equal(t, "2 of 3 items failed:\n  /b: file /b not found\n  /c: disk full", err.Error())
+
+	require.NoError(t, (&BulkResponse{Results: response.Results[:1]}).Err())
+	require.NoError(t, (*BulkResponse)(nil).Err())
+}
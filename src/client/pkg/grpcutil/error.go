@@ -1,19 +1,34 @@
 package grpcutil
 
 import (
-	"errors"
-
 	"google.golang.org/grpc/status"
 )
 
-// ScrubGRPC removes GRPC error code information from 'err' if it came from
-// GRPC (and returns it unchanged otherwise)
+// ScrubGRPC removes GRPC error code information from the message of 'err' if
+// it came from GRPC (and returns it unchanged otherwise). The returned error
+// still carries the error's status, so that its code and details can be read
+// (e.g. with the errcode package).
 func ScrubGRPC(err error) error {
 	if err == nil {
 		return nil
 	}
-	if s, ok := status.FromError(err); ok {
-		return errors.New(s.Message())
+	if s, ok := status.FromError(err); ok && err.Error() != s.Message() {
+		return &scrubbedError{s: s}
 	}
 	return err
 }
+
+// scrubbedError is an error returned by GRPC whose message doesn't include
+// its code
+type scrubbedError struct {
+	s *status.Status
+}
+
+func (e *scrubbedError) Error() string {
+	return e.s.Message()
+}
+
+// GRPCStatus returns the status of the error
+func (e *scrubbedError) GRPCStatus() *status.Status {
+	return e.s
+}
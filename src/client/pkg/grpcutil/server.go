@@ -22,6 +22,11 @@ import (
 type Server struct {
 	Server *grpc.Server
 	eg     *errgroup.Group
+
+	// unaryInterceptors and streamInterceptors are called (in order) on each RPC
+	// handled by Server, after tracing. They're set by Intercept.
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
 }
 
 // NewServer creates a new gRPC server, but does not start serving yet.
@@ -32,7 +37,11 @@ type Server struct {
 // corresponding private key in 'TLSVolumePath', this will serve GRPC traffic
 // over TLS. If either are missing this will serve GRPC traffic over
 // unencrypted HTTP,
-func NewServer(ctx context.Context, publicPortTLSAllowed bool) (*Server, error) {
+//
+// Any 'extraOpts' (e.g. the mutual TLS credentials of ports that only serve
+// other components of the cluster) are passed to the underlying gRPC server.
+func NewServer(ctx context.Context, publicPortTLSAllowed bool, extraOpts ...grpc.ServerOption) (*Server, error) {
+	s := &Server{}
 	opts := []grpc.ServerOption{
 		grpc.MaxConcurrentStreams(math.MaxUint32),
 		grpc.MaxRecvMsgSize(MaxMsgSize),
@@ -41,8 +50,8 @@ func NewServer(ctx context.Context, publicPortTLSAllowed bool) (*Server, error)
 			MinTime:             5 * time.Second,
 			PermitWithoutStream: true,
 		}),
-		grpc.UnaryInterceptor(tracing.UnaryServerInterceptor()),
-		grpc.StreamInterceptor(tracing.StreamServerInterceptor()),
+		grpc.UnaryInterceptor(s.unaryInterceptor(tracing.UnaryServerInterceptor())),
+		grpc.StreamInterceptor(s.streamInterceptor(tracing.StreamServerInterceptor())),
 	}
 
 	if publicPortTLSAllowed {
@@ -60,19 +69,57 @@ func NewServer(ctx context.Context, publicPortTLSAllowed bool) (*Server, error)
 		}
 	}
 
-	server := grpc.NewServer(opts...)
-	eg, ctx := errgroup.WithContext(ctx)
+	opts = append(opts, extraOpts...)
+	s.Server = grpc.NewServer(opts...)
+	var eg *errgroup.Group
+	eg, ctx = errgroup.WithContext(ctx)
+	s.eg = eg
 
 	eg.Go(func() error {
 		<-ctx.Done()
-		server.GracefulStop() // This also closes the listeners
+		s.Server.GracefulStop() // This also closes the listeners
 		return nil
 	})
 
-	return &Server{
-		Server: server,
-		eg:     eg,
-	}, nil
+	return s, nil
+}
+
+// Intercept adds 'unary' and 'stream' (either of which may be nil) to the
+// interceptors that are called on each RPC handled by 's'. Intercept must be
+// called before 's' starts listening, as interceptors aren't synchronized.
+func (s *Server) Intercept(unary grpc.UnaryServerInterceptor, stream grpc.StreamServerInterceptor) {
+	if unary != nil {
+		s.unaryInterceptors = append(s.unaryInterceptors, unary)
+	}
+	if stream != nil {
+		s.streamInterceptors = append(s.streamInterceptors, stream)
+	}
+}
+
+// unaryInterceptor chains 'first' with the interceptors added by Intercept
+func (s *Server) unaryInterceptor(first grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		for i := len(s.unaryInterceptors) - 1; i >= 0; i-- {
+			interceptor, next := s.unaryInterceptors[i], handler
+			handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+		return first(ctx, req, info, handler)
+	}
+}
+
+// streamInterceptor chains 'first' with the interceptors added by Intercept
+func (s *Server) streamInterceptor(first grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		for i := len(s.streamInterceptors) - 1; i >= 0; i-- {
+			interceptor, next := s.streamInterceptors[i], handler
+			handler = func(srv interface{}, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, next)
+			}
+		}
+		return first(srv, ss, info, handler)
+	}
 }
 
 // ListenTCP causes the gRPC server to listen on a given TCP host and port
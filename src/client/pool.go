@@ -0,0 +1,158 @@
+package client
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/resolver"
+
+	// Registers grpc's client-side health checking, which the pool's service
+	// config enables
+	_ "google.golang.org/grpc/health"
+)
+
+const (
+	// poolScheme is the scheme of the grpc targets that APIClients created
+	// with WithConnectionPool dial. Targets have the form
+	// "pachd-pool://<size>/<host>:<port>".
+	poolScheme = "pachd-pool"
+
+	// poolResolveInterval is how often a pool re-resolves pachd's address, to
+	// find new pachd replicas
+	poolResolveInterval = 30 * time.Second
+
+	// poolServiceConfig spreads RPCs across a pool's connections, and stops
+	// sending RPCs to connections whose pachd fails its health check (these
+	// are reconnected by grpc in the background). pachds that don't
+	// implement grpc's health service are treated as healthy.
+	poolServiceConfig = `{
+		"loadBalancingPolicy": "round_robin",
+		"healthCheckConfig": {"serviceName": ""}
+	}`
+)
+
+func init() {
+	resolver.Register(&poolResolverBuilder{})
+}
+
+// WithConnectionPool instructs the New* functions to create a client that
+// opens 'size' connections to each pachd replica that its address resolves to
+// (rather than a single connection), and spreads RPCs across them. This lets
+// many goroutines share one client without their streams queueing behind one
+// another on a single connection. Connections to unhealthy pachds are taken
+// out of rotation until they recover.
+func WithConnectionPool(size int) Option {
+	return func(settings *clientSettings) error {
+		if size < 1 {
+			return fmt.Errorf("connection pool size must be at least 1, but was %d", size)
+		}
+		settings.poolSize = size
+		return nil
+	}
+}
+
+// poolTarget returns the grpc target of a pool of 'size' connections to each
+// replica at 'addr'
+func poolTarget(addr string, size int) string {
+	return fmt.Sprintf("%s://%d/%s", poolScheme, size, addr)
+}
+
+type poolResolverBuilder struct{}
+
+func (*poolResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOption) (resolver.Resolver, error) {
+	size, err := strconv.Atoi(target.Authority)
+	if err != nil || size < 1 {
+		return nil, fmt.Errorf("invalid connection pool size %q", target.Authority)
+	}
+	host, port, err := net.SplitHostPort(target.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pachd address %q: %v", target.Endpoint, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &poolResolver{
+		host:       host,
+		port:       port,
+		size:       size,
+		cc:         cc,
+		lookupHost: net.DefaultResolver.LookupHost,
+		ctx:        ctx,
+		cancel:     cancel,
+		resolveNow: make(chan struct{}, 1),
+	}
+	go r.watch()
+	return r, nil
+}
+
+func (*poolResolverBuilder) Scheme() string {
+	return poolScheme
+}
+
+// poolResolver resolves a pool's target to 'size' addresses for each IP that
+// its host resolves to (distinguished by their metadata, so that the balancer
+// opens a connection for each)
+type poolResolver struct {
+	host, port string
+	size       int
+	cc         resolver.ClientConn
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+
+	ctx        context.Context
+	cancel     context.CancelFunc
+	resolveNow chan struct{}
+}
+
+// watch resolves r's host until r is closed
+func (r *poolResolver) watch() {
+	ticker := time.NewTicker(poolResolveInterval)
+	defer ticker.Stop()
+	for {
+		if addrs, err := r.resolve(); err == nil {
+			r.cc.UpdateState(resolver.State{Addresses: addrs})
+		}
+		// If resolution fails, keep the previous addresses (grpc will ask
+		// for a new resolution if they stop working)
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.resolveNow:
+		}
+	}
+}
+
+// resolve returns the addresses of the connections in r's pool
+func (r *poolResolver) resolve() ([]resolver.Address, error) {
+	ips := []string{r.host}
+	if net.ParseIP(r.host) == nil {
+		var err error
+		if ips, err = r.lookupHost(r.ctx, r.host); err != nil {
+			return nil, err
+		}
+	}
+	var addrs []resolver.Address
+	for _, ip := range ips {
+		for i := 0; i < r.size; i++ {
+			addrs = append(addrs, resolver.Address{
+				Addr: net.JoinHostPort(ip, r.port),
+				// Verify pachd's TLS cert against its hostname, not its IP
+				ServerName: r.host,
+				Metadata:   i,
+			})
+		}
+	}
+	return addrs, nil
+}
+
+func (r *poolResolver) ResolveNow(resolver.ResolveNowOption) {
+	select {
+	case r.resolveNow <- struct{}{}:
+	default:
+	}
+}
+
+func (r *poolResolver) Close() {
+	r.cancel()
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/pachyderm/pachyderm/src/client/health"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	healthserver "github.com/pachyderm/pachyderm/src/server/health"
+)
+
+func TestPoolResolve(t *testing.T) {
+	r := &poolResolver{
+		host: "pachd",
+		port: "650",
+		size: 2,
+		lookupHost: func(ctx context.Context, host string) ([]string, error) {
+			return []string{"10.0.0.1", "10.0.0.2"}, nil
+		},
+		ctx: context.Background(),
+	}
+	addrs, err := r.resolve()
+	require.NoError(t, err)
+	require.Equal(t, 4, len(addrs))
+	seen := make(map[interface{}]bool)
+	for _, addr := range addrs {
+		require.Equal(t, "pachd", addr.ServerName)
+		seen[addr] = true
+	}
+	require.Equal(t, 4, len(seen)) // every address gets its own connection
+	require.Equal(t, "10.0.0.2:650", addrs[3].Addr)
+
+	// IPs aren't looked up
+	r.host = "127.0.0.1"
+	addrs, err = r.resolve()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(addrs))
+	require.Equal(t, "127.0.0.1:650", addrs[0].Addr)
+}
+
+// countingListener counts the connections that it accepts
+type countingListener struct {
+	net.Listener
+	accepted int64
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		atomic.AddInt64(&l.accepted, 1)
+	}
+	return conn, err
+}
+
+func TestConnectionPool(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	counter := &countingListener{Listener: lis}
+	server := grpc.NewServer()
+	healthServer := healthserver.NewHealthServer()
+	health.RegisterHealthServer(server, healthServer)
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+	healthServer.Ready()
+	go server.Serve(counter)
+	defer server.Stop()
+
+	c, err := NewFromAddress(lis.Addr().String(), WithConnectionPool(3), WithDialTimeout(10*time.Second))
+	require.NoError(t, err)
+	defer c.Close()
+	require.NoError(t, c.Health())
+	require.NoError(t, c.WithAuthToken("token").Health())
+	require.NoErrorWithinTRetry(t, 10*time.Second, func() error {
+		if n := atomic.LoadInt64(&counter.accepted); n != 3 {
+			return fmt.Errorf("expected 3 connections, but pachd accepted %d", n)
+		}
+		return nil
+	})
+}
@@ -3,12 +3,15 @@ package client
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"io"
 	"time"
 
 	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errcode"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
 	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/client/version"
 	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
 
 	"github.com/gogo/protobuf/types"
@@ -40,6 +43,9 @@ const (
 	// PPSInputPrefix is the prefix of the path where datums are downloaded
 	// to.  A datum of an input named `XXX` is downloaded to `/pfs/XXX/`.
 	PPSInputPrefix = "/pfs"
+	// PPSWindowsInputPrefix is the equivalent of PPSInputPrefix for pipelines
+	// that run on Windows nodes.
+	PPSWindowsInputPrefix = `C:\pfs`
 	// PPSScratchSpace is where pps workers store data while it's waiting to be
 	// processed.
 	PPSScratchSpace = ".scratch"
@@ -64,8 +70,85 @@ const (
 	// OutputCommitIDEnv is an env var that is added to the environment of user
 	// pipelined code and indicates the id of the output commit.
 	OutputCommitIDEnv = "PACH_OUTPUT_COMMIT_ID"
+	// DatumFailureFileEnv is an env var that is added to the environment of
+	// user pipelined code and names a file to which it can write "permanent"
+	// or "transient" to say whether a failed datum should be retried.
+	DatumFailureFileEnv = "PACH_DATUM_FAILURE_FILE"
+	// PPSS3EndpointEnv is an env var that is added to the environment of the
+	// user code of pipelines with S3 inputs or s3_out, and holds the host and
+	// port of the job's S3 gateway (see PPSJobS3GatewayPort).
+	PPSS3EndpointEnv = "S3_ENDPOINT"
+	// PPSS3UseHTTPSEnv is set to "0" alongside PPSS3EndpointEnv, as the job's
+	// S3 gateway serves plain HTTP.
+	PPSS3UseHTTPSEnv = "S3_USE_HTTPS"
+	// PPSJobS3GatewayPort is the port on which a worker serves the S3 gateway
+	// of the job that it's processing, on localhost.
+	PPSJobS3GatewayPort = 1600
+	// PipelineCRDGroup is the API group of the Pipeline custom resource. If
+	// it's enabled, pachd creates, updates and deletes a pipeline for each
+	// Pipeline resource in its namespace.
+	PipelineCRDGroup = "pachyderm.io"
+	// PipelineCRDVersion is the API version of the Pipeline custom resource
+	PipelineCRDVersion = "v1"
+	// PipelineCRDKind is the kind of the Pipeline custom resource
+	PipelineCRDKind = "Pipeline"
+	// PipelineCRDPlural is the plural (resource) name of the Pipeline custom
+	// resource
+	PipelineCRDPlural = "pipelines"
 	// PeerPortEnv is the env var that sets a custom peer port
 	PeerPortEnv = "PEER_PORT"
+	// RPCCacheSizeEnv is the env var that sets the size in bytes of a
+	// worker's cache of pachd responses that can't change (see the rpccache
+	// package). Workers don't cache responses if it's unset.
+	RPCCacheSizeEnv = "RPC_CACHE_SIZE"
+	// PPSWorkerImageEnv is the env var that tells workers which image they
+	// were started from (the image that the init container copies the worker
+	// binary out of).
+	PPSWorkerImageEnv = "PPS_WORKER_IMAGE"
+	// PPSExternalChunkEnv is set in the jobs that an external execution
+	// backend runs, and holds the object hash of the chunk of datums that the
+	// job processes. If it's set, the worker binary processes the chunk and
+	// exits, instead of running as a worker.
+	PPSExternalChunkEnv = "PPS_EXTERNAL_CHUNK"
+	// PPSBackendMountPath is where the credentials for a pipeline's execution
+	// backend are mounted in its workers.
+	PPSBackendMountPath = "/pach-backend"
+	// PPSEgressSecretEnv holds the password (or credentials) of the database
+	// that a pipeline's SQL egress loads its output into, read from the
+	// egress's secret.
+	PPSEgressSecretEnv = "PPS_EGRESS_SECRET"
+	// PPSTrackingSecretEnv holds the token that a pipeline's workers publish
+	// its jobs to its experiment tracker with, read from the tracking's secret.
+	PPSTrackingSecretEnv = "PPS_TRACKING_SECRET"
+	// PPSDatumProfileEnv is set in the workers of a pipeline's datum profiles
+	// (see pps.DatumProfile), and holds the name of the workers' profile.
+	// They only process the datums in that profile.
+	PPSDatumProfileEnv = "PPS_DATUM_PROFILE"
+	// PPSReplayJobEnv is set in the workers that replay a job (see
+	// pps.ReplayJob), and holds the ID of the job being replayed. They only
+	// process its replay.
+	PPSReplayJobEnv = "PPS_REPLAY_JOB"
+	// PPSWorkerPoolEnv is set in the workers of the extra worker pools of a
+	// pipeline with max_concurrent_jobs, and holds the number of the workers'
+	// pool (see pps.EtcdJobInfo.worker_pool). They only process that pool's
+	// jobs.
+	PPSWorkerPoolEnv = "PPS_WORKER_POOL"
+	// PPSSecretsMountPath is where the secrets that a pipeline exposes to its
+	// user code as env vars are mounted in its workers, at <secret>/<key>.
+	// Workers read the env vars from here before running the user code, so
+	// that they pick up rotated secrets.
+	PPSSecretsMountPath = "/pach-secrets"
+	// PPSConfigMapsMountPath is where the config map keys that a pipeline
+	// exposes to its user code (see pps.ConfigMapKey) are mounted in its
+	// workers, at <config map>/<key>. Like secrets, workers read them from
+	// here before running the user code, so that they pick up changes.
+	PPSConfigMapsMountPath = "/pach-config-maps"
+	// PPSCloudTokenMountPath is where the service account token that a
+	// pipeline's user code exchanges for AWS credentials (see
+	// pps.AWSCredentials) is mounted in its workers.
+	PPSCloudTokenMountPath = "/pach-cloud-token"
+	// PPSAWSTokenFile is the name of the token in PPSCloudTokenMountPath
+	PPSAWSTokenFile = "aws"
 )
 
 // NewJob creates a pps.Job.
@@ -126,6 +209,15 @@ func NewJoinInput(input ...*pps.Input) *pps.Input {
 	}
 }
 
+// NewGroupInput returns an input which groups the datums of other inputs.
+// That means that all datums which match on `groupBy` will be seen by the job /
+// pipeline as a single datum.
+func NewGroupInput(input ...*pps.Input) *pps.Input {
+	return &pps.Input{
+		Group: input,
+	}
+}
+
 // NewUnionInput returns an input which is the union of other inputs. That
 // means that all datums from any of the inputs will be seen individually by
 // the job / pipeline.
@@ -203,6 +295,29 @@ func (c APIClient) InspectJob(jobID string, blockState bool) (*pps.JobInfo, erro
 	return jobInfo, grpcutil.ScrubGRPC(err)
 }
 
+// JobProgress calls f with the progress of the job 'jobID' each time it
+// changes (and periodically in between), until the job finishes.
+func (c APIClient) JobProgress(jobID string, f func(*pps.JobProgress) error) error {
+	client, err := c.PpsAPIClient.JobProgress(c.Ctx(), &pps.JobProgressRequest{
+		Job: NewJob(jobID),
+	})
+	if err != nil {
+		return c.scrubFeatureGRPC(version.FeatureJobProgress, err)
+	}
+	for {
+		progress, err := client.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return grpcutil.ScrubGRPC(err)
+		}
+		if err := f(progress); err != nil {
+			return err
+		}
+	}
+}
+
 // InspectJobOutputCommit returns info about a job that created a commit.
 // blockState will cause the call to block until the job reaches a terminal state (failure or success).
 func (c APIClient) InspectJobOutputCommit(repoName, commitID string, blockState bool) (*pps.JobInfo, error) {
@@ -263,15 +378,19 @@ func (c APIClient) ListJobF(pipelineName string, inputCommit []*pfs.Commit,
 	if pipelineName != "" {
 		pipeline = NewPipeline(pipelineName)
 	}
-	client, err := c.PpsAPIClient.ListJobStream(
-		c.Ctx(),
-		&pps.ListJobRequest{
-			Pipeline:     pipeline,
-			InputCommit:  inputCommit,
-			OutputCommit: outputCommit,
-			History:      history,
-			Full:         includePipelineInfo,
-		})
+	return c.ListJobWithRequest(&pps.ListJobRequest{
+		Pipeline:     pipeline,
+		InputCommit:  inputCommit,
+		OutputCommit: outputCommit,
+		History:      history,
+		Full:         includePipelineInfo,
+	}, f)
+}
+
+// ListJobWithRequest is like ListJobF, but takes a ListJobRequest, which
+// allows setting fields (such as LabelSelector) that ListJobF doesn't expose.
+func (c APIClient) ListJobWithRequest(request *pps.ListJobRequest, f func(*pps.JobInfo) error) error {
+	client, err := c.PpsAPIClient.ListJobStream(c.Ctx(), request)
 	if err != nil {
 		return grpcutil.ScrubGRPC(err)
 	}
@@ -291,6 +410,59 @@ func (c APIClient) ListJobF(pipelineName string, inputCommit []*pfs.Commit,
 	}
 }
 
+// ListJobPages is like ListJobWithRequest, but reads jobs with ListJob, one
+// page of request.PageSize jobs at a time, so that no single RPC has to read
+// every job on clusters with many of them. request.PageToken is overwritten.
+func (c APIClient) ListJobPages(request *pps.ListJobRequest, f func(*pps.JobInfo) error) error {
+	if request.PageSize <= 0 {
+		return fmt.Errorf("page size must be positive")
+	}
+	request.PageToken = ""
+	for {
+		jobInfos, err := c.PpsAPIClient.ListJob(c.Ctx(), request)
+		if err != nil {
+			return grpcutil.ScrubGRPC(err)
+		}
+		for _, ji := range jobInfos.JobInfo {
+			if err := f(ji); err != nil {
+				if err == errutil.ErrBreak {
+					return nil
+				}
+				return err
+			}
+		}
+		if jobInfos.NextPageToken == "" {
+			return nil
+		}
+		request.PageToken = jobInfos.NextPageToken
+	}
+}
+
+// WatchJob calls f with every job that matches 'request', and then again with
+// each job whenever it changes. It returns when f returns an error
+// (errutil.ErrBreak causes WatchJob to return nil) or c's context is
+// cancelled. Filtering by input or output commit isn't supported.
+func (c APIClient) WatchJob(request *pps.ListJobRequest, f func(*pps.JobInfo) error) error {
+	client, err := c.PpsAPIClient.WatchJob(c.Ctx(), request)
+	if err != nil {
+		return c.scrubFeatureGRPC(version.FeatureWatch, err)
+	}
+	for {
+		ji, err := client.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return grpcutil.ScrubGRPC(err)
+		}
+		if err := f(ji); err != nil {
+			if err == errutil.ErrBreak {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
 // FlushJob calls f with all the jobs which were triggered by commits.
 // If toPipelines is non-nil then only the jobs between commits and those
 // pipelines in the DAG will be returned.
@@ -498,7 +670,6 @@ func (c APIClient) GetLogs(
 		Follow: follow,
 		Tail:   tail,
 	}
-	resp := &LogsIter{}
 	if pipelineName != "" {
 		request.Pipeline = NewPipeline(pipelineName)
 	}
@@ -512,7 +683,15 @@ func (c APIClient) GetLogs(
 			ID:  datumID,
 		}
 	}
-	resp.logsClient, resp.err = c.PpsAPIClient.GetLogs(c.Ctx(), &request)
+	return c.GetLogsWithRequest(&request)
+}
+
+// GetLogsWithRequest is like GetLogs, but takes a complete GetLogsRequest, so
+// that filters that GetLogs doesn't expose (e.g. 'since' and 'pattern') can
+// be set.
+func (c APIClient) GetLogsWithRequest(request *pps.GetLogsRequest) *LogsIter {
+	resp := &LogsIter{}
+	resp.logsClient, resp.err = c.PpsAPIClient.GetLogs(c.Ctx(), request)
 	resp.err = grpcutil.ScrubGRPC(resp.err)
 	return resp
 }
@@ -564,6 +743,43 @@ func (c APIClient) CreatePipeline(
 	return grpcutil.ScrubGRPC(err)
 }
 
+// CreatePipelines creates (or updates) several pipelines in one request, in
+// the order given. With errcode.BulkMode_ATOMIC, the first failure deletes
+// the pipelines that the request already created and is returned. With
+// errcode.BulkMode_BEST_EFFORT, every pipeline is attempted, and the response
+// reports which ones failed (its Err method returns them as a single error).
+func (c APIClient) CreatePipelines(mode errcode.BulkMode, requests ...*pps.CreatePipelineRequest) (*errcode.BulkResponse, error) {
+	response, err := c.PpsAPIClient.CreatePipelines(
+		c.Ctx(),
+		&pps.CreatePipelinesRequest{
+			Pipelines: requests,
+			Mode:      mode,
+		},
+	)
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureBulkResults, err)
+	}
+	return response, nil
+}
+
+// InstantiateTemplate renders the pipeline template at repo@commit:path once
+// for each set of parameters, and creates the resulting pipelines. It returns
+// the pipelines that were created.
+func (c APIClient) InstantiateTemplate(repo string, commit string, path string, parameters []map[string]string, update bool) ([]*pps.Pipeline, error) {
+	request := &pps.InstantiateTemplateRequest{
+		Template: NewFile(repo, commit, path),
+		Update:   update,
+	}
+	for _, values := range parameters {
+		request.Parameters = append(request.Parameters, &pps.TemplateParameters{Values: values})
+	}
+	response, err := c.PpsAPIClient.InstantiateTemplate(c.Ctx(), request)
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureTemplates, err)
+	}
+	return response.Pipelines, nil
+}
+
 // InspectPipeline returns info about a specific pipeline.
 func (c APIClient) InspectPipeline(pipelineName string) (*pps.PipelineInfo, error) {
 	pipelineInfo, err := c.PpsAPIClient.InspectPipeline(
@@ -575,6 +791,20 @@ func (c APIClient) InspectPipeline(pipelineName string) (*pps.PipelineInfo, erro
 	return pipelineInfo, grpcutil.ScrubGRPC(err)
 }
 
+// InspectPipelineFull returns info about a specific pipeline, including its
+// raw etcd state and with defaults filled in for any fields that were added
+// after the pipeline was created.
+func (c APIClient) InspectPipelineFull(pipelineName string) (*pps.PipelineInfo, error) {
+	pipelineInfo, err := c.PpsAPIClient.InspectPipeline(
+		c.Ctx(),
+		&pps.InspectPipelineRequest{
+			Pipeline: NewPipeline(pipelineName),
+			Full:     true,
+		},
+	)
+	return pipelineInfo, grpcutil.ScrubGRPC(err)
+}
+
 // ListPipeline returns info about all pipelines.
 func (c APIClient) ListPipeline() ([]*pps.PipelineInfo, error) {
 	pipelineInfos, err := c.PpsAPIClient.ListPipeline(
@@ -601,19 +831,48 @@ func (c APIClient) ListPipelineHistory(pipeline string, history int64) ([]*pps.P
 	if pipeline != "" {
 		_pipeline = NewPipeline(pipeline)
 	}
-	pipelineInfos, err := c.PpsAPIClient.ListPipeline(
-		c.Ctx(),
-		&pps.ListPipelineRequest{
-			Pipeline: _pipeline,
-			History:  history,
-		},
-	)
+	return c.ListPipelineWithRequest(&pps.ListPipelineRequest{
+		Pipeline: _pipeline,
+		History:  history,
+	})
+}
+
+// ListPipelineWithRequest is like ListPipelineHistory, but takes a
+// ListPipelineRequest, which allows setting fields (such as LabelSelector)
+// that ListPipelineHistory doesn't expose.
+func (c APIClient) ListPipelineWithRequest(request *pps.ListPipelineRequest) ([]*pps.PipelineInfo, error) {
+	pipelineInfos, err := c.PpsAPIClient.ListPipeline(c.Ctx(), request)
 	if err != nil {
 		return nil, grpcutil.ScrubGRPC(err)
 	}
 	return pipelineInfos.PipelineInfo, nil
 }
 
+// WatchPipeline calls f with every pipeline that matches 'request', and then
+// again with each pipeline whenever it changes. It returns when f returns an
+// error (errutil.ErrBreak causes WatchPipeline to return nil) or c's context
+// is cancelled. 'request.History' isn't supported.
+func (c APIClient) WatchPipeline(request *pps.ListPipelineRequest, f func(*pps.PipelineInfo) error) error {
+	client, err := c.PpsAPIClient.WatchPipeline(c.Ctx(), request)
+	if err != nil {
+		return c.scrubFeatureGRPC(version.FeatureWatch, err)
+	}
+	for {
+		pi, err := client.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return grpcutil.ScrubGRPC(err)
+		}
+		if err := f(pi); err != nil {
+			if err == errutil.ErrBreak {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
 // DeletePipeline deletes a pipeline along with its output Repo.
 func (c APIClient) DeletePipeline(name string, force bool) error {
 	_, err := c.PpsAPIClient.DeletePipeline(
@@ -686,6 +945,22 @@ func (c APIClient) CreateSecret(file []byte) error {
 	return grpcutil.ScrubGRPC(err)
 }
 
+// RotateSecret replaces the data of the secret 'secret' (which must have been
+// created with CreateSecret) with the data of 'file', a kubernetes secret in
+// JSON. Pipelines' workers pick up the new data without being restarted.
+func (c APIClient) RotateSecret(secret string, file []byte) error {
+	_, err := c.PpsAPIClient.RotateSecret(
+		c.Ctx(),
+		&pps.RotateSecretRequest{
+			Secret: &pps.Secret{Name: secret},
+			File:   file,
+		})
+	if err != nil {
+		return c.scrubFeatureGRPC(version.FeatureSecretRotation, err)
+	}
+	return nil
+}
+
 // DeleteSecret deletes a secret from the cluster.
 func (c APIClient) DeleteSecret(secret string) error {
 	_, err := c.PpsAPIClient.DeleteSecret(
@@ -717,6 +992,24 @@ func (c APIClient) ListSecret() ([]*pps.SecretInfo, error) {
 	return secretInfos.SecretInfo, grpcutil.ScrubGRPC(err)
 }
 
+// ListNames returns the names of repos, branches, pipelines or jobs (newest
+// first), depending on 'kind'. 'repo' is only used to list branch names. If
+// 'limit' is 0, all names are returned.
+func (c APIClient) ListNames(kind pps.ListNamesRequest_Kind, repo string, limit int64) ([]string, error) {
+	request := &pps.ListNamesRequest{
+		Kind:  kind,
+		Limit: limit,
+	}
+	if repo != "" {
+		request.Repo = NewRepo(repo)
+	}
+	response, err := c.PpsAPIClient.ListNames(c.Ctx(), request)
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return response.Names, nil
+}
+
 // CreatePipelineService creates a new pipeline service.
 func (c APIClient) CreatePipelineService(
 	name string,
@@ -752,9 +1045,11 @@ func (c APIClient) CreatePipelineService(
 	return grpcutil.ScrubGRPC(err)
 }
 
-// GarbageCollect garbage collects unused data.  Currently GC needs to be run
-// while no data is being added or removed (which, among other things, implies
-// that there shouldn't be jobs actively running).  Pfs Garbage collection uses
+// GarbageCollect garbage collects unused data. GC runs concurrently with
+// writes, and spares unreferenced objects that were written in the last hour,
+// as they may be about to be referenced (see GarbageCollectWithGracePeriod).
+// It blocks until GC finishes, and InspectGarbageCollect reports its progress.
+// Pfs Garbage collection uses
 // bloom filters to keep track of live objects because it can store more
 // objects than can be indexed in memory. This means that there is a chance for
 // unreferenced objects to not be GCed, this chance increases as the number of
@@ -769,6 +1064,136 @@ func (c APIClient) GarbageCollect(memoryBytes int64) error {
 	return grpcutil.ScrubGRPC(err)
 }
 
+// GarbageCollectWithGracePeriod is like GarbageCollect, but only spares the
+// unreferenced objects that were written within 'gracePeriod', which must be at
+// most an hour. A grace period of 0 should only be used when nothing is being
+// written.
+func (c APIClient) GarbageCollectWithGracePeriod(memoryBytes int64, gracePeriod time.Duration) error {
+	if err := c.RequireFeatures(version.FeatureIncrementalGC); err != nil {
+		return err
+	}
+	_, err := c.PpsAPIClient.GarbageCollect(
+		c.Ctx(),
+		&pps.GarbageCollectRequest{
+			MemoryBytes: memoryBytes,
+			GracePeriod: types.DurationProto(gracePeriod),
+		},
+	)
+	return grpcutil.ScrubGRPC(err)
+}
+
+// InspectGarbageCollect returns the progress of the running garbage
+// collection, or the result of the last one
+func (c APIClient) InspectGarbageCollect() (*pps.GarbageCollectInfo, error) {
+	info, err := c.PpsAPIClient.InspectGarbageCollect(c.Ctx(), &pps.InspectGarbageCollectRequest{})
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureIncrementalGC, err)
+	}
+	return info, nil
+}
+
+// ListStuckBranches returns the branches whose heads have been unfinished for
+// at least 'threshold', along with the commits and jobs that are blocking
+// them. If 'threshold' is 0, pachd's default (one hour) is used.
+func (c APIClient) ListStuckBranches(threshold time.Duration) ([]*pps.StuckBranch, error) {
+	request := &pps.ListStuckBranchesRequest{}
+	if threshold != 0 {
+		request.Threshold = types.DurationProto(threshold)
+	}
+	response, err := c.PpsAPIClient.ListStuckBranches(c.Ctx(), request)
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureStuckBranches, err)
+	}
+	return response.Branches, nil
+}
+
+// Diagnose runs pachd's checks for common problems in the cluster, and
+// returns what they find (most severe first) along with suggested fixes
+func (c APIClient) Diagnose() (*pps.Diagnosis, error) {
+	response, err := c.PpsAPIClient.Diagnose(c.Ctx(), &pps.DiagnoseRequest{
+		ClientVersion: version.PrettyVersion(),
+	})
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureDiagnose, err)
+	}
+	return response, nil
+}
+
+// ReplayJob re-runs the successful job 'jobID' with the spec and input
+// commits that it ran with, puts the replay's output commit on 'branch' of the
+// job's output repo (or on "replay-<job ID>" if 'branch' is empty), and
+// returns how the replay's output differs from the job's. It blocks until the
+// replay finishes.
+func (c APIClient) ReplayJob(jobID string, branch string) (*pps.ReplayJobResponse, error) {
+	response, err := c.PpsAPIClient.ReplayJob(c.Ctx(), &pps.ReplayJobRequest{
+		Job:    NewJob(jobID),
+		Branch: branch,
+	})
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureReplayJob, err)
+	}
+	return response, nil
+}
+
+// InspectImpact returns everything downstream of the commit 'commitID' in
+// 'repoName': the commits with it in their provenance, the jobs that wrote
+// them, the branches that point at them and where the jobs egressed them to
+func (c APIClient) InspectImpact(repoName string, commitID string) (*pps.Impact, error) {
+	impact, err := c.PpsAPIClient.InspectImpact(c.Ctx(), &pps.InspectImpactRequest{
+		Commit: NewCommit(repoName, commitID),
+	})
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureImpact, err)
+	}
+	return impact, nil
+}
+
+// PutArtifact attaches the content of 'r' to the job 'jobID' as the artifact
+// 'name', replacing the job's artifact with that name if there is one. User
+// code can find the ID of the job that it's running in in JobIDEnv.
+func (c APIClient) PutArtifact(jobID string, name string, r io.Reader) (retErr error) {
+	putArtifactClient, err := c.PpsAPIClient.PutArtifact(c.Ctx())
+	if err != nil {
+		return c.scrubFeatureGRPC(version.FeatureArtifacts, err)
+	}
+	defer func() {
+		if _, err := putArtifactClient.CloseAndRecv(); err != nil && retErr == nil {
+			retErr = c.scrubFeatureGRPC(version.FeatureArtifacts, err)
+		}
+	}()
+	request := &pps.PutArtifactRequest{
+		Job:  NewJob(jobID),
+		Name: name,
+	}
+	// The first request is sent even if the artifact is empty, as it names
+	// the artifact
+	if err := putArtifactClient.Send(request); err != nil {
+		return grpcutil.ScrubGRPC(err)
+	}
+	if _, err := grpcutil.ChunkReader(r, func(data []byte) error {
+		return putArtifactClient.Send(&pps.PutArtifactRequest{Value: data})
+	}); err != nil {
+		return grpcutil.ScrubGRPC(err)
+	}
+	return nil
+}
+
+// GetArtifact writes the content of the artifact 'name' of the job 'jobID'
+// to 'writer'.
+func (c APIClient) GetArtifact(jobID string, name string, writer io.Writer) error {
+	getArtifactClient, err := c.PpsAPIClient.GetArtifact(c.Ctx(), &pps.GetArtifactRequest{
+		Job:  NewJob(jobID),
+		Name: name,
+	})
+	if err != nil {
+		return c.scrubFeatureGRPC(version.FeatureArtifacts, err)
+	}
+	if err := grpcutil.WriteFromStreamingBytesClient(getArtifactClient, writer); err != nil {
+		return c.scrubFeatureGRPC(version.FeatureArtifacts, err)
+	}
+	return nil
+}
+
 // GetDatumTotalTime sums the timing stats from a DatumInfo
 func GetDatumTotalTime(s *pps.ProcessStats) time.Duration {
 	totalDuration := time.Duration(0)
@@ -0,0 +1,474 @@
+// Code generated by protoc-gen-pachdoc. DO NOT EDIT.
+// source: client/pps/pps.proto
+
+package pps
+
+// Docs maps the fully-qualified names of the messages, fields and enums in
+// pps.proto (e.g. "pps.Foo.bar") to their comments
+var Docs = map[string]string{
+	"pps.AWSBatchBackend":                                 "AWSBatchBackend submits datum chunks to an AWS Batch job queue. The job\ndefinition's image must contain pachyderm's worker binary at\n/pach-bin/worker (along with the pipeline's code).",
+	"pps.AWSBatchBackend.credentials_secret":              "credentials_secret is the name of a kubernetes secret with the keys\nAWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, which are used to submit\njobs. If unset, the worker's IAM role is used.",
+	"pps.AWSCredentials":                                  "AWSCredentials lets user code assume an IAM role with IRSA (IAM roles for\nservice accounts). Workers mount a token for the pipeline's service\naccount, and user code's AWS SDK exchanges it for temporary credentials\nwith STS AssumeRoleWithWebIdentity, refreshing them as they expire. Each\njob's credentials have their own session name, <pipeline>-<job>, so that\nthe requests of each job can be told apart in CloudTrail.",
+	"pps.AWSCredentials.region":                           "region, if set, is the AWS region that user code's SDK uses",
+	"pps.AWSCredentials.role_arn":                         "role_arn is the IAM role that user code assumes, e.g.\n\"arn:aws:iam::123456789012:role/my-pipeline\"",
+	"pps.AWSCredentials.token_expiration":                 "token_expiration is how long each service account token is valid for,\nbetween 10 minutes and 24 hours. Tokens are refreshed before they\nexpire. It defaults to 1 hour.",
+	"pps.Artifact":                                        "Artifact is a named file that's attached to a job rather than committed to\nits output repo, e.g. a metrics report, a confusion matrix or a model card\nthat the job's user code produced. Its content is stored as an object.",
+	"pps.Blocker":                                         "Blocker is an unfinished commit that's keeping branches from progressing,\nalong with what's writing it and how to unblock it.",
+	"pps.Blocker.job":                                     "Job is the job that's writing the commit, if any",
+	"pps.Blocker.pipeline":                                "Pipeline is the pipeline that writes the commit, if any",
+	"pps.Blocker.reason":                                  "Reason explains why the commit is unfinished",
+	"pps.Blocker.remediations":                            "Remediations are pachctl commands that may unblock the commit, most\nlikely first",
+	"pps.Budget":                                          "Budget caps a pipeline's estimated spend in each calendar month (in UTC).\nSpend is estimated from how long the pipeline's jobs run and how many\nworkers they run on.",
+	"pps.Budget.monthly_limit":                            "monthly_limit is the most that the pipeline may spend in a month, in the\nsame unit as worker_hour_cost. Once its estimated spend exceeds this, the\npipeline doesn't start new jobs until the next month.",
+	"pps.Budget.worker_hour_cost":                         "worker_hour_cost is the estimated cost of running one of the pipeline's\nworkers (with its resource requests) for an hour",
+	"pps.BudgetSpend":                                     "BudgetSpend is a pipeline's estimated spend in a calendar month",
+	"pps.BudgetSpend.exceeded":                            "exceeded is true if 'spend' exceeded the pipeline's monthly_limit, in\nwhich case the pipeline doesn't start new jobs until the next month",
+	"pps.BudgetSpend.month":                               "month is the month that 'spend' covers, as \"YYYY-MM\" (in UTC)",
+	"pps.BudgetSpend.spend":                               "spend is the estimated cost of the pipeline's jobs that finished in\n'month'",
+	"pps.ChunkSpec":                                       "ChunkSpec specifies how a pipeline should chunk its datums.",
+	"pps.ChunkSpec.number":                                "number, if nonzero, specifies that each chunk should contain `number`\ndatums. Chunks may contain fewer if the total number of datums don't\ndivide evenly.",
+	"pps.ChunkSpec.size_bytes":                            "size_bytes, if nonzero, specifies a target size for each chunk of datums.\nChunks may be larger or smaller than size_bytes, but will usually be\npretty close to size_bytes in size.",
+	"pps.CloudCredentials":                                "CloudCredentials gives a pipeline's user code short-lived credentials for\ncloud services, so that it doesn't need long-lived keys in its image or in\nsecrets. Each pipeline's workers run as their own kubernetes service\naccount, named like the pipeline's network policy, which the cloud\nprovider's IAM must trust (e.g. in an AWS role's trust policy, or a GCP\nworkload identity binding).",
+	"pps.CommitMetadata":                                  "CommitMetadata is the metadata of one of the commits in a job's provenance\n(see pfs.CommitInfo.metadata). It's copied from the commit when the job is\ncreated.",
+	"pps.ConfigMapKey":                                    "ConfigMapKey exposes a key of a kubernetes config map to a pipeline's user\ncode as an env var, so that operational tunables can be changed in the\nconfig map without updating the pipeline. Workers pick up the key's new\nvalue before running the user code on the next datum.",
+	"pps.ConfigMapKey.env_var":                            "env_var is the env var that's set to the key's value",
+	"pps.ConfigMapKey.key":                                "key is the key in the config map",
+	"pps.ConfigMapKey.name":                               "name is the name of the config map, which must be in pachd's namespace",
+	"pps.ConfigMapKey.reprocess":                          "reprocess, if true, makes a change to the key's value update the\npipeline with reprocess, so that all of its datums are processed with\nthe new value. Otherwise only datums that are processed after the change\nsee it.",
+	"pps.CreateJobRequest.data_processed":                 "Counts of how many times we processed or skipped a datum",
+	"pps.CreateJobRequest.restart":                        "Fields below should only be set when restoring an extracted job.",
+	"pps.CreateJobRequest.stats":                          "Download/process/upload time and download/upload bytes",
+	"pps.CreatePipelineRequest.backend":                   "backend, if set, runs the pipeline's datums on a batch system other than\nkubernetes (see ExecutionBackend)",
+	"pps.CreatePipelineRequest.budget":                    "budget, if set, caps the pipeline's estimated spend per month. Once it's\nexceeded, the pipeline doesn't start new jobs until the next month.",
+	"pps.CreatePipelineRequest.cache_size":                "cache_size is the amount of memory each worker uses to cache data",
+	"pps.CreatePipelineRequest.chunk_spec":                "chunk_spec controls how many datums are assigned to a worker at once",
+	"pps.CreatePipelineRequest.cloud_credentials":         "cloud_credentials, if set, gives the pipeline's user code short-lived\ncredentials for AWS or GCP (see CloudCredentials)",
+	"pps.CreatePipelineRequest.datum_cache":               "datum_cache, if true, keys the pipeline's datums on its version as well\nas their inputs, so a datum is skipped, and its cached output copied,\nonly if a previous job of the same version of the pipeline processed\nidentical inputs. Without it, datums' outputs are also reused by later\nversions of the pipeline, until it's updated with reprocess set.",
+	"pps.CreatePipelineRequest.datum_order":               "datum_order, if set, controls the order in which the pipeline's workers\nprocess the datums of each job (see DatumOrder)",
+	"pps.CreatePipelineRequest.datum_profiles":            "datum_profiles, if set, are size classes of the pipeline's datums, each\nof which is processed by its own pool of workers (see DatumProfile)",
+	"pps.CreatePipelineRequest.datum_retry":               "datum_retry, if set, controls how long workers wait before retrying a\nfailed datum, and which failures aren't retried (see DatumRetry)",
+	"pps.CreatePipelineRequest.datum_timeout":             "datum_timeout is the maximum time that a datum may be processed for,\nafter which it fails",
+	"pps.CreatePipelineRequest.datum_tries":               "datum_tries is the number of times that a failed datum is retried before\nthe job fails. It defaults to 3.",
+	"pps.CreatePipelineRequest.description":               "description is a human-readable description of the pipeline",
+	"pps.CreatePipelineRequest.determinism_check":         "determinism_check, if set, makes the pipeline's workers run a sample of\neach job's datums twice, and count the datums whose outputs differ in\nthe job's stats (see DeterminismCheck)",
+	"pps.CreatePipelineRequest.downtime_windows":          "downtime_windows are recurring windows during which the pipeline doesn't\nstart new jobs. Commits that arrive during a window queue up, and are\nprocessed once it ends.",
+	"pps.CreatePipelineRequest.egress":                    "egress, if set, copies the pipeline's output to an object store URL when\neach job finishes",
+	"pps.CreatePipelineRequest.egress_proxy":              "egress_proxy, if set, runs a proxy in the pipeline's worker pods that\nonly allows requests to the hosts that it lists (see EgressProxy)",
+	"pps.CreatePipelineRequest.empty_job_policy":          "empty_job_policy controls what happens to the pipeline's jobs that don't\nprocess any datums and leave its output unchanged (see EmptyJobPolicy)",
+	"pps.CreatePipelineRequest.enable_stats":              "enable_stats, if true, makes the pipeline collect timing and size\nstatistics for each datum, and keep the logs of failed datums",
+	"pps.CreatePipelineRequest.experiment_tracking":       "experiment_tracking, if set, publishes a record of each of the pipeline's\nfinished jobs to an experiment tracker (see ExperimentTracking)",
+	"pps.CreatePipelineRequest.hashtree_spec":             "hashtree_spec controls how many shards the pipeline's output hashtrees\nare split into",
+	"pps.CreatePipelineRequest.input":                     "input specifies the data that the pipeline processes, and how it's split\ninto datums",
+	"pps.CreatePipelineRequest.job_timeout":               "job_timeout is the maximum time that a job may run for, after which it's\nkilled",
+	"pps.CreatePipelineRequest.max_concurrent_jobs":       "max_concurrent_jobs, if greater than 1, is the number of the pipeline's\njobs that may run at once, each in its own pool of workers. Concurrent\njobs don't wait for the jobs of earlier commits to finish, so they only\nskip the datums of jobs that already have.",
+	"pps.CreatePipelineRequest.max_queue_size":            "MaxQueueSize caps the number of datums a worker queues at once (1 by\ndefault). Within that cap, workers only queue datums as long as they have\nroom for their inputs.",
+	"pps.CreatePipelineRequest.metadata":                  "metadata holds annotations and labels that are attached to the pipeline\nand its jobs (see Metadata)",
+	"pps.CreatePipelineRequest.network_policy":            "network_policy, if set, restricts the destinations that the pipeline's\nworkers can reach over the network (see NetworkPolicy)",
+	"pps.CreatePipelineRequest.output_branch":             "output_branch is the branch of the output repo that the pipeline writes\nto. It defaults to \"master\".",
+	"pps.CreatePipelineRequest.parallelism_spec":          "parallelism_spec controls how many workers the pipeline runs",
+	"pps.CreatePipelineRequest.pipeline":                  "pipeline is the name of the pipeline, and of its output repo",
+	"pps.CreatePipelineRequest.pod_patch":                 "a json patch will be applied to the pipeline's pod_spec before it's created;",
+	"pps.CreatePipelineRequest.pod_spec":                  "deprecated, use pod_patch below",
+	"pps.CreatePipelineRequest.reprocess":                 "Reprocess forces the pipeline to reprocess all datums.\nIt only has meaning if Update is true",
+	"pps.CreatePipelineRequest.resource_limits":           "resource_limits is the maximum amount of resources that each worker may\nuse",
+	"pps.CreatePipelineRequest.resource_requests":         "resource_requests is the amount of resources that each worker requests\nfrom kubernetes",
+	"pps.CreatePipelineRequest.s3_out":                    "s3_out, if true, makes the pipeline's code write its output to the \"out\"\nbucket of the job's S3 gateway, rather than to /pfs/out. The bucket is\nwrite-only.",
+	"pps.CreatePipelineRequest.salt":                      "salt is mixed into the hashes of the pipeline's datums. It's randomly\ngenerated when a pipeline is created, so pipelines never share skipped\ndatums.",
+	"pps.CreatePipelineRequest.scheduling_spec":           "scheduling_spec controls which nodes the pipeline's workers run on",
+	"pps.CreatePipelineRequest.scratch_volume":            "scratch_volume, if set, is the volume in which the pipeline's workers\nstore datums (see ScratchVolume)",
+	"pps.CreatePipelineRequest.service":                   "service, if set, runs the pipeline as a long-lived service that serves\nits input data",
+	"pps.CreatePipelineRequest.spec_commit":               "spec_commit is the commit in the spec repo that holds the pipeline's\nspec. It's set by pachctl when restoring a pipeline.",
+	"pps.CreatePipelineRequest.spill":                     "spill, if set, stores the pipeline's datum hashtrees in an object store\nlocation outside of PFS (see Spill)",
+	"pps.CreatePipelineRequest.spout":                     "spout, if set, runs the pipeline as a spout, whose code writes data into\nits output repo continuously instead of processing input",
+	"pps.CreatePipelineRequest.standby":                   "standby, if true, scales the pipeline's workers down to zero when it has\nno jobs to run",
+	"pps.CreatePipelineRequest.standby_grace_period":      "StandbyGracePeriod, if set, is how long a standby pipeline keeps its\nworkers running after its last job finishes, before scaling them down to\nzero. New input commits that arrive in this period are processed without\nwaiting for workers to start.",
+	"pps.CreatePipelineRequest.standby_wake_alarm":        "standby_wake_alarm, if set, is how long a standby pipeline may take to\nwake up and start processing a job before pachd reports it (see\nStandbyWake)",
+	"pps.CreatePipelineRequest.stream_output":             "stream_output, if true, makes workers upload each file in /pfs/out as\nsoon as the user code closes it, rather than after the datum finishes,\nand then truncate the local copy. User code can't read or append to a\nfile in /pfs/out after closing it, but can rename it.",
+	"pps.CreatePipelineRequest.tf_job":                    "tf_job encodes a Kubeflow TFJob spec. Pachyderm uses this to create TFJobs\nwhen running in a kubernetes cluster on which kubeflow has been installed.\nExactly one of 'tf_job' and 'transform' should be set",
+	"pps.CreatePipelineRequest.timeout_policy":            "timeout_policy controls what happens when datum_timeout or job_timeout\nfires (see TimeoutPolicy)",
+	"pps.CreatePipelineRequest.transform":                 "transform is the code that the pipeline runs, and the container it runs in",
+	"pps.CreatePipelineRequest.update":                    "update, if true, updates an existing pipeline rather than creating a new\none",
+	"pps.CreatePipelinesRequest.mode":                     "In ATOMIC mode, the pipelines that were created by the request are\ndeleted if a later pipeline can't be created. Pipelines that were\nupdated are not restored.",
+	"pps.CreatePipelinesRequest.pipelines":                "Pipelines are created (or updated) in order, so a pipeline may take the\noutput of a pipeline before it as input.",
+	"pps.CreateSecretRequest.file":                        "File is a kubernetes secret, in JSON",
+	"pps.CreateSecretRequest.registry":                    "Registry, if set instead of File, creates an image pull secret holding\ncredentials for a private docker registry, which pipelines can reference\nin their transform's image_pull_secrets.",
+	"pps.CronInput.overwrite":                             "Overwrite, if true, will expose a single datum that gets overwritten each\ntick. If false, it will create a new datum for each tick.",
+	"pps.Datum.id":                                        "ID is the hash computed from all the files",
+	"pps.DatumBalance":                                    "DatumBalance is how evenly a job's datums were spread across its workers.\nA job is balanced when its workers were busy for about as long as each\nother, and finished at about the same time.",
+	"pps.DatumBalance.tail":                               "tail is how long the job ran after its first worker ran out of datums",
+	"pps.DatumBalance.worker_time_max":                    "How long the busiest worker and the average worker spent processing the\njob's datums",
+	"pps.DatumBalance.workers":                            "workers is how many workers processed the job's datums",
+	"pps.DatumCost":                                       "DatumCost is how a DATUM_ORDER_COST order estimates the cost of each datum",
+	"pps.DatumCost.DATUM_COST_DURATION":                   "How long the datum's files took to process when the pipeline last\nprocessed them. Datums that haven't been processed before are estimated\nfrom their size, at the rate of the datums that have.",
+	"pps.DatumCost.DATUM_COST_SIZE":                       "The total size of the datum's files, across all of its inputs",
+	"pps.DatumOrder":                                      "DatumOrder controls the order in which a pipeline's workers process the\ndatums of each job, so that important datums (e.g. the latest partitions of\na dataset) are processed first. Datums that the order doesn't distinguish\nkeep their default order.",
+	"pps.DatumOrder.cost":                                 "cost is how a DATUM_ORDER_COST order estimates the cost of each datum",
+	"pps.DatumOrder.priority_file":                        "priority_file is the path of the priority file in priority_input. Each of\nits lines is a glob pattern, and datums whose files match earlier lines\nare processed first. Datums that match no line are processed last.",
+	"pps.DatumOrder.priority_input":                       "priority_input is the name of the pfs input that holds the priority file\nof a DATUM_ORDER_PRIORITY_FILE order",
+	"pps.DatumOrder.reverse":                              "reverse, if true, reverses the order (e.g. newest first)",
+	"pps.DatumOrderBy":                                    "DatumOrderBy is what a pipeline orders the datums of each job by (see\nDatumOrder)",
+	"pps.DatumOrderBy.DATUM_ORDER_COST":                   "Most expensive first, by each datum's estimated cost (see\nDatumOrder.cost), so that the slowest datums start early rather than\nholding up the end of the job",
+	"pps.DatumOrderBy.DATUM_ORDER_DEFAULT":                "Largest datums first, then by path. This is the default.",
+	"pps.DatumOrderBy.DATUM_ORDER_LEXICAL":                "By the paths of each datum's files",
+	"pps.DatumOrderBy.DATUM_ORDER_MODIFIED":               "Oldest first, by when the commits that hold each datum's files finished.\nPFS doesn't record when individual files change, so datums whose files\nare all in the same commits keep their default order.",
+	"pps.DatumOrderBy.DATUM_ORDER_PRIORITY_FILE":          "By the first line of a priority file that matches any of each datum's\nfiles (see DatumOrder.priority_file)",
+	"pps.DatumOrderBy.DATUM_ORDER_SIZE":                   "Smallest first, by the total size of each datum's files",
+	"pps.DatumProfile":                                    "DatumProfile is a size class of a pipeline's datums. The datums in each\nclass are processed by their own pool of workers, which have their own\nresource requests and limits, e.g. so that a few large datums don't force\nevery worker to request enough memory for them.",
+	"pps.DatumProfile.min_size_bytes":                     "min_size_bytes is the smallest datum (by the total size of its input\nfiles) that the profile's workers process. Each datum is processed by the\nprofile with the largest min_size_bytes that's no larger than the datum,\nor by the pipeline's own workers if the datum is smaller than every\nprofile's min_size_bytes.",
+	"pps.DatumProfile.name":                               "name identifies the profile's workers (it's part of the name of their\nRC), and must be a valid DNS label",
+	"pps.DatumProfile.parallelism_spec":                   "parallelism_spec is the number of workers in the profile's pool. It\ndefaults to a single worker.",
+	"pps.DatumRetry":                                      "DatumRetry controls how a pipeline's workers retry datums that fail. By\ndefault, failed datums are retried immediately, and all failures are\nretried.",
+	"pps.DatumRetry.initial_backoff":                      "initial_backoff is how long a worker waits before the first retry of a\nfailed datum",
+	"pps.DatumRetry.max_backoff":                          "max_backoff is the longest that a worker waits between retries",
+	"pps.DatumRetry.multiplier":                           "multiplier is the factor by which the wait grows after each retry. It\ndefaults to 2.",
+	"pps.DatumRetry.permanent_exit_codes":                 "permanent_exit_codes are the exit codes with which user code signals\nthat a datum failed permanently, and isn't retried. User code can also\nwrite \"permanent\" or \"transient\" to the file named by\n$PACH_DATUM_FAILURE_FILE, which takes precedence over its exit code.",
+	"pps.DeterminismCheck":                                "DeterminismCheck makes a pipeline's workers run a sample of each job's\ndatums twice and compare the two outputs, to catch transforms whose output\nisn't a function of their input. Datum skipping assumes that it is.\nNondeterministic datums are counted in the job's stats (see\nProcessStats.datums_nondeterministic).",
+	"pps.DeterminismCheck.fraction":                       "fraction is the share of each job's datums that are run twice, between 0\n(exclusive) and 1",
+	"pps.DiagnoseRequest.client_version":                  "client_version is the version of the client (e.g. pachctl), which is\ncompared with pachd's. It's not compared if it's unset.",
+	"pps.Diagnosis.checks":                                "checks are the names of the checks that Diagnose ran",
+	"pps.Diagnosis.findings":                              "findings are the problems that the checks found, most severe first",
+	"pps.DowntimeWindow":                                  "DowntimeWindow is a recurring window of time during which a pipeline\ndoesn't start new jobs (e.g. to save costs at night, or while an upstream\nsystem is under maintenance)",
+	"pps.DowntimeWindow.duration":                         "duration is how long the window stays open",
+	"pps.DowntimeWindow.start":                            "start is a cron expression (e.g. \"0 22 * * *\") for when the window opens",
+	"pps.Egress.kafka":                                    "kafka, if set, publishes each of a job's output commits to a Kafka topic,\ninstead of copying it to the object store at URL",
+	"pps.Egress.sql_database":                             "sql_database, if set, loads each of a job's output commits into a\ndatabase, instead of copying it to the object store at URL",
+	"pps.EgressProxy":                                     "EgressProxy routes the HTTP and HTTPS requests of a pipeline's user code\nthrough a proxy in each worker pod, which refuses requests to hosts that\naren't in 'hosts' and records them in the audit log. The proxy is set in\nthe user code's HTTP_PROXY and HTTPS_PROXY environment variables, so it\nonly applies to code that respects them (use a NetworkPolicy to restrict\nall of a pipeline's traffic, on clusters that enforce them).",
+	"pps.EgressProxy.hosts":                               "hosts are the external hosts that user code can reach, e.g. \"pypi.org\".\n\"*.example.com\" matches every subdomain of example.com, and a host may\ninclude a port (e.g. \"example.com:8443\"), otherwise every port is\nallowed.",
+	"pps.EmptyJobPolicy":                                  "EmptyJobPolicy controls what happens to a pipeline's empty jobs: those that\ndidn't process any datums (e.g. because the glob pattern matched nothing\nnew, so every datum was skipped) and whose output commit is identical to its\nparent",
+	"pps.EmptyJobPolicy.EMPTY_JOB_ELIDE":                  "Like EMPTY_JOB_SKIP, but delete empty jobs once their output commits are\nfinished, so that they aren't listed at all. Their output commits, which\nhave the same content as their parents, are kept: PFS keeps an output\ncommit for the latest commits of the pipeline's inputs, and would start a\nnew one (and so a new job) if it were deleted.",
+	"pps.EmptyJobPolicy.EMPTY_JOB_KEEP":                   "Finish empty jobs as successful, like any other job. This is the default.",
+	"pps.EmptyJobPolicy.EMPTY_JOB_SKIP":                   "Finish empty jobs in the state JOB_SKIPPED, without egressing their\noutput or publishing them to the experiment tracker",
+	"pps.EtcdJobInfo":                                     "EtcdJobInfo is the portion of the JobInfo that gets stored in etcd during\njob execution. It contains fields which change over the lifetime of the job\nbut aren't used in the execution of the job.",
+	"pps.EtcdJobInfo.artifacts":                           "The artifacts that the job's user code registered (see PutArtifact)",
+	"pps.EtcdJobInfo.data_processed":                      "Counts of how many times we processed or skipped a datum",
+	"pps.EtcdJobInfo.datum_balance":                       "How evenly the job's datums were spread across its workers, and how long\neach of the datums that the job processed took, for pipelines that order\ndatums by their duration (see DatumCost). Both are set when the job\nfinishes.",
+	"pps.EtcdJobInfo.estimated_cost":                      "The job's estimated cost, if its pipeline has a budget (see pps.Budget).\nIt's set when the job finishes.",
+	"pps.EtcdJobInfo.input_metadata":                      "The metadata of the job's input commits (see pps.CommitMetadata)",
+	"pps.EtcdJobInfo.labels":                              "The labels of the job's pipeline when the job was created, encoded for\nppsdb.JobsLabelIndex (see ppsdb.LabelIndexValues)",
+	"pps.EtcdJobInfo.output_diff":                         "How the job's output commit differs from its parent. It's set when the\njob's output commit is finished.",
+	"pps.EtcdJobInfo.replay_of":                           "The job that this job replays (see ReplayJob), if any",
+	"pps.EtcdJobInfo.restart":                             "Job restart count (e.g. due to datum failure)",
+	"pps.EtcdJobInfo.schema_version":                      "The version of the schema that this EtcdJobInfo was written with (see\nppsdb.SchemaVersion). 0 means it was written before the schema was\nversioned.",
+	"pps.EtcdJobInfo.standby_wake":                        "How long the job's pipeline took to wake from standby to process the job,\nif it did",
+	"pps.EtcdJobInfo.started_index":                       "The job's start time, encoded for ppsdb.JobsStartedIndex (see\nppsdb.StartedIndexValue)",
+	"pps.EtcdJobInfo.stats":                               "Download/process/upload time and download/upload bytes",
+	"pps.EtcdJobInfo.worker_pool":                         "The worker pool (from 1 to the pipeline's max_concurrent_jobs) whose\nworkers process the job, or 0 if the pipeline runs one job at a time.\nIt's set by the pipeline's master before it plans the job.",
+	"pps.EtcdPipelineInfo":                                "EtcdPipelineInfo is proto that Pachd stores in etcd for each pipeline. It\ntracks the state of the pipeline, and points to its metadata in PFS (and,\nby pointing to a PFS commit, de facto tracks the pipeline's version)",
+	"pps.EtcdPipelineInfo.budget":                         "The pipeline's budget, copied from its spec (like 'labels') so that the\ncost of its jobs can be recorded when they finish",
+	"pps.EtcdPipelineInfo.budget_spend":                   "The pipeline's estimated spend in the current (or most recent) month in\nwhich one of its jobs finished",
+	"pps.EtcdPipelineInfo.labels":                         "The pipeline's labels, encoded for ppsdb.PipelinesLabelIndex (see\nppsdb.LabelIndexValues)",
+	"pps.EtcdPipelineInfo.schema_version":                 "The version of the schema that this EtcdPipelineInfo was written with\n(see ppsdb.SchemaVersion). 0 means it was written before the schema was\nversioned.",
+	"pps.EtcdPipelineInfo.service_autoscaling":            "The most recent scaling decision for the pipeline, if it's a service with\nautoscaling",
+	"pps.EtcdPipelineInfo.standby_wake":                   "The pipeline's most recent (or current) wake from standby",
+	"pps.EtcdPipelineInfo.state_history":                  "The pipeline's most recent state transitions, oldest first (see\nppsutil.MaxPipelineStateHistory)",
+	"pps.ExecutionBackend":                                "ExecutionBackend runs a pipeline's datums on compute outside of the\npachyderm cluster (e.g. for burst capacity). The pipeline's master submits\neach chunk of datums to the backend as a separate job, which downloads its\ninputs from pachd and uploads its outputs to the job's output commit.\nExactly one of aws_batch or kubernetes must be set.",
+	"pps.ExecutionBackend.pachd_address":                  "pachd_address is the address at which the external jobs can reach pachd,\ne.g. \"grpc://pachd.example.com:30650\"",
+	"pps.ExperimentTracking":                              "ExperimentTracking publishes a record of each of a pipeline's finished jobs\n(see RunRecord) to an experiment tracker, so that the runs of training\npipelines show up there without any tracking code in their transform.",
+	"pps.ExperimentTracking.Secret.key":                   "key is the key in the secret that holds the token that's sent to the\ntracker as a bearer token",
+	"pps.ExperimentTracking.Secret.name":                  "name is the name of the kubernetes secret",
+	"pps.ExperimentTracking.experiment":                   "experiment is the MLflow experiment that jobs are logged in, which is\ncreated if it doesn't exist. It defaults to the pipeline's name.",
+	"pps.ExperimentTracking.metrics_artifact":             "metrics_artifact is the job artifact (see PutArtifact) that holds a job's\nmetrics, as a JSON object of metric names to numbers. It defaults to\n\"metrics.json\". Jobs without it are published without metrics.",
+	"pps.ExperimentTracking.mlflow_url":                   "mlflow_url is the URL of an MLflow tracking server, e.g.\n\"http://mlflow.default:5000\". Each job is logged as a run of\n'experiment'.",
+	"pps.ExperimentTracking.url":                          "url is a generic endpoint that each job's RunRecord is POSTed to as JSON.\nExactly one of mlflow_url and url must be set.",
+	"pps.FailureClass":                                    "FailureClass is whether a failed datum is worth retrying",
+	"pps.FailureClass.FAILURE_PERMANENT":                  "The failure will happen again, so the datum isn't retried",
+	"pps.FailureClass.FAILURE_TRANSIENT":                  "The failure may not happen again, so the datum is retried",
+	"pps.Finding":                                         "Finding is a problem that Diagnose found in the cluster",
+	"pps.Finding.check":                                   "check is the name of the check that found the problem, e.g.\n\"version_skew\"",
+	"pps.Finding.details":                                 "details are more information about the problem, e.g. the kubernetes\nevents of a crash-looping worker",
+	"pps.Finding.remediations":                            "remediations are commands or steps that may fix the problem",
+	"pps.FindingSeverity":                                 "FindingSeverity orders the problems that Diagnose finds",
+	"pps.FindingSeverity.FINDING_CRITICAL":                "FINDING_CRITICAL is a problem that's causing failures now",
+	"pps.FindingSeverity.FINDING_INFO":                    "FINDING_INFO is worth knowing about, but isn't a problem yet",
+	"pps.FindingSeverity.FINDING_WARNING":                 "FINDING_WARNING is a problem that may cause failures later",
+	"pps.GCPCredentials":                                  "GCPCredentials lets user code act as a GCP service account with GKE\nworkload identity. The pipeline's kubernetes service account is annotated\nwith the GCP service account, and the GKE metadata server gives user code\nshort-lived tokens for it.",
+	"pps.GCPCredentials.service_account":                  "service_account is the email of the GCP service account, e.g.\n\"my-pipeline@my-project.iam.gserviceaccount.com\"",
+	"pps.GPUSpec.fraction":                                "The fraction of a single GPU to request (greater than 0 and at most 1),\nfor workers that share GPUs with other pods. It can't be set with number.",
+	"pps.GPUSpec.number":                                  "The number of GPUs to request.",
+	"pps.GPUSpec.shares_per_gpu":                          "The number of pods that can share each GPU, as configured in the GPU\ndevice plugin (e.g. the replicas of NVIDIA time-slicing or MPS), which is\nrequired with fraction. A fraction is requested as that share of this\nmany units of 'type', which must be the resource that the device plugin\nadvertises for each share (e.g. nvidia.com/gpu.shared).",
+	"pps.GPUSpec.type":                                    "The type of GPU (nvidia.com/gpu or amd.com/gpu for example).",
+	"pps.GarbageCollectInfo":                              "GarbageCollectInfo describes the progress of the running garbage collection,\nor the result of the last one",
+	"pps.GarbageCollectInfo.bytes_reclaimed":              "BytesReclaimed is the total size of the deleted objects",
+	"pps.GarbageCollectInfo.objects_estimated":            "ObjectsEstimated is the number of objects that the last successful\ngarbage collection scanned, which is used to estimate the time remaining",
+	"pps.GarbageCollectInfo.objects_scanned":              "ObjectsScanned is the number of objects that the sweep has checked",
+	"pps.GarbageCollectInfo.objects_spared":               "ObjectsSpared is the number of unreferenced objects that weren't deleted\nbecause they were written within the grace period, and may be about to be\nreferenced",
+	"pps.GarbageCollectInfo.reason":                       "Reason is why the garbage collection failed, if it did",
+	"pps.GarbageCollectInfo.remaining":                    "Remaining estimates how long the sweep will take to finish. It's only set\nby InspectGarbageCollect, while the sweep is running.",
+	"pps.GarbageCollectRequest.grace_period":              "GracePeriod is how long GC spares unreferenced objects and tags for\nafter they're written, as they may be about to be referenced. It\ndefaults to, and can't exceed, an hour.",
+	"pps.GarbageCollectRequest.memory_bytes":              "Memory is how much memory to use in computing which objects are alive. A\nlarger number will result in more precise garbage collection (at the\ncost of more memory usage).",
+	"pps.GarbageCollectState.GC_MARKING":                  "GC_MARKING means that GC is finding the objects and tags that are in use",
+	"pps.GarbageCollectState.GC_NOT_RUN":                  "GC_NOT_RUN means that garbage collection has never been run",
+	"pps.GarbageCollectState.GC_SWEEPING":                 "GC_SWEEPING means that GC is deleting the objects and tags that aren't",
+	"pps.GetLogsRequest.data_filters":                     "Names of input files from which we want processing logs. This may contain\nmultiple files, to query pipelines that contain multiple inputs. Each\nfilter may be an absolute path of a file within a pps repo, or it may be\na hash for that file (to search for files at specific versions)",
+	"pps.GetLogsRequest.follow":                           "Continue to follow new logs as they become available.",
+	"pps.GetLogsRequest.job":                              "The job from which we want to get logs.",
+	"pps.GetLogsRequest.master":                           "If true get logs from the master process",
+	"pps.GetLogsRequest.pattern":                          "If set, only log messages matching this regular expression (in RE2\nsyntax) are returned.",
+	"pps.GetLogsRequest.pipeline":                         "The pipeline from which we want to get logs (required if the job in 'job'\nwas created as part of a pipeline. To get logs from a non-orphan job\nwithout the pipeline that created it, you need to use ElasticSearch).",
+	"pps.GetLogsRequest.since":                            "If set, only logs written within this duration of the request are\nreturned.",
+	"pps.GetLogsRequest.tail":                             "If nonzero, the number of lines from the end of the logs to return.  Note:\ntail applies per container, so you will get tail * <number of pods> total\nlines back.",
+	"pps.GetLogsRequest.worker_id":                        "If set, only logs from this worker (pod) are returned.",
+	"pps.HashtreeSpec":                                    "HashTreeSpec sets the number of shards into which pps splits a pipeline's\noutput commits (sharded commits are implemented in Pachyderm 1.8+ only)",
+	"pps.ImagePinning":                                    "ImagePinning controls whether a pipeline's image tag is resolved to a\ndigest when the pipeline is created. The cluster's policy (pachd's\nIMAGE_PINNING setting) is a minimum: a pipeline can pin more strictly than\nthe cluster, but not less.",
+	"pps.ImagePinning.IMAGE_PINNING_NONE":                 "Run the pipeline's image by tag.",
+	"pps.ImagePinning.IMAGE_PINNING_PIN":                  "Resolve the image's tag to a digest, and run the pipeline's workers with\nthat digest (recorded in PipelineInfo.image_digest).",
+	"pps.ImagePinning.IMAGE_PINNING_STRICT":               "Like IMAGE_PINNING_PIN, but also reject floating tags (no tag, or\n\"latest\").",
+	"pps.Impact":                                          "Impact is everything downstream of a commit: the commits that have it in\ntheir provenance, the jobs that wrote them, the branches that point at them\nand the places that their jobs egressed them to. It's what's affected if the\ncommit is bad.",
+	"pps.Impact.branches":                                 "branches are the branches whose head is commit, or one of commits",
+	"pps.Impact.commit":                                   "commit is the (resolved) commit whose impact this is",
+	"pps.Impact.commits":                                  "commits are the commits downstream of commit, ordered by repo",
+	"pps.Impact.egresses":                                 "egresses are the destinations that the jobs in commits sent their output\nto",
+	"pps.Impact.remediations":                             "remediations are pachctl commands that invalidate commit and everything\ndownstream of it, if commit is an input commit",
+	"pps.ImpactedCommit":                                  "ImpactedCommit is a commit downstream of an Impact's commit, along with the\npipeline and job that wrote it, if any",
+	"pps.ImpactedEgress":                                  "ImpactedEgress is a destination outside of Pachyderm that a job sent an\nimpacted commit to",
+	"pps.ImpactedEgress.destination":                      "destination describes where the commit was sent: the egress URL, the\nURL of the SQL database (which has no password) or the Kafka topic",
+	"pps.Input.cron":                                      "cron is an input that triggers the pipeline on a schedule",
+	"pps.Input.cross":                                     "cross is a list of inputs whose datums are combined with every datum of\nthe other inputs (i.e. their cross product)",
+	"pps.Input.git":                                       "git is an input that reads from a git repo, which is updated by a webhook",
+	"pps.Input.group":                                     "group is a list of inputs whose datums are grouped by their group_by\nvalues. Each group is a single datum.",
+	"pps.Input.join":                                      "join is a list of inputs whose datums are joined on their join_on values",
+	"pps.Input.pfs":                                       "pfs is an input that reads files from a PFS repo",
+	"pps.Input.union":                                     "union is a list of inputs whose datums are all processed, independently",
+	"pps.InputConflict":                                   "InputConflict is a branch in a job's provenance that resolves to more than\none commit. Inputs that read different commits of it may see different\nversions of the same upstream data.",
+	"pps.InputConflict.commits":                           "commits are the branch's commits in the job's provenance, sorted by ID",
+	"pps.InputConsistency":                                "InputConsistency describes whether the commits that a job reads are a\nsingle, provenance-consistent snapshot: that every branch in the job's\n(transitive) provenance resolves to one commit, and every input is bound to\na commit. Usually they are, but e.g. an input on a branch that isn't\nupdated when its own inputs are (such as a pipeline's non-output branch) may\nstill be derived from older commits than another input of the job.",
+	"pps.InputConsistency.conflicts":                      "conflicts are the branches that resolve to more than one commit",
+	"pps.InputConsistency.unbound":                        "unbound are the names of the inputs that aren't bound to any commit,\nbecause their branch had no commits when the job was created",
+	"pps.InputFile.hash":                                  "This file's hash",
+	"pps.InputFile.path":                                  "This file's absolute path within its pfs repo.",
+	"pps.InspectJobRequest.block_state":                   "block until state is either JOB_STATE_FAILURE or JOB_STATE_SUCCESS",
+	"pps.InspectJobRequest.job":                           "Callers should set either Job or OutputCommit, not both.",
+	"pps.InspectPipelineRequest.full":                     "Full, if true, returns the pipeline's raw etcd state along with its spec,\nand fills in the defaults for any fields that were added after the\npipeline was created.",
+	"pps.InstantiateTemplateRequest.parameters":           "Parameters holds one set of values per instantiation. The template is\nrendered and its pipelines are created once for each set.",
+	"pps.InstantiateTemplateRequest.reprocess":            "Reprocess is passed to the created pipelines when Update is true.",
+	"pps.InstantiateTemplateRequest.template":             "Template is a file in PFS containing one or more pipeline specs (JSON or\nYAML), with parameters written as Go template actions, e.g. {{.customer}}",
+	"pps.InstantiateTemplateRequest.update":               "Update, if true, updates pipelines that already exist rather than\nfailing.",
+	"pps.InstantiateTemplateResponse.pipelines":           "Pipelines are the pipelines that were created, in the order they were\nrendered.",
+	"pps.JobIndex":                                        "JobIndex is an index of the jobs collection that ListJob can read jobs from\n(see ListJobRequest.index), rather than reading every job",
+	"pps.JobIndex.JOB_INDEX_AUTO":                         "Use the index of the most selective filter that ListJobRequest sets, out\nof output_commit, pipeline, label_selector (if it requires a label to\nhave a value), state (if it sets one state) and started_after or\nstarted_before, in that order. If it sets none of them, every job is read.",
+	"pps.JobIndex.JOB_INDEX_NONE":                         "Read every job",
+	"pps.JobIndex.JOB_INDEX_STARTED":                      "Jobs read by start time are ordered by when they started",
+	"pps.JobIndex.JOB_INDEX_STATE":                        "Jobs read by state are ordered by when they entered their state",
+	"pps.JobInfo.chunk_spec":                              "requires ListJobRequest.Full",
+	"pps.JobInfo.datum_cache":                             "requires ListJobRequest.Full",
+	"pps.JobInfo.datum_order":                             "requires ListJobRequest.Full",
+	"pps.JobInfo.datum_retry":                             "requires ListJobRequest.Full",
+	"pps.JobInfo.datum_timeout":                           "requires ListJobRequest.Full",
+	"pps.JobInfo.datum_tries":                             "requires ListJobRequest.Full",
+	"pps.JobInfo.egress":                                  "requires ListJobRequest.Full",
+	"pps.JobInfo.enable_stats":                            "requires ListJobRequest.Full",
+	"pps.JobInfo.input":                                   "requires ListJobRequest.Full",
+	"pps.JobInfo.input_commits":                           "input_commits are the commits that the job's inputs are bound to (see\n'input'), one per PFS, cron and git input, in the order of the inputs in\nits pipeline's spec. Unlike 'input', they don't require\nListJobRequest.Full.",
+	"pps.JobInfo.input_consistency":                       "input_consistency says whether the job's input commits are a single,\nprovenance-consistent snapshot (requires ListJobRequest.Full)",
+	"pps.JobInfo.input_metadata":                          "input_metadata is the metadata of the commits in the job's provenance\nthat have any, e.g. the IDs of the upstream batches that the job's input\ndata came from",
+	"pps.JobInfo.job_timeout":                             "requires ListJobRequest.Full",
+	"pps.JobInfo.metadata":                                "annotations require ListJobRequest.Full",
+	"pps.JobInfo.output_branch":                           "requires ListJobRequest.Full",
+	"pps.JobInfo.output_diff":                             "output_diff is set once the job's output commit is finished",
+	"pps.JobInfo.parallelism_spec":                        "requires ListJobRequest.Full",
+	"pps.JobInfo.pipeline_version":                        "requires ListJobRequest.Full",
+	"pps.JobInfo.pod_patch":                               "requires ListJobRequest.Full",
+	"pps.JobInfo.pod_spec":                                "requires ListJobRequest.Full",
+	"pps.JobInfo.reason":                                  "reason explains why the job is in the current state",
+	"pps.JobInfo.replay_of":                               "replay_of is the job that this job replays (see ReplayJob), if any",
+	"pps.JobInfo.resource_limits":                         "requires ListJobRequest.Full",
+	"pps.JobInfo.resource_requests":                       "requires ListJobRequest.Full",
+	"pps.JobInfo.salt":                                    "requires ListJobRequest.Full",
+	"pps.JobInfo.scheduling_spec":                         "requires ListJobRequest.Full",
+	"pps.JobInfo.service":                                 "requires ListJobRequest.Full",
+	"pps.JobInfo.spout":                                   "requires ListJobRequest.Full",
+	"pps.JobInfo.timeout_policy":                          "requires ListJobRequest.Full",
+	"pps.JobInfo.transform":                               "requires ListJobRequest.Full",
+	"pps.JobInfo.worker_pool":                             "worker_pool is the pool of workers that processes the job, for pipelines\nwith max_concurrent_jobs (see EtcdJobInfo.worker_pool)",
+	"pps.JobInfos.next_page_token":                        "NextPageToken is the token of the next page of jobs, if the request set\nPageSize. It's empty if there are no more jobs.",
+	"pps.JobProgress":                                     "JobProgress describes how far along a job is. JobProgress streams one each\ntime the job's datum counts change, and periodically in between (as the\nthroughput and ETA change over time even if the counts don't).",
+	"pps.JobProgress.eta":                                 "ETA is the estimated time until every datum is finished. It's unset if\nthe job isn't running or if no datums have been finished recently.",
+	"pps.JobProgress.throughput":                          "Throughput is the number of datums finished per second, measured over the\nlast minute",
+	"pps.JobState.JOB_PARTIAL_SUCCESS":                    "JOB_PARTIAL_SUCCESS is the state of a job of a pipeline with the timeout\npolicy TIMEOUT_PARTIAL_SUCCESS that finished its output commit without\nsome of its datums, because they (or the job) timed out",
+	"pps.JobState.JOB_SKIPPED":                            "JOB_SKIPPED is the state of an empty job (see EmptyJobPolicy) of a\npipeline with the empty job policy EMPTY_JOB_SKIP",
+	"pps.Kafka":                                           "Kafka configures a spout that consumes a Kafka topic. Messages are\ncommitted to the spout's output repo in batches, and their offsets are\ncommitted to Kafka only after the batch's output commit is finished, so\nevery message is written at least once.",
+	"pps.Kafka.batch_interval":                            "batch_interval is how long a batch waits for more messages, after its\nfirst message arrives, before it's committed. It defaults to 10s.",
+	"pps.Kafka.batch_size":                                "batch_size is the maximum number of messages in each output commit. It\ndefaults to 1000.",
+	"pps.Kafka.brokers":                                   "brokers are the addresses (host:port) of the Kafka brokers",
+	"pps.Kafka.format":                                    "format is how messages are written to the output repo. \"raw\" (the\ndefault) writes each message to its own file, /<partition>/<offset>.\n\"lines\" appends the messages in each batch, one per line, to /<topic>.",
+	"pps.Kafka.group":                                     "group is the consumer group that the spout's offsets are stored under. It\ndefaults to the pipeline's name.",
+	"pps.Kafka.topic":                                     "topic is the topic that's consumed",
+	"pps.KafkaEgress":                                     "KafkaEgress publishes the files in an output commit to a Kafka topic. Each\nmessage has the headers \"pachyderm-commit\" and \"pachyderm-path\", which name\nthe commit and file that it's from. Messages are published at least once:\nif egress is retried, the whole commit is published again.",
+	"pps.KafkaEgress.brokers":                             "brokers are the addresses (host:port) of the Kafka brokers",
+	"pps.KafkaEgress.format":                              "format is what each message holds. \"raw\" (the default) publishes each\nfile as one message. \"lines\" publishes each line of each file as its own\nmessage. Messages are keyed by the path of the file that they're from.",
+	"pps.KafkaEgress.topic":                               "topic is the topic that's published to",
+	"pps.KubernetesBackend":                               "KubernetesBackend runs datum chunks as kubernetes Jobs in another cluster.",
+	"pps.KubernetesBackend.context":                       "context is the kubeconfig context to use. If unset, the kubeconfig's\ncurrent context is used.",
+	"pps.KubernetesBackend.kubeconfig_secret":             "kubeconfig_secret is the name of a kubernetes secret whose \"config\" key\nholds a kubeconfig file for the remote cluster.",
+	"pps.ListDatumStreamResponse":                         "ListDatumStreamResponse is identical to ListDatumResponse, except that only\none DatumInfo is present (as these responses are streamed)",
+	"pps.ListDatumStreamResponse.page":                    "page is only set in the first response (and set to 0 in all other\nresponses)",
+	"pps.ListDatumStreamResponse.total_pages":             "total_pages is only set in the first response (and set to 0 in all other\nresponses)",
+	"pps.ListJobRequest.full":                             "Full indicates whether the result should include all pipeline details in\neach JobInfo, or limited information including name and status, but\nexcluding information in the pipeline spec. Leaving this \"false\" can make\nthe call significantly faster in clusters with a large number of pipelines\nand jobs.",
+	"pps.ListJobRequest.history":                          "History indicates return jobs from historical versions of pipelines\nsemantics are:\n0: Return jobs from the current version of the pipeline or pipelines.\n1: Return the above and jobs from the next most recent version\n2: etc.\n-1: Return jobs from all historical versions.",
+	"pps.ListJobRequest.index":                            "Index is the index of the jobs collection that jobs are read from. The\nother filters are applied to the jobs that it yields.",
+	"pps.ListJobRequest.input_commit":                     "input_commit, if set, limits the results to jobs that read all of these\ncommits (see JobInfo.input_commits), e.g. to find the jobs that consumed\na bad upstream commit. nil means all inputs.",
+	"pps.ListJobRequest.label_selector":                   "LabelSelector, if set, is a kubernetes label selector (e.g.\n\"team=nlp,env!=dev\"), and only jobs whose labels match it are returned",
+	"pps.ListJobRequest.output_commit":                    "nil means all outputs",
+	"pps.ListJobRequest.page_size":                        "PageSize, if non-zero, is the maximum number of jobs returned. Jobs are\nreturned newest first, and ListJob's response includes the token of the\nnext page, which is passed as PageToken to get that page.",
+	"pps.ListJobRequest.pipeline":                         "nil means all pipelines",
+	"pps.ListJobRequest.started_after":                    "StartedAfter and StartedBefore, if set, make only jobs that started in\nthe given time range be returned",
+	"pps.ListJobRequest.state":                            "State, if set, makes only jobs in one of the given states be returned",
+	"pps.ListNamesRequest.limit":                          "limit is the maximum number of names to return. If it's 0, all names are\nreturned.",
+	"pps.ListNamesRequest.repo":                           "repo is the repo whose branches are listed, if kind is BRANCH",
+	"pps.ListPipelineRequest.history":                     "History indicates how many historical versions you want returned. Its\nsemantics are:\n0: Return the current version of the pipeline or pipelines.\n1: Return the above and the next most recent version\n2: etc.\n-1: Return all historical versions.",
+	"pps.ListPipelineRequest.label_selector":              "LabelSelector, if set, is a kubernetes label selector (e.g.\n\"team=nlp,env!=dev\"), and only pipelines whose labels match it are\nreturned",
+	"pps.ListPipelineRequest.page_size":                   "PageSize, if non-zero, is the maximum number of pipelines returned (and\ncan't be combined with History). ListPipeline's response includes the\ntoken of the next page, which is passed as PageToken to get that page.",
+	"pps.ListPipelineRequest.pipeline":                    "If non-nil, only return info about a single pipeline, this is redundant\nwith InspectPipeline unless history is non-zero.",
+	"pps.ListPipelineRequest.state":                       "State, if set, makes only pipelines in one of the given states be\nreturned",
+	"pps.ListStuckBranchesRequest.threshold":              "Threshold is how long a branch's head must have been unfinished for the\nbranch to be reported. It defaults to one hour.",
+	"pps.LogMessage":                                      "LogMessage is a log line from a PPS worker, annotated with metadata\nindicating when and why the line was logged.",
+	"pps.LogMessage.data":                                 "The PFS files being processed (one per pipeline/job input)",
+	"pps.LogMessage.pipeline_name":                        "The job and pipeline for which a PFS file is being processed (if the job\nis an orphan job, pipeline name and ID will be unset)",
+	"pps.LogMessage.ts":                                   "The message logged, and the time at which it was logged",
+	"pps.LogMessage.user":                                 "User is true if log message comes from the users code.",
+	"pps.Metadata":                                        "Metadata holds user-defined annotations and labels for a pipeline, which\nits jobs inherit. Labels are indexed, so that ListPipeline and ListJob can\nselect pipelines and jobs by label (see ListPipelineRequest.label_selector).",
+	"pps.NetworkPeer":                                     "NetworkPeer is a destination that a pipeline's workers can reach. Exactly\none of cidr and pod_labels must be set.",
+	"pps.NetworkPeer.cidr":                                "cidr is a block of IP addresses, e.g. \"10.0.0.0/16\"",
+	"pps.NetworkPeer.pod_labels":                          "pod_labels selects the pods in pachd's namespace that have these labels",
+	"pps.NetworkPeer.ports":                               "ports are the TCP ports that workers can reach. If it's empty, workers\ncan reach every port.",
+	"pps.NetworkPolicy":                                   "NetworkPolicy restricts the destinations that a pipeline's workers can\nreach over the network, with a kubernetes NetworkPolicy. Workers can always\nreach pachd, etcd, their pipeline's other workers, DNS, and the addresses\nthat pachd allows for every pipeline (e.g. its object store). If pachd is\ndeployed with --worker-network-policy, pipelines without a NetworkPolicy\ncan only reach those destinations.",
+	"pps.NetworkPolicy.allow":                             "allow lists the other destinations that workers can reach",
+	"pps.OutputDiff":                                      "OutputDiff summarizes how a job's output commit differs from its parent,\nwhich is usually the output commit of the pipeline's previous job. A job\nthat rewrites most of its output when little of its input changed may\nindicate that the pipeline's datums aren't being skipped.",
+	"pps.OutputDiff.size_delta":                           "size_delta is how many bytes larger the output commit is than its parent",
+	"pps.PFSInput.branch":                                 "branch is the branch of 'repo' that the input reads from. It defaults to\n\"master\".",
+	"pps.PFSInput.commit":                                 "commit is the commit that a job reads from. It's set in JobInfo, not in\npipeline specs.",
+	"pps.PFSInput.empty_files":                            "EmptyFiles, if true, will cause files from this PFS input to be\npresented as empty files. This is useful in shuffle pipelines where you\nwant to read the names of files and reorganize them using symlinks.",
+	"pps.PFSInput.glob":                                   "glob is a glob pattern that splits the input's files into datums. Each\nfile or directory that it matches is a datum.",
+	"pps.PFSInput.group_by":                               "group_by is a pattern (with capture groups) that's matched against each\ndatum's path. Datums from inputs in a group whose group_by values are\nequal are combined into a single datum.",
+	"pps.PFSInput.join_on":                                "join_on is a pattern (with capture groups) that's matched against each\ndatum's path. Datums from inputs in a join are joined if their join_on\nvalues are equal.",
+	"pps.PFSInput.lazy":                                   "lazy, if true, makes the input's files available as named pipes that\nare only downloaded when they're read, rather than downloading them before\ncmd runs",
+	"pps.PFSInput.name":                                   "name is the name of the directory in /pfs that the input's files appear\nin. It defaults to 'repo'.",
+	"pps.PFSInput.repo":                                   "repo is the repo that the input reads from",
+	"pps.PFSInput.s3":                                     "s3, if true, exposes the input's commit to the pipeline's code as a\nread-only bucket (named after the input) in the job's S3 gateway, rather\nthan as files in /pfs. Its glob must be \"/\".",
+	"pps.ParallelismSpec.coefficient":                     "Starts the pipeline/job with number of workers equal to 'coefficient' * N,\nwhere N is the number of nodes in the kubernetes cluster.\n\nFor example, if each Kubernetes node has four CPUs, you might set\n'coefficient' to four, so that there are four Pachyderm workers per\nKubernetes node, and each Pachyderm worker gets one CPU. If you want to\nreserve half the nodes in your cluster for other tasks, you might set\n'coefficient' to 0.5.",
+	"pps.ParallelismSpec.constant":                        "Starts the pipeline/job with a 'constant' workers, unless 'constant' is\nzero. If 'constant' is zero (which is the zero value of ParallelismSpec),\nthen Pachyderm will choose the number of workers that is started,\n(currently it chooses the number of workers in the cluster)",
+	"pps.PipelineInfo.budget_spend":                       "budget_spend is the pipeline's estimated spend this month. It's filled\nin by PPS.InspectPipeline.",
+	"pps.PipelineInfo.config_maps_hash":                   "config_maps_hash is the hash of the values of the transform's config map\nkeys that have 'reprocess' set, when this version of the pipeline was\ncreated. The PPS master updates the pipeline when it changes.",
+	"pps.PipelineInfo.etcd_pipeline_info":                 "etcd_pipeline_info is the pipeline's raw state in etcd (without its auth\ntoken). It's not stored in PFS--PPS.InspectPipeline only fills it in if\nInspectPipelineRequest.Full is set.",
+	"pps.PipelineInfo.image_digest":                       "image_digest is the digest that transform.image resolved to when the\npipeline was created, if its image is pinned.",
+	"pps.PipelineInfo.job_counts":                         "job_counts and last_job_state indicates the number of jobs within this\npipeline in a given state and the state of the most recently created job,\nrespectively. This is not stored in PFS along with the rest of this data\nstructure--PPS.InspectPipeline fills it in from the EtcdPipelineInfo.",
+	"pps.PipelineInfo.max_queue_size":                     "MaxQueueSize caps the number of datums a worker queues at once (1 by\ndefault). Within that cap, workers only queue datums as long as they have\nroom for their inputs.",
+	"pps.PipelineInfo.reason":                             "reason includes any error messages associated with a failed pipeline",
+	"pps.PipelineInfo.reason_code":                        "reason_code identifies the cause of a failed pipeline's failure, if it's\nknown",
+	"pps.PipelineInfo.service_autoscaling":                "service_autoscaling is the most recent scaling decision for the pipeline,\nif it's a service with autoscaling. It's filled in by\nPPS.InspectPipeline.",
+	"pps.PipelineInfo.standby_wake":                       "standby_wake is the pipeline's most recent (or current) wake from\nstandby. It's filled in by PPS.InspectPipeline.",
+	"pps.PipelineInfo.state":                              "state indicates the current state of the pipeline. This is not stored in\nPFS along with the rest of this data structure--PPS.InspectPipeline fills\nit in",
+	"pps.PipelineInfo.state_history":                      "state_history is the pipeline's most recent state transitions, oldest\nfirst. Like 'state', it's filled in by PPS.InspectPipeline.",
+	"pps.PipelineInfo.stopped":                            "same for stopped field",
+	"pps.PipelineInfo.tf_job":                             "tf_job encodes a Kubeflow TFJob spec. Pachyderm uses this to create TFJobs\nwhen running in a kubernetes cluster on which kubeflow has been installed.\nExactly one of 'tf_job' and 'transform' should be set",
+	"pps.PipelineInfos.next_page_token":                   "NextPageToken is the token of the next page of pipelines, if the request\nset PageSize. It's empty if there are no more pipelines.",
+	"pps.PipelineReasonCode":                              "PipelineReasonCode identifies the cause of a pipeline's failure, for the\nfailures that clients can act on",
+	"pps.PipelineReasonCode.PIPELINE_REASON_VERSION_SKEW": "pachd's worker image is from a different version of Pachyderm than pachd,\nso the pipeline's workers can't talk to it",
+	"pps.PipelineState.PIPELINE_FAILURE":                  "We have retried too many times and we have given up on this pipeline (or\nthe pipeline image doesn't exist)",
+	"pps.PipelineState.PIPELINE_PAUSED":                   "The pipeline has been explicitly paused by the user (the pipeline spec's\nStopped field should be true if the pipeline is in this state)",
+	"pps.PipelineState.PIPELINE_RESTARTING":               "Equivalent to STARTING (there is an EtcdPipelineInfo + commit, but no RC)\nAfter some error caused runPipeline to exit, but before the pipeline is\nre-run. This is when the exponential backoff is in effect.",
+	"pps.PipelineState.PIPELINE_RUNNING":                  "A pipeline has a spec commit and a service + RC\nThis is the normal state of a pipeline.",
+	"pps.PipelineState.PIPELINE_STANDBY":                  "The pipeline is fully functional, but there are no commits to process.",
+	"pps.PipelineState.PIPELINE_STARTING":                 "There is an EtcdPipelineInfo + spec commit, but no RC\nThis happens when a pipeline has been created but not yet picked up by a\nPPS server.",
+	"pps.PipelineStateTransition":                         "PipelineStateTransition records a change in a pipeline's state",
+	"pps.PipelineStateTransition.reason":                  "reason is the reason that the pipeline moved to 'state', if any",
+	"pps.ProcessStats.datums_checked":                     "datums_checked is how many datums were run twice by the pipeline's\ndeterminism check (see DeterminismCheck), and datums_nondeterministic is\nhow many of those produced different outputs the second time",
+	"pps.ProcessStats.tries":                              "tries and failure_class are only set in the stats of a single datum",
+	"pps.PrometheusTrigger":                               "PrometheusTrigger scales a service with the result of a Prometheus query,\nrunning one worker per 'threshold' of the result",
+	"pps.PrometheusTrigger.query":                         "query is a PromQL query whose result (summed, if it's a vector) is the\nservice's load, e.g. \"sum(rate(http_requests_total{app=\\\"serve\\\"}[1m]))\"",
+	"pps.PrometheusTrigger.server_address":                "server_address is the Prometheus server's URL, e.g.\n\"http://prometheus.monitoring:9090\"",
+	"pps.PrometheusTrigger.threshold":                     "threshold is how much of the load each worker should take on",
+	"pps.PutArtifactRequest.job":                          "job and name are only read from the first request of the stream, and\nvalue is the next piece of the artifact's content",
+	"pps.RegistryCredential.name":                         "Name is the name of the secret to create",
+	"pps.RegistryCredential.server":                       "Server is the registry's domain, e.g. \"quay.io\"",
+	"pps.ReplayDiff":                                      "ReplayDiff is a file whose content differs between a job's output and the\noutput of its replay",
+	"pps.ReplayDiff.original_hash":                        "original_hash and replay_hash are the hashes of the file in the job's\noutput commit and in the replay's. Either is empty if the file is missing\nfrom that commit.",
+	"pps.ReplayJobRequest.branch":                         "branch is the branch of the job's output repo that the replay's output\ncommit is put on, for comparing it with the job's. It defaults to\n\"replay-<job ID>\", and is moved if it already exists.",
+	"pps.ReplayJobRequest.job":                            "job is the job to replay. It must have succeeded.",
+	"pps.ReplayJobResponse.diffs":                         "diffs are the files whose content differs between the original job's\noutput and the replay's, ordered by path",
+	"pps.ReplayJobResponse.job":                           "job is the replay's job, and state and reason are where it ended up",
+	"pps.ReplayJobResponse.output_commit":                 "output_commit is the replay's output commit, which branch points at",
+	"pps.ReplayJobResponse.reproducible":                  "reproducible is true if the replay succeeded and its output is identical\nto the original job's",
+	"pps.ResourceSpec":                                    "ResourceSpec describes the amount of resources that pipeline pods should\nrequest from kubernetes, for scheduling.",
+	"pps.ResourceSpec.cpu":                                "The number of CPUs each worker needs (partial values are allowed, and\nencouraged)",
+	"pps.ResourceSpec.disk":                               "The amount of ephemeral storage each worker needs (in bytes, with allowed\nSI suffixes (M, K, G, Mi, Ki, Gi, etc).",
+	"pps.ResourceSpec.gpu":                                "The spec for GPU resources.",
+	"pps.ResourceSpec.memory":                             "The amount of memory each worker needs (in bytes, with allowed\nSI suffixes (M, K, G, Mi, Ki, Gi, etc).",
+	"pps.RotateSecretRequest.file":                        "File is the secret's new value, as a kubernetes secret in JSON. Its data\nreplaces all of the secret's old data; its other fields are ignored.",
+	"pps.RunRecord":                                       "RunRecord is what's published about a finished job to its pipeline's\nexperiment tracker (see ExperimentTracking)",
+	"pps.RunRecord.artifacts":                             "artifacts maps the name of each of the job's artifacts to its link,\n\"pachyderm://job/<job>/artifact/<name>\", which is downloaded with\n'pachctl get artifact <job> <name>'",
+	"pps.RunRecord.metrics":                               "metrics are read from the job's metrics_artifact",
+	"pps.RunRecord.params":                                "params are the pipeline's transform.env, along with its image\n(\"pachyderm.image\")",
+	"pps.SQLDatabaseEgress":                               "SQLDatabaseEgress loads the files under /<table>/ in an output commit into\nthe database table <table>. Each table is loaded at most once per commit.",
+	"pps.SQLDatabaseEgress.FileFormat.columns":            "columns are the names of the columns in a CSV file, in order. If unset,\neach CSV file's first line must be a header that names its columns.",
+	"pps.SQLDatabaseEgress.Secret.key":                    "key is the key in the secret that holds the database's password (or,\nfor BigQuery, the JSON credentials of a service account)",
+	"pps.SQLDatabaseEgress.Secret.name":                   "name is the name of the kubernetes secret",
+	"pps.SQLDatabaseEgress.url":                           "url is the location of the database, without its password, e.g.\n\"postgres://user@host:5432/db\", \"mysql://user@host:3306/db\",\n\"snowflake://user@account/db/schema?warehouse=wh\" or\n\"bigquery://project/dataset\"",
+	"pps.Sandbox":                                         "Sandbox restricts what a pipeline's user code can do, for clusters that run\npipelines from untrusted images. User code in a sandbox runs as an\nunprivileged user ('user', if that isn't root, and \"nobody\" otherwise),\ncan't gain privileges (e.g. through setuid binaries) and can't reach the\nnode's docker socket, and the worker keeps only the capabilities that it\nneeds to run user code.",
+	"pps.Sandbox.apparmor_profile":                        "apparmor_profile is the AppArmor profile of the worker container:\n\"runtime/default\" (the default), \"localhost/<profile>\" for a profile\nloaded on the cluster's nodes, or \"unconfined\"",
+	"pps.Sandbox.seccomp":                                 "seccomp is the seccomp filter applied to the user code",
+	"pps.SchedulingSpec.arch":                             "arch is the CPU architecture of the nodes that the pipeline's workers run\non, e.g. \"amd64\" or \"arm64\". If unset, workers may run on any node.",
+	"pps.SchedulingSpec.os":                               "os is the operating system of the nodes that the pipeline's workers run\non, either \"linux\" (the default) or \"windows\".",
+	"pps.ScratchVolume":                                   "ScratchVolume is the volume in which a pipeline's workers store the datums\nthat they're processing, including the user code's output in /pfs/out.\nWithout one, datums are stored in the user container's filesystem.",
+	"pps.ScratchVolume.capacity":                          "capacity is the size of the volume, e.g. \"100Gi\". Workers request this\nmuch ephemeral storage, and k8s evicts workers whose volume grows larger.",
+	"pps.ScratchVolume.local_ssd_path":                    "local_ssd_path, if set, is a directory on each node (e.g. the mount point\nof a local SSD) in which workers store datums, instead of an emptyDir.\nEach worker uses its own subdirectory.",
+	"pps.SeccompProfile":                                  "SeccompProfile is a seccomp filter that can be applied to sandboxed user\ncode",
+	"pps.SeccompProfile.SECCOMP_PACHYDERM":                "Pachyderm's filter, which blocks the syscalls that are used to escape\ncontainers or that change the state of the whole node (e.g. mount,\nptrace, kexec_load and init_module)",
+	"pps.SeccompProfile.SECCOMP_UNCONFINED":               "No filter, other than the container runtime's",
+	"pps.SecretInfo.rotated":                              "Rotated is when the secret's data was last replaced by RotateSecret, if\never",
+	"pps.SecretMount.key":                                 "Key of the secret to load into env_var, this field only has meaning if EnvVar != \"\".",
+	"pps.SecretMount.name":                                "Name must be the name of the secret in kubernetes.",
+	"pps.Service.autoscaling":                             "autoscaling, if set, scales the service's workers with the requests that\nthey serve, instead of running a single worker",
+	"pps.ServiceAutoscaling":                              "ServiceAutoscaling scales a service pipeline's workers between min_replicas\nand max_replicas. Every 'interval', the PPS master measures the service's\ntraffic, either by scraping its workers' user code for Prometheus metrics\nor (like a KEDA prometheus trigger) with a Prometheus query, and runs\nenough workers to meet each of the targets that are set.",
+	"pps.ServiceAutoscaling.interval":                     "interval is how often the traffic is measured (default 15s)",
+	"pps.ServiceAutoscaling.latency_metric":               "latency_metric is the histogram (or summary) of how long requests take,\nwhose _sum and _count series give their mean latency (default\n\"http_request_duration_seconds\")",
+	"pps.ServiceAutoscaling.max_replicas":                 "max_replicas is the most workers that the service runs",
+	"pps.ServiceAutoscaling.metrics_port":                 "metrics_port and metrics_path are where the workers' user code serves\nPrometheus metrics (default: the service's internal_port and \"/metrics\")",
+	"pps.ServiceAutoscaling.min_replicas":                 "min_replicas is the fewest workers that the service runs (default 1)",
+	"pps.ServiceAutoscaling.prometheus":                   "prometheus, if set, measures the traffic with a Prometheus query instead\nof by scraping the workers",
+	"pps.ServiceAutoscaling.requests_metric":              "requests_metric is the counter of requests that the user code has served\n(default \"http_requests_total\"). Its series are summed.",
+	"pps.ServiceAutoscaling.scale_down_delay":             "scale_down_delay is how long the traffic must stay low before workers are\nremoved, so that bursty traffic doesn't make the service flap (default\n5m). Workers are added immediately.",
+	"pps.ServiceAutoscaling.target_latency":               "target_latency is the mean time that requests should take, measured with\n'latency_metric'. The service is scaled up in proportion to how far\nrequests exceed it.",
+	"pps.ServiceAutoscaling.target_requests_per_second":   "target_requests_per_second is the rate of requests that each worker\nshould serve, measured with 'requests_metric'",
+	"pps.ServiceAutoscalingStatus":                        "ServiceAutoscalingStatus is the most recent scaling decision for a service\npipeline with autoscaling",
+	"pps.ServiceAutoscalingStatus.replicas":               "replicas is the number of workers that the service should run",
+	"pps.ServiceAutoscalingStatus.requests_per_second":    "requests_per_second, latency and trigger_value are the measurements that\n'replicas' was chosen from (those that the service is scaled on)",
+	"pps.ServiceAutoscalingStatus.updated":                "updated is when 'replicas' last changed",
+	"pps.Spill":                                           "Spill directs a pipeline's intermediate artifacts (the hashtrees and stats\nof individual datums, which are only read while merging a job's output and\nwhen skipping datums in later jobs) to a separate object store location, so\nthat they can have their own lifecycle policy.",
+	"pps.Spill.URL":                                       "URL is an object store URL, e.g. \"s3://bucket/prefix\", in the same format\nas Egress.URL",
+	"pps.Spout.kafka":                                     "kafka, if set, makes Pachyderm consume a Kafka topic and commit its\nmessages to the spout's output repo, instead of running the pipeline's\ntransform",
+	"pps.StandbyWake":                                     "StandbyWake records how long a standby pipeline took to wake up and start\nprocessing a job, phase by phase",
+	"pps.StandbyWake.alarm":                               "alarm is the pipeline's standby_wake_alarm when the job started",
+	"pps.StandbyWake.alarm_exceeded":                      "alarm_exceeded is true if the pipeline took longer than 'alarm' to wake\nup, from commit_seen to first_datum_started",
+	"pps.StandbyWake.commit_seen":                         "commit_seen is when the PPS master saw a new output commit, and moved the\npipeline out of standby",
+	"pps.StandbyWake.first_datum_started":                 "first_datum_started is when the job's datums became available to the\nworkers, i.e. when its first datum could start. It's unset until then.",
+	"pps.StandbyWake.job":                                 "job is the job that the pipeline woke up to process",
+	"pps.StandbyWake.workers_ready":                       "workers_ready is when one of the pipeline's workers became its master,\ni.e. when the first worker was running",
+	"pps.StandbyWake.workers_scaled":                      "workers_scaled is when the PPS master scaled up the pipeline's workers",
+	"pps.StuckBranch":                                     "StuckBranch is a branch whose head commit has been unfinished for longer\nthan the threshold of a ListStuckBranchesRequest.",
+	"pps.StuckBranch.blockers":                            "Blockers are the unfinished commits that the branch is waiting on: either\nits own head, or the unfinished commits upstream of it whose provenance is\nfinished.",
+	"pps.TFJob.tf_job":                                    "tf_job  is a serialized Kubeflow TFJob spec. Pachyderm sends this directly\nto a kubernetes cluster on which kubeflow has been installed, instead of\ncreating a pipeline ReplicationController as it normally would.",
+	"pps.TimeoutPolicy":                                   "TimeoutPolicy controls what happens when a pipeline's datum_timeout or\njob_timeout fires",
+	"pps.TimeoutPolicy.TIMEOUT_FAIL_JOB":                  "Fail the job. This is the default.",
+	"pps.TimeoutPolicy.TIMEOUT_PARTIAL_SUCCESS":           "Like TIMEOUT_SKIP_DATUM, but a job that leaves out any datums finishes in\nthe state JOB_PARTIAL_SUCCESS. A job that reaches job_timeout stops\nprocessing datums, and commits the output of those that it has already\nprocessed rather than failing.",
+	"pps.TimeoutPolicy.TIMEOUT_SKIP_DATUM":                "Count a datum that times out as failed, leave it out of the job's\noutput, and keep processing the job's other datums, which the job commits\nif they succeed. Datums that time out aren't retried, but the pipeline's\nnext job processes them again. A job that reaches job_timeout still\nfails.",
+	"pps.Toleration":                                      "Toleration allows a pipeline's workers to be scheduled on nodes with a\nmatching taint. See the kubernetes docs on taints and tolerations.",
+	"pps.Transform.accept_return_code":                    "accept_return_code is a list of exit codes, other than 0, that are\nconsidered a success",
+	"pps.Transform.cmd":                                   "cmd is the command that's run for each datum (or chunk of datums), e.g.\n[\"python3\", \"/my_code.py\"]. If unset, the image's entrypoint is used.",
+	"pps.Transform.config_maps":                           "config_maps are config map keys that are exposed to the user code as\nenv vars (see ConfigMapKey)",
+	"pps.Transform.debug":                                 "debug, if true, enables debug logging in the pipeline's workers",
+	"pps.Transform.dockerfile":                            "dockerfile is the path of the Dockerfile that 'pachctl create pipeline\n--build' builds 'image' from",
+	"pps.Transform.env":                                   "env is a map of environment variables that are set in the container",
+	"pps.Transform.err_cmd":                               "err_cmd, if set, is run for each datum that fails (after its retries),\ninstead of failing the job. If it exits with 0, the datum is recovered.",
+	"pps.Transform.err_stdin":                             "err_stdin is an array of lines that are written to err_cmd's stdin",
+	"pps.Transform.image":                                 "image is the docker image that the pipeline's code runs in",
+	"pps.Transform.image_pinning":                         "image_pinning controls whether 'image's tag is resolved to a digest\nwhen the pipeline is created (see ImagePinning)",
+	"pps.Transform.image_pull_secrets":                    "image_pull_secrets are the names of kubernetes secrets used to pull\n'image' from a private registry",
+	"pps.Transform.sandbox":                               "sandbox, if set, runs cmd and err_cmd with reduced privileges (see\nSandbox)",
+	"pps.Transform.secrets":                               "secrets are kubernetes secrets that are mounted into the container or\nexposed as environment variables",
+	"pps.Transform.stdin":                                 "stdin is an array of lines that are written to cmd's stdin",
+	"pps.Transform.user":                                  "user is the user that cmd runs as. If unset, the image's user is used.",
+	"pps.Transform.working_dir":                           "working_dir is the directory that cmd runs in. If unset, the image's\nworking directory is used.",
+	"pps.WorkerStatus.credits":                            "Credits is the number of bytes of datum input that the worker can still\nqueue, based on its memory and disk headroom.",
+	"pps.WorkerStatus.started":                            "Started is the time processing on the current datum began.",
+}
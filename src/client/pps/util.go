@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/gogo/protobuf/types"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
 
 	"gopkg.in/src-d/go-git.v4"
@@ -23,6 +25,10 @@ func VisitInput(input *Input, f func(*Input)) {
 		for _, input := range input.Join {
 			VisitInput(input, f)
 		}
+	case input.Group != nil:
+		for _, input := range input.Group {
+			VisitInput(input, f)
+		}
 	case input.Union != nil:
 		for _, input := range input.Union {
 			VisitInput(input, f)
@@ -46,6 +52,10 @@ func InputName(input *Input) string {
 		if len(input.Join) > 0 {
 			return InputName(input.Join[0])
 		}
+	case input.Group != nil:
+		if len(input.Group) > 0 {
+			return InputName(input.Group[0])
+		}
 	case input.Union != nil:
 		if len(input.Union) > 0 {
 			return InputName(input.Union[0])
@@ -65,6 +75,8 @@ func SortInput(input *Input) {
 			SortInputs(input.Cross)
 		case input.Join != nil:
 			SortInputs(input.Join)
+		case input.Group != nil:
+			SortInputs(input.Group)
 		case input.Union != nil:
 			SortInputs(input.Union)
 		}
@@ -97,6 +109,65 @@ func InputBranches(input *Input) []*pfs.Branch {
 	return result
 }
 
+// ContainsS3Inputs returns true if any of the PFS inputs in 'input' are S3
+// inputs, which are read through the job's S3 gateway
+func ContainsS3Inputs(input *Input) bool {
+	var result bool
+	VisitInput(input, func(input *Input) {
+		if input.Pfs != nil && input.Pfs.S3 {
+			result = true
+		}
+	})
+	return result
+}
+
+// StandbyWakeLatency returns how long the pipeline took to wake from standby
+// in 'wake', from seeing the commit to starting the job's datums, or 0 if it
+// hasn't started them yet
+func StandbyWakeLatency(wake *StandbyWake) time.Duration {
+	if wake == nil || wake.CommitSeen == nil || wake.FirstDatumStarted == nil {
+		return 0
+	}
+	commitSeen, err := types.TimestampFromProto(wake.CommitSeen)
+	if err != nil {
+		return 0
+	}
+	started, err := types.TimestampFromProto(wake.FirstDatumStarted)
+	if err != nil {
+		return 0
+	}
+	return started.Sub(commitSeen)
+}
+
+// StandbyWakePhases describes how long each phase of 'wake' took, for the
+// phases that have finished, e.g. "starting workers took 1m30s"
+func StandbyWakePhases(wake *StandbyWake) []string {
+	phases := []struct {
+		name     string
+		from, to *types.Timestamp
+	}{
+		{"scaling up workers", wake.CommitSeen, wake.WorkersScaled},
+		{"starting workers", wake.WorkersScaled, wake.WorkersReady},
+		{"planning the job", wake.WorkersReady, wake.FirstDatumStarted},
+	}
+	var result []string
+	for _, phase := range phases {
+		if phase.from == nil || phase.to == nil {
+			continue
+		}
+		from, err := types.TimestampFromProto(phase.from)
+		if err != nil {
+			continue
+		}
+		to, err := types.TimestampFromProto(phase.to)
+		if err != nil {
+			continue
+		}
+		result = append(result, fmt.Sprintf("%s took %v", phase.name, to.Sub(from)))
+	}
+	return result
+}
+
 // ValidateGitCloneURL returns an error if the provided URL is invalid
 func ValidateGitCloneURL(url string) error {
 	exampleURL := "https://github.com/org/foo.git"
@@ -9,6 +9,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/debug"
 	"github.com/pachyderm/pachyderm/src/client/enterprise"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errcode"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
 	"github.com/pachyderm/pachyderm/src/client/pps"
 	"github.com/pachyderm/pachyderm/src/client/transaction"
@@ -332,6 +333,12 @@ func (c *pfsBuilderClient) ListCommit(ctx context.Context, req *pfs.ListCommitRe
 func (c *pfsBuilderClient) ListCommitStream(ctx context.Context, req *pfs.ListCommitRequest, opts ...grpc.CallOption) (pfs.API_ListCommitStreamClient, error) {
 	return nil, unsupportedError("ListCommitStream")
 }
+func (c *pfsBuilderClient) WatchCommit(ctx context.Context, req *pfs.ListCommitRequest, opts ...grpc.CallOption) (pfs.API_WatchCommitClient, error) {
+	return nil, unsupportedError("WatchCommit")
+}
+func (c *pfsBuilderClient) WatchBranch(ctx context.Context, req *pfs.ListBranchRequest, opts ...grpc.CallOption) (pfs.API_WatchBranchClient, error) {
+	return nil, unsupportedError("WatchBranch")
+}
 func (c *pfsBuilderClient) FlushCommit(ctx context.Context, req *pfs.FlushCommitRequest, opts ...grpc.CallOption) (pfs.API_FlushCommitClient, error) {
 	return nil, unsupportedError("FlushCommit")
 }
@@ -350,6 +357,21 @@ func (c *pfsBuilderClient) ListBranch(ctx context.Context, req *pfs.ListBranchRe
 func (c *pfsBuilderClient) PutFile(ctx context.Context, opts ...grpc.CallOption) (pfs.API_PutFileClient, error) {
 	return nil, unsupportedError("PutFile")
 }
+func (c *pfsBuilderClient) StartUpload(ctx context.Context, req *pfs.StartUploadRequest, opts ...grpc.CallOption) (*pfs.Upload, error) {
+	return nil, unsupportedError("StartUpload")
+}
+func (c *pfsBuilderClient) UploadChunk(ctx context.Context, opts ...grpc.CallOption) (pfs.API_UploadChunkClient, error) {
+	return nil, unsupportedError("UploadChunk")
+}
+func (c *pfsBuilderClient) InspectUpload(ctx context.Context, req *pfs.InspectUploadRequest, opts ...grpc.CallOption) (*pfs.UploadInfo, error) {
+	return nil, unsupportedError("InspectUpload")
+}
+func (c *pfsBuilderClient) FinishUpload(ctx context.Context, req *pfs.FinishUploadRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("FinishUpload")
+}
+func (c *pfsBuilderClient) DeleteUpload(ctx context.Context, req *pfs.DeleteUploadRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("DeleteUpload")
+}
 func (c *pfsBuilderClient) CopyFile(ctx context.Context, req *pfs.CopyFileRequest, opts ...grpc.CallOption) (*types.Empty, error) {
 	return nil, unsupportedError("CopyFile")
 }
@@ -380,6 +402,9 @@ func (c *pfsBuilderClient) DiffFile(ctx context.Context, req *pfs.DiffFileReques
 func (c *pfsBuilderClient) DeleteFile(ctx context.Context, req *pfs.DeleteFileRequest, opts ...grpc.CallOption) (*types.Empty, error) {
 	return nil, unsupportedError("DeleteFile")
 }
+func (c *pfsBuilderClient) DeleteFiles(ctx context.Context, req *pfs.DeleteFilesRequest, opts ...grpc.CallOption) (*errcode.BulkResponse, error) {
+	return nil, unsupportedError("DeleteFiles")
+}
 func (c *pfsBuilderClient) DeleteAll(ctx context.Context, req *types.Empty, opts ...grpc.CallOption) (*types.Empty, error) {
 	return nil, unsupportedError("DeleteAll")
 }
@@ -392,6 +417,31 @@ func (c *pfsBuilderClient) PutTar(ctx context.Context, opts ...grpc.CallOption)
 func (c *pfsBuilderClient) GetTar(ctx context.Context, req *pfs.GetTarRequest, opts ...grpc.CallOption) (pfs.API_GetTarClient, error) {
 	return nil, unsupportedError("GetTar")
 }
+func (c *pfsBuilderClient) SetBranchRetention(ctx context.Context, req *pfs.SetBranchRetentionRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("SetBranchRetention")
+}
+
+func (c *pfsBuilderClient) SetBranchTrigger(ctx context.Context, req *pfs.SetBranchTriggerRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("SetBranchTrigger")
+}
+func (c *pfsBuilderClient) CreateModelVersion(ctx context.Context, req *pfs.CreateModelVersionRequest, opts ...grpc.CallOption) (*pfs.ModelVersion, error) {
+	return nil, unsupportedError("CreateModelVersion")
+}
+func (c *pfsBuilderClient) PromoteModel(ctx context.Context, req *pfs.PromoteModelRequest, opts ...grpc.CallOption) (*pfs.ModelVersion, error) {
+	return nil, unsupportedError("PromoteModel")
+}
+func (c *pfsBuilderClient) InspectModel(ctx context.Context, req *pfs.InspectModelRequest, opts ...grpc.CallOption) (*pfs.ModelInfo, error) {
+	return nil, unsupportedError("InspectModel")
+}
+func (c *pfsBuilderClient) ResolveModel(ctx context.Context, req *pfs.ResolveModelRequest, opts ...grpc.CallOption) (*pfs.ModelVersion, error) {
+	return nil, unsupportedError("ResolveModel")
+}
+func (c *pfsBuilderClient) GetStorageUsage(ctx context.Context, req *pfs.GetStorageUsageRequest, opts ...grpc.CallOption) (*pfs.StorageUsage, error) {
+	return nil, unsupportedError("GetStorageUsage")
+}
+func (c *pfsBuilderClient) DiffFileStream(ctx context.Context, req *pfs.DiffFileRequest, opts ...grpc.CallOption) (pfs.API_DiffFileStreamClient, error) {
+	return nil, unsupportedError("DiffFileStream")
+}
 
 func (c *objectBuilderClient) PutObject(ctx context.Context, opts ...grpc.CallOption) (pfs.ObjectAPI_PutObjectClient, error) {
 	return nil, unsupportedError("PutObject")
@@ -490,6 +540,9 @@ func (c *ppsBuilderClient) RestartDatum(ctx context.Context, req *pps.RestartDat
 func (c *ppsBuilderClient) CreatePipeline(ctx context.Context, req *pps.CreatePipelineRequest, opts ...grpc.CallOption) (*types.Empty, error) {
 	return nil, unsupportedError("CreatePipeline")
 }
+func (c *ppsBuilderClient) CreatePipelines(ctx context.Context, req *pps.CreatePipelinesRequest, opts ...grpc.CallOption) (*errcode.BulkResponse, error) {
+	return nil, unsupportedError("CreatePipelines")
+}
 func (c *ppsBuilderClient) InspectPipeline(ctx context.Context, req *pps.InspectPipelineRequest, opts ...grpc.CallOption) (*pps.PipelineInfo, error) {
 	return nil, unsupportedError("InspectPipeline")
 }
@@ -517,6 +570,9 @@ func (c *ppsBuilderClient) GetLogs(ctx context.Context, req *pps.GetLogsRequest,
 func (c *ppsBuilderClient) GarbageCollect(ctx context.Context, req *pps.GarbageCollectRequest, opts ...grpc.CallOption) (*pps.GarbageCollectResponse, error) {
 	return nil, unsupportedError("GarbageCollect")
 }
+func (c *ppsBuilderClient) InspectGarbageCollect(ctx context.Context, req *pps.InspectGarbageCollectRequest, opts ...grpc.CallOption) (*pps.GarbageCollectInfo, error) {
+	return nil, unsupportedError("InspectGarbageCollect")
+}
 func (c *ppsBuilderClient) ActivateAuth(ctx context.Context, req *pps.ActivateAuthRequest, opts ...grpc.CallOption) (*pps.ActivateAuthResponse, error) {
 	return nil, unsupportedError("ActivateAuth")
 }
@@ -535,6 +591,47 @@ func (c *ppsBuilderClient) InspectSecret(ctx context.Context, req *pps.InspectSe
 func (c *ppsBuilderClient) ListSecret(ctx context.Context, in *types.Empty, opt ...grpc.CallOption) (*pps.SecretInfos, error) {
 	return nil, unsupportedError("ListSecret")
 }
+func (c *ppsBuilderClient) InstantiateTemplate(ctx context.Context, req *pps.InstantiateTemplateRequest, opts ...grpc.CallOption) (*pps.InstantiateTemplateResponse, error) {
+	return nil, unsupportedError("InstantiateTemplate")
+}
+func (c *ppsBuilderClient) JobProgress(ctx context.Context, req *pps.JobProgressRequest, opts ...grpc.CallOption) (pps.API_JobProgressClient, error) {
+	return nil, unsupportedError("JobProgress")
+}
+func (c *ppsBuilderClient) WatchJob(ctx context.Context, req *pps.ListJobRequest, opts ...grpc.CallOption) (pps.API_WatchJobClient, error) {
+	return nil, unsupportedError("WatchJob")
+}
+func (c *ppsBuilderClient) WatchPipeline(ctx context.Context, req *pps.ListPipelineRequest, opts ...grpc.CallOption) (pps.API_WatchPipelineClient, error) {
+	return nil, unsupportedError("WatchPipeline")
+}
+func (c *ppsBuilderClient) ListNames(ctx context.Context, req *pps.ListNamesRequest, opts ...grpc.CallOption) (*pps.ListNamesResponse, error) {
+	return nil, unsupportedError("ListNames")
+}
+func (c *ppsBuilderClient) RotateSecret(ctx context.Context, req *pps.RotateSecretRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("RotateSecret")
+}
+func (c *ppsBuilderClient) ListStuckBranches(ctx context.Context, req *pps.ListStuckBranchesRequest, opts ...grpc.CallOption) (*pps.StuckBranches, error) {
+	return nil, unsupportedError("ListStuckBranches")
+}
+
+func (c *ppsBuilderClient) Diagnose(ctx context.Context, req *pps.DiagnoseRequest, opts ...grpc.CallOption) (*pps.Diagnosis, error) {
+	return nil, unsupportedError("Diagnose")
+}
+
+func (c *ppsBuilderClient) ReplayJob(ctx context.Context, req *pps.ReplayJobRequest, opts ...grpc.CallOption) (*pps.ReplayJobResponse, error) {
+	return nil, unsupportedError("ReplayJob")
+}
+
+func (c *ppsBuilderClient) PutArtifact(ctx context.Context, opts ...grpc.CallOption) (pps.API_PutArtifactClient, error) {
+	return nil, unsupportedError("PutArtifact")
+}
+
+func (c *ppsBuilderClient) GetArtifact(ctx context.Context, req *pps.GetArtifactRequest, opts ...grpc.CallOption) (pps.API_GetArtifactClient, error) {
+	return nil, unsupportedError("GetArtifact")
+}
+
+func (c *ppsBuilderClient) InspectImpact(ctx context.Context, req *pps.InspectImpactRequest, opts ...grpc.CallOption) (*pps.Impact, error) {
+	return nil, unsupportedError("InspectImpact")
+}
 
 func (c *authBuilderClient) Activate(ctx context.Context, req *auth.ActivateRequest, opts ...grpc.CallOption) (*auth.ActivateResponse, error) {
 	return nil, unsupportedError("Activate")
@@ -599,6 +696,12 @@ func (c *authBuilderClient) GetUsers(ctx context.Context, req *auth.GetUsersRequ
 func (c *authBuilderClient) GetOneTimePassword(ctx context.Context, req *auth.GetOneTimePasswordRequest, opts ...grpc.CallOption) (*auth.GetOneTimePasswordResponse, error) {
 	return nil, unsupportedError("GetOneTimePassword")
 }
+func (c *authBuilderClient) GetOIDCLogin(ctx context.Context, req *auth.GetOIDCLoginRequest, opts ...grpc.CallOption) (*auth.GetOIDCLoginResponse, error) {
+	return nil, unsupportedError("GetOIDCLogin")
+}
+func (c *authBuilderClient) ListAuditEvents(ctx context.Context, req *auth.ListAuditEventsRequest, opts ...grpc.CallOption) (auth.API_ListAuditEventsClient, error) {
+	return nil, unsupportedError("ListAuditEvents")
+}
 
 func (c *enterpriseBuilderClient) Activate(ctx context.Context, req *enterprise.ActivateRequest, opts ...grpc.CallOption) (*enterprise.ActivateResponse, error) {
 	return nil, unsupportedError("Activate")
@@ -613,6 +716,9 @@ func (c *enterpriseBuilderClient) Deactivate(ctx context.Context, req *enterpris
 func (c *versionBuilderClient) GetVersion(ctx context.Context, req *types.Empty, opts ...grpc.CallOption) (*versionpb.Version, error) {
 	return nil, unsupportedError("GetVersion")
 }
+func (c *versionBuilderClient) Negotiate(ctx context.Context, req *versionpb.NegotiateRequest, opts ...grpc.CallOption) (*versionpb.NegotiateResponse, error) {
+	return nil, unsupportedError("Negotiate")
+}
 
 func (c *adminBuilderClient) Extract(ctx context.Context, req *admin.ExtractRequest, opts ...grpc.CallOption) (admin.API_ExtractClient, error) {
 	return nil, unsupportedError("Extract")
@@ -652,9 +758,15 @@ func (c *transactionBuilderClient) DeleteAll(ctx context.Context, req *transacti
 func (c *debugBuilderClient) Dump(ctx context.Context, req *debug.DumpRequest, opts ...grpc.CallOption) (debug.Debug_DumpClient, error) {
 	return nil, unsupportedError("Dump")
 }
+func (c *debugBuilderClient) DumpArchive(ctx context.Context, req *debug.DumpArchiveRequest, opts ...grpc.CallOption) (debug.Debug_DumpArchiveClient, error) {
+	return nil, unsupportedError("DumpArchive")
+}
 func (c *debugBuilderClient) Profile(ctx context.Context, req *debug.ProfileRequest, opts ...grpc.CallOption) (debug.Debug_ProfileClient, error) {
 	return nil, unsupportedError("Profile")
 }
 func (c *debugBuilderClient) Binary(ctx context.Context, req *debug.BinaryRequest, opts ...grpc.CallOption) (debug.Debug_BinaryClient, error) {
 	return nil, unsupportedError("Binary")
 }
+func (c *debugBuilderClient) Masters(ctx context.Context, req *debug.MastersRequest, opts ...grpc.CallOption) (*debug.MastersResponse, error) {
+	return nil, unsupportedError("Masters")
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errcode"
+	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/client/version"
+	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UploadChunkSize is the amount of data that UploadFile sends in each chunk.
+// An upload that's interrupted resumes from the start of the chunk that was
+// being sent, so at most this much data is sent twice.
+const UploadChunkSize = 64 * 1024 * 1024
+
+// StartUpload starts an upload session for repo@commitID:path, which is
+// sent in chunks (see UploadChunk and UploadFile) and put by FinishUpload.
+// If overwrite is true, the upload replaces the file's existing content
+// rather than being appended to it.
+func (c APIClient) StartUpload(repoName string, commitID string, path string, overwrite bool) (*pfs.Upload, error) {
+	upload, err := c.PfsAPIClient.StartUpload(
+		c.Ctx(),
+		&pfs.StartUploadRequest{
+			File:      NewFile(repoName, commitID, path),
+			Overwrite: overwrite,
+		},
+	)
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureResumableUpload, err)
+	}
+	return upload, nil
+}
+
+// UploadChunk sends the contents of 'r' as the chunk of 'upload' that starts
+// at 'offset', which must be the number of bytes uploaded so far. The chunk
+// is only added to the upload if all of it is received.
+func (c APIClient) UploadChunk(upload *pfs.Upload, offset uint64, r io.Reader) (*pfs.UploadInfo, error) {
+	uploadChunkClient, err := c.PfsAPIClient.UploadChunk(c.Ctx())
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureResumableUpload, err)
+	}
+	buf := grpcutil.GetBuffer()
+	defer grpcutil.PutBuffer(buf)
+	req := &pfs.UploadChunkRequest{
+		Upload: upload,
+		Offset: offset,
+	}
+	sent := false // whether the first request (with upload and offset) was sent
+	for {
+		n, err := r.Read(buf)
+		if n > 0 || (!sent && err == io.EOF) {
+			req.Value = buf[:n]
+			if err := uploadChunkClient.Send(req); err != nil {
+				if err == io.EOF {
+					// pachd ended the call; its error is returned by
+					// CloseAndRecv
+					break
+				}
+				return nil, grpcutil.ScrubGRPC(err)
+			}
+			sent = true
+			req = &pfs.UploadChunkRequest{}
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			uploadChunkClient.CloseSend()
+			return nil, err
+		}
+	}
+	uploadInfo, err := uploadChunkClient.CloseAndRecv()
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureResumableUpload, err)
+	}
+	return uploadInfo, nil
+}
+
+// InspectUpload returns how much of 'upload' has been received.
+func (c APIClient) InspectUpload(upload *pfs.Upload) (*pfs.UploadInfo, error) {
+	uploadInfo, err := c.PfsAPIClient.InspectUpload(
+		c.Ctx(),
+		&pfs.InspectUploadRequest{
+			Upload: upload,
+		},
+	)
+	if err != nil {
+		return nil, c.scrubFeatureGRPC(version.FeatureResumableUpload, err)
+	}
+	return uploadInfo, nil
+}
+
+// FinishUpload puts 'uploads' into their files and ends their sessions.
+// Uploads into the same branch are put in a single commit.
+func (c APIClient) FinishUpload(uploads ...*pfs.Upload) error {
+	_, err := c.PfsAPIClient.FinishUpload(
+		c.Ctx(),
+		&pfs.FinishUploadRequest{
+			Uploads: uploads,
+		},
+	)
+	return c.scrubFeatureGRPC(version.FeatureResumableUpload, err)
+}
+
+// DeleteUpload ends 'upload' without putting its file.
+func (c APIClient) DeleteUpload(upload *pfs.Upload) error {
+	_, err := c.PfsAPIClient.DeleteUpload(
+		c.Ctx(),
+		&pfs.DeleteUploadRequest{
+			Upload: upload,
+		},
+	)
+	return c.scrubFeatureGRPC(version.FeatureResumableUpload, err)
+}
+
+// UploadFile sends the first 'size' bytes of 'r' to 'upload', in chunks of
+// UploadChunkSize. It starts from however much of the upload pachd has
+// already received, so it can be called again to resume an upload that
+// failed. Chunks that fail because of network errors are retried with
+// backoff. 'progress', if non-nil, is called with the number of bytes
+// uploaded so far after each chunk.
+func (c APIClient) UploadFile(upload *pfs.Upload, r io.ReaderAt, size int64, progress func(uint64)) error {
+	uploadInfo, err := c.InspectUpload(upload)
+	if err != nil {
+		return err
+	}
+	offset := uploadInfo.SizeBytes
+	for {
+		if offset > uint64(size) {
+			return fmt.Errorf("upload %s has %d bytes, but the file being uploaded only has %d", upload.Id, offset, size)
+		}
+		if progress != nil {
+			progress(offset)
+		}
+		if offset == uint64(size) {
+			return nil
+		}
+		if err := backoff.RetryNotify(func() error {
+			n := uint64(size) - offset
+			if n > UploadChunkSize {
+				n = UploadChunkSize
+			}
+			uploadInfo, err := c.UploadChunk(upload, offset, io.NewSectionReader(r, int64(offset), int64(n)))
+			if err != nil {
+				return err
+			}
+			offset = uploadInfo.SizeBytes
+			return nil
+		}, backoff.NewExponentialBackOff(), func(err error, d time.Duration) error {
+			if !isRetryableUploadErr(err) {
+				return err
+			}
+			// pachd may have added the chunk even though the call failed, so
+			// resume from wherever the upload ends
+			if uploadInfo, err := c.InspectUpload(upload); err == nil {
+				offset = uploadInfo.SizeBytes
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// isRetryableUploadErr returns true if 'err' is a network error, or if the
+// chunk didn't start where the upload ends (which happens when a chunk is
+// received by pachd, but the response is lost)
+func isRetryableUploadErr(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.Internal:
+		return true
+	}
+	return errcode.Of(err) == errcode.Conflict
+}
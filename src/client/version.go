@@ -1,9 +1,15 @@
 package client
 
 import (
+	"fmt"
+
 	"github.com/gogo/protobuf/types"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/client/pps"
 	"github.com/pachyderm/pachyderm/src/client/version"
+	"github.com/pachyderm/pachyderm/src/client/version/versionpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Version returns the version of pachd as a string.
@@ -14,3 +20,166 @@ func (c APIClient) Version() (string, error) {
 	}
 	return version.PrettyPrintVersion(v), nil
 }
+
+// Negotiate tells pachd which version of the client this is and which API
+// features it supports, and returns the features and deprecations that pachd
+// reports in response. Versions of pachd that predate negotiation get a
+// response with only their version (and no features) and a warning.
+func (c APIClient) Negotiate() (*versionpb.NegotiateResponse, error) {
+	response, err := c.VersionAPIClient.Negotiate(c.Ctx(), &versionpb.NegotiateRequest{
+		ClientVersion: version.Version,
+		Features:      version.Features,
+	})
+	if err == nil {
+		return response, nil
+	}
+	if status.Code(err) != codes.Unimplemented {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	v, err := c.VersionAPIClient.GetVersion(c.Ctx(), &types.Empty{})
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return &versionpb.NegotiateResponse{
+		ServerVersion: v,
+		Warnings: []string{fmt.Sprintf(
+			"pachd version %s is older than client version %s, and may not support all of its features",
+			version.PrettyPrintVersion(v), version.PrettyPrintVersion(version.Version))},
+	}, nil
+}
+
+// RequireFeatures returns an error naming the first of 'features' that pachd
+// doesn't support, so that requests that depend on them can fail with an
+// explanation rather than being (partially) ignored by older versions of
+// pachd.
+func (c APIClient) RequireFeatures(features ...string) error {
+	if len(features) == 0 {
+		return nil
+	}
+	response, err := c.Negotiate()
+	if err != nil {
+		return err
+	}
+	for _, feature := range features {
+		if !version.Supports(response, feature) {
+			return unsupportedFeatureError(response.ServerVersion, feature)
+		}
+	}
+	return nil
+}
+
+// PipelineFeatures returns the API features that 'request' depends on,
+// which older versions of pachd would silently ignore
+func PipelineFeatures(request *pps.CreatePipelineRequest) []string {
+	var features []string
+	if request.Egress != nil && request.Egress.SQLDatabase != nil {
+		features = append(features, version.FeatureSQLEgress)
+	}
+	if request.Egress != nil && request.Egress.Kafka != nil {
+		features = append(features, version.FeatureKafkaEgress)
+	}
+	if request.StandbyGracePeriod != nil {
+		features = append(features, version.FeatureStandbyGracePeriod)
+	}
+	if request.Metadata != nil && len(request.Metadata.Labels) > 0 {
+		features = append(features, version.FeatureLabels)
+	}
+	if request.NetworkPolicy != nil {
+		features = append(features, version.FeatureNetworkPolicy)
+	}
+	if request.EgressProxy != nil {
+		features = append(features, version.FeatureEgressProxy)
+	}
+	if request.ScratchVolume != nil {
+		features = append(features, version.FeatureScratchVolume)
+	}
+	if request.StreamOutput {
+		features = append(features, version.FeatureStreamOutput)
+	}
+	if len(request.DatumProfiles) > 0 {
+		features = append(features, version.FeatureDatumProfiles)
+	}
+	if requestsFractionalGPU(request) {
+		features = append(features, version.FeatureFractionalGPU)
+	}
+	if request.TimeoutPolicy != pps.TimeoutPolicy_TIMEOUT_FAIL_JOB {
+		features = append(features, version.FeatureTimeoutPolicy)
+	}
+	if request.DatumRetry != nil {
+		features = append(features, version.FeatureDatumRetry)
+	}
+	if request.DatumOrder != nil {
+		features = append(features, version.FeatureDatumOrder)
+	}
+	if request.S3Out || pps.ContainsS3Inputs(request.Input) {
+		features = append(features, version.FeatureS3)
+	}
+	if request.StandbyWakeAlarm != nil {
+		features = append(features, version.FeatureStandbyWakeAlarm)
+	}
+	if len(request.DowntimeWindows) > 0 {
+		features = append(features, version.FeatureDowntimeWindows)
+	}
+	if request.Budget != nil {
+		features = append(features, version.FeatureBudget)
+	}
+	if request.DeterminismCheck != nil {
+		features = append(features, version.FeatureDeterminismCheck)
+	}
+	if request.ExperimentTracking != nil {
+		features = append(features, version.FeatureExperimentTracking)
+	}
+	if request.Service.GetAutoscaling() != nil {
+		features = append(features, version.FeatureServiceAutoscaling)
+	}
+	if request.CloudCredentials != nil {
+		features = append(features, version.FeatureCloudCredentials)
+	}
+	if len(request.Transform.GetConfigMaps()) > 0 {
+		features = append(features, version.FeatureConfigMaps)
+	}
+	if request.MaxConcurrentJobs > 1 {
+		features = append(features, version.FeatureMaxConcurrentJobs)
+	}
+	if request.EmptyJobPolicy != pps.EmptyJobPolicy_EMPTY_JOB_KEEP {
+		features = append(features, version.FeatureEmptyJobPolicy)
+	}
+	return features
+}
+
+// requestsFractionalGPU returns true if 'request' requests a fraction of a
+// GPU, for the pipeline's workers or those of any of its datum profiles
+func requestsFractionalGPU(request *pps.CreatePipelineRequest) bool {
+	specs := []*pps.ResourceSpec{request.ResourceRequests, request.ResourceLimits}
+	for _, profile := range request.DatumProfiles {
+		specs = append(specs, profile.ResourceRequests, profile.ResourceLimits)
+	}
+	for _, spec := range specs {
+		if spec != nil && spec.Gpu != nil && spec.Gpu.Fraction != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func unsupportedFeatureError(v *versionpb.Version, feature string) error {
+	if v == nil {
+		return fmt.Errorf("pachd doesn't support %s; upgrade pachd to use it", feature)
+	}
+	return fmt.Errorf("pachd version %s doesn't support %s; upgrade pachd to use it",
+		version.PrettyPrintVersion(v), feature)
+}
+
+// scrubFeatureGRPC is like grpcutil.ScrubGRPC, but if 'err' says that pachd
+// doesn't implement the RPC that provides 'feature', it returns an error
+// saying so instead of gRPC's
+func (c APIClient) scrubFeatureGRPC(feature string, err error) error {
+	if status.Code(err) != codes.Unimplemented {
+		return grpcutil.ScrubGRPC(err)
+	}
+	v, versionErr := c.VersionAPIClient.GetVersion(c.Ctx(), &types.Empty{})
+	if versionErr != nil {
+		v = nil
+	}
+	return unsupportedFeatureError(v, feature)
+}
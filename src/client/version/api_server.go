@@ -23,6 +23,10 @@ func (a *apiServer) GetVersion(ctx context.Context, request *types.Empty) (respo
 	return a.version, nil
 }
 
+func (a *apiServer) Negotiate(ctx context.Context, request *pb.NegotiateRequest) (response *pb.NegotiateResponse, err error) {
+	return Negotiate(a.version, request.ClientVersion, request.Features), nil
+}
+
 // APIServerOptions are options when creating a new APIServer.
 type APIServerOptions struct {
 	DisableLogging bool
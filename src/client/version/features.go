@@ -0,0 +1,215 @@
+package version
+
+import (
+	"fmt"
+
+	pb "github.com/pachyderm/pachyderm/src/client/version/versionpb"
+)
+
+// The API features that clients and pachd negotiate (see Negotiate). A
+// feature is added whenever an RPC, or a field that older versions would
+// silently ignore, is added to the API.
+const (
+	// FeatureNegotiate is the Negotiate RPC itself
+	FeatureNegotiate = "version.negotiate"
+	// FeatureTypedErrors is error codes in the details of errors' gRPC
+	// statuses (see errcode)
+	FeatureTypedErrors = "errors.typed"
+	// FeatureStorageUsage is the GetStorageUsage RPC
+	FeatureStorageUsage = "pfs.storage_usage"
+	// FeatureBranchRetention is the SetBranchRetention RPC
+	FeatureBranchRetention = "pfs.branch_retention"
+	// FeatureBranchTriggers is the SetBranchTrigger RPC
+	FeatureBranchTriggers = "pfs.branch_triggers"
+	// FeatureDiffFileStream is the DiffFileStream RPC
+	FeatureDiffFileStream = "pfs.diff_file_stream"
+	// FeatureJobProgress is the JobProgress RPC
+	FeatureJobProgress = "pps.job_progress"
+	// FeatureWatch is the WatchJob and WatchPipeline RPCs
+	FeatureWatch = "pps.watch"
+	// FeatureLabels is pipeline labels and label selectors
+	FeatureLabels = "pps.labels"
+	// FeatureTemplates is pipeline templates
+	FeatureTemplates = "pps.templates"
+	// FeatureStandbyGracePeriod is the standby_grace_period pipeline field
+	FeatureStandbyGracePeriod = "pps.standby_grace_period"
+	// FeatureSQLEgress is SQL database egress
+	FeatureSQLEgress = "pps.egress.sql_database"
+	// FeatureKafkaEgress is Kafka egress
+	FeatureKafkaEgress = "pps.egress.kafka"
+	// FeatureBulkResults is per-item results from bulk RPCs (PutFile,
+	// DeleteFiles and CreatePipelines), and best-effort bulk requests
+	FeatureBulkResults = "bulk.results"
+	// FeatureResumableUpload is the upload session RPCs (StartUpload,
+	// UploadChunk, InspectUpload, FinishUpload and DeleteUpload)
+	FeatureResumableUpload = "pfs.resumable_upload"
+	// FeatureNetworkPolicy is the network_policy pipeline field
+	FeatureNetworkPolicy = "pps.network_policy"
+	// FeatureEgressProxy is the egress_proxy pipeline field
+	FeatureEgressProxy = "pps.egress_proxy"
+	// FeatureScratchVolume is the scratch_volume pipeline field
+	FeatureScratchVolume = "pps.scratch_volume"
+	// FeatureStreamOutput is the stream_output pipeline field
+	FeatureStreamOutput = "pps.stream_output"
+	// FeatureDatumProfiles is the datum_profiles pipeline field
+	FeatureDatumProfiles = "pps.datum_profiles"
+	// FeatureFractionalGPU is the fraction and shares_per_gpu GPU fields
+	FeatureFractionalGPU = "pps.gpu.fraction"
+	// FeatureStuckBranches is the ListStuckBranches RPC
+	FeatureStuckBranches = "pps.stuck_branches"
+	// FeatureSecretRotation is the RotateSecret RPC
+	FeatureSecretRotation = "pps.secret_rotation"
+	// FeatureTimeoutPolicy is the timeout_policy pipeline field
+	FeatureTimeoutPolicy = "pps.timeout_policy"
+	// FeatureDiagnose is the Diagnose RPC
+	FeatureDiagnose = "pps.diagnose"
+	// FeatureDatumRetry is the datum_retry pipeline field
+	FeatureDatumRetry = "pps.datum_retry"
+	// FeatureDatumOrder is the datum_order pipeline field
+	FeatureDatumOrder = "pps.datum_order"
+	// FeatureS3 is the s3 input and s3_out pipeline fields
+	FeatureS3 = "pps.s3"
+	// FeatureStandbyWakeAlarm is the standby_wake_alarm pipeline field
+	FeatureStandbyWakeAlarm = "pps.standby_wake_alarm"
+	// FeatureDowntimeWindows is the downtime_windows pipeline field
+	FeatureDowntimeWindows = "pps.downtime_windows"
+	// FeatureBudget is the budget pipeline field
+	FeatureBudget = "pps.budget"
+	// FeatureDeterminismCheck is the determinism_check pipeline field
+	FeatureDeterminismCheck = "pps.determinism_check"
+	// FeatureReplayJob is the ReplayJob RPC
+	FeatureReplayJob = "pps.replay_job"
+	// FeatureIncrementalGC is the InspectGarbageCollect RPC and garbage
+	// collection's grace period
+	FeatureIncrementalGC = "pps.incremental_gc"
+	// FeatureArtifacts is the PutArtifact and GetArtifact RPCs
+	FeatureArtifacts = "pps.artifacts"
+	// FeatureExperimentTracking is the experiment_tracking pipeline field
+	FeatureExperimentTracking = "pps.experiment_tracking"
+	// FeatureModels is the model registry RPCs (CreateModelVersion,
+	// PromoteModel, InspectModel and ResolveModel)
+	FeatureModels = "pfs.models"
+	// FeatureServiceAutoscaling is the autoscaling field of service pipelines
+	FeatureServiceAutoscaling = "pps.service_autoscaling"
+	// FeatureCloudCredentials is the cloud_credentials pipeline field
+	FeatureCloudCredentials = "pps.cloud_credentials"
+	// FeatureConfigMaps is the config_maps transform field
+	FeatureConfigMaps = "pps.config_maps"
+	// FeaturePFSWatch is the WatchCommit and WatchBranch RPCs
+	FeaturePFSWatch = "pfs.watch"
+	// FeatureDumpArchive is the DumpArchive RPC
+	FeatureDumpArchive = "debug.dump_archive"
+	// FeatureMaxConcurrentJobs is the max_concurrent_jobs pipeline field
+	FeatureMaxConcurrentJobs = "pps.max_concurrent_jobs"
+	// FeatureImpact is the InspectImpact RPC
+	FeatureImpact = "pps.impact"
+	// FeatureEmptyJobPolicy is the empty_job_policy pipeline field, and the
+	// JOB_SKIPPED job state
+	FeatureEmptyJobPolicy = "pps.empty_job_policy"
+)
+
+var (
+	// Features are the API features that this version of Pachyderm supports
+	Features = []string{
+		FeatureNegotiate,
+		FeatureTypedErrors,
+		FeatureStorageUsage,
+		FeatureBranchRetention,
+		FeatureBranchTriggers,
+		FeatureDiffFileStream,
+		FeatureJobProgress,
+		FeatureWatch,
+		FeatureLabels,
+		FeatureTemplates,
+		FeatureStandbyGracePeriod,
+		FeatureSQLEgress,
+		FeatureKafkaEgress,
+		FeatureBulkResults,
+		FeatureResumableUpload,
+		FeatureNetworkPolicy,
+		FeatureEgressProxy,
+		FeatureScratchVolume,
+		FeatureStreamOutput,
+		FeatureDatumProfiles,
+		FeatureFractionalGPU,
+		FeatureStuckBranches,
+		FeatureSecretRotation,
+		FeatureTimeoutPolicy,
+		FeatureDiagnose,
+		FeatureDatumRetry,
+		FeatureDatumOrder,
+		FeatureS3,
+		FeatureStandbyWakeAlarm,
+		FeatureDowntimeWindows,
+		FeatureBudget,
+		FeatureDeterminismCheck,
+		FeatureReplayJob,
+		FeatureIncrementalGC,
+		FeatureArtifacts,
+		FeatureExperimentTracking,
+		FeatureModels,
+		FeatureServiceAutoscaling,
+		FeatureCloudCredentials,
+		FeatureConfigMaps,
+		FeaturePFSWatch,
+		FeatureDumpArchive,
+		FeatureMaxConcurrentJobs,
+		FeatureImpact,
+		FeatureEmptyJobPolicy,
+	}
+
+	// Deprecations are the API features that this version of Pachyderm
+	// supports, but that may be removed in a future version
+	Deprecations = []*pb.Deprecation{
+		{Feature: "pfs.ListCommit", Replacement: "ListCommitStream"},
+		{Feature: "pfs.ListFile", Replacement: "ListFileStream"},
+		{Feature: "pfs.GlobFile", Replacement: "GlobFileStream"},
+		{Feature: "pps.ListJob", Replacement: "ListJobStream"},
+		{Feature: "pps.ListDatum", Replacement: "ListDatumStream"},
+		{Feature: "pps.pod_spec", Replacement: "pod_patch"},
+	}
+)
+
+// Negotiate returns pachd's response to a client with version 'client' that
+// supports 'clientFeatures'
+func Negotiate(server *pb.Version, client *pb.Version, clientFeatures []string) *pb.NegotiateResponse {
+	response := &pb.NegotiateResponse{
+		ServerVersion: server,
+		Features:      Features,
+		Deprecations:  Deprecations,
+	}
+	if client == nil {
+		return response
+	}
+	supported := make(map[string]bool)
+	for _, feature := range Features {
+		supported[feature] = true
+	}
+	var unsupported []string
+	for _, feature := range clientFeatures {
+		if !supported[feature] {
+			unsupported = append(unsupported, feature)
+		}
+	}
+	switch {
+	case client.Major != server.Major:
+		response.Warnings = append(response.Warnings, fmt.Sprintf(
+			"client version %s and pachd version %s have different major versions, and may be incompatible",
+			PrettyPrintVersion(client), PrettyPrintVersion(server)))
+	case len(unsupported) > 0:
+		response.Warnings = append(response.Warnings, fmt.Sprintf(
+			"client version %s is newer than pachd version %s, which doesn't support %v",
+			PrettyPrintVersion(client), PrettyPrintVersion(server), unsupported))
+	}
+	return response
+}
+
+// Supports returns true if 'response' says that pachd supports 'feature'
+func Supports(response *pb.NegotiateResponse, feature string) bool {
+	for _, f := range response.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	pb "github.com/pachyderm/pachyderm/src/client/version/versionpb"
+)
+
+func TestNegotiate(t *testing.T) {
+	server := &pb.Version{Major: 1, Minor: 10, Micro: 0}
+
+	// A client with the same features gets no warnings
+	response := Negotiate(server, &pb.Version{Major: 1, Minor: 9, Micro: 2}, Features)
+	require.Equal(t, server, response.ServerVersion)
+	require.Equal(t, 0, len(response.Warnings))
+	require.True(t, Supports(response, FeatureNegotiate))
+	require.True(t, Supports(response, FeatureWatch))
+	require.False(t, Supports(response, "pps.unknown"))
+	require.True(t, len(response.Deprecations) > 0)
+
+	// A newer client is told which of its features aren't supported
+	response = Negotiate(server, &pb.Version{Major: 1, Minor: 11, Micro: 0},
+		append([]string{"pps.unknown"}, Features...))
+	require.Equal(t, 1, len(response.Warnings))
+	require.Matches(t, "pps.unknown", response.Warnings[0])
+
+	// A client with a different major version is warned about it
+	response = Negotiate(server, &pb.Version{Major: 2, Minor: 0, Micro: 0}, Features)
+	require.Equal(t, 1, len(response.Warnings))
+	require.Matches(t, "different major versions", response.Warnings[0])
+
+	// Requests without a client version just get pachd's features
+	response = Negotiate(server, nil, nil)
+	require.Equal(t, 0, len(response.Warnings))
+	require.True(t, Supports(response, FeatureTypedErrors))
+}
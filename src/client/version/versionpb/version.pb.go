@@ -98,8 +98,202 @@ func (m *Version) GetAdditional() string {
 	return ""
 }
 
+// Deprecation describes an API feature that's deprecated
+type Deprecation struct {
+	// feature is the deprecated feature (e.g. "pfs.ListFile")
+	Feature string `protobuf:"bytes,1,opt,name=feature,proto3" json:"feature,omitempty"`
+	// replacement describes what to use instead
+	Replacement          string   `protobuf:"bytes,2,opt,name=replacement,proto3" json:"replacement,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Deprecation) Reset()         { *m = Deprecation{} }
+func (m *Deprecation) String() string { return proto.CompactTextString(m) }
+func (*Deprecation) ProtoMessage()    {}
+func (*Deprecation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_66657ffe705dda95, []int{1}
+}
+func (m *Deprecation) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Deprecation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Deprecation.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Deprecation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Deprecation.Merge(m, src)
+}
+func (m *Deprecation) XXX_Size() int {
+	return m.Size()
+}
+func (m *Deprecation) XXX_DiscardUnknown() {
+	xxx_messageInfo_Deprecation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Deprecation proto.InternalMessageInfo
+
+func (m *Deprecation) GetFeature() string {
+	if m != nil {
+		return m.Feature
+	}
+	return ""
+}
+
+func (m *Deprecation) GetReplacement() string {
+	if m != nil {
+		return m.Replacement
+	}
+	return ""
+}
+
+type NegotiateRequest struct {
+	// client_version is the version of the client
+	ClientVersion *Version `protobuf:"bytes,1,opt,name=client_version,json=clientVersion,proto3" json:"client_version,omitempty"`
+	// features are the API features that the client supports
+	Features             []string `protobuf:"bytes,2,rep,name=features,proto3" json:"features,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NegotiateRequest) Reset()         { *m = NegotiateRequest{} }
+func (m *NegotiateRequest) String() string { return proto.CompactTextString(m) }
+func (*NegotiateRequest) ProtoMessage()    {}
+func (*NegotiateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_66657ffe705dda95, []int{2}
+}
+func (m *NegotiateRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *NegotiateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_NegotiateRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *NegotiateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NegotiateRequest.Merge(m, src)
+}
+func (m *NegotiateRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *NegotiateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_NegotiateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NegotiateRequest proto.InternalMessageInfo
+
+func (m *NegotiateRequest) GetClientVersion() *Version {
+	if m != nil {
+		return m.ClientVersion
+	}
+	return nil
+}
+
+func (m *NegotiateRequest) GetFeatures() []string {
+	if m != nil {
+		return m.Features
+	}
+	return nil
+}
+
+type NegotiateResponse struct {
+	// server_version is the version of pachd
+	ServerVersion *Version `protobuf:"bytes,1,opt,name=server_version,json=serverVersion,proto3" json:"server_version,omitempty"`
+	// features are the API features that pachd supports
+	Features []string `protobuf:"bytes,2,rep,name=features,proto3" json:"features,omitempty"`
+	// deprecations are the API features that pachd supports but are
+	// deprecated
+	Deprecations []*Deprecation `protobuf:"bytes,3,rep,name=deprecations,proto3" json:"deprecations,omitempty"`
+	// warnings describe incompatibilities between the client and pachd
+	Warnings             []string `protobuf:"bytes,4,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NegotiateResponse) Reset()         { *m = NegotiateResponse{} }
+func (m *NegotiateResponse) String() string { return proto.CompactTextString(m) }
+func (*NegotiateResponse) ProtoMessage()    {}
+func (*NegotiateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_66657ffe705dda95, []int{3}
+}
+func (m *NegotiateResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *NegotiateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_NegotiateResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *NegotiateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NegotiateResponse.Merge(m, src)
+}
+func (m *NegotiateResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *NegotiateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_NegotiateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NegotiateResponse proto.InternalMessageInfo
+
+func (m *NegotiateResponse) GetServerVersion() *Version {
+	if m != nil {
+		return m.ServerVersion
+	}
+	return nil
+}
+
+func (m *NegotiateResponse) GetFeatures() []string {
+	if m != nil {
+		return m.Features
+	}
+	return nil
+}
+
+func (m *NegotiateResponse) GetDeprecations() []*Deprecation {
+	if m != nil {
+		return m.Deprecations
+	}
+	return nil
+}
+
+func (m *NegotiateResponse) GetWarnings() []string {
+	if m != nil {
+		return m.Warnings
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Version)(nil), "versionpb.Version")
+	proto.RegisterType((*Deprecation)(nil), "versionpb.Deprecation")
+	proto.RegisterType((*NegotiateRequest)(nil), "versionpb.NegotiateRequest")
+	proto.RegisterType((*NegotiateResponse)(nil), "versionpb.NegotiateResponse")
 }
 
 func init() {
@@ -107,22 +301,33 @@ func init() {
 }
 
 var fileDescriptor_66657ffe705dda95 = []byte{
-	// 236 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x52, 0x4b, 0xce, 0xc9, 0x4c,
-	0xcd, 0x2b, 0xd1, 0x2f, 0x4b, 0x2d, 0x2a, 0xce, 0xcc, 0xcf, 0x83, 0xd1, 0x05, 0x49, 0x30, 0x96,
-	0x5e, 0x41, 0x51, 0x7e, 0x49, 0xbe, 0x10, 0x27, 0x5c, 0x42, 0x4a, 0x3a, 0x3d, 0x3f, 0x3f, 0x3d,
-	0x27, 0x55, 0x1f, 0x2c, 0x91, 0x54, 0x9a, 0xa6, 0x9f, 0x9a, 0x5b, 0x50, 0x52, 0x09, 0x51, 0xa7,
-	0x94, 0xcd, 0xc5, 0x1e, 0x06, 0x51, 0x29, 0x24, 0xc2, 0xc5, 0x9a, 0x9b, 0x98, 0x95, 0x5f, 0x24,
-	0xc1, 0xa8, 0xc0, 0xa8, 0xc1, 0x1b, 0x04, 0xe1, 0x80, 0x45, 0x33, 0xf3, 0xf2, 0x8b, 0x24, 0x98,
-	0xa0, 0xa2, 0x20, 0x0e, 0x44, 0x34, 0xb9, 0x28, 0x5f, 0x82, 0x19, 0x26, 0x9a, 0x5c, 0x94, 0x2f,
-	0x24, 0xc7, 0xc5, 0x95, 0x98, 0x92, 0x92, 0x59, 0x92, 0x99, 0x9f, 0x97, 0x98, 0x23, 0xc1, 0xa2,
-	0xc0, 0xa8, 0xc1, 0x19, 0x84, 0x24, 0x62, 0xe4, 0xc8, 0xc5, 0xec, 0x18, 0xe0, 0x29, 0x64, 0xc5,
-	0xc5, 0xe5, 0x9e, 0x5a, 0x02, 0xb3, 0x56, 0x4c, 0x0f, 0xe2, 0x3e, 0x3d, 0x98, 0xfb, 0xf4, 0x5c,
-	0x41, 0xee, 0x93, 0x12, 0xd2, 0x83, 0x7b, 0x41, 0x0f, 0xaa, 0x56, 0x89, 0xc1, 0xc9, 0xf3, 0xc4,
-	0x23, 0x39, 0xc6, 0x0b, 0x8f, 0xe4, 0x18, 0x1f, 0x3c, 0x92, 0x63, 0x8c, 0xb2, 0x4e, 0xcf, 0x2c,
-	0xc9, 0x28, 0x4d, 0xd2, 0x4b, 0xce, 0xcf, 0xd5, 0x2f, 0x48, 0x4c, 0xce, 0xa8, 0x4c, 0x49, 0x2d,
-	0x42, 0x66, 0x15, 0x17, 0x25, 0xeb, 0xe3, 0x0a, 0xb0, 0x24, 0x36, 0xb0, 0x85, 0xc6, 0x80, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0x10, 0xf7, 0x6f, 0xf8, 0x53, 0x01, 0x00, 0x00,
+	// 413 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x52, 0xc1, 0x6e, 0xd4, 0x30,
+	0x14, 0xac, 0x9b, 0x42, 0xc9, 0x5b, 0x8a, 0xc0, 0x42, 0x95, 0xb5, 0x45, 0xab, 0x28, 0x07, 0xb4,
+	0xa7, 0x44, 0x5a, 0x4e, 0x94, 0x13, 0x08, 0x04, 0x7b, 0x41, 0xc8, 0x07, 0x0e, 0x5c, 0x90, 0xd7,
+	0xfb, 0x9a, 0x1a, 0x12, 0x3b, 0xd8, 0x4e, 0x51, 0xbf, 0x81, 0xbf, 0xe2, 0xc4, 0x91, 0x4f, 0x40,
+	0xfb, 0x25, 0x28, 0x71, 0x12, 0x02, 0x2a, 0xd2, 0x9e, 0xd6, 0x33, 0x6f, 0xf4, 0x66, 0x67, 0xf2,
+	0xe0, 0xb1, 0x2c, 0x15, 0x6a, 0x9f, 0x5f, 0xa1, 0x75, 0xca, 0xe8, 0xe1, 0xb7, 0xde, 0x0c, 0xaf,
+	0xac, 0xb6, 0xc6, 0x1b, 0x1a, 0x8f, 0x83, 0xf9, 0x59, 0x61, 0x4c, 0x51, 0x62, 0xde, 0x0d, 0x36,
+	0xcd, 0x45, 0x8e, 0x55, 0xed, 0xaf, 0x83, 0x2e, 0xfd, 0x0c, 0xc7, 0xef, 0x83, 0x92, 0x3e, 0x84,
+	0x5b, 0x95, 0xf8, 0x64, 0x2c, 0x23, 0x09, 0x59, 0x9e, 0xf0, 0x00, 0x3a, 0x56, 0x69, 0x63, 0xd9,
+	0x61, 0xcf, 0xb6, 0x20, 0xb0, 0xd2, 0x1a, 0x16, 0x0d, 0xac, 0xb4, 0x86, 0x2e, 0x00, 0xc4, 0x76,
+	0xab, 0xbc, 0x32, 0x5a, 0x94, 0xec, 0x28, 0x21, 0xcb, 0x98, 0x4f, 0x98, 0x74, 0x0d, 0xb3, 0x97,
+	0x58, 0x5b, 0x94, 0xa2, 0x25, 0x28, 0x83, 0xe3, 0x0b, 0x14, 0xbe, 0xb1, 0xd8, 0x59, 0xc6, 0x7c,
+	0x80, 0x34, 0x81, 0x99, 0xc5, 0xba, 0x14, 0x12, 0x2b, 0xd4, 0xbe, 0xb3, 0x8e, 0xf9, 0x94, 0x4a,
+	0x15, 0xdc, 0x7f, 0x8b, 0x85, 0xf1, 0x4a, 0x78, 0xe4, 0xf8, 0xa5, 0x41, 0xe7, 0xe9, 0x53, 0xb8,
+	0x17, 0xda, 0xf9, 0xd8, 0x87, 0xef, 0xd6, 0xce, 0x56, 0x34, 0x1b, 0xcb, 0xc8, 0xfa, 0xb0, 0xfc,
+	0x24, 0x28, 0x87, 0xec, 0x73, 0xb8, 0xd3, 0x7b, 0x3b, 0x76, 0x98, 0x44, 0xcb, 0x98, 0x8f, 0x38,
+	0xfd, 0x4e, 0xe0, 0xc1, 0xc4, 0xcb, 0xd5, 0x46, 0x3b, 0x6c, 0xcd, 0x1c, 0xda, 0x2b, 0xb4, 0xfb,
+	0x98, 0x05, 0xe5, 0x1e, 0x66, 0xf4, 0x1c, 0xee, 0x6e, 0xff, 0x54, 0xe4, 0x58, 0x94, 0x44, 0xcb,
+	0xd9, 0xea, 0x74, 0xb2, 0x74, 0xd2, 0x20, 0xff, 0x4b, 0xdb, 0xee, 0xfd, 0x2a, 0xac, 0x56, 0xba,
+	0x70, 0xec, 0x28, 0xec, 0x1d, 0xf0, 0xea, 0x1b, 0x81, 0xe8, 0xf9, 0xbb, 0x35, 0x3d, 0x07, 0x78,
+	0x8d, 0x63, 0xec, 0xd3, 0x2c, 0xdc, 0x46, 0x36, 0xdc, 0x46, 0xf6, 0xaa, 0xbd, 0x8d, 0xf9, 0x0d,
+	0x21, 0xd2, 0x03, 0xfa, 0x06, 0xe2, 0xb1, 0x07, 0x7a, 0x36, 0x91, 0xfc, 0xfb, 0x25, 0xe6, 0x8f,
+	0x6e, 0x1e, 0x86, 0xea, 0xd2, 0x83, 0x17, 0xeb, 0x1f, 0xbb, 0x05, 0xf9, 0xb9, 0x5b, 0x90, 0x5f,
+	0xbb, 0x05, 0xf9, 0xf0, 0xac, 0x50, 0xfe, 0xb2, 0xd9, 0x64, 0xd2, 0x54, 0x79, 0x2d, 0xe4, 0xe5,
+	0xf5, 0x16, 0xed, 0xf4, 0xe5, 0xac, 0xcc, 0xff, 0x77, 0xf6, 0x9b, 0xdb, 0xdd, 0x5f, 0x7f, 0xf2,
+	0x3b, 0x00, 0x00, 0xff, 0xff, 0x81, 0xe1, 0xf4, 0xe4, 0x19, 0x03, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -138,6 +343,9 @@ const _ = grpc.SupportPackageIsVersion4
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type APIClient interface {
 	GetVersion(ctx context.Context, in *types.Empty, opts ...grpc.CallOption) (*Version, error)
+	// Negotiate exchanges the versions and API features of the client and
+	// pachd, so that each can avoid features that the other doesn't support
+	Negotiate(ctx context.Context, in *NegotiateRequest, opts ...grpc.CallOption) (*NegotiateResponse, error)
 }
 
 type aPIClient struct {
@@ -157,9 +365,21 @@ func (c *aPIClient) GetVersion(ctx context.Context, in *types.Empty, opts ...grp
 	return out, nil
 }
 
+func (c *aPIClient) Negotiate(ctx context.Context, in *NegotiateRequest, opts ...grpc.CallOption) (*NegotiateResponse, error) {
+	out := new(NegotiateResponse)
+	err := c.cc.Invoke(ctx, "/versionpb.API/Negotiate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // APIServer is the server API for API service.
 type APIServer interface {
 	GetVersion(context.Context, *types.Empty) (*Version, error)
+	// Negotiate exchanges the versions and API features of the client and
+	// pachd, so that each can avoid features that the other doesn't support
+	Negotiate(context.Context, *NegotiateRequest) (*NegotiateResponse, error)
 }
 
 // UnimplementedAPIServer can be embedded to have forward compatible implementations.
@@ -169,6 +389,9 @@ type UnimplementedAPIServer struct {
 func (*UnimplementedAPIServer) GetVersion(ctx context.Context, req *types.Empty) (*Version, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetVersion not implemented")
 }
+func (*UnimplementedAPIServer) Negotiate(ctx context.Context, req *NegotiateRequest) (*NegotiateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Negotiate not implemented")
+}
 
 func RegisterAPIServer(s *grpc.Server, srv APIServer) {
 	s.RegisterService(&_API_serviceDesc, srv)
@@ -192,6 +415,24 @@ func _API_GetVersion_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_Negotiate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NegotiateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).Negotiate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/versionpb.API/Negotiate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).Negotiate(ctx, req.(*NegotiateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _API_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "versionpb.API",
 	HandlerType: (*APIServer)(nil),
@@ -200,6 +441,10 @@ var _API_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetVersion",
 			Handler:    _API_GetVersion_Handler,
 		},
+		{
+			MethodName: "Negotiate",
+			Handler:    _API_Negotiate_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "client/version/versionpb/version.proto",
@@ -254,6 +499,166 @@ func (m *Version) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *Deprecation) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Deprecation) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Deprecation) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Replacement) > 0 {
+		i -= len(m.Replacement)
+		copy(dAtA[i:], m.Replacement)
+		i = encodeVarintVersion(dAtA, i, uint64(len(m.Replacement)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Feature) > 0 {
+		i -= len(m.Feature)
+		copy(dAtA[i:], m.Feature)
+		i = encodeVarintVersion(dAtA, i, uint64(len(m.Feature)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *NegotiateRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *NegotiateRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *NegotiateRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Features) > 0 {
+		for iNdEx := len(m.Features) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Features[iNdEx])
+			copy(dAtA[i:], m.Features[iNdEx])
+			i = encodeVarintVersion(dAtA, i, uint64(len(m.Features[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if m.ClientVersion != nil {
+		{
+			size, err := m.ClientVersion.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintVersion(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *NegotiateResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *NegotiateResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *NegotiateResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Warnings) > 0 {
+		for iNdEx := len(m.Warnings) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Warnings[iNdEx])
+			copy(dAtA[i:], m.Warnings[iNdEx])
+			i = encodeVarintVersion(dAtA, i, uint64(len(m.Warnings[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if len(m.Deprecations) > 0 {
+		for iNdEx := len(m.Deprecations) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Deprecations[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintVersion(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if len(m.Features) > 0 {
+		for iNdEx := len(m.Features) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Features[iNdEx])
+			copy(dAtA[i:], m.Features[iNdEx])
+			i = encodeVarintVersion(dAtA, i, uint64(len(m.Features[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if m.ServerVersion != nil {
+		{
+			size, err := m.ServerVersion.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintVersion(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintVersion(dAtA []byte, offset int, v uint64) int {
 	offset -= sovVersion(v)
 	base := offset
@@ -290,16 +695,92 @@ func (m *Version) Size() (n int) {
 	return n
 }
 
-func sovVersion(x uint64) (n int) {
-	return (math_bits.Len64(x|1) + 6) / 7
-}
-func sozVersion(x uint64) (n int) {
-	return sovVersion(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+func (m *Deprecation) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Feature)
+	if l > 0 {
+		n += 1 + l + sovVersion(uint64(l))
+	}
+	l = len(m.Replacement)
+	if l > 0 {
+		n += 1 + l + sovVersion(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
 }
-func (m *Version) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
+
+func (m *NegotiateRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ClientVersion != nil {
+		l = m.ClientVersion.Size()
+		n += 1 + l + sovVersion(uint64(l))
+	}
+	if len(m.Features) > 0 {
+		for _, s := range m.Features {
+			l = len(s)
+			n += 1 + l + sovVersion(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *NegotiateResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ServerVersion != nil {
+		l = m.ServerVersion.Size()
+		n += 1 + l + sovVersion(uint64(l))
+	}
+	if len(m.Features) > 0 {
+		for _, s := range m.Features {
+			l = len(s)
+			n += 1 + l + sovVersion(uint64(l))
+		}
+	}
+	if len(m.Deprecations) > 0 {
+		for _, e := range m.Deprecations {
+			l = e.Size()
+			n += 1 + l + sovVersion(uint64(l))
+		}
+	}
+	if len(m.Warnings) > 0 {
+		for _, s := range m.Warnings {
+			l = len(s)
+			n += 1 + l + sovVersion(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func sovVersion(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozVersion(x uint64) (n int) {
+	return sovVersion(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *Version) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
 		preIndex := iNdEx
 		var wire uint64
 		for shift := uint(0); ; shift += 7 {
@@ -439,6 +920,434 @@ func (m *Version) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *Deprecation) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowVersion
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Deprecation: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Deprecation: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Feature", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowVersion
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthVersion
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthVersion
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Feature = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Replacement", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowVersion
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthVersion
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthVersion
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Replacement = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipVersion(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthVersion
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthVersion
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *NegotiateRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowVersion
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NegotiateRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NegotiateRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClientVersion", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowVersion
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthVersion
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthVersion
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ClientVersion == nil {
+				m.ClientVersion = &Version{}
+			}
+			if err := m.ClientVersion.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Features", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowVersion
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthVersion
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthVersion
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Features = append(m.Features, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipVersion(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthVersion
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthVersion
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *NegotiateResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowVersion
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NegotiateResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NegotiateResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ServerVersion", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowVersion
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthVersion
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthVersion
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ServerVersion == nil {
+				m.ServerVersion = &Version{}
+			}
+			if err := m.ServerVersion.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Features", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowVersion
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthVersion
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthVersion
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Features = append(m.Features, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Deprecations", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowVersion
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthVersion
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthVersion
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Deprecations = append(m.Deprecations, &Deprecation{})
+			if err := m.Deprecations[len(m.Deprecations)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Warnings", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowVersion
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthVersion
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthVersion
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Warnings = append(m.Warnings, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipVersion(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthVersion
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthVersion
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipVersion(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -3,10 +3,13 @@ package cmds
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/server/pkg/cmdutil"
+	"github.com/pachyderm/pachyderm/src/server/pps/pretty"
 
+	"github.com/gogo/protobuf/jsonpb"
 	"github.com/golang/snappy"
 	"github.com/spf13/cobra"
 )
@@ -79,6 +82,8 @@ $ {{alias}} -u s3://bucket/backup`,
 	restore.Flags().StringVarP(&url, "url", "u", "", "An object storage url (i.e. s3://...) to restore from.")
 	commands = append(commands, cmdutil.CreateAlias(restore, "restore"))
 
+	// admin commands have always printed raw resources in lowerCamelCase
+	output := &cmdutil.Output{}
 	inspectCluster := &cobra.Command{
 		Short: "Returns info about the pachyderm cluster",
 		Long:  "Returns info about the pachyderm cluster",
@@ -88,15 +93,96 @@ $ {{alias}} -u s3://bucket/backup`,
 				return err
 			}
 			defer c.Close()
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
 			ci, err := c.InspectCluster()
 			if err != nil {
 				return err
 			}
+			if e != nil {
+				return e.EncodeProto(ci)
+			}
 			fmt.Println(ci.ID)
 			return nil
 		}),
 	}
+	inspectCluster.Flags().AddFlagSet(output.Flags("output", "o"))
 	commands = append(commands, cmdutil.CreateAlias(inspectCluster, "inspect cluster"))
 
+	var threshold time.Duration
+	var raw bool
+	var fullTimestamps bool
+	doctor := &cobra.Command{
+		Short: "Diagnose common problems in the cluster.",
+		Long: `Diagnose common problems in the cluster.
+
+Doctor runs a battery of checks for common problems, and reports what they
+find (most severe first) along with suggested fixes. It checks for:
+- version skew between pachctl, pachd and pipelines' workers
+- crash-looping workers, along with their recent kubernetes events
+- pipelines whose auth tokens have expired or expire soon
+- an etcd database that's full or nearly full
+- object storage that pachd can't write, read or delete
+- standby pipelines that took longer than their standby_wake_alarm to wake up
+- pipelines that have exceeded their monthly budget
+
+Doctor also reports branches whose head commits have been unfinished for
+longer than --threshold, along with the commits upstream of them that they're
+waiting on, the pipelines and jobs writing those commits, and commands that may
+unblock them.`,
+		Example: `
+# Check for problems, including branches that have been stuck for at least an
+# hour:
+$ {{alias}}
+
+# Check for problems, including branches that have been stuck for at least ten
+# minutes:
+$ {{alias}} --threshold 10m`,
+		Run: cmdutil.RunFixedArgs(0, func(args []string) error {
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			diagnosis, err := c.Diagnose()
+			if err != nil {
+				return err
+			}
+			stuckBranches, err := c.ListStuckBranches(threshold)
+			if err != nil {
+				return err
+			}
+			if raw {
+				marshaller := &jsonpb.Marshaler{Indent: "  "}
+				if err := marshaller.Marshal(os.Stdout, diagnosis); err != nil {
+					return err
+				}
+				for _, sb := range stuckBranches {
+					if err := marshaller.Marshal(os.Stdout, sb); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			if len(diagnosis.Findings) == 0 && len(stuckBranches) == 0 {
+				fmt.Println("No problems found.")
+				return nil
+			}
+			for _, finding := range diagnosis.Findings {
+				pretty.PrintFinding(os.Stdout, finding)
+			}
+			for _, sb := range stuckBranches {
+				pretty.PrintStuckBranch(os.Stdout, sb, fullTimestamps)
+			}
+			return nil
+		}),
+	}
+	doctor.Flags().DurationVar(&threshold, "threshold", time.Hour, "Report branches whose head commits have been unfinished for at least this long.")
+	doctor.Flags().BoolVar(&raw, "raw", false, "disable pretty printing, print raw json")
+	doctor.Flags().BoolVar(&fullTimestamps, "full-timestamps", false, "Return absolute timestamps (as opposed to the default, relative timestamps).")
+	commands = append(commands, cmdutil.CreateAlias(doctor, "doctor"))
+
 	return commands
 }
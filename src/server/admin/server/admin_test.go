@@ -158,7 +158,7 @@ func testExtractRestore(t *testing.T, testObjects bool) {
 
 	if testObjects {
 		// Delete existing objects
-		require.NoError(t, c.GarbageCollect(10000))
+		require.NoError(t, c.GarbageCollectWithGracePeriod(10000, 0))
 	}
 
 	// Restore metadata and possibly objects
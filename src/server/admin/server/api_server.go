@@ -21,6 +21,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/server/pkg/ancestry"
 	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/log"
+	"github.com/pachyderm/pachyderm/src/server/pkg/mtls"
 	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
@@ -471,8 +472,10 @@ func sanitizePipeline(req *pps.CreatePipelineRequest) {
 
 func (a *apiServer) getPachClient() *client.APIClient {
 	a.pachClientOnce.Do(func() {
-		var err error
-		a.pachClient, err = client.NewFromAddress(a.address)
+		options, err := mtls.PeerClientOptions()
+		if err == nil {
+			a.pachClient, err = client.NewFromAddress(a.address, options...)
+		}
 		if err != nil {
 			panic(fmt.Sprintf("pps failed to initialize pach client: %v", err))
 		}
@@ -3,8 +3,12 @@ package cmds
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"runtime"
 	"strings"
+	"text/tabwriter"
 	"text/template"
 	"time"
 
@@ -13,7 +17,10 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/pkg/config"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/cmdutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/pretty"
 
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/types"
 	"github.com/spf13/cobra"
 )
 
@@ -34,6 +41,39 @@ func githubLogin() (string, error) {
 	return strings.TrimSpace(token), nil // drop trailing newline
 }
 
+// openBrowser tries to open 'url' in the user's web browser
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+// oidcLogin authenticates the user with the cluster's OIDC ID provider, by
+// opening the provider's login page and waiting for the user to log in
+func oidcLogin(c *client.APIClient, noBrowser bool) (*auth.AuthenticateResponse, error) {
+	loginInfo, err := c.GetOIDCLogin(c.Ctx(), &auth.GetOIDCLoginRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if noBrowser || openBrowser(loginInfo.LoginURL) != nil {
+		fmt.Println("Please paste this link into a browser to log in with your ID provider:\n\n" +
+			loginInfo.LoginURL + "\n")
+	} else {
+		fmt.Println("Opening your ID provider's login page in your browser. If it " +
+			"doesn't open, please paste this link into a browser:\n\n" +
+			loginInfo.LoginURL + "\n")
+	}
+	fmt.Println("Waiting for you to log in...")
+	return c.Authenticate(c.Ctx(), &auth.AuthenticateRequest{OIDCState: loginInfo.State})
+}
+
 func writePachTokenToCfg(token string) error {
 	cfg, err := config.Read(false)
 	if err != nil {
@@ -139,10 +179,12 @@ func DeactivateCmd() *cobra.Command {
 }
 
 // LoginCmd returns a cobra.Command to login to a Pachyderm cluster with your
-// GitHub account. Any resources that have been restricted to the email address
-// registered with your GitHub account will subsequently be accessible.
+// account with the cluster's OIDC ID provider, or your GitHub account. Any
+// resources that have been restricted to that account will subsequently be
+// accessible.
 func LoginCmd() *cobra.Command {
 	var useOTP bool
+	var noBrowser bool
 	login := &cobra.Command{
 		Short: "Log in to Pachyderm",
 		Long: "Login to Pachyderm. Any resources that have been restricted to " +
@@ -170,15 +212,19 @@ func LoginCmd() *cobra.Command {
 					c.Ctx(),
 					&auth.AuthenticateRequest{OneTimePassword: code})
 			} else {
-				// Exchange GitHub token for Pachyderm token
-				token, err := githubLogin()
-				if err != nil {
-					return err
+				// Log in with the cluster's OIDC ID provider, if it has one
+				resp, authErr = oidcLogin(c, noBrowser)
+				if auth.IsErrOIDCNotConfigured(authErr) {
+					// Exchange GitHub token for Pachyderm token
+					token, err := githubLogin()
+					if err != nil {
+						return err
+					}
+					fmt.Println("Retrieving Pachyderm token...")
+					resp, authErr = c.Authenticate(
+						c.Ctx(),
+						&auth.AuthenticateRequest{GitHubToken: token})
 				}
-				fmt.Println("Retrieving Pachyderm token...")
-				resp, authErr = c.Authenticate(
-					c.Ctx(),
-					&auth.AuthenticateRequest{GitHubToken: token})
 			}
 
 			// Write new Pachyderm token to config
@@ -196,7 +242,10 @@ func LoginCmd() *cobra.Command {
 	}
 	login.PersistentFlags().BoolVarP(&useOTP, "one-time-password", "o", false,
 		"If set, authenticate with a Dash-provided One-Time Password, rather than "+
-			"via GitHub")
+			"via your ID provider")
+	login.PersistentFlags().BoolVar(&noBrowser, "no-browser", false,
+		"If set, don't try to open your ID provider's login page in a browser "+
+			"(print the link instead)")
 	return cmdutil.CreateAlias(login, "auth login")
 }
 
@@ -260,6 +309,7 @@ func WhoamiCmd() *cobra.Command {
 // CheckCmd returns a cobra command that sends an "Authorize" RPC to Pachd, to
 // determine whether the specified user has access to the specified repo.
 func CheckCmd() *cobra.Command {
+	var branch string
 	check := &cobra.Command{
 		Use:   "{{alias}} (none|reader|writer|owner) <repo>",
 		Short: "Check whether you have reader/writer/etc-level access to 'repo'",
@@ -268,7 +318,8 @@ func CheckCmd() *cobra.Command {
 			"if the you have at least \"reader\" access to the repo " +
 			"\"private-data\" (you could be a reader, writer, or owner). Unlike " +
 			"`pachctl auth get`, you do not need to have access to 'repo' to " +
-			"discover your own access level.",
+			"discover your own access level. If --branch is set, the branch's " +
+			"ACL is taken into account.",
 		Run: cmdutil.RunFixedArgs(2, func(args []string) error {
 			scope, err := auth.ParseScope(args[0])
 			if err != nil {
@@ -281,22 +332,28 @@ func CheckCmd() *cobra.Command {
 			}
 			defer c.Close()
 			resp, err := c.Authorize(c.Ctx(), &auth.AuthorizeRequest{
-				Repo:  repo,
-				Scope: scope,
+				Repo:   repo,
+				Scope:  scope,
+				Branch: branch,
 			})
 			if err != nil {
 				return grpcutil.ScrubGRPC(err)
 			}
 			fmt.Printf("%t\n", resp.Authorized)
+			if resp.Authorized && len(resp.ReadPaths) > 0 {
+				fmt.Printf("(may only read files under: %s)\n", strings.Join(resp.ReadPaths, ", "))
+			}
 			return nil
 		}),
 	}
+	check.Flags().StringVarP(&branch, "branch", "b", "", "check your access to this branch of 'repo'")
 	return cmdutil.CreateAlias(check, "auth check")
 }
 
 // GetCmd returns a cobra command that gets either the ACL for a Pachyderm
 // repo or another user's scope of access to that repo
 func GetCmd() *cobra.Command {
+	var branch string
 	get := &cobra.Command{
 		Use:   "{{alias}} [<username>] <repo>",
 		Short: "Get the ACL for 'repo' or the access that 'username' has to 'repo'",
@@ -322,7 +379,9 @@ func GetCmd() *cobra.Command {
 					return grpcutil.ScrubGRPC(err)
 				}
 				t := template.Must(template.New("ACLEntries").Parse(
-					"{{range .}}{{.Username }}: {{.Scope}}\n{{end}}"))
+					"{{range .}}{{.Username }}: {{.Scope}}" +
+						"{{if .Branch}} (branch {{.Branch}}){{end}}" +
+						"{{if .ReadPaths}} (read paths:{{range .ReadPaths}} {{.}}{{end}}){{end}}\n{{end}}"))
 				return t.Execute(os.Stdout, resp.Entries)
 			}
 			// Get User's scope on an acl
@@ -330,6 +389,7 @@ func GetCmd() *cobra.Command {
 			resp, err := c.GetScope(c.Ctx(), &auth.GetScopeRequest{
 				Repos:    []string{repo},
 				Username: username,
+				Branch:   branch,
 			})
 			if err != nil {
 				return grpcutil.ScrubGRPC(err)
@@ -338,12 +398,16 @@ func GetCmd() *cobra.Command {
 			return nil
 		}),
 	}
+	get.Flags().StringVarP(&branch, "branch", "b", "", "get the access that "+
+		"'username' has to this branch of 'repo'")
 	return cmdutil.CreateAlias(get, "auth get")
 }
 
 // SetScopeCmd returns a cobra command that lets a user set the level of access
 // that another user has to a repo
 func SetScopeCmd() *cobra.Command {
+	var branch string
+	var readPaths []string
 	setScope := &cobra.Command{
 		Use:   "{{alias}} <username> (none|reader|writer|owner) <repo>",
 		Short: "Set the scope of access that 'username' has to 'repo'",
@@ -354,7 +418,14 @@ func SetScopeCmd() *cobra.Command {
 			"private-data' would let \"github-alice\" read from \"private-data\" but " +
 			"not create commits (writer) or modify the repo's access permissions " +
 			"(owner). Currently all Pachyderm authentication uses GitHub OAuth, so " +
-			"'username' must be a GitHub username",
+			"'username' must be a GitHub username.\n\n" +
+			"If --branch is set, the scope only applies to that branch of 'repo', " +
+			"and overrides the scope that 'username' has to the rest of 'repo'. For " +
+			"example, 'pachctl auth set github-alice reader private-data --branch " +
+			"master' prevents \"github-alice\" from writing to the master branch, " +
+			"even if they're a writer of \"private-data\". If --read-path is set, " +
+			"'username' (who must be a reader) may only read files under the given " +
+			"paths.",
 		Run: cmdutil.RunFixedArgs(3, func(args []string) error {
 			scope, err := auth.ParseScope(args[1])
 			if err != nil {
@@ -367,13 +438,19 @@ func SetScopeCmd() *cobra.Command {
 			}
 			defer c.Close()
 			_, err = c.SetScope(c.Ctx(), &auth.SetScopeRequest{
-				Repo:     repo,
-				Scope:    scope,
-				Username: username,
+				Repo:      repo,
+				Scope:     scope,
+				Username:  username,
+				Branch:    branch,
+				ReadPaths: readPaths,
 			})
 			return grpcutil.ScrubGRPC(err)
 		}),
 	}
+	setScope.Flags().StringVarP(&branch, "branch", "b", "", "set the scope that "+
+		"'username' has to this branch of 'repo'")
+	setScope.Flags().StringSliceVar(&readPaths, "read-path", nil, "restrict "+
+		"'username' to reading files under this path (may be repeated)")
 	return cmdutil.CreateAlias(setScope, "auth set")
 }
 
@@ -556,6 +633,113 @@ func GetOneTimePasswordCmd() *cobra.Command {
 	return cmdutil.CreateAlias(getOneTimePassword, "auth get-otp")
 }
 
+// AuditCmd returns a cobra command that lists the events in the audit log
+func AuditCmd() *cobra.Command {
+	var since, from, to, principal string
+	var raw bool
+	audit := &cobra.Command{
+		Short: "List the RPCs recorded in the cluster's audit log",
+		Long: "List the RPCs recorded in the cluster's audit log, oldest first. " +
+			"Every RPC handled by pachd while auth is active is recorded, along " +
+			"with its caller, arguments (unless they contain credentials), and " +
+			"result. Requests refused by pipelines' egress proxies are also " +
+			"recorded, with the method 'egress'. Only cluster admins may read " +
+			"the audit log.",
+		Example: `
+# list the RPCs made by alice in the last day
+$ {{alias}} --principal github:alice --since 24h
+
+# list all RPCs made in January 2020
+$ {{alias}} --from 2020-01-01T00:00:00Z --to 2020-02-01T00:00:00Z`,
+		Run: cmdutil.RunFixedArgs(0, func([]string) error {
+			req := &auth.ListAuditEventsRequest{Principal: principal}
+			if since != "" {
+				if from != "" {
+					return fmt.Errorf("only one of --since and --from may be set")
+				}
+				d, err := time.ParseDuration(since)
+				if err != nil {
+					return fmt.Errorf("could not parse duration %q: %v", since, err)
+				}
+				if req.From, err = types.TimestampProto(time.Now().Add(-d)); err != nil {
+					return err
+				}
+			}
+			for _, t := range []struct {
+				flag, value string
+				ts          **types.Timestamp
+			}{{"from", from, &req.From}, {"to", to, &req.To}} {
+				if t.value == "" {
+					continue
+				}
+				parsed, err := time.Parse(time.RFC3339, t.value)
+				if err != nil {
+					return fmt.Errorf("could not parse --%s %q (must be RFC 3339, e.g. %q): %v",
+						t.flag, t.value, time.RFC3339, err)
+				}
+				if *t.ts, err = types.TimestampProto(parsed); err != nil {
+					return err
+				}
+			}
+
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return fmt.Errorf("could not connect: %v", err)
+			}
+			defer c.Close()
+			events, err := c.ListAuditEvents(c.Ctx(), req)
+			if err != nil {
+				return grpcutil.ScrubGRPC(err)
+			}
+			marshaller := &jsonpb.Marshaler{Indent: "  "}
+			writer := tabwriter.NewWriter(os.Stdout, 0, 1, 1, ' ', 0)
+			if !raw {
+				fmt.Fprintln(writer, "TIME\tPRINCIPAL\tMETHOD\tDURATION\tRESULT\tREQUEST")
+			}
+			for {
+				event, err := events.Recv()
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					return grpcutil.ScrubGRPC(err)
+				}
+				if raw {
+					if err := marshaller.Marshal(os.Stdout, event); err != nil {
+						return err
+					}
+					fmt.Println()
+					continue
+				}
+				t, err := types.TimestampFromProto(event.Time)
+				if err != nil {
+					return err
+				}
+				method, result, request := event.Method, "ok", event.Request
+				if event.Error != "" {
+					result = event.Error
+				}
+				if v := event.EgressViolation; v != nil {
+					method, result, request = "egress", "refused", fmt.Sprintf("%s (from pod %s)", v.Host, v.Pod)
+				}
+				fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", t.Format(time.RFC3339),
+					event.Principal, method, pretty.Duration(event.Duration),
+					result, request)
+			}
+			return writer.Flush()
+		}),
+	}
+	audit.Flags().StringVar(&since, "since", "", "only list RPCs made within "+
+		"this duration of the current time (e.g. \"24h\")")
+	audit.Flags().StringVar(&from, "from", "", "only list RPCs made at or after "+
+		"this time (in RFC 3339 format)")
+	audit.Flags().StringVar(&to, "to", "", "only list RPCs made before this "+
+		"time (in RFC 3339 format)")
+	audit.Flags().StringVar(&principal, "principal", "", "only list RPCs made "+
+		"by this principal (e.g. \"github:alice\" or \"robot:ci\")")
+	audit.Flags().BoolVar(&raw, "raw", false, "disable pretty printing, print raw json")
+	return cmdutil.CreateAlias(audit, "auth audit")
+}
+
 // Cmds returns a list of cobra commands for authenticating and authorizing
 // users in an auth-enabled Pachyderm cluster.
 func Cmds() []*cobra.Command {
@@ -582,6 +766,7 @@ func Cmds() []*cobra.Command {
 	commands = append(commands, GetConfigCmd())
 	commands = append(commands, SetConfigCmd())
 	commands = append(commands, GetOneTimePasswordCmd())
+	commands = append(commands, AuditCmd())
 
 	return commands
 }
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ancestry"
+)
+
+// getAccess computes the scope that 'subject' has in 'acl' for operations on
+// 'branch' (or on the whole repo, if 'branch' is empty), taking the subject's
+// groups into account. It also returns the path prefixes under which 'subject'
+// may read files, or nil if 'subject' may read any file.
+func (a *apiServer) getAccess(ctx context.Context, subject string, acl *auth.ACL, branch string) (auth.Scope, []string, error) {
+	groups, err := a.getGroups(ctx, subject)
+	if err != nil {
+		return auth.Scope_NONE, nil, fmt.Errorf("could not retrieve caller's "+
+			"group memberships: %v", err)
+	}
+	scope, readPaths := aclAccess(acl, append([]string{subject}, groups...), branch)
+	return scope, readPaths, nil
+}
+
+// aclAccess is the implementation of getAccess. 'principals' are the subject
+// and all of its groups.
+func aclAccess(acl *auth.ACL, principals []string, branch string) (auth.Scope, []string) {
+	var scope auth.Scope
+	for _, p := range principals {
+		if acl.Entries[p] > scope {
+			scope = acl.Entries[p]
+		}
+	}
+	if scope == auth.Scope_OWNER {
+		return scope, nil
+	}
+
+	// If any of 'principals' is on the branch's ACL, the branch ACL overrides
+	// their repo-level scope (and isn't subject to path restrictions)
+	if branchACL, ok := acl.Branches[branch]; ok && branch != "" {
+		branchScope, onBranchACL := auth.Scope_NONE, false
+		for _, p := range principals {
+			if s, ok := branchACL.Entries[p]; ok {
+				onBranchACL = true
+				if s > branchScope {
+					branchScope = s
+				}
+			}
+		}
+		if onBranchACL {
+			return branchScope, nil
+		}
+	}
+
+	// Read paths only apply if none of the subject's principals grant it
+	// unrestricted read access
+	var readPaths []string
+	for _, p := range principals {
+		if acl.Entries[p] < auth.Scope_READER {
+			continue
+		}
+		restriction, ok := acl.ReadPaths[p]
+		if !ok {
+			return scope, nil
+		}
+		readPaths = append(readPaths, restriction.Prefixes...)
+	}
+	return scope, readPaths
+}
+
+// validateACLEntry returns an error if 'entry' can't be added to an ACL, and
+// otherwise returns its read paths in canonical form.
+func validateACLEntry(entry *auth.ACLEntry) ([]string, error) {
+	if entry.Branch != "" {
+		if err := ancestry.ValidateName(entry.Branch); err != nil {
+			return nil, fmt.Errorf("invalid branch %q: %v", entry.Branch, err)
+		}
+		if len(entry.ReadPaths) > 0 {
+			return nil, fmt.Errorf("read paths cannot be set on a branch's ACL")
+		}
+	}
+	if len(entry.ReadPaths) > 0 && entry.Scope != auth.Scope_READER {
+		return nil, fmt.Errorf("read paths can only be set on READER entries, not %v", entry.Scope)
+	}
+	var readPaths []string
+	for _, p := range entry.ReadPaths {
+		readPaths = append(readPaths, path.Clean("/"+p))
+	}
+	return readPaths, nil
+}
+
+// setACLEntry adds 'entry' (whose principal must be canonical and whose read
+// paths must have been validated by validateACLEntry) to 'acl', replacing any
+// existing entry for the same principal and branch. Entries with scope NONE
+// are removed.
+func setACLEntry(acl *auth.ACL, entry *auth.ACLEntry) {
+	principal := entry.Username
+	if entry.Branch != "" {
+		branchACL, ok := acl.Branches[entry.Branch]
+		if !ok {
+			branchACL = &auth.BranchACL{Entries: make(map[string]auth.Scope)}
+		}
+		if entry.Scope == auth.Scope_NONE {
+			delete(branchACL.Entries, principal)
+		} else {
+			branchACL.Entries[principal] = entry.Scope
+		}
+		if len(branchACL.Entries) == 0 {
+			delete(acl.Branches, entry.Branch)
+		} else {
+			if acl.Branches == nil {
+				acl.Branches = make(map[string]*auth.BranchACL)
+			}
+			acl.Branches[entry.Branch] = branchACL
+		}
+		return
+	}
+	delete(acl.ReadPaths, principal)
+	if entry.Scope == auth.Scope_NONE {
+		delete(acl.Entries, principal)
+		return
+	}
+	if acl.Entries == nil {
+		acl.Entries = make(map[string]auth.Scope)
+	}
+	acl.Entries[principal] = entry.Scope
+	if len(entry.ReadPaths) > 0 {
+		if acl.ReadPaths == nil {
+			acl.ReadPaths = make(map[string]*auth.ReadPaths)
+		}
+		acl.ReadPaths[principal] = &auth.ReadPaths{Prefixes: entry.ReadPaths}
+	}
+}
+
+// aclEntries returns the entries of 'acl', in the form returned by GetACL
+func aclEntries(acl *auth.ACL) []*auth.ACLEntry {
+	result := make([]*auth.ACLEntry, 0)
+	for principal, scope := range acl.Entries {
+		result = append(result, &auth.ACLEntry{
+			Username:  principal,
+			Scope:     scope,
+			ReadPaths: acl.ReadPaths[principal].GetPrefixes(),
+		})
+	}
+	for branch, branchACL := range acl.Branches {
+		for principal, scope := range branchACL.Entries {
+			result = append(result, &auth.ACLEntry{
+				Username: principal,
+				Scope:    scope,
+				Branch:   branch,
+			})
+		}
+	}
+	return result
+}
+
+// aclIsEmpty returns true if 'acl' has no entries (and can be deleted)
+func aclIsEmpty(acl *auth.ACL) bool {
+	return len(acl.Entries) == 0 && len(acl.Branches) == 0
+}
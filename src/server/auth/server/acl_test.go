@@ -0,0 +1,89 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestACLAccess(t *testing.T) {
+	acl := &auth.ACL{}
+	for _, entry := range []*auth.ACLEntry{
+		{Username: "github:alice", Scope: auth.Scope_OWNER},
+		{Username: "github:bob", Scope: auth.Scope_WRITER},
+		{Username: "github:bob", Scope: auth.Scope_READER, Branch: "master"},
+		{Username: "github:carol", Scope: auth.Scope_READER, ReadPaths: []string{"/a"}},
+		{Username: "group:eng", Scope: auth.Scope_READER, ReadPaths: []string{"/b"}},
+		{Username: "group:ops", Scope: auth.Scope_READER},
+		{Username: "github:alice", Scope: auth.Scope_READER, Branch: "master"},
+	} {
+		_, err := validateACLEntry(entry)
+		require.NoError(t, err)
+		setACLEntry(acl, entry)
+	}
+
+	// Owners aren't affected by branch ACLs
+	scope, readPaths := aclAccess(acl, []string{"github:alice"}, "master")
+	require.Equal(t, auth.Scope_OWNER, scope)
+	require.Equal(t, 0, len(readPaths))
+
+	// Branch ACLs override the repo-level scope on that branch only
+	scope, _ = aclAccess(acl, []string{"github:bob"}, "master")
+	require.Equal(t, auth.Scope_READER, scope)
+	scope, _ = aclAccess(acl, []string{"github:bob"}, "staging")
+	require.Equal(t, auth.Scope_WRITER, scope)
+	scope, _ = aclAccess(acl, []string{"github:bob"}, "")
+	require.Equal(t, auth.Scope_WRITER, scope)
+
+	// Read paths are combined across principals, unless one is unrestricted
+	scope, readPaths = aclAccess(acl, []string{"github:carol"}, "master")
+	require.Equal(t, auth.Scope_READER, scope)
+	require.ElementsEqual(t, []string{"/a"}, readPaths)
+	_, readPaths = aclAccess(acl, []string{"github:carol", "group:eng"}, "")
+	require.ElementsEqual(t, []string{"/a", "/b"}, readPaths)
+	_, readPaths = aclAccess(acl, []string{"github:carol", "group:eng", "group:ops"}, "")
+	require.Equal(t, 0, len(readPaths))
+
+	scope, _ = aclAccess(acl, []string{"github:dave"}, "master")
+	require.Equal(t, auth.Scope_NONE, scope)
+
+	// Removing entries
+	setACLEntry(acl, &auth.ACLEntry{Username: "github:bob", Branch: "master"})
+	setACLEntry(acl, &auth.ACLEntry{Username: "github:alice", Branch: "master"})
+	require.Equal(t, 0, len(acl.Branches))
+	setACLEntry(acl, &auth.ACLEntry{Username: "github:carol", Scope: auth.Scope_WRITER})
+	_, ok := acl.ReadPaths["github:carol"]
+	require.False(t, ok)
+	require.Equal(t, 5, len(aclEntries(acl)))
+}
+
+func TestValidateACLEntry(t *testing.T) {
+	readPaths, err := validateACLEntry(&auth.ACLEntry{
+		Username:  "github:carol",
+		Scope:     auth.Scope_READER,
+		ReadPaths: []string{"a/b/", "/c"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/a/b", "/c"}, readPaths)
+
+	_, err = validateACLEntry(&auth.ACLEntry{
+		Username:  "github:carol",
+		Scope:     auth.Scope_WRITER,
+		ReadPaths: []string{"/a"},
+	})
+	require.YesError(t, err)
+	_, err = validateACLEntry(&auth.ACLEntry{
+		Username:  "github:carol",
+		Scope:     auth.Scope_READER,
+		Branch:    "master",
+		ReadPaths: []string{"/a"},
+	})
+	require.YesError(t, err)
+	_, err = validateACLEntry(&auth.ACLEntry{
+		Username: "github:carol",
+		Scope:    auth.Scope_READER,
+		Branch:   "bad branch",
+	})
+	require.YesError(t, err)
+}
@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 
 	"github.com/crewjam/saml"
@@ -52,6 +53,8 @@ const (
 	membersPrefix          = "/members"
 	groupsPrefix           = "/groups"
 	configPrefix           = "/config"
+	oidcStatesPrefix       = "/oidc-states"
+	auditEventsPrefix      = "/audit"
 
 	// defaultSessionTTLSecs is the lifetime of an auth token from Authenticate,
 	// and the default lifetime of an auth token from GetAuthToken.
@@ -64,6 +67,12 @@ const (
 	// information is passed during SAML authentication, so a short TTL ensures
 	// that group membership information is updated somewhat regularly.
 	defaultSAMLTTLSecs = 24 * 60 * 60 // 24 hours
+	// defaultOIDCTTLSecs is the session TTL for OIDC-authenticated tokens. Like
+	// SAML, OIDC ID providers may pass group memberships during authentication.
+	defaultOIDCTTLSecs = 24 * 60 * 60 // 24 hours
+	// oidcStateTTLSecs is how long a user has to complete an OIDC login after
+	// calling GetOIDCLogin
+	oidcStateTTLSecs = 10 * 60 // 10 minutes
 	// minSessionTTL is the shortest session TTL that Authenticate() will attach
 	// to a new token. This avoids confusing behavior with stale OTPs and such.
 	minSessionTTL = 10 * time.Second // 30 days
@@ -123,6 +132,10 @@ var epsilon = &types.BoolValue{Value: true}
 type APIServer interface {
 	auth.APIServer
 	txnenv.AuthTransactionServer
+
+	// AuditInterceptors returns gRPC interceptors that record the RPCs handled
+	// by a server in the audit log
+	AuditInterceptors() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor)
 }
 
 // apiServer implements the public interface of the Pachyderm auth system,
@@ -163,6 +176,15 @@ type apiServer struct {
 	groups col.Collection
 	// collection containing the auth config (under the key configKey)
 	authConfig col.Collection
+	// oidcStates is a collection of hash(state) -> OIDCState mappings, which
+	// track OIDC logins that are in progress
+	oidcStates col.Collection
+	// auditEvents is an append-only collection of uuid -> AuditEvent mappings
+	// (see audit.go)
+	auditEvents col.Collection
+	// auditQueue holds the audit events that are waiting to be written to
+	// 'auditEvents'
+	auditQueue chan *auth.AuditEvent
 
 	// This is a cache of the PPS master token. It's set once on startup and then
 	// never updated
@@ -267,10 +289,21 @@ func NewAuthServer(
 			nil,
 			nil,
 		),
-		public: public,
+		oidcStates: col.NewCollection(
+			env.GetEtcdClient(),
+			path.Join(etcdPrefix, oidcStatesPrefix),
+			nil,
+			&auth.OIDCState{},
+			nil,
+			nil,
+		),
+		auditEvents: auditEventsCollection(env.GetEtcdClient(), etcdPrefix),
+		auditQueue: make(chan *auth.AuditEvent, auditQueueSize),
+		public:     public,
 	}
 	go s.retrieveOrGeneratePPSToken()
 	go s.watchAdmins(path.Join(etcdPrefix, adminsPrefix))
+	go s.writeAuditEvents()
 
 	if public {
 		// start SAML service (won't respond to
@@ -875,6 +908,32 @@ func (a *apiServer) Authenticate(ctx context.Context, req *auth.AuthenticateRequ
 			return nil, fmt.Errorf("error storing auth token for user \"%s\": %v", username, err)
 		}
 
+	case req.OIDCState != "":
+		// Wait for the caller to log in with the cluster's OIDC ID provider
+		subject, err := a.awaitOIDCLogin(ctx, req.OIDCState)
+		if err != nil {
+			return nil, err
+		}
+
+		// If the cluster's enterprise token is expired, only admins may log in.
+		// Check if 'subject' is an admin
+		if err := a.expiredClusterAdminCheck(ctx, subject); err != nil {
+			return nil, err
+		}
+
+		// Generate a new Pachyderm token and write it
+		pachToken = uuid.NewWithoutDashes()
+		if _, err := col.NewSTM(ctx, a.env.GetEtcdClient(), func(stm col.STM) error {
+			return a.tokens.ReadWrite(stm).PutTTL(hashToken(pachToken),
+				&auth.TokenInfo{
+					Subject: subject,
+					Source:  auth.TokenInfo_AUTHENTICATE,
+				},
+				defaultOIDCTTLSecs)
+		}); err != nil {
+			return nil, fmt.Errorf("error storing auth token for user \"%s\": %v", subject, err)
+		}
+
 	case req.OneTimePassword != "":
 		if _, err := col.NewSTM(ctx, a.env.GetEtcdClient(), func(stm col.STM) error {
 			// read short-lived authentication code (and delete it if found)
@@ -1147,12 +1206,13 @@ func (a *apiServer) AuthorizeInTransaction(
 		return nil, fmt.Errorf("error getting ACL for repo \"%s\": %v", req.Repo, err)
 	}
 
-	scope, err := a.getScope(txnCtx.ClientContext, callerInfo.Subject, &acl)
+	scope, readPaths, err := a.getAccess(txnCtx.ClientContext, callerInfo.Subject, &acl, req.Branch)
 	if err != nil {
 		return nil, err
 	}
 	return &auth.AuthorizeResponse{
 		Authorized: scope >= req.Scope,
+		ReadPaths:  readPaths,
 	}, nil
 }
 
@@ -1220,6 +1280,13 @@ func validateSetScopeRequest(ctx context.Context, req *auth.SetScopeRequest) err
 	if req.Repo == "" {
 		return fmt.Errorf("invalid request: must set repo")
 	}
+	if _, err := validateACLEntry(&auth.ACLEntry{
+		Scope:     req.Scope,
+		Branch:    req.Branch,
+		ReadPaths: req.ReadPaths,
+	}); err != nil {
+		return fmt.Errorf("invalid request: %v", err)
+	}
 	return nil
 }
 
@@ -1331,12 +1398,21 @@ func (a *apiServer) SetScopeInTransaction(
 	if err != nil {
 		return nil, err
 	}
-	if req.Scope != auth.Scope_NONE {
-		acl.Entries[principal] = req.Scope
-	} else {
-		delete(acl.Entries, principal)
+	readPaths, err := validateACLEntry(&auth.ACLEntry{
+		Scope:     req.Scope,
+		Branch:    req.Branch,
+		ReadPaths: req.ReadPaths,
+	})
+	if err != nil {
+		return nil, err
 	}
-	if len(acl.Entries) == 0 {
+	setACLEntry(&acl, &auth.ACLEntry{
+		Username:  principal,
+		Scope:     req.Scope,
+		Branch:    req.Branch,
+		ReadPaths: readPaths,
+	})
+	if aclIsEmpty(&acl) {
 		err = acls.Delete(req.Repo)
 	} else {
 		err = acls.Put(req.Repo, &acl)
@@ -1461,8 +1537,8 @@ func (a *apiServer) GetScopeInTransaction(
 			}
 		}
 
-		// compute target's access scope to this repo
-		targetScope, err := a.getScope(txnCtx.ClientContext, targetSubject, &acl)
+		// compute target's access scope to this repo (or branch)
+		targetScope, _, err := a.getAccess(txnCtx.ClientContext, targetSubject, &acl, req.Branch)
 		if err != nil {
 			return nil, err
 		}
@@ -1518,13 +1594,7 @@ func (a *apiServer) GetACLInTransaction(
 		return nil, err
 	}
 	response := &auth.GetACLResponse{
-		Entries: make([]*auth.ACLEntry, 0),
-	}
-	for user, scope := range acl.Entries {
-		response.Entries = append(response.Entries, &auth.ACLEntry{
-			Username: user,
-			Scope:    scope,
-		})
+		Entries: aclEntries(acl),
 	}
 	// For now, no access is require to read a repo's ACL
 	// https://github.com/pachyderm/pachyderm/issues/2353
@@ -1578,22 +1648,28 @@ func (a *apiServer) SetACLInTransaction(
 	eg := &errgroup.Group{}
 	var aclMu sync.Mutex
 	newACL := new(auth.ACL)
-	if len(req.Entries) > 0 {
-		newACL.Entries = make(map[string]auth.Scope)
-	}
 	for _, entry := range req.Entries {
-		user, scope := entry.Username, entry.Scope
-		if user == ppsUser {
+		entry := entry
+		if entry.Username == ppsUser {
 			continue
 		}
+		readPaths, err := validateACLEntry(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ACL entry for %q: %v", entry.Username, err)
+		}
 		eg.Go(func() error {
-			principal, err := a.canonicalizeSubject(txnCtx.ClientContext, user)
+			principal, err := a.canonicalizeSubject(txnCtx.ClientContext, entry.Username)
 			if err != nil {
 				return err
 			}
 			aclMu.Lock()
 			defer aclMu.Unlock()
-			newACL.Entries[principal] = scope
+			setACLEntry(newACL, &auth.ACLEntry{
+				Username:  principal,
+				Scope:     entry.Scope,
+				Branch:    entry.Branch,
+				ReadPaths: readPaths,
+			})
 			return nil
 		})
 	}
@@ -1627,7 +1703,7 @@ func (a *apiServer) SetACLInTransaction(
 			// ACL not found -- construct empty ACL proto
 			acl.Entries = make(map[string]auth.Scope)
 		}
-		if len(acl.Entries) > 0 {
+		if !aclIsEmpty(&acl) {
 			// ACL is present; caller must be authorized directly
 			scope, err := a.getScope(txnCtx.ClientContext, callerInfo.Subject, &acl)
 			if err != nil {
@@ -1668,7 +1744,7 @@ func (a *apiServer) SetACLInTransaction(
 	}
 
 	// Set new ACL
-	if len(newACL.Entries) == 0 {
+	if aclIsEmpty(newACL) {
 		err := acls.Delete(req.Repo)
 		if err != nil && !col.IsErrNotFound(err) {
 			return nil, err
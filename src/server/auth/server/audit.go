@@ -0,0 +1,201 @@
+package server
+
+import (
+	"path"
+	"time"
+
+	etcd "github.com/coreos/etcd/clientv3"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	logrus "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
+)
+
+// auditQueueSize is the number of audit events that may be waiting to be
+// written to etcd before RPCs start blocking on the audit log
+const auditQueueSize = 1000
+
+// unauditedMethods are RPCs that aren't recorded in the audit log, as they're
+// called constantly by clients and k8s and don't read or modify any data
+var unauditedMethods = map[string]bool{
+	"/grpc.health.v1.Health/Check": true,
+	"/versionpb.API/GetVersion":    true,
+}
+
+// redactedMethods are RPCs whose requests contain credentials, and so aren't
+// recorded in the audit log (just like they aren't logged)
+var redactedMethods = map[string]bool{
+	"/auth.API/Activate":         true,
+	"/auth.API/Authenticate":     true,
+	"/auth.API/ExtendAuthToken":  true,
+	"/auth.API/RevokeAuthToken":  true,
+	"/auth.API/SetConfiguration": true,
+	"/enterprise.API/Activate":   true,
+}
+
+// AuditInterceptors returns gRPC interceptors that record each RPC handled by
+// a server in the audit log, while auth is active.
+func (a *apiServer) AuditInterceptors() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	unary := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		event := a.newAuditEvent(ctx, info.FullMethod, req)
+		resp, err := handler(ctx, req)
+		a.recordAuditEvent(ctx, event, err)
+		return resp, err
+	}
+	stream := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		event := a.newAuditEvent(ss.Context(), info.FullMethod, nil)
+		err := handler(srv, ss)
+		a.recordAuditEvent(ss.Context(), event, err)
+		return err
+	}
+	return unary, stream
+}
+
+// newAuditEvent returns an AuditEvent for a call to 'method' by the caller in
+// 'ctx', or nil if the call shouldn't be audited. It must be called before the
+// RPC is handled, so that the caller's principal can be read even if the RPC
+// revokes the caller's token or deactivates auth.
+func (a *apiServer) newAuditEvent(ctx context.Context, method string, req interface{}) *auth.AuditEvent {
+	if a.activationState() != full || unauditedMethods[method] {
+		return nil
+	}
+	event := &auth.AuditEvent{
+		Time:   types.TimestampNow(),
+		Method: method,
+	}
+	if tokenInfo, err := a.getAuthenticatedUser(ctx); err == nil {
+		event.Principal = tokenInfo.Subject
+	}
+	if msg, ok := req.(proto.Message); ok && !redactedMethods[method] {
+		request, err := (&jsonpb.Marshaler{}).MarshalToString(msg)
+		if err != nil {
+			logrus.Errorf("could not marshal %s request for audit log: %v", method, err)
+		}
+		event.Request = request
+	}
+	return event
+}
+
+// recordAuditEvent completes 'event' with the result of its RPC and queues it
+// to be written to etcd. It blocks if the queue is full, so that RPCs can't
+// outpace the audit log.
+func (a *apiServer) recordAuditEvent(ctx context.Context, event *auth.AuditEvent, err error) {
+	if event == nil {
+		return
+	}
+	start, _ := types.TimestampFromProto(event.Time)
+	event.Duration = types.DurationProto(time.Since(start))
+	if err != nil {
+		event.Error = err.Error()
+	}
+	select {
+	case a.auditQueue <- event:
+	case <-ctx.Done():
+		logrus.Errorf("could not record %s by %q in audit log: %v", event.Method, event.Principal, ctx.Err())
+	}
+}
+
+// auditEventsCollection returns the collection of audit events under the auth
+// etcd prefix 'etcdPrefix'
+func auditEventsCollection(etcdClient *etcd.Client, etcdPrefix string) col.Collection {
+	return col.NewCollection(
+		etcdClient,
+		path.Join(etcdPrefix, auditEventsPrefix),
+		nil,
+		&auth.AuditEvent{},
+		nil,
+		nil,
+	)
+}
+
+// WriteAuditEvent writes 'event' to the audit log under the auth etcd prefix
+// 'etcdPrefix'. It's for processes other than the auth server that record
+// events in the audit log, such as pipelines' egress proxies.
+func WriteAuditEvent(ctx context.Context, etcdClient *etcd.Client, etcdPrefix string, event *auth.AuditEvent) error {
+	auditEvents := auditEventsCollection(etcdClient, etcdPrefix)
+	_, err := col.NewSTM(ctx, etcdClient, func(stm col.STM) error {
+		return auditEvents.ReadWrite(stm).Put(uuid.NewWithoutDashes(), event)
+	})
+	return err
+}
+
+// writeAuditEvents writes the events in a.auditQueue to etcd. It runs for the
+// lifetime of the auth server.
+func (a *apiServer) writeAuditEvents() {
+	for event := range a.auditQueue {
+		if err := backoff.RetryNotify(func() error {
+			_, err := col.NewSTM(context.Background(), a.env.GetEtcdClient(), func(stm col.STM) error {
+				return a.auditEvents.ReadWrite(stm).Put(uuid.NewWithoutDashes(), event)
+			})
+			return err
+		}, backoff.NewExponentialBackOff(), func(err error, d time.Duration) error {
+			logrus.Errorf("error writing to audit log: %v; retrying in %v", err, d)
+			return nil
+		}); err != nil {
+			logrus.Errorf("could not record %s by %q in audit log: %v", event.Method, event.Principal, err)
+		}
+	}
+}
+
+// ListAuditEvents implements the protobuf auth.ListAuditEvents RPC
+func (a *apiServer) ListAuditEvents(req *auth.ListAuditEventsRequest, server auth.API_ListAuditEventsServer) (retErr error) {
+	a.LogReq(req)
+	defer func(start time.Time) { a.LogResp(req, nil, retErr, time.Since(start)) }(time.Now())
+	if a.activationState() != full {
+		return auth.ErrNotActivated
+	}
+	ctx := server.Context()
+	callerInfo, err := a.getAuthenticatedUser(ctx)
+	if err != nil {
+		return err
+	}
+	isAdmin, err := a.isAdmin(ctx, callerInfo.Subject)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return &auth.ErrNotAuthorized{
+			Subject: callerInfo.Subject,
+			AdminOp: "ListAuditEvents",
+		}
+	}
+
+	// Events are listed in the order in which they were written, which is
+	// (approximately) the order of their timestamps
+	var event auth.AuditEvent
+	opts := &col.Options{Target: etcd.SortByCreateRevision, Order: etcd.SortAscend}
+	if err := a.auditEvents.ReadOnly(ctx).List(&event, opts, func(string) error {
+		if !auditEventMatches(req, &event) {
+			return nil
+		}
+		return server.Send(&event)
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// auditEventMatches returns true if 'event' matches the filters in 'req'
+func auditEventMatches(req *auth.ListAuditEventsRequest, event *auth.AuditEvent) bool {
+	if req.Principal != "" && event.Principal != req.Principal {
+		return false
+	}
+	t, err := types.TimestampFromProto(event.Time)
+	if err != nil {
+		return false
+	}
+	if from, err := types.TimestampFromProto(req.From); err == nil && t.Before(from) {
+		return false
+	}
+	if to, err := types.TimestampFromProto(req.To); err == nil && !t.Before(to) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestAuditEventMatches(t *testing.T) {
+	now := time.Now()
+	ts := func(d time.Duration) *types.Timestamp {
+		result, err := types.TimestampProto(now.Add(d))
+		require.NoError(t, err)
+		return result
+	}
+	event := &auth.AuditEvent{Time: ts(0), Principal: "github:alice", Method: "/pfs.API/CreateRepo"}
+
+	require.True(t, auditEventMatches(&auth.ListAuditEventsRequest{}, event))
+	require.True(t, auditEventMatches(&auth.ListAuditEventsRequest{Principal: "github:alice"}, event))
+	require.False(t, auditEventMatches(&auth.ListAuditEventsRequest{Principal: "github:bob"}, event))
+
+	// 'from' is inclusive and 'to' is exclusive
+	require.True(t, auditEventMatches(&auth.ListAuditEventsRequest{From: ts(0), To: ts(time.Second)}, event))
+	require.False(t, auditEventMatches(&auth.ListAuditEventsRequest{From: ts(time.Second)}, event))
+	require.False(t, auditEventMatches(&auth.ListAuditEventsRequest{To: ts(0)}, event))
+	require.True(t, auditEventMatches(&auth.ListAuditEventsRequest{From: ts(-time.Hour), Principal: "github:alice"}, event))
+}
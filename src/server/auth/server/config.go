@@ -39,12 +39,23 @@ type canonicalSAMLIDP struct {
 
 type canonicalGitHubIDP struct{}
 
+type canonicalOIDCIDP struct {
+	Issuer           *url.URL
+	ClientID         string
+	ClientSecret     string
+	RedirectURI      *url.URL
+	AdditionalScopes []string
+	UserClaim        string // optional (use defaultOIDCUserClaim if unset)
+	GroupsClaim      string // optional
+}
+
 type canonicalIDPConfig struct {
 	Name        string
 	Description string
 
 	SAML   *canonicalSAMLIDP
 	GitHub *canonicalGitHubIDP
+	OIDC   *canonicalOIDCIDP
 }
 
 type canonicalSAMLSvcConfig struct {
@@ -107,8 +118,22 @@ func (c *canonicalConfig) ToProto() (*auth.AuthConfig, error) {
 				samlIDP.SAML.MetadataURL = idp.SAML.MetadataURL.String()
 			}
 			idpProtos = append(idpProtos, samlIDP)
+		} else if idp.OIDC != nil {
+			idpProtos = append(idpProtos, &auth.IDProvider{
+				Name:        idp.Name,
+				Description: idp.Description,
+				OIDC: &auth.IDProvider_OIDCOptions{
+					Issuer:           idp.OIDC.Issuer.String(),
+					ClientID:         idp.OIDC.ClientID,
+					ClientSecret:     idp.OIDC.ClientSecret,
+					RedirectURI:      idp.OIDC.RedirectURI.String(),
+					AdditionalScopes: idp.OIDC.AdditionalScopes,
+					UserClaim:        idp.OIDC.UserClaim,
+					GroupsClaim:      idp.OIDC.GroupsClaim,
+				},
+			})
 		} else {
-			return nil, fmt.Errorf("could not marshal non-SAML, non-GitHub ID provider %q", idp.Name)
+			return nil, fmt.Errorf("could not marshal ID provider %q of unknown type", idp.Name)
 		}
 	}
 
@@ -198,8 +223,9 @@ func validateIDP(idp *auth.IDProvider, src configSource) (*canonicalIDPConfig, e
 		return nil, fmt.Errorf("cannot configure ID provider with reserved prefix %q", auth.PipelinePrefix)
 	}
 
-	// Check if the IDP is a known type (right now the only types of IDPs are SAML and GitHub)
-	if idp.SAML == nil && idp.GitHub == nil {
+	// Check if the IDP is a known type (right now the only types of IDPs are
+	// SAML, GitHub and OIDC)
+	if idp.SAML == nil && idp.GitHub == nil && idp.OIDC == nil {
 		// render ID provider as json for error message
 		idpConfigAsJSON, err := json.MarshalIndent(idp, "", "  ")
 		idpConfigMsg := string(idpConfigAsJSON)
@@ -211,13 +237,27 @@ func validateIDP(idp *auth.IDProvider, src configSource) (*canonicalIDPConfig, e
 	newIDP := &canonicalIDPConfig{}
 	newIDP.Name = idp.Name
 	newIDP.Description = idp.Description
-	if idp.SAML != nil && idp.GitHub != nil {
-		return nil, errors.New("cannot configure ID provider for both SAML and GitHub")
+	numTypes := 0
+	for _, set := range []bool{idp.SAML != nil, idp.GitHub != nil, idp.OIDC != nil} {
+		if set {
+			numTypes++
+		}
+	}
+	if numTypes > 1 {
+		return nil, fmt.Errorf("ID provider %q must be exactly one of SAML, GitHub or OIDC", idp.Name)
 	}
 	if idp.GitHub != nil {
 		newIDP.GitHub = &canonicalGitHubIDP{}
 		return newIDP, nil
 	}
+	if idp.OIDC != nil {
+		var err error
+		newIDP.OIDC, err = validateOIDCIDP(idp.Name, idp.OIDC)
+		if err != nil {
+			return nil, err
+		}
+		return newIDP, nil
+	}
 	newIDP.SAML = &canonicalSAMLIDP{
 		GroupAttribute: idp.SAML.GroupAttribute,
 	}
@@ -302,6 +342,41 @@ func validateIDP(idp *auth.IDProvider, src configSource) (*canonicalIDPConfig, e
 	return newIDP, nil
 }
 
+// validateOIDCIDP is a helper for validateIDP, that validates the options of
+// an OIDC ID provider
+func validateOIDCIDP(name string, opts *auth.IDProvider_OIDCOptions) (*canonicalOIDCIDP, error) {
+	result := &canonicalOIDCIDP{
+		ClientID:         opts.ClientID,
+		ClientSecret:     opts.ClientSecret,
+		AdditionalScopes: opts.AdditionalScopes,
+		UserClaim:        opts.UserClaim,
+		GroupsClaim:      opts.GroupsClaim,
+	}
+	var err error
+	if opts.Issuer == "" {
+		return nil, fmt.Errorf("must set issuer for the OIDC ID provider %q", name)
+	}
+	if result.Issuer, err = url.Parse(opts.Issuer); err != nil {
+		return nil, fmt.Errorf("could not parse issuer URL (%q) of the OIDC ID "+
+			"provider %q: %v", opts.Issuer, name, err)
+	} else if result.Issuer.Scheme == "" {
+		return nil, fmt.Errorf("issuer URL %q is invalid (no scheme)", opts.Issuer)
+	}
+	if opts.ClientID == "" {
+		return nil, fmt.Errorf("must set client_id for the OIDC ID provider %q", name)
+	}
+	if opts.RedirectURI == "" {
+		return nil, fmt.Errorf("must set redirect_uri for the OIDC ID provider %q", name)
+	}
+	if result.RedirectURI, err = url.Parse(opts.RedirectURI); err != nil {
+		return nil, fmt.Errorf("could not parse redirect URI (%q) of the OIDC ID "+
+			"provider %q: %v", opts.RedirectURI, name, err)
+	} else if result.RedirectURI.Scheme == "" {
+		return nil, fmt.Errorf("redirect URI %q is invalid (no scheme)", opts.RedirectURI)
+	}
+	return result, nil
+}
+
 // validateConfig converts an auth.AuthConfig proto from an RPC into a
 // canonicalized config (with all URLs parsed, SAML metadata fetched and
 // persisted, etc.)
@@ -316,8 +391,17 @@ func validateConfig(config *auth.AuthConfig, src configSource) (*canonicalConfig
 
 	// Validate all ID providers (and fetch IDP metadata for all SAML ID
 	// providers)
-	var samlIDP string
+	var samlIDP, oidcIDP string
 	for _, idp := range config.IDProviders {
+		if idp.OIDC != nil {
+			// confirm that there is only one OIDC IDP, so that 'pachctl auth
+			// login' knows which one to use
+			if oidcIDP != "" {
+				return nil, fmt.Errorf("two OIDC providers found in config, %q and %q, "+
+					"but only one is allowed", idp.Name, oidcIDP)
+			}
+			oidcIDP = idp.Name
+		}
 		if idp.SAML != nil {
 			// confirm that there is only one SAML IDP (requirement for now)
 			if samlIDP != "" {
@@ -0,0 +1,390 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
+	"github.com/pachyderm/pachyderm/src/server/pkg/watch"
+)
+
+const (
+	// oidcCallbackPath is the path (on SamlPort) where OIDC ID providers
+	// redirect users after they log in
+	oidcCallbackPath = "/authorization-code/callback"
+
+	// defaultOIDCUserClaim is the ID token claim that identifies users, if the
+	// OIDC ID provider's config doesn't set user_claim
+	defaultOIDCUserClaim = "email"
+
+	// oidcLoginTimeout is how long Authenticate waits for a user to complete an
+	// OIDC login
+	oidcLoginTimeout = 5 * time.Minute
+
+	// oidcClockSkew is the clock skew that's tolerated when validating the
+	// expiration time of ID tokens
+	oidcClockSkew = time.Minute
+)
+
+// oidcDiscovery is the subset of an OIDC ID provider's discovery document
+// (served at <issuer>/.well-known/openid-configuration) that pachd uses
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// getJSON retrieves the JSON document at 'u' and decodes it into 'out'
+func getJSON(ctx context.Context, u string, out interface{}) error {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", u, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// discoverOIDC retrieves the discovery document of the OIDC ID provider at
+// 'issuer'
+func discoverOIDC(ctx context.Context, issuer *url.URL) (*oidcDiscovery, error) {
+	wellKnown := strings.TrimSuffix(issuer.String(), "/") + "/.well-known/openid-configuration"
+	d := &oidcDiscovery{}
+	if err := getJSON(ctx, wellKnown, d); err != nil {
+		return nil, fmt.Errorf("could not retrieve OIDC discovery document: %v", err)
+	}
+	if strings.TrimSuffix(d.Issuer, "/") != strings.TrimSuffix(issuer.String(), "/") {
+		return nil, fmt.Errorf("OIDC discovery document has issuer %q, but the "+
+			"configured issuer is %q", d.Issuer, issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, fmt.Errorf("OIDC discovery document at %q is missing "+
+			"authorization_endpoint, token_endpoint or jwks_uri", wellKnown)
+	}
+	return d, nil
+}
+
+// oauth2Config returns the OAuth2 client config that pachd uses to log users
+// in with 'idp'
+func (idp *canonicalOIDCIDP) oauth2Config(d *oidcDiscovery) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  d.AuthorizationEndpoint,
+			TokenURL: d.TokenEndpoint,
+		},
+		RedirectURL: idp.RedirectURI.String(),
+		Scopes:      append([]string{"openid", "profile", "email"}, idp.AdditionalScopes...),
+	}
+}
+
+// getOIDCIDP returns the config of the cluster's OIDC ID provider, or nil if
+// none is configured
+func getOIDCIDP(cfg *canonicalConfig) *canonicalIDPConfig {
+	if cfg == nil {
+		return nil
+	}
+	for i := range cfg.IDPs {
+		if cfg.IDPs[i].OIDC != nil {
+			return &cfg.IDPs[i]
+		}
+	}
+	return nil
+}
+
+// verifyIDToken checks the signature and standard claims (issuer, audience,
+// expiration and nonce) of 'rawIDToken', using the keys in 'keys', and returns
+// the token's claims.
+func verifyIDToken(rawIDToken string, keys *jose.JSONWebKeySet, issuer, clientID, nonce string, now time.Time) (map[string]interface{}, error) {
+	token, err := jwt.ParseSigned(rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse ID token: %v", err)
+	}
+	// Try the keys that match the token's key ID (or all keys, if it has none)
+	candidates := keys.Keys
+	for _, header := range token.Headers {
+		if header.KeyID != "" {
+			candidates = keys.Key(header.KeyID)
+		}
+	}
+	var standard jwt.Claims
+	var claims map[string]interface{}
+	verified := false
+	for _, key := range candidates {
+		if err := token.Claims(key.Key, &standard, &claims); err == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("ID token signature could not be verified")
+	}
+	if standard.Expiry == nil {
+		return nil, errors.New("ID token has no expiration time")
+	}
+	if err := standard.ValidateWithLeeway(jwt.Expected{
+		Issuer:   issuer,
+		Audience: jwt.Audience{clientID},
+		Time:     now,
+	}, oidcClockSkew); err != nil {
+		return nil, fmt.Errorf("invalid ID token: %v", err)
+	}
+	if claims["nonce"] != nonce {
+		return nil, errors.New("ID token nonce does not match login")
+	}
+	return claims, nil
+}
+
+// oidcSubject returns the Pachyderm subject and groups of the user that
+// 'idp' authenticated, according to the user's ID token 'claims'
+func oidcSubject(idp *canonicalIDPConfig, claims map[string]interface{}) (string, []string, error) {
+	userClaim := idp.OIDC.UserClaim
+	if userClaim == "" {
+		userClaim = defaultOIDCUserClaim
+	}
+	user, ok := claims[userClaim].(string)
+	if !ok || user == "" {
+		return "", nil, fmt.Errorf("ID token does not contain the claim %q", userClaim)
+	}
+	// Unverified email addresses can't be used to identify users, as anyone
+	// could claim them
+	if userClaim == "email" {
+		if verified, ok := claims["email_verified"].(bool); ok && !verified {
+			return "", nil, fmt.Errorf("email address %q has not been verified", user)
+		}
+	}
+	subject := fmt.Sprintf("%s:%s", idp.Name, user)
+
+	var groups []string
+	if idp.OIDC.GroupsClaim != "" {
+		values, _ := claims[idp.OIDC.GroupsClaim].([]interface{})
+		for _, v := range values {
+			if group, ok := v.(string); ok {
+				groups = append(groups, fmt.Sprintf("group/%s:%s", idp.Name, group))
+			}
+		}
+	}
+	return subject, groups, nil
+}
+
+// GetOIDCLogin implements the protobuf auth.GetOIDCLogin RPC
+func (a *apiServer) GetOIDCLogin(ctx context.Context, req *auth.GetOIDCLoginRequest) (resp *auth.GetOIDCLoginResponse, retErr error) {
+	switch a.activationState() {
+	case none:
+		return nil, auth.ErrNotActivated
+	case partial:
+		return nil, auth.ErrPartiallyActivated
+	}
+	// We don't want to log the response, as its state can be used to
+	// authenticate
+	a.LogReq(req)
+	defer func(start time.Time) { a.LogResp(req, nil, retErr, time.Since(start)) }(time.Now())
+
+	idp := getOIDCIDP(a.getCacheConfig())
+	if idp == nil {
+		return nil, auth.ErrOIDCNotConfigured
+	}
+	d, err := discoverOIDC(ctx, idp.OIDC.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	state, nonce := uuid.NewWithoutDashes(), uuid.NewWithoutDashes()
+	if _, err := col.NewSTM(ctx, a.env.GetEtcdClient(), func(stm col.STM) error {
+		return a.oidcStates.ReadWrite(stm).PutTTL(hashToken(state),
+			&auth.OIDCState{Nonce: nonce}, oidcStateTTLSecs)
+	}); err != nil {
+		return nil, fmt.Errorf("error storing OIDC login state: %v", err)
+	}
+	return &auth.GetOIDCLoginResponse{
+		LoginURL: idp.OIDC.oauth2Config(d).AuthCodeURL(state, oauth2.SetAuthURLParam("nonce", nonce)),
+		State:    state,
+	}, nil
+}
+
+// awaitOIDCLogin waits for the caller to complete the OIDC login identified
+// by 'state' (i.e. for pachd's OIDC callback to record the user's identity),
+// consumes the login, and returns the authenticated subject.
+func (a *apiServer) awaitOIDCLogin(ctx context.Context, state string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, oidcLoginTimeout)
+	defer cancel()
+	key := hashToken(state)
+	var oidcState auth.OIDCState
+	if err := a.oidcStates.ReadOnly(ctx).Get(key, &oidcState); err != nil {
+		if col.IsErrNotFound(err) {
+			return "", errors.New("OIDC login is invalid or has expired")
+		}
+		return "", err
+	}
+	if err := a.oidcStates.ReadOnly(ctx).WatchOneF(key, func(e *watch.Event) error {
+		switch e.Type {
+		case watch.EventPut:
+			var k string
+			if err := e.Unmarshal(&k, &oidcState); err != nil {
+				return err
+			}
+			if oidcState.Subject != "" || oidcState.Error != "" {
+				return errutil.ErrBreak
+			}
+		case watch.EventDelete:
+			return errors.New("OIDC login has expired")
+		case watch.EventError:
+			return e.Err
+		}
+		return nil
+	}); err != nil {
+		if err == context.DeadlineExceeded {
+			return "", errors.New("timed out waiting for OIDC login")
+		}
+		return "", err
+	}
+	// Consume the login, so that it can't be used twice
+	if _, err := col.NewSTM(ctx, a.env.GetEtcdClient(), func(stm col.STM) error {
+		oidcStates := a.oidcStates.ReadWrite(stm)
+		var current auth.OIDCState
+		if err := oidcStates.Get(key, &current); err != nil {
+			if col.IsErrNotFound(err) {
+				return errors.New("OIDC login has already been used or has expired")
+			}
+			return err
+		}
+		return oidcStates.Delete(key)
+	}); err != nil {
+		return "", err
+	}
+	if oidcState.Error != "" {
+		return "", fmt.Errorf("OIDC login failed: %s", oidcState.Error)
+	}
+	return oidcState.Subject, nil
+}
+
+// handleOIDCCallbackInternal is a helper function called by
+// handleOIDCCallback. It returns the subject that logged in.
+func (a *apiServer) handleOIDCCallbackInternal(req *http.Request) (string, *errutil.HTTPError) {
+	ctx := req.Context()
+	query := req.URL.Query()
+	state := query.Get("state")
+	if state == "" {
+		return "", errutil.NewHTTPError(http.StatusBadRequest, "OIDC callback is missing the state parameter")
+	}
+	key := hashToken(state)
+	var oidcState auth.OIDCState
+	if err := a.oidcStates.ReadOnly(ctx).Get(key, &oidcState); err != nil {
+		if col.IsErrNotFound(err) {
+			return "", errutil.NewHTTPError(http.StatusBadRequest, "OIDC login is invalid or has expired (try logging in again)")
+		}
+		return "", errutil.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if oidcState.Subject != "" || oidcState.Error != "" {
+		return "", errutil.NewHTTPError(http.StatusBadRequest, "OIDC login has already completed")
+	}
+
+	subject, loginErr := a.verifyOIDCLogin(ctx, query, oidcState.Nonce)
+	// Record the outcome for Authenticate (which is waiting for it)
+	if loginErr != nil {
+		oidcState.Error = loginErr.Error()
+	} else {
+		oidcState.Subject = subject
+	}
+	if _, err := col.NewSTM(ctx, a.env.GetEtcdClient(), func(stm col.STM) error {
+		return a.oidcStates.ReadWrite(stm).PutTTL(key, &oidcState, oidcStateTTLSecs)
+	}); err != nil {
+		return "", errutil.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if loginErr != nil {
+		return "", errutil.NewHTTPError(http.StatusUnauthorized, "Could not log in: %v", loginErr)
+	}
+	return subject, nil
+}
+
+// verifyOIDCLogin exchanges the authorization code in the OIDC callback
+// parameters 'query' for an ID token, verifies the token, updates the user's
+// group memberships (if the ID provider is configured to pass them), and
+// returns the user's subject.
+func (a *apiServer) verifyOIDCLogin(ctx context.Context, query url.Values, nonce string) (string, error) {
+	if errCode := query.Get("error"); errCode != "" {
+		return "", fmt.Errorf("ID provider returned an error: %s %s", errCode, query.Get("error_description"))
+	}
+	code := query.Get("code")
+	if code == "" {
+		return "", errors.New("OIDC callback is missing the code parameter")
+	}
+	idp := getOIDCIDP(a.getCacheConfig())
+	if idp == nil {
+		return "", errors.New("no OIDC ID provider is configured")
+	}
+	d, err := discoverOIDC(ctx, idp.OIDC.Issuer)
+	if err != nil {
+		return "", err
+	}
+	token, err := idp.OIDC.oauth2Config(d).Exchange(ctx, code)
+	if err != nil {
+		return "", fmt.Errorf("could not exchange authorization code: %v", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return "", errors.New("ID provider did not return an ID token")
+	}
+	keys := &jose.JSONWebKeySet{}
+	if err := getJSON(ctx, d.JWKSURI, keys); err != nil {
+		return "", fmt.Errorf("could not retrieve ID provider's signing keys: %v", err)
+	}
+	claims, err := verifyIDToken(rawIDToken, keys, d.Issuer, idp.OIDC.ClientID, nonce, time.Now())
+	if err != nil {
+		return "", err
+	}
+	subject, groups, err := oidcSubject(idp, claims)
+	if err != nil {
+		return "", err
+	}
+	if idp.OIDC.GroupsClaim != "" {
+		if err := a.setGroupsForUserInternal(ctx, subject, groups); err != nil {
+			return "", err
+		}
+	}
+	return subject, nil
+}
+
+// handleOIDCCallback is the HTTP handler for pachd's OIDC callback, where the
+// cluster's OIDC ID provider (if one is configured) redirects users after they
+// log in
+func (a *apiServer) handleOIDCCallback(w http.ResponseWriter, req *http.Request) {
+	var subject string
+	var err *errutil.HTTPError
+
+	logRequest := "OIDC login request"
+	a.LogReq(logRequest)
+	defer func(start time.Time) {
+		if subject != "" {
+			logRequest = fmt.Sprintf("OIDC login request for %s", subject)
+		}
+		a.LogResp(logRequest, errutil.PrettyPrintCode(err), err, time.Since(start))
+	}(time.Now())
+
+	subject, err = a.handleOIDCCallbackInternal(req)
+	if err != nil {
+		http.Error(w, err.Error(), err.Code())
+		return
+	}
+	fmt.Fprintf(w, "You are now logged in to Pachyderm as %s. You may close this window.\n", subject)
+}
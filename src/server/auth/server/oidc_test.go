@@ -0,0 +1,79 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestVerifyIDToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keys := &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: &key.PublicKey, KeyID: "k1", Algorithm: string(jose.RS256), Use: "sig"},
+	}}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithHeader("kid", "k1"))
+	require.NoError(t, err)
+
+	now := time.Now()
+	sign := func(issuer, audience, nonce string) string {
+		token, err := jwt.Signed(signer).Claims(jwt.Claims{
+			Issuer:   issuer,
+			Audience: jwt.Audience{audience},
+			Subject:  "1234",
+			Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt: jwt.NewNumericDate(now),
+		}).Claims(map[string]interface{}{
+			"nonce":          nonce,
+			"email":          "alice@example.com",
+			"email_verified": true,
+			"groups":         []string{"eng", "ops"},
+		}).CompactSerialize()
+		require.NoError(t, err)
+		return token
+	}
+
+	claims, err := verifyIDToken(sign("https://idp", "pach", "n0nce"), keys, "https://idp", "pach", "n0nce", now)
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", claims["email"])
+
+	// Tokens for another issuer, client or login are rejected
+	_, err = verifyIDToken(sign("https://other", "pach", "n0nce"), keys, "https://idp", "pach", "n0nce", now)
+	require.YesError(t, err)
+	_, err = verifyIDToken(sign("https://idp", "other", "n0nce"), keys, "https://idp", "pach", "n0nce", now)
+	require.YesError(t, err)
+	_, err = verifyIDToken(sign("https://idp", "pach", "other"), keys, "https://idp", "pach", "n0nce", now)
+	require.YesError(t, err)
+	// Expired tokens are rejected
+	_, err = verifyIDToken(sign("https://idp", "pach", "n0nce"), keys, "https://idp", "pach", "n0nce", now.Add(2*time.Hour))
+	require.YesError(t, err)
+	// Tokens signed with another key are rejected
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKeys := &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &otherKey.PublicKey, KeyID: "k1"}}}
+	_, err = verifyIDToken(sign("https://idp", "pach", "n0nce"), otherKeys, "https://idp", "pach", "n0nce", now)
+	require.YesError(t, err)
+
+	// The subject and groups are read from the configured claims
+	idp := &canonicalIDPConfig{Name: "okta", OIDC: &canonicalOIDCIDP{GroupsClaim: "groups"}}
+	subject, groups, err := oidcSubject(idp, claims)
+	require.NoError(t, err)
+	require.Equal(t, "okta:alice@example.com", subject)
+	require.Equal(t, []string{"group/okta:eng", "group/okta:ops"}, groups)
+
+	idp.OIDC.UserClaim = "preferred_username"
+	_, _, err = oidcSubject(idp, claims)
+	require.YesError(t, err)
+
+	claims["email_verified"] = false
+	idp.OIDC.UserClaim = ""
+	_, _, err = oidcSubject(idp, claims)
+	require.YesError(t, err)
+}
@@ -151,6 +151,7 @@ func (a *apiServer) serveSAML() {
 	samlMux := http.NewServeMux()
 	samlMux.HandleFunc("/saml/acs", a.handleSAMLResponse)
 	samlMux.HandleFunc("/saml/metadata", a.handleMetadata)
+	samlMux.HandleFunc(oidcCallbackPath, a.handleOIDCCallback)
 	samlMux.HandleFunc("/*", func(w http.ResponseWriter, req *http.Request) {
 		w.WriteHeader(http.StatusTeapot)
 	})
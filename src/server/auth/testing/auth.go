@@ -2,6 +2,7 @@ package testing
 
 import (
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
 
 	"github.com/pachyderm/pachyderm/src/client/auth"
 	txnenv "github.com/pachyderm/pachyderm/src/server/pkg/transactionenv"
@@ -38,6 +39,11 @@ func (a *InactiveAPIServer) Authenticate(context.Context, *auth.AuthenticateRequ
 	return nil, auth.ErrNotActivated
 }
 
+// GetOIDCLogin implements the GetOIDCLogin RPC, but just returns NotActivatedError
+func (a *InactiveAPIServer) GetOIDCLogin(context.Context, *auth.GetOIDCLoginRequest) (*auth.GetOIDCLoginResponse, error) {
+	return nil, auth.ErrNotActivated
+}
+
 // Authorize implements the Authorize RPC, but just returns NotActivatedError
 func (a *InactiveAPIServer) Authorize(context.Context, *auth.AuthorizeRequest) (*auth.AuthorizeResponse, error) {
 	return nil, auth.ErrNotActivated
@@ -147,3 +153,14 @@ func (a *InactiveAPIServer) GetConfiguration(context.Context, *auth.GetConfigura
 func (a *InactiveAPIServer) GetOneTimePassword(context.Context, *auth.GetOneTimePasswordRequest) (*auth.GetOneTimePasswordResponse, error) {
 	return nil, auth.ErrNotActivated
 }
+
+// ListAuditEvents implements the ListAuditEvents RPC, but just returns NotActivatedError
+func (a *InactiveAPIServer) ListAuditEvents(*auth.ListAuditEventsRequest, auth.API_ListAuditEventsServer) error {
+	return auth.ErrNotActivated
+}
+
+// AuditInterceptors implements the AuditInterceptors method of the auth
+// server, but returns nil interceptors, as nothing is audited
+func (a *InactiveAPIServer) AuditInterceptors() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	return nil, nil
+}
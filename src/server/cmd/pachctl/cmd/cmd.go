@@ -56,8 +56,15 @@ __pachctl_get_object() {
 	fi
 }
 
+# $1: kind of object (repo, branch, pipeline or job)
+# $2: repo name, if $1 is branch
+__pachctl_get_names() {
+	local out=($(pachctl completion values $1 $2 2>/dev/null))
+	COMPREPLY+=($(compgen -P "${__pachctl_prefix}" -S "${__pachctl_suffix}" -W "${out[*]}" "$cur"))
+}
+
 __pachctl_get_repo() {
-	__pachctl_get_object "list repo" 1
+	__pachctl_get_names repo
 }
 
 # $1: repo name
@@ -66,7 +73,7 @@ __pachctl_get_commit() {
 		return
 	fi
 	__pachctl_get_object "list commit $1" 2
-	__pachctl_get_object "list branch $1" 1
+	__pachctl_get_names branch $1
 }
 
 # Performs completion of the standard format <repo>@<branch-or-commit>
@@ -133,15 +140,15 @@ __pachctl_get_branch() {
 	if [[ -z $1 ]]; then
 		return
 	fi
-	__pachctl_get_object "list branch $1" 1
+	__pachctl_get_names branch $1
 }
 
 __pachctl_get_job() {
-	__pachctl_get_object "list job" 1
+	__pachctl_get_names job
 }
 
 __pachctl_get_pipeline() {
-	__pachctl_get_object "list pipeline" 1
+	__pachctl_get_names pipeline
 }
 
 __pachctl_get_datum() {
@@ -396,6 +403,7 @@ Environment variables:
 			// Dial pachd & get server version
 			var pachClient *client.APIClient
 			var err error
+			rpcTimeout := time.Second
 			if timeoutFlag != "default" {
 				var timeout time.Duration
 				timeout, err = time.ParseDuration(timeoutFlag)
@@ -403,6 +411,7 @@ Environment variables:
 					return fmt.Errorf("could not parse timeout duration %q: %v", timeout, err)
 				}
 				pachClient, err = client.NewOnUserMachine("user", client.WithDialTimeout(timeout))
+				rpcTimeout = timeout
 			} else {
 				pachClient, err = client.NewOnUserMachine("user")
 			}
@@ -410,7 +419,10 @@ Environment variables:
 				return err
 			}
 			defer pachClient.Close()
-			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			ctx, cancel := context.Background(), func() {}
+			if rpcTimeout > 0 {
+				ctx, cancel = context.WithTimeout(ctx, rpcTimeout)
+			}
 			defer cancel()
 			version, err := pachClient.GetVersion(ctx, &types.Empty{})
 
@@ -429,9 +441,23 @@ Environment variables:
 				}
 			} else {
 				printVersion(writer, "pachd", version)
+				// Check the health of the connection, timing a round trip
+				healthStart := time.Now()
+				if err := pachClient.WithCtx(ctx).Health(); err != nil {
+					fmt.Fprintf(os.Stderr, "WARNING: pachd at %s is unhealthy: %s\n", pachClient.GetAddress(), status.Convert(err).Message())
+				} else {
+					fmt.Fprintf(writer, "connection\thealthy (%v round trip)\t\n", time.Since(healthStart).Round(time.Millisecond))
+				}
 				if err := writer.Flush(); err != nil {
 					return err
 				}
+				// Warn about incompatibilities between pachctl and pachd, but
+				// don't fail, as the versions have been printed already
+				if negotiated, err := pachClient.WithCtx(ctx).Negotiate(); err == nil {
+					for _, warning := range negotiated.Warnings {
+						fmt.Fprintf(os.Stderr, "WARNING: %s\n", warning)
+					}
+				}
 			}
 			return nil
 		}),
@@ -443,14 +469,33 @@ Environment variables:
 		"'pachctl version' will timeout after the given duration (formatted as a "+
 		"golang time duration--a number followed by ns, us, ms, s, m, or h). If "+
 		"--client-only is set, this flag is ignored. If unset, pachctl will use a "+
-		"default timeout; if set to 0s, the call will never time out.")
+		"default timeout; if set to 0s, the call will never time out. The "+
+		"timeout also bounds the check of the connection's health.")
 	versionCmd.Flags().AddFlagSet(rawFlags)
 	subcommands = append(subcommands, cmdutil.CreateAlias(versionCmd, "version"))
 
 	var maxCompletions int64
 	shellCmd := &cobra.Command{
 		Short: "Run the pachyderm shell.",
-		Long:  "Run the pachyderm shell.",
+		Long: `Run the pachyderm shell, which runs pachctl commands with tab completion
+of commands, repos, branches, files, pipelines and jobs (press F5 to refresh
+the completions). Commands are saved to ~/.pachyderm/shell_history, so that
+they can be recalled (with the up arrow) in later sessions.
+
+The shell also has builtin commands that navigate PFS like a filesystem:
+  cd <repo>[@<branch-or-commit>][:<path>]  change the working directory (to
+                                           master, if no branch is given)
+  cd <path>                                change to a directory in the
+                                           working commit ('..' from its
+                                           root leaves the repo)
+  ls [<path>...]                           list the repos, or files
+  cat <path>...                            print files
+  pwd                                      print the working directory
+  exit                                     exit the shell (or press Ctrl-D)`,
+		Example: `
+>>> cd images@master
+>>> ls
+>>> cat data/cat.png > cat.png`,
 		Run: cmdutil.RunFixedArgs(0, func(args []string) (retErr error) {
 			cfg, err := config.Read(true)
 			if err != nil {
@@ -683,6 +728,29 @@ This resets the cluster to its initial state.`,
 	completionZsh.Flags().StringVar(&installPathZsh, "path", "_pachctl", "Path to install the completions to.")
 	subcommands = append(subcommands, cmdutil.CreateAlias(completionZsh, "completion zsh"))
 
+	completionValues := &cobra.Command{
+		Use:   "{{alias}} (repo | branch <repo> | pipeline | job)",
+		Short: "Print the names of Pachyderm resources, for shell completion.",
+		Long: "Print the names of Pachyderm resources, one per line, for shell completion. " +
+			"Names are fetched with a single RPC and cached for 30 seconds, so this is fast " +
+			"enough to run for each completion.",
+		Run: cmdutil.RunBoundedArgs(1, 2, func(args []string) error {
+			var repo string
+			if len(args) > 1 {
+				repo = args[1]
+			}
+			names, err := completionNames(args[0], repo)
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				fmt.Println(name)
+			}
+			return nil
+		}),
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(completionValues, "completion values"))
+
 	// Logical commands for grouping commands by verb (no run functions)
 	completionDocs := &cobra.Command{
 		Short: "Print or install terminal completion code.",
@@ -786,12 +854,36 @@ This resets the cluster to its initial state.`,
 	}
 	subcommands = append(subcommands, cmdutil.CreateAlias(restartDocs, "restart"))
 
+	promoteDocs := &cobra.Command{
+		Short: "Move the label of a Pachyderm resource to a new version.",
+		Long:  "Move the label of a Pachyderm resource to a new version.",
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(promoteDocs, "promote"))
+
+	replayDocs := &cobra.Command{
+		Short: "Re-run a finished task and compare the results.",
+		Long:  "Re-run a finished task and compare the results.",
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(replayDocs, "replay"))
+
 	resumeDocs := &cobra.Command{
 		Short: "Resume a stopped task.",
 		Long:  "Resume a stopped task.",
 	}
 	subcommands = append(subcommands, cmdutil.CreateAlias(resumeDocs, "resume"))
 
+	setDocs := &cobra.Command{
+		Short: "Set a property of an existing Pachyderm resource.",
+		Long:  "Set a property of an existing Pachyderm resource.",
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(setDocs, "set"))
+
+	instantiateDocs := &cobra.Command{
+		Short: "Create Pachyderm resources from a template.",
+		Long:  "Create Pachyderm resources from a template.",
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(instantiateDocs, "instantiate"))
+
 	runDocs := &cobra.Command{
 		Short: "Manually run a Pachyderm resource.",
 		Long:  "Manually run a Pachyderm resource.",
@@ -860,9 +952,13 @@ func applyRootUsageFunc(rootCmd *cobra.Command) {
 			"get",
 			"glob",
 			"inspect",
+			"instantiate",
 			"list",
+			"promote",
 			"put",
+			"replay",
 			"restart",
+			"set",
 			"start",
 			"stop",
 			"subscribe",
@@ -870,6 +966,7 @@ func applyRootUsageFunc(rootCmd *cobra.Command) {
 			actions = append(actions, subcmd)
 		case
 			"deploy",
+			"doctor",
 			"undeploy",
 			"extract",
 			"restore",
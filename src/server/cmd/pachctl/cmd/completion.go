@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/config"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+const (
+	// completionCacheTTL is how long the names fetched for shell completion
+	// are reused for, so that completing each word of a command doesn't need
+	// an RPC
+	completionCacheTTL = 30 * time.Second
+	// completionJobLimit is the number of (most recent) job IDs that are
+	// offered as completions
+	completionJobLimit = 100
+)
+
+// completionKinds maps the kinds accepted by 'pachctl completion values' to
+// ListNames kinds
+var completionKinds = map[string]pps.ListNamesRequest_Kind{
+	"repo":     pps.ListNamesRequest_REPO,
+	"branch":   pps.ListNamesRequest_BRANCH,
+	"pipeline": pps.ListNamesRequest_PIPELINE,
+	"job":      pps.ListNamesRequest_JOB,
+}
+
+// completionNames returns the names of the objects of the given kind in the
+// active context's cluster ('repo' is only used to list branches). Names are
+// cached on disk for completionCacheTTL.
+func completionNames(kind string, repo string) ([]string, error) {
+	k, ok := completionKinds[kind]
+	if !ok {
+		return nil, fmt.Errorf("unrecognized kind %q (must be repo, branch, pipeline or job)", kind)
+	}
+	if k == pps.ListNamesRequest_BRANCH && repo == "" {
+		return nil, fmt.Errorf("must specify a repo to complete branch names")
+	}
+
+	cachePath, err := completionCachePath(kind, repo)
+	if err == nil {
+		if info, err := os.Stat(cachePath); err == nil && time.Since(info.ModTime()) < completionCacheTTL {
+			if data, err := ioutil.ReadFile(cachePath); err == nil {
+				return strings.Fields(string(data)), nil
+			}
+		}
+	}
+
+	c, err := client.NewOnUserMachine("user-completion")
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	var limit int64
+	if k == pps.ListNamesRequest_JOB {
+		limit = completionJobLimit
+	}
+	names, err := c.ListNames(k, repo, limit)
+	if err != nil {
+		return nil, err
+	}
+	if cachePath != "" {
+		// Failing to write the cache only makes the next completion slower
+		if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+			ioutil.WriteFile(cachePath, []byte(strings.Join(names, "\n")), 0644)
+		}
+	}
+	return names, nil
+}
+
+// completionCachePath returns the path of the file that caches the names of
+// the objects of the given kind, which is specific to the active context
+func completionCachePath(kind string, repo string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	cfg, err := config.Read(false)
+	if err != nil {
+		return "", err
+	}
+	contextName, _, err := cfg.ActiveContext()
+	if err != nil {
+		return "", err
+	}
+	name := kind
+	if repo != "" {
+		name += "-" + url.PathEscape(repo)
+	}
+	return filepath.Join(cacheDir, "pachyderm", "completion", url.PathEscape(contextName), name), nil
+}
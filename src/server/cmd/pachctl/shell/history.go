@@ -0,0 +1,71 @@
+package shell
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxHistory is the number of commands kept in the shell's history file
+const maxHistory = 1000
+
+// historyPath returns the path of the shell's history file, which is kept
+// with pachctl's config
+func historyPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".pachyderm", "shell_history")
+}
+
+// history is the shell's command history, which is saved to a file so that
+// it persists across sessions
+type history struct {
+	path string
+	cmds []string
+}
+
+// newHistory reads the history saved at 'path', if any
+func newHistory(path string) *history {
+	h := &history{path: path}
+	if data, err := ioutil.ReadFile(path); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if line != "" {
+				h.cmds = append(h.cmds, line)
+			}
+		}
+	}
+	if len(h.cmds) > maxHistory {
+		h.cmds = h.cmds[len(h.cmds)-maxHistory:]
+	}
+	return h
+}
+
+// lines returns the commands in the history, oldest first
+func (h *history) lines() []string {
+	return h.cmds
+}
+
+// add adds 'cmd' to the history and saves it. Repeats of the most recent
+// command aren't added.
+func (h *history) add(cmd string) error {
+	if n := len(h.cmds); n > 0 && h.cmds[n-1] == cmd {
+		return nil
+	}
+	h.cmds = append(h.cmds, cmd)
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return err
+	}
+	if len(h.cmds) > maxHistory {
+		// Rewrite the file without the oldest commands
+		h.cmds = h.cmds[len(h.cmds)-maxHistory:]
+		return ioutil.WriteFile(h.path, []byte(strings.Join(h.cmds, "\n")+"\n"), 0600)
+	}
+	f, err := os.OpenFile(h.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(cmd + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
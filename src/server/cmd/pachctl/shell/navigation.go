@@ -0,0 +1,233 @@
+package shell
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/server/pkg/cmdutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
+
+	prompt "github.com/c-bata/go-prompt"
+)
+
+// location is the shell's working directory: a directory in a commit (or
+// branch) of a repo. The zero location is the top level, which contains all
+// of the cluster's repos.
+type location struct {
+	repo   string
+	commit string
+	path   string
+}
+
+// String renders 'l' in the <repo>@<branch-or-commit>:<path> form that
+// pachctl commands take, or returns "" if 'l' is the top level
+func (l location) String() string {
+	if l.repo == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s@%s:%s", l.repo, l.commit, l.path)
+}
+
+// resolve returns the location that 'arg' refers to, relative to 'l'. 'arg'
+// may be:
+//   - empty, or "/", "." or ".." at the top level: the top level
+//   - <repo>[@<branch-or-commit>[:<path>]]: a location in any repo (the branch
+//     defaults to master). At the top level, the repo's name is enough.
+//   - a path: a directory in the current commit, e.g. "/", "..", "data/2020".
+//     ".." from the root of a commit is the top level.
+func (l location) resolve(arg string) (location, error) {
+	switch {
+	case arg == "" || (l.repo == "" && (arg == "/" || arg == "." || arg == "..")):
+		return location{}, nil
+	case strings.Contains(arg, "@") || l.repo == "":
+		file, err := cmdutil.ParseFile(arg)
+		if err != nil {
+			return location{}, err
+		}
+		if l.repo == "" && strings.Contains(file.Commit.Repo.Name, "/") {
+			return location{}, fmt.Errorf("%q is not a repo", arg)
+		}
+		result := location{repo: file.Commit.Repo.Name, commit: file.Commit.ID, path: path.Join("/", file.Path)}
+		if result.commit == "" {
+			result.commit = "master"
+		}
+		return result, nil
+	}
+	p := arg
+	if !strings.HasPrefix(p, "/") {
+		if l.path == "/" && (p == ".." || strings.HasPrefix(p, "../")) {
+			// Leave the commit for the top level, and resolve the rest from there
+			return location{}.resolve(strings.TrimPrefix(strings.TrimPrefix(p, ".."), "/"))
+		}
+		p = path.Join(l.path, p)
+	}
+	return location{repo: l.repo, commit: l.commit, path: path.Clean(p)}, nil
+}
+
+// builtins are the shell's own commands, which navigate PFS from the shell's
+// working directory. All other input is run as a pachctl command.
+var builtins = []prompt.Suggest{
+	{Text: "cd", Description: "Change the working directory to a repo, <repo>@<branch> or a directory in it."},
+	{Text: "ls", Description: "List the repos, or the files in the working directory (or in a path relative to it)."},
+	{Text: "cat", Description: "Print files in the working directory (or paths relative to it)."},
+	{Text: "pwd", Description: "Print the working directory."},
+	{Text: "exit", Description: "Exit the shell."},
+}
+
+func isBuiltin(name string) bool {
+	for _, b := range builtins {
+		if b.Text == name {
+			return true
+		}
+	}
+	return false
+}
+
+// runBuiltin runs 'args' if it's a builtin command, returning false if it
+// isn't one
+func (s *shell) runBuiltin(args []string) bool {
+	if len(args) == 0 || !isBuiltin(args[0]) {
+		return false
+	}
+	if err := s.builtin(args[0], args[1:]); err != nil {
+		fmt.Printf("%s: %v\n", args[0], err)
+	}
+	return true
+}
+
+func (s *shell) builtin(name string, args []string) error {
+	switch name {
+	case "cd":
+		if len(args) > 1 {
+			return fmt.Errorf("too many arguments")
+		}
+		var arg string
+		if len(args) == 1 {
+			arg = args[0]
+		}
+		target, err := s.cwd.resolve(arg)
+		if err != nil {
+			return err
+		}
+		if err := checkDir(target); err != nil {
+			return err
+		}
+		s.cwd = target
+	case "pwd":
+		if s.cwd.repo == "" {
+			fmt.Println("/")
+		} else {
+			fmt.Println(s.cwd)
+		}
+	case "ls":
+		if len(args) == 0 {
+			s.ls(s.cwd)
+		}
+		for _, arg := range args {
+			target, err := s.cwd.resolve(arg)
+			if err != nil {
+				return err
+			}
+			s.ls(target)
+		}
+	case "cat":
+		if len(args) == 0 {
+			return fmt.Errorf("a file is required")
+		}
+		for _, arg := range args {
+			target, err := s.cwd.resolve(arg)
+			if err != nil {
+				return err
+			}
+			if target.repo == "" {
+				return fmt.Errorf("%q is not a file", arg)
+			}
+			s.execute("get file " + quote(target.String()))
+		}
+	case "exit":
+		s.exit()
+	}
+	return nil
+}
+
+// ls lists the repos, if 'l' is the top level, or the files in 'l'
+func (s *shell) ls(l location) {
+	if l.repo == "" {
+		s.execute("list repo")
+		return
+	}
+	s.execute("list file " + quote(l.String()))
+}
+
+// checkDir returns an error if 'l' isn't a directory that the shell can
+// change to. A branch with no commits yet is treated as empty.
+func checkDir(l location) error {
+	if l.repo == "" {
+		return nil
+	}
+	c := getPachClient()
+	if _, err := c.InspectRepo(l.repo); err != nil {
+		return err
+	}
+	if l.path == "/" {
+		return nil
+	}
+	fi, err := c.InspectFile(l.repo, l.commit, l.path)
+	if err != nil {
+		return err
+	}
+	if fi.FileType != pfs.FileType_DIR {
+		return fmt.Errorf("%s is not a directory", l)
+	}
+	return nil
+}
+
+// quote quotes 's' for bash, which the shell runs pachctl commands with
+func quote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// navigationCompletion completes the argument 'text' of a builtin command,
+// relative to the working directory 'cwd'. Directories are completed with a
+// trailing "/", so that their contents can be completed next.
+func navigationCompletion(cwd location, text string, maxCompletions int64) ([]prompt.Suggest, CacheFunc) {
+	if strings.Contains(text, "@") {
+		return FileCompletion("", text, maxCompletions)
+	}
+	if cwd.repo == "" {
+		return RepoCompletion("", text, maxCompletions)
+	}
+	dir := ""
+	if i := strings.LastIndex(text, "/"); i >= 0 {
+		dir = text[:i+1]
+	}
+	target := cwd
+	if dir != "" {
+		var err error
+		if target, err = cwd.resolve(dir); err != nil || target.repo == "" {
+			return nil, CacheNone
+		}
+	}
+	var result []prompt.Suggest
+	if err := getPachClient().ListFileF(target.repo, target.commit, target.path, 0, func(fi *pfs.FileInfo) error {
+		if maxCompletions > 0 {
+			maxCompletions--
+		} else {
+			return errutil.ErrBreak
+		}
+		name := dir + path.Base(fi.File.Path)
+		if fi.FileType == pfs.FileType_DIR {
+			name += "/"
+		}
+		result = append(result, prompt.Suggest{Text: name})
+		return nil
+	}); err != nil {
+		return nil, CacheNone
+	}
+	// The results can be reused until the user types into another directory
+	return result, func(_, text string) bool {
+		return strings.HasPrefix(text, dir) && !strings.Contains(text[len(dir):], "/")
+	}
+}
@@ -0,0 +1,68 @@
+package shell
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestResolve(t *testing.T) {
+	top := location{}
+	images := location{repo: "images", commit: "master", path: "/"}
+	data := location{repo: "images", commit: "master", path: "/data"}
+
+	for _, c := range []struct {
+		from     location
+		arg      string
+		expected location
+	}{
+		{top, "images", images},
+		{top, "images@master:/data", data},
+		{top, "..", top},
+		{images, "", top},
+		{images, "data", data},
+		{images, "./data/", data},
+		{images, "..", top},
+		{images, "../edges", location{repo: "edges", commit: "master", path: "/"}},
+		{data, "..", images},
+		{data, "/", images},
+		{data, "2020/01", location{repo: "images", commit: "master", path: "/data/2020/01"}},
+		{data, "edges@v1", location{repo: "edges", commit: "v1", path: "/"}},
+	} {
+		result, err := c.from.resolve(c.arg)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, result, "resolving %q from %q", c.arg, c.from)
+	}
+
+	_, err := top.resolve("images/data")
+	require.YesError(t, err)
+	_, err = top.resolve("@master")
+	require.YesError(t, err)
+}
+
+func TestHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shell")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".pachyderm", "shell_history")
+
+	h := newHistory(path)
+	require.Equal(t, 0, len(h.lines()))
+	require.NoError(t, h.add("list repo"))
+	require.NoError(t, h.add("list repo"))
+	require.NoError(t, h.add("cd images"))
+	require.Equal(t, []string{"list repo", "cd images"}, newHistory(path).lines())
+
+	// Only the most recent commands are kept
+	for i := 0; i < maxHistory; i++ {
+		require.NoError(t, h.add(fmt.Sprintf("inspect job %d", i)))
+	}
+	lines := newHistory(path).lines()
+	require.Equal(t, maxHistory, len(lines))
+	require.Equal(t, "inspect job 0", lines[0])
+	require.Equal(t, fmt.Sprintf("inspect job %d", maxHistory-1), lines[maxHistory-1])
+}
@@ -18,6 +18,9 @@ const (
 	completionAnnotation  string = "completion"
 	ldThreshold           int    = 2
 	defaultMaxCompletions int64  = 64
+	// builtinCompletionID is the completionID of the builtin commands'
+	// completions
+	builtinCompletionID string = "builtin"
 )
 
 // CacheFunc is a function which returns whether or not cached results from a
@@ -75,6 +78,10 @@ func RegisterCompletionFunc(cmd *cobra.Command, completionFunc CompletionFunc) {
 type shell struct {
 	rootCmd        *cobra.Command
 	maxCompletions int64
+	history        *history
+
+	// cwd is the working directory of the builtin commands (see builtins)
+	cwd location
 
 	// variables for caching completion calls
 	completionID string
@@ -89,10 +96,25 @@ func newShell(rootCmd *cobra.Command, maxCompletions int64) *shell {
 	return &shell{
 		rootCmd:        rootCmd,
 		maxCompletions: maxCompletions,
+		history:        newHistory(historyPath()),
 	}
 }
 
 func (s *shell) executor(in string) {
+	if strings.TrimSpace(in) == "" {
+		return
+	}
+	if err := s.history.add(in); err != nil {
+		fmt.Fprintf(os.Stderr, "could not save history: %v\n", err)
+	}
+	if s.runBuiltin(strings.Fields(in)) {
+		return
+	}
+	s.execute(in)
+}
+
+// execute runs the pachctl command 'in'
+func (s *shell) execute(in string) {
 	cmd := exec.Command("bash")
 	cmd.Stdin = strings.NewReader("pachctl " + in)
 	cmd.Stdout = os.Stdout
@@ -100,6 +122,15 @@ func (s *shell) executor(in string) {
 	cmd.Run()
 }
 
+// exit exits the shell. It's only called by the executor, when the terminal
+// isn't in raw mode, so it can exit without restoring the terminal.
+func (s *shell) exit() {
+	if err := closePachClient(); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(0)
+}
+
 func (s *shell) suggestor(in prompt.Document) []prompt.Suggest {
 	args := strings.Fields(in.Text)
 	if len(strings.TrimSuffix(in.Text, " ")) < len(in.Text) {
@@ -115,6 +146,13 @@ func (s *shell) suggestor(in prompt.Document) []prompt.Suggest {
 		}
 		text = args[len(args)-1]
 	}
+	if len(args) > 1 && isBuiltin(args[0]) {
+		if s.completionID != builtinCompletionID || s.cacheF == nil || !s.cacheF("", text) {
+			s.completionID = builtinCompletionID
+			s.suggests, s.cacheF = navigationCompletion(s.cwd, text, s.maxCompletions)
+		}
+		return s.filter(text)
+	}
 	flag := ""
 	if len(args) > 1 {
 		if args[len(args)-2][0] == '-' {
@@ -122,7 +160,11 @@ func (s *shell) suggestor(in prompt.Document) []prompt.Suggest {
 		}
 	}
 	suggestions := cmd.SuggestionsFor(text)
-	if len(suggestions) > 0 {
+	var builtinSuggests []prompt.Suggest
+	if len(args) == 1 {
+		builtinSuggests = prompt.FilterHasPrefix(builtins, text, false)
+	}
+	if len(suggestions) > 0 || len(builtinSuggests) > 0 {
 		var result []prompt.Suggest
 		for _, suggestion := range suggestions {
 			cmd, _, err := cmd.Traverse([]string{suggestion})
@@ -134,7 +176,7 @@ func (s *shell) suggestor(in prompt.Document) []prompt.Suggest {
 				Description: cmd.Short,
 			})
 		}
-		return result
+		return append(result, builtinSuggests...)
 	}
 	if id, ok := cmd.Annotations[completionAnnotation]; ok {
 		completionFunc := completions[id]
@@ -142,24 +184,29 @@ func (s *shell) suggestor(in prompt.Document) []prompt.Suggest {
 			s.completionID = id
 			s.suggests, s.cacheF = completionFunc(flag, text, s.maxCompletions)
 		}
-		var result []prompt.Suggest
-		for _, sug := range s.suggests {
-			sText := sug.Text
-			if len(text) < len(sText) {
-				sText = sText[:len(text)]
-			}
-			if ld(sText, text, true) < ldThreshold {
-				result = append(result, sug)
-			}
-			if int64(len(result)) > s.maxCompletions {
-				break
-			}
-		}
-		return result
+		return s.filter(text)
 	}
 	return nil
 }
 
+// filter returns the cached suggestions that are close to 'text'
+func (s *shell) filter(text string) []prompt.Suggest {
+	var result []prompt.Suggest
+	for _, sug := range s.suggests {
+		sText := sug.Text
+		if len(text) < len(sText) {
+			sText = sText[:len(text)]
+		}
+		if ld(sText, text, true) < ldThreshold {
+			result = append(result, sug)
+		}
+		if int64(len(result)) > s.maxCompletions {
+			break
+		}
+	}
+	return result
+}
+
 func (s *shell) clearCache() {
 	s.completionID = ""
 	s.suggests = nil
@@ -173,6 +220,7 @@ func (s *shell) run() {
 		s.suggestor,
 		prompt.OptionPrefix(">>> "),
 		prompt.OptionTitle("Pachyderm Shell"),
+		prompt.OptionHistory(s.history.lines()),
 		prompt.OptionAddKeyBind(prompt.KeyBind{
 			Key: prompt.F5,
 			Fn:  func(*prompt.Buffer) { s.clearCache() },
@@ -186,6 +234,9 @@ func (s *shell) run() {
 			if err != nil {
 				return "", false
 			}
+			if s.cwd.repo != "" {
+				return fmt.Sprintf("context:(%s) %s >>> ", activeContext, s.cwd), true
+			}
 			return fmt.Sprintf("context:(%s) >>> ", activeContext), true
 		}),
 	).Run()
@@ -9,9 +9,13 @@ import (
 	"runtime/debug"
 	"runtime/pprof"
 	"strconv"
+	"strings"
+	"time"
 
 	etcd "github.com/coreos/etcd/clientv3"
 	units "github.com/docker/go-units"
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client"
 	adminclient "github.com/pachyderm/pachyderm/src/client/admin"
 	authclient "github.com/pachyderm/pachyderm/src/client/auth"
 	debugclient "github.com/pachyderm/pachyderm/src/client/debug"
@@ -32,8 +36,10 @@ import (
 	eprsserver "github.com/pachyderm/pachyderm/src/server/enterprise/server"
 	"github.com/pachyderm/pachyderm/src/server/health"
 	pach_http "github.com/pachyderm/pachyderm/src/server/http"
+	"github.com/pachyderm/pachyderm/src/server/pfs/artifactcache"
 	"github.com/pachyderm/pachyderm/src/server/pfs/s3"
 	pfs_server "github.com/pachyderm/pachyderm/src/server/pfs/server"
+	"github.com/pachyderm/pachyderm/src/server/pkg/blockshare"
 	cache_pb "github.com/pachyderm/pachyderm/src/server/pkg/cache/groupcachepb"
 	cache_server "github.com/pachyderm/pachyderm/src/server/pkg/cache/server"
 	"github.com/pachyderm/pachyderm/src/server/pkg/cmdutil"
@@ -42,10 +48,14 @@ import (
 	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
 	logutil "github.com/pachyderm/pachyderm/src/server/pkg/log"
 	"github.com/pachyderm/pachyderm/src/server/pkg/metrics"
+	"github.com/pachyderm/pachyderm/src/server/pkg/mtls"
 	"github.com/pachyderm/pachyderm/src/server/pkg/netutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsdb"
 	"github.com/pachyderm/pachyderm/src/server/pkg/serviceenv"
 	txnenv "github.com/pachyderm/pachyderm/src/server/pkg/transactionenv"
 	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
+	"github.com/pachyderm/pachyderm/src/server/pps/egressproxy"
 	pps_server "github.com/pachyderm/pachyderm/src/server/pps/server"
 	"github.com/pachyderm/pachyderm/src/server/pps/server/githook"
 	txnserver "github.com/pachyderm/pachyderm/src/server/transaction/server"
@@ -55,7 +65,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	flag "github.com/spf13/pflag"
 	"golang.org/x/net/context"
-	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -67,7 +77,7 @@ var mode string
 var readiness bool
 
 func init() {
-	flag.StringVar(&mode, "mode", "full", "Pachd currently supports two modes: full and sidecar.  The former includes everything you need in a full pachd node.  The latter runs only PFS, the Auth service, and a stripped-down version of PPS.")
+	flag.StringVar(&mode, "mode", "full", "Pachd currently supports four modes: full, sidecar, egress-proxy and rewrap-objects.  The first includes everything you need in a full pachd node.  The second runs only PFS, the Auth service, and a stripped-down version of PPS.  The third runs a pipeline's egress proxy, in its worker pods.  The fourth re-wraps the data keys of pachd's encrypted objects with the current STORAGE_ENCRYPTION_KEY (and encrypts any objects that aren't encrypted) and exits, e.g. after the key is rotated.")
 	flag.BoolVar(&readiness, "readiness", false, "Run readiness check.")
 	flag.Parse()
 }
@@ -82,6 +92,10 @@ func main() {
 		cmdutil.Main(doFullMode, &serviceenv.PachdFullConfiguration{})
 	case mode == "sidecar":
 		cmdutil.Main(doSidecarMode, &serviceenv.PachdFullConfiguration{})
+	case mode == "egress-proxy":
+		cmdutil.Main(doEgressProxyMode, &serviceenv.PachdFullConfiguration{})
+	case mode == "rewrap-objects":
+		cmdutil.Main(doRewrapObjectsMode, &serviceenv.PachdFullConfiguration{})
 	default:
 		fmt.Printf("unrecognized mode: %s\n", mode)
 	}
@@ -141,7 +155,11 @@ func doSidecarMode(config interface{}) (retErr error) {
 	if err != nil {
 		return fmt.Errorf("lru.New: %v", err)
 	}
-	server, err := grpcutil.NewServer(context.Background(), false)
+	internalTLS, err := mtls.Internal()
+	if err != nil {
+		return err
+	}
+	server, err := grpcutil.NewServer(context.Background(), false, internalTLS.ServerOptions()...)
 	if err != nil {
 		return err
 	}
@@ -150,8 +168,30 @@ func doSidecarMode(config interface{}) (retErr error) {
 	if err != nil {
 		return fmt.Errorf("units.RAMInBytes: %v", err)
 	}
+	if env.BlockSharingCacheSize > 0 {
+		if err := logGRPCServerSetup("Block Sharing", func() error {
+			// Share blocks with the sidecars of the pipeline's other workers on
+			// this node, which reach this one at the pod's IP
+			pipeline, node := os.Getenv(client.PPSPipelineNameEnv), os.Getenv(blockshare.NodeNameEnvVar)
+			sharer, err := blockshare.New(
+				env.GetEtcdClient(),
+				path.Join(env.EtcdPrefix, blockshare.EtcdPrefix, pipeline, node),
+				net.JoinHostPort(os.Getenv(client.PPSWorkerIPEnv), strconv.Itoa(int(env.PeerPort))),
+				path.Join(os.TempDir(), "pachyderm-block-sharing"),
+				env.BlockSharingCacheSize,
+			)
+			if err != nil {
+				return err
+			}
+			blockshare.Register(sharer)
+			blockshare.RegisterBlockShareServer(server.Server, sharer)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
 	if err := logGRPCServerSetup("Block API", func() error {
-		blockAPIServer, err := pfs_server.NewBlockAPIServer(env.StorageRoot, blockCacheBytes, env.StorageBackend, net.JoinHostPort(env.EtcdHost, env.EtcdPort), false)
+		blockAPIServer, err := pfs_server.NewBlockAPIServer(env.StorageRoot, blockCacheBytes, env.StorageBackend, serviceenv.EtcdAddress(env.Configuration), false)
 		if err != nil {
 			return err
 		}
@@ -251,8 +291,8 @@ func doSidecarMode(config interface{}) (retErr error) {
 	}
 	if err := logGRPCServerSetup("Debug", func() error {
 		debugclient.RegisterDebugServer(server.Server, debugserver.NewDebugServer(
+			env,
 			"", // no name for pachd servers
-			env.GetEtcdClient(),
 			path.Join(env.EtcdPrefix, env.PPSEtcdPrefix),
 			env.PPSWorkerPort,
 			clusterID,
@@ -271,6 +311,62 @@ func doSidecarMode(config interface{}) (retErr error) {
 	return server.Wait()
 }
 
+// doEgressProxyMode runs the egress proxy of the pipeline whose worker pod
+// pachd is running in, which records the requests that it refuses in the
+// audit log
+func doEgressProxyMode(config interface{}) error {
+	env := serviceenv.InitServiceEnv(serviceenv.NewConfiguration(config))
+	if env.EtcdPrefix == "" {
+		env.EtcdPrefix = col.DefaultPrefix
+	}
+	pipeline, pod := os.Getenv(client.PPSPipelineNameEnv), os.Getenv(client.PPSPodNameEnv)
+	var hosts []string
+	for _, host := range strings.Split(env.EgressProxyHosts, ",") {
+		if host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return egressproxy.Server(hosts, func(host string) {
+		event := &authclient.AuditEvent{
+			Time:      types.TimestampNow(),
+			Principal: authclient.PipelinePrefix + pipeline,
+			EgressViolation: &authclient.EgressViolation{
+				Pipeline: pipeline,
+				Pod:      pod,
+				Host:     host,
+			},
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := authserver.WriteAuditEvent(ctx, env.GetEtcdClient(), path.Join(env.EtcdPrefix, env.AuthEtcdPrefix), event); err != nil {
+			log.Errorf("could not record egress violation (request to %s) in audit log: %v", host, err)
+		}
+	}).ListenAndServe()
+}
+
+// doRewrapObjectsMode wraps the data keys of all of the objects in pachd's
+// object store with the current encryption key, and encrypts the objects that
+// aren't encrypted
+func doRewrapObjectsMode(config interface{}) error {
+	storageRoot, err := obj.StorageRootFromEnv()
+	if err != nil {
+		return err
+	}
+	objClient, err := obj.NewClientFromSecret(storageRoot)
+	if err != nil {
+		return err
+	}
+	var count int
+	if err := obj.RewrapObjects(context.Background(), objClient, strings.TrimSuffix(storageRoot, "/")+"/", func(name string) {
+		count++
+		log.Debugf("rewrapped %s", name)
+	}); err != nil {
+		return err
+	}
+	log.Infof("rewrapped %d objects", count)
+	return nil
+}
+
 func doFullMode(config interface{}) (retErr error) {
 	defer func() {
 		if retErr != nil {
@@ -303,13 +399,27 @@ func doFullMode(config interface{}) (retErr error) {
 	if err != nil {
 		return fmt.Errorf("getClusterID: %v", err)
 	}
+	if err := migratePPSSchema(env); err != nil {
+		return err
+	}
 	var reporter *metrics.Reporter
 	if env.Metrics {
 		reporter = metrics.NewReporter(clusterID, env)
 	}
 	// (bryce) Do we have to use etcd client v2 here for sharder? Might want to re-visit this later.
-	etcdAddress := fmt.Sprintf("http://%s", net.JoinHostPort(env.EtcdHost, env.EtcdPort))
-	etcdClientV2 := getEtcdClient(etcdAddress)
+	internalTLS, err := mtls.Internal()
+	if err != nil {
+		return err
+	}
+	if ca := internalTLS.CA(); ca != nil {
+		go func() {
+			if err := mtls.RotateSecrets(context.Background(), env.GetKubeClient(), getNamespace(), ca); err != nil {
+				log.Errorf("error rotating internal certs: %v", err)
+			}
+		}()
+	}
+	etcdAddress := serviceenv.EtcdAddress(env.Configuration)
+	etcdClientV2 := getEtcdClient(internalTLS, etcdAddress)
 	ip, err := netutil.ExternalIP()
 	if err != nil {
 		return fmt.Errorf("error getting pachd external ip: %v", err)
@@ -328,7 +438,7 @@ func doFullMode(config interface{}) (retErr error) {
 	router := shard.NewRouter(
 		sharder,
 		grpcutil.NewDialer(
-			grpc.WithInsecure(),
+			internalTLS.DialOption(),
 		),
 		address,
 	)
@@ -382,12 +492,21 @@ func doFullMode(config interface{}) (retErr error) {
 				env.IAMRole,
 				env.ImagePullSecret,
 				env.NoExposeDockerSocket,
+				env.PipelineDefaults,
+				env.ImagePinning,
 				reporter,
 				env.WorkerUsesRoot,
 				env.PPSWorkerPort,
 				env.Port,
 				env.HTTPPort,
 				env.PeerPort,
+				env.ArtifactCachePort,
+				env.WorkerNetworkPolicy,
+				env.WorkerNetworkPolicyAllow,
+				env.OrphanedWorkerGCDryRun,
+				env.PipelineCRD,
+				env.WorkerRPCCacheSize,
+				env.WorkerBlockSharingCacheSize,
 			)
 			if err != nil {
 				return err
@@ -423,6 +542,9 @@ func doFullMode(config interface{}) (retErr error) {
 				return err
 			}
 			authclient.RegisterAPIServer(externalServer.Server, authAPIServer)
+			// Record all RPCs received on the public port in the audit log
+			// (RPCs on the peer port, from other pachd instances, aren't audited)
+			externalServer.Intercept(authAPIServer.AuditInterceptors())
 			return nil
 		}); err != nil {
 			return err
@@ -465,6 +587,7 @@ func doFullMode(config interface{}) (retErr error) {
 		healthServer := health.NewHealthServer()
 		if err := logGRPCServerSetup("Health", func() error {
 			healthclient.RegisterHealthServer(externalServer.Server, healthServer)
+			grpc_health_v1.RegisterHealthServer(externalServer.Server, healthServer)
 			return nil
 		}); err != nil {
 			return err
@@ -477,8 +600,8 @@ func doFullMode(config interface{}) (retErr error) {
 		}
 		if err := logGRPCServerSetup("Debug", func() error {
 			debugclient.RegisterDebugServer(externalServer.Server, debugserver.NewDebugServer(
+				env,
 				"", // no name for pachd servers
-				env.GetEtcdClient(),
 				path.Join(env.EtcdPrefix, env.PPSEtcdPrefix),
 				env.PPSWorkerPort,
 				clusterID,
@@ -497,7 +620,7 @@ func doFullMode(config interface{}) (retErr error) {
 		return err
 	}
 	// Setup Internal Pachd GRPC Server.
-	internalServer, err := grpcutil.NewServer(context.Background(), false)
+	internalServer, err := grpcutil.NewServer(context.Background(), false, internalTLS.ServerOptions()...)
 	if err != nil {
 		return err
 	}
@@ -568,12 +691,21 @@ func doFullMode(config interface{}) (retErr error) {
 				env.IAMRole,
 				env.ImagePullSecret,
 				env.NoExposeDockerSocket,
+				env.PipelineDefaults,
+				env.ImagePinning,
 				reporter,
 				env.WorkerUsesRoot,
 				env.PPSWorkerPort,
 				env.Port,
 				env.HTTPPort,
 				env.PeerPort,
+				env.ArtifactCachePort,
+				env.WorkerNetworkPolicy,
+				env.WorkerNetworkPolicyAllow,
+				env.OrphanedWorkerGCDryRun,
+				env.PipelineCRD,
+				env.WorkerRPCCacheSize,
+				env.WorkerBlockSharingCacheSize,
 			)
 			if err != nil {
 				return err
@@ -628,6 +760,7 @@ func doFullMode(config interface{}) (retErr error) {
 		healthServer := health.NewHealthServer()
 		if err := logGRPCServerSetup("Health", func() error {
 			healthclient.RegisterHealthServer(internalServer.Server, healthServer)
+			grpc_health_v1.RegisterHealthServer(internalServer.Server, healthServer)
 			return nil
 		}); err != nil {
 			return err
@@ -689,6 +822,11 @@ func doFullMode(config interface{}) (retErr error) {
 		}
 		return server.ListenAndServeTLS(certPath, keyPath)
 	})
+	if env.ArtifactCachePort != 0 {
+		go waitForError("Artifact Cache Server", errChan, requireNoncriticalServers, func() error {
+			return artifactcache.Server(env.ArtifactCachePort, env.Port, env.GetEtcdClient()).ListenAndServe()
+		})
+	}
 	go waitForError("Prometheus Server", errChan, requireNoncriticalServers, func() error {
 		http.Handle("/metrics", promhttp.Handler())
 		return http.ListenAndServe(fmt.Sprintf(":%v", assets.PrometheusPort), nil)
@@ -696,7 +834,10 @@ func doFullMode(config interface{}) (retErr error) {
 	return <-errChan
 }
 
-func getEtcdClient(etcdAddress string) discovery.Client {
+func getEtcdClient(internalTLS *mtls.Config, etcdAddress string) discovery.Client {
+	if internalTLS != nil {
+		return discovery.NewEtcdTLSClient(internalTLS.ClientTLSConfig(), etcdAddress)
+	}
 	return discovery.NewEtcdClient(etcdAddress)
 }
 
@@ -722,6 +863,40 @@ func getClusterID(client *etcd.Client) (string, error) {
 	return getClusterID(client)
 }
 
+// migratePPSSchema migrates PPS's etcd records to the schema that this version
+// of pachd uses or, if PPS_SCHEMA_ROLLBACK_VERSION is set, rolls them back to
+// an older schema and returns an error so that pachd exits
+func migratePPSSchema(env *serviceenv.ServiceEnv) error {
+	opts := ppsdb.MigrateOptions{
+		Target: ppsdb.SchemaVersion,
+		DryRun: env.PPSMigrationsDryRun,
+	}
+	rollback := env.PPSSchemaRollbackVersion != ""
+	if rollback {
+		target, err := strconv.ParseUint(env.PPSSchemaRollbackVersion, 10, 64)
+		if err != nil {
+			return fmt.Errorf("could not parse PPS_SCHEMA_ROLLBACK_VERSION: %v", err)
+		}
+		opts.Target = target
+	}
+	etcdPrefix := path.Join(env.EtcdPrefix, env.PPSEtcdPrefix)
+	migrated, err := ppsdb.Migrate(context.Background(), env.GetEtcdClient(),
+		ppsdb.Pipelines(env.GetEtcdClient(), etcdPrefix), ppsdb.Jobs(env.GetEtcdClient(), etcdPrefix), opts)
+	if err != nil {
+		return fmt.Errorf("could not migrate PPS records to schema version %d: %v", opts.Target, err)
+	}
+	if opts.DryRun {
+		log.Infof("would migrate %d PPS records to schema version %d (dry run)", migrated, opts.Target)
+		return nil
+	}
+	log.Infof("migrated %d PPS records to schema version %d", migrated, opts.Target)
+	if rollback {
+		return fmt.Errorf("rolled PPS records back to schema version %d; deploy the "+
+			"version of pachd that uses it (and unset PPS_SCHEMA_ROLLBACK_VERSION)", opts.Target)
+	}
+	return nil
+}
+
 // getNamespace returns the kubernetes namespace that this pachd pod runs in
 func getNamespace() string {
 	namespace := os.Getenv("PACHD_POD_NAMESPACE")
@@ -0,0 +1,173 @@
+// protoc-gen-pachdoc is a protoc plugin that extracts the comments on the
+// messages, fields and enums in a .proto file into a Go map, so that they're
+// available at runtime (protoc-gen-gogofast strips them from the descriptors
+// it embeds). It's used by 'pachctl explain'.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	plugin "github.com/gogo/protobuf/protoc-gen-gogo/plugin"
+)
+
+// Field numbers in descriptor.proto, which make up the paths in
+// SourceCodeInfo
+const (
+	fileMessageTag  = 4
+	fileEnumTag     = 5
+	messageFieldTag = 2
+	messageNestTag  = 3
+	messageEnumTag  = 4
+	enumValueTag    = 2
+)
+
+func main() {
+	data, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		fail(err)
+	}
+	request := &plugin.CodeGeneratorRequest{}
+	if err := proto.Unmarshal(data, request); err != nil {
+		fail(err)
+	}
+	response := &plugin.CodeGeneratorResponse{}
+	for _, name := range request.FileToGenerate {
+		for _, file := range request.ProtoFile {
+			if file.GetName() == name {
+				response.File = append(response.File, generate(file))
+			}
+		}
+	}
+	data, err = proto.Marshal(response)
+	if err != nil {
+		fail(err)
+	}
+	if _, err := os.Stdout.Write(data); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "protoc-gen-pachdoc: %v\n", err)
+	os.Exit(1)
+}
+
+// generate returns a Go file containing the comments in 'file'
+func generate(file *descriptor.FileDescriptorProto) *plugin.CodeGeneratorResponse_File {
+	comments := make(map[string]string)
+	for _, loc := range file.GetSourceCodeInfo().GetLocation() {
+		comment := loc.GetLeadingComments()
+		if comment == "" {
+			comment = loc.GetTrailingComments()
+		}
+		if comment == "" {
+			continue
+		}
+		comments[pathKey(loc.Path)] = clean(comment)
+	}
+
+	docs := make(map[string]string)
+	var addMessage func(prefix string, path []int32, msg *descriptor.DescriptorProto)
+	addEnum := func(prefix string, path []int32, enum *descriptor.EnumDescriptorProto) {
+		name := prefix + "." + enum.GetName()
+		docs[name] = comments[pathKey(path)]
+		for i, value := range enum.Value {
+			docs[name+"."+value.GetName()] = comments[pathKey(childPath(path, enumValueTag, i))]
+		}
+	}
+	addMessage = func(prefix string, path []int32, msg *descriptor.DescriptorProto) {
+		name := prefix + "." + msg.GetName()
+		docs[name] = comments[pathKey(path)]
+		for i, field := range msg.Field {
+			docs[name+"."+field.GetName()] = comments[pathKey(childPath(path, messageFieldTag, i))]
+		}
+		for i, nested := range msg.NestedType {
+			addMessage(name, childPath(path, messageNestTag, i), nested)
+		}
+		for i, enum := range msg.EnumType {
+			addEnum(name, childPath(path, messageEnumTag, i), enum)
+		}
+	}
+	for i, msg := range file.MessageType {
+		addMessage(file.GetPackage(), []int32{fileMessageTag, int32(i)}, msg)
+	}
+	for i, enum := range file.EnumType {
+		addEnum(file.GetPackage(), []int32{fileEnumTag, int32(i)}, enum)
+	}
+
+	names := make([]string, 0, len(docs))
+	for name, doc := range docs {
+		if doc != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by protoc-gen-pachdoc. DO NOT EDIT.\n// source: %s\n\n", file.GetName())
+	fmt.Fprintf(&buf, "package %s\n\n", goPackageName(file))
+	fmt.Fprintf(&buf, "// Docs maps the fully-qualified names of the messages, fields and enums in\n")
+	fmt.Fprintf(&buf, "// %s (e.g. \"%s.Foo.bar\") to their comments\n", path.Base(file.GetName()), file.GetPackage())
+	fmt.Fprintf(&buf, "var Docs = map[string]string{\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\t%q: %q,\n", name, docs[name])
+	}
+	fmt.Fprintf(&buf, "}\n")
+	content, err := format.Source(buf.Bytes())
+	if err != nil {
+		fail(err)
+	}
+
+	base := strings.TrimSuffix(path.Base(file.GetName()), ".proto")
+	return &plugin.CodeGeneratorResponse_File{
+		Name:    proto.String(path.Join(goPackagePath(file), base+".doc.go")),
+		Content: proto.String(string(content)),
+	}
+}
+
+// pathKey converts a SourceCodeInfo path into a map key
+func pathKey(path []int32) string {
+	return fmt.Sprint(path)
+}
+
+// childPath returns the path of the i'th element of the field 'tag' in the
+// element at 'path'
+func childPath(path []int32, tag int32, i int) []int32 {
+	result := make([]int32, len(path), len(path)+2)
+	copy(result, path)
+	return append(result, tag, int32(i))
+}
+
+// clean removes the leading space that follows '//' from each line of
+// 'comment', and any surrounding blank lines
+func clean(comment string) string {
+	lines := strings.Split(strings.Trim(comment, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(strings.TrimPrefix(line, " "), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// goPackagePath returns the import path of 'file's Go package
+func goPackagePath(file *descriptor.FileDescriptorProto) string {
+	if p := file.GetOptions().GetGoPackage(); p != "" {
+		return strings.Split(p, ";")[0]
+	}
+	return path.Dir(file.GetName())
+}
+
+// goPackageName returns the name of 'file's Go package
+func goPackageName(file *descriptor.FileDescriptorProto) string {
+	if p := file.GetOptions().GetGoPackage(); strings.Contains(p, ";") {
+		return strings.Split(p, ";")[1]
+	}
+	return path.Base(goPackagePath(file))
+}
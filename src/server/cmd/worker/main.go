@@ -7,6 +7,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	etcd "github.com/coreos/etcd/clientv3"
@@ -20,6 +21,8 @@ import (
 	debugserver "github.com/pachyderm/pachyderm/src/server/debug/server"
 	"github.com/pachyderm/pachyderm/src/server/pkg/cmdutil"
 	logutil "github.com/pachyderm/pachyderm/src/server/pkg/log"
+	"github.com/pachyderm/pachyderm/src/server/pkg/mtls"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsdb"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/serviceenv"
 	"github.com/pachyderm/pachyderm/src/server/worker"
@@ -28,6 +31,13 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == worker.SandboxArg {
+		// Run sandboxed user code, rather than the worker
+		if err := worker.RunSandbox(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "could not run user code in the sandbox: %v\n", err)
+			os.Exit(1)
+		}
+	}
 	log.SetFormatter(logutil.FormatterFunc(logutil.Pretty))
 
 	// Copy the contents of /pach-bin/certs into /etc/ssl/certs. Don't return an
@@ -94,6 +104,14 @@ func main() {
 				"worker binary to error while communicating with object storage for " +
 				"egress pipelines or for merging pipeline outputs")
 	}
+	// In jobs started by an external execution backend, process a single
+	// chunk of datums rather than running as a worker
+	if chunk, ok := os.LookupEnv(client.PPSExternalChunkEnv); ok {
+		if err := worker.RunExternalChunk(chunk); err != nil {
+			log.Fatalf("error processing chunk: %v", err)
+		}
+		return
+	}
 	cmdutil.Main(do, &serviceenv.WorkerFullConfiguration{})
 }
 
@@ -104,16 +122,12 @@ func main() {
 func getPipelineInfo(pachClient *client.APIClient, env *serviceenv.ServiceEnv) (*pps.PipelineInfo, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	resp, err := env.GetEtcdClient().Get(ctx, path.Join(env.PPSEtcdPrefix, "pipelines", env.PPSPipelineName))
-	if err != nil {
-		return nil, err
-	}
-	if len(resp.Kvs) != 1 {
-		return nil, fmt.Errorf("expected to find 1 pipeline (%s), got %d: %v", env.PPSPipelineName, len(resp.Kvs), resp)
-	}
+	// Read the pipeline through its collection, which may be stored in etcd or
+	// postgres (see col.UsePostgres)
 	var pipelinePtr pps.EtcdPipelineInfo
-	if err := pipelinePtr.Unmarshal(resp.Kvs[0].Value); err != nil {
-		return nil, err
+	pipelines := ppsdb.Pipelines(env.GetEtcdClient(), env.PPSEtcdPrefix)
+	if err := pipelines.ReadOnly(ctx).Get(env.PPSPipelineName, &pipelinePtr); err != nil {
+		return nil, fmt.Errorf("could not read pipeline %s: %v", env.PPSPipelineName, err)
 	}
 	pachClient.SetAuthToken(pipelinePtr.AuthToken)
 	// Notice we use the SpecCommitID from our env, not from etcd. This is
@@ -139,20 +153,33 @@ func do(config interface{}) error {
 
 	// Construct worker API server.
 	workerRcName := ppsutil.PipelineRcName(pipelineInfo.Pipeline.Name, pipelineInfo.Version)
+	if replayOf := os.Getenv(client.PPSReplayJobEnv); replayOf != "" {
+		workerRcName = ppsutil.ReplayRcName(pipelineInfo.Pipeline.Name, pipelineInfo.Version, replayOf)
+	} else if pool := os.Getenv(client.PPSWorkerPoolEnv); pool != "" {
+		n, err := strconv.ParseInt(pool, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid worker pool %q: %v", pool, err)
+		}
+		workerRcName = ppsutil.WorkerPoolRcName(pipelineInfo.Pipeline.Name, pipelineInfo.Version, n)
+	}
 	apiServer, err := worker.NewAPIServer(pachClient, env.GetEtcdClient(), env.PPSEtcdPrefix, pipelineInfo, env.PodName, env.Namespace, env.StorageRoot)
 	if err != nil {
 		return err
 	}
 
 	// Start worker api server
-	server, err := grpcutil.NewServer(context.Background(), false)
+	internalTLS, err := mtls.Internal()
+	if err != nil {
+		return err
+	}
+	server, err := grpcutil.NewServer(context.Background(), false, internalTLS.ServerOptions()...)
 	if err != nil {
 		return err
 	}
 
 	worker.RegisterWorkerServer(server.Server, apiServer)
 	versionpb.RegisterAPIServer(server.Server, version.NewAPIServer(version.Version, version.APIServerOptions{}))
-	debugclient.RegisterDebugServer(server.Server, debugserver.NewDebugServer(env.PodName, env.GetEtcdClient(), env.PPSEtcdPrefix, env.PPSWorkerPort, ""))
+	debugclient.RegisterDebugServer(server.Server, debugserver.NewDebugServer(env, env.PodName, env.PPSEtcdPrefix, env.PPSWorkerPort, ""))
 
 	// Put our IP address into etcd, so pachd can discover us
 	key := path.Join(env.PPSEtcdPrefix, worker.WorkerEtcdPrefix, workerRcName, env.PPSWorkerIP)
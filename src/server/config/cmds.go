@@ -2,14 +2,18 @@ package cmds
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"sort"
+	"time"
 
+	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/pkg/config"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
 	"github.com/pachyderm/pachyderm/src/server/cmd/pachctl/shell"
@@ -202,6 +206,104 @@ func Cmds() []*cobra.Command {
 	shell.RegisterCompletionFunc(setContext, contextCompletion)
 	commands = append(commands, cmdutil.CreateAlias(setContext, "config set context"))
 
+	var clusterAddress string
+	var serverCAsFile string
+	var tlsServerName string
+	var proxyURL string
+	var keepalive time.Duration
+	var reconnectTimeout time.Duration
+	var activate bool
+	var setCluster *cobra.Command // standalone declaration so Run() can refer
+	setCluster = &cobra.Command{
+		Use:   "{{alias}} <context>",
+		Short: "Set how a context connects directly to a cluster.",
+		Long: "Set how a context connects directly to a cluster's pachd (e.g. " +
+			"through an ingress or a LoadBalancer service), rather than " +
+			"through a port forwarder. The context is created if it doesn't " +
+			"exist; otherwise only the given settings are changed. Use " +
+			"`pachctl version --timeout` to check the connection.",
+		Example: `
+# connect to a cluster behind a TLS ingress
+$ {{alias}} prod --pachd-address grpcs://pachd.example.com:443 --server-cas-file ca.pem --activate
+
+# connect through a corporate proxy, retrying requests while reconnecting
+$ {{alias}} prod --proxy http://proxy.corp:3128 --keepalive 30s --reconnect-timeout 2m`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) (retErr error) {
+			name := args[0]
+			cfg, err := config.Read(false)
+			if err != nil {
+				return err
+			}
+
+			context, ok := cfg.V2.Contexts[name]
+			if !ok {
+				if clusterAddress == "" {
+					return fmt.Errorf("context %q does not exist, so --pachd-address must be set", name)
+				}
+				context = &config.Context{}
+			}
+			if setCluster.Flags().Changed("pachd-address") {
+				parsedAddress, err := grpcutil.ParsePachdAddress(clusterAddress)
+				if err != nil {
+					return err
+				}
+				context.PachdAddress = parsedAddress.Qualified()
+			}
+			if setCluster.Flags().Changed("server-cas-file") {
+				context.ServerCAs = ""
+				if serverCAsFile != "" {
+					pemBytes, err := ioutil.ReadFile(serverCAsFile)
+					if err != nil {
+						return fmt.Errorf("could not read server CAs: %v", err)
+					}
+					context.ServerCAs = base64.StdEncoding.EncodeToString(pemBytes)
+				}
+			}
+			if setCluster.Flags().Changed("tls-server-name") {
+				context.TLSServerName = tlsServerName
+			}
+			if setCluster.Flags().Changed("proxy") {
+				context.ProxyURL = proxyURL
+			}
+			if setCluster.Flags().Changed("keepalive") {
+				context.KeepaliveSeconds = int64(keepalive / time.Second)
+			}
+			if setCluster.Flags().Changed("reconnect-timeout") {
+				context.ReconnectTimeoutSeconds = int64(reconnectTimeout / time.Second)
+			}
+
+			// Validate the settings before saving them, so that a bad
+			// setting doesn't break every pachctl command
+			if context.ServerCAs != "" || context.TLSServerName != "" {
+				parsedAddress, err := grpcutil.ParsePachdAddress(context.PachdAddress)
+				if err != nil {
+					return err
+				}
+				if !parsedAddress.Secured {
+					return errors.New("--server-cas-file and --tls-server-name require a grpcs:// pachd address")
+				}
+			}
+			if _, err := client.ContextOptions(context); err != nil {
+				return err
+			}
+
+			cfg.V2.Contexts[name] = context
+			if activate {
+				cfg.V2.ActiveContext = name
+			}
+			return cfg.Write()
+		}),
+	}
+	setCluster.Flags().StringVar(&clusterAddress, "pachd-address", "", "The address of pachd's ingress or load balancer (grpc://host:port, or grpcs://host:port for TLS).")
+	setCluster.Flags().StringVar(&serverCAsFile, "server-cas-file", "", "A PEM file of CA certs to trust pachd's TLS certificate with, instead of the system's CA certs.")
+	setCluster.Flags().StringVar(&tlsServerName, "tls-server-name", "", "The name to verify pachd's TLS certificate against, if it isn't the host of --pachd-address.")
+	setCluster.Flags().StringVar(&proxyURL, "proxy", "", "An HTTP proxy (http://[user:password@]host:port) to connect to pachd through. If unset, HTTPS_PROXY is respected.")
+	setCluster.Flags().DurationVar(&keepalive, "keepalive", 0, "How often to ping pachd over an idle connection, so that proxies and load balancers don't drop it. If 0, pachd is pinged every 20s.")
+	setCluster.Flags().DurationVar(&reconnectTimeout, "reconnect-timeout", 0, "How long to retry requests that can't reach pachd while reconnecting. If 0, they fail immediately.")
+	setCluster.Flags().BoolVar(&activate, "activate", false, "Make the context the active context.")
+	shell.RegisterCompletionFunc(setCluster, contextCompletion)
+	commands = append(commands, cmdutil.CreateAlias(setCluster, "config set cluster"))
+
 	var pachdAddress string
 	var clusterName string
 	var authInfo string
@@ -124,6 +124,42 @@ func TestUpdateContext(t *testing.T) {
 	`))
 }
 
+func TestSetCluster(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+
+	// New contexts need an address
+	require.YesError(t, run(t, `
+		pachctl config set cluster foo --keepalive=30s
+	`))
+
+	// TLS settings need a grpcs address
+	require.YesError(t, run(t, `
+		pachctl config set cluster foo --pachd-address=foobar:9000 --tls-server-name=pachd
+	`))
+
+	require.YesError(t, run(t, `
+		pachctl config set cluster foo --pachd-address=foobar:9000 --proxy=proxy:3128
+	`))
+
+	require.YesError(t, run(t, `
+		pachctl config set cluster foo --pachd-address=foobar:9000 --keepalive=1s
+	`))
+
+	require.NoError(t, run(t, `
+		pachctl config set cluster foo --pachd-address=grpcs://foobar:443 --proxy=http://proxy:3128 --keepalive=30s --activate
+		pachctl config get active-context | match foo
+		pachctl config get context foo | match '"pachd_address": "grpcs://foobar:443"'
+		pachctl config get context foo | match '"proxy_url": "http://proxy:3128"'
+		pachctl config get context foo | match '"keepalive_seconds": "30"'
+		pachctl config set cluster foo --reconnect-timeout=2m --proxy=""
+		pachctl config get context foo | match '"reconnect_timeout_seconds": "120"'
+		pachctl config get context foo | match '"keepalive_seconds": "30"'
+		pachctl config get context foo | match -v proxy_url
+	`))
+}
+
 func TestDeleteContext(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration tests in short mode")
@@ -4,32 +4,92 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 	"time"
 
 	"golang.org/x/sync/errgroup"
 
 	units "github.com/docker/go-units"
+	"github.com/gogo/protobuf/types"
 	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/debug"
 	"github.com/pachyderm/pachyderm/src/server/pkg/cmdutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/tabwriter"
 	"github.com/spf13/cobra"
 )
 
+// mastersHeader is the header of 'pachctl debug masters'
+const mastersHeader = "MASTER\tHOLDER\tLEASE\tTTL\tSTANDBYS\t\n"
+
+func printMasterLease(w *tabwriter.Writer, lease *debug.MasterLease) {
+	ttl := "-"
+	if d, err := types.DurationFromProto(lease.Ttl); err == nil {
+		ttl = d.String()
+	}
+	holder := lease.Holder
+	if holder == "" {
+		holder = "-" // locked by an older pachd or worker
+	}
+	standbys := strings.Join(lease.Standbys, ",")
+	if standbys == "" {
+		standbys = "-"
+	}
+	fmt.Fprintf(w, "%s\t%s\t%x\t%s\t%s\t\n", lease.Name, holder, lease.LeaseId, ttl, standbys)
+}
+
 // Cmds returns a slice containing debug commands.
 func Cmds() []*cobra.Command {
 	var commands []*cobra.Command
 
+	var dumpFile string
+	var logLines int64
+	var goroutines bool
 	dump := &cobra.Command{
-		Short: "Return a dump of running goroutines.",
-		Long:  "Return a dump of running goroutines.",
-		Run: cmdutil.RunFixedArgs(0, func(args []string) error {
+		Short: "Collect the information needed to diagnose the cluster into an archive.",
+		Long: "Collect the information needed to diagnose the cluster into a gzipped tar archive: pachd's and workers' goroutines and " +
+			"heap profiles, the most recent lines of each of Pachyderm's containers' logs, pipeline specs, jobs, etcd's health and " +
+			"Pachyderm's kubernetes objects. The values of environment variables are redacted. If auth is activated, only admins " +
+			"can create a dump. Parts of the dump that can't be collected are listed in errors.txt in the archive.",
+		Example: `
+# write a dump of the cluster to dump.tar.gz, to attach to a support ticket
+$ {{alias}}
+
+# include the most recent 10000 lines of each container's logs
+$ {{alias}} -o cluster.tar.gz --log-lines 10000
+
+# only print the goroutines of pachd and its workers
+$ {{alias}} --goroutines`,
+		Run: cmdutil.RunFixedArgs(0, func(args []string) (retErr error) {
 			client, err := client.NewOnUserMachine("debug-dump")
 			if err != nil {
 				return err
 			}
 			defer client.Close()
-			return client.Dump(os.Stdout)
+			if goroutines {
+				return client.Dump(os.Stdout)
+			}
+			if dumpFile == "-" {
+				return client.DumpArchive(logLines, os.Stdout)
+			}
+			f, err := os.Create(dumpFile)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if err := f.Close(); err != nil && retErr == nil {
+					retErr = err
+				}
+			}()
+			if err := client.DumpArchive(logLines, f); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Wrote dump to %s\n", dumpFile)
+			return nil
 		}),
 	}
+	dump.Flags().StringVarP(&dumpFile, "output", "o", "dump.tar.gz", "The file to write the dump to, or '-' for stdout.")
+	dump.Flags().Int64Var(&logLines, "log-lines", 1000, "The number of most recent lines of each container's logs to include.")
+	dump.Flags().BoolVar(&goroutines, "goroutines", false, "Only print the goroutines of pachd and its workers, rather than writing an archive.")
 	commands = append(commands, cmdutil.CreateAlias(dump, "debug dump"))
 
 	var duration time.Duration
@@ -120,6 +180,29 @@ func Cmds() []*cobra.Command {
 	pprof.Flags().DurationVarP(&duration, "duration", "d", time.Minute, "Duration to run a CPU profile for.")
 	commands = append(commands, cmdutil.CreateAlias(pprof, "debug pprof"))
 
+	masters := &cobra.Command{
+		Short: "Show which pods run the PPS master and pipelines' masters.",
+		Long: "Show which pods run the PPS master and pipelines' masters. Each master is run by the pod that holds its lock in etcd; " +
+			"if that pod's lease expires (for example, because the pod died), the first standby pod takes over the master.",
+		Run: cmdutil.RunFixedArgs(0, func(args []string) error {
+			client, err := client.NewOnUserMachine("debug-masters")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			leases, err := client.Masters()
+			if err != nil {
+				return err
+			}
+			writer := tabwriter.NewWriter(os.Stdout, mastersHeader)
+			for _, lease := range leases {
+				printMasterLease(writer, lease)
+			}
+			return writer.Flush()
+		}),
+	}
+	commands = append(commands, cmdutil.CreateAlias(masters, "debug masters"))
+
 	debug := &cobra.Command{
 		Short: "Debug commands for analyzing a running cluster.",
 		Long:  "Debug commands for analyzing a running cluster.",
@@ -0,0 +1,481 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/debug"
+	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/client/version"
+	"github.com/pachyderm/pachyderm/src/server/pkg/mtls"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
+
+	"google.golang.org/grpc"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// defaultLogLines is the number of lines of each container's logs that a
+	// dump archive includes, if the request doesn't say
+	defaultLogLines = 1000
+	// workerDumpTimeout bounds the time spent collecting each worker's
+	// goroutines and heap profile, so that a stuck worker can't stall the dump
+	workerDumpTimeout = 30 * time.Second
+	// redacted replaces the values of environment variables in dump archives
+	redacted = "<redacted>"
+)
+
+// suiteSelector selects the kubernetes objects that belong to Pachyderm
+var suiteSelector = metav1.FormatLabelSelector(metav1.SetAsLabelSelector(map[string]string{"suite": "pachyderm"}))
+
+// archive writes files into a dump archive. Collecting a dump is best effort:
+// if a part of it can't be collected, the error is recorded in errors.txt and
+// the rest of the dump is still written, since a cluster that needs
+// diagnosing is often partly broken.
+type archive struct {
+	tw      *tar.Writer
+	modTime time.Time
+	errs    []string
+}
+
+// write writes the file 'name' with the contents that 'f' writes. If 'f'
+// fails, the error is recorded, and anything it wrote is still included. The
+// returned error is only non-nil if the archive itself can't be written.
+func (a *archive) write(name string, f func(w io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := f(&buf); err != nil {
+		a.fail(name, err)
+		if buf.Len() == 0 {
+			return nil
+		}
+	}
+	if err := a.tw.WriteHeader(&tar.Header{
+		Name:    path.Join("dump", name),
+		Mode:    0644,
+		Size:    int64(buf.Len()),
+		ModTime: a.modTime,
+	}); err != nil {
+		return err
+	}
+	_, err := a.tw.Write(buf.Bytes())
+	return err
+}
+
+func (a *archive) writeJSON(name string, v interface{}) error {
+	return a.write(name, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	})
+}
+
+func (a *archive) writeProtos(name string, messages []proto.Message) error {
+	return a.write(name, func(w io.Writer) error {
+		marshaler := &jsonpb.Marshaler{Indent: "  "}
+		for _, m := range messages {
+			if err := marshaler.Marshal(w, m); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// fail records that the part of the dump 'name' couldn't be collected
+func (a *archive) fail(name string, err error) {
+	a.errs = append(a.errs, fmt.Sprintf("%s: %v", name, err))
+}
+
+// close writes errors.txt, if any part of the dump couldn't be collected,
+// and closes the archive
+func (a *archive) close() error {
+	if len(a.errs) > 0 {
+		errs := a.errs
+		if err := a.write("errors.txt", func(w io.Writer) error {
+			_, err := fmt.Fprintln(w, strings.Join(errs, "\n"))
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	return a.tw.Close()
+}
+
+func (s *debugServer) DumpArchive(request *debug.DumpArchiveRequest, server debug.Debug_DumpArchiveServer) (retErr error) {
+	if s.name != "" {
+		return fmt.Errorf("dump archives can only be created by pachd, not by %s", s.name)
+	}
+	pachClient := s.env.GetPachClient(server.Context())
+	// The archive includes the specs and logs of all pipelines, so only
+	// admins can create it
+	if me, err := pachClient.WhoAmI(pachClient.Ctx(), &auth.WhoAmIRequest{}); err == nil {
+		if !me.IsAdmin {
+			return &auth.ErrNotAuthorized{
+				Subject: me.Username,
+				AdminOp: "DumpArchive",
+			}
+		}
+	} else if !auth.IsErrNotActivated(err) {
+		return fmt.Errorf("error during authorization check: %v", err)
+	}
+	logLines := request.LogLines
+	if logLines <= 0 {
+		logLines = defaultLogLines
+	}
+
+	gw := gzip.NewWriter(grpcutil.NewStreamingBytesWriter(server))
+	defer func() {
+		if err := gw.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	a := &archive{tw: tar.NewWriter(gw), modTime: time.Now()}
+	for _, dump := range []func(*archive) error{
+		func(a *archive) error { return s.dumpPachd(server.Context(), a) },
+		func(a *archive) error { return s.dumpWorkers(server.Context(), a) },
+		func(a *archive) error { return s.dumpLogs(a, logLines) },
+		func(a *archive) error { return dumpPipelines(pachClient, a) },
+		func(a *archive) error { return dumpJobs(pachClient, a) },
+		func(a *archive) error { return s.dumpEtcd(server.Context(), a) },
+		s.dumpKube,
+	} {
+		if err := dump(a); err != nil {
+			return err
+		}
+	}
+	return a.close()
+}
+
+// dumpPachd writes the version, goroutines, heap profile and masters of the
+// pachd serving the request
+func (s *debugServer) dumpPachd(ctx context.Context, a *archive) error {
+	if err := a.write("pachd/version.txt", func(w io.Writer) error {
+		_, err := fmt.Fprintln(w, version.PrettyVersion())
+		return err
+	}); err != nil {
+		return err
+	}
+	for _, p := range []struct {
+		profile, file string
+		debug         int
+	}{
+		{"goroutine", "pachd/goroutines.txt", 2},
+		{"heap", "pachd/heap.pprof", 0},
+	} {
+		p := p
+		if err := a.write(p.file, func(w io.Writer) error {
+			profile := pprof.Lookup(p.profile)
+			if profile == nil {
+				return fmt.Errorf("unable to find %s profile", p.profile)
+			}
+			return profile.WriteTo(w, p.debug)
+		}); err != nil {
+			return err
+		}
+	}
+	masters, err := s.Masters(ctx, &debug.MastersRequest{})
+	if err != nil {
+		a.fail("pachd/masters.json", err)
+		return nil
+	}
+	return a.writeJSON("pachd/masters.json", masters.Masters)
+}
+
+// dumpWorkers writes the goroutines and heap profile of each running worker
+func (s *debugServer) dumpWorkers(ctx context.Context, a *archive) error {
+	pods, err := s.env.GetKubeClient().CoreV1().Pods(s.env.Namespace).List(metav1.ListOptions{
+		LabelSelector: metav1.FormatLabelSelector(metav1.SetAsLabelSelector(map[string]string{"component": "worker"})),
+	})
+	if err != nil {
+		a.fail("workers", err)
+		return nil
+	}
+	internalTLS, err := mtls.Internal()
+	if err != nil {
+		a.fail("workers", err)
+		return nil
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != v1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+		dir := path.Join("workers", pod.Name)
+		if err := func() (retErr error) {
+			conn, err := grpc.Dial(fmt.Sprintf("%s:%d", pod.Status.PodIP, s.workerGrpcPort),
+				append(client.DefaultDialOptions(), internalTLS.DialOption())...)
+			if err != nil {
+				a.fail(dir, err)
+				return nil
+			}
+			defer func() {
+				if err := conn.Close(); err != nil && retErr == nil {
+					retErr = err
+				}
+			}()
+			ctx, cancel := context.WithTimeout(ctx, workerDumpTimeout)
+			defer cancel()
+			debugClient := debug.NewDebugClient(conn)
+			if err := a.write(path.Join(dir, "goroutines.txt"), func(w io.Writer) error {
+				dumpClient, err := debugClient.Dump(ctx, &debug.DumpRequest{Recursed: true})
+				if err != nil {
+					return err
+				}
+				return grpcutil.WriteFromStreamingBytesClient(dumpClient, w)
+			}); err != nil {
+				return err
+			}
+			return a.write(path.Join(dir, "heap.pprof"), func(w io.Writer) error {
+				profileClient, err := debugClient.Profile(ctx, &debug.ProfileRequest{Profile: "heap"})
+				if err != nil {
+					return err
+				}
+				return grpcutil.WriteFromStreamingBytesClient(profileClient, w)
+			})
+		}(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dumpLogs writes the most recent 'logLines' lines of the logs of each of
+// Pachyderm's containers, and of the previous instance of each container
+// that has restarted
+func (s *debugServer) dumpLogs(a *archive, logLines int64) error {
+	podsClient := s.env.GetKubeClient().CoreV1().Pods(s.env.Namespace)
+	pods, err := podsClient.List(metav1.ListOptions{LabelSelector: suiteSelector})
+	if err != nil {
+		a.fail("logs", err)
+		return nil
+	}
+	for _, pod := range pods.Items {
+		restarted := make(map[string]bool)
+		for _, status := range pod.Status.ContainerStatuses {
+			restarted[status.Name] = status.RestartCount > 0
+		}
+		for _, container := range pod.Spec.Containers {
+			for _, previous := range []bool{false, true} {
+				if previous && !restarted[container.Name] {
+					continue
+				}
+				name := path.Join("logs", pod.Name, container.Name+".log")
+				if previous {
+					name = path.Join("logs", pod.Name, container.Name+".previous.log")
+				}
+				if err := a.write(name, func(w io.Writer) (retErr error) {
+					stream, err := podsClient.GetLogs(pod.Name, &v1.PodLogOptions{
+						Container: container.Name,
+						TailLines: &logLines,
+						Previous:  previous,
+					}).Timeout(10 * time.Second).Stream()
+					if err != nil {
+						return err
+					}
+					defer func() {
+						if err := stream.Close(); err != nil && retErr == nil {
+							retErr = err
+						}
+					}()
+					_, err = io.Copy(w, stream)
+					return err
+				}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// dumpPipelines writes the spec and info of each pipeline
+func dumpPipelines(pachClient *client.APIClient, a *archive) error {
+	pipelineInfos, err := pachClient.ListPipeline()
+	if err != nil {
+		a.fail("pipelines", err)
+		return nil
+	}
+	for _, pipelineInfo := range pipelineInfos {
+		redactPipelineInfo(pipelineInfo)
+		dir := path.Join("pipelines", pipelineInfo.Pipeline.Name)
+		if err := a.writeProtos(path.Join(dir, "spec.json"), []proto.Message{ppsutil.PipelineReqFromInfo(pipelineInfo)}); err != nil {
+			return err
+		}
+		if err := a.writeProtos(path.Join(dir, "info.json"), []proto.Message{pipelineInfo}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dumpJobs writes the info of each job
+func dumpJobs(pachClient *client.APIClient, a *archive) error {
+	var jobInfos []proto.Message
+	if err := pachClient.ListJobF("", nil, nil, 0, false, func(jobInfo *pps.JobInfo) error {
+		jobInfos = append(jobInfos, jobInfo)
+		return nil
+	}); err != nil {
+		a.fail("jobs.json", err)
+	}
+	return a.writeProtos("jobs.json", jobInfos)
+}
+
+// etcdEndpointStatus is the status of an etcd endpoint, or the error that
+// prevented getting it
+type etcdEndpointStatus struct {
+	Endpoint string      `json:"endpoint"`
+	Status   interface{} `json:"status,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// dumpEtcd writes the status of each of etcd's endpoints, and its members
+// and alarms (e.g. that it's out of space)
+func (s *debugServer) dumpEtcd(ctx context.Context, a *archive) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	var statuses []etcdEndpointStatus
+	for _, endpoint := range s.env.GetEtcdClient().Endpoints() {
+		status := etcdEndpointStatus{Endpoint: endpoint}
+		if resp, err := s.env.GetEtcdClient().Status(ctx, endpoint); err != nil {
+			status.Error = err.Error()
+		} else {
+			status.Status = resp
+		}
+		statuses = append(statuses, status)
+	}
+	if err := a.writeJSON("etcd/status.json", statuses); err != nil {
+		return err
+	}
+	if members, err := s.env.GetEtcdClient().MemberList(ctx); err != nil {
+		a.fail("etcd/members.json", err)
+	} else if err := a.writeJSON("etcd/members.json", members.Members); err != nil {
+		return err
+	}
+	if alarms, err := s.env.GetEtcdClient().AlarmList(ctx); err != nil {
+		a.fail("etcd/alarms.json", err)
+	} else if err := a.writeJSON("etcd/alarms.json", alarms.Alarms); err != nil {
+		return err
+	}
+	return nil
+}
+
+// dumpKube writes Pachyderm's pods, services and replication controllers, the
+// events in its namespace and the cluster's nodes, with the values of
+// environment variables redacted
+func (s *debugServer) dumpKube(a *archive) error {
+	coreV1 := s.env.GetKubeClient().CoreV1()
+	for _, object := range []struct {
+		file string
+		list func() (interface{}, error)
+	}{
+		{"pods.json", func() (interface{}, error) {
+			pods, err := coreV1.Pods(s.env.Namespace).List(metav1.ListOptions{LabelSelector: suiteSelector})
+			if err != nil {
+				return nil, err
+			}
+			for i := range pods.Items {
+				redactObjectMeta(&pods.Items[i].ObjectMeta)
+				redactPodSpec(&pods.Items[i].Spec)
+			}
+			return pods, nil
+		}},
+		{"services.json", func() (interface{}, error) {
+			services, err := coreV1.Services(s.env.Namespace).List(metav1.ListOptions{LabelSelector: suiteSelector})
+			if err != nil {
+				return nil, err
+			}
+			for i := range services.Items {
+				redactObjectMeta(&services.Items[i].ObjectMeta)
+			}
+			return services, nil
+		}},
+		{"replicationcontrollers.json", func() (interface{}, error) {
+			rcs, err := coreV1.ReplicationControllers(s.env.Namespace).List(metav1.ListOptions{LabelSelector: suiteSelector})
+			if err != nil {
+				return nil, err
+			}
+			for i := range rcs.Items {
+				redactObjectMeta(&rcs.Items[i].ObjectMeta)
+				if rcs.Items[i].Spec.Template != nil {
+					redactPodSpec(&rcs.Items[i].Spec.Template.Spec)
+				}
+			}
+			return rcs, nil
+		}},
+		{"events.json", func() (interface{}, error) {
+			events, err := coreV1.Events(s.env.Namespace).List(metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			sort.Slice(events.Items, func(i, j int) bool {
+				return events.Items[i].LastTimestamp.Before(&events.Items[j].LastTimestamp)
+			})
+			return events, nil
+		}},
+		{"nodes.json", func() (interface{}, error) {
+			return coreV1.Nodes().List(metav1.ListOptions{})
+		}},
+	} {
+		name := path.Join("kubernetes", object.file)
+		list, err := object.list()
+		if err != nil {
+			a.fail(name, err)
+			continue
+		}
+		if err := a.writeJSON(name, list); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactPipelineInfo redacts the values of the environment variables in the
+// transform of 'pipelineInfo', which may hold credentials
+func redactPipelineInfo(pipelineInfo *pps.PipelineInfo) {
+	if pipelineInfo.Transform == nil {
+		return
+	}
+	for name := range pipelineInfo.Transform.Env {
+		pipelineInfo.Transform.Env[name] = redacted
+	}
+}
+
+// redactPodSpec redacts the values of the environment variables of the
+// containers in 'spec', which may hold credentials. Variables set from
+// secrets only refer to them, so they're kept.
+func redactPodSpec(spec *v1.PodSpec) {
+	for _, containers := range [][]v1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			for j := range containers[i].Env {
+				if containers[i].Env[j].Value != "" {
+					containers[i].Env[j].Value = redacted
+				}
+			}
+		}
+	}
+}
+
+// redactObjectMeta removes the annotation in which kubectl stores an
+// object's whole previous configuration, which would include anything that
+// redactPodSpec redacts
+func redactObjectMeta(meta *metav1.ObjectMeta) {
+	delete(meta.Annotations, v1.LastAppliedConfigAnnotation)
+}
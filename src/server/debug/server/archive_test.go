@@ -0,0 +1,84 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestArchive(t *testing.T) {
+	var buf bytes.Buffer
+	a := &archive{tw: tar.NewWriter(&buf), modTime: time.Now()}
+	require.NoError(t, a.write("pachd/version.txt", func(w io.Writer) error {
+		_, err := fmt.Fprint(w, "1.11.0")
+		return err
+	}))
+	// A part that fails is recorded, along with whatever it wrote
+	require.NoError(t, a.write("logs/pachd/pachd.log", func(w io.Writer) error {
+		fmt.Fprint(w, "started")
+		return fmt.Errorf("stream closed")
+	}))
+	require.NoError(t, a.write("etcd/status.json", func(w io.Writer) error {
+		return fmt.Errorf("context deadline exceeded")
+	}))
+	require.NoError(t, a.close())
+
+	files := make(map[string]string)
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		contents, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = string(contents)
+	}
+	require.Equal(t, map[string]string{
+		"dump/pachd/version.txt":    "1.11.0",
+		"dump/logs/pachd/pachd.log": "started",
+		"dump/errors.txt":           "logs/pachd/pachd.log: stream closed\netcd/status.json: context deadline exceeded\n",
+	}, files)
+}
+
+func TestRedact(t *testing.T) {
+	spec := &v1.PodSpec{
+		Containers: []v1.Container{{
+			Env: []v1.EnvVar{
+				{Name: "AWS_SECRET_ACCESS_KEY", Value: "hunter2"},
+				{Name: "PACH_ROOT", Value: ""},
+				{Name: "PACHD_TOKEN", ValueFrom: &v1.EnvVarSource{
+					SecretKeyRef: &v1.SecretKeySelector{Key: "token"},
+				}},
+			},
+		}},
+	}
+	redactPodSpec(spec)
+	env := spec.Containers[0].Env
+	require.Equal(t, redacted, env[0].Value)
+	require.Equal(t, "", env[1].Value)
+	require.Equal(t, "token", env[2].ValueFrom.SecretKeyRef.Key)
+
+	meta := &metav1.ObjectMeta{Annotations: map[string]string{
+		v1.LastAppliedConfigAnnotation: `{"spec":{}}`,
+		"prometheus.io/scrape":         "true",
+	}}
+	redactObjectMeta(meta)
+	require.Equal(t, map[string]string{"prometheus.io/scrape": "true"}, meta.Annotations)
+
+	pipelineInfo := &pps.PipelineInfo{Transform: &pps.Transform{Env: map[string]string{"DB_PASSWORD": "hunter2"}}}
+	redactPipelineInfo(pipelineInfo)
+	require.Equal(t, redacted, pipelineInfo.Transform.Env["DB_PASSWORD"])
+	redactPipelineInfo(&pps.PipelineInfo{})
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	etcd "github.com/coreos/etcd/clientv3"
+	"github.com/gogo/protobuf/types"
+
+	"github.com/pachyderm/pachyderm/src/client/debug"
+	"github.com/pachyderm/pachyderm/src/server/pkg/dlock"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
+)
+
+func (s *debugServer) Masters(ctx context.Context, request *debug.MastersRequest) (*debug.MastersResponse, error) {
+	response := &debug.MastersResponse{}
+	ppsMaster, err := s.masterLease(ctx, "pps", path.Join(s.etcdPrefix, ppsconsts.MasterLockPath))
+	if err != nil {
+		return nil, err
+	}
+	if ppsMaster != nil {
+		response.Masters = append(response.Masters, ppsMaster)
+	}
+
+	// Find the locks of pipelines' masters, which are the parents of the keys
+	// under WorkerMasterLockPath
+	workerLockPath := path.Join(s.etcdPrefix, ppsconsts.WorkerMasterLockPath)
+	resp, err := s.etcdClient.Get(ctx, workerLockPath+"/", etcd.WithPrefix(), etcd.WithKeysOnly())
+	if err != nil {
+		return nil, err
+	}
+	lockNames := make(map[string]string) // lock prefix -> master name
+	for _, kv := range resp.Kvs {
+		lock := path.Dir(string(kv.Key))
+		pipeline := path.Dir(strings.TrimPrefix(lock, workerLockPath+"/"))
+		lockNames[lock] = path.Join("pipeline", pipeline)
+	}
+	var locks []string
+	for lock := range lockNames {
+		locks = append(locks, lock)
+	}
+	sort.Strings(locks)
+	for _, lock := range locks {
+		lease, err := s.masterLease(ctx, lockNames[lock], lock)
+		if err != nil {
+			return nil, err
+		}
+		if lease != nil {
+			response.Masters = append(response.Masters, lease)
+		}
+	}
+	return response, nil
+}
+
+// masterLease describes the lock at 'prefix', or returns nil if nothing
+// holds it
+func (s *debugServer) masterLease(ctx context.Context, name string, prefix string) (*debug.MasterLease, error) {
+	holders, err := dlock.ListHolders(ctx, s.etcdClient, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(holders) == 0 {
+		return nil, nil
+	}
+	lease := &debug.MasterLease{
+		Name:    name,
+		Holder:  holders[0].Name,
+		LeaseId: holders[0].LeaseID,
+		Ttl:     types.DurationProto(time.Duration(holders[0].TTL) * time.Second),
+	}
+	for _, standby := range holders[1:] {
+		lease.Standbys = append(lease.Standbys, standby.Name)
+	}
+	return lease, nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/gogo/protobuf/types"
 	"github.com/pachyderm/pachyderm/src/client/debug"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/serviceenv"
 	"github.com/pachyderm/pachyderm/src/server/worker"
 )
 
@@ -18,11 +19,13 @@ const (
 	defaultDuration = time.Minute
 )
 
-// NewDebugServer creates a new server that serves the debug api over GRPC
-func NewDebugServer(name string, etcdClient *etcd.Client, etcdPrefix string, workerGrpcPort uint16, clusterID string) debug.DebugServer {
+// NewDebugServer creates a new server that serves the debug api over GRPC.
+// 'name' is the name of the worker pod that it runs in, or "" in pachd.
+func NewDebugServer(env *serviceenv.ServiceEnv, name string, etcdPrefix string, workerGrpcPort uint16, clusterID string) debug.DebugServer {
 	return &debugServer{
+		env:            env,
 		name:           name,
-		etcdClient:     etcdClient,
+		etcdClient:     env.GetEtcdClient(),
 		etcdPrefix:     etcdPrefix,
 		workerGrpcPort: workerGrpcPort,
 		clusterID:      clusterID,
@@ -30,6 +33,7 @@ func NewDebugServer(name string, etcdClient *etcd.Client, etcdPrefix string, wor
 }
 
 type debugServer struct {
+	env            *serviceenv.ServiceEnv
 	name           string
 	etcdClient     *etcd.Client
 	etcdPrefix     string
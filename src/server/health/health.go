@@ -6,20 +6,28 @@ import (
 	"github.com/gogo/protobuf/types"
 	"github.com/pachyderm/pachyderm/src/client/health"
 	"golang.org/x/net/context"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
-// Server adds the Ready method to health.HealthServer.
+// Server adds the Ready method to health.HealthServer. It also implements
+// grpc's standard health service, which reports the same status, so that
+// clients with connection pools can stop using pachds that aren't ready.
 type Server interface {
 	health.HealthServer
+	grpc_health_v1.HealthServer
 	Ready()
 }
 
 // NewHealthServer returns a new health server
 func NewHealthServer() Server {
-	return &healthServer{}
+	h := &healthServer{Server: grpchealth.NewServer()}
+	h.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	return h
 }
 
 type healthServer struct {
+	*grpchealth.Server
 	ready bool
 }
 
@@ -35,4 +43,5 @@ func (h *healthServer) Health(context.Context, *types.Empty) (*types.Empty, erro
 // will cause the node to pass its k8s readiness check.
 func (h *healthServer) Ready() {
 	h.ready = true
+	h.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/auth"
 	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/mtls"
 
 	"github.com/gogo/protobuf/types"
 	"github.com/julienschmidt/httprouter"
@@ -152,8 +153,10 @@ func httpError(w http.ResponseWriter, err error) {
 
 func (s *server) getPachClient() *client.APIClient {
 	s.pachClientOnce.Do(func() {
-		var err error
-		s.pachClient, err = client.NewFromAddress(s.address)
+		options, err := mtls.PeerClientOptions()
+		if err == nil {
+			s.pachClient, err = client.NewFromAddress(s.address, options...)
+		}
 		if err != nil {
 			panic(fmt.Sprintf("http server failed to initialize pach client: %v", err))
 		}
@@ -179,6 +179,58 @@ func TestRepoSize(t *testing.T) {
 	require.Equal(t, uint64(3), repoInfo.SizeBytes)
 }
 
+func TestJobOutputDiff(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+
+	c := getPachClient(t)
+	require.NoError(t, c.DeleteAll())
+
+	dataRepo := tu.UniqueString("TestJobOutputDiff_data")
+	require.NoError(t, c.CreateRepo(dataRepo))
+
+	pipeline := tu.UniqueString("TestJobOutputDiff")
+	require.NoError(t, c.CreatePipeline(
+		pipeline,
+		"",
+		[]string{"bash"},
+		[]string{
+			fmt.Sprintf("cp /pfs/%s/* /pfs/out/", dataRepo),
+		},
+		&pps.ParallelismSpec{
+			Constant: 1,
+		},
+		client.NewPFSInput(dataRepo, "/*"),
+		"",
+		false,
+	))
+
+	commit1, err := c.StartCommit(dataRepo, "master")
+	require.NoError(t, err)
+	_, err = c.PutFile(dataRepo, commit1.ID, "file1", strings.NewReader("foo"))
+	require.NoError(t, err)
+	_, err = c.PutFile(dataRepo, commit1.ID, "file2", strings.NewReader("bar"))
+	require.NoError(t, err)
+	require.NoError(t, c.FinishCommit(dataRepo, commit1.ID))
+	jobInfos, err := c.FlushJobAll([]*pfs.Commit{commit1}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(jobInfos))
+	require.Equal(t, &pps.OutputDiff{FilesAdded: 2, SizeDelta: 6}, jobInfos[0].OutputDiff)
+
+	// The second job only adds and deletes the files whose datums changed
+	commit2, err := c.StartCommit(dataRepo, "master")
+	require.NoError(t, err)
+	_, err = c.PutFile(dataRepo, commit2.ID, "file3", strings.NewReader("buzz"))
+	require.NoError(t, err)
+	require.NoError(t, c.DeleteFile(dataRepo, commit2.ID, "file2"))
+	require.NoError(t, c.FinishCommit(dataRepo, commit2.ID))
+	jobInfos, err = c.FlushJobAll([]*pfs.Commit{commit2}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(jobInfos))
+	require.Equal(t, &pps.OutputDiff{FilesAdded: 1, FilesDeleted: 1, SizeDelta: 1}, jobInfos[0].OutputDiff)
+}
+
 func TestPFSPipeline(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration tests in short mode")
@@ -2875,7 +2927,7 @@ func TestManyPipelineUpdate(t *testing.T) {
 		return func(t *testing.T) {
 			c := getPachClient(t)
 			require.NoError(t, c.DeleteAll())
-			require.NoError(t, c.GarbageCollect(0))
+			require.NoError(t, c.GarbageCollectWithGracePeriod(0, 0))
 
 			dataRepo := tu.UniqueString("input-")
 			require.NoError(t, c.CreateRepo(dataRepo))
@@ -3938,7 +3990,7 @@ func TestParallelismSpec(t *testing.T) {
 	numNodes := len(nodes.Items)
 
 	// Test Constant strategy
-	parellelism, err := ppsutil.GetExpectedNumWorkers(tu.GetKubeClient(t), &pps.ParallelismSpec{
+	parellelism, err := ppsutil.GetExpectedNumWorkers(context.Background(), tu.GetKubeClient(t), &pps.ParallelismSpec{
 		Constant: 7,
 	})
 	require.NoError(t, err)
@@ -3948,33 +4000,33 @@ func TestParallelismSpec(t *testing.T) {
 	// TODO(msteffen): This test can fail when run against cloud providers, if the
 	// remote cluster has more than one node (in which case "Coefficient: 1" will
 	// cause more than 1 worker to start)
-	parellelism, err = ppsutil.GetExpectedNumWorkers(kubeclient, &pps.ParallelismSpec{
+	parellelism, err = ppsutil.GetExpectedNumWorkers(context.Background(), kubeclient, &pps.ParallelismSpec{
 		Coefficient: 1,
 	})
 	require.NoError(t, err)
 	require.Equal(t, numNodes, parellelism)
 
 	// Coefficient > 1
-	parellelism, err = ppsutil.GetExpectedNumWorkers(kubeclient, &pps.ParallelismSpec{
+	parellelism, err = ppsutil.GetExpectedNumWorkers(context.Background(), kubeclient, &pps.ParallelismSpec{
 		Coefficient: 2,
 	})
 	require.NoError(t, err)
 	require.Equal(t, 2*numNodes, parellelism)
 
 	// Make sure we start at least one worker
-	parellelism, err = ppsutil.GetExpectedNumWorkers(kubeclient, &pps.ParallelismSpec{
+	parellelism, err = ppsutil.GetExpectedNumWorkers(context.Background(), kubeclient, &pps.ParallelismSpec{
 		Coefficient: 0.01,
 	})
 	require.NoError(t, err)
 	require.Equal(t, 1, parellelism)
 
 	// Test 0-initialized JobSpec
-	parellelism, err = ppsutil.GetExpectedNumWorkers(kubeclient, &pps.ParallelismSpec{})
+	parellelism, err = ppsutil.GetExpectedNumWorkers(context.Background(), kubeclient, &pps.ParallelismSpec{})
 	require.NoError(t, err)
 	require.Equal(t, 1, parellelism)
 
 	// Test nil JobSpec
-	parellelism, err = ppsutil.GetExpectedNumWorkers(kubeclient, nil)
+	parellelism, err = ppsutil.GetExpectedNumWorkers(context.Background(), kubeclient, nil)
 	require.NoError(t, err)
 	require.Equal(t, 1, parellelism)
 }
@@ -5443,7 +5495,7 @@ func TestGarbageCollection(t *testing.T) {
 	// Delete everything, then run garbage collection and finally check that
 	// we're at a baseline of 0 tags and 0 objects.
 	require.NoError(t, c.DeleteAll())
-	require.NoError(t, c.GarbageCollect(0))
+	require.NoError(t, c.GarbageCollectWithGracePeriod(0, 0))
 	originalObjects := getAllObjects(t, c)
 	originalTags := getAllTags(t, c)
 	require.Equal(t, 0, len(originalObjects))
@@ -5512,14 +5564,18 @@ func TestGarbageCollection(t *testing.T) {
 	objectsBefore := getAllObjects(t, c)
 	tagsBefore := getAllTags(t, c)
 	specObjectCountBefore := getObjectCountForRepo(t, c, ppsconsts.SpecRepo)
-	// Try to GC without stopping the pipeline.
-	require.YesError(t, c.GarbageCollect(0))
+	// GC runs while the pipeline is running, and with the default grace
+	// period, it spares everything that was just written
+	require.NoError(t, c.GarbageCollect(0))
+	gcInfo, err := c.InspectGarbageCollect()
+	require.NoError(t, err)
+	require.Equal(t, pps.GarbageCollectState_GC_SUCCESS, gcInfo.State)
+	require.Equal(t, int64(len(objectsBefore)), gcInfo.ObjectsScanned)
+	require.Equal(t, int64(0), gcInfo.ObjectsDeleted)
 
-	// Now stop the pipeline  and GC
+	// Now stop the pipeline and GC
 	require.NoError(t, c.StopPipeline(pipeline))
-	require.NoErrorWithinTRetry(t, 90*time.Second, func() error {
-		return c.GarbageCollect(0)
-	})
+	require.NoError(t, c.GarbageCollectWithGracePeriod(0, 0))
 
 	// Check that data still exists in the input repo
 	var buf bytes.Buffer
@@ -5558,7 +5614,7 @@ func TestGarbageCollection(t *testing.T) {
 	require.NoError(t, c.DeletePipeline(pipeline, false))
 	require.NoError(t, c.DeletePipeline(failurePipeline, false))
 	require.NoErrorWithinTRetry(t, 30*time.Second, func() error {
-		require.NoError(t, c.GarbageCollect(0))
+		require.NoError(t, c.GarbageCollectWithGracePeriod(0, 0))
 
 		// We should've deleted one tag since the functioning pipeline only processed
 		// one datum.
@@ -5591,7 +5647,7 @@ func TestGarbageCollection(t *testing.T) {
 
 	// Now we delete everything.
 	require.NoError(t, c.DeleteAll())
-	require.NoError(t, c.GarbageCollect(0))
+	require.NoError(t, c.GarbageCollectWithGracePeriod(0, 0))
 
 	// Since we've now deleted everything that we created in this test,
 	// the tag count and object count should be back to the originals.
@@ -5619,6 +5675,34 @@ func TestGarbageCollection(t *testing.T) {
 	require.Equal(t, "barbar\n", buf.String())
 }
 
+// TestGarbageCollectUpload tests that garbage collection keeps the chunks of
+// an upload session that hasn't been finished yet
+func TestGarbageCollectUpload(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	c := getPachClient(t)
+	require.NoError(t, c.DeleteAll())
+
+	dataRepo := tu.UniqueString("TestGarbageCollectUpload_data")
+	require.NoError(t, c.CreateRepo(dataRepo))
+	upload, err := c.StartUpload(dataRepo, "master", "file", false)
+	require.NoError(t, err)
+	_, err = c.UploadChunk(upload, 0, strings.NewReader("foo"))
+	require.NoError(t, err)
+
+	// Without a grace period, GC only spares the first chunk because the
+	// upload references it
+	require.NoError(t, c.GarbageCollectWithGracePeriod(0, 0))
+	_, err = c.UploadChunk(upload, 3, strings.NewReader("bar"))
+	require.NoError(t, err)
+	require.NoError(t, c.FinishUpload(upload))
+
+	var buf bytes.Buffer
+	require.NoError(t, c.GetFile(dataRepo, "master", "file", 0, 0, &buf))
+	require.Equal(t, "foobar", buf.String())
+}
+
 func TestPipelineWithStats(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration tests in short mode")
@@ -9414,6 +9498,62 @@ func TestNewHeaderCausesReprocess(t *testing.T) {
 		})
 }
 
+func TestDatumCache(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+
+	c := getPachClient(t)
+	require.NoError(t, c.DeleteAll())
+
+	dataRepo := tu.UniqueString("TestDatumCache_data")
+	require.NoError(t, c.CreateRepo(dataRepo))
+	for _, file := range []string{"a", "b"} {
+		_, err := c.PutFile(dataRepo, "master", file, strings.NewReader(file))
+		require.NoError(t, err)
+	}
+
+	pipeline := tu.UniqueString("TestDatumCache")
+	createPipeline := func(stdin string, update bool) {
+		_, err := c.PpsAPIClient.CreatePipeline(
+			context.Background(),
+			&pps.CreatePipelineRequest{
+				Pipeline: client.NewPipeline(pipeline),
+				Transform: &pps.Transform{
+					Cmd:   []string{"bash"},
+					Stdin: []string{stdin, fmt.Sprintf("cp /pfs/%s/* /pfs/out/", dataRepo)},
+				},
+				Input:      client.NewPFSInput(dataRepo, "/*"),
+				DatumCache: true,
+				Update:     update,
+			})
+		require.NoError(t, err)
+	}
+	requireJob := func(processed, skipped int64) {
+		jis, err := c.FlushJobAll([]*pfs.Commit{client.NewCommit(dataRepo, "master")}, nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(jis))
+		require.Equal(t, pps.JobState_JOB_SUCCESS, jis[0].State)
+		require.Equal(t, processed, jis[0].DataProcessed)
+		require.Equal(t, skipped, jis[0].DataSkipped)
+	}
+	createPipeline("echo v1", false)
+	requireJob(2, 0)
+
+	// Later jobs of the same version skip the datums that were processed
+	_, err := c.PutFile(dataRepo, "master", "c", strings.NewReader("c"))
+	require.NoError(t, err)
+	requireJob(1, 2)
+
+	// Updating the pipeline, even without reprocess, processes every datum
+	// again
+	createPipeline("echo v2", true)
+	requireJob(3, 0)
+	_, err = c.PutFile(dataRepo, "master", "d", strings.NewReader("d"))
+	require.NoError(t, err)
+	requireJob(1, 3)
+}
+
 func TestSpout(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration tests in short mode")
@@ -10522,7 +10662,7 @@ func TestListTag(t *testing.T) {
 
 	c := getPachClient(t)
 	require.NoError(t, c.DeleteAll())
-	require.NoError(t, c.GarbageCollect(0)) // makes ListTags faster
+	require.NoError(t, c.GarbageCollectWithGracePeriod(0, 0)) // makes ListTags faster
 
 	// Create a large number of objects w/ tags
 	numTags := 1000
@@ -10919,7 +11059,7 @@ func getObjectCountForRepo(t testing.TB, c *client.APIClient, repo string) int {
 	require.NoError(t, err)
 	repoInfo, err := pachClient.InspectRepo(repo)
 	require.NoError(t, err)
-	activeStat, err := pps_server.CollectActiveObjectsAndTags(context.Background(), c, []*pfs.RepoInfo{repoInfo}, pipelineInfos, 0, "")
+	activeStat, err := pps_server.CollectActiveObjectsAndTags(context.Background(), c, []*pfs.RepoInfo{repoInfo}, pipelineInfos, nil, 0, "")
 	require.NoError(t, err)
 	return activeStat.NObjects
 }
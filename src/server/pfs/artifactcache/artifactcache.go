@@ -0,0 +1,283 @@
+// Package artifactcache implements a pull-through cache for package indexes
+// (PyPI, npm and conda), backed by PFS. Pipelines that install their
+// dependencies at runtime download them through the cache (see the env vars
+// in WorkerEnv), so that each artifact is only downloaded from the internet
+// once, and so that pipelines keep working (with the same artifacts) if the
+// upstream index is unreachable.
+package artifactcache
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	etcd "github.com/coreos/etcd/clientv3"
+	"github.com/gogo/protobuf/types"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
+)
+
+const (
+	// cacheRepo is the PFS repo in which cached artifacts are stored, at
+	// '/<upstream prefix>/<path>'
+	cacheRepo = "_artifact_cache_"
+	// upstreamTimeout is the maximum time that the cache waits for an upstream
+	// index to respond (artifacts themselves may take longer to download)
+	upstreamTimeout = 30 * time.Second
+)
+
+type cache struct {
+	pachdPort  uint16
+	etcdClient *etcd.Client
+	logger     *logrus.Entry
+	httpClient *http.Client
+
+	// pachClient is initialized by getPachClient()
+	pachClient   *client.APIClient
+	pachClientMu sync.Mutex
+}
+
+// Server returns an HTTP server for the artifact cache, which listens on
+// 'port' and stores artifacts in the pachd at 'pachdPort'. As with the S3
+// gateway, it's the caller's responsibility to start the returned server.
+//
+// The cache writes to PFS as PPS (i.e. with the PPS token, read from etcd), as
+// its clients (pipeline workers) don't present any credentials, and the cached
+// artifacts are all public.
+func Server(port, pachdPort uint16, etcdClient *etcd.Client) *http.Server {
+	c := &cache{
+		pachdPort:  pachdPort,
+		etcdClient: etcdClient,
+		logger:     logrus.WithFields(logrus.Fields{"source": "artifact-cache"}),
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				ResponseHeaderTimeout: upstreamTimeout,
+			},
+		},
+	}
+	return &http.Server{
+		Addr:    fmt.Sprintf(":%d", port),
+		Handler: c,
+	}
+}
+
+func (c *cache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "the artifact cache is read-only", http.StatusMethodNotAllowed)
+		return
+	}
+	u, p := route(r.URL.Path)
+	if u == nil {
+		http.NotFound(w, r)
+		return
+	}
+	c.logger.Debugf("http request: %s %s", r.Method, r.RequestURI)
+	cachePath := path.Join("/", u.prefix, p)
+	if u.immutable(p) {
+		c.serveArtifact(w, r, u, p, cachePath)
+	} else {
+		c.serveIndex(w, r, u, p, cachePath)
+	}
+}
+
+// serveArtifact serves an immutable artifact from the cache, or, if it isn't
+// cached yet, downloads it from 'u' and caches it.
+func (c *cache) serveArtifact(w http.ResponseWriter, r *http.Request, u *upstream, p, cachePath string) {
+	pachClient, err := c.getPachClient()
+	if err != nil {
+		c.error(w, err)
+		return
+	}
+	if _, err := pachClient.InspectFile(cacheRepo, "master", cachePath); err == nil {
+		w.Header().Set("X-Cache", "HIT")
+		if r.Method == http.MethodGet {
+			if err := pachClient.GetFile(cacheRepo, "master", cachePath, 0, 0, w); err != nil {
+				c.logger.Errorf("error serving %s from cache: %v", cachePath, err)
+			}
+		}
+		return
+	} else if !errutil.IsNotFoundError(err) {
+		c.error(w, err)
+		return
+	}
+
+	// Download the artifact to a temporary file (artifacts may be too large
+	// to buffer in memory), cache it, and then serve it
+	resp, err := c.fetch(r, u, p)
+	if err != nil {
+		c.error(w, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || r.Method == http.MethodHead {
+		c.passThrough(w, resp, nil)
+		return
+	}
+	f, err := ioutil.TempFile("", "artifact-cache-")
+	if err != nil {
+		c.error(w, err)
+		return
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		c.error(w, fmt.Errorf("error downloading %s: %v", p, err))
+		return
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		c.error(w, err)
+		return
+	}
+	if err := c.put(cachePath, f); err != nil {
+		c.logger.Errorf("could not cache %s: %v", cachePath, err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		c.error(w, err)
+		return
+	}
+	w.Header().Set("X-Cache", "MISS")
+	c.passThrough(w, resp, f)
+}
+
+// serveIndex serves an index page (which may change upstream) from 'u', and
+// caches it. If 'u' is unreachable, the last cached copy is served instead.
+func (c *cache) serveIndex(w http.ResponseWriter, r *http.Request, u *upstream, p, cachePath string) {
+	baseURL := "http://" + r.Host
+	resp, err := c.fetch(r, u, p)
+	if err == nil && resp.StatusCode < http.StatusInternalServerError {
+		defer resp.Body.Close()
+		content, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			c.error(w, fmt.Errorf("error downloading %s: %v", p, err))
+			return
+		}
+		if resp.StatusCode == http.StatusOK && r.Method == http.MethodGet {
+			if err := c.put(cachePath, bytes.NewReader(content)); err != nil {
+				c.logger.Errorf("could not cache %s: %v", cachePath, err)
+			}
+		}
+		w.Header().Set("X-Cache", "MISS")
+		c.passThrough(w, resp, bytes.NewReader(u.rewrite(content, baseURL)))
+		return
+	}
+	if err == nil {
+		resp.Body.Close()
+		err = fmt.Errorf("%s returned %s", u.url, resp.Status)
+	}
+
+	// Serve a stale copy of the page
+	c.logger.Warnf("could not fetch %s from %s, serving cached copy: %v", p, u.url, err)
+	pachClient, pachErr := c.getPachClient()
+	if pachErr != nil {
+		c.error(w, pachErr)
+		return
+	}
+	var buf bytes.Buffer
+	if pachErr := pachClient.GetFile(cacheRepo, "master", cachePath, 0, 0, &buf); pachErr != nil {
+		if errutil.IsNotFoundError(pachErr) {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		c.error(w, pachErr)
+		return
+	}
+	content := u.rewrite(buf.Bytes(), baseURL)
+	w.Header().Set("X-Cache", "STALE")
+	w.Header().Set("Warning", `110 - "Response is Stale"`)
+	w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+	if r.Method == http.MethodGet {
+		w.Write(content)
+	}
+}
+
+// fetch requests the artifact at 'p' from 'u'
+func (c *cache) fetch(r *http.Request, u *upstream, p string) (*http.Response, error) {
+	url := u.url + "/" + p
+	if r.URL.RawQuery != "" {
+		url += "?" + r.URL.RawQuery
+	}
+	req, err := http.NewRequest(r.Method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(r.Context())
+	for _, header := range []string{"Accept", "User-Agent"} {
+		if value := r.Header.Get(header); value != "" {
+			req.Header.Set(header, value)
+		}
+	}
+	return c.httpClient.Do(req)
+}
+
+// passThrough writes the status and content headers of 'resp' to 'w',
+// followed by 'body' (or by resp.Body, if 'body' is nil)
+func (c *cache) passThrough(w http.ResponseWriter, resp *http.Response, body io.Reader) {
+	for _, header := range []string{"Content-Type", "Last-Modified", "ETag"} {
+		if value := resp.Header.Get(header); value != "" {
+			w.Header().Set(header, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	if body == nil || resp.Request.Method == http.MethodHead {
+		io.Copy(w, resp.Body)
+		return
+	}
+	if _, err := io.Copy(w, body); err != nil {
+		c.logger.Errorf("error writing response: %v", err)
+	}
+}
+
+// put writes the artifact in 'r' to the cache at 'cachePath'
+func (c *cache) put(cachePath string, r io.Reader) error {
+	pachClient, err := c.getPachClient()
+	if err != nil {
+		return err
+	}
+	_, err = pachClient.PutFileOverwrite(cacheRepo, "master", cachePath, r, 0)
+	return err
+}
+
+func (c *cache) error(w http.ResponseWriter, err error) {
+	c.logger.Errorf("error: %v", err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// getPachClient returns a client for the local pachd, authenticated as PPS,
+// and creates 'cacheRepo' if it doesn't exist. If any of this fails, it's
+// retried on the next call.
+func (c *cache) getPachClient() (*client.APIClient, error) {
+	c.pachClientMu.Lock()
+	defer c.pachClientMu.Unlock()
+	if c.pachClient != nil {
+		return c.pachClient, nil
+	}
+	pachClient, err := client.NewFromAddress(fmt.Sprintf("localhost:%d", c.pachdPort))
+	if err != nil {
+		return nil, err
+	}
+	var token types.StringValue
+	tokens := col.NewCollection(c.etcdClient, ppsconsts.PPSTokenKey, nil, &types.StringValue{}, nil, nil)
+	if err := tokens.ReadOnly(context.Background()).Get("", &token); err != nil {
+		pachClient.Close()
+		return nil, fmt.Errorf("could not read PPS token: %v", err)
+	}
+	pachClient.SetAuthToken(token.Value)
+	if err := pachClient.CreateRepo(cacheRepo); err != nil && !errutil.IsAlreadyExistError(err) {
+		pachClient.Close()
+		return nil, err
+	}
+	c.pachClient = pachClient
+	return pachClient, nil
+}
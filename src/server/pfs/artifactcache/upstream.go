@@ -0,0 +1,103 @@
+package artifactcache
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// upstream is a package index that's proxied by the artifact cache. Requests
+// for '/<prefix>/<path>' are proxied to '<url>/<path>'.
+type upstream struct {
+	prefix string
+	url    string
+
+	// immutable returns true if the artifact at 'path' (relative to 'url')
+	// never changes once published (e.g. a package archive, as opposed to an
+	// index page), and so can be served from the cache without contacting the
+	// upstream index.
+	immutable func(path string) bool
+
+	// links maps the base URLs that appear in this upstream's index pages to
+	// the cache paths that proxy them. Links to these URLs are rewritten so
+	// that package managers download artifacts through the cache.
+	links map[string]string
+}
+
+// upstreams are the package indexes proxied by the artifact cache, in the
+// order in which they're matched against request paths
+var upstreams = []*upstream{
+	{
+		// Python package archives, which are linked to by PyPI's index pages.
+		// This must precede "pypi", as routes are matched in order.
+		prefix:    "pypi/files",
+		url:       "https://files.pythonhosted.org",
+		immutable: func(string) bool { return true },
+	},
+	{
+		// pip: PIP_INDEX_URL=http://<cache>/pypi/simple
+		prefix:    "pypi",
+		url:       "https://pypi.org",
+		immutable: func(string) bool { return false },
+		links: map[string]string{
+			"https://files.pythonhosted.org/": "/pypi/files/",
+		},
+	},
+	{
+		// npm: NPM_CONFIG_REGISTRY=http://<cache>/npm/
+		prefix: "npm",
+		url:    "https://registry.npmjs.org",
+		immutable: func(path string) bool {
+			return strings.Contains(path, "/-/")
+		},
+		links: map[string]string{
+			"https://registry.npmjs.org/": "/npm/",
+		},
+	},
+	{
+		// conda: CONDA_CHANNEL_ALIAS=http://<cache>/conda
+		prefix: "conda",
+		url:    "https://conda.anaconda.org",
+		immutable: func(path string) bool {
+			return !strings.HasSuffix(path, ".json")
+		},
+	},
+}
+
+// route returns the upstream that serves 'urlPath' (a request path, e.g.
+// "/pypi/simple/numpy/"), and the path of the artifact relative to the
+// upstream's URL. It returns nil if no upstream serves 'urlPath'.
+func route(urlPath string) (*upstream, string) {
+	urlPath = strings.TrimPrefix(urlPath, "/")
+	for _, u := range upstreams {
+		if strings.HasPrefix(urlPath, u.prefix+"/") {
+			path := strings.TrimPrefix(urlPath, u.prefix+"/")
+			if path == "" || strings.Contains(path, "..") {
+				return nil, ""
+			}
+			return u, path
+		}
+	}
+	return nil, ""
+}
+
+// rewrite rewrites the links in 'content' (an index page from 'u') so that
+// they point at the cache at 'baseURL' (e.g. "http://10.0.0.1:656").
+func (u *upstream) rewrite(content []byte, baseURL string) []byte {
+	for from, to := range u.links {
+		content = bytes.Replace(content, []byte(from), []byte(baseURL+to), -1)
+	}
+	return content
+}
+
+// WorkerEnv returns the env vars that configure pip, npm and conda to
+// download packages through the artifact cache at 'host':'port'
+func WorkerEnv(host string, port uint16) map[string]string {
+	baseURL := fmt.Sprintf("http://%s:%d", host, port)
+	return map[string]string{
+		"PIP_INDEX_URL":       baseURL + "/pypi/simple",
+		"PIP_TRUSTED_HOST":    host,
+		"NPM_CONFIG_REGISTRY": baseURL + "/npm/",
+		"CONDA_CHANNEL_ALIAS": baseURL + "/conda",
+	}
+}
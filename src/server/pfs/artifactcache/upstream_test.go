@@ -0,0 +1,43 @@
+package artifactcache
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestRoute(t *testing.T) {
+	for _, c := range []struct {
+		urlPath, prefix, path string
+		immutable             bool
+	}{
+		{"/pypi/simple/numpy/", "pypi", "simple/numpy/", false},
+		{"/pypi/files/packages/ab/cd/numpy-1.18.1.whl", "pypi/files", "packages/ab/cd/numpy-1.18.1.whl", true},
+		{"/npm/left-pad", "npm", "left-pad", false},
+		{"/npm/left-pad/-/left-pad-1.3.0.tgz", "npm", "left-pad/-/left-pad-1.3.0.tgz", true},
+		{"/conda/conda-forge/linux-64/repodata.json", "conda", "conda-forge/linux-64/repodata.json", false},
+		{"/conda/conda-forge/linux-64/zlib-1.2.11-0.tar.bz2", "conda", "conda-forge/linux-64/zlib-1.2.11-0.tar.bz2", true},
+	} {
+		u, p := route(c.urlPath)
+		require.NotNil(t, u, c.urlPath)
+		require.Equal(t, c.prefix, u.prefix)
+		require.Equal(t, c.path, p)
+		require.Equal(t, c.immutable, u.immutable(p), c.urlPath)
+	}
+	for _, urlPath := range []string{"/", "/pypi/", "/maven/junit", "/npm/../pypi/simple"} {
+		u, _ := route(urlPath)
+		require.True(t, u == nil, urlPath)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	u, _ := route("/pypi/simple/numpy/")
+	content := `<a href="https://files.pythonhosted.org/packages/ab/numpy.whl#sha256=00">numpy.whl</a>`
+	require.Equal(t,
+		`<a href="http://10.0.0.1:657/pypi/files/packages/ab/numpy.whl#sha256=00">numpy.whl</a>`,
+		string(u.rewrite([]byte(content), "http://10.0.0.1:657")))
+
+	env := WorkerEnv("10.0.0.1", 657)
+	require.Equal(t, "http://10.0.0.1:657/pypi/simple", env["PIP_INDEX_URL"])
+	require.Equal(t, "10.0.0.1", env["PIP_TRUSTED_HOST"])
+}
@@ -2,6 +2,7 @@ package cmds
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -11,19 +12,23 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	gosync "sync"
+	"time"
 
 	prompt "github.com/c-bata/go-prompt"
-	"github.com/gogo/protobuf/jsonpb"
+	units "github.com/docker/go-units"
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/limit"
 	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errcode"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/client/version"
 	"github.com/pachyderm/pachyderm/src/server/cmd/pachctl/shell"
 	"github.com/pachyderm/pachyderm/src/server/pfs/pretty"
 	"github.com/pachyderm/pachyderm/src/server/pkg/cmdutil"
-	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/mirror"
 	"github.com/pachyderm/pachyderm/src/server/pkg/pager"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
 	"github.com/pachyderm/pachyderm/src/server/pkg/sync"
@@ -43,9 +48,9 @@ const (
 func Cmds() []*cobra.Command {
 	var commands []*cobra.Command
 
-	raw := false
-	rawFlags := pflag.NewFlagSet("", pflag.ContinueOnError)
-	rawFlags.BoolVar(&raw, "raw", false, "disable pretty printing, print raw json")
+	// pfs commands have always printed raw resources in lowerCamelCase
+	output := &cmdutil.Output{}
+	outputFlags := output.Flags("output", "o")
 
 	fullTimestamps := false
 	fullTimestampsFlags := pflag.NewFlagSet("", pflag.ContinueOnError)
@@ -55,8 +60,6 @@ func Cmds() []*cobra.Command {
 	noPagerFlags := pflag.NewFlagSet("", pflag.ContinueOnError)
 	noPagerFlags.BoolVar(&noPager, "no-pager", false, "Don't pipe output into a pager (i.e. less).")
 
-	marshaller := &jsonpb.Marshaler{Indent: "  "}
-
 	repoDocs := &cobra.Command{
 		Short: "Docs for repos.",
 		Long: `Repos, short for repository, are the top level data objects in Pachyderm.
@@ -67,6 +70,8 @@ or type (e.g. csv, binary, images, etc).`,
 	commands = append(commands, cmdutil.CreateDocsAlias(repoDocs, "repo", " repo$"))
 
 	var description string
+	var quotaSize string
+	var quotaCommits int64
 	createRepo := &cobra.Command{
 		Use:   "{{alias}} <repo>",
 		Short: "Create a new repo.",
@@ -78,12 +83,17 @@ or type (e.g. csv, binary, images, etc).`,
 			}
 			defer c.Close()
 
+			quota, err := parseRepoQuota(quotaSize, quotaCommits)
+			if err != nil {
+				return err
+			}
 			err = txncmds.WithActiveTransaction(c, func(c *client.APIClient) error {
 				_, err = c.PfsAPIClient.CreateRepo(
 					c.Ctx(),
 					&pfsclient.CreateRepoRequest{
 						Repo:        client.NewRepo(args[0]),
 						Description: description,
+						Quota:       quota,
 					},
 				)
 				return err
@@ -92,6 +102,8 @@ or type (e.g. csv, binary, images, etc).`,
 		}),
 	}
 	createRepo.Flags().StringVarP(&description, "description", "d", "", "A description of the repo.")
+	createRepo.Flags().StringVar(&quotaSize, "quota-size", "", "The maximum total size of the commits in the repo, e.g. '10G'.")
+	createRepo.Flags().Int64Var(&quotaCommits, "quota-commits", 0, "The maximum number of commits in the repo.")
 	commands = append(commands, cmdutil.CreateAlias(createRepo, "create repo"))
 
 	updateRepo := &cobra.Command{
@@ -105,6 +117,10 @@ or type (e.g. csv, binary, images, etc).`,
 			}
 			defer c.Close()
 
+			quota, err := parseRepoQuota(quotaSize, quotaCommits)
+			if err != nil {
+				return err
+			}
 			err = txncmds.WithActiveTransaction(c, func(c *client.APIClient) error {
 				_, err = c.PfsAPIClient.CreateRepo(
 					c.Ctx(),
@@ -112,6 +128,7 @@ or type (e.g. csv, binary, images, etc).`,
 						Repo:        client.NewRepo(args[0]),
 						Description: description,
 						Update:      true,
+						Quota:       quota,
 					},
 				)
 				return err
@@ -120,9 +137,12 @@ or type (e.g. csv, binary, images, etc).`,
 		}),
 	}
 	updateRepo.Flags().StringVarP(&description, "description", "d", "", "A description of the repo.")
+	updateRepo.Flags().StringVar(&quotaSize, "quota-size", "", "The maximum total size of the commits in the repo, e.g. '10G'. If neither quota flag is set, the repo's quota is unchanged.")
+	updateRepo.Flags().Int64Var(&quotaCommits, "quota-commits", 0, "The maximum number of commits in the repo. If neither quota flag is set, the repo's quota is unchanged.")
 	shell.RegisterCompletionFunc(updateRepo, shell.RepoCompletion)
 	commands = append(commands, cmdutil.CreateAlias(updateRepo, "update repo"))
 
+	var showUsage bool
 	inspectRepo := &cobra.Command{
 		Use:   "{{alias}} <repo>",
 		Short: "Return info about a repo.",
@@ -133,6 +153,10 @@ or type (e.g. csv, binary, images, etc).`,
 				return err
 			}
 			defer c.Close()
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
 			repoInfo, err := c.InspectRepo(args[0])
 			if err != nil {
 				return err
@@ -140,17 +164,37 @@ or type (e.g. csv, binary, images, etc).`,
 			if repoInfo == nil {
 				return fmt.Errorf("repo %s not found", args[0])
 			}
-			if raw {
-				return marshaller.Marshal(os.Stdout, repoInfo)
+			var usage *pfsclient.StorageUsage
+			if showUsage {
+				usage, err = c.GetRepoStorageUsage(args[0])
+				if err != nil {
+					return err
+				}
+			}
+			if e != nil {
+				if err := e.EncodeProto(repoInfo); err != nil {
+					return err
+				}
+				if usage != nil {
+					return e.EncodeProto(usage)
+				}
+				return nil
 			}
 			ri := &pretty.PrintableRepoInfo{
 				RepoInfo:       repoInfo,
-				FullTimestamps: fullTimestamps,
+				FullTimestamps: fullTimestamps || output.Wide(),
+			}
+			if err := pretty.PrintDetailedRepoInfo(ri); err != nil {
+				return err
 			}
-			return pretty.PrintDetailedRepoInfo(ri)
+			if usage != nil {
+				return pretty.PrintStorageUsage(usage)
+			}
+			return nil
 		}),
 	}
-	inspectRepo.Flags().AddFlagSet(rawFlags)
+	inspectRepo.Flags().BoolVar(&showUsage, "usage", false, "Also report the repo's storage usage: the total size of its commits, and the size of the deduplicated data they reference (this reads every commit in the repo, so it may be slow).")
+	inspectRepo.Flags().AddFlagSet(outputFlags)
 	inspectRepo.Flags().AddFlagSet(fullTimestampsFlags)
 	shell.RegisterCompletionFunc(inspectRepo, shell.RepoCompletion)
 	commands = append(commands, cmdutil.CreateAlias(inspectRepo, "inspect repo"))
@@ -164,13 +208,17 @@ or type (e.g. csv, binary, images, etc).`,
 				return err
 			}
 			defer c.Close()
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
 			repoInfos, err := c.ListRepo()
 			if err != nil {
 				return err
 			}
-			if raw {
+			if e != nil {
 				for _, repoInfo := range repoInfos {
-					if err := marshaller.Marshal(os.Stdout, repoInfo); err != nil {
+					if err := e.EncodeProto(repoInfo); err != nil {
 						return err
 					}
 				}
@@ -183,12 +231,12 @@ or type (e.g. csv, binary, images, etc).`,
 			}
 			writer := tabwriter.NewWriter(os.Stdout, header)
 			for _, repoInfo := range repoInfos {
-				pretty.PrintRepoInfo(writer, repoInfo, fullTimestamps)
+				pretty.PrintRepoInfo(writer, repoInfo, fullTimestamps || output.Wide())
 			}
 			return writer.Flush()
 		}),
 	}
-	listRepo.Flags().AddFlagSet(rawFlags)
+	listRepo.Flags().AddFlagSet(outputFlags)
 	listRepo.Flags().AddFlagSet(fullTimestampsFlags)
 	commands = append(commands, cmdutil.CreateAlias(listRepo, "list repo"))
 
@@ -247,6 +295,7 @@ Commits can be created with another commit as a parent.`,
 	commands = append(commands, cmdutil.CreateDocsAlias(commitDocs, "commit", " commit$"))
 
 	var parent string
+	var metadata []string
 	startCommit := &cobra.Command{
 		Use:   "{{alias}} <repo>@<branch-or-commit>",
 		Short: "Start a new commit.",
@@ -261,12 +310,27 @@ $ {{alias}} test@master
 $ {{alias}} test@patch -p master
 
 # Start a commit with XXX as the parent in repo "test", not on any branch
-$ {{alias}} test -p XXX`,
+$ {{alias}} test -p XXX
+
+# Start a commit on branch "master" in repo "test", recording the batch that
+# its data came from
+$ {{alias}} test@master --metadata source=warehouse --metadata batch=1234`,
 		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
 			branch, err := cmdutil.ParseBranch(args[0])
 			if err != nil {
 				return err
 			}
+			var commitMetadata map[string]string
+			if len(metadata) > 0 {
+				commitMetadata = make(map[string]string)
+				for _, m := range metadata {
+					kv := strings.SplitN(m, "=", 2)
+					if len(kv) != 2 {
+						return fmt.Errorf("malformed metadata %q, expected key=value", m)
+					}
+					commitMetadata[kv[0]] = kv[1]
+				}
+			}
 			c, err := client.NewOnUserMachine("user")
 			if err != nil {
 				return err
@@ -282,6 +346,7 @@ $ {{alias}} test -p XXX`,
 						Branch:      branch.Name,
 						Parent:      client.NewCommit(branch.Repo.Name, parent),
 						Description: description,
+						Metadata:    commitMetadata,
 					},
 				)
 				return err
@@ -296,6 +361,7 @@ $ {{alias}} test -p XXX`,
 	startCommit.MarkFlagCustom("parent", "__pachctl_get_commit $(__parse_repo ${nouns[0]})")
 	startCommit.Flags().StringVarP(&description, "message", "m", "", "A description of this commit's contents")
 	startCommit.Flags().StringVar(&description, "description", "", "A description of this commit's contents (synonym for --message)")
+	startCommit.Flags().StringArrayVar(&metadata, "metadata", nil, "Metadata for the commit, as key=value (may be repeated). It's passed to the jobs that process the commit.")
 	shell.RegisterCompletionFunc(startCommit, shell.BranchCompletion)
 	commands = append(commands, cmdutil.CreateAlias(startCommit, "start commit"))
 
@@ -347,6 +413,10 @@ $ {{alias}} test -p XXX`,
 			}
 			defer c.Close()
 
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
 			commitInfo, err := c.InspectCommit(commit.Repo.Name, commit.ID)
 			if err != nil {
 				return err
@@ -354,23 +424,24 @@ $ {{alias}} test -p XXX`,
 			if commitInfo == nil {
 				return fmt.Errorf("commit %s not found", commit.ID)
 			}
-			if raw {
-				return marshaller.Marshal(os.Stdout, commitInfo)
+			if e != nil {
+				return e.EncodeProto(commitInfo)
 			}
 			ci := &pretty.PrintableCommitInfo{
 				CommitInfo:     commitInfo,
-				FullTimestamps: fullTimestamps,
+				FullTimestamps: fullTimestamps || output.Wide(),
 			}
 			return pretty.PrintDetailedCommitInfo(ci)
 		}),
 	}
-	inspectCommit.Flags().AddFlagSet(rawFlags)
+	inspectCommit.Flags().AddFlagSet(outputFlags)
 	inspectCommit.Flags().AddFlagSet(fullTimestampsFlags)
 	shell.RegisterCompletionFunc(inspectCommit, shell.BranchCompletion)
 	commands = append(commands, cmdutil.CreateAlias(inspectCommit, "inspect commit"))
 
 	var from string
 	var number int
+	var watchList bool
 	listCommit := &cobra.Command{
 		Use:   "{{alias}} <repo>[@<branch>]",
 		Short: "Return all commits on a repo.",
@@ -386,7 +457,11 @@ $ {{alias}} foo@master
 $ {{alias}} foo@master -n 20
 
 # return commits in repo "foo" since commit XXX
-$ {{alias}} foo@master --from XXX`,
+$ {{alias}} foo@master --from XXX
+
+# show the commits on branch "master" of repo "foo" in a table that's
+# updated as they change
+$ {{alias}} foo@master --watch`,
 		Run: cmdutil.RunFixedArgs(1, func(args []string) (retErr error) {
 			c, err := client.NewOnUserMachine("user")
 			if err != nil {
@@ -399,14 +474,38 @@ $ {{alias}} foo@master --from XXX`,
 				return err
 			}
 
-			if raw {
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
+			if watchList {
+				if from != "" || number != 0 {
+					return fmt.Errorf("cannot set --watch with --from or --number")
+				}
+				if e != nil {
+					return c.WatchCommit(branch.Repo.Name, branch.Name, func(ci *pfsclient.CommitInfo) error {
+						return e.EncodeProto(ci)
+					})
+				}
+				// Newest commits first, as in the static table
+				table := tabwriter.NewLiveTable(os.Stdout, pretty.CommitHeader, true)
+				return table.Run(func() error {
+					return c.WatchCommit(branch.Repo.Name, branch.Name, func(ci *pfsclient.CommitInfo) error {
+						var buf bytes.Buffer
+						pretty.PrintCommitInfo(&buf, ci, fullTimestamps || output.Wide())
+						table.Set(ci.Commit.ID, tabwriter.SortableTimestamp(ci.Started), buf.String())
+						return nil
+					})
+				})
+			}
+			if e != nil {
 				return c.ListCommitF(branch.Repo.Name, branch.Name, from, uint64(number), false, func(ci *pfsclient.CommitInfo) error {
-					return marshaller.Marshal(os.Stdout, ci)
+					return e.EncodeProto(ci)
 				})
 			}
 			writer := tabwriter.NewWriter(os.Stdout, pretty.CommitHeader)
 			if err := c.ListCommitF(branch.Repo.Name, branch.Name, from, uint64(number), false, func(ci *pfsclient.CommitInfo) error {
-				pretty.PrintCommitInfo(writer, ci, fullTimestamps)
+				pretty.PrintCommitInfo(writer, ci, fullTimestamps || output.Wide())
 				return nil
 			}); err != nil {
 				return err
@@ -416,14 +515,19 @@ $ {{alias}} foo@master --from XXX`,
 	}
 	listCommit.Flags().StringVarP(&from, "from", "f", "", "list all commits since this commit")
 	listCommit.Flags().IntVarP(&number, "number", "n", 0, "list only this many commits; if set to zero, list all commits")
+	listCommit.Flags().BoolVarP(&watchList, "watch", "w", false, "After listing commits, keep the list updated as commits change.")
 	listCommit.MarkFlagCustom("from", "__pachctl_get_commit $(__parse_repo ${nouns[0]})")
-	listCommit.Flags().AddFlagSet(rawFlags)
+	listCommit.Flags().AddFlagSet(outputFlags)
 	listCommit.Flags().AddFlagSet(fullTimestampsFlags)
 	shell.RegisterCompletionFunc(listCommit, shell.RepoCompletion)
 	commands = append(commands, cmdutil.CreateAlias(listCommit, "list commit"))
 
 	printCommitIter := func(commitIter client.CommitInfoIterator) error {
-		if raw {
+		e, err := output.Encoder(os.Stdout)
+		if err != nil {
+			return err
+		}
+		if e != nil {
 			for {
 				commitInfo, err := commitIter.Next()
 				if err == io.EOF {
@@ -432,7 +536,7 @@ $ {{alias}} foo@master --from XXX`,
 				if err != nil {
 					return err
 				}
-				if err := marshaller.Marshal(os.Stdout, commitInfo); err != nil {
+				if err := e.EncodeProto(commitInfo); err != nil {
 					return err
 				}
 			}
@@ -446,7 +550,7 @@ $ {{alias}} foo@master --from XXX`,
 			if err != nil {
 				return err
 			}
-			pretty.PrintCommitInfo(writer, commitInfo, fullTimestamps)
+			pretty.PrintCommitInfo(writer, commitInfo, fullTimestamps || output.Wide())
 		}
 		return writer.Flush()
 	}
@@ -489,7 +593,7 @@ $ {{alias}} foo@XXX -r bar -r baz`,
 	}
 	flushCommit.Flags().VarP(&repos, "repos", "r", "Wait only for commits leading to a specific set of repos")
 	flushCommit.MarkFlagCustom("repos", "__pachctl_get_repo")
-	flushCommit.Flags().AddFlagSet(rawFlags)
+	flushCommit.Flags().AddFlagSet(outputFlags)
 	flushCommit.Flags().AddFlagSet(fullTimestampsFlags)
 	shell.RegisterCompletionFunc(flushCommit, shell.BranchCompletion)
 	commands = append(commands, cmdutil.CreateAlias(flushCommit, "flush commit"))
@@ -549,7 +653,7 @@ $ {{alias}} test@master --new`,
 	subscribeCommit.Flags().StringVar(&pipeline, "pipeline", "", "subscribe to all commits created by this pipeline")
 	subscribeCommit.MarkFlagCustom("from", "__pachctl_get_commit $(__parse_repo ${nouns[0]})")
 	subscribeCommit.Flags().BoolVar(&newCommits, "new", false, "subscribe to only new commits created from now on")
-	subscribeCommit.Flags().AddFlagSet(rawFlags)
+	subscribeCommit.Flags().AddFlagSet(outputFlags)
 	subscribeCommit.Flags().AddFlagSet(fullTimestampsFlags)
 	shell.RegisterCompletionFunc(subscribeCommit, shell.BranchCompletion)
 	commands = append(commands, cmdutil.CreateAlias(subscribeCommit, "subscribe commit"))
@@ -636,6 +740,10 @@ Any pachctl command that can take a Commit ID, can take a branch name instead.`,
 				return err
 			}
 
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
 			branchInfo, err := c.InspectBranch(branch.Repo.Name, branch.Name)
 			if err != nil {
 				return err
@@ -643,14 +751,14 @@ Any pachctl command that can take a Commit ID, can take a branch name instead.`,
 			if branchInfo == nil {
 				return fmt.Errorf("branch %s not found", args[0])
 			}
-			if raw {
-				return marshaller.Marshal(os.Stdout, branchInfo)
+			if e != nil {
+				return e.EncodeProto(branchInfo)
 			}
 
 			return pretty.PrintDetailedBranchInfo(branchInfo)
 		}),
 	}
-	inspectBranch.Flags().AddFlagSet(rawFlags)
+	inspectBranch.Flags().AddFlagSet(outputFlags)
 	inspectBranch.Flags().AddFlagSet(fullTimestampsFlags)
 	shell.RegisterCompletionFunc(inspectBranch, shell.BranchCompletion)
 	commands = append(commands, cmdutil.CreateAlias(inspectBranch, "inspect branch"))
@@ -659,19 +767,46 @@ Any pachctl command that can take a Commit ID, can take a branch name instead.`,
 		Use:   "{{alias}} <repo>",
 		Short: "Return all branches on a repo.",
 		Long:  "Return all branches on a repo.",
+		Example: `
+# return the branches of repo "foo"
+$ {{alias}} foo
+
+# show the branches of repo "foo" in a table that's updated as their heads
+# move
+$ {{alias}} foo --watch`,
 		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
 			c, err := client.NewOnUserMachine("user")
 			if err != nil {
 				return err
 			}
 			defer c.Close()
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
+			if watchList {
+				if e != nil {
+					return c.WatchBranch(args[0], func(bi *pfsclient.BranchInfo) error {
+						return e.EncodeProto(bi)
+					})
+				}
+				table := tabwriter.NewLiveTable(os.Stdout, pretty.BranchHeader, false)
+				return table.Run(func() error {
+					return c.WatchBranch(args[0], func(bi *pfsclient.BranchInfo) error {
+						var buf bytes.Buffer
+						pretty.PrintBranch(&buf, bi)
+						table.Set(bi.Name, bi.Name, buf.String())
+						return nil
+					})
+				})
+			}
 			branches, err := c.ListBranch(args[0])
 			if err != nil {
 				return err
 			}
-			if raw {
+			if e != nil {
 				for _, branch := range branches {
-					if err := marshaller.Marshal(os.Stdout, branch); err != nil {
+					if err := e.EncodeProto(branch); err != nil {
 						return err
 					}
 				}
@@ -684,7 +819,8 @@ Any pachctl command that can take a Commit ID, can take a branch name instead.`,
 			return writer.Flush()
 		}),
 	}
-	listBranch.Flags().AddFlagSet(rawFlags)
+	listBranch.Flags().AddFlagSet(outputFlags)
+	listBranch.Flags().BoolVarP(&watchList, "watch", "w", false, "After listing branches, keep the list updated as branches change.")
 	shell.RegisterCompletionFunc(listBranch, shell.RepoCompletion)
 	commands = append(commands, cmdutil.CreateAlias(listBranch, "list branch"))
 
@@ -712,6 +848,73 @@ Any pachctl command that can take a Commit ID, can take a branch name instead.`,
 	shell.RegisterCompletionFunc(deleteBranch, shell.BranchCompletion)
 	commands = append(commands, cmdutil.CreateAlias(deleteBranch, "delete branch"))
 
+	var retainHistory int64
+	var maxAge time.Duration
+	setBranchRetention := &cobra.Command{
+		Use:   "{{alias}} <repo>@<branch>",
+		Short: "Set the retention policy of a branch.",
+		Long:  "Set the retention policy of a branch. When garbage collection runs, it deletes the ancestors of the branch's head that are neither among its last --history commits nor younger than --max-age, along with any data that only they reference. Running this without either flag removes the branch's retention policy.",
+		Example: `
+# keep only the last 20 commits on master
+$ {{alias}} foo@master --history 20
+
+# keep the commits on master from the last 30 days, and always keep at least 5
+$ {{alias}} foo@master --max-age 720h --history 5`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			branch, err := cmdutil.ParseBranch(args[0])
+			if err != nil {
+				return err
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			return c.SetBranchRetention(branch.Repo.Name, branch.Name, retainHistory, maxAge)
+		}),
+	}
+	setBranchRetention.Flags().Int64Var(&retainHistory, "history", 0, "The number of most recent commits to keep.")
+	setBranchRetention.Flags().DurationVar(&maxAge, "max-age", 0, "Keep commits finished less than this long ago, e.g. '720h'.")
+	shell.RegisterCompletionFunc(setBranchRetention, shell.BranchCompletion)
+	commands = append(commands, cmdutil.CreateAlias(setBranchRetention, "set branch-retention"))
+
+	var trigger pfsclient.Trigger
+	setBranchTrigger := &cobra.Command{
+		Use:   "{{alias}} <repo>@<branch>",
+		Short: "Set the trigger of a branch.",
+		Long:  "Set the trigger of a branch. A branch with a trigger is moved to the head of the --from branch when a commit is finished there and the trigger's conditions are met, so pipelines that take the branch as input only run when the trigger fires. By default any one condition fires the trigger; --all requires all of them. Running this without --from removes the branch's trigger.",
+		Example: `
+# process the data on master once 10 commits have accumulated
+$ {{alias}} foo@staging --from master --commits 10
+
+# process the data on master once 1GB has been added, but at most once an hour
+$ {{alias}} foo@staging --from master --size 1GB --cron '@hourly' --all`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			branch, err := cmdutil.ParseBranch(args[0])
+			if err != nil {
+				return err
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			if trigger.Branch == "" {
+				return c.SetBranchTrigger(branch.Repo.Name, branch.Name, nil)
+			}
+			return c.SetBranchTrigger(branch.Repo.Name, branch.Name, &trigger)
+		}),
+	}
+	setBranchTrigger.Flags().StringVar(&trigger.Branch, "from", "", "The branch (in the same repo) whose commits fire the trigger.")
+	setBranchTrigger.Flags().StringVar(&trigger.CronSpec, "cron", "", "Fire the trigger when a tick of this cron schedule has passed since the branch was last moved.")
+	setBranchTrigger.Flags().StringVar(&trigger.Size_, "size", "", "Fire the trigger when this much data (e.g. '1GB') has been added since the branch was last moved.")
+	setBranchTrigger.Flags().Int64Var(&trigger.Commits, "commits", 0, "Fire the trigger when this many commits have been finished since the branch was last moved.")
+	setBranchTrigger.Flags().BoolVar(&trigger.All, "all", false, "Require all of the trigger's conditions to be met, rather than any one of them.")
+	shell.RegisterCompletionFunc(setBranchTrigger, shell.BranchCompletion)
+	commands = append(commands, cmdutil.CreateAlias(setBranchTrigger, "set branch-trigger"))
+
 	fileDocs := &cobra.Command{
 		Short: "Docs for files.",
 		Long: `Files are the lowest level data objects in Pachyderm.
@@ -732,6 +935,7 @@ from commits with 'get file'.`,
 	var headerRecords uint
 	var putFileCommit bool
 	var overwrite bool
+	var bestEffort bool
 	putFile := &cobra.Command{
 		Use:   "{{alias}} <repo>@<branch-or-commit>[:<path/in/pfs>]",
 		Short: "Put a file into the filesystem.",
@@ -758,6 +962,10 @@ $ {{alias}} -r repo@branch -f dir
 # Put the contents of a directory as repo/branch/file, i.e. put files at the top level:
 $ {{alias}} -r repo@branch:/ -f dir
 
+# Local files put with -r are uploaded in parallel (see --parallelism), and an
+# upload that's interrupted by a network failure is resumed where it left off.
+# The files are put in a single commit once they have all been uploaded.
+
 # Put the data from a URL as repo/branch/path:
 $ {{alias}} repo@branch:/path -f http://host/path
 
@@ -787,7 +995,14 @@ $ {{alias}} repo@branch -i http://host/path`,
 			defer c.Close()
 
 			// load data into pachyderm
-			pfc, err := c.NewPutFileClient()
+			mode := errcode.BulkMode_ATOMIC
+			if bestEffort {
+				if err := c.RequireFeatures(version.FeatureBulkResults); err != nil {
+					return err
+				}
+				mode = errcode.BulkMode_BEST_EFFORT
+			}
+			pfc, err := c.NewPutFileClientWithMode(mode)
 			if err != nil {
 				return err
 			}
@@ -801,6 +1016,12 @@ $ {{alias}} repo@branch -i http://host/path`,
 			}
 
 			limiter := limit.New(int(parallelism))
+			// Local files put with -r are uploaded in resumable upload
+			// sessions, if pachd supports them
+			var uploader *fileUploader
+			if recursive && split == "" && !bestEffort && c.RequireFeatures(version.FeatureResumableUpload) == nil {
+				uploader = newFileUploader(c, file.Commit.Repo.Name, file.Commit.ID, overwrite, parallelism)
+			}
 			var sources []string
 			if inputFile != "" {
 				// User has provided a file listing sources, one per line. Read sources
@@ -853,35 +1074,42 @@ $ {{alias}} repo@branch -i http://host/path`,
 						return fmt.Errorf("must specify filename when reading data from stdin")
 					}
 					eg.Go(func() error {
-						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, joinPaths("", source), source, recursive, overwrite, limiter, split, targetFileDatums, targetFileBytes, headerRecords, filesPut)
+						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, joinPaths("", source), source, recursive, overwrite, limiter, uploader, split, targetFileDatums, targetFileBytes, headerRecords, filesPut)
 					})
 				} else if len(sources) == 1 {
 					// We have a single source and the user has specified a path,
 					// we use the path and ignore source (in terms of naming the file).
 					eg.Go(func() error {
-						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, file.Path, source, recursive, overwrite, limiter, split, targetFileDatums, targetFileBytes, headerRecords, filesPut)
+						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, file.Path, source, recursive, overwrite, limiter, uploader, split, targetFileDatums, targetFileBytes, headerRecords, filesPut)
 					})
 				} else {
 					// We have multiple sources and the user has specified a path,
 					// we use that path as a prefix for the filepaths.
 					eg.Go(func() error {
-						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, joinPaths(file.Path, source), source, recursive, overwrite, limiter, split, targetFileDatums, targetFileBytes, headerRecords, filesPut)
+						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, joinPaths(file.Path, source), source, recursive, overwrite, limiter, uploader, split, targetFileDatums, targetFileBytes, headerRecords, filesPut)
 					})
 				}
 			}
-			return eg.Wait()
+			if err := eg.Wait(); err != nil {
+				return err
+			}
+			if uploader != nil {
+				return uploader.upload()
+			}
+			return nil
 		}),
 	}
 	putFile.Flags().StringSliceVarP(&filePaths, "file", "f", []string{"-"}, "The file to be put, it can be a local file or a URL.")
 	putFile.Flags().StringVarP(&inputFile, "input-file", "i", "", "Read filepaths or URLs from a file.  If - is used, paths are read from the standard input.")
 	putFile.Flags().BoolVarP(&recursive, "recursive", "r", false, "Recursively put the files in a directory.")
 	putFile.Flags().IntVarP(&parallelism, "parallelism", "p", DefaultParallelism, "The maximum number of files that can be uploaded in parallel.")
-	putFile.Flags().StringVar(&split, "split", "", "Split the input file into smaller files, subject to the constraints of --target-file-datums and --target-file-bytes. Permissible values are `line`, `json`, `sql` and `csv`.")
+	putFile.Flags().StringVar(&split, "split", "", "Split the input file into smaller files, subject to the constraints of --target-file-datums and --target-file-bytes. Permissible values are `line`, `json`, `sql`, `csv`, `parquet` (split on row groups) and `avro` (split on blocks).")
 	putFile.Flags().UintVar(&targetFileDatums, "target-file-datums", 0, "The upper bound of the number of datums that each file contains, the last file will contain fewer if the datums don't divide evenly; needs to be used with --split.")
 	putFile.Flags().UintVar(&targetFileBytes, "target-file-bytes", 0, "The target upper bound of the number of bytes that each file contains; needs to be used with --split.")
 	putFile.Flags().UintVar(&headerRecords, "header-records", 0, "the number of records that will be converted to a PFS 'header', and prepended to future retrievals of any subset of data from PFS; needs to be used with --split=(json|line|csv)")
 	putFile.Flags().BoolVarP(&putFileCommit, "commit", "c", false, "DEPRECATED: Put file(s) in a new commit.")
 	putFile.Flags().BoolVarP(&overwrite, "overwrite", "o", false, "Overwrite the existing content of the file, either from previous commits or previous calls to 'put file' within this commit.")
+	putFile.Flags().BoolVar(&bestEffort, "best-effort", false, "If some of the files can't be put, put the rest and report the ones that failed, rather than failing the whole request.")
 	shell.RegisterCompletionFunc(putFile,
 		func(flag, text string, maxCompletions int64) ([]prompt.Suggest, shell.CacheFunc) {
 			if flag == "-f" || flag == "--file" || flag == "-i" || flag == "input-file" {
@@ -993,6 +1221,10 @@ $ {{alias}} foo@master^2:XXX`,
 				return err
 			}
 			defer c.Close()
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
 			fileInfo, err := c.InspectFile(file.Commit.Repo.Name, file.Commit.ID, file.Path)
 			if err != nil {
 				return err
@@ -1000,13 +1232,13 @@ $ {{alias}} foo@master^2:XXX`,
 			if fileInfo == nil {
 				return fmt.Errorf("file %s not found", file.Path)
 			}
-			if raw {
-				return marshaller.Marshal(os.Stdout, fileInfo)
+			if e != nil {
+				return e.EncodeProto(fileInfo)
 			}
 			return pretty.PrintDetailedFileInfo(fileInfo)
 		}),
 	}
-	inspectFile.Flags().AddFlagSet(rawFlags)
+	inspectFile.Flags().AddFlagSet(outputFlags)
 	shell.RegisterCompletionFunc(inspectFile, shell.FileCompletion)
 	commands = append(commands, cmdutil.CreateAlias(inspectFile, "inspect file"))
 
@@ -1049,18 +1281,24 @@ $ {{alias}} foo@master --history all`,
 				return err
 			}
 			defer c.Close()
-			if raw {
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
+			if e != nil {
 				return c.ListFileF(file.Commit.Repo.Name, file.Commit.ID, file.Path, history, func(fi *pfsclient.FileInfo) error {
-					return marshaller.Marshal(os.Stdout, fi)
+					return e.EncodeProto(fi)
 				})
 			}
+			// Wide tables always say which commit each file is from
+			withCommit := history != 0 || output.Wide()
 			header := pretty.FileHeader
-			if history != 0 {
+			if withCommit {
 				header = pretty.FileHeaderWithCommit
 			}
 			writer := tabwriter.NewWriter(os.Stdout, header)
 			if err := c.ListFileF(file.Commit.Repo.Name, file.Commit.ID, file.Path, history, func(fi *pfsclient.FileInfo) error {
-				pretty.PrintFileInfo(writer, fi, fullTimestamps, history != 0)
+				pretty.PrintFileInfo(writer, fi, fullTimestamps || output.Wide(), withCommit)
 				return nil
 			}); err != nil {
 				return err
@@ -1068,7 +1306,7 @@ $ {{alias}} foo@master --history all`,
 			return writer.Flush()
 		}),
 	}
-	listFile.Flags().AddFlagSet(rawFlags)
+	listFile.Flags().AddFlagSet(outputFlags)
 	listFile.Flags().AddFlagSet(fullTimestampsFlags)
 	listFile.Flags().StringVar(&history, "history", "none", "Return revision history for files.")
 	shell.RegisterCompletionFunc(listFile, shell.FileCompletion)
@@ -1096,13 +1334,17 @@ $ {{alias}} "foo@master:data/*"`,
 				return err
 			}
 			defer c.Close()
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
 			fileInfos, err := c.GlobFile(file.Commit.Repo.Name, file.Commit.ID, file.Path)
 			if err != nil {
 				return err
 			}
-			if raw {
+			if e != nil {
 				for _, fileInfo := range fileInfos {
-					if err := marshaller.Marshal(os.Stdout, fileInfo); err != nil {
+					if err := e.EncodeProto(fileInfo); err != nil {
 						return err
 					}
 				}
@@ -1110,18 +1352,19 @@ $ {{alias}} "foo@master:data/*"`,
 			}
 			writer := tabwriter.NewWriter(os.Stdout, pretty.FileHeader)
 			for _, fileInfo := range fileInfos {
-				pretty.PrintFileInfo(writer, fileInfo, fullTimestamps, false)
+				pretty.PrintFileInfo(writer, fileInfo, fullTimestamps || output.Wide(), false)
 			}
 			return writer.Flush()
 		}),
 	}
-	globFile.Flags().AddFlagSet(rawFlags)
+	globFile.Flags().AddFlagSet(outputFlags)
 	globFile.Flags().AddFlagSet(fullTimestampsFlags)
 	shell.RegisterCompletionFunc(globFile, shell.FileCompletion)
 	commands = append(commands, cmdutil.CreateAlias(globFile, "glob file"))
 
 	var shallow bool
 	var nameOnly bool
+	var detectRenames bool
 	var diffCmdArg string
 	diffFile := &cobra.Command{
 		Use:   "{{alias}} <new-repo>@<new-branch-or-commit>:<new-path> [<old-repo>@<old-branch-or-commit>:<old-path>]",
@@ -1164,59 +1407,64 @@ $ {{alias}} foo@master:path1 bar@master:path2`,
 					}()
 				}
 
-				newFiles, oldFiles, err := c.DiffFile(
+				diffCmd := diffCommand(diffCmdArg)
+				return c.DiffFileF(
 					newFile.Commit.Repo.Name, newFile.Commit.ID, newFile.Path,
 					oldFile.Commit.Repo.Name, oldFile.Commit.ID, oldFile.Path,
-					shallow,
-				)
-				if err != nil {
-					return err
-				}
-				diffCmd := diffCommand(diffCmdArg)
-				return forEachDiffFile(newFiles, oldFiles, func(nFI, oFI *pfsclient.FileInfo) error {
-					if nameOnly {
+					shallow, detectRenames, func(fileDiff *pfsclient.FileDiff) error {
+						nFI, oFI := fileDiff.NewFile, fileDiff.OldFile
+						if fileDiff.Renamed {
+							if nameOnly {
+								pretty.PrintRenamedFileInfo(writer, oFI, nFI, fullTimestamps)
+							} else {
+								fmt.Fprintf(w, "renamed %s -> %s\n", oFI.File.Path, nFI.File.Path)
+							}
+							return nil
+						}
+						if nameOnly {
+							if nFI != nil {
+								pretty.PrintDiffFileInfo(writer, true, nFI, fullTimestamps)
+							}
+							if oFI != nil {
+								pretty.PrintDiffFileInfo(writer, false, oFI, fullTimestamps)
+							}
+							return nil
+						}
+						nPath, oPath := "/dev/null", "/dev/null"
 						if nFI != nil {
-							pretty.PrintDiffFileInfo(writer, true, nFI, fullTimestamps)
+							nPath, err = dlFile(c, nFI.File)
+							if err != nil {
+								return err
+							}
+							defer func() {
+								if err := os.RemoveAll(nPath); err != nil && retErr == nil {
+									retErr = err
+								}
+							}()
 						}
 						if oFI != nil {
-							pretty.PrintDiffFileInfo(writer, false, oFI, fullTimestamps)
+							oPath, err = dlFile(c, oFI.File)
+							defer func() {
+								if err := os.RemoveAll(oPath); err != nil && retErr == nil {
+									retErr = err
+								}
+							}()
 						}
-						return nil
-					}
-					nPath, oPath := "/dev/null", "/dev/null"
-					if nFI != nil {
-						nPath, err = dlFile(c, nFI.File)
-						if err != nil {
+						cmd := exec.Command(diffCmd[0], append(diffCmd[1:], oPath, nPath)...)
+						cmd.Stdout = w
+						cmd.Stderr = os.Stderr
+						// Diff returns exit code 1 when it finds differences
+						// between the files, so we catch it.
+						if err := cmd.Run(); err != nil && cmd.ProcessState.ExitCode() != 1 {
 							return err
 						}
-						defer func() {
-							if err := os.RemoveAll(nPath); err != nil && retErr == nil {
-								retErr = err
-							}
-						}()
-					}
-					if oFI != nil {
-						oPath, err = dlFile(c, oFI.File)
-						defer func() {
-							if err := os.RemoveAll(oPath); err != nil && retErr == nil {
-								retErr = err
-							}
-						}()
-					}
-					cmd := exec.Command(diffCmd[0], append(diffCmd[1:], oPath, nPath)...)
-					cmd.Stdout = w
-					cmd.Stderr = os.Stderr
-					// Diff returns exit code 1 when it finds differences
-					// between the files, so we catch it.
-					if err := cmd.Run(); err != nil && cmd.ProcessState.ExitCode() != 1 {
-						return err
-					}
-					return nil
-				})
+						return nil
+					})
 			})
 		}),
 	}
 	diffFile.Flags().BoolVarP(&shallow, "shallow", "s", false, "Don't descend into sub directories.")
+	diffFile.Flags().BoolVarP(&detectRenames, "detect-renames", "M", false, "Report deleted files whose contents match an added file as renames.")
 	diffFile.Flags().BoolVar(&nameOnly, "name-only", false, "Show only the names of changed files.")
 	diffFile.Flags().StringVar(&diffCmdArg, "diff-command", "", "Use a program other than git to diff files.")
 	diffFile.Flags().AddFlagSet(fullTimestampsFlags)
@@ -1224,12 +1472,13 @@ $ {{alias}} foo@master:path1 bar@master:path2`,
 	shell.RegisterCompletionFunc(diffFile, shell.FileCompletion)
 	commands = append(commands, cmdutil.CreateAlias(diffFile, "diff file"))
 
+	var deleteBestEffort bool
 	deleteFile := &cobra.Command{
-		Use:   "{{alias}} <repo>@<branch-or-commit>:<path/in/pfs>",
+		Use:   "{{alias}} <repo>@<branch-or-commit>:<path/in/pfs>...",
 		Short: "Delete a file.",
-		Long:  "Delete a file.",
-		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
-			file, err := cmdutil.ParseFile(args[0])
+		Long:  "Delete one or more files. Several files are deleted in a single request: either all of them are deleted or, if one of them can't be, none are (unless --best-effort is set).",
+		Run: cmdutil.RunMinimumArgs(1, func(args []string) error {
+			files, err := cmdutil.ParseFiles(args)
 			if err != nil {
 				return err
 			}
@@ -1239,9 +1488,25 @@ $ {{alias}} foo@master:path1 bar@master:path2`,
 			}
 			defer c.Close()
 
-			return c.DeleteFile(file.Commit.Repo.Name, file.Commit.ID, file.Path)
+			if len(files) == 1 && !deleteBestEffort {
+				file := files[0]
+				return c.DeleteFile(file.Commit.Repo.Name, file.Commit.ID, file.Path)
+			}
+			if err := c.RequireFeatures(version.FeatureBulkResults); err != nil {
+				return err
+			}
+			mode := errcode.BulkMode_ATOMIC
+			if deleteBestEffort {
+				mode = errcode.BulkMode_BEST_EFFORT
+			}
+			response, err := c.DeleteFiles(mode, files...)
+			if err != nil {
+				return err
+			}
+			return response.Err()
 		}),
 	}
+	deleteFile.Flags().BoolVar(&deleteBestEffort, "best-effort", false, "If some of the files can't be deleted, delete the rest and report the ones that failed, rather than deleting none of them.")
 	shell.RegisterCompletionFunc(deleteFile, shell.FileCompletion)
 	commands = append(commands, cmdutil.CreateAlias(deleteFile, "delete file"))
 
@@ -1291,6 +1556,235 @@ Tags are a low-level resource and should not be accessed directly by most users.
 	}
 	commands = append(commands, cmdutil.CreateAlias(getTag, "get tag"))
 
+	mirrorDocs := &cobra.Command{
+		Short: "Docs for mirrors.",
+		Long: `Mirrors asynchronously replicate the commits on a branch to the same branch
+of a repo in another Pachyderm cluster.
+
+The target cluster is referenced by the name of a pachctl context. Each
+finished commit on the source branch is copied to a new commit on the target,
+whose description records the source commit it was copied from. Provenance is
+preserved where the provenant commits have themselves been mirrored to the
+target.`,
+	}
+	commands = append(commands, cmdutil.CreateDocsAlias(mirrorDocs, "mirror", " mirror$"))
+
+	var target string
+	var once bool
+	var mirrorParallelism int
+	createMirror := &cobra.Command{
+		Use:   "{{alias}} <repo>@<branch>",
+		Short: "Replicate a branch to another cluster.",
+		Long:  "Replicate the commits on a branch to the same branch in another cluster. By default the mirror keeps running and copies new commits as they're finished; use --once to copy the existing commits and exit.",
+		Example: `
+# mirror the master branch of repo "test" to the cluster in context "dr"
+$ {{alias}} test@master --target dr
+
+# copy any commits that haven't been mirrored yet, and then exit
+$ {{alias}} test@master --target dr --once`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			branch, err := cmdutil.ParseBranch(args[0])
+			if err != nil {
+				return err
+			}
+			if target == "" {
+				return fmt.Errorf("--target must be set")
+			}
+			src, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+			dst, err := client.NewOnUserMachineForContext(target, "user")
+			if err != nil {
+				return err
+			}
+			defer dst.Close()
+			m := mirror.New(src, dst, branch.Repo.Name, branch.Name, mirrorParallelism)
+			if once {
+				return m.Sync()
+			}
+			return m.Run()
+		}),
+	}
+	createMirror.Flags().StringVar(&target, "target", "", "The name of the pachctl context of the cluster to mirror to.")
+	createMirror.Flags().BoolVar(&once, "once", false, "Copy the commits that haven't been mirrored yet, and then exit.")
+	createMirror.Flags().IntVarP(&mirrorParallelism, "parallelism", "p", mirror.DefaultParallelism, "The maximum number of files that can be copied in parallel.")
+	shell.RegisterCompletionFunc(createMirror, shell.BranchCompletion)
+	commands = append(commands, cmdutil.CreateAlias(createMirror, "create mirror"))
+
+	inspectMirror := &cobra.Command{
+		Use:   "{{alias}} <repo>@<branch>",
+		Short: "Return the replication status of a mirrored branch.",
+		Long:  "Return the replication status of a mirrored branch, including how far the target cluster lags behind the source.",
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			branch, err := cmdutil.ParseBranch(args[0])
+			if err != nil {
+				return err
+			}
+			if target == "" {
+				return fmt.Errorf("--target must be set")
+			}
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
+			src, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+			dst, err := client.NewOnUserMachineForContext(target, "user")
+			if err != nil {
+				return err
+			}
+			defer dst.Close()
+			status, err := mirror.New(src, dst, branch.Repo.Name, branch.Name, 0).Status()
+			if err != nil {
+				return err
+			}
+			if e != nil {
+				return e.Encode(status)
+			}
+			fmt.Printf("Source: %s@%s\n", branch.Repo.Name, branch.Name)
+			fmt.Printf("Target: %s\n", target)
+			fmt.Printf("Source head: %s\n", status.SourceHead.Commit.ID)
+			if status.LastMirrored != nil {
+				fmt.Printf("Last mirrored: %s\n", status.LastMirrored.Commit.ID)
+			} else {
+				fmt.Printf("Last mirrored: none\n")
+			}
+			fmt.Printf("Commits behind: %d\n", status.CommitsBehind)
+			fmt.Printf("Lag: %s\n", status.Lag)
+			return nil
+		}),
+	}
+	inspectMirror.Flags().StringVar(&target, "target", "", "The name of the pachctl context of the cluster being mirrored to.")
+	inspectMirror.Flags().AddFlagSet(outputFlags)
+	shell.RegisterCompletionFunc(inspectMirror, shell.BranchCompletion)
+	commands = append(commands, cmdutil.CreateAlias(inspectMirror, "inspect mirror"))
+
+	modelDocs := &cobra.Command{
+		Short: "Docs for models.",
+		Long: `A model is a registry of the commits in a repo (typically the output commits
+of a training pipeline) that have been registered as versions of the model.
+
+Versions are numbered in the order that they're registered, and can be
+labelled with stages, such as "staging" and "production". Each stage labels
+at most one version, and promoting a version to a stage atomically moves the
+label from the version that had it, so serving pipelines that look up a
+stage's commit always see a single, consistent version.`,
+	}
+	commands = append(commands, cmdutil.CreateDocsAlias(modelDocs, "model", " model$"))
+
+	var modelDescription string
+	var stage string
+	createModel := &cobra.Command{
+		Use:   "{{alias}} <repo>@<branch-or-commit>",
+		Short: "Register a commit as a new version of a repo's model.",
+		Long:  "Register a finished commit as a new version of the model in its repo, optionally labelling it with a stage. Registering a commit that's already a version of the model returns its existing version.",
+		Example: `
+# register the head of the master branch of repo "train" as a model version
+$ {{alias}} train@master --description "lr=0.01"
+
+# register a commit and label it as the staging version
+$ {{alias}} train@4a8bc1e2 --stage staging`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			commit, err := cmdutil.ParseCommit(args[0])
+			if err != nil {
+				return err
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			modelVersion, err := c.CreateModelVersion(commit.Repo.Name, commit.ID, modelDescription, stage)
+			if err != nil {
+				return err
+			}
+			fmt.Println(modelVersion.Version)
+			return nil
+		}),
+	}
+	createModel.Flags().StringVarP(&modelDescription, "description", "d", "", "A description of the model version.")
+	createModel.Flags().StringVar(&stage, "stage", "", "A stage to label the model version with, such as \"staging\" or \"production\".")
+	shell.RegisterCompletionFunc(createModel, shell.BranchCompletion)
+	commands = append(commands, cmdutil.CreateAlias(createModel, "create model"))
+
+	promoteModel := &cobra.Command{
+		Use:   "{{alias}} <repo> <version> <stage>",
+		Short: "Label a version of a repo's model with a stage.",
+		Long:  "Label a version of a repo's model with a stage, atomically moving the label from the version that had it.",
+		Example: `
+# make version 3 of the model in repo "train" the production version
+$ {{alias}} train 3 production`,
+		Run: cmdutil.RunFixedArgs(3, func(args []string) error {
+			modelVersion, err := strconv.ParseUint(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid model version %q: %v", args[1], err)
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			_, err = c.PromoteModel(args[0], modelVersion, args[2])
+			return err
+		}),
+	}
+	shell.RegisterCompletionFunc(promoteModel, shell.RepoCompletion)
+	commands = append(commands, cmdutil.CreateAlias(promoteModel, "promote model"))
+
+	inspectModel := &cobra.Command{
+		Use:   "{{alias}} <repo>",
+		Short: "Return the versions of a repo's model.",
+		Long:  "Return the versions of a repo's model and the stages that they're labelled with. With --stage, only the ID of the commit labelled with the stage is printed, for use by serving pipelines and scripts.",
+		Example: `
+# print the versions of the model in repo "train"
+$ {{alias}} train
+
+# print the ID of the production commit of the model in repo "train"
+$ {{alias}} train --stage production`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
+			if stage != "" {
+				modelVersion, err := c.ResolveModel(args[0], stage)
+				if err != nil {
+					return err
+				}
+				if e != nil {
+					return e.EncodeProto(modelVersion)
+				}
+				fmt.Println(modelVersion.Commit.ID)
+				return nil
+			}
+			modelInfo, err := c.InspectModel(args[0])
+			if err != nil {
+				return err
+			}
+			if e != nil {
+				return e.EncodeProto(modelInfo)
+			}
+			writer := tabwriter.NewWriter(os.Stdout, pretty.ModelVersionHeader)
+			pretty.PrintModelInfo(writer, modelInfo, fullTimestamps || output.Wide())
+			return writer.Flush()
+		}),
+	}
+	inspectModel.Flags().StringVar(&stage, "stage", "", "Only print the ID of the commit labelled with this stage.")
+	inspectModel.Flags().AddFlagSet(outputFlags)
+	inspectModel.Flags().AddFlagSet(fullTimestampsFlags)
+	shell.RegisterCompletionFunc(inspectModel, shell.RepoCompletion)
+	commands = append(commands, cmdutil.CreateAlias(inspectModel, "inspect model"))
+
 	var fix bool
 	fsck := &cobra.Command{
 		Use:   "{{alias}}",
@@ -1332,7 +1826,7 @@ Tags are a low-level resource and should not be accessed directly by most users.
 
 func putFileHelper(c *client.APIClient, pfc client.PutFileClient,
 	repo, commit, path, source string, recursive, overwrite bool, // destination
-	limiter limit.ConcurrencyLimiter,
+	limiter limit.ConcurrencyLimiter, uploader *fileUploader,
 	split string, targetFileDatums, targetFileBytes, headerRecords uint, // split
 	filesPut *gosync.Map) (retErr error) {
 	// Resolve the path, then trim any prefixed '../' to avoid sending bad paths
@@ -1374,9 +1868,13 @@ func putFileHelper(c *client.APIClient, pfc client.PutFileClient,
 			delimiter = pfsclient.Delimiter_SQL
 		case "csv":
 			delimiter = pfsclient.Delimiter_CSV
+		case "parquet":
+			delimiter = pfsclient.Delimiter_PARQUET
+		case "avro":
+			delimiter = pfsclient.Delimiter_AVRO
 		default:
 			return fmt.Errorf("unrecognized delimiter '%s'; only accepts one of "+
-				"{json,line,sql,csv}", split)
+				"{json,line,sql,csv,parquet,avro}", split)
 		}
 		_, err := pfc.PutFileSplit(repo, commit, path, delimiter, int64(targetFileDatums), int64(targetFileBytes), int64(headerRecords), overwrite, reader)
 		return err
@@ -1413,8 +1911,8 @@ func putFileHelper(c *client.APIClient, pfc client.PutFileClient,
 				// filePath into childDest, and then this walk loop will go on to the
 				// next one
 				return putFileHelper(c, pfc, repo, commit, childDest, filePath, false,
-					overwrite, limiter, split, targetFileDatums, targetFileBytes,
-					headerRecords, filesPut)
+					overwrite, limiter, uploader, split, targetFileDatums,
+					targetFileBytes, headerRecords, filesPut)
 			})
 			return nil
 		}); err != nil {
@@ -1422,6 +1920,10 @@ func putFileHelper(c *client.APIClient, pfc client.PutFileClient,
 		}
 		return eg.Wait()
 	}
+	if uploader != nil && split == "" {
+		// the file is uploaded once all of the files have been found
+		return uploader.add(path, source)
+	}
 	limiter.Acquire()
 	defer limiter.Release()
 	f, err := os.Open(source)
@@ -1436,6 +1938,29 @@ func putFileHelper(c *client.APIClient, pfc client.PutFileClient,
 	return putFile(f)
 }
 
+// parseRepoQuota converts the --quota-* flags into a RepoQuota, returning nil
+// if neither flag is set
+func parseRepoQuota(size string, commits int64) (*pfsclient.RepoQuota, error) {
+	if size == "" && commits == 0 {
+		return nil, nil
+	}
+	if commits < 0 {
+		return nil, fmt.Errorf("--quota-commits must be nonnegative")
+	}
+	quota := &pfsclient.RepoQuota{Commits: commits}
+	if size != "" {
+		sizeBytes, err := units.RAMInBytes(size)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse --quota-size: %v", err)
+		}
+		if sizeBytes < 0 {
+			return nil, fmt.Errorf("--quota-size must be nonnegative")
+		}
+		quota.SizeBytes = uint64(sizeBytes)
+	}
+	return quota, nil
+}
+
 func joinPaths(prefix, filePath string) string {
 	if url, err := url.Parse(filePath); err == nil && url.Scheme != "" {
 		if url.Scheme == "pfs" {
@@ -1493,33 +2018,3 @@ func diffCommand(cmdArg string) []string {
 	}
 	return []string{"diff"}
 }
-
-func forEachDiffFile(newFiles, oldFiles []*pfsclient.FileInfo, f func(newFile, oldFile *pfsclient.FileInfo) error) error {
-	nI, oI := 0, 0
-	for {
-		if nI == len(newFiles) && oI == len(oldFiles) {
-			return nil
-		}
-		var oFI *pfsclient.FileInfo
-		var nFI *pfsclient.FileInfo
-		switch {
-		case oI == len(oldFiles) || (nI < len(newFiles) && newFiles[nI].File.Path < oldFiles[oI].File.Path):
-			nFI = newFiles[nI]
-			nI++
-		case nI == len(newFiles) || (oI < len(oldFiles) && oldFiles[oI].File.Path < newFiles[nI].File.Path):
-			oFI = oldFiles[oI]
-			oI++
-		case newFiles[nI].File.Path == oldFiles[oI].File.Path:
-			nFI = newFiles[nI]
-			nI++
-			oFI = oldFiles[oI]
-			oI++
-		}
-		if err := f(nFI, oFI); err != nil {
-			if err == errutil.ErrBreak {
-				return nil
-			}
-			return err
-		}
-	}
-}
@@ -6,10 +6,13 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"syscall"
 
+	units "github.com/docker/go-units"
 	"github.com/hanwen/go-fuse/fuse/nodefs"
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/server/pfs/fuse"
@@ -34,11 +37,29 @@ func mountCmds() []*cobra.Command {
 
 	var debug bool
 	var commits cmdutil.RepeatedStringArg
+	var write bool
+	var cacheDir string
+	var cacheSize string
+	var prefetch int
 	mount := &cobra.Command{
 		Use:   "{{alias}} <path/to/mount/point>",
 		Short: "Mount pfs locally. This command blocks.",
-		Long:  "Mount pfs locally. This command blocks.",
+		Long: `Mount pfs locally. This command blocks.
+
+With --write, files in repos that are mounted at a branch can be written to.
+Writes are staged locally, and committed to the branch when pfs is unmounted or
+when 'pachctl mount commit' is run.`,
+		Example: `
+# Mount pfs read-only at /pfs
+$ {{alias}} /pfs
+
+# Mount pfs writably at /pfs, mounting the repo 'foo' at its branch 'dev'
+$ {{alias}} /pfs --write -c foo@dev`,
 		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			cacheBytes, err := units.RAMInBytes(cacheSize)
+			if err != nil {
+				return fmt.Errorf("invalid --cache-size: %v", err)
+			}
 			c, err := client.NewOnUserMachine("fuse")
 			if err != nil {
 				return err
@@ -53,7 +74,14 @@ func mountCmds() []*cobra.Command {
 				Fuse: &nodefs.Options{
 					Debug: debug,
 				},
-				Commits: commits,
+				Commits:        commits,
+				Write:          write,
+				CacheDir:       cacheDir,
+				ReadCacheBytes: cacheBytes,
+				Prefetch:       prefetch,
+			}
+			if prefetch == 0 {
+				opts.Prefetch = -1
 			}
 			return fuse.Mount(c, mountPoint, opts)
 		}),
@@ -61,8 +89,26 @@ func mountCmds() []*cobra.Command {
 	mount.Flags().BoolVarP(&debug, "debug", "d", false, "Turn on debug messages.")
 	mount.Flags().VarP(&commits, "commits", "c", "Commits to mount for repos, arguments should be of the form \"repo@commit\"")
 	mount.MarkFlagCustom("commits", "__pachctl_get_repo_branch")
+	mount.Flags().BoolVarP(&write, "write", "w", false, "Allow writes to repos that are mounted at a branch.")
+	mount.Flags().StringVar(&cacheDir, "cache-dir", "", "The directory to stage writes in, defaults to a temporary directory.")
+	mount.Flags().StringVar(&cacheSize, "cache-size", "256M", "The amount of file content to cache in memory.")
+	mount.Flags().IntVar(&prefetch, "prefetch", fuse.DefaultPrefetch, "The number of blocks to read ahead of sequential reads.")
 	commands = append(commands, cmdutil.CreateAlias(mount, "mount"))
 
+	mountCommit := &cobra.Command{
+		Use:   "{{alias}} <path/to/mount/point>",
+		Short: "Commit the writes to a writable mount.",
+		Long:  "Commit the writes to a mount that was created with 'pachctl mount --write'. The writes to each repo are committed to the branch that it's mounted at.",
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			err := ioutil.WriteFile(filepath.Join(args[0], fuse.CommitFile), []byte("\n"), 0644)
+			if os.IsNotExist(err) {
+				return fmt.Errorf("%s is not a writable pfs mount", args[0])
+			}
+			return err
+		}),
+	}
+	commands = append(commands, cmdutil.CreateAlias(mountCommit, "mount commit"))
+
 	var all bool
 	unmount := &cobra.Command{
 		Use:   "{{alias}} <path/to/mount/point>",
@@ -0,0 +1,163 @@
+package cmds
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/limit"
+	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/server/pfs/pretty"
+
+	"golang.org/x/crypto/ssh/terminal"
+	"golang.org/x/sync/errgroup"
+)
+
+// fileUploader uploads local files in parallel using upload sessions, which
+// resume where they left off if the connection to pachd fails. The files are
+// all put by a single FinishUpload call, so files put into a branch are put
+// in a single commit.
+type fileUploader struct {
+	c           *client.APIClient
+	repo        string
+	commit      string
+	overwrite   bool
+	parallelism int
+
+	mu    sync.Mutex
+	files []*localFile
+}
+
+// localFile is a local file that's uploaded to 'path'
+type localFile struct {
+	path   string
+	source string
+	size   int64
+	upload *pfsclient.Upload
+}
+
+func newFileUploader(c *client.APIClient, repo, commit string, overwrite bool, parallelism int) *fileUploader {
+	return &fileUploader{
+		c:           c,
+		repo:        repo,
+		commit:      commit,
+		overwrite:   overwrite,
+		parallelism: parallelism,
+	}
+}
+
+// add adds the local file 'source' to the files to upload, to be put at
+// 'path'
+func (u *fileUploader) add(path, source string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.files = append(u.files, &localFile{
+		path:   path,
+		source: source,
+		size:   info.Size(),
+	})
+	return nil
+}
+
+// upload uploads and puts the files that were added. If stderr is a
+// terminal, it shows the upload's progress there.
+func (u *fileUploader) upload() (retErr error) {
+	if len(u.files) == 0 {
+		return nil
+	}
+	defer func() {
+		if retErr == nil {
+			return
+		}
+		// Clean up the sessions, since the uploaded files won't be put
+		for _, f := range u.files {
+			if f.upload != nil {
+				u.c.DeleteUpload(f.upload)
+			}
+		}
+	}()
+	var total, done uint64
+	var filesDone int64
+	for _, f := range u.files {
+		total += uint64(f.size)
+	}
+	if terminal.IsTerminal(int(os.Stderr.Fd())) {
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			ticker := time.NewTicker(500 * time.Millisecond)
+			defer ticker.Stop()
+			for {
+				uploaded := atomic.LoadUint64(&done)
+				rate := float64(uploaded) / time.Since(start).Seconds()
+				// Redraw the bar in place
+				fmt.Fprintf(os.Stderr, "\r%s\033[K", pretty.UploadProgressBar(uploaded, total,
+					int(atomic.LoadInt64(&filesDone)), len(u.files), rate, 40))
+				select {
+				case <-stop:
+					fmt.Fprintln(os.Stderr)
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+		defer wg.Wait()
+		defer close(stop)
+	}
+	limiter := limit.New(u.parallelism)
+	var eg errgroup.Group
+	for _, f := range u.files {
+		f := f
+		limiter.Acquire()
+		eg.Go(func() error {
+			defer limiter.Release()
+			if err := u.uploadFile(f, &done); err != nil {
+				return fmt.Errorf("error uploading %s: %v", f.source, err)
+			}
+			atomic.AddInt64(&filesDone, 1)
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+	var uploads []*pfsclient.Upload
+	for _, f := range u.files {
+		uploads = append(uploads, f.upload)
+	}
+	return u.c.FinishUpload(uploads...)
+}
+
+// uploadFile uploads 'f', adding the number of bytes uploaded to 'done' as
+// the upload progresses
+func (u *fileUploader) uploadFile(f *localFile, done *uint64) (retErr error) {
+	upload, err := u.c.StartUpload(u.repo, u.commit, f.path, u.overwrite)
+	if err != nil {
+		return err
+	}
+	f.upload = upload
+	r, err := os.Open(f.source)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := r.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	var uploaded uint64
+	return u.c.UploadFile(upload, r, f.size, func(n uint64) {
+		atomic.AddUint64(done, n-uploaded)
+		uploaded = n
+	})
+}
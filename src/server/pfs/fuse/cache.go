@@ -0,0 +1,103 @@
+package fuse
+
+import (
+	"container/list"
+	"sync"
+)
+
+// blockSize is the size of the blocks that file content is read and cached in
+const blockSize = 4 * 1024 * 1024
+
+// blockKey identifies a block of a file
+type blockKey struct {
+	// file is the file, as "repo@commit:path". Commits are immutable, so
+	// blocks can be cached across opens of the file.
+	file  string
+	index int64
+}
+
+type block struct {
+	key  blockKey
+	data []byte
+}
+
+// fetch is an in-progress read of a block, which concurrent gets of the
+// block wait for
+type fetch struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// blockCache is an LRU cache of file blocks, which holds at most 'size' bytes
+type blockCache struct {
+	size int64
+
+	mu       sync.Mutex
+	used     int64
+	lru      *list.List // of *block, most recently used first
+	blocks   map[blockKey]*list.Element
+	fetching map[blockKey]*fetch
+}
+
+func newBlockCache(size int64) *blockCache {
+	return &blockCache{
+		size:     size,
+		lru:      list.New(),
+		blocks:   make(map[blockKey]*list.Element),
+		fetching: make(map[blockKey]*fetch),
+	}
+}
+
+// get returns the block 'key', calling 'read' to read it if it isn't cached.
+// Concurrent gets of a block that isn't cached share a single read.
+func (c *blockCache) get(key blockKey, read func() ([]byte, error)) ([]byte, error) {
+	c.mu.Lock()
+	if e, ok := c.blocks[key]; ok {
+		c.lru.MoveToFront(e)
+		c.mu.Unlock()
+		return e.Value.(*block).data, nil
+	}
+	if f, ok := c.fetching[key]; ok {
+		c.mu.Unlock()
+		<-f.done
+		return f.data, f.err
+	}
+	f := &fetch{done: make(chan struct{})}
+	c.fetching[key] = f
+	c.mu.Unlock()
+
+	f.data, f.err = read()
+	c.mu.Lock()
+	delete(c.fetching, key)
+	if f.err == nil {
+		c.add(key, f.data)
+	}
+	c.mu.Unlock()
+	close(f.done)
+	return f.data, f.err
+}
+
+// has returns true if the block 'key' is cached or being read
+func (c *blockCache) has(key blockKey) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, cached := c.blocks[key]
+	_, fetching := c.fetching[key]
+	return cached || fetching
+}
+
+// add adds a block to the cache, evicting the least recently used blocks to
+// make room for it. c.mu must be held.
+func (c *blockCache) add(key blockKey, data []byte) {
+	if int64(len(data)) > c.size {
+		return
+	}
+	c.blocks[key] = c.lru.PushFront(&block{key: key, data: data})
+	c.used += int64(len(data))
+	for c.used > c.size {
+		b := c.lru.Remove(c.lru.Back()).(*block)
+		delete(c.blocks, b.key)
+		c.used -= int64(len(b.data))
+	}
+}
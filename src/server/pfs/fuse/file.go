@@ -1,11 +1,8 @@
 package fuse
 
 import (
-	"context"
-	"io"
-	"io/ioutil"
-	"math"
-	"os"
+	"bytes"
+	"fmt"
 	"sync"
 	"syscall"
 	"time"
@@ -15,14 +12,19 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/pfs"
 )
 
+// file is a pfs file opened for reading. Its content is read in blocks, which
+// are cached in the filesystem's block cache, and the blocks after sequential
+// reads are read ahead of time.
 type file struct {
+	fs      *filesystem
 	name    string
 	attr    *fuse.Attr
-	cancel  func()
 	pfsFile *pfs.File
-	file    *os.File
-	counter *counter
-	err     error
+
+	mu sync.Mutex
+	// next is the offset after the last read, reads that start there are
+	// sequential
+	next int64
 }
 
 func newFile(fs *filesystem, name string) (*file, fuse.Status) {
@@ -30,42 +32,19 @@ func newFile(fs *filesystem, name string) (*file, fuse.Status) {
 	if status != fuse.OK {
 		return nil, status
 	}
-	ctx, cancel := context.WithCancel(fs.c.Ctx())
-	c := fs.c.WithCtx(ctx)
-	f, err := ioutil.TempFile("", "pfs-fuse")
-	if err != nil {
-		return nil, fuse.ToStatus(err)
-	}
-	if err := os.Remove(f.Name()); err != nil {
-		return nil, fuse.ToStatus(err)
-	}
 	_, pfsFile, err := fs.parsePath(name)
 	if err != nil {
 		return nil, toStatus(err)
 	}
-	if pfsFile == nil {
+	if pfsFile == nil || attr.IsDir() {
 		return nil, fuse.Status(syscall.EISDIR)
 	}
-	counter := newCounter()
-	// Argument order is important here because it means that writes to w must
-	// complete writing to f before being written to counter. Thus counter can
-	// tell us conclusively at least (but not at most) a certain number of
-	// bytes has been written to f.
-	w := io.MultiWriter(f, counter)
-	result := &file{
+	return &file{
+		fs:      fs,
+		name:    name,
 		attr:    attr,
-		cancel:  cancel,
 		pfsFile: pfsFile,
-		file:    f,
-		counter: counter,
-	}
-	go func() {
-		if err := c.GetFile(pfsFile.Commit.Repo.Name, pfsFile.Commit.ID, pfsFile.Path, 0, 0, w); err != nil {
-			result.err = err
-			counter.cancel()
-		}
-	}()
-	return result, fuse.OK
+	}, fuse.OK
 }
 
 func (f *file) Write(data []byte, off int64) (written uint32, code fuse.Status) {
@@ -83,19 +62,72 @@ func (f *file) InnerFile() nodefs.File {
 }
 
 func (f *file) Read(dest []byte, offset int64) (fuse.ReadResult, fuse.Status) {
-	waitn := offset + int64(len(dest))
-	if waitn > int64(f.attr.Size) {
-		waitn = int64(f.attr.Size)
+	size := int64(f.attr.Size)
+	end := offset + int64(len(dest))
+	if end > size {
+		end = size
 	}
-	f.counter.wait(waitn)
-	// check if there was an error reading the file
-	if f.err != nil {
-		return nil, toStatus(f.err)
+	if offset >= end {
+		return fuse.ReadResultData(nil), fuse.OK
 	}
-	if err := f.file.Sync(); err != nil {
-		return nil, toStatus(err)
+	result := make([]byte, 0, end-offset)
+	for i := offset / blockSize; i*blockSize < end; i++ {
+		data, err := f.block(i)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		start, stop := offset-i*blockSize, end-i*blockSize
+		if start < 0 {
+			start = 0
+		}
+		if stop > int64(len(data)) {
+			stop = int64(len(data))
+		}
+		if start >= stop {
+			break
+		}
+		result = append(result, data[start:stop]...)
+	}
+	f.prefetch(offset, end)
+	return fuse.ReadResultData(result), fuse.OK
+}
+
+// block returns the i'th block of the file
+func (f *file) block(i int64) ([]byte, error) {
+	key := f.blockKey(i)
+	return f.fs.cache.get(key, func() ([]byte, error) {
+		var buf bytes.Buffer
+		if err := f.fs.c.GetFile(f.pfsFile.Commit.Repo.Name, f.pfsFile.Commit.ID, f.pfsFile.Path, i*blockSize, blockSize, &buf); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	})
+}
+
+func (f *file) blockKey(i int64) blockKey {
+	return blockKey{
+		file:  fmt.Sprintf("%s@%s:%s", f.pfsFile.Commit.Repo.Name, f.pfsFile.Commit.ID, f.pfsFile.Path),
+		index: i,
+	}
+}
+
+// prefetch reads the blocks after 'end' into the cache in the background, if
+// the read from 'offset' to 'end' continued the previous read
+func (f *file) prefetch(offset, end int64) {
+	f.mu.Lock()
+	sequential := offset == f.next
+	f.next = end
+	f.mu.Unlock()
+	if !sequential {
+		return
+	}
+	first := (end-1)/blockSize + 1
+	for i := first; i < first+int64(f.fs.prefetch) && i*blockSize < int64(f.attr.Size); i++ {
+		if f.fs.cache.has(f.blockKey(i)) {
+			continue
+		}
+		go f.block(i)
 	}
-	return fuse.ReadResultFd(f.file.Fd(), offset, len(dest)), fuse.OK
 }
 
 func (f *file) Flock(flags int) fuse.Status {
@@ -107,9 +139,7 @@ func (f *file) Flush() fuse.Status {
 	return fuse.OK
 }
 
-func (f *file) Release() {
-	f.cancel()
-}
+func (f *file) Release() {}
 
 func (f *file) Fsync(flags int) (code fuse.Status) {
 	return fuse.EROFS
@@ -139,40 +169,3 @@ func (f *file) Utimens(atime *time.Time, mtime *time.Time) fuse.Status {
 func (f *file) Allocate(off uint64, size uint64, mode uint32) fuse.Status {
 	return fuse.EROFS
 }
-
-type counter struct {
-	n    int64
-	mu   sync.Mutex
-	cond *sync.Cond
-}
-
-func newCounter() *counter {
-	result := &counter{}
-	result.cond = sync.NewCond(&result.mu)
-	return result
-}
-
-func (c *counter) Write(p []byte) (int, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.n += int64(len(p))
-	c.cond.Broadcast()
-	return len(p), nil
-}
-
-// wait until more than n bytes have been written
-func (c *counter) wait(n int64) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	for c.n < n {
-		c.cond.Wait()
-	}
-}
-
-// cancel indicates that an error has occurred which will prevent any further
-// calls to Write, it causes all calls to wait() to return
-func (c *counter) cancel() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.n = math.MaxInt64
-}
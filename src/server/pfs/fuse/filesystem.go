@@ -22,10 +22,17 @@ const (
 )
 
 // Mount pfs to mountPoint, opts may be left nil.
+// Writes to writable mounts that haven't been committed are committed when
+// the mount is unmounted.
 func Mount(c *client.APIClient, mountPoint string, opts *Options) error {
-	nfs := pathfs.NewPathNodeFs(newFileSystem(c, opts.getCommits()), nil)
+	fs, err := newFileSystem(c, opts)
+	if err != nil {
+		return err
+	}
+	nfs := pathfs.NewPathNodeFs(fs, nil)
 	server, _, err := nodefs.MountRoot(mountPoint, nfs.Root(), opts.getFuse())
 	if err != nil {
+		fs.close()
 		return fmt.Errorf("nodefs.MountRoot: %v", err)
 	}
 	sigChan := make(chan os.Signal, 1)
@@ -38,7 +45,7 @@ func Mount(c *client.APIClient, mountPoint string, opts *Options) error {
 		server.Unmount()
 	}()
 	server.Serve()
-	return nil
+	return fs.close()
 }
 
 type filesystem struct {
@@ -46,24 +53,63 @@ type filesystem struct {
 	c         *client.APIClient
 	commits   map[string]string
 	commitsMu sync.RWMutex
+	cache     *blockCache
+	prefetch  int
+
+	// The fields below are only used by writable mounts, see writeback.go
+	write bool
+	// branches maps repos to the branches that writes to them are committed
+	// to. Repos mounted at a commit map to "".
+	branches map[string]string
+	// stageDir is the directory that writes are staged in, as
+	// <stageDir>/<repo>/<path>
+	stageDir string
+	// tempStageDir is true if stageDir was created by the mount, and is
+	// removed when it's unmounted
+	tempStageDir bool
+	// deleted contains the names of the pfs files and directories that have
+	// been deleted, but whose deletion hasn't been committed
+	deleted   map[string]bool
+	deletedMu sync.Mutex
+	// commitMu is held while writes are committed
+	commitMu sync.Mutex
 }
 
-func newFileSystem(c *client.APIClient, commits map[string]string) pathfs.FileSystem {
+func newFileSystem(c *client.APIClient, opts *Options) (*filesystem, error) {
+	commits := opts.getCommits()
 	if commits == nil {
 		commits = make(map[string]string)
 	}
-	return &filesystem{
+	fs := &filesystem{
 		FileSystem: pathfs.NewDefaultFileSystem(),
 		c:          c,
 		commits:    commits,
+		cache:      newBlockCache(opts.getReadCacheBytes()),
+		prefetch:   opts.getPrefetch(),
+	}
+	if opts.getWrite() {
+		if err := fs.initWrite(opts.getCacheDir()); err != nil {
+			return nil, err
+		}
 	}
+	return fs, nil
 }
 
 func (fs *filesystem) GetAttr(name string, context *fuse.Context) (*fuse.Attr, fuse.Status) {
+	if fs.write {
+		return fs.writableAttr(name)
+	}
 	return fs.getAttr(name)
 }
 
 func (fs *filesystem) OpenDir(name string, context *fuse.Context) ([]fuse.DirEntry, fuse.Status) {
+	if fs.write {
+		return fs.writableDir(name)
+	}
+	return fs.openDir(name)
+}
+
+func (fs *filesystem) openDir(name string) ([]fuse.DirEntry, fuse.Status) {
 	var result []fuse.DirEntry
 	r, f, err := fs.parsePath(name)
 	if err != nil {
@@ -109,6 +155,9 @@ func (fs *filesystem) OpenDir(name string, context *fuse.Context) ([]fuse.DirEnt
 }
 
 func (fs *filesystem) Open(name string, flags uint32, context *fuse.Context) (nodefs.File, fuse.Status) {
+	if fs.write {
+		return fs.openWritable(name, flags)
+	}
 	f := int(flags)
 	writeFlags := os.O_WRONLY | os.O_RDWR
 	if f&writeFlags != 0 {
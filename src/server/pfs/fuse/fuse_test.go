@@ -1,19 +1,25 @@
 package fuse
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"fmt"
 	"io/ioutil"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
+	"github.com/hanwen/go-fuse/fuse"
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
 	"github.com/pachyderm/pachyderm/src/client/pkg/require"
 	"github.com/pachyderm/pachyderm/src/server/pfs/server"
+	tu "github.com/pachyderm/pachyderm/src/server/pkg/testutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/workload"
 )
 
@@ -134,19 +140,204 @@ func TestHeadlessBranch(t *testing.T) {
 	})
 }
 
+func TestWrite(t *testing.T) {
+	c := server.GetPachClient(t, server.GetBasicConfig())
+	require.NoError(t, c.CreateRepo("repo"))
+	_, err := c.PutFile("repo", "master", "dir/file1", strings.NewReader("foo"))
+	require.NoError(t, err)
+	_, err = c.PutFile("repo", "master", "dir/file2", strings.NewReader("foo"))
+	require.NoError(t, err)
+	mountWithOptions(t, c, &Options{Write: true}, func(mountPoint string) {
+		dir := filepath.Join(mountPoint, "repo", "dir")
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "file1"), []byte("bar"), 0644))
+		require.NoError(t, os.Remove(filepath.Join(dir, "file2")))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "file3"), []byte("buzz"), 0644))
+
+		// The writes are visible through the mount, but aren't committed
+		files, err := ioutil.ReadDir(dir)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(files))
+		require.Equal(t, "file1", files[0].Name())
+		require.Equal(t, "file3", files[1].Name())
+		data, err := ioutil.ReadFile(filepath.Join(dir, "file1"))
+		require.NoError(t, err)
+		require.Equal(t, "bar", string(data))
+		var buf bytes.Buffer
+		require.NoError(t, c.GetFile("repo", "master", "dir/file1", 0, 0, &buf))
+		require.Equal(t, "foo", buf.String())
+
+		require.NoError(t, ioutil.WriteFile(filepath.Join(mountPoint, CommitFile), nil, 0644))
+		buf.Reset()
+		require.NoError(t, c.GetFile("repo", "master", "dir/file1", 0, 0, &buf))
+		require.Equal(t, "bar", buf.String())
+		_, err = c.InspectFile("repo", "master", "dir/file2")
+		require.YesError(t, err)
+
+		// Writes that haven't been committed are committed on unmount
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "file4"), []byte("fizz"), 0644))
+	})
+	buf := &bytes.Buffer{}
+	require.NoError(t, c.GetFile("repo", "master", "dir/file4", 0, 0, buf))
+	require.Equal(t, "fizz", buf.String())
+}
+
+func TestWriteCommit(t *testing.T) {
+	c := server.GetPachClient(t, server.GetBasicConfig())
+	require.NoError(t, c.CreateRepo("repo"))
+	commit, err := c.StartCommit("repo", "master")
+	require.NoError(t, err)
+	_, err = c.PutFile("repo", commit.ID, "file", strings.NewReader("foo"))
+	require.NoError(t, err)
+	require.NoError(t, c.FinishCommit("repo", commit.ID))
+	opts := &Options{
+		Write:   true,
+		Commits: map[string]string{"repo": commit.ID},
+	}
+	mountWithOptions(t, c, opts, func(mountPoint string) {
+		// Repos mounted at a commit aren't writable
+		err := ioutil.WriteFile(filepath.Join(mountPoint, "repo", "file"), []byte("bar"), 0644)
+		require.YesError(t, err)
+		data, err := ioutil.ReadFile(filepath.Join(mountPoint, "repo", "file"))
+		require.NoError(t, err)
+		require.Equal(t, "foo", string(data))
+	})
+}
+
+// TestWriteBack tests staging and committing writes without mounting pfs
+func TestWriteBack(t *testing.T) {
+	require.NoError(t, tu.WithRealEnv(func(env *tu.RealEnv) error {
+		c := env.PachClient
+		require.NoError(t, c.CreateRepo("repo"))
+		_, err := c.PutFile("repo", "master", "dir/file1", strings.NewReader("foo"))
+		require.NoError(t, err)
+		_, err = c.PutFile("repo", "master", "dir/file2", strings.NewReader("foo"))
+		require.NoError(t, err)
+		fs, err := newFileSystem(c, &Options{Write: true})
+		require.NoError(t, err)
+		stageDir := fs.stageDir
+
+		names := func(name string) []string {
+			entries, status := fs.OpenDir(name, nil)
+			require.Equal(t, fuse.OK, status)
+			var result []string
+			for _, entry := range entries {
+				result = append(result, entry.Name)
+			}
+			sort.Strings(result)
+			return result
+		}
+		write := func(name string, data string) {
+			f, status := fs.Create(name, uint32(os.O_WRONLY|os.O_TRUNC), 0644, nil)
+			require.Equal(t, fuse.OK, status)
+			_, status = f.Write([]byte(data), 0)
+			require.Equal(t, fuse.OK, status)
+			f.Release()
+		}
+		read := func(name string) string {
+			f, status := fs.Open(name, uint32(os.O_RDONLY), nil)
+			require.Equal(t, fuse.OK, status)
+			defer f.Release()
+			result, status := f.Read(make([]byte, 100), 0)
+			require.Equal(t, fuse.OK, status)
+			data, status := result.Bytes(make([]byte, 100))
+			require.Equal(t, fuse.OK, status)
+			return string(data)
+		}
+
+		// Appending to a file copies it from pfs
+		f, status := fs.Open("repo/dir/file1", uint32(os.O_WRONLY|os.O_APPEND), nil)
+		require.Equal(t, fuse.OK, status)
+		_, status = f.Write([]byte("bar"), 3)
+		require.Equal(t, fuse.OK, status)
+		f.Release()
+		require.Equal(t, "foobar", read("repo/dir/file1"))
+
+		write("repo/dir/file3", "buzz")
+		require.Equal(t, fuse.OK, fs.Unlink("repo/dir/file2", nil))
+		require.Equal(t, fuse.ENOENT, fs.Unlink("repo/dir/file2", nil))
+		require.Equal(t, fuse.OK, fs.Mkdir("repo/dir2", 0755, nil))
+		require.Equal(t, fuse.OK, fs.Rename("repo/dir/file3", "repo/dir2/file3", nil))
+		require.Equal(t, []string{"file1"}, names("repo/dir"))
+		require.Equal(t, []string{"dir", "dir2"}, names("repo"))
+		require.Equal(t, fuse.Status(syscall.ENOTEMPTY), fs.Rmdir("repo/dir", nil))
+		require.Equal(t, fuse.EPERM, fs.Mkdir("repo2", 0755, nil))
+
+		require.NoError(t, fs.commitWrites())
+		var buf bytes.Buffer
+		require.NoError(t, c.GetFile("repo", "master", "dir/file1", 0, 0, &buf))
+		require.Equal(t, "foobar", buf.String())
+		buf.Reset()
+		require.NoError(t, c.GetFile("repo", "master", "dir2/file3", 0, 0, &buf))
+		require.Equal(t, "buzz", buf.String())
+		fis, err := c.ListFile("repo", "master", "dir")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(fis))
+
+		// Deleting a directory and recreating a file in it only keeps the new file
+		require.Equal(t, fuse.OK, fs.Unlink("repo/dir/file1", nil))
+		require.Equal(t, fuse.OK, fs.Rmdir("repo/dir", nil))
+		require.Equal(t, fuse.OK, fs.Mkdir("repo/dir", 0755, nil))
+		write("repo/dir/file4", "fizz")
+		require.Equal(t, []string{"file4"}, names("repo/dir"))
+		require.NoError(t, fs.close())
+		fis, err = c.ListFile("repo", "master", "dir")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(fis))
+		require.Equal(t, "/dir/file4", fis[0].File.Path)
+		_, err = os.Stat(stageDir)
+		require.True(t, os.IsNotExist(err))
+		return nil
+	}))
+}
+
+func TestBlockCache(t *testing.T) {
+	cache := newBlockCache(10)
+	reads := 0
+	get := func(i int64) []byte {
+		data, err := cache.get(blockKey{file: "file", index: i}, func() ([]byte, error) {
+			reads++
+			return []byte{byte(i), byte(i), byte(i), byte(i)}, nil
+		})
+		require.NoError(t, err)
+		return data
+	}
+	require.Equal(t, []byte{1, 1, 1, 1}, get(1))
+	get(2)
+	get(1)
+	require.Equal(t, 2, reads)
+	// Block 2 is evicted, since it was used least recently
+	get(3)
+	require.True(t, cache.has(blockKey{file: "file", index: 1}))
+	require.False(t, cache.has(blockKey{file: "file", index: 2}))
+	get(2)
+	require.Equal(t, 4, reads)
+
+	_, err := cache.get(blockKey{file: "file", index: 4}, func() ([]byte, error) {
+		return nil, fmt.Errorf("error")
+	})
+	require.YesError(t, err)
+	require.False(t, cache.has(blockKey{file: "file", index: 4}))
+}
+
 func mount(tb testing.TB, c *client.APIClient, commits map[string]string, f func(mountPoint string)) {
+	mountWithOptions(tb, c, &Options{Commits: commits}, f)
+}
+
+func mountWithOptions(tb testing.TB, c *client.APIClient, opts *Options, f func(mountPoint string)) {
 	dir, err := ioutil.TempDir("", "pfs")
 	require.NoError(tb, err)
 	defer os.RemoveAll(dir)
-	opts := &Options{
-		Commits: commits,
-		Unmount: make(chan struct{}),
-	}
+	opts.Unmount = make(chan struct{})
+	done := make(chan struct{})
 	defer func() {
 		close(opts.Unmount)
+		<-done
 	}()
 	go func() {
-		Mount(c, dir, opts)
+		defer close(done)
+		if err := Mount(c, dir, opts); err != nil {
+			tb.Error(err)
+		}
 	}()
 	// Gotta give the fuse mount time to come up.
 	time.Sleep(2 * time.Second)
@@ -2,6 +2,15 @@ package fuse
 
 import "github.com/hanwen/go-fuse/fuse/nodefs"
 
+const (
+	// DefaultReadCacheBytes is the default number of bytes of file content
+	// that mounts cache in memory.
+	DefaultReadCacheBytes = 256 * 1024 * 1024
+	// DefaultPrefetch is the default number of blocks that mounts read ahead
+	// of sequential reads.
+	DefaultPrefetch = 4
+)
+
 // Options is for configuring fuse mounts. Any of the fields may be left nil
 // and `nil` itself is a valid set of Options which uses the default for
 // everything.
@@ -13,6 +22,23 @@ type Options struct {
 	Commits map[string]string
 
 	Unmount chan struct{}
+
+	// Write makes the mount writable. Writes are staged in CacheDir, and
+	// committed to the mounted branches when the mount is unmounted or when
+	// CommitFile is written to. Repos that are mounted at a commit, rather
+	// than a branch, can't be written to.
+	Write bool
+	// CacheDir is the directory that writes are staged in, if it's unset a
+	// temporary directory is used. Writes staged in CacheDir that couldn't be
+	// committed are committed by the next mount that uses it.
+	CacheDir string
+	// ReadCacheBytes is the number of bytes of file content to cache in
+	// memory, if it's unset DefaultReadCacheBytes is used.
+	ReadCacheBytes int64
+	// Prefetch is the number of blocks to read ahead of sequential reads, if
+	// it's unset DefaultPrefetch is used, and if it's negative nothing is
+	// read ahead.
+	Prefetch int
 }
 
 func (o *Options) getFuse() *nodefs.Options {
@@ -35,3 +61,34 @@ func (o *Options) getUnmount() chan struct{} {
 	}
 	return o.Unmount
 }
+
+func (o *Options) getWrite() bool {
+	if o == nil {
+		return false
+	}
+	return o.Write
+}
+
+func (o *Options) getCacheDir() string {
+	if o == nil {
+		return ""
+	}
+	return o.CacheDir
+}
+
+func (o *Options) getReadCacheBytes() int64 {
+	if o == nil || o.ReadCacheBytes == 0 {
+		return DefaultReadCacheBytes
+	}
+	return o.ReadCacheBytes
+}
+
+func (o *Options) getPrefetch() int {
+	if o == nil || o.Prefetch == 0 {
+		return DefaultPrefetch
+	}
+	if o.Prefetch < 0 {
+		return 0
+	}
+	return o.Prefetch
+}
@@ -0,0 +1,573 @@
+package fuse
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hanwen/go-fuse/fuse"
+	"github.com/hanwen/go-fuse/fuse/nodefs"
+	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// CommitFile is the name of a file at the root of writable mounts, writing to
+// it commits the writes that have been made to the mount.
+const CommitFile = ".commit"
+
+// Writable mounts stage writes in a local directory. A file is staged when
+// it's created or opened for writing (with its content copied from pfs, unless
+// it's truncated), and deletes of pfs files are recorded in fs.deleted. Reads
+// and listings prefer staged files, and hide deleted ones. When the writes are
+// committed, each repo's deletes and staged files are applied in a single
+// commit on the repo's branch.
+//
+// pfs has no empty directories, so directories that are created but left
+// empty aren't committed.
+
+func (fs *filesystem) initWrite(cacheDir string) error {
+	fs.write = true
+	fs.deleted = make(map[string]bool)
+	fs.branches = make(map[string]string)
+	for repo, commit := range fs.commits {
+		if uuid.IsUUIDWithoutDashes(commit) {
+			fs.branches[repo] = ""
+		} else {
+			fs.branches[repo] = commit
+		}
+	}
+	fs.stageDir = cacheDir
+	if fs.stageDir == "" {
+		dir, err := ioutil.TempDir("", "pfs-fuse")
+		if err != nil {
+			return err
+		}
+		fs.stageDir = dir
+		fs.tempStageDir = true
+	}
+	return os.MkdirAll(fs.stageDir, 0755)
+}
+
+// close commits the writes to writable mounts, and removes the directory
+// that they were staged in if the mount created it
+func (fs *filesystem) close() error {
+	if !fs.write {
+		return nil
+	}
+	if err := fs.commitWrites(); err != nil {
+		return fmt.Errorf("%v (uncommitted writes are staged in %s)", err, fs.stageDir)
+	}
+	if fs.tempStageDir {
+		return os.RemoveAll(fs.stageDir)
+	}
+	return nil
+}
+
+// branch returns the branch that writes to 'repo' are committed to, and
+// false if the repo is mounted at a commit
+func (fs *filesystem) branch(repo string) (string, bool) {
+	branch, ok := fs.branches[repo]
+	if !ok {
+		return "master", true
+	}
+	return branch, branch != ""
+}
+
+// checkWritable checks that 'name' is a path inside of a repo that can be
+// written to
+func (fs *filesystem) checkWritable(name string) fuse.Status {
+	components := strings.Split(name, "/")
+	if len(components) < 2 {
+		// repos can't be created or modified through the mount
+		return fuse.EPERM
+	}
+	if _, ok := fs.branch(components[0]); !ok {
+		return fuse.EROFS
+	}
+	if _, status := fs.getAttr(components[0]); status != fuse.OK {
+		return status
+	}
+	return fuse.OK
+}
+
+func (fs *filesystem) stagePath(name string) string {
+	return filepath.Join(fs.stageDir, filepath.FromSlash(name))
+}
+
+// staged returns the staged file or directory 'name', or nil if it isn't
+// staged
+func (fs *filesystem) staged(name string) (os.FileInfo, error) {
+	if !strings.Contains(name, "/") {
+		// the root and repos are never staged
+		return nil, nil
+	}
+	fi, err := os.Stat(fs.stagePath(name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return fi, err
+}
+
+// isDeleted returns true if 'name', or one of the directories containing it,
+// has been deleted from pfs
+func (fs *filesystem) isDeleted(name string) bool {
+	fs.deletedMu.Lock()
+	defer fs.deletedMu.Unlock()
+	for ; name != "." && name != ""; name = path.Dir(name) {
+		if fs.deleted[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// inPFS returns true if 'name' exists in pfs and hasn't been deleted
+func (fs *filesystem) inPFS(name string) bool {
+	if fs.isDeleted(name) {
+		return false
+	}
+	_, status := fs.getAttr(name)
+	return status == fuse.OK
+}
+
+// remove removes 'name' from the mount, by removing it from the stage and
+// deleting it from pfs
+func (fs *filesystem) remove(name string) fuse.Status {
+	inPFS := fs.inPFS(name)
+	if err := os.Remove(fs.stagePath(name)); err != nil && !os.IsNotExist(err) {
+		return fuse.ToStatus(err)
+	} else if os.IsNotExist(err) && !inPFS {
+		return fuse.ENOENT
+	}
+	if inPFS {
+		fs.deletedMu.Lock()
+		defer fs.deletedMu.Unlock()
+		fs.deleted[name] = true
+	}
+	return fuse.OK
+}
+
+// stage stages the file 'name', copying its content from pfs if 'copy' is
+// true
+func (fs *filesystem) stage(name string, copy bool) (retStatus fuse.Status) {
+	if status := fs.checkWritable(name); status != fuse.OK {
+		return status
+	}
+	p := fs.stagePath(name)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return fuse.ToStatus(err)
+	}
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fuse.ToStatus(err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil && retStatus == fuse.OK {
+			retStatus = fuse.ToStatus(err)
+		}
+	}()
+	if !copy || !fs.inPFS(name) {
+		return fuse.OK
+	}
+	_, pfsFile, err := fs.parsePath(name)
+	if err != nil {
+		return toStatus(err)
+	}
+	if err := fs.c.GetFile(pfsFile.Commit.Repo.Name, pfsFile.Commit.ID, pfsFile.Path, 0, 0, f); err != nil {
+		return toStatus(err)
+	}
+	return fuse.OK
+}
+
+func (fs *filesystem) writableAttr(name string) (*fuse.Attr, fuse.Status) {
+	if name == CommitFile {
+		return &fuse.Attr{Mode: fuse.S_IFREG | 0222}, fuse.OK
+	}
+	fi, err := fs.staged(name)
+	if err != nil {
+		return nil, fuse.ToStatus(err)
+	}
+	if fi != nil {
+		return fuse.ToAttr(fi), fuse.OK
+	}
+	if fs.isDeleted(name) {
+		return nil, fuse.ENOENT
+	}
+	attr, status := fs.getAttr(name)
+	if status != fuse.OK {
+		return nil, status
+	}
+	attr.Mode |= 0200
+	return attr, fuse.OK
+}
+
+func (fs *filesystem) writableDir(name string) ([]fuse.DirEntry, fuse.Status) {
+	if name == "" {
+		return fs.openDir(name)
+	}
+	var result []fuse.DirEntry
+	staged := make(map[string]bool)
+	fis, err := ioutil.ReadDir(fs.stagePath(name))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fuse.ToStatus(err)
+	}
+	for _, fi := range fis {
+		staged[fi.Name()] = true
+		result = append(result, fuse.DirEntry{
+			Name: fi.Name(),
+			Mode: fuse.ToAttr(fi).Mode,
+		})
+	}
+	if fs.isDeleted(name) {
+		if len(fis) == 0 && err != nil {
+			return nil, fuse.ENOENT
+		}
+		return result, fuse.OK
+	}
+	entries, status := fs.openDir(name)
+	if status != fuse.OK {
+		if status == fuse.ENOENT && err == nil {
+			// the directory is only staged
+			return result, fuse.OK
+		}
+		return nil, status
+	}
+	fs.deletedMu.Lock()
+	defer fs.deletedMu.Unlock()
+	for _, entry := range entries {
+		if !staged[entry.Name] && !fs.deleted[path.Join(name, entry.Name)] {
+			result = append(result, entry)
+		}
+	}
+	return result, fuse.OK
+}
+
+func (fs *filesystem) openWritable(name string, flags uint32) (nodefs.File, fuse.Status) {
+	if name == CommitFile {
+		return &commitFile{File: nodefs.NewDefaultFile(), fs: fs}, fuse.OK
+	}
+	fi, err := fs.staged(name)
+	if err != nil {
+		return nil, fuse.ToStatus(err)
+	}
+	if fi == nil {
+		if fs.isDeleted(name) {
+			return nil, fuse.ENOENT
+		}
+		if int(flags)&(os.O_WRONLY|os.O_RDWR) == 0 {
+			return newFile(fs, name)
+		}
+		if status := fs.stage(name, int(flags)&os.O_TRUNC == 0); status != fuse.OK {
+			return nil, status
+		}
+	}
+	// The kernel gives appends their offsets, and writes to files opened
+	// with O_APPEND can't have offsets
+	f, err := os.OpenFile(fs.stagePath(name), int(flags)&^os.O_APPEND, 0)
+	if err != nil {
+		return nil, fuse.ToStatus(err)
+	}
+	return nodefs.NewLoopbackFile(f), fuse.OK
+}
+
+func (fs *filesystem) Create(name string, flags uint32, mode uint32, context *fuse.Context) (nodefs.File, fuse.Status) {
+	if !fs.write {
+		return nil, fuse.EROFS
+	}
+	if status := fs.checkWritable(name); status != fuse.OK {
+		return nil, status
+	}
+	p := fs.stagePath(name)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return nil, fuse.ToStatus(err)
+	}
+	f, err := os.OpenFile(p, int(flags)&^os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fuse.ToStatus(err)
+	}
+	return nodefs.NewLoopbackFile(f), fuse.OK
+}
+
+func (fs *filesystem) Mkdir(name string, mode uint32, context *fuse.Context) fuse.Status {
+	if !fs.write {
+		return fuse.EROFS
+	}
+	if status := fs.checkWritable(name); status != fuse.OK {
+		return status
+	}
+	if _, status := fs.writableAttr(name); status == fuse.OK {
+		return fuse.Status(syscall.EEXIST)
+	}
+	return fuse.ToStatus(os.MkdirAll(fs.stagePath(name), 0755))
+}
+
+func (fs *filesystem) Unlink(name string, context *fuse.Context) fuse.Status {
+	if !fs.write {
+		return fuse.EROFS
+	}
+	if status := fs.checkWritable(name); status != fuse.OK {
+		return status
+	}
+	return fs.remove(name)
+}
+
+func (fs *filesystem) Rmdir(name string, context *fuse.Context) fuse.Status {
+	if !fs.write {
+		return fuse.EROFS
+	}
+	if status := fs.checkWritable(name); status != fuse.OK {
+		return status
+	}
+	entries, status := fs.writableDir(name)
+	if status != fuse.OK {
+		return status
+	}
+	if len(entries) > 0 {
+		return fuse.Status(syscall.ENOTEMPTY)
+	}
+	return fs.remove(name)
+}
+
+func (fs *filesystem) Rename(oldName string, newName string, context *fuse.Context) fuse.Status {
+	if !fs.write {
+		return fuse.EROFS
+	}
+	for _, name := range []string{oldName, newName} {
+		if status := fs.checkWritable(name); status != fuse.OK {
+			return status
+		}
+	}
+	attr, status := fs.writableAttr(oldName)
+	if status != fuse.OK {
+		return status
+	}
+	fi, err := fs.staged(oldName)
+	if err != nil {
+		return fuse.ToStatus(err)
+	}
+	if attr.IsDir() {
+		if fs.inPFS(oldName) {
+			// Moving a directory out of pfs would mean staging all of its
+			// files, EXDEV makes 'mv' copy them instead
+			return fuse.Status(syscall.EXDEV)
+		}
+	} else if fi == nil {
+		if status := fs.stage(oldName, true); status != fuse.OK {
+			return status
+		}
+	}
+	newPath := fs.stagePath(newName)
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return fuse.ToStatus(err)
+	}
+	if err := os.Rename(fs.stagePath(oldName), newPath); err != nil {
+		return fuse.ToStatus(err)
+	}
+	if fs.inPFS(oldName) {
+		fs.deletedMu.Lock()
+		defer fs.deletedMu.Unlock()
+		fs.deleted[oldName] = true
+	}
+	return fuse.OK
+}
+
+func (fs *filesystem) Truncate(name string, size uint64, context *fuse.Context) fuse.Status {
+	if !fs.write {
+		return fuse.EROFS
+	}
+	if name == CommitFile {
+		return fuse.OK
+	}
+	fi, err := fs.staged(name)
+	if err != nil {
+		return fuse.ToStatus(err)
+	}
+	if fi == nil {
+		if status := fs.stage(name, size > 0); status != fuse.OK {
+			return status
+		}
+	}
+	return fuse.ToStatus(os.Truncate(fs.stagePath(name), int64(size)))
+}
+
+func (fs *filesystem) Utimens(name string, atime *time.Time, mtime *time.Time, context *fuse.Context) fuse.Status {
+	if !fs.write {
+		return fuse.EROFS
+	}
+	fi, err := fs.staged(name)
+	if err != nil {
+		return fuse.ToStatus(err)
+	}
+	if fi == nil || atime == nil || mtime == nil {
+		// pfs doesn't store times
+		return fuse.OK
+	}
+	return fuse.ToStatus(os.Chtimes(fs.stagePath(name), *atime, *mtime))
+}
+
+func (fs *filesystem) Chmod(name string, mode uint32, context *fuse.Context) fuse.Status {
+	if !fs.write {
+		return fuse.EROFS
+	}
+	// pfs doesn't store modes
+	return fuse.OK
+}
+
+// commitWrites commits the writes to each repo in a single commit on the
+// repo's branch, and clears them from the stage
+func (fs *filesystem) commitWrites() error {
+	fs.commitMu.Lock()
+	defer fs.commitMu.Unlock()
+	repos := make(map[string]bool)
+	fis, err := ioutil.ReadDir(fs.stageDir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range fis {
+		if fi.IsDir() {
+			repos[fi.Name()] = true
+		}
+	}
+	fs.deletedMu.Lock()
+	for name := range fs.deleted {
+		repos[strings.Split(name, "/")[0]] = true
+	}
+	fs.deletedMu.Unlock()
+	var sorted []string
+	for repo := range repos {
+		sorted = append(sorted, repo)
+	}
+	sort.Strings(sorted)
+	for _, repo := range sorted {
+		if err := fs.commitRepo(repo); err != nil {
+			return fmt.Errorf("could not commit writes to %s: %v", repo, err)
+		}
+	}
+	return nil
+}
+
+// commitRepo commits the writes to 'repo'
+func (fs *filesystem) commitRepo(repo string) (retErr error) {
+	branch, ok := fs.branch(repo)
+	if !ok {
+		return fmt.Errorf("%s is mounted at a commit", repo)
+	}
+	prefix := repo + "/"
+	var deleted []string
+	fs.deletedMu.Lock()
+	for name := range fs.deleted {
+		if strings.HasPrefix(name, prefix) {
+			deleted = append(deleted, name)
+		}
+	}
+	fs.deletedMu.Unlock()
+	sort.Strings(deleted)
+	dir := fs.stagePath(repo)
+	var staged []string
+	if err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			staged = append(staged, p)
+		}
+		return nil
+	}); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(deleted) == 0 && len(staged) == 0 {
+		// only empty directories were staged
+		return os.RemoveAll(dir)
+	}
+
+	commit, err := fs.c.StartCommit(repo, branch)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if retErr != nil {
+			// don't leave the branch's head open
+			if err := fs.c.DeleteCommit(repo, commit.ID); err != nil {
+				log.Errorf("could not delete commit %s@%s: %v", repo, commit.ID, err)
+			}
+		}
+	}()
+	for _, name := range deleted {
+		if err := fs.c.DeleteFile(repo, commit.ID, strings.TrimPrefix(name, prefix)); err != nil {
+			return err
+		}
+	}
+	for _, p := range staged {
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if err := fs.putStaged(repo, commit.ID, filepath.ToSlash(rel), p); err != nil {
+			return err
+		}
+	}
+	if err := fs.c.FinishCommit(repo, commit.ID); err != nil {
+		return err
+	}
+
+	// The writes are committed, so clear them and read the new commit
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	fs.deletedMu.Lock()
+	for _, name := range deleted {
+		delete(fs.deleted, name)
+	}
+	fs.deletedMu.Unlock()
+	fs.commitsMu.Lock()
+	defer fs.commitsMu.Unlock()
+	fs.commits[repo] = commit.ID
+	return nil
+}
+
+func (fs *filesystem) putStaged(repo, commit, file, stagePath string) (retErr error) {
+	f, err := os.Open(stagePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := f.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	_, err = fs.c.PutFileOverwrite(repo, commit, file, f, 0)
+	return err
+}
+
+// commitFile is CommitFile, writes to it commit the writes to the mount
+type commitFile struct {
+	nodefs.File
+	fs *filesystem
+}
+
+func (f *commitFile) Write(data []byte, off int64) (uint32, fuse.Status) {
+	if err := f.fs.commitWrites(); err != nil {
+		log.Errorf("could not commit writes: %v", err)
+		return 0, fuse.EIO
+	}
+	return uint32(len(data)), fuse.OK
+}
+
+func (f *commitFile) Truncate(size uint64) fuse.Status {
+	return fuse.OK
+}
+
+func (f *commitFile) Flush() fuse.Status {
+	return fuse.OK
+}
+
+func (f *commitFile) GetAttr(out *fuse.Attr) fuse.Status {
+	out.Mode = fuse.S_IFREG | 0222
+	return fuse.OK
+}
@@ -5,7 +5,40 @@ import (
 	"regexp"
 
 	"github.com/pachyderm/pachyderm/src/client/pfs"
-	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errcode"
+
+	"google.golang.org/grpc/status"
+)
+
+// The resource types of the errors returned by PFS (see errcode.Error)
+const (
+	RepoResource   = "repos"
+	BranchResource = "branches"
+	CommitResource = "commits"
+	FileResource   = "files"
+	UploadResource = "uploads"
+	ModelResource  = "models"
+)
+
+// The reasons of the errors returned by PFS, which distinguish errors with
+// the same code and resource type (see errcode.Error)
+const (
+	ReasonFileNotFound            = "FILE_NOT_FOUND"
+	ReasonRepoNotFound            = "REPO_NOT_FOUND"
+	ReasonRepoExists              = "REPO_EXISTS"
+	ReasonCommitNotFound          = "COMMIT_NOT_FOUND"
+	ReasonNoHead                  = "NO_HEAD"
+	ReasonCommitExists            = "COMMIT_EXISTS"
+	ReasonCommitFinished          = "COMMIT_FINISHED"
+	ReasonCommitDeleted           = "COMMIT_DELETED"
+	ReasonParentCommitNotFound    = "PARENT_COMMIT_NOT_FOUND"
+	ReasonOutputCommitNotFinished = "OUTPUT_COMMIT_NOT_FINISHED"
+	ReasonQuotaExceeded           = "QUOTA_EXCEEDED"
+	ReasonUploadNotFound          = "UPLOAD_NOT_FOUND"
+	ReasonUploadOffset            = "UPLOAD_OFFSET_MISMATCH"
+	ReasonModelNotFound           = "MODEL_NOT_FOUND"
+	ReasonModelVersionNotFound    = "MODEL_VERSION_NOT_FOUND"
+	ReasonModelStageNotFound      = "MODEL_STAGE_NOT_FOUND"
 )
 
 // ErrFileNotFound represents a file-not-found error.
@@ -62,6 +95,46 @@ type ErrOutputCommitNotFinished struct {
 	Commit *pfs.Commit
 }
 
+// ErrQuotaExceeded represents an error where finishing a commit would put its
+// repo over the repo's quota
+type ErrQuotaExceeded struct {
+	Repo   *pfs.Repo
+	Reason string
+}
+
+// ErrUploadNotFound represents an error where an upload session doesn't
+// exist (or has already been finished)
+type ErrUploadNotFound struct {
+	Upload *pfs.Upload
+}
+
+// ErrUploadOffset represents an error where a chunk doesn't start at the end
+// of the data that's been uploaded so far
+type ErrUploadOffset struct {
+	Upload   *pfs.Upload
+	Offset   uint64
+	Expected uint64
+}
+
+// ErrModelNotFound represents an error where a repo has no model versions
+type ErrModelNotFound struct {
+	Repo *pfs.Repo
+}
+
+// ErrModelVersionNotFound represents an error where a version of a repo's
+// model doesn't exist
+type ErrModelVersionNotFound struct {
+	Repo    *pfs.Repo
+	Version uint64
+}
+
+// ErrModelStageNotFound represents an error where no version of a repo's
+// model is labelled with a stage
+type ErrModelStageNotFound struct {
+	Repo  *pfs.Repo
+	Stage string
+}
+
 func (e ErrFileNotFound) Error() string {
 	return fmt.Sprintf("file %v not found in repo %v at commit %v", e.File.Path, e.File.Commit.Repo.Name, e.File.Commit.ID)
 }
@@ -103,6 +176,118 @@ func (e ErrOutputCommitNotFinished) Error() string {
 	return fmt.Sprintf("output commit %v not finished", e.Commit.ID)
 }
 
+func (e ErrQuotaExceeded) Error() string {
+	return fmt.Sprintf("quota exceeded for repo %v: %v", e.Repo.Name, e.Reason)
+}
+
+func (e ErrUploadNotFound) Error() string {
+	return fmt.Sprintf("upload %v not found", e.Upload.Id)
+}
+
+func (e ErrUploadOffset) Error() string {
+	return fmt.Sprintf("chunk of upload %v starts at offset %d, but %d bytes have been uploaded", e.Upload.Id, e.Offset, e.Expected)
+}
+
+func (e ErrModelNotFound) Error() string {
+	return fmt.Sprintf("repo %v has no model versions", e.Repo.Name)
+}
+
+func (e ErrModelVersionNotFound) Error() string {
+	return fmt.Sprintf("version %d of model %v not found", e.Version, e.Repo.Name)
+}
+
+func (e ErrModelStageNotFound) Error() string {
+	return fmt.Sprintf("no version of model %v is in stage %q", e.Repo.Name, e.Stage)
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrFileNotFound) GRPCStatus() *status.Status {
+	return errcode.New(errcode.NotFound, e.Error()).WithResource(FileResource, fileName(e.File)).WithReason(ReasonFileNotFound).GRPCStatus()
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrRepoNotFound) GRPCStatus() *status.Status {
+	return errcode.New(errcode.NotFound, e.Error()).WithResource(RepoResource, e.Repo.Name).WithReason(ReasonRepoNotFound).GRPCStatus()
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrRepoExists) GRPCStatus() *status.Status {
+	return errcode.New(errcode.Conflict, e.Error()).WithResource(RepoResource, e.Repo.Name).WithReason(ReasonRepoExists).GRPCStatus()
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrCommitNotFound) GRPCStatus() *status.Status {
+	return errcode.New(errcode.NotFound, e.Error()).WithResource(CommitResource, commitName(e.Commit)).WithReason(ReasonCommitNotFound).GRPCStatus()
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrNoHead) GRPCStatus() *status.Status {
+	return errcode.New(errcode.NotFound, e.Error()).WithResource(BranchResource, e.Branch.Repo.Name+"@"+e.Branch.Name).WithReason(ReasonNoHead).GRPCStatus()
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrCommitExists) GRPCStatus() *status.Status {
+	return errcode.New(errcode.Conflict, e.Error()).WithResource(CommitResource, commitName(e.Commit)).WithReason(ReasonCommitExists).GRPCStatus()
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrCommitFinished) GRPCStatus() *status.Status {
+	return errcode.New(errcode.Conflict, e.Error()).WithResource(CommitResource, commitName(e.Commit)).WithReason(ReasonCommitFinished).GRPCStatus()
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrCommitDeleted) GRPCStatus() *status.Status {
+	return errcode.New(errcode.NotFound, e.Error()).WithResource(CommitResource, commitName(e.Commit)).WithReason(ReasonCommitDeleted).GRPCStatus()
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrParentCommitNotFound) GRPCStatus() *status.Status {
+	return errcode.New(errcode.NotFound, e.Error()).WithResource(CommitResource, commitName(e.Commit)).WithReason(ReasonParentCommitNotFound).GRPCStatus()
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrOutputCommitNotFinished) GRPCStatus() *status.Status {
+	return errcode.New(errcode.Conflict, e.Error()).WithResource(CommitResource, commitName(e.Commit)).WithReason(ReasonOutputCommitNotFinished).GRPCStatus()
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrQuotaExceeded) GRPCStatus() *status.Status {
+	return errcode.New(errcode.QuotaExceeded, e.Error()).WithResource(RepoResource, e.Repo.Name).WithReason(ReasonQuotaExceeded).GRPCStatus()
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrUploadNotFound) GRPCStatus() *status.Status {
+	return errcode.New(errcode.NotFound, e.Error()).WithResource(UploadResource, e.Upload.Id).WithReason(ReasonUploadNotFound).GRPCStatus()
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrUploadOffset) GRPCStatus() *status.Status {
+	return errcode.New(errcode.Conflict, e.Error()).WithResource(UploadResource, e.Upload.Id).WithReason(ReasonUploadOffset).GRPCStatus()
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrModelNotFound) GRPCStatus() *status.Status {
+	return errcode.New(errcode.NotFound, e.Error()).WithResource(ModelResource, e.Repo.Name).WithReason(ReasonModelNotFound).GRPCStatus()
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrModelVersionNotFound) GRPCStatus() *status.Status {
+	return errcode.New(errcode.NotFound, e.Error()).WithResource(ModelResource, e.Repo.Name).WithReason(ReasonModelVersionNotFound).GRPCStatus()
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrModelStageNotFound) GRPCStatus() *status.Status {
+	return errcode.New(errcode.NotFound, e.Error()).WithResource(ModelResource, e.Repo.Name).WithReason(ReasonModelStageNotFound).GRPCStatus()
+}
+
+func commitName(commit *pfs.Commit) string {
+	return commit.Repo.Name + "@" + commit.ID
+}
+
+func fileName(file *pfs.File) string {
+	return commitName(file.Commit) + ":" + file.Path
+}
+
 // ByteRangeSize returns byteRange.Upper - byteRange.Lower.
 func ByteRangeSize(byteRange *pfs.ByteRange) uint64 {
 	return byteRange.Upper - byteRange.Lower
@@ -117,76 +302,75 @@ var (
 	fileNotFoundRe            = regexp.MustCompile(`file .+ not found`)
 	hasNoHeadRe               = regexp.MustCompile(`the branch .+ has no head \(create one with 'start commit'\)`)
 	outputCommitNotFinishedRe = regexp.MustCompile("output commit .+ not finished")
+	quotaExceededRe           = regexp.MustCompile("quota exceeded for repo [^ ]+:")
 )
 
-// IsCommitNotFoundErr returns true if 'err' has an error message that matches
-// ErrCommitNotFound
-func IsCommitNotFoundErr(err error) bool {
+// isErr returns true if 'err' has one of 'reasons'. Errors without a reason
+// (e.g. errors from older versions of pachd, or errors that were wrapped in
+// another error) are matched by their message instead.
+func isErr(err error, re *regexp.Regexp, reasons ...string) bool {
 	if err == nil {
 		return false
 	}
-	return commitNotFoundRe.MatchString(grpcutil.ScrubGRPC(err).Error())
+	if e := errcode.FromError(err); e.Reason != "" {
+		for _, reason := range reasons {
+			if e.Reason == reason {
+				return true
+			}
+		}
+		return false
+	}
+	return re.MatchString(errcode.Message(err))
+}
+
+// IsCommitNotFoundErr returns true if 'err' is an ErrCommitNotFound or an
+// ErrParentCommitNotFound
+func IsCommitNotFoundErr(err error) bool {
+	return isErr(err, commitNotFoundRe, ReasonCommitNotFound, ReasonParentCommitNotFound)
 }
 
-// IsCommitDeletedErr returns true if 'err' has an error message that matches
-// ErrCommitDeleted
+// IsCommitDeletedErr returns true if 'err' is an ErrCommitDeleted
 func IsCommitDeletedErr(err error) bool {
-	if err == nil {
-		return false
-	}
-	return commitDeletedRe.MatchString(grpcutil.ScrubGRPC(err).Error())
+	return isErr(err, commitDeletedRe, ReasonCommitDeleted)
 }
 
-// IsCommitFinishedErr returns true of 'err' has an error message that matches
-// ErrCommitFinished
+// IsCommitFinishedErr returns true if 'err' is an ErrCommitFinished
 func IsCommitFinishedErr(err error) bool {
-	if err == nil {
-		return false
-	}
-	return commitFinishedRe.MatchString(grpcutil.ScrubGRPC(err).Error())
+	return isErr(err, commitFinishedRe, ReasonCommitFinished)
 }
 
-// IsRepoNotFoundErr returns true if 'err' is an error message about a repo
-// not being found
+// IsRepoNotFoundErr returns true if 'err' is an error about a repo not being
+// found
 func IsRepoNotFoundErr(err error) bool {
-	if err == nil {
-		return false
-	}
-	return repoNotFoundRe.MatchString(err.Error())
+	return isErr(err, repoNotFoundRe, ReasonRepoNotFound)
 }
 
-// IsBranchNotFoundErr returns true if 'err' is an error message about a
-// branch not being found
+// IsBranchNotFoundErr returns true if 'err' is an error about a branch not
+// being found
 func IsBranchNotFoundErr(err error) bool {
-	if err == nil {
-		return false
-	}
-	return branchNotFoundRe.MatchString(err.Error())
+	return isErr(err, branchNotFoundRe)
 }
 
-// IsFileNotFoundErr returns true if 'err' is an error message about a PFS
-// file not being found
+// IsFileNotFoundErr returns true if 'err' is an error about a PFS file not
+// being found
 func IsFileNotFoundErr(err error) bool {
-	if err == nil {
-		return false
-	}
-	return fileNotFoundRe.MatchString(err.Error())
+	return isErr(err, fileNotFoundRe, ReasonFileNotFound)
 }
 
 // IsNoHeadErr returns true if the err is due to an operation that cannot be
 // performed on a headless branch
 func IsNoHeadErr(err error) bool {
-	if err == nil {
-		return false
-	}
-	return hasNoHeadRe.MatchString(err.Error())
+	return isErr(err, hasNoHeadRe, ReasonNoHead)
 }
 
 // IsOutputCommitNotFinishedErr returns true if the err is due to an operation
 // that cannot be performed on an unfinished output commit
 func IsOutputCommitNotFinishedErr(err error) bool {
-	if err == nil {
-		return false
-	}
-	return outputCommitNotFinishedRe.MatchString(err.Error())
+	return isErr(err, outputCommitNotFinishedRe, ReasonOutputCommitNotFinished)
+}
+
+// IsQuotaExceededErr returns true if the err is due to a commit that would
+// put its repo over the repo's quota
+func IsQuotaExceededErr(err error) bool {
+	return isErr(err, quotaExceededRe, ReasonQuotaExceeded)
 }
@@ -1,10 +1,15 @@
 package pfs
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errcode"
+	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
 	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+
+	"google.golang.org/grpc/status"
 )
 
 func TestErrorMatching(t *testing.T) {
@@ -20,4 +25,33 @@ func TestErrorMatching(t *testing.T) {
 	require.False(t, IsCommitFinishedErr(ErrCommitNotFound{c}))
 	require.False(t, IsCommitFinishedErr(ErrCommitDeleted{c}))
 	require.True(t, IsCommitFinishedErr(ErrCommitFinished{c}))
+
+	require.True(t, IsQuotaExceededErr(ErrQuotaExceeded{c.Repo, "too big"}))
+	require.False(t, IsQuotaExceededErr(ErrCommitFinished{c}))
+}
+
+// sent returns 'err' as it's returned to clients by gRPC
+func sent(err error) error {
+	return status.ErrorProto(status.Convert(err).Proto())
+}
+
+func TestErrorCodes(t *testing.T) {
+	c := client.NewCommit("foo", "bar")
+	err := sent(ErrCommitNotFound{c})
+	require.True(t, errcode.IsNotFound(err))
+	require.True(t, errcode.Is(err, errcode.NotFound, CommitResource))
+	require.Equal(t, "foo@bar", errcode.FromError(err).ResourceName)
+	require.True(t, IsCommitNotFoundErr(err))
+	require.True(t, IsCommitNotFoundErr(grpcutil.ScrubGRPC(err)))
+	require.False(t, IsCommitDeletedErr(err))
+
+	// Errors are matched by their reason rather than their message
+	require.False(t, IsCommitNotFoundErr(sent(errcode.New(errcode.NotFound, ErrCommitNotFound{c}.Error()).WithReason(ReasonCommitDeleted))))
+	require.True(t, IsCommitDeletedErr(sent(ErrCommitDeleted{c})))
+	require.True(t, IsRepoNotFoundErr(sent(ErrRepoNotFound{c.Repo})))
+	require.True(t, errcode.IsConflict(sent(ErrCommitFinished{c})))
+	require.True(t, errcode.IsQuotaExceeded(sent(ErrQuotaExceeded{c.Repo, "too big"})))
+
+	// Errors without a reason are still matched by their message
+	require.True(t, IsCommitNotFoundErr(fmt.Errorf("could not inspect: %v", ErrCommitNotFound{c})))
 }
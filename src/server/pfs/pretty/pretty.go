@@ -5,9 +5,13 @@ import (
 	"html/template"
 	"io"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	units "github.com/docker/go-units"
 	"github.com/fatih/color"
+	"github.com/gogo/protobuf/types"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
 	"github.com/pachyderm/pachyderm/src/server/pkg/pretty"
 )
@@ -27,6 +31,8 @@ const (
 	FileHeaderWithCommit = "COMMIT\tNAME\tTYPE\tCOMMITTED\tSIZE\t\n"
 	// DiffFileHeader is the header for files produced by diff file.
 	DiffFileHeader = "OP\t" + FileHeader
+	// ModelVersionHeader is the header for model versions.
+	ModelVersionHeader = "VERSION\tCOMMIT\tSTAGES\tCREATED\tDESCRIPTION\t\n"
 )
 
 // PrintRepoInfo pretty-prints repo info.
@@ -66,7 +72,9 @@ func PrintDetailedRepoInfo(repoInfo *PrintableRepoInfo) error {
 Description: {{.Description}}{{end}}{{if .FullTimestamps}}
 Created: {{.Created}}{{else}}
 Created: {{prettyAgo .Created}}{{end}}
-Size of HEAD on master: {{prettySize .SizeBytes}}{{if .AuthInfo}}
+Size of HEAD on master: {{prettySize .SizeBytes}}{{if .Quota}}{{if .Quota.SizeBytes}}
+Quota (size): {{prettySize .UsageBytes}} of {{prettySize .Quota.SizeBytes}}{{end}}{{if .Quota.Commits}}
+Quota (commits): {{.Commits}} of {{.Quota.Commits}}{{end}}{{end}}{{if .AuthInfo}}
 Access level: {{ .AuthInfo.AccessLevel.String }}{{end}}
 `)
 	if err != nil {
@@ -79,6 +87,24 @@ Access level: {{ .AuthInfo.AccessLevel.String }}{{end}}
 	return nil
 }
 
+// PrintStorageUsage pretty-prints the storage usage of a repo or commit.
+func PrintStorageUsage(usage *pfs.StorageUsage) error {
+	template, err := template.New("StorageUsage").Funcs(funcMap).Parse(
+		`Logical size: {{prettySize .LogicalBytes}}
+Physical size: {{prettySize .PhysicalBytes}}{{if .PhysicalBytes}} (dedup ratio {{dedupRatio .}}){{end}}
+Shared with other repos: {{prettySize .SharedBytes}}{{range .SharedWith}}
+  {{.Repo.Name}}: {{prettySize .Bytes}}{{end}}
+`)
+	if err != nil {
+		return err
+	}
+	return template.Execute(os.Stdout, usage)
+}
+
+func dedupRatio(usage *pfs.StorageUsage) string {
+	return fmt.Sprintf("%.2fx", float64(usage.LogicalBytes)/float64(usage.PhysicalBytes))
+}
+
 // PrintBranch pretty-prints a Branch.
 func PrintBranch(w io.Writer, branchInfo *pfs.BranchInfo) {
 	fmt.Fprintf(w, "%s\t", branchInfo.Branch.Name)
@@ -95,7 +121,9 @@ func PrintDetailedBranchInfo(branchInfo *pfs.BranchInfo) error {
 	template, err := template.New("BranchInfo").Funcs(funcMap).Parse(
 		`Name: {{.Branch.Repo.Name}}@{{.Branch.Name}}{{if .Head}}
 Head Commit: {{ .Head.Repo.Name}}@{{.Head.ID}} {{end}}{{if .Provenance}}
-Provenance: {{range .Provenance}} {{.Repo.Name}}@{{.Name}} {{end}} {{end}}
+Provenance: {{range .Provenance}} {{.Repo.Name}}@{{.Name}} {{end}} {{end}}{{if .Retention}}
+Retention: {{retention .Retention}}{{end}}{{if .Trigger}}
+Trigger: {{trigger .Trigger}}{{end}}
 `)
 	if err != nil {
 		return err
@@ -162,7 +190,8 @@ func PrintDetailedCommitInfo(commitInfo *PrintableCommitInfo) error {
 	template, err := template.New("CommitInfo").Funcs(funcMap).Parse(
 		`Commit: {{.Commit.Repo.Name}}@{{.Commit.ID}}{{if .Branch}}
 Original Branch: {{.Branch.Name}}{{end}}{{if .Description}}
-Description: {{.Description}}{{end}}{{if .ParentCommit}}
+Description: {{.Description}}{{end}}{{if .Metadata}}
+Metadata: {{range $key, $value := .Metadata}} {{$key}}={{$value}} {{end}}{{end}}{{if .ParentCommit}}
 Parent: {{.ParentCommit.ID}}{{end}}{{if .FullTimestamps}}
 Started: {{.Started}}{{else}}
 Started: {{prettyAgo .Started}}{{end}}{{if .Finished}}{{if .FullTimestamps}}
@@ -217,6 +246,17 @@ func PrintDiffFileInfo(w io.Writer, added bool, fileInfo *pfs.FileInfo, fullTime
 	PrintFileInfo(w, fileInfo, fullTimestamps, false)
 }
 
+// PrintRenamedFileInfo pretty-prints a file that diff file found was renamed.
+func PrintRenamedFileInfo(w io.Writer, oldFileInfo, newFileInfo *pfs.FileInfo, fullTimestamps bool) {
+	fmt.Fprint(w, color.YellowString("R\t"))
+	fileInfo := *newFileInfo
+	fileInfo.File = &pfs.File{
+		Commit: newFileInfo.File.Commit,
+		Path:   fmt.Sprintf("%s -> %s", oldFileInfo.File.Path, newFileInfo.File.Path),
+	}
+	PrintFileInfo(w, &fileInfo, fullTimestamps, false)
+}
+
 // PrintDetailedFileInfo pretty-prints detailed file info.
 func PrintDetailedFileInfo(fileInfo *pfs.FileInfo) error {
 	template, err := template.New("FileInfo").Funcs(funcMap).Parse(
@@ -231,6 +271,33 @@ Children: {{range .Children}} {{.}} {{end}}
 	return template.Execute(os.Stdout, fileInfo)
 }
 
+// PrintModelInfo pretty-prints the versions of a model, newest first, along
+// with the stages that they're labelled with.
+func PrintModelInfo(w io.Writer, modelInfo *pfs.ModelInfo, fullTimestamps bool) {
+	stages := make(map[uint64][]string)
+	for stage, version := range modelInfo.Stages {
+		stages[version] = append(stages[version], stage)
+	}
+	for i := len(modelInfo.Versions) - 1; i >= 0; i-- {
+		version := modelInfo.Versions[i]
+		fmt.Fprintf(w, "%d\t", version.Version)
+		fmt.Fprintf(w, "%s\t", version.Commit.ID)
+		if s := stages[version.Version]; len(s) > 0 {
+			sort.Strings(s)
+			fmt.Fprintf(w, "%s\t", strings.Join(s, ", "))
+		} else {
+			fmt.Fprintf(w, "-\t")
+		}
+		if fullTimestamps {
+			fmt.Fprintf(w, "%s\t", version.Created.String())
+		} else {
+			fmt.Fprintf(w, "%s\t", pretty.Ago(version.Created))
+		}
+		fmt.Fprintf(w, "%s\t", version.Description)
+		fmt.Fprintln(w)
+	}
+}
+
 func fileType(fileType pfs.FileType) string {
 	if fileType == pfs.FileType_FILE {
 		return "file"
@@ -238,10 +305,42 @@ func fileType(fileType pfs.FileType) string {
 	return "dir"
 }
 
+func retention(policy *pfs.RetentionPolicy) string {
+	var parts []string
+	if policy.History > 0 {
+		parts = append(parts, fmt.Sprintf("last %d commits", policy.History))
+	}
+	if maxAge, err := types.DurationFromProto(policy.MaxAge); err == nil {
+		parts = append(parts, fmt.Sprintf("commits younger than %v", maxAge))
+	}
+	return strings.Join(parts, ", and ")
+}
+
+func trigger(t *pfs.Trigger) string {
+	var parts []string
+	if t.CronSpec != "" {
+		parts = append(parts, fmt.Sprintf("cron %q", t.CronSpec))
+	}
+	if t.Size_ != "" {
+		parts = append(parts, fmt.Sprintf("%s added", t.Size_))
+	}
+	if t.Commits > 0 {
+		parts = append(parts, fmt.Sprintf("%d commits", t.Commits))
+	}
+	sep := ", or "
+	if t.All {
+		sep = ", and "
+	}
+	return fmt.Sprintf("%s on %s", strings.Join(parts, sep), t.Branch)
+}
+
 var funcMap = template.FuncMap{
 	"prettyAgo":  pretty.Ago,
 	"prettySize": pretty.Size,
 	"fileType":   fileType,
+	"retention":  retention,
+	"trigger":    trigger,
+	"dedupRatio": dedupRatio,
 }
 
 // CompactPrintBranch renders 'b' as a compact string, e.g.
@@ -261,3 +360,28 @@ func CompactPrintCommit(c *pfs.Commit) string {
 func CompactPrintFile(f *pfs.File) string {
 	return fmt.Sprintf("%s@%s:%s", f.Commit.Repo.Name, f.Commit.ID, f.Path)
 }
+
+// UploadProgressBar pretty prints the progress of uploading several files as
+// a single line, with a bar of the given width. 'rate' is in bytes per
+// second.
+func UploadProgressBar(done, total uint64, filesDone, files int, rate float64, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = int(uint64(width) * done / total)
+	}
+	if filled > width {
+		filled = width
+	}
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", width-filled)
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s / %s  %d / %d files", bar,
+		units.BytesSize(float64(done)), units.BytesSize(float64(total)), filesDone, files)
+	if rate > 0 {
+		fmt.Fprintf(&b, "  %s/s", units.BytesSize(rate))
+		if done < total {
+			eta := time.Duration(float64(total-done) / rate * float64(time.Second))
+			fmt.Fprintf(&b, "  ETA %s", units.HumanDuration(eta))
+		}
+	}
+	return b.String()
+}
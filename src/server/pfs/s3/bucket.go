@@ -38,32 +38,30 @@ func newCommonPrefixes(dir string) s2.CommonPrefixes {
 	}
 }
 
-func (c controller) GetLocation(r *http.Request, bucket string) (string, error) {
+func (c controller) GetLocation(r *http.Request, bucketName string) (string, error) {
 	vars := mux.Vars(r)
 	pc, err := c.pachClient(vars["authAccessKey"])
 	if err != nil {
 		return "", err
 	}
-	repo, branch, err := bucketArgs(r, bucket)
+	bucket, err := c.driver.bucket(pc, r, bucketName)
 	if err != nil {
 		return "", err
 	}
-
-	_, err = pc.InspectBranch(repo, branch)
-	if err != nil {
-		return "", maybeNotFoundError(r, err)
+	if _, err = c.driver.bucketCapabilities(pc, r, bucket); err != nil {
+		return "", err
 	}
 
 	return globalLocation, nil
 }
 
-func (c controller) ListObjects(r *http.Request, bucket, prefix, marker, delimiter string, maxKeys int) (*s2.ListObjectsResult, error) {
+func (c controller) ListObjects(r *http.Request, bucketName, prefix, marker, delimiter string, maxKeys int) (*s2.ListObjectsResult, error) {
 	vars := mux.Vars(r)
 	pc, err := c.pachClient(vars["authAccessKey"])
 	if err != nil {
 		return nil, err
 	}
-	repo, branch, err := bucketArgs(r, bucket)
+	bucket, err := c.driver.bucket(pc, r, bucketName)
 	if err != nil {
 		return nil, err
 	}
@@ -77,13 +75,14 @@ func (c controller) ListObjects(r *http.Request, bucket, prefix, marker, delimit
 		CommonPrefixes: []s2.CommonPrefixes{},
 	}
 
-	// ensure the branch exists and has a head
-	branchInfo, err := pc.InspectBranch(repo, branch)
+	// ensure the bucket exists and can be read
+	bucketCaps, err := c.driver.bucketCapabilities(pc, r, bucket)
 	if err != nil {
-		return nil, maybeNotFoundError(r, err)
+		return nil, err
 	}
-	if branchInfo.Head == nil {
-		// if there's no head commit, just print an empty list of files
+	if !bucketCaps.readable {
+		// if there's nothing to read (e.g. no head commit), just print an
+		// empty list of files
 		return &result, nil
 	}
 
@@ -95,7 +94,7 @@ func (c controller) ListObjects(r *http.Request, bucket, prefix, marker, delimit
 		pattern = fmt.Sprintf("%s*", glob.QuoteMeta(prefix))
 	}
 
-	err = pc.GlobFileF(repo, branch, pattern, func(fileInfo *pfsClient.FileInfo) error {
+	err = pc.GlobFileF(bucket.Repo, bucket.Commit, pattern, func(fileInfo *pfsClient.FileInfo) error {
 		if fileInfo.FileType == pfsClient.FileType_DIR {
 			if fileInfo.File.Path == "/" {
 				// skip the root directory
@@ -142,16 +141,20 @@ func (c controller) ListObjects(r *http.Request, bucket, prefix, marker, delimit
 	return &result, err
 }
 
-func (c controller) CreateBucket(r *http.Request, bucket string) error {
+func (c controller) CreateBucket(r *http.Request, bucketName string) error {
+	if !c.driver.canModifyBuckets() {
+		return s2.NotImplementedError(r)
+	}
 	vars := mux.Vars(r)
 	pc, err := c.pachClient(vars["authAccessKey"])
 	if err != nil {
 		return err
 	}
-	repo, branch, err := bucketArgs(r, bucket)
+	bucket, err := c.driver.bucket(pc, r, bucketName)
 	if err != nil {
 		return err
 	}
+	repo, branch := bucket.Repo, bucket.Commit
 
 	err = pc.CreateRepo(repo)
 	if err != nil {
@@ -185,16 +188,20 @@ func (c controller) CreateBucket(r *http.Request, bucket string) error {
 	return nil
 }
 
-func (c controller) DeleteBucket(r *http.Request, bucket string) error {
+func (c controller) DeleteBucket(r *http.Request, bucketName string) error {
+	if !c.driver.canModifyBuckets() {
+		return s2.NotImplementedError(r)
+	}
 	vars := mux.Vars(r)
 	pc, err := c.pachClient(vars["authAccessKey"])
 	if err != nil {
 		return err
 	}
-	repo, branch, err := bucketArgs(r, bucket)
+	bucket, err := c.driver.bucket(pc, r, bucketName)
 	if err != nil {
 		return err
 	}
+	repo, branch := bucket.Repo, bucket.Commit
 
 	// `DeleteBranch` does not return an error if a non-existing branch is
 	// deleting. So first, we verify that the branch exists so we can
@@ -1,14 +1,17 @@
 package s3
 
 import (
-	"fmt"
-
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/sirupsen/logrus"
 )
 
 type controller struct {
-	pachdPort uint16
+	// clientFactory returns a pach client that makes requests with
+	// 'authToken'
+	clientFactory func(authToken string) (*client.APIClient, error)
+
+	// driver determines the buckets that are served
+	driver driver
 
 	logger *logrus.Entry
 
@@ -21,12 +24,5 @@ type controller struct {
 }
 
 func (c *controller) pachClient(authToken string) (*client.APIClient, error) {
-	pc, err := client.NewFromAddress(fmt.Sprintf("localhost:%d", c.pachdPort))
-	if err != nil {
-		return nil, err
-	}
-	if authToken != "" {
-		pc.SetAuthToken(authToken)
-	}
-	return pc, nil
+	return c.clientFactory(authToken)
 }
@@ -0,0 +1,175 @@
+package s3
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/s2"
+)
+
+// Bucket is a PFS branch or commit that the S3 gateway serves as an S3
+// bucket
+type Bucket struct {
+	// Name is the name of the bucket in S3 requests
+	Name string
+	// Repo is the PFS repo that the bucket's objects are in
+	Repo string
+	// Commit is the branch or commit ID that the bucket's objects are in
+	Commit string
+}
+
+// bucketCapabilities describes the operations that a bucket supports
+type bucketCapabilities struct {
+	// readable is false if the bucket's objects can't be read (e.g. if its
+	// branch has no head commit yet)
+	readable bool
+	// writable is false if objects can't be put in or deleted from the
+	// bucket
+	writable bool
+	// historicVersions is true if objects can be read at older versions
+	// (i.e. at ancestors of the bucket's commit)
+	historicVersions bool
+}
+
+// driver determines which buckets the S3 gateway serves, and what can be done
+// with them
+type driver interface {
+	// listBuckets appends the buckets that the gateway serves to 'buckets'
+	listBuckets(pc *client.APIClient, r *http.Request, buckets *[]s2.Bucket) error
+	// bucket returns the bucket named 'name'. The bucket may not exist; use
+	// bucketCapabilities to check.
+	bucket(pc *client.APIClient, r *http.Request, name string) (*Bucket, error)
+	// bucketCapabilities returns the capabilities of 'bucket', or an S3
+	// error if it doesn't exist
+	bucketCapabilities(pc *client.APIClient, r *http.Request, bucket *Bucket) (bucketCapabilities, error)
+	// canModifyBuckets returns true if buckets can be created and deleted
+	canModifyBuckets() bool
+}
+
+// masterDriver is the driver of pachd's S3 gateway, which serves every branch
+// of every repo as a bucket named "<branch>.<repo>"
+type masterDriver struct{}
+
+func (d *masterDriver) listBuckets(pc *client.APIClient, r *http.Request, buckets *[]s2.Bucket) error {
+	repos, err := pc.ListRepo()
+	if err != nil {
+		return err
+	}
+
+	for _, repo := range repos {
+		t, err := types.TimestampFromProto(repo.Created)
+		if err != nil {
+			return err
+		}
+
+		for _, branch := range repo.Branches {
+			*buckets = append(*buckets, s2.Bucket{
+				Name:         fmt.Sprintf("%s.%s", branch.Name, branch.Repo.Name),
+				CreationDate: t,
+			})
+		}
+	}
+
+	return nil
+}
+
+func (d *masterDriver) bucket(pc *client.APIClient, r *http.Request, name string) (*Bucket, error) {
+	repo, branch, err := bucketArgs(r, name)
+	if err != nil {
+		return nil, err
+	}
+	return &Bucket{
+		Name:   name,
+		Repo:   repo,
+		Commit: branch,
+	}, nil
+}
+
+func (d *masterDriver) bucketCapabilities(pc *client.APIClient, r *http.Request, bucket *Bucket) (bucketCapabilities, error) {
+	branchInfo, err := pc.InspectBranch(bucket.Repo, bucket.Commit)
+	if err != nil {
+		return bucketCapabilities{}, maybeNotFoundError(r, err)
+	}
+	return bucketCapabilities{
+		readable:         branchInfo.Head != nil,
+		writable:         true,
+		historicVersions: true,
+	}, nil
+}
+
+func (d *masterDriver) canModifyBuckets() bool {
+	return true
+}
+
+// jobDriver is the driver of a job's S3 gateway, which serves the job's input
+// commits as read-only buckets and its output commit as a write-only bucket.
+// Output commits can't be read until they're finished, so the output bucket
+// can't be read from.
+type jobDriver struct {
+	inputBuckets []*Bucket
+	outputBucket *Bucket
+	buckets      map[string]*Bucket
+}
+
+func newJobDriver(inputBuckets []*Bucket, outputBucket *Bucket) *jobDriver {
+	d := &jobDriver{
+		inputBuckets: inputBuckets,
+		outputBucket: outputBucket,
+		buckets:      make(map[string]*Bucket),
+	}
+	for _, bucket := range inputBuckets {
+		d.buckets[bucket.Name] = bucket
+	}
+	if outputBucket != nil {
+		d.buckets[outputBucket.Name] = outputBucket
+	}
+	return d
+}
+
+func (d *jobDriver) listBuckets(pc *client.APIClient, r *http.Request, buckets *[]s2.Bucket) error {
+	add := func(bucket *Bucket) error {
+		commitInfo, err := pc.InspectCommit(bucket.Repo, bucket.Commit)
+		if err != nil {
+			return err
+		}
+		t, err := types.TimestampFromProto(commitInfo.Started)
+		if err != nil {
+			return err
+		}
+		*buckets = append(*buckets, s2.Bucket{
+			Name:         bucket.Name,
+			CreationDate: t,
+		})
+		return nil
+	}
+	for _, bucket := range d.inputBuckets {
+		if err := add(bucket); err != nil {
+			return err
+		}
+	}
+	if d.outputBucket != nil {
+		return add(d.outputBucket)
+	}
+	return nil
+}
+
+func (d *jobDriver) bucket(pc *client.APIClient, r *http.Request, name string) (*Bucket, error) {
+	bucket, ok := d.buckets[name]
+	if !ok {
+		return nil, s2.NoSuchBucketError(r)
+	}
+	return bucket, nil
+}
+
+func (d *jobDriver) bucketCapabilities(pc *client.APIClient, r *http.Request, bucket *Bucket) (bucketCapabilities, error) {
+	if bucket == d.outputBucket {
+		return bucketCapabilities{writable: true}, nil
+	}
+	return bucketCapabilities{readable: true}, nil
+}
+
+func (d *jobDriver) canModifyBuckets() bool {
+	return false
+}
@@ -0,0 +1,43 @@
+package s3
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/s2"
+)
+
+func TestJobDriverBuckets(t *testing.T) {
+	input := &Bucket{Name: "images", Repo: "images", Commit: "abc"}
+	output := &Bucket{Name: "out", Repo: "edges", Commit: "def"}
+	d := newJobDriver([]*Bucket{input}, output)
+	r := httptest.NewRequest("GET", "/images", nil)
+
+	bucket, err := d.bucket(nil, r, "images")
+	require.NoError(t, err)
+	require.Equal(t, input, bucket)
+	caps, err := d.bucketCapabilities(nil, r, bucket)
+	require.NoError(t, err)
+	require.Equal(t, bucketCapabilities{readable: true}, caps)
+
+	bucket, err = d.bucket(nil, r, "out")
+	require.NoError(t, err)
+	require.Equal(t, output, bucket)
+	caps, err = d.bucketCapabilities(nil, r, bucket)
+	require.NoError(t, err)
+	require.Equal(t, bucketCapabilities{writable: true}, caps)
+
+	// Buckets are only looked up by name, not by "<branch>.<repo>"
+	_, err = d.bucket(nil, r, "abc.images")
+	require.YesError(t, err)
+	require.Equal(t, "NoSuchBucket", err.(*s2.Error).Code)
+	require.False(t, d.canModifyBuckets())
+}
+
+func TestJobDriverNoOutput(t *testing.T) {
+	d := newJobDriver([]*Bucket{{Name: "in", Repo: "in", Commit: "abc"}}, nil)
+	r := httptest.NewRequest("GET", "/out", nil)
+	_, err := d.bucket(nil, r, "out")
+	require.YesError(t, err)
+}
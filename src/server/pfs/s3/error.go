@@ -3,6 +3,7 @@ package s3
 import (
 	"net/http"
 
+	"github.com/pachyderm/pachyderm/src/client/auth"
 	"github.com/pachyderm/pachyderm/src/server/pfs"
 	"github.com/pachyderm/s2"
 )
@@ -24,6 +25,8 @@ func maybeNotFoundError(r *http.Request, err error) *s2.Error {
 		return s2.NoSuchBucketError(r)
 	} else if pfs.IsFileNotFoundErr(err) {
 		return s2.NoSuchKeyError(r)
+	} else if auth.IsErrNotAuthorized(err) {
+		return s2.AccessDeniedError(r)
 	}
 	return s2.InternalError(r, err)
 }
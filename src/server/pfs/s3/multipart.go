@@ -87,20 +87,24 @@ func (c *controller) ensureRepo(pc *client.APIClient) error {
 	return nil
 }
 
-func (c *controller) ListMultipart(r *http.Request, bucket, keyMarker, uploadIDMarker string, maxUploads int) (*s2.ListMultipartResult, error) {
+func (c *controller) ListMultipart(r *http.Request, bucketName, keyMarker, uploadIDMarker string, maxUploads int) (*s2.ListMultipartResult, error) {
 	vars := mux.Vars(r)
 	pc, err := c.pachClient(vars["authAccessKey"])
 	if err != nil {
 		return nil, err
 	}
-	repo, branch, err := bucketArgs(r, bucket)
+	bucket, err := c.driver.bucket(pc, r, bucketName)
 	if err != nil {
 		return nil, err
 	}
-	_, err = pc.InspectBranch(repo, branch)
+	bucketCaps, err := c.driver.bucketCapabilities(pc, r, bucket)
 	if err != nil {
-		return nil, maybeNotFoundError(r, err)
+		return nil, err
+	}
+	if !bucketCaps.writable {
+		return nil, s2.NotImplementedError(r)
 	}
+	repo, branch := bucket.Repo, bucket.Commit
 	if err = c.ensureRepo(pc); err != nil {
 		return nil, err
 	}
@@ -146,20 +150,24 @@ func (c *controller) ListMultipart(r *http.Request, bucket, keyMarker, uploadIDM
 	return &result, err
 }
 
-func (c *controller) InitMultipart(r *http.Request, bucket, key string) (string, error) {
+func (c *controller) InitMultipart(r *http.Request, bucketName, key string) (string, error) {
 	vars := mux.Vars(r)
 	pc, err := c.pachClient(vars["authAccessKey"])
 	if err != nil {
 		return "", err
 	}
-	repo, branch, err := bucketArgs(r, bucket)
+	bucket, err := c.driver.bucket(pc, r, bucketName)
 	if err != nil {
 		return "", err
 	}
-	_, err = pc.InspectBranch(repo, branch)
+	bucketCaps, err := c.driver.bucketCapabilities(pc, r, bucket)
 	if err != nil {
-		return "", maybeNotFoundError(r, err)
+		return "", err
+	}
+	if !bucketCaps.writable {
+		return "", s2.NotImplementedError(r)
 	}
+	repo, branch := bucket.Repo, bucket.Commit
 	if err = c.ensureRepo(pc); err != nil {
 		return "", err
 	}
@@ -174,20 +182,24 @@ func (c *controller) InitMultipart(r *http.Request, bucket, key string) (string,
 	return uploadID, nil
 }
 
-func (c *controller) AbortMultipart(r *http.Request, bucket, key, uploadID string) error {
+func (c *controller) AbortMultipart(r *http.Request, bucketName, key, uploadID string) error {
 	vars := mux.Vars(r)
 	pc, err := c.pachClient(vars["authAccessKey"])
 	if err != nil {
 		return err
 	}
-	repo, branch, err := bucketArgs(r, bucket)
+	bucket, err := c.driver.bucket(pc, r, bucketName)
 	if err != nil {
 		return err
 	}
-	_, err = pc.InspectBranch(repo, branch)
+	bucketCaps, err := c.driver.bucketCapabilities(pc, r, bucket)
 	if err != nil {
-		return maybeNotFoundError(r, err)
+		return err
+	}
+	if !bucketCaps.writable {
+		return s2.NotImplementedError(r)
 	}
+	repo, branch := bucket.Repo, bucket.Commit
 	if err = c.ensureRepo(pc); err != nil {
 		return err
 	}
@@ -205,20 +217,24 @@ func (c *controller) AbortMultipart(r *http.Request, bucket, key, uploadID strin
 	return nil
 }
 
-func (c *controller) CompleteMultipart(r *http.Request, bucket, key, uploadID string, parts []s2.Part) (*s2.CompleteMultipartResult, error) {
+func (c *controller) CompleteMultipart(r *http.Request, bucketName, key, uploadID string, parts []s2.Part) (*s2.CompleteMultipartResult, error) {
 	vars := mux.Vars(r)
 	pc, err := c.pachClient(vars["authAccessKey"])
 	if err != nil {
 		return nil, err
 	}
-	repo, branch, err := bucketArgs(r, bucket)
+	bucket, err := c.driver.bucket(pc, r, bucketName)
 	if err != nil {
 		return nil, err
 	}
-	_, err = pc.InspectBranch(repo, branch)
+	bucketCaps, err := c.driver.bucketCapabilities(pc, r, bucket)
 	if err != nil {
-		return nil, maybeNotFoundError(r, err)
+		return nil, err
 	}
+	if !bucketCaps.writable {
+		return nil, s2.NotImplementedError(r)
+	}
+	repo, branch := bucket.Repo, bucket.Commit
 	if err = c.ensureRepo(pc); err != nil {
 		return nil, err
 	}
@@ -298,20 +314,24 @@ func (c *controller) CompleteMultipart(r *http.Request, bucket, key, uploadID st
 	return &result, nil
 }
 
-func (c *controller) ListMultipartChunks(r *http.Request, bucket, key, uploadID string, partNumberMarker, maxParts int) (*s2.ListMultipartChunksResult, error) {
+func (c *controller) ListMultipartChunks(r *http.Request, bucketName, key, uploadID string, partNumberMarker, maxParts int) (*s2.ListMultipartChunksResult, error) {
 	vars := mux.Vars(r)
 	pc, err := c.pachClient(vars["authAccessKey"])
 	if err != nil {
 		return nil, err
 	}
-	repo, branch, err := bucketArgs(r, bucket)
+	bucket, err := c.driver.bucket(pc, r, bucketName)
 	if err != nil {
 		return nil, err
 	}
-	_, err = pc.InspectBranch(repo, branch)
+	bucketCaps, err := c.driver.bucketCapabilities(pc, r, bucket)
 	if err != nil {
-		return nil, maybeNotFoundError(r, err)
+		return nil, err
+	}
+	if !bucketCaps.writable {
+		return nil, s2.NotImplementedError(r)
 	}
+	repo, branch := bucket.Repo, bucket.Commit
 	if err = c.ensureRepo(pc); err != nil {
 		return nil, err
 	}
@@ -352,21 +372,24 @@ func (c *controller) ListMultipartChunks(r *http.Request, bucket, key, uploadID
 	return &result, err
 }
 
-func (c *controller) UploadMultipartChunk(r *http.Request, bucket, key, uploadID string, partNumber int, reader io.Reader) (string, error) {
+func (c *controller) UploadMultipartChunk(r *http.Request, bucketName, key, uploadID string, partNumber int, reader io.Reader) (string, error) {
 	vars := mux.Vars(r)
 	pc, err := c.pachClient(vars["authAccessKey"])
 	if err != nil {
 		return "", err
 	}
-	repo, branch, err := bucketArgs(r, bucket)
+	bucket, err := c.driver.bucket(pc, r, bucketName)
 	if err != nil {
 		return "", err
 	}
-	_, err = pc.InspectBranch(repo, branch)
+	bucketCaps, err := c.driver.bucketCapabilities(pc, r, bucket)
 	if err != nil {
-		err = maybeNotFoundError(r, err)
 		return "", err
 	}
+	if !bucketCaps.writable {
+		return "", s2.NotImplementedError(r)
+	}
+	repo, branch := bucket.Repo, bucket.Commit
 	if err = c.ensureRepo(pc); err != nil {
 		return "", err
 	}
@@ -393,20 +416,24 @@ func (c *controller) UploadMultipartChunk(r *http.Request, bucket, key, uploadID
 	return fmt.Sprintf("%x", fileInfo.Hash), nil
 }
 
-func (c *controller) DeleteMultipartChunk(r *http.Request, bucket, key, uploadID string, partNumber int) error {
+func (c *controller) DeleteMultipartChunk(r *http.Request, bucketName, key, uploadID string, partNumber int) error {
 	vars := mux.Vars(r)
 	pc, err := c.pachClient(vars["authAccessKey"])
 	if err != nil {
 		return err
 	}
-	repo, branch, err := bucketArgs(r, bucket)
+	bucket, err := c.driver.bucket(pc, r, bucketName)
 	if err != nil {
 		return err
 	}
-	_, err = pc.InspectBranch(repo, branch)
+	bucketCaps, err := c.driver.bucketCapabilities(pc, r, bucket)
 	if err != nil {
-		return maybeNotFoundError(r, err)
+		return err
+	}
+	if !bucketCaps.writable {
+		return s2.NotImplementedError(r)
 	}
+	repo, branch := bucket.Repo, bucket.Commit
 	if err = c.ensureRepo(pc); err != nil {
 		return err
 	}
@@ -14,22 +14,21 @@ import (
 	"github.com/pachyderm/s2"
 )
 
-func (c *controller) GetObject(r *http.Request, bucket, file, version string) (*s2.GetObjectResult, error) {
+func (c *controller) GetObject(r *http.Request, bucketName, file, version string) (*s2.GetObjectResult, error) {
 	vars := mux.Vars(r)
 	pc, err := c.pachClient(vars["authAccessKey"])
 	if err != nil {
 		return nil, err
 	}
-	repo, branch, err := bucketArgs(r, bucket)
+	bucket, err := c.driver.bucket(pc, r, bucketName)
 	if err != nil {
 		return nil, err
 	}
-
-	branchInfo, err := pc.InspectBranch(repo, branch)
+	bucketCaps, err := c.driver.bucketCapabilities(pc, r, bucket)
 	if err != nil {
-		return nil, maybeNotFoundError(r, err)
+		return nil, err
 	}
-	if branchInfo.Head == nil {
+	if !bucketCaps.readable {
 		return nil, s2.NoSuchKeyError(r)
 	}
 	if strings.HasSuffix(file, "/") {
@@ -37,19 +36,22 @@ func (c *controller) GetObject(r *http.Request, bucket, file, version string) (*
 	}
 
 	var commitInfo *pfsClient.CommitInfo
-	commitID := branch
+	commitID := bucket.Commit
 	if version != "" {
-		commitInfo, err = pc.InspectCommit(repo, version)
+		if !bucketCaps.historicVersions {
+			return nil, s2.NotImplementedError(r)
+		}
+		commitInfo, err = pc.InspectCommit(bucket.Repo, version)
 		if err != nil {
 			return nil, maybeNotFoundError(r, err)
 		}
-		if commitInfo.Branch.Name != branch {
+		if commitInfo.Branch.Name != bucket.Commit {
 			return nil, s2.NoSuchVersionError(r)
 		}
 		commitID = commitInfo.Commit.ID
 	}
 
-	fileInfo, err := pc.InspectFile(branchInfo.Branch.Repo.Name, commitID, file)
+	fileInfo, err := pc.InspectFile(bucket.Repo, commitID, file)
 	if err != nil {
 		return nil, maybeNotFoundError(r, err)
 	}
@@ -59,7 +61,7 @@ func (c *controller) GetObject(r *http.Request, bucket, file, version string) (*
 		return nil, err
 	}
 
-	content, err := pc.GetFileReadSeeker(branchInfo.Branch.Repo.Name, commitID, file)
+	content, err := pc.GetFileReadSeeker(bucket.Repo, commitID, file)
 	if err != nil {
 		return nil, err
 	}
@@ -75,26 +77,28 @@ func (c *controller) GetObject(r *http.Request, bucket, file, version string) (*
 	return &result, nil
 }
 
-func (c *controller) PutObject(r *http.Request, bucket, file string, reader io.Reader) (*s2.PutObjectResult, error) {
+func (c *controller) PutObject(r *http.Request, bucketName, file string, reader io.Reader) (*s2.PutObjectResult, error) {
 	vars := mux.Vars(r)
 	pc, err := c.pachClient(vars["authAccessKey"])
 	if err != nil {
 		return nil, err
 	}
-	repo, branch, err := bucketArgs(r, bucket)
+	bucket, err := c.driver.bucket(pc, r, bucketName)
 	if err != nil {
 		return nil, err
 	}
-
-	branchInfo, err := pc.InspectBranch(repo, branch)
+	bucketCaps, err := c.driver.bucketCapabilities(pc, r, bucket)
 	if err != nil {
-		return nil, maybeNotFoundError(r, err)
+		return nil, err
+	}
+	if !bucketCaps.writable {
+		return nil, s2.NotImplementedError(r)
 	}
 	if strings.HasSuffix(file, "/") {
 		return nil, invalidFilePathError(r)
 	}
 
-	_, err = pc.PutFileOverwrite(branchInfo.Branch.Repo.Name, branchInfo.Branch.Name, file, reader, 0)
+	_, err = pc.PutFileOverwrite(bucket.Repo, bucket.Commit, file, reader, 0)
 	if err != nil {
 		if errutil.IsWriteToOutputBranchError(err) {
 			return nil, writeToOutputBranchError(r)
@@ -102,7 +106,7 @@ func (c *controller) PutObject(r *http.Request, bucket, file string, reader io.R
 		return nil, err
 	}
 
-	fileInfo, err := pc.InspectFile(branchInfo.Branch.Repo.Name, branchInfo.Branch.Name, file)
+	fileInfo, err := pc.InspectFile(bucket.Repo, bucket.Commit, file)
 	if err != nil && !pfsServer.IsOutputCommitNotFinishedErr(err) {
 		return nil, err
 	}
@@ -116,22 +120,24 @@ func (c *controller) PutObject(r *http.Request, bucket, file string, reader io.R
 	return &result, nil
 }
 
-func (c *controller) DeleteObject(r *http.Request, bucket, file, version string) (*s2.DeleteObjectResult, error) {
+func (c *controller) DeleteObject(r *http.Request, bucketName, file, version string) (*s2.DeleteObjectResult, error) {
 	vars := mux.Vars(r)
 	pc, err := c.pachClient(vars["authAccessKey"])
 	if err != nil {
 		return nil, err
 	}
-	repo, branch, err := bucketArgs(r, bucket)
+	bucket, err := c.driver.bucket(pc, r, bucketName)
 	if err != nil {
 		return nil, err
 	}
-
-	branchInfo, err := pc.InspectBranch(repo, branch)
+	bucketCaps, err := c.driver.bucketCapabilities(pc, r, bucket)
 	if err != nil {
-		return nil, maybeNotFoundError(r, err)
+		return nil, err
+	}
+	if !bucketCaps.writable {
+		return nil, s2.NotImplementedError(r)
 	}
-	if branchInfo.Head == nil {
+	if !bucketCaps.readable {
 		return nil, s2.NoSuchKeyError(r)
 	}
 	if strings.HasSuffix(file, "/") {
@@ -141,7 +147,7 @@ func (c *controller) DeleteObject(r *http.Request, bucket, file, version string)
 		return nil, s2.NotImplementedError(r)
 	}
 
-	if err = pc.DeleteFile(branchInfo.Branch.Repo.Name, branchInfo.Branch.Name, file); err != nil {
+	if err = pc.DeleteFile(bucket.Repo, bucket.Commit, file); err != nil {
 		if errutil.IsWriteToOutputBranchError(err) {
 			return nil, writeToOutputBranchError(r)
 		}
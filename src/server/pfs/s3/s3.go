@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
 	"github.com/pachyderm/s2"
 	"github.com/sirupsen/logrus"
@@ -36,27 +37,61 @@ const (
 // this API will ignore them - otherwise, you'll get an opaque config error:
 // https://github.com/s3tools/s3cmd/issues/845#issuecomment-464885959
 func Server(port, pachdPort uint16) (*http.Server, error) {
-	logger := logrus.WithFields(logrus.Fields{
-		"source": "s3gateway",
-	})
+	c := &controller{
+		clientFactory: func(authToken string) (*client.APIClient, error) {
+			pc, err := client.NewFromAddress(fmt.Sprintf("localhost:%d", pachdPort))
+			if err != nil {
+				return nil, err
+			}
+			if authToken != "" {
+				pc.SetAuthToken(authToken)
+			}
+			return pc, nil
+		},
+		driver: &masterDriver{},
+	}
+	return newServer(fmt.Sprintf(":%d", port), c, true), nil
+}
 
+// JobServer runs an HTTP server with an S3-like API for a single job. It
+// serves each of 'inputBuckets' read-only, and 'outputBucket' (if it isn't
+// nil) write-only. Buckets can't be created or deleted.
+//
+// Every request is made with 'pachClient', so requests aren't authenticated;
+// callers should listen only on addresses that the job's own code can reach
+// (e.g. localhost in the worker's pod).
+func JobServer(addr string, pachClient *client.APIClient, inputBuckets []*Bucket, outputBucket *Bucket) *http.Server {
 	c := &controller{
-		pachdPort:       pachdPort,
-		logger:          logger,
-		repo:            multipartRepo,
-		maxAllowedParts: maxAllowedParts,
+		clientFactory: func(string) (*client.APIClient, error) {
+			return pachClient, nil
+		},
+		driver: newJobDriver(inputBuckets, outputBucket),
 	}
+	return newServer(addr, c, false)
+}
+
+// newServer returns an HTTP server on 'addr' for 'c'. If 'auth' is false,
+// requests aren't authenticated.
+func newServer(addr string, c *controller, auth bool) *http.Server {
+	logger := logrus.WithFields(logrus.Fields{
+		"source": "s3gateway",
+	})
+	c.logger = logger
+	c.repo = multipartRepo
+	c.maxAllowedParts = maxAllowedParts
 
 	s3Server := s2.NewS2(logger, maxRequestBodyLength, readBodyTimeout)
-	s3Server.Auth = c
+	if auth {
+		s3Server.Auth = c
+	}
 	s3Server.Service = c
 	s3Server.Bucket = c
 	s3Server.Object = c
 	s3Server.Multipart = c
 	router := s3Server.Router()
 
-	server := &http.Server{
-		Addr:         fmt.Sprintf(":%d", port),
+	return &http.Server{
+		Addr:         addr,
 		ReadTimeout:  requestTimeout,
 		WriteTimeout: requestTimeout,
 		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -78,6 +113,4 @@ func Server(port, pachdPort uint16) (*http.Server, error) {
 		// NOTE: this is not closed. If the standard logger gets customized, this will need to be fixed
 		ErrorLog: stdlog.New(logger.Writer(), "", 0),
 	}
-
-	return server, nil
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/gogo/protobuf/types"
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errcode"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
 	"github.com/pachyderm/pachyderm/src/client/pkg/tracing"
 	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
@@ -60,7 +61,7 @@ func (a *apiServer) CreateRepoInTransaction(
 	txnCtx *txnenv.TransactionContext,
 	request *pfs.CreateRepoRequest,
 ) error {
-	return a.driver.createRepo(txnCtx, request.Repo, request.Description, request.Update)
+	return a.driver.createRepo(txnCtx, request.Repo, request.Description, request.Quota, request.Update)
 }
 
 // CreateRepo implements the protobuf pfs.CreateRepo RPC
@@ -103,6 +104,13 @@ func (a *apiServer) InspectRepo(ctx context.Context, request *pfs.InspectRepoReq
 	return info, nil
 }
 
+// GetStorageUsage implements the protobuf pfs.GetStorageUsage RPC
+func (a *apiServer) GetStorageUsage(ctx context.Context, request *pfs.GetStorageUsageRequest) (response *pfs.StorageUsage, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	return a.driver.storageUsage(a.env.GetPachClient(ctx), request.Repo, request.Commit)
+}
+
 // ListRepo implements the protobuf pfs.ListRepo RPC
 func (a *apiServer) ListRepo(ctx context.Context, request *pfs.ListRepoRequest) (response *pfs.ListRepoResponse, retErr error) {
 	func() { a.Log(request, nil, nil, 0) }()
@@ -168,9 +176,9 @@ func (a *apiServer) StartCommitInTransaction(
 		id = commit.ID
 	}
 	if a.env.NewStorageLayer {
-		return a.driver.startCommitNewStorageLayer(txnCtx, id, request.Parent, request.Branch, request.Provenance, request.Description)
+		return a.driver.startCommitNewStorageLayer(txnCtx, id, request.Parent, request.Branch, request.Provenance, request.Description, request.Metadata)
 	}
-	return a.driver.startCommit(txnCtx, id, request.Parent, request.Branch, request.Provenance, request.Description)
+	return a.driver.startCommit(txnCtx, id, request.Parent, request.Branch, request.Provenance, request.Description, request.Metadata)
 }
 
 // StartCommit implements the protobuf pfs.StartCommit RPC
@@ -264,6 +272,26 @@ func (a *apiServer) ListCommitStream(request *pfs.ListCommitRequest, respServer
 	})
 }
 
+// WatchCommit implements the protobuf pfs.WatchCommit RPC
+func (a *apiServer) WatchCommit(request *pfs.ListCommitRequest, respServer pfs.API_WatchCommitServer) (retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	sent := 0
+	defer func(start time.Time) {
+		a.Log(request, fmt.Sprintf("stream containing %d commits", sent), retErr, time.Since(start))
+	}(time.Now())
+	if request.From != nil || request.Number != 0 {
+		return fmt.Errorf("WatchCommit doesn't support 'from' or 'number'")
+	}
+	var branch string
+	if request.To != nil {
+		branch = request.To.ID
+	}
+	return a.driver.watchCommit(a.env.GetPachClient(respServer.Context()), request.Repo, branch, func(ci *pfs.CommitInfo) error {
+		sent++
+		return respServer.Send(ci)
+	})
+}
+
 // CreateBranchInTransaction is identical to CreateBranch except that it can run
 // inside an existing etcd STM transaction.  This is not an RPC.
 func (a *apiServer) CreateBranchInTransaction(
@@ -314,6 +342,19 @@ func (a *apiServer) ListBranch(ctx context.Context, request *pfs.ListBranchReque
 	return &pfs.BranchInfos{BranchInfo: branches}, nil
 }
 
+// WatchBranch implements the protobuf pfs.WatchBranch RPC
+func (a *apiServer) WatchBranch(request *pfs.ListBranchRequest, respServer pfs.API_WatchBranchServer) (retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	sent := 0
+	defer func(start time.Time) {
+		a.Log(request, fmt.Sprintf("stream containing %d branches", sent), retErr, time.Since(start))
+	}(time.Now())
+	return a.driver.watchBranch(a.env.GetPachClient(respServer.Context()), request.Repo, func(bi *pfs.BranchInfo) error {
+		sent++
+		return respServer.Send(bi)
+	})
+}
+
 // DeleteBranchInTransaction is identical to DeleteBranch except that it can run
 // inside an existing etcd STM transaction.  This is not an RPC.
 func (a *apiServer) DeleteBranchInTransaction(
@@ -336,6 +377,60 @@ func (a *apiServer) DeleteBranch(ctx context.Context, request *pfs.DeleteBranchR
 	return &types.Empty{}, nil
 }
 
+// SetBranchRetention implements the protobuf pfs.SetBranchRetention RPC
+func (a *apiServer) SetBranchRetention(ctx context.Context, request *pfs.SetBranchRetentionRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	if err := a.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		return a.driver.setBranchRetention(txnCtx, request.Branch, request.Retention)
+	}); err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
+// SetBranchTrigger implements the protobuf pfs.SetBranchTrigger RPC
+func (a *apiServer) SetBranchTrigger(ctx context.Context, request *pfs.SetBranchTriggerRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	if err := a.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		return a.driver.setBranchTrigger(txnCtx, request.Branch, request.Trigger)
+	}); err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
+// CreateModelVersion implements the protobuf pfs.CreateModelVersion RPC
+func (a *apiServer) CreateModelVersion(ctx context.Context, request *pfs.CreateModelVersionRequest) (response *pfs.ModelVersion, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	return a.driver.createModelVersion(a.env.GetPachClient(ctx), request.Commit, request.Description, request.Stage)
+}
+
+// PromoteModel implements the protobuf pfs.PromoteModel RPC
+func (a *apiServer) PromoteModel(ctx context.Context, request *pfs.PromoteModelRequest) (response *pfs.ModelVersion, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	return a.driver.promoteModel(a.env.GetPachClient(ctx), request.Repo, request.Version, request.Stage)
+}
+
+// InspectModel implements the protobuf pfs.InspectModel RPC
+func (a *apiServer) InspectModel(ctx context.Context, request *pfs.InspectModelRequest) (response *pfs.ModelInfo, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	return a.driver.inspectModel(a.env.GetPachClient(ctx), request.Repo)
+}
+
+// ResolveModel implements the protobuf pfs.ResolveModel RPC
+func (a *apiServer) ResolveModel(ctx context.Context, request *pfs.ResolveModelRequest) (response *pfs.ModelVersion, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	return a.driver.resolveModel(a.env.GetPachClient(ctx), request.Repo, request.Stage)
+}
+
 // DeleteCommitInTransaction is identical to DeleteCommit except that it can run
 // inside an existing etcd STM transaction.  This is not an RPC.
 func (a *apiServer) DeleteCommitInTransaction(
@@ -386,13 +481,19 @@ func (a *apiServer) PutFile(putFileServer pfs.API_PutFileServer) (retErr error)
 	func() { a.Log(request, nil, nil, 0) }()
 	defer func(start time.Time) { a.Log(request, nil, retErr, time.Since(start)) }(time.Now())
 	defer drainFileServer(putFileServer)
+	response := &errcode.BulkResponse{}
 	defer func() {
-		if err := putFileServer.SendAndClose(&types.Empty{}); err != nil && retErr == nil {
+		if err := putFileServer.SendAndClose(response); err != nil && retErr == nil {
 			retErr = err
 		}
 	}()
 	pachClient := a.env.GetPachClient(s.Context())
-	return a.driver.putFiles(pachClient, s)
+	results, err := a.driver.putFiles(pachClient, s)
+	if err != nil {
+		return err
+	}
+	response.Results = results.Results
+	return nil
 }
 
 // CopyFile implements the protobuf pfs.CopyFile RPC
@@ -551,6 +652,19 @@ func (a *apiServer) DiffFile(ctx context.Context, request *pfs.DiffFileRequest)
 	}, nil
 }
 
+// DiffFileStream implements the protobuf pfs.DiffFileStream RPC
+func (a *apiServer) DiffFileStream(request *pfs.DiffFileRequest, respServer pfs.API_DiffFileStreamServer) (retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	var sent int
+	defer func(start time.Time) {
+		a.Log(request, fmt.Sprintf("response stream with %d objects", sent), retErr, time.Since(start))
+	}(time.Now())
+	return a.driver.diffFileF(a.env.GetPachClient(respServer.Context()), request.NewFile, request.OldFile, request.Shallow, request.DetectRenames, func(fileDiff *pfs.FileDiff) error {
+		sent++
+		return respServer.Send(fileDiff)
+	})
+}
+
 // DeleteFile implements the protobuf pfs.DeleteFile RPC
 func (a *apiServer) DeleteFile(ctx context.Context, request *pfs.DeleteFileRequest) (response *types.Empty, retErr error) {
 	func() { a.Log(request, nil, nil, 0) }()
@@ -563,6 +677,60 @@ func (a *apiServer) DeleteFile(ctx context.Context, request *pfs.DeleteFileReque
 	return &types.Empty{}, nil
 }
 
+// DeleteFiles implements the protobuf pfs.DeleteFiles RPC
+func (a *apiServer) DeleteFiles(ctx context.Context, request *pfs.DeleteFilesRequest) (response *errcode.BulkResponse, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	return a.driver.deleteFiles(a.env.GetPachClient(ctx), request.Files, request.Mode)
+}
+
+// StartUpload implements the protobuf pfs.StartUpload RPC
+func (a *apiServer) StartUpload(ctx context.Context, request *pfs.StartUploadRequest) (response *pfs.Upload, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	return a.driver.startUpload(a.env.GetPachClient(ctx), request.File, request.Overwrite)
+}
+
+// UploadChunk implements the protobuf pfs.UploadChunk RPC
+func (a *apiServer) UploadChunk(server pfs.API_UploadChunkServer) (retErr error) {
+	var response *pfs.UploadInfo
+	func() { a.Log(nil, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(nil, response, retErr, time.Since(start)) }(time.Now())
+	response, err := a.driver.uploadChunk(a.env.GetPachClient(server.Context()), server)
+	if err != nil {
+		return err
+	}
+	return server.SendAndClose(response)
+}
+
+// InspectUpload implements the protobuf pfs.InspectUpload RPC
+func (a *apiServer) InspectUpload(ctx context.Context, request *pfs.InspectUploadRequest) (response *pfs.UploadInfo, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	return a.driver.inspectUpload(a.env.GetPachClient(ctx), request.Upload)
+}
+
+// FinishUpload implements the protobuf pfs.FinishUpload RPC
+func (a *apiServer) FinishUpload(ctx context.Context, request *pfs.FinishUploadRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	if err := a.driver.finishUpload(a.env.GetPachClient(ctx), request.Uploads); err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
+// DeleteUpload implements the protobuf pfs.DeleteUpload RPC
+func (a *apiServer) DeleteUpload(ctx context.Context, request *pfs.DeleteUploadRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	if err := a.driver.deleteUpload(a.env.GetPachClient(ctx), request.Upload); err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
 // DeleteAll implements the protobuf pfs.DeleteAll RPC
 func (a *apiServer) DeleteAll(ctx context.Context, request *types.Empty) (response *types.Empty, retErr error) {
 	func() { a.Log(request, nil, nil, 0) }()
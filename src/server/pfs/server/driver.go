@@ -30,10 +30,12 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/auth"
 	"github.com/pachyderm/pachyderm/src/client/limit"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errcode"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
 	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ancestry"
 	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/columnar"
 	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
 	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
@@ -49,11 +51,20 @@ import (
 	"github.com/sirupsen/logrus"
 
 	etcd "github.com/coreos/etcd/clientv3"
+	units "github.com/docker/go-units"
 	"github.com/gogo/protobuf/proto"
 	"github.com/gogo/protobuf/types"
 )
 
 const (
+	// maxRepoNameLen is the length of the longest repo name. Repo names are
+	// the default names of pipeline inputs, which are directories in /pfs, and
+	// of the directories in pachctl mount.
+	maxRepoNameLen = 255
+	// reservedRepoPrefix begins the names of the repos that Pachyderm creates
+	// itself, such as the spec repo
+	reservedRepoPrefix = "__"
+
 	splitSuffixBase  = 16
 	splitSuffixWidth = 64
 	splitSuffixFmt   = "%016x"
@@ -99,6 +110,9 @@ type driver struct {
 	commits        collectionFactory
 	branches       collectionFactory
 	openCommits    col.Collection
+	uploads        col.Collection
+	uploadRecords  col.Collection
+	models         col.Collection
 
 	// a cache for hashtrees
 	treeCache *hashtree.Cache
@@ -143,9 +157,12 @@ func newDriver(
 		branches: func(repo string) col.Collection {
 			return pfsdb.Branches(etcdClient, etcdPrefix, repo)
 		},
-		openCommits: pfsdb.OpenCommits(etcdClient, etcdPrefix),
-		treeCache:   treeCache,
-		storageRoot: storageRoot,
+		openCommits:   pfsdb.OpenCommits(etcdClient, etcdPrefix),
+		uploads:       pfsdb.Uploads(etcdClient, etcdPrefix),
+		uploadRecords: pfsdb.UploadRecords(etcdClient, etcdPrefix),
+		models:        pfsdb.Models(etcdClient, etcdPrefix),
+		treeCache:     treeCache,
+		storageRoot:   storageRoot,
 		// Allow up to a third of the requested memory to be used for memory intensive operations
 		memoryLimiter:    semaphore.NewWeighted(memoryRequest / 3),
 		putObjectLimiter: limit.New(env.StorageUploadConcurrencyLimit),
@@ -181,12 +198,42 @@ func newDriver(
 // checkIsAuthorizedInTransaction is identicalto checkIsAuthorized except that
 // it performs reads consistent with the latest state of the STM transaction.
 func (d *driver) checkIsAuthorizedInTransaction(txnCtx *txnenv.TransactionContext, r *pfs.Repo, s auth.Scope) error {
+	return d.authorizeInTransaction(txnCtx, r, s, nil)
+}
+
+// checkIsAuthorizedOnBranchInTransaction is like
+// checkIsAuthorizedInTransaction, but also applies the ACL of 'branch'
+func (d *driver) checkIsAuthorizedOnBranchInTransaction(txnCtx *txnenv.TransactionContext, branch *pfs.Branch, s auth.Scope) error {
+	return d.authorizeInTransaction(txnCtx, branch.Repo, s, func() (string, error) {
+		return branch.Name, nil
+	})
+}
+
+// checkIsAuthorizedOnCommitInTransaction is like
+// checkIsAuthorizedInTransaction, but also applies the ACL of the branch that
+// 'commit' is on
+func (d *driver) checkIsAuthorizedOnCommitInTransaction(txnCtx *txnenv.TransactionContext, commit *pfs.Commit, s auth.Scope) error {
+	return d.authorizeInTransaction(txnCtx, commit.Repo, s, func() (string, error) {
+		return commitBranch(d.commits(commit.Repo.Name).ReadWrite(txnCtx.Stm), commit)
+	})
+}
+
+// authorizeInTransaction is the implementation of
+// checkIsAuthorizedInTransaction and its variants. 'branch', if set, is only
+// called if auth is active, and returns the branch that the current user is
+// accessing.
+func (d *driver) authorizeInTransaction(txnCtx *txnenv.TransactionContext, r *pfs.Repo, s auth.Scope, branch func() (string, error)) error {
 	me, err := txnCtx.Client.WhoAmI(txnCtx.ClientContext, &auth.WhoAmIRequest{})
 	if auth.IsErrNotActivated(err) {
 		return nil
 	}
 
 	req := &auth.AuthorizeRequest{Repo: r.Name, Scope: s}
+	if branch != nil {
+		if req.Branch, err = branch(); err != nil {
+			return err
+		}
+	}
 	resp, err := txnCtx.Auth().AuthorizeInTransaction(txnCtx, req)
 	if err != nil {
 		return fmt.Errorf("error during authorization check for operation on \"%s\": %v",
@@ -201,22 +248,67 @@ func (d *driver) checkIsAuthorizedInTransaction(txnCtx *txnenv.TransactionContex
 // checkIsAuthorized returns an error if the current user (in 'pachClient') has
 // authorization scope 's' for repo 'r'
 func (d *driver) checkIsAuthorized(pachClient *client.APIClient, r *pfs.Repo, s auth.Scope) error {
+	_, err := d.authorize(pachClient, r, s, nil)
+	return err
+}
+
+// checkIsAuthorizedOnCommit is like checkIsAuthorized, but also applies the
+// ACL of the branch that 'commit' is on. It returns a readFilter that
+// restricts the files that the current user may read in 'commit'.
+func (d *driver) checkIsAuthorizedOnCommit(pachClient *client.APIClient, commit *pfs.Commit, s auth.Scope) (*readFilter, error) {
+	return d.authorize(pachClient, commit.Repo, s, func() (string, error) {
+		return commitBranch(d.commits(commit.Repo.Name).ReadOnly(pachClient.Ctx()), commit)
+	})
+}
+
+// authorize is the implementation of checkIsAuthorized and
+// checkIsAuthorizedOnCommit. As in authorizeInTransaction, 'branch' returns
+// the branch that the current user is accessing.
+func (d *driver) authorize(pachClient *client.APIClient, r *pfs.Repo, s auth.Scope, branch func() (string, error)) (*readFilter, error) {
 	ctx := pachClient.Ctx()
 	me, err := pachClient.WhoAmI(ctx, &auth.WhoAmIRequest{})
 	if auth.IsErrNotActivated(err) {
-		return nil
+		return nil, nil
 	}
 
 	req := &auth.AuthorizeRequest{Repo: r.Name, Scope: s}
+	if branch != nil {
+		if req.Branch, err = branch(); err != nil {
+			return nil, err
+		}
+	}
 	resp, err := pachClient.AuthAPIClient.Authorize(ctx, req)
 	if err != nil {
-		return fmt.Errorf("error during authorization check for operation on \"%s\": %v",
+		return nil, fmt.Errorf("error during authorization check for operation on \"%s\": %v",
 			r.Name, grpcutil.ScrubGRPC(err))
 	}
 	if !resp.Authorized {
-		return &auth.ErrNotAuthorized{Subject: me.Username, Repo: r.Name, Required: s}
+		return nil, &auth.ErrNotAuthorized{Subject: me.Username, Repo: r.Name, Required: s}
 	}
-	return nil
+	return newReadFilter(me.Username, r.Name, resp.ReadPaths), nil
+}
+
+// commitBranch returns the name of the branch that 'commit' (which may be a
+// commit ID or a branch reference) is on, or "" if it isn't on a branch (or
+// doesn't exist). 'commits' is the collection of commits in 'commit's repo.
+func commitBranch(commits interface {
+	Get(key string, val proto.Message) error
+}, commit *pfs.Commit) (string, error) {
+	id, _, err := ancestry.Parse(commit.ID)
+	if err != nil {
+		return "", err
+	}
+	if !uuid.IsUUIDWithoutDashes(id) {
+		return id, nil
+	}
+	commitInfo := &pfs.CommitInfo{}
+	if err := commits.Get(id, commitInfo); err != nil {
+		if col.IsErrNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return commitInfo.Branch.GetName(), nil
 }
 
 func now() *types.Timestamp {
@@ -227,7 +319,7 @@ func now() *types.Timestamp {
 	return t
 }
 
-func (d *driver) createRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, description string, update bool) error {
+func (d *driver) createRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, description string, quota *pfs.RepoQuota, update bool) error {
 	// Validate arguments
 	if repo == nil {
 		return errors.New("repo cannot be nil")
@@ -257,6 +349,11 @@ func (d *driver) createRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, d
 			repo.Name, err)
 	} else if err == nil && !update {
 		return fmt.Errorf("cannot create \"%s\" as it already exists", repo.Name)
+	} else if err != nil {
+		// Only new repos are checked, so that existing repos can be updated
+		if err := validateNewRepoName(repo.Name); err != nil {
+			return err
+		}
 	}
 	created := now()
 	if err == nil {
@@ -281,10 +378,23 @@ func (d *driver) createRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, d
 		}
 	}
 
+	if quota == nil && update {
+		quota = existingRepoInfo.Quota
+	}
 	repoInfo := &pfs.RepoInfo{
 		Repo:        repo,
 		Created:     created,
 		Description: description,
+		Quota:       quota,
+		Commits:     existingRepoInfo.Commits,
+		UsageBytes:  existingRepoInfo.UsageBytes,
+	}
+	// Repos created before their usage was tracked need it counted once
+	// they're given a quota
+	if quota != nil && existingRepoInfo.Quota == nil && err == nil {
+		if err := d.countRepoUsage(txnCtx, repoInfo); err != nil {
+			return err
+		}
 	}
 	// Only Put the new repoInfo if something has changed.  This
 	// optimization is impactful because pps will frequently update the
@@ -894,7 +1004,17 @@ func (d *driver) fsck(pachClient *client.APIClient, fix bool, cb func(*pfs.FsckR
 				// We've observed users getting ErrExists from this create,
 				// which doesn't make a lot of sense, but we insulate against
 				// it anyways so it doesn't prevent the command from working.
-				if err := d.commits(ci.Commit.Repo.Name).ReadWrite(stm).Create(ci.Commit.ID, ci); err != nil && !col.IsErrExists(err) {
+				if err := d.commits(ci.Commit.Repo.Name).ReadWrite(stm).Create(ci.Commit.ID, ci); err != nil {
+					if col.IsErrExists(err) {
+						continue
+					}
+					return err
+				}
+				repoInfo := &pfs.RepoInfo{}
+				if err := d.repos.ReadWrite(stm).Update(ci.Commit.Repo.Name, repoInfo, func() error {
+					repoInfo.Commits++
+					return nil
+				}); err != nil {
 					return err
 				}
 			}
@@ -1090,6 +1210,9 @@ func (d *driver) deleteRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, f
 	if err := repos.Delete(repo.Name); err != nil && !col.IsErrNotFound(err) {
 		return fmt.Errorf("repos.Delete: %v", err)
 	}
+	if err := d.models.ReadWrite(txnCtx.Stm).Delete(repo.Name); err != nil && !col.IsErrNotFound(err) {
+		return fmt.Errorf("models.Delete: %v", err)
+	}
 
 	if _, err = txnCtx.Auth().SetACLInTransaction(txnCtx, &auth.SetACLRequest{
 		Repo: repo.Name, // NewACL is unset, so this will clear the acl for 'repo'
@@ -1101,8 +1224,24 @@ func (d *driver) deleteRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, f
 
 // ID can be passed in for transactions, which need to ensure the ID doesn't
 // change after the commit ID has been reported to a client.
-func (d *driver) startCommit(txnCtx *txnenv.TransactionContext, ID string, parent *pfs.Commit, branch string, provenance []*pfs.CommitProvenance, description string) (*pfs.Commit, error) {
-	return d.makeCommit(txnCtx, ID, parent, branch, provenance, nil, nil, nil, nil, nil, description, 0)
+func (d *driver) startCommit(txnCtx *txnenv.TransactionContext, ID string, parent *pfs.Commit, branch string, provenance []*pfs.CommitProvenance, description string, metadata map[string]string) (*pfs.Commit, error) {
+	for key := range metadata {
+		if key == "" {
+			return nil, fmt.Errorf("commit metadata can't have an empty key")
+		}
+	}
+	commit, err := d.makeCommit(txnCtx, ID, parent, branch, provenance, nil, nil, nil, nil, nil, description, 0)
+	if err != nil || len(metadata) == 0 {
+		return commit, err
+	}
+	commitInfo := &pfs.CommitInfo{}
+	if err := d.commits(commit.Repo.Name).ReadWrite(txnCtx.Stm).Update(commit.ID, commitInfo, func() error {
+		commitInfo.Metadata = metadata
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return commit, nil
 }
 
 func (d *driver) buildCommit(ctx context.Context, ID string, parent *pfs.Commit,
@@ -1149,7 +1288,11 @@ func (d *driver) makeCommit(
 	}
 
 	// Check that caller is authorized
-	if err := d.checkIsAuthorizedInTransaction(txnCtx, parent.Repo, auth.Scope_WRITER); err != nil {
+	if branch != "" {
+		if err := d.checkIsAuthorizedOnBranchInTransaction(txnCtx, &pfs.Branch{Repo: parent.Repo, Name: branch}, auth.Scope_WRITER); err != nil {
+			return nil, err
+		}
+	} else if err := d.checkIsAuthorizedOnCommitInTransaction(txnCtx, parent, auth.Scope_WRITER); err != nil {
 		return nil, err
 	}
 
@@ -1290,6 +1433,7 @@ func (d *driver) makeCommit(
 		if branch == "master" {
 			repoInfo.SizeBytes = newCommitInfo.SizeBytes
 		}
+		repoInfo.UsageBytes += newCommitInfo.SizeBytes
 	} else {
 		if err := d.openCommits.ReadWrite(txnCtx.Stm).Put(newCommit.ID, newCommit); err != nil {
 			return nil, err
@@ -1297,6 +1441,7 @@ func (d *driver) makeCommit(
 	}
 
 	// Update repoInfo (potentially with new branch and new size)
+	repoInfo.Commits++
 	if err := repos.Put(parent.Repo.Name, repoInfo); err != nil {
 		return nil, err
 	}
@@ -1384,6 +1529,11 @@ func (d *driver) makeCommit(
 	if err := commits.Create(newCommit.ID, newCommitInfo); err != nil {
 		return nil, err
 	}
+	if newCommitInfo.Finished != nil {
+		if err := d.fireTriggers(txnCtx, newCommitInfo); err != nil {
+			return nil, err
+		}
+	}
 	// Defer propagation of the commit until the end of the transaction so we can
 	// batch downstream commits together if there are multiple changes.
 	if branch != "" {
@@ -1404,7 +1554,7 @@ func (d *driver) finishCommit(txnCtx *txnenv.TransactionContext, commit *pfs.Com
 		return errors.New("commit repo cannot be nil")
 	}
 
-	if err := d.checkIsAuthorizedInTransaction(txnCtx, commit.Repo, auth.Scope_WRITER); err != nil {
+	if err := d.checkIsAuthorizedOnCommitInTransaction(txnCtx, commit, auth.Scope_WRITER); err != nil {
 		return err
 	}
 	commitInfo, err := d.resolveCommit(txnCtx.Stm, commit)
@@ -1469,11 +1619,17 @@ func (d *driver) finishCommit(txnCtx *txnenv.TransactionContext, commit *pfs.Com
 
 		commitInfo.SizeBytes = uint64(finishedTree.FSSize())
 	}
+	if err := d.checkQuota(txnCtx, commitInfo); err != nil {
+		return err
+	}
 	commitInfo.Finished = now()
 	if err := d.updateProvenanceProgress(txnCtx, !empty, commitInfo); err != nil {
 		return err
 	}
-	return d.writeFinishedCommit(txnCtx.Stm, commit, commitInfo)
+	if err := d.writeFinishedCommit(txnCtx.Stm, commit, commitInfo); err != nil {
+		return err
+	}
+	return d.fireTriggers(txnCtx, commitInfo)
 }
 
 func (d *driver) finishOutputCommit(txnCtx *txnenv.TransactionContext, commit *pfs.Commit, trees []*pfs.Object, datums *pfs.Object, size uint64) (retErr error) {
@@ -1490,11 +1646,17 @@ func (d *driver) finishOutputCommit(txnCtx *txnenv.TransactionContext, commit *p
 	commitInfo.Trees = trees
 	commitInfo.Datums = datums
 	commitInfo.SizeBytes = size
+	if err := d.checkQuota(txnCtx, commitInfo); err != nil {
+		return err
+	}
 	commitInfo.Finished = now()
 	if err := d.updateProvenanceProgress(txnCtx, true, commitInfo); err != nil {
 		return err
 	}
-	return d.writeFinishedCommit(txnCtx.Stm, commit, commitInfo)
+	if err := d.writeFinishedCommit(txnCtx.Stm, commit, commitInfo); err != nil {
+		return err
+	}
+	return d.fireTriggers(txnCtx, commitInfo)
 }
 
 func (d *driver) updateProvenanceProgress(txnCtx *txnenv.TransactionContext, success bool, ci *pfs.CommitInfo) error {
@@ -1514,10 +1676,74 @@ func (d *driver) updateProvenanceProgress(txnCtx *txnenv.TransactionContext, suc
 	return nil
 }
 
+// checkQuota returns an ErrQuotaExceeded if finishing 'commitInfo' would put
+// its repo over the repo's quota. The repo's usage is read from its RepoInfo
+// inside the transaction, so concurrent FinishCommits can't both slip under
+// the quota.
+func (d *driver) checkQuota(txnCtx *txnenv.TransactionContext, commitInfo *pfs.CommitInfo) error {
+	repo := commitInfo.Commit.Repo
+	repoInfo := &pfs.RepoInfo{}
+	if err := d.repos.ReadWrite(txnCtx.Stm).Get(repo.Name, repoInfo); err != nil {
+		return err
+	}
+	quota := repoInfo.Quota
+	if quota == nil {
+		return nil
+	}
+	if usage := repoInfo.UsageBytes + commitInfo.SizeBytes; quota.SizeBytes > 0 && usage > quota.SizeBytes {
+		return pfsserver.ErrQuotaExceeded{
+			Repo: repo,
+			Reason: fmt.Sprintf("commit %s would bring the repo to %s, which is more than the quota of %s", commitInfo.Commit.ID,
+				units.BytesSize(float64(usage)), units.BytesSize(float64(quota.SizeBytes))),
+		}
+	}
+	// 'commitInfo' was counted when it was started
+	if quota.Commits > 0 && repoInfo.Commits > quota.Commits {
+		return pfsserver.ErrQuotaExceeded{
+			Repo:   repo,
+			Reason: fmt.Sprintf("repo has %d commits, which is more than the quota of %d", repoInfo.Commits, quota.Commits),
+		}
+	}
+	return nil
+}
+
+// countRepoUsage recomputes 'repoInfo's commit count and usage from its
+// commits. The commits are listed outside of the transaction, but every change
+// to a repo's commits also writes its RepoInfo, which the caller has read in
+// the transaction, so a concurrent change makes the transaction retry.
+func (d *driver) countRepoUsage(txnCtx *txnenv.TransactionContext, repoInfo *pfs.RepoInfo) error {
+	repoInfo.Commits, repoInfo.UsageBytes = 0, 0
+	commitInfo := &pfs.CommitInfo{}
+	return d.commits(repoInfo.Repo.Name).ReadOnly(txnCtx.ClientContext).List(commitInfo, col.DefaultOptions, func(string) error {
+		repoInfo.Commits++
+		if commitInfo.Finished != nil {
+			repoInfo.UsageBytes += commitInfo.SizeBytes
+		}
+		return nil
+	})
+}
+
+// removeCommitUsage subtracts the deleted commit 'commitInfo' from its repo's
+// commit count and usage
+func removeCommitUsage(repoInfo *pfs.RepoInfo, commitInfo *pfs.CommitInfo) {
+	if repoInfo.Commits > 0 {
+		repoInfo.Commits--
+	}
+	if commitInfo.Finished == nil {
+		return
+	}
+	if repoInfo.UsageBytes > commitInfo.SizeBytes {
+		repoInfo.UsageBytes -= commitInfo.SizeBytes
+	} else {
+		repoInfo.UsageBytes = 0
+	}
+}
+
 // writeFinishedCommit writes these changes to etcd:
 // 1) it closes the input commit (i.e., it writes any changes made to it and
 //    removes it from the open commits)
-// 2) if the commit is the new HEAD of master, it updates the repo size
+// 2) it adds the commit's size to the repo's usage, and if the commit is the
+//    new HEAD of master, it updates the repo size
 func (d *driver) writeFinishedCommit(stm col.STM, commit *pfs.Commit, commitInfo *pfs.CommitInfo) error {
 	commits := d.commits(commit.Repo.Name).ReadWrite(stm)
 	if err := commits.Put(commit.ID, commitInfo); err != nil {
@@ -1526,12 +1752,13 @@ func (d *driver) writeFinishedCommit(stm col.STM, commit *pfs.Commit, commitInfo
 	if err := d.openCommits.ReadWrite(stm).Delete(commit.ID); err != nil {
 		return fmt.Errorf("could not confirm that commit %s is open; this is likely a bug. err: %v", commit.ID, err)
 	}
-	// update the repo size if this is the head of master
+	// update the repo usage, and the repo size if this is the head of master
 	repos := d.repos.ReadWrite(stm)
 	repoInfo := new(pfs.RepoInfo)
 	if err := repos.Get(commit.Repo.Name, repoInfo); err != nil {
 		return err
 	}
+	repoInfo.UsageBytes += commitInfo.SizeBytes
 	for _, branch := range repoInfo.Branches {
 		if branch.Name == "master" {
 			branchInfo := &pfs.BranchInfo{}
@@ -1542,13 +1769,10 @@ func (d *driver) writeFinishedCommit(stm col.STM, commit *pfs.Commit, commitInfo
 			// had shared its head commit with master, and then we created a new commit on that branch
 			if branchInfo.Head != nil && branchInfo.Head.ID == commit.ID {
 				repoInfo.SizeBytes = commitInfo.SizeBytes
-				if err := repos.Put(commit.Repo.Name, repoInfo); err != nil {
-					return err
-				}
 			}
 		}
 	}
-	return nil
+	return repos.Put(commit.Repo.Name, repoInfo)
 }
 
 // propagateCommits selectively starts commits in or downstream of 'branches' in
@@ -1756,6 +1980,13 @@ nextSubvBI:
 		if err := d.openCommits.ReadWrite(stm).Put(newCommit.ID, newCommit); err != nil {
 			return err
 		}
+		repoInfo := &pfs.RepoInfo{}
+		if err := d.repos.ReadWrite(stm).Update(subvB.Repo.Name, repoInfo, func() error {
+			repoInfo.Commits++
+			return nil
+		}); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -1769,7 +2000,7 @@ func (d *driver) inspectCommit(pachClient *client.APIClient, commit *pfs.Commit,
 	if commit == nil {
 		return nil, fmt.Errorf("cannot inspect nil commit")
 	}
-	if err := d.checkIsAuthorized(pachClient, commit.Repo, auth.Scope_READER); err != nil {
+	if _, err := d.checkIsAuthorizedOnCommit(pachClient, commit, auth.Scope_READER); err != nil {
 		return nil, err
 	}
 
@@ -2121,6 +2352,62 @@ func (d *driver) subscribeCommit(pachClient *client.APIClient, repo *pfs.Repo, b
 	}
 }
 
+// watchCommit calls f with every commit in 'repo', and then again with each
+// commit whenever it changes. If 'branch' is set, only the commits made on it
+// are included.
+func (d *driver) watchCommit(pachClient *client.APIClient, repo *pfs.Repo, branch string, f func(*pfs.CommitInfo) error) error {
+	// Validate arguments
+	if repo == nil {
+		return errors.New("repo cannot be nil")
+	}
+	ctx := pachClient.Ctx()
+	if err := d.checkIsAuthorized(pachClient, repo, auth.Scope_READER); err != nil {
+		return err
+	}
+	// Make sure that the repo (and branch) exist
+	if err := d.txnEnv.WithReadContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		if _, err := d.inspectRepo(txnCtx, repo, !includeAuth); err != nil {
+			return err
+		}
+		if branch != "" {
+			_, err := d.inspectBranch(txnCtx, client.NewBranch(repo.Name, branch))
+			return err
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	commits := d.commits(repo.Name).ReadOnly(ctx)
+	watcher, err := commits.Watch(watch.WithSort(etcd.SortByCreateRevision, etcd.SortAscend))
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	for {
+		event, ok := <-watcher.Watch()
+		if !ok {
+			return fmt.Errorf("the stream for commit updates closed unexpectedly")
+		}
+		switch event.Type {
+		case watch.EventError:
+			return event.Err
+		case watch.EventPut:
+			var commitID string
+			commitInfo := &pfs.CommitInfo{}
+			if err := event.Unmarshal(&commitID, commitInfo); err != nil {
+				return fmt.Errorf("unmarshal: %v", err)
+			}
+			if branch != "" && (commitInfo.Branch == nil || commitInfo.Branch.Name != branch) {
+				continue
+			}
+			if err := f(commitInfo); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 func (d *driver) flushCommit(pachClient *client.APIClient, fromCommits []*pfs.Commit, toRepos []*pfs.Repo, f func(*pfs.CommitInfo) error) error {
 	if len(fromCommits) == 0 {
 		return fmt.Errorf("fromCommits cannot be empty")
@@ -2201,7 +2488,7 @@ func (d *driver) deleteCommit(txnCtx *txnenv.TransactionContext, userCommit *pfs
 		return errors.New("commit repo cannot be nil")
 	}
 
-	if err := d.checkIsAuthorizedInTransaction(txnCtx, userCommit.Repo, auth.Scope_WRITER); err != nil {
+	if err := d.checkIsAuthorizedOnCommitInTransaction(txnCtx, userCommit, auth.Scope_WRITER); err != nil {
 		return err
 	}
 	// Main txn: Delete all downstream commits, and update subvenance of upstream commits
@@ -2422,6 +2709,14 @@ func (d *driver) deleteCommit(txnCtx *txnenv.TransactionContext, userCommit *pfs
 		if err := repos.Get(repo, repoInfo); err != nil {
 			return err
 		}
+		for _, deletedInfo := range deleted {
+			if deletedInfo.Commit.Repo.Name == repo {
+				removeCommitUsage(repoInfo, deletedInfo)
+			}
+		}
+		if err := repos.Put(repo, repoInfo); err != nil {
+			return err
+		}
 		for _, brokenBranch := range repoInfo.Branches {
 			// Traverse HEAD commit until we find a non-deleted parent or nil;
 			// rewrite branch
@@ -2468,7 +2763,15 @@ func (d *driver) deleteCommit(txnCtx *txnenv.TransactionContext, userCommit *pfs
 		}
 	}
 
-	// 8) propagate the changes to 'branch' and its subvenance. This may start
+	// 8) Remove the deleted commits from the model registries of the affected
+	// repos
+	for repo := range affectedRepos {
+		if err := d.deleteModelVersions(txnCtx, repo, deleted); err != nil {
+			return err
+		}
+	}
+
+	// 9) propagate the changes to 'branch' and its subvenance. This may start
 	// new HEAD commits downstream, if the new branch heads haven't been
 	// processed yet
 	for _, afBranch := range affectedBranches {
@@ -2524,7 +2827,7 @@ func (d *driver) createBranch(txnCtx *txnenv.TransactionContext, branch *pfs.Bra
 	}
 
 	var err error
-	if err := d.checkIsAuthorizedInTransaction(txnCtx, branch.Repo, auth.Scope_WRITER); err != nil {
+	if err := d.checkIsAuthorizedOnBranchInTransaction(txnCtx, branch, auth.Scope_WRITER); err != nil {
 		return err
 	}
 	// Validate request
@@ -2581,6 +2884,9 @@ func (d *driver) createBranch(txnCtx *txnenv.TransactionContext, branch *pfs.Bra
 		branchInfo.Name = branch.Name // set in case 'branch' is new
 		branchInfo.Branch = branch
 		branchInfo.Head = commit
+		if branchInfo.Trigger != nil && len(provenance) > 0 {
+			return fmt.Errorf("cannot give branch %s@%s provenance because it has a trigger", branch.Repo.Name, branch.Name)
+		}
 		branchInfo.DirectProvenance = nil
 		for _, provBranch := range provenance {
 			if provBranch.Repo.Name == branch.Repo.Name && provBranch.Name == branch.Name {
@@ -2746,6 +3052,51 @@ func (d *driver) listBranch(pachClient *client.APIClient, repo *pfs.Repo, revers
 	return result, nil
 }
 
+// watchBranch calls f with every branch in 'repo', and then again with each
+// branch whenever it changes
+func (d *driver) watchBranch(pachClient *client.APIClient, repo *pfs.Repo, f func(*pfs.BranchInfo) error) error {
+	// Validate arguments
+	if repo == nil {
+		return errors.New("repo cannot be nil")
+	}
+	ctx := pachClient.Ctx()
+	if err := d.checkIsAuthorized(pachClient, repo, auth.Scope_READER); err != nil {
+		return err
+	}
+	// Make sure that the repo exists
+	if err := d.txnEnv.WithReadContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		_, err := d.inspectRepo(txnCtx, repo, !includeAuth)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	watcher, err := d.branches(repo.Name).ReadOnly(ctx).Watch(watch.WithSort(etcd.SortByCreateRevision, etcd.SortAscend))
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	for {
+		event, ok := <-watcher.Watch()
+		if !ok {
+			return fmt.Errorf("the stream for branch updates closed unexpectedly")
+		}
+		switch event.Type {
+		case watch.EventError:
+			return event.Err
+		case watch.EventPut:
+			var branch string
+			branchInfo := &pfs.BranchInfo{}
+			if err := event.Unmarshal(&branch, branchInfo); err != nil {
+				return fmt.Errorf("unmarshal: %v", err)
+			}
+			if err := f(branchInfo); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 func (d *driver) deleteBranch(txnCtx *txnenv.TransactionContext, branch *pfs.Branch, force bool) error {
 	// Validate arguments
 	if branch == nil {
@@ -2755,7 +3106,7 @@ func (d *driver) deleteBranch(txnCtx *txnenv.TransactionContext, branch *pfs.Bra
 		return errors.New("branch repo cannot be nil")
 	}
 
-	if err := d.checkIsAuthorizedInTransaction(txnCtx, branch.Repo, auth.Scope_WRITER); err != nil {
+	if err := d.checkIsAuthorizedOnBranchInTransaction(txnCtx, branch, auth.Scope_WRITER); err != nil {
 		return err
 	}
 
@@ -2797,6 +3148,48 @@ func (d *driver) deleteBranch(txnCtx *txnenv.TransactionContext, branch *pfs.Bra
 	return nil
 }
 
+func (d *driver) setBranchRetention(txnCtx *txnenv.TransactionContext, branch *pfs.Branch, retention *pfs.RetentionPolicy) error {
+	// Validate arguments
+	if branch == nil {
+		return errors.New("branch cannot be nil")
+	}
+	if branch.Repo == nil {
+		return errors.New("branch repo cannot be nil")
+	}
+	if retention != nil {
+		if retention.History < 0 {
+			return fmt.Errorf("retention history must be nonnegative, but was %d", retention.History)
+		}
+		if retention.MaxAge != nil {
+			maxAge, err := types.DurationFromProto(retention.MaxAge)
+			if err != nil {
+				return err
+			}
+			if maxAge < 0 {
+				return fmt.Errorf("retention max age must be nonnegative, but was %v", maxAge)
+			}
+		}
+		if retention.History == 0 && retention.MaxAge == nil {
+			retention = nil
+		}
+	}
+
+	if err := d.checkIsAuthorizedOnBranchInTransaction(txnCtx, branch, auth.Scope_WRITER); err != nil {
+		return err
+	}
+
+	branchInfo := &pfs.BranchInfo{}
+	return d.branches(branch.Repo.Name).ReadWrite(txnCtx.Stm).Update(branch.Name, branchInfo, func() error {
+		// The history of a branch with provenance follows the history of its
+		// provenance, as its commits are deleted along with their provenance
+		if retention != nil && len(branchInfo.Provenance) > 0 {
+			return fmt.Errorf("cannot set a retention policy on branch %s@%s because it has provenance", branch.Repo.Name, branch.Name)
+		}
+		branchInfo.Retention = retention
+		return nil
+	})
+}
+
 // scratchCommitPrefix returns an etcd prefix that's used to temporarily
 // store the state of a file in an open commit.  Once the commit is finished,
 // the scratch space is removed.
@@ -2823,16 +3216,37 @@ func (d *driver) scratchFilePrefix(file *pfs.File) (string, error) {
 	return path.Join(d.scratchCommitPrefix(file.Commit), cleanedPath), nil
 }
 
-func (d *driver) putFiles(pachClient *client.APIClient, s *putFileServer) error {
+func (d *driver) putFiles(pachClient *client.APIClient, s *putFileServer) (*errcode.BulkResponse, error) {
+	mode := errcode.BulkMode_ATOMIC
+	if req, err := s.Peek(); err == nil {
+		mode = req.Mode
+	}
 	var files []*pfs.File
 	var putFilePaths []string
 	var putFileRecords []*pfs.PutFileRecords
+	var failed []*errcode.ItemResult
 	var mu sync.Mutex
-	oneOff, repo, branch, err := d.forEachPutFile(pachClient, s, func(req *pfs.PutFileRequest, r io.Reader) error {
+	// itemErr handles the failure of one of the files being put. Best-effort
+	// calls skip the file and report it in the response.
+	itemErr := func(req *pfs.PutFileRequest, err error) error {
+		if mode != errcode.BulkMode_BEST_EFFORT {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		failed = append(failed, errcode.NewItemResult(req.File.Path, err))
+		return nil
+	}
+	oneOff, repo, branch, err := d.forEachPutFile(pachClient, s, itemErr, func(req *pfs.PutFileRequest, r io.Reader) error {
 		records, err := d.putFile(pachClient, req.File, req.Delimiter, req.TargetFileDatums,
 			req.TargetFileBytes, req.HeaderRecords, req.OverwriteIndex, r)
 		if err != nil {
-			return err
+			if mode == errcode.BulkMode_BEST_EFFORT {
+				// read the rest of the file, so that the files after it can
+				// still be read from the stream
+				io.Copy(ioutil.Discard, r)
+			}
+			return itemErr(req, err)
 		}
 		mu.Lock()
 		defer mu.Unlock()
@@ -2842,31 +3256,44 @@ func (d *driver) putFiles(pachClient *client.APIClient, s *putFileServer) error
 		return nil
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	response := &errcode.BulkResponse{Results: failed}
 	ctx := pachClient.Ctx()
 	if oneOff {
 		// oneOff puts only work on branches, so we know branch != "". We pass
 		// a commit with no ID, that ID will be filled in with the head of
 		// branch (if it exists).
-		return d.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		if err := d.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
 			_, err := d.makeCommit(txnCtx, "", client.NewCommit(repo, ""), branch, nil, nil, nil, nil, putFilePaths, putFileRecords, "", 0)
 			return err
-		})
-	}
-	for i, file := range files {
-		if err := d.upsertPutFileRecords(pachClient, file, putFileRecords[i]); err != nil {
-			return err
+		}); err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			response.Results = append(response.Results, errcode.NewItemResult(file.Path, nil))
+		}
+	} else {
+		for i, file := range files {
+			err := d.upsertPutFileRecords(pachClient, file, putFileRecords[i])
+			if err != nil && mode != errcode.BulkMode_BEST_EFFORT {
+				return nil, err
+			}
+			response.Results = append(response.Results, errcode.NewItemResult(file.Path, err))
 		}
 	}
-	return nil
+	// files are put concurrently, so sort their results
+	sort.SliceStable(response.Results, func(i, j int) bool {
+		return response.Results[i].Item < response.Results[j].Item
+	})
+	return response, nil
 }
 
 func (d *driver) putFile(pachClient *client.APIClient, file *pfs.File, delimiter pfs.Delimiter,
 	targetFileDatums, targetFileBytes, headerRecords int64, overwriteIndex *pfs.OverwriteIndex,
 	reader io.Reader) (*pfs.PutFileRecords, error) {
-	if err := d.checkIsAuthorized(pachClient, file.Commit.Repo, auth.Scope_WRITER); err != nil {
+	if _, err := d.checkIsAuthorizedOnCommit(pachClient, file.Commit, auth.Scope_WRITER); err != nil {
 		return nil, err
 	}
 	//  validation -- make sure the various putFileSplit options are coherent
@@ -2874,6 +3301,9 @@ func (d *driver) putFile(pachClient *client.APIClient, file *pfs.File, delimiter
 	if hasPutFileOptions && delimiter == pfs.Delimiter_NONE {
 		return nil, fmt.Errorf("cannot set split options--targetFileBytes, targetFileDatums, or headerRecords--with delimiter == NONE, split disabled")
 	}
+	if headerRecords != 0 && (delimiter == pfs.Delimiter_PARQUET || delimiter == pfs.Delimiter_AVRO) {
+		return nil, fmt.Errorf("cannot set headerRecords with delimiter == %s, which splits files on row group or block boundaries", delimiter)
+	}
 	records := &pfs.PutFileRecords{}
 	if overwriteIndex != nil && overwriteIndex.Index == 0 {
 		records.Tombstone = true
@@ -2933,6 +3363,9 @@ func (d *driver) putFile(pachClient *client.APIClient, file *pfs.File, delimiter
 			csvReader = csv.NewReader(bufioR)
 			csvBuffer bytes.Buffer
 			csvWriter = csv.NewWriter(&csvBuffer)
+			avroReader = columnar.NewAvroReader(bufioR)
+			// only used if delimiter == PARQUET
+			parquetReader *columnar.ParquetReader
 			// indexToRecord serves as a de-facto slice of PutFileRecords. We can't
 			// use a real slice of PutFileRecords b/c indexToRecord has data appended
 			// to it by concurrent processes, and you can't append() to a slice
@@ -2943,6 +3376,36 @@ func (d *driver) putFile(pachClient *client.APIClient, file *pfs.File, delimiter
 		)
 		csvReader.FieldsPerRecord = -1 // ignore unexpected # of fields, for now
 		csvReader.ReuseRecord = true   // returned rows are written to buffer immediately
+		if delimiter == pfs.Delimiter_PARQUET {
+			// Parquet files' metadata is at the end of the file, so the file has
+			// to be spooled to disk before it can be split
+			f, err := ioutil.TempFile("", "pachyderm-parquet")
+			if err != nil {
+				return nil, err
+			}
+			defer os.Remove(f.Name())
+			defer f.Close()
+			size, err := io.Copy(f, bufioR)
+			if err != nil {
+				return nil, err
+			}
+			if parquetReader, err = columnar.NewParquetReader(f, size); err != nil {
+				return nil, err
+			}
+		}
+		if delimiter == pfs.Delimiter_AVRO && !records.Tombstone {
+			// Blocks appended to an existing split avro file must use its
+			// header's sync marker, as the header is shared by all of its blocks
+			existing, err := d.splitFileHeader(pachClient, file)
+			if err != nil {
+				return nil, err
+			}
+			if existing != nil {
+				if err := avroReader.AppendTo(existing); err != nil {
+					return nil, err
+				}
+			}
+		}
 		for !EOF {
 			var err error
 			var value []byte
@@ -2977,6 +3440,13 @@ func (d *driver) putFile(pachClient *client.APIClient, file *pfs.File, delimiter
 					}
 					value = csvBuffer.Bytes()
 				}
+			case pfs.Delimiter_PARQUET:
+				value, err = parquetReader.ReadRowGroup()
+			case pfs.Delimiter_AVRO:
+				value, err = avroReader.ReadBlock()
+				if header == nil && avroReader.Header != nil {
+					header = avroReader.Header
+				}
 			default:
 				return nil, fmt.Errorf("unrecognized delimiter %s", delimiter.String())
 			}
@@ -3004,6 +3474,14 @@ func (d *driver) putFile(pachClient *client.APIClient, file *pfs.File, delimiter
 					header = _buffer.Bytes() // record header
 				} else {
 					// put contents
+					if delimiter == pfs.Delimiter_PARQUET {
+						// each split file is a complete parquet file
+						file, err := parquetReader.File(_buffer.Bytes())
+						if err != nil {
+							return nil, err
+						}
+						_buffer = bytes.NewBuffer(file)
+					}
 					_bufferLen := int64(_buffer.Len())
 					index := filesPut
 					filesPut++
@@ -3071,6 +3549,35 @@ func (d *driver) putFile(pachClient *client.APIClient, file *pfs.File, delimiter
 	return records, nil
 }
 
+// splitFileHeader returns the contents of the header of the split file
+// 'file', or nil if 'file' doesn't exist or has no header
+func (d *driver) splitFileHeader(pachClient *client.APIClient, file *pfs.File) ([]byte, error) {
+	tree, err := d.getTreeForFile(pachClient, file)
+	if err != nil {
+		// putting a file can create the branch that it's on
+		if col.IsErrNotFound(err) || pfsserver.IsBranchNotFoundErr(err) || pfsserver.IsNoHeadErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer destroyHashtree(tree)
+	node, err := tree.Get(file.Path)
+	if err != nil {
+		if hashtree.Code(err) == hashtree.PathNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if node.DirNode == nil || node.DirNode.Shared == nil || node.DirNode.Shared.Header == nil {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	if err := pachClient.GetObject(node.DirNode.Shared.Header.Hash, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func appendRecords(pfr *pfs.PutFileRecords, node *hashtree.NodeProto) {
 	for i, object := range node.FileNode.Objects {
 		// We only have the whole file size in src file, so mark the first object
@@ -3165,10 +3672,14 @@ func (d *driver) copyFile(pachClient *client.APIClient, src *pfs.File, dst *pfs.
 		return errors.New("dst commit repo cannot be nil")
 	}
 
-	if err := d.checkIsAuthorized(pachClient, src.Commit.Repo, auth.Scope_READER); err != nil {
+	srcFilter, err := d.checkIsAuthorizedOnCommit(pachClient, src.Commit, auth.Scope_READER)
+	if err != nil {
 		return err
 	}
-	if err := d.checkIsAuthorized(pachClient, dst.Commit.Repo, auth.Scope_WRITER); err != nil {
+	if err := srcFilter.checkRead(src.Path); err != nil {
+		return err
+	}
+	if _, err := d.checkIsAuthorizedOnCommit(pachClient, dst.Commit, auth.Scope_WRITER); err != nil {
 		return err
 	}
 	if err := d.checkFilePath(dst.Path); err != nil {
@@ -3483,7 +3994,7 @@ func (d *driver) getTreeForOpenCommit(pachClient *client.APIClient, file *pfs.Fi
 
 	recordsCol := d.putFileRecords.ReadOnly(pachClient.Ctx())
 	putFileRecords := &pfs.PutFileRecords{}
-	opts := &col.Options{etcd.SortByModRevision, etcd.SortAscend, true}
+	opts := &col.Options{Target: etcd.SortByModRevision, Order: etcd.SortAscend, SelfSort: true}
 	err = recordsCol.ListPrefix(prefix, putFileRecords, opts, func(key string) error {
 		return d.applyWrite(path.Join(file.Path, key), putFileRecords, tree)
 	})
@@ -3520,9 +4031,16 @@ func (d *driver) getFile(pachClient *client.APIClient, file *pfs.File, offset in
 	if file.Commit.Repo == nil {
 		return nil, errors.New("file commit repo cannot be nil")
 	}
+	if offset < 0 || size < 0 {
+		return nil, fmt.Errorf("offset and size cannot be negative (got offset %d and size %d)", offset, size)
+	}
 
 	ctx := pachClient.Ctx()
-	if err := d.checkIsAuthorized(pachClient, file.Commit.Repo, auth.Scope_READER); err != nil {
+	filter, err := d.checkIsAuthorizedOnCommit(pachClient, file.Commit, auth.Scope_READER)
+	if err != nil {
+		return nil, err
+	}
+	if err := filter.checkRead(file.Path); err != nil {
 		return nil, err
 	}
 	commitInfo, err := d.inspectCommit(pachClient, file.Commit, pfs.CommitState_STARTED)
@@ -3601,18 +4119,7 @@ func (d *driver) getFile(pachClient *client.APIClient, file *pfs.File, offset in
 
 		// retrieve the content of all objects in 'objects'
 		if len(brs) > 0 {
-			getBlocksClient, err := pachClient.ObjectAPIClient.GetBlocks(
-				ctx,
-				&pfs.GetBlocksRequest{
-					BlockRefs:   brs,
-					OffsetBytes: uint64(offset),
-					SizeBytes:   uint64(size),
-					TotalSize:   uint64(totalSize),
-				})
-			if err != nil {
-				return nil, err
-			}
-			return grpcutil.NewStreamingBytesReader(getBlocksClient, nil), nil
+			return getBlocks(pachClient, brs, uint64(offset), uint64(size), totalSize)
 		}
 		getObjectsClient, err := pachClient.ObjectAPIClient.GetObjects(
 			ctx,
@@ -3668,13 +4175,43 @@ func (d *driver) getFile(pachClient *client.APIClient, file *pfs.File, offset in
 	if !found {
 		return nil, pfsserver.ErrFileNotFound{file}
 	}
+	return getBlocks(pachClient, blockRefs, uint64(offset), uint64(size), uint64(totalSize))
+}
+
+// getBlocks returns a reader for the 'size' bytes at 'offset' in the
+// concatenation of 'blockRefs' (or for all of the bytes after 'offset', if
+// 'size' is 0). Only the block refs that overlap that range are sent to
+// GetBlocks, so reading a small range of a large file doesn't send (or
+// iterate through) all of the file's block refs.
+func getBlocks(pachClient *client.APIClient, blockRefs []*pfs.BlockRef, offset, size, totalSize uint64) (io.Reader, error) {
+	// Skip the block refs that end before 'offset'
+	for len(blockRefs) > 0 {
+		blockSize := blockRefs[0].Range.Upper - blockRefs[0].Range.Lower
+		if offset < blockSize {
+			break
+		}
+		offset -= blockSize
+		blockRefs = blockRefs[1:]
+	}
+	// Drop the block refs that start after the end of the range
+	if size > 0 {
+		end := offset + size
+		for i, blockRef := range blockRefs {
+			blockSize := blockRef.Range.Upper - blockRef.Range.Lower
+			if end <= blockSize {
+				blockRefs = blockRefs[:i+1]
+				break
+			}
+			end -= blockSize
+		}
+	}
 	getBlocksClient, err := pachClient.ObjectAPIClient.GetBlocks(
-		ctx,
+		pachClient.Ctx(),
 		&pfs.GetBlocksRequest{
 			BlockRefs:   blockRefs,
-			OffsetBytes: uint64(offset),
-			SizeBytes:   uint64(size),
-			TotalSize:   uint64(totalSize),
+			OffsetBytes: offset,
+			SizeBytes:   size,
+			TotalSize:   totalSize,
 		},
 	)
 	if err != nil {
@@ -3776,9 +4313,13 @@ func (d *driver) inspectFile(pachClient *client.APIClient, file *pfs.File) (fi *
 		return nil, errors.New("file commit repo cannot be nil")
 	}
 
-	if err := d.checkIsAuthorized(pachClient, file.Commit.Repo, auth.Scope_READER); err != nil {
+	filter, err := d.checkIsAuthorizedOnCommit(pachClient, file.Commit, auth.Scope_READER)
+	if err != nil {
 		return nil, err
 	}
+	if !filter.canSee(file.Path) {
+		return nil, filter.checkRead(file.Path)
+	}
 	commitInfo, err := d.inspectCommit(pachClient, file.Commit, pfs.CommitState_STARTED)
 	if err != nil {
 		return nil, err
@@ -3833,9 +4374,11 @@ func (d *driver) listFile(pachClient *client.APIClient, file *pfs.File, full boo
 		return errors.New("file commit repo cannot be nil")
 	}
 
-	if err := d.checkIsAuthorized(pachClient, file.Commit.Repo, auth.Scope_READER); err != nil {
+	filter, err := d.checkIsAuthorizedOnCommit(pachClient, file.Commit, auth.Scope_READER)
+	if err != nil {
 		return err
 	}
+	f = filter.filter(f)
 	commitInfo, err := d.inspectCommit(pachClient, file.Commit, pfs.CommitState_STARTED)
 	if err != nil {
 		return err
@@ -3954,9 +4497,11 @@ func (d *driver) walkFile(pachClient *client.APIClient, file *pfs.File, f func(*
 		return errors.New("file commit repo cannot be nil")
 	}
 
-	if err := d.checkIsAuthorized(pachClient, file.Commit.Repo, auth.Scope_READER); err != nil {
+	filter, err := d.checkIsAuthorizedOnCommit(pachClient, file.Commit, auth.Scope_READER)
+	if err != nil {
 		return err
 	}
+	f = filter.filter(f)
 	commitInfo, err := d.inspectCommit(pachClient, file.Commit, pfs.CommitState_STARTED)
 	if err != nil {
 		return err
@@ -4008,9 +4553,11 @@ func (d *driver) globFile(pachClient *client.APIClient, commit *pfs.Commit, patt
 		return errors.New("commit repo cannot be nil")
 	}
 
-	if err := d.checkIsAuthorized(pachClient, commit.Repo, auth.Scope_READER); err != nil {
+	filter, err := d.checkIsAuthorizedOnCommit(pachClient, commit, auth.Scope_READER)
+	if err != nil {
 		return err
 	}
+	f = filter.filter(f)
 	commitInfo, err := d.inspectCommit(pachClient, commit, pfs.CommitState_STARTED)
 	if err != nil {
 		return err
@@ -4065,36 +4612,59 @@ func (d *driver) globFile(pachClient *client.APIClient, commit *pfs.Commit, patt
 }
 
 func (d *driver) diffFile(pachClient *client.APIClient, newFile *pfs.File, oldFile *pfs.File, shallow bool) ([]*pfs.FileInfo, []*pfs.FileInfo, error) {
+	var newFileInfos []*pfs.FileInfo
+	var oldFileInfos []*pfs.FileInfo
+	if err := d.diffFileF(pachClient, newFile, oldFile, shallow, false, func(fileDiff *pfs.FileDiff) error {
+		if fileDiff.NewFile != nil {
+			newFileInfos = append(newFileInfos, fileDiff.NewFile)
+		}
+		if fileDiff.OldFile != nil {
+			oldFileInfos = append(oldFileInfos, fileDiff.OldFile)
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+	return newFileInfos, oldFileInfos, nil
+}
+
+// diffFileF calls 'f' with each difference between 'newFile' and 'oldFile' as
+// it's found, pairing the old and new versions of modified files. If
+// 'detectRenames' is set, files that were only added or only deleted are held
+// back until the whole diff has been computed, and then each deleted file
+// whose contents match an added file is reported as having been renamed.
+func (d *driver) diffFileF(pachClient *client.APIClient, newFile *pfs.File, oldFile *pfs.File, shallow bool, detectRenames bool, f func(*pfs.FileDiff) error) error {
 	// Validate arguments
 	if newFile == nil {
-		return nil, nil, errors.New("file cannot be nil")
+		return errors.New("file cannot be nil")
 	}
 	if newFile.Commit == nil {
-		return nil, nil, errors.New("file commit cannot be nil")
+		return errors.New("file commit cannot be nil")
 	}
 	if newFile.Commit.Repo == nil {
-		return nil, nil, errors.New("file commit repo cannot be nil")
+		return errors.New("file commit repo cannot be nil")
 	}
 
 	// Do READER authorization check for both newFile and oldFile
-	if oldFile != nil && oldFile.Commit != nil {
-		if err := d.checkIsAuthorized(pachClient, oldFile.Commit.Repo, auth.Scope_READER); err != nil {
-			return nil, nil, err
-		}
+	newFilter, err := d.checkIsAuthorizedOnCommit(pachClient, newFile.Commit, auth.Scope_READER)
+	if err != nil {
+		return err
 	}
-	if newFile != nil && newFile.Commit != nil {
-		if err := d.checkIsAuthorized(pachClient, newFile.Commit.Repo, auth.Scope_READER); err != nil {
-			return nil, nil, err
+	oldFilter := newFilter
+	if oldFile != nil && oldFile.Commit != nil {
+		if oldFilter, err = d.checkIsAuthorizedOnCommit(pachClient, oldFile.Commit, auth.Scope_READER); err != nil {
+			return err
 		}
 	}
+	f = filterDiff(newFilter, oldFilter, f)
 	newTree, err := d.getTreeForFile(pachClient, newFile)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 	defer destroyHashtree(newTree)
 	newCommitInfo, err := d.inspectCommit(pachClient, newFile.Commit, pfs.CommitState_STARTED)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 	// if oldFile is nil we use the parent of newFile
 	if oldFile == nil {
@@ -4112,55 +4682,149 @@ func (d *driver) diffFile(pachClient *client.APIClient, newFile *pfs.File, oldFi
 	if oldFile.Commit != nil {
 		oldCommitInfo, err = d.inspectCommit(pachClient, oldFile.Commit, pfs.CommitState_STARTED)
 		if err != nil {
-			return nil, nil, err
+			return err
 		}
 	}
 	oldTree, err := d.getTreeForFile(pachClient, oldFile)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 	defer destroyHashtree(oldTree)
-	var newFileInfos []*pfs.FileInfo
-	var oldFileInfos []*pfs.FileInfo
 	recursiveDepth := -1
 	if shallow {
 		recursiveDepth = 1
 	}
+
+	// added and deleted hold the files that were only added or only deleted
+	// while renames are being detected
+	var added, deleted []*pfs.FileInfo
+	send := func(fileDiff *pfs.FileDiff) error {
+		if detectRenames && fileDiff.OldFile == nil {
+			added = append(added, fileDiff.NewFile)
+			return nil
+		}
+		if detectRenames && fileDiff.NewFile == nil {
+			deleted = append(deleted, fileDiff.OldFile)
+			return nil
+		}
+		return f(fileDiff)
+	}
+	// Diff visits the new version of a modified file immediately before its
+	// old version, so the new version is held in 'pending' until we know
+	// whether the next file is its old version.
+	var pending *pfs.FileInfo
+	flush := func() error {
+		if pending == nil {
+			return nil
+		}
+		fileInfo := pending
+		pending = nil
+		return send(&pfs.FileDiff{NewFile: fileInfo})
+	}
 	if err := newTree.Diff(oldTree, newFile.Path, oldFile.Path, int64(recursiveDepth), func(path string, node *hashtree.NodeProto, isNewFile bool) error {
 		if isNewFile {
 			fi, err := nodeToFileInfoHeaderFooter(newCommitInfo, path, node, newTree, false)
 			if err != nil {
 				return err
 			}
-			newFileInfos = append(newFileInfos, fi)
-		} else {
-			fi, err := nodeToFileInfoHeaderFooter(oldCommitInfo, path, node, oldTree, false)
-			if err != nil {
+			if err := flush(); err != nil {
 				return err
 			}
-			oldFileInfos = append(oldFileInfos, fi)
+			pending = fi
+			return nil
 		}
-		return nil
+		fi, err := nodeToFileInfoHeaderFooter(oldCommitInfo, path, node, oldTree, false)
+		if err != nil {
+			return err
+		}
+		if pending != nil && strings.TrimPrefix(pending.File.Path, newFile.Path) == strings.TrimPrefix(path, oldFile.Path) {
+			fileInfo := pending
+			pending = nil
+			return send(&pfs.FileDiff{NewFile: fileInfo, OldFile: fi})
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		return send(&pfs.FileDiff{OldFile: fi})
 	}); err != nil {
-		return nil, nil, err
+		return err
 	}
-	return newFileInfos, oldFileInfos, nil
+	if err := flush(); err != nil {
+		return err
+	}
+	if !detectRenames {
+		return nil
+	}
+
+	// Match deleted files with added files that have the same contents.
+	// Empty files are never matched, as their contents don't identify them.
+	renameKey := func(fi *pfs.FileInfo) string {
+		if fi.FileType != pfs.FileType_FILE || fi.SizeBytes == 0 || len(fi.Hash) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%x:%d", fi.Hash, fi.SizeBytes)
+	}
+	candidates := make(map[string][]*pfs.FileInfo)
+	for _, fi := range deleted {
+		if key := renameKey(fi); key != "" {
+			candidates[key] = append(candidates[key], fi)
+		}
+	}
+	renamed := make(map[*pfs.FileInfo]bool)
+	for _, fi := range added {
+		fileDiff := &pfs.FileDiff{NewFile: fi}
+		if key := renameKey(fi); key != "" && len(candidates[key]) > 0 {
+			fileDiff.OldFile = candidates[key][0]
+			fileDiff.Renamed = true
+			candidates[key] = candidates[key][1:]
+			renamed[fileDiff.OldFile] = true
+		}
+		if err := f(fileDiff); err != nil {
+			return err
+		}
+	}
+	for _, fi := range deleted {
+		if !renamed[fi] {
+			if err := f(&pfs.FileDiff{OldFile: fi}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func (d *driver) deleteFile(pachClient *client.APIClient, file *pfs.File) error {
+	branch, commitInfo, err := d.checkDeleteFile(pachClient, file)
+	if err != nil {
+		return err
+	}
+	if commitInfo.Finished != nil {
+		return d.txnEnv.WithWriteContext(pachClient.Ctx(), func(txnCtx *txnenv.TransactionContext) error {
+			_, err := d.makeCommit(txnCtx, "", client.NewCommit(file.Commit.Repo.Name, ""), branch, nil, nil, nil, nil, []string{file.Path}, []*pfs.PutFileRecords{&pfs.PutFileRecords{Tombstone: true}}, "", 0)
+			return err
+		})
+	}
+
+	return d.upsertPutFileRecords(pachClient, file, &pfs.PutFileRecords{Tombstone: true})
+}
+
+// checkDeleteFile checks that 'file' can be deleted, and returns the commit
+// that it's in. If the commit is finished, the file is deleted in a new
+// commit on 'branch'.
+func (d *driver) checkDeleteFile(pachClient *client.APIClient, file *pfs.File) (string, *pfs.CommitInfo, error) {
 	// Validate arguments
 	if file == nil {
-		return errors.New("file cannot be nil")
+		return "", nil, errors.New("file cannot be nil")
 	}
 	if file.Commit == nil {
-		return errors.New("file commit cannot be nil")
+		return "", nil, errors.New("file commit cannot be nil")
 	}
 	if file.Commit.Repo == nil {
-		return errors.New("file commit repo cannot be nil")
+		return "", nil, errors.New("file commit repo cannot be nil")
 	}
 
-	if err := d.checkIsAuthorized(pachClient, file.Commit.Repo, auth.Scope_WRITER); err != nil {
-		return err
+	if _, err := d.checkIsAuthorizedOnCommit(pachClient, file.Commit, auth.Scope_WRITER); err != nil {
+		return "", nil, err
 	}
 	branch := ""
 	if !uuid.IsUUIDWithoutDashes(file.Commit.ID) {
@@ -4168,19 +4832,91 @@ func (d *driver) deleteFile(pachClient *client.APIClient, file *pfs.File) error
 	}
 	commitInfo, err := d.inspectCommit(pachClient, file.Commit, pfs.CommitState_STARTED)
 	if err != nil {
-		return err
+		return "", nil, err
 	}
-	if commitInfo.Finished != nil {
-		if branch == "" {
-			return pfsserver.ErrCommitFinished{file.Commit}
+	if commitInfo.Finished != nil && branch == "" {
+		return "", nil, pfsserver.ErrCommitFinished{file.Commit}
+	}
+	return branch, commitInfo, nil
+}
+
+// deleteFiles deletes 'files'. Files on branches whose HEAD is finished are
+// deleted in one new commit per branch, rather than one commit per file.
+func (d *driver) deleteFiles(pachClient *client.APIClient, files []*pfs.File, mode errcode.BulkMode) (*errcode.BulkResponse, error) {
+	type branchDeletes struct {
+		repo, branch string
+		indexes      []int
+	}
+	var (
+		items    = make([]string, len(files))
+		errs     = make([]error, len(files))
+		open     []int // indexes of the files in open commits
+		branches = make(map[string]*branchDeletes)
+		order    []*branchDeletes
+	)
+	// Check every file before deleting any of them, so that atomic requests
+	// with an invalid file delete nothing
+	for i, file := range files {
+		items[i] = fileItem(file) // before checkDeleteFile resolves the commit
+		branch, commitInfo, err := d.checkDeleteFile(pachClient, file)
+		if err != nil {
+			if mode != errcode.BulkMode_BEST_EFFORT {
+				return nil, err
+			}
+			errs[i] = err
+			continue
 		}
-		return d.txnEnv.WithWriteContext(pachClient.Ctx(), func(txnCtx *txnenv.TransactionContext) error {
-			_, err := d.makeCommit(txnCtx, "", client.NewCommit(file.Commit.Repo.Name, ""), branch, nil, nil, nil, nil, []string{file.Path}, []*pfs.PutFileRecords{&pfs.PutFileRecords{Tombstone: true}}, "", 0)
+		if commitInfo.Finished == nil {
+			open = append(open, i)
+			continue
+		}
+		key := path.Join(file.Commit.Repo.Name, branch)
+		if branches[key] == nil {
+			branches[key] = &branchDeletes{repo: file.Commit.Repo.Name, branch: branch}
+			order = append(order, branches[key])
+		}
+		branches[key].indexes = append(branches[key].indexes, i)
+	}
+	for _, i := range open {
+		if err := d.upsertPutFileRecords(pachClient, files[i], &pfs.PutFileRecords{Tombstone: true}); err != nil {
+			if mode != errcode.BulkMode_BEST_EFFORT {
+				return nil, err
+			}
+			errs[i] = err
+		}
+	}
+	for _, b := range order {
+		var paths []string
+		var records []*pfs.PutFileRecords
+		for _, i := range b.indexes {
+			paths = append(paths, files[i].Path)
+			records = append(records, &pfs.PutFileRecords{Tombstone: true})
+		}
+		if err := d.txnEnv.WithWriteContext(pachClient.Ctx(), func(txnCtx *txnenv.TransactionContext) error {
+			_, err := d.makeCommit(txnCtx, "", client.NewCommit(b.repo, ""), b.branch, nil, nil, nil, nil, paths, records, "", 0)
 			return err
-		})
+		}); err != nil {
+			if mode != errcode.BulkMode_BEST_EFFORT {
+				return nil, err
+			}
+			for _, i := range b.indexes {
+				errs[i] = err
+			}
+		}
+	}
+	response := &errcode.BulkResponse{}
+	for i := range files {
+		response.Results = append(response.Results, errcode.NewItemResult(items[i], errs[i]))
 	}
+	return response, nil
+}
 
-	return d.upsertPutFileRecords(pachClient, file, &pfs.PutFileRecords{Tombstone: true})
+// fileItem identifies 'file' in the results of bulk requests
+func fileItem(file *pfs.File) string {
+	if file == nil || file.Commit == nil || file.Commit.Repo == nil {
+		return file.GetPath()
+	}
+	return fmt.Sprintf("%s@%s:%s", file.Commit.Repo.Name, file.Commit.ID, file.Path)
 }
 
 func (d *driver) deleteAll(txnCtx *txnenv.TransactionContext) error {
@@ -4481,7 +5217,10 @@ func (s *putFileServer) Peek() (*pfs.PutFileRequest, error) {
 	return req, nil
 }
 
-func (d *driver) forEachPutFile(pachClient *client.APIClient, server pfs.API_PutFileServer, f func(*pfs.PutFileRequest, io.Reader) error) (oneOff bool, repo string, branch string, err error) {
+// forEachPutFile calls 'f' with each file in a PutFile call and its contents.
+// If the contents of a file can't be fetched from its URL, forEachPutFile
+// calls 'itemErr', and fails if it returns an error.
+func (d *driver) forEachPutFile(pachClient *client.APIClient, server pfs.API_PutFileServer, itemErr func(*pfs.PutFileRequest, error) error, f func(*pfs.PutFileRequest, io.Reader) error) (oneOff bool, repo string, branch string, err error) {
 	limiter := limit.New(client.DefaultMaxConcurrentStreams)
 	var pr *io.PipeReader
 	var pw *io.PipeWriter
@@ -4556,10 +5295,16 @@ func (d *driver) forEachPutFile(pachClient *client.APIClient, server pfs.API_Put
 				case "https":
 					limiter.Acquire()
 					resp, err := http.Get(req.Url)
+					if err == nil && resp.StatusCode >= 400 {
+						resp.Body.Close()
+						err = fmt.Errorf("error retrieving content from %q: %s", req.Url, resp.Status)
+					}
 					if err != nil {
-						return false, "", "", err
-					} else if resp.StatusCode >= 400 {
-						return false, "", "", fmt.Errorf("error retrieving content from %q: %s", req.Url, resp.Status)
+						limiter.Release()
+						if err := itemErr(req, err); err != nil {
+							return false, "", "", err
+						}
+						continue
 					}
 					eg.Go(func() (retErr error) {
 						defer limiter.Release()
@@ -4574,11 +5319,17 @@ func (d *driver) forEachPutFile(pachClient *client.APIClient, server pfs.API_Put
 					url, err := obj.ParseURL(req.Url)
 					if err != nil {
 						err = fmt.Errorf("error parsing url %v: %v", req.Url, err)
-						return false, "", "", err
+						if err := itemErr(req, err); err != nil {
+							return false, "", "", err
+						}
+						continue
 					}
 					objClient, err := obj.NewClientFromURLAndSecret(url, false)
 					if err != nil {
-						return false, "", "", err
+						if err := itemErr(req, err); err != nil {
+							return false, "", "", err
+						}
+						continue
 					}
 					if req.Recursive {
 						path := strings.TrimPrefix(url.Object, "/")
@@ -4593,7 +5344,8 @@ func (d *driver) forEachPutFile(pachClient *client.APIClient, server pfs.API_Put
 							limiter.Acquire()
 							r, err := objClient.Reader(server.Context(), name, 0, 0)
 							if err != nil {
-								return err
+								limiter.Release()
+								return itemErr(&req, err)
 							}
 							eg.Go(func() (retErr error) {
 								defer limiter.Release()
@@ -4612,7 +5364,11 @@ func (d *driver) forEachPutFile(pachClient *client.APIClient, server pfs.API_Put
 						limiter.Acquire()
 						r, err := objClient.Reader(server.Context(), url.Object, 0, 0)
 						if err != nil {
-							return false, "", "", err
+							limiter.Release()
+							if err := itemErr(req, err); err != nil {
+								return false, "", "", err
+							}
+							continue
 						}
 						eg.Go(func() (retErr error) {
 							defer limiter.Release()
@@ -4662,3 +5418,18 @@ func (d *driver) forEachPutFile(pachClient *client.APIClient, server pfs.API_Put
 	err = eg.Wait()
 	return oneOff, repo, branch, err
 }
+
+// validateNewRepoName returns an error if 'name' can't be the name of a new
+// repo. It's in addition to ancestry.ValidateName, which all repo names pass.
+func validateNewRepoName(name string) error {
+	if len(name) > maxRepoNameLen {
+		return fmt.Errorf("repo name is %d characters long, but must have at most %d: %q",
+			len(name), maxRepoNameLen, name)
+	}
+	// PPS recreates the spec repo when all data is deleted
+	if strings.HasPrefix(name, reservedRepoPrefix) && name != ppsconsts.SpecRepo {
+		return fmt.Errorf("invalid repo name %q: names beginning with %q are reserved for Pachyderm's own repos",
+			name, reservedRepoPrefix)
+	}
+	return nil
+}
@@ -23,8 +23,8 @@ const (
 	tmpPrefix = "tmp"
 )
 
-func (d *driver) startCommitNewStorageLayer(txnCtx *txnenv.TransactionContext, id string, parent *pfs.Commit, branch string, provenance []*pfs.CommitProvenance, description string) (*pfs.Commit, error) {
-	commit, err := d.startCommit(txnCtx, id, parent, branch, provenance, description)
+func (d *driver) startCommitNewStorageLayer(txnCtx *txnenv.TransactionContext, id string, parent *pfs.Commit, branch string, provenance []*pfs.CommitProvenance, description string, metadata map[string]string) (*pfs.Commit, error) {
+	commit, err := d.startCommit(txnCtx, id, parent, branch, provenance, description, metadata)
 	if err != nil {
 		return nil, err
 	}
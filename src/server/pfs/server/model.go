@@ -0,0 +1,207 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ancestry"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	txnenv "github.com/pachyderm/pachyderm/src/server/pkg/transactionenv"
+)
+
+// Model registries let a repo's commits (typically the output commits of a
+// training pipeline) be registered as versions of a model, and labelled with
+// stages like "staging" and "production" that serving pipelines look up.
+// Each repo's registry is a single ModelInfo in etcd, so moving a stage's
+// label from one version to another is atomic.
+
+// createModelVersion registers 'commit' as a new version of the model in its
+// repo, or returns its version if it's already registered. If 'stage' is set,
+// the version is also labelled with it.
+func (d *driver) createModelVersion(pachClient *client.APIClient, commit *pfs.Commit, description string, stage string) (*pfs.ModelVersion, error) {
+	if commit == nil || commit.Repo == nil {
+		return nil, errors.New("commit repo cannot be nil")
+	}
+	if err := d.checkIsAuthorized(pachClient, commit.Repo, auth.Scope_WRITER); err != nil {
+		return nil, err
+	}
+	if stage != "" {
+		if err := validateModelStage(stage); err != nil {
+			return nil, err
+		}
+	}
+	commitInfo, err := d.inspectCommit(pachClient, commit, pfs.CommitState_STARTED)
+	if err != nil {
+		return nil, err
+	}
+	if commitInfo.Finished == nil {
+		return nil, fmt.Errorf("commit %s@%s must be finished to be a model version", commit.Repo.Name, commitInfo.Commit.ID)
+	}
+	var result *pfs.ModelVersion
+	if _, err := col.NewSTM(pachClient.Ctx(), d.etcdClient, func(stm col.STM) error {
+		modelInfo := &pfs.ModelInfo{}
+		return d.models.ReadWrite(stm).Upsert(commit.Repo.Name, modelInfo, func() error {
+			modelInfo.Repo = commitInfo.Commit.Repo
+			result = nil
+			for _, version := range modelInfo.Versions {
+				if version.Commit.ID == commitInfo.Commit.ID {
+					result = version
+				}
+			}
+			if result == nil {
+				// Versions are never renumbered, so the newest version's number
+				// is only reused if it's deleted
+				var latest uint64
+				if n := len(modelInfo.Versions); n > 0 {
+					latest = modelInfo.Versions[n-1].Version
+				}
+				result = &pfs.ModelVersion{
+					Version:     latest + 1,
+					Commit:      commitInfo.Commit,
+					Description: description,
+					Created:     now(),
+				}
+				modelInfo.Versions = append(modelInfo.Versions, result)
+			}
+			if stage != "" {
+				if modelInfo.Stages == nil {
+					modelInfo.Stages = make(map[string]uint64)
+				}
+				modelInfo.Stages[stage] = result.Version
+			}
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// promoteModel labels version 'version' of the model in 'repo' with 'stage',
+// moving the label from whichever version had it before
+func (d *driver) promoteModel(pachClient *client.APIClient, repo *pfs.Repo, version uint64, stage string) (*pfs.ModelVersion, error) {
+	if repo == nil {
+		return nil, errors.New("repo cannot be nil")
+	}
+	if err := d.checkIsAuthorized(pachClient, repo, auth.Scope_WRITER); err != nil {
+		return nil, err
+	}
+	if err := validateModelStage(stage); err != nil {
+		return nil, err
+	}
+	var result *pfs.ModelVersion
+	if _, err := col.NewSTM(pachClient.Ctx(), d.etcdClient, func(stm col.STM) error {
+		modelInfo := &pfs.ModelInfo{}
+		if err := d.models.ReadWrite(stm).Update(repo.Name, modelInfo, func() error {
+			result = modelVersion(modelInfo, version)
+			if result == nil {
+				return pfsserver.ErrModelVersionNotFound{Repo: repo, Version: version}
+			}
+			if modelInfo.Stages == nil {
+				modelInfo.Stages = make(map[string]uint64)
+			}
+			modelInfo.Stages[stage] = version
+			return nil
+		}); err != nil {
+			if col.IsErrNotFound(err) {
+				return pfsserver.ErrModelNotFound{Repo: repo}
+			}
+			return err
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// inspectModel returns the model registry of 'repo'
+func (d *driver) inspectModel(pachClient *client.APIClient, repo *pfs.Repo) (*pfs.ModelInfo, error) {
+	if repo == nil {
+		return nil, errors.New("repo cannot be nil")
+	}
+	if err := d.checkIsAuthorized(pachClient, repo, auth.Scope_READER); err != nil {
+		return nil, err
+	}
+	modelInfo := &pfs.ModelInfo{}
+	if err := d.models.ReadOnly(pachClient.Ctx()).Get(repo.Name, modelInfo); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil, pfsserver.ErrModelNotFound{Repo: repo}
+		}
+		return nil, err
+	}
+	return modelInfo, nil
+}
+
+// resolveModel returns the version of the model in 'repo' that's labelled
+// with 'stage'
+func (d *driver) resolveModel(pachClient *client.APIClient, repo *pfs.Repo, stage string) (*pfs.ModelVersion, error) {
+	modelInfo, err := d.inspectModel(pachClient, repo)
+	if err != nil {
+		return nil, err
+	}
+	version, ok := modelInfo.Stages[stage]
+	if !ok {
+		return nil, pfsserver.ErrModelStageNotFound{Repo: repo, Stage: stage}
+	}
+	if result := modelVersion(modelInfo, version); result != nil {
+		return result, nil
+	}
+	return nil, pfsserver.ErrModelVersionNotFound{Repo: repo, Version: version}
+}
+
+// deleteModelVersions removes the versions of the model in 'repo' whose
+// commits are in 'deleted', along with their stage labels
+func (d *driver) deleteModelVersions(txnCtx *txnenv.TransactionContext, repo string, deleted map[string]*pfs.CommitInfo) error {
+	modelInfo := &pfs.ModelInfo{}
+	if err := d.models.ReadWrite(txnCtx.Stm).Update(repo, modelInfo, func() error {
+		versions := modelInfo.Versions[:0]
+		removed := make(map[uint64]bool)
+		for _, version := range modelInfo.Versions {
+			if _, ok := deleted[version.Commit.ID]; ok {
+				removed[version.Version] = true
+				continue
+			}
+			versions = append(versions, version)
+		}
+		modelInfo.Versions = versions
+		for stage, version := range modelInfo.Stages {
+			if removed[version] {
+				delete(modelInfo.Stages, stage)
+			}
+		}
+		return nil
+	}); err != nil && !col.IsErrNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// modelVersion returns version 'version' of 'modelInfo', or nil if it doesn't
+// exist
+func modelVersion(modelInfo *pfs.ModelInfo, version uint64) *pfs.ModelVersion {
+	for _, v := range modelInfo.Versions {
+		if v.Version == version {
+			return proto.Clone(v).(*pfs.ModelVersion)
+		}
+	}
+	return nil
+}
+
+// validateModelStage returns an error if 'stage' can't label a model version.
+// Stages follow the same rules as branch names.
+func validateModelStage(stage string) error {
+	if stage == "" {
+		return errors.New("stage cannot be empty")
+	}
+	if err := ancestry.ValidateName(stage); err != nil {
+		return fmt.Errorf("invalid stage %q: %v", stage, err)
+	}
+	return nil
+}
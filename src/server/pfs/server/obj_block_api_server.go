@@ -27,8 +27,11 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
 	"github.com/pachyderm/pachyderm/src/client/pkg/tracing"
 	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+	"github.com/pachyderm/pachyderm/src/server/pkg/blockshare"
 	"github.com/pachyderm/pachyderm/src/server/pkg/log"
+	"github.com/pachyderm/pachyderm/src/server/pkg/mtls"
 	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+	"github.com/pachyderm/pachyderm/src/server/pkg/objgc"
 	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
 	"github.com/pachyderm/pachyderm/src/server/pkg/watch"
 )
@@ -63,6 +66,10 @@ type objBlockAPIServer struct {
 
 	objectIndexes     map[string]*pfsclient.ObjectIndex
 	objectIndexesLock sync.RWMutex
+
+	// barrier marks the objects and tags that are written young, so that GC
+	// doesn't delete them before they're referenced (see objgc)
+	barrier *objgc.Barrier
 }
 
 // newObjBlockAPIServer creates a new struct for handling Pachyderm Object API
@@ -80,11 +87,24 @@ type objBlockAPIServer struct {
 // 2. PFS storage tests, which create several local ObjBlockAPIServers (none of
 //    which are primary but cannot collide)
 func newObjBlockAPIServer(dir string, cacheBytes int64, etcdAddress string, objClient obj.Client, duplicate bool) (*objBlockAPIServer, error) {
+	// Share blocks with the other workers on this node, if this is a worker's
+	// sidecar and block sharing is enabled. Blocks are shared as they're
+	// stored (i.e. encrypted, if encryption is enabled).
+	objClient = blockshare.ObjClient(objClient, filepath.Join(dir, "block")+"/")
+	// Encrypt objects before they're uploaded, if encryption is enabled
+	objClient, err := obj.EncryptedObjClient(objClient)
+	if err != nil {
+		return nil, err
+	}
 	// defensive measure to make sure storage is working and error early if it's not
 	// this is where we'll find out if the credentials have been misconfigured
 	if err := obj.TestStorage(context.Background(), objClient); err != nil {
 		return nil, err
 	}
+	etcdClient, err := newEtcdClient(etcdAddress)
+	if err != nil {
+		return nil, err
+	}
 	oneCacheShare := cacheBytes / (objectCacheShares + tagCacheShares + objectInfoCacheShares + blockCacheShares)
 	s := &objBlockAPIServer{
 		Logger:           log.NewLogger("pfs.BlockAPI.Obj"),
@@ -92,6 +112,7 @@ func newObjBlockAPIServer(dir string, cacheBytes int64, etcdAddress string, objC
 		objClient:        objClient,
 		objectIndexes:    make(map[string]*pfsclient.ObjectIndex),
 		objectCacheBytes: oneCacheShare * objectCacheShares,
+		barrier:          objgc.NewBarrier(etcdClient),
 	}
 
 	objectGroupName := "object"
@@ -118,10 +139,28 @@ func newObjBlockAPIServer(dir string, cacheBytes int64, etcdAddress string, objC
 		RegisterCacheStats("object_info", &s.objectInfoCache.Stats)
 	}
 
-	go s.watchGC(etcdAddress)
+	go s.watchGC(etcdClient)
 	return s, nil
 }
 
+func newEtcdClient(etcdAddress string) (*etcd.Client, error) {
+	internalTLS, err := mtls.Internal()
+	if err != nil {
+		return nil, err
+	}
+	etcdClient, err := etcd.New(etcd.Config{
+		Endpoints:          []string{etcdAddress},
+		TLS:                internalTLS.ClientTLSConfig(),
+		DialOptions:        client.DefaultDialOptions(),
+		MaxCallSendMsgSize: math.MaxInt32,
+		MaxCallRecvMsgSize: math.MaxInt32,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error instantiating etcd client: %v", err)
+	}
+	return etcdClient, nil
+}
+
 // prettyObjPath renders an object hash as a path, for more readable traces
 // and logs
 func (s *objBlockAPIServer) prettyObjPath(obj *pfsclient.Object) string {
@@ -132,19 +171,9 @@ func (s *objBlockAPIServer) prettyObjPath(obj *pfsclient.Object) string {
 }
 
 // watchGC watches for GC runs and invalidate all cache when GC happens.
-func (s *objBlockAPIServer) watchGC(etcdAddress string) {
+func (s *objBlockAPIServer) watchGC(etcdClient *etcd.Client) {
 	b := backoff.NewInfiniteBackOff()
 	backoff.RetryNotify(func() error {
-		etcdClient, err := etcd.New(etcd.Config{
-			Endpoints:          []string{etcdAddress},
-			DialOptions:        client.DefaultDialOptions(),
-			MaxCallSendMsgSize: math.MaxInt32,
-			MaxCallRecvMsgSize: math.MaxInt32,
-		})
-		if err != nil {
-			return fmt.Errorf("error instantiating etcd client: %v", err)
-		}
-
 		watcher, err := watch.NewWatcher(context.Background(), etcdClient, "", client.GCGenerationKey, nil)
 		if err != nil {
 			return fmt.Errorf("error instantiating watch stream from generation number: %v", err)
@@ -217,6 +246,14 @@ func newMicrosoftBlockAPIServer(dir string, cacheBytes int64, etcdAddress string
 	return newObjBlockAPIServer(dir, cacheBytes, etcdAddress, objClient, duplicate)
 }
 
+func newADLSBlockAPIServer(dir string, cacheBytes int64, etcdAddress string, duplicate bool) (*objBlockAPIServer, error) {
+	objClient, err := obj.NewADLSClientFromSecret("")
+	if err != nil {
+		return nil, err
+	}
+	return newObjBlockAPIServer(dir, cacheBytes, etcdAddress, objClient, duplicate)
+}
+
 func newLocalBlockAPIServer(dir string, cacheBytes int64, etcdAddress string, duplicate bool) (*objBlockAPIServer, error) {
 	objClient, err := obj.NewLocalClient(dir)
 	if err != nil {
@@ -251,6 +288,9 @@ func (s *objBlockAPIServer) PutObject(server pfsclient.ObjectAPI_PutObjectServer
 	if err != nil {
 		return err
 	}
+	if err := s.touchTags(server.Context(), putObjectReader.tags); err != nil {
+		return err
+	}
 	var eg errgroup.Group
 	for _, tag := range putObjectReader.tags {
 		tag := tag
@@ -320,6 +360,10 @@ func (s *objBlockAPIServer) putObject(ctx context.Context, dataReader io.Reader,
 	}
 	object := &pfsclient.Object{Hash: pfsclient.EncodeHash(hash.Sum(nil))}
 	// Now that we have a hash of the object we can check if it already exists.
+	// It's touched first, so that GC can't delete it once we've seen it.
+	if err := s.barrier.Touch(ctx, objgc.ObjectKey(object)); err != nil {
+		return nil, err
+	}
 	resp, err := s.CheckObject(ctx, &pfsclient.CheckObjectRequest{Object: object})
 	if err != nil {
 		return nil, err
@@ -382,6 +426,9 @@ func (s *objBlockAPIServer) PutObjects(server pfsclient.ObjectAPI_PutObjectsServ
 func (s *objBlockAPIServer) CreateObject(ctx context.Context, request *pfsclient.CreateObjectRequest) (response *types.Empty, retErr error) {
 	func() { s.Log(request, nil, nil, 0) }()
 	defer func(start time.Time) { s.Log(request, nil, retErr, time.Since(start)) }(time.Now())
+	if err := s.barrier.Touch(ctx, objgc.ObjectKey(request.Object)); err != nil {
+		return nil, err
+	}
 	if err := s.writeProto(ctx, s.objectPath(request.Object), request.BlockRef); err != nil {
 		return nil, err
 	}
@@ -459,7 +506,7 @@ func (s *objBlockAPIServer) GetObjects(request *pfsclient.GetObjectsRequest, get
 		}
 
 		objectSize := objectInfo.BlockRef.Range.Upper - objectInfo.BlockRef.Range.Lower
-		if offset > objectSize {
+		if offset >= objectSize {
 			offset -= objectSize
 			continue
 		}
@@ -508,6 +555,9 @@ func (s *objBlockAPIServer) TagObject(ctx context.Context, request *pfsclient.Ta
 	func() { s.Log(request, nil, nil, 0) }()
 	defer func(start time.Time) { s.Log(request, response, retErr, time.Since(start)) }(time.Now())
 	// First inspect the object to make sure it actually exists
+	if err := s.barrier.Touch(ctx, objgc.ObjectKey(request.Object)); err != nil {
+		return nil, err
+	}
 	resp, err := s.CheckObject(ctx, &pfsclient.CheckObjectRequest{Object: request.Object})
 	if err != nil {
 		return nil, err
@@ -515,6 +565,9 @@ func (s *objBlockAPIServer) TagObject(ctx context.Context, request *pfsclient.Ta
 	if !resp.Exists {
 		return nil, fmt.Errorf("object %v does not exist", request.Object)
 	}
+	if err := s.touchTags(ctx, request.Tags); err != nil {
+		return nil, err
+	}
 	var eg errgroup.Group
 	for _, tag := range request.Tags {
 		tag := tag
@@ -529,6 +582,16 @@ func (s *objBlockAPIServer) TagObject(ctx context.Context, request *pfsclient.Ta
 	return &types.Empty{}, nil
 }
 
+// touchTags marks 'tags' young before they're written, so that GC doesn't
+// delete them before they're read (see objgc)
+func (s *objBlockAPIServer) touchTags(ctx context.Context, tags []*pfsclient.Tag) error {
+	var keys []string
+	for _, tag := range tags {
+		keys = append(keys, objgc.TagKey(tag))
+	}
+	return s.barrier.Touch(ctx, keys...)
+}
+
 func (s *objBlockAPIServer) InspectObject(ctx context.Context, request *pfsclient.Object) (response *pfsclient.ObjectInfo, retErr error) {
 	func() { s.Log(request, nil, nil, 0) }()
 	defer func(start time.Time) {
@@ -711,7 +774,7 @@ func (s *objBlockAPIServer) GetBlocks(request *pfsclient.GetBlocksRequest, getBl
 	size := request.SizeBytes
 	for _, blockRef := range request.BlockRefs {
 		blockSize := blockRef.Range.Upper - blockRef.Range.Lower
-		if offset > blockSize {
+		if offset >= blockSize {
 			offset -= blockSize
 			continue
 		}
@@ -1000,7 +1063,7 @@ func (s *objBlockAPIServer) writeProto(ctx context.Context, path string, pb prot
 	b.MaxInterval = 10 * time.Second
 	return backoff.RetryNotify(func() error {
 		return s.writeInternal(ctx, path, data)
-	}, b, func(err error, duration time.Duration) error {
+	}, backoff.WithContext(b, ctx), func(err error, duration time.Duration) error {
 		logrus.Errorf("could not write proto: %v, retrying in %v", err, duration)
 		return nil
 	})
@@ -1173,7 +1236,7 @@ func (s *objBlockAPIServer) readObj(ctx context.Context, path string, offset uin
 			return err
 		}
 		return nil
-	}, obj.NewExponentialBackOffConfig(), func(err error, d time.Duration) error {
+	}, backoff.WithContext(obj.NewExponentialBackOffConfig(), ctx), func(err error, d time.Duration) error {
 		logrus.Infof("Error creating reader; retrying in %s: %#v", d, obj.RetryError{
 			Err:               err.Error(),
 			TimeTillNextRetry: d.String(),
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"path"
+	"strings"
+
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+)
+
+// readFilter restricts the files that a user may read in a repo to the files
+// under a set of path prefixes (see auth.ACL.ReadPaths). A nil readFilter
+// permits reading any file.
+type readFilter struct {
+	subject  string
+	repo     string
+	prefixes []string
+}
+
+func newReadFilter(subject, repo string, prefixes []string) *readFilter {
+	if len(prefixes) == 0 {
+		return nil
+	}
+	return &readFilter{subject: subject, repo: repo, prefixes: prefixes}
+}
+
+// canRead returns true if the file at 'p' may be read
+func (f *readFilter) canRead(p string) bool {
+	if f == nil {
+		return true
+	}
+	p = path.Clean("/" + p)
+	for _, prefix := range f.prefixes {
+		if prefix == "/" || p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// canSee returns true if the file at 'p' may be read, or if it's one of the
+// directories that contain the readable files (so that they can be found by
+// e.g. ListFile).
+func (f *readFilter) canSee(p string) bool {
+	if f.canRead(p) {
+		return true
+	}
+	p = path.Clean("/" + p)
+	for _, prefix := range f.prefixes {
+		if p == "/" || strings.HasPrefix(prefix, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRead returns an error if the file at 'p' may not be read
+func (f *readFilter) checkRead(p string) error {
+	if f.canRead(p) {
+		return nil
+	}
+	return &auth.ErrNotAuthorized{
+		Subject:  f.subject,
+		Repo:     f.repo,
+		Required: auth.Scope_READER,
+		Path:     p,
+	}
+}
+
+// filter wraps 'cb' so that it's only called on the files that may be seen
+func (f *readFilter) filter(cb func(*pfs.FileInfo) error) func(*pfs.FileInfo) error {
+	if f == nil {
+		return cb
+	}
+	return func(fileInfo *pfs.FileInfo) error {
+		if !f.canSee(fileInfo.File.Path) {
+			return nil
+		}
+		return cb(fileInfo)
+	}
+}
+
+// filterDiff wraps 'cb' so that it's only called with the files that may be
+// seen ('newFilter' applies to new files and 'oldFilter' to old files). Files
+// that may not be seen are removed from each diff.
+func filterDiff(newFilter, oldFilter *readFilter, cb func(*pfs.FileDiff) error) func(*pfs.FileDiff) error {
+	if newFilter == nil && oldFilter == nil {
+		return cb
+	}
+	return func(diff *pfs.FileDiff) error {
+		if diff.NewFile != nil && !newFilter.canSee(diff.NewFile.File.Path) {
+			diff.NewFile, diff.Renamed = nil, false
+		}
+		if diff.OldFile != nil && !oldFilter.canSee(diff.OldFile.File.Path) {
+			diff.OldFile, diff.Renamed = nil, false
+		}
+		if diff.NewFile == nil && diff.OldFile == nil {
+			return nil
+		}
+		return cb(diff)
+	}
+}
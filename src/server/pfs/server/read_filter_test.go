@@ -0,0 +1,62 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestReadFilter(t *testing.T) {
+	var unrestricted *readFilter
+	require.True(t, unrestricted.canRead("/any/file"))
+	require.NoError(t, unrestricted.checkRead("/any/file"))
+	require.True(t, newReadFilter("github:carol", "repo", nil) == nil)
+
+	f := newReadFilter("github:carol", "repo", []string{"/a/b", "/c"})
+	require.True(t, f.canRead("/a/b"))
+	require.True(t, f.canRead("a/b/file"))
+	require.True(t, f.canRead("/c/d/e"))
+	require.False(t, f.canRead("/a"))
+	require.False(t, f.canRead("/a/bc"))
+	require.False(t, f.canRead("/d"))
+	require.True(t, f.canSee("/"))
+	require.True(t, f.canSee("/a"))
+	require.False(t, f.canSee("/a/c"))
+	require.False(t, f.canSee("/d"))
+
+	err := f.checkRead("/d")
+	require.YesError(t, err)
+	require.True(t, auth.IsErrNotAuthorized(err))
+
+	var seen []string
+	cb := f.filter(func(fileInfo *pfs.FileInfo) error {
+		seen = append(seen, fileInfo.File.Path)
+		return nil
+	})
+	for _, p := range []string{"/a", "/a/b/1", "/a/c", "/d"} {
+		require.NoError(t, cb(&pfs.FileInfo{File: &pfs.File{Path: p}}))
+	}
+	require.Equal(t, []string{"/a", "/a/b/1"}, seen)
+}
+
+func TestFilterDiff(t *testing.T) {
+	f := newReadFilter("github:carol", "repo", []string{"/a"})
+	var diffs []*pfs.FileDiff
+	cb := filterDiff(f, nil, func(diff *pfs.FileDiff) error {
+		diffs = append(diffs, diff)
+		return nil
+	})
+	fileInfo := func(p string) *pfs.FileInfo {
+		return &pfs.FileInfo{File: &pfs.File{Path: p}}
+	}
+	// A file renamed out of the readable prefix is shown as deleted...
+	require.NoError(t, cb(&pfs.FileDiff{NewFile: fileInfo("/b/1"), OldFile: fileInfo("/a/1"), Renamed: true}))
+	// ...and new files outside of it are hidden
+	require.NoError(t, cb(&pfs.FileDiff{NewFile: fileInfo("/b/2")}))
+	require.Equal(t, 1, len(diffs))
+	require.True(t, diffs[0].NewFile == nil)
+	require.False(t, diffs[0].Renamed)
+	require.Equal(t, "/a/1", diffs[0].OldFile.File.Path)
+}
@@ -0,0 +1,18 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
+)
+
+func TestValidateNewRepoName(t *testing.T) {
+	require.NoError(t, validateNewRepoName("images"))
+	require.NoError(t, validateNewRepoName("my_repo__v2"))
+	require.NoError(t, validateNewRepoName(ppsconsts.SpecRepo))
+	require.NoError(t, validateNewRepoName(strings.Repeat("a", maxRepoNameLen)))
+	require.YesError(t, validateNewRepoName(strings.Repeat("a", maxRepoNameLen+1)))
+	require.YesError(t, validateNewRepoName("__internal"))
+}
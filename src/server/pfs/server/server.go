@@ -13,6 +13,7 @@ const (
 	AmazonBackendEnvVar    = "AMAZON"
 	GoogleBackendEnvVar    = "GOOGLE"
 	MicrosoftBackendEnvVar = "MICROSOFT"
+	ADLSBackendEnvVar      = "ADLS"
 	LocalBackendEnvVar     = "LOCAL"
 )
 
@@ -78,6 +79,12 @@ func NewBlockAPIServer(dir string, cacheBytes int64, backend string, etcdAddress
 			return nil, err
 		}
 		return blockAPIServer, nil
+	case ADLSBackendEnvVar:
+		blockAPIServer, err := newADLSBlockAPIServer(dir, cacheBytes, etcdAddress, duplicate)
+		if err != nil {
+			return nil, err
+		}
+		return blockAPIServer, nil
 	case LocalBackendEnvVar:
 		fallthrough
 	default:
@@ -22,7 +22,9 @@ import (
 	"github.com/gogo/protobuf/types"
 	pclient "github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errcode"
 	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ancestry"
 	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
 	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
@@ -31,6 +33,9 @@ import (
 	pfssync "github.com/pachyderm/pachyderm/src/server/pkg/sync"
 	tu "github.com/pachyderm/pachyderm/src/server/pkg/testutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/writer"
 
 	"golang.org/x/net/context"
 	"golang.org/x/sync/errgroup"
@@ -2485,6 +2490,74 @@ func TestGetFile(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestGetFileRange(t *testing.T) {
+	t.Parallel()
+	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
+		repo := tu.UniqueString("TestGetFileRange")
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		commit, err := env.PachClient.StartCommit(repo, "")
+		require.NoError(t, err)
+		// Each put adds a separate chunk to the file
+		for _, data := range []string{"foo\n", "bar\n", "buzz\n"} {
+			_, err = env.PachClient.PutFile(repo, commit.ID, "file", strings.NewReader(data))
+			require.NoError(t, err)
+		}
+		require.NoError(t, env.PachClient.FinishCommit(repo, commit.ID))
+
+		for _, c := range []struct {
+			offset, size int64
+			expected     string
+		}{
+			{0, 0, "foo\nbar\nbuzz\n"},
+			{4, 4, "bar\n"}, // starts and ends on chunk boundaries
+			{2, 5, "o\nbar"},
+			{8, 0, "buzz\n"},
+			{10, 100, "zz\n"},
+			{13, 0, ""},
+		} {
+			var buf bytes.Buffer
+			require.NoError(t, env.PachClient.GetFile(repo, commit.ID, "file", c.offset, c.size, &buf))
+			require.Equal(t, c.expected, buf.String())
+		}
+		require.YesError(t, env.PachClient.GetFile(repo, commit.ID, "file", -1, 0, &bytes.Buffer{}))
+
+		r, size, err := env.PachClient.GetFileReaderAt(repo, commit.ID, "file")
+		require.NoError(t, err)
+		require.Equal(t, int64(13), size)
+		p := make([]byte, 3)
+		n, err := r.ReadAt(p, 10)
+		require.NoError(t, err)
+		require.Equal(t, "zz\n", string(p[:n]))
+		p = make([]byte, 5)
+		n, err = r.ReadAt(p, 10)
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, "zz\n", string(p[:n]))
+
+		rs, err := env.PachClient.GetFileReadSeeker(repo, commit.ID, "file")
+		require.NoError(t, err)
+		offset, err := rs.Seek(-5, io.SeekEnd)
+		require.NoError(t, err)
+		require.Equal(t, int64(8), offset)
+		data, err := ioutil.ReadAll(rs)
+		require.NoError(t, err)
+		require.Equal(t, "buzz\n", string(data))
+		_, err = rs.Seek(4, io.SeekStart)
+		require.NoError(t, err)
+		p = make([]byte, 3)
+		_, err = io.ReadFull(rs, p)
+		require.NoError(t, err)
+		require.Equal(t, "bar", string(p))
+		offset, err = rs.Seek(1, io.SeekCurrent)
+		require.NoError(t, err)
+		require.Equal(t, int64(8), offset)
+		_, err = io.ReadFull(rs, p)
+		require.NoError(t, err)
+		require.Equal(t, "buz", string(p))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
 func TestManyPutsSingleFileSingleCommit(t *testing.T) {
 	t.Parallel()
 	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
@@ -3218,6 +3291,404 @@ func TestPutFileSplitCSV(t *testing.T) {
 	require.NoError(t, err)
 }
 
+type parquetTestRow struct {
+	ID   int64  `parquet:"name=id, type=INT64"`
+	Name string `parquet:"name=name, type=UTF8"`
+}
+
+func TestPutFileSplitParquet(t *testing.T) {
+	t.Parallel()
+	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
+		repo := tu.UniqueString("TestPutFileSplitParquet")
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+
+		// Write a parquet file with two row groups
+		file, err := buffer.NewBufferFile(nil)
+		require.NoError(t, err)
+		pw, err := writer.NewParquetWriter(file, new(parquetTestRow), 1)
+		require.NoError(t, err)
+		require.NoError(t, pw.Write(parquetTestRow{ID: 1, Name: "a"}))
+		require.NoError(t, pw.Write(parquetTestRow{ID: 2, Name: "b"}))
+		require.NoError(t, pw.Flush(true))
+		require.NoError(t, pw.Write(parquetTestRow{ID: 3, Name: "c"}))
+		require.NoError(t, pw.WriteStop())
+
+		_, err = env.PachClient.PutFileSplit(repo, "master", "/data", pfs.Delimiter_PARQUET, 1, 0, 0, false,
+			bytes.NewReader(file.(buffer.BufferFile).Bytes()))
+		require.NoError(t, err)
+		fileInfos, err := env.PachClient.ListFile(repo, "master", "/data")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(fileInfos))
+
+		// Each split file is a complete parquet file
+		readRows := func(path string) []parquetTestRow {
+			var contents bytes.Buffer
+			require.NoError(t, env.PachClient.GetFile(repo, "master", path, 0, 0, &contents))
+			file, err := buffer.NewBufferFile(contents.Bytes())
+			require.NoError(t, err)
+			pr, err := reader.NewParquetReader(file, new(parquetTestRow), 1)
+			require.NoError(t, err)
+			defer pr.ReadStop()
+			rows := make([]parquetTestRow, pr.GetNumRows())
+			require.NoError(t, pr.Read(&rows))
+			return rows
+		}
+		require.Equal(t, []parquetTestRow{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}, readRows("/data/0000000000000000"))
+		require.Equal(t, []parquetTestRow{{ID: 3, Name: "c"}}, readRows("/data/0000000000000001"))
+
+		// Header records can't be used with parquet
+		_, err = env.PachClient.PutFileSplit(repo, "master", "/data", pfs.Delimiter_PARQUET, 1, 0, 1, false,
+			bytes.NewReader(file.(buffer.BufferFile).Bytes()))
+		require.YesError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+// avroTestFile returns an avro file of longs with the sync marker 'sync',
+// and one block for each of 'values' (each of which must be in [0, 64))
+func avroTestFile(sync string, values ...byte) (header []byte, blocks []byte) {
+	header = append([]byte("Obj\x01\x02\x16avro.schema\x0c\"long\"\x00"), sync...)
+	for _, v := range values {
+		blocks = append(blocks, 2, 2, 2*v)
+		blocks = append(blocks, sync...)
+	}
+	return header, blocks
+}
+
+func TestPutFileSplitAvro(t *testing.T) {
+	t.Parallel()
+	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
+		repo := tu.UniqueString("TestPutFileSplitAvro")
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+
+		header, blocks := avroTestFile("0123456789abcdef", 1, 2)
+		_, err := env.PachClient.PutFileSplit(repo, "master", "/data", pfs.Delimiter_AVRO, 0, 0, 0, false,
+			bytes.NewReader(append(append([]byte{}, header...), blocks...)))
+		require.NoError(t, err)
+		fileInfos, err := env.PachClient.ListFile(repo, "master", "/data")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(fileInfos))
+
+		// Each split file, and all of them together, is a valid avro file
+		var contents bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "/data/0000000000000001", 0, 0, &contents))
+		_, second := avroTestFile("0123456789abcdef", 2)
+		require.Equal(t, append(append([]byte{}, header...), second...), contents.Bytes())
+
+		// Appending a file with a different sync marker converts its blocks to
+		// use the existing header's
+		appendedHeader, appended := avroTestFile("fedcba9876543210", 3)
+		_, err = env.PachClient.PutFileSplit(repo, "master", "/data", pfs.Delimiter_AVRO, 0, 0, 0, false,
+			bytes.NewReader(append(appendedHeader, appended...)))
+		require.NoError(t, err)
+		contents.Reset()
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "/data/*", 0, 0, &contents))
+		_, all := avroTestFile("0123456789abcdef", 1, 2, 3)
+		require.Equal(t, append(append([]byte{}, header...), all...), contents.Bytes())
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestPutFileBestEffort(t *testing.T) {
+	t.Parallel()
+	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
+		repo := tu.UniqueString("TestPutFileBestEffort")
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		badURL := "http://127.0.0.1:1/nothing"
+
+		// An atomic put with a bad URL puts nothing
+		pfc, err := env.PachClient.NewPutFileClient()
+		require.NoError(t, err)
+		_, err = pfc.PutFile(repo, "master", "foo", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		require.NoError(t, pfc.PutFileURL(repo, "master", "url", badURL, false, false))
+		require.YesError(t, pfc.Close())
+		_, err = env.PachClient.InspectFile(repo, "master", "foo")
+		require.YesError(t, err)
+
+		// A best-effort put puts the other files, and reports the bad URL
+		pfc, err = env.PachClient.NewPutFileClientWithMode(errcode.BulkMode_BEST_EFFORT)
+		require.NoError(t, err)
+		_, err = pfc.PutFile(repo, "master", "foo", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		require.NoError(t, pfc.PutFileURL(repo, "master", "url", badURL, false, false))
+		_, err = pfc.PutFile(repo, "master", "bar", strings.NewReader("bar\n"))
+		require.NoError(t, err)
+		err = pfc.Close()
+		require.YesError(t, err)
+		bulkErr, ok := err.(*errcode.BulkError)
+		require.True(t, ok)
+		require.Equal(t, 3, bulkErr.Total)
+		require.Equal(t, 1, len(bulkErr.Failed))
+		require.Equal(t, "url", bulkErr.Failed[0].Item)
+
+		fileInfos, err := env.PachClient.ListFile(repo, "master", "")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(fileInfos))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestDeleteFiles(t *testing.T) {
+	t.Parallel()
+	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
+		repo := tu.UniqueString("TestDeleteFiles")
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		commit1, err := env.PachClient.StartCommit(repo, "master")
+		require.NoError(t, err)
+		for _, name := range []string{"foo", "bar", "baz"} {
+			_, err = env.PachClient.PutFile(repo, commit1.ID, name, strings.NewReader(name+"\n"))
+			require.NoError(t, err)
+		}
+		require.NoError(t, env.PachClient.FinishCommit(repo, commit1.ID))
+
+		// Files can't be deleted from finished commits that aren't named by a
+		// branch, so an atomic request with one of those deletes nothing
+		_, err = env.PachClient.DeleteFiles(errcode.BulkMode_ATOMIC,
+			pclient.NewFile(repo, "master", "foo"),
+			pclient.NewFile(repo, commit1.ID, "bar"))
+		require.YesError(t, err)
+		fileInfos, err := env.PachClient.ListFile(repo, "master", "")
+		require.NoError(t, err)
+		require.Equal(t, 3, len(fileInfos))
+
+		// A best-effort request deletes the files that it can
+		response, err := env.PachClient.DeleteFiles(errcode.BulkMode_BEST_EFFORT,
+			pclient.NewFile(repo, "master", "foo"),
+			pclient.NewFile(repo, commit1.ID, "bar"))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(response.Results))
+		failed := response.Failed()
+		require.Equal(t, 1, len(failed))
+		require.Equal(t, fmt.Sprintf("%s@%s:bar", repo, commit1.ID), failed[0].Item)
+		require.YesError(t, response.Err())
+		_, err = env.PachClient.InspectFile(repo, "master", "foo")
+		require.YesError(t, err)
+
+		// Files on the same branch are deleted in a single commit
+		response, err = env.PachClient.DeleteFiles(errcode.BulkMode_ATOMIC,
+			pclient.NewFile(repo, "master", "bar"),
+			pclient.NewFile(repo, "master", "baz"))
+		require.NoError(t, err)
+		require.NoError(t, response.Err())
+		fileInfos, err = env.PachClient.ListFile(repo, "master", "")
+		require.NoError(t, err)
+		require.Equal(t, 0, len(fileInfos))
+		commitInfos, err := env.PachClient.ListCommit(repo, "master", "", 0)
+		require.NoError(t, err)
+		require.Equal(t, 3, len(commitInfos))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestUpload(t *testing.T) {
+	t.Parallel()
+	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
+		repo := tu.UniqueString("TestUpload")
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		_, err := env.PachClient.PutFile(repo, "master", "file", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+
+		upload, err := env.PachClient.StartUpload(repo, "master", "file", false)
+		require.NoError(t, err)
+		uploadInfo, err := env.PachClient.UploadChunk(upload, 0, strings.NewReader("bar\n"))
+		require.NoError(t, err)
+		require.Equal(t, uint64(4), uploadInfo.SizeBytes)
+		// A chunk that doesn't start at the end of the upload is rejected
+		_, err = env.PachClient.UploadChunk(upload, 0, strings.NewReader("bar\n"))
+		require.YesError(t, err)
+		require.True(t, errcode.IsConflict(err))
+		_, err = env.PachClient.UploadChunk(upload, 4, strings.NewReader("buzz\n"))
+		require.NoError(t, err)
+		uploadInfo, err = env.PachClient.InspectUpload(upload)
+		require.NoError(t, err)
+		require.Equal(t, uint64(9), uploadInfo.SizeBytes)
+		require.Equal(t, "file", uploadInfo.File.Path)
+
+		// Nothing is put until the upload is finished
+		var buf bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "file", 0, 0, &buf))
+		require.Equal(t, "foo\n", buf.String())
+		require.NoError(t, env.PachClient.FinishUpload(upload))
+		buf.Reset()
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "file", 0, 0, &buf))
+		require.Equal(t, "foo\nbar\nbuzz\n", buf.String())
+
+		// The session ends once the upload is finished
+		err = env.PachClient.FinishUpload(upload)
+		require.YesError(t, err)
+		require.True(t, errcode.IsNotFound(err))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestUploadOverwrite(t *testing.T) {
+	t.Parallel()
+	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
+		repo := tu.UniqueString("TestUploadOverwrite")
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		_, err := env.PachClient.PutFile(repo, "master", "file", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+
+		// Uploads into the same branch are put in one commit
+		upload1, err := env.PachClient.StartUpload(repo, "master", "file", true)
+		require.NoError(t, err)
+		_, err = env.PachClient.UploadChunk(upload1, 0, strings.NewReader("bar\n"))
+		require.NoError(t, err)
+		upload2, err := env.PachClient.StartUpload(repo, "master", "dir/file", true)
+		require.NoError(t, err)
+		_, err = env.PachClient.UploadChunk(upload2, 0, strings.NewReader("buzz\n"))
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.FinishUpload(upload1, upload2))
+
+		var buf bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "file", 0, 0, &buf))
+		require.Equal(t, "bar\n", buf.String())
+		buf.Reset()
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "dir/file", 0, 0, &buf))
+		require.Equal(t, "buzz\n", buf.String())
+		commitInfos, err := env.PachClient.ListCommit(repo, "master", "", 0)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(commitInfos))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestUploadFileResume(t *testing.T) {
+	t.Parallel()
+	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
+		repo := tu.UniqueString("TestUploadFileResume")
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		commit, err := env.PachClient.StartCommit(repo, "master")
+		require.NoError(t, err)
+
+		data := []byte(strings.Repeat("data\n", 1000))
+		upload, err := env.PachClient.StartUpload(repo, commit.ID, "file", false)
+		require.NoError(t, err)
+		// Upload part of the file, as if the upload had been interrupted
+		_, err = env.PachClient.UploadChunk(upload, 0, bytes.NewReader(data[:1234]))
+		require.NoError(t, err)
+		var progress []uint64
+		require.NoError(t, env.PachClient.UploadFile(upload, bytes.NewReader(data), int64(len(data)), func(n uint64) {
+			progress = append(progress, n)
+		}))
+		require.Equal(t, []uint64{1234, uint64(len(data))}, progress)
+		require.NoError(t, env.PachClient.FinishUpload(upload))
+		require.NoError(t, env.PachClient.FinishCommit(repo, commit.ID))
+
+		var buf bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile(repo, commit.ID, "file", 0, 0, &buf))
+		require.Equal(t, data, buf.Bytes())
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestDeleteUpload(t *testing.T) {
+	t.Parallel()
+	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
+		repo := tu.UniqueString("TestDeleteUpload")
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		_, err := env.PachClient.StartUpload(tu.UniqueString("nonexistent"), "master", "file", false)
+		require.YesError(t, err)
+		require.True(t, errcode.IsNotFound(err))
+
+		upload, err := env.PachClient.StartUpload(repo, "master", "file", false)
+		require.NoError(t, err)
+		_, err = env.PachClient.UploadChunk(upload, 0, strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.DeleteUpload(upload))
+		_, err = env.PachClient.InspectUpload(upload)
+		require.YesError(t, err)
+		require.True(t, errcode.IsNotFound(err))
+		require.YesError(t, env.PachClient.FinishUpload(upload))
+		_, err = env.PachClient.InspectFile(repo, "master", "file")
+		require.YesError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestModel(t *testing.T) {
+	t.Parallel()
+	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
+		repo := tu.UniqueString("TestModel")
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		_, err := env.PachClient.InspectModel(repo)
+		require.YesError(t, err)
+		require.True(t, errcode.IsNotFound(err))
+
+		// Only finished commits can be model versions
+		commit1, err := env.PachClient.StartCommit(repo, "master")
+		require.NoError(t, err)
+		_, err = env.PachClient.CreateModelVersion(repo, commit1.ID, "", "")
+		require.YesError(t, err)
+		require.NoError(t, env.PachClient.FinishCommit(repo, commit1.ID))
+		v1, err := env.PachClient.CreateModelVersion(repo, commit1.ID, "first", "production")
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), v1.Version)
+		// Registering the same commit again returns its version
+		v, err := env.PachClient.CreateModelVersion(repo, "master", "", "")
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), v.Version)
+
+		_, err = env.PachClient.PutFile(repo, "master", "file", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		v2, err := env.PachClient.CreateModelVersion(repo, "master", "second", "staging")
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), v2.Version)
+
+		resolved, err := env.PachClient.ResolveModel(repo, "production")
+		require.NoError(t, err)
+		require.Equal(t, commit1.ID, resolved.Commit.ID)
+		_, err = env.PachClient.ResolveModel(repo, "canary")
+		require.YesError(t, err)
+		require.True(t, errcode.IsNotFound(err))
+
+		// Promoting a version moves the stage's label to it
+		_, err = env.PachClient.PromoteModel(repo, 2, "production")
+		require.NoError(t, err)
+		resolved, err = env.PachClient.ResolveModel(repo, "production")
+		require.NoError(t, err)
+		require.Equal(t, v2.Commit.ID, resolved.Commit.ID)
+		_, err = env.PachClient.PromoteModel(repo, 3, "production")
+		require.YesError(t, err)
+		require.True(t, errcode.IsNotFound(err))
+		_, err = env.PachClient.PromoteModel(repo, 1, "bad stage")
+		require.YesError(t, err)
+
+		modelInfo, err := env.PachClient.InspectModel(repo)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(modelInfo.Versions))
+		require.Equal(t, "first", modelInfo.Versions[0].Description)
+		require.Equal(t, map[string]uint64{"production": 2, "staging": 2}, modelInfo.Stages)
+
+		// Deleting a commit deletes its version and the stages that label it
+		require.NoError(t, env.PachClient.DeleteCommit(repo, v2.Commit.ID))
+		modelInfo, err = env.PachClient.InspectModel(repo)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(modelInfo.Versions))
+		require.Equal(t, 0, len(modelInfo.Stages))
+		_, err = env.PachClient.ResolveModel(repo, "production")
+		require.YesError(t, err)
+
+		// Deleting the repo deletes its model
+		require.NoError(t, env.PachClient.DeleteRepo(repo, false))
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		_, err = env.PachClient.InspectModel(repo)
+		require.YesError(t, err)
+		require.True(t, errcode.IsNotFound(err))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
 func TestPutFileSplitSQL(t *testing.T) {
 	t.Parallel()
 	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
@@ -5232,6 +5703,61 @@ func TestSubscribeStates(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestWatchCommitAndBranch(t *testing.T) {
+	t.Parallel()
+	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
+		repo := "repo"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		_, err := env.PachClient.StartCommit(repo, "master")
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.FinishCommit(repo, "master"))
+
+		ctx, cancel := context.WithCancel(env.PachClient.Ctx())
+		defer cancel()
+		client := env.PachClient.WithCtx(ctx)
+
+		// Existing commits are sent first, then new ones and their updates
+		var masterCommits, otherCommits, branches int64
+		go func() {
+			client.WatchCommit(repo, "master", func(ci *pfs.CommitInfo) error {
+				if ci.Branch.Name == "master" {
+					atomic.AddInt64(&masterCommits, 1)
+				} else {
+					atomic.AddInt64(&otherCommits, 1)
+				}
+				return nil
+			})
+		}()
+		go func() {
+			client.WatchBranch(repo, func(bi *pfs.BranchInfo) error {
+				atomic.AddInt64(&branches, 1)
+				return nil
+			})
+		}()
+		require.NoErrorWithinTRetry(t, time.Second*10, func() error {
+			if atomic.LoadInt64(&masterCommits) != 1 || atomic.LoadInt64(&branches) != 1 {
+				return fmt.Errorf("wrong number of commits or branches")
+			}
+			return nil
+		})
+
+		_, err = client.StartCommit(repo, "dev")
+		require.NoError(t, err)
+		require.NoError(t, client.FinishCommit(repo, "dev"))
+		_, err = client.StartCommit(repo, "master")
+		require.NoError(t, err)
+		require.NoErrorWithinTRetry(t, time.Second*10, func() error {
+			if atomic.LoadInt64(&masterCommits) < 2 || atomic.LoadInt64(&branches) < 2 {
+				return fmt.Errorf("watch missed new commits or branches")
+			}
+			return nil
+		})
+		require.Equal(t, int64(0), atomic.LoadInt64(&otherCommits))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
 func TestPutFileCommit(t *testing.T) {
 	t.Parallel()
 	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
@@ -5619,6 +6145,258 @@ func TestUpdateRepo(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestGetStorageUsage(t *testing.T) {
+	t.Parallel()
+	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
+		require.NoError(t, env.PachClient.CreateRepo("a"))
+		require.NoError(t, env.PachClient.CreateRepo("b"))
+		content := strings.Repeat("x", 1000)
+		_, err := env.PachClient.PutFile("a", "master", "foo", strings.NewReader(content))
+		require.NoError(t, err)
+		// A second commit that adds a copy of foo references the same data
+		require.NoError(t, env.PachClient.CopyFile("a", "master", "foo", "a", "master", "bar", false))
+
+		usage, err := env.PachClient.GetCommitStorageUsage("a", "master")
+		require.NoError(t, err)
+		require.Equal(t, uint64(2000), usage.LogicalBytes)
+		require.Equal(t, uint64(1000), usage.PhysicalBytes)
+		require.Equal(t, uint64(0), usage.SharedBytes)
+
+		usage, err = env.PachClient.GetRepoStorageUsage("a")
+		require.NoError(t, err)
+		require.Equal(t, uint64(3000), usage.LogicalBytes)
+		require.Equal(t, uint64(1000), usage.PhysicalBytes)
+
+		// Copying the file into another repo shares its data
+		commit, err := env.PachClient.StartCommit("b", "master")
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.CopyFile("a", "master", "foo", "b", commit.ID, "foo", false))
+		require.NoError(t, env.PachClient.FinishCommit("b", commit.ID))
+		usage, err = env.PachClient.GetRepoStorageUsage("a")
+		require.NoError(t, err)
+		require.Equal(t, uint64(1000), usage.SharedBytes)
+		require.Equal(t, 1, len(usage.SharedWith))
+		require.Equal(t, "b", usage.SharedWith[0].Repo.Name)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestSetBranchRetention(t *testing.T) {
+	t.Parallel()
+	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
+		require.NoError(t, env.PachClient.CreateRepo("in"))
+		require.NoError(t, env.PachClient.CreateRepo("out"))
+		require.NoError(t, env.PachClient.CreateBranch("out", "master", "", []*pfs.Branch{pclient.NewBranch("in", "master")}))
+
+		require.NoError(t, env.PachClient.SetBranchRetention("in", "master", 20, time.Hour))
+		branchInfo, err := env.PachClient.InspectBranch("in", "master")
+		require.NoError(t, err)
+		require.Equal(t, int64(20), branchInfo.Retention.History)
+		maxAge, err := types.DurationFromProto(branchInfo.Retention.MaxAge)
+		require.NoError(t, err)
+		require.Equal(t, time.Hour, maxAge)
+
+		// Updating the branch keeps its retention policy
+		_, err = env.PachClient.PutFile("in", "master", "foo", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		branchInfo, err = env.PachClient.InspectBranch("in", "master")
+		require.NoError(t, err)
+		require.Equal(t, int64(20), branchInfo.Retention.History)
+
+		// Setting an empty policy removes it
+		require.NoError(t, env.PachClient.SetBranchRetention("in", "master", 0, 0))
+		branchInfo, err = env.PachClient.InspectBranch("in", "master")
+		require.NoError(t, err)
+		require.Nil(t, branchInfo.Retention)
+
+		// Branches with provenance can't have a retention policy
+		require.YesError(t, env.PachClient.SetBranchRetention("out", "master", 20, 0))
+		require.YesError(t, env.PachClient.SetBranchRetention("in", "master", -1, 0))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestBranchTrigger(t *testing.T) {
+	t.Parallel()
+	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
+		require.NoError(t, env.PachClient.CreateRepo("in"))
+		require.NoError(t, env.PachClient.CreateBranch("in", "staging", "", nil))
+		require.NoError(t, env.PachClient.SetBranchTrigger("in", "staging", &pfs.Trigger{
+			Branch:  "master",
+			Commits: 3,
+		}))
+		branchInfo, err := env.PachClient.InspectBranch("in", "staging")
+		require.NoError(t, err)
+		require.Equal(t, "master", branchInfo.Trigger.Branch)
+
+		// staging only moves to master's head after every third commit
+		for i := 0; i < 6; i++ {
+			_, err := env.PachClient.PutFile("in", "master", fmt.Sprintf("file%d", i), strings.NewReader("foo\n"))
+			require.NoError(t, err)
+			branchInfo, err := env.PachClient.InspectBranch("in", "staging")
+			require.NoError(t, err)
+			if i%3 != 2 {
+				if i < 2 {
+					require.Nil(t, branchInfo.Head)
+				}
+				continue
+			}
+			masterInfo, err := env.PachClient.InspectBranch("in", "master")
+			require.NoError(t, err)
+			require.Equal(t, masterInfo.Head.ID, branchInfo.Head.ID)
+		}
+
+		// Triggers can be chained, but not in a cycle
+		require.NoError(t, env.PachClient.CreateBranch("in", "prod", "", nil))
+		require.NoError(t, env.PachClient.SetBranchTrigger("in", "prod", &pfs.Trigger{Branch: "staging", Size_: "1B"}))
+		require.YesError(t, env.PachClient.SetBranchTrigger("in", "staging", &pfs.Trigger{Branch: "prod", Commits: 1}))
+		require.YesError(t, env.PachClient.SetBranchTrigger("in", "staging", &pfs.Trigger{Branch: "staging", Commits: 1}))
+		require.YesError(t, env.PachClient.SetBranchTrigger("in", "staging", &pfs.Trigger{Branch: "master"}))
+		require.YesError(t, env.PachClient.SetBranchTrigger("in", "staging", &pfs.Trigger{Branch: "master", CronSpec: "not a spec"}))
+		for i := 0; i < 3; i++ {
+			_, err := env.PachClient.PutFile("in", "master", fmt.Sprintf("chained%d", i), strings.NewReader("foo\n"))
+			require.NoError(t, err)
+		}
+		masterInfo, err := env.PachClient.InspectBranch("in", "master")
+		require.NoError(t, err)
+		branchInfo, err = env.PachClient.InspectBranch("in", "prod")
+		require.NoError(t, err)
+		require.Equal(t, masterInfo.Head.ID, branchInfo.Head.ID)
+
+		// Removing the trigger stops staging from moving
+		require.NoError(t, env.PachClient.SetBranchTrigger("in", "staging", nil))
+		branchInfo, err = env.PachClient.InspectBranch("in", "staging")
+		require.NoError(t, err)
+		require.Nil(t, branchInfo.Trigger)
+		head := branchInfo.Head
+		for i := 0; i < 3; i++ {
+			_, err := env.PachClient.PutFile("in", "master", fmt.Sprintf("more%d", i), strings.NewReader("foo\n"))
+			require.NoError(t, err)
+		}
+		branchInfo, err = env.PachClient.InspectBranch("in", "staging")
+		require.NoError(t, err)
+		require.Equal(t, head.ID, branchInfo.Head.ID)
+
+		// Branches with provenance can't have a trigger
+		require.NoError(t, env.PachClient.CreateRepo("out"))
+		require.NoError(t, env.PachClient.CreateBranch("out", "master", "", []*pfs.Branch{pclient.NewBranch("in", "staging")}))
+		require.YesError(t, env.PachClient.SetBranchTrigger("out", "master", &pfs.Trigger{Branch: "other", Commits: 1}))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestRepoQuota(t *testing.T) {
+	t.Parallel()
+	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
+		repo := "test"
+		_, err := env.PachClient.PfsAPIClient.CreateRepo(
+			env.PachClient.Ctx(),
+			&pfs.CreateRepoRequest{
+				Repo:  pclient.NewRepo(repo),
+				Quota: &pfs.RepoQuota{SizeBytes: 4, Commits: 2},
+			},
+		)
+		require.NoError(t, err)
+
+		// A commit under the size quota can be finished
+		_, err = env.PachClient.PutFile(repo, "master", "foo", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		ri, err := env.PachClient.InspectRepo(repo)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), ri.Commits)
+
+		// A commit over the size quota can't be
+		commit, err := env.PachClient.StartCommit(repo, "master")
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, commit.ID, "bar", strings.NewReader("bar\n"))
+		require.NoError(t, err)
+		err = env.PachClient.FinishCommit(repo, commit.ID)
+		require.YesError(t, err)
+		require.True(t, pfsserver.IsQuotaExceededErr(err))
+		require.NoError(t, env.PachClient.DeleteCommit(repo, commit.ID))
+
+		// Updating the repo's description keeps its quota
+		_, err = env.PachClient.PfsAPIClient.CreateRepo(
+			env.PachClient.Ctx(),
+			&pfs.CreateRepoRequest{
+				Repo:        pclient.NewRepo(repo),
+				Description: "foo",
+				Update:      true,
+			},
+		)
+		require.NoError(t, err)
+		ri, err = env.PachClient.InspectRepo(repo)
+		require.NoError(t, err)
+		require.Equal(t, uint64(4), ri.Quota.SizeBytes)
+
+		// The commit quota is also enforced
+		require.NoError(t, env.PachClient.DeleteFile(repo, "master", "foo"))
+		commit, err = env.PachClient.StartCommit(repo, "master")
+		require.NoError(t, err)
+		err = env.PachClient.FinishCommit(repo, commit.ID)
+		require.YesError(t, err)
+		require.True(t, pfsserver.IsQuotaExceededErr(err))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestRepoQuotaTotalSize(t *testing.T) {
+	t.Parallel()
+	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
+		repo := "test"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		_, err := env.PachClient.PutFile(repo, "master", "foo", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+
+		// Usage is tracked before the repo has a quota, and kept when one is set
+		_, err = env.PachClient.PfsAPIClient.CreateRepo(
+			env.PachClient.Ctx(),
+			&pfs.CreateRepoRequest{
+				Repo:   pclient.NewRepo(repo),
+				Quota:  &pfs.RepoQuota{SizeBytes: 10},
+				Update: true,
+			},
+		)
+		require.NoError(t, err)
+		ri, err := env.PachClient.InspectRepo(repo)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), ri.Commits)
+		require.Equal(t, uint64(4), ri.UsageBytes)
+
+		// Commits on other branches count towards the repo's quota, even
+		// though each of them is under it
+		_, err = env.PachClient.PutFile(repo, "other", "bar", strings.NewReader("bar\n"))
+		require.NoError(t, err)
+		commit, err := env.PachClient.StartCommit(repo, "third")
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, commit.ID, "baz", strings.NewReader("baz\n"))
+		require.NoError(t, err)
+		err = env.PachClient.FinishCommit(repo, commit.ID)
+		require.YesError(t, err)
+		require.True(t, pfsserver.IsQuotaExceededErr(err))
+		ri, err = env.PachClient.InspectRepo(repo)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), ri.Commits)
+		require.Equal(t, uint64(8), ri.UsageBytes)
+
+		// Deleting commits frees up their usage
+		require.NoError(t, env.PachClient.DeleteCommit(repo, commit.ID))
+		require.NoError(t, env.PachClient.DeleteCommit(repo, "other"))
+		ri, err = env.PachClient.InspectRepo(repo)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), ri.Commits)
+		require.Equal(t, uint64(4), ri.UsageBytes)
+		_, err = env.PachClient.PutFile(repo, "third", "baz", strings.NewReader("baz\n"))
+		require.NoError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
 func TestPutObjectAsync(t *testing.T) {
 	t.Parallel()
 	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
@@ -6119,3 +6897,44 @@ func TestFuzzProvenance(t *testing.T) {
 	})
 	require.NoError(t, err)
 }
+
+func TestDiffFileStreamRenames(t *testing.T) {
+	t.Parallel()
+	err := tu.WithRealEnv(func(env *tu.RealEnv) error {
+		repo := "test"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		_, err := env.PachClient.PutFile(repo, "master", "foo", strings.NewReader("foo content\n"))
+		require.NoError(t, err)
+		_, err = env.PachClient.PutFile(repo, "master", "mod", strings.NewReader("old\n"))
+		require.NoError(t, err)
+
+		// Move foo to bar and modify mod in a single commit
+		commit, err := env.PachClient.StartCommit(repo, "master")
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.CopyFile(repo, commit.ID, "foo", repo, commit.ID, "bar", false))
+		require.NoError(t, env.PachClient.DeleteFile(repo, commit.ID, "foo"))
+		_, err = env.PachClient.PutFileOverwrite(repo, commit.ID, "mod", strings.NewReader("new\n"), 0)
+		require.NoError(t, err)
+		require.NoError(t, env.PachClient.FinishCommit(repo, commit.ID))
+
+		diff := func(detectRenames bool) []string {
+			var result []string
+			require.NoError(t, env.PachClient.DiffFileF(repo, "master", "", "", "", "", false, detectRenames, func(fileDiff *pfs.FileDiff) error {
+				var newPath, oldPath string
+				if fileDiff.NewFile != nil {
+					newPath = fileDiff.NewFile.File.Path
+				}
+				if fileDiff.OldFile != nil {
+					oldPath = fileDiff.OldFile.File.Path
+				}
+				result = append(result, fmt.Sprintf("%s:%s:%t", oldPath, newPath, fileDiff.Renamed))
+				return nil
+			}))
+			return result
+		}
+		require.Equal(t, []string{":bar:false", "foo::false", "mod:mod:false"}, diff(false))
+		require.Equal(t, []string{"mod:mod:false", "foo:bar:true"}, diff(true))
+		return nil
+	})
+	require.NoError(t, err)
+}
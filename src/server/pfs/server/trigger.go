@@ -0,0 +1,241 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	units "github.com/docker/go-units"
+	"github.com/gogo/protobuf/types"
+	"github.com/robfig/cron"
+
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ancestry"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	txnenv "github.com/pachyderm/pachyderm/src/server/pkg/transactionenv"
+)
+
+// validateTrigger returns an error if 'trigger' can't be set on 'branch'
+func validateTrigger(branch *pfs.Branch, trigger *pfs.Trigger) error {
+	if trigger.Branch == "" {
+		return errors.New("trigger branch cannot be empty")
+	}
+	if err := ancestry.ValidateName(trigger.Branch); err != nil {
+		return err
+	}
+	if trigger.Branch == branch.Name {
+		return fmt.Errorf("branch %s@%s cannot trigger itself", branch.Repo.Name, branch.Name)
+	}
+	if trigger.CronSpec == "" && trigger.Size_ == "" && trigger.Commits == 0 {
+		return errors.New("trigger must have at least one of a cron spec, a size or a number of commits")
+	}
+	if trigger.CronSpec != "" {
+		if _, err := cron.ParseStandard(trigger.CronSpec); err != nil {
+			return fmt.Errorf("error parsing trigger cron spec: %v", err)
+		}
+	}
+	if trigger.Size_ != "" {
+		if _, err := units.FromHumanSize(trigger.Size_); err != nil {
+			return fmt.Errorf("error parsing trigger size: %v", err)
+		}
+	}
+	if trigger.Commits < 0 {
+		return fmt.Errorf("trigger commits must be nonnegative, but was %d", trigger.Commits)
+	}
+	return nil
+}
+
+// triggerFires returns true if 'trigger' fires when 'newHead' is finished on
+// the trigger's branch. 'oldHead' is the current head of the triggered branch
+// (nil if it has none) and 'commits' is the number of commits between the two,
+// including 'newHead'.
+func triggerFires(trigger *pfs.Trigger, oldHead, newHead *pfs.CommitInfo, commits int64) (bool, error) {
+	var results []bool
+	if trigger.CronSpec != "" {
+		schedule, err := cron.ParseStandard(trigger.CronSpec)
+		if err != nil {
+			return false, err
+		}
+		fired := true
+		if oldHead != nil && oldHead.Finished != nil && newHead.Finished != nil {
+			last, err := types.TimestampFromProto(oldHead.Finished)
+			if err != nil {
+				return false, err
+			}
+			finished, err := types.TimestampFromProto(newHead.Finished)
+			if err != nil {
+				return false, err
+			}
+			fired = !schedule.Next(last).After(finished)
+		}
+		results = append(results, fired)
+	}
+	if trigger.Size_ != "" {
+		size, err := units.FromHumanSize(trigger.Size_)
+		if err != nil {
+			return false, err
+		}
+		added := int64(newHead.SizeBytes)
+		if oldHead != nil {
+			added -= int64(oldHead.SizeBytes)
+		}
+		results = append(results, added >= size)
+	}
+	if trigger.Commits > 0 {
+		results = append(results, commits >= trigger.Commits)
+	}
+	if len(results) == 0 {
+		return false, nil
+	}
+	for _, fired := range results {
+		if fired && !trigger.All {
+			return true, nil
+		}
+		if !fired && trigger.All {
+			return false, nil
+		}
+	}
+	return trigger.All, nil
+}
+
+func (d *driver) setBranchTrigger(txnCtx *txnenv.TransactionContext, branch *pfs.Branch, trigger *pfs.Trigger) error {
+	// Validate arguments
+	if branch == nil {
+		return errors.New("branch cannot be nil")
+	}
+	if branch.Repo == nil {
+		return errors.New("branch repo cannot be nil")
+	}
+	if trigger != nil {
+		if err := validateTrigger(branch, trigger); err != nil {
+			return err
+		}
+	}
+
+	if err := d.checkIsAuthorizedOnBranchInTransaction(txnCtx, branch, auth.Scope_WRITER); err != nil {
+		return err
+	}
+
+	branchInfo := &pfs.BranchInfo{}
+	if err := d.branches(branch.Repo.Name).ReadWrite(txnCtx.Stm).Update(branch.Name, branchInfo, func() error {
+		// A branch with provenance is moved by its provenance, so it can't
+		// also be moved by a trigger
+		if trigger != nil && len(branchInfo.Provenance) > 0 {
+			return fmt.Errorf("cannot set a trigger on branch %s@%s because it has provenance", branch.Repo.Name, branch.Name)
+		}
+		branchInfo.Trigger = trigger
+		return nil
+	}); err != nil {
+		return err
+	}
+	if trigger == nil {
+		return nil
+	}
+	// Triggers can be chained, but not in a cycle
+	seen := map[string]bool{branch.Name: true}
+	for next := trigger.Branch; next != ""; {
+		if seen[next] {
+			return fmt.Errorf("trigger on branch %s@%s would create a cycle", branch.Repo.Name, branch.Name)
+		}
+		seen[next] = true
+		nextInfo := &pfs.BranchInfo{}
+		if err := d.branches(branch.Repo.Name).ReadWrite(txnCtx.Stm).Get(next, nextInfo); err != nil {
+			// The trigger's branch doesn't need to exist yet
+			if col.IsErrNotFound(err) {
+				break
+			}
+			return err
+		}
+		next = nextInfo.Trigger.GetBranch()
+	}
+	return nil
+}
+
+// fireTriggers is called when 'commitInfo' is finished. If the commit is the
+// head of its branch, it moves every branch in the repo whose trigger is on
+// that branch and whose trigger fires to the commit. Triggered branches may
+// themselves fire triggers, which are evaluated in turn.
+func (d *driver) fireTriggers(txnCtx *txnenv.TransactionContext, commitInfo *pfs.CommitInfo) error {
+	if commitInfo.Branch == nil {
+		return nil
+	}
+	repo := commitInfo.Commit.Repo
+	branches := d.branches(repo.Name).ReadWrite(txnCtx.Stm)
+	branchInfo := &pfs.BranchInfo{}
+	if err := branches.Get(commitInfo.Branch.Name, branchInfo); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if branchInfo.Head == nil || branchInfo.Head.ID != commitInfo.Commit.ID {
+		return nil
+	}
+	repoInfo := &pfs.RepoInfo{}
+	if err := d.repos.ReadWrite(txnCtx.Stm).Get(repo.Name, repoInfo); err != nil {
+		return err
+	}
+	for _, b := range repoInfo.Branches {
+		triggeredInfo := &pfs.BranchInfo{}
+		if err := branches.Get(b.Name, triggeredInfo); err != nil {
+			return err
+		}
+		trigger := triggeredInfo.Trigger
+		if trigger == nil || trigger.Branch != branchInfo.Branch.Name {
+			continue
+		}
+		var oldHead *pfs.CommitInfo
+		if triggeredInfo.Head != nil {
+			if triggeredInfo.Head.ID == commitInfo.Commit.ID {
+				continue
+			}
+			var err error
+			oldHead, err = d.resolveCommit(txnCtx.Stm, triggeredInfo.Head)
+			if err != nil {
+				return err
+			}
+		}
+		commits, err := d.commitsSince(txnCtx, commitInfo, triggeredInfo.Head, trigger.Commits)
+		if err != nil {
+			return err
+		}
+		fired, err := triggerFires(trigger, oldHead, commitInfo, commits)
+		if err != nil {
+			return err
+		}
+		if !fired {
+			continue
+		}
+		if err := d.createBranch(txnCtx, triggeredInfo.Branch, commitInfo.Commit, nil); err != nil {
+			return err
+		}
+		triggered := *commitInfo
+		triggered.Branch = triggeredInfo.Branch
+		if err := d.fireTriggers(txnCtx, &triggered); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commitsSince returns the number of commits in the ancestry of 'commitInfo',
+// including 'commitInfo', up to (but excluding) 'since'. It stops counting at
+// 'limit', as that's all a trigger needs to know.
+func (d *driver) commitsSince(txnCtx *txnenv.TransactionContext, commitInfo *pfs.CommitInfo, since *pfs.Commit, limit int64) (int64, error) {
+	var count int64
+	for ci := commitInfo; count < limit; {
+		if since != nil && ci.Commit.ID == since.ID {
+			break
+		}
+		count++
+		if ci.ParentCommit == nil {
+			break
+		}
+		var err error
+		ci, err = d.resolveCommit(txnCtx.Stm, ci.ParentCommit)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
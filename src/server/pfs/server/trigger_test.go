@@ -0,0 +1,60 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestValidateTrigger(t *testing.T) {
+	branch := client.NewBranch("repo", "staging")
+	require.NoError(t, validateTrigger(branch, &pfs.Trigger{Branch: "master", Commits: 10}))
+	require.NoError(t, validateTrigger(branch, &pfs.Trigger{Branch: "master", Size_: "1GB", CronSpec: "@daily", All: true}))
+	require.YesError(t, validateTrigger(branch, &pfs.Trigger{Commits: 10}))
+	require.YesError(t, validateTrigger(branch, &pfs.Trigger{Branch: "staging", Commits: 10}))
+	require.YesError(t, validateTrigger(branch, &pfs.Trigger{Branch: "master"}))
+	require.YesError(t, validateTrigger(branch, &pfs.Trigger{Branch: "master", Commits: -1}))
+	require.YesError(t, validateTrigger(branch, &pfs.Trigger{Branch: "master", Size_: "lots"}))
+	require.YesError(t, validateTrigger(branch, &pfs.Trigger{Branch: "master", CronSpec: "every day"}))
+}
+
+func TestTriggerFires(t *testing.T) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	commitAt := func(finished time.Time, size uint64) *pfs.CommitInfo {
+		ts, err := types.TimestampProto(finished)
+		require.NoError(t, err)
+		return &pfs.CommitInfo{Finished: ts, SizeBytes: size}
+	}
+	oldHead := commitAt(start, 1000)
+
+	fires := func(trigger *pfs.Trigger, oldHead, newHead *pfs.CommitInfo, commits int64) bool {
+		fired, err := triggerFires(trigger, oldHead, newHead, commits)
+		require.NoError(t, err)
+		return fired
+	}
+	// commits
+	commits := &pfs.Trigger{Branch: "master", Commits: 3}
+	require.False(t, fires(commits, oldHead, commitAt(start, 1000), 2))
+	require.True(t, fires(commits, oldHead, commitAt(start, 1000), 3))
+	// size counts the data added since the old head
+	size := &pfs.Trigger{Branch: "master", Size_: "1KB"}
+	require.False(t, fires(size, oldHead, commitAt(start, 1999), 1))
+	require.True(t, fires(size, oldHead, commitAt(start, 2000), 1))
+	require.True(t, fires(size, nil, commitAt(start, 1000), 1))
+	// cron fires once a tick has passed since the old head was finished
+	cron := &pfs.Trigger{Branch: "master", CronSpec: "0 * * * *"}
+	require.False(t, fires(cron, oldHead, commitAt(start.Add(59*time.Minute), 0), 1))
+	require.True(t, fires(cron, oldHead, commitAt(start.Add(time.Hour), 0), 1))
+	require.True(t, fires(cron, nil, commitAt(start, 0), 1))
+	// any condition is enough, unless all are required
+	any := &pfs.Trigger{Branch: "master", Commits: 3, Size_: "1KB"}
+	require.True(t, fires(any, oldHead, commitAt(start, 2000), 1))
+	all := &pfs.Trigger{Branch: "master", Commits: 3, Size_: "1KB", All: true}
+	require.False(t, fires(all, oldHead, commitAt(start, 2000), 1))
+	require.True(t, fires(all, oldHead, commitAt(start, 2000), 3))
+}
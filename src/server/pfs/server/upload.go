@@ -0,0 +1,269 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
+	txnenv "github.com/pachyderm/pachyderm/src/server/pkg/transactionenv"
+	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
+)
+
+// Upload sessions let clients put a file in chunks over several requests, so
+// that an interrupted upload can be resumed from the last chunk that was
+// received rather than from the start. Each chunk is stored as objects when
+// it's received, and the PutFileRecords referencing them are accumulated in
+// etcd until the upload is finished, at which point they're applied to the
+// upload's file like the records of a PutFile call.
+
+// startUpload starts an upload session for 'file'
+func (d *driver) startUpload(pachClient *client.APIClient, file *pfs.File, overwrite bool) (*pfs.Upload, error) {
+	if file == nil || file.Commit == nil || file.Commit.Repo == nil {
+		return nil, errors.New("file commit repo cannot be nil")
+	}
+	if _, err := d.checkIsAuthorizedOnCommit(pachClient, file.Commit, auth.Scope_WRITER); err != nil {
+		return nil, err
+	}
+	if err := d.checkFilePath(file.Path); err != nil {
+		return nil, err
+	}
+	if err := hashtree.ValidatePath(file.Path); err != nil {
+		return nil, err
+	}
+	if err := d.repos.ReadOnly(pachClient.Ctx()).Get(file.Commit.Repo.Name, &pfs.RepoInfo{}); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil, pfsserver.ErrRepoNotFound{Repo: file.Commit.Repo}
+		}
+		return nil, err
+	}
+	uploadInfo := &pfs.UploadInfo{
+		Upload:    &pfs.Upload{Id: uuid.NewWithoutDashes()},
+		File:      file,
+		Overwrite: overwrite,
+		Started:   now(),
+	}
+	if _, err := col.NewSTM(pachClient.Ctx(), d.etcdClient, func(stm col.STM) error {
+		if err := d.uploads.ReadWrite(stm).Create(uploadInfo.Upload.Id, uploadInfo); err != nil {
+			return err
+		}
+		return d.uploadRecords.ReadWrite(stm).Create(uploadInfo.Upload.Id, &pfs.PutFileRecords{
+			Tombstone: overwrite,
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return uploadInfo.Upload, nil
+}
+
+// inspectUpload returns the state of 'upload', if the current user may write
+// to its file
+func (d *driver) inspectUpload(pachClient *client.APIClient, upload *pfs.Upload) (*pfs.UploadInfo, error) {
+	if upload == nil {
+		return nil, errors.New("upload cannot be nil")
+	}
+	uploadInfo := &pfs.UploadInfo{}
+	if err := d.uploads.ReadOnly(pachClient.Ctx()).Get(upload.Id, uploadInfo); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil, pfsserver.ErrUploadNotFound{Upload: upload}
+		}
+		return nil, err
+	}
+	if _, err := d.checkIsAuthorizedOnCommit(pachClient, uploadInfo.File.Commit, auth.Scope_WRITER); err != nil {
+		return nil, err
+	}
+	return uploadInfo, nil
+}
+
+// uploadChunk adds the chunk sent by 'server' to the end of its upload. The
+// chunk is only added once all of it has been received, so a call that fails
+// part way through leaves the upload as it was.
+func (d *driver) uploadChunk(pachClient *client.APIClient, server pfs.API_UploadChunkServer) (*pfs.UploadInfo, error) {
+	req, err := server.Recv()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("no chunk was sent")
+		}
+		return nil, err
+	}
+	uploadInfo, err := d.inspectUpload(pachClient, req.Upload)
+	if err != nil {
+		return nil, err
+	}
+	if req.Offset != uploadInfo.SizeBytes {
+		return nil, pfsserver.ErrUploadOffset{Upload: req.Upload, Offset: req.Offset, Expected: uploadInfo.SizeBytes}
+	}
+	d.putObjectLimiter.Acquire()
+	objects, size, err := pachClient.PutObjectSplit(&chunkReader{server: server, buf: req.Value})
+	d.putObjectLimiter.Release()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := col.NewSTM(pachClient.Ctx(), d.etcdClient, func(stm col.STM) error {
+		uploads := d.uploads.ReadWrite(stm)
+		uploadRecords := d.uploadRecords.ReadWrite(stm)
+		if err := uploads.Get(req.Upload.Id, uploadInfo); err != nil {
+			if col.IsErrNotFound(err) {
+				return pfsserver.ErrUploadNotFound{Upload: req.Upload}
+			}
+			return err
+		}
+		// Another chunk may have been added while this one was being stored
+		if req.Offset != uploadInfo.SizeBytes {
+			return pfsserver.ErrUploadOffset{Upload: req.Upload, Offset: req.Offset, Expected: uploadInfo.SizeBytes}
+		}
+		records := &pfs.PutFileRecords{}
+		if err := uploadRecords.Get(req.Upload.Id, records); err != nil {
+			return err
+		}
+		// As in putFile, every object but the last is ChunkSize bytes
+		remaining := size
+		for _, object := range objects {
+			record := &pfs.PutFileRecord{
+				ObjectHash: object.Hash,
+				SizeBytes:  remaining,
+			}
+			if remaining > pfs.ChunkSize {
+				record.SizeBytes = pfs.ChunkSize
+			}
+			remaining -= record.SizeBytes
+			records.Records = append(records.Records, record)
+		}
+		uploadInfo.SizeBytes += uint64(size)
+		if err := uploadRecords.Put(req.Upload.Id, records); err != nil {
+			return err
+		}
+		return uploads.Put(req.Upload.Id, uploadInfo)
+	}); err != nil {
+		return nil, err
+	}
+	return uploadInfo, nil
+}
+
+// finishUpload puts 'uploads' into their files and deletes them. Uploads into
+// open commits are added to those commits, and uploads into branches whose
+// HEAD is finished are put in one new commit per branch.
+func (d *driver) finishUpload(pachClient *client.APIClient, uploads []*pfs.Upload) error {
+	type branchUploads struct {
+		repo, branch string
+		paths        []string
+		records      []*pfs.PutFileRecords
+	}
+	var (
+		seen     = make(map[string]bool)
+		open     []*pfs.File
+		records  []*pfs.PutFileRecords // the records of the files in 'open'
+		branches = make(map[string]*branchUploads)
+		order    []*branchUploads
+	)
+	// Check every upload before putting any of them
+	for _, upload := range uploads {
+		uploadInfo, err := d.inspectUpload(pachClient, upload)
+		if err != nil {
+			return err
+		}
+		if seen[upload.Id] {
+			return fmt.Errorf("upload %v is finished more than once", upload.Id)
+		}
+		seen[upload.Id] = true
+		uploadRecords := &pfs.PutFileRecords{}
+		if err := d.uploadRecords.ReadOnly(pachClient.Ctx()).Get(upload.Id, uploadRecords); err != nil {
+			return err
+		}
+		// inspectCommit resolves the commit's ID, so the upload's branch (if
+		// any) is saved first
+		file := client.NewFile(uploadInfo.File.Commit.Repo.Name, uploadInfo.File.Commit.ID, uploadInfo.File.Path)
+		branch := ""
+		if !uuid.IsUUIDWithoutDashes(file.Commit.ID) {
+			branch = file.Commit.ID
+		}
+		commitInfo, err := d.inspectCommit(pachClient, file.Commit, pfs.CommitState_STARTED)
+		if err != nil && (branch == "" || (!isNotFoundErr(err) && !isNoHeadErr(err))) {
+			return err
+		}
+		if commitInfo != nil && commitInfo.Finished == nil {
+			open = append(open, file)
+			records = append(records, uploadRecords)
+			continue
+		}
+		if branch == "" {
+			return pfsserver.ErrCommitFinished{Commit: file.Commit}
+		}
+		key := path.Join(file.Commit.Repo.Name, branch)
+		if branches[key] == nil {
+			branches[key] = &branchUploads{repo: file.Commit.Repo.Name, branch: branch}
+			order = append(order, branches[key])
+		}
+		branches[key].paths = append(branches[key].paths, file.Path)
+		branches[key].records = append(branches[key].records, uploadRecords)
+	}
+	for i, file := range open {
+		if err := d.upsertPutFileRecords(pachClient, file, records[i]); err != nil {
+			return err
+		}
+	}
+	for _, b := range order {
+		if err := d.txnEnv.WithWriteContext(pachClient.Ctx(), func(txnCtx *txnenv.TransactionContext) error {
+			_, err := d.makeCommit(txnCtx, "", client.NewCommit(b.repo, ""), b.branch, nil, nil, nil, nil, b.paths, b.records, "", 0)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	_, err := col.NewSTM(pachClient.Ctx(), d.etcdClient, func(stm col.STM) error {
+		for _, upload := range uploads {
+			if err := d.deleteUploadInTransaction(stm, upload); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// deleteUpload deletes 'upload' without putting its file. The objects that it
+// stored are removed by the next garbage collection.
+func (d *driver) deleteUpload(pachClient *client.APIClient, upload *pfs.Upload) error {
+	if _, err := d.inspectUpload(pachClient, upload); err != nil {
+		return err
+	}
+	_, err := col.NewSTM(pachClient.Ctx(), d.etcdClient, func(stm col.STM) error {
+		return d.deleteUploadInTransaction(stm, upload)
+	})
+	return err
+}
+
+func (d *driver) deleteUploadInTransaction(stm col.STM, upload *pfs.Upload) error {
+	if err := d.uploads.ReadWrite(stm).Delete(upload.Id); err != nil {
+		if col.IsErrNotFound(err) {
+			return pfsserver.ErrUploadNotFound{Upload: upload}
+		}
+		return err
+	}
+	return d.uploadRecords.ReadWrite(stm).Delete(upload.Id)
+}
+
+// chunkReader reads the values of the requests in an UploadChunk call
+type chunkReader struct {
+	server pfs.API_UploadChunkServer
+	buf    []byte
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		req, err := r.server.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = req.Value
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
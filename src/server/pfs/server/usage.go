@@ -0,0 +1,218 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
+)
+
+// usageCounter accumulates the data referenced by a set of files. Each piece
+// of data is identified by the block range that it's stored in, so data
+// referenced by several files (or several commits) is only counted once.
+type usageCounter struct {
+	// ranges maps each referenced block range to its size
+	ranges map[string]uint64
+	// objects caches the block ranges of the objects seen so far
+	objects map[string]*pfs.BlockRef
+}
+
+func newUsageCounter() *usageCounter {
+	return &usageCounter{
+		ranges:  make(map[string]uint64),
+		objects: make(map[string]*pfs.BlockRef),
+	}
+}
+
+func blockRangeKey(blockRef *pfs.BlockRef) string {
+	return fmt.Sprintf("%s:%d-%d", blockRef.Block.Hash, blockRef.Range.Lower, blockRef.Range.Upper)
+}
+
+// blockRefs returns the block ranges that contain the contents of 'node'.
+func (u *usageCounter) blockRefs(pachClient *client.APIClient, node *hashtree.FileNodeProto) ([]*pfs.BlockRef, error) {
+	result := append([]*pfs.BlockRef(nil), node.BlockRefs...)
+	for _, object := range node.Objects {
+		blockRef, ok := u.objects[object.Hash]
+		if !ok {
+			objectInfo, err := pachClient.InspectObject(object.Hash)
+			if err != nil {
+				return nil, err
+			}
+			blockRef = objectInfo.BlockRef
+			u.objects[object.Hash] = blockRef
+		}
+		result = append(result, blockRef)
+	}
+	return result, nil
+}
+
+// add records the data referenced by 'node'.
+func (u *usageCounter) add(pachClient *client.APIClient, node *hashtree.FileNodeProto) error {
+	blockRefs, err := u.blockRefs(pachClient, node)
+	if err != nil {
+		return err
+	}
+	for _, blockRef := range blockRefs {
+		u.ranges[blockRangeKey(blockRef)] = blockRef.Range.Upper - blockRef.Range.Lower
+	}
+	return nil
+}
+
+// physicalBytes returns the total size of the data recorded by 'u'.
+func (u *usageCounter) physicalBytes() uint64 {
+	var result uint64
+	for _, size := range u.ranges {
+		result += size
+	}
+	return result
+}
+
+// walkCommitFiles calls 'f' on the node of each file in 'commitInfo', which
+// must be finished.
+func (d *driver) walkCommitFiles(pachClient *client.APIClient, commitInfo *pfs.CommitInfo, f func(*hashtree.FileNodeProto) error) (retErr error) {
+	walkFn := func(path string, node *hashtree.NodeProto) error {
+		if node.FileNode == nil {
+			return nil
+		}
+		return f(node.FileNode)
+	}
+	// Handle commits that use the old hashtree format.
+	if !provenantOnInput(commitInfo.Provenance) || commitInfo.Tree != nil {
+		tree, err := d.getTreeForFile(pachClient, client.NewFile(commitInfo.Commit.Repo.Name, commitInfo.Commit.ID, "/"))
+		if err != nil {
+			return err
+		}
+		defer destroyHashtree(tree)
+		return tree.Walk("/", walkFn)
+	}
+	// Handle commits that use the newer hashtree format.
+	if commitInfo.Trees == nil {
+		return nil
+	}
+	rs, err := d.getTrees(pachClient, commitInfo, "/")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, r := range rs {
+			if err := r.Close(); err != nil && retErr == nil {
+				retErr = err
+			}
+		}
+	}()
+	return hashtree.Walk(rs, "/", walkFn)
+}
+
+// storageUsage reports the logical and physical sizes of 'commit', or of all
+// of the finished commits in 'repo'.
+func (d *driver) storageUsage(pachClient *client.APIClient, repo *pfs.Repo, commit *pfs.Commit) (*pfs.StorageUsage, error) {
+	// Validate arguments
+	if (repo == nil) == (commit == nil) {
+		return nil, errors.New("exactly one of repo and commit must be set")
+	}
+	if commit != nil {
+		repo = commit.Repo
+	}
+	if repo == nil {
+		return nil, errors.New("commit repo cannot be nil")
+	}
+	if err := d.checkIsAuthorized(pachClient, repo, auth.Scope_READER); err != nil {
+		return nil, err
+	}
+
+	result := &pfs.StorageUsage{}
+	counter := newUsageCounter()
+	addCommit := func(commitInfo *pfs.CommitInfo) error {
+		if commitInfo.Finished == nil {
+			return nil
+		}
+		result.LogicalBytes += commitInfo.SizeBytes
+		return d.walkCommitFiles(pachClient, commitInfo, func(node *hashtree.FileNodeProto) error {
+			return counter.add(pachClient, node)
+		})
+	}
+	if commit != nil {
+		commitInfo, err := d.inspectCommit(pachClient, commit, pfs.CommitState_STARTED)
+		if err != nil {
+			return nil, err
+		}
+		if commitInfo.Finished == nil {
+			return nil, fmt.Errorf("commit %s@%s is not finished", commit.Repo.Name, commit.ID)
+		}
+		if err := addCommit(commitInfo); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := d.listCommitF(pachClient, repo, nil, nil, 0, false, addCommit); err != nil {
+			return nil, err
+		}
+	}
+	result.PhysicalBytes = counter.physicalBytes()
+
+	// Find the data that's shared with the branch heads of other repos
+	repoInfos, err := d.listRepo(pachClient, false)
+	if err != nil {
+		return nil, err
+	}
+	shared := make(map[string]bool)
+	for _, repoInfo := range repoInfos.RepoInfo {
+		if repoInfo.Repo.Name == repo.Name {
+			continue
+		}
+		if err := d.checkIsAuthorized(pachClient, repoInfo.Repo, auth.Scope_READER); err != nil {
+			continue // Don't report on repos that the caller can't read
+		}
+		branchInfos, err := d.listBranch(pachClient, repoInfo.Repo, false)
+		if err != nil {
+			return nil, err
+		}
+		sharedWithRepo := make(map[string]bool)
+		visited := make(map[string]bool)
+		for _, branchInfo := range branchInfos {
+			if branchInfo.Head == nil || visited[branchInfo.Head.ID] {
+				continue
+			}
+			visited[branchInfo.Head.ID] = true
+			headInfo, err := d.inspectCommit(pachClient, branchInfo.Head, pfs.CommitState_STARTED)
+			if err != nil {
+				return nil, err
+			}
+			if headInfo.Finished == nil {
+				continue
+			}
+			if err := d.walkCommitFiles(pachClient, headInfo, func(node *hashtree.FileNodeProto) error {
+				blockRefs, err := counter.blockRefs(pachClient, node)
+				if err != nil {
+					return err
+				}
+				for _, blockRef := range blockRefs {
+					if key := blockRangeKey(blockRef); counter.ranges[key] > 0 {
+						sharedWithRepo[key] = true
+						shared[key] = true
+					}
+				}
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+		if len(sharedWithRepo) > 0 {
+			sharedUsage := &pfs.SharedUsage{Repo: repoInfo.Repo}
+			for key := range sharedWithRepo {
+				sharedUsage.Bytes += counter.ranges[key]
+			}
+			result.SharedWith = append(result.SharedWith, sharedUsage)
+		}
+	}
+	for key := range shared {
+		result.SharedBytes += counter.ranges[key]
+	}
+	sort.Slice(result.SharedWith, func(i, j int) bool {
+		return result.SharedWith[i].Bytes > result.SharedWith[j].Bytes
+	})
+	return result, nil
+}
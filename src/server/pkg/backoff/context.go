@@ -0,0 +1,52 @@
+package backoff
+
+import (
+	"context"
+	"time"
+)
+
+// BackOffContext is a backoff policy that stops backing off when its context
+// is done, and whose waits RetryNotify cuts short when it's done.
+type BackOffContext interface {
+	BackOff
+	Context() context.Context
+}
+
+type backOffContext struct {
+	BackOff
+	ctx context.Context
+}
+
+// WithContext returns a BackOffContext with 'ctx' as its context. As long as
+// 'ctx' isn't done, it backs off like 'b'.
+func WithContext(b BackOff, ctx context.Context) BackOffContext {
+	if ctx == nil {
+		panic("nil context")
+	}
+	if b, ok := b.(*backOffContext); ok {
+		return &backOffContext{BackOff: b.BackOff, ctx: ctx}
+	}
+	return &backOffContext{BackOff: b, ctx: ctx}
+}
+
+func (b *backOffContext) Context() context.Context {
+	return b.ctx
+}
+
+func (b *backOffContext) NextBackOff() time.Duration {
+	select {
+	case <-b.ctx.Done():
+		return Stop
+	default:
+		return b.BackOff.NextBackOff()
+	}
+}
+
+// getContext returns the context of 'b', if it's a BackOffContext, and
+// context.Background() otherwise
+func getContext(b BackOff) context.Context {
+	if cb, ok := b.(BackOffContext); ok {
+		return cb.Context()
+	}
+	return context.Background()
+}
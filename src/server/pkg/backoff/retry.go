@@ -21,7 +21,8 @@ type Notify func(error, time.Duration) error
 // It is the caller's responsibility to reset b after Retry returns.
 //
 // Retry sleeps the goroutine for the duration returned by BackOff after a
-// failed operation returns.
+// failed operation returns. If b is a BackOffContext, Retry stops when its
+// context is done, returning the operation's last error.
 func Retry(o Operation, b BackOff) error { return RetryNotify(o, b, nil) }
 
 // RetryNotify calls notify function with the error and wait duration
@@ -30,6 +31,7 @@ func RetryNotify(operation Operation, b BackOff, notify Notify) error {
 	var err error
 	var next time.Duration
 
+	ctx := getContext(b)
 	b.Reset()
 	for {
 		if err = operation(); err == nil {
@@ -46,6 +48,12 @@ func RetryNotify(operation Operation, b BackOff, notify Notify) error {
 			}
 		}
 
-		time.Sleep(next)
+		timer := time.NewTimer(next)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
 	}
 }
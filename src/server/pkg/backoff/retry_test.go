@@ -1,9 +1,11 @@
 package backoff
 
 import (
+	"context"
 	"errors"
 	"log"
 	"testing"
+	"time"
 )
 
 func TestRetry(t *testing.T) {
@@ -32,3 +34,45 @@ func TestRetry(t *testing.T) {
 		t.Errorf("invalid number of retries: %d", i)
 	}
 }
+
+func TestRetryContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var i = 0
+
+	// This function cancels the context on its third call, and always fails
+	f := func() error {
+		i++
+		if i == 3 {
+			cancel()
+		}
+		return errors.New("error")
+	}
+
+	err := Retry(f, WithContext(NewConstantBackOff(10*time.Millisecond), ctx))
+	if err == nil {
+		t.Errorf("expected an error")
+	}
+	if i != 3 {
+		t.Errorf("invalid number of retries: %d", i)
+	}
+
+	// Retry must return without waiting out the backoff once the context is
+	// cancelled
+	ctx, cancel = context.WithCancel(context.Background())
+	i = 0
+	start := time.Now()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	err = Retry(f, WithContext(NewConstantBackOff(time.Hour), ctx))
+	if err == nil {
+		t.Errorf("expected an error")
+	}
+	if i != 1 {
+		t.Errorf("invalid number of retries: %d", i)
+	}
+	if time.Since(start) > time.Minute {
+		t.Errorf("retry didn't stop when its context was cancelled")
+	}
+}
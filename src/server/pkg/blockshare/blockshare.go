@@ -0,0 +1,380 @@
+// Package blockshare shares the blocks that a pipeline's workers read between
+// the workers that run on the same node, so that a block that all of them
+// read (e.g. a big reference file that's in every datum) is fetched from
+// object storage once per node, rather than once per worker.
+//
+// Each worker's sidecar owns some of the blocks, by consistent hashing over
+// the sidecars of the pipeline's workers on its node, which register
+// themselves in etcd. A sidecar reads the blocks that it owns from object
+// storage, caches them on disk and serves them to its peers, and reads the
+// other blocks from their owners (or from object storage, if their owner
+// can't be reached).
+package blockshare
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	etcd "github.com/coreos/etcd/clientv3"
+	"github.com/golang/groupcache/consistenthash"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+	"github.com/pachyderm/pachyderm/src/server/pkg/mtls"
+	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+	"github.com/pachyderm/pachyderm/src/server/pkg/watch"
+)
+
+const (
+	// CacheSizeEnvVar is the size (in bytes) of each worker sidecar's on-disk
+	// cache of the blocks that it shares with the other workers on its node.
+	// Workers don't share blocks if it's unset.
+	CacheSizeEnvVar = "BLOCK_SHARING_CACHE_SIZE"
+	// NodeNameEnvVar is the name of the node that a worker's pod runs on
+	NodeNameEnvVar = "NODE_NAME"
+
+	// EtcdPrefix is the prefix in etcd under which sidecars register
+	// themselves, by pipeline and node
+	EtcdPrefix = "blockshare"
+
+	// leaseTTL is how long (in seconds) a sidecar stays registered after it
+	// stops renewing its registration, e.g. because it died
+	leaseTTL = 10
+	// ringReplicas is the number of points that each sidecar has on the hash
+	// ring, which spreads the blocks evenly between them
+	ringReplicas = 50
+)
+
+var (
+	readCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pachyderm",
+			Subsystem: "block_sharing",
+			Name:      "read_count",
+			Help:      "Number of block reads, by where they were served from (cache, peer or storage)",
+		},
+		[]string{"source"},
+	)
+	registerOnce sync.Once
+)
+
+func observe(source string) {
+	registerOnce.Do(func() {
+		if err := prometheus.Register(readCount); err != nil {
+			// metrics may be redundantly registered; ignore these errors
+			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+				log.Errorf("error registering prometheus metric: %v", err)
+			}
+		}
+	})
+	readCount.WithLabelValues(source).Inc()
+}
+
+// Sharer shares blocks with the sidecars of the other workers on its node. It
+// serves the BlockShare API to them.
+type Sharer struct {
+	// address is the address that the sidecar's peers reach it at
+	address string
+	cache   *diskCache
+
+	mu     sync.RWMutex
+	client obj.Client // set by ObjClient
+	ring   *consistenthash.Map
+	peers  map[string]*grpc.ClientConn
+}
+
+func newSharer(address string, cacheDir string, cacheSize int64) (*Sharer, error) {
+	cache, err := newDiskCache(cacheDir, cacheSize)
+	if err != nil {
+		return nil, err
+	}
+	s := &Sharer{
+		address: address,
+		cache:   cache,
+		peers:   make(map[string]*grpc.ClientConn),
+	}
+	s.setPeers(nil)
+	return s, nil
+}
+
+// New returns a Sharer for the sidecar at 'address', which caches up to
+// 'cacheSize' bytes of the blocks that it owns in 'cacheDir'. It registers
+// the sidecar under 'prefix' in etcd (which is specific to the pipeline and
+// node, e.g. <etcd prefix>/blockshare/<pipeline>/<node>), and shares blocks
+// with the other sidecars that are registered there.
+func New(etcdClient *etcd.Client, prefix string, address string, cacheDir string, cacheSize int64) (*Sharer, error) {
+	s, err := newSharer(address, cacheDir, cacheSize)
+	if err != nil {
+		return nil, err
+	}
+	// Register the sidecar with a lease, so that it's unregistered if it dies
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	resp, err := etcdClient.Grant(ctx, leaseTTL)
+	if err != nil {
+		return nil, fmt.Errorf("error granting lease: %v", err)
+	}
+	if _, err := etcdClient.KeepAlive(context.Background(), resp.ID); err != nil {
+		return nil, fmt.Errorf("error with KeepAlive: %v", err)
+	}
+	if _, err := etcdClient.Put(ctx, path.Join(prefix, address), "", etcd.WithLease(resp.ID)); err != nil {
+		return nil, fmt.Errorf("error registering block sharing peer: %v", err)
+	}
+	go s.watchPeers(etcdClient, prefix)
+	return s, nil
+}
+
+// watchPeers keeps the sidecar's peers up to date with the sidecars that are
+// registered under 'prefix'
+func (s *Sharer) watchPeers(etcdClient *etcd.Client, prefix string) {
+	backoff.RetryNotify(func() error {
+		watcher, err := watch.NewWatcher(context.Background(), etcdClient, prefix+"/", prefix+"/", nil)
+		if err != nil {
+			return err
+		}
+		defer watcher.Close()
+		peers := make(map[string]bool)
+		for event := range watcher.Watch() {
+			switch event.Type {
+			case watch.EventError:
+				return event.Err
+			case watch.EventPut:
+				peers[string(event.Key)] = true
+			case watch.EventDelete:
+				delete(peers, string(event.Key))
+			}
+			var addresses []string
+			for address := range peers {
+				addresses = append(addresses, address)
+			}
+			s.setPeers(addresses)
+		}
+		return fmt.Errorf("block sharing peer watch closed unexpectedly")
+	}, backoff.NewInfiniteBackOff(), func(err error, d time.Duration) error {
+		log.Errorf("error watching block sharing peers: %v; retrying in %v", err, d)
+		return nil
+	})
+}
+
+// setPeers sets the addresses of the sidecars that blocks are shared between
+// (which may or may not include this one)
+func (s *Sharer) setPeers(addresses []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	addresses = append(addresses, s.address)
+	sort.Strings(addresses)
+	ring := consistenthash.New(ringReplicas, nil)
+	peers := make(map[string]*grpc.ClientConn)
+	for i, address := range addresses {
+		if i > 0 && address == addresses[i-1] {
+			continue
+		}
+		ring.Add(address)
+		if address == s.address {
+			continue
+		}
+		if conn, ok := s.peers[address]; ok {
+			peers[address] = conn
+			continue
+		}
+		internalTLS, err := mtls.Internal()
+		if err != nil {
+			log.Errorf("error dialing block sharing peer %s: %v", address, err)
+			continue
+		}
+		// Connect in the background, so that unreachable peers don't block
+		// the others
+		conn, err := grpc.Dial(address, internalTLS.DialOption(), grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(grpcutil.MaxMsgSize),
+			grpc.MaxCallSendMsgSize(grpcutil.MaxMsgSize),
+		))
+		if err != nil {
+			log.Errorf("error dialing block sharing peer %s: %v", address, err)
+			continue
+		}
+		peers[address] = conn
+	}
+	for address, conn := range s.peers {
+		if _, ok := peers[address]; !ok {
+			conn.Close()
+		}
+	}
+	s.ring, s.peers = ring, peers
+}
+
+// owner returns a connection to the peer that owns the block 'name', or nil
+// if this sidecar owns it
+func (s *Sharer) owner(name string) *grpc.ClientConn {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.peers[s.ring.Get(name)]
+}
+
+func (s *Sharer) objClient() (obj.Client, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.client == nil {
+		return nil, fmt.Errorf("block sharing isn't ready yet")
+	}
+	return s.client, nil
+}
+
+// Get implements the BlockShare API
+func (s *Sharer) Get(request *GetRequest, server BlockShare_GetServer) (retErr error) {
+	r, err := s.readLocal(server.Context(), request.Name, request.OffsetBytes, request.SizeBytes)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := r.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	return grpcutil.WriteToStreamingBytesServer(r, server)
+}
+
+// reader reads a range of the block 'name' from its owner
+func (s *Sharer) reader(ctx context.Context, name string, offset uint64, size uint64) (io.ReadCloser, error) {
+	conn := s.owner(name)
+	if conn == nil {
+		return s.readLocal(ctx, name, offset, size)
+	}
+	r, err := readPeer(ctx, conn, name, offset, size)
+	if err == nil {
+		observe("peer")
+		return r, nil
+	}
+	log.Errorf("error reading block %s from block sharing peer %s; reading it from object storage: %v", name, conn.Target(), err)
+	c, err := s.objClient()
+	if err != nil {
+		return nil, err
+	}
+	observe("storage")
+	return c.Reader(ctx, name, offset, size)
+}
+
+// readLocal reads a range of the block 'name' from the cache, and if it isn't
+// cached, caches it from object storage
+func (s *Sharer) readLocal(ctx context.Context, name string, offset uint64, size uint64) (io.ReadCloser, error) {
+	c, err := s.objClient()
+	if err != nil {
+		return nil, err
+	}
+	key := strings.Join([]string{name, strconv.FormatUint(offset, 10), strconv.FormatUint(size, 10)}, "|")
+	r, cached, err := s.cache.get(key, func(w io.Writer) (retErr error) {
+		r, err := c.Reader(ctx, name, offset, size)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := r.Close(); err != nil && retErr == nil {
+				retErr = err
+			}
+		}()
+		_, err = io.Copy(w, r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cached {
+		observe("cache")
+	} else {
+		observe("storage")
+	}
+	return r, nil
+}
+
+// readPeer reads a range of the block 'name' from the peer 'conn'. It waits
+// for the peer's first response, so that it fails if the peer can't serve the
+// block (e.g. because it's gone).
+func readPeer(ctx context.Context, conn *grpc.ClientConn, name string, offset uint64, size uint64) (io.ReadCloser, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	getClient, err := NewBlockShareClient(conn).Get(ctx, &GetRequest{
+		Name:        name,
+		OffsetBytes: offset,
+		SizeBytes:   size,
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	first, err := getClient.Recv()
+	if err == io.EOF {
+		cancel()
+		return ioutil.NopCloser(bytes.NewReader(nil)), nil
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	rest := grpcutil.NewStreamingBytesReader(getClient, cancel)
+	return &readCloser{Reader: io.MultiReader(bytes.NewReader(first.Value), rest), Closer: rest}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+var (
+	registeredMu sync.Mutex
+	registered   *Sharer
+)
+
+// Register makes the object clients that are wrapped by ObjClient share
+// blocks through 's'. Like groupcache.RegisterPeerPicker, it's called at most
+// once per process, before any clients are wrapped.
+func Register(s *Sharer) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	if registered != nil {
+		panic("blockshare.Register called more than once")
+	}
+	registered = s
+}
+
+// ObjClient wraps 'c' so that its reads of the objects under 'prefix' (i.e.
+// blocks, which never change) are shared through the registered Sharer. It
+// returns 'c' unchanged if no Sharer is registered.
+func ObjClient(c obj.Client, prefix string) obj.Client {
+	registeredMu.Lock()
+	s := registered
+	registeredMu.Unlock()
+	if s == nil {
+		return c
+	}
+	return s.wrap(c, prefix)
+}
+
+func (s *Sharer) wrap(c obj.Client, prefix string) obj.Client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.client = c
+	return &sharedObjClient{Client: c, sharer: s, prefix: prefix}
+}
+
+type sharedObjClient struct {
+	obj.Client
+	sharer *Sharer
+	prefix string
+}
+
+func (c *sharedObjClient) Reader(ctx context.Context, name string, offset uint64, size uint64) (io.ReadCloser, error) {
+	if !strings.HasPrefix(name, c.prefix) {
+		return c.Client.Reader(ctx, name, offset, size)
+	}
+	return c.sharer.reader(ctx, name, offset, size)
+}
@@ -0,0 +1,525 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: server/pkg/blockshare/blockshare.proto
+
+package blockshare
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/gogo/protobuf/proto"
+	types "github.com/gogo/protobuf/types"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type GetRequest struct {
+	// name is the name of the block's object in object storage
+	Name        string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	OffsetBytes uint64 `protobuf:"varint,2,opt,name=offset_bytes,json=offsetBytes,proto3" json:"offset_bytes,omitempty"`
+	// size_bytes is the number of bytes to read, or 0 to read to the end of
+	// the block
+	SizeBytes            uint64   `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetRequest) Reset()         { *m = GetRequest{} }
+func (m *GetRequest) String() string { return proto.CompactTextString(m) }
+func (*GetRequest) ProtoMessage()    {}
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8a3af4d0242258ca, []int{0}
+}
+func (m *GetRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetRequest.Merge(m, src)
+}
+func (m *GetRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetRequest proto.InternalMessageInfo
+
+func (m *GetRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *GetRequest) GetOffsetBytes() uint64 {
+	if m != nil {
+		return m.OffsetBytes
+	}
+	return 0
+}
+
+func (m *GetRequest) GetSizeBytes() uint64 {
+	if m != nil {
+		return m.SizeBytes
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*GetRequest)(nil), "blockshare.GetRequest")
+}
+
+func init() {
+	proto.RegisterFile("server/pkg/blockshare/blockshare.proto", fileDescriptor_8a3af4d0242258ca)
+}
+
+var fileDescriptor_8a3af4d0242258ca = []byte{
+	// 251 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x90, 0xb1, 0x4e, 0xc3, 0x30,
+	0x14, 0x45, 0x31, 0xad, 0x90, 0xfa, 0x60, 0xf2, 0x80, 0xaa, 0x22, 0xa2, 0xd2, 0x01, 0x75, 0xb2,
+	0x11, 0x2c, 0x4c, 0x0c, 0x19, 0xe8, 0xc2, 0x14, 0x24, 0x06, 0x16, 0x64, 0x87, 0x97, 0xa4, 0x6a,
+	0x8a, 0x8d, 0x9f, 0x03, 0x2a, 0x5f, 0xc8, 0xc8, 0x27, 0xa0, 0x7c, 0x09, 0xb2, 0x03, 0x4a, 0x06,
+	0xb6, 0xab, 0x73, 0xaf, 0xec, 0xa3, 0x07, 0xe7, 0x84, 0xee, 0x0d, 0x9d, 0xb4, 0x9b, 0x52, 0xea,
+	0xda, 0xe4, 0x1b, 0xaa, 0x94, 0xc3, 0x41, 0x14, 0xd6, 0x19, 0x6f, 0x38, 0xf4, 0x64, 0x96, 0x94,
+	0xc6, 0x94, 0x35, 0xca, 0xd8, 0xe8, 0xa6, 0x90, 0xef, 0x4e, 0x59, 0x8b, 0x8e, 0xba, 0xed, 0x42,
+	0x03, 0xac, 0xd0, 0x67, 0xf8, 0xda, 0x20, 0x79, 0xce, 0x61, 0xfc, 0xa2, 0xb6, 0x38, 0x65, 0x73,
+	0xb6, 0x9c, 0x64, 0x31, 0xf3, 0x33, 0x38, 0x32, 0x45, 0x41, 0xe8, 0x9f, 0xf4, 0xce, 0x23, 0x4d,
+	0xf7, 0xe7, 0x6c, 0x39, 0xce, 0x0e, 0x3b, 0x96, 0x06, 0xc4, 0x4f, 0x01, 0x68, 0xfd, 0x81, 0xbf,
+	0x83, 0x51, 0x1c, 0x4c, 0x02, 0x89, 0xf5, 0xe5, 0x1d, 0x40, 0x1a, 0x8c, 0xee, 0x83, 0x11, 0xbf,
+	0x81, 0xd1, 0x0a, 0x3d, 0x3f, 0x16, 0x03, 0xef, 0x5e, 0x61, 0x76, 0x22, 0x3a, 0x63, 0xf1, 0x67,
+	0x2c, 0xe2, 0x23, 0x0f, 0xaa, 0x6e, 0x70, 0xb1, 0x77, 0xc1, 0xd2, 0xdb, 0xcf, 0x36, 0x61, 0x5f,
+	0x6d, 0xc2, 0xbe, 0xdb, 0x84, 0x3d, 0x5e, 0x97, 0x6b, 0x5f, 0x35, 0x5a, 0xe4, 0x66, 0x2b, 0xad,
+	0xca, 0xab, 0xdd, 0x33, 0xba, 0x61, 0x22, 0x97, 0xcb, 0x7f, 0xcf, 0xa6, 0x0f, 0xe2, 0x07, 0x57,
+	0x3f, 0x03, 0x00, 0x8b, 0x17, 0x50, 0xa7, 0x56, 0x01, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// BlockShareClient is the client API for BlockShare service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type BlockShareClient interface {
+	// Get reads a range of a block that the sidecar owns, from its cache or
+	// (if it isn't cached) object storage
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (BlockShare_GetClient, error)
+}
+
+type blockShareClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewBlockShareClient(cc *grpc.ClientConn) BlockShareClient {
+	return &blockShareClient{cc}
+}
+
+func (c *blockShareClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (BlockShare_GetClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_BlockShare_serviceDesc.Streams[0], "/blockshare.BlockShare/Get", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &blockShareGetClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type BlockShare_GetClient interface {
+	Recv() (*types.BytesValue, error)
+	grpc.ClientStream
+}
+
+type blockShareGetClient struct {
+	grpc.ClientStream
+}
+
+func (x *blockShareGetClient) Recv() (*types.BytesValue, error) {
+	m := new(types.BytesValue)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// BlockShareServer is the server API for BlockShare service.
+type BlockShareServer interface {
+	// Get reads a range of a block that the sidecar owns, from its cache or
+	// (if it isn't cached) object storage
+	Get(*GetRequest, BlockShare_GetServer) error
+}
+
+// UnimplementedBlockShareServer can be embedded to have forward compatible implementations.
+type UnimplementedBlockShareServer struct {
+}
+
+func (*UnimplementedBlockShareServer) Get(req *GetRequest, srv BlockShare_GetServer) error {
+	return status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+
+func RegisterBlockShareServer(s *grpc.Server, srv BlockShareServer) {
+	s.RegisterService(&_BlockShare_serviceDesc, srv)
+}
+
+func _BlockShare_Get_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BlockShareServer).Get(m, &blockShareGetServer{stream})
+}
+
+type BlockShare_GetServer interface {
+	Send(*types.BytesValue) error
+	grpc.ServerStream
+}
+
+type blockShareGetServer struct {
+	grpc.ServerStream
+}
+
+func (x *blockShareGetServer) Send(m *types.BytesValue) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _BlockShare_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "blockshare.BlockShare",
+	HandlerType: (*BlockShareServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Get",
+			Handler:       _BlockShare_Get_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "server/pkg/blockshare/blockshare.proto",
+}
+
+func (m *GetRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *GetRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.SizeBytes != 0 {
+		i = encodeVarintBlockshare(dAtA, i, uint64(m.SizeBytes))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.OffsetBytes != 0 {
+		i = encodeVarintBlockshare(dAtA, i, uint64(m.OffsetBytes))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintBlockshare(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintBlockshare(dAtA []byte, offset int, v uint64) int {
+	offset -= sovBlockshare(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *GetRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovBlockshare(uint64(l))
+	}
+	if m.OffsetBytes != 0 {
+		n += 1 + sovBlockshare(uint64(m.OffsetBytes))
+	}
+	if m.SizeBytes != 0 {
+		n += 1 + sovBlockshare(uint64(m.SizeBytes))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func sovBlockshare(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozBlockshare(x uint64) (n int) {
+	return sovBlockshare(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *GetRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowBlockshare
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowBlockshare
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthBlockshare
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthBlockshare
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OffsetBytes", wireType)
+			}
+			m.OffsetBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowBlockshare
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.OffsetBytes |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SizeBytes", wireType)
+			}
+			m.SizeBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowBlockshare
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SizeBytes |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipBlockshare(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthBlockshare
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthBlockshare
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipBlockshare(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowBlockshare
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowBlockshare
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowBlockshare
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthBlockshare
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupBlockshare
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthBlockshare
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthBlockshare        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowBlockshare          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupBlockshare = fmt.Errorf("proto: unexpected end of group")
+)
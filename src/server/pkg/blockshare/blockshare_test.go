@@ -0,0 +1,164 @@
+package blockshare
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/golang/groupcache/consistenthash"
+	"google.golang.org/grpc"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+)
+
+// countingClient is an object client that counts the reads that it serves
+type countingClient struct {
+	obj.Client
+	reads int64
+}
+
+func (c *countingClient) Reader(ctx context.Context, name string, offset uint64, size uint64) (io.ReadCloser, error) {
+	atomic.AddInt64(&c.reads, 1)
+	return c.Client.Reader(ctx, name, offset, size)
+}
+
+// newTestSharer returns a Sharer that's served on a local port, and a
+// function that stops it
+func newTestSharer(t *testing.T, dir string, cacheSize int64) (*Sharer, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s, err := newSharer(lis.Addr().String(), filepath.Join(dir, lis.Addr().String()), cacheSize)
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	RegisterBlockShareServer(server, s)
+	go server.Serve(lis)
+	return s, server.Stop
+}
+
+func read(t *testing.T, c obj.Client, name string, offset uint64, size uint64) string {
+	r, err := c.Reader(context.Background(), name, offset, size)
+	require.NoError(t, err)
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestShareBlocks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blockshare")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	local, err := obj.NewLocalClient(filepath.Join(dir, "storage"))
+	require.NoError(t, err)
+	w, err := local.Writer(context.Background(), "block/1")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("0123456789"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	// Two sidecars share blocks, each through its own object client
+	var storage [2]*countingClient
+	var clients [2]obj.Client
+	var sharers [2]*Sharer
+	for i := range sharers {
+		var stop func()
+		sharers[i], stop = newTestSharer(t, dir, 1024)
+		defer stop()
+		storage[i] = &countingClient{Client: local}
+		clients[i] = sharers[i].wrap(storage[i], "block/")
+	}
+	for _, s := range sharers {
+		s.setPeers([]string{sharers[0].address, sharers[1].address})
+	}
+
+	// Each range of a block is read from object storage once, by its owner
+	for i := 0; i < 3; i++ {
+		for _, c := range clients {
+			require.Equal(t, "0123456789", read(t, c, "block/1", 0, 0))
+			require.Equal(t, "234", read(t, c, "block/1", 2, 3))
+		}
+	}
+	require.Equal(t, int64(2), atomic.LoadInt64(&storage[0].reads)+atomic.LoadInt64(&storage[1].reads))
+
+	// Other objects aren't shared
+	w, err = local.Writer(context.Background(), "tag/1")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	for _, c := range clients {
+		read(t, c, "tag/1", 0, 0)
+	}
+	require.Equal(t, int64(4), atomic.LoadInt64(&storage[0].reads)+atomic.LoadInt64(&storage[1].reads))
+}
+
+func TestShareBlocksUnreachablePeer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blockshare")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	local, err := obj.NewLocalClient(filepath.Join(dir, "storage"))
+	require.NoError(t, err)
+	w, err := local.Writer(context.Background(), "block/1")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("block"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	s, stop := newTestSharer(t, dir, 1024)
+	defer stop()
+	c := s.wrap(local, "block/")
+	// Find an address that nothing listens on, and make it the owner of
+	// every block
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	gone := lis.Addr().String()
+	lis.Close()
+	s.setPeers([]string{gone})
+	s.mu.Lock()
+	s.ring = consistenthash.New(ringReplicas, nil)
+	s.ring.Add(gone)
+	s.mu.Unlock()
+
+	// The block is read from object storage instead
+	require.Equal(t, "block", read(t, c, "block/1", 0, 0))
+}
+
+func TestDiskCacheEviction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blockshare")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cache, err := newDiskCache(dir, 10)
+	require.NoError(t, err)
+	var fills int
+	get := func(key string, data string) {
+		r, _, err := cache.get(key, func(w io.Writer) error {
+			fills++
+			_, err := io.WriteString(w, data)
+			return err
+		})
+		require.NoError(t, err)
+		content, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		require.Equal(t, data, string(content))
+	}
+	get("a", "aaaa")
+	get("b", "bbbb")
+	get("a", "aaaa")
+	require.Equal(t, 2, fills)
+	// "b" is the least recently used, so it's evicted to make room
+	get("c", "cccc")
+	get("a", "aaaa")
+	require.Equal(t, 3, fills)
+	get("b", "bbbb")
+	require.Equal(t, 4, fills)
+	// Ranges that are bigger than the cache are served, but not cached
+	get("d", strings.Repeat("d", 11))
+	get("d", strings.Repeat("d", 11))
+	require.Equal(t, 6, fills)
+}
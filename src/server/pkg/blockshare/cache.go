@@ -0,0 +1,149 @@
+package blockshare
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// diskCache is an LRU cache of byte ranges of blocks, which stores them in
+// files in a directory and holds at most 'size' bytes. Concurrent reads of a
+// range that isn't cached share a single fill.
+type diskCache struct {
+	dir  string
+	size int64
+
+	mu       sync.Mutex
+	used     int64
+	lru      *list.List               // of *cacheEntry, most recently used first
+	entries  map[string]*list.Element // by key
+	inflight map[string]*fill
+}
+
+type cacheEntry struct {
+	key  string
+	size int64
+}
+
+// fill is a fill of a cache entry that's in progress, whose result is
+// available once 'done' is closed
+type fill struct {
+	done chan struct{}
+	err  error
+}
+
+func newDiskCache(dir string, size int64) (*diskCache, error) {
+	// Blocks that were cached by a previous run of the sidecar aren't
+	// tracked, so they're removed
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &diskCache{
+		dir:      dir,
+		size:     size,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+		inflight: make(map[string]*fill),
+	}, nil
+}
+
+func (c *diskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// get returns a reader of the range 'key', which it fills by calling 'f' if
+// the range isn't cached. It returns whether the range was cached.
+func (c *diskCache) get(key string, f func(w io.Writer) error) (io.ReadCloser, bool, error) {
+	for {
+		c.mu.Lock()
+		if e, ok := c.entries[key]; ok {
+			c.lru.MoveToFront(e)
+			// Opening the file with the lock held keeps it from being
+			// evicted first (and once it's open, eviction doesn't affect it)
+			file, err := os.Open(c.path(key))
+			c.mu.Unlock()
+			return file, true, err
+		}
+		if inflight, ok := c.inflight[key]; ok {
+			c.mu.Unlock()
+			<-inflight.done
+			if inflight.err != nil {
+				return nil, false, inflight.err
+			}
+			// The range was cached (unless it was too big, or it's already
+			// been evicted, in which case it's filled again)
+			continue
+		}
+		inflight := &fill{done: make(chan struct{})}
+		c.inflight[key] = inflight
+		c.mu.Unlock()
+
+		file, err := c.fill(key, f)
+		c.mu.Lock()
+		delete(c.inflight, key)
+		c.mu.Unlock()
+		inflight.err = err
+		close(inflight.done)
+		return file, false, err
+	}
+}
+
+// fill writes the range 'key' to a file with 'f', adds it to the cache if it
+// fits, and returns the file, positioned at its start
+func (c *diskCache) fill(key string, f func(w io.Writer) error) (_ *os.File, retErr error) {
+	tmp, err := ioutil.TempFile(c.dir, "fill-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		// The file can still be read once it's removed
+		if err := os.Remove(tmp.Name()); err != nil && !os.IsNotExist(err) && retErr == nil {
+			retErr = err
+		}
+		if retErr != nil {
+			tmp.Close()
+		}
+	}()
+	if err := f(tmp); err != nil {
+		return nil, err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if size > c.size {
+		// The range is served, but not cached
+		return tmp, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.Link(tmp.Name(), c.path(key)); err != nil {
+		return nil, fmt.Errorf("error caching block range: %v", err)
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, size: size})
+	c.used += size
+	for c.used > c.size {
+		e := c.lru.Back()
+		entry := e.Value.(*cacheEntry)
+		c.lru.Remove(e)
+		delete(c.entries, entry.key)
+		c.used -= entry.size
+		if err := os.Remove(c.path(entry.key)); err != nil {
+			return nil, err
+		}
+	}
+	return tmp, nil
+}
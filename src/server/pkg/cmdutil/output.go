@@ -0,0 +1,159 @@
+package cmdutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/template"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/pachyderm/pachyderm/src/server/pkg/serde"
+	"github.com/spf13/pflag"
+)
+
+// Output is how list and inspect commands print the resources that they
+// return, as set by the flags from Flags(). The output formats are:
+//   - "" (the default): a table, for list commands, or a summary, for inspect
+//     commands
+//   - "wide": the same, with absolute timestamps and (for some tables)
+//     additional columns
+//   - "json" and "yaml": each resource, serialized
+//   - "go-template=<template>" and "go-template-file=<path>": the output of a
+//     Go template, executed on each resource in turn. Templates see resources
+//     as they're serialized in json (so, e.g., '{{.pipeline.name}}' prints a
+//     pipeline's name, if OrigName is set)
+//
+// --raw is the same as '--output json', and is kept for backwards
+// compatibility (it may also be combined with '--output yaml').
+type Output struct {
+	// OrigName, if set, serializes resources with the field names in their
+	// .proto definitions (e.g. "size_bytes") rather than in lowerCamelCase
+	// (e.g. "sizeBytes"). Each group of commands keeps the naming that its
+	// --raw output has always had.
+	OrigName bool
+
+	raw    bool
+	format string
+}
+
+// Flags returns the --raw flag and a flag named 'name' (with shorthand
+// 'shorthand', if it's set) that sets the output format. Commands use
+// "output" and "o", like kubectl, unless they already have an --output flag.
+func (o *Output) Flags(name, shorthand string) *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.BoolVar(&o.raw, "raw", false, "Disable pretty printing; print raw json (the same as '--"+name+" json').")
+	// The format has no default, so that the default table (or summary) can be
+	// told apart from '-o json' in combination with --raw
+	flags.StringVarP(&o.format, name, shorthand, "", "Output format: \"json\", \"yaml\", \"wide\", \"go-template=<template>\" or \"go-template-file=<path>\".")
+	return flags
+}
+
+// Wide returns true if tables and summaries should have absolute timestamps
+// and additional columns
+func (o *Output) Wide() bool {
+	return o.format == "wide"
+}
+
+// Encoder returns an encoder that writes resources to 'w' in the output
+// format, or nil if they should be pretty-printed (i.e. the format is the
+// default or "wide"). It returns an error if the output flags are invalid,
+// so commands call it before printing anything.
+func (o *Output) Encoder(w io.Writer) (Encoder, error) {
+	format, arg := o.format, ""
+	if i := strings.Index(format, "="); i >= 0 {
+		format, arg = format[:i], format[i+1:]
+	}
+	switch format {
+	case "":
+		if !o.raw {
+			return nil, nil
+		}
+		format = "json"
+	case "wide", "go-template", "go-template-file":
+		if o.raw {
+			return nil, fmt.Errorf("cannot set --raw with an output format of %q", format)
+		}
+	}
+	switch format {
+	case "wide":
+		return nil, nil
+	case "json", "yaml":
+		if arg != "" {
+			return nil, fmt.Errorf("output format %q doesn't take an argument", format)
+		}
+		return serde.GetEncoder(format, w, serde.WithIndent(2), serde.WithOrigName(o.OrigName))
+	case "go-template", "go-template-file":
+		text := arg
+		if format == "go-template-file" {
+			data, err := ioutil.ReadFile(arg)
+			if err != nil {
+				return nil, fmt.Errorf("could not read template: %v", err)
+			}
+			text = string(data)
+		}
+		if text == "" {
+			return nil, fmt.Errorf("output format %q requires a template (e.g. '%s=...')", format, format)
+		}
+		t, err := template.New("output").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %v", err)
+		}
+		return &templateEncoder{w: w, t: t, origName: o.OrigName}, nil
+	default:
+		return nil, fmt.Errorf("unrecognized output format %q (must be \"json\", \"yaml\", \"wide\", \"go-template=<template>\" or \"go-template-file=<path>\")", o.format)
+	}
+}
+
+// Encoder writes resources to an output stream in a structured format. It's
+// the subset of serde.Encoder that list and inspect commands use.
+type Encoder interface {
+	// Encode writes 'v' (a struct or Go map)
+	Encode(v interface{}) error
+	// EncodeProto writes 'v', converting it with 'jsonpb'
+	EncodeProto(v proto.Message) error
+}
+
+// templateEncoder is an Encoder that writes the output of a Go template,
+// followed by a newline if the output doesn't end with one
+type templateEncoder struct {
+	w        io.Writer
+	t        *template.Template
+	origName bool
+}
+
+func (e *templateEncoder) Encode(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("serialization error while canonicalizing output: %v", err)
+	}
+	return e.execute(data)
+}
+
+func (e *templateEncoder) EncodeProto(v proto.Message) error {
+	var buf bytes.Buffer
+	m := jsonpb.Marshaler{OrigName: e.origName}
+	if err := m.Marshal(&buf, v); err != nil {
+		return fmt.Errorf("serialization error while canonicalizing output: %v", err)
+	}
+	return e.execute(buf.Bytes())
+}
+
+func (e *templateEncoder) execute(jsonData []byte) error {
+	var holder interface{}
+	if err := json.Unmarshal(jsonData, &holder); err != nil {
+		return fmt.Errorf("deserialization error while canonicalizing output: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := e.t.Execute(&buf, holder); err != nil {
+		return err
+	}
+	if buf.Len() > 0 && buf.Bytes()[buf.Len()-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,73 @@
+package cmdutil
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func encode(t *testing.T, o *Output, args ...string) (string, error) {
+	t.Helper()
+	require.NoError(t, o.Flags("output", "o").Parse(args))
+	var buf bytes.Buffer
+	e, err := o.Encoder(&buf)
+	if err != nil {
+		return "", err
+	}
+	if e == nil {
+		return "", nil
+	}
+	require.NoError(t, e.EncodeProto(&pfs.RepoInfo{
+		Repo:      &pfs.Repo{Name: "images"},
+		SizeBytes: 10,
+	}))
+	return buf.String(), nil
+}
+
+func TestOutputPretty(t *testing.T) {
+	for _, args := range [][]string{nil, {"-o", "wide"}} {
+		o := &Output{}
+		out, err := encode(t, o, args...)
+		require.NoError(t, err)
+		require.Equal(t, "", out)
+		require.Equal(t, len(args) > 0, o.Wide())
+	}
+}
+
+func TestOutputJSON(t *testing.T) {
+	out, err := encode(t, &Output{}, "--raw")
+	require.NoError(t, err)
+	require.Equal(t, "{\n  \"repo\": {\n    \"name\": \"images\"\n  },\n  \"sizeBytes\": \"10\"\n}", out)
+
+	out, err = encode(t, &Output{OrigName: true}, "-o", "json")
+	require.NoError(t, err)
+	require.Equal(t, "{\n  \"repo\": {\n    \"name\": \"images\"\n  },\n  \"size_bytes\": \"10\"\n}", out)
+}
+
+func TestOutputYAML(t *testing.T) {
+	out, err := encode(t, &Output{}, "--raw", "-o", "yaml")
+	require.NoError(t, err)
+	require.Equal(t, "repo:\n  name: images\nsizeBytes: \"10\"\n", out)
+}
+
+func TestOutputTemplate(t *testing.T) {
+	out, err := encode(t, &Output{}, "-o", "go-template={{.repo.name}} {{.sizeBytes}}")
+	require.NoError(t, err)
+	require.Equal(t, "images 10\n", out)
+}
+
+func TestOutputInvalid(t *testing.T) {
+	for _, args := range [][]string{
+		{"-o", "xml"},
+		{"-o", "json=x"},
+		{"-o", "go-template="},
+		{"-o", "go-template={{.repo"},
+		{"--raw", "-o", "wide"},
+		{"--raw", "-o", "go-template={{.repo.name}}"},
+	} {
+		_, err := encode(t, &Output{}, args...)
+		require.YesError(t, err, "%v", args)
+	}
+}
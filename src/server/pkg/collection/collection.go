@@ -56,6 +56,10 @@ type collection struct {
 
 	// valCheck is a function that checks if a value is valid.
 	valCheck func(proto.Message) error
+
+	// store is the Postgres backend that the collection is stored in, or nil
+	// if it's stored in etcd (see UsePostgres)
+	store *PostgresStore
 }
 
 // NewCollection creates a new collection.
@@ -76,6 +80,7 @@ func NewCollection(etcdClient *etcd.Client, prefix string, indexes []*Index, tem
 		template:   template,
 		keyCheck:   keyCheck,
 		valCheck:   valCheck,
+		store:      postgresStore,
 	}
 }
 
@@ -94,6 +99,12 @@ func (c *collection) ReadWriteInt(stm STM) ReadWriteIntCollection {
 }
 
 func (c *collection) ReadOnly(ctx context.Context) ReadonlyCollection {
+	if c.store != nil {
+		return &postgresReadonlyCollection{
+			readonlyCollection: readonlyCollection{collection: c, ctx: ctx},
+			store:              c.store,
+		}
+	}
 	return &readonlyCollection{
 		collection: c,
 		ctx:        ctx,
@@ -175,6 +186,20 @@ func (c *collection) indexDir(index *Index, indexVal interface{}) string {
 	return path.Join(c.indexRoot(index), indexValStr)
 }
 
+// indexRange returns the first key, and the key after the last, of the
+// entries of 'index' whose values are from 'from' (inclusive) to 'to'
+// (exclusive). See GetByIndexRange.
+func (c *collection) indexRange(index *Index, from, to interface{}) (string, string) {
+	start, end := c.indexRoot(index), etcd.GetPrefixRangeEnd(c.indexRoot(index))
+	if from != nil {
+		start = c.indexDir(index, from)
+	}
+	if to != nil {
+		end = c.indexDir(index, to)
+	}
+	return start, end
+}
+
 // See the documentation for `Index` for details.
 func (c *collection) indexPath(index *Index, indexVal interface{}, key string) string {
 	return path.Join(c.indexDir(index, indexVal), key)
@@ -534,6 +559,26 @@ func (c *readonlyCollection) GetByIndex(index *Index, indexVal interface{}, val
 	})
 }
 
+func (c *readonlyCollection) GetByIndexRange(index *Index, from, to interface{}, val proto.Message, opts *Options, f func(key string) error) error {
+	span, _ := tracing.AddSpanToAnyExisting(c.ctx, "/etcd.RO/GetByIndexRange", "col", c.prefix, "index", index, "from", from, "to", to)
+	defer tracing.FinishAnySpan(span)
+	if atomic.LoadInt64(&index.limit) == 0 {
+		atomic.CompareAndSwapInt64(&index.limit, 0, defaultLimit)
+	}
+	start, end := c.indexRange(index, from, to)
+	return c.listRange(c.indexRoot(index), start, end, &index.limit, opts, func(kv *mvccpb.KeyValue) error {
+		key := path.Base(string(kv.Key))
+		if err := c.Get(key, val); err != nil {
+			if IsErrNotFound(err) {
+				// See GetByIndex
+				return nil
+			}
+			return err
+		}
+		return f(key)
+	})
+}
+
 func (c *readonlyCollection) GetBlock(key string, val proto.Message) error {
 	span, ctx := tracing.AddSpanToAnyExisting(c.ctx, "/etcd.RO/GetBlock", "col", c.prefix, "key", key)
 	defer tracing.FinishAnySpan(span)
@@ -629,11 +674,17 @@ func (c *readonlyCollection) ListRev(val proto.Message, opts *Options, f func(ke
 }
 
 func (c *readonlyCollection) list(prefix string, limitPtr *int64, opts *Options, f func(*mvccpb.KeyValue) error) error {
+	return c.listRange(prefix, prefix, etcd.GetPrefixRangeEnd(prefix), limitPtr, opts, f)
+}
+
+// listRange is like list, but only lists the keys from 'start' (inclusive)
+// to 'end' (exclusive), which are both under 'prefix'
+func (c *readonlyCollection) listRange(prefix, start, end string, limitPtr *int64, opts *Options, f func(*mvccpb.KeyValue) error) error {
 	if opts.SelfSort {
-		return listSelfSortRevision(c, prefix, limitPtr, opts, f)
+		return listSelfSortRevision(c, prefix, start, end, limitPtr, opts, f)
 	}
 
-	return listRevision(c, prefix, limitPtr, opts, f)
+	return listRevision(c, prefix, start, end, limitPtr, opts, f)
 }
 
 func (c *readonlyCollection) Count() (int64, error) {
@@ -679,12 +730,25 @@ func (c *readonlyCollection) watchF(watcher watch.Watcher, f func(e *watch.Event
 
 // WatchByIndex watches items in a collection that match a particular index
 func (c *readonlyCollection) WatchByIndex(index *Index, val interface{}) (watch.Watcher, error) {
-	eventCh := make(chan *watch.Event)
-	done := make(chan struct{})
 	watcher, err := watch.NewWatcher(c.ctx, c.etcdClient, c.prefix, c.indexDir(index, val), c.template)
 	if err != nil {
 		return nil, err
 	}
+	return watchByIndex(watcher, c.template, func(key string) ([]byte, bool, error) {
+		resp, err := c.get(c.Path(key))
+		if err != nil || len(resp.Kvs) == 0 {
+			return nil, false, err
+		}
+		return resp.Kvs[0].Value, true, nil
+	}), nil
+}
+
+// watchByIndex translates the events from 'watcher', which watches an index,
+// into events for the items that the index points to. 'get' returns the
+// current value of the item at 'key', or false if there isn't one.
+func watchByIndex(watcher watch.Watcher, template proto.Message, get func(key string) ([]byte, bool, error)) watch.Watcher {
+	eventCh := make(chan *watch.Event)
+	done := make(chan struct{})
 	go func() (retErr error) {
 		defer func() {
 			if retErr != nil {
@@ -716,32 +780,32 @@ func (c *readonlyCollection) WatchByIndex(index *Index, val interface{}) (watch.
 				// pass along the error
 				return ev.Err
 			case watch.EventPut:
-				resp, err := c.get(c.Path(path.Base(string(ev.Key))))
+				value, ok, err := get(path.Base(string(ev.Key)))
 				if err != nil {
 					return err
 				}
-				if len(resp.Kvs) == 0 {
+				if !ok {
 					// this happens only if the item was deleted shortly after
 					// we receive this event.
 					continue
 				}
 				directEv = &watch.Event{
 					Key:      []byte(path.Base(string(ev.Key))),
-					Value:    resp.Kvs[0].Value,
+					Value:    value,
 					Type:     ev.Type,
-					Template: c.template,
+					Template: template,
 				}
 			case watch.EventDelete:
 				directEv = &watch.Event{
 					Key:      []byte(path.Base(string(ev.Key))),
 					Type:     ev.Type,
-					Template: c.template,
+					Template: template,
 				}
 			}
 			eventCh <- directEv
 		}
 	}()
-	return watch.MakeWatcher(eventCh, done), nil
+	return watch.MakeWatcher(eventCh, done)
 }
 
 // WatchOne watches a given item.  The first value returned from the watch
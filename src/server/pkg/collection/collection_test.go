@@ -14,6 +14,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/pfs"
 	"github.com/pachyderm/pachyderm/src/client/pkg/require"
 	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
 	"github.com/pachyderm/pachyderm/src/server/pkg/watch"
 
@@ -494,6 +495,41 @@ func TestBoolIndex(t *testing.T) {
 	}
 }
 
+func TestIndexRange(t *testing.T) {
+	etcdClient := getEtcdClient()
+	uuidPrefix := uuid.NewWithoutDashes()
+	valueIndex := &Index{Field: "Value"}
+	values := NewCollection(etcdClient, uuidPrefix, []*Index{valueIndex}, &types.StringValue{}, nil, nil)
+	_, err := NewSTM(context.Background(), etcdClient, func(stm STM) error {
+		values := values.ReadWrite(stm)
+		for _, v := range []string{"a1", "a2", "b1", "b2", "c1"} {
+			if err := values.Put("key-"+v, &types.StringValue{Value: v}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	ro := values.ReadOnly(context.Background())
+	getRange := func(from, to interface{}) []string {
+		var result []string
+		val := &types.StringValue{}
+		opts := &Options{Target: etcd.SortByCreateRevision, Order: etcd.SortAscend}
+		require.NoError(t, ro.GetByIndexRange(valueIndex, from, to, val, opts, func(key string) error {
+			require.Equal(t, "key-"+val.Value, key)
+			result = append(result, val.Value)
+			return nil
+		}))
+		return result
+	}
+	require.Equal(t, []string{"a2", "b1"}, getRange("a2", "b2"))
+	require.Equal(t, []string{"a1", "a2", "b1"}, getRange(nil, "b2"))
+	require.Equal(t, []string{"b2", "c1"}, getRange("b2", nil))
+	require.Equal(t, []string{"a1", "a2", "b1", "b2", "c1"}, getRange(nil, nil))
+	require.Equal(t, 0, len(getRange("c2", nil)))
+}
+
 var epsilon = &types.BoolValue{Value: true}
 
 func TestTTL(t *testing.T) {
@@ -649,7 +685,7 @@ func TestIteration(t *testing.T) {
 		require.Equal(t, numVals, len(vals), "didn't receive every value")
 		vals = make(map[string]bool)
 		valsOrder = []string{}
-		require.NoError(t, ro.List(val, &Options{etcd.SortByCreateRevision, etcd.SortAscend, true}, func(key string) error {
+		require.NoError(t, ro.List(val, &Options{Target: etcd.SortByCreateRevision, Order: etcd.SortAscend, SelfSort: true}, func(key string) error {
 			require.False(t, vals[key], "saw value %s twice", key)
 			vals[key] = true
 			valsOrder = append(valsOrder, key)
@@ -662,6 +698,68 @@ func TestIteration(t *testing.T) {
 	})
 }
 
+func TestListCursor(t *testing.T) {
+	etcdClient := getEtcdClient()
+	uuidPrefix := uuid.NewWithoutDashes()
+	jobInfos := NewCollection(etcdClient, uuidPrefix, []*Index{pipelineIndex}, &pps.JobInfo{}, nil, nil)
+	var expected []string
+	for i := 0; i < 10; i++ {
+		_, err := NewSTM(context.Background(), etcdClient, func(stm STM) error {
+			// Write some jobs in the same txn, so that pages split revisions
+			for j := 0; j < i%3+1; j++ {
+				job := &pps.JobInfo{
+					Job:      client.NewJob(fmt.Sprintf("%d-%d", i, j)),
+					Pipeline: client.NewPipeline("p1"),
+				}
+				if err := jobInfos.ReadWrite(stm).Put(job.Job.ID, job); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		// Jobs written in the same txn are listed in key order
+		for j := 0; j < i%3+1; j++ {
+			expected = append(expected, fmt.Sprintf("%d-%d", i, j))
+		}
+	}
+	// listPages lists the jobs 'pageSize' at a time, resuming each page from
+	// the token of the last
+	listPages := func(pageSize int, list func(opts *Options, f func(string) error) error) []string {
+		var result []string
+		token := ""
+		for {
+			cursor, err := ParseCursor(token)
+			require.NoError(t, err)
+			var page []string
+			require.NoError(t, list(&Options{Target: etcd.SortByCreateRevision, Order: etcd.SortAscend, Cursor: cursor}, func(key string) error {
+				page = append(page, key)
+				if len(page) == pageSize {
+					return errutil.ErrBreak
+				}
+				return nil
+			}))
+			result = append(result, page...)
+			if len(page) < pageSize {
+				return result
+			}
+			token = cursor.Token()
+		}
+	}
+	ro := jobInfos.ReadOnly(context.Background())
+	job := &pps.JobInfo{}
+	for _, pageSize := range []int{1, 2, 5, 100} {
+		require.Equal(t, expected, listPages(pageSize, func(opts *Options, f func(string) error) error {
+			return ro.List(job, opts, f)
+		}))
+		require.Equal(t, expected, listPages(pageSize, func(opts *Options, f func(string) error) error {
+			return ro.GetByIndex(pipelineIndex, client.NewPipeline("p1"), job, opts, f)
+		}))
+	}
+	_, err := ParseCursor("not a token")
+	require.YesError(t, err)
+}
+
 var etcdClient *etcd.Client
 var etcdClientOnce sync.Once
 
@@ -3,6 +3,10 @@ package collection
 import (
 	"fmt"
 	"strings"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errcode"
+
+	"google.golang.org/grpc/status"
 )
 
 // ErrNotFound indicates that a key was not found when it was expected to
@@ -16,6 +20,11 @@ func (e ErrNotFound) Error() string {
 	return fmt.Sprintf("%s %s not found", strings.TrimPrefix(e.Type, DefaultPrefix), e.Key)
 }
 
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrNotFound) GRPCStatus() *status.Status {
+	return errcode.New(errcode.NotFound, e.Error()).GRPCStatus()
+}
+
 // IsErrNotFound determines if an error is an ErrNotFound error
 func IsErrNotFound(e error) bool {
 	_, ok := e.(ErrNotFound)
@@ -33,6 +42,11 @@ func (e ErrExists) Error() string {
 	return fmt.Sprintf("%s %s already exists", strings.TrimPrefix(e.Type, DefaultPrefix), e.Key)
 }
 
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrExists) GRPCStatus() *status.Status {
+	return errcode.New(errcode.Conflict, e.Error()).GRPCStatus()
+}
+
 // IsErrExists determines if an error is an ErrExists error
 func IsErrExists(e error) bool {
 	_, ok := e.(ErrExists)
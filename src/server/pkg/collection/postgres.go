@@ -0,0 +1,339 @@
+package collection
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+)
+
+// This file implements a Postgres backend for collections, for clusters whose
+// metadata outgrows etcd. It stores the keys and values that collections
+// would write to etcd in a single table, along with the create revision, mod
+// revision and version that etcd would assign them, so that the rest of this
+// package (and its callers) can treat both backends the same way. etcd is
+// still used for everything other than collections (e.g. locks and leases).
+//
+// STM transactions run as serializable Postgres transactions (see
+// postgresSTM), and watches are implemented with LISTEN/NOTIFY (see
+// newPostgresWatcher).
+
+const (
+	// postgresTable is the table in which collections' keys are stored
+	postgresTable = "pachyderm_kv"
+	// postgresRevisionSequence assigns revisions to transactions, like etcd's
+	// revision counter
+	postgresRevisionSequence = "pachyderm_revision"
+	// postgresChannel is the channel on which changes to keys are announced
+	postgresChannel = "pachyderm_kv"
+	// postgresExpiryInterval is how often expired keys are deleted (so that
+	// watchers see them deleted, as they would when an etcd lease expires)
+	postgresExpiryInterval = time.Second
+)
+
+var postgresSchema = []string{
+	fmt.Sprintf(`CREATE SEQUENCE IF NOT EXISTS %s`, postgresRevisionSequence),
+	fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		key BYTEA PRIMARY KEY,
+		value BYTEA NOT NULL,
+		create_revision BIGINT NOT NULL,
+		mod_revision BIGINT NOT NULL,
+		version BIGINT NOT NULL,
+		expires_at TIMESTAMPTZ
+	)`, postgresTable),
+	fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_expires_at ON %s (expires_at) WHERE expires_at IS NOT NULL`,
+		postgresTable, postgresTable),
+}
+
+// postgresStore is the Postgres backend that all collections use, if
+// UsePostgres has been called
+var postgresStore *PostgresStore
+
+// PostgresStore stores collections in a Postgres database
+type PostgresStore struct {
+	db  *sql.DB
+	url string
+
+	// subscribers receive the changes announced on postgresChannel. They're
+	// served by a single connection (see subscribe).
+	mu          sync.Mutex
+	listener    *pq.Listener
+	subscribers map[*postgresSubscriber]struct{}
+}
+
+// NewPostgresStore connects to the Postgres database at 'url' (a connection
+// string understood by lib/pq), and creates the tables that collections are
+// stored in, if they don't exist
+func NewPostgresStore(url string) (*PostgresStore, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range postgresSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("could not create postgres schema: %v", err)
+		}
+	}
+	return &PostgresStore{
+		db:          db,
+		url:         url,
+		subscribers: make(map[*postgresSubscriber]struct{}),
+	}, nil
+}
+
+// UsePostgres makes every collection created after it's called store its
+// data in 'store' rather than etcd, and makes NewSTM run transactions against
+// 'store'. It must be called before any collection is created, and only
+// once.
+func UsePostgres(store *PostgresStore) {
+	postgresStore = store
+	go store.expireKeys()
+}
+
+// isPostgresConflict returns true if 'err' means that a serializable
+// transaction conflicted with another one, and should be retried
+func isPostgresConflict(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	// serialization_failure and deadlock_detected
+	return ok && (pqErr.Code == "40001" || pqErr.Code == "40P01")
+}
+
+// postgresChange is the payload of a notification on postgresChannel
+type postgresChange struct {
+	Key    []byte `json:"key"`
+	Delete bool   `json:"delete,omitempty"`
+	Rev    int64  `json:"rev"`
+	// TxID is the ID of the transaction that made the change, which watchers
+	// use to tell whether their snapshot already includes it
+	TxID int64 `json:"txid"`
+}
+
+// notify announces 'changes', which were made by 'tx', to watchers. The
+// notifications are delivered when (and if) 'tx' commits.
+func notify(ctx context.Context, tx *sql.Tx, changes []postgresChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	payloads := make([]string, 0, len(changes))
+	for _, change := range changes {
+		payload, err := json.Marshal(change)
+		if err != nil {
+			return err
+		}
+		payloads = append(payloads, string(payload))
+	}
+	_, err := tx.ExecContext(ctx, `SELECT pg_notify($1, payload) FROM unnest($2::text[]) AS payload`,
+		postgresChannel, pq.Array(payloads))
+	return err
+}
+
+// beginWrite returns the revision and ID of 'tx', which is about to write
+func beginWrite(ctx context.Context, tx *sql.Tx) (rev int64, txID int64, retErr error) {
+	err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT nextval('%s'), txid_current()`, postgresRevisionSequence)).Scan(&rev, &txID)
+	return rev, txID, err
+}
+
+// expireKeys deletes keys whose TTL has passed, and announces their deletion,
+// until the process exits
+func (s *PostgresStore) expireKeys() {
+	for range time.Tick(postgresExpiryInterval) {
+		if err := s.deleteExpiredKeys(context.Background()); err != nil && !isPostgresConflict(err) {
+			log.Errorf("error deleting expired keys from postgres: %v", err)
+		}
+	}
+}
+
+func (s *PostgresStore) deleteExpiredKeys(ctx context.Context) (retErr error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if retErr != nil {
+			tx.Rollback()
+		}
+	}()
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE expires_at <= now() RETURNING key`, postgresTable))
+	if err != nil {
+		return err
+	}
+	var keys [][]byte
+	for rows.Next() {
+		var key []byte
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return tx.Commit()
+	}
+	rev, txID, err := beginWrite(ctx, tx)
+	if err != nil {
+		return err
+	}
+	changes := make([]postgresChange, 0, len(keys))
+	for _, key := range keys {
+		changes = append(changes, postgresChange{Key: key, Delete: true, Rev: rev, TxID: txID})
+	}
+	if err := notify(ctx, tx, changes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// postgresSubscriber receives the changes to keys under 'prefix'. Changes are
+// queued without bound, so that a slow watcher can't hold up the others.
+type postgresSubscriber struct {
+	prefix string
+	mu     sync.Mutex
+	queue  []postgresChange
+	err    error
+	// ready is signalled when 'queue' or 'err' is updated
+	ready chan struct{}
+}
+
+func (sub *postgresSubscriber) push(change postgresChange, err error) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if err != nil {
+		sub.err = err
+	} else {
+		sub.queue = append(sub.queue, change)
+	}
+	select {
+	case sub.ready <- struct{}{}:
+	default:
+	}
+}
+
+// pop returns the changes queued for 'sub' (or the error that ended its
+// subscription), waiting for at least one if there are none
+func (sub *postgresSubscriber) pop(ctx context.Context, done <-chan struct{}) ([]postgresChange, error) {
+	for {
+		sub.mu.Lock()
+		queue, err := sub.queue, sub.err
+		sub.queue = nil
+		sub.mu.Unlock()
+		if len(queue) > 0 || err != nil {
+			return queue, err
+		}
+		select {
+		case <-sub.ready:
+		case <-done:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// subscribe returns a subscriber that receives the changes to keys under
+// 'prefix' from now on. It must be passed to unsubscribe once it's no longer
+// needed.
+func (s *PostgresStore) subscribe(prefix string) (*postgresSubscriber, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		listener := pq.NewListener(s.url, 10*time.Millisecond, time.Minute, nil)
+		if err := listener.Listen(postgresChannel); err != nil {
+			listener.Close()
+			return nil, err
+		}
+		s.listener = listener
+		go s.dispatch(listener)
+	}
+	sub := &postgresSubscriber{prefix: prefix, ready: make(chan struct{}, 1)}
+	s.subscribers[sub] = struct{}{}
+	return sub, nil
+}
+
+func (s *PostgresStore) unsubscribe(sub *postgresSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscribers, sub)
+}
+
+// dispatch delivers the notifications received by 'listener' to subscribers
+func (s *PostgresStore) dispatch(listener *pq.Listener) {
+	for n := range listener.Notify {
+		if n == nil {
+			// The listener reconnected, so notifications may have been lost.
+			// End every subscription, so that watchers start over.
+			s.mu.Lock()
+			for sub := range s.subscribers {
+				sub.push(postgresChange{}, fmt.Errorf("lost connection to postgres while watching %q", sub.prefix))
+				delete(s.subscribers, sub)
+			}
+			s.mu.Unlock()
+			continue
+		}
+		var change postgresChange
+		if err := json.Unmarshal([]byte(n.Extra), &change); err != nil {
+			log.Errorf("could not parse postgres notification %q: %v", n.Extra, err)
+			continue
+		}
+		s.mu.Lock()
+		for sub := range s.subscribers {
+			if strings.HasPrefix(string(change.Key), sub.prefix) {
+				sub.push(change, nil)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// txSnapshot is a Postgres transaction snapshot (as returned by
+// txid_current_snapshot()), which determines which transactions' changes a
+// transaction sees
+type txSnapshot struct {
+	xmin, xmax int64
+	inProgress map[int64]bool
+}
+
+// parseTxSnapshot parses the text form of a snapshot, "xmin:xmax:xip,..."
+func parseTxSnapshot(s string) (*txSnapshot, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed transaction snapshot %q", s)
+	}
+	snapshot := &txSnapshot{inProgress: make(map[int64]bool)}
+	var err error
+	if snapshot.xmin, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return nil, fmt.Errorf("malformed transaction snapshot %q: %v", s, err)
+	}
+	if snapshot.xmax, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+		return nil, fmt.Errorf("malformed transaction snapshot %q: %v", s, err)
+	}
+	if parts[2] != "" {
+		for _, xip := range strings.Split(parts[2], ",") {
+			txID, err := strconv.ParseInt(xip, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("malformed transaction snapshot %q: %v", s, err)
+			}
+			snapshot.inProgress[txID] = true
+		}
+	}
+	return snapshot, nil
+}
+
+// visible returns true if the changes made by transaction 'txID' are visible
+// in the snapshot (like Postgres's txid_visible_in_snapshot)
+func (s *txSnapshot) visible(txID int64) bool {
+	if txID < s.xmin {
+		return true
+	}
+	return txID < s.xmax && !s.inProgress[txID]
+}
@@ -0,0 +1,489 @@
+package collection
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	etcd "github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/pachyderm/pachyderm/src/client/pkg/tracing"
+	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/watch"
+)
+
+// postgresListBatchSize is the number of keys that postgresReadonlyCollection
+// reads at a time when listing a collection
+const postgresListBatchSize = 10000
+
+// postgresReadonlyCollection is a ReadonlyCollection whose data is stored in
+// Postgres (see UsePostgres)
+type postgresReadonlyCollection struct {
+	readonlyCollection
+	store *PostgresStore
+}
+
+// get returns the key-value pair stored at 'key', or nil if there isn't one
+func (c *postgresReadonlyCollection) get(key string) (*mvccpb.KeyValue, *time.Time, error) {
+	span, ctx := tracing.AddSpanToAnyExisting(c.ctx, "/postgres.RO/Get", "col", c.prefix, "key", key)
+	defer tracing.FinishAnySpan(span)
+	return c.store.get(ctx, key)
+}
+
+// get returns the key-value pair stored at 'key' and its expiry time, or nil
+// if there isn't one
+func (s *PostgresStore) get(ctx context.Context, key string) (*mvccpb.KeyValue, *time.Time, error) {
+	kv := &mvccpb.KeyValue{Key: []byte(key)}
+	var expiresAt *time.Time
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT value, create_revision, mod_revision, version, expires_at
+		FROM %s WHERE key = $1 AND (expires_at IS NULL OR expires_at > now())`, postgresTable),
+		[]byte(key)).Scan(&kv.Value, &kv.CreateRevision, &kv.ModRevision, &kv.Version, &expiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return kv, expiresAt, nil
+}
+
+func (c *postgresReadonlyCollection) Get(key string, val proto.Message) error {
+	if err := watch.CheckType(c.template, val); err != nil {
+		return err
+	}
+	kv, _, err := c.get(c.Path(key))
+	if err != nil {
+		return err
+	}
+	if kv == nil {
+		return ErrNotFound{c.prefix, key}
+	}
+	return proto.Unmarshal(kv.Value, val)
+}
+
+func (c *postgresReadonlyCollection) GetByIndex(index *Index, indexVal interface{}, val proto.Message, opts *Options, f func(key string) error) error {
+	span, _ := tracing.AddSpanToAnyExisting(c.ctx, "/postgres.RO/GetByIndex", "col", c.prefix, "index", index, "indexVal", indexVal)
+	defer tracing.FinishAnySpan(span)
+	return c.list(c.indexDir(index, indexVal), opts, func(kv *mvccpb.KeyValue) error {
+		key := path.Base(string(kv.Key))
+		if err := c.Get(key, val); err != nil {
+			if IsErrNotFound(err) {
+				// See readonlyCollection.GetByIndex
+				return nil
+			}
+			return err
+		}
+		return f(key)
+	})
+}
+
+func (c *postgresReadonlyCollection) GetByIndexRange(index *Index, from, to interface{}, val proto.Message, opts *Options, f func(key string) error) error {
+	span, _ := tracing.AddSpanToAnyExisting(c.ctx, "/postgres.RO/GetByIndexRange", "col", c.prefix, "index", index, "from", from, "to", to)
+	defer tracing.FinishAnySpan(span)
+	start, end := c.indexRange(index, from, to)
+	return c.listRange(c.indexRoot(index), start, end, opts, func(kv *mvccpb.KeyValue) error {
+		key := path.Base(string(kv.Key))
+		if err := c.Get(key, val); err != nil {
+			if IsErrNotFound(err) {
+				// See readonlyCollection.GetByIndex
+				return nil
+			}
+			return err
+		}
+		return f(key)
+	})
+}
+
+func (c *postgresReadonlyCollection) GetBlock(key string, val proto.Message) error {
+	if err := watch.CheckType(c.template, val); err != nil {
+		return err
+	}
+	watcher, err := c.WatchOne(key)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	for {
+		e := <-watcher.Watch()
+		if e.Err != nil {
+			return e.Err
+		}
+		if e.Type == watch.EventPut {
+			return e.Unmarshal(&key, val)
+		}
+	}
+}
+
+func (c *postgresReadonlyCollection) TTL(key string) (int64, error) {
+	kv, expiresAt, err := c.get(c.Path(key))
+	if err != nil {
+		return 0, err
+	}
+	if kv == nil {
+		return 0, ErrNotFound{c.prefix, key}
+	}
+	return remainingTTL(expiresAt), nil
+}
+
+func (c *postgresReadonlyCollection) ListPrefix(prefix string, val proto.Message, opts *Options, f func(string) error) error {
+	queryPrefix := c.prefix
+	if prefix != "" {
+		// See readonlyCollection.ListPrefix
+		queryPrefix = filepath.Join(c.prefix, prefix)
+	}
+	return c.list(queryPrefix, opts, func(kv *mvccpb.KeyValue) error {
+		if err := proto.Unmarshal(kv.Value, val); err != nil {
+			return err
+		}
+		return f(strings.TrimPrefix(string(kv.Key), queryPrefix))
+	})
+}
+
+func (c *postgresReadonlyCollection) List(val proto.Message, opts *Options, f func(key string) error) error {
+	if err := watch.CheckType(c.template, val); err != nil {
+		return err
+	}
+	return c.list(c.prefix, opts, func(kv *mvccpb.KeyValue) error {
+		if err := proto.Unmarshal(kv.Value, val); err != nil {
+			return err
+		}
+		return f(strings.TrimPrefix(string(kv.Key), c.prefix))
+	})
+}
+
+func (c *postgresReadonlyCollection) ListRev(val proto.Message, opts *Options, f func(key string, createRev int64) error) error {
+	if err := watch.CheckType(c.template, val); err != nil {
+		return err
+	}
+	return c.list(c.prefix, opts, func(kv *mvccpb.KeyValue) error {
+		if err := proto.Unmarshal(kv.Value, val); err != nil {
+			return err
+		}
+		return f(strings.TrimPrefix(string(kv.Key), c.prefix), kv.CreateRevision)
+	})
+}
+
+// postgresOrder returns the column and direction that the rows of
+// pachyderm_kv are sorted by for the sort target and order 'target' and
+// 'order'
+func postgresOrder(target etcd.SortTarget, order etcd.SortOrder) (string, string) {
+	column := "key"
+	switch target {
+	case etcd.SortByVersion:
+		column = "version"
+	case etcd.SortByCreateRevision:
+		column = "create_revision"
+	case etcd.SortByModRevision:
+		column = "mod_revision"
+	}
+	if order == etcd.SortDescend {
+		return column, "DESC"
+	}
+	return column, "ASC"
+}
+
+// list calls 'f' with each key-value pair under 'prefix' (other than index
+// entries), in the order given by 'opts'. Keys are read in batches, so that a
+// large collection doesn't have to fit in memory. SelfSort is ignored, as
+// Postgres can always do the sort.
+func (c *postgresReadonlyCollection) list(prefix string, opts *Options, f func(*mvccpb.KeyValue) error) error {
+	return c.listRange(prefix, prefix, etcd.GetPrefixRangeEnd(prefix), opts, f)
+}
+
+// listRange is like list, but only lists the keys from 'start' (inclusive)
+// to 'end' (exclusive), which are both under 'prefix'
+func (c *postgresReadonlyCollection) listRange(prefix, start, end string, opts *Options, f func(*mvccpb.KeyValue) error) error {
+	span, ctx := tracing.AddSpanToAnyExisting(c.ctx, "/postgres.RO/List", "col", c.prefix, "start", start, "end", end)
+	defer tracing.FinishAnySpan(span)
+	if opts == nil {
+		opts = DefaultOptions
+	}
+	cursor, err := startCursor(opts)
+	if err != nil {
+		return err
+	}
+	column, direction := postgresOrder(opts.Target, opts.Order)
+	comparison := ">"
+	if direction == "DESC" {
+		comparison = "<"
+	}
+	// Items with the same sort value are ordered by key, like etcd (see
+	// Cursor), which makes (column, key) a unique position to resume from
+	orderBy := fmt.Sprintf("%s %s, key", column, direction)
+	after := fmt.Sprintf("AND (%[1]s %[2]s $3 OR (%[1]s = $3 AND key > $4))", column, comparison)
+	if column == "key" {
+		orderBy = "key " + direction
+		after = fmt.Sprintf("AND key %s $4 AND $3::bigint IS NOT NULL", comparison)
+	}
+	query := fmt.Sprintf(`
+		SELECT key, value, create_revision, mod_revision, version FROM %s
+		WHERE key >= $1 AND key < $2 AND (expires_at IS NULL OR expires_at > now())
+		%%s ORDER BY %s LIMIT %d`, postgresTable, orderBy, postgresListBatchSize)
+	firstQuery := fmt.Sprintf(query, "")
+	nextQuery := fmt.Sprintf(query, after)
+	var last *mvccpb.KeyValue
+	if cursor != nil && cursor.Rev != 0 {
+		last = &mvccpb.KeyValue{Key: []byte(cursor.Key), CreateRevision: cursor.Rev, ModRevision: cursor.Rev}
+	}
+	for {
+		var rows *sql.Rows
+		var err error
+		if last == nil {
+			rows, err = c.store.db.QueryContext(ctx, firstQuery, []byte(start), []byte(end))
+		} else {
+			rows, err = c.store.db.QueryContext(ctx, nextQuery, []byte(start), []byte(end),
+				sortValue(last, column), last.Key)
+		}
+		if err != nil {
+			return err
+		}
+		var kvs []*mvccpb.KeyValue
+		for rows.Next() {
+			kv := &mvccpb.KeyValue{}
+			if err := rows.Scan(&kv.Key, &kv.Value, &kv.CreateRevision, &kv.ModRevision, &kv.Version); err != nil {
+				rows.Close()
+				return err
+			}
+			kvs = append(kvs, kv)
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return err
+		}
+		rows.Close()
+		for _, kv := range kvs {
+			if strings.Contains(strings.TrimPrefix(string(kv.Key), prefix), indexIdentifier) {
+				continue
+			}
+			moveCursor(opts, kv)
+			if err := f(kv); err != nil {
+				if err == errutil.ErrBreak {
+					return nil
+				}
+				return err
+			}
+		}
+		if len(kvs) < postgresListBatchSize {
+			return nil
+		}
+		last = kvs[len(kvs)-1]
+	}
+}
+
+// sortValue returns the value of 'kv' in 'column' (see postgresOrder)
+func sortValue(kv *mvccpb.KeyValue, column string) int64 {
+	switch column {
+	case "version":
+		return kv.Version
+	case "create_revision":
+		return kv.CreateRevision
+	case "mod_revision":
+		return kv.ModRevision
+	default:
+		return 0 // the key is compared on its own
+	}
+}
+
+func (c *postgresReadonlyCollection) Count() (int64, error) {
+	var count int64
+	err := c.store.db.QueryRowContext(c.ctx, fmt.Sprintf(`
+		SELECT count(*) FROM %s
+		WHERE key >= $1 AND key < $2 AND (expires_at IS NULL OR expires_at > now())`, postgresTable),
+		[]byte(c.prefix), []byte(etcd.GetPrefixRangeEnd(c.prefix))).Scan(&count)
+	return count, err
+}
+
+func (c *postgresReadonlyCollection) Watch(opts ...watch.OpOption) (watch.Watcher, error) {
+	return newPostgresWatcher(c.ctx, c.store, c.prefix, c.prefix, c.template, opts...)
+}
+
+func (c *postgresReadonlyCollection) WatchF(f func(e *watch.Event) error, opts ...watch.OpOption) error {
+	watcher, err := c.Watch(opts...)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	return c.watchF(watcher, f)
+}
+
+func (c *postgresReadonlyCollection) WatchByIndex(index *Index, val interface{}) (watch.Watcher, error) {
+	watcher, err := newPostgresWatcher(c.ctx, c.store, c.prefix, c.indexDir(index, val), c.template)
+	if err != nil {
+		return nil, err
+	}
+	return watchByIndex(watcher, c.template, func(key string) ([]byte, bool, error) {
+		kv, _, err := c.get(c.Path(key))
+		if err != nil || kv == nil {
+			return nil, false, err
+		}
+		return kv.Value, true, nil
+	}), nil
+}
+
+func (c *postgresReadonlyCollection) WatchOne(key string, opts ...watch.OpOption) (watch.Watcher, error) {
+	return newPostgresWatcher(c.ctx, c.store, c.prefix, c.Path(key), c.template, opts...)
+}
+
+func (c *postgresReadonlyCollection) WatchOneF(key string, f func(e *watch.Event) error, opts ...watch.OpOption) error {
+	watcher, err := c.WatchOne(key, opts...)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	return c.watchF(watcher, f)
+}
+
+// newPostgresWatcher is like watch.NewWatcher, but watches keys stored in
+// 'store'. It sends the keys currently under 'prefix', and then the changes
+// to them. Changes are read from notifications (see notify), which are
+// compared against the snapshot that the current keys were read in, so that
+// no change is missed or sent twice. Unlike etcd, Postgres only announces
+// which keys changed, so a watcher that falls behind sends the latest value
+// of a key rather than each of its intermediate values.
+func newPostgresWatcher(ctx context.Context, store *PostgresStore, trimPrefix, prefix string, template proto.Message, opts ...watch.OpOption) (watch.Watcher, error) {
+	var filterPut, filterDelete bool
+	target, order := etcd.SortByModRevision, etcd.SortAscend
+	for _, opt := range opts {
+		filterPut = filterPut || opt.FilterPut
+		filterDelete = filterDelete || opt.FilterDelete
+		if opt.Sort {
+			target, order = opt.SortTarget, opt.SortOrder
+		}
+	}
+	// Subscribe before reading the current keys, so that no change made in
+	// between is missed
+	sub, err := store.subscribe(prefix)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, kvs, err := readSnapshot(ctx, store, prefix, target, order)
+	if err != nil {
+		store.unsubscribe(sub)
+		return nil, err
+	}
+	eventCh := make(chan *watch.Event)
+	done := make(chan struct{})
+	go func() (retErr error) {
+		defer func() {
+			store.unsubscribe(sub)
+			if retErr != nil {
+				select {
+				case eventCh <- &watch.Event{Err: retErr, Type: watch.EventError}:
+				case <-done:
+				}
+			}
+			close(eventCh)
+		}()
+		send := func(ev *watch.Event) bool {
+			select {
+			case eventCh <- ev:
+				return true
+			case <-done:
+				return false
+			}
+		}
+		// sent holds the mod revision of the last value sent for each key, so
+		// that a change isn't sent again if its value was already read
+		sent := make(map[string]int64)
+		for _, kv := range kvs {
+			sent[string(kv.Key)] = kv.ModRevision
+			if !send(&watch.Event{
+				Key:      []byte(strings.TrimPrefix(string(kv.Key), trimPrefix)),
+				Value:    kv.Value,
+				Type:     watch.EventPut,
+				Rev:      kv.ModRevision,
+				Ver:      kv.Version,
+				Template: template,
+			}) {
+				return nil
+			}
+		}
+		for {
+			changes, err := sub.pop(ctx, done)
+			if err != nil {
+				return err
+			}
+			if changes == nil {
+				return nil // closed
+			}
+			for _, change := range changes {
+				key := string(change.Key)
+				if snapshot.visible(change.TxID) || change.Rev <= sent[key] {
+					continue
+				}
+				ev := &watch.Event{
+					Key:      []byte(strings.TrimPrefix(key, trimPrefix)),
+					Rev:      change.Rev,
+					Template: template,
+				}
+				if change.Delete {
+					delete(sent, key)
+					if filterDelete {
+						continue
+					}
+					ev.Type = watch.EventDelete
+				} else {
+					if filterPut {
+						continue
+					}
+					kv, _, err := store.get(ctx, key)
+					if err != nil {
+						return err
+					}
+					if kv == nil {
+						continue // deleted since; its deletion will be sent
+					}
+					sent[key] = kv.ModRevision
+					ev.Type, ev.Value, ev.Rev, ev.Ver = watch.EventPut, kv.Value, kv.ModRevision, kv.Version
+				}
+				if !send(ev) {
+					return nil
+				}
+			}
+		}
+	}()
+	return watch.MakeWatcher(eventCh, done), nil
+}
+
+// readSnapshot returns the key-value pairs under 'prefix', sorted by 'target'
+// and 'order', along with the snapshot that they were read in
+func readSnapshot(ctx context.Context, store *PostgresStore, prefix string, target etcd.SortTarget, order etcd.SortOrder) (_ *txSnapshot, _ []*mvccpb.KeyValue, retErr error) {
+	tx, err := store.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+	var snapshotStr string
+	if err := tx.QueryRowContext(ctx, `SELECT txid_current_snapshot()::text`).Scan(&snapshotStr); err != nil {
+		return nil, nil, err
+	}
+	snapshot, err := parseTxSnapshot(snapshotStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	column, direction := postgresOrder(target, order)
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+		SELECT key, value, create_revision, mod_revision, version FROM %s
+		WHERE key >= $1 AND key < $2 AND (expires_at IS NULL OR expires_at > now())
+		ORDER BY %s %s, key`, postgresTable, column, direction),
+		[]byte(prefix), []byte(etcd.GetPrefixRangeEnd(prefix)))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	var kvs []*mvccpb.KeyValue
+	for rows.Next() {
+		kv := &mvccpb.KeyValue{}
+		if err := rows.Scan(&kv.Key, &kv.Value, &kv.CreateRevision, &kv.ModRevision, &kv.Version); err != nil {
+			return nil, nil, err
+		}
+		kvs = append(kvs, kv)
+	}
+	return snapshot, kvs, rows.Err()
+}
@@ -0,0 +1,259 @@
+package collection
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	v3 "github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/pachyderm/pachyderm/src/client/pkg/tracing"
+)
+
+// postgresSTM implements STM with a serializable Postgres transaction. Reads
+// are made in the transaction (and cached), and writes are buffered until
+// commit, like stm. If Postgres finds that the transaction conflicts with
+// another one, the transaction is retried.
+type postgresSTM struct {
+	store *PostgresStore
+	ctx   context.Context
+	tx    *sql.Tx
+	// rset holds read key values, in the same form as stm's
+	rset map[string]*v3.GetResponse
+	// wset holds overwritten keys and their values
+	wset map[string]stmPut
+	// deletedPrefixes holds the set of prefixes that have been deleted
+	deletedPrefixes []string
+	// ttlset caches the remaining TTL of keys that have been read
+	ttlset map[string]int64
+}
+
+func newPostgresSTM(ctx context.Context, store *PostgresStore) *postgresSTM {
+	return &postgresSTM{store: store, ctx: ctx}
+}
+
+func (s *postgresSTM) Context() context.Context {
+	return s.ctx
+}
+
+func (s *postgresSTM) Get(key string) (string, error) {
+	if wv, ok := s.wset[key]; ok {
+		return wv.val, nil
+	}
+	if isKeyRangeDeleted(s.deletedPrefixes, key) {
+		return "", ErrNotFound{Key: key}
+	}
+	return respToValue(key, s.fetch(key))
+}
+
+func (s *postgresSTM) Put(key, val string, ttl int64, ptr uintptr) error {
+	s.wset[key] = stmPut{val, ttl, v3.OpPut(key, val), ptr}
+	return nil
+}
+
+func (s *postgresSTM) Rev(key string) int64 {
+	if resp := s.fetch(key); len(resp.Kvs) != 0 {
+		return resp.Kvs[0].ModRevision
+	}
+	return 0
+}
+
+func (s *postgresSTM) Del(key string) {
+	s.wset[key] = stmPut{"", 0, v3.OpDelete(key), 0}
+}
+
+func (s *postgresSTM) DelAll(prefix string) {
+	s.deletedPrefixes = delAll(s.deletedPrefixes, s.wset, prefix)
+}
+
+func (s *postgresSTM) TTL(key string) (int64, error) {
+	if wv, ok := s.wset[key]; ok {
+		return wv.ttl, nil
+	}
+	if isKeyRangeDeleted(s.deletedPrefixes, key) {
+		return 0, ErrNotFound{Key: key}
+	}
+	if len(s.fetch(key).Kvs) == 0 {
+		return 0, ErrNotFound{Key: key}
+	}
+	return s.ttlset[key], nil
+}
+
+func (s *postgresSTM) SetSafePutCheck(key string, ptr uintptr) {
+	if wv, ok := s.wset[key]; ok {
+		wv.safePutPtr = ptr
+		s.wset[key] = wv
+	}
+}
+
+func (s *postgresSTM) IsSafePut(key string, ptr uintptr) bool {
+	if _, ok := s.wset[key]; ok && s.wset[key].safePutPtr != 0 && ptr != s.wset[key].safePutPtr {
+		return false
+	}
+	return true
+}
+
+// fail aborts the txn with 'err', or retries it if 'err' is a conflict with
+// another txn
+func (s *postgresSTM) fail(err error) {
+	if isPostgresConflict(err) {
+		panic(stmConflict{})
+	}
+	panic(stmError{err})
+}
+
+func (s *postgresSTM) fetch(key string) *v3.GetResponse {
+	if resp, ok := s.rset[key]; ok {
+		return resp
+	}
+	span, ctx := tracing.AddSpanToAnyExisting(s.ctx, "/postgres.stm/Get", "key", key)
+	defer tracing.FinishAnySpan(span)
+	kv := &mvccpb.KeyValue{Key: []byte(key)}
+	var expiresAt *time.Time
+	err := s.tx.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT value, create_revision, mod_revision, version, expires_at
+		FROM %s WHERE key = $1 AND (expires_at IS NULL OR expires_at > now())`, postgresTable),
+		[]byte(key)).Scan(&kv.Value, &kv.CreateRevision, &kv.ModRevision, &kv.Version, &expiresAt)
+	resp := &v3.GetResponse{Header: &etcdserverpb.ResponseHeader{}}
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		s.fail(err)
+	default:
+		resp.Kvs = []*mvccpb.KeyValue{kv}
+		s.ttlset[key] = remainingTTL(expiresAt)
+	}
+	s.rset[key] = resp
+	return resp
+}
+
+// remainingTTL returns the number of seconds until 'expiresAt' (rounded up),
+// or 0 if it's nil
+func remainingTTL(expiresAt *time.Time) int64 {
+	if expiresAt == nil {
+		return 0
+	}
+	return int64(math.Ceil(time.Until(*expiresAt).Seconds()))
+}
+
+func (s *postgresSTM) commit() *v3.TxnResponse {
+	span, ctx := tracing.AddSpanToAnyExisting(s.ctx, "/postgres/Txn")
+	defer tracing.FinishAnySpan(span)
+	rev, err := s.write(ctx)
+	if err == nil {
+		err = s.tx.Commit()
+	}
+	s.tx = nil
+	if err != nil {
+		if isPostgresConflict(err) {
+			return nil // retry
+		}
+		panic(stmError{err})
+	}
+	tracing.TagAnySpan(span, "applied-at-revision", rev)
+	return &v3.TxnResponse{
+		Header:    &etcdserverpb.ResponseHeader{Revision: rev},
+		Succeeded: true,
+	}
+}
+
+// write applies the txn's buffered writes in its Postgres transaction, and
+// returns the revision that they were written at
+func (s *postgresSTM) write(ctx context.Context) (int64, error) {
+	if len(s.wset) == 0 && len(s.deletedPrefixes) == 0 {
+		return 0, nil
+	}
+	rev, txID, err := beginWrite(ctx, s.tx)
+	if err != nil {
+		return 0, err
+	}
+	var changes []postgresChange
+	// Delete prefixes first, as any puts under them were made after the
+	// prefix was deleted (see delAll)
+	for _, prefix := range s.deletedPrefixes {
+		rows, err := s.tx.QueryContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE key >= $1 AND key < $2 RETURNING key`, postgresTable),
+			[]byte(prefix), []byte(v3.GetPrefixRangeEnd(prefix)))
+		if err != nil {
+			return 0, err
+		}
+		for rows.Next() {
+			var key []byte
+			if err := rows.Scan(&key); err != nil {
+				rows.Close()
+				return 0, err
+			}
+			changes = append(changes, postgresChange{Key: key, Delete: true, Rev: rev, TxID: txID})
+		}
+		if err := rows.Close(); err != nil {
+			return 0, err
+		}
+	}
+	keys := make([]string, 0, len(s.wset))
+	for key := range s.wset {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		put := s.wset[key]
+		if put.op.IsDelete() {
+			result, err := s.tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE key = $1`, postgresTable), []byte(key))
+			if err != nil {
+				return 0, err
+			}
+			if n, err := result.RowsAffected(); err != nil {
+				return 0, err
+			} else if n > 0 {
+				changes = append(changes, postgresChange{Key: []byte(key), Delete: true, Rev: rev, TxID: txID})
+			}
+			continue
+		}
+		var expiresAt *time.Time
+		if put.ttl > 0 {
+			t := time.Now().Add(time.Duration(put.ttl) * time.Second)
+			expiresAt = &t
+		}
+		// A key that has expired (but hasn't been deleted yet) is recreated,
+		// rather than updated
+		if _, err := s.tx.ExecContext(ctx, fmt.Sprintf(`
+			INSERT INTO %[1]s (key, value, create_revision, mod_revision, version, expires_at)
+			VALUES ($1, $2, $3, $3, 1, $4)
+			ON CONFLICT (key) DO UPDATE SET
+				value = EXCLUDED.value,
+				mod_revision = EXCLUDED.mod_revision,
+				create_revision = CASE WHEN %[1]s.expires_at <= now() THEN EXCLUDED.create_revision ELSE %[1]s.create_revision END,
+				version = CASE WHEN %[1]s.expires_at <= now() THEN 1 ELSE %[1]s.version + 1 END,
+				expires_at = EXCLUDED.expires_at`, postgresTable),
+			[]byte(key), []byte(put.val), rev, expiresAt); err != nil {
+			return 0, err
+		}
+		changes = append(changes, postgresChange{Key: []byte(key), Rev: rev, TxID: txID})
+	}
+	if err := notify(ctx, s.tx, changes); err != nil {
+		return 0, err
+	}
+	return rev, nil
+}
+
+func (s *postgresSTM) abort() {
+	if s.tx != nil {
+		s.tx.Rollback()
+		s.tx = nil
+	}
+}
+
+func (s *postgresSTM) reset() {
+	s.abort()
+	tx, err := s.store.db.BeginTx(s.ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		panic(stmError{err})
+	}
+	s.tx = tx
+	s.rset = make(map[string]*v3.GetResponse)
+	s.wset = make(map[string]stmPut)
+	s.deletedPrefixes = []string{}
+	s.ttlset = make(map[string]int64)
+}
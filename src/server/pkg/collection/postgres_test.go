@@ -0,0 +1,40 @@
+package collection
+
+import (
+	"testing"
+
+	etcd "github.com/coreos/etcd/clientv3"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestParseTxSnapshot(t *testing.T) {
+	snapshot, err := parseTxSnapshot("10:20:12,15")
+	require.NoError(t, err)
+	require.True(t, snapshot.visible(9))
+	require.True(t, snapshot.visible(11))
+	require.False(t, snapshot.visible(12))
+	require.False(t, snapshot.visible(15))
+	require.False(t, snapshot.visible(20))
+	require.False(t, snapshot.visible(25))
+
+	snapshot, err = parseTxSnapshot("10:10:")
+	require.NoError(t, err)
+	require.True(t, snapshot.visible(9))
+	require.False(t, snapshot.visible(10))
+
+	_, err = parseTxSnapshot("10:20")
+	require.YesError(t, err)
+	_, err = parseTxSnapshot("10:x:")
+	require.YesError(t, err)
+}
+
+func TestPostgresOrder(t *testing.T) {
+	column, direction := postgresOrder(DefaultOptions.Target, DefaultOptions.Order)
+	require.Equal(t, "create_revision", column)
+	require.Equal(t, "DESC", direction)
+	column, direction = postgresOrder(etcd.SortByModRevision, etcd.SortNone)
+	require.Equal(t, "mod_revision", column)
+	require.Equal(t, "ASC", direction)
+	column, _ = postgresOrder(etcd.SortByKey, etcd.SortAscend)
+	require.Equal(t, "key", column)
+}
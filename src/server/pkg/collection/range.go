@@ -1,8 +1,10 @@
 package collection
 
 import (
+	"encoding/base64"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"sync/atomic"
 
@@ -20,10 +22,100 @@ type Options struct {
 	Target   etcd.SortTarget
 	Order    etcd.SortOrder
 	SelfSort bool
+	// Cursor, if set, makes the listing start after the item that it points
+	// to, and is moved to each item before the item is passed to the caller
+	// (see Cursor)
+	Cursor *Cursor
 }
 
 // DefaultOptions are the default sort options when iterating through etcd key/values.
-var DefaultOptions = &Options{etcd.SortByCreateRevision, etcd.SortDescend, false}
+var DefaultOptions = &Options{Target: etcd.SortByCreateRevision, Order: etcd.SortDescend}
+
+// Cursor is a position in a listing sorted by create or mod revision. A
+// caller that stops listing early (e.g. after a page of items) can resume
+// where it stopped by listing again with the same cursor. Items with the same
+// revision (i.e. written in the same transaction) are ordered by key. The
+// zero Cursor is the start of a listing.
+type Cursor struct {
+	Rev int64
+	Key string
+}
+
+// Token encodes 'c' as an opaque string (e.g. a page token in an API
+// response), which ParseCursor decodes
+func (c *Cursor) Token() string {
+	if c.Rev == 0 {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d/%s", c.Rev, c.Key)))
+}
+
+// ParseCursor decodes a token returned by Cursor.Token. The empty token is
+// the start of a listing.
+func ParseCursor(token string) (*Cursor, error) {
+	if token == "" {
+		return &Cursor{}, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page token %q", token)
+	}
+	parts := strings.SplitN(string(data), "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid page token %q", token)
+	}
+	rev, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || rev <= 0 {
+		return nil, fmt.Errorf("invalid page token %q", token)
+	}
+	return &Cursor{Rev: rev, Key: parts[1]}, nil
+}
+
+// startCursor returns a copy of the cursor in 'opts' (which is moved as the
+// listing proceeds), or nil if there isn't one. It returns an error if
+// 'opts' doesn't sort by revision.
+func startCursor(opts *Options) (*Cursor, error) {
+	if opts.Cursor == nil {
+		return nil, nil
+	}
+	if opts.Target != etcd.SortByCreateRevision && opts.Target != etcd.SortByModRevision {
+		return nil, fmt.Errorf("a cursor can only be used in a listing sorted by create or mod revision")
+	}
+	start := *opts.Cursor
+	return &start, nil
+}
+
+// revision returns the revision of 'kv' that 'target' sorts by
+func revision(target etcd.SortTarget, kv *mvccpb.KeyValue) int64 {
+	if target == etcd.SortByModRevision {
+		return kv.ModRevision
+	}
+	return kv.CreateRevision
+}
+
+// afterCursor returns true if 'kv' comes after 'start' in a listing sorted by
+// 'opts'. Every item is after a nil or zero cursor.
+func afterCursor(start *Cursor, opts *Options, kv *mvccpb.KeyValue) bool {
+	if start == nil || start.Rev == 0 {
+		return true
+	}
+	rev := revision(opts.Target, kv)
+	switch {
+	case rev == start.Rev:
+		return string(kv.Key) > start.Key
+	case opts.Order == etcd.SortDescend:
+		return rev < start.Rev
+	default:
+		return rev > start.Rev
+	}
+}
+
+// moveCursor moves the cursor in 'opts' (if any) to 'kv'
+func moveCursor(opts *Options, kv *mvccpb.KeyValue) {
+	if opts.Cursor != nil {
+		*opts.Cursor = Cursor{Rev: revision(opts.Target, kv), Key: string(kv.Key)}
+	}
+}
 
 func listFuncs(opts *Options) (func(*mvccpb.KeyValue) etcd.OpOption, func(kv1 *mvccpb.KeyValue, kv2 *mvccpb.KeyValue) int) {
 	var from func(*mvccpb.KeyValue) etcd.OpOption
@@ -53,23 +145,43 @@ func listFuncs(opts *Options) (func(*mvccpb.KeyValue) etcd.OpOption, func(kv1 *m
 	return from, compare
 }
 
-func listRevision(c *readonlyCollection, prefix string, limitPtr *int64, opts *Options, f func(*mvccpb.KeyValue) error) error {
-	etcdOpts := []etcd.OpOption{etcd.WithPrefix(), etcd.WithSort(opts.Target, opts.Order)}
+func listRevision(c *readonlyCollection, prefix, start, end string, limitPtr *int64, opts *Options, f func(*mvccpb.KeyValue) error) error {
+	etcdOpts := []etcd.OpOption{etcd.WithRange(end), etcd.WithSort(opts.Target, opts.Order)}
 	var fromKey *mvccpb.KeyValue
 	from, compare := listFuncs(opts)
+	cursor, err := startCursor(opts)
+	if err != nil {
+		return err
+	}
+	if cursor != nil && cursor.Rev != 0 {
+		etcdOpts = append(etcdOpts, from(&mvccpb.KeyValue{CreateRevision: cursor.Rev, ModRevision: cursor.Rev}))
+	}
 	for {
 		if fromKey != nil {
 			etcdOpts = append(etcdOpts, from(fromKey))
 		}
-		resp, done, err := getWithLimit(c, prefix, limitPtr, etcdOpts)
+		resp, done, err := getWithLimit(c, start, limitPtr, etcdOpts)
 		if err != nil {
 			return err
 		}
-		kvs := getNewKeys(resp.Kvs, fromKey)
+		kvs := sortByRevision(resp.Kvs, opts)
+		if !done {
+			if compare(kvs[0], kvs[len(kvs)-1]) == 0 {
+				return fmt.Errorf("revision contains too many objects to fit in one batch (this is likely a bug)")
+			}
+			// The batch may end partway through the keys with its last
+			// revision, so they're left for the next batch, which starts at
+			// that revision
+			kvs = trimLastRevision(kvs, opts)
+		}
 		for _, kv := range kvs {
 			if strings.Contains(strings.TrimPrefix(string(kv.Key), prefix), indexIdentifier) {
 				continue
 			}
+			if !afterCursor(cursor, opts, kv) {
+				continue
+			}
+			moveCursor(opts, kv)
 			if err := f(kv); err != nil {
 				if err == errutil.ErrBreak {
 					return nil
@@ -80,23 +192,40 @@ func listRevision(c *readonlyCollection, prefix string, limitPtr *int64, opts *O
 		if done {
 			return nil
 		}
-		if compare(resp.Kvs[0], resp.Kvs[len(resp.Kvs)-1]) == 0 {
-			return fmt.Errorf("revision contains too many objects to fit in one batch (this is likely a bug)")
+		// Every key with the last revision in 'kvs' was read, so the next
+		// batch starts at the revision after it
+		last := revision(opts.Target, kvs[len(kvs)-1])
+		if opts.Order == etcd.SortDescend {
+			last--
+		} else {
+			last++
 		}
-		fromKey = kvs[len(kvs)-1]
+		fromKey = &mvccpb.KeyValue{CreateRevision: last, ModRevision: last}
 	}
 }
 
-func getNewKeys(respKvs []*mvccpb.KeyValue, fromKey *mvccpb.KeyValue) []*mvccpb.KeyValue {
-	if fromKey == nil {
-		return respKvs
-	}
-	for i, kv := range respKvs {
-		if string(kv.Key) == string(fromKey.Key) {
-			return respKvs[i+1:]
+// sortByRevision sorts 'kvs', which etcd sorted by revision, so that keys
+// with the same revision are in key order (see Cursor)
+func sortByRevision(kvs []*mvccpb.KeyValue, opts *Options) []*mvccpb.KeyValue {
+	sort.SliceStable(kvs, func(i, j int) bool {
+		ri, rj := revision(opts.Target, kvs[i]), revision(opts.Target, kvs[j])
+		if ri != rj {
+			return (ri < rj) != (opts.Order == etcd.SortDescend)
 		}
+		return string(kvs[i].Key) < string(kvs[j].Key)
+	})
+	return kvs
+}
+
+// trimLastRevision removes the keys with the last revision in 'kvs', which
+// must contain more than one revision
+func trimLastRevision(kvs []*mvccpb.KeyValue, opts *Options) []*mvccpb.KeyValue {
+	last := revision(opts.Target, kvs[len(kvs)-1])
+	i := len(kvs)
+	for i > 0 && revision(opts.Target, kvs[i-1]) == last {
+		i--
 	}
-	return nil
+	return kvs[:i]
 }
 
 type kvSort struct {
@@ -104,16 +233,20 @@ type kvSort struct {
 	compare func(kv1 *mvccpb.KeyValue, kv2 *mvccpb.KeyValue) int
 }
 
-func listSelfSortRevision(c *readonlyCollection, prefix string, limitPtr *int64, opts *Options, f func(*mvccpb.KeyValue) error) error {
-	etcdOpts := []etcd.OpOption{etcd.WithFromKey(), etcd.WithRange(endKeyFromPrefix(prefix))}
-	fromKey := prefix
+func listSelfSortRevision(c *readonlyCollection, prefix, start, end string, limitPtr *int64, opts *Options, f func(*mvccpb.KeyValue) error) error {
+	cursor, err := startCursor(opts)
+	if err != nil {
+		return err
+	}
+	etcdOpts := []etcd.OpOption{etcd.WithFromKey(), etcd.WithRange(end)}
+	fromKey := start
 	kvs := []*mvccpb.KeyValue{}
 	for {
 		resp, done, err := getWithLimit(c, fromKey, limitPtr, etcdOpts)
 		if err != nil {
 			return err
 		}
-		if fromKey == prefix {
+		if fromKey == start {
 			kvs = append(kvs, resp.Kvs...)
 		} else {
 			kvs = append(kvs, resp.Kvs[1:]...)
@@ -125,16 +258,22 @@ func listSelfSortRevision(c *readonlyCollection, prefix string, limitPtr *int64,
 	}
 	_, compare := listFuncs(opts)
 	sorter := &kvSort{kvs, compare}
+	// kvs are in key order, so a stable sort orders items with the same
+	// revision by key (see Cursor)
 	switch opts.Order {
 	case etcd.SortAscend:
-		sort.Sort(sorter)
+		sort.Stable(sorter)
 	case etcd.SortDescend:
-		sort.Sort(sort.Reverse(sorter))
+		sort.Stable(sort.Reverse(sorter))
 	}
 	for _, kv := range kvs {
 		if strings.Contains(strings.TrimPrefix(string(kv.Key), prefix), indexIdentifier) {
 			continue
 		}
+		if !afterCursor(cursor, opts, kv) {
+			continue
+		}
+		moveCursor(opts, kv)
 		if err := f(kv); err != nil {
 			if err == errutil.ErrBreak {
 				return nil
@@ -145,11 +284,6 @@ func listSelfSortRevision(c *readonlyCollection, prefix string, limitPtr *int64,
 	return nil
 }
 
-func endKeyFromPrefix(prefix string) string {
-	// Lexicographically increment the last character
-	return prefix[0:len(prefix)-1] + string(byte(prefix[len(prefix)-1])+1)
-}
-
 func (s *kvSort) Len() int {
 	return len(s.kvs)
 }
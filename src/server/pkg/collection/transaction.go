@@ -57,19 +57,34 @@ type STM interface {
 	commit() *v3.TxnResponse
 	reset()
 	fetch(key string) *v3.GetResponse
+	// abort releases anything held by a txn that won't be committed
+	abort()
 }
 
 // stmError safely passes STM errors through panic to the STM error channel.
 type stmError struct{ err error }
 
-// NewSTM intiates a new STM operation. It uses a serializable model.
+// stmConflict is passed through panic when a txn's read conflicts with
+// another txn, in which case the txn is retried
+type stmConflict struct{}
+
+// NewSTM intiates a new STM operation. It uses a serializable model. If
+// collections are stored in Postgres (see UsePostgres), the transaction runs
+// there, and 'c' is unused.
 func NewSTM(ctx context.Context, c *v3.Client, apply func(STM) error) (*v3.TxnResponse, error) {
+	if postgresStore != nil {
+		return runSTM(newPostgresSTM(ctx, postgresStore), apply, false)
+	}
 	return newSTMSerializable(ctx, c, apply, false)
 }
 
 // NewDryrunSTM intiates a new STM operation, but the final commit is skipped.
 // It uses a serializable model.
 func NewDryrunSTM(ctx context.Context, c *v3.Client, apply func(STM) error) error {
+	if postgresStore != nil {
+		_, err := runSTM(newPostgresSTM(ctx, postgresStore), apply, true)
+		return err
+	}
 	_, err := newSTMSerializable(ctx, c, apply, true)
 	return err
 }
@@ -102,10 +117,15 @@ func runSTM(s STM, apply func(STM) error, dryrun bool) (*v3.TxnResponse, error)
 				outc <- stmResponse{nil, e.err}
 			}
 		}()
+		defer s.abort()
 		var out stmResponse
 		for {
 			s.reset()
-			if out.err = apply(s); out.err != nil {
+			var conflict bool
+			if conflict, out.err = tryApply(s, apply); conflict {
+				continue
+			}
+			if out.err != nil {
 				break
 			}
 			if dryrun {
@@ -120,6 +140,20 @@ func runSTM(s STM, apply func(STM) error, dryrun bool) (*v3.TxnResponse, error)
 	return r.resp, r.err
 }
 
+// tryApply calls 'apply', and returns true if it was interrupted by a
+// conflicting read (see stmConflict)
+func tryApply(s STM, apply func(STM) error) (conflict bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(stmConflict); !ok {
+				panic(r)
+			}
+			conflict = true
+		}
+	}()
+	return false, apply(s)
+}
+
 // stm implements repeatable-read software transactional memory over etcd
 type stm struct {
 	client *v3.Client
@@ -181,7 +215,11 @@ func (s *stm) IsSafePut(key string, ptr uintptr) bool {
 }
 
 func (s *stm) isKeyRangeDeleted(key string) bool {
-	for _, prefix := range s.deletedPrefixes {
+	return isKeyRangeDeleted(s.deletedPrefixes, key)
+}
+
+func isKeyRangeDeleted(deletedPrefixes []string, key string) bool {
+	for _, prefix := range deletedPrefixes {
 		if strings.HasPrefix(key, prefix) {
 			return true
 		}
@@ -215,31 +253,38 @@ func (s *stm) Del(key string) {
 }
 
 func (s *stm) DelAll(prefix string) {
+	s.deletedPrefixes = delAll(s.deletedPrefixes, s.wset, prefix)
+}
+
+// delAll adds 'prefix' to 'deletedPrefixes' (returning the result), and
+// removes the writes under 'prefix' from 'wset'
+func delAll(deletedPrefixes []string, wset map[string]stmPut, prefix string) []string {
 	// Remove any eclipsed deletes then add the new delete
 	isEclipsed := false
 	i := 0
-	for _, deletedPrefix := range s.deletedPrefixes {
+	for _, deletedPrefix := range deletedPrefixes {
 		if strings.HasPrefix(prefix, deletedPrefix) {
 			isEclipsed = true
 		}
 		if !strings.HasPrefix(deletedPrefix, prefix) {
-			s.deletedPrefixes[i] = deletedPrefix
+			deletedPrefixes[i] = deletedPrefix
 			i++
 		}
 	}
-	s.deletedPrefixes = s.deletedPrefixes[:i]
+	deletedPrefixes = deletedPrefixes[:i]
 
 	// If the new DelAll prefix is eclipsed by an already-deleted prefix, don't
 	// add it to the set, but still clean up any eclipsed writes.
 	if !isEclipsed {
-		s.deletedPrefixes = append(s.deletedPrefixes, prefix)
+		deletedPrefixes = append(deletedPrefixes, prefix)
 	}
 
-	for k := range s.wset {
+	for k := range wset {
 		if strings.HasPrefix(k, prefix) {
-			delete(s.wset, k)
+			delete(wset, k)
 		}
 	}
+	return deletedPrefixes
 }
 
 func (s *stm) Rev(key string) int64 {
@@ -339,6 +384,8 @@ func (s *stm) writes() []v3.Op {
 	return writes
 }
 
+func (s *stm) abort() {}
+
 func (s *stm) reset() {
 	s.rset = make(map[string]*v3.GetResponse)
 	s.wset = make(map[string]stmPut)
@@ -100,6 +100,12 @@ type ReadWriteIntCollection interface {
 type ReadonlyCollection interface {
 	Get(key string, val proto.Message) error
 	GetByIndex(index *Index, indexVal interface{}, val proto.Message, opts *Options, f func(key string) error) error
+	// GetByIndexRange is like GetByIndex, but calls 'f' with the items whose
+	// index values are from 'from' (inclusive) to 'to' (exclusive). Values
+	// are compared as strings, so the index's values must have a fixed width
+	// (e.g. timestamps in a fixed format). A nil 'from' or 'to' leaves that
+	// end of the range open.
+	GetByIndexRange(index *Index, from, to interface{}, val proto.Message, opts *Options, f func(key string) error) error
 	// GetBlock is like Get but waits for the key to exist if it doesn't already.
 	GetBlock(key string, val proto.Message) error
 	// TTL returns the number of seconds that 'key' will continue to exist in the
@@ -0,0 +1,152 @@
+// Package columnar splits files in columnar and row-oriented binary formats
+// (Parquet and Avro) on the boundaries of the record batches that they're
+// made of, so that each piece can be processed on its own.
+package columnar
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+var avroMagic = []byte("Obj\x01")
+
+const avroSyncSize = 16
+
+// AvroReader parses an Avro object container file into a header and blocks
+// of objects. The header followed by any sequence of the file's blocks is a
+// valid Avro file.
+type AvroReader struct {
+	Header []byte
+	rd     *bufio.Reader
+	// sync is the sync marker of the file being read, which may differ from
+	// the sync marker in Header (see AppendTo)
+	sync []byte
+}
+
+// NewAvroReader creates a new AvroReader
+func NewAvroReader(r *bufio.Reader) *AvroReader {
+	return &AvroReader{
+		rd: r,
+	}
+}
+
+// AppendTo makes the blocks that r reads continue the Avro file whose header
+// is 'header' (which becomes r's Header), by replacing their sync markers with
+// the sync marker in 'header'. It must be called before ReadBlock. The schema
+// in 'header' isn't checked against the schema of the file being read.
+func (r *AvroReader) AppendTo(header []byte) error {
+	existing := NewAvroReader(bufio.NewReader(bytes.NewReader(header)))
+	if err := existing.readHeader(); err != nil {
+		return fmt.Errorf("existing header is not an avro header: %v", err)
+	}
+	r.Header = header
+	return nil
+}
+
+// ReadBlock returns the next block of the file, including its object count,
+// size and sync marker. The file's header is read (and Header populated,
+// unless AppendTo was called) before the first block. It returns io.EOF after
+// the last block.
+func (r *AvroReader) ReadBlock() ([]byte, error) {
+	if r.sync == nil {
+		if err := r.readHeader(); err != nil {
+			return nil, err
+		}
+	}
+	var block bytes.Buffer
+	if _, err := r.rd.Peek(1); err == io.EOF {
+		return nil, io.EOF
+	}
+	count, err := r.readLong(&block)
+	if err != nil {
+		return nil, err
+	}
+	size, err := r.readLong(&block)
+	if err != nil {
+		return nil, err
+	}
+	if count < 0 || size < 0 {
+		return nil, fmt.Errorf("invalid avro block with %d objects and %d bytes", count, size)
+	}
+	if _, err := io.CopyN(&block, r.rd, size+avroSyncSize); err != nil {
+		return nil, fmt.Errorf("error reading avro block: %v", unexpectedEOF(err))
+	}
+	if !bytes.Equal(block.Bytes()[block.Len()-avroSyncSize:], r.sync) {
+		return nil, fmt.Errorf("invalid avro block: sync marker doesn't match the header's")
+	}
+	result := block.Bytes()
+	copy(result[len(result)-avroSyncSize:], r.Header[len(r.Header)-avroSyncSize:])
+	return result, nil
+}
+
+// readHeader reads the magic bytes, metadata and sync marker at the start
+// of the file
+func (r *AvroReader) readHeader() error {
+	var header bytes.Buffer
+	if _, err := io.CopyN(&header, r.rd, int64(len(avroMagic))); err != nil || !bytes.Equal(header.Bytes(), avroMagic) {
+		return fmt.Errorf("invalid avro file: missing magic bytes")
+	}
+	// The metadata is a map, which is encoded as blocks of entries, ending
+	// with an empty block
+	for {
+		count, err := r.readLong(&header)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			break
+		}
+		if count < 0 {
+			// Negative counts are followed by the size of the block
+			count = -count
+			if _, err := r.readLong(&header); err != nil {
+				return err
+			}
+		}
+		for i := int64(0); i < 2*count; i++ {
+			// Each entry is a string key and a bytes value, both of which are
+			// a length followed by that many bytes
+			n, err := r.readLong(&header)
+			if err != nil {
+				return err
+			}
+			if _, err := io.CopyN(&header, r.rd, n); err != nil {
+				return fmt.Errorf("error reading avro header: %v", unexpectedEOF(err))
+			}
+		}
+	}
+	if _, err := io.CopyN(&header, r.rd, avroSyncSize); err != nil {
+		return fmt.Errorf("error reading avro header: %v", unexpectedEOF(err))
+	}
+	if r.Header == nil {
+		r.Header = header.Bytes()
+	}
+	r.sync = header.Bytes()[header.Len()-avroSyncSize:]
+	return nil
+}
+
+// readLong reads a zig-zag encoded varint, and copies its bytes to 'buf'
+func (r *AvroReader) readLong(buf *bytes.Buffer) (int64, error) {
+	var n uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		b, err := r.rd.ReadByte()
+		if err != nil {
+			return 0, fmt.Errorf("error reading avro file: %v", unexpectedEOF(err))
+		}
+		buf.WriteByte(b)
+		n |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return int64(n>>1) ^ -int64(n&1), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid avro file: varint is too long")
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,161 @@
+package columnar
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+type parquetRow struct {
+	ID   int64  `parquet:"name=id, type=INT64"`
+	Name string `parquet:"name=name, type=UTF8, encoding=PLAIN_DICTIONARY"`
+}
+
+// parquetFile returns a parquet file with one row group per element of
+// 'groups'
+func parquetFile(t *testing.T, groups ...[]parquetRow) []byte {
+	file, err := buffer.NewBufferFile(nil)
+	require.NoError(t, err)
+	pw, err := writer.NewParquetWriter(file, new(parquetRow), 1)
+	require.NoError(t, err)
+	for _, rows := range groups {
+		for _, row := range rows {
+			require.NoError(t, pw.Write(row))
+		}
+		require.NoError(t, pw.Flush(true))
+	}
+	require.NoError(t, pw.WriteStop())
+	return file.(buffer.BufferFile).Bytes()
+}
+
+func readParquetRows(t *testing.T, data []byte) []parquetRow {
+	file, err := buffer.NewBufferFile(data)
+	require.NoError(t, err)
+	pr, err := reader.NewParquetReader(file, new(parquetRow), 1)
+	require.NoError(t, err)
+	defer pr.ReadStop()
+	rows := make([]parquetRow, pr.GetNumRows())
+	require.NoError(t, pr.Read(&rows))
+	return rows
+}
+
+func TestSplitParquet(t *testing.T) {
+	groups := [][]parquetRow{
+		{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}},
+		{{ID: 3, Name: "c"}},
+		{{ID: 4, Name: "d"}, {ID: 5, Name: "e"}, {ID: 6, Name: "a"}},
+	}
+	data := parquetFile(t, groups...)
+	r, err := NewParquetReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	// Each row group on its own
+	group, err := r.ReadRowGroup()
+	require.NoError(t, err)
+	file, err := r.File(group)
+	require.NoError(t, err)
+	require.Equal(t, groups[0], readParquetRows(t, file))
+
+	// Several row groups in one file
+	var buf bytes.Buffer
+	for i := 0; i < 2; i++ {
+		group, err := r.ReadRowGroup()
+		require.NoError(t, err)
+		buf.Write(group)
+	}
+	file, err = r.File(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, append(groups[1], groups[2]...), readParquetRows(t, file))
+
+	_, err = r.ReadRowGroup()
+	require.Equal(t, io.EOF, err)
+
+	_, err = NewParquetReader(bytes.NewReader([]byte("not parquet")), 11)
+	require.YesError(t, err)
+}
+
+// avroLong appends the zig-zag varint encoding of 'n' to 'buf'
+func avroLong(buf *bytes.Buffer, n int64) {
+	u := uint64((n << 1) ^ (n >> 63))
+	for u >= 0x80 {
+		buf.WriteByte(byte(u) | 0x80)
+		u >>= 7
+	}
+	buf.WriteByte(byte(u))
+}
+
+func avroString(buf *bytes.Buffer, s string) {
+	avroLong(buf, int64(len(s)))
+	buf.WriteString(s)
+}
+
+// avroFile returns an avro file of longs, with one block per element of
+// 'blocks'. It also returns the file's header.
+func avroFile(sync string, blocks ...[]int64) (file []byte, header []byte) {
+	var buf bytes.Buffer
+	buf.Write(avroMagic)
+	avroLong(&buf, 1)
+	avroString(&buf, "avro.schema")
+	avroString(&buf, `"long"`)
+	avroLong(&buf, 0)
+	buf.WriteString(sync)
+	header = append([]byte{}, buf.Bytes()...)
+	for _, block := range blocks {
+		var objects bytes.Buffer
+		for _, n := range block {
+			avroLong(&objects, n)
+		}
+		avroLong(&buf, int64(len(block)))
+		avroLong(&buf, int64(objects.Len()))
+		buf.Write(objects.Bytes())
+		buf.WriteString(sync)
+	}
+	return buf.Bytes(), header
+}
+
+func TestSplitAvro(t *testing.T) {
+	sync := "0123456789abcdef"
+	data, header := avroFile(sync, []int64{1, 2}, []int64{-300}, []int64{3})
+	r := NewAvroReader(bufio.NewReader(bytes.NewReader(data)))
+	var blocks [][]byte
+	for {
+		block, err := r.ReadBlock()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		blocks = append(blocks, block)
+	}
+	require.Equal(t, header, r.Header)
+	require.Equal(t, 3, len(blocks))
+	// The header followed by the blocks is the original file
+	require.Equal(t, data, bytes.Join(append([][]byte{r.Header}, blocks...), nil))
+	one, _ := avroFile(sync, []int64{-300})
+	require.Equal(t, one, append(append([]byte{}, r.Header...), blocks[1]...))
+
+	// Blocks appended to another file get its sync marker
+	_, existing := avroFile("fedcba9876543210")
+	r = NewAvroReader(bufio.NewReader(bytes.NewReader(data)))
+	require.NoError(t, r.AppendTo(existing))
+	block, err := r.ReadBlock()
+	require.NoError(t, err)
+	require.Equal(t, existing, r.Header)
+	appended, _ := avroFile("fedcba9876543210", []int64{1, 2})
+	require.Equal(t, appended, append(append([]byte{}, existing...), block...))
+
+	// Corrupt files are rejected
+	r = NewAvroReader(bufio.NewReader(bytes.NewReader(data[:len(data)-1])))
+	var readErr error
+	for readErr == nil {
+		_, readErr = r.ReadBlock()
+	}
+	require.NotEqual(t, io.EOF, readErr)
+	_, err = NewAvroReader(bufio.NewReader(bytes.NewReader([]byte("not avro")))).ReadBlock()
+	require.YesError(t, err)
+}
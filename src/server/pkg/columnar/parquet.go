@@ -0,0 +1,173 @@
+package columnar
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+var parquetMagic = []byte("PAR1")
+
+// ParquetReader reads the row groups of a Parquet file. Because a Parquet
+// file's metadata is at its end, and records the offsets of its row groups,
+// row groups can't simply be concatenated like Avro blocks. Instead, File
+// rewrites the metadata so that the row groups read since the last call to
+// File form a complete Parquet file.
+type ParquetReader struct {
+	r        io.ReaderAt
+	metadata *parquet.FileMetaData
+	next     int
+	pending  []*parquetRowGroup
+}
+
+// parquetRowGroup is a row group, and the position of its column chunks in
+// the file that it was read from
+type parquetRowGroup struct {
+	group         *parquet.RowGroup
+	start, length int64
+}
+
+// NewParquetReader reads the metadata of the Parquet file 'r', which is
+// 'size' bytes long
+func NewParquetReader(r io.ReaderAt, size int64) (*ParquetReader, error) {
+	tail := make([]byte, 8)
+	if size < int64(len(parquetMagic)+len(tail)) {
+		return nil, fmt.Errorf("invalid parquet file: too short")
+	}
+	if _, err := r.ReadAt(tail, size-int64(len(tail))); err != nil {
+		return nil, fmt.Errorf("error reading parquet footer: %v", err)
+	}
+	head := make([]byte, len(parquetMagic))
+	if _, err := r.ReadAt(head, 0); err != nil {
+		return nil, fmt.Errorf("error reading parquet header: %v", err)
+	}
+	if !bytes.Equal(head, parquetMagic) || !bytes.Equal(tail[4:], parquetMagic) {
+		return nil, fmt.Errorf("invalid parquet file: missing magic bytes")
+	}
+	footerSize := int64(binary.LittleEndian.Uint32(tail[:4]))
+	footerStart := size - int64(len(tail)) - footerSize
+	if footerStart < int64(len(parquetMagic)) {
+		return nil, fmt.Errorf("invalid parquet file: footer size %d is too large", footerSize)
+	}
+	footer := make([]byte, footerSize)
+	if _, err := r.ReadAt(footer, footerStart); err != nil {
+		return nil, fmt.Errorf("error reading parquet footer: %v", err)
+	}
+	deserializer := thrift.NewTDeserializer()
+	deserializer.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(deserializer.Transport)
+	metadata := parquet.NewFileMetaData()
+	if err := deserializer.Read(metadata, footer); err != nil {
+		return nil, fmt.Errorf("error parsing parquet footer: %v", err)
+	}
+	return &ParquetReader{
+		r:        r,
+		metadata: metadata,
+	}, nil
+}
+
+// ReadRowGroup returns the column chunks of the next row group in the file.
+// It returns io.EOF after the last row group.
+func (r *ParquetReader) ReadRowGroup() ([]byte, error) {
+	if r.next >= len(r.metadata.RowGroups) {
+		return nil, io.EOF
+	}
+	group := r.metadata.RowGroups[r.next]
+	r.next++
+	start, end := int64(-1), int64(-1)
+	for _, column := range group.Columns {
+		if column.FilePath != nil || column.MetaData == nil {
+			return nil, fmt.Errorf("parquet files with column chunks in other files are not supported")
+		}
+		columnStart := chunkStart(column.MetaData)
+		if start < 0 || columnStart < start {
+			start = columnStart
+		}
+		if columnEnd := columnStart + column.MetaData.TotalCompressedSize; columnEnd > end {
+			end = columnEnd
+		}
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("invalid parquet file: row group %d has no columns", r.next-1)
+	}
+	data := make([]byte, end-start)
+	if _, err := r.r.ReadAt(data, start); err != nil {
+		return nil, fmt.Errorf("error reading parquet row group: %v", err)
+	}
+	r.pending = append(r.pending, &parquetRowGroup{
+		group:  group,
+		start:  start,
+		length: end - start,
+	})
+	return data, nil
+}
+
+// File returns a Parquet file containing the row groups read since the last
+// call to File, whose concatenated column chunks are 'data'
+func (r *ParquetReader) File(data []byte) ([]byte, error) {
+	metadata := *r.metadata
+	metadata.NumRows = 0
+	metadata.RowGroups = nil
+	offset := int64(len(parquetMagic))
+	for _, p := range r.pending {
+		metadata.RowGroups = append(metadata.RowGroups, moveRowGroup(p.group, offset-p.start))
+		metadata.NumRows += p.group.NumRows
+		offset += p.length
+	}
+	r.pending = nil
+	if offset != int64(len(parquetMagic)+len(data)) {
+		return nil, fmt.Errorf("parquet row groups are %d bytes, but %d bytes were passed", offset-int64(len(parquetMagic)), len(data))
+	}
+	serializer := thrift.NewTSerializer()
+	serializer.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(serializer.Transport)
+	footer, err := serializer.Write(context.Background(), &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing parquet footer: %v", err)
+	}
+	var buf bytes.Buffer
+	buf.Write(parquetMagic)
+	buf.Write(data)
+	buf.Write(footer)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(footer)))
+	buf.Write(parquetMagic)
+	return buf.Bytes(), nil
+}
+
+// chunkStart returns the offset of the first page of a column chunk
+func chunkStart(metadata *parquet.ColumnMetaData) int64 {
+	if metadata.DictionaryPageOffset != nil && *metadata.DictionaryPageOffset > 0 &&
+		*metadata.DictionaryPageOffset < metadata.DataPageOffset {
+		return *metadata.DictionaryPageOffset
+	}
+	return metadata.DataPageOffset
+}
+
+// moveRowGroup returns a copy of 'group' with its offsets moved by 'shift'
+// bytes. Page indexes aren't copied, as they aren't part of the row group.
+func moveRowGroup(group *parquet.RowGroup, shift int64) *parquet.RowGroup {
+	result := *group
+	result.Columns = make([]*parquet.ColumnChunk, len(group.Columns))
+	for i, column := range group.Columns {
+		c := *column
+		metadata := *column.MetaData
+		c.FileOffset += shift
+		metadata.DataPageOffset += shift
+		if metadata.DictionaryPageOffset != nil && *metadata.DictionaryPageOffset > 0 {
+			offset := *metadata.DictionaryPageOffset + shift
+			metadata.DictionaryPageOffset = &offset
+		}
+		if metadata.IndexPageOffset != nil {
+			offset := *metadata.IndexPageOffset + shift
+			metadata.IndexPageOffset = &offset
+		}
+		c.MetaData = &metadata
+		c.OffsetIndexOffset, c.OffsetIndexLength = nil, nil
+		c.ColumnIndexOffset, c.ColumnIndexLength = nil, nil
+		result.Columns[i] = &c
+	}
+	return &result
+}
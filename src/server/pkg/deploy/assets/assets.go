@@ -52,6 +52,8 @@ var (
 	pachdName                   = "pachd"
 	// PrometheusPort hosts the prometheus stats for scraping
 	PrometheusPort = 656
+	// ArtifactCachePort hosts the artifact cache, if it's enabled
+	ArtifactCachePort = 657
 
 	// Role & binding names, used for Roles or ClusterRoles and their associated
 	// bindings.
@@ -61,15 +63,19 @@ var (
 	rolePolicyRules = []rbacv1.PolicyRule{{
 		APIGroups: []string{""},
 		Verbs:     []string{"get", "list", "watch"},
-		Resources: []string{"nodes", "pods", "pods/log", "endpoints"},
+		Resources: []string{"nodes", "pods", "pods/log", "endpoints", "events", "configmaps"},
 	}, {
 		APIGroups: []string{""},
 		Verbs:     []string{"get", "list", "watch", "create", "update", "delete"},
-		Resources: []string{"replicationcontrollers", "services"},
+		Resources: []string{"replicationcontrollers", "services", "serviceaccounts"},
 	}, {
 		APIGroups: []string{""},
 		Verbs:     []string{"get", "list", "watch", "create", "update", "delete", "deletecollection"},
 		Resources: []string{"secrets"},
+	}, {
+		APIGroups: []string{"networking.k8s.io"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "delete"},
+		Resources: []string{"networkpolicies"},
 	}}
 
 	// The name of the local volume (mounted kubernetes secret) where pachd
@@ -105,6 +111,7 @@ const (
 	googleBackend
 	microsoftBackend
 	minioBackend
+	adlsBackend
 	s3CustomArgs = 6
 )
 
@@ -124,6 +131,9 @@ type FeatureFlags struct {
 const (
 	// UploadConcurrencyLimitEnvVar is the environment variable for the upload concurrency limit.
 	UploadConcurrencyLimitEnvVar = "STORAGE_UPLOAD_CONCURRENCY_LIMIT"
+	// CompressionEnvVar is the environment variable for the codec that chunks
+	// are compressed with.
+	CompressionEnvVar = "STORAGE_COMPRESSION"
 )
 
 const (
@@ -136,6 +146,12 @@ const (
 // StorageOpts are options that are applicable to the storage layer.
 type StorageOpts struct {
 	UploadConcurrencyLimit int
+	// Policy controls how pachd and its workers retry and throttle object
+	// storage requests (see obj.ClientPolicy). Zero fields use obj's defaults.
+	Policy obj.ClientPolicy
+	// Encryption, if its Key is set, makes pachd and its workers encrypt
+	// objects before they're uploaded (see obj.EncryptedObjClient)
+	Encryption obj.EncryptionConfig
 }
 
 const (
@@ -251,6 +267,52 @@ type AssetOpts struct {
 	// RequireCriticalServersOnly is true when only the critical Pachd servers
 	// are required to startup and run without error.
 	RequireCriticalServersOnly bool
+
+	// PipelineDefaults is a partial pipeline spec (JSON or YAML) whose fields
+	// pachd uses as defaults for every pipeline created in the cluster.
+	PipelineDefaults string
+
+	// ImagePinning is the minimum image pinning policy ("none", "pin" or
+	// "strict") that pachd applies to every pipeline created in the cluster.
+	ImagePinning string
+
+	// WorkerNetworkPolicy, if set, makes pachd create a network policy for
+	// every pipeline, which only lets its workers reach pachd, etcd, DNS, the
+	// CIDRs in WorkerNetworkPolicyAllow and the destinations that the
+	// pipeline allows.
+	WorkerNetworkPolicy bool
+
+	// WorkerNetworkPolicyAllow are CIDRs that every pipeline's workers can
+	// reach when they have a network policy, e.g. the object store's.
+	WorkerNetworkPolicyAllow []string
+
+	// WorkerRPCCacheSize is the size in bytes of each pipeline worker's cache
+	// of pachd responses that can't change, or 0 if workers don't cache them.
+	WorkerRPCCacheSize int64
+
+	// WorkerBlockSharingCacheSize is the size in bytes of each pipeline
+	// worker's on-disk cache of the blocks that it shares with the pipeline's
+	// other workers on its node, or 0 if workers don't share blocks.
+	WorkerBlockSharingCacheSize int64
+
+	// ArtifactCache, if set, makes pachd serve a pull-through cache for PyPI,
+	// npm and conda that pipeline workers use to download their dependencies.
+	ArtifactCache bool
+
+	// PipelineCRD, if set, creates the Pipeline custom resource definition
+	// and makes pachd create, update and delete a pipeline for each Pipeline
+	// resource in its namespace.
+	PipelineCRD bool
+
+	// InternalTLS, if set, generates a certificate authority for the cluster,
+	// and makes pachd, workers and etcd authenticate each other with
+	// short-lived certs that it issues (see the mtls package)
+	InternalTLS bool
+
+	// MetadataStore is where pachd stores collections: "etcd" (the default)
+	// or "postgres", in which case PostgresURL is the database's URL
+	MetadataStore string
+	PostgresURL   string
 }
 
 // replicas lets us create a pointer to a non-zero int32 in-line. This is
@@ -330,6 +392,15 @@ func ServiceAccount(opts *AssetOpts) *v1.ServiceAccount {
 	}
 }
 
+// policyRules returns the rules of Pachyderm's Role or ClusterRole
+func policyRules(opts *AssetOpts) []rbacv1.PolicyRule {
+	rules := append([]rbacv1.PolicyRule{}, rolePolicyRules...)
+	if opts.PipelineCRD {
+		rules = append(rules, pipelineCRDPolicyRules...)
+	}
+	return rules
+}
+
 // ClusterRole returns a ClusterRole that should be bound to the Pachyderm service account.
 func ClusterRole(opts *AssetOpts) *rbacv1.ClusterRole {
 	return &rbacv1.ClusterRole{
@@ -338,7 +409,7 @@ func ClusterRole(opts *AssetOpts) *rbacv1.ClusterRole {
 			APIVersion: "rbac.authorization.k8s.io/v1",
 		},
 		ObjectMeta: objectMeta(roleName, labels(""), nil, opts.Namespace),
-		Rules:      rolePolicyRules,
+		Rules:      policyRules(opts),
 	}
 }
 
@@ -371,7 +442,7 @@ func Role(opts *AssetOpts) *rbacv1.Role {
 			APIVersion: "rbac.authorization.k8s.io/v1",
 		},
 		ObjectMeta: objectMeta(roleName, labels(""), nil, opts.Namespace),
-		Rules:      rolePolicyRules,
+		Rules:      policyRules(opts),
 	}
 }
 
@@ -441,9 +512,30 @@ func GetSecretEnvVars(storageBackend string) []v1.EnvVar {
 }
 
 func getStorageEnvVars(opts *AssetOpts) []v1.EnvVar {
-	return []v1.EnvVar{
+	envVars := []v1.EnvVar{
 		{Name: UploadConcurrencyLimitEnvVar, Value: strconv.Itoa(opts.StorageOpts.UploadConcurrencyLimit)},
 	}
+	policy := opts.StorageOpts.Policy
+	for _, e := range []struct {
+		name  string
+		value string
+		set   bool
+	}{
+		{obj.MaxAttemptsEnvVar, strconv.Itoa(policy.MaxAttempts), policy.MaxAttempts != 0},
+		{obj.BackoffInitialEnvVar, policy.BackoffInitial.String(), policy.BackoffInitial != 0},
+		{obj.BackoffMaxEnvVar, policy.BackoffMax.String(), policy.BackoffMax != 0},
+		{obj.MaxConcurrencyEnvVar, strconv.Itoa(policy.MaxConcurrency), policy.MaxConcurrency != 0},
+		{obj.RateLimitEnvVar, strconv.FormatFloat(policy.RateLimit, 'g', -1, 64), policy.RateLimit != 0},
+		{obj.CircuitBreakerThresholdEnvVar, strconv.Itoa(policy.CircuitBreakerThreshold), policy.CircuitBreakerThreshold != 0},
+		{obj.CircuitBreakerCooldownEnvVar, policy.CircuitBreakerCooldown.String(), policy.CircuitBreakerCooldown != 0},
+		{obj.EncryptionKeyEnvVar, opts.Encryption.Key, opts.Encryption.Key != ""},
+		{obj.EncryptionKeyReuseEnvVar, opts.Encryption.KeyReuse.String(), opts.Encryption.Key != "" && opts.Encryption.KeyReuse != 0},
+	} {
+		if e.set {
+			envVars = append(envVars, v1.EnvVar{Name: e.name, Value: e.value})
+		}
+	}
+	return envVars
 }
 
 func versionedPachdImage(opts *AssetOpts) string {
@@ -519,10 +611,17 @@ func PachdDeployment(opts *AssetOpts, objectStoreBackend backend, hostPath strin
 		backendEnvVar = pfs.GoogleBackendEnvVar
 	case microsoftBackend:
 		backendEnvVar = pfs.MicrosoftBackendEnvVar
+	case adlsBackend:
+		backendEnvVar = pfs.ADLSBackendEnvVar
 	}
 	volume, mount := GetBackendSecretVolumeAndMount(backendEnvVar)
 	volumes = append(volumes, volume)
 	volumeMounts = append(volumeMounts, mount)
+	if opts.InternalTLS {
+		volume, mount := internalCAVolume()
+		volumes = append(volumes, volume)
+		volumeMounts = append(volumeMounts, mount)
+	}
 	if opts.TLS != nil {
 		volumes = append(volumes, v1.Volume{
 			Name: tlsVolumeName,
@@ -590,9 +689,56 @@ func PachdDeployment(opts *AssetOpts, objectStoreBackend backend, hostPath strin
 		{Name: "EXPOSE_OBJECT_API", Value: strconv.FormatBool(opts.ExposeObjectAPI)},
 		{Name: "CLUSTER_DEPLOYMENT_ID", Value: opts.ClusterDeploymentID},
 		{Name: RequireCriticalServersOnlyEnvVar, Value: strconv.FormatBool(opts.RequireCriticalServersOnly)},
+		{Name: "PIPELINE_DEFAULTS", Value: opts.PipelineDefaults},
+		{Name: "IMAGE_PINNING", Value: opts.ImagePinning},
+		{Name: "WORKER_NETWORK_POLICY", Value: strconv.FormatBool(opts.WorkerNetworkPolicy)},
+		{Name: "PIPELINE_CRD", Value: strconv.FormatBool(opts.PipelineCRD)},
+		{Name: "WORKER_NETWORK_POLICY_ALLOW", Value: strings.Join(opts.WorkerNetworkPolicyAllow, ",")},
+		{Name: "WORKER_RPC_CACHE_SIZE", Value: strconv.FormatInt(opts.WorkerRPCCacheSize, 10)},
+		{Name: "WORKER_BLOCK_SHARING_CACHE_SIZE", Value: strconv.FormatInt(opts.WorkerBlockSharingCacheSize, 10)},
+	}
+	ports := []v1.ContainerPort{
+		{
+			ContainerPort: opts.PachdPort, // also set in cmd/pachd/main.go
+			Protocol:      "TCP",
+			Name:          "api-grpc-port",
+		},
+		{
+			ContainerPort: opts.TracePort, // also set in cmd/pachd/main.go
+			Name:          "trace-port",
+		},
+		{
+			ContainerPort: opts.HTTPPort, // also set in cmd/pachd/main.go
+			Protocol:      "TCP",
+			Name:          "api-http-port",
+		},
+		{
+			ContainerPort: opts.PeerPort, // also set in cmd/pachd/main.go
+			Protocol:      "TCP",
+			Name:          "peer-port",
+		},
+		{
+			ContainerPort: githook.GitHookPort,
+			Protocol:      "TCP",
+			Name:          "api-git-port",
+		},
+		{
+			ContainerPort: auth.SamlPort,
+			Protocol:      "TCP",
+			Name:          "saml-port",
+		},
+	}
+	if opts.ArtifactCache {
+		envVars = append(envVars, v1.EnvVar{Name: "ARTIFACT_CACHE_PORT", Value: strconv.Itoa(ArtifactCachePort)})
+		ports = append(ports, v1.ContainerPort{
+			ContainerPort: int32(ArtifactCachePort),
+			Protocol:      "TCP",
+			Name:          "artifact-cache-port",
+		})
 	}
 	envVars = append(envVars, GetSecretEnvVars("")...)
 	envVars = append(envVars, getStorageEnvVars(opts)...)
+	envVars = append(envVars, MetadataStoreEnvVars(opts.MetadataStore)...)
 	return &apps.Deployment{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Deployment",
@@ -613,37 +759,7 @@ func PachdDeployment(opts *AssetOpts, objectStoreBackend backend, hostPath strin
 							Name:  pachdName,
 							Image: image,
 							Env:   envVars,
-							Ports: []v1.ContainerPort{
-								{
-									ContainerPort: opts.PachdPort, // also set in cmd/pachd/main.go
-									Protocol:      "TCP",
-									Name:          "api-grpc-port",
-								},
-								{
-									ContainerPort: opts.TracePort, // also set in cmd/pachd/main.go
-									Name:          "trace-port",
-								},
-								{
-									ContainerPort: opts.HTTPPort, // also set in cmd/pachd/main.go
-									Protocol:      "TCP",
-									Name:          "api-http-port",
-								},
-								{
-									ContainerPort: opts.PeerPort, // also set in cmd/pachd/main.go
-									Protocol:      "TCP",
-									Name:          "peer-port",
-								},
-								{
-									ContainerPort: githook.GitHookPort,
-									Protocol:      "TCP",
-									Name:          "api-git-port",
-								},
-								{
-									ContainerPort: auth.SamlPort,
-									Protocol:      "TCP",
-									Name:          "saml-port",
-								},
-							},
+							Ports: ports,
 							VolumeMounts:    volumeMounts,
 							ImagePullPolicy: "IfNotPresent",
 							Resources:       resourceRequirements,
@@ -671,7 +787,7 @@ func PachdService(opts *AssetOpts) *v1.Service {
 		"prometheus.io/scrape": "true",
 		"prometheus.io/port":   strconv.Itoa(PrometheusPort),
 	}
-	return &v1.Service{
+	service := &v1.Service{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Service",
 			APIVersion: "v1",
@@ -718,6 +834,15 @@ func PachdService(opts *AssetOpts) *v1.Service {
 			},
 		},
 	}
+	if opts.ArtifactCache {
+		// Workers reach the artifact cache through this service (at
+		// PACHD_SERVICE_HOST), so it needn't be exposed outside of the cluster
+		service.Spec.Ports = append(service.Spec.Ports, v1.ServicePort{
+			Port: int32(ArtifactCachePort),
+			Name: "artifact-cache-port",
+		})
+	}
+	return service
 }
 
 // PachdPeerService returns an internal pachd service. This service will
@@ -814,6 +939,19 @@ func EtcdDeployment(opts *AssetOpts, hostPath string) *apps.Deployment {
 			v1.ResourceMemory: mem,
 		}
 	}
+	volumeMounts := []v1.VolumeMount{
+		{
+			Name:      "etcd-storage",
+			MountPath: "/var/data/etcd",
+		},
+	}
+	cmd := etcdCmd
+	if opts.InternalTLS {
+		volume, mount := etcdInternalTLSVolume()
+		volumes = append(volumes, volume)
+		volumeMounts = append(volumeMounts, mount)
+		cmd = withEtcdInternalTLS(cmd, false)
+	}
 	// Don't want to strip the registry out of etcdImage since it's from quay
 	// not docker hub.
 	image := etcdImage
@@ -839,7 +977,7 @@ func EtcdDeployment(opts *AssetOpts, hostPath string) *apps.Deployment {
 							Name:  etcdName,
 							Image: image,
 							//TODO figure out how to get a cluster of these to talk to each other
-							Command: etcdCmd,
+							Command: cmd,
 							Ports: []v1.ContainerPort{
 								{
 									ContainerPort: 2379,
@@ -850,12 +988,7 @@ func EtcdDeployment(opts *AssetOpts, hostPath string) *apps.Deployment {
 									Name:          "peer-port",
 								},
 							},
-							VolumeMounts: []v1.VolumeMount{
-								{
-									Name:      "etcd-storage",
-									MountPath: "/var/data/etcd",
-								},
-							},
+							VolumeMounts:    volumeMounts,
 							ImagePullPolicy: "IfNotPresent",
 							Resources:       resourceRequirements,
 						},
@@ -1052,6 +1185,28 @@ func EtcdStatefulSet(opts *AssetOpts, backend backend, diskSpace int) interface{
 		"--initial-advertise-peer-urls=http://${ETCD_NAME}.etcd-headless.${NAMESPACE}.svc.cluster.local:2380",
 		"--initial-cluster="+strings.Join(initialCluster, ","),
 	)
+	volumeMounts := []interface{}{
+		map[string]interface{}{
+			"name":      etcdVolumeClaimName,
+			"mountPath": "/var/data/etcd",
+		},
+	}
+	var volumes []interface{}
+	if opts.InternalTLS {
+		etcdCmd = withEtcdInternalTLS(etcdCmd, true)
+		volume, mount := etcdInternalTLSVolume()
+		volumes = append(volumes, map[string]interface{}{
+			"name": volume.Name,
+			"secret": map[string]interface{}{
+				"secretName": volume.Secret.SecretName,
+			},
+		})
+		volumeMounts = append(volumeMounts, map[string]interface{}{
+			"name":      mount.Name,
+			"mountPath": mount.MountPath,
+			"readOnly":  true,
+		})
+	}
 	for i, str := range etcdCmd {
 		etcdCmd[i] = fmt.Sprintf("\"%s\"", str) // quote all arguments, for shell
 	}
@@ -1139,6 +1294,7 @@ func EtcdStatefulSet(opts *AssetOpts, backend backend, diskSpace int) interface{
 				},
 				"spec": map[string]interface{}{
 					"imagePullSecrets": imagePullSecrets,
+					"volumes":          volumes,
 					"containers": []interface{}{
 						map[string]interface{}{
 							"name":    etcdName,
@@ -1174,12 +1330,7 @@ func EtcdStatefulSet(opts *AssetOpts, backend backend, diskSpace int) interface{
 									"name":          "peer-port",
 								},
 							},
-							"volumeMounts": []interface{}{
-								map[string]interface{}{
-									"name":      etcdVolumeClaimName,
-									"mountPath": "/var/data/etcd",
-								},
-							},
+							"volumeMounts": volumeMounts,
 							"imagePullPolicy": "IfNotPresent",
 							"resources": map[string]interface{}{
 								"requests": map[string]interface{}{
@@ -1301,6 +1452,15 @@ func WriteSecret(encoder serde.Encoder, data map[string][]byte, opts *AssetOpts)
 	if opts.DashOnly {
 		return nil
 	}
+	// The Vault credentials that data keys are wrapped with are stored with
+	// the object store's
+	if opts.Encryption.VaultAddress != "" {
+		if data == nil {
+			data = make(map[string][]byte)
+		}
+		data["encryption-vault-addr"] = []byte(opts.Encryption.VaultAddress)
+		data["encryption-vault-token"] = []byte(opts.Encryption.VaultToken)
+	}
 	secret := &v1.Secret{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Secret",
@@ -1396,6 +1556,20 @@ func MicrosoftSecret(container string, id string, secret string) map[string][]by
 	}
 }
 
+// ADLSSecret creates an ADLS (Azure Data Lake Storage Gen2) secret with
+// following parameters:
+//   filesystem - ADLS filesystem
+//   account    - Azure storage account name
+//   identity   - client ID of the user-assigned managed identity to use, or ""
+//                to use the node's only managed identity
+func ADLSSecret(filesystem string, account string, identity string) map[string][]byte {
+	return map[string][]byte{
+		"adls-filesystem": []byte(filesystem),
+		"adls-account":    []byte(account),
+		"adls-identity":   []byte(identity),
+	}
+}
+
 // WriteDashboardAssets writes the k8s config for deploying the Pachyderm
 // dashboard to 'encoder'
 func WriteDashboardAssets(encoder serde.Encoder, opts *AssetOpts) error {
@@ -1427,6 +1601,11 @@ func WriteAssets(encoder serde.Encoder, opts *AssetOpts, objectStoreBackend back
 	if err := encoder.Encode(ServiceAccount(opts)); err != nil {
 		return err
 	}
+	if opts.PipelineCRD {
+		if err := encoder.Encode(PipelineCRD()); err != nil {
+			return err
+		}
+	}
 	if !opts.NoRBAC {
 		if opts.LocalRoles {
 			if err := encoder.Encode(Role(opts)); err != nil {
@@ -1516,6 +1695,16 @@ func WriteAssets(encoder serde.Encoder, opts *AssetOpts, objectStoreBackend back
 			return err
 		}
 	}
+	if opts.InternalTLS {
+		if err := WriteInternalTLSSecrets(encoder, opts); err != nil {
+			return err
+		}
+	}
+	if opts.MetadataStore == "postgres" {
+		if err := WritePostgresSecret(encoder, opts); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -1659,6 +1848,15 @@ func WriteGoogleAssets(encoder serde.Encoder, opts *AssetOpts, bucket string, cr
 	return WriteSecret(encoder, GoogleSecret(bucket, cred), opts)
 }
 
+// WriteADLSAssets writes assets to an ADLS backend. Etcd's persistent volumes
+// are Azure disks, as with a microsoft backend.
+func WriteADLSAssets(encoder serde.Encoder, opts *AssetOpts, filesystem string, account string, identity string, volumeSize int) error {
+	if err := WriteAssets(encoder, opts, adlsBackend, microsoftBackend, volumeSize, ""); err != nil {
+		return err
+	}
+	return WriteSecret(encoder, ADLSSecret(filesystem, account, identity), opts)
+}
+
 // WriteMicrosoftAssets writes assets to a microsoft backend
 func WriteMicrosoftAssets(encoder serde.Encoder, opts *AssetOpts, container string, id string, secret string, volumeSize int) error {
 	if err := WriteAssets(encoder, opts, microsoftBackend, microsoftBackend, volumeSize, ""); err != nil {
@@ -1,10 +1,17 @@
 package assets
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/server/pkg/mtls"
+	"github.com/pachyderm/pachyderm/src/server/pkg/serde"
 )
 
 func TestAddRegistry_NoSlash(t *testing.T) {
@@ -36,3 +43,71 @@ func TestAddRegistry_SwitchRegistry(t *testing.T) {
 	img := AddRegistry(registry, imageName)
 	require.Equal(t, expected, img)
 }
+
+func TestPipelineCRDRules(t *testing.T) {
+	opts := &AssetOpts{}
+	require.Equal(t, len(rolePolicyRules), len(ClusterRole(opts).Rules))
+	opts.PipelineCRD = true
+	require.Equal(t, len(rolePolicyRules)+len(pipelineCRDPolicyRules), len(ClusterRole(opts).Rules))
+	require.Equal(t, len(rolePolicyRules)+len(pipelineCRDPolicyRules), len(Role(opts).Rules))
+	// Enabling the CRD doesn't change the shared rules
+	require.Equal(t, 4, len(rolePolicyRules))
+}
+
+func TestWriteHelmChart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var buf bytes.Buffer
+	encoder, err := serde.GetEncoder("yaml", &buf)
+	require.NoError(t, err)
+	opts := &AssetOpts{Namespace: HelmNamespace, Version: HelmVersion, PipelineCRD: true}
+	require.NoError(t, encoder.Encode(ServiceAccount(opts)))
+	require.NoError(t, encoder.Encode(PipelineCRD()))
+	require.NoError(t, WriteHelmChart(dir, buf.Bytes(), "1.10.0"))
+
+	chart, err := ioutil.ReadFile(filepath.Join(dir, "Chart.yaml"))
+	require.NoError(t, err)
+	require.True(t, strings.Contains(string(chart), "version: 1.10.0"))
+	values, err := ioutil.ReadFile(filepath.Join(dir, "values.yaml"))
+	require.NoError(t, err)
+	require.True(t, strings.Contains(string(values), `version: "1.10.0"`))
+	template, err := ioutil.ReadFile(filepath.Join(dir, "templates", "pachyderm.yaml"))
+	require.NoError(t, err)
+	require.True(t, strings.Contains(string(template), HelmNamespace))
+	require.True(t, strings.Contains(string(template), "CustomResourceDefinition"))
+}
+
+func TestInternalTLS(t *testing.T) {
+	cmd := withEtcdInternalTLS([]string{
+		"--listen-client-urls=http://0.0.0.0:2379",
+		"--listen-peer-urls=http://0.0.0.0:2380",
+		"--data-dir=/var/data/etcd",
+	}, false)
+	require.Equal(t, "--listen-client-urls=https://0.0.0.0:2379", cmd[0])
+	// Peer URLs are only changed if etcd nodes use TLS with each other
+	require.Equal(t, "--listen-peer-urls=http://0.0.0.0:2380", cmd[1])
+	require.OneOfEquals(t, "--client-cert-auth", cmd)
+
+	var buf bytes.Buffer
+	encoder, err := serde.GetEncoder("yaml", &buf)
+	require.NoError(t, err)
+	require.NoError(t, WriteInternalTLSSecrets(encoder, &AssetOpts{Namespace: "pach"}))
+	require.True(t, strings.Contains(buf.String(), internalCASecretName))
+	require.True(t, strings.Contains(buf.String(), mtls.EtcdSecretName))
+}
+
+func TestPostgresMetadataStore(t *testing.T) {
+	require.Equal(t, 0, len(MetadataStoreEnvVars("etcd")))
+	envVars := MetadataStoreEnvVars("postgres")
+	require.Equal(t, 2, len(envVars))
+	require.Equal(t, PostgresSecretName, envVars[1].ValueFrom.SecretKeyRef.Name)
+
+	var buf bytes.Buffer
+	encoder, err := serde.GetEncoder("yaml", &buf)
+	require.NoError(t, err)
+	require.YesError(t, WritePostgresSecret(encoder, &AssetOpts{Namespace: "pach"}))
+	require.NoError(t, WritePostgresSecret(encoder, &AssetOpts{Namespace: "pach", PostgresURL: "postgres://localhost/pach"}))
+	require.True(t, strings.Contains(buf.String(), PostgresSecretName))
+}
@@ -0,0 +1,51 @@
+package assets
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// HelmNamespace is the namespace that assets are written with when they're
+	// written as a Helm chart, which Helm replaces with the release's
+	// namespace
+	HelmNamespace = "{{ .Release.Namespace }}"
+	// HelmVersion is the version that assets are written with when they're
+	// written as a Helm chart, which Helm replaces with the chart's "version"
+	// value
+	HelmVersion = "{{ .Values.version }}"
+
+	helmChartName = "pachyderm"
+)
+
+// WriteHelmChart writes a Helm chart to 'dir' whose template is 'manifest',
+// the YAML assets of a deployment written with HelmNamespace and HelmVersion
+// as their namespace and version. The chart's "version" value defaults to
+// 'version', the version of Pachyderm that wrote it.
+func WriteHelmChart(dir string, manifest []byte, version string) error {
+	if err := os.MkdirAll(filepath.Join(dir, "templates"), 0755); err != nil {
+		return err
+	}
+	chart := fmt.Sprintf(`apiVersion: v2
+name: %s
+description: A Pachyderm cluster (generated by 'pachctl deploy --helm-chart')
+type: application
+version: %s
+appVersion: %q
+`, helmChartName, version, version)
+	values := fmt.Sprintf(`# version is the version of the pachd and worker images
+version: %q
+`, version)
+	for name, contents := range map[string][]byte{
+		"Chart.yaml":                                      []byte(chart),
+		"values.yaml":                                     []byte(values),
+		filepath.Join("templates", helmChartName+".yaml"): manifest,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), contents, 0644); err != nil {
+			return fmt.Errorf("could not write Helm chart: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package assets
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pachyderm/pachyderm/src/server/pkg/mtls"
+	"github.com/pachyderm/pachyderm/src/server/pkg/serde"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// internalCASecretName is the name of the secret holding the cluster CA,
+	// which is mounted in pachd's pods if internal TLS is enabled
+	internalCASecretName = "pachyderm-internal-ca"
+	internalCAVolumeName = "pachyderm-internal-ca"
+
+	// internalTLSVolumeName is the name of the volume holding etcd's internal
+	// cert, if internal TLS is enabled
+	internalTLSVolumeName = "pachyderm-internal-tls"
+)
+
+// WriteInternalTLSSecrets generates a new cluster CA and writes the secrets
+// that internal TLS needs: the CA itself, which pachd uses to issue certs,
+// and etcd's first cert. pachd reissues etcd's cert (and issues the workers'
+// cert) before it expires, so the manifest doesn't have to be reapplied.
+func WriteInternalTLSSecrets(encoder serde.Encoder, opts *AssetOpts) error {
+	ca, err := mtls.GenerateCA()
+	if err != nil {
+		return err
+	}
+	// Clients verify etcd's cert against mtls.ServerName, so its other names
+	// are only needed by clients outside of Pachyderm, and can be left out if
+	// the namespace isn't known yet (e.g. in a Helm chart)
+	var hosts []string
+	switch opts.Namespace {
+	case "":
+		hosts = mtls.EtcdHosts("default")
+	case HelmNamespace:
+	default:
+		hosts = mtls.EtcdHosts(opts.Namespace)
+	}
+	etcdData, err := ca.SecretData(mtls.EtcdSecretName, hosts, mtls.DefaultCertTTL)
+	if err != nil {
+		return err
+	}
+	for _, secret := range []*v1.Secret{{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: objectMeta(internalCASecretName, labels(internalCASecretName), nil, opts.Namespace),
+		Data: map[string][]byte{
+			mtls.CACertFile: ca.CertPEM(),
+			mtls.CAKeyFile:  ca.KeyPEM(),
+		},
+	}, {
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: objectMeta(mtls.EtcdSecretName, labels(mtls.EtcdSecretName), nil, opts.Namespace),
+		Data:       etcdData,
+	}} {
+		if err := encoder.Encode(secret); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// internalCAVolume returns the volume and volume mount of the cluster CA in
+// pachd's pods
+func internalCAVolume() (v1.Volume, v1.VolumeMount) {
+	return v1.Volume{
+		Name: internalCAVolumeName,
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName: internalCASecretName,
+			},
+		},
+	}, v1.VolumeMount{
+		Name:      internalCAVolumeName,
+		MountPath: mtls.CAVolumePath,
+		ReadOnly:  true,
+	}
+}
+
+// etcdInternalTLSVolume returns the volume and volume mount of etcd's
+// internal cert
+func etcdInternalTLSVolume() (v1.Volume, v1.VolumeMount) {
+	return v1.Volume{
+		Name: internalTLSVolumeName,
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName: mtls.EtcdSecretName,
+			},
+		},
+	}, v1.VolumeMount{
+		Name:      internalTLSVolumeName,
+		MountPath: mtls.CertVolumePath,
+		ReadOnly:  true,
+	}
+}
+
+// withEtcdInternalTLS returns 'cmd' (a command that runs etcd), modified to
+// serve clients (and, if 'peer' is set, other etcd nodes) over TLS, with the
+// cert mounted by etcdInternalTLSVolume, and to require a cert issued by the
+// cluster CA from them. etcd rereads its cert for each connection, so it picks
+// up the certs that pachd reissues.
+func withEtcdInternalTLS(cmd []string, peer bool) []string {
+	var result []string
+	for _, arg := range cmd {
+		if strings.HasPrefix(arg, "--listen-client-urls=") || strings.HasPrefix(arg, "--advertise-client-urls=") ||
+			(peer && (strings.HasPrefix(arg, "--listen-peer-urls=") ||
+				strings.HasPrefix(arg, "--initial-advertise-peer-urls=") ||
+				strings.HasPrefix(arg, "--initial-cluster="))) {
+			arg = strings.Replace(arg, "http://", "https://", -1)
+		}
+		result = append(result, arg)
+	}
+	certFile := path.Join(mtls.CertVolumePath, mtls.CertFile)
+	keyFile := path.Join(mtls.CertVolumePath, mtls.KeyFile)
+	caFile := path.Join(mtls.CertVolumePath, mtls.CACertFile)
+	result = append(result,
+		fmt.Sprintf("--cert-file=%s", certFile),
+		fmt.Sprintf("--key-file=%s", keyFile),
+		fmt.Sprintf("--trusted-ca-file=%s", caFile),
+		"--client-cert-auth",
+	)
+	if peer {
+		result = append(result,
+			fmt.Sprintf("--peer-cert-file=%s", certFile),
+			fmt.Sprintf("--peer-key-file=%s", keyFile),
+			fmt.Sprintf("--peer-trusted-ca-file=%s", caFile),
+			"--peer-client-cert-auth",
+		)
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package assets
+
+import (
+	"fmt"
+
+	"github.com/pachyderm/pachyderm/src/client"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// pipelineCRDPolicyRules let pachd watch Pipeline resources and report their
+// status
+var pipelineCRDPolicyRules = []rbacv1.PolicyRule{{
+	APIGroups: []string{client.PipelineCRDGroup},
+	Verbs:     []string{"get", "list", "watch"},
+	Resources: []string{client.PipelineCRDPlural},
+}, {
+	APIGroups: []string{client.PipelineCRDGroup},
+	Verbs:     []string{"get", "update", "patch"},
+	Resources: []string{client.PipelineCRDPlural + "/status"},
+}}
+
+// PipelineCRD returns the definition of the Pipeline custom resource. Each
+// Pipeline resource's spec is a pipeline spec, as passed to
+// 'pachctl create pipeline' (the pipeline's name defaults to the resource's),
+// so that pipelines can be managed with 'kubectl apply'. The resource's
+// status is written by pachd.
+func PipelineCRD() interface{} {
+	return map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1beta1",
+		"kind":       "CustomResourceDefinition",
+		"metadata": map[string]interface{}{
+			"name":   fmt.Sprintf("%s.%s", client.PipelineCRDPlural, client.PipelineCRDGroup),
+			"labels": labels(""),
+		},
+		"spec": map[string]interface{}{
+			"group":   client.PipelineCRDGroup,
+			"version": client.PipelineCRDVersion,
+			"scope":   "Namespaced",
+			"names": map[string]interface{}{
+				"kind":       client.PipelineCRDKind,
+				"plural":     client.PipelineCRDPlural,
+				"singular":   "pipeline",
+				"shortNames": []string{"pachpipeline"},
+			},
+			// The spec is validated by pachd when it creates the pipeline
+			"preserveUnknownFields": true,
+			"subresources": map[string]interface{}{
+				"status": map[string]interface{}{},
+			},
+			"additionalPrinterColumns": []map[string]interface{}{{
+				"name":     "Version",
+				"type":     "integer",
+				"JSONPath": ".status.pipelineVersion",
+			}, {
+				"name":     "Error",
+				"type":     "string",
+				"JSONPath": ".status.error",
+			}},
+		},
+	}
+}
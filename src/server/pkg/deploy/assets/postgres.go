@@ -0,0 +1,60 @@
+package assets
+
+import (
+	"fmt"
+
+	"github.com/pachyderm/pachyderm/src/server/pkg/serde"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PostgresSecretName is the name of the secret holding the URL of the
+	// Postgres database that collections are stored in, if the cluster was
+	// deployed with --metadata-store=postgres
+	PostgresSecretName = "pachyderm-postgres"
+	// postgresURLKey is the key of the URL in PostgresSecretName
+	postgresURLKey = "url"
+)
+
+// MetadataStoreEnvVars returns the env vars that tell pachd (or a worker)
+// where collections are stored (see serviceenv.GlobalConfiguration). The
+// Postgres URL, which contains a password, is read from PostgresSecretName.
+func MetadataStoreEnvVars(metadataStore string) []v1.EnvVar {
+	if metadataStore != "postgres" {
+		return nil
+	}
+	return []v1.EnvVar{
+		{Name: "METADATA_STORE", Value: metadataStore},
+		{
+			Name: "POSTGRES_URL",
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{
+						Name: PostgresSecretName,
+					},
+					Key: postgresURLKey,
+				},
+			},
+		},
+	}
+}
+
+// WritePostgresSecret writes the secret holding opts.PostgresURL
+func WritePostgresSecret(encoder serde.Encoder, opts *AssetOpts) error {
+	if opts.PostgresURL == "" {
+		return fmt.Errorf("a postgres URL is required to use the postgres metadata store")
+	}
+	secret := &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: objectMeta(PostgresSecretName, labels(PostgresSecretName), nil, opts.Namespace),
+		Data: map[string][]byte{
+			postgresURLKey: []byte(opts.PostgresURL),
+		},
+	}
+	return encoder.Encode(secret)
+}
@@ -3,6 +3,8 @@ package dlock
 
 import (
 	"context"
+	"os"
+	"strings"
 
 	etcd "github.com/coreos/etcd/clientv3"
 	"github.com/coreos/etcd/clientv3/concurrency"
@@ -23,12 +25,14 @@ type etcdImpl struct {
 	client *etcd.Client
 	prefix string
 
-	session *concurrency.Session
-	mutex   *concurrency.Mutex
+	session  *concurrency.Session
+	election *concurrency.Election
 }
 
 // NewDLock attempts to acquire a distributed lock that locks a given prefix
-// in the data store.
+// in the data store. Processes waiting for the lock are queued under the
+// prefix along with their hostnames (i.e. their pods' names in kubernetes),
+// which ListHolders returns.
 func NewDLock(client *etcd.Client, prefix string) DLock {
 	return &etcdImpl{
 		client: client,
@@ -44,8 +48,15 @@ func (d *etcdImpl) Lock(ctx context.Context) (context.Context, error) {
 		return nil, err
 	}
 
-	mutex := concurrency.NewMutex(session, d.prefix)
-	if err := mutex.Lock(ctx); err != nil {
+	// An election with the process's hostname as its value queues waiters
+	// exactly like concurrency.Mutex (which this used to be), so processes
+	// using either can share a lock
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	election := concurrency.NewElection(session, d.prefix)
+	if err := election.Campaign(ctx, hostname); err != nil {
 		return nil, err
 	}
 
@@ -59,13 +70,54 @@ func (d *etcdImpl) Lock(ctx context.Context) (context.Context, error) {
 	}()
 
 	d.session = session
-	d.mutex = mutex
+	d.election = election
 	return ctx, nil
 }
 
 func (d *etcdImpl) Unlock(ctx context.Context) error {
-	if err := d.mutex.Unlock(ctx); err != nil {
+	if err := d.election.Resign(ctx); err != nil {
 		return err
 	}
 	return d.session.Close()
 }
+
+// Holder is a process that holds, or is waiting for, a lock
+type Holder struct {
+	// Name is the process's hostname (i.e. its pod's name in kubernetes), or
+	// "" if it locked the lock with an older version of DLock
+	Name string
+	// LeaseID is the ID of the etcd lease that keeps the process in the lock's
+	// queue. The lease expires if the process stops renewing it (e.g. because
+	// it died), at which point the next process in the queue acquires the lock
+	LeaseID int64
+	// TTL is the number of seconds until the lease expires, if it isn't
+	// renewed
+	TTL int64
+}
+
+// ListHolders returns the processes queued for the lock at 'prefix', in
+// order: the first process holds the lock, and the rest are waiting for it.
+func ListHolders(ctx context.Context, client *etcd.Client, prefix string) ([]*Holder, error) {
+	resp, err := client.Get(ctx, prefix+"/", etcd.WithPrefix(),
+		etcd.WithSort(etcd.SortByCreateRevision, etcd.SortAscend))
+	if err != nil {
+		return nil, err
+	}
+	var holders []*Holder
+	for _, kv := range resp.Kvs {
+		if strings.Contains(strings.TrimPrefix(string(kv.Key), prefix+"/"), "/") {
+			continue // a key of some other lock, nested under this one
+		}
+		holder := &Holder{
+			Name:    string(kv.Value),
+			LeaseID: kv.Lease,
+		}
+		ttlResp, err := client.TimeToLive(ctx, etcd.LeaseID(kv.Lease))
+		if err != nil {
+			return nil, err
+		}
+		holder.TTL = ttlResp.TTL
+		holders = append(holders, holder)
+	}
+	return holders, nil
+}
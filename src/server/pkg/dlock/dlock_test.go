@@ -0,0 +1,193 @@
+package dlock_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	etcd "github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/clientv3/concurrency"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/server/pkg/dlock"
+	"github.com/pachyderm/pachyderm/src/server/pkg/testutil"
+)
+
+// lockAsync locks 'lock' in a goroutine, and returns a channel that's closed
+// once it's locked
+func lockAsync(t *testing.T, ctx context.Context, lock func(context.Context) error) <-chan struct{} {
+	locked := make(chan struct{})
+	go func() {
+		require.NoError(t, lock(ctx))
+		close(locked)
+	}()
+	return locked
+}
+
+func dlockFunc(d dlock.DLock) func(context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := d.Lock(ctx)
+		return err
+	}
+}
+
+// requireWaiting fails the test if 'locked' is closed within a short time
+func requireWaiting(t *testing.T, locked <-chan struct{}, msg string) {
+	select {
+	case <-locked:
+		t.Fatal(msg)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// requireLocked fails the test if 'locked' isn't closed soon
+func requireLocked(t *testing.T, locked <-chan struct{}, msg string) {
+	select {
+	case <-locked:
+	case <-time.After(10 * time.Second):
+		t.Fatal(msg)
+	}
+}
+
+// waitForHolders waits until 'n' processes are queued for the lock at
+// 'prefix', so that the next one is queued behind them
+func waitForHolders(t *testing.T, ctx context.Context, client *etcd.Client, prefix string, n int) {
+	require.NoErrorWithinTRetry(t, 10*time.Second, func() error {
+		holders, err := dlock.ListHolders(ctx, client, prefix)
+		if err != nil {
+			return err
+		}
+		if len(holders) != n {
+			return fmt.Errorf("expected %d holders, but there are %d", n, len(holders))
+		}
+		return nil
+	})
+}
+
+func TestMutualExclusion(t *testing.T) {
+	require.NoError(t, testutil.WithEtcdEnv(func(env *testutil.EtcdEnv) error {
+		a := dlock.NewDLock(env.EtcdClient, "lock")
+		b := dlock.NewDLock(env.EtcdClient, "lock")
+		_, err := a.Lock(env.Context)
+		require.NoError(t, err)
+
+		locked := lockAsync(t, env.Context, dlockFunc(b))
+		requireWaiting(t, locked, "lock was acquired while it was held")
+		require.NoError(t, a.Unlock(env.Context))
+		requireLocked(t, locked, "lock wasn't acquired after it was released")
+		require.NoError(t, b.Unlock(env.Context))
+
+		// Locks with other prefixes are independent
+		c := dlock.NewDLock(env.EtcdClient, "other")
+		_, err = a.Lock(env.Context)
+		require.NoError(t, err)
+		requireLocked(t, lockAsync(t, env.Context, dlockFunc(c)), "lock with another prefix wasn't acquired")
+		require.NoError(t, c.Unlock(env.Context))
+		return a.Unlock(env.Context)
+	}))
+}
+
+func TestQueueOrder(t *testing.T) {
+	require.NoError(t, testutil.WithEtcdEnv(func(env *testutil.EtcdEnv) error {
+		a := dlock.NewDLock(env.EtcdClient, "lock")
+		_, err := a.Lock(env.Context)
+		require.NoError(t, err)
+
+		b, c := dlock.NewDLock(env.EtcdClient, "lock"), dlock.NewDLock(env.EtcdClient, "lock")
+		bLocked := lockAsync(t, env.Context, dlockFunc(b))
+		waitForHolders(t, env.Context, env.EtcdClient, "lock", 2)
+		cLocked := lockAsync(t, env.Context, dlockFunc(c))
+		waitForHolders(t, env.Context, env.EtcdClient, "lock", 3)
+
+		// Waiters acquire the lock in the order that they started waiting
+		require.NoError(t, a.Unlock(env.Context))
+		requireLocked(t, bLocked, "first waiter didn't acquire the lock")
+		requireWaiting(t, cLocked, "second waiter acquired the lock before the first one released it")
+		require.NoError(t, b.Unlock(env.Context))
+		requireLocked(t, cLocked, "second waiter didn't acquire the lock")
+		return c.Unlock(env.Context)
+	}))
+}
+
+// TestMutexWaiters tests that DLocks share a lock with processes that lock it
+// with concurrency.Mutex, as older versions of DLock did
+func TestMutexWaiters(t *testing.T) {
+	require.NoError(t, testutil.WithEtcdEnv(func(env *testutil.EtcdEnv) error {
+		newMutex := func() *concurrency.Mutex {
+			session, err := concurrency.NewSession(env.EtcdClient, concurrency.WithContext(env.Context))
+			require.NoError(t, err)
+			return concurrency.NewMutex(session, "lock")
+		}
+
+		// A DLock holds the lock, and an old and a new process wait for it
+		a := dlock.NewDLock(env.EtcdClient, "lock")
+		_, err := a.Lock(env.Context)
+		require.NoError(t, err)
+		mutex := newMutex()
+		mutexLocked := lockAsync(t, env.Context, mutex.Lock)
+		waitForHolders(t, env.Context, env.EtcdClient, "lock", 2)
+		b := dlock.NewDLock(env.EtcdClient, "lock")
+		bLocked := lockAsync(t, env.Context, dlockFunc(b))
+		waitForHolders(t, env.Context, env.EtcdClient, "lock", 3)
+
+		require.NoError(t, a.Unlock(env.Context))
+		requireLocked(t, mutexLocked, "mutex didn't acquire the lock")
+		requireWaiting(t, bLocked, "lock was acquired while a mutex held it")
+		require.NoError(t, mutex.Unlock(env.Context))
+		requireLocked(t, bLocked, "lock wasn't acquired after the mutex released it")
+
+		// ...and a mutex waits for a DLock
+		mutex = newMutex()
+		mutexLocked = lockAsync(t, env.Context, mutex.Lock)
+		requireWaiting(t, mutexLocked, "mutex was acquired while a DLock held it")
+		require.NoError(t, b.Unlock(env.Context))
+		requireLocked(t, mutexLocked, "mutex didn't acquire the lock")
+		return mutex.Unlock(env.Context)
+	}))
+}
+
+func TestListHolders(t *testing.T) {
+	require.NoError(t, testutil.WithEtcdEnv(func(env *testutil.EtcdEnv) error {
+		holders, err := dlock.ListHolders(env.Context, env.EtcdClient, "lock")
+		require.NoError(t, err)
+		require.Equal(t, 0, len(holders))
+
+		a := dlock.NewDLock(env.EtcdClient, "lock")
+		_, err = a.Lock(env.Context)
+		require.NoError(t, err)
+		// Locks nested under the prefix aren't listed
+		nested := dlock.NewDLock(env.EtcdClient, "lock/nested")
+		_, err = nested.Lock(env.Context)
+		require.NoError(t, err)
+		session, err := concurrency.NewSession(env.EtcdClient, concurrency.WithContext(env.Context))
+		require.NoError(t, err)
+		mutex := concurrency.NewMutex(session, "lock")
+		mutexLocked := lockAsync(t, env.Context, mutex.Lock)
+		waitForHolders(t, env.Context, env.EtcdClient, "lock", 2)
+
+		// The holder is listed first, then the waiters, which are named after
+		// their hosts unless they're old mutexes
+		hostname, err := os.Hostname()
+		require.NoError(t, err)
+		holders, err = dlock.ListHolders(env.Context, env.EtcdClient, "lock")
+		require.NoError(t, err)
+		require.Equal(t, hostname, holders[0].Name)
+		require.Equal(t, "", holders[1].Name)
+		require.Equal(t, int64(session.Lease()), holders[1].LeaseID)
+		require.NotEqual(t, int64(0), holders[0].LeaseID)
+		require.True(t, holders[0].TTL > 0 && holders[0].TTL <= 15)
+		require.True(t, holders[1].TTL > 0)
+
+		// Mutexes also wait for the keys nested under their prefix
+		require.NoError(t, nested.Unlock(env.Context))
+		require.NoError(t, a.Unlock(env.Context))
+		requireLocked(t, mutexLocked, "mutex didn't acquire the lock")
+		holders, err = dlock.ListHolders(env.Context, env.EtcdClient, "lock")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(holders))
+		require.Equal(t, "", holders[0].Name)
+		return mutex.Unlock(env.Context)
+	}))
+}
@@ -3,6 +3,8 @@ package errutil
 import (
 	"fmt"
 	"strings"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errcode"
 )
 
 var (
@@ -13,21 +15,28 @@ var (
 )
 
 // IsAlreadyExistError returns true if err is due to trying to create a
-// resource that already exists. It uses simple string matching, it's not
-// terribly smart.
+// resource that already exists. Errors without a code (see errcode) are
+// matched with simple string matching, which isn't terribly smart.
 func IsAlreadyExistError(err error) bool {
 	if err == nil {
 		return false
 	}
+	if code := errcode.Of(err); code != errcode.Unknown {
+		return code == errcode.Conflict && strings.Contains(err.Error(), "already exists")
+	}
 	return strings.Contains(err.Error(), "already exists")
 }
 
-// IsNotFoundError returns true if err is due to a resource not being found. It
-// uses simple string matching, it's not terribly smart.
+// IsNotFoundError returns true if err is due to a resource not being found.
+// Errors without a code (see errcode) are matched with simple string matching,
+// which isn't terribly smart.
 func IsNotFoundError(err error) bool {
 	if err == nil {
 		return false
 	}
+	if code := errcode.Of(err); code != errcode.Unknown {
+		return code == errcode.NotFound
+	}
 	return strings.Contains(err.Error(), "not found")
 }
 
@@ -17,7 +17,6 @@ import (
 
 	"github.com/OneOfOne/xxhash"
 	bolt "github.com/coreos/bbolt"
-	globlib "github.com/pachyderm/ohmyglob"
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
 	"github.com/pachyderm/pachyderm/src/client/pkg/pbutil"
@@ -171,8 +170,9 @@ func (h *dbHashTree) Get(path string) (*NodeProto, error) {
 // Get gets a hashtree node.
 func Get(rs []io.ReadCloser, filePath string) (*NodeProto, error) {
 	filePath = clean(filePath)
+	k := b(filePath)
 	var fileNode *NodeProto
-	if err := nodes(rs, func(path string, node *NodeProto) error {
+	if err := nodes(rs, func(_k []byte) bool { return bytes.Equal(_k, k) }, func(path string, node *NodeProto) error {
 		if path == filePath {
 			fileNode = node
 		}
@@ -240,16 +240,16 @@ func (h *dbHashTree) ListAll(path string) ([]*NodeProto, error) {
 
 // List executes a callback for each file under a directory (or a file if the path is a file).
 func List(rs []io.ReadCloser, pattern string, f func(string, *NodeProto) error) (retErr error) {
-	pattern = clean(pattern)
-	if pattern == "" {
-		pattern = "/"
-	}
-	g, err := globlib.Compile(pattern, '/')
+	p, err := compileGlob(clean(pattern))
 	if err != nil {
-		return errorf(MalformedGlob, err.Error())
+		return err
 	}
-	return nodes(rs, func(path string, node *NodeProto) error {
-		if (g.Match(path) && node.DirNode == nil) || (g.Match(pathlib.Dir(path))) {
+	// Only nodes that match or whose parent matches are decoded
+	filter := func(k []byte) bool {
+		return p.matchKey(k) || p.matchParentKey(k)
+	}
+	return nodes(rs, filter, func(path string, node *NodeProto) error {
+		if (p.matchKey(b(path)) && node.DirNode == nil) || p.matchParentKey(b(path)) {
 			return f(path, node)
 		}
 		return nil
@@ -264,27 +264,11 @@ func glob(tx *bolt.Tx, pattern string, f func(string, *NodeProto) error) error {
 		}
 		return f(externalDefault(pattern), node)
 	}
-
-	g, err := globlib.Compile(pattern, '/')
+	p, err := compileGlob(pattern)
 	if err != nil {
-		return errorf(MalformedGlob, err.Error())
-	}
-	c := fs(tx).Cursor()
-	for k, v := c.First(); k != nil; k, v = c.Next() {
-		if g.Match(s(k)) {
-			node := &NodeProto{}
-			if node.Unmarshal(v); err != nil {
-				return err
-			}
-			if err := f(externalDefault(s(k)), node); err != nil {
-				if err == errutil.ErrBreak {
-					return nil
-				}
-				return err
-			}
-		}
+		return err
 	}
-	return nil
+	return globDB(tx, p, f)
 }
 
 // Glob executes a callback for each path that matches the glob pattern.
@@ -298,15 +282,12 @@ func (h *dbHashTree) Glob(pattern string, f func(string, *NodeProto) error) erro
 // Glob executes a callback for each path that matches the glob pattern.
 func Glob(rs []io.ReadCloser, pattern string, f func(string, *NodeProto) error) (retErr error) {
 	pattern = clean(pattern)
-	g, err := globlib.Compile(pattern, '/')
+	p, err := compileGlob(pattern)
 	if err != nil {
-		return errorf(MalformedGlob, err.Error())
+		return err
 	}
-	return nodes(rs, func(path string, node *NodeProto) error {
-		if g.Match(path) {
-			return f(externalDefault(path), node)
-		}
-		return nil
+	return nodes(rs, p.matchKey, func(path string, node *NodeProto) error {
+		return f(externalDefault(path), node)
 	})
 }
 
@@ -351,7 +332,7 @@ func (h *dbHashTree) Walk(path string, f func(path string, node *NodeProto) erro
 // Walk executes a callback against every node in the subtree of path.
 func Walk(rs []io.ReadCloser, walkPath string, f func(path string, node *NodeProto) error) error {
 	walkPath = clean(walkPath)
-	return nodes(rs, func(path string, node *NodeProto) error {
+	return nodes(rs, nil, func(path string, node *NodeProto) error {
 		if path == "" {
 			path = "/"
 		}
@@ -1330,11 +1311,13 @@ func Merge(w *Writer, rs []*Reader) error {
 	return nil
 }
 
-func nodes(rs []io.ReadCloser, f func(path string, nodeProto *NodeProto) error) error {
+// nodes calls 'f' with each node in the serialized hashtrees 'rs' whose key
+// passes 'filter' (if it's set). Other nodes are skipped without being decoded.
+func nodes(rs []io.ReadCloser, filter Filter, f func(path string, nodeProto *NodeProto) error) error {
 	mq := &mergePQ{q: make([]*nodeStream, len(rs)+1)}
 	// Setup first set of nodes
 	for _, r := range rs {
-		if err := mq.insert(&nodeStream{r: NewReader(r, nil)}); err != nil {
+		if err := mq.insert(&nodeStream{r: NewReader(r, filter)}); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,207 @@
+package hashtree
+
+import (
+	"bytes"
+	pathlib "path"
+	"strings"
+
+	bolt "github.com/coreos/bbolt"
+	globlib "github.com/pachyderm/ohmyglob"
+	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
+)
+
+// globSegment matches one component of a path
+type globSegment struct {
+	// literal is set if the component contains no glob characters
+	literal []byte
+	g       *globlib.Glob
+}
+
+func (s *globSegment) match(name []byte) bool {
+	if s.g == nil {
+		return bytes.Equal(s.literal, name)
+	}
+	return s.g.Match(string(name))
+}
+
+// pathGlob is a compiled glob pattern. Most patterns (anything without "**",
+// "!(...)" or a group that spans a '/') match paths one component at a time,
+// which lets a glob descend only into the directories that it can match
+// rather than matching every path in a hashtree, and lets paths be rejected
+// by their depth and literal components without running a regex.
+type pathGlob struct {
+	g *globlib.Glob
+	// prefix is the literal prefix of the pattern
+	prefix []byte
+	// segments is nil if the pattern can only be matched against whole paths
+	segments []*globSegment
+}
+
+// compileGlob compiles the clean glob pattern 'pattern'
+func compileGlob(pattern string) (*pathGlob, error) {
+	g, err := globlib.Compile(pattern, '/')
+	if err != nil {
+		return nil, errorf(MalformedGlob, err.Error())
+	}
+	result := &pathGlob{g: g, prefix: b(GlobLiteralPrefix(pattern))}
+	if strings.Contains(pattern, "**") || strings.Contains(pattern, "!") {
+		return result, nil
+	}
+	segments := []*globSegment{}
+	if pattern != "" {
+		for _, component := range strings.Split(pattern[1:], "/") {
+			if !balanced(component) {
+				return result, nil
+			}
+			if !IsGlob(component) {
+				segments = append(segments, &globSegment{literal: []byte(component)})
+				continue
+			}
+			g, err := globlib.Compile(component, '/')
+			if err != nil {
+				return result, nil
+			}
+			segments = append(segments, &globSegment{g: g})
+		}
+	}
+	result.segments = segments
+	return result, nil
+}
+
+// balanced returns true if every group or character class opened in
+// 'component' is also closed in it
+func balanced(component string) bool {
+	var depth int
+	for _, c := range component {
+		switch c {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		}
+		if depth < 0 {
+			return false
+		}
+	}
+	return depth == 0
+}
+
+// matchKey returns true if the path of the (encoded) key 'k' matches the glob
+func (p *pathGlob) matchKey(k []byte) bool {
+	if p.segments == nil {
+		return p.g.Match(s(k))
+	}
+	if bytes.Equal(k, nullByte) {
+		return len(p.segments) == 0
+	}
+	// k is "\x00a\x00b..." and so has one null byte per path component
+	if bytes.Count(k, nullByte) != len(p.segments) {
+		return false
+	}
+	for _, segment := range p.segments {
+		k = k[1:]
+		end := bytes.IndexByte(k, 0)
+		if end < 0 {
+			end = len(k)
+		}
+		if !segment.match(k[:end]) {
+			return false
+		}
+		k = k[end:]
+	}
+	return true
+}
+
+// matchParentKey returns true if the parent directory of the path of the
+// (encoded) key 'k' matches the glob
+func (p *pathGlob) matchParentKey(k []byte) bool {
+	if bytes.Equal(k, nullByte) {
+		return false
+	}
+	if p.segments == nil {
+		return p.g.Match(pathlib.Dir(s(k)))
+	}
+	end := bytes.LastIndexByte(k, 0)
+	if end == 0 {
+		return p.matchKey(nullByte)
+	}
+	return p.matchKey(k[:end])
+}
+
+// globDB calls 'f' with each node in 'tx' whose path matches 'p'
+func globDB(tx *bolt.Tx, p *pathGlob, f func(string, *NodeProto) error) error {
+	var err error
+	if p.segments == nil {
+		// Patterns that can't be matched a component at a time are matched
+		// against every path that starts with their literal prefix
+		c := fs(tx).Cursor()
+		for k, v := c.Seek(p.prefix); k != nil && bytes.HasPrefix(k, p.prefix); k, v = c.Next() {
+			if p.g.Match(s(k)) {
+				if err = globNode(k, v, f); err != nil {
+					break
+				}
+			}
+		}
+	} else {
+		err = globDir(tx, p, b(""), 0, f)
+	}
+	if err != nil && err != errutil.ErrBreak {
+		return err
+	}
+	return nil
+}
+
+// globDir calls 'f' with each node under the (encoded) directory 'dir' whose
+// path matches 'p', which must have segments. 'depth' is the number of
+// components in 'dir'.
+func globDir(tx *bolt.Tx, p *pathGlob, dir []byte, depth int, f func(string, *NodeProto) error) error {
+	if depth == len(p.segments) {
+		if v := fs(tx).Get(dir); v != nil {
+			return globNode(dir, v, f)
+		}
+		return nil
+	}
+	segment := p.segments[depth]
+	if segment.g == nil {
+		// Only the child named by the literal can match, so look it up
+		// directly rather than iterating over every child of 'dir'
+		child := append(childPrefix(dir), segment.literal...)
+		if fs(tx).Get(child) == nil {
+			return nil
+		}
+		return globDir(tx, p, child, depth+1, f)
+	}
+	prefix := childPrefix(dir)
+	c := NewChildCursor(tx, s(dir))
+	for k := c.K(); k != nil; k, _ = c.Next() {
+		if !segment.match(k[len(prefix):]) {
+			continue
+		}
+		// The cursor's key is only valid until it moves
+		child := make([]byte, len(k))
+		copy(child, k)
+		if err := globDir(tx, p, child, depth+1, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// childPrefix returns the prefix shared by the keys of the children of the
+// (encoded) directory 'dir'
+func childPrefix(dir []byte) []byte {
+	if bytes.Equal(dir, nullByte) {
+		return []byte{0}
+	}
+	prefix := make([]byte, len(dir)+1)
+	copy(prefix, dir)
+	return prefix
+}
+
+func globNode(k, v []byte, f func(string, *NodeProto) error) error {
+	node := &NodeProto{}
+	if err := node.Unmarshal(v); err != nil {
+		return err
+	}
+	return f(externalDefault(s(k)), node)
+}
@@ -147,3 +147,36 @@ func BenchmarkDelete10k(b *testing.B) {
 func BenchmarkDelete100k(b *testing.B) {
 	benchmarkDeleteN(b, 1e5)
 }
+
+// BenchmarkGlob tests the amount of time it takes to Glob a pattern that
+// matches a small fraction of the 'cnt' files in a HashTree. Globs descend only
+// into the directories that can match, so this should depend on the number of
+// directories (and files in them) that match, rather than on 'cnt'.
+func benchmarkGlobN(b *testing.B, cnt int) {
+	h := newHashTree(b)
+	for i := 0; i < cnt; i++ {
+		require.NoError(b, h.PutFile(fmt.Sprintf("/dir-%02d/data/file-%06d.csv", i%10, i),
+			obj(fmt.Sprintf(`hash:"%x"`, i)), 1))
+		require.NoError(b, h.PutFile(fmt.Sprintf("/dir-%02d/other/file-%06d.csv", i%10, i),
+			obj(fmt.Sprintf(`hash:"%x"`, i)), 1))
+	}
+	require.NoError(b, h.Hash())
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		require.NoError(b, h.Glob("/dir-0[01]/data/*.csv", func(string, *NodeProto) error {
+			return nil
+		}))
+	}
+}
+
+func BenchmarkGlob1k(b *testing.B) {
+	benchmarkGlobN(b, 1e3)
+}
+
+func BenchmarkGlob10k(b *testing.B) {
+	benchmarkGlobN(b, 1e4)
+}
+
+func BenchmarkGlob100k(b *testing.B) {
+	benchmarkGlobN(b, 1e5)
+}
@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"testing"
 
 	bolt "github.com/coreos/bbolt"
 	"github.com/golang/protobuf/proto"
+	globlib "github.com/pachyderm/ohmyglob"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
 	"github.com/pachyderm/pachyderm/src/client/pkg/require"
 )
@@ -472,6 +475,67 @@ func TestGlobFile(t *testing.T) {
 	}
 }
 
+// Test that globs, which are evaluated a path component at a time where
+// possible, match the same paths as matching the pattern against every path
+func TestGlobComponents(t *testing.T) {
+	h := newHashTree(t)
+	for _, p := range []string{"/a/data/1.csv", "/a/data/2.txt", "/a/data.csv", "/ab/data/3.csv",
+		"/b/data/sub/4.csv", "/b/other/5.csv", "/c.csv", "/d/e/f/g.csv"} {
+		require.NoError(t, h.PutFile(p, obj(`hash:"20c27"`), 1))
+	}
+	require.NoError(t, h.Hash())
+	// Write 'h' in the format that's read by Glob() and List()
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	var all []string
+	require.NoError(t, h.Walk("/", func(path string, node *NodeProto) error {
+		all = append(all, internalDefault(path))
+		return w.Write(&MergeNode{k: b(internalDefault(path)), nodeProto: node})
+	}))
+
+	for _, pattern := range []string{"*", "/*/data/*.csv", "/a/*", "/*/data", "/a/data/[12].*",
+		"/{a,b}/data/*", "/@(a|ab)/data/*", "/**.csv", "/*/{data/sub,other}/*", "/!(a)/*", "/d/*/f"} {
+		g, err := globlib.Compile(clean(pattern), '/')
+		require.NoError(t, err)
+		var expected []string
+		for _, path := range all {
+			if g.Match(path) {
+				expected = append(expected, externalDefault(path))
+			}
+		}
+
+		var paths []string
+		require.NoError(t, h.Glob(pattern, func(path string, _ *NodeProto) error {
+			paths = append(paths, path)
+			return nil
+		}), pattern)
+		require.Equal(t, expected, paths, pattern)
+
+		paths = nil
+		rs := []io.ReadCloser{ioutil.NopCloser(bytes.NewReader(buf.Bytes()))}
+		require.NoError(t, Glob(rs, pattern, func(path string, _ *NodeProto) error {
+			paths = append(paths, path)
+			return nil
+		}), pattern)
+		require.Equal(t, expected, paths, pattern)
+	}
+
+	var paths []string
+	rs := []io.ReadCloser{ioutil.NopCloser(bytes.NewReader(buf.Bytes()))}
+	require.NoError(t, List(rs, "/*/data", func(path string, _ *NodeProto) error {
+		paths = append(paths, path)
+		return nil
+	}))
+	require.Equal(t, []string{"/a/data/1.csv", "/a/data/2.txt", "/ab/data/3.csv", "/b/data/sub"}, paths)
+	paths = nil
+	rs = []io.ReadCloser{ioutil.NopCloser(bytes.NewReader(buf.Bytes()))}
+	require.NoError(t, List(rs, "/", func(path string, _ *NodeProto) error {
+		paths = append(paths, path)
+		return nil
+	}))
+	require.Equal(t, []string{"/a", "/ab", "/b", "/c.csv", "/d"}, paths)
+}
+
 // Test that Walk() works
 func TestWalk(t *testing.T) {
 	h := newHashTree(t)
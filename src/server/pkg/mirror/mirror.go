@@ -0,0 +1,345 @@
+// Package mirror replicates the commits on a PFS branch from one Pachyderm
+// cluster (the source) to the same branch on another cluster (the target).
+//
+// Replication is asynchronous: each finished source commit is replayed onto
+// the target as a new commit containing the same diff. The ID of the source
+// commit is recorded in the description of the target commit, which is how a
+// mirror knows where to resume after a restart and how far behind the target
+// is.
+package mirror
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/limit"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// sourceCommitTrailer prefixes the line of a mirrored commit's description
+	// that identifies the source commit it was copied from.
+	sourceCommitTrailer = "mirrored-from: "
+
+	// DefaultParallelism is the default number of files copied concurrently
+	// while mirroring a commit.
+	DefaultParallelism = 10
+)
+
+// Mirror copies the finished commits on a source branch to a target cluster.
+type Mirror struct {
+	src, dst    *client.APIClient
+	repo        string
+	branch      string
+	parallelism int
+}
+
+// Status describes how far a mirror's target lags behind its source.
+type Status struct {
+	// SourceHead is the head commit of the mirrored branch on the source.
+	SourceHead *pfs.CommitInfo
+	// LastMirrored is the source commit that was most recently copied to the
+	// target, or nil if nothing has been mirrored yet.
+	LastMirrored *pfs.CommitInfo
+	// CommitsBehind is the number of source commits that haven't been copied
+	// to the target yet.
+	CommitsBehind int64
+	// Lag is the time between the source head being finished and the most
+	// recently mirrored commit being finished on the source. It's zero if the
+	// target is up to date.
+	Lag time.Duration
+}
+
+// New returns a Mirror that copies commits on repo@branch from 'src' to 'dst'.
+func New(src, dst *client.APIClient, repo, branch string, parallelism int) *Mirror {
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	}
+	return &Mirror{
+		src:         src,
+		dst:         dst,
+		repo:        repo,
+		branch:      branch,
+		parallelism: parallelism,
+	}
+}
+
+// Run copies every source commit that hasn't been mirrored yet, and then
+// keeps copying new commits as they're finished. It only returns on error
+// (including cancellation of the source client's context).
+func (m *Mirror) Run() error {
+	last, err := m.prepare()
+	if err != nil {
+		return err
+	}
+	return m.src.SubscribeCommitF(m.repo, m.branch, nil, last, pfs.CommitState_FINISHED, func(ci *pfs.CommitInfo) error {
+		if ci.Commit.ID == last {
+			return nil
+		}
+		return m.mirrorCommit(ci)
+	})
+}
+
+// Sync copies every source commit up to the current head of the source
+// branch, and then returns.
+func (m *Mirror) Sync() error {
+	last, err := m.prepare()
+	if err != nil {
+		return err
+	}
+	head, err := m.src.InspectCommit(m.repo, m.branch)
+	if err != nil {
+		return err
+	}
+	if head.Commit.ID == last {
+		return nil
+	}
+	iter, err := m.src.SubscribeCommit(m.repo, m.branch, nil, last, pfs.CommitState_FINISHED)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+	for {
+		ci, err := iter.Next()
+		if err != nil {
+			return grpcutil.ScrubGRPC(err)
+		}
+		if ci.Commit.ID == last {
+			continue
+		}
+		if err := m.mirrorCommit(ci); err != nil {
+			return err
+		}
+		if ci.Commit.ID == head.Commit.ID {
+			return nil
+		}
+	}
+}
+
+// Status reports how far the target is behind the source.
+func (m *Mirror) Status() (*Status, error) {
+	head, err := m.src.InspectCommit(m.repo, m.branch)
+	if err != nil {
+		return nil, err
+	}
+	last, err := m.lastMirrored()
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{SourceHead: head}
+	if last != "" {
+		if status.LastMirrored, err = m.src.InspectCommit(m.repo, last); err != nil {
+			return nil, err
+		}
+	}
+	if last == head.Commit.ID {
+		return status, nil
+	}
+	if err := m.src.ListCommitF(m.repo, m.branch, last, 0, false, func(ci *pfs.CommitInfo) error {
+		if ci.Commit.ID != last {
+			status.CommitsBehind++
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if head.Finished != nil {
+		headFinished, err := types.TimestampFromProto(head.Finished)
+		if err != nil {
+			return nil, err
+		}
+		lastFinished := time.Time{}
+		if status.LastMirrored != nil && status.LastMirrored.Finished != nil {
+			if lastFinished, err = types.TimestampFromProto(status.LastMirrored.Finished); err != nil {
+				return nil, err
+			}
+		}
+		if !lastFinished.IsZero() {
+			status.Lag = headFinished.Sub(lastFinished)
+		}
+	}
+	return status, nil
+}
+
+// prepare creates the target repo if necessary and returns the ID of the last
+// source commit copied to the target ("" if none has been).
+func (m *Mirror) prepare() (string, error) {
+	if _, err := m.dst.InspectRepo(m.repo); err != nil {
+		if !errutil.IsNotFoundError(err) {
+			return "", err
+		}
+		if _, err := m.dst.PfsAPIClient.CreateRepo(m.dst.Ctx(), &pfs.CreateRepoRequest{
+			Repo:        client.NewRepo(m.repo),
+			Description: fmt.Sprintf("Mirror of %s@%s.", m.repo, m.branch),
+		}); err != nil {
+			return "", grpcutil.ScrubGRPC(err)
+		}
+	}
+	return m.lastMirrored()
+}
+
+// lastMirrored returns the ID of the source commit recorded in the head of the
+// target branch, or "" if the target branch doesn't exist yet.
+func (m *Mirror) lastMirrored() (string, error) {
+	ci, err := m.dst.InspectCommit(m.repo, m.branch)
+	if err != nil {
+		if errutil.IsNotFoundError(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	id, ok := SourceCommit(ci.Description)
+	if !ok {
+		return "", fmt.Errorf("the head of %s@%s on the target was not created by a mirror", m.repo, m.branch)
+	}
+	return id, nil
+}
+
+// mirrorCommit replays the diff between 'ci' and its parent onto a new commit
+// on the target.
+func (m *Mirror) mirrorCommit(ci *pfs.CommitInfo) (retErr error) {
+	provenance, err := m.mirroredProvenance(ci)
+	if err != nil {
+		return err
+	}
+	commit, err := m.dst.PfsAPIClient.StartCommit(m.dst.Ctx(), &pfs.StartCommitRequest{
+		Parent:      client.NewCommit(m.repo, ""),
+		Branch:      m.branch,
+		Description: WithSourceCommit(ci.Description, ci.Commit.ID),
+		Provenance:  provenance,
+	})
+	if err != nil {
+		return grpcutil.ScrubGRPC(err)
+	}
+	defer func() {
+		if retErr != nil {
+			// Don't leave a half-written commit at the head of the target branch,
+			// otherwise the next run would think it's been mirrored
+			m.dst.DeleteCommit(m.repo, commit.ID)
+		}
+	}()
+	var newFiles, oldFiles []*pfs.FileInfo
+	if ci.ParentCommit != nil {
+		newFiles, oldFiles, err = m.src.DiffFile(m.repo, ci.Commit.ID, "", m.repo, ci.ParentCommit.ID, "", false)
+	} else {
+		newFiles, oldFiles, err = m.src.DiffFile(m.repo, ci.Commit.ID, "", "", "", "", false)
+	}
+	if err != nil {
+		return err
+	}
+	// Files that only appear in the old commit have been deleted. Files that
+	// appear in both have changed and are overwritten below.
+	present := make(map[string]bool)
+	for _, fi := range newFiles {
+		present[fi.File.Path] = true
+	}
+	for _, fi := range oldFiles {
+		if present[fi.File.Path] || fi.File.Path == "/" || deletedParent(fi.File.Path, oldFiles, present) {
+			continue
+		}
+		if err := m.dst.DeleteFile(m.repo, commit.ID, fi.File.Path); err != nil && !errutil.IsNotFoundError(err) {
+			return err
+		}
+	}
+	pfc, err := m.dst.NewPutFileClient()
+	if err != nil {
+		return err
+	}
+	var eg errgroup.Group
+	limiter := limit.New(m.parallelism)
+	for _, fi := range newFiles {
+		if fi.FileType != pfs.FileType_FILE {
+			continue
+		}
+		fi := fi
+		limiter.Acquire()
+		eg.Go(func() error {
+			defer limiter.Release()
+			r, err := m.src.GetFileReader(m.repo, ci.Commit.ID, fi.File.Path, 0, 0)
+			if err != nil {
+				return err
+			}
+			_, err = pfc.PutFileOverwrite(m.repo, commit.ID, fi.File.Path, r, 0)
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		pfc.Close()
+		return err
+	}
+	if err := pfc.Close(); err != nil {
+		return err
+	}
+	return m.dst.FinishCommit(m.repo, commit.ID)
+}
+
+// mirroredProvenance maps the provenance of a source commit onto the target
+// where possible: a provenant commit is included only if it has itself been
+// mirrored to the target (by another mirror on its repo).
+func (m *Mirror) mirroredProvenance(ci *pfs.CommitInfo) ([]*pfs.CommitProvenance, error) {
+	var result []*pfs.CommitProvenance
+	for _, prov := range ci.Provenance {
+		if prov.Commit.Repo.Name == ppsconsts.SpecRepo || prov.Branch == nil {
+			continue
+		}
+		var found *pfs.Commit
+		if err := m.dst.ListCommitF(prov.Commit.Repo.Name, prov.Branch.Name, "", 0, false, func(dstCI *pfs.CommitInfo) error {
+			if id, ok := SourceCommit(dstCI.Description); ok && id == prov.Commit.ID {
+				found = dstCI.Commit
+				return errutil.ErrBreak
+			}
+			return nil
+		}); err != nil {
+			if errutil.IsNotFoundError(err) {
+				continue
+			}
+			return nil, err
+		}
+		if found != nil {
+			result = append(result, client.NewCommitProvenance(found.Repo.Name, prov.Branch.Name, found.ID))
+		}
+	}
+	return result, nil
+}
+
+// deletedParent returns true if one of the directories containing 'p' was
+// deleted outright (in which case deleting that directory also deletes 'p').
+func deletedParent(p string, oldFiles []*pfs.FileInfo, present map[string]bool) bool {
+	for _, fi := range oldFiles {
+		if fi.FileType == pfs.FileType_DIR && !present[fi.File.Path] &&
+			fi.File.Path != "/" && strings.HasPrefix(p, path.Clean(fi.File.Path)+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// WithSourceCommit returns 'description' with a trailer identifying
+// 'sourceCommitID' as the commit it was mirrored from.
+func WithSourceCommit(description string, sourceCommitID string) string {
+	trailer := sourceCommitTrailer + sourceCommitID
+	if description == "" {
+		return trailer
+	}
+	return strings.TrimRight(description, "\n") + "\n\n" + trailer
+}
+
+// SourceCommit returns the ID of the source commit recorded in a mirrored
+// commit's description, and false if the description doesn't contain one.
+func SourceCommit(description string) (string, bool) {
+	lines := strings.Split(strings.TrimRight(description, "\n"), "\n")
+	last := lines[len(lines)-1]
+	if !strings.HasPrefix(last, sourceCommitTrailer) {
+		return "", false
+	}
+	return strings.TrimPrefix(last, sourceCommitTrailer), true
+}
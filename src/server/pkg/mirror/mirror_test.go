@@ -0,0 +1,184 @@
+package mirror
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/server/pkg/testutil"
+)
+
+func TestSourceCommit(t *testing.T) {
+	for _, description := range []string{"", "nightly import", "multi\nline\n"} {
+		id, ok := SourceCommit(WithSourceCommit(description, "abc123"))
+		require.True(t, ok, "description: %q", description)
+		require.Equal(t, "abc123", id)
+	}
+	_, ok := SourceCommit("nightly import")
+	require.False(t, ok)
+	_, ok = SourceCommit("")
+	require.False(t, ok)
+}
+
+func TestDeletedParent(t *testing.T) {
+	dir := &pfs.FileInfo{File: client.NewFile("repo", "c", "/dir"), FileType: pfs.FileType_DIR}
+	root := &pfs.FileInfo{File: client.NewFile("repo", "c", "/"), FileType: pfs.FileType_DIR}
+	file := &pfs.FileInfo{File: client.NewFile("repo", "c", "/dir/file"), FileType: pfs.FileType_FILE}
+	oldFiles := []*pfs.FileInfo{root, dir, file}
+
+	require.True(t, deletedParent("/dir/file", oldFiles, map[string]bool{}))
+	// The directory still exists, so its files are deleted one by one
+	require.False(t, deletedParent("/dir/file", oldFiles, map[string]bool{"/dir": true}))
+	// The root is never deleted, and "/dirt" isn't in "/dir"
+	require.False(t, deletedParent("/top", oldFiles, map[string]bool{}))
+	require.False(t, deletedParent("/dirt/file", oldFiles, map[string]bool{}))
+}
+
+// withClusters runs 'cb' with clients of two in-process clusters
+func withClusters(t *testing.T, cb func(src, dst *client.APIClient)) {
+	require.NoError(t, testutil.WithRealEnv(func(srcEnv *testutil.RealEnv) error {
+		return testutil.WithRealEnv(func(dstEnv *testutil.RealEnv) error {
+			cb(srcEnv.PachClient, dstEnv.PachClient)
+			return nil
+		})
+	}))
+}
+
+func putFiles(t *testing.T, c *client.APIClient, repo string, files map[string]string, deletes ...string) *pfs.Commit {
+	commit, err := c.StartCommit(repo, "master")
+	require.NoError(t, err)
+	for _, p := range deletes {
+		require.NoError(t, c.DeleteFile(repo, commit.ID, p))
+	}
+	for p, content := range files {
+		_, err := c.PutFileOverwrite(repo, commit.ID, p, strings.NewReader(content), 0)
+		require.NoError(t, err)
+	}
+	require.NoError(t, c.FinishCommit(repo, commit.ID))
+	return commit
+}
+
+// requireFiles checks that the head of repo@master contains exactly 'files'
+func requireFiles(t *testing.T, c *client.APIClient, repo string, files map[string]string) {
+	var paths []string
+	require.NoError(t, c.Walk(repo, "master", "/", func(fi *pfs.FileInfo) error {
+		if fi.FileType == pfs.FileType_FILE {
+			paths = append(paths, fi.File.Path)
+		}
+		return nil
+	}))
+	require.Equal(t, len(files), len(paths), "files: %v", paths)
+	for p, content := range files {
+		var buf bytes.Buffer
+		require.NoError(t, c.GetFile(repo, "master", p, 0, 0, &buf))
+		require.Equal(t, content, buf.String())
+	}
+}
+
+func TestMirrorSync(t *testing.T) {
+	withClusters(t, func(src, dst *client.APIClient) {
+		require.NoError(t, src.CreateRepo("data"))
+		first := putFiles(t, src, "data", map[string]string{
+			"/a":     "a1",
+			"/dir/b": "b1",
+			"/dir/c": "c1",
+			"/e/f":   "f1",
+		})
+		require.NoError(t, New(src, dst, "data", "master", 2).Sync())
+		requireFiles(t, dst, "data", map[string]string{"/a": "a1", "/dir/b": "b1", "/dir/c": "c1", "/e/f": "f1"})
+		head, err := dst.InspectCommit("data", "master")
+		require.NoError(t, err)
+		id, ok := SourceCommit(head.Description)
+		require.True(t, ok)
+		require.Equal(t, first.ID, id)
+
+		// Only the diff is copied: a changed file, a new file, a deleted
+		// directory, and a file deleted from a directory that still exists
+		second := putFiles(t, src, "data", map[string]string{"/a": "a2", "/g": "g1"}, "/dir", "/e/f")
+		putFiles(t, src, "data", map[string]string{"/e/h": "h1"})
+		status, err := New(src, dst, "data", "master", 0).Status()
+		require.NoError(t, err)
+		require.Equal(t, first.ID, status.LastMirrored.Commit.ID)
+		require.Equal(t, int64(2), status.CommitsBehind)
+
+		require.NoError(t, New(src, dst, "data", "master", 0).Sync())
+		requireFiles(t, dst, "data", map[string]string{"/a": "a2", "/g": "g1", "/e/h": "h1"})
+		commitInfos, err := dst.ListCommit("data", "master", "", 0)
+		require.NoError(t, err)
+		require.Equal(t, 3, len(commitInfos))
+		id, ok = SourceCommit(commitInfos[1].Description)
+		require.True(t, ok)
+		require.Equal(t, second.ID, id)
+
+		status, err = New(src, dst, "data", "master", 0).Status()
+		require.NoError(t, err)
+		require.Equal(t, int64(0), status.CommitsBehind)
+		require.Equal(t, status.SourceHead.Commit.ID, status.LastMirrored.Commit.ID)
+	})
+}
+
+func TestMirrorResume(t *testing.T) {
+	withClusters(t, func(src, dst *client.APIClient) {
+		require.NoError(t, src.CreateRepo("data"))
+		putFiles(t, src, "data", map[string]string{"/a": "a1"})
+		require.NoError(t, New(src, dst, "data", "master", 0).Sync())
+
+		// A new mirror resumes from the last mirrored commit rather than
+		// copying the branch again
+		last := putFiles(t, src, "data", map[string]string{"/b": "b1"})
+		require.NoError(t, New(src, dst, "data", "master", 0).Sync())
+		require.NoError(t, New(src, dst, "data", "master", 0).Sync())
+		commitInfos, err := dst.ListCommit("data", "master", "", 0)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(commitInfos))
+		id, ok := SourceCommit(commitInfos[0].Description)
+		require.True(t, ok)
+		require.Equal(t, last.ID, id)
+		requireFiles(t, dst, "data", map[string]string{"/a": "a1", "/b": "b1"})
+
+		// Branches on the target that weren't written by a mirror are left
+		// alone
+		require.NoError(t, src.CreateRepo("other"))
+		putFiles(t, src, "other", map[string]string{"/a": "a1"})
+		require.NoError(t, dst.CreateRepo("other"))
+		putFiles(t, dst, "other", map[string]string{"/x": "x1"})
+		require.YesError(t, New(src, dst, "other", "master", 0).Sync())
+	})
+}
+
+func TestMirroredProvenance(t *testing.T) {
+	withClusters(t, func(src, dst *client.APIClient) {
+		require.NoError(t, src.CreateRepo("in"))
+		require.NoError(t, src.CreateRepo("out"))
+		require.NoError(t, src.CreateBranch("out", "master", "", []*pfs.Branch{client.NewBranch("in", "master")}))
+		inCommit := putFiles(t, src, "in", map[string]string{"/a": "a1"})
+		outCI, err := src.InspectCommit("out", "master")
+		require.NoError(t, err)
+		_, err = src.PutFile("out", outCI.Commit.ID, "/a", strings.NewReader("out"))
+		require.NoError(t, err)
+		require.NoError(t, src.FinishCommit("out", outCI.Commit.ID))
+
+		// 'out's provenance is only kept once 'in' has been mirrored too
+		m := New(src, dst, "out", "master", 0)
+		require.NoError(t, m.Sync())
+		outHead, err := dst.InspectCommit("out", "master")
+		require.NoError(t, err)
+		require.Equal(t, 0, len(outHead.Provenance))
+
+		require.NoError(t, New(src, dst, "in", "master", 0).Sync())
+		inHead, err := dst.InspectCommit("in", "master")
+		require.NoError(t, err)
+		outCI, err = src.InspectCommit("out", "master")
+		require.NoError(t, err)
+		provenance, err := m.mirroredProvenance(outCI)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(provenance))
+		require.Equal(t, "in", provenance[0].Commit.Repo.Name)
+		require.Equal(t, inHead.Commit.ID, provenance[0].Commit.ID)
+		id, _ := SourceCommit(inHead.Description)
+		require.Equal(t, inCommit.ID, id)
+	})
+}
@@ -0,0 +1,411 @@
+// Package mtls implements mutual TLS between the components of a Pachyderm
+// cluster (pachd, workers and etcd).
+//
+// When internal TLS is enabled (see 'pachctl deploy --internal-tls'), the
+// cluster has its own certificate authority, whose cert and key are mounted in
+// pachd's pods at CAVolumePath. pachd issues itself short-lived certs with it,
+// and writes short-lived certs for workers and etcd to kubernetes secrets,
+// which are mounted at CertVolumePath and reissued before they expire (see
+// RotateSecrets). Every gRPC connection between pods then requires a cert
+// issued by the cluster's CA on both ends.
+package mtls
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/server/pkg/cert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	// CAVolumePath is the path at which the cluster CA's cert and private key
+	// are mounted in pachd's pods
+	CAVolumePath = "/pachyderm-internal-ca"
+
+	// CertVolumePath is the path at which the internal cert and private key of
+	// a worker or etcd pod (issued by the cluster CA), along with the CA's
+	// cert, are mounted
+	CertVolumePath = "/pachyderm-internal-tls"
+
+	// CACertFile is the name of the mounted file containing the cluster CA's
+	// cert
+	CACertFile = "ca.crt"
+
+	// CAKeyFile is the name of the mounted file containing the cluster CA's
+	// private key
+	CAKeyFile = "ca.key"
+
+	// CertFile is the name of the mounted file containing a pod's cert
+	CertFile = "tls.crt"
+
+	// KeyFile is the name of the mounted file containing a pod's private key
+	KeyFile = "tls.key"
+
+	// ServerName is a DNS name that every cert issued by the cluster CA is
+	// valid for. Pods connect to each other by IP address, so clients verify
+	// the server's cert against this name instead: only the cluster CA is
+	// trusted, so any cert that's valid for it belongs to the cluster.
+	ServerName = "pachyderm-internal"
+
+	// DefaultCertTTL is how long the certs issued by the cluster CA are valid.
+	// They're reissued once half of this has passed.
+	DefaultCertTTL = 24 * time.Hour
+
+	caTTL      = 10 * 365 * 24 * time.Hour
+	rsaKeySize = 2048
+)
+
+// CA is the certificate authority of a cluster, which issues the certs that
+// its components use to authenticate each other
+type CA struct {
+	Cert *x509.Certificate
+	Key  *rsa.PrivateKey
+}
+
+// GenerateCA generates a new, self-signed cluster CA
+func GenerateCA() (*CA, error) {
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate CA private key: %v", err)
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "pachyderm-internal-ca"},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(caTTL),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("could not self-sign CA cert: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the just-generated CA cert: %v", err)
+	}
+	return &CA{Cert: caCert, Key: key}, nil
+}
+
+// LoadCA reads the cluster CA's cert and private key from 'dir'
+func LoadCA(dir string) (*CA, error) {
+	pair, err := tls.LoadX509KeyPair(filepath.Join(dir, CACertFile), filepath.Join(dir, CAKeyFile))
+	if err != nil {
+		return nil, fmt.Errorf("could not load cluster CA from %s: %v", dir, err)
+	}
+	key, ok := pair.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("cluster CA in %s doesn't have an RSA private key", dir)
+	}
+	caCert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("could not parse cluster CA cert: %v", err)
+	}
+	return &CA{Cert: caCert, Key: key}, nil
+}
+
+// CertPEM returns the CA's cert, PEM-encoded
+func (ca *CA) CertPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Cert.Raw})
+}
+
+// KeyPEM returns the CA's private key, PEM-encoded
+func (ca *CA) KeyPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(ca.Key),
+	})
+}
+
+// Pool returns a cert pool that trusts only the CA
+func (ca *CA) Pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	return pool
+}
+
+// Issue issues a cert for 'name', which is valid for 'ttl' for both serving
+// and authenticating to ServerName and 'hosts'
+func (ca *CA) Issue(name string, hosts []string, ttl time.Duration) (*tls.Certificate, error) {
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate private key: %v", err)
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    now.Add(-time.Minute), // tolerate some clock skew
+		NotAfter:     now.Add(ttl),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     append([]string{ServerName}, hosts...),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, &key.PublicKey, ca.Key)
+	if err != nil {
+		return nil, fmt.Errorf("could not sign cert for %q: %v", name, err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the just-issued cert for %q: %v", name, err)
+	}
+	return &tls.Certificate{
+		Certificate: [][]byte{der},
+		Leaf:        leaf,
+		PrivateKey:  key,
+	}, nil
+}
+
+// SecretData issues a cert for 'name' (see Issue) and returns the contents of
+// a kubernetes secret holding it, which may be mounted at CertVolumePath
+func (ca *CA) SecretData(name string, hosts []string, ttl time.Duration) (map[string][]byte, error) {
+	issued, err := ca.Issue(name, hosts, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		CACertFile: ca.CertPEM(),
+		CertFile:   cert.PublicCertToPEM(issued),
+		KeyFile:    cert.KeyToPEM(issued),
+	}, nil
+}
+
+func serialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("could not generate serial number: %v", err)
+	}
+	return serial, nil
+}
+
+// needsReissue returns true if 'leaf' is at least halfway through its
+// validity period at 'now'
+func needsReissue(leaf *x509.Certificate, now time.Time) bool {
+	halfway := leaf.NotBefore.Add(leaf.NotAfter.Sub(leaf.NotBefore) / 2)
+	return !now.Before(halfway)
+}
+
+// certSource provides the current cert of a component, which changes as the
+// cert is reissued
+type certSource interface {
+	certificate() (*tls.Certificate, error)
+}
+
+// issuingSource issues its own certs with the cluster CA (used in pachd)
+type issuingSource struct {
+	ca   *CA
+	name string
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	current *tls.Certificate
+}
+
+func (s *issuingSource) certificate() (*tls.Certificate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == nil || needsReissue(s.current.Leaf, time.Now()) {
+		issued, err := s.ca.Issue(s.name, nil, s.ttl)
+		if err != nil {
+			return nil, err
+		}
+		s.current = issued
+	}
+	return s.current, nil
+}
+
+// fileSource reads its certs from a mounted secret, and rereads them whenever
+// the secret is updated (used in workers)
+type fileSource struct {
+	dir string
+
+	mu      sync.Mutex
+	modTime time.Time
+	current *tls.Certificate
+}
+
+func (s *fileSource) certificate() (*tls.Certificate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	certPath := filepath.Join(s.dir, CertFile)
+	info, err := os.Stat(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not stat internal cert: %v", err)
+	}
+	if s.current == nil || !info.ModTime().Equal(s.modTime) {
+		pair, err := tls.LoadX509KeyPair(certPath, filepath.Join(s.dir, KeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("could not load internal cert: %v", err)
+		}
+		s.current, s.modTime = &pair, info.ModTime()
+	}
+	return s.current, nil
+}
+
+// Config is the internal TLS configuration of a pachd or worker process. A nil
+// *Config means that internal TLS is disabled, in which case its methods
+// return plaintext options.
+type Config struct {
+	ca     *CA // set if this process issues its own certs
+	source certSource
+	roots  *x509.CertPool
+}
+
+var (
+	internalOnce   sync.Once
+	internalConfig *Config
+	internalErr    error
+)
+
+// Internal returns the internal TLS configuration of this process, based on
+// what's mounted in its pod: pachd's pods have the cluster CA at CAVolumePath,
+// and worker pods have a cert at CertVolumePath. If neither is mounted,
+// internal TLS is disabled and Internal returns nil.
+func Internal() (*Config, error) {
+	internalOnce.Do(func() {
+		internalConfig, internalErr = load(CAVolumePath, CertVolumePath)
+	})
+	return internalConfig, internalErr
+}
+
+func load(caDir, certDir string) (*Config, error) {
+	if _, err := os.Stat(filepath.Join(caDir, CAKeyFile)); err == nil {
+		ca, err := LoadCA(caDir)
+		if err != nil {
+			return nil, err
+		}
+		name, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("could not get hostname for internal cert: %v", err)
+		}
+		return &Config{
+			ca:     ca,
+			source: &issuingSource{ca: ca, name: name, ttl: DefaultCertTTL},
+			roots:  ca.Pool(),
+		}, nil
+	}
+	if _, err := os.Stat(filepath.Join(certDir, CertFile)); err == nil {
+		caPEM, err := ioutil.ReadFile(filepath.Join(certDir, CACertFile))
+		if err != nil {
+			return nil, fmt.Errorf("could not read cluster CA cert: %v", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("could not parse cluster CA cert")
+		}
+		return &Config{source: &fileSource{dir: certDir}, roots: roots}, nil
+	}
+	return nil, nil
+}
+
+// CA returns the cluster CA if this process issues its own certs (i.e. it's
+// pachd), or nil otherwise
+func (c *Config) CA() *CA {
+	if c == nil {
+		return nil
+	}
+	return c.ca
+}
+
+// ServerTLSConfig returns a TLS config for servers, which requires clients to
+// present a cert issued by the cluster CA, or nil if internal TLS is disabled
+func (c *Config) ServerTLSConfig() *tls.Config {
+	if c == nil {
+		return nil
+	}
+	return &tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return c.source.certificate()
+		},
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  c.roots,
+		MinVersion: tls.VersionTLS12,
+	}
+}
+
+// ClientTLSConfig returns a TLS config for clients, which requires servers to
+// present a cert issued by the cluster CA, or nil if internal TLS is disabled
+func (c *Config) ClientTLSConfig() *tls.Config {
+	if c == nil {
+		return nil
+	}
+	return &tls.Config{
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return c.source.certificate()
+		},
+		RootCAs:    c.roots,
+		ServerName: ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+}
+
+// ServerOptions returns the options for gRPC servers that only serve other
+// components of the cluster
+func (c *Config) ServerOptions() []grpc.ServerOption {
+	if c == nil {
+		return nil
+	}
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(c.ServerTLSConfig()))}
+}
+
+// TransportCredentials returns the credentials for gRPC clients of other
+// components of the cluster, or nil if internal TLS is disabled
+func (c *Config) TransportCredentials() credentials.TransportCredentials {
+	if c == nil {
+		return nil
+	}
+	return credentials.NewTLS(c.ClientTLSConfig())
+}
+
+// DialOption returns the transport option for gRPC clients of other
+// components of the cluster
+func (c *Config) DialOption() grpc.DialOption {
+	if c == nil {
+		return grpc.WithInsecure()
+	}
+	return grpc.WithTransportCredentials(c.TransportCredentials())
+}
+
+// EtcdScheme returns the URL scheme of etcd's client endpoint
+func (c *Config) EtcdScheme() string {
+	if c == nil {
+		return "http"
+	}
+	return "https"
+}
+
+// PeerClientOptions returns the options for pach clients of pachd's peer port,
+// which requires a cert issued by the cluster CA if internal TLS is enabled
+func PeerClientOptions() ([]client.Option, error) {
+	internalTLS, err := Internal()
+	if err != nil || internalTLS == nil {
+		return nil, err
+	}
+	return []client.Option{client.WithTransportCredentials(internalTLS.TransportCredentials())}, nil
+}
@@ -0,0 +1,124 @@
+package mtls
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/server/pkg/cert"
+)
+
+// handshake runs a TLS handshake between a server using 'server' and a client
+// using 'client', and returns the handshake's error (from either side)
+func handshake(t *testing.T, server, client *tls.Config) error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close()
+		serverErr <- tls.Server(conn, server).Handshake()
+	}()
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	clientErr := tls.Client(conn, client).Handshake()
+	if err := <-serverErr; err != nil {
+		return err
+	}
+	return clientErr
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca, err := GenerateCA()
+	require.NoError(t, err)
+	config := &Config{
+		ca:     ca,
+		source: &issuingSource{ca: ca, name: "pachd", ttl: time.Hour},
+		roots:  ca.Pool(),
+	}
+	require.NoError(t, handshake(t, config.ServerTLSConfig(), config.ClientTLSConfig()))
+
+	// Certs issued by a different CA are rejected in both directions
+	otherCA, err := GenerateCA()
+	require.NoError(t, err)
+	other := &Config{
+		source: &issuingSource{ca: otherCA, name: "intruder", ttl: time.Hour},
+		roots:  otherCA.Pool(),
+	}
+	require.YesError(t, handshake(t, config.ServerTLSConfig(), other.ClientTLSConfig()))
+	require.YesError(t, handshake(t, other.ServerTLSConfig(), config.ClientTLSConfig()))
+
+	// Clients without a cert are rejected
+	anonymous := config.ClientTLSConfig()
+	anonymous.GetClientCertificate = nil
+	require.YesError(t, handshake(t, config.ServerTLSConfig(), anonymous))
+}
+
+func TestLoadFromSecret(t *testing.T) {
+	ca, err := GenerateCA()
+	require.NoError(t, err)
+	dir, err := ioutil.TempDir("", "mtls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Nothing mounted: internal TLS is disabled
+	config, err := load(filepath.Join(dir, "ca"), filepath.Join(dir, "cert"))
+	require.NoError(t, err)
+	require.Nil(t, config)
+	require.Nil(t, config.ServerTLSConfig())
+
+	// A worker's mounted secret
+	data, err := ca.SecretData("worker", nil, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "cert"), 0700))
+	for name, contents := range data {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "cert", name), contents, 0600))
+	}
+	worker, err := load(filepath.Join(dir, "ca"), filepath.Join(dir, "cert"))
+	require.NoError(t, err)
+	require.Nil(t, worker.CA())
+
+	// pachd's mounted CA
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "ca"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "ca", CACertFile), ca.CertPEM(), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "ca", CAKeyFile), ca.KeyPEM(), 0600))
+	pachd, err := load(filepath.Join(dir, "ca"), filepath.Join(dir, "cert"))
+	require.NoError(t, err)
+	require.NotNil(t, pachd.CA())
+
+	require.NoError(t, handshake(t, worker.ServerTLSConfig(), pachd.ClientTLSConfig()))
+	require.NoError(t, handshake(t, pachd.ServerTLSConfig(), worker.ClientTLSConfig()))
+}
+
+func TestSecretNeedsReissue(t *testing.T) {
+	ca, err := GenerateCA()
+	require.NoError(t, err)
+	now := time.Now()
+	data, err := ca.SecretData(WorkerSecretName, nil, DefaultCertTTL)
+	require.NoError(t, err)
+
+	// A fresh cert isn't reissued, but one that's halfway through its
+	// validity period is
+	require.False(t, secretNeedsReissue(data, ca, now))
+	require.True(t, secretNeedsReissue(data, ca, now.Add(DefaultCertTTL/2)))
+
+	// So is a missing cert, or one that wasn't issued by the CA
+	require.True(t, secretNeedsReissue(nil, ca, now))
+	otherCA, err := GenerateCA()
+	require.NoError(t, err)
+	require.True(t, secretNeedsReissue(data, otherCA, now))
+	self, err := cert.GenerateSelfSignedCert("etcd", nil)
+	require.NoError(t, err)
+	require.True(t, secretNeedsReissue(map[string][]byte{CertFile: cert.PublicCertToPEM(self)}, ca, now))
+}
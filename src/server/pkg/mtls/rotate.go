@@ -0,0 +1,122 @@
+package mtls
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kube "k8s.io/client-go/kubernetes"
+)
+
+const (
+	// WorkerSecretName is the name of the kubernetes secret holding the
+	// internal cert of worker pods
+	WorkerSecretName = "pachyderm-worker-tls"
+
+	// EtcdSecretName is the name of the kubernetes secret holding the internal
+	// cert of etcd's pods
+	EtcdSecretName = "pachyderm-etcd-tls"
+
+	// rotationInterval is how often RotateSecrets checks whether the certs in
+	// its secrets need to be reissued
+	rotationInterval = 10 * time.Minute
+)
+
+// EtcdHosts returns the DNS names of etcd in 'namespace', which etcd's cert
+// must be valid for (etcd nodes also use the cert to authenticate each other)
+func EtcdHosts(namespace string) []string {
+	return []string{
+		"etcd",
+		fmt.Sprintf("etcd.%s", namespace),
+		fmt.Sprintf("etcd.%s.svc", namespace),
+		fmt.Sprintf("etcd.%s.svc.cluster.local", namespace),
+		fmt.Sprintf("*.etcd-headless.%s.svc.cluster.local", namespace),
+	}
+}
+
+// RotateSecrets keeps the certs in the worker and etcd secrets in
+// 'namespace' valid, by reissuing them with 'ca' (creating the worker secret
+// if it doesn't exist) once they're halfway through their validity period.
+// Kubernetes then updates the secrets wherever they're mounted, and workers
+// and etcd pick up the new certs. It runs until 'ctx' is cancelled.
+func RotateSecrets(ctx context.Context, kubeClient kube.Interface, namespace string, ca *CA) error {
+	secrets := map[string][]string{
+		WorkerSecretName: nil,
+		EtcdSecretName:   EtcdHosts(namespace),
+	}
+	for {
+		for name, hosts := range secrets {
+			if err := backoff.RetryNotify(func() error {
+				return rotateSecret(kubeClient, namespace, name, hosts, ca, time.Now())
+			}, backoff.NewExponentialBackOff(), func(err error, d time.Duration) error {
+				log.Errorf("error rotating internal cert in secret %q: %v; retrying in %v", name, err, d)
+				return nil
+			}); err != nil {
+				log.Errorf("giving up on rotating internal cert in secret %q until the next check: %v", name, err)
+			}
+		}
+		select {
+		case <-time.After(rotationInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// rotateSecret reissues the cert in the secret 'name' if it needs to be
+// reissued at 'now', creating the secret if it doesn't exist
+func rotateSecret(kubeClient kube.Interface, namespace, name string, hosts []string, ca *CA, now time.Time) error {
+	secrets := kubeClient.CoreV1().Secrets(namespace)
+	secret, err := secrets.Get(name, metav1.GetOptions{})
+	notFound := k8serrors.IsNotFound(err)
+	if err != nil && !notFound {
+		return err
+	}
+	if !notFound && !secretNeedsReissue(secret.Data, ca, now) {
+		return nil
+	}
+	data, err := ca.SecretData(name, hosts, DefaultCertTTL)
+	if err != nil {
+		return err
+	}
+	if notFound {
+		_, err = secrets.Create(&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"app": name, "suite": "pachyderm"},
+			},
+			Data: data,
+		})
+		return err
+	}
+	secret.Data = data
+	if _, err := secrets.Update(secret); err != nil {
+		return err
+	}
+	log.Infof("reissued internal cert in secret %q", name)
+	return nil
+}
+
+// secretNeedsReissue returns true if the secret with contents 'data' doesn't
+// hold a cert issued by 'ca', or if its cert needs to be reissued at 'now'
+func secretNeedsReissue(data map[string][]byte, ca *CA, now time.Time) bool {
+	block, _ := pem.Decode(data[CertFile])
+	if block == nil {
+		return true
+	}
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return true
+	}
+	if err := leaf.CheckSignatureFrom(ca.Cert); err != nil {
+		return true
+	}
+	return needsReissue(leaf, now)
+}
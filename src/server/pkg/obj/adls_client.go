@@ -0,0 +1,345 @@
+package obj
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest/adal"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/pachyderm/pachyderm/src/client/limit"
+	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/client/pkg/tracing"
+	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
+)
+
+const (
+	// adlsVersion is the version of the DFS API that the ADLS client uses
+	adlsVersion = "2018-11-09"
+	// adlsResource is the resource that the ADLS client's managed identity
+	// tokens are for
+	adlsResource = "https://storage.azure.com/"
+	// adlsTmpDir is the directory that objects are written to before they're
+	// renamed into place, so that readers never see partially written objects
+	adlsTmpDir = ".pachyderm-tmp"
+)
+
+// adlsClient is a client for Azure Data Lake Storage Gen2, i.e. storage
+// accounts with a hierarchical namespace. It uses the DFS endpoints rather
+// than the blob endpoints, so objects are renamed into place atomically and
+// prefixes are listed as directories.
+type adlsClient struct {
+	token      *adal.ServicePrincipalToken
+	filesystem string
+	// url is the URL of the client's filesystem, e.g.
+	// https://account.dfs.core.windows.net/filesystem
+	url string
+}
+
+func newADLSClient(filesystem string, accountName string, identity string) (*adlsClient, error) {
+	token, err := adlsToken(identity)
+	if err != nil {
+		return nil, err
+	}
+	return &adlsClient{
+		token:      token,
+		filesystem: filesystem,
+		url:        fmt.Sprintf("https://%s.dfs.core.windows.net/%s", accountName, filesystem),
+	}, nil
+}
+
+// adlsToken returns a token for the managed identity of the machine that
+// pachd is running on, or for the user-assigned managed identity with the
+// client ID 'identity' if it's set
+func adlsToken(identity string) (*adal.ServicePrincipalToken, error) {
+	msiEndpoint, err := adal.GetMSIVMEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	if identity != "" {
+		return adal.NewServicePrincipalTokenFromMSIWithUserAssignedID(msiEndpoint, adlsResource, identity)
+	}
+	return adal.NewServicePrincipalTokenFromMSI(msiEndpoint, adlsResource)
+}
+
+// adlsError is an error response from the DFS API
+type adlsError struct {
+	statusCode int
+	code       string
+	message    string
+}
+
+func (e *adlsError) Error() string {
+	return fmt.Sprintf("ADLS error %d (%s): %s", e.statusCode, e.code, e.message)
+}
+
+// do sends a request for the path 'name' in the client's filesystem (or for
+// the filesystem itself, if 'name' is empty) to the DFS API, and returns the
+// response if it succeeded. The caller must close the response's body.
+func (c *adlsClient) do(ctx context.Context, method string, name string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	if err := c.token.EnsureFreshWithContext(ctx); err != nil {
+		return nil, err
+	}
+	u := c.url
+	if name != "" {
+		u += (&url.URL{Path: "/" + strings.TrimPrefix(name, "/")}).EscapedPath()
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token.OAuthToken())
+	req.Header.Set("x-ms-version", adlsVersion)
+	req.ContentLength = int64(len(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		e := &adlsError{statusCode: resp.StatusCode, code: resp.Header.Get("x-ms-error-code")}
+		var errBody struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&errBody); err == nil {
+			e.message = errBody.Error.Message
+		}
+		return nil, e
+	}
+	return resp, nil
+}
+
+// send is like do, but for requests whose responses have no body
+func (c *adlsClient) send(ctx context.Context, method string, name string, query url.Values, header http.Header, body []byte) error {
+	resp, err := c.do(ctx, method, name, query, header, body)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (c *adlsClient) Writer(ctx context.Context, name string) (io.WriteCloser, error) {
+	tmp := path.Join(adlsTmpDir, uuid.NewWithoutDashes())
+	if err := c.send(ctx, "PUT", tmp, url.Values{"resource": {"file"}}, nil, nil); err != nil {
+		return nil, err
+	}
+	return newADLSWriter(ctx, c, name, tmp), nil
+}
+
+func (c *adlsClient) Reader(ctx context.Context, name string, offset uint64, size uint64) (io.ReadCloser, error) {
+	header := make(http.Header)
+	if r := byteRange(offset, size); r != "" {
+		header.Set("Range", "bytes="+r)
+	}
+	resp, err := c.do(ctx, "GET", name, nil, header, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *adlsClient) Delete(ctx context.Context, name string) error {
+	return c.send(ctx, "DELETE", name, nil, nil, nil)
+}
+
+// Walk lists the directory that contains 'prefix' recursively, which is much
+// faster than listing a flat namespace when objects are spread across many
+// directories
+func (c *adlsClient) Walk(ctx context.Context, prefix string, f func(name string) error) error {
+	dir := prefix
+	if !strings.HasSuffix(dir, "/") {
+		dir = path.Dir(dir)
+	}
+	query := url.Values{"resource": {"filesystem"}, "recursive": {"true"}}
+	if dir = strings.Trim(dir, "/."); dir != "" {
+		query.Set("directory", dir)
+	}
+	for {
+		resp, err := c.do(ctx, "GET", "", query, nil, nil)
+		if err != nil {
+			if c.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		var list struct {
+			Paths []struct {
+				Name        string `json:"name"`
+				IsDirectory string `json:"isDirectory"`
+			} `json:"paths"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		for _, p := range list.Paths {
+			if p.IsDirectory == "true" || !strings.HasPrefix(p.Name, prefix) ||
+				strings.HasPrefix(p.Name, adlsTmpDir+"/") {
+				continue
+			}
+			if err := f(p.Name); err != nil {
+				return err
+			}
+		}
+		// The continuation header is empty when all results have been returned
+		continuation := resp.Header.Get("x-ms-continuation")
+		if continuation == "" {
+			return nil
+		}
+		query.Set("continuation", continuation)
+	}
+}
+
+func (c *adlsClient) Exists(ctx context.Context, name string) bool {
+	err := c.send(ctx, "HEAD", name, nil, nil, nil)
+	tracing.TagAnySpan(ctx, "err", err)
+	return err == nil
+}
+
+func (c *adlsClient) IsRetryable(err error) bool {
+	adlsErr, ok := err.(*adlsError)
+	return ok && adlsErr.statusCode >= 500
+}
+
+func (c *adlsClient) IsNotExist(err error) bool {
+	adlsErr, ok := err.(*adlsError)
+	return ok && adlsErr.statusCode == http.StatusNotFound
+}
+
+func (c *adlsClient) IsIgnorable(err error) bool {
+	return false
+}
+
+// rename atomically moves the file 'src' to 'dst', replacing it if it exists
+func (c *adlsClient) rename(ctx context.Context, src string, dst string) error {
+	source := (&url.URL{Path: path.Join("/", c.filesystem, src)}).EscapedPath()
+	header := http.Header{"X-Ms-Rename-Source": {source}}
+	err := c.send(ctx, "PUT", dst, url.Values{"mode": {"posix"}}, header, nil)
+	if c.IsNotExist(err) && path.Dir(dst) != "." {
+		// The destination's parent directory doesn't exist yet (renames don't
+		// create it, unlike creating a file)
+		if err := c.send(ctx, "PUT", path.Dir(dst), url.Values{"resource": {"directory"}}, nil, nil); err != nil {
+			return err
+		}
+		err = c.send(ctx, "PUT", dst, url.Values{"mode": {"posix"}}, header, nil)
+	}
+	return err
+}
+
+// adlsWriter writes an object to a temporary file, appending its blocks in
+// parallel, and then renames the file to the object's name when it's closed
+type adlsWriter struct {
+	ctx     context.Context
+	client  *adlsClient
+	name    string
+	tmp     string
+	w       *grpcutil.ChunkWriteCloser
+	limiter limit.ConcurrencyLimiter
+	eg      *errgroup.Group
+	size    int64
+	err     error
+}
+
+func newADLSWriter(ctx context.Context, client *adlsClient, name string, tmp string) *adlsWriter {
+	eg, cancelCtx := errgroup.WithContext(ctx)
+	w := &adlsWriter{
+		ctx:     cancelCtx,
+		client:  client,
+		name:    name,
+		tmp:     tmp,
+		limiter: limit.New(concurrency),
+		eg:      eg,
+	}
+	w.w = grpcutil.NewChunkWriteCloser(bufPool, w.writeBlock)
+	return w
+}
+
+func (w *adlsWriter) Write(data []byte) (retN int, retErr error) {
+	span, _ := tracing.AddSpanToAnyExisting(w.ctx, "/ADLS.Writer/Write")
+	defer func() {
+		tracing.FinishAnySpan(span, "bytes", retN, "err", retErr)
+	}()
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.w.Write(data)
+}
+
+func (w *adlsWriter) writeBlock(block []byte) (retErr error) {
+	span, _ := tracing.AddSpanToAnyExisting(w.ctx, "/ADLS.Writer/WriteBlock")
+	defer func() {
+		tracing.FinishAnySpan(span, "err", retErr)
+	}()
+	// Blocks are appended at explicit positions, so they can be uploaded in
+	// any order
+	query := url.Values{
+		"action":   {"append"},
+		"position": {strconv.FormatInt(w.size, 10)},
+	}
+	w.size += int64(len(block))
+	w.limiter.Acquire()
+	w.eg.Go(func() error {
+		defer w.limiter.Release()
+		defer bufPool.Put(block[:cap(block)]) //lint:ignore SA6002 []byte is sufficiently pointer-like for our purposes
+		// w.ctx is also cancelled if another block fails
+		if err := w.ctx.Err(); err != nil {
+			return err
+		}
+		if err := w.client.send(w.ctx, "PATCH", w.tmp, query, nil, block); err != nil {
+			w.err = err
+			return err
+		}
+		return nil
+	})
+	return nil
+}
+
+func (w *adlsWriter) Close() (retErr error) {
+	span, _ := tracing.AddSpanToAnyExisting(w.ctx, "/ADLS.Writer/Close")
+	defer func() {
+		tracing.FinishAnySpan(span, "err", retErr)
+	}()
+	defer func() {
+		if retErr != nil {
+			// Don't leave the temporary file behind
+			w.client.Delete(context.Background(), w.tmp)
+		}
+	}()
+	if err := w.w.Close(); err != nil {
+		return err
+	}
+	if err := w.eg.Wait(); err != nil {
+		return err
+	}
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	// Commit the appended blocks
+	query := url.Values{
+		"action":   {"flush"},
+		"position": {strconv.FormatInt(w.size, 10)},
+		"close":    {"true"},
+	}
+	if err := w.client.send(w.ctx, "PATCH", w.tmp, query, nil, nil); err != nil {
+		return err
+	}
+	return w.client.rename(w.ctx, w.tmp, w.name)
+}
@@ -240,7 +240,7 @@ func (c *amazonClient) Writer(ctx context.Context, name string) (io.WriteCloser,
 	return newBackoffWriteCloser(ctx, c, newWriter(ctx, c, name)), nil
 }
 
-func (c *amazonClient) Walk(_ context.Context, name string, fn func(name string) error) error {
+func (c *amazonClient) Walk(ctx context.Context, name string, fn func(name string) error) error {
 	var fnErr error
 	var prefix *string
 
@@ -250,7 +250,7 @@ func (c *amazonClient) Walk(_ context.Context, name string, fn func(name string)
 		prefix = &name
 	}
 
-	if err := c.s3.ListObjectsPages(
+	if err := c.s3.ListObjectsPagesWithContext(ctx,
 		&s3.ListObjectsInput{
 			Bucket: aws.String(c.bucket),
 			Prefix: prefix,
@@ -301,6 +301,7 @@ func (c *amazonClient) Reader(ctx context.Context, name string, offset uint64, s
 		if err != nil {
 			return nil, err
 		}
+		req = req.WithContext(ctx)
 		req.Header.Add("Range", byteRange)
 
 		backoff.RetryNotify(func() (retErr error) {
@@ -313,7 +314,7 @@ func (c *amazonClient) Reader(ctx context.Context, name string, offset uint64, s
 				return connErr
 			}
 			return nil
-		}, backoff.NewExponentialBackOff(), func(err error, d time.Duration) error {
+		}, backoff.WithContext(backoff.NewExponentialBackOff(), ctx), func(err error, d time.Duration) error {
 			log.Infof("Error connecting to (%v); retrying in %s: %#v", url, d, err)
 			return nil
 		})
@@ -333,7 +334,7 @@ func (c *amazonClient) Reader(ctx context.Context, name string, offset uint64, s
 		if byteRange != "" {
 			objIn.Range = aws.String(byteRange)
 		}
-		getObjectOutput, err := c.s3.GetObject(objIn)
+		getObjectOutput, err := c.s3.GetObjectWithContext(ctx, objIn)
 		if err != nil {
 			return nil, err
 		}
@@ -342,11 +343,11 @@ func (c *amazonClient) Reader(ctx context.Context, name string, offset uint64, s
 	return newBackoffReadCloser(ctx, c, reader), nil
 }
 
-func (c *amazonClient) Delete(_ context.Context, name string) error {
+func (c *amazonClient) Delete(ctx context.Context, name string) error {
 	if c.advancedConfig.Reverse {
 		name = reverse(name)
 	}
-	_, err := c.s3.DeleteObject(&s3.DeleteObjectInput{
+	_, err := c.s3.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(c.bucket),
 		Key:    aws.String(name),
 	})
@@ -357,7 +358,7 @@ func (c *amazonClient) Exists(ctx context.Context, name string) bool {
 	if c.advancedConfig.Reverse {
 		name = reverse(name)
 	}
-	_, err := c.s3.HeadObject(&s3.HeadObjectInput{
+	_, err := c.s3.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
 		Bucket: aws.String(c.bucket),
 		Key:    aws.String(name),
 	})
@@ -373,6 +374,11 @@ func (c *amazonClient) IsRetryable(err error) (retVal bool) {
 		return true
 	}
 
+	// S3 returns 503s when it's throttling requests, which don't always have
+	// one of the error codes below
+	if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() >= 500 {
+		return true
+	}
 	awsErr, ok := err.(awserr.Error)
 	if !ok {
 		return false
@@ -411,7 +417,9 @@ func (c *amazonClient) IsNotExist(err error) bool {
 }
 
 type amazonWriter struct {
-	ctx     context.Context
+	ctx context.Context
+	// errChan is buffered, so that the upload goroutine can exit even if the
+	// writer is never closed (e.g. because ctx was cancelled)
 	errChan chan error
 	pipe    *io.PipeWriter
 }
@@ -420,11 +428,11 @@ func newWriter(ctx context.Context, client *amazonClient, name string) *amazonWr
 	reader, writer := io.Pipe()
 	w := &amazonWriter{
 		ctx:     ctx,
-		errChan: make(chan error),
+		errChan: make(chan error, 1),
 		pipe:    writer,
 	}
 	go func() {
-		_, err := client.uploader.Upload(&s3manager.UploadInput{
+		_, err := client.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
 			ACL:             aws.String(client.advancedConfig.UploadACL),
 			Body:            reader,
 			Bucket:          aws.String(client.bucket),
@@ -0,0 +1,673 @@
+package obj
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+)
+
+const (
+	// EncryptionKeyEnvVar is the URI of the master key that pachd and workers
+	// wrap objects' data keys with, e.g. "awskms://alias/pachyderm" (see
+	// newKeyProvider for the supported KMSs). Objects aren't encrypted if
+	// it's unset.
+	EncryptionKeyEnvVar = "STORAGE_ENCRYPTION_KEY"
+	// EncryptionKeyReuseEnvVar is how long a data key is used to encrypt new
+	// objects before a new one is generated
+	EncryptionKeyReuseEnvVar = "STORAGE_ENCRYPTION_KEY_REUSE"
+	// EncryptionVaultAddrEnvVar is the address of the Vault server whose
+	// transit secrets engine holds the master key, for vault:// keys
+	EncryptionVaultAddrEnvVar = "STORAGE_ENCRYPTION_VAULT_ADDR"
+	// EncryptionVaultTokenEnvVar is the Vault token that pachd and workers
+	// use to encrypt and decrypt data keys, for vault:// keys
+	EncryptionVaultTokenEnvVar = "STORAGE_ENCRYPTION_VAULT_TOKEN"
+
+	// DefaultEncryptionKeyReuse is how long a data key is reused for if
+	// STORAGE_ENCRYPTION_KEY_REUSE is unset. Reusing data keys means that
+	// pachd doesn't have to call the KMS for every object it writes or reads.
+	DefaultEncryptionKeyReuse = time.Hour
+)
+
+const (
+	// encryptionMagic starts every encrypted object, so that objects written
+	// before encryption was enabled can still be read
+	encryptionMagic = "\x89PACHENC\x01"
+	// encryptionSegmentSize is the amount of plaintext in each of an
+	// encrypted object's segments, which are encrypted and authenticated
+	// separately so that ranges of an object can be read
+	encryptionSegmentSize = 64 * 1024
+	// encryptionSaltSize is the size of each object's random salt, which its
+	// key is derived from (along with its data key)
+	encryptionSaltSize = 32
+	// maxEncryptionHeaderSize bounds the size of an encrypted object's header
+	maxEncryptionHeaderSize = 8 * 1024
+	// unwrappedKeyCacheSize is the number of unwrapped data keys that a
+	// client caches
+	unwrappedKeyCacheSize = 1024
+)
+
+// errObjectTruncated is returned when an encrypted object ends before its
+// final segment, i.e. it was truncated
+var errObjectTruncated = errors.New("encrypted object is truncated")
+
+// EncryptionConfig configures client-side encryption of objects
+type EncryptionConfig struct {
+	// Key is the URI of the master key, or "" if objects aren't encrypted
+	Key string
+	// KeyReuse is how long a data key is reused for
+	KeyReuse time.Duration
+	// VaultAddress and VaultToken are used to reach Vault, for vault:// keys
+	VaultAddress string
+	VaultToken   string
+}
+
+// EncryptionFromEnv returns the encryption configuration in pachd's (or a
+// worker's) environment
+func EncryptionFromEnv() (*EncryptionConfig, error) {
+	config := &EncryptionConfig{
+		Key:          os.Getenv(EncryptionKeyEnvVar),
+		VaultAddress: os.Getenv(EncryptionVaultAddrEnvVar),
+		VaultToken:   os.Getenv(EncryptionVaultTokenEnvVar),
+	}
+	var err error
+	if config.KeyReuse, err = durationFromEnv(EncryptionKeyReuseEnvVar, DefaultEncryptionKeyReuse); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// dataKey is a data key, which is used to encrypt objects and is wrapped by
+// a master key
+type dataKey struct {
+	uri     string // the master key's URI
+	key     []byte
+	wrapped []byte
+	created time.Time
+}
+
+// encryptedClient is a Client that encrypts objects before they're uploaded
+// and decrypts them when they're read, using envelope encryption: each
+// object's key is derived from a data key, which is stored in the object's
+// header, wrapped by a master key in a KMS.
+//
+// An encrypted object consists of:
+//
+//	encryptionMagic
+//	the master key's URI (a uint16 length and the URI)
+//	the wrapped data key (a uint16 length and the key)
+//	a random salt
+//	segments of at most encryptionSegmentSize bytes of plaintext, each
+//	encrypted with AES-GCM
+//
+// Each segment's nonce contains its index and whether it's the last segment,
+// so that segments can't be reordered and objects can't be truncated
+// without it being detected.
+type encryptedClient struct {
+	Client
+	key         string
+	keyReuse    time.Duration
+	newProvider func(ctx context.Context, uri string) (keyProvider, error)
+
+	mu        sync.Mutex
+	providers map[string]keyProvider
+	current   *dataKey
+	unwrapped simplelru.LRUCache // unwrapped data keys, by URI and wrapped key
+}
+
+// EncryptedObjClient wraps the given object client 'c', encrypting the
+// objects that it writes and decrypting the objects that it reads if
+// STORAGE_ENCRYPTION_KEY is set. Objects that aren't encrypted are read as
+// they are, so encryption can be enabled in an existing cluster.
+func EncryptedObjClient(c Client) (Client, error) {
+	config, err := EncryptionFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if config.Key == "" {
+		return c, nil
+	}
+	return newEncryptedClient(c, config, func(ctx context.Context, uri string) (keyProvider, error) {
+		return newKeyProvider(ctx, uri, config)
+	})
+}
+
+func newEncryptedClient(c Client, config *EncryptionConfig, newProvider func(context.Context, string) (keyProvider, error)) (*encryptedClient, error) {
+	unwrapped, err := simplelru.NewLRU(unwrappedKeyCacheSize, nil)
+	if err != nil {
+		return nil, err
+	}
+	e := &encryptedClient{
+		Client:      c,
+		key:         config.Key,
+		keyReuse:    config.KeyReuse,
+		newProvider: newProvider,
+		providers:   make(map[string]keyProvider),
+		unwrapped:   unwrapped,
+	}
+	// Fail early if the master key is misconfigured
+	if _, err := e.provider(context.Background(), config.Key); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// provider returns the keyProvider for the master key 'uri'. Objects record
+// the master key that their data key is wrapped with, so objects written
+// before the master key was changed can still be read.
+func (c *encryptedClient) provider(ctx context.Context, uri string) (keyProvider, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p, ok := c.providers[uri]; ok {
+		return p, nil
+	}
+	p, err := c.newProvider(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	c.providers[uri] = p
+	return p, nil
+}
+
+// dataKey returns the data key that new objects are encrypted with,
+// generating a new one if the current one has been used for keyReuse
+func (c *encryptedClient) dataKey(ctx context.Context) (*dataKey, error) {
+	c.mu.Lock()
+	current := c.current
+	c.mu.Unlock()
+	if current != nil && time.Since(current.created) < c.keyReuse {
+		return current, nil
+	}
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	wrapped, err := c.wrap(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	current = &dataKey{uri: c.key, key: key, wrapped: wrapped, created: time.Now()}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current = current
+	c.unwrapped.Add(c.key+"\x00"+string(wrapped), key)
+	return current, nil
+}
+
+// wrap wraps 'key' with the current master key
+func (c *encryptedClient) wrap(ctx context.Context, key []byte) ([]byte, error) {
+	p, err := c.provider(ctx, c.key)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := p.wrap(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("error wrapping data key with %s: %v", c.key, err)
+	}
+	return wrapped, nil
+}
+
+// unwrap returns the data key 'wrapped', which is wrapped by the master key
+// 'uri'
+func (c *encryptedClient) unwrap(ctx context.Context, uri string, wrapped []byte) ([]byte, error) {
+	cacheKey := uri + "\x00" + string(wrapped)
+	c.mu.Lock()
+	key, ok := c.unwrapped.Get(cacheKey)
+	c.mu.Unlock()
+	if ok {
+		return key.([]byte), nil
+	}
+	p, err := c.provider(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	unwrapped, err := p.unwrap(ctx, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("error unwrapping data key with %s: %v", uri, err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unwrapped.Add(cacheKey, unwrapped)
+	return unwrapped, nil
+}
+
+// encryptionHeader is the header of an encrypted object
+type encryptionHeader struct {
+	uri     string
+	wrapped []byte
+	salt    []byte
+}
+
+func (h *encryptionHeader) marshal() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(encryptionMagic)
+	binary.Write(&buf, binary.BigEndian, uint16(len(h.uri)))
+	buf.WriteString(h.uri)
+	binary.Write(&buf, binary.BigEndian, uint16(len(h.wrapped)))
+	buf.Write(h.wrapped)
+	buf.Write(h.salt)
+	return buf.Bytes()
+}
+
+// size is the size of the header, which is where the object's segments
+// start
+func (h *encryptionHeader) size() int64 {
+	return int64(len(encryptionMagic) + 2 + len(h.uri) + 2 + len(h.wrapped) + len(h.salt))
+}
+
+// isEncrypted returns whether the object that 'r' reads is encrypted,
+// without consuming any of it
+func isEncrypted(r *bufio.Reader) bool {
+	prefix, _ := r.Peek(len(encryptionMagic))
+	return string(prefix) == encryptionMagic
+}
+
+// readEncryptionHeader reads an encrypted object's header from 'r'
+func readEncryptionHeader(r io.Reader) (*encryptionHeader, error) {
+	magic := make([]byte, len(encryptionMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, err
+	}
+	readField := func() ([]byte, error) {
+		var n uint16
+		if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+			return nil, err
+		}
+		field := make([]byte, n)
+		_, err := io.ReadFull(r, field)
+		return field, err
+	}
+	uri, err := readField()
+	if err != nil {
+		return nil, fmt.Errorf("error reading encryption header: %v", err)
+	}
+	wrapped, err := readField()
+	if err != nil {
+		return nil, fmt.Errorf("error reading encryption header: %v", err)
+	}
+	salt := make([]byte, encryptionSaltSize)
+	if _, err := io.ReadFull(r, salt); err != nil {
+		return nil, fmt.Errorf("error reading encryption header: %v", err)
+	}
+	return &encryptionHeader{uri: string(uri), wrapped: wrapped, salt: salt}, nil
+}
+
+// objectCipher returns the cipher that an object's segments are encrypted
+// with, whose key is derived from the object's data key and salt
+func objectCipher(key []byte, salt []byte) (cipher.AEAD, error) {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(salt)
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// segmentNonce returns the nonce of the segment 'index' of an object
+func segmentNonce(aead cipher.AEAD, index int64, final bool) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce, uint64(index))
+	if final {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+func (c *encryptedClient) Writer(ctx context.Context, name string) (io.WriteCloser, error) {
+	key, err := c.dataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	header := &encryptionHeader{uri: key.uri, wrapped: key.wrapped, salt: make([]byte, encryptionSaltSize)}
+	if _, err := io.ReadFull(rand.Reader, header.salt); err != nil {
+		return nil, err
+	}
+	if header.size() > maxEncryptionHeaderSize {
+		return nil, fmt.Errorf("encryption header is too big (%d bytes)", header.size())
+	}
+	aead, err := objectCipher(key.key, header.salt)
+	if err != nil {
+		return nil, err
+	}
+	w, err := c.Client.Writer(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header.marshal()); err != nil {
+		w.Close()
+		return nil, err
+	}
+	return &encryptedWriter{w: w, aead: aead}, nil
+}
+
+// encryptedWriter encrypts the data written to it in segments. It holds
+// back the last segment until it's closed, since the last segment is
+// encrypted differently.
+type encryptedWriter struct {
+	w     io.WriteCloser
+	aead  cipher.AEAD
+	index int64
+	buf   []byte
+	err   error
+}
+
+func (w *encryptedWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.buf = append(w.buf, p...)
+	for len(w.buf) > encryptionSegmentSize {
+		if w.err = w.writeSegment(w.buf[:encryptionSegmentSize], false); w.err != nil {
+			return 0, w.err
+		}
+		w.buf = w.buf[encryptionSegmentSize:]
+	}
+	return len(p), nil
+}
+
+func (w *encryptedWriter) writeSegment(plaintext []byte, final bool) error {
+	ciphertext := w.aead.Seal(nil, segmentNonce(w.aead, w.index, final), plaintext, nil)
+	w.index++
+	_, err := w.w.Write(ciphertext)
+	return err
+}
+
+func (w *encryptedWriter) Close() error {
+	if w.err != nil {
+		w.w.Close()
+		return w.err
+	}
+	if err := w.writeSegment(w.buf, true); err != nil {
+		w.w.Close()
+		return err
+	}
+	return w.w.Close()
+}
+
+func (c *encryptedClient) Reader(ctx context.Context, name string, offset uint64, size uint64) (io.ReadCloser, error) {
+	if offset == 0 && size == 0 {
+		// Read the header and the segments in a single request
+		r, err := c.Client.Reader(ctx, name, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		br := bufio.NewReaderSize(r, maxEncryptionHeaderSize)
+		if !isEncrypted(br) {
+			return &readCloser{Reader: br, Closer: r}, nil
+		}
+		header, err := readEncryptionHeader(br)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		aead, err := c.objectCipher(ctx, header)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		return &readCloser{Reader: newDecryptingReader(br, aead, 0, 0, -1, -1), Closer: r}, nil
+	}
+	// Read the header, and then the segments that contain the range
+	hr, err := c.Client.Reader(ctx, name, 0, maxEncryptionHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	defer hr.Close()
+	br := bufio.NewReaderSize(hr, maxEncryptionHeaderSize)
+	if !isEncrypted(br) {
+		return c.Client.Reader(ctx, name, offset, size)
+	}
+	header, err := readEncryptionHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := c.objectCipher(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+	segmentSize := uint64(encryptionSegmentSize + aead.Overhead())
+	first := offset / encryptionSegmentSize
+	var rangeSize uint64
+	segments := int64(-1)
+	limit := int64(-1)
+	if size > 0 {
+		last := (offset + size - 1) / encryptionSegmentSize
+		segments = int64(last - first + 1)
+		rangeSize = uint64(segments) * segmentSize
+		limit = int64(size)
+	}
+	r, err := c.Client.Reader(ctx, name, uint64(header.size())+first*segmentSize, rangeSize)
+	if err != nil {
+		return nil, err
+	}
+	dr := newDecryptingReader(r, aead, int64(first), int64(offset-first*encryptionSegmentSize), segments, limit)
+	return &readCloser{Reader: dr, Closer: r}, nil
+}
+
+// objectCipher returns the cipher that the object with 'header' is
+// encrypted with
+func (c *encryptedClient) objectCipher(ctx context.Context, header *encryptionHeader) (cipher.AEAD, error) {
+	key, err := c.unwrap(ctx, header.uri, header.wrapped)
+	if err != nil {
+		return nil, err
+	}
+	return objectCipher(key, header.salt)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// decryptingReader decrypts an encrypted object's segments
+type decryptingReader struct {
+	r     io.Reader
+	aead  cipher.AEAD
+	index int64
+	// skip is the number of bytes to skip at the start of the first segment
+	skip int64
+	// segments is the number of segments to read, or -1 to read until the
+	// final segment
+	segments int64
+	// limit is the number of bytes left to return, or -1 for no limit
+	limit int64
+
+	segment   []byte
+	plaintext []byte
+	buf       []byte // the decrypted data that hasn't been returned yet
+	done      bool
+}
+
+func newDecryptingReader(r io.Reader, aead cipher.AEAD, index int64, skip int64, segments int64, limit int64) *decryptingReader {
+	return &decryptingReader{
+		r:         r,
+		aead:      aead,
+		index:     index,
+		skip:      skip,
+		segments:  segments,
+		limit:     limit,
+		segment:   make([]byte, encryptionSegmentSize+aead.Overhead()),
+		plaintext: make([]byte, 0, encryptionSegmentSize),
+	}
+}
+
+func (r *decryptingReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done || r.limit == 0 || r.segments == 0 {
+			return 0, io.EOF
+		}
+		if err := r.readSegment(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *decryptingReader) readSegment() error {
+	n, err := io.ReadFull(r.r, r.segment)
+	switch {
+	case err == io.EOF:
+		// The object ended without a final segment
+		return errObjectTruncated
+	case err == io.ErrUnexpectedEOF:
+		// Only the final segment is shorter than the others
+	case err != nil:
+		return err
+	}
+	ciphertext := r.segment[:n]
+	final := n < len(r.segment)
+	plaintext, err := r.aead.Open(r.plaintext[:0], segmentNonce(r.aead, r.index, final), ciphertext, nil)
+	if err != nil && !final {
+		// A full segment may also be the final one
+		final = true
+		plaintext, err = r.aead.Open(r.plaintext[:0], segmentNonce(r.aead, r.index, final), ciphertext, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("error decrypting segment %d of encrypted object: %v", r.index, err)
+	}
+	r.index++
+	r.done = final
+	if r.segments > 0 {
+		r.segments--
+	}
+	if r.skip > 0 {
+		if r.skip > int64(len(plaintext)) {
+			r.skip = int64(len(plaintext))
+		}
+		plaintext = plaintext[r.skip:]
+		r.skip = 0
+	}
+	if r.limit >= 0 {
+		if int64(len(plaintext)) > r.limit {
+			plaintext = plaintext[:r.limit]
+		}
+		r.limit -= int64(len(plaintext))
+	}
+	r.buf = plaintext
+	return nil
+}
+
+// RewrapObjects rewrites the headers of the encrypted objects in 'c' that
+// start with 'prefix', wrapping their data keys with the current master key,
+// and encrypts the objects that aren't encrypted. It's used to rotate the
+// master key (after which the old key can be disabled) or to encrypt the
+// objects that were written before encryption was enabled. 'f', if set, is
+// called with the name of each object that's rewritten.
+func RewrapObjects(ctx context.Context, c Client, prefix string, f func(name string)) error {
+	e, ok := c.(*encryptedClient)
+	if !ok {
+		return fmt.Errorf("objects can't be rewrapped, as %s isn't set", EncryptionKeyEnvVar)
+	}
+	// Walk to completion before rewriting anything, as some object stores
+	// list objects that are written during a walk
+	var names []string
+	if err := e.Client.Walk(ctx, prefix, func(name string) error {
+		names = append(names, name)
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := e.rewrap(ctx, name); err != nil {
+			return fmt.Errorf("error rewrapping %s: %v", name, err)
+		}
+		if f != nil {
+			f(name)
+		}
+	}
+	return nil
+}
+
+// rewrap rewrites the object 'name' with its data key wrapped by the current
+// master key, or encrypts it if it isn't encrypted. The object is buffered
+// in a temporary file, as some object stores overwrite an object as soon as
+// it's opened for writing.
+func (c *encryptedClient) rewrap(ctx context.Context, name string) (retErr error) {
+	tmp, err := ioutil.TempFile("", "pachyderm-rewrap")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tmp.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+		if err := os.Remove(tmp.Name()); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	r, err := c.Client.Reader(ctx, name, 0, 0)
+	if err != nil {
+		return err
+	}
+	br := bufio.NewReaderSize(r, maxEncryptionHeaderSize)
+	var header *encryptionHeader
+	if isEncrypted(br) {
+		header, err = readEncryptionHeader(br)
+		if err != nil {
+			r.Close()
+			return err
+		}
+	}
+	_, err = io.Copy(tmp, br)
+	if closeErr := r.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if header == nil {
+		w, err := c.Writer(ctx, name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, tmp); err != nil {
+			w.Close()
+			return err
+		}
+		return w.Close()
+	}
+	// The segments are encrypted with a key derived from the data key, which
+	// doesn't change, so only the header needs to be rewritten
+	key, err := c.unwrap(ctx, header.uri, header.wrapped)
+	if err != nil {
+		return err
+	}
+	wrapped, err := c.wrap(ctx, key)
+	if err != nil {
+		return err
+	}
+	header.uri, header.wrapped = c.key, wrapped
+	w, err := c.Client.Writer(ctx, name)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(header.marshal()); err != nil {
+		w.Close()
+		return err
+	}
+	if _, err := io.Copy(w, tmp); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
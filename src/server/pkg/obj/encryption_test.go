@@ -0,0 +1,166 @@
+package obj
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+// testKeyProvider "wraps" data keys by prefixing them with its master key's
+// URI, so that keys wrapped by other master keys can't be unwrapped
+type testKeyProvider struct {
+	uri string
+}
+
+func (p *testKeyProvider) wrap(ctx context.Context, key []byte) ([]byte, error) {
+	return append([]byte(p.uri+":"), key...), nil
+}
+
+func (p *testKeyProvider) unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	if !bytes.HasPrefix(wrapped, []byte(p.uri+":")) {
+		return nil, fmt.Errorf("%s didn't wrap this key", p.uri)
+	}
+	return wrapped[len(p.uri)+1:], nil
+}
+
+func newTestEncryptedClient(t *testing.T, c Client, key string) *encryptedClient {
+	e, err := newEncryptedClient(c, &EncryptionConfig{Key: key, KeyReuse: DefaultEncryptionKeyReuse},
+		func(ctx context.Context, uri string) (keyProvider, error) {
+			return &testKeyProvider{uri: uri}, nil
+		})
+	require.NoError(t, err)
+	return e
+}
+
+func newTestLocalClient(t *testing.T) (Client, func()) {
+	dir, err := ioutil.TempDir("", "pachyderm-encryption")
+	require.NoError(t, err)
+	c, err := NewLocalClient(dir)
+	require.NoError(t, err)
+	return c, func() { os.RemoveAll(dir) }
+}
+
+func writeObject(t *testing.T, c Client, name string, data []byte) {
+	w, err := c.Writer(context.Background(), name)
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+}
+
+func readObject(t *testing.T, c Client, name string, offset uint64, size uint64) []byte {
+	r, err := c.Reader(context.Background(), name, offset, size)
+	require.NoError(t, err)
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	return data
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	raw, cleanup := newTestLocalClient(t)
+	defer cleanup()
+	c := newTestEncryptedClient(t, raw, "vault://transit/key")
+
+	for _, size := range []int{0, 1, encryptionSegmentSize, 3*encryptionSegmentSize + 17} {
+		data := make([]byte, size)
+		rand.Read(data)
+		name := fmt.Sprintf("object-%d", size)
+		writeObject(t, c, name, data)
+
+		// The object is encrypted in the object store
+		stored := readObject(t, raw, name, 0, 0)
+		require.True(t, bytes.HasPrefix(stored, []byte(encryptionMagic)))
+		if size > 0 {
+			require.False(t, bytes.Contains(stored, data))
+		}
+
+		require.True(t, bytes.Equal(data, readObject(t, c, name, 0, 0)))
+		for _, r := range [][2]int{
+			{0, 1},
+			{1, size - 1},
+			{encryptionSegmentSize - 1, 2},
+			{encryptionSegmentSize, encryptionSegmentSize},
+			{2*encryptionSegmentSize + 5, 0},
+		} {
+			offset, n := r[0], r[1]
+			if offset+n > size || offset >= size || n < 0 {
+				continue
+			}
+			expected := data[offset:]
+			if n > 0 {
+				expected = expected[:n]
+			}
+			require.True(t, bytes.Equal(expected, readObject(t, c, name, uint64(offset), uint64(n))),
+				"offset %d, size %d of a %d byte object", offset, n, size)
+		}
+	}
+}
+
+func TestEncryptionPlaintextObjects(t *testing.T) {
+	raw, cleanup := newTestLocalClient(t)
+	defer cleanup()
+	c := newTestEncryptedClient(t, raw, "vault://transit/key")
+
+	// Objects written before encryption was enabled can still be read
+	writeObject(t, raw, "plain", []byte("plaintext"))
+	require.Equal(t, "plaintext", string(readObject(t, c, "plain", 0, 0)))
+	require.Equal(t, "text", string(readObject(t, c, "plain", 5, 4)))
+}
+
+func TestEncryptionTruncated(t *testing.T) {
+	raw, cleanup := newTestLocalClient(t)
+	defer cleanup()
+	c := newTestEncryptedClient(t, raw, "vault://transit/key")
+
+	data := make([]byte, 2*encryptionSegmentSize+1)
+	writeObject(t, c, "object", data)
+	stored := readObject(t, raw, "object", 0, 0)
+
+	// Dropping the final segment is detected, even though the remaining
+	// segments decrypt
+	header := len(stored) - len(data) - 3*16
+	writeObject(t, raw, "object", stored[:header+2*(encryptionSegmentSize+16)])
+	r, err := c.Reader(context.Background(), "object", 0, 0)
+	require.NoError(t, err)
+	_, err = ioutil.ReadAll(r)
+	require.YesError(t, err)
+	r.Close()
+}
+
+func TestRewrapObjects(t *testing.T) {
+	raw, cleanup := newTestLocalClient(t)
+	defer cleanup()
+	ctx := context.Background()
+	old := newTestEncryptedClient(t, raw, "vault://transit/old")
+	writeObject(t, old, "dir/encrypted", []byte("encrypted"))
+	writeObject(t, raw, "dir/plain", []byte("plain"))
+
+	// After the master key is rotated, the old objects' keys are rewrapped
+	// and the unencrypted objects are encrypted
+	rotated := newTestEncryptedClient(t, raw, "vault://transit/new")
+	var rewrapped []string
+	require.NoError(t, RewrapObjects(ctx, rotated, "dir", func(name string) {
+		rewrapped = append(rewrapped, name)
+	}))
+	require.Equal(t, 2, len(rewrapped))
+
+	for name, data := range map[string]string{"dir/encrypted": "encrypted", "dir/plain": "plain"} {
+		r, err := raw.Reader(ctx, name, 0, 0)
+		require.NoError(t, err)
+		header, err := readEncryptionHeader(r)
+		require.NoError(t, err)
+		r.Close()
+		require.Equal(t, "vault://transit/new", header.uri)
+		// A client that only has the new key can read them
+		require.Equal(t, data, string(readObject(t, newTestEncryptedClient(t, raw, "vault://transit/new"), name, 0, 0)))
+	}
+
+	require.YesError(t, RewrapObjects(ctx, raw, "dir", nil))
+}
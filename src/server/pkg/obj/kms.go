@@ -0,0 +1,215 @@
+package obj
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	vault "github.com/hashicorp/vault/api"
+	"google.golang.org/api/cloudkms/v1"
+)
+
+// keyProvider wraps and unwraps (i.e. encrypts and decrypts) the data keys
+// that objects are encrypted with, using a master key that never leaves a
+// key management service
+type keyProvider interface {
+	wrap(ctx context.Context, key []byte) ([]byte, error)
+	unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// newKeyProvider returns the keyProvider for the master key 'uri', using the
+// credentials in 'config' if it needs any. 'uri' is one of:
+//
+//	awskms://<key ID, alias or ARN>
+//	gcpkms://projects/<project>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>
+//	vault://<transit mount>/<key name>
+func newKeyProvider(ctx context.Context, uri string, config *EncryptionConfig) (keyProvider, error) {
+	kms, key, err := parseEncryptionKey(uri)
+	if err != nil {
+		return nil, err
+	}
+	switch kms {
+	case "awskms":
+		return newAWSKeyProvider(key)
+	case "gcpkms":
+		return newGCPKeyProvider(ctx, key)
+	default:
+		return newVaultKeyProvider(key, config.VaultAddress, config.VaultToken)
+	}
+}
+
+// parseEncryptionKey splits the master key 'uri' into its KMS and the key's
+// name in that KMS
+func parseEncryptionKey(uri string) (string, string, error) {
+	parts := strings.SplitN(uri, "://", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid encryption key %q: must be of the form <kms>://<key>", uri)
+	}
+	switch parts[0] {
+	case "awskms", "gcpkms", "vault":
+		return parts[0], parts[1], nil
+	default:
+		return "", "", fmt.Errorf("invalid encryption key %q: unrecognized KMS %q (must be \"awskms\", \"gcpkms\" or \"vault\")", uri, parts[0])
+	}
+}
+
+// ValidateEncryptionKey returns an error if 'uri' isn't a valid master key
+// URI. It doesn't check that the key exists.
+func ValidateEncryptionKey(uri string, config *EncryptionConfig) error {
+	kms, _, err := parseEncryptionKey(uri)
+	if err != nil {
+		return err
+	}
+	if kms == "vault" && config.VaultAddress == "" {
+		return fmt.Errorf("the Vault server's address is required for the encryption key %q", uri)
+	}
+	return nil
+}
+
+type awsKeyProvider struct {
+	keyID string
+	kms   *kms.KMS
+}
+
+// newAWSKeyProvider returns a provider for an AWS KMS key. It uses pachd's
+// AWS credentials if it has any (and otherwise the instance's role), and the
+// key's region if 'keyID' is an ARN (and otherwise pachd's AWS region).
+func newAWSKeyProvider(keyID string) (*awsKeyProvider, error) {
+	awsConfig := &aws.Config{}
+	if keyARN, err := arn.Parse(keyID); err == nil {
+		awsConfig.Region = aws.String(keyARN.Region)
+	} else if region, ok := os.LookupEnv(AmazonRegionEnvVar); ok {
+		awsConfig.Region = aws.String(region)
+	}
+	if id, ok := os.LookupEnv(AmazonIDEnvVar); ok && id != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(id, os.Getenv(AmazonSecretEnvVar), os.Getenv(AmazonTokenEnvVar))
+	}
+	session, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &awsKeyProvider{keyID: keyID, kms: kms.New(session)}, nil
+}
+
+func (p *awsKeyProvider) wrap(ctx context.Context, key []byte) ([]byte, error) {
+	out, err := p.kms.EncryptWithContext(ctx, &kms.EncryptInput{
+		KeyId:     aws.String(p.keyID),
+		Plaintext: key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.CiphertextBlob, nil
+}
+
+func (p *awsKeyProvider) unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	// The ciphertext identifies the key (and key version) that it was
+	// encrypted with
+	out, err := p.kms.DecryptWithContext(ctx, &kms.DecryptInput{CiphertextBlob: wrapped})
+	if err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
+
+type gcpKeyProvider struct {
+	name string
+	keys *cloudkms.ProjectsLocationsKeyRingsCryptoKeysService
+}
+
+// newGCPKeyProvider returns a provider for a Cloud KMS key, which uses the
+// application default credentials
+func newGCPKeyProvider(ctx context.Context, name string) (*gcpKeyProvider, error) {
+	service, err := cloudkms.NewService(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &gcpKeyProvider{name: name, keys: service.Projects.Locations.KeyRings.CryptoKeys}, nil
+}
+
+func (p *gcpKeyProvider) wrap(ctx context.Context, key []byte) ([]byte, error) {
+	resp, err := p.keys.Encrypt(p.name, &cloudkms.EncryptRequest{
+		Plaintext: base64.StdEncoding.EncodeToString(key),
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(resp.Ciphertext)
+}
+
+func (p *gcpKeyProvider) unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	resp, err := p.keys.Decrypt(p.name, &cloudkms.DecryptRequest{
+		Ciphertext: base64.StdEncoding.EncodeToString(wrapped),
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(resp.Plaintext)
+}
+
+type vaultKeyProvider struct {
+	mount string
+	key   string
+	vault *vault.Client
+}
+
+// newVaultKeyProvider returns a provider for a key in a Vault transit secrets
+// engine, e.g. "transit/pachyderm", on the Vault server at 'addr'
+func newVaultKeyProvider(path string, addr string, token string) (*vaultKeyProvider, error) {
+	i := strings.LastIndex(path, "/")
+	if i <= 0 || i == len(path)-1 {
+		return nil, fmt.Errorf("invalid Vault encryption key %q: must be of the form <transit mount>/<key name>", path)
+	}
+	if addr == "" {
+		return nil, fmt.Errorf("%s not found", EncryptionVaultAddrEnvVar)
+	}
+	vaultClient, err := vault.NewClient(&vault.Config{Address: addr})
+	if err != nil {
+		return nil, fmt.Errorf("error creating vault client: %v", err)
+	}
+	vaultClient.SetToken(token)
+	return &vaultKeyProvider{mount: path[:i], key: path[i+1:], vault: vaultClient}, nil
+}
+
+func (p *vaultKeyProvider) wrap(ctx context.Context, key []byte) ([]byte, error) {
+	secret, err := p.vault.Logical().Write(p.mount+"/encrypt/"+p.key, map[string]interface{}{
+		"plaintext": base64.StdEncoding.EncodeToString(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var ciphertext string
+	if secret != nil {
+		ciphertext, _ = secret.Data["ciphertext"].(string)
+	}
+	if ciphertext == "" {
+		return nil, fmt.Errorf("Vault returned no ciphertext for key %s/%s", p.mount, p.key)
+	}
+	// Vault's ciphertexts are strings like "vault:v1:<base64>", where v1 is
+	// the version of the key that they're encrypted with
+	return []byte(ciphertext), nil
+}
+
+func (p *vaultKeyProvider) unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	secret, err := p.vault.Logical().Write(p.mount+"/decrypt/"+p.key, map[string]interface{}{
+		"ciphertext": string(wrapped),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var plaintext string
+	if secret != nil {
+		plaintext, _ = secret.Data["plaintext"].(string)
+	}
+	if plaintext == "" {
+		return nil, fmt.Errorf("Vault returned no plaintext for key %s/%s", p.mount, p.key)
+	}
+	return base64.StdEncoding.DecodeString(plaintext)
+}
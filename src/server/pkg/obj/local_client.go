@@ -72,7 +72,7 @@ func (c *localClient) Delete(_ context.Context, path string) error {
 	return os.Remove(c.normPath(path))
 }
 
-func (c *localClient) Walk(_ context.Context, dir string, walkFn func(name string) error) error {
+func (c *localClient) Walk(ctx context.Context, dir string, walkFn func(name string) error) error {
 	dir = c.normPath(dir)
 	fi, _ := os.Stat(dir)
 	prefix := ""
@@ -80,6 +80,9 @@ func (c *localClient) Walk(_ context.Context, dir string, walkFn func(name strin
 		dir, prefix = filepath.Split(dir)
 	}
 	return filepath.Walk(dir, func(path string, fileInfo os.FileInfo, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if err != nil {
 			if c.IsNotExist(err) {
 				return nil
@@ -43,11 +43,22 @@ func (c *microsoftClient) Writer(ctx context.Context, name string) (io.WriteClos
 }
 
 func (c *microsoftClient) Reader(ctx context.Context, name string, offset uint64, size uint64) (io.ReadCloser, error) {
-	blobRange := blobRange(offset, size)
-	if blobRange == nil {
-		return c.container.GetBlobReference(name).Get(nil)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var r io.ReadCloser
+	var err error
+	if blobRange := blobRange(offset, size); blobRange == nil {
+		r, err = c.container.GetBlobReference(name).Get(nil)
+	} else {
+		r, err = c.container.GetBlobReference(name).GetRange(&storage.GetBlobRangeOptions{Range: blobRange})
+	}
+	if err != nil {
+		return nil, err
 	}
-	return c.container.GetBlobReference(name).GetRange(&storage.GetBlobRangeOptions{Range: blobRange})
+	// The azure SDK can't cancel requests, so stop reading the blob once ctx is
+	// done instead
+	return &cancelReadCloser{ctx: ctx, ReadCloser: r}, nil
 }
 
 func blobRange(offset, size uint64) *storage.BlobRange {
@@ -59,14 +70,20 @@ func blobRange(offset, size uint64) *storage.BlobRange {
 	return &storage.BlobRange{Start: offset, End: offset + size}
 }
 
-func (c *microsoftClient) Delete(_ context.Context, name string) error {
+func (c *microsoftClient) Delete(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	_, err := c.container.GetBlobReference(name).DeleteIfExists(nil)
 	return err
 }
 
-func (c *microsoftClient) Walk(_ context.Context, name string, f func(name string) error) error {
+func (c *microsoftClient) Walk(ctx context.Context, name string, f func(name string) error) error {
 	var marker string
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		blobList, err := c.container.ListBlobs(storage.ListBlobsParameters{
 			Prefix: name,
 			Marker: marker,
@@ -158,6 +175,10 @@ func (w *microsoftWriter) writeBlock(block []byte) (retErr error) {
 	w.eg.Go(func() error {
 		defer w.limiter.Release()
 		defer bufPool.Put(block[:cap(block)]) //lint:ignore SA6002 []byte is sufficiently pointer-like for our purposes
+		// w.ctx is also cancelled if another block fails
+		if err := w.ctx.Err(); err != nil {
+			return err
+		}
 		if err := w.blob.PutBlock(blockID, block, nil); err != nil {
 			w.err = err
 			return err
@@ -182,6 +203,9 @@ func (w *microsoftWriter) Close() (retErr error) {
 	if err := w.eg.Wait(); err != nil {
 		return err
 	}
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
 	// Finalize the blocks.
 	blocks := make([]storage.Block, w.numBlocks)
 	for i := range blocks {
@@ -189,3 +213,16 @@ func (w *microsoftWriter) Close() (retErr error) {
 	}
 	return w.blob.PutBlockList(blocks, nil)
 }
+
+// cancelReadCloser stops reading from its ReadCloser once ctx is done
+type cancelReadCloser struct {
+	io.ReadCloser
+	ctx context.Context
+}
+
+func (r *cancelReadCloser) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.ReadCloser.Read(p)
+}
@@ -41,7 +41,9 @@ func newMinioClientV2(endpoint, bucket, id, secret string, secure bool) (*minioC
 
 // Represents minio writer structure with pipe and the error channel
 type minioWriter struct {
-	ctx     context.Context
+	ctx context.Context
+	// errChan is buffered, so that the upload goroutine can exit even if the
+	// writer is never closed (e.g. because ctx was cancelled)
 	errChan chan error
 	pipe    *io.PipeWriter
 }
@@ -51,14 +53,14 @@ func newMinioWriter(ctx context.Context, client *minioClient, name string) *mini
 	reader, writer := io.Pipe()
 	w := &minioWriter{
 		ctx:     ctx,
-		errChan: make(chan error),
+		errChan: make(chan error, 1),
 		pipe:    writer,
 	}
 	go func() {
 		opts := minio.PutObjectOptions{
 			ContentType: "application/octet-stream",
 		}
-		_, err := client.PutObject(client.bucket, name, reader, -1, opts)
+		_, err := client.PutObjectWithContext(ctx, client.bucket, name, reader, -1, opts)
 		if err != nil {
 			reader.CloseWithError(err)
 		}
@@ -91,12 +93,13 @@ func (c *minioClient) Writer(ctx context.Context, name string) (io.WriteCloser,
 	return newMinioWriter(ctx, c, name), nil
 }
 
-func (c *minioClient) Walk(_ context.Context, name string, fn func(name string) error) error {
+func (c *minioClient) Walk(ctx context.Context, name string, fn func(name string) error) error {
 	recursive := true // Recursively walk by default.
 
-	doneCh := make(chan struct{})
-	defer close(doneCh)
-	for objInfo := range c.ListObjectsV2(c.bucket, name, recursive, doneCh) {
+	// Listing stops when ctx is cancelled (by the caller, or when Walk returns)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for objInfo := range c.ListObjectsV2(c.bucket, name, recursive, ctx.Done()) {
 		if objInfo.Err != nil {
 			return objInfo.Err
 		}
@@ -104,7 +107,8 @@ func (c *minioClient) Walk(_ context.Context, name string, fn func(name string)
 			return err
 		}
 	}
-	return nil
+	// The listing may have been cut short because the caller's ctx is done
+	return ctx.Err()
 }
 
 // limitReadCloser implements a closer compatible wrapper
@@ -128,7 +132,7 @@ func (l *limitReadCloser) Read(p []byte) (retN int, retErr error) {
 }
 
 func (c *minioClient) Reader(ctx context.Context, name string, offset uint64, size uint64) (io.ReadCloser, error) {
-	obj, err := c.GetObject(c.bucket, name, minio.GetObjectOptions{})
+	obj, err := c.GetObjectWithContext(ctx, c.bucket, name, minio.GetObjectOptions{})
 	if err != nil {
 		return nil, err
 	}
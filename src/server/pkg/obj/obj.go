@@ -36,6 +36,7 @@ const (
 	Amazon    = "AMAZON"
 	Google    = "GOOGLE"
 	Microsoft = "MICROSOFT"
+	ADLS      = "ADLS"
 	Local     = "LOCAL"
 )
 
@@ -52,6 +53,15 @@ const (
 	MicrosoftSecretEnvVar    = "MICROSOFT_SECRET"
 )
 
+// ADLS (Azure Data Lake Storage Gen2) environment variables. ADLSIdentityEnvVar
+// is the client ID of the user-assigned managed identity to authenticate as,
+// if pachd's node has more than one.
+const (
+	ADLSFilesystemEnvVar = "ADLS_FILESYSTEM"
+	ADLSAccountEnvVar    = "ADLS_ACCOUNT"
+	ADLSIdentityEnvVar   = "ADLS_IDENTITY"
+)
+
 // Minio environment variables
 const (
 	MinioBucketEnvVar    = "MINIO_BUCKET"
@@ -136,6 +146,9 @@ var EnvVarToSecretKey = []struct {
 	{Key: MicrosoftContainerEnvVar, Value: "microsoft-container"},
 	{Key: MicrosoftIDEnvVar, Value: "microsoft-id"},
 	{Key: MicrosoftSecretEnvVar, Value: "microsoft-secret"},
+	{Key: ADLSFilesystemEnvVar, Value: "adls-filesystem"},
+	{Key: ADLSAccountEnvVar, Value: "adls-account"},
+	{Key: ADLSIdentityEnvVar, Value: "adls-identity"},
 	{Key: MinioBucketEnvVar, Value: "minio-bucket"},
 	{Key: MinioEndpointEnvVar, Value: "minio-endpoint"},
 	{Key: MinioIDEnvVar, Value: "minio-id"},
@@ -160,6 +173,8 @@ var EnvVarToSecretKey = []struct {
 	{Key: MaxUploadPartsEnvVar, Value: "max-upload-parts"},
 	{Key: DisableSSLEnvVar, Value: "disable-ssl"},
 	{Key: NoVerifySSLEnvVar, Value: "no-verify-ssl"},
+	{Key: EncryptionVaultAddrEnvVar, Value: "encryption-vault-addr"},
+	{Key: EncryptionVaultTokenEnvVar, Value: "encryption-vault-token"},
 }
 
 // StorageRootFromEnv gets the storage root based on environment variables.
@@ -322,6 +337,53 @@ func NewMicrosoftClientFromEnv() (Client, error) {
 	return NewMicrosoftClient(container, id, secret)
 }
 
+// NewADLSClient creates a client for Azure Data Lake Storage Gen2, which
+// authenticates with a managed identity:
+//	filesystem  - ADLS filesystem name
+//	accountName - Azure Storage Account name (the account must have a
+//	              hierarchical namespace)
+//	identity    - Client ID of a user-assigned managed identity, or "" to use
+//	              the node's only managed identity
+func NewADLSClient(filesystem string, accountName string, identity string) (Client, error) {
+	return newADLSClient(filesystem, accountName, identity)
+}
+
+// NewADLSClientFromSecret creates an ADLS client by reading its configuration
+// from a mounted ADLS secret. You may pass "" for filesystem in which case it
+// will read the filesystem from the secret.
+func NewADLSClientFromSecret(filesystem string) (Client, error) {
+	var err error
+	if filesystem == "" {
+		filesystem, err = readSecretFile("/adls-filesystem")
+		if err != nil {
+			return nil, fmt.Errorf("adls-filesystem not found")
+		}
+	}
+	account, err := readSecretFile("/adls-account")
+	if err != nil {
+		return nil, fmt.Errorf("adls-account not found")
+	}
+	identity, err := readSecretFile("/adls-identity")
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return NewADLSClient(filesystem, account, identity)
+}
+
+// NewADLSClientFromEnv creates an ADLS client based on environment variables.
+func NewADLSClientFromEnv() (Client, error) {
+	filesystem, ok := os.LookupEnv(ADLSFilesystemEnvVar)
+	if !ok {
+		return nil, fmt.Errorf("%s not found", ADLSFilesystemEnvVar)
+	}
+	account, ok := os.LookupEnv(ADLSAccountEnvVar)
+	if !ok {
+		return nil, fmt.Errorf("%s not found", ADLSAccountEnvVar)
+	}
+	identity, _ := os.LookupEnv(ADLSIdentityEnvVar)
+	return NewADLSClient(filesystem, account, identity)
+}
+
 // NewMinioClient creates an s3 compatible client with the following credentials:
 //   endpoint - S3 compatible endpoint
 //   bucket - S3 bucket name
@@ -518,6 +580,10 @@ func NewClientFromURLAndSecret(url *ObjectStoreURL, reverse ...bool) (c Client,
 	case "wasb":
 		// In Azure, the first part of the path is the container name.
 		c, err = NewMicrosoftClientFromSecret(url.Bucket)
+	case "abfs":
+		fallthrough
+	case "abfss":
+		c, err = NewADLSClientFromSecret(url.Bucket)
 	case "local":
 		c, err = NewLocalClient("/" + url.Bucket)
 	}
@@ -525,7 +591,7 @@ func NewClientFromURLAndSecret(url *ObjectStoreURL, reverse ...bool) (c Client,
 	case err != nil:
 		return nil, err
 	case c != nil:
-		return TracingObjClient(url.Store, c), nil
+		return PolicyObjClient(url.Store, TracingObjClient(url.Store, c))
 	default:
 		return nil, fmt.Errorf("unrecognized object store: %s", url.Bucket)
 	}
@@ -535,7 +601,8 @@ func NewClientFromURLAndSecret(url *ObjectStoreURL, reverse ...bool) (c Client,
 type ObjectStoreURL struct {
 	// The object store, e.g. s3, gcs, as...
 	Store string
-	// The "bucket" (in AWS parlance) or the "container" (in Azure parlance).
+	// The "bucket" (in AWS parlance), the "container" (in Azure parlance) or
+	// the "filesystem" (in ADLS parlance).
 	Bucket string
 	// The object itself.
 	Object string
@@ -565,6 +632,17 @@ func ParseURL(urlStr string) (*ObjectStoreURL, error) {
 			Bucket: parts[0],
 			Object: strings.Trim(path.Join(parts[1:]...), "/"),
 		}, nil
+	case "abfs", "abfss":
+		// In ADLS, the filesystem is the user part of the host, e.g.
+		// abfss://filesystem@account.dfs.core.windows.net/path
+		if url.User == nil || url.User.Username() == "" {
+			return nil, fmt.Errorf("malformed ADLS URI: %v", urlStr)
+		}
+		return &ObjectStoreURL{
+			Store:  url.Scheme,
+			Bucket: url.User.Username(),
+			Object: strings.Trim(url.Path, "/"),
+		}, nil
 	}
 	return nil, fmt.Errorf("unrecognized object store: %s", url.Scheme)
 }
@@ -582,6 +660,8 @@ func NewClientFromEnv(storageRoot string) (c Client, err error) {
 		c, err = NewGoogleClientFromEnv()
 	case Microsoft:
 		c, err = NewMicrosoftClientFromEnv()
+	case ADLS:
+		c, err = NewADLSClientFromEnv()
 	case Minio:
 		c, err = NewMinioClientFromEnv()
 	case Local:
@@ -591,7 +671,10 @@ func NewClientFromEnv(storageRoot string) (c Client, err error) {
 	case err != nil:
 		return nil, err
 	case c != nil:
-		return TracingObjClient(storageBackend, c), nil
+		if c, err = PolicyObjClient(storageBackend, TracingObjClient(storageBackend, c)); err != nil {
+			return nil, err
+		}
+		return EncryptedObjClient(c)
 	default:
 		return nil, fmt.Errorf("unrecognized storage backend: %s", storageBackend)
 	}
@@ -610,6 +693,8 @@ func NewClientFromSecret(storageRoot string) (c Client, err error) {
 		c, err = NewGoogleClientFromSecret("")
 	case Microsoft:
 		c, err = NewMicrosoftClientFromSecret("")
+	case ADLS:
+		c, err = NewADLSClientFromSecret("")
 	case Minio:
 		c, err = NewMinioClientFromSecret("")
 	case Local:
@@ -619,7 +704,10 @@ func NewClientFromSecret(storageRoot string) (c Client, err error) {
 	case err != nil:
 		return nil, err
 	case c != nil:
-		return TracingObjClient(storageBackend, c), nil
+		if c, err = PolicyObjClient(storageBackend, TracingObjClient(storageBackend, c)); err != nil {
+			return nil, err
+		}
+		return EncryptedObjClient(c)
 	default:
 		return nil, fmt.Errorf("unrecognized storage backend: %s", storageBackend)
 	}
@@ -675,7 +763,7 @@ func (b *BackoffReadCloser) Read(data []byte) (retN int, retErr error) {
 			return err
 		}
 		return nil
-	}, b.backoffConfig, func(err error, d time.Duration) error {
+	}, backoff.WithContext(b.backoffConfig, b.ctx), func(err error, d time.Duration) error {
 		log.Infof("Error reading; retrying in %s: %#v", d, RetryError{
 			Err:               err.Error(),
 			TimeTillNextRetry: d.String(),
@@ -727,7 +815,7 @@ func (b *BackoffWriteCloser) Write(data []byte) (retN int, retErr error) {
 			return err
 		}
 		return nil
-	}, b.backoffConfig, func(err error, d time.Duration) error {
+	}, backoff.WithContext(b.backoffConfig, b.ctx), func(err error, d time.Duration) error {
 		log.Infof("Error writing; retrying in %s: %#v", d, RetryError{
 			Err:               err.Error(),
 			TimeTillNextRetry: d.String(),
@@ -0,0 +1,460 @@
+package obj
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+
+	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+)
+
+// Object storage policy environment variables (see ClientPolicy). They apply
+// to every backend.
+const (
+	MaxAttemptsEnvVar             = "STORAGE_MAX_ATTEMPTS"
+	BackoffInitialEnvVar          = "STORAGE_BACKOFF_INITIAL"
+	BackoffMaxEnvVar              = "STORAGE_BACKOFF_MAX"
+	MaxConcurrencyEnvVar          = "STORAGE_MAX_CONCURRENCY"
+	RateLimitEnvVar               = "STORAGE_RATE_LIMIT"
+	CircuitBreakerThresholdEnvVar = "STORAGE_CIRCUIT_BREAKER_THRESHOLD"
+	CircuitBreakerCooldownEnvVar  = "STORAGE_CIRCUIT_BREAKER_COOLDOWN"
+)
+
+const (
+	// DefaultMaxAttempts is the default number of times an object storage
+	// request is attempted, if it fails with a retryable error.
+	DefaultMaxAttempts = 5
+	// DefaultBackoffInitial is the default time to wait before retrying an
+	// object storage request the first time.
+	DefaultBackoffInitial = time.Second
+	// DefaultBackoffMax is the default maximum time to wait before retrying an
+	// object storage request.
+	DefaultBackoffMax = 30 * time.Second
+	// DefaultCircuitBreakerCooldown is the default time that requests to an
+	// object storage backend are rejected for once its circuit breaker opens.
+	DefaultCircuitBreakerCooldown = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned by object storage requests that are rejected
+// because their backend's circuit breaker is open (see ClientPolicy).
+var ErrCircuitOpen = fmt.Errorf("object storage circuit breaker is open, too many requests failed")
+
+// ClientPolicy controls how the requests of every object storage client for a
+// backend are retried and throttled. Requests are Writer, Reader, Delete, Walk
+// and Exists calls; the readers and writers they return are retried by the
+// backends themselves (see BackoffReadCloser).
+type ClientPolicy struct {
+	// MaxAttempts is how many times a request is attempted if it fails with a
+	// retryable error (see IsRetryable), where 0 and 1 disable retries.
+	// Retries wait between BackoffInitial and BackoffMax, increasing
+	// exponentially.
+	MaxAttempts    int
+	BackoffInitial time.Duration
+	BackoffMax     time.Duration
+	// MaxConcurrency, if nonzero, is the maximum number of requests to the
+	// backend that can run at once. Readers and writers count as running
+	// until they're closed.
+	MaxConcurrency int
+	// RateLimit, if nonzero, is the maximum number of requests to the backend
+	// per second.
+	RateLimit float64
+	// CircuitBreakerThreshold, if nonzero, is how many attempts in a row can
+	// fail with retryable errors before the backend's circuit breaker opens.
+	// While it's open, requests are rejected with ErrCircuitOpen, until
+	// CircuitBreakerCooldown has passed and a request succeeds again.
+	CircuitBreakerThreshold int
+	CircuitBreakerCooldown  time.Duration
+}
+
+// PolicyFromEnv reads the object storage policy from environment variables,
+// using the defaults for any that aren't set.
+func PolicyFromEnv() (*ClientPolicy, error) {
+	policy := &ClientPolicy{}
+	var err error
+	if policy.MaxAttempts, err = intFromEnv(MaxAttemptsEnvVar, DefaultMaxAttempts); err != nil {
+		return nil, err
+	}
+	if policy.BackoffInitial, err = durationFromEnv(BackoffInitialEnvVar, DefaultBackoffInitial); err != nil {
+		return nil, err
+	}
+	if policy.BackoffMax, err = durationFromEnv(BackoffMaxEnvVar, DefaultBackoffMax); err != nil {
+		return nil, err
+	}
+	if policy.MaxConcurrency, err = intFromEnv(MaxConcurrencyEnvVar, 0); err != nil {
+		return nil, err
+	}
+	if rateLimit, ok := os.LookupEnv(RateLimitEnvVar); ok && rateLimit != "" {
+		if policy.RateLimit, err = strconv.ParseFloat(rateLimit, 64); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", RateLimitEnvVar, rateLimit, err)
+		}
+	}
+	if policy.CircuitBreakerThreshold, err = intFromEnv(CircuitBreakerThresholdEnvVar, 0); err != nil {
+		return nil, err
+	}
+	if policy.CircuitBreakerCooldown, err = durationFromEnv(CircuitBreakerCooldownEnvVar, DefaultCircuitBreakerCooldown); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func intFromEnv(name string, defaultValue int) (int, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return defaultValue, nil
+	}
+	result, err := strconv.Atoi(value)
+	if err != nil || result < 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a non-negative integer", name, value)
+	}
+	return result, nil
+}
+
+func durationFromEnv(name string, defaultValue time.Duration) (time.Duration, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return defaultValue, nil
+	}
+	result, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %v", name, value, err)
+	}
+	return result, nil
+}
+
+var (
+	throttleCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pachyderm",
+			Subsystem: "obj",
+			Name:      "throttle_count",
+			Help:      "Number of object storage requests that were delayed or rejected, by provider and reason (concurrency|rate|circuit_open)",
+		},
+		[]string{
+			"provider",
+			"reason",
+		},
+	)
+	retryCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pachyderm",
+			Subsystem: "obj",
+			Name:      "retry_count",
+			Help:      "Number of object storage requests that were retried, by provider and operation",
+		},
+		[]string{
+			"provider",
+			"operation",
+		},
+	)
+	registerPolicyMetrics sync.Once
+)
+
+// backendState is the state shared by every client for a backend, so that the
+// backend's limits apply across all of a process's clients
+type backendState struct {
+	policy  *ClientPolicy
+	slots   chan struct{} // nil if concurrency is unlimited
+	limiter *rate.Limiter // nil if the rate is unlimited
+	breaker *circuitBreaker
+}
+
+var (
+	backendsMu sync.Mutex
+	backends   = make(map[string]*backendState)
+)
+
+// getBackendState returns the state of the backend 'provider', creating it
+// with 'policy' if this is the process's first client for the backend
+func getBackendState(provider string, policy *ClientPolicy) *backendState {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	if state, ok := backends[provider]; ok {
+		return state
+	}
+	state := newBackendState(policy)
+	backends[provider] = state
+	return state
+}
+
+func newBackendState(policy *ClientPolicy) *backendState {
+	state := &backendState{
+		policy:  policy,
+		breaker: &circuitBreaker{threshold: policy.CircuitBreakerThreshold, cooldown: policy.CircuitBreakerCooldown},
+	}
+	if policy.MaxConcurrency > 0 {
+		state.slots = make(chan struct{}, policy.MaxConcurrency)
+	}
+	if policy.RateLimit > 0 {
+		// Allow a second's worth of requests at once
+		burst := int(policy.RateLimit)
+		if burst < 1 {
+			burst = 1
+		}
+		state.limiter = rate.NewLimiter(rate.Limit(policy.RateLimit), burst)
+	}
+	return state
+}
+
+// PolicyObjClient wraps the given object client 'c', retrying and throttling
+// its requests according to the object storage policy in the environment
+// (see ClientPolicy)
+func PolicyObjClient(provider string, c Client) (Client, error) {
+	policy, err := PolicyFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	registerPolicyMetrics.Do(func() {
+		for _, metric := range []prometheus.Collector{throttleCount, retryCount} {
+			if err := prometheus.Register(metric); err != nil {
+				// metrics may be redundantly registered; ignore these errors
+				if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+					log.Errorf("error registering prometheus metric: %v", err)
+				}
+			}
+		}
+	})
+	provider = prettyProvider(provider)
+	return &policyObjClient{
+		Client:   c,
+		provider: provider,
+		state:    getBackendState(provider, policy),
+	}, nil
+}
+
+type policyObjClient struct {
+	Client
+	provider string
+	state    *backendState
+}
+
+// acquire waits until a request to the backend can run, and returns a
+// function that must be called once it's done
+func (o *policyObjClient) acquire(ctx context.Context) (func(), error) {
+	if !o.state.breaker.allow() {
+		throttleCount.WithLabelValues(o.provider, "circuit_open").Inc()
+		return nil, ErrCircuitOpen
+	}
+	if o.state.limiter != nil && !o.state.limiter.Allow() {
+		throttleCount.WithLabelValues(o.provider, "rate").Inc()
+		if err := o.state.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if o.state.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case o.state.slots <- struct{}{}:
+	default:
+		throttleCount.WithLabelValues(o.provider, "concurrency").Inc()
+		select {
+		case o.state.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-o.state.slots }) }, nil
+}
+
+// do runs the request 'operation', retrying it if it fails with a retryable
+// error. 'operation' is passed a function that it must call once the
+// resources it holds (e.g. a reader) are released, which may be after it
+// returns.
+func (o *policyObjClient) do(ctx context.Context, name string, operation func(release func()) error) error {
+	config := backoff.NewExponentialBackOff()
+	config.InitialInterval = o.state.policy.BackoffInitial
+	config.MaxInterval = o.state.policy.BackoffMax
+	config.Multiplier = 2
+	config.MaxElapsedTime = 0
+	attempts := 0
+	return backoff.RetryNotify(func() error {
+		attempts++
+		release, err := o.acquire(ctx)
+		if err != nil {
+			return err
+		}
+		err = operation(release)
+		if err != nil {
+			release()
+		}
+		o.state.breaker.record(err == nil || !o.isRetryable(err))
+		return err
+	}, backoff.WithContext(config, ctx), func(err error, d time.Duration) error {
+		if attempts >= o.state.policy.MaxAttempts || !o.isRetryable(err) {
+			return err
+		}
+		retryCount.WithLabelValues(o.provider, name).Inc()
+		log.Infof("Error in object storage %s; retrying in %s: %#v", name, d, RetryError{
+			Err:               err.Error(),
+			TimeTillNextRetry: d.String(),
+		})
+		return nil
+	})
+}
+
+func (o *policyObjClient) isRetryable(err error) bool {
+	if _, ok := err.(*nonRetryableError); ok || err == ErrCircuitOpen {
+		return false
+	}
+	return IsRetryable(o.Client, err)
+}
+
+// Writer implements the corresponding method in the Client interface
+func (o *policyObjClient) Writer(ctx context.Context, name string) (io.WriteCloser, error) {
+	var result io.WriteCloser
+	if err := o.do(ctx, "Writer", func(release func()) error {
+		w, err := o.Client.Writer(ctx, name)
+		if err != nil {
+			return err
+		}
+		result = &releaseWriteCloser{WriteCloser: w, release: release}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Reader implements the corresponding method in the Client interface
+func (o *policyObjClient) Reader(ctx context.Context, name string, offset uint64, size uint64) (io.ReadCloser, error) {
+	var result io.ReadCloser
+	if err := o.do(ctx, "Reader", func(release func()) error {
+		r, err := o.Client.Reader(ctx, name, offset, size)
+		if err != nil {
+			return err
+		}
+		result = &releaseReadCloser{ReadCloser: r, release: release}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Delete implements the corresponding method in the Client interface
+func (o *policyObjClient) Delete(ctx context.Context, name string) error {
+	return o.do(ctx, "Delete", func(release func()) error {
+		defer release()
+		return o.Client.Delete(ctx, name)
+	})
+}
+
+// Walk implements the corresponding method in the Client interface. It's
+// only retried if it fails before 'fn' is called, so that 'fn' isn't called
+// twice for the same object.
+func (o *policyObjClient) Walk(ctx context.Context, prefix string, fn func(name string) error) error {
+	var called bool
+	var fnErr error
+	err := o.do(ctx, "Walk", func(release func()) error {
+		defer release()
+		err := o.Client.Walk(ctx, prefix, func(name string) error {
+			called = true
+			if err := fn(name); err != nil {
+				fnErr = err
+				return err
+			}
+			return nil
+		})
+		if err != nil && (called || err == fnErr) {
+			return &nonRetryableError{err}
+		}
+		return err
+	})
+	if nrErr, ok := err.(*nonRetryableError); ok {
+		return nrErr.err
+	}
+	return err
+}
+
+// Exists implements the corresponding method in the Client interface. It
+// isn't retried, since it doesn't return errors, but it's throttled, and it
+// returns false while the backend's circuit breaker is open.
+func (o *policyObjClient) Exists(ctx context.Context, name string) bool {
+	release, err := o.acquire(ctx)
+	if err != nil {
+		return false
+	}
+	defer release()
+	return o.Client.Exists(ctx, name)
+}
+
+// nonRetryableError wraps an error that do shouldn't retry, and that
+// shouldn't count against the backend's circuit breaker
+type nonRetryableError struct {
+	err error
+}
+
+func (e *nonRetryableError) Error() string {
+	return e.err.Error()
+}
+
+type releaseReadCloser struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseReadCloser) Close() error {
+	defer r.release()
+	return r.ReadCloser.Close()
+}
+
+type releaseWriteCloser struct {
+	io.WriteCloser
+	release func()
+}
+
+func (w *releaseWriteCloser) Close() error {
+	defer w.release()
+	return w.WriteCloser.Close()
+}
+
+// circuitBreaker rejects requests to a backend for a while once too many have
+// failed in a row
+type circuitBreaker struct {
+	threshold int // 0 disables the breaker
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// allow returns false if the breaker is open
+func (b *circuitBreaker) allow() bool {
+	if b.threshold == 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !time.Now().Before(b.openUntil)
+}
+
+// record records whether an attempt succeeded (or failed with an error that
+// doesn't indicate a problem with the backend), and opens the breaker if too
+// many attempts in a row have failed
+func (b *circuitBreaker) record(ok bool) {
+	if b.threshold == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ok {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		// After the cooldown, a single failure reopens the breaker
+		b.failures = b.threshold - 1
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
@@ -0,0 +1,102 @@
+package obj
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+// flakyClient is a Client whose Reader and Delete fail with a retryable error
+// the first 'failures' times they're called
+type flakyClient struct {
+	Client
+	failures int
+	calls    int
+}
+
+var errFlaky = fmt.Errorf("503 service unavailable")
+
+func (c *flakyClient) Reader(ctx context.Context, name string, offset uint64, size uint64) (io.ReadCloser, error) {
+	c.calls++
+	if c.calls <= c.failures {
+		return nil, errFlaky
+	}
+	return ioutil.NopCloser(strings.NewReader(name)), nil
+}
+
+func (c *flakyClient) Delete(ctx context.Context, name string) error {
+	c.calls++
+	if c.calls <= c.failures {
+		return errFlaky
+	}
+	return nil
+}
+
+func (c *flakyClient) IsRetryable(err error) bool {
+	return err == errFlaky
+}
+
+func newTestPolicyClient(c Client, policy *ClientPolicy) *policyObjClient {
+	policy.BackoffInitial = time.Millisecond
+	policy.BackoffMax = time.Millisecond
+	return &policyObjClient{Client: c, provider: "test", state: newBackendState(policy)}
+}
+
+func TestPolicyRetries(t *testing.T) {
+	ctx := context.Background()
+	c := &flakyClient{failures: 2}
+	r, err := newTestPolicyClient(c, &ClientPolicy{MaxAttempts: 3}).Reader(ctx, "obj", 0, 0)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "obj", string(data))
+	require.NoError(t, r.Close())
+	require.Equal(t, 3, c.calls)
+
+	c = &flakyClient{failures: 3}
+	require.Equal(t, errFlaky, newTestPolicyClient(c, &ClientPolicy{MaxAttempts: 3}).Delete(ctx, "obj"))
+	require.Equal(t, 3, c.calls)
+	// Retries can be disabled
+	c = &flakyClient{failures: 1}
+	require.Equal(t, errFlaky, newTestPolicyClient(c, &ClientPolicy{MaxAttempts: 1}).Delete(ctx, "obj"))
+	require.Equal(t, 1, c.calls)
+}
+
+func TestPolicyConcurrency(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	c := newTestPolicyClient(&flakyClient{}, &ClientPolicy{MaxConcurrency: 1})
+	r, err := c.Reader(ctx, "obj", 0, 0)
+	require.NoError(t, err)
+	// The open reader holds the only slot until it's closed
+	_, err = c.Reader(ctx, "obj", 0, 0)
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.NoError(t, r.Close())
+	require.NoError(t, c.Delete(context.Background(), "obj"))
+}
+
+func TestPolicyCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	c := &flakyClient{failures: 2}
+	pc := newTestPolicyClient(c, &ClientPolicy{
+		MaxAttempts:             1,
+		CircuitBreakerThreshold: 2,
+		CircuitBreakerCooldown:  time.Hour,
+	})
+	require.Equal(t, errFlaky, pc.Delete(ctx, "obj"))
+	require.Equal(t, errFlaky, pc.Delete(ctx, "obj"))
+	// The breaker is open, so the backend isn't called, even though it would
+	// succeed now
+	require.Equal(t, ErrCircuitOpen, pc.Delete(ctx, "obj"))
+	require.Equal(t, 2, c.calls)
+	// Once the cooldown has passed, a successful request closes the breaker
+	pc.state.breaker.openUntil = time.Now()
+	require.NoError(t, pc.Delete(ctx, "obj"))
+	require.NoError(t, pc.Delete(ctx, "obj"))
+}
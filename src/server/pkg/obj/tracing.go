@@ -16,6 +16,8 @@ func prettyProvider(provider string) string {
 		return "Google"
 	case "as", "wasb", Microsoft:
 		return "Microsoft"
+	case "abfs", "abfss", ADLS:
+		return "ADLS"
 	case "local", Local:
 		return "Local"
 	case Minio:
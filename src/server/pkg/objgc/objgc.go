@@ -0,0 +1,233 @@
+// Package objgc implements the protocol that lets PFS's objects and tags be
+// garbage collected while they're being written.
+//
+// GC's mark phase finds the objects and tags that are referenced by commits,
+// open commits' files and datum tags, but there's a window between an object
+// being written and it being referenced, and a write may be deduplicated onto
+// an unreferenced object that GC is about to delete. To close both gaps:
+//
+//  1. Writers call Barrier.Touch on the objects and tags that they write (or
+//     would write, if they already exist), which records when they were
+//     touched for at least YoungTTL, and then check whether they exist.
+//  2. GC calls Collector.Condemn on the unreferenced objects and tags before
+//     deleting them, which atomically skips the ones that were touched within
+//     its grace period (which is at most YoungTTL), and calls
+//     Collector.Release once they're deleted. Touch waits for condemned keys to
+//     be released, so a writer never deduplicates onto an object that's being
+//     deleted.
+package objgc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	etcd "github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/clientv3/concurrency"
+
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+)
+
+const (
+	// youngPrefix is the etcd prefix of the keys of young objects and tags
+	youngPrefix = "gc-young/"
+	// condemnedPrefix is the etcd prefix of the keys of the objects and tags
+	// that GC is deleting
+	condemnedPrefix = "gc-condemned/"
+	// collectorTTL is the TTL (in seconds) of a Collector's lease, after which
+	// the keys that it has condemned are released if it dies
+	collectorTTL = 60
+)
+
+// YoungTTL is the minimum amount of time for which an object or tag's touch
+// time is kept, and so the maximum grace period that GC can give writers to
+// reference the objects and tags that they write
+const YoungTTL = time.Hour
+
+// ObjectKey returns the key that identifies 'object' to Touch and Condemn
+func ObjectKey(object *pfs.Object) string {
+	return "object/" + object.Hash
+}
+
+// TagKey returns the key that identifies 'tag' to Touch and Condemn
+func TagKey(tag *pfs.Tag) string {
+	return "tag/" + tag.Name
+}
+
+// Barrier marks the objects and tags written by a process young
+type Barrier struct {
+	etcdClient *etcd.Client
+
+	// lease is the lease that young keys are attached to. A new lease is
+	// granted every YoungTTL, and each one lasts for twice that, so keys live
+	// for between YoungTTL and 2*YoungTTL.
+	lease        etcd.LeaseID
+	leaseGranted time.Time
+	leaseMu      sync.Mutex
+}
+
+// NewBarrier returns a Barrier that writes to 'etcdClient'
+func NewBarrier(etcdClient *etcd.Client) *Barrier {
+	return &Barrier{etcdClient: etcdClient}
+}
+
+// Touch records that the objects and tags with the keys 'keys' were touched
+// now, and then waits until GC has finished deleting any of them that it has
+// condemned. Once Touch returns, GC won't delete them within its grace period,
+// but they may have been deleted before it was called, so callers must check
+// that they exist afterwards.
+func (b *Barrier) Touch(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	lease, err := b.getLease(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	var ops []etcd.Op
+	for _, key := range keys {
+		ops = append(ops, etcd.OpPut(youngPrefix+key, now, etcd.WithLease(lease)))
+	}
+	if _, err := b.etcdClient.Txn(ctx).Then(ops...).Commit(); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := waitForRelease(ctx, b.etcdClient, condemnedPrefix+key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Barrier) getLease(ctx context.Context) (etcd.LeaseID, error) {
+	b.leaseMu.Lock()
+	defer b.leaseMu.Unlock()
+	if b.lease == 0 || time.Since(b.leaseGranted) > YoungTTL {
+		resp, err := b.etcdClient.Grant(ctx, int64(2*YoungTTL/time.Second))
+		if err != nil {
+			return 0, err
+		}
+		b.lease, b.leaseGranted = resp.ID, time.Now()
+	}
+	return b.lease, nil
+}
+
+// waitForRelease returns once 'key' doesn't exist
+func waitForRelease(ctx context.Context, etcdClient *etcd.Client, key string) error {
+	resp, err := etcdClient.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for watchResp := range etcdClient.Watch(ctx, key, etcd.WithRev(resp.Header.Revision+1)) {
+		if err := watchResp.Err(); err != nil {
+			return err
+		}
+		for _, ev := range watchResp.Events {
+			if ev.Type == etcd.EventTypeDelete {
+				return nil
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// Collector condemns the objects and tags that a GC run deletes. The keys it
+// condemns are attached to its session's lease, so writers don't wait for
+// them forever if the GC run dies.
+type Collector struct {
+	etcdClient *etcd.Client
+	session    *concurrency.Session
+}
+
+// NewCollector returns a Collector that writes to 'etcdClient'
+func NewCollector(ctx context.Context, etcdClient *etcd.Client) (*Collector, error) {
+	session, err := concurrency.NewSession(etcdClient, concurrency.WithContext(ctx), concurrency.WithTTL(collectorTTL))
+	if err != nil {
+		return nil, err
+	}
+	return &Collector{
+		etcdClient: etcdClient,
+		session:    session,
+	}, nil
+}
+
+// Condemn condemns the objects and tags with the keys 'keys' that weren't
+// touched within 'gracePeriod', and returns the keys that it condemned. The
+// caller may delete them, and must then release them.
+func (c *Collector) Condemn(ctx context.Context, keys []string, gracePeriod time.Duration) ([]string, error) {
+	var result []string
+	for _, key := range keys {
+		condemned, err := c.condemn(ctx, key, gracePeriod)
+		if err != nil {
+			return nil, err
+		}
+		if condemned {
+			result = append(result, key)
+		}
+	}
+	return result, nil
+}
+
+func (c *Collector) condemn(ctx context.Context, key string, gracePeriod time.Duration) (bool, error) {
+	young := youngPrefix + key
+	condemn := etcd.OpPut(condemnedPrefix+key, "", etcd.WithLease(c.session.Lease()))
+	for {
+		resp, err := c.etcdClient.Txn(ctx).
+			If(etcd.Compare(etcd.CreateRevision(young), "=", 0)).
+			Then(condemn).
+			Else(etcd.OpGet(young)).
+			Commit()
+		if err != nil {
+			return false, err
+		}
+		if resp.Succeeded {
+			return true, nil
+		}
+		kvs := resp.Responses[0].GetResponseRange().Kvs
+		if len(kvs) == 0 {
+			continue // the touch time expired after the comparison
+		}
+		touched, err := time.Parse(time.RFC3339Nano, string(kvs[0].Value))
+		if err != nil {
+			return false, err
+		}
+		if time.Since(touched) < gracePeriod {
+			return false, nil
+		}
+		// Only condemn the key if it hasn't been touched again since its touch
+		// time was read
+		resp, err = c.etcdClient.Txn(ctx).
+			If(etcd.Compare(etcd.ModRevision(young), "=", kvs[0].ModRevision)).
+			Then(condemn).
+			Commit()
+		if err != nil {
+			return false, err
+		}
+		return resp.Succeeded, nil
+	}
+}
+
+// Release releases the condemned keys 'keys', which lets writers that are
+// waiting for them proceed
+func (c *Collector) Release(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	var ops []etcd.Op
+	for _, key := range keys {
+		ops = append(ops, etcd.OpDelete(condemnedPrefix+key))
+	}
+	_, err := c.etcdClient.Txn(ctx).Then(ops...).Commit()
+	return err
+}
+
+// Close ends the Collector's session, which releases any keys it hasn't
+func (c *Collector) Close() error {
+	return c.session.Close()
+}
@@ -0,0 +1,68 @@
+package objgc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/server/pkg/objgc"
+	"github.com/pachyderm/pachyderm/src/server/pkg/testutil"
+)
+
+func TestCondemnSparesYoungKeys(t *testing.T) {
+	require.NoError(t, testutil.WithEtcdEnv(func(env *testutil.EtcdEnv) error {
+		barrier := objgc.NewBarrier(env.EtcdClient)
+		collector, err := objgc.NewCollector(env.Context, env.EtcdClient)
+		require.NoError(t, err)
+		defer collector.Close()
+
+		young, old := objgc.ObjectKey(client.NewObject("young")), objgc.ObjectKey(client.NewObject("old"))
+		require.NoError(t, barrier.Touch(env.Context, young))
+		condemned, err := collector.Condemn(env.Context, []string{young, old}, objgc.YoungTTL)
+		require.NoError(t, err)
+		require.Equal(t, []string{old}, condemned)
+
+		// Keys that were touched before the grace period are condemned
+		time.Sleep(100 * time.Millisecond)
+		condemned, err = collector.Condemn(env.Context, []string{young}, 50*time.Millisecond)
+		require.NoError(t, err)
+		require.Equal(t, []string{young}, condemned)
+		return nil
+	}))
+}
+
+func TestTouchWaitsForRelease(t *testing.T) {
+	require.NoError(t, testutil.WithEtcdEnv(func(env *testutil.EtcdEnv) error {
+		barrier := objgc.NewBarrier(env.EtcdClient)
+		collector, err := objgc.NewCollector(env.Context, env.EtcdClient)
+		require.NoError(t, err)
+		defer collector.Close()
+
+		key := objgc.TagKey(client.NewTag("tag"))
+		condemned, err := collector.Condemn(env.Context, []string{key}, objgc.YoungTTL)
+		require.NoError(t, err)
+		require.Equal(t, []string{key}, condemned)
+
+		touched := make(chan error)
+		go func() { touched <- barrier.Touch(env.Context, key) }()
+		select {
+		case err := <-touched:
+			t.Fatalf("Touch returned before the key was released: %v", err)
+		case <-time.After(time.Second):
+		}
+		require.NoError(t, collector.Release(env.Context, condemned))
+		select {
+		case err := <-touched:
+			require.NoError(t, err)
+		case <-time.After(10 * time.Second):
+			t.Fatal("Touch didn't return after the key was released")
+		}
+
+		// The key has now been touched, so it can't be condemned again
+		condemned, err = collector.Condemn(env.Context, []string{key}, objgc.YoungTTL)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(condemned))
+		return nil
+	}))
+}
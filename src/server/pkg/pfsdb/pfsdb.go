@@ -19,6 +19,9 @@ const (
 	openCommitsPrefix    = "/openCommits"
 	mergesPrefix         = "/merges"
 	shardsPrefix         = "/shards"
+	uploadsPrefix        = "/uploads"
+	uploadRecordsPrefix  = "/uploadRecords"
+	modelsPrefix         = "/models"
 )
 
 var (
@@ -90,3 +93,40 @@ func OpenCommits(etcdClient *etcd.Client, etcdPrefix string) col.Collection {
 		nil,
 	)
 }
+
+// Uploads returns a collection of upload sessions
+func Uploads(etcdClient *etcd.Client, etcdPrefix string) col.Collection {
+	return col.NewCollection(
+		etcdClient,
+		path.Join(etcdPrefix, uploadsPrefix),
+		nil,
+		&pfs.UploadInfo{},
+		nil,
+		nil,
+	)
+}
+
+// Models returns a collection of repos' model registries, keyed by repo
+func Models(etcdClient *etcd.Client, etcdPrefix string) col.Collection {
+	return col.NewCollection(
+		etcdClient,
+		path.Join(etcdPrefix, modelsPrefix),
+		nil,
+		&pfs.ModelInfo{},
+		nil,
+		nil,
+	)
+}
+
+// UploadRecords returns a collection of the putFileRecords of upload
+// sessions, which have the same keys as the sessions in Uploads
+func UploadRecords(etcdClient *etcd.Client, etcdPrefix string) col.Collection {
+	return col.NewCollection(
+		etcdClient,
+		path.Join(etcdPrefix, uploadRecordsPrefix),
+		nil,
+		&pfs.PutFileRecords{},
+		nil,
+		nil,
+	)
+}
@@ -21,4 +21,18 @@ const (
 
 	// SpoutMarkerBranch is the branch that spouts use for keeping track of spout marker files
 	SpoutMarkerBranch = "marker"
+
+	// MasterLockPath is the path (under PPS's etcd prefix) of the lock held by
+	// the pachd that runs the PPS master
+	MasterLockPath = "_master_lock"
+
+	// WorkerMasterLockPath is the path (under PPS's etcd prefix) of the locks
+	// held by the workers that run pipelines' masters. Each pipeline's lock is
+	// at WorkerMasterLockPath/<pipeline>/<salt>.
+	WorkerMasterLockPath = "_master_worker_lock"
+
+	// ReplayMetadataKey is the commit metadata key that marks the output
+	// commits of job replays (see pps.ReplayJob). Its value is the ID of the
+	// job being replayed.
+	ReplayMetadataKey = "pps.replay_of"
 )
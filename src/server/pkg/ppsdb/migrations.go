@@ -0,0 +1,195 @@
+package ppsdb
+
+import (
+	"context"
+	"fmt"
+
+	etcd "github.com/coreos/etcd/clientv3"
+	"github.com/gogo/protobuf/proto"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+)
+
+// SchemaVersion is the version of the schema of the EtcdPipelineInfos and
+// EtcdJobInfos that this version of pachd writes. Every version after 0 has a
+// migration in 'migrations'.
+const SchemaVersion = 2
+
+// Migration upgrades PPS's etcd records from schema version Version-1 to
+// Version (the Up functions), and downgrades them back (the Down functions).
+// A nil function leaves that type of record unchanged, other than its schema
+// version.
+type Migration struct {
+	Version      uint64
+	Description  string
+	UpPipeline   func(*pps.EtcdPipelineInfo) error
+	DownPipeline func(*pps.EtcdPipelineInfo) error
+	UpJob        func(*pps.EtcdJobInfo) error
+	DownJob      func(*pps.EtcdJobInfo) error
+}
+
+// migrations is the registry of schema migrations, in order. To change the
+// schema, append a migration here and increment SchemaVersion. Migrations
+// must be safe to run on records that pachd is also updating, as each record
+// is migrated in its own transaction.
+var migrations = []*Migration{
+	{
+		Version:     1,
+		Description: "add schema versions to EtcdPipelineInfo and EtcdJobInfo",
+	},
+	{
+		// Rewriting each job also adds it to JobsStateIndex. Older versions of
+		// pachd don't maintain either index, so their entries may go stale
+		// after a rollback, which is why ListJob re-checks the jobs that it
+		// reads from them.
+		Version:     2,
+		Description: "index jobs by state and start time",
+		UpJob: func(jobPtr *pps.EtcdJobInfo) error {
+			jobPtr.StartedIndex = StartedIndexValue(jobPtr.Started)
+			return nil
+		},
+	},
+}
+
+// MigrateOptions configures Migrate
+type MigrateOptions struct {
+	// Target is the schema version to migrate records to. It's SchemaVersion,
+	// except when rolling records back for an older pachd.
+	Target uint64
+	// DryRun makes Migrate log the records it would migrate without changing
+	// them
+	DryRun bool
+}
+
+// Migrate migrates every record in 'pipelines' and 'jobs' (as returned by
+// Pipelines and Jobs) to the schema version opts.Target, and returns the
+// number of records that it migrated (or, in a dry run, would have).
+func Migrate(ctx context.Context, etcdClient *etcd.Client, pipelines, jobs col.Collection, opts MigrateOptions) (int, error) {
+	return migrate(ctx, etcdClient, pipelines, jobs, migrations, opts)
+}
+
+func migrate(ctx context.Context, etcdClient *etcd.Client, pipelines, jobs col.Collection, migrations []*Migration, opts MigrateOptions) (int, error) {
+	for i, m := range migrations {
+		if m.Version != uint64(i+1) {
+			return 0, fmt.Errorf("migration %d (%q) has version %d; migrations must be in order", i, m.Description, m.Version)
+		}
+	}
+	latest := uint64(len(migrations))
+	if opts.Target > latest {
+		return 0, fmt.Errorf("cannot migrate to schema version %d, as the latest version is %d", opts.Target, latest)
+	}
+	// steps returns the migrations from schema version 'from' to opts.Target,
+	// in the order that they're applied
+	steps := func(from uint64) ([]*Migration, bool, error) {
+		if from > latest {
+			return nil, false, fmt.Errorf("record has schema version %d, but this version of pachd "+
+				"only knows versions up to %d (roll the record back with the pachd version that wrote it)", from, latest)
+		}
+		if from <= opts.Target {
+			return migrations[from:opts.Target], true, nil
+		}
+		var result []*Migration
+		for v := from; v > opts.Target; v-- {
+			result = append(result, migrations[v-1])
+		}
+		return result, false, nil
+	}
+
+	// migrateRecord applies the migrations from schema version 'from' to
+	// opts.Target to a record, by calling 'apply' with each one
+	migrateRecord := func(from uint64, apply func(m *Migration, up bool) error) (bool, error) {
+		if from == opts.Target {
+			return false, nil
+		}
+		ms, up, err := steps(from)
+		if err != nil {
+			return false, err
+		}
+		for _, m := range ms {
+			if err := apply(m, up); err != nil {
+				return false, fmt.Errorf("error applying migration %d (%q): %v", m.Version, m.Description, err)
+			}
+		}
+		return true, nil
+	}
+
+	pipelinesMigrated, err := migrateCollection(ctx, etcdClient, pipelines, &pps.EtcdPipelineInfo{}, opts, func(val proto.Message) (bool, error) {
+		ptr := val.(*pps.EtcdPipelineInfo)
+		changed, err := migrateRecord(ptr.SchemaVersion, func(m *Migration, up bool) error {
+			f := m.DownPipeline
+			if up {
+				f = m.UpPipeline
+			}
+			if f == nil {
+				return nil
+			}
+			return f(ptr)
+		})
+		ptr.SchemaVersion = opts.Target
+		return changed, err
+	})
+	if err != nil {
+		return pipelinesMigrated, fmt.Errorf("error migrating pipelines: %v", err)
+	}
+	jobsMigrated, err := migrateCollection(ctx, etcdClient, jobs, &pps.EtcdJobInfo{}, opts, func(val proto.Message) (bool, error) {
+		jobPtr := val.(*pps.EtcdJobInfo)
+		changed, err := migrateRecord(jobPtr.SchemaVersion, func(m *Migration, up bool) error {
+			f := m.DownJob
+			if up {
+				f = m.UpJob
+			}
+			if f == nil {
+				return nil
+			}
+			return f(jobPtr)
+		})
+		jobPtr.SchemaVersion = opts.Target
+		return changed, err
+	})
+	if err != nil {
+		return pipelinesMigrated + jobsMigrated, fmt.Errorf("error migrating jobs: %v", err)
+	}
+	return pipelinesMigrated + jobsMigrated, nil
+}
+
+// migrateCollection calls 'f' on every record in 'c' and, if it returns true,
+// writes the record that 'f' modified back (unless opts.DryRun is set). Each
+// record is read and written in its own transaction.
+func migrateCollection(ctx context.Context, etcdClient *etcd.Client, c col.Collection, template proto.Message, opts MigrateOptions, f func(proto.Message) (bool, error)) (int, error) {
+	var keys []string
+	val := proto.Clone(template)
+	if err := c.ReadOnly(ctx).List(val, col.DefaultOptions, func(key string) error {
+		keys = append(keys, key)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	var migrated int
+	for _, key := range keys {
+		var changed bool
+		if _, err := col.NewSTM(ctx, etcdClient, func(stm col.STM) error {
+			changed = false
+			val := proto.Clone(template)
+			if err := c.ReadWrite(stm).Get(key, val); err != nil {
+				if col.IsErrNotFound(err) {
+					return nil // the record was deleted after it was listed
+				}
+				return err
+			}
+			var err error
+			if changed, err = f(val); err != nil || !changed || opts.DryRun {
+				return err
+			}
+			return c.ReadWrite(stm).Put(key, val)
+		}); err != nil {
+			return migrated, fmt.Errorf("error migrating %q: %v", key, err)
+		}
+		if changed {
+			migrated++
+			log.Debugf("migrated %q to PPS schema version %d (dry run: %t)", key, opts.Target, opts.DryRun)
+		}
+	}
+	return migrated, nil
+}
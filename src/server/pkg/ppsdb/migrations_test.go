@@ -0,0 +1,125 @@
+package ppsdb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/testutil"
+)
+
+func TestMigrate(t *testing.T) {
+	testMigrations := []*Migration{
+		migrations[0],
+		{
+			Version:     2,
+			Description: "prefix reasons",
+			UpPipeline: func(ptr *pps.EtcdPipelineInfo) error {
+				ptr.Reason = "v2: " + ptr.Reason
+				return nil
+			},
+			DownPipeline: func(ptr *pps.EtcdPipelineInfo) error {
+				ptr.Reason = ptr.Reason[len("v2: "):]
+				return nil
+			},
+			UpJob: func(jobPtr *pps.EtcdJobInfo) error {
+				jobPtr.Reason = "v2: " + jobPtr.Reason
+				return nil
+			},
+			DownJob: func(jobPtr *pps.EtcdJobInfo) error {
+				jobPtr.Reason = jobPtr.Reason[len("v2: "):]
+				return nil
+			},
+		},
+	}
+	require.NoError(t, testutil.WithEtcdEnv(func(env *testutil.EtcdEnv) error {
+		pipelines := Pipelines(env.EtcdClient, "")
+		jobs := Jobs(env.EtcdClient, "")
+		_, err := col.NewSTM(env.Context, env.EtcdClient, func(stm col.STM) error {
+			// An old pipeline, a current one and a job
+			if err := pipelines.ReadWrite(stm).Put("old", &pps.EtcdPipelineInfo{Reason: "old"}); err != nil {
+				return err
+			}
+			if err := pipelines.ReadWrite(stm).Put("new", &pps.EtcdPipelineInfo{Reason: "v2: new", SchemaVersion: 2}); err != nil {
+				return err
+			}
+			return jobs.ReadWrite(stm).Put("job", &pps.EtcdJobInfo{Job: client.NewJob("job"), Pipeline: client.NewPipeline("old"), OutputCommit: client.NewCommit("old", "c"), Reason: "job"})
+		})
+		require.NoError(t, err)
+		check := func(oldReason string, oldVersion uint64, jobReason string, jobVersion uint64) {
+			ptr := &pps.EtcdPipelineInfo{}
+			require.NoError(t, pipelines.ReadOnly(env.Context).Get("old", ptr))
+			require.Equal(t, oldReason, ptr.Reason)
+			require.Equal(t, oldVersion, ptr.SchemaVersion)
+			jobPtr := &pps.EtcdJobInfo{}
+			require.NoError(t, jobs.ReadOnly(env.Context).Get("job", jobPtr))
+			require.Equal(t, jobReason, jobPtr.Reason)
+			require.Equal(t, jobVersion, jobPtr.SchemaVersion)
+		}
+
+		// A dry run doesn't change anything
+		migrated, err := migrate(env.Context, env.EtcdClient, pipelines, jobs, testMigrations, MigrateOptions{Target: 2, DryRun: true})
+		require.NoError(t, err)
+		require.Equal(t, 2, migrated)
+		check("old", 0, "job", 0)
+
+		migrated, err = migrate(env.Context, env.EtcdClient, pipelines, jobs, testMigrations, MigrateOptions{Target: 2})
+		require.NoError(t, err)
+		require.Equal(t, 2, migrated)
+		check("v2: old", 2, "v2: job", 2)
+		// Migrating again is a no-op
+		migrated, err = migrate(env.Context, env.EtcdClient, pipelines, jobs, testMigrations, MigrateOptions{Target: 2})
+		require.NoError(t, err)
+		require.Equal(t, 0, migrated)
+
+		// Rolling back applies the Down functions
+		migrated, err = migrate(env.Context, env.EtcdClient, pipelines, jobs, testMigrations, MigrateOptions{Target: 1})
+		require.NoError(t, err)
+		require.Equal(t, 3, migrated)
+		check("old", 1, "job", 1)
+		ptr := &pps.EtcdPipelineInfo{}
+		require.NoError(t, pipelines.ReadOnly(env.Context).Get("new", ptr))
+		require.Equal(t, "new", ptr.Reason)
+
+		// Records can't be migrated past the latest migration, or from a
+		// version that pachd doesn't know
+		_, err = migrate(env.Context, env.EtcdClient, pipelines, jobs, testMigrations, MigrateOptions{Target: 3})
+		require.YesError(t, err)
+		_, err = col.NewSTM(env.Context, env.EtcdClient, func(stm col.STM) error {
+			return pipelines.ReadWrite(stm).Put("newer", &pps.EtcdPipelineInfo{SchemaVersion: 3})
+		})
+		require.NoError(t, err)
+		_, err = migrate(env.Context, env.EtcdClient, pipelines, jobs, testMigrations, MigrateOptions{Target: 2})
+		require.YesError(t, err)
+		return nil
+	}))
+}
+
+func TestMigrateValidatesMigrations(t *testing.T) {
+	outOfOrder := []*Migration{migrations[0], {Version: 3}}
+	_, err := migrate(nil, nil, nil, nil, outOfOrder, MigrateOptions{Target: 1})
+	require.YesError(t, err)
+	failing := []*Migration{{
+		Version: 1,
+		UpPipeline: func(*pps.EtcdPipelineInfo) error {
+			return errors.New("failed")
+		},
+	}}
+	require.NoError(t, testutil.WithEtcdEnv(func(env *testutil.EtcdEnv) error {
+		pipelines := Pipelines(env.EtcdClient, "")
+		_, err := col.NewSTM(env.Context, env.EtcdClient, func(stm col.STM) error {
+			return pipelines.ReadWrite(stm).Put("pipeline", &pps.EtcdPipelineInfo{})
+		})
+		require.NoError(t, err)
+		_, err = migrate(env.Context, env.EtcdClient, pipelines, Jobs(env.EtcdClient, ""), failing, MigrateOptions{Target: 1})
+		require.YesError(t, err)
+		// The failed record is left as it was
+		ptr := &pps.EtcdPipelineInfo{}
+		require.NoError(t, pipelines.ReadOnly(env.Context).Get("pipeline", ptr))
+		require.Equal(t, uint64(0), ptr.SchemaVersion)
+		return nil
+	}))
+}
@@ -2,9 +2,15 @@
 package ppsdb
 
 import (
+	"fmt"
+	"net/url"
 	"path"
+	"sort"
+	"strings"
+	"time"
 
 	etcd "github.com/coreos/etcd/clientv3"
+	"github.com/gogo/protobuf/types"
 
 	"github.com/pachyderm/pachyderm/src/client/pps"
 	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
@@ -13,6 +19,13 @@ import (
 const (
 	pipelinesPrefix = "/pipelines"
 	jobsPrefix      = "/jobs"
+
+	// startedIndexFormat is the format of the start times in JobsStartedIndex,
+	// which has a fixed width so that they sort by time
+	startedIndexFormat = "2006-01-02T15:04:05.000000000Z"
+	// unstartedIndexValue is the JobsStartedIndex value of jobs that haven't
+	// started, which sorts after every start time
+	unstartedIndexValue = "unstarted"
 )
 
 var (
@@ -25,6 +38,22 @@ var (
 
 	// JobsOutputIndex maps job outputs to the job that create them.
 	JobsOutputIndex = &col.Index{Field: "OutputCommit", Multi: false}
+
+	// JobsLabelIndex maps labels (as encoded by LabelIndexValue) to the jobs
+	// that have them
+	JobsLabelIndex = &col.Index{Field: "Labels", Multi: true}
+
+	// JobsStateIndex maps job states to the jobs in them
+	JobsStateIndex = &col.Index{Field: "State", Multi: false}
+
+	// JobsStartedIndex maps start times (as encoded by StartedIndexValue) to
+	// the jobs that started then. Its values sort by time, so it can be read
+	// by time range (see StartedIndexRange).
+	JobsStartedIndex = &col.Index{Field: "StartedIndex", Multi: false}
+
+	// PipelinesLabelIndex maps labels (as encoded by LabelIndexValue) to the
+	// pipelines that have them
+	PipelinesLabelIndex = &col.Index{Field: "Labels", Multi: true}
 )
 
 // Pipelines returns a Collection of pipelines
@@ -32,7 +61,7 @@ func Pipelines(etcdClient *etcd.Client, etcdPrefix string) col.Collection {
 	return col.NewCollection(
 		etcdClient,
 		path.Join(etcdPrefix, pipelinesPrefix),
-		nil,
+		[]*col.Index{PipelinesLabelIndex},
 		&pps.EtcdPipelineInfo{},
 		nil,
 		nil,
@@ -44,9 +73,87 @@ func Jobs(etcdClient *etcd.Client, etcdPrefix string) col.Collection {
 	return col.NewCollection(
 		etcdClient,
 		path.Join(etcdPrefix, jobsPrefix),
-		[]*col.Index{JobsPipelineIndex, JobsOutputIndex},
+		[]*col.Index{JobsPipelineIndex, JobsOutputIndex, JobsLabelIndex, JobsStateIndex, JobsStartedIndex},
 		&pps.EtcdJobInfo{},
 		nil,
 		nil,
 	)
 }
+
+// LabelIndexValue encodes the label 'key=value' as a value of the label
+// indexes. Both parts are escaped, as label keys may contain slashes, which
+// would otherwise be read as part of the index path.
+func LabelIndexValue(key, value string) string {
+	return url.PathEscape(key) + "=" + url.PathEscape(value)
+}
+
+// LabelIndexValues encodes 'labels' for EtcdPipelineInfo.Labels and
+// EtcdJobInfo.Labels
+func LabelIndexValues(labels map[string]string) []string {
+	var result []string
+	for key, value := range labels {
+		result = append(result, LabelIndexValue(key, value))
+	}
+	sort.Strings(result)
+	return result
+}
+
+// StartedIndexValue encodes a job's start time 'started' (which is nil if the
+// job hasn't started) for EtcdJobInfo.StartedIndex
+func StartedIndexValue(started *types.Timestamp) string {
+	if started == nil {
+		return unstartedIndexValue
+	}
+	t, err := types.TimestampFromProto(started)
+	if err != nil {
+		return unstartedIndexValue
+	}
+	return t.UTC().Format(startedIndexFormat)
+}
+
+// StartedIndexRange returns the range of JobsStartedIndex values (for
+// ReadonlyCollection.GetByIndexRange) of the jobs that started at or after
+// 'after' and before 'before'. Either may be nil, which leaves that end of
+// the range open, although jobs that haven't started are never in it.
+func StartedIndexRange(after, before *types.Timestamp) (string, string, error) {
+	from, to := time.Time{}.Format(startedIndexFormat), unstartedIndexValue
+	if after != nil {
+		t, err := types.TimestampFromProto(after)
+		if err != nil {
+			return "", "", err
+		}
+		from = t.UTC().Format(startedIndexFormat)
+	}
+	if before != nil {
+		t, err := types.TimestampFromProto(before)
+		if err != nil {
+			return "", "", err
+		}
+		to = t.UTC().Format(startedIndexFormat)
+	}
+	return from, to, nil
+}
+
+// ParseLabelIndexValues decodes the output of LabelIndexValues
+func ParseLabelIndexValues(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]string)
+	for _, v := range values {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("malformed label %q", v)
+		}
+		key, err := url.PathUnescape(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		value, err := url.PathUnescape(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+	return result, nil
+}
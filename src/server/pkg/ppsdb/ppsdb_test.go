@@ -0,0 +1,87 @@
+package ppsdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/testutil"
+)
+
+func TestJobsStateAndStartedIndexes(t *testing.T) {
+	start := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *types.Timestamp {
+		ts, err := types.TimestampProto(start.Add(d))
+		require.NoError(t, err)
+		return ts
+	}
+	jobPtrs := []*pps.EtcdJobInfo{
+		{Job: client.NewJob("a"), State: pps.JobState_JOB_SUCCESS, Started: at(0)},
+		{Job: client.NewJob("b"), State: pps.JobState_JOB_RUNNING, Started: at(time.Minute)},
+		{Job: client.NewJob("c"), State: pps.JobState_JOB_RUNNING, Started: at(time.Hour)},
+		{Job: client.NewJob("d"), State: pps.JobState_JOB_STARTING},
+	}
+	require.NoError(t, testutil.WithEtcdEnv(func(env *testutil.EtcdEnv) error {
+		jobs := Jobs(env.EtcdClient, "")
+		_, err := col.NewSTM(env.Context, env.EtcdClient, func(stm col.STM) error {
+			for _, jobPtr := range jobPtrs {
+				jobPtr.Pipeline = client.NewPipeline("p")
+				jobPtr.OutputCommit = client.NewCommit("p", jobPtr.Job.ID)
+				jobPtr.StartedIndex = StartedIndexValue(jobPtr.Started)
+				if err := jobs.ReadWrite(stm).Put(jobPtr.Job.ID, jobPtr); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		opts := &col.Options{Target: col.DefaultOptions.Target, Order: col.DefaultOptions.Order}
+		read := func(f func(jobPtr *pps.EtcdJobInfo, f func(string) error) error) []string {
+			var result []string
+			jobPtr := &pps.EtcdJobInfo{}
+			require.NoError(t, f(jobPtr, func(string) error {
+				result = append(result, jobPtr.Job.ID)
+				return nil
+			}))
+			return result
+		}
+		ro := jobs.ReadOnly(env.Context)
+		byState := func(state pps.JobState) []string {
+			return read(func(jobPtr *pps.EtcdJobInfo, f func(string) error) error {
+				return ro.GetByIndex(JobsStateIndex, state, jobPtr, opts, f)
+			})
+		}
+		require.ElementsEqual(t, []string{"b", "c"}, byState(pps.JobState_JOB_RUNNING))
+		require.ElementsEqual(t, []string{"d"}, byState(pps.JobState_JOB_STARTING))
+		require.Equal(t, 0, len(byState(pps.JobState_JOB_FAILURE)))
+
+		startedBetween := func(after, before *types.Timestamp) []string {
+			from, to, err := StartedIndexRange(after, before)
+			require.NoError(t, err)
+			return read(func(jobPtr *pps.EtcdJobInfo, f func(string) error) error {
+				return ro.GetByIndexRange(JobsStartedIndex, from, to, jobPtr, opts, f)
+			})
+		}
+		require.ElementsEqual(t, []string{"a", "b", "c"}, startedBetween(nil, nil))
+		require.ElementsEqual(t, []string{"a", "b"}, startedBetween(at(0), at(time.Hour)))
+		require.ElementsEqual(t, []string{"b", "c"}, startedBetween(at(time.Second), nil))
+		require.ElementsEqual(t, []string{"a"}, startedBetween(nil, at(time.Second)))
+
+		// Changing a job's state moves it in the index
+		_, err = col.NewSTM(env.Context, env.EtcdClient, func(stm col.STM) error {
+			jobPtr := &pps.EtcdJobInfo{}
+			return jobs.ReadWrite(stm).Update("b", jobPtr, func() error {
+				jobPtr.State = pps.JobState_JOB_SUCCESS
+				return nil
+			})
+		})
+		require.NoError(t, err)
+		require.ElementsEqual(t, []string{"c"}, byState(pps.JobState_JOB_RUNNING))
+		require.ElementsEqual(t, []string{"a", "b"}, byState(pps.JobState_JOB_SUCCESS))
+		return nil
+	}))
+}
@@ -0,0 +1,174 @@
+package ppsutil
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// Defaults for the fields of pps.ServiceAutoscaling
+const (
+	DefaultAutoscalingInterval = 15 * time.Second
+	DefaultScaleDownDelay      = 5 * time.Minute
+	DefaultMetricsPath         = "/metrics"
+	DefaultRequestsMetric      = "http_requests_total"
+	DefaultLatencyMetric       = "http_request_duration_seconds"
+)
+
+// latencyTolerance is how far (as a fraction of target_latency) a service's
+// latency may be from its target before it's scaled on latency, so that noise
+// in the latency doesn't make it flap
+const latencyTolerance = 0.1
+
+// ValidateServiceAutoscaling returns an error if 'autoscaling' (which may be
+// nil) doesn't have a valid range of replicas, or doesn't have exactly one way
+// of measuring the service's traffic
+func ValidateServiceAutoscaling(autoscaling *pps.ServiceAutoscaling) error {
+	if autoscaling == nil {
+		return nil
+	}
+	if autoscaling.MaxReplicas == 0 {
+		return fmt.Errorf("service autoscaling must have a positive max_replicas")
+	}
+	if autoscaling.MinReplicas > autoscaling.MaxReplicas {
+		return fmt.Errorf("service autoscaling's min_replicas (%d) cannot be greater than its max_replicas (%d)",
+			autoscaling.MinReplicas, autoscaling.MaxReplicas)
+	}
+	if autoscaling.MetricsPort < 0 || autoscaling.MetricsPort > 65535 {
+		return fmt.Errorf("invalid service autoscaling metrics_port %d", autoscaling.MetricsPort)
+	}
+	if autoscaling.TargetRequestsPerSecond < 0 {
+		return fmt.Errorf("service autoscaling's target_requests_per_second cannot be negative")
+	}
+	for name, d := range map[string]*types.Duration{
+		"target_latency":   autoscaling.TargetLatency,
+		"interval":         autoscaling.Interval,
+		"scale_down_delay": autoscaling.ScaleDownDelay,
+	} {
+		if d == nil {
+			continue
+		}
+		duration, err := types.DurationFromProto(d)
+		if err != nil {
+			return fmt.Errorf("invalid service autoscaling %s: %v", name, err)
+		}
+		if duration <= 0 {
+			return fmt.Errorf("service autoscaling's %s must be positive", name)
+		}
+	}
+	targets := autoscaling.TargetRequestsPerSecond > 0 || autoscaling.TargetLatency != nil
+	if trigger := autoscaling.Prometheus; trigger != nil {
+		if targets {
+			return fmt.Errorf("service autoscaling cannot set both a prometheus trigger and target_requests_per_second or target_latency")
+		}
+		if trigger.ServerAddress == "" || trigger.Query == "" {
+			return fmt.Errorf("service autoscaling's prometheus trigger must have a server_address and a query")
+		}
+		if u, err := url.Parse(trigger.ServerAddress); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("service autoscaling's prometheus server_address %q must be an http or https url", trigger.ServerAddress)
+		}
+		if trigger.Threshold <= 0 {
+			return fmt.Errorf("service autoscaling's prometheus trigger must have a positive threshold")
+		}
+		return nil
+	}
+	if !targets {
+		return fmt.Errorf("service autoscaling must set target_requests_per_second, target_latency or a prometheus trigger")
+	}
+	return nil
+}
+
+// AutoscalingIntervals returns how often the traffic of a service with
+// 'autoscaling' is measured, and how long it must stay low before the
+// service is scaled down
+func AutoscalingIntervals(autoscaling *pps.ServiceAutoscaling) (interval time.Duration, scaleDownDelay time.Duration) {
+	interval, scaleDownDelay = DefaultAutoscalingInterval, DefaultScaleDownDelay
+	if d, err := types.DurationFromProto(autoscaling.Interval); err == nil && d > 0 {
+		interval = d
+	}
+	if d, err := types.DurationFromProto(autoscaling.ScaleDownDelay); err == nil && d > 0 {
+		scaleDownDelay = d
+	}
+	return interval, scaleDownDelay
+}
+
+// ServiceReplicas returns the number of workers that a service with
+// 'autoscaling' runs, given its most recent scaling decision 'status' (which
+// may be nil). The decision is clamped to the service's current range of
+// replicas, in case it was made before the service was updated.
+func ServiceReplicas(autoscaling *pps.ServiceAutoscaling, status *pps.ServiceAutoscalingStatus) uint64 {
+	return clampReplicas(autoscaling, status.GetReplicas())
+}
+
+// AutoscalingSample is a measurement of a service's traffic, from which its
+// number of workers is chosen (see DesiredServiceReplicas)
+type AutoscalingSample struct {
+	// RequestsPerSecond is the rate of requests to all of the service's workers
+	RequestsPerSecond float64
+	// Latency is the mean time that requests took, or 0 if there were none
+	Latency time.Duration
+	// TriggerValue is the result of the service's Prometheus query, if it has
+	// a prometheus trigger
+	TriggerValue float64
+}
+
+// DesiredServiceReplicas returns the number of workers that a service with
+// 'autoscaling', which is running 'current' workers, needs for the traffic in
+// 'sample'. The service needs enough workers to meet each of its targets.
+func DesiredServiceReplicas(autoscaling *pps.ServiceAutoscaling, current uint64, sample AutoscalingSample) uint64 {
+	var desired uint64
+	if trigger := autoscaling.Prometheus; trigger != nil {
+		desired = ceil(sample.TriggerValue / trigger.Threshold)
+		return clampReplicas(autoscaling, desired)
+	}
+	if target := autoscaling.TargetRequestsPerSecond; target > 0 {
+		desired = ceil(sample.RequestsPerSecond / target)
+	}
+	if autoscaling.TargetLatency != nil {
+		target, err := types.DurationFromProto(autoscaling.TargetLatency)
+		if err == nil && target > 0 && sample.Latency > 0 {
+			// Like kubernetes' HPA, scale in proportion to how far the latency is
+			// from its target, unless it's close
+			ratio := float64(sample.Latency) / float64(target)
+			byLatency := current
+			if math.Abs(ratio-1) > latencyTolerance {
+				byLatency = ceil(float64(current) * ratio)
+			}
+			if byLatency > desired {
+				desired = byLatency
+			}
+		}
+	}
+	return clampReplicas(autoscaling, desired)
+}
+
+// clampReplicas returns 'replicas', limited to the range of replicas in
+// 'autoscaling'
+func clampReplicas(autoscaling *pps.ServiceAutoscaling, replicas uint64) uint64 {
+	min := autoscaling.MinReplicas
+	if min == 0 {
+		min = 1
+	}
+	if replicas < min {
+		return min
+	}
+	if max := autoscaling.MaxReplicas; max > 0 && replicas > max {
+		return max
+	}
+	return replicas
+}
+
+// ceil returns 'f' rounded up to a whole, non-negative number
+func ceil(f float64) uint64 {
+	if f <= 0 || math.IsNaN(f) {
+		return 0
+	}
+	if math.IsInf(f, 1) || f >= math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return uint64(math.Ceil(f))
+}
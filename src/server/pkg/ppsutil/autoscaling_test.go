@@ -0,0 +1,84 @@
+package ppsutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestValidateServiceAutoscaling(t *testing.T) {
+	require.NoError(t, ValidateServiceAutoscaling(nil))
+	require.NoError(t, ValidateServiceAutoscaling(&pps.ServiceAutoscaling{
+		MaxReplicas: 4, TargetRequestsPerSecond: 100,
+	}))
+	require.NoError(t, ValidateServiceAutoscaling(&pps.ServiceAutoscaling{
+		MinReplicas: 2, MaxReplicas: 4, TargetLatency: types.DurationProto(100 * time.Millisecond),
+	}))
+	require.NoError(t, ValidateServiceAutoscaling(&pps.ServiceAutoscaling{
+		MaxReplicas: 4,
+		Prometheus:  &pps.PrometheusTrigger{ServerAddress: "http://prometheus:9090", Query: "up", Threshold: 10},
+	}))
+
+	// No max_replicas, or a min_replicas above it
+	require.YesError(t, ValidateServiceAutoscaling(&pps.ServiceAutoscaling{TargetRequestsPerSecond: 100}))
+	require.YesError(t, ValidateServiceAutoscaling(&pps.ServiceAutoscaling{
+		MinReplicas: 5, MaxReplicas: 4, TargetRequestsPerSecond: 100,
+	}))
+	// No targets, or targets and a trigger
+	require.YesError(t, ValidateServiceAutoscaling(&pps.ServiceAutoscaling{MaxReplicas: 4}))
+	require.YesError(t, ValidateServiceAutoscaling(&pps.ServiceAutoscaling{
+		MaxReplicas: 4, TargetRequestsPerSecond: 100,
+		Prometheus: &pps.PrometheusTrigger{ServerAddress: "http://prometheus:9090", Query: "up", Threshold: 10},
+	}))
+	// Incomplete trigger
+	require.YesError(t, ValidateServiceAutoscaling(&pps.ServiceAutoscaling{
+		MaxReplicas: 4, Prometheus: &pps.PrometheusTrigger{ServerAddress: "http://prometheus:9090", Threshold: 10},
+	}))
+	require.YesError(t, ValidateServiceAutoscaling(&pps.ServiceAutoscaling{
+		MaxReplicas: 4, Prometheus: &pps.PrometheusTrigger{ServerAddress: "http://prometheus:9090", Query: "up"},
+	}))
+	// Non-positive durations
+	require.YesError(t, ValidateServiceAutoscaling(&pps.ServiceAutoscaling{
+		MaxReplicas: 4, TargetRequestsPerSecond: 100, Interval: types.DurationProto(0),
+	}))
+}
+
+func TestDesiredServiceReplicas(t *testing.T) {
+	autoscaling := &pps.ServiceAutoscaling{
+		MinReplicas:             2,
+		MaxReplicas:             10,
+		TargetRequestsPerSecond: 100,
+		TargetLatency:           types.DurationProto(100 * time.Millisecond),
+	}
+	// Enough workers for the requests, but no fewer than min_replicas
+	require.Equal(t, uint64(5), DesiredServiceReplicas(autoscaling, 3, AutoscalingSample{RequestsPerSecond: 450}))
+	require.Equal(t, uint64(2), DesiredServiceReplicas(autoscaling, 3, AutoscalingSample{}))
+	// Slow requests need proportionally more workers, unless they're close to
+	// the target
+	require.Equal(t, uint64(6), DesiredServiceReplicas(autoscaling, 3, AutoscalingSample{
+		RequestsPerSecond: 150, Latency: 200 * time.Millisecond,
+	}))
+	require.Equal(t, uint64(3), DesiredServiceReplicas(autoscaling, 3, AutoscalingSample{
+		RequestsPerSecond: 150, Latency: 105 * time.Millisecond,
+	}))
+	// No more than max_replicas
+	require.Equal(t, uint64(10), DesiredServiceReplicas(autoscaling, 3, AutoscalingSample{RequestsPerSecond: 5000}))
+
+	// Triggers need one worker per threshold
+	trigger := &pps.ServiceAutoscaling{
+		MaxReplicas: 10,
+		Prometheus:  &pps.PrometheusTrigger{ServerAddress: "http://prometheus:9090", Query: "up", Threshold: 20},
+	}
+	require.Equal(t, uint64(3), DesiredServiceReplicas(trigger, 1, AutoscalingSample{TriggerValue: 41}))
+	require.Equal(t, uint64(1), DesiredServiceReplicas(trigger, 4, AutoscalingSample{}))
+}
+
+func TestServiceReplicas(t *testing.T) {
+	autoscaling := &pps.ServiceAutoscaling{MinReplicas: 2, MaxReplicas: 4}
+	require.Equal(t, uint64(2), ServiceReplicas(autoscaling, nil))
+	require.Equal(t, uint64(3), ServiceReplicas(autoscaling, &pps.ServiceAutoscalingStatus{Replicas: 3}))
+	require.Equal(t, uint64(4), ServiceReplicas(autoscaling, &pps.ServiceAutoscalingStatus{Replicas: 8}))
+}
@@ -0,0 +1,91 @@
+package ppsutil
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// budgetMonthFormat is the format of pps.BudgetSpend.Month
+const budgetMonthFormat = "2006-01"
+
+// ValidateBudget returns an error if 'budget' (which may be nil) doesn't have
+// a positive monthly limit and a non-negative worker-hour cost
+func ValidateBudget(budget *pps.Budget) error {
+	if budget == nil {
+		return nil
+	}
+	if budget.MonthlyLimit <= 0 {
+		return fmt.Errorf("budget must have a positive monthly_limit")
+	}
+	if budget.WorkerHourCost < 0 {
+		return fmt.Errorf("budget's worker_hour_cost cannot be negative")
+	}
+	return nil
+}
+
+// nextBudgetMonth returns the start of the month (in UTC) after the one
+// containing 't', i.e. when a pipeline's budget resets
+func nextBudgetMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// JobCost returns the estimated cost of the job in 'jobPtr', which finished at
+// 'finished', given the budget and worker count in 'pipelinePtr'. It returns 0
+// if the pipeline has no budget or the job never started.
+func JobCost(pipelinePtr *pps.EtcdPipelineInfo, jobPtr *pps.EtcdJobInfo, finished time.Time) (float64, error) {
+	if pipelinePtr.Budget == nil || jobPtr.Started == nil {
+		return 0, nil
+	}
+	started, err := types.TimestampFromProto(jobPtr.Started)
+	if err != nil {
+		return 0, err
+	}
+	workers := pipelinePtr.Parallelism
+	if workers < 1 {
+		workers = 1
+	}
+	return finished.Sub(started).Hours() * float64(workers) * pipelinePtr.Budget.WorkerHourCost, nil
+}
+
+// AddBudgetSpend adds 'cost' to the spend recorded in 'pipelinePtr' for the
+// month containing 'now', starting a new month's spend if needed
+func AddBudgetSpend(pipelinePtr *pps.EtcdPipelineInfo, cost float64, now time.Time) {
+	month := now.UTC().Format(budgetMonthFormat)
+	if pipelinePtr.BudgetSpend == nil || pipelinePtr.BudgetSpend.Month != month {
+		pipelinePtr.BudgetSpend = &pps.BudgetSpend{Month: month}
+	}
+	spend := pipelinePtr.BudgetSpend
+	spend.Spend += cost
+	spend.Exceeded = pipelinePtr.Budget != nil && spend.Spend > pipelinePtr.Budget.MonthlyLimit
+}
+
+// BudgetExceeded returns true if the pipeline in 'pipelinePtr' has exceeded
+// its budget in the month containing 'now', along with the time at which its
+// budget resets. The spend is checked against the pipeline's current monthly
+// limit, so raising the limit lifts the cap.
+func BudgetExceeded(pipelinePtr *pps.EtcdPipelineInfo, now time.Time) (time.Time, bool) {
+	spend := pipelinePtr.BudgetSpend
+	if pipelinePtr.Budget == nil || spend == nil || spend.Month != now.UTC().Format(budgetMonthFormat) {
+		return time.Time{}, false
+	}
+	return nextBudgetMonth(now), spend.Spend > pipelinePtr.Budget.MonthlyLimit
+}
+
+// CurrentBudgetSpend returns the pipeline's spend in the month containing
+// 'now', which is empty if none of its jobs finished this month
+func CurrentBudgetSpend(pipelinePtr *pps.EtcdPipelineInfo, now time.Time) *pps.BudgetSpend {
+	month := now.UTC().Format(budgetMonthFormat)
+	if pipelinePtr.BudgetSpend == nil || pipelinePtr.BudgetSpend.Month != month {
+		return &pps.BudgetSpend{Month: month}
+	}
+	_, exceeded := BudgetExceeded(pipelinePtr, now)
+	return &pps.BudgetSpend{
+		Month:    month,
+		Spend:    pipelinePtr.BudgetSpend.Spend,
+		Exceeded: exceeded,
+	}
+}
@@ -0,0 +1,65 @@
+package ppsutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestValidateBudget(t *testing.T) {
+	require.NoError(t, ValidateBudget(nil))
+	require.NoError(t, ValidateBudget(&pps.Budget{MonthlyLimit: 100, WorkerHourCost: 0.5}))
+	require.YesError(t, ValidateBudget(&pps.Budget{WorkerHourCost: 0.5}))
+	require.YesError(t, ValidateBudget(&pps.Budget{MonthlyLimit: 100, WorkerHourCost: -1}))
+}
+
+func TestBudgetSpend(t *testing.T) {
+	now := time.Date(2020, time.March, 31, 20, 0, 0, 0, time.UTC)
+	pipelinePtr := &pps.EtcdPipelineInfo{
+		Budget:      &pps.Budget{MonthlyLimit: 10, WorkerHourCost: 1},
+		Parallelism: 4,
+	}
+	started, err := types.TimestampProto(now.Add(-2 * time.Hour))
+	require.NoError(t, err)
+	jobPtr := &pps.EtcdJobInfo{Started: started}
+
+	// A 2 hour job on 4 workers costs 8 worker-hours
+	cost, err := JobCost(pipelinePtr, jobPtr, now)
+	require.NoError(t, err)
+	require.Equal(t, 8.0, cost)
+	AddBudgetSpend(pipelinePtr, cost, now)
+	_, exceeded := BudgetExceeded(pipelinePtr, now)
+	require.False(t, exceeded)
+
+	// A second one exceeds the budget until the next month
+	AddBudgetSpend(pipelinePtr, cost, now)
+	require.True(t, pipelinePtr.BudgetSpend.Exceeded)
+	reset, exceeded := BudgetExceeded(pipelinePtr, now)
+	require.True(t, exceeded)
+	require.Equal(t, time.Date(2020, time.April, 1, 0, 0, 0, 0, time.UTC), reset)
+	_, exceeded = BudgetExceeded(pipelinePtr, reset)
+	require.False(t, exceeded)
+	require.Equal(t, 0.0, CurrentBudgetSpend(pipelinePtr, reset).Spend)
+
+	// Raising the limit lifts the cap
+	pipelinePtr.Budget.MonthlyLimit = 20
+	_, exceeded = BudgetExceeded(pipelinePtr, now)
+	require.False(t, exceeded)
+	require.False(t, CurrentBudgetSpend(pipelinePtr, now).Exceeded)
+
+	// Spend in a new month starts from 0
+	AddBudgetSpend(pipelinePtr, 1, reset)
+	require.Equal(t, "2020-04", pipelinePtr.BudgetSpend.Month)
+	require.Equal(t, 1.0, pipelinePtr.BudgetSpend.Spend)
+
+	// Jobs of pipelines without a budget, or that never started, cost nothing
+	cost, err = JobCost(pipelinePtr, &pps.EtcdJobInfo{}, now)
+	require.NoError(t, err)
+	require.Equal(t, 0.0, cost)
+	cost, err = JobCost(&pps.EtcdPipelineInfo{}, jobPtr, now)
+	require.NoError(t, err)
+	require.Equal(t, 0.0, cost)
+}
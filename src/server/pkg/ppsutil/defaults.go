@@ -0,0 +1,89 @@
+package ppsutil
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	ppsclient "github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// ParsePipelineDefaults parses a cluster's pipeline defaults, which are
+// written as a partial pipeline spec (JSON or YAML) containing only the fields
+// that ApplyPipelineDefaults knows how to merge. An empty spec yields nil.
+func ParsePipelineDefaults(data []byte) (*ppsclient.CreatePipelineRequest, error) {
+	request, err := NewPipelineManifestReaderFromBytes(data).NextCreatePipelineRequest()
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("malformed pipeline defaults: %v", err)
+	}
+	// Check that 'request' doesn't set any fields that can't be defaulted
+	supported := &ppsclient.CreatePipelineRequest{}
+	ApplyPipelineDefaults(supported, request)
+	if !proto.Equal(supported, request) {
+		return nil, fmt.Errorf("pipeline defaults may only set resource_requests, " +
+			"resource_limits, datum_tries, datum_timeout, job_timeout, standby and " +
+			"transform.image_pull_secrets")
+	}
+	return request, nil
+}
+
+// ApplyPipelineDefaults fills in the fields of 'request' that the pipeline's
+// spec doesn't set with the cluster's pipeline 'defaults'. Fields set in the
+// spec always take precedence over the cluster's defaults, which in turn take
+// precedence over Pachyderm's built-in defaults (applied later, when the
+// pipeline is created). Note that because 'standby' is a bool, a spec can't
+// turn it off if the cluster's defaults turn it on.
+func ApplyPipelineDefaults(request *ppsclient.CreatePipelineRequest, defaults *ppsclient.CreatePipelineRequest) {
+	if defaults == nil {
+		return
+	}
+	request.ResourceRequests = mergeResourceSpec(request.ResourceRequests, defaults.ResourceRequests)
+	request.ResourceLimits = mergeResourceSpec(request.ResourceLimits, defaults.ResourceLimits)
+	if request.DatumTries == 0 {
+		request.DatumTries = defaults.DatumTries
+	}
+	if request.DatumTimeout == nil && defaults.DatumTimeout != nil {
+		request.DatumTimeout = proto.Clone(defaults.DatumTimeout).(*types.Duration)
+	}
+	if request.JobTimeout == nil && defaults.JobTimeout != nil {
+		request.JobTimeout = proto.Clone(defaults.JobTimeout).(*types.Duration)
+	}
+	if !request.Standby {
+		request.Standby = defaults.Standby
+	}
+	if defaults.Transform != nil && len(defaults.Transform.ImagePullSecrets) > 0 {
+		if request.Transform == nil {
+			request.Transform = &ppsclient.Transform{}
+		}
+		if len(request.Transform.ImagePullSecrets) == 0 {
+			request.Transform.ImagePullSecrets = append([]string(nil), defaults.Transform.ImagePullSecrets...)
+		}
+	}
+}
+
+// mergeResourceSpec returns 'spec' with any unset fields filled in from
+// 'defaults'
+func mergeResourceSpec(spec, defaults *ppsclient.ResourceSpec) *ppsclient.ResourceSpec {
+	if defaults == nil {
+		return spec
+	}
+	if spec == nil {
+		return proto.Clone(defaults).(*ppsclient.ResourceSpec)
+	}
+	if spec.Cpu == 0 {
+		spec.Cpu = defaults.Cpu
+	}
+	if spec.Memory == "" {
+		spec.Memory = defaults.Memory
+	}
+	if spec.Disk == "" {
+		spec.Disk = defaults.Disk
+	}
+	if spec.Gpu == nil && defaults.Gpu != nil {
+		spec.Gpu = proto.Clone(defaults.Gpu).(*ppsclient.GPUSpec)
+	}
+	return spec
+}
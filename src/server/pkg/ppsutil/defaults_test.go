@@ -0,0 +1,49 @@
+package ppsutil
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	ppsclient "github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+const testDefaults = `
+resource_requests:
+  cpu: 0.5
+  memory: 1G
+datum_tries: 5
+standby: true
+transform:
+  image_pull_secrets: [regcred]
+`
+
+func TestApplyPipelineDefaults(t *testing.T) {
+	defaults, err := ParsePipelineDefaults([]byte(testDefaults))
+	require.NoError(t, err)
+
+	request := &ppsclient.CreatePipelineRequest{
+		Transform:        &ppsclient.Transform{Cmd: []string{"true"}},
+		ResourceRequests: &ppsclient.ResourceSpec{Memory: "2G"},
+		DatumTries:       1,
+	}
+	ApplyPipelineDefaults(request, defaults)
+	// Fields set in the spec take precedence
+	require.Equal(t, "2G", request.ResourceRequests.Memory)
+	require.Equal(t, int64(1), request.DatumTries)
+	// Unset fields are filled in
+	require.Equal(t, float32(0.5), request.ResourceRequests.Cpu)
+	require.True(t, request.Standby)
+	require.Equal(t, []string{"regcred"}, request.Transform.ImagePullSecrets)
+	require.Nil(t, request.ResourceLimits)
+}
+
+func TestParsePipelineDefaults(t *testing.T) {
+	defaults, err := ParsePipelineDefaults(nil)
+	require.NoError(t, err)
+	require.Nil(t, defaults)
+
+	_, err = ParsePipelineDefaults([]byte(`{"pipeline": {"name": "foo"}}`))
+	require.YesError(t, err)
+	_, err = ParsePipelineDefaults([]byte(`{"transform": {"image": "ubuntu"}}`))
+	require.YesError(t, err)
+}
@@ -0,0 +1,69 @@
+package ppsutil
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/robfig/cron"
+)
+
+// ValidateDowntimeWindows returns an error if any of 'windows' has an
+// unparseable start or a non-positive duration
+func ValidateDowntimeWindows(windows []*pps.DowntimeWindow) error {
+	for _, window := range windows {
+		if _, err := cron.ParseStandard(window.Start); err != nil {
+			return fmt.Errorf("error parsing downtime window start %q: %v", window.Start, err)
+		}
+		if window.Duration == nil {
+			return fmt.Errorf("downtime window %q must have a duration", window.Start)
+		}
+		duration, err := types.DurationFromProto(window.Duration)
+		if err != nil {
+			return fmt.Errorf("invalid duration for downtime window %q: %v", window.Start, err)
+		}
+		if duration <= 0 {
+			return fmt.Errorf("downtime window %q must have a positive duration", window.Start)
+		}
+	}
+	return nil
+}
+
+// maxDowntimeWindowMerges bounds how many overlapping windows
+// DowntimeWindowEnd merges, so that windows that never close (e.g. an hour
+// long window that opens every minute) can't keep it looping forever
+const maxDowntimeWindowMerges = 1000
+
+// DowntimeWindowEnd returns the time at which the downtime windows in
+// 'windows' that are open at 'now' close, and whether any of them is open.
+// Overlapping windows are treated as a single window, so the returned time is
+// the first one at which no window is open.
+func DowntimeWindowEnd(windows []*pps.DowntimeWindow, now time.Time) (time.Time, bool, error) {
+	end := now
+	for i := 0; i < maxDowntimeWindowMerges; i++ {
+		extended := false
+		for _, window := range windows {
+			schedule, err := cron.ParseStandard(window.Start)
+			if err != nil {
+				return time.Time{}, false, err
+			}
+			duration, err := types.DurationFromProto(window.Duration)
+			if err != nil {
+				return time.Time{}, false, err
+			}
+			// The windows that are open at 'at' started in (at-duration, at]
+			at := end
+			for start := schedule.Next(at.Add(-duration)); !start.After(at); start = schedule.Next(start) {
+				if start.Add(duration).After(end) {
+					end = start.Add(duration)
+					extended = true
+				}
+			}
+		}
+		if !extended {
+			break
+		}
+	}
+	return end, end.After(now), nil
+}
@@ -0,0 +1,65 @@
+package ppsutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestDowntimeWindowEnd(t *testing.T) {
+	nightly := &pps.DowntimeWindow{Start: "0 22 * * *", Duration: types.DurationProto(8 * time.Hour)}
+	day := func(hour, min int) time.Time {
+		return time.Date(2020, 3, 10, hour, min, 0, 0, time.Local)
+	}
+
+	// Outside the window
+	_, in, err := DowntimeWindowEnd([]*pps.DowntimeWindow{nightly}, day(12, 0))
+	require.NoError(t, err)
+	require.False(t, in)
+
+	// Inside the window, both before and after midnight
+	end, in, err := DowntimeWindowEnd([]*pps.DowntimeWindow{nightly}, day(23, 0))
+	require.NoError(t, err)
+	require.True(t, in)
+	require.True(t, end.Equal(day(22, 0).Add(8*time.Hour)))
+	end, in, err = DowntimeWindowEnd([]*pps.DowntimeWindow{nightly}, day(5, 0))
+	require.NoError(t, err)
+	require.True(t, in)
+	require.True(t, end.Equal(day(6, 0)))
+
+	// The window closes at its end
+	_, in, err = DowntimeWindowEnd([]*pps.DowntimeWindow{nightly}, day(6, 0))
+	require.NoError(t, err)
+	require.False(t, in)
+
+	// Overlapping windows are merged
+	morning := &pps.DowntimeWindow{Start: "0 5 * * *", Duration: types.DurationProto(3 * time.Hour)}
+	end, in, err = DowntimeWindowEnd([]*pps.DowntimeWindow{nightly, morning}, day(1, 0))
+	require.NoError(t, err)
+	require.True(t, in)
+	require.True(t, end.Equal(day(8, 0)))
+
+	// Windows that never close don't loop forever
+	always := &pps.DowntimeWindow{Start: "* * * * *", Duration: types.DurationProto(time.Hour)}
+	_, in, err = DowntimeWindowEnd([]*pps.DowntimeWindow{always}, day(1, 0))
+	require.NoError(t, err)
+	require.True(t, in)
+}
+
+func TestValidateDowntimeWindows(t *testing.T) {
+	require.NoError(t, ValidateDowntimeWindows([]*pps.DowntimeWindow{
+		{Start: "0 22 * * *", Duration: types.DurationProto(time.Hour)},
+	}))
+	require.YesError(t, ValidateDowntimeWindows([]*pps.DowntimeWindow{
+		{Start: "not a schedule", Duration: types.DurationProto(time.Hour)},
+	}))
+	require.YesError(t, ValidateDowntimeWindows([]*pps.DowntimeWindow{
+		{Start: "0 22 * * *"},
+	}))
+	require.YesError(t, ValidateDowntimeWindows([]*pps.DowntimeWindow{
+		{Start: "0 22 * * *", Duration: types.DurationProto(0)},
+	}))
+}
@@ -0,0 +1,119 @@
+package ppsutil
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
+)
+
+const (
+	// specFormatV1 is the original format of spec files: a marshalled
+	// PipelineInfo, with no header
+	specFormatV1 = 1
+	// specFormatV2 is a marshalled PipelineInfo after a two-byte header: 0
+	// and the format version. 0 isn't a valid protobuf tag, so a V1 spec never
+	// begins with it
+	specFormatV2 = 2
+
+	// CurrentSpecFormat is the format that WritePipelineSpec writes
+	CurrentSpecFormat = specFormatV2
+)
+
+// SpecStore reads and writes the PipelineInfos that PPS stores in the spec
+// repo. Each pipeline has a branch in the spec repo, and each commit on that
+// branch holds one version of the pipeline's spec. Callers shouldn't need to
+// know about the repo's layout or about the format of its files.
+type SpecStore struct {
+	pachClient *client.APIClient
+}
+
+// NewSpecStore returns a SpecStore that reads and writes specs with
+// 'pachClient'. Only PPS (with its superuser token) can write to the spec repo
+func NewSpecStore(pachClient *client.APIClient) *SpecStore {
+	return &SpecStore{pachClient: pachClient}
+}
+
+// ReadPipelineSpec returns the PipelineInfo in the spec commit 'specCommit'.
+// Only the fields stored in PFS are set (see GetPipelineInfo).
+func (s *SpecStore) ReadPipelineSpec(specCommit *pfs.Commit) (*pps.PipelineInfo, error) {
+	var buf bytes.Buffer
+	if err := s.pachClient.GetFile(ppsconsts.SpecRepo, specCommit.ID, ppsconsts.SpecFile, 0, 0, &buf); err != nil {
+		return nil, fmt.Errorf("could not read existing PipelineInfo from PFS: %v", err)
+	}
+	return decodeSpec(buf.Bytes())
+}
+
+// WritePipelineSpec writes 'pipelineInfo' to a new commit on its pipeline's
+// spec branch, and returns the commit
+func (s *SpecStore) WritePipelineSpec(pipelineInfo *pps.PipelineInfo) (*pfs.Commit, error) {
+	data, err := encodeSpec(pipelineInfo)
+	if err != nil {
+		return nil, err
+	}
+	pipelineName := pipelineInfo.Pipeline.Name
+	if _, err := s.pachClient.PutFileOverwrite(ppsconsts.SpecRepo, pipelineName, ppsconsts.SpecFile, bytes.NewReader(data), 0); err != nil {
+		return nil, err
+	}
+	branchInfo, err := s.pachClient.InspectBranch(ppsconsts.SpecRepo, pipelineName)
+	if err != nil {
+		return nil, err
+	}
+	return branchInfo.Head, nil
+}
+
+// ListSpecHistory calls 'f' on 'specCommit' and then on each of its ancestors,
+// from newest to oldest, stopping after 'history' ancestors (or after all of
+// them if 'history' is -1)
+func (s *SpecStore) ListSpecHistory(specCommit *pfs.Commit, history int64, f func(*pfs.Commit) error) error {
+	for i := int64(0); i <= history || history == -1; i++ {
+		if err := f(specCommit); err != nil {
+			return err
+		}
+		ci, err := s.pachClient.InspectCommit(ppsconsts.SpecRepo, specCommit.ID)
+		if err != nil {
+			return err
+		}
+		if ci.ParentCommit == nil {
+			return nil
+		}
+		specCommit = ci.ParentCommit
+	}
+	return nil
+}
+
+// encodeSpec returns the contents of the spec file for 'pipelineInfo', in the
+// current format
+func encodeSpec(pipelineInfo *pps.PipelineInfo) ([]byte, error) {
+	data, err := pipelineInfo.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal PipelineInfo: %v", err)
+	}
+	return append([]byte{0, CurrentSpecFormat}, data...), nil
+}
+
+// decodeSpec returns the PipelineInfo in the spec file 'data', which can be
+// in any format that PPS has written
+func decodeSpec(data []byte) (*pps.PipelineInfo, error) {
+	format := specFormatV1
+	if len(data) > 0 && data[0] == 0 {
+		if len(data) < 2 {
+			return nil, fmt.Errorf("could not read PipelineInfo from PFS: truncated spec header")
+		}
+		format, data = int(data[1]), data[2:]
+	}
+	switch format {
+	case specFormatV1, specFormatV2:
+		result := &pps.PipelineInfo{}
+		if err := result.Unmarshal(data); err != nil {
+			return nil, fmt.Errorf("could not unmarshal PipelineInfo bytes from PFS: %v", err)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("could not read PipelineInfo from PFS: unknown spec format %d "+
+			"(it may have been written by a newer version of pachd)", format)
+	}
+}
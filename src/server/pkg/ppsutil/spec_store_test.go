@@ -0,0 +1,36 @@
+package ppsutil
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestEncodeSpec(t *testing.T) {
+	pipelineInfo := &pps.PipelineInfo{
+		Pipeline:  client.NewPipeline("edges"),
+		Version:   3,
+		Transform: &pps.Transform{Cmd: []string{"python3", "/edges.py"}},
+	}
+	data, err := encodeSpec(pipelineInfo)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, CurrentSpecFormat}, data[:2])
+	decoded, err := decodeSpec(data)
+	require.NoError(t, err)
+	require.Equal(t, pipelineInfo, decoded)
+
+	// Specs written before the format was versioned have no header
+	legacy, err := pipelineInfo.Marshal()
+	require.NoError(t, err)
+	decoded, err = decodeSpec(legacy)
+	require.NoError(t, err)
+	require.Equal(t, pipelineInfo, decoded)
+
+	// Unknown formats are rejected rather than misread
+	_, err = decodeSpec(append([]byte{0, CurrentSpecFormat + 1}, legacy...))
+	require.YesError(t, err)
+	_, err = decodeSpec([]byte{0})
+	require.YesError(t, err)
+}
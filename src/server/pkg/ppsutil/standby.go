@@ -0,0 +1,55 @@
+package ppsutil
+
+import (
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// StandbyWakePending returns true if the pipeline in 'pipelinePtr' has woken
+// from standby, but hasn't started the datums of a job since
+func StandbyWakePending(pipelinePtr *pps.EtcdPipelineInfo) bool {
+	return pipelinePtr.StandbyWake != nil && pipelinePtr.StandbyWake.FirstDatumStarted == nil
+}
+
+// StartStandbyWake records, in 'pipelinePtr', that its pipeline saw a new
+// output commit at 'now' and is waking from standby. This replaces any
+// earlier wake.
+func StartStandbyWake(pipelinePtr *pps.EtcdPipelineInfo, now time.Time) error {
+	commitSeen, err := types.TimestampProto(now)
+	if err != nil {
+		return err
+	}
+	pipelinePtr.StandbyWake = &pps.StandbyWake{CommitSeen: commitSeen}
+	return nil
+}
+
+// FinishStandbyWake records, if the pipeline in 'pipelinePtr' is waking from
+// standby, that the job in 'jobPtr' made its datums available to workers at
+// 'now', which completes the wake. The completed wake is stored in both
+// 'pipelinePtr' and 'jobPtr', and is checked against 'alarm' (the pipeline's
+// standby_wake_alarm, which may be nil). It returns the completed wake, or nil
+// if the pipeline wasn't waking.
+func FinishStandbyWake(pipelinePtr *pps.EtcdPipelineInfo, jobPtr *pps.EtcdJobInfo, alarm *types.Duration, now time.Time) (*pps.StandbyWake, error) {
+	if !StandbyWakePending(pipelinePtr) {
+		return nil, nil
+	}
+	started, err := types.TimestampProto(now)
+	if err != nil {
+		return nil, err
+	}
+	wake := pipelinePtr.StandbyWake
+	wake.FirstDatumStarted = started
+	wake.Job = jobPtr.Job
+	if alarm != nil {
+		alarmDuration, err := types.DurationFromProto(alarm)
+		if err != nil {
+			return nil, err
+		}
+		wake.Alarm = alarm
+		wake.AlarmExceeded = pps.StandbyWakeLatency(wake) > alarmDuration
+	}
+	jobPtr.StandbyWake = wake
+	return wake, nil
+}
@@ -0,0 +1,54 @@
+package ppsutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestStandbyWake(t *testing.T) {
+	start := time.Unix(1000, 0)
+	pipelinePtr := &pps.EtcdPipelineInfo{}
+	jobPtr := &pps.EtcdJobInfo{Job: client.NewJob("job")}
+
+	// Nothing is recorded if the pipeline isn't waking
+	wake, err := FinishStandbyWake(pipelinePtr, jobPtr, nil, start)
+	require.NoError(t, err)
+	require.Nil(t, wake)
+	require.Nil(t, jobPtr.StandbyWake)
+
+	require.NoError(t, StartStandbyWake(pipelinePtr, start))
+	require.True(t, StandbyWakePending(pipelinePtr))
+	pipelinePtr.StandbyWake.WorkersScaled, _ = types.TimestampProto(start.Add(time.Second))
+	pipelinePtr.StandbyWake.WorkersReady, _ = types.TimestampProto(start.Add(time.Minute))
+	wake, err = FinishStandbyWake(pipelinePtr, jobPtr, types.DurationProto(30*time.Second), start.Add(2*time.Minute))
+	require.NoError(t, err)
+	require.False(t, StandbyWakePending(pipelinePtr))
+	require.Equal(t, wake, jobPtr.StandbyWake)
+	require.Equal(t, "job", wake.Job.ID)
+	require.Equal(t, 2*time.Minute, pps.StandbyWakeLatency(wake))
+	require.True(t, wake.AlarmExceeded)
+	require.Equal(t, []string{
+		"scaling up workers took 1s",
+		"starting workers took 59s",
+		"planning the job took 1m0s",
+	}, pps.StandbyWakePhases(wake))
+
+	// A completed wake isn't attributed to later jobs
+	otherJobPtr := &pps.EtcdJobInfo{Job: client.NewJob("other")}
+	wake, err = FinishStandbyWake(pipelinePtr, otherJobPtr, nil, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Nil(t, wake)
+	require.Nil(t, otherJobPtr.StandbyWake)
+
+	// The alarm is only raised if the wake is slower than it
+	jobPtr = &pps.EtcdJobInfo{Job: client.NewJob("fast")}
+	require.NoError(t, StartStandbyWake(pipelinePtr, start))
+	wake, err = FinishStandbyWake(pipelinePtr, jobPtr, types.DurationProto(time.Minute), start.Add(10*time.Second))
+	require.NoError(t, err)
+	require.False(t, wake.AlarmExceeded)
+}
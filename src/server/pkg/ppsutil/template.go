@@ -0,0 +1,40 @@
+package ppsutil
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"text/template"
+
+	ppsclient "github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// RenderPipelineTemplate renders a pipeline template (one or more pipeline
+// specs containing Go template actions, e.g. {{.customer}}) with 'params',
+// and parses the resulting pipeline specs. It's an error for the template to
+// reference a parameter that isn't in 'params'.
+func RenderPipelineTemplate(templateBytes []byte, params map[string]string) ([]*ppsclient.CreatePipelineRequest, error) {
+	tmpl, err := template.New("pipeline").Option("missingkey=error").Parse(string(templateBytes))
+	if err != nil {
+		return nil, fmt.Errorf("malformed pipeline template: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, params); err != nil {
+		return nil, fmt.Errorf("could not render pipeline template: %v", err)
+	}
+	r := NewPipelineManifestReaderFromBytes(buf.Bytes())
+	var result []*ppsclient.CreatePipelineRequest
+	for {
+		request, err := r.NextCreatePipelineRequest()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		result = append(result, request)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("pipeline template does not contain any pipelines")
+	}
+	return result, nil
+}
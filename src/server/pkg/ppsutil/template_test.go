@@ -0,0 +1,29 @@
+package ppsutil
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+const testTemplate = `
+{
+  "pipeline": {"name": "edges-{{.customer}}"},
+  "transform": {"cmd": ["/edges.sh", "{{.customer}}"]},
+  "input": {"pfs": {"repo": "images-{{.customer}}", "glob": "/*"}}
+}
+`
+
+func TestRenderPipelineTemplate(t *testing.T) {
+	requests, err := RenderPipelineTemplate([]byte(testTemplate), map[string]string{"customer": "acme"})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(requests))
+	require.Equal(t, "edges-acme", requests[0].Pipeline.Name)
+	require.Equal(t, []string{"/edges.sh", "acme"}, requests[0].Transform.Cmd)
+	require.Equal(t, "images-acme", requests[0].Input.Pfs.Repo)
+}
+
+func TestRenderPipelineTemplateMissingParameter(t *testing.T) {
+	_, err := RenderPipelineTemplate([]byte(testTemplate), map[string]string{"other": "acme"})
+	require.YesError(t, err)
+}
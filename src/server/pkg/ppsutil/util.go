@@ -14,10 +14,11 @@
 package ppsutil
 
 import (
-	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"math"
 	"path"
+	"sort"
 	"strings"
 	"time"
 
@@ -28,7 +29,8 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/pps"
 	ppsclient "github.com/pachyderm/pachyderm/src/client/pps"
 	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
-	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsdb"
+	"github.com/pachyderm/pachyderm/src/server/pkg/registry"
 
 	etcd "github.com/coreos/etcd/clientv3"
 	log "github.com/sirupsen/logrus"
@@ -37,6 +39,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kube "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 )
 
 // PipelineRepo creates a pfs repo for a given pipeline.
@@ -44,14 +47,90 @@ func PipelineRepo(pipeline *ppsclient.Pipeline) *pfs.Repo {
 	return &pfs.Repo{Name: pipeline.Name}
 }
 
+// maxRcNameLen is the length that PipelineRcName truncates RC names to. A
+// pipeline's services are named after its RC, with a suffix of up to five
+// characters ("-user"), and service names can be at most 63 characters long.
+const maxRcNameLen = 63 - len("-user")
+
 // PipelineRcName generates the name of the k8s replication controller that
-// manages a pipeline's workers
+// manages a pipeline's workers. The RC's services are named after it.
 func PipelineRcName(name string, version uint64) string {
-	// k8s won't allow RC names that contain upper-case letters
-	// or underscores
-	// TODO: deal with name collision
-	name = strings.Replace(name, "_", "-", -1)
-	return fmt.Sprintf("pipeline-%s-v%d", strings.ToLower(name), version)
+	return pipelineResourceName(name, fmt.Sprintf("-v%d", version), maxRcNameLen)
+}
+
+// MaxDatumProfileNameLen is the length of the longest datum profile name that
+// DatumProfileRcName can fit in an RC name, along with the hash of the
+// pipeline's name and the pipeline's version (of up to 20 digits).
+const MaxDatumProfileNameLen = 63 - len("pipeline--01234567-v-") - 20
+
+// DatumProfileRcName generates the name of the k8s replication controller
+// that manages the workers of a pipeline's datum profile 'profile'.
+func DatumProfileRcName(name string, version uint64, profile string) string {
+	return pipelineResourceName(name, fmt.Sprintf("-v%d-%s", version, profile), 63)
+}
+
+// ReplayRcName generates the name of the k8s replication controller that
+// manages the workers replaying the job 'jobID' (see pps.ReplayJob) of
+// version 'version' of a pipeline. Only a prefix of the job ID fits.
+func ReplayRcName(name string, version uint64, jobID string) string {
+	return pipelineResourceName(name, fmt.Sprintf("-v%d-replay-%.8s", version, jobID), maxRcNameLen)
+}
+
+// WorkerPoolRcName generates the name of the k8s replication controller that
+// manages the workers of worker pool 'pool' of version 'version' of a
+// pipeline with max_concurrent_jobs (see pps.EtcdJobInfo.worker_pool). The
+// first pool is the pipeline's own RC.
+func WorkerPoolRcName(name string, version uint64, pool int64) string {
+	if pool <= 1 {
+		return PipelineRcName(name, version)
+	}
+	return pipelineResourceName(name, fmt.Sprintf("-v%d-pool%d", version, pool), maxRcNameLen)
+}
+
+// ReplaySalt returns the salt that the workers replaying the job 'jobID' use
+// in place of their pipeline's salt 'salt', so that the replay doesn't reuse
+// datums that the pipeline has already processed.
+func ReplaySalt(salt string, jobID string) string {
+	return salt + "-replay-" + jobID
+}
+
+// PipelineResourceName generates the name of a k8s resource that a pipeline
+// keeps across its versions, such as its network policy.
+func PipelineResourceName(name string) string {
+	return pipelineResourceName(name, "", 63)
+}
+
+func pipelineResourceName(name, suffix string, maxLen int) string {
+	// k8s won't allow names that contain upper-case letters or underscores, so
+	// distinct pipeline names (e.g. "My_Pipe" and "my-pipe") can sanitize to
+	// the same k8s name. A hash of the original name keeps them apart, even
+	// when long names are truncated to 'maxLen'.
+	hash := sha256.Sum256([]byte(name))
+	suffix = fmt.Sprintf("-%x%s", hash[:4], suffix)
+	name = strings.ToLower(strings.Replace(name, "_", "-", -1))
+	if max := maxLen - len("pipeline-") - len(suffix); len(name) > max {
+		if max < 0 {
+			max = 0
+		}
+		name = name[:max]
+	}
+	return "pipeline-" + name + suffix
+}
+
+// PipelineImage returns the image that a pipeline's workers run: its
+// transform's image, pinned to the digest that the image resolved to when the
+// pipeline was created (if any).
+func PipelineImage(pipelineInfo *pps.PipelineInfo) string {
+	image := pipelineInfo.Transform.Image
+	if pipelineInfo.ImageDigest == "" || image == "" {
+		return image
+	}
+	ref, err := registry.ParseReference(image)
+	if err != nil {
+		return image
+	}
+	ref.Digest = pipelineInfo.ImageDigest
+	return ref.String()
 }
 
 // GetRequestsResourceListFromPipeline returns a list of resources that the pipeline,
@@ -96,7 +175,7 @@ func getResourceListFromSpec(resources *pps.ResourceSpec, cacheSize string) (*v1
 	}
 
 	if resources.Gpu != nil {
-		gpuStr := fmt.Sprintf("%d", resources.Gpu.Number)
+		gpuStr := fmt.Sprintf("%d", GPUUnits(resources.Gpu))
 		gpuQuantity, err := resource.ParseQuantity(gpuStr)
 		if err != nil {
 			log.Warnf("error parsing gpu string: %s: %+v", gpuStr, err)
@@ -108,17 +187,50 @@ func getResourceListFromSpec(resources *pps.ResourceSpec, cacheSize string) (*v1
 	return &result, nil
 }
 
+// GPUUnits returns the number of units of gpu.Type that 'gpu' requests. A
+// fraction of a GPU is requested as that share of the GPU's shares (rounded
+// up), since extended resources can only be requested in whole units.
+func GPUUnits(gpu *pps.GPUSpec) int64 {
+	if gpu.Fraction == 0 {
+		return gpu.Number
+	}
+	units := int64(math.Ceil(gpu.Fraction * float64(gpu.SharesPerGpu)))
+	if units < 1 {
+		units = 1
+	}
+	return units
+}
+
 // GetLimitsResourceListFromPipeline returns a list of resources that the pipeline,
 // maximally is limited to.
 func GetLimitsResourceListFromPipeline(pipelineInfo *pps.PipelineInfo) (*v1.ResourceList, error) {
 	return getResourceListFromSpec(pipelineInfo.ResourceLimits, pipelineInfo.CacheSize)
 }
 
+// GetRequestsResourceListFromDatumProfile returns a list of resources that the
+// workers of one of a pipeline's datum profiles minimally require.
+func GetRequestsResourceListFromDatumProfile(profile *pps.DatumProfile, cacheSize string) (*v1.ResourceList, error) {
+	return getResourceListFromSpec(profile.ResourceRequests, cacheSize)
+}
+
+// GetLimitsResourceListFromDatumProfile returns a list of resources that the
+// workers of one of a pipeline's datum profiles are maximally limited to.
+func GetLimitsResourceListFromDatumProfile(profile *pps.DatumProfile, cacheSize string) (*v1.ResourceList, error) {
+	return getResourceListFromSpec(profile.ResourceLimits, cacheSize)
+}
+
 // getNumNodes attempts to retrieve the number of nodes in the current k8s
-// cluster
-func getNumNodes(kubeClient *kube.Clientset) (int, error) {
-	nodeList, err := kubeClient.CoreV1().Nodes().List(metav1.ListOptions{})
-	if err != nil {
+// cluster. The request is abandoned if 'ctx' is done.
+func getNumNodes(ctx context.Context, kubeClient *kube.Clientset) (int, error) {
+	nodeList := &v1.NodeList{}
+	// The typed Nodes() client doesn't accept a context, so make the same
+	// request through the REST client
+	if err := kubeClient.CoreV1().RESTClient().Get().
+		Context(ctx).
+		Resource("nodes").
+		VersionedParams(&metav1.ListOptions{}, scheme.ParameterCodec).
+		Do().
+		Into(nodeList); err != nil {
 		return 0, fmt.Errorf("unable to retrieve node list from k8s to determine parallelism: %v", err)
 	}
 	if len(nodeList.Items) == 0 {
@@ -131,14 +243,14 @@ func getNumNodes(kubeClient *kube.Clientset) (int, error) {
 // pachyderm will start given the ParallelismSpec 'spec'.
 //
 // This is only exported for testing
-func GetExpectedNumWorkers(kubeClient *kube.Clientset, spec *ppsclient.ParallelismSpec) (int, error) {
+func GetExpectedNumWorkers(ctx context.Context, kubeClient *kube.Clientset, spec *ppsclient.ParallelismSpec) (int, error) {
 	if spec == nil || (spec.Constant == 0 && spec.Coefficient == 0) {
 		return 1, nil
 	} else if spec.Constant > 0 && spec.Coefficient == 0 {
 		return int(spec.Constant), nil
 	} else if spec.Constant == 0 && spec.Coefficient > 0 {
 		// Start ('coefficient' * 'nodes') workers. Determine number of workers
-		numNodes, err := getNumNodes(kubeClient)
+		numNodes, err := getNumNodes(ctx, kubeClient)
 		if err != nil {
 			return 0, err
 		}
@@ -162,32 +274,65 @@ func GetExpectedNumHashtrees(spec *ppsclient.HashtreeSpec) (int64, error) {
 // GetPipelineInfo retrieves and returns a valid PipelineInfo from PFS. It does
 // the PFS read/unmarshalling of bytes as well as filling in missing fields
 func GetPipelineInfo(pachClient *client.APIClient, ptr *pps.EtcdPipelineInfo) (*pps.PipelineInfo, error) {
-	result := &pps.PipelineInfo{}
-	buf := bytes.Buffer{}
-	if err := pachClient.GetFile(ppsconsts.SpecRepo, ptr.SpecCommit.ID, ppsconsts.SpecFile, 0, 0, &buf); err != nil {
-		return nil, fmt.Errorf("could not read existing PipelineInfo from PFS: %v", err)
-	}
-	if err := result.Unmarshal(buf.Bytes()); err != nil {
-		return nil, fmt.Errorf("could not unmarshal PipelineInfo bytes from PFS: %v", err)
+	result, err := NewSpecStore(pachClient).ReadPipelineSpec(ptr.SpecCommit)
+	if err != nil {
+		return nil, err
 	}
 	result.State = ptr.State
 	result.Reason = ptr.Reason
+	result.ReasonCode = ptr.ReasonCode
+	result.StateHistory = ptr.StateHistory
+	result.StandbyWake = ptr.StandbyWake
+	result.ServiceAutoscaling = ptr.ServiceAutoscaling
+	if ptr.Budget != nil {
+		result.BudgetSpend = CurrentBudgetSpend(ptr, time.Now())
+	}
 	result.JobCounts = ptr.JobCounts
 	result.LastJobState = ptr.LastJobState
 	result.SpecCommit = ptr.SpecCommit
 	return result, nil
 }
 
+// MaxPipelineStateHistory is the number of state transitions that are kept in
+// a pipeline's state history
+const MaxPipelineStateHistory = 20
+
+// SetPipelineState moves the pipeline in 'pipelinePtr' to 'state' for
+// 'reason', and records the transition in its state history (dropping the
+// oldest transition if the history is full). Only transitions to a different
+// state are recorded, except that a pipeline's first state is always recorded.
+func SetPipelineState(pipelinePtr *pps.EtcdPipelineInfo, state pps.PipelineState, reason string) {
+	if pipelinePtr.State != state || len(pipelinePtr.StateHistory) == 0 {
+		pipelinePtr.StateHistory = append(pipelinePtr.StateHistory, &pps.PipelineStateTransition{
+			PreviousState: pipelinePtr.State,
+			State:         state,
+			Reason:        reason,
+			Time:          types.TimestampNow(),
+		})
+		if extra := len(pipelinePtr.StateHistory) - MaxPipelineStateHistory; extra > 0 {
+			pipelinePtr.StateHistory = pipelinePtr.StateHistory[extra:]
+		}
+	}
+	pipelinePtr.State = state
+	pipelinePtr.Reason = reason
+}
+
 // FailPipeline updates the pipeline's state to failed and sets the failure reason
 func FailPipeline(ctx context.Context, etcdClient *etcd.Client, pipelinesCollection col.Collection, pipelineName string, reason string) error {
+	return FailPipelineWithCode(ctx, etcdClient, pipelinesCollection, pipelineName, pps.PipelineReasonCode_PIPELINE_REASON_UNKNOWN, reason)
+}
+
+// FailPipelineWithCode is like FailPipeline, but also records a code that
+// identifies the cause of the failure
+func FailPipelineWithCode(ctx context.Context, etcdClient *etcd.Client, pipelinesCollection col.Collection, pipelineName string, code pps.PipelineReasonCode, reason string) error {
 	_, err := col.NewSTM(ctx, etcdClient, func(stm col.STM) error {
 		pipelines := pipelinesCollection.ReadWrite(stm)
 		pipelinePtr := new(pps.EtcdPipelineInfo)
 		if err := pipelines.Get(pipelineName, pipelinePtr); err != nil {
 			return err
 		}
-		pipelinePtr.State = pps.PipelineState_PIPELINE_FAILURE
-		pipelinePtr.Reason = reason
+		SetPipelineState(pipelinePtr, pps.PipelineState_PIPELINE_FAILURE, reason)
+		pipelinePtr.ReasonCode = code
 		pipelines.Put(pipelineName, pipelinePtr)
 		return nil
 	})
@@ -223,42 +368,150 @@ func JobInput(pipelineInfo *pps.PipelineInfo, outputCommitInfo *pfs.CommitInfo)
 	return jobInput
 }
 
+// InputCommits returns the commits that the inputs of 'jobInput' (as returned
+// by JobInput) are bound to, in the order in which VisitInput visits them.
+// Inputs that aren't bound to a commit are left out.
+func InputCommits(jobInput *pps.Input) []*pfs.Commit {
+	var result []*pfs.Commit
+	pps.VisitInput(jobInput, func(input *pps.Input) {
+		var repo, commit string
+		switch {
+		case input.Pfs != nil:
+			repo, commit = input.Pfs.Repo, input.Pfs.Commit
+		case input.Cron != nil:
+			repo, commit = input.Cron.Repo, input.Cron.Commit
+		case input.Git != nil:
+			repo, commit = input.Git.Name, input.Git.Commit
+		}
+		if commit != "" {
+			result = append(result, client.NewCommit(repo, commit))
+		}
+	})
+	return result
+}
+
+// InputConsistency checks whether the commits that JobInput binds to the
+// inputs of a job are a single, provenance-consistent snapshot, i.e. whether
+// every branch in 'outputCommitInfo's provenance resolves to a single commit,
+// and every input of 'pipelineInfo' is bound to a commit.
+func InputConsistency(pipelineInfo *pps.PipelineInfo, outputCommitInfo *pfs.CommitInfo) *pps.InputConsistency {
+	// branchToCommits maps strings of the form "<repo>/<branch>" to the
+	// distinct commits of that branch in the output commit's provenance
+	branchToCommits := make(map[string][]*pfs.Commit)
+	var branches []*pfs.Branch
+	key := path.Join
+	for _, prov := range outputCommitInfo.Provenance {
+		k := key(prov.Commit.Repo.Name, prov.Branch.Name)
+		commits, ok := branchToCommits[k]
+		if !ok {
+			branches = append(branches, prov.Branch)
+		}
+		found := false
+		for _, commit := range commits {
+			if commit.ID == prov.Commit.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			branchToCommits[k] = append(commits, prov.Commit)
+		}
+	}
+	result := &pps.InputConsistency{}
+	for _, branch := range branches {
+		commits := branchToCommits[key(branch.Repo.Name, branch.Name)]
+		if len(commits) < 2 {
+			continue
+		}
+		sort.Slice(commits, func(i, j int) bool { return commits[i].ID < commits[j].ID })
+		result.Conflicts = append(result.Conflicts, &pps.InputConflict{
+			Branch:  branch,
+			Commits: commits,
+		})
+	}
+	sort.Slice(result.Conflicts, func(i, j int) bool {
+		return key(result.Conflicts[i].Branch.Repo.Name, result.Conflicts[i].Branch.Name) <
+			key(result.Conflicts[j].Branch.Repo.Name, result.Conflicts[j].Branch.Name)
+	})
+	pps.VisitInput(pipelineInfo.Input, func(input *pps.Input) {
+		var name, branch string
+		switch {
+		case input.Pfs != nil:
+			name, branch = input.Pfs.Name, key(input.Pfs.Repo, input.Pfs.Branch)
+		case input.Cron != nil:
+			name, branch = input.Cron.Name, key(input.Cron.Repo, "master")
+		case input.Git != nil:
+			name, branch = input.Git.Name, key(input.Git.Name, input.Git.Branch)
+		default:
+			return
+		}
+		if len(branchToCommits[branch]) == 0 {
+			result.Unbound = append(result.Unbound, name)
+		}
+	})
+	result.Consistent = len(result.Conflicts) == 0 && len(result.Unbound) == 0
+	return result
+}
+
 // PipelineReqFromInfo converts a PipelineInfo into a CreatePipelineRequest.
 func PipelineReqFromInfo(pipelineInfo *ppsclient.PipelineInfo) *ppsclient.CreatePipelineRequest {
 	return &ppsclient.CreatePipelineRequest{
-		Pipeline:         pipelineInfo.Pipeline,
-		Transform:        pipelineInfo.Transform,
-		ParallelismSpec:  pipelineInfo.ParallelismSpec,
-		HashtreeSpec:     pipelineInfo.HashtreeSpec,
-		Egress:           pipelineInfo.Egress,
-		OutputBranch:     pipelineInfo.OutputBranch,
-		ResourceRequests: pipelineInfo.ResourceRequests,
-		ResourceLimits:   pipelineInfo.ResourceLimits,
-		Input:            pipelineInfo.Input,
-		Description:      pipelineInfo.Description,
-		CacheSize:        pipelineInfo.CacheSize,
-		EnableStats:      pipelineInfo.EnableStats,
-		MaxQueueSize:     pipelineInfo.MaxQueueSize,
-		Service:          pipelineInfo.Service,
-		ChunkSpec:        pipelineInfo.ChunkSpec,
-		DatumTimeout:     pipelineInfo.DatumTimeout,
-		JobTimeout:       pipelineInfo.JobTimeout,
-		Salt:             pipelineInfo.Salt,
-		PodSpec:          pipelineInfo.PodSpec,
-		PodPatch:         pipelineInfo.PodPatch,
-		Spout:            pipelineInfo.Spout,
-		SchedulingSpec:   pipelineInfo.SchedulingSpec,
-		DatumTries:       pipelineInfo.DatumTries,
-		Standby:          pipelineInfo.Standby,
+		Pipeline:           pipelineInfo.Pipeline,
+		Transform:          pipelineInfo.Transform,
+		ParallelismSpec:    pipelineInfo.ParallelismSpec,
+		HashtreeSpec:       pipelineInfo.HashtreeSpec,
+		Egress:             pipelineInfo.Egress,
+		OutputBranch:       pipelineInfo.OutputBranch,
+		ResourceRequests:   pipelineInfo.ResourceRequests,
+		ResourceLimits:     pipelineInfo.ResourceLimits,
+		Input:              pipelineInfo.Input,
+		Description:        pipelineInfo.Description,
+		CacheSize:          pipelineInfo.CacheSize,
+		EnableStats:        pipelineInfo.EnableStats,
+		MaxQueueSize:       pipelineInfo.MaxQueueSize,
+		Service:            pipelineInfo.Service,
+		ChunkSpec:          pipelineInfo.ChunkSpec,
+		DatumTimeout:       pipelineInfo.DatumTimeout,
+		JobTimeout:         pipelineInfo.JobTimeout,
+		Salt:               pipelineInfo.Salt,
+		PodSpec:            pipelineInfo.PodSpec,
+		PodPatch:           pipelineInfo.PodPatch,
+		Spout:              pipelineInfo.Spout,
+		SchedulingSpec:     pipelineInfo.SchedulingSpec,
+		DatumTries:         pipelineInfo.DatumTries,
+		Standby:            pipelineInfo.Standby,
+		Backend:            pipelineInfo.Backend,
+		Spill:              pipelineInfo.Spill,
+		Metadata:           pipelineInfo.Metadata,
+		StandbyGracePeriod: pipelineInfo.StandbyGracePeriod,
+		StandbyWakeAlarm:   pipelineInfo.StandbyWakeAlarm,
+		DowntimeWindows:    pipelineInfo.DowntimeWindows,
+		Budget:             pipelineInfo.Budget,
+		DeterminismCheck:   pipelineInfo.DeterminismCheck,
+		ExperimentTracking: pipelineInfo.ExperimentTracking,
+		NetworkPolicy:      pipelineInfo.NetworkPolicy,
+		EgressProxy:        pipelineInfo.EgressProxy,
+		ScratchVolume:      pipelineInfo.ScratchVolume,
+		StreamOutput:       pipelineInfo.StreamOutput,
+		DatumProfiles:      pipelineInfo.DatumProfiles,
+		TimeoutPolicy:      pipelineInfo.TimeoutPolicy,
+		DatumRetry:         pipelineInfo.DatumRetry,
+		DatumOrder:         pipelineInfo.DatumOrder,
+		S3Out:              pipelineInfo.S3Out,
+		CloudCredentials:   pipelineInfo.CloudCredentials,
+		MaxConcurrentJobs:  pipelineInfo.MaxConcurrentJobs,
+		EmptyJobPolicy:     pipelineInfo.EmptyJobPolicy,
+		DatumCache:         pipelineInfo.DatumCache,
 	}
 }
 
 // IsTerminal returns 'true' if 'state' indicates that the job is done (i.e.
-// the state will not change later: SUCCESS, FAILURE, KILLED) and 'false'
-// otherwise.
+// the state will not change later: SUCCESS, FAILURE, KILLED, PARTIAL_SUCCESS,
+// SKIPPED) and 'false' otherwise.
 func IsTerminal(state pps.JobState) bool {
 	switch state {
-	case pps.JobState_JOB_SUCCESS, pps.JobState_JOB_FAILURE, pps.JobState_JOB_KILLED:
+	case pps.JobState_JOB_SUCCESS, pps.JobState_JOB_FAILURE, pps.JobState_JOB_KILLED, pps.JobState_JOB_PARTIAL_SUCCESS,
+		pps.JobState_JOB_SKIPPED:
 		return true
 	case pps.JobState_JOB_STARTING, pps.JobState_JOB_RUNNING, pps.JobState_JOB_MERGING:
 		return false
@@ -285,7 +538,22 @@ func UpdateJobState(pipelines col.ReadWriteCollection, jobs col.ReadWriteCollect
 		pipelinePtr.JobCounts[int32(jobPtr.State)]--
 	}
 	pipelinePtr.JobCounts[int32(state)]++
-	pipelinePtr.LastJobState = state
+	if jobPtr.ReplayOf == nil {
+		// Replays (see pps.ReplayJob) don't reflect on the pipeline's state
+		pipelinePtr.LastJobState = state
+	}
+	now := time.Now()
+	if IsTerminal(state) && !IsTerminal(jobPtr.State) {
+		// Charge the job's cost to the pipeline's budget
+		cost, err := JobCost(pipelinePtr, jobPtr, now)
+		if err != nil {
+			return err
+		}
+		if pipelinePtr.Budget != nil {
+			jobPtr.EstimatedCost = cost
+			AddBudgetSpend(pipelinePtr, cost, now)
+		}
+	}
 	if err := pipelines.Put(jobPtr.Pipeline.Name, pipelinePtr); err != nil {
 		return err
 	}
@@ -293,13 +561,14 @@ func UpdateJobState(pipelines col.ReadWriteCollection, jobs col.ReadWriteCollect
 	// Update job info
 	var err error
 	if state == pps.JobState_JOB_STARTING {
-		jobPtr.Started, err = types.TimestampProto(time.Now())
+		jobPtr.Started, err = types.TimestampProto(now)
 	} else if IsTerminal(state) {
-		jobPtr.Finished, err = types.TimestampProto(time.Now())
+		jobPtr.Finished, err = types.TimestampProto(now)
 	}
 	if err != nil {
 		return err
 	}
+	jobPtr.StartedIndex = ppsdb.StartedIndexValue(jobPtr.Started)
 	jobPtr.State = state
 	jobPtr.Reason = reason
 	return jobs.Put(jobPtr.Job.ID, jobPtr)
@@ -0,0 +1,141 @@
+package ppsutil
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestInputConsistency(t *testing.T) {
+	pipelineInfo := &pps.PipelineInfo{
+		Input: client.NewCrossInput(
+			client.NewPFSInputOpts("images", "images", "master", "/*", "", false),
+			client.NewPFSInputOpts("model", "model", "master", "/", "", false),
+		),
+	}
+
+	// The images commit, and the images commit that the model was trained on,
+	// are the same
+	outputCommitInfo := &pfs.CommitInfo{
+		Provenance: []*pfs.CommitProvenance{
+			client.NewCommitProvenance("images", "master", "a"),
+			client.NewCommitProvenance("model", "master", "b"),
+			client.NewCommitProvenance("images", "master", "a"),
+		},
+	}
+	consistency := InputConsistency(pipelineInfo, outputCommitInfo)
+	require.True(t, consistency.Consistent)
+	require.Equal(t, 0, len(consistency.Conflicts))
+	require.Equal(t, 0, len(consistency.Unbound))
+
+	// The model was trained on an older images commit
+	outputCommitInfo.Provenance = append(outputCommitInfo.Provenance,
+		client.NewCommitProvenance("images", "master", "0"))
+	consistency = InputConsistency(pipelineInfo, outputCommitInfo)
+	require.False(t, consistency.Consistent)
+	require.Equal(t, 1, len(consistency.Conflicts))
+	require.Equal(t, "images", consistency.Conflicts[0].Branch.Repo.Name)
+	require.Equal(t, "master", consistency.Conflicts[0].Branch.Name)
+	require.Equal(t, 2, len(consistency.Conflicts[0].Commits))
+	require.Equal(t, "0", consistency.Conflicts[0].Commits[0].ID)
+	require.Equal(t, "a", consistency.Conflicts[0].Commits[1].ID)
+
+	// The model branch has no commits
+	outputCommitInfo.Provenance = outputCommitInfo.Provenance[:1]
+	consistency = InputConsistency(pipelineInfo, outputCommitInfo)
+	require.False(t, consistency.Consistent)
+	require.Equal(t, 0, len(consistency.Conflicts))
+	require.Equal(t, []string{"model"}, consistency.Unbound)
+}
+
+func TestInputCommits(t *testing.T) {
+	jobInput := client.NewCrossInput(
+		client.NewPFSInput("images", "/*"),
+		client.NewUnionInput(
+			client.NewPFSInput("model", "/"),
+			client.NewCronInput("tick", "@every 1m"),
+		),
+	)
+	jobInput.Cross[0].Pfs.Commit = "a"
+	// Cron inputs' repos are filled in by CreatePipeline
+	jobInput.Cross[1].Union[1].Cron.Repo = "tick"
+	jobInput.Cross[1].Union[1].Cron.Commit = "c"
+	// The model input is unbound, so it's left out
+	var commits []string
+	for _, commit := range InputCommits(jobInput) {
+		commits = append(commits, commit.Repo.Name+"@"+commit.ID)
+	}
+	require.Equal(t, []string{"images@a", "tick@c"}, commits)
+	require.Equal(t, 0, len(InputCommits(nil)))
+}
+
+func TestGPUUnits(t *testing.T) {
+	require.Equal(t, int64(2), GPUUnits(&pps.GPUSpec{Type: "nvidia.com/gpu", Number: 2}))
+	require.Equal(t, int64(2), GPUUnits(&pps.GPUSpec{Type: "nvidia.com/gpu.shared", Fraction: 0.5, SharesPerGpu: 4}))
+	require.Equal(t, int64(2), GPUUnits(&pps.GPUSpec{Type: "nvidia.com/gpu.shared", Fraction: 0.3, SharesPerGpu: 4}))
+	require.Equal(t, int64(1), GPUUnits(&pps.GPUSpec{Type: "nvidia.com/gpu.shared", Fraction: 0.1, SharesPerGpu: 4}))
+
+	requests, err := GetRequestsResourceListFromPipeline(&pps.PipelineInfo{
+		ResourceRequests: &pps.ResourceSpec{
+			Memory: "1G",
+			Gpu:    &pps.GPUSpec{Type: "nvidia.com/gpu.shared", Fraction: 0.25, SharesPerGpu: 8},
+		},
+	})
+	require.NoError(t, err)
+	gpus := (*requests)["nvidia.com/gpu.shared"]
+	require.Equal(t, "2", gpus.String())
+}
+
+func TestPipelineRcName(t *testing.T) {
+	require.Equal(t, "pipeline-edges-f394aa79-v1", PipelineRcName("edges", 1))
+	// Names that sanitize to the same k8s name don't collide
+	require.NotEqual(t, PipelineRcName("My_Pipe", 1), PipelineRcName("my-pipe", 1))
+	require.NotEqual(t, PipelineResourceName("My_Pipe"), PipelineResourceName("my-pipe"))
+	require.NotEqual(t, PipelineRcName("edges", 1), PipelineRcName("edges", 2))
+
+	// Long names are truncated so that the RC's services are valid k8s names,
+	// without colliding
+	long := strings.Repeat("a", 63)
+	for _, version := range []uint64{1, math.MaxUint64} {
+		rcName := PipelineRcName(long, version)
+		require.True(t, len(rcName+"-user") <= 63)
+		require.NotEqual(t, rcName, PipelineRcName(long[:62], version))
+	}
+	require.True(t, len(PipelineResourceName(long)) <= 63)
+	profile := strings.Repeat("p", MaxDatumProfileNameLen)
+	require.True(t, len(DatumProfileRcName(long, math.MaxUint64, profile)) <= 63)
+	require.Equal(t, "pipeline-edges-f394aa79-v1-large", DatumProfileRcName("edges", 1, "large"))
+	require.Equal(t, PipelineRcName("edges", 1), WorkerPoolRcName("edges", 1, 1))
+	require.Equal(t, "pipeline-edges-f394aa79-v1-pool2", WorkerPoolRcName("edges", 1, 2))
+	require.True(t, len(WorkerPoolRcName(long, math.MaxUint64, 100)) <= 63)
+	require.NotEqual(t, PipelineResourceName(long), PipelineResourceName(long[:62]))
+}
+
+func TestSetPipelineState(t *testing.T) {
+	pipelinePtr := &pps.EtcdPipelineInfo{}
+	SetPipelineState(pipelinePtr, pps.PipelineState_PIPELINE_STARTING, "")
+	require.Equal(t, 1, len(pipelinePtr.StateHistory))
+	SetPipelineState(pipelinePtr, pps.PipelineState_PIPELINE_RUNNING, "")
+	SetPipelineState(pipelinePtr, pps.PipelineState_PIPELINE_RUNNING, "still running")
+	SetPipelineState(pipelinePtr, pps.PipelineState_PIPELINE_FAILURE, "image pull failed")
+	require.Equal(t, pps.PipelineState_PIPELINE_FAILURE, pipelinePtr.State)
+	require.Equal(t, "image pull failed", pipelinePtr.Reason)
+	require.Equal(t, 3, len(pipelinePtr.StateHistory))
+	last := pipelinePtr.StateHistory[2]
+	require.Equal(t, pps.PipelineState_PIPELINE_RUNNING, last.PreviousState)
+	require.Equal(t, pps.PipelineState_PIPELINE_FAILURE, last.State)
+	require.Equal(t, "image pull failed", last.Reason)
+	require.NotNil(t, last.Time)
+
+	for i := 0; i < MaxPipelineStateHistory; i++ {
+		SetPipelineState(pipelinePtr, pps.PipelineState_PIPELINE_RUNNING, "")
+		SetPipelineState(pipelinePtr, pps.PipelineState_PIPELINE_STANDBY, "")
+	}
+	require.Equal(t, MaxPipelineStateHistory, len(pipelinePtr.StateHistory))
+	require.Equal(t, pps.PipelineState_PIPELINE_STANDBY, pipelinePtr.StateHistory[MaxPipelineStateHistory-1].State)
+}
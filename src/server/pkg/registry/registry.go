@@ -0,0 +1,365 @@
+// Package registry resolves docker image tags to content digests by querying
+// the image's registry (using the Docker Registry HTTP API V2), so that
+// pipelines can be pinned to the exact image they were created with.
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// defaultDomain is the registry that images without a domain are pulled
+	// from
+	defaultDomain = "docker.io"
+	// defaultAPIHost is the host that serves defaultDomain's registry API
+	defaultAPIHost = "registry-1.docker.io"
+	// digestHeader is the response header that contains a manifest's digest
+	digestHeader = "Docker-Content-Digest"
+)
+
+// manifestTypes are the manifest media types that we accept. Manifest lists
+// (multi-arch images) are preferred so that the digest works on any node.
+var manifestTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+// Reference is a parsed docker image reference, e.g.
+// "quay.io/org/image:tag@sha256:abc..."
+type Reference struct {
+	// Domain is the image's registry, e.g. "docker.io"
+	Domain string
+	// Path is the image's repository within the registry, e.g. "library/ubuntu"
+	Path string
+	// Tag is the image's tag, if any
+	Tag string
+	// Digest is the image's digest, if any
+	Digest string
+}
+
+// ParseReference parses a docker image reference.
+func ParseReference(image string) (*Reference, error) {
+	if image == "" {
+		return nil, fmt.Errorf("image cannot be empty")
+	}
+	result := &Reference{}
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, result.Digest = name[:i], name[i+1:]
+		if !strings.Contains(result.Digest, ":") {
+			return nil, fmt.Errorf("invalid digest in image %q", image)
+		}
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, result.Tag = name[:i], name[i+1:]
+		if result.Tag == "" {
+			return nil, fmt.Errorf("invalid tag in image %q", image)
+		}
+	}
+	// The first component of the name is a registry domain if it looks like
+	// a host name
+	if i := strings.Index(name, "/"); i >= 0 &&
+		(strings.ContainsAny(name[:i], ".:") || name[:i] == "localhost") {
+		result.Domain, result.Path = name[:i], name[i+1:]
+	} else {
+		result.Domain, result.Path = defaultDomain, name
+	}
+	if result.Domain == defaultDomain && !strings.Contains(result.Path, "/") {
+		result.Path = "library/" + result.Path
+	}
+	if result.Path == "" {
+		return nil, fmt.Errorf("invalid image %q", image)
+	}
+	return result, nil
+}
+
+// String returns the reference in the canonical form accepted by docker.
+func (r *Reference) String() string {
+	result := r.Domain + "/" + r.Path
+	if r.Tag != "" {
+		result += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		result += "@" + r.Digest
+	}
+	return result
+}
+
+// IsFloating returns true if 'r' refers to an image by a tag that
+// conventionally moves (no tag, or "latest") rather than by digest.
+func (r *Reference) IsFloating() bool {
+	return r.Digest == "" && (r.Tag == "" || r.Tag == "latest")
+}
+
+// Credential is a username and password for a registry.
+type Credential struct {
+	Username string
+	Password string
+}
+
+// ParseDockerConfig parses the contents of a docker config file (or a
+// kubernetes image pull secret) and returns the credentials that it contains,
+// keyed by registry domain.
+func ParseDockerConfig(data []byte) (map[string]Credential, error) {
+	var config struct {
+		Auths map[string]struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			Auth     string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("could not parse docker config: %v", err)
+	}
+	result := make(map[string]Credential)
+	for server, auth := range config.Auths {
+		cred := Credential{Username: auth.Username, Password: auth.Password}
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("could not decode credentials for %s: %v", server, err)
+			}
+			parts := strings.SplitN(string(decoded), ":", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("malformed credentials for %s", server)
+			}
+			cred.Username, cred.Password = parts[0], parts[1]
+		}
+		result[configDomain(server)] = cred
+	}
+	return result, nil
+}
+
+// DockerConfig returns the contents of a docker config file (the
+// ".dockerconfigjson" key of a kubernetes image pull secret) containing a
+// single set of credentials for 'server'.
+func DockerConfig(server, username, password, email string) ([]byte, error) {
+	type auth struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Email    string `json:"email,omitempty"`
+		Auth     string `json:"auth"`
+	}
+	config := struct {
+		Auths map[string]auth `json:"auths"`
+	}{
+		Auths: map[string]auth{
+			server: {
+				Username: username,
+				Password: password,
+				Email:    email,
+				Auth:     base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+			},
+		},
+	}
+	return json.Marshal(config)
+}
+
+// configDomain normalizes a server in a docker config file (which may be a
+// URL, e.g. "https://index.docker.io/v1/") to a registry domain.
+func configDomain(server string) string {
+	if u, err := url.Parse(server); err == nil && u.Host != "" {
+		server = u.Host
+	}
+	server = strings.TrimSuffix(server, "/")
+	switch server {
+	case "index.docker.io", defaultAPIHost:
+		return defaultDomain
+	}
+	return server
+}
+
+// Resolver resolves image tags to digests.
+type Resolver struct {
+	client *http.Client
+	// scheme is the URL scheme used to talk to registries ("https", except in
+	// tests)
+	scheme string
+}
+
+// NewResolver returns a Resolver that uses http.DefaultClient.
+func NewResolver() *Resolver {
+	return &Resolver{client: http.DefaultClient, scheme: "https"}
+}
+
+// Digest returns the digest of the image that 'ref' currently refers to. If
+// 'ref' already has a digest, it's returned as is. 'creds' holds the
+// credentials to use for each registry domain, and may be nil.
+func (r *Resolver) Digest(ctx context.Context, ref *Reference, creds map[string]Credential) (string, error) {
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+	resp, err := r.manifest(ctx, ref, creds)
+	if err != nil {
+		return "", err
+	}
+	digest := resp.Header.Get(digestHeader)
+	if digest == "" {
+		return "", fmt.Errorf("registry %s did not return a digest for %s", ref.Domain, ref)
+	}
+	return digest, nil
+}
+
+// Check returns an error if the image that 'ref' refers to can't be pulled
+// with 'creds', e.g. because it doesn't exist or the credentials are wrong.
+// Unlike Digest, it queries the registry even if 'ref' has a digest.
+func (r *Resolver) Check(ctx context.Context, ref *Reference, creds map[string]Credential) error {
+	_, err := r.manifest(ctx, ref, creds)
+	return err
+}
+
+// manifest requests the manifest of the image that 'ref' refers to (by
+// digest, if it has one, or else by tag), authenticating with 'creds' if the
+// registry requires it.
+func (r *Resolver) manifest(ctx context.Context, ref *Reference, creds map[string]Credential) (*http.Response, error) {
+	host := ref.Domain
+	if host == defaultDomain {
+		host = defaultAPIHost
+	}
+	reference := ref.Digest
+	if reference == "" {
+		reference = ref.Tag
+	}
+	if reference == "" {
+		reference = "latest"
+	}
+	manifestURL := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", r.scheme, host, ref.Path, reference)
+	cred, hasCred := creds[ref.Domain]
+
+	resp, err := r.headManifest(ctx, manifestURL, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		authorization, err := r.authorize(ctx, resp.Header.Get("WWW-Authenticate"), cred, hasCred)
+		if err != nil {
+			return nil, fmt.Errorf("could not authenticate with registry %s: %v", ref.Domain, err)
+		}
+		resp, err = r.headManifest(ctx, manifestURL, authorization)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not get manifest for %s: %s", ref, resp.Status)
+	}
+	return resp, nil
+}
+
+func (r *Resolver) headManifest(ctx context.Context, manifestURL string, authorization string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", strings.Join(manifestTypes, ", "))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// authorize answers a registry's authentication challenge, returning the
+// value of the Authorization header to retry the request with.
+func (r *Resolver) authorize(ctx context.Context, challenge string, cred Credential, hasCred bool) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if !hasCred {
+			return "", fmt.Errorf("registry requires credentials")
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(cred.Username+":"+cred.Password)), nil
+	case "bearer":
+		realm, ok := params["realm"]
+		if !ok {
+			return "", fmt.Errorf("bearer challenge has no realm")
+		}
+		tokenURL, err := url.Parse(realm)
+		if err != nil {
+			return "", err
+		}
+		q := tokenURL.Query()
+		for _, key := range []string{"service", "scope"} {
+			if v, ok := params[key]; ok {
+				q.Set(key, v)
+			}
+		}
+		tokenURL.RawQuery = q.Encode()
+		req, err := http.NewRequest(http.MethodGet, tokenURL.String(), nil)
+		if err != nil {
+			return "", err
+		}
+		req = req.WithContext(ctx)
+		if hasCred {
+			req.SetBasicAuth(cred.Username, cred.Password)
+		}
+		resp, err := r.client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("token request failed: %s", resp.Status)
+		}
+		var token struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+			return "", fmt.Errorf("could not parse token: %v", err)
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		return "Bearer " + token.Token, nil
+	}
+	return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+}
+
+// parseChallenge parses a WWW-Authenticate header, e.g.
+// `Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := make(map[string]string)
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	if len(parts) < 2 {
+		return parts[0], params
+	}
+	rest := parts[1]
+	for rest != "" {
+		eq := strings.Index(rest, "=")
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(rest[:eq]))
+		rest = rest[eq+1:]
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else if comma := strings.Index(rest, ","); comma >= 0 {
+			value, rest = rest[:comma], rest[comma:]
+		} else {
+			value, rest = rest, ""
+		}
+		params[key] = value
+		rest = strings.TrimLeft(rest, ", ")
+	}
+	return parts[0], params
+}
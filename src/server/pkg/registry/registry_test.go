@@ -0,0 +1,115 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestParseReference(t *testing.T) {
+	for image, expected := range map[string]string{
+		"ubuntu":                            "docker.io/library/ubuntu",
+		"ubuntu:18.04":                      "docker.io/library/ubuntu:18.04",
+		"pachyderm/opencv:1.0":              "docker.io/pachyderm/opencv:1.0",
+		"quay.io/org/image@sha256:abc":      "quay.io/org/image@sha256:abc",
+		"localhost:5000/image:v1":           "localhost:5000/image:v1",
+		"gcr.io/proj/image:v1@sha256:abc12": "gcr.io/proj/image:v1@sha256:abc12",
+	} {
+		ref, err := ParseReference(image)
+		require.NoError(t, err, "image: %s", image)
+		require.Equal(t, expected, ref.String())
+	}
+	for _, image := range []string{"", "ubuntu:", "ubuntu@abc"} {
+		_, err := ParseReference(image)
+		require.YesError(t, err, "image: %s", image)
+	}
+
+	floating := map[string]bool{
+		"ubuntu":            true,
+		"ubuntu:latest":     true,
+		"ubuntu:18.04":      false,
+		"ubuntu@sha256:abc": false,
+	}
+	for image, expected := range floating {
+		ref, err := ParseReference(image)
+		require.NoError(t, err)
+		require.Equal(t, expected, ref.IsFloating(), "image: %s", image)
+	}
+}
+
+func TestParseDockerConfig(t *testing.T) {
+	creds, err := ParseDockerConfig([]byte(`{"auths": {
+		"https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNz"},
+		"quay.io": {"username": "robot", "password": "secret"}
+	}}`))
+	require.NoError(t, err)
+	require.Equal(t, Credential{Username: "user", Password: "pass"}, creds["docker.io"])
+	require.Equal(t, Credential{Username: "robot", Password: "secret"}, creds["quay.io"])
+
+	data, err := DockerConfig("https://index.docker.io/v1/", "user", "p:ss", "")
+	require.NoError(t, err)
+	creds, err = ParseDockerConfig(data)
+	require.NoError(t, err)
+	require.Equal(t, Credential{Username: "user", Password: "p:ss"}, creds["docker.io"])
+}
+
+func TestDigest(t *testing.T) {
+	const digest = "sha256:0123456789abcdef"
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != "user" || pass != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			require.Equal(t, "repository:org/image:pull", r.URL.Query().Get("scope"))
+			fmt.Fprint(w, `{"token": "t0ken"}`)
+		case r.URL.Path == "/v2/org/image/manifests/v1" || r.URL.Path == "/v2/org/image/manifests/"+digest:
+			if r.Header.Get("Authorization") != "Bearer t0ken" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+					`Bearer realm="%s/token",service="test",scope="repository:org/image:pull"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			require.True(t, strings.Contains(r.Header.Get("Accept"), "manifest.list.v2"))
+			w.Header().Set(digestHeader, digest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	domain := strings.TrimPrefix(server.URL, "http://")
+	resolver := &Resolver{client: server.Client(), scheme: "http"}
+
+	ref, err := ParseReference(domain + "/org/image:v1")
+	require.NoError(t, err)
+	result, err := resolver.Digest(context.Background(), ref, map[string]Credential{
+		domain: {Username: "user", Password: "pass"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, digest, result)
+
+	// Without credentials, the token request fails
+	_, err = resolver.Digest(context.Background(), ref, nil)
+	require.YesError(t, err)
+
+	// Unknown tags fail
+	ref.Tag = "v2"
+	_, err = resolver.Digest(context.Background(), ref, nil)
+	require.YesError(t, err)
+	require.YesError(t, resolver.Check(context.Background(), ref, nil))
+
+	// Check queries the registry for images that are referenced by digest
+	ref.Tag, ref.Digest = "", digest
+	require.NoError(t, resolver.Check(context.Background(), ref, map[string]Credential{
+		domain: {Username: "user", Password: "pass"},
+	}))
+	require.YesError(t, resolver.Check(context.Background(), ref, nil))
+}
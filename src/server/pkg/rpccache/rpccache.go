@@ -0,0 +1,425 @@
+// Package rpccache implements a pull-through cache for the pachd RPCs that
+// every worker of a pipeline sends at the start of a job (InspectCommit, and
+// GetFile of files that all of them read, like a datum order's priority
+// file). Workers install it in their pachd client with
+// client.WithInterceptors, so that repeated and concurrent reads of the same
+// finished commits are served from the worker's memory rather than by pachd.
+//
+// Only responses that can't change are cached: InspectCommit of a finished
+// commit that's requested by ID (rather than by branch), and GetFile of a
+// file in such a commit. Responses are cached per auth token, so a worker
+// never sees a response that was returned to a different user.
+package rpccache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+)
+
+const (
+	inspectCommitMethod = "/pfs.API/InspectCommit"
+	getFileMethod       = "/pfs.API/GetFile"
+
+	// commitInfoTTL is how long a finished commit's CommitInfo is cached for.
+	// Most of a finished commit's info can't change, but its subvenance and
+	// child commits can, and it can be deleted, so the cache only absorbs the
+	// burst of identical requests at the start of a job.
+	commitInfoTTL = time.Minute
+)
+
+var (
+	cacheCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pachyderm",
+			Subsystem: "rpc_cache",
+			Name:      "count",
+			Help:      "Number of pachd RPCs that were sent through the cache, by method and whether they were served from it",
+		},
+		[]string{"method", "result"},
+	)
+	registerOnce sync.Once
+)
+
+func registerMetrics() {
+	registerOnce.Do(func() {
+		if err := prometheus.Register(cacheCount); err != nil {
+			// metrics may be redundantly registered; ignore these errors
+			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+				cacheCount = nil
+			}
+		}
+	})
+}
+
+func observe(method string, hit bool) {
+	if cacheCount == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheCount.WithLabelValues(method, result).Inc()
+}
+
+type entry struct {
+	key     string
+	data    []byte
+	expires time.Time // zero if the entry never expires
+}
+
+// fetch is an in-progress request for an uncached response, which concurrent
+// identical requests wait for rather than sending to pachd themselves
+type fetch struct {
+	done chan struct{}
+	data []byte
+	ok   bool // whether 'data' is a cacheable response
+}
+
+// Cache is an LRU cache of serialized pachd responses, which holds at most
+// 'size' bytes
+type Cache struct {
+	size int64
+
+	mu       sync.Mutex
+	used     int64
+	lru      *list.List // of *entry, most recently used first
+	entries  map[string]*list.Element
+	fetching map[string]*fetch
+}
+
+// New returns a Cache that holds at most 'size' bytes of responses. Responses
+// bigger than a quarter of 'size' aren't cached, so that a single large file
+// can't evict everything else.
+func New(size int64) *Cache {
+	registerMetrics()
+	return &Cache{
+		size:     size,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+		fetching: make(map[string]*fetch),
+	}
+}
+
+func (c *Cache) maxEntrySize() int64 {
+	return c.size / 4
+}
+
+// cached returns the cached response for 'key', if there is one
+func (c *Cache) cached(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cachedLocked(key)
+}
+
+func (c *Cache) cachedLocked(key string) ([]byte, bool) {
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	ent := e.Value.(*entry)
+	if !ent.expires.IsZero() && !time.Now().Before(ent.expires) {
+		c.remove(e)
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return ent.data, true
+}
+
+// add caches 'data' as the response for 'key' for 'ttl' (or until it's
+// evicted, if 'ttl' is 0)
+func (c *Cache) add(key string, data []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addLocked(key, data, ttl)
+}
+
+func (c *Cache) addLocked(key string, data []byte, ttl time.Duration) {
+	if int64(len(data)) > c.maxEntrySize() {
+		return
+	}
+	ent := &entry{key: key, data: data}
+	if ttl > 0 {
+		ent.expires = time.Now().Add(ttl)
+	}
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	c.entries[key] = c.lru.PushFront(ent)
+	c.used += int64(len(data))
+	for c.used > c.size {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *Cache) remove(e *list.Element) {
+	ent := c.lru.Remove(e).(*entry)
+	delete(c.entries, ent.key)
+	c.used -= int64(len(ent.data))
+}
+
+// get returns the cached response for 'key', waiting for an in-progress
+// request for it if there is one. If the response isn't cached, get returns
+// a fetch, and the caller must send the request itself and then complete the
+// fetch with finish.
+func (c *Cache) get(ctx context.Context, key string) ([]byte, *fetch, error) {
+	for {
+		c.mu.Lock()
+		if data, ok := c.cachedLocked(key); ok {
+			c.mu.Unlock()
+			return data, nil, nil
+		}
+		f, ok := c.fetching[key]
+		if !ok {
+			f = &fetch{done: make(chan struct{})}
+			c.fetching[key] = f
+			c.mu.Unlock()
+			return nil, f, nil
+		}
+		c.mu.Unlock()
+		select {
+		case <-f.done:
+			if f.ok {
+				return f.data, nil, nil
+			}
+			// The request failed or its response can't be cached, so send
+			// this one (or wait for another request that's sending it)
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+}
+
+// finish completes the fetch of 'key', caching 'data' if 'ok' is set
+func (c *Cache) finish(key string, f *fetch, data []byte, ok bool, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.fetching, key)
+	f.data, f.ok = data, ok
+	close(f.done)
+	if ok {
+		c.addLocked(key, data, ttl)
+	}
+}
+
+// authToken returns the auth token that RPCs sent with 'ctx' are
+// authenticated with, which is part of every cache key
+func authToken(ctx context.Context) string {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok || len(md[auth.ContextTokenKey]) == 0 {
+		return ""
+	}
+	return md[auth.ContextTokenKey][0]
+}
+
+// commitKey is the cache key of InspectCommit requests for 'commit'
+func commitKey(ctx context.Context, commit *pfs.Commit) string {
+	return inspectCommitMethod + "\x00" + authToken(ctx) + "\x00" + commit.Repo.Name + "@" + commit.ID
+}
+
+// finishedCommit returns whether 'commitInfo' is the info of a finished
+// commit that was requested by its ID
+func finishedCommit(commit *pfs.Commit, commitInfo *pfs.CommitInfo) bool {
+	return commitInfo.Finished != nil && commitInfo.Commit != nil && commitInfo.Commit.ID == commit.ID
+}
+
+// UnaryClientInterceptor returns an interceptor that serves InspectCommit
+// requests for finished commits from the cache
+func (c *Cache) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		request, ok := req.(*pfs.InspectCommitRequest)
+		if method != inspectCommitMethod || !ok || request.Commit == nil || request.Commit.Repo == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		key := commitKey(ctx, request.Commit)
+		data, f, err := c.get(ctx, key)
+		if err != nil {
+			return err
+		}
+		observe(method, f == nil)
+		if f == nil {
+			return proto.Unmarshal(data, reply.(proto.Message))
+		}
+		if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+			c.finish(key, f, nil, false, 0)
+			return err
+		}
+		if !finishedCommit(request.Commit, reply.(*pfs.CommitInfo)) {
+			c.finish(key, f, nil, false, 0)
+			return nil
+		}
+		data, err = proto.Marshal(reply.(proto.Message))
+		c.finish(key, f, data, err == nil, commitInfoTTL)
+		return nil
+	}
+}
+
+// StreamClientInterceptor returns an interceptor that serves GetFile requests
+// for files in finished commits from the cache
+func (c *Cache) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if method != getFileMethod {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		// GetFile's request is only sent after the stream is created, so the
+		// stream isn't opened until then
+		return &getFileStream{
+			ctx:      ctx,
+			cache:    c,
+			desc:     desc,
+			cc:       cc,
+			method:   method,
+			streamer: streamer,
+			opts:     opts,
+		}, nil
+	}
+}
+
+// getFileStream is a GetFile stream that reads the file from the cache if
+// it's there, and otherwise from pachd, caching it if it's in a finished
+// commit. Unlike InspectCommit, concurrent reads of an uncached file aren't
+// combined, as a reader may stop reading the file before it's finished.
+type getFileStream struct {
+	ctx      context.Context
+	cache    *Cache
+	desc     *grpc.StreamDesc
+	cc       *grpc.ClientConn
+	method   string
+	streamer grpc.Streamer
+	opts     []grpc.CallOption
+
+	// cached is the remainder of the file if it was read from the cache
+	cached []byte
+	hit    bool
+
+	// stream is the stream to pachd if the file wasn't in the cache
+	stream grpc.ClientStream
+	// key is the file's cache key, and buf is the part of it that's been
+	// read from 'stream', while the file is being cached
+	key string
+	buf []byte
+}
+
+func (s *getFileStream) SendMsg(m interface{}) error {
+	if s.hit || s.stream != nil {
+		return fmt.Errorf("GetFile request was already sent")
+	}
+	if request, ok := m.(*pfs.GetFileRequest); ok {
+		s.lookup(request)
+		if s.hit {
+			return nil
+		}
+	}
+	stream, err := s.streamer(s.ctx, s.desc, s.cc, s.method, s.opts...)
+	if err != nil {
+		return err
+	}
+	s.stream = stream
+	return stream.SendMsg(m)
+}
+
+// lookup checks whether 'request's file is in the cache, and if it isn't but
+// could be (because it's in a finished commit), sets the stream's key so
+// that it's cached once it's been read
+func (s *getFileStream) lookup(request *pfs.GetFileRequest) {
+	if request.File == nil || request.File.Commit == nil || request.File.Commit.Repo == nil {
+		return
+	}
+	commitInfo, err := pfs.NewAPIClient(s.cc).InspectCommit(s.ctx, &pfs.InspectCommitRequest{
+		Commit: request.File.Commit,
+	})
+	if err != nil || !finishedCommit(request.File.Commit, commitInfo) {
+		// Let pachd return the error, if there is one
+		return
+	}
+	requestBytes, err := proto.Marshal(request)
+	if err != nil {
+		return
+	}
+	key := getFileMethod + "\x00" + authToken(s.ctx) + "\x00" + string(requestBytes)
+	data, ok := s.cache.cached(key)
+	observe(getFileMethod, ok)
+	if ok {
+		s.cached, s.hit = data, true
+		return
+	}
+	s.key = key
+}
+
+func (s *getFileStream) RecvMsg(m interface{}) error {
+	if s.hit {
+		if len(s.cached) == 0 {
+			return io.EOF
+		}
+		n := len(s.cached)
+		if n > grpcutil.MaxMsgSize/2 {
+			n = grpcutil.MaxMsgSize / 2
+		}
+		m.(*types.BytesValue).Value = s.cached[:n]
+		s.cached = s.cached[n:]
+		return nil
+	}
+	if s.stream == nil {
+		return io.EOF
+	}
+	err := s.stream.RecvMsg(m)
+	if s.key == "" {
+		return err
+	}
+	switch {
+	case err == io.EOF:
+		s.cache.add(s.key, s.buf, 0)
+		s.key, s.buf = "", nil
+	case err != nil:
+		s.key, s.buf = "", nil
+	default:
+		s.buf = append(s.buf, m.(*types.BytesValue).Value...)
+		if int64(len(s.buf)) > s.cache.maxEntrySize() {
+			// The file is too big to cache
+			s.key, s.buf = "", nil
+		}
+	}
+	return err
+}
+
+func (s *getFileStream) Header() (metadata.MD, error) {
+	if s.stream == nil {
+		return metadata.MD{}, nil
+	}
+	return s.stream.Header()
+}
+
+func (s *getFileStream) Trailer() metadata.MD {
+	if s.stream == nil {
+		return metadata.MD{}
+	}
+	return s.stream.Trailer()
+}
+
+func (s *getFileStream) CloseSend() error {
+	if s.stream == nil {
+		return nil
+	}
+	return s.stream.CloseSend()
+}
+
+func (s *getFileStream) Context() context.Context {
+	if s.stream == nil {
+		return s.ctx
+	}
+	return s.stream.Context()
+}
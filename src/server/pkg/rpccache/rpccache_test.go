@@ -0,0 +1,134 @@
+package rpccache
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+// fakePFS serves commit "finished" (which master points at) and commit
+// "open" in repo "in", each of which has a file "/file" whose content is
+// the commit's ID, and counts the requests it serves
+type fakePFS struct {
+	pfs.APIServer
+	inspects int64
+	gets     int64
+}
+
+func (s *fakePFS) InspectCommit(ctx context.Context, request *pfs.InspectCommitRequest) (*pfs.CommitInfo, error) {
+	atomic.AddInt64(&s.inspects, 1)
+	id := request.Commit.ID
+	if id == "master" {
+		id = "finished"
+	}
+	commitInfo := &pfs.CommitInfo{Commit: client.NewCommit(request.Commit.Repo.Name, id)}
+	switch id {
+	case "finished":
+		commitInfo.Finished = types.TimestampNow()
+	case "open":
+	default:
+		return nil, fmt.Errorf("commit %s not found", id)
+	}
+	return commitInfo, nil
+}
+
+func (s *fakePFS) GetFile(request *pfs.GetFileRequest, server pfs.API_GetFileServer) error {
+	atomic.AddInt64(&s.gets, 1)
+	// Send the file in two messages, to check that they're reassembled
+	content := []byte(request.File.Commit.ID)
+	for _, part := range [][]byte{content[:2], content[2:]} {
+		if err := server.Send(&types.BytesValue{Value: part}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newTestClient returns a client of a fakePFS that caches its responses in a
+// Cache of 'size' bytes, and a function that stops them
+func newTestClient(t *testing.T, size int64) (*client.APIClient, *fakePFS, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	fake := &fakePFS{}
+	pfs.RegisterAPIServer(server, fake)
+	go server.Serve(lis)
+
+	cache := New(size)
+	c, err := client.NewFromAddress(lis.Addr().String(),
+		client.WithInterceptors(cache.UnaryClientInterceptor(), cache.StreamClientInterceptor()),
+		client.WithDialTimeout(10*time.Second))
+	require.NoError(t, err)
+	return c, fake, func() {
+		c.Close()
+		server.Stop()
+	}
+}
+
+func TestInspectCommit(t *testing.T) {
+	c, fake, stop := newTestClient(t, 1024)
+	defer stop()
+
+	// Finished commits are cached
+	for i := 0; i < 3; i++ {
+		commitInfo, err := c.InspectCommit("in", "finished")
+		require.NoError(t, err)
+		require.Equal(t, "finished", commitInfo.Commit.ID)
+	}
+	require.Equal(t, int64(1), atomic.LoadInt64(&fake.inspects))
+
+	// ...separately for each auth token
+	_, err := c.WithAuthToken("token").InspectCommit("in", "finished")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), atomic.LoadInt64(&fake.inspects))
+
+	// Open commits, branches and errors aren't cached
+	for _, id := range []string{"open", "master", "missing"} {
+		for i := 0; i < 2; i++ {
+			c.InspectCommit("in", id)
+		}
+	}
+	require.Equal(t, int64(8), atomic.LoadInt64(&fake.inspects))
+}
+
+func TestGetFile(t *testing.T) {
+	c, fake, stop := newTestClient(t, 1024)
+	defer stop()
+
+	for i := 0; i < 3; i++ {
+		var buf bytes.Buffer
+		require.NoError(t, c.GetFile("in", "finished", "/file", 0, 0, &buf))
+		require.Equal(t, "finished", buf.String())
+	}
+	require.Equal(t, int64(1), atomic.LoadInt64(&fake.gets))
+
+	for i := 0; i < 2; i++ {
+		var buf bytes.Buffer
+		require.NoError(t, c.GetFile("in", "open", "/file", 0, 0, &buf))
+		require.Equal(t, "open", buf.String())
+	}
+	require.Equal(t, int64(3), atomic.LoadInt64(&fake.gets))
+}
+
+func TestGetFileTooBig(t *testing.T) {
+	// "finished" is bigger than a quarter of the cache, so it isn't cached
+	c, fake, stop := newTestClient(t, 16)
+	defer stop()
+	for i := 0; i < 2; i++ {
+		var buf bytes.Buffer
+		require.NoError(t, c.GetFile("in", "finished", "/file", 0, 0, &buf))
+		require.Equal(t, "finished", buf.String())
+	}
+	require.Equal(t, int64(2), atomic.LoadInt64(&fake.gets))
+}
@@ -19,6 +19,10 @@ type GlobalConfiguration struct {
 	PPSEtcdPrefix string `env:"PPS_ETCD_PREFIX,default=pachyderm_pps"`
 	Namespace     string `env:"NAMESPACE,default=default"`
 	StorageRoot   string `env:"PACH_ROOT,default=/pach"`
+
+	// MetadataStore is where collections are stored: "etcd" or "postgres"
+	MetadataStore string `env:"METADATA_STORE,default=etcd"`
+	PostgresURL   string `env:"POSTGRES_URL,default="`
 }
 
 // PachdFullConfiguration contains the full pachd configuration.
@@ -53,8 +57,48 @@ type PachdSpecificConfiguration struct {
 	MemoryRequest              string `env:"PACHD_MEMORY_REQUEST,default=1T"`
 	WorkerUsesRoot             bool   `env:"WORKER_USES_ROOT,default=true"`
 	S3GatewayPort              uint16 `env:"S3GATEWAY_PORT,default=600"`
+	ArtifactCachePort          uint16 `env:"ARTIFACT_CACHE_PORT,default=0"`
 	DeploymentID               string `env:"CLUSTER_DEPLOYMENT_ID,default="`
 	RequireCriticalServersOnly bool   `env:"REQUIRE_CRITICAL_SERVERS_ONLY",default=false"`
+	// PipelineDefaults is a partial pipeline spec whose fields are used as
+	// defaults for every pipeline created in the cluster
+	PipelineDefaults string `env:"PIPELINE_DEFAULTS,default="`
+	// ImagePinning is the cluster's minimum image pinning policy for
+	// pipelines: "none", "pin" or "strict"
+	ImagePinning string `env:"IMAGE_PINNING,default="`
+	// WorkerNetworkPolicy makes pachd create a network policy for every
+	// pipeline, which restricts the destinations that its workers can reach
+	WorkerNetworkPolicy bool `env:"WORKER_NETWORK_POLICY,default=false"`
+	// WorkerNetworkPolicyAllow is a comma-separated list of CIDRs that every
+	// pipeline's network policy allows its workers to reach
+	WorkerNetworkPolicyAllow string `env:"WORKER_NETWORK_POLICY_ALLOW,default="`
+	// OrphanedWorkerGCDryRun makes pachd log the worker RCs and services of
+	// pipelines that no longer exist, rather than deleting them
+	OrphanedWorkerGCDryRun bool `env:"ORPHANED_WORKER_GC_DRY_RUN,default=false"`
+	// EgressProxyHosts is a comma-separated list of the hosts that pachd's
+	// egress-proxy mode (which runs in the worker pods of pipelines with an
+	// egress proxy) forwards requests to
+	EgressProxyHosts string `env:"EGRESS_PROXY_HOSTS,default="`
+	// PPSMigrationsDryRun makes pachd log the PPS etcd records that it would
+	// migrate to the current schema at startup, rather than migrating them
+	PPSMigrationsDryRun bool `env:"PPS_MIGRATIONS_DRY_RUN,default=false"`
+	// PPSSchemaRollbackVersion, if set, makes pachd migrate PPS's etcd records
+	// back to this (older) schema version at startup and then exit, so that
+	// the version of pachd that uses that schema can be deployed
+	PPSSchemaRollbackVersion string `env:"PPS_SCHEMA_ROLLBACK_VERSION,default="`
+	// PipelineCRD makes pachd create, update and delete a pipeline for each
+	// Pipeline custom resource in its namespace
+	PipelineCRD bool `env:"PIPELINE_CRD,default=false"`
+	// WorkerRPCCacheSize is the size in bytes of each worker's cache of pachd
+	// responses that can't change, or 0 if workers don't cache them
+	WorkerRPCCacheSize int64 `env:"WORKER_RPC_CACHE_SIZE,default=0"`
+	// WorkerBlockSharingCacheSize is the size in bytes of each worker
+	// sidecar's on-disk cache of the blocks that it shares with the other
+	// workers of its pipeline on its node, or 0 if workers don't share blocks
+	WorkerBlockSharingCacheSize int64 `env:"WORKER_BLOCK_SHARING_CACHE_SIZE,default=0"`
+	// BlockSharingCacheSize is WorkerBlockSharingCacheSize, in a worker's
+	// sidecar (see the blockshare package)
+	BlockSharingCacheSize int64 `env:"BLOCK_SHARING_CACHE_SIZE,default=0"`
 }
 
 // StorageConfiguration contains the storage configuration.
@@ -64,6 +108,9 @@ type StorageConfiguration struct {
 	StorageLevelZeroSize          int64 `env:"STORAGE_LEVEL_ZERO_SIZE"`
 	StorageLevelSizeBase          int   `env:"STORAGE_LEVEL_SIZE_BASE"`
 	StorageUploadConcurrencyLimit int   `env:"STORAGE_UPLOAD_CONCURRENCY_LIMIT,default=100"`
+	// StorageCompression is the codec that chunks are compressed with before
+	// they're uploaded: "gzip" (the default), "zstd", "lz4" or "none"
+	StorageCompression string `env:"STORAGE_COMPRESSION,default="`
 }
 
 // WorkerFullConfiguration contains the full worker configuration.
@@ -83,6 +130,10 @@ type WorkerSpecificConfiguration struct {
 	PPSSpecCommitID string `env:"PPS_SPEC_COMMIT,required"`
 	// The name of this pod
 	PodName string `env:"PPS_POD_NAME,required"`
+	// RPCCacheSize is the size in bytes of the worker's cache of pachd
+	// responses that can't change (see the rpccache package), or 0 if the
+	// worker doesn't cache them
+	RPCCacheSize int64 `env:"RPC_CACHE_SIZE,default=0"`
 }
 
 // FeatureFlags contains the configuration for feature flags.
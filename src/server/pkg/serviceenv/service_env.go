@@ -11,8 +11,12 @@ import (
 	etcd "github.com/coreos/etcd/clientv3"
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/mtls"
+	"github.com/pachyderm/pachyderm/src/server/pkg/rpccache"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"k8s.io/client-go/dynamic"
 	kube "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
@@ -51,6 +55,9 @@ type ServiceEnv struct {
 	// kubeClient is a kubernetes client that, if initialized, is shared by all
 	// users of this environment
 	kubeClient *kube.Clientset
+	// dynamicKubeClient is a kubernetes client for custom resources (e.g.
+	// Pipelines), initialized along with kubeClient
+	dynamicKubeClient dynamic.Interface
 	// kubeEg coordinates the initialization of kubeClient (see pachdEg)
 	kubeEg errgroup.Group
 }
@@ -76,7 +83,7 @@ func InitPachOnlyEnv(config *Configuration) *ServiceEnv {
 // until their respective clients are ready.
 func InitServiceEnv(config *Configuration) *ServiceEnv {
 	env := InitPachOnlyEnv(config)
-	env.etcdAddress = fmt.Sprintf("http://%s", net.JoinHostPort(env.EtcdHost, env.EtcdPort))
+	env.etcdAddress = EtcdAddress(config)
 	env.etcdEg.Go(env.initEtcdClient)
 	return env // env is not ready yet
 }
@@ -89,15 +96,34 @@ func InitWithKube(config *Configuration) *ServiceEnv {
 	return env // env is not ready yet
 }
 
+// EtcdAddress returns the URL of etcd's client endpoint, whose scheme depends
+// on whether internal TLS is enabled (see mtls.Internal)
+func EtcdAddress(config *Configuration) string {
+	internalTLS, err := mtls.Internal()
+	if err != nil {
+		// initEtcdClient will fail with the same error
+		log.Errorf("could not load internal TLS config: %v", err)
+	}
+	return fmt.Sprintf("%s://%s", internalTLS.EtcdScheme(), net.JoinHostPort(config.EtcdHost, config.EtcdPort))
+}
+
 func (env *ServiceEnv) initPachClient() error {
 	// validate argument
 	if env.pachAddress == "" {
 		return errors.New("cannot initialize pach client with empty pach address")
 	}
+	options, err := mtls.PeerClientOptions()
+	if err != nil {
+		return err
+	}
+	if env.WorkerSpecificConfiguration != nil && env.RPCCacheSize > 0 {
+		cache := rpccache.New(env.RPCCacheSize)
+		options = append(options, client.WithInterceptors(cache.UnaryClientInterceptor(), cache.StreamClientInterceptor()))
+	}
 	// Initialize pach client
 	return backoff.Retry(func() error {
 		var err error
-		env.pachClient, err = client.NewFromAddress(env.pachAddress)
+		env.pachClient, err = client.NewFromAddress(env.pachAddress, options...)
 		if err != nil {
 			return fmt.Errorf("failed to initialize pach client: %v", err)
 		}
@@ -110,11 +136,16 @@ func (env *ServiceEnv) initEtcdClient() error {
 	if env.etcdAddress == "" {
 		return errors.New("cannot initialize pach client with empty pach address")
 	}
+	internalTLS, err := mtls.Internal()
+	if err != nil {
+		return err
+	}
 	// Initialize etcd
-	return backoff.Retry(func() error {
+	if err := backoff.Retry(func() error {
 		var err error
 		env.etcdClient, err = etcd.New(etcd.Config{
 			Endpoints: []string{env.etcdAddress},
+			TLS:       internalTLS.ClientTLSConfig(),
 			// Use a long timeout with Etcd so that Pachyderm doesn't crash loop
 			// while waiting for etcd to come up (makes startup net faster)
 			DialOptions:        append(client.DefaultDialOptions(), grpc.WithTimeout(3*time.Minute)), //lint:ignore SA1019 can't call grpc.Dial directly
@@ -125,6 +156,33 @@ func (env *ServiceEnv) initEtcdClient() error {
 			return fmt.Errorf("failed to initialize etcd client: %v", err)
 		}
 		return nil
+	}, backoff.RetryEvery(time.Second).For(5*time.Minute)); err != nil {
+		return err
+	}
+	return env.initMetadataStore()
+}
+
+// initMetadataStore connects to the Postgres database that collections are
+// stored in, if the cluster was deployed with one. This happens before any
+// collection is created, as collections are created with the etcd client.
+func (env *ServiceEnv) initMetadataStore() error {
+	switch env.MetadataStore {
+	case "", "etcd":
+		return nil
+	case "postgres":
+	default:
+		return fmt.Errorf("unrecognized metadata store %q (must be \"etcd\" or \"postgres\")", env.MetadataStore)
+	}
+	if env.PostgresURL == "" {
+		return errors.New("POSTGRES_URL must be set to use the postgres metadata store")
+	}
+	return backoff.Retry(func() error {
+		store, err := col.NewPostgresStore(env.PostgresURL)
+		if err != nil {
+			return fmt.Errorf("failed to connect to postgres: %v", err)
+		}
+		col.UsePostgres(store)
+		return nil
 	}, backoff.RetryEvery(time.Second).For(5*time.Minute))
 }
 
@@ -152,6 +210,10 @@ func (env *ServiceEnv) initKubeClient() error {
 		if err != nil {
 			return fmt.Errorf("could not initialize kube client: %v", err)
 		}
+		env.dynamicKubeClient, err = dynamic.NewForConfig(cfg)
+		if err != nil {
+			return fmt.Errorf("could not initialize dynamic kube client: %v", err)
+		}
 		return nil
 	}, backoff.RetryEvery(time.Second).For(5*time.Minute))
 }
@@ -195,3 +257,15 @@ func (env *ServiceEnv) GetKubeClient() *kube.Clientset {
 	}
 	return env.kubeClient
 }
+
+// GetDynamicKubeClient returns the already connected Kubernetes API client
+// for custom resources without modification.
+func (env *ServiceEnv) GetDynamicKubeClient() dynamic.Interface {
+	if err := env.kubeEg.Wait(); err != nil {
+		panic(err) // If env can't connect, there's no sensible way to recover
+	}
+	if env.dynamicKubeClient == nil {
+		panic("service env never connected to kubernetes")
+	}
+	return env.dynamicKubeClient
+}
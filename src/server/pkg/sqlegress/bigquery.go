@@ -0,0 +1,109 @@
+package sqlegress
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// bigQueryLoader loads each file into BigQuery with its own load job. Jobs
+// have IDs derived from the commit, table and file that they load, and
+// BigQuery never runs two jobs with the same ID, so no file is loaded twice.
+type bigQueryLoader struct {
+	client  *bigquery.Client
+	dataset *bigquery.Dataset
+	format  *pps.SQLDatabaseEgress_FileFormat
+}
+
+// bigQueryDataset returns the project and dataset in a url of the form
+// "bigquery://<project>/<dataset>"
+func bigQueryDataset(u *url.URL) (string, string, error) {
+	dataset := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || dataset == "" || strings.Contains(dataset, "/") {
+		return "", "", fmt.Errorf("bigquery url must have the form bigquery://<project>/<dataset>")
+	}
+	return u.Host, dataset, nil
+}
+
+func newBigQueryLoader(ctx context.Context, u *url.URL, credentials string, format *pps.SQLDatabaseEgress_FileFormat) (*bigQueryLoader, error) {
+	project, dataset, err := bigQueryDataset(u)
+	if err != nil {
+		return nil, err
+	}
+	var opts []option.ClientOption
+	if credentials != "" {
+		opts = append(opts, option.WithCredentialsJSON([]byte(credentials)))
+	}
+	client, err := bigquery.NewClient(ctx, project, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &bigQueryLoader{
+		client:  client,
+		dataset: client.Dataset(dataset),
+		format:  format,
+	}, nil
+}
+
+// bigQueryJobID returns the ID of the job that loads 'file' from 'commitID'
+// into 'table'
+func bigQueryJobID(commitID string, table string, file string) string {
+	sum := sha256.Sum256([]byte(table + "\x00" + file))
+	return fmt.Sprintf("pachyderm_%s_%s", commitID, hex.EncodeToString(sum[:8]))
+}
+
+func (l *bigQueryLoader) Load(ctx context.Context, commitID string, table string, files []File) (bool, error) {
+	loaded := false
+	for _, f := range files {
+		source := bigquery.NewReaderSource(bytes.NewReader(f.Data))
+		switch l.format.Type {
+		case pps.SQLDatabaseEgress_FileFormat_CSV:
+			source.SourceFormat = bigquery.CSV
+			if len(l.format.Columns) == 0 {
+				source.SkipLeadingRows = 1
+			}
+		case pps.SQLDatabaseEgress_FileFormat_PARQUET:
+			source.SourceFormat = bigquery.Parquet
+		default:
+			return false, fmt.Errorf("unrecognized file format %v", l.format.Type)
+		}
+		loader := l.dataset.Table(table).LoaderFrom(source)
+		loader.WriteDisposition = bigquery.WriteAppend
+		loader.JobID = bigQueryJobID(commitID, table, f.Path)
+		job, err := loader.Run(ctx)
+		if err != nil {
+			if apiErr, ok := err.(*googleapi.Error); !ok || apiErr.Code != http.StatusConflict {
+				return false, fmt.Errorf("could not load %s into %s: %v", f.Path, table, err)
+			}
+			// The file was loaded (or is being loaded) by an earlier attempt
+			if job, err = l.client.JobFromID(ctx, loader.JobID); err != nil {
+				return false, err
+			}
+		} else {
+			loaded = true
+		}
+		status, err := job.Wait(ctx)
+		if err != nil {
+			return false, err
+		}
+		if err := status.Err(); err != nil {
+			return false, fmt.Errorf("could not load %s into %s: %v", f.Path, table, err)
+		}
+	}
+	return loaded, nil
+}
+
+func (l *bigQueryLoader) Close() error {
+	return l.client.Close()
+}
@@ -0,0 +1,102 @@
+package sqlegress
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// readRows parses 'data', a file in 'format', and returns the names of its
+// columns and its rows
+func readRows(format *pps.SQLDatabaseEgress_FileFormat, data []byte) ([]string, [][]interface{}, error) {
+	switch format.Type {
+	case pps.SQLDatabaseEgress_FileFormat_CSV:
+		return readCSVRows(format.Columns, data)
+	case pps.SQLDatabaseEgress_FileFormat_PARQUET:
+		return readParquetRows(data)
+	default:
+		return nil, nil, fmt.Errorf("unrecognized file format %v", format.Type)
+	}
+}
+
+// readCSVRows parses a CSV file. If 'columns' is empty, the file's first line
+// is its header. Empty fields are NULL.
+func readCSVRows(columns []string, data []byte) ([]string, [][]interface{}, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = len(columns)
+	if len(columns) == 0 {
+		header, err := r.Read()
+		if err == io.EOF {
+			return nil, nil, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		columns = header
+	}
+	var rows [][]interface{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return columns, rows, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		row := make([]interface{}, len(record))
+		for i, field := range record {
+			if field != "" {
+				row[i] = field
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+// readParquetRows parses a Parquet file, which must have a flat schema (each
+// column is a top-level, non-repeated field)
+func readParquetRows(data []byte) ([]string, [][]interface{}, error) {
+	file, err := buffer.NewBufferFile(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	pr, err := reader.NewParquetColumnReader(file, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer pr.ReadStop()
+	numRows := pr.GetNumRows()
+	rows := make([][]interface{}, numRows)
+	for i := range rows {
+		rows[i] = make([]interface{}, len(pr.SchemaHandler.ValueColumns))
+	}
+	var columns []string
+	for i, inPath := range pr.SchemaHandler.ValueColumns {
+		path := common.StrToPath(pr.SchemaHandler.InPathToExPath[inPath])
+		if len(path) != 2 {
+			return nil, nil, fmt.Errorf("nested parquet column %q is not supported", inPath)
+		}
+		columns = append(columns, path[1])
+		if numRows == 0 {
+			continue
+		}
+		values, _, _, err := pr.ReadColumnByIndex(int64(i), numRows)
+		if err != nil {
+			return nil, nil, err
+		}
+		if int64(len(values)) != numRows {
+			return nil, nil, fmt.Errorf("repeated parquet column %q is not supported", path[1])
+		}
+		for j, v := range values {
+			rows[j][i] = v
+		}
+	}
+	return columns, rows, nil
+}
@@ -0,0 +1,248 @@
+package sqlegress
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	// Registers the postgres and snowflake drivers with database/sql
+	_ "github.com/lib/pq"
+	_ "github.com/snowflakedb/gosnowflake"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+const (
+	// maxInsertRows is the maximum number of rows inserted by each INSERT
+	// statement
+	maxInsertRows = 500
+	// maxInsertParams is the maximum number of values in each INSERT
+	// statement (postgres allows at most 65535 parameters)
+	maxInsertParams = 65535
+)
+
+// identifierRegex matches the table and column names that loaders accept.
+// They're limited so that they never need escaping.
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+// dialect describes the SQL that a database accepts
+type dialect struct {
+	// quote is the character that quotes identifiers (if any)
+	quote string
+	// numberedParams is true if parameters are written $1, $2, ..., rather
+	// than ?
+	numberedParams bool
+}
+
+var (
+	postgresDialect = dialect{quote: `"`, numberedParams: true}
+	mysqlDialect    = dialect{quote: "`"}
+	// Quoted identifiers are case-sensitive in snowflake, unlike the
+	// (upper-cased) unquoted identifiers that tables are usually created with
+	snowflakeDialect = dialect{}
+)
+
+// quoteIdentifier validates and quotes the table or column name 'name',
+// which may be qualified (e.g. "schema.table")
+func (d dialect) quoteIdentifier(name string) (string, error) {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if !identifierRegex.MatchString(part) {
+			return "", fmt.Errorf("invalid name %q (names must contain only letters, digits, '_' and '$')", name)
+		}
+		parts[i] = d.quote + part + d.quote
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// param returns the placeholder of the i'th (from 1) parameter of a statement
+func (d dialect) param(i int) string {
+	if d.numberedParams {
+		return fmt.Sprintf("$%d", i)
+	}
+	return "?"
+}
+
+// insertStatement returns a statement that inserts 'numRows' rows of values
+// for 'columns' into 'table'
+func (d dialect) insertStatement(table string, columns []string, numRows int) (string, error) {
+	quotedTable, err := d.quoteIdentifier(table)
+	if err != nil {
+		return "", err
+	}
+	var quotedColumns []string
+	for _, c := range columns {
+		quoted, err := d.quoteIdentifier(c)
+		if err != nil {
+			return "", err
+		}
+		quotedColumns = append(quotedColumns, quoted)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "INSERT INTO %s (%s) VALUES ", quotedTable, strings.Join(quotedColumns, ", "))
+	param := 1
+	for i := 0; i < numRows; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(")
+		for j := range columns {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(d.param(param))
+			param++
+		}
+		b.WriteString(")")
+	}
+	return b.String(), nil
+}
+
+// dataSourceName returns the data source name that connects to the database at
+// 'u' with 'password', and the database's dialect
+func dataSourceName(u *url.URL, password string) (string, dialect, error) {
+	if u.User == nil || u.User.Username() == "" {
+		return "", dialect{}, fmt.Errorf("database url must include a user")
+	}
+	if _, ok := u.User.Password(); ok {
+		return "", dialect{}, fmt.Errorf("database url must not include a password (it's read from the egress secret)")
+	}
+	user := u.User.Username()
+	withPassword := *u
+	if password != "" {
+		withPassword.User = url.UserPassword(user, password)
+	}
+	switch u.Scheme {
+	case "postgres", "postgresql":
+		return withPassword.String(), postgresDialect, nil
+	case "mysql":
+		cfg := mysql.NewConfig()
+		cfg.User = user
+		cfg.Passwd = password
+		cfg.Net = "tcp"
+		cfg.Addr = u.Host
+		cfg.DBName = strings.TrimPrefix(u.Path, "/")
+		if q := u.Query(); len(q) > 0 {
+			cfg.Params = make(map[string]string)
+			for k := range q {
+				cfg.Params[k] = q.Get(k)
+			}
+		}
+		return cfg.FormatDSN(), mysqlDialect, nil
+	case "snowflake":
+		return strings.TrimPrefix(withPassword.String(), "snowflake://"), snowflakeDialect, nil
+	default:
+		return "", dialect{}, fmt.Errorf("unsupported database %q (must be postgres, mysql, snowflake or bigquery)", u.Scheme)
+	}
+}
+
+// driverName returns the database/sql driver that connects to databases with
+// 'scheme'
+func driverName(scheme string) string {
+	if scheme == "postgresql" {
+		return "postgres"
+	}
+	return scheme
+}
+
+// sqlLoader loads files into a database through database/sql. Each table is
+// loaded in a transaction that also records the commit in TrackingTable.
+type sqlLoader struct {
+	db      *sql.DB
+	dialect dialect
+	format  *pps.SQLDatabaseEgress_FileFormat
+}
+
+func newSQLLoader(u *url.URL, password string, format *pps.SQLDatabaseEgress_FileFormat) (*sqlLoader, error) {
+	dsn, d, err := dataSourceName(u, password)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(driverName(u.Scheme), dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlLoader{db: db, dialect: d, format: format}, nil
+}
+
+func (l *sqlLoader) Load(ctx context.Context, commitID string, table string, files []File) (retLoaded bool, retErr error) {
+	// This can't be part of the transaction below, as MySQL commits
+	// transactions implicitly before DDL
+	if _, err := l.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		table_name VARCHAR(255) NOT NULL,
+		commit_id VARCHAR(64) NOT NULL,
+		loaded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (table_name, commit_id)
+	)`, TrackingTable)); err != nil {
+		return false, fmt.Errorf("could not create %s: %v", TrackingTable, err)
+	}
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if retErr != nil {
+			tx.Rollback()
+		}
+	}()
+	var loads int
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE table_name = %s AND commit_id = %s",
+		TrackingTable, l.dialect.param(1), l.dialect.param(2)), table, commitID).Scan(&loads); err != nil {
+		return false, err
+	}
+	if loads > 0 {
+		return false, tx.Rollback()
+	}
+	for _, f := range files {
+		if err := l.insertFile(ctx, tx, table, f); err != nil {
+			return false, fmt.Errorf("could not load %s into %s: %v", f.Path, table, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (table_name, commit_id) VALUES (%s, %s)",
+		TrackingTable, l.dialect.param(1), l.dialect.param(2)), table, commitID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// insertFile inserts the rows in 'f' into 'table'
+func (l *sqlLoader) insertFile(ctx context.Context, tx *sql.Tx, table string, f File) error {
+	columns, rows, err := readRows(l.format, f.Data)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	batchSize := maxInsertRows
+	if batchSize*len(columns) > maxInsertParams {
+		batchSize = maxInsertParams / len(columns)
+	}
+	for len(rows) > 0 {
+		n := batchSize
+		if n > len(rows) {
+			n = len(rows)
+		}
+		args := make([]interface{}, 0, n*len(columns))
+		for _, row := range rows[:n] {
+			args = append(args, row...)
+		}
+		stmt, err := l.dialect.insertStatement(table, columns, n)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return err
+		}
+		rows = rows[n:]
+	}
+	return nil
+}
+
+func (l *sqlLoader) Close() error {
+	return l.db.Close()
+}
@@ -0,0 +1,63 @@
+// Package sqlegress loads the output commits of pipelines that egress to SQL
+// databases into those databases.
+package sqlegress
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// TrackingTable is the table in which loaders record the commits that they've
+// loaded into each table, so that a commit is never loaded twice
+const TrackingTable = "pachyderm_egress"
+
+// File is a file from an output commit
+type File struct {
+	Path string
+	Data []byte
+}
+
+// Loader loads files into a database
+type Loader interface {
+	// Load loads 'files' into 'table' (all or none of them), unless they've
+	// already been loaded from the commit 'commitID'. It returns false if
+	// they had been.
+	Load(ctx context.Context, commitID string, table string, files []File) (bool, error)
+	// Close releases the loader's connections to the database
+	Close() error
+}
+
+// NewLoader returns a Loader for the database at 'dbURL' (the url of a
+// SQLDatabaseEgress), which authenticates with 'secret' and reads files in
+// 'format'
+func NewLoader(ctx context.Context, dbURL string, secret string, format *pps.SQLDatabaseEgress_FileFormat) (Loader, error) {
+	u, err := url.Parse(dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database url: %v", err)
+	}
+	if format == nil {
+		format = &pps.SQLDatabaseEgress_FileFormat{}
+	}
+	if u.Scheme == "bigquery" {
+		return newBigQueryLoader(ctx, u, secret, format)
+	}
+	return newSQLLoader(u, secret, format)
+}
+
+// ValidateURL returns an error if 'dbURL' isn't the url of a database that
+// NewLoader supports
+func ValidateURL(dbURL string) error {
+	u, err := url.Parse(dbURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "bigquery" {
+		_, _, err := bigQueryDataset(u)
+		return err
+	}
+	_, _, err = dataSourceName(u, "")
+	return err
+}
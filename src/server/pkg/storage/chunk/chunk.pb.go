@@ -22,6 +22,40 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
+// CompressionAlgo is the codec that a chunk is compressed with before it's
+// uploaded. Chunks that predate the codec's selection are compressed with
+// gzip.
+type CompressionAlgo int32
+
+const (
+	CompressionAlgo_GZIP CompressionAlgo = 0
+	CompressionAlgo_NONE CompressionAlgo = 1
+	CompressionAlgo_ZSTD CompressionAlgo = 2
+	CompressionAlgo_LZ4  CompressionAlgo = 3
+)
+
+var CompressionAlgo_name = map[int32]string{
+	0: "GZIP",
+	1: "NONE",
+	2: "ZSTD",
+	3: "LZ4",
+}
+
+var CompressionAlgo_value = map[string]int32{
+	"GZIP": 0,
+	"NONE": 1,
+	"ZSTD": 2,
+	"LZ4":  3,
+}
+
+func (x CompressionAlgo) String() string {
+	return proto.EnumName(CompressionAlgo_name, int32(x))
+}
+
+func (CompressionAlgo) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_80b36f82a9f02ff9, []int{0}
+}
+
 // DataRef is a reference to data within a chunk.
 type DataRef struct {
 	// The chunk the referenced data is located in.
@@ -154,12 +188,13 @@ func (m *Chunk) GetHash() string {
 }
 
 type ChunkInfo struct {
-	Chunk                *Chunk   `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
-	SizeBytes            int64    `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
-	Edge                 bool     `protobuf:"varint,3,opt,name=edge,proto3" json:"edge,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Chunk                *Chunk          `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	SizeBytes            int64           `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	Edge                 bool            `protobuf:"varint,3,opt,name=edge,proto3" json:"edge,omitempty"`
+	Compression          CompressionAlgo `protobuf:"varint,4,opt,name=compression,proto3,enum=chunk.CompressionAlgo" json:"compression,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
 }
 
 func (m *ChunkInfo) Reset()         { *m = ChunkInfo{} }
@@ -216,6 +251,13 @@ func (m *ChunkInfo) GetEdge() bool {
 	return false
 }
 
+func (m *ChunkInfo) GetCompression() CompressionAlgo {
+	if m != nil {
+		return m.Compression
+	}
+	return CompressionAlgo_GZIP
+}
+
 type Tag struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	SizeBytes            int64    `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
@@ -272,6 +314,7 @@ func (m *Tag) GetSizeBytes() int64 {
 }
 
 func init() {
+	proto.RegisterEnum("chunk.CompressionAlgo", CompressionAlgo_name, CompressionAlgo_value)
 	proto.RegisterType((*DataRef)(nil), "chunk.DataRef")
 	proto.RegisterType((*Chunk)(nil), "chunk.Chunk")
 	proto.RegisterType((*ChunkInfo)(nil), "chunk.ChunkInfo")
@@ -283,27 +326,31 @@ func init() {
 }
 
 var fileDescriptor_80b36f82a9f02ff9 = []byte{
-	// 305 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x51, 0x31, 0x6b, 0xf3, 0x30,
-	0x10, 0x45, 0xb6, 0xf3, 0x7d, 0xf5, 0x25, 0x94, 0xa2, 0xc9, 0x50, 0x6a, 0x5c, 0xd3, 0xc1, 0x53,
-	0x04, 0x69, 0xb7, 0x6e, 0x69, 0x97, 0xac, 0x22, 0x53, 0x97, 0x20, 0xdb, 0xb2, 0x6c, 0x42, 0x2d,
-	0x23, 0x29, 0x85, 0xf4, 0x57, 0xf5, 0x67, 0x74, 0xec, 0x4f, 0x28, 0xfe, 0x25, 0xc5, 0x92, 0x9b,
-	0x86, 0x40, 0xe9, 0x72, 0x3c, 0xbd, 0x3b, 0xdd, 0x7b, 0x8f, 0x83, 0x1b, 0xcd, 0xd5, 0x0b, 0x57,
-	0xa4, 0xdb, 0x0a, 0xa2, 0x8d, 0x54, 0x4c, 0x70, 0x52, 0xd4, 0xbb, 0x76, 0xeb, 0xea, 0xbc, 0x53,
-	0xd2, 0x48, 0x3c, 0xb1, 0x8f, 0xf4, 0x0d, 0xc1, 0xff, 0x47, 0x66, 0x18, 0xe5, 0x15, 0x26, 0x00,
-	0x96, 0xdc, 0x34, 0x6d, 0x25, 0x23, 0x94, 0xa0, 0x6c, 0xba, 0xb8, 0x98, 0xbb, 0x4f, 0x0f, 0x43,
-	0x5d, 0xb5, 0x95, 0xa4, 0x61, 0xf1, 0x0d, 0x31, 0x86, 0xa0, 0x66, 0xba, 0x8e, 0xbc, 0x04, 0x65,
-	0x21, 0xb5, 0x18, 0x5f, 0xc3, 0x4c, 0x56, 0x95, 0xe6, 0x66, 0x93, 0xef, 0x0d, 0xd7, 0x91, 0x9f,
-	0xa0, 0xcc, 0xa7, 0x53, 0xc7, 0x2d, 0x07, 0x0a, 0x5f, 0x01, 0xe8, 0xe6, 0x95, 0x8f, 0x03, 0x81,
-	0x1d, 0x08, 0x07, 0xc6, 0xb5, 0x63, 0x08, 0x0c, 0x13, 0x3a, 0x9a, 0x24, 0x7e, 0x36, 0x5d, 0xc0,
-	0x68, 0x60, 0xcd, 0x04, 0xb5, 0x7c, 0x7a, 0x09, 0x13, 0xeb, 0xe6, 0x20, 0x8f, 0x7e, 0xe4, 0xd3,
-	0x1c, 0xc2, 0x83, 0x55, 0x9c, 0x82, 0x4b, 0x39, 0x66, 0x99, 0x1d, 0x67, 0xa1, 0xae, 0x75, 0x62,
-	0xc6, 0x3b, 0x35, 0x83, 0x21, 0xe0, 0xa5, 0xe0, 0x36, 0xc6, 0x19, 0xb5, 0x38, 0xbd, 0x03, 0x7f,
-	0xcd, 0x04, 0x3e, 0x07, 0xaf, 0x29, 0x47, 0x71, 0xaf, 0x29, 0xff, 0xd8, 0xb4, 0x5c, 0xbd, 0xf7,
-	0x31, 0xfa, 0xe8, 0x63, 0xf4, 0xd9, 0xc7, 0xe8, 0xe9, 0x5e, 0x34, 0xa6, 0xde, 0xe5, 0xf3, 0x42,
-	0x3e, 0x93, 0x8e, 0x15, 0xf5, 0xbe, 0xe4, 0xea, 0x18, 0x69, 0x55, 0x90, 0xdf, 0xee, 0x98, 0xff,
-	0xb3, 0x27, 0xbc, 0xfd, 0x0a, 0x00, 0x00, 0xff, 0xff, 0xa9, 0x8b, 0x90, 0x8b, 0xea, 0x01, 0x00,
-	0x00,
+	// 374 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x92, 0xcf, 0xca, 0xd3, 0x40,
+	0x14, 0xc5, 0x9d, 0x24, 0xb5, 0xcd, 0x4d, 0xa9, 0x61, 0x16, 0x12, 0x10, 0x43, 0x0c, 0x2e, 0x82,
+	0x8b, 0x06, 0x6a, 0x17, 0x05, 0x57, 0xd6, 0x8a, 0x14, 0xa4, 0xca, 0xd8, 0x55, 0x36, 0x65, 0x9a,
+	0x4c, 0x26, 0xa1, 0x36, 0x13, 0x32, 0xa9, 0x50, 0x9f, 0xc5, 0x87, 0xf0, 0x31, 0x5c, 0xfa, 0x08,
+	0xd2, 0x27, 0x91, 0x4c, 0xd2, 0x3f, 0x14, 0x3e, 0xbe, 0xcd, 0x70, 0x72, 0xee, 0xcd, 0xbd, 0xbf,
+	0x03, 0x17, 0x5e, 0x4b, 0x56, 0xfd, 0x60, 0x55, 0x58, 0xee, 0x78, 0x28, 0x6b, 0x51, 0x51, 0xce,
+	0xc2, 0x38, 0x3b, 0x14, 0xbb, 0xf6, 0x1d, 0x97, 0x95, 0xa8, 0x05, 0xee, 0xa9, 0x0f, 0xff, 0x37,
+	0x82, 0xfe, 0x82, 0xd6, 0x94, 0xb0, 0x14, 0x87, 0x00, 0xca, 0xdc, 0xe4, 0x45, 0x2a, 0x1c, 0xe4,
+	0xa1, 0xc0, 0x9a, 0xd8, 0xe3, 0xf6, 0xa7, 0x0f, 0xcd, 0xbb, 0x2c, 0x52, 0x41, 0xcc, 0xf8, 0x2c,
+	0x31, 0x06, 0x23, 0xa3, 0x32, 0x73, 0x34, 0x0f, 0x05, 0x26, 0x51, 0x1a, 0xbf, 0x82, 0xa1, 0x48,
+	0x53, 0xc9, 0xea, 0xcd, 0xf6, 0x58, 0x33, 0xe9, 0xe8, 0x1e, 0x0a, 0x74, 0x62, 0xb5, 0xde, 0xbc,
+	0xb1, 0xf0, 0x4b, 0x00, 0x99, 0xff, 0x64, 0x5d, 0x83, 0xa1, 0x1a, 0xcc, 0xc6, 0x69, 0xcb, 0x2e,
+	0x18, 0x35, 0xe5, 0xd2, 0xe9, 0x79, 0x7a, 0x60, 0x4d, 0xa0, 0x03, 0x58, 0x53, 0x4e, 0x94, 0xef,
+	0xbf, 0x80, 0x9e, 0xa2, 0xb9, 0xac, 0x47, 0xd7, 0xf5, 0xfe, 0x2f, 0x04, 0xe6, 0x85, 0x15, 0xfb,
+	0xd0, 0xc6, 0xec, 0xc2, 0x0c, 0x6f, 0xc3, 0x90, 0xb6, 0x74, 0x47, 0xa3, 0xdd, 0xd3, 0x60, 0x30,
+	0x58, 0xc2, 0x99, 0xca, 0x31, 0x20, 0x4a, 0xe3, 0x19, 0x58, 0xb1, 0xd8, 0x97, 0x15, 0x93, 0x32,
+	0x17, 0x85, 0x4a, 0x30, 0x9a, 0x3c, 0x3f, 0x0f, 0xbf, 0x56, 0xde, 0x7f, 0xe7, 0x82, 0xdc, 0xb6,
+	0xfa, 0x53, 0xd0, 0xd7, 0x94, 0xe3, 0x11, 0x68, 0x79, 0xd2, 0x71, 0x6b, 0x79, 0xf2, 0x08, 0xc3,
+	0x9b, 0x19, 0x3c, 0xbb, 0x9b, 0x8a, 0x07, 0x60, 0x7c, 0x8a, 0x96, 0x5f, 0xed, 0x27, 0x8d, 0x5a,
+	0x7d, 0x59, 0x7d, 0xb4, 0x51, 0xa3, 0xa2, 0x6f, 0xeb, 0x85, 0xad, 0xe1, 0x3e, 0xe8, 0x9f, 0xa3,
+	0xa9, 0xad, 0xcf, 0x97, 0x7f, 0x4e, 0x2e, 0xfa, 0x7b, 0x72, 0xd1, 0xbf, 0x93, 0x8b, 0xa2, 0x77,
+	0x3c, 0xaf, 0xb3, 0xc3, 0x76, 0x1c, 0x8b, 0x7d, 0x58, 0xd2, 0x38, 0x3b, 0x26, 0xac, 0xba, 0x55,
+	0xb2, 0x8a, 0xc3, 0x87, 0x8e, 0x67, 0xfb, 0x54, 0xdd, 0xcd, 0xdb, 0xff, 0x03, 0x00, 0xf7, 0x0c,
+	0x5e, 0x21, 0x5f, 0x02, 0x00, 0x00,
 }
 
 func (m *DataRef) Marshal() (dAtA []byte, err error) {
@@ -434,6 +481,11 @@ func (m *ChunkInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.Compression != 0 {
+		i = encodeVarintChunk(dAtA, i, uint64(m.Compression))
+		i--
+		dAtA[i] = 0x20
+	}
 	if m.Edge {
 		i--
 		if m.Edge {
@@ -578,6 +630,9 @@ func (m *ChunkInfo) Size() (n int) {
 	if m.Edge {
 		n += 2
 	}
+	if m.Compression != 0 {
+		n += 1 + sovChunk(uint64(m.Compression))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -993,6 +1048,25 @@ func (m *ChunkInfo) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.Edge = bool(v != 0)
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Compression", wireType)
+			}
+			m.Compression = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChunk
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Compression |= CompressionAlgo(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipChunk(dAtA[iNdEx:])
@@ -102,6 +102,31 @@ func TestCopy(t *testing.T) {
 	}
 }
 
+func TestCompression(t *testing.T) {
+	objC, chunks := LocalStorage(t)
+	defer Cleanup(objC, chunks)
+	// Setup seed.
+	seed := time.Now().UTC().UnixNano()
+	rand.Seed(seed)
+	msg := seedStr(seed)
+	test := test{1 * KB, 1 * KB, 1 * MB}
+	for algo, name := range CompressionAlgo_name {
+		t.Run(name, func(t *testing.T) {
+			chunks := NewStorage(objC, WithCompression(CompressionAlgo(algo)))
+			as := generateAnnotations(test)
+			writeAnnotations(t, chunks, as, msg)
+			readAnnotations(t, chunks, as, msg)
+			// The chunks' objects are stored under their codec's path.
+			for _, a := range as {
+				for _, dataRef := range a.dataRefs {
+					require.Equal(t, CompressionAlgo(algo), dataRef.ChunkInfo.Compression, msg)
+					require.True(t, objC.Exists(context.Background(), chunkPath(dataRef.ChunkInfo)), msg)
+				}
+			}
+		})
+	}
+}
+
 func BenchmarkWriter(b *testing.B) {
 	objC, chunks := LocalStorage(b)
 	defer Cleanup(objC, chunks)
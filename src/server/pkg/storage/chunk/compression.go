@@ -0,0 +1,91 @@
+package chunk
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4"
+)
+
+// ParseCompressionAlgo parses the name of a compression codec ("gzip",
+// "zstd", "lz4" or "none").
+func ParseCompressionAlgo(name string) (CompressionAlgo, error) {
+	algo, ok := CompressionAlgo_value[strings.ToUpper(name)]
+	if !ok {
+		return 0, fmt.Errorf("unrecognized compression codec %q (must be \"gzip\", \"zstd\", \"lz4\" or \"none\")", name)
+	}
+	return CompressionAlgo(algo), nil
+}
+
+// chunkPath returns the path of a chunk's object. Chunks are deduplicated by
+// their path, so chunks with the same content that are compressed with
+// different codecs are stored separately. Gzipped chunks have no suffix, as
+// they predate the other codecs.
+func chunkPath(chunkInfo *ChunkInfo) string {
+	p := path.Join(prefix, chunkInfo.Chunk.Hash)
+	if chunkInfo.Compression != CompressionAlgo_GZIP {
+		p += "." + strings.ToLower(chunkInfo.Compression.String())
+	}
+	return p
+}
+
+// compress returns a writer that compresses the data written to it with
+// 'algo' into 'w'. The data isn't completely written until the writer is
+// closed.
+func compress(algo CompressionAlgo, w io.Writer) (io.WriteCloser, error) {
+	switch algo {
+	case CompressionAlgo_GZIP:
+		return gzip.NewWriterLevel(w, gzip.BestSpeed)
+	case CompressionAlgo_NONE:
+		return nopWriteCloser{w}, nil
+	case CompressionAlgo_ZSTD:
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedFastest))
+	case CompressionAlgo_LZ4:
+		return lz4.NewWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unrecognized compression codec %v", algo)
+	}
+}
+
+// decompress returns a reader of the data that 'r' compressed with 'algo'
+func decompress(algo CompressionAlgo, r io.Reader) (io.ReadCloser, error) {
+	switch algo {
+	case CompressionAlgo_GZIP:
+		return gzip.NewReader(r)
+	case CompressionAlgo_NONE:
+		return ioutil.NopCloser(r), nil
+	case CompressionAlgo_ZSTD:
+		zstdR, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return zstdReadCloser{zstdR}, nil
+	case CompressionAlgo_LZ4:
+		return ioutil.NopCloser(lz4.NewReader(r)), nil
+	default:
+		return nil, fmt.Errorf("unrecognized compression codec %v", algo)
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// zstdReadCloser releases a zstd decoder's resources when it's closed
+type zstdReadCloser struct {
+	*zstd.Decoder
+}
+
+func (r zstdReadCloser) Close() error {
+	r.Decoder.Close()
+	return nil
+}
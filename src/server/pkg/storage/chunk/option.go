@@ -1,12 +1,33 @@
 package chunk
 
-import "github.com/pachyderm/pachyderm/src/server/pkg/serviceenv"
+import (
+	"github.com/pachyderm/pachyderm/src/server/pkg/serviceenv"
+
+	log "github.com/sirupsen/logrus"
+)
 
 // StorageOption configures a storage.
 type StorageOption func(s *Storage)
 
+// WithCompression sets the codec that chunks are compressed with before
+// they're uploaded.
+func WithCompression(algo CompressionAlgo) StorageOption {
+	return func(s *Storage) {
+		s.compression = algo
+	}
+}
+
 // ServiceEnvToOptions converts a service environment configuration (specifically
 // the storage configuration) to a set of storage options.
 func ServiceEnvToOptions(env *serviceenv.ServiceEnv) []StorageOption {
-	return nil
+	var opts []StorageOption
+	if env.StorageCompression != "" {
+		algo, err := ParseCompressionAlgo(env.StorageCompression)
+		if err != nil {
+			log.Errorf("%v; compressing chunks with gzip", err)
+		} else {
+			opts = append(opts, WithCompression(algo))
+		}
+	}
+	return opts
 }
@@ -2,10 +2,8 @@ package chunk
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"io"
-	"path"
 	"sync"
 
 	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
@@ -279,18 +277,18 @@ func (dr *DataReader) getChunk() error {
 		return nil
 	}
 	// Get chunk from object storage.
-	objR, err := dr.objC.Reader(dr.ctx, path.Join(prefix, dr.dataRef.ChunkInfo.Chunk.Hash), 0, 0)
+	objR, err := dr.objC.Reader(dr.ctx, chunkPath(dr.dataRef.ChunkInfo), 0, 0)
 	if err != nil {
 		return err
 	}
 	defer objR.Close()
-	gzipR, err := gzip.NewReader(objR)
+	decompressR, err := decompress(dr.dataRef.ChunkInfo.Compression, objR)
 	if err != nil {
 		return err
 	}
-	defer gzipR.Close()
+	defer decompressR.Close()
 	buf := &bytes.Buffer{}
-	if _, err := io.Copy(buf, gzipR); err != nil {
+	if _, err := io.Copy(buf, decompressR); err != nil {
 		return err
 	}
 	dr.chunk = buf.Bytes()
@@ -3,7 +3,6 @@ package chunk
 import (
 	"bytes"
 	"context"
-	"path"
 
 	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
 )
@@ -25,7 +24,8 @@ type Annotation struct {
 
 // Storage is the abstraction that manages chunk storage.
 type Storage struct {
-	objC obj.Client
+	objC        obj.Client
+	compression CompressionAlgo
 }
 
 // NewStorage creates a new Storage.
@@ -49,7 +49,7 @@ func (s *Storage) NewReader(ctx context.Context, dataRefs ...*DataRef) *Reader {
 // object storage.
 // The callback arguments are the chunk hash and annotations.
 func (s *Storage) NewWriter(ctx context.Context, averageBits int, seed int64, f WriterFunc) *Writer {
-	return newWriter(ctx, s.objC, averageBits, f, seed)
+	return newWriter(ctx, s.objC, averageBits, f, seed, s.compression)
 }
 
 // List lists all of the chunks in object storage.
@@ -64,7 +64,14 @@ func (s *Storage) DeleteAll(ctx context.Context) error {
 	})
 }
 
-// Delete deletes a chunk in object storage.
+// Delete deletes a chunk in object storage, with each of the codecs that it's
+// compressed with.
 func (s *Storage) Delete(ctx context.Context, hash string) error {
-	return s.objC.Delete(ctx, path.Join(prefix, hash))
+	for algo := range CompressionAlgo_name {
+		p := chunkPath(&ChunkInfo{Chunk: &Chunk{Hash: hash}, Compression: CompressionAlgo(algo)})
+		if err := s.objC.Delete(ctx, p); err != nil && !s.objC.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
 }
@@ -2,10 +2,8 @@ package chunk
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"io"
-	"path"
 
 	"github.com/chmduquesne/rollinghash/buzhash64"
 	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
@@ -79,6 +77,7 @@ func newNextChanSet(c *chanSet) *nextChanSet {
 type worker struct {
 	ctx                  context.Context
 	objC                 obj.Client
+	compression          CompressionAlgo
 	hash                 *buzhash64.Buzhash64
 	splitMask            uint64
 	first                bool
@@ -238,8 +237,13 @@ func (w *worker) put(edge bool) error {
 	for _, a := range w.annotations {
 		chunkBytes = append(chunkBytes, a.buf.Bytes()...)
 	}
-	chunk := &Chunk{Hash: hash.EncodeHash(hash.Sum(chunkBytes))}
-	path := path.Join(prefix, chunk.Hash)
+	chunkInfo := &ChunkInfo{
+		Chunk:       &Chunk{Hash: hash.EncodeHash(hash.Sum(chunkBytes))},
+		SizeBytes:   int64(len(chunkBytes)),
+		Edge:        edge,
+		Compression: w.compression,
+	}
+	path := chunkPath(chunkInfo)
 	// If the chunk does not exist, upload it.
 	if !w.objC.Exists(w.ctx, path) {
 		if err := w.upload(path, chunkBytes); err != nil {
@@ -247,11 +251,7 @@ func (w *worker) put(edge bool) error {
 		}
 	}
 	chunkRef := &DataRef{
-		ChunkInfo: &ChunkInfo{
-			Chunk:     chunk,
-			SizeBytes: int64(len(chunkBytes)),
-			Edge:      edge,
-		},
+		ChunkInfo: chunkInfo,
 		SizeBytes: int64(len(chunkBytes)),
 	}
 	// Update the annotations for the current chunk.
@@ -280,19 +280,27 @@ func (w *worker) updateAnnotations(chunkRef *DataRef) {
 	}
 }
 
-func (w *worker) upload(path string, chunk []byte) error {
+func (w *worker) upload(path string, chunk []byte) (retErr error) {
 	objW, err := w.objC.Writer(w.ctx, path)
 	if err != nil {
 		return err
 	}
-	defer objW.Close()
-	gzipW, err := gzip.NewWriterLevel(objW, gzip.BestSpeed)
+	defer func() {
+		if err := objW.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	compressW, err := compress(w.compression, objW)
 	if err != nil {
 		return err
 	}
-	defer gzipW.Close()
+	defer func() {
+		if err := compressW.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
 	// (bryce) Encrypt?
-	_, err = io.Copy(gzipW, bytes.NewReader(chunk))
+	_, err = io.Copy(compressW, bytes.NewReader(chunk))
 	return err
 }
 
@@ -416,19 +424,20 @@ type Writer struct {
 	stats          *stats
 }
 
-func newWriter(ctx context.Context, objC obj.Client, averageBits int, f WriterFunc, seed int64) *Writer {
+func newWriter(ctx context.Context, objC obj.Client, averageBits int, f WriterFunc, seed int64, compression CompressionAlgo) *Writer {
 	stats := &stats{}
 	newWorkerFunc := func(ctx context.Context, prev *prevChanSet, next *nextChanSet) *worker {
 		w := &worker{
-			ctx:       ctx,
-			objC:      objC,
-			hash:      buzhash64.NewFromUint64Array(buzhash64.GenerateHashes(seed)),
-			splitMask: (1 << uint64(averageBits)) - 1,
-			first:     true,
-			prev:      prev,
-			next:      next,
-			f:         f,
-			stats:     stats,
+			ctx:         ctx,
+			objC:        objC,
+			compression: compression,
+			hash:        buzhash64.NewFromUint64Array(buzhash64.GenerateHashes(seed)),
+			splitMask:   (1 << uint64(averageBits)) - 1,
+			first:       true,
+			prev:        prev,
+			next:        next,
+			f:           f,
+			stats:       stats,
 		}
 		w.hash.Reset()
 		w.hash.Write(initialWindow)
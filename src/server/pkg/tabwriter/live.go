@@ -0,0 +1,129 @@
+package tabwriter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/juju/ansiterm"
+)
+
+const (
+	// redrawInterval is the minimum time between redraws of a LiveTable
+	redrawInterval = 500 * time.Millisecond
+	// clearScreen moves the cursor to the top left and clears the terminal
+	clearScreen = "\033[H\033[2J"
+)
+
+type liveRow struct {
+	sortKey string
+	line    string
+}
+
+// LiveTable is a table whose rows change over time (e.g. as they're updated
+// by a watch). It's redrawn in place, by clearing the terminal, whenever its
+// rows change. Only the first termHeight rows are drawn.
+type LiveTable struct {
+	w       io.Writer
+	header  string
+	reverse bool
+
+	mu    sync.Mutex
+	rows  map[string]*liveRow
+	dirty bool
+}
+
+// NewLiveTable returns a new LiveTable that draws to 'w'. Rows are sorted by
+// their sort key, in descending order if 'reverse' is true. NewLiveTable will
+// panic if it's given a header that doesn't end in \n.
+func NewLiveTable(w io.Writer, header string, reverse bool) *LiveTable {
+	if header[len(header)-1] != '\n' {
+		panic("header must end in a new line")
+	}
+	return &LiveTable{
+		w:       w,
+		header:  header,
+		reverse: reverse,
+		rows:    make(map[string]*liveRow),
+	}
+}
+
+// SortableTimestamp formats 't' so that timestamps sort lexicographically in
+// chronological order (nil sorts first), for use as a LiveTable sort key
+func SortableTimestamp(t *types.Timestamp) string {
+	if t == nil {
+		return ""
+	}
+	return fmt.Sprintf("%020d.%09d", t.Seconds, t.Nanos)
+}
+
+// Set adds the row identified by 'key', or replaces it if it already exists.
+// 'line' is the row's content, and must end in \n.
+func (t *LiveTable) Set(key, sortKey, line string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rows[key] = &liveRow{sortKey: sortKey, line: line}
+	t.dirty = true
+}
+
+// Run calls 'watch' (which should call Set as rows change) and redraws the
+// table periodically until 'watch' returns
+func (t *LiveTable) Run(watch func() error) error {
+	done := make(chan struct{})
+	redrawn := make(chan struct{})
+	go func() {
+		defer close(redrawn)
+		ticker := time.NewTicker(redrawInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.draw()
+			case <-done:
+				t.draw()
+				return
+			}
+		}
+	}()
+	err := watch()
+	close(done)
+	<-redrawn
+	return err
+}
+
+// draw redraws the table if its rows have changed since the last draw
+func (t *LiveTable) draw() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.dirty {
+		return
+	}
+	t.dirty = false
+	rows := make([]*liveRow, 0, len(t.rows))
+	for _, row := range t.rows {
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if t.reverse {
+			return rows[i].sortKey > rows[j].sortKey
+		}
+		return rows[i].sortKey < rows[j].sortKey
+	})
+	var buf bytes.Buffer
+	buf.WriteString(clearScreen)
+	tw := ansiterm.NewTabWriter(&buf, 0, 1, 1, ' ', 0)
+	tw.Write([]byte(t.header))
+	for i, row := range rows {
+		if i == termHeight-2 {
+			fmt.Fprintf(tw, "(%d more)\n", len(rows)-i)
+			break
+		}
+		tw.Write([]byte(row.line))
+	}
+	tw.Flush()
+	t.w.Write(buf.Bytes())
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/auth"
 	"github.com/pachyderm/pachyderm/src/client/enterprise"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errcode"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
 	"github.com/pachyderm/pachyderm/src/client/pps"
 	"github.com/pachyderm/pachyderm/src/client/transaction"
@@ -119,6 +120,8 @@ type modifyMembersFunc func(context.Context, *auth.ModifyMembersRequest) (*auth.
 type getGroupsFunc func(context.Context, *auth.GetGroupsRequest) (*auth.GetGroupsResponse, error)
 type getUsersFunc func(context.Context, *auth.GetUsersRequest) (*auth.GetUsersResponse, error)
 type getOneTimePasswordFunc func(context.Context, *auth.GetOneTimePasswordRequest) (*auth.GetOneTimePasswordResponse, error)
+type getOIDCLoginFunc func(context.Context, *auth.GetOIDCLoginRequest) (*auth.GetOIDCLoginResponse, error)
+type listAuditEventsFunc func(*auth.ListAuditEventsRequest, auth.API_ListAuditEventsServer) error
 
 type mockActivateAuth struct{ handler activateAuthFunc }
 type mockDeactivateAuth struct{ handler deactivateAuthFunc }
@@ -141,6 +144,8 @@ type mockModifyMembers struct{ handler modifyMembersFunc }
 type mockGetGroups struct{ handler getGroupsFunc }
 type mockGetUsers struct{ handler getUsersFunc }
 type mockGetOneTimePassword struct{ handler getOneTimePasswordFunc }
+type mockGetOIDCLogin struct{ handler getOIDCLoginFunc }
+type mockListAuditEvents struct{ handler listAuditEventsFunc }
 
 func (mock *mockActivateAuth) Use(cb activateAuthFunc)             { mock.handler = cb }
 func (mock *mockDeactivateAuth) Use(cb deactivateAuthFunc)         { mock.handler = cb }
@@ -163,6 +168,8 @@ func (mock *mockModifyMembers) Use(cb modifyMembersFunc)           { mock.handle
 func (mock *mockGetGroups) Use(cb getGroupsFunc)                   { mock.handler = cb }
 func (mock *mockGetUsers) Use(cb getUsersFunc)                     { mock.handler = cb }
 func (mock *mockGetOneTimePassword) Use(cb getOneTimePasswordFunc) { mock.handler = cb }
+func (mock *mockGetOIDCLogin) Use(cb getOIDCLoginFunc)             { mock.handler = cb }
+func (mock *mockListAuditEvents) Use(cb listAuditEventsFunc)       { mock.handler = cb }
 
 type authServerAPI struct {
 	mock *mockAuthServer
@@ -191,6 +198,8 @@ type mockAuthServer struct {
 	GetGroups          mockGetGroups
 	GetUsers           mockGetUsers
 	GetOneTimePassword mockGetOneTimePassword
+	GetOIDCLogin       mockGetOIDCLogin
+	ListAuditEvents    mockListAuditEvents
 }
 
 func (api *authServerAPI) Activate(ctx context.Context, req *auth.ActivateRequest) (*auth.ActivateResponse, error) {
@@ -319,6 +328,18 @@ func (api *authServerAPI) GetOneTimePassword(ctx context.Context, req *auth.GetO
 	}
 	return nil, fmt.Errorf("unhandled pachd mock auth.GetOneTimePassword")
 }
+func (api *authServerAPI) GetOIDCLogin(ctx context.Context, req *auth.GetOIDCLoginRequest) (*auth.GetOIDCLoginResponse, error) {
+	if api.mock.GetOIDCLogin.handler != nil {
+		return api.mock.GetOIDCLogin.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock auth.GetOIDCLogin")
+}
+func (api *authServerAPI) ListAuditEvents(req *auth.ListAuditEventsRequest, serv auth.API_ListAuditEventsServer) error {
+	if api.mock.ListAuditEvents.handler != nil {
+		return api.mock.ListAuditEvents.handler(req, serv)
+	}
+	return fmt.Errorf("unhandled pachd mock auth.ListAuditEvents")
+}
 
 /* Enterprise Server Mocks */
 
@@ -375,6 +396,7 @@ type finishCommitFunc func(context.Context, *pfs.FinishCommitRequest) (*types.Em
 type inspectCommitFunc func(context.Context, *pfs.InspectCommitRequest) (*pfs.CommitInfo, error)
 type listCommitFunc func(context.Context, *pfs.ListCommitRequest) (*pfs.CommitInfos, error)
 type listCommitStreamFunc func(*pfs.ListCommitRequest, pfs.API_ListCommitStreamServer) error
+type watchCommitFunc func(*pfs.ListCommitRequest, pfs.API_WatchCommitServer) error
 type deleteCommitFunc func(context.Context, *pfs.DeleteCommitRequest) (*types.Empty, error)
 type flushCommitFunc func(*pfs.FlushCommitRequest, pfs.API_FlushCommitServer) error
 type subscribeCommitFunc func(*pfs.SubscribeCommitRequest, pfs.API_SubscribeCommitServer) error
@@ -382,8 +404,14 @@ type buildCommitFunc func(context.Context, *pfs.BuildCommitRequest) (*pfs.Commit
 type createBranchFunc func(context.Context, *pfs.CreateBranchRequest) (*types.Empty, error)
 type inspectBranchFunc func(context.Context, *pfs.InspectBranchRequest) (*pfs.BranchInfo, error)
 type listBranchFunc func(context.Context, *pfs.ListBranchRequest) (*pfs.BranchInfos, error)
+type watchBranchFunc func(*pfs.ListBranchRequest, pfs.API_WatchBranchServer) error
 type deleteBranchFunc func(context.Context, *pfs.DeleteBranchRequest) (*types.Empty, error)
 type putFileFunc func(pfs.API_PutFileServer) error
+type startUploadFunc func(context.Context, *pfs.StartUploadRequest) (*pfs.Upload, error)
+type uploadChunkFunc func(pfs.API_UploadChunkServer) error
+type inspectUploadFunc func(context.Context, *pfs.InspectUploadRequest) (*pfs.UploadInfo, error)
+type finishUploadFunc func(context.Context, *pfs.FinishUploadRequest) (*types.Empty, error)
+type deleteUploadFunc func(context.Context, *pfs.DeleteUploadRequest) (*types.Empty, error)
 type copyFileFunc func(context.Context, *pfs.CopyFileRequest) (*types.Empty, error)
 type getFileFunc func(*pfs.GetFileRequest, pfs.API_GetFileServer) error
 type inspectFileFunc func(context.Context, *pfs.InspectFileRequest) (*pfs.FileInfo, error)
@@ -394,10 +422,19 @@ type globFileFunc func(context.Context, *pfs.GlobFileRequest) (*pfs.FileInfos, e
 type globFileStreamFunc func(*pfs.GlobFileRequest, pfs.API_GlobFileStreamServer) error
 type diffFileFunc func(context.Context, *pfs.DiffFileRequest) (*pfs.DiffFileResponse, error)
 type deleteFileFunc func(context.Context, *pfs.DeleteFileRequest) (*types.Empty, error)
+type deleteFilesFunc func(context.Context, *pfs.DeleteFilesRequest) (*errcode.BulkResponse, error)
 type deleteAllPFSFunc func(context.Context, *types.Empty) (*types.Empty, error)
 type fsckFunc func(*pfs.FsckRequest, pfs.API_FsckServer) error
 type putTarFunc func(pfs.API_PutTarServer) error
 type getTarFunc func(*pfs.GetTarRequest, pfs.API_GetTarServer) error
+type setBranchRetentionFunc func(context.Context, *pfs.SetBranchRetentionRequest) (*types.Empty, error)
+type setBranchTriggerFunc func(context.Context, *pfs.SetBranchTriggerRequest) (*types.Empty, error)
+type getStorageUsageFunc func(context.Context, *pfs.GetStorageUsageRequest) (*pfs.StorageUsage, error)
+type diffFileStreamFunc func(*pfs.DiffFileRequest, pfs.API_DiffFileStreamServer) error
+type createModelVersionFunc func(context.Context, *pfs.CreateModelVersionRequest) (*pfs.ModelVersion, error)
+type promoteModelFunc func(context.Context, *pfs.PromoteModelRequest) (*pfs.ModelVersion, error)
+type inspectModelFunc func(context.Context, *pfs.InspectModelRequest) (*pfs.ModelInfo, error)
+type resolveModelFunc func(context.Context, *pfs.ResolveModelRequest) (*pfs.ModelVersion, error)
 
 type mockCreateRepo struct{ handler createRepoFunc }
 type mockInspectRepo struct{ handler inspectRepoFunc }
@@ -408,6 +445,7 @@ type mockFinishCommit struct{ handler finishCommitFunc }
 type mockInspectCommit struct{ handler inspectCommitFunc }
 type mockListCommit struct{ handler listCommitFunc }
 type mockListCommitStream struct{ handler listCommitStreamFunc }
+type mockWatchCommit struct{ handler watchCommitFunc }
 type mockDeleteCommit struct{ handler deleteCommitFunc }
 type mockFlushCommit struct{ handler flushCommitFunc }
 type mockSubscribeCommit struct{ handler subscribeCommitFunc }
@@ -415,8 +453,14 @@ type mockBuildCommit struct{ handler buildCommitFunc }
 type mockCreateBranch struct{ handler createBranchFunc }
 type mockInspectBranch struct{ handler inspectBranchFunc }
 type mockListBranch struct{ handler listBranchFunc }
+type mockWatchBranch struct{ handler watchBranchFunc }
 type mockDeleteBranch struct{ handler deleteBranchFunc }
 type mockPutFile struct{ handler putFileFunc }
+type mockStartUpload struct{ handler startUploadFunc }
+type mockUploadChunk struct{ handler uploadChunkFunc }
+type mockInspectUpload struct{ handler inspectUploadFunc }
+type mockFinishUpload struct{ handler finishUploadFunc }
+type mockDeleteUpload struct{ handler deleteUploadFunc }
 type mockCopyFile struct{ handler copyFileFunc }
 type mockGetFile struct{ handler getFileFunc }
 type mockInspectFile struct{ handler inspectFileFunc }
@@ -427,82 +471,123 @@ type mockGlobFile struct{ handler globFileFunc }
 type mockGlobFileStream struct{ handler globFileStreamFunc }
 type mockDiffFile struct{ handler diffFileFunc }
 type mockDeleteFile struct{ handler deleteFileFunc }
+type mockDeleteFiles struct{ handler deleteFilesFunc }
 type mockDeleteAllPFS struct{ handler deleteAllPFSFunc }
 type mockFsck struct{ handler fsckFunc }
 type mockPutTar struct{ handler putTarFunc }
 type mockGetTar struct{ handler getTarFunc }
-
-func (mock *mockCreateRepo) Use(cb createRepoFunc)             { mock.handler = cb }
-func (mock *mockInspectRepo) Use(cb inspectRepoFunc)           { mock.handler = cb }
-func (mock *mockListRepo) Use(cb listRepoFunc)                 { mock.handler = cb }
-func (mock *mockDeleteRepo) Use(cb deleteRepoFunc)             { mock.handler = cb }
-func (mock *mockStartCommit) Use(cb startCommitFunc)           { mock.handler = cb }
-func (mock *mockFinishCommit) Use(cb finishCommitFunc)         { mock.handler = cb }
-func (mock *mockInspectCommit) Use(cb inspectCommitFunc)       { mock.handler = cb }
-func (mock *mockListCommit) Use(cb listCommitFunc)             { mock.handler = cb }
-func (mock *mockListCommitStream) Use(cb listCommitStreamFunc) { mock.handler = cb }
-func (mock *mockDeleteCommit) Use(cb deleteCommitFunc)         { mock.handler = cb }
-func (mock *mockFlushCommit) Use(cb flushCommitFunc)           { mock.handler = cb }
-func (mock *mockSubscribeCommit) Use(cb subscribeCommitFunc)   { mock.handler = cb }
-func (mock *mockBuildCommit) Use(cb buildCommitFunc)           { mock.handler = cb }
-func (mock *mockCreateBranch) Use(cb createBranchFunc)         { mock.handler = cb }
-func (mock *mockInspectBranch) Use(cb inspectBranchFunc)       { mock.handler = cb }
-func (mock *mockListBranch) Use(cb listBranchFunc)             { mock.handler = cb }
-func (mock *mockDeleteBranch) Use(cb deleteBranchFunc)         { mock.handler = cb }
-func (mock *mockPutFile) Use(cb putFileFunc)                   { mock.handler = cb }
-func (mock *mockCopyFile) Use(cb copyFileFunc)                 { mock.handler = cb }
-func (mock *mockGetFile) Use(cb getFileFunc)                   { mock.handler = cb }
-func (mock *mockInspectFile) Use(cb inspectFileFunc)           { mock.handler = cb }
-func (mock *mockListFile) Use(cb listFileFunc)                 { mock.handler = cb }
-func (mock *mockListFileStream) Use(cb listFileStreamFunc)     { mock.handler = cb }
-func (mock *mockWalkFile) Use(cb walkFileFunc)                 { mock.handler = cb }
-func (mock *mockGlobFile) Use(cb globFileFunc)                 { mock.handler = cb }
-func (mock *mockGlobFileStream) Use(cb globFileStreamFunc)     { mock.handler = cb }
-func (mock *mockDiffFile) Use(cb diffFileFunc)                 { mock.handler = cb }
-func (mock *mockDeleteFile) Use(cb deleteFileFunc)             { mock.handler = cb }
-func (mock *mockDeleteAllPFS) Use(cb deleteAllPFSFunc)         { mock.handler = cb }
-func (mock *mockFsck) Use(cb fsckFunc)                         { mock.handler = cb }
-func (mock *mockPutTar) Use(cb putTarFunc)                     { mock.handler = cb }
-func (mock *mockGetTar) Use(cb getTarFunc)                     { mock.handler = cb }
+type mockSetBranchRetention struct{ handler setBranchRetentionFunc }
+type mockSetBranchTrigger struct{ handler setBranchTriggerFunc }
+type mockGetStorageUsage struct{ handler getStorageUsageFunc }
+type mockDiffFileStream struct{ handler diffFileStreamFunc }
+type mockCreateModelVersion struct{ handler createModelVersionFunc }
+type mockPromoteModel struct{ handler promoteModelFunc }
+type mockInspectModel struct{ handler inspectModelFunc }
+type mockResolveModel struct{ handler resolveModelFunc }
+
+func (mock *mockCreateRepo) Use(cb createRepoFunc)                 { mock.handler = cb }
+func (mock *mockInspectRepo) Use(cb inspectRepoFunc)               { mock.handler = cb }
+func (mock *mockListRepo) Use(cb listRepoFunc)                     { mock.handler = cb }
+func (mock *mockDeleteRepo) Use(cb deleteRepoFunc)                 { mock.handler = cb }
+func (mock *mockStartCommit) Use(cb startCommitFunc)               { mock.handler = cb }
+func (mock *mockFinishCommit) Use(cb finishCommitFunc)             { mock.handler = cb }
+func (mock *mockInspectCommit) Use(cb inspectCommitFunc)           { mock.handler = cb }
+func (mock *mockListCommit) Use(cb listCommitFunc)                 { mock.handler = cb }
+func (mock *mockListCommitStream) Use(cb listCommitStreamFunc)     { mock.handler = cb }
+func (mock *mockWatchCommit) Use(cb watchCommitFunc)               { mock.handler = cb }
+func (mock *mockDeleteCommit) Use(cb deleteCommitFunc)             { mock.handler = cb }
+func (mock *mockFlushCommit) Use(cb flushCommitFunc)               { mock.handler = cb }
+func (mock *mockSubscribeCommit) Use(cb subscribeCommitFunc)       { mock.handler = cb }
+func (mock *mockBuildCommit) Use(cb buildCommitFunc)               { mock.handler = cb }
+func (mock *mockCreateBranch) Use(cb createBranchFunc)             { mock.handler = cb }
+func (mock *mockInspectBranch) Use(cb inspectBranchFunc)           { mock.handler = cb }
+func (mock *mockListBranch) Use(cb listBranchFunc)                 { mock.handler = cb }
+func (mock *mockWatchBranch) Use(cb watchBranchFunc)               { mock.handler = cb }
+func (mock *mockDeleteBranch) Use(cb deleteBranchFunc)             { mock.handler = cb }
+func (mock *mockPutFile) Use(cb putFileFunc)                       { mock.handler = cb }
+func (mock *mockStartUpload) Use(cb startUploadFunc)               { mock.handler = cb }
+func (mock *mockUploadChunk) Use(cb uploadChunkFunc)               { mock.handler = cb }
+func (mock *mockInspectUpload) Use(cb inspectUploadFunc)           { mock.handler = cb }
+func (mock *mockFinishUpload) Use(cb finishUploadFunc)             { mock.handler = cb }
+func (mock *mockDeleteUpload) Use(cb deleteUploadFunc)             { mock.handler = cb }
+func (mock *mockCopyFile) Use(cb copyFileFunc)                     { mock.handler = cb }
+func (mock *mockGetFile) Use(cb getFileFunc)                       { mock.handler = cb }
+func (mock *mockInspectFile) Use(cb inspectFileFunc)               { mock.handler = cb }
+func (mock *mockListFile) Use(cb listFileFunc)                     { mock.handler = cb }
+func (mock *mockListFileStream) Use(cb listFileStreamFunc)         { mock.handler = cb }
+func (mock *mockWalkFile) Use(cb walkFileFunc)                     { mock.handler = cb }
+func (mock *mockGlobFile) Use(cb globFileFunc)                     { mock.handler = cb }
+func (mock *mockGlobFileStream) Use(cb globFileStreamFunc)         { mock.handler = cb }
+func (mock *mockDiffFile) Use(cb diffFileFunc)                     { mock.handler = cb }
+func (mock *mockDeleteFile) Use(cb deleteFileFunc)                 { mock.handler = cb }
+func (mock *mockDeleteFiles) Use(cb deleteFilesFunc)               { mock.handler = cb }
+func (mock *mockDeleteAllPFS) Use(cb deleteAllPFSFunc)             { mock.handler = cb }
+func (mock *mockFsck) Use(cb fsckFunc)                             { mock.handler = cb }
+func (mock *mockPutTar) Use(cb putTarFunc)                         { mock.handler = cb }
+func (mock *mockGetTar) Use(cb getTarFunc)                         { mock.handler = cb }
+func (mock *mockSetBranchRetention) Use(cb setBranchRetentionFunc) { mock.handler = cb }
+func (mock *mockSetBranchTrigger) Use(cb setBranchTriggerFunc)     { mock.handler = cb }
+func (mock *mockGetStorageUsage) Use(cb getStorageUsageFunc)       { mock.handler = cb }
+func (mock *mockDiffFileStream) Use(cb diffFileStreamFunc)         { mock.handler = cb }
+func (mock *mockCreateModelVersion) Use(cb createModelVersionFunc) { mock.handler = cb }
+func (mock *mockPromoteModel) Use(cb promoteModelFunc)             { mock.handler = cb }
+func (mock *mockInspectModel) Use(cb inspectModelFunc)             { mock.handler = cb }
+func (mock *mockResolveModel) Use(cb resolveModelFunc)             { mock.handler = cb }
 
 type pfsServerAPI struct {
 	mock *mockPFSServer
 }
 
 type mockPFSServer struct {
-	api              pfsServerAPI
-	CreateRepo       mockCreateRepo
-	InspectRepo      mockInspectRepo
-	ListRepo         mockListRepo
-	DeleteRepo       mockDeleteRepo
-	StartCommit      mockStartCommit
-	FinishCommit     mockFinishCommit
-	InspectCommit    mockInspectCommit
-	ListCommit       mockListCommit
-	ListCommitStream mockListCommitStream
-	DeleteCommit     mockDeleteCommit
-	FlushCommit      mockFlushCommit
-	SubscribeCommit  mockSubscribeCommit
-	BuildCommit      mockBuildCommit
-	CreateBranch     mockCreateBranch
-	InspectBranch    mockInspectBranch
-	ListBranch       mockListBranch
-	DeleteBranch     mockDeleteBranch
-	PutFile          mockPutFile
-	CopyFile         mockCopyFile
-	GetFile          mockGetFile
-	InspectFile      mockInspectFile
-	ListFile         mockListFile
-	ListFileStream   mockListFileStream
-	WalkFile         mockWalkFile
-	GlobFile         mockGlobFile
-	GlobFileStream   mockGlobFileStream
-	DiffFile         mockDiffFile
-	DeleteFile       mockDeleteFile
-	DeleteAll        mockDeleteAllPFS
-	Fsck             mockFsck
-	PutTar           mockPutTar
-	GetTar           mockGetTar
+	api                pfsServerAPI
+	CreateRepo         mockCreateRepo
+	InspectRepo        mockInspectRepo
+	ListRepo           mockListRepo
+	DeleteRepo         mockDeleteRepo
+	StartCommit        mockStartCommit
+	FinishCommit       mockFinishCommit
+	InspectCommit      mockInspectCommit
+	ListCommit         mockListCommit
+	ListCommitStream   mockListCommitStream
+	WatchCommit        mockWatchCommit
+	DeleteCommit       mockDeleteCommit
+	FlushCommit        mockFlushCommit
+	SubscribeCommit    mockSubscribeCommit
+	BuildCommit        mockBuildCommit
+	CreateBranch       mockCreateBranch
+	InspectBranch      mockInspectBranch
+	ListBranch         mockListBranch
+	WatchBranch        mockWatchBranch
+	DeleteBranch       mockDeleteBranch
+	PutFile            mockPutFile
+	StartUpload        mockStartUpload
+	UploadChunk        mockUploadChunk
+	InspectUpload      mockInspectUpload
+	FinishUpload       mockFinishUpload
+	DeleteUpload       mockDeleteUpload
+	CopyFile           mockCopyFile
+	GetFile            mockGetFile
+	InspectFile        mockInspectFile
+	ListFile           mockListFile
+	ListFileStream     mockListFileStream
+	WalkFile           mockWalkFile
+	GlobFile           mockGlobFile
+	GlobFileStream     mockGlobFileStream
+	DiffFile           mockDiffFile
+	DeleteFile         mockDeleteFile
+	DeleteFiles        mockDeleteFiles
+	DeleteAll          mockDeleteAllPFS
+	Fsck               mockFsck
+	PutTar             mockPutTar
+	GetTar             mockGetTar
+	SetBranchRetention mockSetBranchRetention
+	SetBranchTrigger   mockSetBranchTrigger
+	GetStorageUsage    mockGetStorageUsage
+	DiffFileStream     mockDiffFileStream
+	CreateModelVersion mockCreateModelVersion
+	PromoteModel       mockPromoteModel
+	InspectModel       mockInspectModel
+	ResolveModel       mockResolveModel
 }
 
 func (api *pfsServerAPI) CreateRepo(ctx context.Context, req *pfs.CreateRepoRequest) (*types.Empty, error) {
@@ -559,6 +644,12 @@ func (api *pfsServerAPI) ListCommitStream(req *pfs.ListCommitRequest, serv pfs.A
 	}
 	return fmt.Errorf("unhandled pachd mock pfs.ListCommitStream")
 }
+func (api *pfsServerAPI) WatchCommit(req *pfs.ListCommitRequest, serv pfs.API_WatchCommitServer) error {
+	if api.mock.WatchCommit.handler != nil {
+		return api.mock.WatchCommit.handler(req, serv)
+	}
+	return fmt.Errorf("unhandled pachd mock pfs.WatchCommit")
+}
 func (api *pfsServerAPI) DeleteCommit(ctx context.Context, req *pfs.DeleteCommitRequest) (*types.Empty, error) {
 	if api.mock.DeleteCommit.handler != nil {
 		return api.mock.DeleteCommit.handler(ctx, req)
@@ -601,6 +692,12 @@ func (api *pfsServerAPI) ListBranch(ctx context.Context, req *pfs.ListBranchRequ
 	}
 	return nil, fmt.Errorf("unhandled pachd mock pfs.ListBranch")
 }
+func (api *pfsServerAPI) WatchBranch(req *pfs.ListBranchRequest, serv pfs.API_WatchBranchServer) error {
+	if api.mock.WatchBranch.handler != nil {
+		return api.mock.WatchBranch.handler(req, serv)
+	}
+	return fmt.Errorf("unhandled pachd mock pfs.WatchBranch")
+}
 func (api *pfsServerAPI) DeleteBranch(ctx context.Context, req *pfs.DeleteBranchRequest) (*types.Empty, error) {
 	if api.mock.DeleteBranch.handler != nil {
 		return api.mock.DeleteBranch.handler(ctx, req)
@@ -613,6 +710,36 @@ func (api *pfsServerAPI) PutFile(serv pfs.API_PutFileServer) error {
 	}
 	return fmt.Errorf("unhandled pachd mock pfs.PutFile")
 }
+func (api *pfsServerAPI) StartUpload(ctx context.Context, req *pfs.StartUploadRequest) (*pfs.Upload, error) {
+	if api.mock.StartUpload.handler != nil {
+		return api.mock.StartUpload.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pfs.StartUpload")
+}
+func (api *pfsServerAPI) UploadChunk(serv pfs.API_UploadChunkServer) error {
+	if api.mock.UploadChunk.handler != nil {
+		return api.mock.UploadChunk.handler(serv)
+	}
+	return fmt.Errorf("unhandled pachd mock pfs.UploadChunk")
+}
+func (api *pfsServerAPI) InspectUpload(ctx context.Context, req *pfs.InspectUploadRequest) (*pfs.UploadInfo, error) {
+	if api.mock.InspectUpload.handler != nil {
+		return api.mock.InspectUpload.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pfs.InspectUpload")
+}
+func (api *pfsServerAPI) FinishUpload(ctx context.Context, req *pfs.FinishUploadRequest) (*types.Empty, error) {
+	if api.mock.FinishUpload.handler != nil {
+		return api.mock.FinishUpload.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pfs.FinishUpload")
+}
+func (api *pfsServerAPI) DeleteUpload(ctx context.Context, req *pfs.DeleteUploadRequest) (*types.Empty, error) {
+	if api.mock.DeleteUpload.handler != nil {
+		return api.mock.DeleteUpload.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pfs.DeleteUpload")
+}
 func (api *pfsServerAPI) CopyFile(ctx context.Context, req *pfs.CopyFileRequest) (*types.Empty, error) {
 	if api.mock.CopyFile.handler != nil {
 		return api.mock.CopyFile.handler(ctx, req)
@@ -673,6 +800,12 @@ func (api *pfsServerAPI) DeleteFile(ctx context.Context, req *pfs.DeleteFileRequ
 	}
 	return nil, fmt.Errorf("unhandled pachd mock pfs.DeleteFile")
 }
+func (api *pfsServerAPI) DeleteFiles(ctx context.Context, req *pfs.DeleteFilesRequest) (*errcode.BulkResponse, error) {
+	if api.mock.DeleteFiles.handler != nil {
+		return api.mock.DeleteFiles.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pfs.DeleteFiles")
+}
 func (api *pfsServerAPI) DeleteAll(ctx context.Context, req *types.Empty) (*types.Empty, error) {
 	if api.mock.DeleteAll.handler != nil {
 		return api.mock.DeleteAll.handler(ctx, req)
@@ -697,6 +830,54 @@ func (api *pfsServerAPI) GetTar(req *pfs.GetTarRequest, serv pfs.API_GetTarServe
 	}
 	return fmt.Errorf("unhandled pachd mock pfs.GetTar")
 }
+func (api *pfsServerAPI) SetBranchRetention(ctx context.Context, req *pfs.SetBranchRetentionRequest) (*types.Empty, error) {
+	if api.mock.SetBranchRetention.handler != nil {
+		return api.mock.SetBranchRetention.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pfs.SetBranchRetention")
+}
+func (api *pfsServerAPI) SetBranchTrigger(ctx context.Context, req *pfs.SetBranchTriggerRequest) (*types.Empty, error) {
+	if api.mock.SetBranchTrigger.handler != nil {
+		return api.mock.SetBranchTrigger.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pfs.SetBranchTrigger")
+}
+func (api *pfsServerAPI) GetStorageUsage(ctx context.Context, req *pfs.GetStorageUsageRequest) (*pfs.StorageUsage, error) {
+	if api.mock.GetStorageUsage.handler != nil {
+		return api.mock.GetStorageUsage.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pfs.GetStorageUsage")
+}
+func (api *pfsServerAPI) DiffFileStream(req *pfs.DiffFileRequest, serv pfs.API_DiffFileStreamServer) error {
+	if api.mock.DiffFileStream.handler != nil {
+		return api.mock.DiffFileStream.handler(req, serv)
+	}
+	return fmt.Errorf("unhandled pachd mock pfs.DiffFileStream")
+}
+func (api *pfsServerAPI) CreateModelVersion(ctx context.Context, req *pfs.CreateModelVersionRequest) (*pfs.ModelVersion, error) {
+	if api.mock.CreateModelVersion.handler != nil {
+		return api.mock.CreateModelVersion.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pfs.CreateModelVersion")
+}
+func (api *pfsServerAPI) PromoteModel(ctx context.Context, req *pfs.PromoteModelRequest) (*pfs.ModelVersion, error) {
+	if api.mock.PromoteModel.handler != nil {
+		return api.mock.PromoteModel.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pfs.PromoteModel")
+}
+func (api *pfsServerAPI) InspectModel(ctx context.Context, req *pfs.InspectModelRequest) (*pfs.ModelInfo, error) {
+	if api.mock.InspectModel.handler != nil {
+		return api.mock.InspectModel.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pfs.InspectModel")
+}
+func (api *pfsServerAPI) ResolveModel(ctx context.Context, req *pfs.ResolveModelRequest) (*pfs.ModelVersion, error) {
+	if api.mock.ResolveModel.handler != nil {
+		return api.mock.ResolveModel.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pfs.ResolveModel")
+}
 
 /* PPS Server Mocks */
 
@@ -713,6 +894,7 @@ type listDatumFunc func(context.Context, *pps.ListDatumRequest) (*pps.ListDatumR
 type listDatumStreamFunc func(*pps.ListDatumRequest, pps.API_ListDatumStreamServer) error
 type restartDatumFunc func(context.Context, *pps.RestartDatumRequest) (*types.Empty, error)
 type createPipelineFunc func(context.Context, *pps.CreatePipelineRequest) (*types.Empty, error)
+type createPipelinesFunc func(context.Context, *pps.CreatePipelinesRequest) (*errcode.BulkResponse, error)
 type inspectPipelineFunc func(context.Context, *pps.InspectPipelineRequest) (*pps.PipelineInfo, error)
 type listPipelineFunc func(context.Context, *pps.ListPipelineRequest) (*pps.PipelineInfos, error)
 type deletePipelineFunc func(context.Context, *pps.DeletePipelineRequest) (*types.Empty, error)
@@ -728,6 +910,19 @@ type deleteAllPPSFunc func(context.Context, *types.Empty) (*types.Empty, error)
 type getLogsFunc func(*pps.GetLogsRequest, pps.API_GetLogsServer) error
 type garbageCollectFunc func(context.Context, *pps.GarbageCollectRequest) (*pps.GarbageCollectResponse, error)
 type activateAuthPPSFunc func(context.Context, *pps.ActivateAuthRequest) (*pps.ActivateAuthResponse, error)
+type instantiateTemplateFunc func(context.Context, *pps.InstantiateTemplateRequest) (*pps.InstantiateTemplateResponse, error)
+type jobProgressFunc func(*pps.JobProgressRequest, pps.API_JobProgressServer) error
+type watchJobFunc func(*pps.ListJobRequest, pps.API_WatchJobServer) error
+type watchPipelineFunc func(*pps.ListPipelineRequest, pps.API_WatchPipelineServer) error
+type listNamesFunc func(context.Context, *pps.ListNamesRequest) (*pps.ListNamesResponse, error)
+type rotateSecretFunc func(context.Context, *pps.RotateSecretRequest) (*types.Empty, error)
+type listStuckBranchesFunc func(context.Context, *pps.ListStuckBranchesRequest) (*pps.StuckBranches, error)
+type diagnoseFunc func(context.Context, *pps.DiagnoseRequest) (*pps.Diagnosis, error)
+type replayJobFunc func(context.Context, *pps.ReplayJobRequest) (*pps.ReplayJobResponse, error)
+type putArtifactFunc func(pps.API_PutArtifactServer) error
+type getArtifactFunc func(*pps.GetArtifactRequest, pps.API_GetArtifactServer) error
+type inspectImpactFunc func(context.Context, *pps.InspectImpactRequest) (*pps.Impact, error)
+type inspectGarbageCollectFunc func(context.Context, *pps.InspectGarbageCollectRequest) (*pps.GarbageCollectInfo, error)
 
 type mockCreateJob struct{ handler createJobFunc }
 type mockInspectJob struct{ handler inspectJobFunc }
@@ -742,6 +937,7 @@ type mockListDatum struct{ handler listDatumFunc }
 type mockListDatumStream struct{ handler listDatumStreamFunc }
 type mockRestartDatum struct{ handler restartDatumFunc }
 type mockCreatePipeline struct{ handler createPipelineFunc }
+type mockCreatePipelines struct{ handler createPipelinesFunc }
 type mockInspectPipeline struct{ handler inspectPipelineFunc }
 type mockListPipeline struct{ handler listPipelineFunc }
 type mockDeletePipeline struct{ handler deletePipelineFunc }
@@ -757,70 +953,111 @@ type mockDeleteAllPPS struct{ handler deleteAllPPSFunc }
 type mockGetLogs struct{ handler getLogsFunc }
 type mockGarbageCollect struct{ handler garbageCollectFunc }
 type mockActivateAuthPPS struct{ handler activateAuthPPSFunc }
-
-func (mock *mockCreateJob) Use(cb createJobFunc)             { mock.handler = cb }
-func (mock *mockInspectJob) Use(cb inspectJobFunc)           { mock.handler = cb }
-func (mock *mockListJob) Use(cb listJobFunc)                 { mock.handler = cb }
-func (mock *mockListJobStream) Use(cb listJobStreamFunc)     { mock.handler = cb }
-func (mock *mockFlushJob) Use(cb flushJobFunc)               { mock.handler = cb }
-func (mock *mockDeleteJob) Use(cb deleteJobFunc)             { mock.handler = cb }
-func (mock *mockStopJob) Use(cb stopJobFunc)                 { mock.handler = cb }
-func (mock *mockUpdateJobState) Use(cb updateJobStateFunc)   { mock.handler = cb }
-func (mock *mockInspectDatum) Use(cb inspectDatumFunc)       { mock.handler = cb }
-func (mock *mockListDatum) Use(cb listDatumFunc)             { mock.handler = cb }
-func (mock *mockListDatumStream) Use(cb listDatumStreamFunc) { mock.handler = cb }
-func (mock *mockRestartDatum) Use(cb restartDatumFunc)       { mock.handler = cb }
-func (mock *mockCreatePipeline) Use(cb createPipelineFunc)   { mock.handler = cb }
-func (mock *mockInspectPipeline) Use(cb inspectPipelineFunc) { mock.handler = cb }
-func (mock *mockListPipeline) Use(cb listPipelineFunc)       { mock.handler = cb }
-func (mock *mockDeletePipeline) Use(cb deletePipelineFunc)   { mock.handler = cb }
-func (mock *mockStartPipeline) Use(cb startPipelineFunc)     { mock.handler = cb }
-func (mock *mockStopPipeline) Use(cb stopPipelineFunc)       { mock.handler = cb }
-func (mock *mockRunPipeline) Use(cb runPipelineFunc)         { mock.handler = cb }
-func (mock *mockRunCron) Use(cb runCronFunc)                 { mock.handler = cb }
-func (mock *mockCreateSecret) Use(cb createSecretFunc)       { mock.handler = cb }
-func (mock *mockDeleteSecret) Use(cb deleteSecretFunc)       { mock.handler = cb }
-func (mock *mockInspectSecret) Use(cb inspectSecretFunc)     { mock.handler = cb }
-func (mock *mockListSecret) Use(cb listSecretFunc)           { mock.handler = cb }
-func (mock *mockDeleteAllPPS) Use(cb deleteAllPPSFunc)       { mock.handler = cb }
-func (mock *mockGetLogs) Use(cb getLogsFunc)                 { mock.handler = cb }
-func (mock *mockGarbageCollect) Use(cb garbageCollectFunc)   { mock.handler = cb }
-func (mock *mockActivateAuthPPS) Use(cb activateAuthPPSFunc) { mock.handler = cb }
+type mockInstantiateTemplate struct{ handler instantiateTemplateFunc }
+type mockJobProgress struct{ handler jobProgressFunc }
+type mockWatchJob struct{ handler watchJobFunc }
+type mockWatchPipeline struct{ handler watchPipelineFunc }
+type mockListNames struct{ handler listNamesFunc }
+type mockRotateSecret struct{ handler rotateSecretFunc }
+type mockListStuckBranches struct{ handler listStuckBranchesFunc }
+type mockDiagnose struct{ handler diagnoseFunc }
+type mockReplayJob struct{ handler replayJobFunc }
+type mockPutArtifact struct{ handler putArtifactFunc }
+type mockGetArtifact struct{ handler getArtifactFunc }
+type mockInspectImpact struct{ handler inspectImpactFunc }
+type mockInspectGarbageCollect struct{ handler inspectGarbageCollectFunc }
+
+func (mock *mockCreateJob) Use(cb createJobFunc)                         { mock.handler = cb }
+func (mock *mockInspectJob) Use(cb inspectJobFunc)                       { mock.handler = cb }
+func (mock *mockListJob) Use(cb listJobFunc)                             { mock.handler = cb }
+func (mock *mockListJobStream) Use(cb listJobStreamFunc)                 { mock.handler = cb }
+func (mock *mockFlushJob) Use(cb flushJobFunc)                           { mock.handler = cb }
+func (mock *mockDeleteJob) Use(cb deleteJobFunc)                         { mock.handler = cb }
+func (mock *mockStopJob) Use(cb stopJobFunc)                             { mock.handler = cb }
+func (mock *mockUpdateJobState) Use(cb updateJobStateFunc)               { mock.handler = cb }
+func (mock *mockInspectDatum) Use(cb inspectDatumFunc)                   { mock.handler = cb }
+func (mock *mockListDatum) Use(cb listDatumFunc)                         { mock.handler = cb }
+func (mock *mockListDatumStream) Use(cb listDatumStreamFunc)             { mock.handler = cb }
+func (mock *mockRestartDatum) Use(cb restartDatumFunc)                   { mock.handler = cb }
+func (mock *mockCreatePipeline) Use(cb createPipelineFunc)               { mock.handler = cb }
+func (mock *mockCreatePipelines) Use(cb createPipelinesFunc)             { mock.handler = cb }
+func (mock *mockInspectPipeline) Use(cb inspectPipelineFunc)             { mock.handler = cb }
+func (mock *mockListPipeline) Use(cb listPipelineFunc)                   { mock.handler = cb }
+func (mock *mockDeletePipeline) Use(cb deletePipelineFunc)               { mock.handler = cb }
+func (mock *mockStartPipeline) Use(cb startPipelineFunc)                 { mock.handler = cb }
+func (mock *mockStopPipeline) Use(cb stopPipelineFunc)                   { mock.handler = cb }
+func (mock *mockRunPipeline) Use(cb runPipelineFunc)                     { mock.handler = cb }
+func (mock *mockRunCron) Use(cb runCronFunc)                             { mock.handler = cb }
+func (mock *mockCreateSecret) Use(cb createSecretFunc)                   { mock.handler = cb }
+func (mock *mockDeleteSecret) Use(cb deleteSecretFunc)                   { mock.handler = cb }
+func (mock *mockInspectSecret) Use(cb inspectSecretFunc)                 { mock.handler = cb }
+func (mock *mockListSecret) Use(cb listSecretFunc)                       { mock.handler = cb }
+func (mock *mockDeleteAllPPS) Use(cb deleteAllPPSFunc)                   { mock.handler = cb }
+func (mock *mockGetLogs) Use(cb getLogsFunc)                             { mock.handler = cb }
+func (mock *mockGarbageCollect) Use(cb garbageCollectFunc)               { mock.handler = cb }
+func (mock *mockActivateAuthPPS) Use(cb activateAuthPPSFunc)             { mock.handler = cb }
+func (mock *mockInstantiateTemplate) Use(cb instantiateTemplateFunc)     { mock.handler = cb }
+func (mock *mockJobProgress) Use(cb jobProgressFunc)                     { mock.handler = cb }
+func (mock *mockWatchJob) Use(cb watchJobFunc)                           { mock.handler = cb }
+func (mock *mockWatchPipeline) Use(cb watchPipelineFunc)                 { mock.handler = cb }
+func (mock *mockListNames) Use(cb listNamesFunc)                         { mock.handler = cb }
+func (mock *mockRotateSecret) Use(cb rotateSecretFunc)                   { mock.handler = cb }
+func (mock *mockListStuckBranches) Use(cb listStuckBranchesFunc)         { mock.handler = cb }
+func (mock *mockDiagnose) Use(cb diagnoseFunc)                           { mock.handler = cb }
+func (mock *mockReplayJob) Use(cb replayJobFunc)                         { mock.handler = cb }
+func (mock *mockPutArtifact) Use(cb putArtifactFunc)                     { mock.handler = cb }
+func (mock *mockGetArtifact) Use(cb getArtifactFunc)                     { mock.handler = cb }
+func (mock *mockInspectImpact) Use(cb inspectImpactFunc)                 { mock.handler = cb }
+func (mock *mockInspectGarbageCollect) Use(cb inspectGarbageCollectFunc) { mock.handler = cb }
 
 type ppsServerAPI struct {
 	mock *mockPPSServer
 }
 
 type mockPPSServer struct {
-	api             ppsServerAPI
-	CreateJob       mockCreateJob
-	InspectJob      mockInspectJob
-	ListJob         mockListJob
-	ListJobStream   mockListJobStream
-	FlushJob        mockFlushJob
-	DeleteJob       mockDeleteJob
-	StopJob         mockStopJob
-	UpdateJobState  mockUpdateJobState
-	InspectDatum    mockInspectDatum
-	ListDatum       mockListDatum
-	ListDatumStream mockListDatumStream
-	RestartDatum    mockRestartDatum
-	CreatePipeline  mockCreatePipeline
-	InspectPipeline mockInspectPipeline
-	ListPipeline    mockListPipeline
-	DeletePipeline  mockDeletePipeline
-	StartPipeline   mockStartPipeline
-	StopPipeline    mockStopPipeline
-	RunPipeline     mockRunPipeline
-	RunCron         mockRunCron
-	CreateSecret    mockCreateSecret
-	DeleteSecret    mockDeleteSecret
-	InspectSecret   mockInspectSecret
-	ListSecret      mockListSecret
-	DeleteAll       mockDeleteAllPPS
-	GetLogs         mockGetLogs
-	GarbageCollect  mockGarbageCollect
-	ActivateAuth    mockActivateAuthPPS
+	api                   ppsServerAPI
+	CreateJob             mockCreateJob
+	InspectJob            mockInspectJob
+	ListJob               mockListJob
+	ListJobStream         mockListJobStream
+	FlushJob              mockFlushJob
+	DeleteJob             mockDeleteJob
+	StopJob               mockStopJob
+	UpdateJobState        mockUpdateJobState
+	InspectDatum          mockInspectDatum
+	ListDatum             mockListDatum
+	ListDatumStream       mockListDatumStream
+	RestartDatum          mockRestartDatum
+	CreatePipeline        mockCreatePipeline
+	CreatePipelines       mockCreatePipelines
+	InspectPipeline       mockInspectPipeline
+	ListPipeline          mockListPipeline
+	DeletePipeline        mockDeletePipeline
+	StartPipeline         mockStartPipeline
+	StopPipeline          mockStopPipeline
+	RunPipeline           mockRunPipeline
+	RunCron               mockRunCron
+	CreateSecret          mockCreateSecret
+	DeleteSecret          mockDeleteSecret
+	InspectSecret         mockInspectSecret
+	ListSecret            mockListSecret
+	DeleteAll             mockDeleteAllPPS
+	GetLogs               mockGetLogs
+	GarbageCollect        mockGarbageCollect
+	ActivateAuth          mockActivateAuthPPS
+	InstantiateTemplate   mockInstantiateTemplate
+	JobProgress           mockJobProgress
+	WatchJob              mockWatchJob
+	WatchPipeline         mockWatchPipeline
+	ListNames             mockListNames
+	RotateSecret          mockRotateSecret
+	ListStuckBranches     mockListStuckBranches
+	Diagnose              mockDiagnose
+	ReplayJob             mockReplayJob
+	PutArtifact           mockPutArtifact
+	GetArtifact           mockGetArtifact
+	InspectImpact         mockInspectImpact
+	InspectGarbageCollect mockInspectGarbageCollect
 }
 
 func (api *ppsServerAPI) CreateJob(ctx context.Context, req *pps.CreateJobRequest) (*pps.Job, error) {
@@ -901,6 +1138,12 @@ func (api *ppsServerAPI) CreatePipeline(ctx context.Context, req *pps.CreatePipe
 	}
 	return nil, fmt.Errorf("unhandled pachd mock pps.CreatePipeline")
 }
+func (api *ppsServerAPI) CreatePipelines(ctx context.Context, req *pps.CreatePipelinesRequest) (*errcode.BulkResponse, error) {
+	if api.mock.CreatePipelines.handler != nil {
+		return api.mock.CreatePipelines.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pps.CreatePipelines")
+}
 func (api *ppsServerAPI) InspectPipeline(ctx context.Context, req *pps.InspectPipelineRequest) (*pps.PipelineInfo, error) {
 	if api.mock.InspectPipeline.handler != nil {
 		return api.mock.InspectPipeline.handler(ctx, req)
@@ -991,6 +1234,84 @@ func (api *ppsServerAPI) ActivateAuth(ctx context.Context, req *pps.ActivateAuth
 	}
 	return nil, fmt.Errorf("unhandled pachd mock pps.ActivateAuth")
 }
+func (api *ppsServerAPI) InstantiateTemplate(ctx context.Context, req *pps.InstantiateTemplateRequest) (*pps.InstantiateTemplateResponse, error) {
+	if api.mock.InstantiateTemplate.handler != nil {
+		return api.mock.InstantiateTemplate.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pps.InstantiateTemplate")
+}
+func (api *ppsServerAPI) JobProgress(req *pps.JobProgressRequest, serv pps.API_JobProgressServer) error {
+	if api.mock.JobProgress.handler != nil {
+		return api.mock.JobProgress.handler(req, serv)
+	}
+	return fmt.Errorf("unhandled pachd mock pps.JobProgress")
+}
+func (api *ppsServerAPI) WatchJob(req *pps.ListJobRequest, serv pps.API_WatchJobServer) error {
+	if api.mock.WatchJob.handler != nil {
+		return api.mock.WatchJob.handler(req, serv)
+	}
+	return fmt.Errorf("unhandled pachd mock pps.WatchJob")
+}
+func (api *ppsServerAPI) WatchPipeline(req *pps.ListPipelineRequest, serv pps.API_WatchPipelineServer) error {
+	if api.mock.WatchPipeline.handler != nil {
+		return api.mock.WatchPipeline.handler(req, serv)
+	}
+	return fmt.Errorf("unhandled pachd mock pps.WatchPipeline")
+}
+func (api *ppsServerAPI) ListNames(ctx context.Context, req *pps.ListNamesRequest) (*pps.ListNamesResponse, error) {
+	if api.mock.ListNames.handler != nil {
+		return api.mock.ListNames.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pps.ListNames")
+}
+func (api *ppsServerAPI) RotateSecret(ctx context.Context, req *pps.RotateSecretRequest) (*types.Empty, error) {
+	if api.mock.RotateSecret.handler != nil {
+		return api.mock.RotateSecret.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pps.RotateSecret")
+}
+func (api *ppsServerAPI) ListStuckBranches(ctx context.Context, req *pps.ListStuckBranchesRequest) (*pps.StuckBranches, error) {
+	if api.mock.ListStuckBranches.handler != nil {
+		return api.mock.ListStuckBranches.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pps.ListStuckBranches")
+}
+func (api *ppsServerAPI) Diagnose(ctx context.Context, req *pps.DiagnoseRequest) (*pps.Diagnosis, error) {
+	if api.mock.Diagnose.handler != nil {
+		return api.mock.Diagnose.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pps.Diagnose")
+}
+func (api *ppsServerAPI) ReplayJob(ctx context.Context, req *pps.ReplayJobRequest) (*pps.ReplayJobResponse, error) {
+	if api.mock.ReplayJob.handler != nil {
+		return api.mock.ReplayJob.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pps.ReplayJob")
+}
+func (api *ppsServerAPI) PutArtifact(serv pps.API_PutArtifactServer) error {
+	if api.mock.PutArtifact.handler != nil {
+		return api.mock.PutArtifact.handler(serv)
+	}
+	return fmt.Errorf("unhandled pachd mock pps.PutArtifact")
+}
+func (api *ppsServerAPI) GetArtifact(req *pps.GetArtifactRequest, serv pps.API_GetArtifactServer) error {
+	if api.mock.GetArtifact.handler != nil {
+		return api.mock.GetArtifact.handler(req, serv)
+	}
+	return fmt.Errorf("unhandled pachd mock pps.GetArtifact")
+}
+func (api *ppsServerAPI) InspectImpact(ctx context.Context, req *pps.InspectImpactRequest) (*pps.Impact, error) {
+	if api.mock.InspectImpact.handler != nil {
+		return api.mock.InspectImpact.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pps.InspectImpact")
+}
+func (api *ppsServerAPI) InspectGarbageCollect(ctx context.Context, req *pps.InspectGarbageCollectRequest) (*pps.GarbageCollectInfo, error) {
+	if api.mock.InspectGarbageCollect.handler != nil {
+		return api.mock.InspectGarbageCollect.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock pps.InspectGarbageCollect")
+}
 
 /* Transaction Server Mocks */
 
@@ -1080,9 +1401,13 @@ func (api *transactionServerAPI) DeleteAll(ctx context.Context, req *transaction
 
 type getVersionFunc func(context.Context, *types.Empty) (*version.Version, error)
 
+type negotiateFunc func(context.Context, *version.NegotiateRequest) (*version.NegotiateResponse, error)
+
 type mockGetVersion struct{ handler getVersionFunc }
+type mockNegotiate struct{ handler negotiateFunc }
 
 func (mock *mockGetVersion) Use(cb getVersionFunc) { mock.handler = cb }
+func (mock *mockNegotiate) Use(cb negotiateFunc)   { mock.handler = cb }
 
 type versionServerAPI struct {
 	mock *mockVersionServer
@@ -1091,6 +1416,7 @@ type versionServerAPI struct {
 type mockVersionServer struct {
 	api        versionServerAPI
 	GetVersion mockGetVersion
+	Negotiate  mockNegotiate
 }
 
 func (api *versionServerAPI) GetVersion(ctx context.Context, req *types.Empty) (*version.Version, error) {
@@ -1099,6 +1425,12 @@ func (api *versionServerAPI) GetVersion(ctx context.Context, req *types.Empty) (
 	}
 	return nil, fmt.Errorf("unhandled pachd mock version.GetVersion")
 }
+func (api *versionServerAPI) Negotiate(ctx context.Context, req *version.NegotiateRequest) (*version.NegotiateResponse, error) {
+	if api.mock.Negotiate.handler != nil {
+		return api.mock.Negotiate.handler(ctx, req)
+	}
+	return nil, fmt.Errorf("unhandled pachd mock version.Negotiate")
+}
 
 /* Object Server Mocks */
 
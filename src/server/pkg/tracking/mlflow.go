@@ -0,0 +1,191 @@
+package tracking
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// jobTag is the MLflow tag that identifies the job that a run records
+const jobTag = "pachyderm.job"
+
+// mlflowClient logs runs with MLflow's REST API
+// (https://mlflow.org/docs/latest/rest-api.html)
+type mlflowClient struct {
+	url   string
+	token string
+}
+
+type mlflowKeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type mlflowMetric struct {
+	Key       string  `json:"key"`
+	Value     float64 `json:"value"`
+	Timestamp int64   `json:"timestamp"`
+	Step      int64   `json:"step"`
+}
+
+type mlflowRun struct {
+	Info struct {
+		RunID string `json:"run_id"`
+	} `json:"info"`
+}
+
+func (c *mlflowClient) endpoint(method string) string {
+	return strings.TrimSuffix(c.url, "/") + "/api/2.0/mlflow/" + method
+}
+
+func (c *mlflowClient) post(ctx context.Context, method string, request interface{}, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	return post(ctx, c.endpoint(method), c.token, body, response)
+}
+
+// experimentID returns the ID of the experiment 'name', creating it if it
+// doesn't exist
+func (c *mlflowClient) experimentID(ctx context.Context, name string) (string, error) {
+	var getResponse struct {
+		Experiment struct {
+			ExperimentID string `json:"experiment_id"`
+		} `json:"experiment"`
+	}
+	err := do(ctx, http.MethodGet, c.endpoint("experiments/get-by-name")+"?experiment_name="+url.QueryEscape(name), c.token, nil, &getResponse)
+	if err == nil {
+		return getResponse.Experiment.ExperimentID, nil
+	}
+	if httpErr, ok := err.(*httpError); !ok || httpErr.status != http.StatusNotFound {
+		return "", err
+	}
+	var createResponse struct {
+		ExperimentID string `json:"experiment_id"`
+	}
+	if err := c.post(ctx, "experiments/create", map[string]interface{}{"name": name}, &createResponse); err != nil {
+		return "", err
+	}
+	return createResponse.ExperimentID, nil
+}
+
+// runID returns the ID of the run that records the job 'jobID' in the
+// experiment 'experimentID', creating it if it doesn't exist yet
+func (c *mlflowClient) runID(ctx context.Context, experimentID string, jobID string, startTime int64) (string, error) {
+	var searchResponse struct {
+		Runs []mlflowRun `json:"runs"`
+	}
+	if err := c.post(ctx, "runs/search", map[string]interface{}{
+		"experiment_ids": []string{experimentID},
+		"filter":         fmt.Sprintf("tags.%q = '%s'", jobTag, jobID),
+		"max_results":    1,
+	}, &searchResponse); err != nil {
+		return "", err
+	}
+	if len(searchResponse.Runs) > 0 {
+		return searchResponse.Runs[0].Info.RunID, nil
+	}
+	var createResponse struct {
+		Run mlflowRun `json:"run"`
+	}
+	if err := c.post(ctx, "runs/create", map[string]interface{}{
+		"experiment_id": experimentID,
+		"start_time":    startTime,
+		"tags":          []mlflowKeyValue{{Key: jobTag, Value: jobID}, {Key: "mlflow.runName", Value: jobID}},
+	}, &createResponse); err != nil {
+		return "", err
+	}
+	return createResponse.Run.Info.RunID, nil
+}
+
+func (c *mlflowClient) publish(ctx context.Context, record *pps.RunRecord) error {
+	started, err := millis(record.Started)
+	if err != nil {
+		return err
+	}
+	finished, err := millis(record.Finished)
+	if err != nil {
+		return err
+	}
+	experimentID, err := c.experimentID(ctx, record.Experiment)
+	if err != nil {
+		return err
+	}
+	runID, err := c.runID(ctx, experimentID, record.Job.ID, started)
+	if err != nil {
+		return err
+	}
+
+	tags := map[string]string{
+		"pachyderm.pipeline":         record.Pipeline.Name,
+		"pachyderm.pipeline_version": fmt.Sprint(record.PipelineVersion),
+		"pachyderm.state":            record.State.String(),
+	}
+	if record.OutputCommit != nil {
+		tags["pachyderm.output_commit"] = record.OutputCommit.ID
+	}
+	for name, link := range record.Artifacts {
+		tags["pachyderm.artifact."+name] = link
+	}
+	var metrics []mlflowMetric
+	for key, value := range record.Metrics {
+		metrics = append(metrics, mlflowMetric{Key: key, Value: value, Timestamp: finished})
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Key < metrics[j].Key })
+	if err := c.post(ctx, "runs/log-batch", map[string]interface{}{
+		"run_id":  runID,
+		"params":  sortedKeyValues(record.Params),
+		"metrics": metrics,
+		"tags":    sortedKeyValues(tags),
+	}, nil); err != nil {
+		return err
+	}
+	return c.post(ctx, "runs/update", map[string]interface{}{
+		"run_id":   runID,
+		"status":   mlflowStatus(record.State),
+		"end_time": finished,
+	}, nil)
+}
+
+// mlflowStatus returns the status of the MLflow run of a job in 'state'
+func mlflowStatus(state pps.JobState) string {
+	switch state {
+	case pps.JobState_JOB_SUCCESS, pps.JobState_JOB_PARTIAL_SUCCESS:
+		return "FINISHED"
+	case pps.JobState_JOB_KILLED:
+		return "KILLED"
+	default:
+		return "FAILED"
+	}
+}
+
+func sortedKeyValues(m map[string]string) []mlflowKeyValue {
+	result := make([]mlflowKeyValue, 0, len(m))
+	for key, value := range m {
+		result = append(result, mlflowKeyValue{Key: key, Value: value})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+// millis converts 'ts' to milliseconds since the epoch, which is how MLflow
+// represents times
+func millis(ts *types.Timestamp) (int64, error) {
+	if ts == nil {
+		return 0, nil
+	}
+	t, err := types.TimestampFromProto(ts)
+	if err != nil {
+		return 0, err
+	}
+	return t.UnixNano() / 1e6, nil
+}
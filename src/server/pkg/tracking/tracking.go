@@ -0,0 +1,147 @@
+// Package tracking publishes records of pipelines' finished jobs to
+// experiment trackers (see pps.ExperimentTracking).
+package tracking
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+const (
+	// DefaultMetricsArtifact is the job artifact that a job's metrics are read
+	// from, if the pipeline's ExperimentTracking doesn't name one
+	DefaultMetricsArtifact = "metrics.json"
+
+	// requestTimeout bounds each request to a tracker
+	requestTimeout = 30 * time.Second
+)
+
+// Validate returns an error if 'spec' can't be published to
+func Validate(spec *pps.ExperimentTracking) error {
+	switch {
+	case spec.MLflowURL == "" && spec.URL == "":
+		return fmt.Errorf("must set either mlflow_url or url")
+	case spec.MLflowURL != "" && spec.URL != "":
+		return fmt.Errorf("can't set both mlflow_url and url")
+	}
+	for _, u := range []string{spec.MLflowURL, spec.URL} {
+		if u == "" {
+			continue
+		}
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return fmt.Errorf("invalid url %q: %v", u, err)
+		}
+		if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("url %q must be an http or https url", u)
+		}
+	}
+	if secret := spec.Secret; secret != nil && (secret.Name == "" || secret.Key == "") {
+		return fmt.Errorf("secret must have a name and a key")
+	}
+	return nil
+}
+
+// MetricsArtifact returns the name of the job artifact that 'spec' reads
+// metrics from
+func MetricsArtifact(spec *pps.ExperimentTracking) string {
+	if spec.MetricsArtifact != "" {
+		return spec.MetricsArtifact
+	}
+	return DefaultMetricsArtifact
+}
+
+// ArtifactLink returns the link that's published for the artifact 'name' of
+// the job 'jobID'
+func ArtifactLink(jobID string, name string) string {
+	return fmt.Sprintf("pachyderm://job/%s/artifact/%s", jobID, url.PathEscape(name))
+}
+
+// ParseMetrics parses a metrics artifact, which is a JSON object of metric
+// names to numbers
+func ParseMetrics(data []byte) (map[string]float64, error) {
+	metrics := make(map[string]float64)
+	if err := json.Unmarshal(data, &metrics); err != nil {
+		return nil, fmt.Errorf("metrics must be a JSON object of metric names to numbers: %v", err)
+	}
+	return metrics, nil
+}
+
+// Publish publishes 'record' to the tracker of 'spec', authenticating with
+// 'token' if it's set. Publishing a job's record again updates what was
+// published before, rather than publishing a second run, as far as the
+// tracker allows: MLflow runs are found by their "pachyderm.job" tag, and
+// generic endpoints should deduplicate records by their job.
+func Publish(ctx context.Context, spec *pps.ExperimentTracking, token string, record *pps.RunRecord) error {
+	if spec.MLflowURL != "" {
+		c := &mlflowClient{url: spec.MLflowURL, token: token}
+		return c.publish(ctx, record)
+	}
+	var buf bytes.Buffer
+	if err := (&jsonpb.Marshaler{OrigName: true}).Marshal(&buf, record); err != nil {
+		return err
+	}
+	return post(ctx, spec.URL, token, buf.Bytes(), nil)
+}
+
+// post POSTs 'body' as JSON to 'u', and decodes the JSON response into
+// 'response' if it's set
+func post(ctx context.Context, u string, token string, body []byte, response interface{}) error {
+	return do(ctx, http.MethodPost, u, token, body, response)
+}
+
+func do(ctx context.Context, method string, u string, token string, body []byte, response interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &httpError{method: method, url: u, status: resp.StatusCode, body: string(respBody)}
+	}
+	if response != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, response); err != nil {
+			return fmt.Errorf("could not decode response from %s: %v", u, err)
+		}
+	}
+	return nil
+}
+
+// httpError is a tracker's response with an unsuccessful status
+type httpError struct {
+	method string
+	url    string
+	status int
+	body   string
+}
+
+func (e *httpError) Error() string {
+	return fmt.Sprintf("%s %s returned %d: %s", e.method, e.url, e.status, e.body)
+}
@@ -0,0 +1,168 @@
+package tracking
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// fakeMLflow is an MLflow tracking server that records the requests that it
+// receives
+type fakeMLflow struct {
+	mu          sync.Mutex
+	experiments map[string]string
+	runs        map[string]string // job ID -> run ID
+	batches     []map[string]interface{}
+	updates     []map[string]interface{}
+	tokens      []string
+}
+
+func newFakeMLflow() *fakeMLflow {
+	return &fakeMLflow{
+		experiments: make(map[string]string),
+		runs:        make(map[string]string),
+	}
+}
+
+func (f *fakeMLflow) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tokens = append(f.tokens, r.Header.Get("Authorization"))
+	var request map[string]interface{}
+	if r.Method == http.MethodPost {
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &request)
+	}
+	var response interface{}
+	switch r.URL.Path {
+	case "/api/2.0/mlflow/experiments/get-by-name":
+		id, ok := f.experiments[r.URL.Query().Get("experiment_name")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code": "RESOURCE_DOES_NOT_EXIST"}`))
+			return
+		}
+		response = map[string]interface{}{"experiment": map[string]string{"experiment_id": id}}
+	case "/api/2.0/mlflow/experiments/create":
+		id := "exp-" + request["name"].(string)
+		f.experiments[request["name"].(string)] = id
+		response = map[string]string{"experiment_id": id}
+	case "/api/2.0/mlflow/runs/search":
+		var runs []interface{}
+		for jobID, runID := range f.runs {
+			if request["filter"] == `tags."pachyderm.job" = '`+jobID+`'` {
+				runs = append(runs, map[string]interface{}{"info": map[string]string{"run_id": runID}})
+			}
+		}
+		response = map[string]interface{}{"runs": runs}
+	case "/api/2.0/mlflow/runs/create":
+		jobID := request["tags"].([]interface{})[0].(map[string]interface{})["value"].(string)
+		f.runs[jobID] = "run-" + jobID
+		response = map[string]interface{}{"run": map[string]interface{}{"info": map[string]string{"run_id": "run-" + jobID}}}
+	case "/api/2.0/mlflow/runs/log-batch":
+		f.batches = append(f.batches, request)
+		response = map[string]string{}
+	case "/api/2.0/mlflow/runs/update":
+		f.updates = append(f.updates, request)
+		response = map[string]string{}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func testRecord(t *testing.T) *pps.RunRecord {
+	started, err := types.TimestampProto(time.Unix(100, 0))
+	require.NoError(t, err)
+	finished, err := types.TimestampProto(time.Unix(160, 0))
+	require.NoError(t, err)
+	return &pps.RunRecord{
+		Job:          client.NewJob("abc"),
+		Pipeline:     client.NewPipeline("train"),
+		Experiment:   "train",
+		State:        pps.JobState_JOB_SUCCESS,
+		Started:      started,
+		Finished:     finished,
+		OutputCommit: client.NewCommit("train", "def"),
+		Params:       map[string]string{"LEARNING_RATE": "0.01"},
+		Metrics:      map[string]float64{"accuracy": 0.93},
+		Artifacts:    map[string]string{"metrics.json": ArtifactLink("abc", "metrics.json")},
+	}
+}
+
+func TestPublishMLflow(t *testing.T) {
+	mlflow := newFakeMLflow()
+	server := httptest.NewServer(mlflow)
+	defer server.Close()
+	spec := &pps.ExperimentTracking{MLflowURL: server.URL}
+
+	// Publishing a job twice logs to the same run
+	for i := 0; i < 2; i++ {
+		require.NoError(t, Publish(context.Background(), spec, "secret", testRecord(t)))
+	}
+	require.Equal(t, map[string]string{"train": "exp-train"}, mlflow.experiments)
+	require.Equal(t, map[string]string{"abc": "run-abc"}, mlflow.runs)
+	require.Equal(t, 2, len(mlflow.batches))
+	batch := mlflow.batches[0]
+	require.Equal(t, "run-abc", batch["run_id"])
+	require.Equal(t, []interface{}{map[string]interface{}{"key": "LEARNING_RATE", "value": "0.01"}}, batch["params"])
+	require.Equal(t, []interface{}{map[string]interface{}{"key": "accuracy", "value": 0.93, "timestamp": float64(160000), "step": float64(0)}}, batch["metrics"])
+	// Tags are sorted by key
+	require.Equal(t, map[string]interface{}{"key": "pachyderm.artifact.metrics.json", "value": "pachyderm://job/abc/artifact/metrics.json"}, batch["tags"].([]interface{})[0])
+	require.Equal(t, "FINISHED", mlflow.updates[0]["status"])
+	require.Equal(t, float64(160000), mlflow.updates[0]["end_time"])
+	for _, token := range mlflow.tokens {
+		require.Equal(t, "Bearer secret", token)
+	}
+}
+
+func TestPublishURL(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+	require.NoError(t, Publish(context.Background(), &pps.ExperimentTracking{URL: server.URL}, "", testRecord(t)))
+	require.Equal(t, "abc", received["job"].(map[string]interface{})["id"])
+	require.Equal(t, "JOB_SUCCESS", received["state"])
+	require.Equal(t, map[string]interface{}{"accuracy": 0.93}, received["metrics"])
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	require.YesError(t, Publish(context.Background(), &pps.ExperimentTracking{URL: failing.URL}, "", testRecord(t)))
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate(&pps.ExperimentTracking{MLflowURL: "http://mlflow:5000"}))
+	require.NoError(t, Validate(&pps.ExperimentTracking{URL: "https://tracker.example.com/runs"}))
+	require.YesError(t, Validate(&pps.ExperimentTracking{}))
+	require.YesError(t, Validate(&pps.ExperimentTracking{MLflowURL: "http://mlflow:5000", URL: "http://tracker"}))
+	require.YesError(t, Validate(&pps.ExperimentTracking{URL: "tracker:8080"}))
+	require.YesError(t, Validate(&pps.ExperimentTracking{
+		URL:    "http://tracker",
+		Secret: &pps.ExperimentTracking_Secret{Name: "tracker"},
+	}))
+}
+
+func TestParseMetrics(t *testing.T) {
+	metrics, err := ParseMetrics([]byte(`{"accuracy": 0.93, "loss": 1}`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]float64{"accuracy": 0.93, "loss": 1}, metrics)
+	_, err = ParseMetrics([]byte(`{"model": "resnet"}`))
+	require.YesError(t, err)
+}
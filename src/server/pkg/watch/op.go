@@ -6,19 +6,27 @@ import etcd "github.com/coreos/etcd/clientv3"
 type OpOption struct {
 	Get   etcd.OpOption
 	Watch etcd.OpOption
+
+	// The fields below describe the option to watchers that aren't backed by
+	// etcd (which can't inspect Get and Watch)
+	FilterPut    bool
+	FilterDelete bool
+	SortTarget   etcd.SortTarget
+	SortOrder    etcd.SortOrder
+	Sort         bool
 }
 
 // WithFilterPut discards PUT events from the watcher.
 func WithFilterPut() OpOption {
-	return OpOption{Watch: etcd.WithFilterPut(), Get: nil}
+	return OpOption{Watch: etcd.WithFilterPut(), Get: nil, FilterPut: true}
 }
 
 // WithSort specifies the sort to use for the watcher
 func WithSort(sortBy etcd.SortTarget, sortOrder etcd.SortOrder) OpOption {
-	return OpOption{Get: etcd.WithSort(sortBy, sortOrder), Watch: nil}
+	return OpOption{Get: etcd.WithSort(sortBy, sortOrder), Watch: nil, Sort: true, SortTarget: sortBy, SortOrder: sortOrder}
 }
 
 // WithFilterDelete discards DELETE events from the watcher.
 func WithFilterDelete() OpOption {
-	return OpOption{Watch: etcd.WithFilterDelete()}
+	return OpOption{Watch: etcd.WithFilterDelete(), FilterDelete: true}
 }
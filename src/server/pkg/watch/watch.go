@@ -132,6 +132,11 @@ func NewWatcher(ctx context.Context, client *etcd.Client, trimPrefix, prefix str
 				return nil
 			}
 			if !ok {
+				// The watch is also closed when 'ctx' is canceled, in which
+				// case reopening it would only close it again
+				if err := ctx.Err(); err != nil {
+					return err
+				}
 				if err := etcdWatcher.Close(); err != nil {
 					return err
 				}
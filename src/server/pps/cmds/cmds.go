@@ -12,12 +12,15 @@ import (
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	pachdclient "github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errcode"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
 	"github.com/pachyderm/pachyderm/src/client/pkg/tracing/extended"
 	ppsclient "github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/client/version"
 	"github.com/pachyderm/pachyderm/src/server/cmd/pachctl/shell"
 	"github.com/pachyderm/pachyderm/src/server/pkg/cmdutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/pager"
@@ -64,20 +67,51 @@ func encoder(format string, w ...io.Writer) serde.Encoder {
 	return e
 }
 
+// jobHeader returns the header of job tables printed in 'output'
+func jobHeader(output *cmdutil.Output) string {
+	if output.Wide() {
+		return pretty.JobWideHeader
+	}
+	return pretty.JobHeader
+}
+
+// printJobInfo prints a row of a job table with a header from jobHeader
+func printJobInfo(w io.Writer, jobInfo *ppsclient.JobInfo, output *cmdutil.Output, fullTimestamps bool) {
+	if output.Wide() {
+		pretty.PrintJobInfoWide(w, jobInfo)
+		return
+	}
+	pretty.PrintJobInfo(w, jobInfo, fullTimestamps)
+}
+
+// pipelineHeader returns the header of pipeline tables printed in 'output'
+func pipelineHeader(output *cmdutil.Output) string {
+	if output.Wide() {
+		return pretty.PipelineWideHeader
+	}
+	return pretty.PipelineHeader
+}
+
+// printPipelineInfo prints a row of a pipeline table with a header from
+// pipelineHeader
+func printPipelineInfo(w io.Writer, pipelineInfo *ppsclient.PipelineInfo, output *cmdutil.Output, fullTimestamps bool) {
+	if output.Wide() {
+		pretty.PrintPipelineInfoWide(w, pipelineInfo)
+		return
+	}
+	pretty.PrintPipelineInfo(w, pipelineInfo, fullTimestamps)
+}
+
 // Cmds returns a slice containing pps commands.
 func Cmds() []*cobra.Command {
 	var commands []*cobra.Command
 
-	raw := false
-	var output string
-	outputFlags := pflag.NewFlagSet("", pflag.ExitOnError)
-	outputFlags.BoolVar(&raw, "raw", false, "Disable pretty printing; serialize data structures to an encoding such as json or yaml")
-	// --output is empty by default, so that we can print an error if a user
-	// explicitly sets --output without --raw, but the effective default is set in
-	// encode(), which assumes "json" if 'format' is empty.
-	// Note: because of how spf13/flags works, no other StringVarP that sets
-	// 'output' can have a default value either
-	outputFlags.StringVarP(&output, "output", "o", "", "Output format when --raw is set: \"json\" or \"yaml\" (default \"json\")")
+	output := &cmdutil.Output{OrigName: true}
+	outputFlags := output.Flags("output", "o")
+	// The format of the pipeline manifests printed by 'extract pipeline' and
+	// edited by 'edit pipeline'. It's empty by default, but encoder() assumes
+	// "json" if it's empty.
+	var manifestFormat string
 
 	fullTimestamps := false
 	fullTimestampsFlags := pflag.NewFlagSet("", pflag.ContinueOnError)
@@ -101,16 +135,42 @@ If the job fails, the output commit will not be populated with data.`,
 	commands = append(commands, cmdutil.CreateDocsAlias(jobDocs, "job", " job$"))
 
 	var block bool
+	var watchProgress bool
 	inspectJob := &cobra.Command{
 		Use:   "{{alias}} <job>",
 		Short: "Return info about a job.",
 		Long:  "Return info about a job.",
+		Example: `
+# Return info about job "abc123"
+$ {{alias}} abc123
+
+# Show a live progress bar (with throughput and ETA) until job "abc123"
+# finishes, then return info about it
+$ {{alias}} abc123 --watch`,
 		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
 			client, err := pachdclient.NewOnUserMachine("user")
 			if err != nil {
 				return err
 			}
 			defer client.Close()
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
+			if watchProgress {
+				if e != nil {
+					cmdutil.ErrorAndExit("cannot set --watch with --raw or --output")
+				}
+				if err := client.JobProgress(args[0], func(progress *ppsclient.JobProgress) error {
+					// Redraw the bar in place
+					fmt.Printf("\r%s\033[K", pretty.ProgressBar(progress, 40))
+					return nil
+				}); err != nil {
+					fmt.Println()
+					cmdutil.ErrorAndExit("error from JobProgress: %s", err.Error())
+				}
+				fmt.Println()
+			}
 			jobInfo, err := client.InspectJob(args[0], block)
 			if err != nil {
 				cmdutil.ErrorAndExit("error from InspectJob: %s", err.Error())
@@ -118,19 +178,18 @@ If the job fails, the output commit will not be populated with data.`,
 			if jobInfo == nil {
 				cmdutil.ErrorAndExit("job %s not found.", args[0])
 			}
-			if raw {
-				return encoder(output).EncodeProto(jobInfo)
-			} else if output != "" {
-				cmdutil.ErrorAndExit("cannot set --output (-o) without --raw")
+			if e != nil {
+				return e.EncodeProto(jobInfo)
 			}
 			ji := &pretty.PrintableJobInfo{
 				JobInfo:        jobInfo,
-				FullTimestamps: fullTimestamps,
+				FullTimestamps: fullTimestamps || output.Wide(),
 			}
 			return pretty.PrintDetailedJobInfo(ji)
 		}),
 	}
 	inspectJob.Flags().BoolVarP(&block, "block", "b", false, "block until the job has either succeeded or failed")
+	inspectJob.Flags().BoolVarP(&watchProgress, "watch", "w", false, "show the job's progress until it has either succeeded or failed")
 	inspectJob.Flags().AddFlagSet(outputFlags)
 	inspectJob.Flags().AddFlagSet(fullTimestampsFlags)
 	shell.RegisterCompletionFunc(inspectJob, shell.JobCompletion)
@@ -140,9 +199,23 @@ If the job fails, the output commit will not be populated with data.`,
 	var outputCommitStr string
 	var inputCommitStrs []string
 	var history string
+	var labelSelector string
+	var watchList bool
+	var stateStrs []string
+	var jobPageSize int64
 	listJob := &cobra.Command{
 		Short: "Return info about jobs.",
-		Long:  "Return info about jobs.",
+		Long: `Return info about jobs.
+
+The INPUT column lists the exact commit, as repo@commit, that each of a job's
+inputs read, so that a job's output can be traced back to its inputs even
+after their branches have moved on.
+
+The OUTPUT column summarizes how each finished job's output commit differs
+from its parent (usually the output of the pipeline's previous job): the
+number of files added (+), changed (~) and deleted (-), and the change in
+size. A job that rewrote most of its output when little of its input changed
+may mean that the pipeline's datums aren't being skipped.`,
 		Example: `
 # Return all jobs
 $ {{alias}}
@@ -153,11 +226,20 @@ $ {{alias}} -p foo
 # Return all jobs from all versions of pipeline "foo"
 $ {{alias}} -p foo --history all
 
-# Return all jobs whose input commits include foo@XXX and bar@YYY
+# Return all jobs that read commit XXX of repo foo and commit YYY of repo bar
 $ {{alias}} -i foo@XXX -i bar@YYY
 
-# Return all jobs in pipeline foo and whose input commits include bar@YYY
-$ {{alias}} -p foo -i bar@YYY`,
+# Return all jobs in pipeline foo that read commit YYY of repo bar
+$ {{alias}} -p foo -i bar@YYY
+
+# Return all jobs from pipelines labeled team=nlp, except those labeled env=dev
+$ {{alias}} -l team=nlp,env!=dev
+
+# Return all jobs that are running or failed
+$ {{alias}} --state running --state failure
+
+# Show all jobs in a table that's updated as they change
+$ {{alias}} --watch`,
 		Run: cmdutil.RunFixedArgs(0, func(args []string) error {
 			commits, err := cmdutil.ParseCommits(inputCommitStrs)
 			if err != nil {
@@ -167,6 +249,10 @@ $ {{alias}} -p foo -i bar@YYY`,
 			if err != nil {
 				return fmt.Errorf("error parsing history flag: %v", err)
 			}
+			states, err := parseJobStates(stateStrs)
+			if err != nil {
+				return err
+			}
 			var outputCommit *pfs.Commit
 			if outputCommitStr != "" {
 				outputCommit, err = cmdutil.ParseCommit(outputCommitStr)
@@ -175,24 +261,72 @@ $ {{alias}} -p foo -i bar@YYY`,
 				}
 			}
 
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
+
 			client, err := pachdclient.NewOnUserMachine("user")
 			if err != nil {
 				return err
 			}
 			defer client.Close()
 
+			var pipeline *ppsclient.Pipeline
+			if pipelineName != "" {
+				pipeline = pachdclient.NewPipeline(pipelineName)
+			}
+			request := &ppsclient.ListJobRequest{
+				Pipeline:      pipeline,
+				InputCommit:   commits,
+				OutputCommit:  outputCommit,
+				History:       history,
+				LabelSelector: labelSelector,
+				State:         states,
+			}
+			listJobs := client.ListJobWithRequest
+			if jobPageSize > 0 {
+				request.PageSize = jobPageSize
+				listJobs = client.ListJobPages
+			}
+
+			if watchList {
+				if len(commits) > 0 || outputCommit != nil {
+					return fmt.Errorf("cannot set --watch with --input or --output")
+				}
+				if e != nil {
+					request.Full = true
+					return client.WatchJob(request, func(ji *ppsclient.JobInfo) error {
+						return e.EncodeProto(ji)
+					})
+				}
+				// Newest jobs first, as in the static table
+				table := tabwriter.NewLiveTable(os.Stdout, jobHeader(output), true)
+				return table.Run(func() error {
+					return client.WatchJob(request, func(ji *ppsclient.JobInfo) error {
+						var buf bytes.Buffer
+						printJobInfo(&buf, ji, output, fullTimestamps)
+						table.Set(ji.Job.ID, tabwriter.SortableTimestamp(ji.Started), buf.String())
+						return nil
+					})
+				})
+			}
+
 			return pager.Page(noPager, os.Stdout, func(w io.Writer) error {
-				if raw {
-					e := encoder(output)
-					return client.ListJobF(pipelineName, commits, outputCommit, history, true, func(ji *ppsclient.JobInfo) error {
+				if e != nil {
+					// 'e' writes to stdout, so it's re-created to write to the pager
+					e, err := output.Encoder(w)
+					if err != nil {
+						return err
+					}
+					request.Full = true
+					return listJobs(request, func(ji *ppsclient.JobInfo) error {
 						return e.EncodeProto(ji)
 					})
-				} else if output != "" {
-					cmdutil.ErrorAndExit("cannot set --output (-o) without --raw")
 				}
-				writer := tabwriter.NewWriter(w, pretty.JobHeader)
-				if err := client.ListJobF(pipelineName, commits, outputCommit, history, false, func(ji *ppsclient.JobInfo) error {
-					pretty.PrintJobInfo(writer, ji, fullTimestamps)
+				writer := tabwriter.NewWriter(w, jobHeader(output))
+				if err := listJobs(request, func(ji *ppsclient.JobInfo) error {
+					printJobInfo(writer, ji, output, fullTimestamps)
 					return nil
 				}); err != nil {
 					return err
@@ -207,10 +341,16 @@ $ {{alias}} -p foo -i bar@YYY`,
 	listJob.MarkFlagCustom("output", "__pachctl_get_repo_commit")
 	listJob.Flags().StringSliceVarP(&inputCommitStrs, "input", "i", []string{}, "List jobs with a specific set of input commits. format: <repo>@<branch-or-commit>")
 	listJob.MarkFlagCustom("input", "__pachctl_get_repo_commit")
-	listJob.Flags().AddFlagSet(outputFlags)
+	// --output (-o) is the output commit filter, so list job's output format
+	// is set with --format
+	listJob.Flags().AddFlagSet(output.Flags("format", ""))
 	listJob.Flags().AddFlagSet(fullTimestampsFlags)
 	listJob.Flags().AddFlagSet(noPagerFlags)
 	listJob.Flags().StringVar(&history, "history", "none", "Return jobs from historical versions of pipelines.")
+	listJob.Flags().StringVarP(&labelSelector, "selector", "l", "", "Only return jobs whose labels match this label selector (e.g. team=nlp,env!=dev).")
+	listJob.Flags().BoolVarP(&watchList, "watch", "w", false, "After listing jobs, keep the list updated as jobs change.")
+	listJob.Flags().StringSliceVar(&stateStrs, "state", []string{}, "Only return jobs in one of these states (e.g. running, failure).")
+	listJob.Flags().Int64Var(&jobPageSize, "page-size", 1000, "The number of jobs read from pachd per request. If 0, all jobs are read in one request.")
 	shell.RegisterCompletionFunc(listJob,
 		func(flag, text string, maxCompletions int64) ([]prompt.Suggest, shell.CacheFunc) {
 			if flag == "-p" || flag == "--pipeline" {
@@ -233,8 +373,9 @@ $ {{alias}} foo@XXX bar@YYY
 # Return jobs caused by foo@XXX leading to pipelines bar and baz.
 $ {{alias}} foo@XXX -p bar -p baz`,
 		Run: cmdutil.Run(func(args []string) error {
-			if output != "" && !raw {
-				cmdutil.ErrorAndExit("cannot set --output (-o) without --raw")
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
 			}
 			commits, err := cmdutil.ParseCommits(args)
 			if err != nil {
@@ -247,23 +388,19 @@ $ {{alias}} foo@XXX -p bar -p baz`,
 			}
 			defer c.Close()
 			var writer *tabwriter.Writer
-			if !raw {
-				writer = tabwriter.NewWriter(os.Stdout, pretty.JobHeader)
+			if e == nil {
+				writer = tabwriter.NewWriter(os.Stdout, jobHeader(output))
 			}
-			e := encoder(output)
 			if err := c.FlushJob(commits, pipelines, func(ji *ppsclient.JobInfo) error {
-				if raw {
-					if err := e.EncodeProto(ji); err != nil {
-						return err
-					}
-					return nil
+				if e != nil {
+					return e.EncodeProto(ji)
 				}
-				pretty.PrintJobInfo(writer, ji, fullTimestamps)
+				printJobInfo(writer, ji, output, fullTimestamps)
 				return nil
 			}); err != nil {
 				return err
 			}
-			if !raw {
+			if e == nil {
 				return writer.Flush()
 			}
 			return nil
@@ -322,6 +459,192 @@ $ {{alias}} foo@XXX -p bar -p baz`,
 	shell.RegisterCompletionFunc(stopJob, shell.JobCompletion)
 	commands = append(commands, cmdutil.CreateAlias(stopJob, "stop job"))
 
+	var replayBranch string
+	replayJob := &cobra.Command{
+		Use:   "{{alias}} <job>",
+		Short: "Re-run a job and compare the output.",
+		Long: `Re-run a successful job with the pipeline spec (and so the image digest, if it's pinned) and input commits that it ran with, and report the files whose content differs from the job's output.
+
+The replay's output commit is put on a branch of the job's output repo ("replay-<job>" by default), and the command waits for the replay to finish. Use it to check that a pipeline is reproducible, or to track down nondeterminism.`,
+		Example: `
+# Replay a job, putting its output on the branch "replay-<job>" of the
+# job's output repo:
+$ {{alias}} 5f93d03b65fa421996185e53f7f8b1e4
+
+# Replay a job, putting its output on the branch "check":
+$ {{alias}} 5f93d03b65fa421996185e53f7f8b1e4 --branch check`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
+			response, err := client.ReplayJob(args[0], replayBranch)
+			if err != nil {
+				return err
+			}
+			if e != nil {
+				return e.EncodeProto(response)
+			}
+			pretty.PrintReplay(os.Stdout, args[0], response)
+			return nil
+		}),
+	}
+	replayJob.Flags().StringVar(&replayBranch, "branch", "", "The branch of the job's output repo to put the replay's output commit on (\"replay-<job>\" by default).")
+	replayJob.Flags().AddFlagSet(outputFlags)
+	shell.RegisterCompletionFunc(replayJob, shell.JobCompletion)
+	commands = append(commands, cmdutil.CreateAlias(replayJob, "replay job"))
+
+	var invalidate bool
+	var forceInvalidate bool
+	inspectImpact := &cobra.Command{
+		Use:   "{{alias}} <repo>@<branch-or-commit>",
+		Short: "Return everything downstream of a commit.",
+		Long: `Return everything that's affected if a commit is bad: the commits that have it in their provenance, the pipelines and jobs that wrote them, the branches that point at them, and the destinations that their jobs egressed them to.
+
+With --invalidate, a bad input commit is deleted along with everything downstream of it, and the pipelines downstream of it reprocess the new heads of its branches. Data that was egressed isn't recalled.`,
+		Example: `
+# Return everything downstream of the head of the "images" repo's master
+# branch:
+$ {{alias}} images@master
+
+# Delete a bad commit and everything downstream of it, and rerun the
+# pipelines downstream of it:
+$ {{alias}} images@a23e4 --invalidate`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			commit, err := cmdutil.ParseCommit(args[0])
+			if err != nil {
+				return err
+			}
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
+			impact, err := client.InspectImpact(commit.Repo.Name, commit.ID)
+			if err != nil {
+				return err
+			}
+			if e != nil {
+				if err := e.EncodeProto(impact); err != nil {
+					return err
+				}
+			} else {
+				pretty.PrintImpact(os.Stdout, impact)
+			}
+			if !invalidate {
+				return nil
+			}
+			bad := impact.Commit
+			if len(impact.Remediations) == 0 {
+				return fmt.Errorf("%s@%s is an output commit, and only input commits can be invalidated; inspect the impact of the commits in its provenance instead", bad.Repo.Name, bad.ID)
+			}
+			if !forceInvalidate {
+				fmt.Printf("%s@%s and its %d downstream commits will be deleted.\n", bad.Repo.Name, bad.ID, len(impact.Commits))
+				fmt.Println("Are you sure you want to do this? (y/n):")
+				answer, err := bufio.NewReader(os.Stdin).ReadBytes('\n')
+				if err != nil {
+					return err
+				}
+				if answer[0] != 'y' && answer[0] != 'Y' {
+					return nil
+				}
+			}
+			return client.DeleteCommit(bad.Repo.Name, bad.ID)
+		}),
+	}
+	inspectImpact.Flags().BoolVar(&invalidate, "invalidate", false, "Delete the commit and everything downstream of it, so that the pipelines downstream of it reprocess the new heads of its branches.")
+	inspectImpact.Flags().BoolVarP(&forceInvalidate, "force", "f", false, "Don't ask for confirmation before invalidating the commit.")
+	inspectImpact.Flags().AddFlagSet(outputFlags)
+	shell.RegisterCompletionFunc(inspectImpact, shell.BranchCompletion)
+	commands = append(commands, cmdutil.CreateAlias(inspectImpact, "inspect impact"))
+
+	artifactDocs := &cobra.Command{
+		Short: "Docs for artifacts.",
+		Long: `Artifacts are named files that are attached to a job rather than committed to its output repo, such as metrics reports, plots and model cards.
+
+User code can attach artifacts to the job that it's running in, whose ID is in the PACH_JOB_ID environment variable. Inspecting a job lists its artifacts.`,
+	}
+	commands = append(commands, cmdutil.CreateDocsAlias(artifactDocs, "artifact", " artifact$"))
+
+	var artifactFile string
+	putArtifact := &cobra.Command{
+		Use:   "{{alias}} <job> <name>",
+		Short: "Attach an artifact to a job.",
+		Long:  "Attach an artifact to a job, replacing the job's artifact with the same name if there is one. Attaching an artifact requires write access to the job's output repo.",
+		Example: `
+# Attach metrics.json to the job that's running this user code:
+$ {{alias}} $PACH_JOB_ID metrics.json -f /tmp/metrics.json
+
+# Attach a model card that's read from stdin:
+$ cat model_card.md | {{alias}} 5f93d03b65fa421996185e53f7f8b1e4 model_card.md`,
+		Run: cmdutil.RunFixedArgs(2, func(args []string) error {
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			r := io.Reader(os.Stdin)
+			if artifactFile != "-" {
+				f, err := os.Open(artifactFile)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				r = f
+			}
+			return client.PutArtifact(args[0], args[1], r)
+		}),
+	}
+	putArtifact.Flags().StringVarP(&artifactFile, "file", "f", "-", "The file to attach. If - is used, the artifact is read from the standard input.")
+	shell.RegisterCompletionFunc(putArtifact, shell.JobCompletion)
+	commands = append(commands, cmdutil.CreateAlias(putArtifact, "put artifact"))
+
+	var artifactOutputPath string
+	getArtifact := &cobra.Command{
+		Use:   "{{alias}} <job> <name>",
+		Short: "Download one of a job's artifacts.",
+		Long:  "Download one of a job's artifacts, printing it to stdout unless an output path is given.",
+		Example: `
+# Print a job's metrics.json:
+$ {{alias}} 5f93d03b65fa421996185e53f7f8b1e4 metrics.json
+
+# Download a job's confusion matrix to a file:
+$ {{alias}} 5f93d03b65fa421996185e53f7f8b1e4 confusion.png -o confusion.png`,
+		Run: cmdutil.RunFixedArgs(2, func(args []string) (retErr error) {
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			w := io.Writer(os.Stdout)
+			if artifactOutputPath != "" {
+				f, err := os.Create(artifactOutputPath)
+				if err != nil {
+					return err
+				}
+				defer func() {
+					if err := f.Close(); err != nil && retErr == nil {
+						retErr = err
+					}
+				}()
+				w = f
+			}
+			return client.GetArtifact(args[0], args[1], w)
+		}),
+	}
+	getArtifact.Flags().StringVarP(&artifactOutputPath, "output", "o", "", "The path where the artifact will be downloaded.")
+	shell.RegisterCompletionFunc(getArtifact, shell.JobCompletion)
+	commands = append(commands, cmdutil.CreateAlias(getArtifact, "get artifact"))
+
 	datumDocs := &cobra.Command{
 		Short: "Docs for datums.",
 		Long: `Datums are the small independent units of processing for Pachyderm jobs.
@@ -379,13 +702,14 @@ each datum.`,
 			if page < 0 {
 				return fmt.Errorf("page must be zero or positive")
 			}
-			if raw {
-				e := encoder(output)
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
+			if e != nil {
 				return client.ListDatumF(args[0], pageSize, page, func(di *ppsclient.DatumInfo) error {
 					return e.EncodeProto(di)
 				})
-			} else if output != "" {
-				cmdutil.ErrorAndExit("cannot set --output (-o) without --raw")
 			}
 			writer := tabwriter.NewWriter(os.Stdout, pretty.DatumHeader)
 			if err := client.ListDatumF(args[0], pageSize, page, func(di *ppsclient.DatumInfo) error {
@@ -413,14 +737,16 @@ each datum.`,
 				return err
 			}
 			defer client.Close()
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
 			datumInfo, err := client.InspectDatum(args[0], args[1])
 			if err != nil {
 				return err
 			}
-			if raw {
-				return encoder(output).EncodeProto(datumInfo)
-			} else if output != "" {
-				cmdutil.ErrorAndExit("cannot set --output (-o) without --raw")
+			if e != nil {
+				return e.EncodeProto(datumInfo)
 			}
 			pretty.PrintDetailedDatumInfo(os.Stdout, datumInfo)
 			return nil
@@ -436,6 +762,10 @@ each datum.`,
 		master      bool
 		follow      bool
 		tail        int64
+		since       time.Duration
+		pattern     string
+		workerID    string
+		rawLogs     bool
 	)
 	getLogs := &cobra.Command{
 		Use:   "{{alias}} [--pipeline=<pipeline>|--job=<job>] [--datum=<datum>]",
@@ -449,7 +779,10 @@ $ {{alias}} --pipeline=filter
 $ {{alias}} --job=aedfa12aedf
 
 # Return logs emitted by the pipeline \"filter\" while processing /apple.txt and a file with the hash 123aef
-$ {{alias}} --pipeline=filter --inputs=/apple.txt,123aef`,
+$ {{alias}} --pipeline=filter --inputs=/apple.txt,123aef
+
+# Follow the logs emitted by the "filter" pipeline in the last hour that contain "error"
+$ {{alias}} --pipeline=filter --since=1h --pattern=error -f`,
 		Run: cmdutil.RunFixedArgs(0, func(args []string) error {
 			client, err := pachdclient.NewOnUserMachine("user")
 			if err != nil {
@@ -471,11 +804,31 @@ $ {{alias}} --pipeline=filter --inputs=/apple.txt,123aef`,
 			}
 
 			// Issue RPC
-			iter := client.GetLogs(pipelineName, jobID, data, datumID, master, follow, tail)
+			request := &ppsclient.GetLogsRequest{
+				DataFilters: data,
+				Master:      master,
+				Follow:      follow,
+				Tail:        tail,
+				Pattern:     pattern,
+				WorkerID:    workerID,
+			}
+			if pipelineName != "" {
+				request.Pipeline = pachdclient.NewPipeline(pipelineName)
+			}
+			if jobID != "" {
+				request.Job = pachdclient.NewJob(jobID)
+			}
+			if datumID != "" {
+				request.Datum = &ppsclient.Datum{Job: pachdclient.NewJob(jobID), ID: datumID}
+			}
+			if since != 0 {
+				request.Since = types.DurationProto(since)
+			}
+			iter := client.GetLogsWithRequest(request)
 			var buf bytes.Buffer
 			encoder := json.NewEncoder(&buf)
 			for iter.Next() {
-				if raw {
+				if rawLogs {
 					buf.Reset()
 					if err := encoder.Encode(iter.Message()); err != nil {
 						fmt.Fprintf(os.Stderr, "error marshalling \"%v\": %s\n", iter.Message(), err)
@@ -502,9 +855,12 @@ $ {{alias}} --pipeline=filter --inputs=/apple.txt,123aef`,
 	getLogs.Flags().StringVar(&commaInputs, "inputs", "", "Filter for log lines "+
 		"generated while processing these files (accepts PFS paths or file hashes)")
 	getLogs.Flags().BoolVar(&master, "master", false, "Return log messages from the master process (pipeline must be set).")
-	getLogs.Flags().BoolVar(&raw, "raw", false, "Return log messages verbatim from server.")
+	getLogs.Flags().BoolVar(&rawLogs, "raw", false, "Return log messages verbatim from server.")
 	getLogs.Flags().BoolVarP(&follow, "follow", "f", false, "Follow logs as more are created.")
 	getLogs.Flags().Int64VarP(&tail, "tail", "t", 0, "Lines of recent logs to display.")
+	getLogs.Flags().DurationVar(&since, "since", 0, "Return log messages more recent than this duration (e.g. 1h or 30m).")
+	getLogs.Flags().StringVar(&pattern, "pattern", "", "Return log messages that match this regular expression (RE2 syntax).")
+	getLogs.Flags().StringVar(&workerID, "worker", "", "Return log messages from this worker (accepts worker pod name).")
 	shell.RegisterCompletionFunc(getLogs,
 		func(flag, text string, maxCompletions int64) ([]prompt.Suggest, shell.CacheFunc) {
 			if flag == "--pipeline" || flag == "-p" {
@@ -536,11 +892,13 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 	var registry string
 	var username string
 	var pipelinePath string
+	var atomic bool
+	var bestEffort bool
 	createPipeline := &cobra.Command{
 		Short: "Create a new pipeline.",
 		Long:  "Create a new pipeline from a pipeline specification. For details on the format, see http://docs.pachyderm.io/en/latest/reference/pipeline_spec.html.",
 		Run: cmdutil.RunFixedArgs(0, func(args []string) (retErr error) {
-			return pipelineHelper(false, build, pushImages, registry, username, pipelinePath, false)
+			return pipelineHelper(false, build, pushImages, registry, username, pipelinePath, false, atomic, bestEffort)
 		}),
 	}
 	createPipeline.Flags().StringVarP(&pipelinePath, "file", "f", "-", "The JSON file containing the pipeline, it can be a url or local file. - reads from stdin.")
@@ -548,6 +906,8 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 	createPipeline.Flags().BoolVarP(&pushImages, "push-images", "p", false, "If true, push local docker images into the docker registry.")
 	createPipeline.Flags().StringVarP(&registry, "registry", "r", "index.docker.io", "The registry to push images to.")
 	createPipeline.Flags().StringVarP(&username, "username", "u", "", "The username to push images as.")
+	createPipeline.Flags().BoolVar(&atomic, "atomic", false, "If the file contains several pipelines, send them in one request, and if any of them fails, delete the ones that were created.")
+	createPipeline.Flags().BoolVar(&bestEffort, "best-effort", false, "If the file contains several pipelines, send them in one request, and report the ones that fail rather than stopping at the first failure.")
 	commands = append(commands, cmdutil.CreateAlias(createPipeline, "create pipeline"))
 
 	var reprocess bool
@@ -555,7 +915,7 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 		Short: "Update an existing Pachyderm pipeline.",
 		Long:  "Update a Pachyderm pipeline with a new pipeline specification. For details on the format, see http://docs.pachyderm.io/en/latest/reference/pipeline_spec.html.",
 		Run: cmdutil.RunFixedArgs(0, func(args []string) (retErr error) {
-			return pipelineHelper(reprocess, build, pushImages, registry, username, pipelinePath, true)
+			return pipelineHelper(reprocess, build, pushImages, registry, username, pipelinePath, true, atomic, bestEffort)
 		}),
 	}
 	updatePipeline.Flags().StringVarP(&pipelinePath, "file", "f", "-", "The JSON file containing the pipeline, it can be a url or local file. - reads from stdin.")
@@ -563,6 +923,8 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 	updatePipeline.Flags().BoolVarP(&pushImages, "push-images", "p", false, "If true, push local docker images into the docker registry.")
 	updatePipeline.Flags().StringVarP(&registry, "registry", "r", "index.docker.io", "The registry to push images to.")
 	updatePipeline.Flags().StringVarP(&username, "username", "u", "", "The username to push images as.")
+	updatePipeline.Flags().BoolVar(&atomic, "atomic", false, "If the file contains several pipelines, send them in one request, and if any of them fails, delete the ones that were created.")
+	updatePipeline.Flags().BoolVar(&bestEffort, "best-effort", false, "If the file contains several pipelines, send them in one request, and report the ones that fail rather than stopping at the first failure.")
 	updatePipeline.Flags().BoolVar(&reprocess, "reprocess", false, "If true, reprocess datums that were already processed by previous version of the pipeline.")
 	commands = append(commands, cmdutil.CreateAlias(updatePipeline, "update pipeline"))
 
@@ -621,6 +983,7 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 	}
 	commands = append(commands, cmdutil.CreateAlias(runCron, "run cron"))
 
+	var full bool
 	inspectPipeline := &cobra.Command{
 		Use:   "{{alias}} <pipeline>",
 		Short: "Return info about a pipeline.",
@@ -631,27 +994,35 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 				return err
 			}
 			defer client.Close()
-			pipelineInfo, err := client.InspectPipeline(args[0])
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
+			var pipelineInfo *ppsclient.PipelineInfo
+			if full {
+				pipelineInfo, err = client.InspectPipelineFull(args[0])
+			} else {
+				pipelineInfo, err = client.InspectPipeline(args[0])
+			}
 			if err != nil {
 				return err
 			}
 			if pipelineInfo == nil {
 				return fmt.Errorf("pipeline %s not found", args[0])
 			}
-			if raw {
-				return encoder(output).EncodeProto(pipelineInfo)
-			} else if output != "" {
-				cmdutil.ErrorAndExit("cannot set --output (-o) without --raw")
+			if e != nil {
+				return e.EncodeProto(pipelineInfo)
 			}
 			pi := &pretty.PrintablePipelineInfo{
 				PipelineInfo:   pipelineInfo,
-				FullTimestamps: fullTimestamps,
+				FullTimestamps: fullTimestamps || output.Wide(),
 			}
 			return pretty.PrintDetailedPipelineInfo(pi)
 		}),
 	}
 	inspectPipeline.Flags().AddFlagSet(outputFlags)
 	inspectPipeline.Flags().AddFlagSet(fullTimestampsFlags)
+	inspectPipeline.Flags().BoolVar(&full, "full", false, "Include the pipeline's etcd state and effective defaults.")
 	commands = append(commands, cmdutil.CreateAlias(inspectPipeline, "inspect pipeline"))
 
 	extractPipeline := &cobra.Command{
@@ -668,10 +1039,10 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 			if err != nil {
 				return err
 			}
-			return encoder(output).EncodeProto(createPipelineRequest)
+			return encoder(manifestFormat).EncodeProto(createPipelineRequest)
 		}),
 	}
-	extractPipeline.Flags().StringVarP(&output, "output", "o", "", "Output format: \"json\" or \"yaml\" (default \"json\")")
+	extractPipeline.Flags().StringVarP(&manifestFormat, "output", "o", "", "Output format: \"json\" or \"yaml\" (default \"json\")")
 	commands = append(commands, cmdutil.CreateAlias(extractPipeline, "extract pipeline"))
 
 	var editor string
@@ -693,7 +1064,7 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 			if err != nil {
 				return err
 			}
-			if err := encoder(output, f).EncodeProto(createPipelineRequest); err != nil {
+			if err := encoder(manifestFormat, f).EncodeProto(createPipelineRequest); err != nil {
 				return err
 			}
 			defer func() {
@@ -728,6 +1099,9 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 			}
 			request.Update = true
 			request.Reprocess = reprocess
+			if err := client.RequireFeatures(pachdclient.PipelineFeatures(request)...); err != nil {
+				return err
+			}
 			if _, err := client.PpsAPIClient.CreatePipeline(
 				client.Ctx(),
 				request,
@@ -739,7 +1113,7 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 	}
 	editPipeline.Flags().BoolVar(&reprocess, "reprocess", false, "If true, reprocess datums that were already processed by previous version of the pipeline.")
 	editPipeline.Flags().StringVar(&editor, "editor", "", "Editor to use for modifying the manifest.")
-	editPipeline.Flags().StringVarP(&output, "output", "o", "", "Output format: \"json\" or \"yaml\" (default \"json\")")
+	editPipeline.Flags().StringVarP(&manifestFormat, "output", "o", "", "Output format: \"json\" or \"yaml\" (default \"json\")")
 	commands = append(commands, cmdutil.CreateAlias(editPipeline, "edit pipeline"))
 
 	var spec bool
@@ -749,10 +1123,18 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 		Long:  "Return info about all pipelines.",
 		Run: cmdutil.RunBoundedArgs(0, 1, func(args []string) error {
 			// validate flags
-			if raw && spec {
-				return fmt.Errorf("cannot set both --raw and --spec")
-			} else if !raw && !spec && output != "" {
-				cmdutil.ErrorAndExit("cannot set --output (-o) without --raw or --spec")
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
+			if spec {
+				if output.Wide() {
+					return fmt.Errorf("cannot set --spec with --output wide")
+				}
+				if e == nil {
+					// Specs are always serialized, in json by default
+					e = encoder("json")
+				}
 			}
 			history, err := cmdutil.ParseHistory(history)
 			if err != nil {
@@ -769,38 +1151,73 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 			if len(args) > 0 {
 				pipeline = args[0]
 			}
-			pipelineInfos, err := client.ListPipelineHistory(pipeline, history)
+			states, err := parsePipelineStates(stateStrs)
+			if err != nil {
+				return err
+			}
+			request := &ppsclient.ListPipelineRequest{
+				History:       history,
+				LabelSelector: labelSelector,
+				State:         states,
+			}
+			if pipeline != "" {
+				request.Pipeline = pachdclient.NewPipeline(pipeline)
+			}
+			if watchList {
+				if history != 0 {
+					return fmt.Errorf("cannot set --watch with --history")
+				}
+				if e != nil {
+					return client.WatchPipeline(request, func(pipelineInfo *ppsclient.PipelineInfo) error {
+						if spec {
+							return e.EncodeProto(ppsutil.PipelineReqFromInfo(pipelineInfo))
+						}
+						return e.EncodeProto(pipelineInfo)
+					})
+				}
+				table := tabwriter.NewLiveTable(os.Stdout, pipelineHeader(output), false)
+				return table.Run(func() error {
+					return client.WatchPipeline(request, func(pipelineInfo *ppsclient.PipelineInfo) error {
+						var buf bytes.Buffer
+						printPipelineInfo(&buf, pipelineInfo, output, fullTimestamps)
+						table.Set(pipelineInfo.Pipeline.Name, pipelineInfo.Pipeline.Name, buf.String())
+						return nil
+					})
+				})
+			}
+			pipelineInfos, err := client.ListPipelineWithRequest(request)
 			if err != nil {
 				return err
 			}
-			if raw {
-				e := encoder(output)
+			if spec {
 				for _, pipelineInfo := range pipelineInfos {
-					if err := e.EncodeProto(pipelineInfo); err != nil {
+					if err := e.EncodeProto(ppsutil.PipelineReqFromInfo(pipelineInfo)); err != nil {
 						return err
 					}
 				}
 				return nil
-			} else if spec {
-				e := encoder(output)
+			} else if e != nil {
 				for _, pipelineInfo := range pipelineInfos {
-					if err := e.EncodeProto(ppsutil.PipelineReqFromInfo(pipelineInfo)); err != nil {
+					if err := e.EncodeProto(pipelineInfo); err != nil {
 						return err
 					}
 				}
 				return nil
 			}
-			writer := tabwriter.NewWriter(os.Stdout, pretty.PipelineHeader)
+			writer := tabwriter.NewWriter(os.Stdout, pipelineHeader(output))
 			for _, pipelineInfo := range pipelineInfos {
-				pretty.PrintPipelineInfo(writer, pipelineInfo, fullTimestamps)
+				printPipelineInfo(writer, pipelineInfo, output, fullTimestamps)
 			}
 			return writer.Flush()
 		}),
 	}
-	listPipeline.Flags().BoolVarP(&spec, "spec", "s", false, "Output 'create pipeline' compatibility specs.")
+	listPipeline.Flags().BoolVarP(&spec, "spec", "s", false, "Output 'create pipeline' compatibility specs (in json, unless --output is yaml or a template).")
 	listPipeline.Flags().AddFlagSet(outputFlags)
 	listPipeline.Flags().AddFlagSet(fullTimestampsFlags)
 	listPipeline.Flags().StringVar(&history, "history", "none", "Return revision history for pipelines.")
+	listPipeline.Flags().StringVarP(&labelSelector, "selector", "l", "", "Only return pipelines whose labels match this label selector (e.g. team=nlp,env!=dev).")
+	listPipeline.Flags().BoolVarP(&watchList, "watch", "w", false, "After listing pipelines, keep the list updated as pipelines change.")
+	listPipeline.Flags().StringSliceVar(&stateStrs, "state", []string{}, "Only return pipelines in one of these states (e.g. running, failure).")
 	commands = append(commands, cmdutil.CreateAlias(listPipeline, "list pipeline"))
 
 	var all bool
@@ -877,26 +1294,131 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 	}
 	commands = append(commands, cmdutil.CreateAlias(stopPipeline, "stop pipeline"))
 
+	templateDocs := &cobra.Command{
+		Short: "Docs for pipeline templates.",
+		Long: `Pipeline templates are pipeline specs, stored in PFS, containing
+parameters written as Go template actions (e.g. {{.customer}}). Instantiating
+a template renders it once for each set of parameter values and creates the
+resulting pipelines, which is useful for running the same transform for
+many customers or datasets.`,
+	}
+	commands = append(commands, cmdutil.CreateDocsAlias(templateDocs, "template", " template$"))
+
+	var params []string
+	var paramsFile string
+	var update bool
+	instantiateTemplate := &cobra.Command{
+		Use:   "{{alias}} <repo>@<branch-or-commit>:<path/to/template>",
+		Short: "Create pipelines from a pipeline template.",
+		Long:  "Create pipelines from a pipeline template stored in PFS. Parameters can be given with --param (to create one instance of the template) or with --params-file, which is a JSON list of parameter objects (to create one instance per object).",
+		Example: `
+		# Create the pipelines in templates@master:/edges.json for customer "acme"
+		$ {{alias}} templates@master:/edges.json --param customer=acme
+
+		# Create the pipelines in templates@master:/edges.json for every customer in customers.json, e.g.
+		# [{"customer": "acme"}, {"customer": "initech"}]
+		$ {{alias}} templates@master:/edges.json --params-file customers.json`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			file, err := cmdutil.ParseFile(args[0])
+			if err != nil {
+				return err
+			}
+			var parameters []map[string]string
+			if paramsFile != "" {
+				data, err := ioutil.ReadFile(paramsFile)
+				if err != nil {
+					return err
+				}
+				if err := json.Unmarshal(data, &parameters); err != nil {
+					return fmt.Errorf("could not parse %s: %v", paramsFile, err)
+				}
+			}
+			if len(params) > 0 {
+				values := make(map[string]string)
+				for _, param := range params {
+					kv := strings.SplitN(param, "=", 2)
+					if len(kv) != 2 {
+						return fmt.Errorf("malformed parameter %q, expected key=value", param)
+					}
+					values[kv[0]] = kv[1]
+				}
+				parameters = append(parameters, values)
+			}
+			if len(parameters) == 0 {
+				return fmt.Errorf("at least one of --param or --params-file must be set")
+			}
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			pipelines, err := client.InstantiateTemplate(file.Commit.Repo.Name, file.Commit.ID, file.Path, parameters, update)
+			if err != nil {
+				return err
+			}
+			for _, pipeline := range pipelines {
+				fmt.Println(pipeline.Name)
+			}
+			return nil
+		}),
+	}
+	instantiateTemplate.Flags().StringSliceVar(&params, "param", nil, "A template parameter, as key=value (may be repeated).")
+	instantiateTemplate.Flags().StringVarP(&paramsFile, "params-file", "f", "", "A JSON file containing a list of parameter objects; the template is instantiated once per object.")
+	instantiateTemplate.Flags().BoolVar(&update, "update", false, "Update pipelines that already exist instead of failing.")
+	shell.RegisterCompletionFunc(instantiateTemplate, shell.FileCompletion)
+	commands = append(commands, cmdutil.CreateAlias(instantiateTemplate, "instantiate template"))
+
 	var file string
+	var secretName string
+	var registryServer string
+	var registryUsername string
 	createSecret := &cobra.Command{
 		Short: "Create a secret on the cluster.",
-		Long:  "Create a secret on the cluster.",
+		Long: `Create a secret on the cluster.
+
+With --registry, the secret holds credentials for a private docker registry,
+and can be used by pipelines that list it in their transform's
+"image_pull_secrets". The credentials are read from your docker config if
+possible, and otherwise prompted for.`,
+		Example: `
+# Create a secret from a kubernetes secret manifest
+$ {{alias}} -f secret.json
+
+# Create a secret holding credentials for quay.io, named "quay-creds"
+$ {{alias}} --registry quay.io --name quay-creds --username robot`,
 		Run: cmdutil.RunFixedArgs(0, func(args []string) (retErr error) {
 			client, err := pachdclient.NewOnUserMachine("user")
 			if err != nil {
 				return err
 			}
 			defer client.Close()
-			fileBytes, err := ioutil.ReadFile(file)
-			if err != nil {
-				return err
+			request := &ppsclient.CreateSecretRequest{}
+			if registryServer != "" {
+				if file != "" {
+					return fmt.Errorf("only one of --file and --registry may be set")
+				}
+				if secretName == "" {
+					return fmt.Errorf("--name must be set when creating a registry secret")
+				}
+				authConfig, err := dockerConfig(registryServer, registryUsername)
+				if err != nil {
+					return err
+				}
+				request.Registry = &ppsclient.RegistryCredential{
+					Name:     secretName,
+					Server:   registryServer,
+					Username: authConfig.Username,
+					Password: authConfig.Password,
+					Email:    authConfig.Email,
+				}
+			} else {
+				request.File, err = ioutil.ReadFile(file)
+				if err != nil {
+					return err
+				}
 			}
 
-			_, err = client.PpsAPIClient.CreateSecret(
-				client.Ctx(),
-				&ppsclient.CreateSecretRequest{
-					File: fileBytes,
-				})
+			_, err = client.PpsAPIClient.CreateSecret(client.Ctx(), request)
 
 			if err != nil {
 				return grpcutil.ScrubGRPC(err)
@@ -905,8 +1427,46 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 		}),
 	}
 	createSecret.Flags().StringVarP(&file, "file", "f", "", "File containing Kubernetes secret.")
+	createSecret.Flags().StringVar(&registryServer, "registry", "", "Create an image pull secret for this docker registry (e.g. \"quay.io\") instead of reading a secret from --file.")
+	createSecret.Flags().StringVar(&secretName, "name", "", "The name of the registry secret to create.")
+	createSecret.Flags().StringVar(&registryUsername, "username", "", "The username to authenticate with the registry as.")
 	commands = append(commands, cmdutil.CreateAlias(createSecret, "create secret"))
 
+	updateSecret := &cobra.Command{
+		Use:   "{{alias}} <secret>",
+		Short: "Replace the data of a secret on the cluster.",
+		Long: `Replace the data of a secret on the cluster, which must have been created
+with 'pachctl create secret'.
+
+Pipelines pick up the new data without being restarted: files of secrets
+mounted with "mount_path" are updated in place, and env vars set from secrets
+with "env_var" take their new values the next time that the pipeline's code
+runs (though a service or spout only sees them when it's restarted). It can
+take a minute or two for the new data to reach every worker.
+
+The new data must contain every key of the secret that's used by a pipeline.`,
+		Example: `
+# Rotate the secret "db-creds" to the data in a kubernetes secret manifest
+$ {{alias}} db-creds -f new-creds.json`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) (retErr error) {
+			if file == "" {
+				return fmt.Errorf("--file must be set")
+			}
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			return client.RotateSecret(args[0], data)
+		}),
+	}
+	updateSecret.Flags().StringVarP(&file, "file", "f", "", "File containing a Kubernetes secret with the new data.")
+	commands = append(commands, cmdutil.CreateAlias(updateSecret, "update secret"))
+
 	deleteSecret := &cobra.Command{
 		Short: "Delete a secret from the cluster.",
 		Long:  "Delete a secret from the cluster.",
@@ -942,6 +1502,10 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 				return err
 			}
 			defer client.Close()
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
 
 			secretInfo, err := client.PpsAPIClient.InspectSecret(
 				client.Ctx(),
@@ -954,11 +1518,15 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 			if err != nil {
 				return grpcutil.ScrubGRPC(err)
 			}
+			if e != nil {
+				return e.EncodeProto(secretInfo)
+			}
 			writer := tabwriter.NewWriter(os.Stdout, pretty.SecretHeader)
 			pretty.PrintSecretInfo(writer, secretInfo)
 			return writer.Flush()
 		}),
 	}
+	inspectSecret.Flags().AddFlagSet(outputFlags)
 	commands = append(commands, cmdutil.CreateAlias(inspectSecret, "inspect secret"))
 
 	listSecret := &cobra.Command{
@@ -970,6 +1538,10 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 				return err
 			}
 			defer client.Close()
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
 
 			secretInfos, err := client.PpsAPIClient.ListSecret(
 				client.Ctx(),
@@ -979,6 +1551,14 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 			if err != nil {
 				return grpcutil.ScrubGRPC(err)
 			}
+			if e != nil {
+				for _, si := range secretInfos.GetSecretInfo() {
+					if err := e.EncodeProto(si); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
 			writer := tabwriter.NewWriter(os.Stdout, pretty.SecretHeader)
 			for _, si := range secretInfos.GetSecretInfo() {
 				pretty.PrintSecretInfo(writer, si)
@@ -986,9 +1566,12 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 			return writer.Flush()
 		}),
 	}
+	listSecret.Flags().AddFlagSet(outputFlags)
 	commands = append(commands, cmdutil.CreateAlias(listSecret, "list secret"))
 
 	var memory string
+	var gcStatus bool
+	var gcGracePeriod string
 	garbageCollect := &cobra.Command{
 		Short: "Garbage collect unused data.",
 		Long: `Garbage collect unused data.
@@ -1001,10 +1584,13 @@ file is not necessarily wiped from disk immediately.
 To actually remove the data, you will need to manually invoke garbage
 collection with "pachctl garbage-collect".
 
-Currently "pachctl garbage-collect" can only be started when there are no
-pipelines running.  You also need to ensure that there's no ongoing "put file".
-Garbage collection puts the cluster into a readonly mode where no new jobs can
-be created and no data can be added.
+Garbage collection runs concurrently with pipelines and "put file". It first
+finds the objects that are in use (marking), and then deletes the rest in
+batches (sweeping), except for those that were written in the last hour (or
+--grace-period), which may be about to be used. "pachctl garbage-collect" blocks
+until garbage collection finishes; "pachctl garbage-collect --status" shows the
+progress of the running garbage collection (including the bytes reclaimed, the
+objects scanned and the estimated time remaining), or the result of the last one.
 
 Pachyderm's garbage collection uses bloom filters to index live objects. This
 means that some dead objects may erronously not be deleted during garbage
@@ -1020,20 +1606,79 @@ you can increase the amount of memory used for the bloom filters with the
 				return err
 			}
 			defer client.Close()
+			if gcStatus {
+				e, err := output.Encoder(os.Stdout)
+				if err != nil {
+					return err
+				}
+				info, err := client.InspectGarbageCollect()
+				if err != nil {
+					return err
+				}
+				if e != nil {
+					return e.EncodeProto(info)
+				}
+				pretty.PrintGarbageCollectInfo(os.Stdout, info, fullTimestamps || output.Wide())
+				return nil
+			}
 			memoryBytes, err := units.RAMInBytes(memory)
 			if err != nil {
 				return err
 			}
+			if gcGracePeriod != "" {
+				gracePeriod, err := time.ParseDuration(gcGracePeriod)
+				if err != nil {
+					return err
+				}
+				return client.GarbageCollectWithGracePeriod(memoryBytes, gracePeriod)
+			}
 			return client.GarbageCollect(memoryBytes)
 		}),
 	}
 	garbageCollect.Flags().StringVarP(&memory, "memory", "m", "0", "The amount of memory to use during garbage collection. Default is 10MB.")
+	garbageCollect.Flags().StringVar(&gcGracePeriod, "grace-period", "", "How long to spare unreferenced data for after it's written, as it may be about to be used (at most, and by default, 1h). Only use a short grace period when nothing is being written.")
+	garbageCollect.Flags().BoolVar(&gcStatus, "status", false, "Show the progress of the running garbage collection, or the result of the last one, instead of starting one.")
+	garbageCollect.Flags().AddFlagSet(outputFlags)
+	garbageCollect.Flags().AddFlagSet(fullTimestampsFlags)
 	commands = append(commands, cmdutil.CreateAlias(garbageCollect, "garbage-collect"))
 
+	explainCmd := &cobra.Command{
+		Use:   "{{alias}} [<field>]",
+		Short: "Print the documentation of a pipeline spec field.",
+		Long: "Print the documentation of a pipeline spec field, along with its type " +
+			"and, if it's an object, its fields. Nested fields are separated by '.'. " +
+			"With no arguments, the top-level fields of the pipeline spec are printed.",
+		Example: `
+# Print the top-level fields of the pipeline spec
+$ {{alias}}
+
+# Print the documentation of the 'transform' field, and its fields
+$ {{alias}} transform
+
+# Print the documentation of the 'glob' field of a pfs input
+$ {{alias}} input.pfs.glob`,
+		Run: cmdutil.RunBoundedArgs(0, 1, func(args []string) error {
+			var fieldPath string
+			if len(args) > 0 {
+				fieldPath = args[0]
+			}
+			text, err := explain(fieldPath)
+			if err != nil {
+				return err
+			}
+			fmt.Print(text)
+			return nil
+		}),
+	}
+	commands = append(commands, cmdutil.CreateAlias(explainCmd, "explain"))
+
 	return commands
 }
 
-func pipelineHelper(reprocess bool, build bool, pushImages bool, registry string, username string, pipelinePath string, update bool) error {
+func pipelineHelper(reprocess bool, build bool, pushImages bool, registry string, username string, pipelinePath string, update bool, atomic bool, bestEffort bool) error {
+	if atomic && bestEffort {
+		return fmt.Errorf("only one of --atomic and --best-effort may be set")
+	}
 	pipelineReader, err := ppsutil.NewPipelineManifestReader(pipelinePath)
 	if err != nil {
 		return err
@@ -1043,6 +1688,9 @@ func pipelineHelper(reprocess bool, build bool, pushImages bool, registry string
 		return fmt.Errorf("error connecting to pachd: %v", err)
 	}
 	defer client.Close()
+	// requests are the pipelines to create in a single CreatePipelines request,
+	// if --atomic or --best-effort is set
+	var requests []*ppsclient.CreatePipelineRequest
 	for {
 		request, err := pipelineReader.NextCreatePipelineRequest()
 		if err == io.EOF {
@@ -1102,6 +1750,13 @@ func pipelineHelper(reprocess bool, build bool, pushImages bool, registry string
 			}
 			request.Transform.Image = image
 		}
+		if err := client.RequireFeatures(pachdclient.PipelineFeatures(request)...); err != nil {
+			return err
+		}
+		if atomic || bestEffort {
+			requests = append(requests, request)
+			continue
+		}
 		if _, err := client.PpsAPIClient.CreatePipeline(
 			client.Ctx(),
 			request,
@@ -1109,7 +1764,21 @@ func pipelineHelper(reprocess bool, build bool, pushImages bool, registry string
 			return grpcutil.ScrubGRPC(err)
 		}
 	}
-	return nil
+	if len(requests) == 0 {
+		return nil
+	}
+	if err := client.RequireFeatures(version.FeatureBulkResults); err != nil {
+		return err
+	}
+	mode := errcode.BulkMode_ATOMIC
+	if bestEffort {
+		mode = errcode.BulkMode_BEST_EFFORT
+	}
+	response, err := client.CreatePipelines(mode, requests...)
+	if err != nil {
+		return err
+	}
+	return response.Err()
 }
 
 // ByCreationTime is an implementation of sort.Interface which
@@ -1221,3 +1890,39 @@ func pushImage(client *docker.Client, authConfig docker.AuthConfiguration, repo
 
 	return destImage, nil
 }
+
+// parseJobStates parses job states given as flags, either as the full enum
+// name (e.g. JOB_RUNNING) or without its prefix, in any case (e.g. running)
+func parseJobStates(strs []string) ([]ppsclient.JobState, error) {
+	var states []ppsclient.JobState
+	for _, str := range strs {
+		name := strings.ToUpper(str)
+		if !strings.HasPrefix(name, "JOB_") {
+			name = "JOB_" + name
+		}
+		state, ok := ppsclient.JobState_value[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown job state %q", str)
+		}
+		states = append(states, ppsclient.JobState(state))
+	}
+	return states, nil
+}
+
+// parsePipelineStates is like parseJobStates, but for pipeline states (e.g.
+// PIPELINE_PAUSED or paused)
+func parsePipelineStates(strs []string) ([]ppsclient.PipelineState, error) {
+	var states []ppsclient.PipelineState
+	for _, str := range strs {
+		name := strings.ToUpper(str)
+		if !strings.HasPrefix(name, "PIPELINE_") {
+			name = "PIPELINE_" + name
+		}
+		state, ok := ppsclient.PipelineState_value[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown pipeline state %q", str)
+		}
+		states = append(states, ppsclient.PipelineState(state))
+	}
+	return states, nil
+}
@@ -0,0 +1,201 @@
+package cmds
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	ppsclient "github.com/pachyderm/pachyderm/src/client/pps"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+)
+
+// wellKnownTypes are the message types that appear as strings in pipeline
+// specs, and so aren't expanded by explain
+var wellKnownTypes = map[string]string{
+	".google.protobuf.Duration":  "duration",
+	".google.protobuf.Timestamp": "timestamp",
+}
+
+// explain returns the documentation of the pipeline spec field at 'fieldPath'
+// (e.g. "transform.image"), including its type and, if it's an object or an
+// enum, its fields or values. If 'fieldPath' is empty, the top-level fields of
+// the pipeline spec are documented.
+func explain(fieldPath string) (string, error) {
+	msg, err := lookupMessage(proto.MessageName(&ppsclient.CreatePipelineRequest{}))
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if fieldPath == "" {
+		fmt.Fprintf(&buf, "PIPELINE SPEC\n\n")
+		writeFields(&buf, msg, "pps.CreatePipelineRequest")
+		return buf.String(), nil
+	}
+
+	msgName := "pps.CreatePipelineRequest"
+	var field *descriptor.FieldDescriptorProto
+	parts := strings.Split(fieldPath, ".")
+	for i, part := range parts {
+		if field = findField(msg, part); field == nil {
+			return "", fmt.Errorf("%q has no field %q", strings.Join(parts[:i], "."), part)
+		}
+		if i == len(parts)-1 {
+			break
+		}
+		if field.GetType() != descriptor.FieldDescriptorProto_TYPE_MESSAGE || isMap(msg, field) ||
+			wellKnownTypes[field.GetTypeName()] != "" {
+			return "", fmt.Errorf("%q has no fields", strings.Join(parts[:i+1], "."))
+		}
+		msgName = strings.TrimPrefix(field.GetTypeName(), ".")
+		if msg, err = lookupMessage(msgName); err != nil {
+			return "", err
+		}
+	}
+
+	fmt.Fprintf(&buf, "FIELD: %s (%s)\n", fieldPath, fieldType(msg, field))
+	if doc := ppsclient.Docs[msgName+"."+field.GetName()]; doc != "" {
+		fmt.Fprintf(&buf, "\n%s\n", indent(doc))
+	}
+	switch {
+	case field.GetType() == descriptor.FieldDescriptorProto_TYPE_MESSAGE &&
+		!isMap(msg, field) && wellKnownTypes[field.GetTypeName()] == "":
+		fieldMsgName := strings.TrimPrefix(field.GetTypeName(), ".")
+		fieldMsg, err := lookupMessage(fieldMsgName)
+		if err != nil {
+			return "", err
+		}
+		if doc := ppsclient.Docs[fieldMsgName]; doc != "" {
+			fmt.Fprintf(&buf, "\n%s\n", indent(doc))
+		}
+		fmt.Fprintf(&buf, "\nFIELDS:\n")
+		writeFields(&buf, fieldMsg, fieldMsgName)
+	case field.GetType() == descriptor.FieldDescriptorProto_TYPE_ENUM:
+		enumName := strings.TrimPrefix(field.GetTypeName(), ".")
+		fmt.Fprintf(&buf, "\nVALUES:\n")
+		writeEnumValues(&buf, enumName)
+	}
+	return buf.String(), nil
+}
+
+// lookupMessage returns the descriptor of the message type 'name' (e.g.
+// "pps.Transform")
+func lookupMessage(name string) (*descriptor.DescriptorProto, error) {
+	t := proto.MessageType(name)
+	if t == nil {
+		return nil, fmt.Errorf("unknown message type %q", name)
+	}
+	msg, ok := reflect.New(t.Elem()).Interface().(descriptor.Message)
+	if !ok {
+		return nil, fmt.Errorf("no descriptor for message type %q", name)
+	}
+	_, md := descriptor.ForMessage(msg)
+	return md, nil
+}
+
+// findField returns the field of 'msg' called 'name' (which may be its proto
+// or JSON name), or nil if there isn't one
+func findField(msg *descriptor.DescriptorProto, name string) *descriptor.FieldDescriptorProto {
+	for _, field := range msg.Field {
+		if field.GetName() == name || field.GetJsonName() == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// mapEntry returns the synthetic message type of 'field' if it's a map field
+// of 'msg', and nil otherwise
+func mapEntry(msg *descriptor.DescriptorProto, field *descriptor.FieldDescriptorProto) *descriptor.DescriptorProto {
+	if field.GetType() != descriptor.FieldDescriptorProto_TYPE_MESSAGE {
+		return nil
+	}
+	typeName := field.GetTypeName()
+	for _, nested := range msg.NestedType {
+		if nested.GetOptions().GetMapEntry() && strings.HasSuffix(typeName, "."+nested.GetName()) {
+			return nested
+		}
+	}
+	return nil
+}
+
+func isMap(msg *descriptor.DescriptorProto, field *descriptor.FieldDescriptorProto) bool {
+	return mapEntry(msg, field) != nil
+}
+
+// fieldType returns a short description of the type of 'field' (a field of
+// 'msg'), e.g. "[]string" or "Transform"
+func fieldType(msg *descriptor.DescriptorProto, field *descriptor.FieldDescriptorProto) string {
+	if entry := mapEntry(msg, field); entry != nil {
+		return fmt.Sprintf("map[%s]%s", fieldType(entry, entry.Field[0]), fieldType(entry, entry.Field[1]))
+	}
+	var result string
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_MESSAGE, descriptor.FieldDescriptorProto_TYPE_ENUM:
+		if wellKnown, ok := wellKnownTypes[field.GetTypeName()]; ok {
+			result = wellKnown
+		} else {
+			result = field.GetTypeName()[strings.LastIndex(field.GetTypeName(), ".")+1:]
+		}
+	default:
+		result = strings.ToLower(strings.TrimPrefix(field.GetType().String(), "TYPE_"))
+	}
+	if field.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		result = "[]" + result
+	}
+	return result
+}
+
+// writeFields writes a table of the fields of 'msg' (whose full name is
+// 'msgName') with the first sentence of each one's documentation
+func writeFields(buf *bytes.Buffer, msg *descriptor.DescriptorProto, msgName string) {
+	w := tabwriter.NewWriter(buf, 0, 1, 2, ' ', 0)
+	for _, field := range msg.Field {
+		doc := ppsclient.Docs[msgName+"."+field.GetName()]
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", field.GetName(), fieldType(msg, field), firstSentence(doc))
+	}
+	w.Flush()
+}
+
+// writeEnumValues writes the values of the enum 'enumName', in order, with
+// their documentation
+func writeEnumValues(buf *bytes.Buffer, enumName string) {
+	values := proto.EnumValueMap(enumName)
+	if values == nil {
+		// Nested enums are registered as "<package>.<Message>_<Enum>"
+		i := strings.LastIndex(enumName, ".")
+		values = proto.EnumValueMap(enumName[:i] + "_" + enumName[i+1:])
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return values[names[i]] < values[names[j]] })
+	w := tabwriter.NewWriter(buf, 0, 1, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\t%s\n", name, firstSentence(ppsclient.Docs[enumName+"."+name]))
+	}
+	w.Flush()
+}
+
+// firstSentence returns the first sentence of 'doc', on one line
+func firstSentence(doc string) string {
+	doc = strings.Join(strings.Fields(doc), " ")
+	for i := 0; i < len(doc); i++ {
+		if doc[i] != '.' || i+1 < len(doc) && doc[i+1] != ' ' {
+			continue
+		}
+		if !strings.HasSuffix(doc[:i], "e.g") && !strings.HasSuffix(doc[:i], "i.e") {
+			return doc[:i+1]
+		}
+	}
+	return doc
+}
+
+func indent(doc string) string {
+	return "  " + strings.Replace(doc, "\n", "\n  ", -1)
+}
@@ -0,0 +1,55 @@
+package cmds
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestExplain(t *testing.T) {
+	text, err := explain("")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(text, "PIPELINE SPEC\n"))
+	require.True(t, strings.Contains(text, "  transform "))
+	require.True(t, strings.Contains(text, "  input "))
+
+	text, err = explain("transform.image")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(text, "FIELD: transform.image (string)\n"))
+	require.True(t, strings.Contains(text, "docker image"))
+
+	text, err = explain("transform")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(text, "FIELD: transform (Transform)\n"))
+	require.True(t, strings.Contains(text, "\nFIELDS:\n"))
+	require.True(t, strings.Contains(text, "  cmd "))
+	require.True(t, strings.Contains(text, "map[string]string"))
+
+	text, err = explain("transform.image_pinning")
+	require.NoError(t, err)
+	require.True(t, strings.Contains(text, "\nVALUES:\n"))
+	require.True(t, strings.Index(text, "IMAGE_PINNING_NONE") < strings.Index(text, "IMAGE_PINNING_STRICT"))
+
+	text, err = explain("datum_timeout")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(text, "FIELD: datum_timeout (duration)\n"))
+
+	// Nested inputs are explained through the input's type
+	text, err = explain("input.cross")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(text, "FIELD: input.cross ([]Input)\n"))
+
+	_, err = explain("transform.nonexistent")
+	require.YesError(t, err)
+	_, err = explain("transform.image.foo")
+	require.YesError(t, err)
+}
+
+func TestFirstSentence(t *testing.T) {
+	require.Equal(t, "", firstSentence(""))
+	require.Equal(t, "one line", firstSentence("one line"))
+	require.Equal(t, "Two lines.", firstSentence("Two\nlines. And more"))
+	require.Equal(t, "Run a command, e.g. ls, in the container.",
+		firstSentence("Run a command, e.g. ls, in the\ncontainer. Other"))
+}
@@ -0,0 +1,192 @@
+// Package egressproxy implements the HTTP proxy that runs in the worker pods
+// of pipelines with an egress proxy (see pps.EgressProxy). User code sends its
+// HTTP and HTTPS requests through the proxy (see UserEnv), which forwards
+// requests to the hosts in the pipeline's allow-list and refuses the rest,
+// reporting each refused request as a violation.
+package egressproxy
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Port is the port that the egress proxy listens on, in the worker pod
+	Port = 3128
+	// dialTimeout is the maximum time that the proxy waits to connect to an
+	// allowed host
+	dialTimeout = 30 * time.Second
+)
+
+// ValidateHost returns an error if 'host' isn't a valid egress proxy host,
+// i.e. a hostname or IP address, optionally preceded by "*." (which matches
+// every subdomain) and optionally followed by a port
+func ValidateHost(host string) error {
+	if strings.ContainsAny(host, "/,") {
+		return fmt.Errorf("invalid host %q (hosts can't include a scheme, a path or commas)", host)
+	}
+	name, port := splitHostPort(host)
+	if port != "" {
+		if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+			return fmt.Errorf("invalid port in host %q", host)
+		}
+	}
+	name = strings.TrimPrefix(name, "*.")
+	if name == "" || strings.Contains(name, "*") {
+		return fmt.Errorf("invalid host %q (wildcards may only be used as a \"*.\" prefix)", host)
+	}
+	return nil
+}
+
+// Allowed returns true if a request to 'hostport' (a host and, optionally, a
+// port) matches one of 'hosts', an egress proxy allow-list
+func Allowed(hosts []string, hostport string) bool {
+	name, port := splitHostPort(hostport)
+	name = strings.ToLower(name)
+	for _, host := range hosts {
+		allowedName, allowedPort := splitHostPort(host)
+		allowedName = strings.ToLower(allowedName)
+		if allowedPort != "" && allowedPort != port {
+			continue
+		}
+		if strings.HasPrefix(allowedName, "*.") {
+			if strings.HasSuffix(name, allowedName[1:]) {
+				return true
+			}
+		} else if name == allowedName {
+			return true
+		}
+	}
+	return false
+}
+
+// splitHostPort is like net.SplitHostPort, but allows 'hostport' to omit the
+// port (in which case 'port' is "")
+func splitHostPort(hostport string) (host, port string) {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return strings.TrimSuffix(strings.TrimPrefix(hostport, "["), "]"), ""
+	}
+	return host, port
+}
+
+// UserEnv returns the environment variables that send the HTTP and HTTPS
+// requests of user code through the egress proxy, except for requests to
+// the worker pod itself and to the hosts in 'noProxy' (e.g. pachd)
+func UserEnv(noProxy ...string) []string {
+	proxy := fmt.Sprintf("http://localhost:%d", Port)
+	noProxy = append([]string{"localhost", "127.0.0.1"}, noProxy...)
+	var result []string
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY"} {
+		result = append(result,
+			fmt.Sprintf("%s=%s", name, proxy),
+			fmt.Sprintf("%s=%s", strings.ToLower(name), proxy))
+	}
+	return append(result,
+		fmt.Sprintf("NO_PROXY=%s", strings.Join(noProxy, ",")),
+		fmt.Sprintf("no_proxy=%s", strings.Join(noProxy, ",")))
+}
+
+type proxy struct {
+	hosts     []string
+	violation func(host string)
+	forward   *httputil.ReverseProxy
+	logger    *logrus.Entry
+}
+
+// Server returns an HTTP server for the egress proxy, which listens on Port
+// and only forwards requests to 'hosts'. 'violation' is called with the host
+// of each request that the proxy refuses. As with the artifact cache, it's
+// the caller's responsibility to start the returned server.
+func Server(hosts []string, violation func(host string)) *http.Server {
+	return &http.Server{
+		Addr:    fmt.Sprintf(":%d", Port),
+		Handler: newProxy(hosts, violation),
+	}
+}
+
+func newProxy(hosts []string, violation func(host string)) *proxy {
+	return &proxy{
+		hosts:     hosts,
+		violation: violation,
+		// Requests to a proxy have absolute URLs, so they can be forwarded
+		// unmodified
+		forward: &httputil.ReverseProxy{Director: func(*http.Request) {}},
+		logger:  logrus.WithFields(logrus.Fields{"source": "egress-proxy"}),
+	}
+}
+
+func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if r.Method != http.MethodConnect {
+		if !r.URL.IsAbs() {
+			http.Error(w, "this is an egress proxy, requests must have absolute URLs", http.StatusBadRequest)
+			return
+		}
+		host = r.URL.Host
+		if _, port := splitHostPort(host); port == "" {
+			port = "80"
+			if r.URL.Scheme == "https" {
+				port = "443"
+			}
+			host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+		}
+	}
+	if !Allowed(p.hosts, host) {
+		p.logger.Warnf("refused %s request to %s, which isn't in the pipeline's egress proxy hosts", r.Method, host)
+		p.violation(host)
+		http.Error(w, fmt.Sprintf("%s isn't in the pipeline's egress proxy hosts", host), http.StatusForbidden)
+		return
+	}
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, host)
+		return
+	}
+	p.forward.ServeHTTP(w, r)
+}
+
+// tunnel connects the client of 'w' to 'host', for a CONNECT request (which
+// is how HTTPS requests are proxied)
+func (p *proxy) tunnel(w http.ResponseWriter, host string) {
+	upstream, err := net.DialTimeout("tcp", host, dialTimeout)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not connect to %s: %v", host, err), http.StatusBadGateway)
+		return
+	}
+	defer upstream.Close()
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "the egress proxy doesn't support CONNECT over this connection", http.StatusInternalServerError)
+		return
+	}
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		p.logger.Errorf("could not hijack connection for CONNECT to %s: %v", host, err)
+		return
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+		return
+	}
+	done := make(chan struct{}, 2)
+	go func() {
+		// 'buf' may hold data that the client sent after its request
+		io.Copy(upstream, buf)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(conn, upstream)
+		done <- struct{}{}
+	}()
+	// Close both connections (unblocking the other copy) as soon as either
+	// side is done
+	<-done
+}
@@ -0,0 +1,97 @@
+package egressproxy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestValidateHost(t *testing.T) {
+	for _, host := range []string{"pypi.org", "*.s3.amazonaws.com", "example.com:8443", "10.0.0.1", "[::1]:443"} {
+		require.NoError(t, ValidateHost(host))
+	}
+	for _, host := range []string{"", "*", "https://pypi.org", "pypi.org/simple", "pypi.org,example.com", "*example.com", "a.*.com", "example.com:0", "example.com:http"} {
+		require.YesError(t, ValidateHost(host))
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	hosts := []string{"pypi.org", "*.s3.amazonaws.com", "example.com:8443"}
+	require.True(t, Allowed(hosts, "pypi.org:443"))
+	require.True(t, Allowed(hosts, "PyPI.org"))
+	require.True(t, Allowed(hosts, "bucket.s3.amazonaws.com:443"))
+	require.True(t, Allowed(hosts, "example.com:8443"))
+	require.False(t, Allowed(hosts, "files.pypi.org:443"))
+	require.False(t, Allowed(hosts, "s3.amazonaws.com:443"))
+	require.False(t, Allowed(hosts, "example.com:443"))
+	require.False(t, Allowed(nil, "pypi.org:443"))
+}
+
+func TestProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "hello")
+	}))
+	defer upstream.Close()
+	upstreamURL, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+
+	var violations []string
+	p := httptest.NewServer(newProxy([]string{upstreamURL.Host}, func(host string) {
+		violations = append(violations, host)
+	}))
+	defer p.Close()
+	proxyURL, err := url.Parse(p.URL)
+	require.NoError(t, err)
+	c := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	resp, err := c.Get(upstream.URL)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "hello", string(body))
+	require.Equal(t, 0, len(violations))
+
+	resp, err = c.Get("http://example.com/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	require.Equal(t, []string{"example.com:80"}, violations)
+}
+
+func TestProxyConnect(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "hello")
+	}))
+	defer upstream.Close()
+	upstreamURL, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+
+	var violations []string
+	p := httptest.NewServer(newProxy([]string{upstreamURL.Host}, func(host string) {
+		violations = append(violations, host)
+	}))
+	defer p.Close()
+	proxyURL, err := url.Parse(p.URL)
+	require.NoError(t, err)
+	transport := upstream.Client().Transport.(*http.Transport)
+	transport.Proxy = http.ProxyURL(proxyURL)
+	c := &http.Client{Transport: transport}
+
+	resp, err := c.Get(upstream.URL)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, "hello", string(body))
+
+	_, err = c.Get("https://example.com/")
+	require.YesError(t, err)
+	require.Equal(t, []string{"example.com:443"}, violations)
+}
@@ -0,0 +1,104 @@
+package pps
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errcode"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+
+	"google.golang.org/grpc/status"
+)
+
+// The resource types of the errors returned by PPS (see errcode.Error)
+const (
+	PipelineResource = "pipelines"
+	JobResource      = "jobs"
+)
+
+// The reasons of the errors returned by PPS, which distinguish errors with
+// the same code and resource type (see errcode.Error)
+const (
+	ReasonPipelineNotFound = "PIPELINE_NOT_FOUND"
+	ReasonPipelineExists   = "PIPELINE_EXISTS"
+	ReasonJobNotFound      = "JOB_NOT_FOUND"
+)
+
+// ErrPipelineNotFound represents a pipeline-not-found error.
+type ErrPipelineNotFound struct {
+	Pipeline *pps.Pipeline
+}
+
+// ErrPipelineExists represents a pipeline-exists error.
+type ErrPipelineExists struct {
+	Pipeline *pps.Pipeline
+}
+
+// ErrJobNotFound represents a job-not-found error.
+type ErrJobNotFound struct {
+	Job *pps.Job
+}
+
+func (e ErrPipelineNotFound) Error() string {
+	return fmt.Sprintf("pipeline %v not found", e.Pipeline.Name)
+}
+
+func (e ErrPipelineExists) Error() string {
+	return fmt.Sprintf("pipeline %v already exists", e.Pipeline.Name)
+}
+
+func (e ErrJobNotFound) Error() string {
+	return fmt.Sprintf("job %v not found", e.Job.ID)
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrPipelineNotFound) GRPCStatus() *status.Status {
+	return errcode.New(errcode.NotFound, e.Error()).WithResource(PipelineResource, e.Pipeline.Name).WithReason(ReasonPipelineNotFound).GRPCStatus()
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrPipelineExists) GRPCStatus() *status.Status {
+	return errcode.New(errcode.Conflict, e.Error()).WithResource(PipelineResource, e.Pipeline.Name).WithReason(ReasonPipelineExists).GRPCStatus()
+}
+
+// GRPCStatus returns the status that the error is sent to clients as
+func (e ErrJobNotFound) GRPCStatus() *status.Status {
+	return errcode.New(errcode.NotFound, e.Error()).WithResource(JobResource, e.Job.ID).WithReason(ReasonJobNotFound).GRPCStatus()
+}
+
+var (
+	pipelineNotFoundRe = regexp.MustCompile(`pipelines?/? "?[^ ]+"? not found`)
+	pipelineExistsRe   = regexp.MustCompile(`pipelines?/? [^ ]+ already exists`)
+	jobNotFoundRe      = regexp.MustCompile(`jobs?/? [^ ]+ not found`)
+)
+
+// isErr returns true if 'err' has 'reason'. Errors without a reason (e.g.
+// errors from older versions of pachd, or errors that were wrapped in another
+// error) are matched by their message instead.
+func isErr(err error, re *regexp.Regexp, reason string) bool {
+	if err == nil {
+		return false
+	}
+	if e := errcode.FromError(err); e.Reason != "" {
+		return e.Reason == reason
+	}
+	return re.MatchString(errcode.Message(err))
+}
+
+// IsPipelineNotFoundErr returns true if 'err' is an error about a pipeline not
+// being found
+func IsPipelineNotFoundErr(err error) bool {
+	return isErr(err, pipelineNotFoundRe, ReasonPipelineNotFound)
+}
+
+// IsPipelineExistsErr returns true if 'err' is an error about a pipeline
+// already existing
+func IsPipelineExistsErr(err error) bool {
+	return isErr(err, pipelineExistsRe, ReasonPipelineExists)
+}
+
+// IsJobNotFoundErr returns true if 'err' is an error about a job not being
+// found
+func IsJobNotFoundErr(err error) bool {
+	return isErr(err, jobNotFoundRe, ReasonJobNotFound)
+}
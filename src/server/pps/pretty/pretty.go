@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -16,14 +17,20 @@ import (
 	"github.com/pachyderm/pachyderm/src/client"
 	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
 	ppsclient "github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/pretty"
 )
 
 const (
 	// PipelineHeader is the header for pipelines.
 	PipelineHeader = "NAME\tVERSION\tINPUT\tCREATED\tSTATE / LAST JOB\tDESCRIPTION\t\n"
+	// PipelineWideHeader is the header for pipelines printed by
+	// PrintPipelineInfoWide.
+	PipelineWideHeader = "NAME\tVERSION\tINPUT\tCREATED\tSTATE / LAST JOB\tDESCRIPTION\tIMAGE\tREASON\t\n"
 	// JobHeader is the header for jobs
-	JobHeader = "ID\tPIPELINE\tSTARTED\tDURATION\tRESTART\tPROGRESS\tDL\tUL\tSTATE\t\n"
+	JobHeader = "ID\tPIPELINE\tSTARTED\tDURATION\tRESTART\tPROGRESS\tDL\tUL\tINPUT\tOUTPUT\tSTATE\t\n"
+	// JobWideHeader is the header for jobs printed by PrintJobInfoWide
+	JobWideHeader = "ID\tPIPELINE\tSTARTED\tDURATION\tRESTART\tPROGRESS\tDL\tUL\tINPUT\tOUTPUT\tSTATE\tOUTPUT COMMIT\tREASON\t\n"
 	// DatumHeader is the header for datums
 	DatumHeader = "ID\tSTATUS\tTIME\t\n"
 	// SecretHeader is the header for secrets
@@ -41,6 +48,31 @@ func safeTrim(s string, l int) string {
 
 // PrintJobInfo pretty-prints job info.
 func PrintJobInfo(w io.Writer, jobInfo *ppsclient.JobInfo, fullTimestamps bool) {
+	printJobColumns(w, jobInfo, fullTimestamps)
+	if jobInfo.State == ppsclient.JobState_JOB_FAILURE || jobInfo.State == ppsclient.JobState_JOB_PARTIAL_SUCCESS {
+		fmt.Fprintf(w, "%s: %s\t", JobState(jobInfo.State), safeTrim(jobInfo.Reason, jobReasonLen))
+	} else {
+		fmt.Fprintf(w, "%s\t", JobState(jobInfo.State))
+	}
+	fmt.Fprintln(w)
+}
+
+// PrintJobInfoWide pretty-prints job info with absolute timestamps, followed
+// by the job's output commit and the full reason for its state.
+func PrintJobInfoWide(w io.Writer, jobInfo *ppsclient.JobInfo) {
+	printJobColumns(w, jobInfo, true)
+	fmt.Fprintf(w, "%s\t", JobState(jobInfo.State))
+	if jobInfo.OutputCommit != nil {
+		fmt.Fprintf(w, "%s\t", jobInfo.OutputCommit.ID)
+	} else {
+		fmt.Fprintf(w, "-\t")
+	}
+	fmt.Fprintf(w, "%s\t", jobInfo.Reason)
+	fmt.Fprintln(w)
+}
+
+// printJobColumns prints the columns of a job that come before its state
+func printJobColumns(w io.Writer, jobInfo *ppsclient.JobInfo, fullTimestamps bool) {
 	fmt.Fprintf(w, "%s\t", jobInfo.Job.ID)
 	fmt.Fprintf(w, "%s\t", jobInfo.Pipeline.Name)
 	if fullTimestamps {
@@ -57,16 +89,31 @@ func PrintJobInfo(w io.Writer, jobInfo *ppsclient.JobInfo, fullTimestamps bool)
 	fmt.Fprintf(w, "%s\t", Progress(jobInfo))
 	fmt.Fprintf(w, "%s\t", pretty.Size(jobInfo.Stats.DownloadBytes))
 	fmt.Fprintf(w, "%s\t", pretty.Size(jobInfo.Stats.UploadBytes))
-	if jobInfo.State == ppsclient.JobState_JOB_FAILURE {
-		fmt.Fprintf(w, "%s: %s\t", JobState(jobInfo.State), safeTrim(jobInfo.Reason, jobReasonLen))
+	fmt.Fprintf(w, "%s\t", InputCommits(jobInfo.InputCommits))
+	fmt.Fprintf(w, "%s\t", OutputDiff(jobInfo.OutputDiff))
+}
+
+// PrintPipelineInfo pretty-prints pipeline info.
+func PrintPipelineInfo(w io.Writer, pipelineInfo *ppsclient.PipelineInfo, fullTimestamps bool) {
+	printPipelineColumns(w, pipelineInfo, fullTimestamps)
+	fmt.Fprintln(w)
+}
+
+// PrintPipelineInfoWide pretty-prints pipeline info with absolute timestamps,
+// followed by the pipeline's image and the reason for its state.
+func PrintPipelineInfoWide(w io.Writer, pipelineInfo *ppsclient.PipelineInfo) {
+	printPipelineColumns(w, pipelineInfo, true)
+	if pipelineInfo.Transform != nil && pipelineInfo.Transform.Image != "" {
+		fmt.Fprintf(w, "%s\t", pipelineInfo.Transform.Image)
 	} else {
-		fmt.Fprintf(w, "%s\t", JobState(jobInfo.State))
+		fmt.Fprintf(w, "-\t")
 	}
+	fmt.Fprintf(w, "%s\t", pipelineInfo.Reason)
 	fmt.Fprintln(w)
 }
 
-// PrintPipelineInfo pretty-prints pipeline info.
-func PrintPipelineInfo(w io.Writer, pipelineInfo *ppsclient.PipelineInfo, fullTimestamps bool) {
+// printPipelineColumns prints the columns of a pipeline in PipelineHeader
+func printPipelineColumns(w io.Writer, pipelineInfo *ppsclient.PipelineInfo, fullTimestamps bool) {
 	fmt.Fprintf(w, "%s\t", pipelineInfo.Pipeline.Name)
 	fmt.Fprintf(w, "%d\t", pipelineInfo.Version)
 	fmt.Fprintf(w, "%s\t", ShorthandInput(pipelineInfo.Input))
@@ -77,12 +124,11 @@ func PrintPipelineInfo(w io.Writer, pipelineInfo *ppsclient.PipelineInfo, fullTi
 	}
 	fmt.Fprintf(w, "%s / %s\t", pipelineState(pipelineInfo.State), JobState(pipelineInfo.LastJobState))
 	fmt.Fprintf(w, "%s\t", pipelineInfo.Description)
-	fmt.Fprintln(w)
 }
 
 // PrintWorkerStatusHeader pretty prints a worker status header.
 func PrintWorkerStatusHeader(w io.Writer) {
-	fmt.Fprint(w, "WORKER\tJOB\tDATUM\tSTARTED\tQUEUE\t\n")
+	fmt.Fprint(w, "WORKER\tJOB\tDATUM\tSTARTED\tQUEUE\tCREDITS\t\n")
 }
 
 // PrintWorkerStatus pretty prints a worker status.
@@ -99,6 +145,7 @@ func PrintWorkerStatus(w io.Writer, workerStatus *ppsclient.WorkerStatus, fullTi
 		fmt.Fprintf(w, "%s\t", pretty.Ago(workerStatus.Started))
 	}
 	fmt.Fprintf(w, "%d\t", workerStatus.QueueSize)
+	fmt.Fprintf(w, "%s\t", units.BytesSize(float64(workerStatus.Credits)))
 	fmt.Fprintln(w)
 }
 
@@ -120,8 +167,10 @@ func NewPrintableJobInfo(ji *ppsclient.JobInfo) *PrintableJobInfo {
 func PrintDetailedJobInfo(jobInfo *PrintableJobInfo) error {
 	template, err := template.New("JobInfo").Funcs(funcMap).Parse(
 		`ID: {{.Job.ID}} {{if .Pipeline}}
-Pipeline: {{.Pipeline.Name}} {{end}} {{if .ParentJob}}
-Parent: {{.ParentJob.ID}} {{end}}{{if .FullTimestamps}}
+Pipeline: {{.Pipeline.Name}} {{end}} {{if .Metadata.GetLabels}}
+Labels: {{labels .Metadata}} {{end}} {{if .ParentJob}}
+Parent: {{.ParentJob.ID}} {{end}}{{if .ReplayOf}}
+Replay Of: {{.ReplayOf.ID}} {{end}}{{if .FullTimestamps}}
 Started: {{.Started}}{{else}}
 Started: {{prettyAgo .Started}} {{end}}{{if .Finished}}
 Duration: {{prettyTimeDifference .Started .Finished}} {{end}}
@@ -137,9 +186,20 @@ Data Uploaded: {{prettySize .Stats.UploadBytes}}
 Download Time: {{prettyDuration .Stats.DownloadTime}}
 Process Time: {{prettyDuration .Stats.ProcessTime}}
 Upload Time: {{prettyDuration .Stats.UploadTime}}
-Datum Timeout: {{.DatumTimeout}}
+{{ if .Stats.DatumsChecked }}Nondeterministic: {{.Stats.DatumsNondeterministic}} of {{.Stats.DatumsChecked}} datums checked
+{{end}}Datum Timeout: {{.DatumTimeout}}
 Job Timeout: {{.JobTimeout}}
-Worker Status:
+{{ if .TimeoutPolicy }}Timeout Policy: {{.TimeoutPolicy}}
+{{end}}{{ if .DatumRetry }}Datum Retry: {{.DatumRetry}}
+{{end}}{{ if .DatumOrder }}Datum Order: {{.DatumOrder}}
+{{end}}{{ if .WorkerPool }}Worker Pool: {{.WorkerPool}}
+{{end}}{{ if .OutputDiff }}Output Diff: {{outputDiff .OutputDiff}}
+{{end}}{{ if .DatumBalance }}Datum Balance: {{datumBalance .DatumBalance}}
+{{end}}{{ if .StandbyWake }}Standby Wake:
+{{standbyWake .StandbyWake}}{{end}}{{ if .EstimatedCost }}Estimated Cost: {{.EstimatedCost}}
+{{end}}{{ if .Artifacts }}Artifacts:
+{{range .Artifacts}}  {{.Name}} ({{prettySize .SizeBytes}})
+{{end}}{{end}}Worker Status:
 {{workerStatus .}}Restarts: {{.Restart}}
 ParallelismSpec: {{.ParallelismSpec}}
 {{ if .ResourceRequests }}ResourceRequests:
@@ -150,16 +210,18 @@ ParallelismSpec: {{.ParallelismSpec}}
   Memory: {{ .ResourceLimits.Memory }}
   {{ if .ResourceLimits.Gpu }}GPU:
     Type: {{ .ResourceLimits.Gpu.Type }}
-    Number: {{ .ResourceLimits.Gpu.Number }} {{end}} {{end}}
+    {{ if .ResourceLimits.Gpu.Fraction }}Fraction: {{ .ResourceLimits.Gpu.Fraction }} ({{ .ResourceLimits.Gpu.SharesPerGpu }} shares per GPU){{ else }}Number: {{ .ResourceLimits.Gpu.Number }}{{ end }} {{end}} {{end}}
 {{ if .Service }}Service:
 	{{ if .Service.InternalPort }}InternalPort: {{ .Service.InternalPort }} {{end}}
 	{{ if .Service.ExternalPort }}ExternalPort: {{ .Service.ExternalPort }} {{end}} {{end}}Input:
-{{jobInput .}}
-Transform:
+{{jobInput .}}{{ if .InputConsistency }}Input Consistency: {{inputConsistency .InputConsistency}}
+{{end}}{{ if .InputMetadata }}Input Metadata:
+{{range .InputMetadata}}  {{.Commit.Repo.Name}}@{{.Commit.ID}}:{{range $key, $value := .Metadata}} {{$key}}={{$value}}{{end}}
+{{end}}{{end}}Transform:
 {{prettyTransform .Transform}} {{if .OutputCommit}}
 Output Commit: {{.OutputCommit.ID}} {{end}} {{ if .StatsCommit }}
 Stats Commit: {{.StatsCommit.ID}} {{end}} {{ if .Egress }}
-Egress: {{.Egress.URL}} {{end}}
+Egress: {{.Egress.URL}}{{ if .Egress.SQLDatabase }}{{.Egress.SQLDatabase.URL}}{{end}}{{ if .Egress.Kafka }}kafka topic {{.Egress.Kafka.Topic}}{{end}} {{end}}
 `)
 	if err != nil {
 		return err
@@ -189,13 +251,17 @@ func NewPrintablePipelineInfo(pi *ppsclient.PipelineInfo) *PrintablePipelineInfo
 func PrintDetailedPipelineInfo(pipelineInfo *PrintablePipelineInfo) error {
 	template, err := template.New("PipelineInfo").Funcs(funcMap).Parse(
 		`Name: {{.Pipeline.Name}}{{if .Description}}
-Description: {{.Description}}{{end}}{{if .FullTimestamps }}
+Description: {{.Description}}{{end}}{{if .Metadata.GetLabels}}
+Labels: {{labels .Metadata}}{{end}}{{if .FullTimestamps }}
 Created: {{.CreatedAt}}{{ else }}
 Created: {{prettyAgo .CreatedAt}} {{end}}
 State: {{pipelineState .State}}
 Stopped: {{ .Stopped }}
 Reason: {{.Reason}}
-Parallelism Spec: {{.ParallelismSpec}}
+{{ if .ReasonCode }}Reason Code: {{pipelineReasonCode .ReasonCode}}
+{{end}}{{ if .StateHistory }}State History:
+{{stateHistory .StateHistory .FullTimestamps}}{{end}}{{ if .StandbyWake }}Last Standby Wake:
+{{standbyWake .StandbyWake}}{{end}}Parallelism Spec: {{.ParallelismSpec}}
 {{ if .ResourceRequests }}ResourceRequests:
   CPU: {{ .ResourceRequests.Cpu }}
   Memory: {{ .ResourceRequests.Memory }} {{end}}
@@ -204,20 +270,36 @@ Parallelism Spec: {{.ParallelismSpec}}
   Memory: {{ .ResourceLimits.Memory }}
   {{ if .ResourceLimits.Gpu }}GPU:
     Type: {{ .ResourceLimits.Gpu.Type }} 
-    Number: {{ .ResourceLimits.Gpu.Number }} {{end}} {{end}}
+    {{ if .ResourceLimits.Gpu.Fraction }}Fraction: {{ .ResourceLimits.Gpu.Fraction }} ({{ .ResourceLimits.Gpu.SharesPerGpu }} shares per GPU){{ else }}Number: {{ .ResourceLimits.Gpu.Number }}{{ end }} {{end}} {{end}}
 Datum Timeout: {{.DatumTimeout}}
 Job Timeout: {{.JobTimeout}}
-Input:
+{{ if .TimeoutPolicy }}Timeout Policy: {{.TimeoutPolicy}}
+{{end}}{{ if .DatumRetry }}Datum Retry: {{.DatumRetry}}
+{{end}}{{ if .DatumOrder }}Datum Order: {{.DatumOrder}}
+{{end}}{{ if .MaxConcurrentJobs }}Max Concurrent Jobs: {{.MaxConcurrentJobs}}
+{{end}}{{ if .EmptyJobPolicy }}Empty Job Policy: {{.EmptyJobPolicy}}
+{{end}}{{ if .DatumCache }}Datum Cache: enabled
+{{end}}{{ if .DowntimeWindows }}Downtime Windows:
+{{downtimeWindows .DowntimeWindows}}{{end}}{{ if .Budget }}Budget: {{budget .Budget .BudgetSpend}}
+{{end}}{{ if .Service }}{{ if .Service.Autoscaling }}Autoscaling: {{serviceAutoscaling .Service.Autoscaling .ServiceAutoscaling}}
+{{end}}{{end}}{{ if .DeterminismCheck }}Determinism Check: {{.DeterminismCheck}}
+{{end}}{{ if .ExperimentTracking }}Experiment Tracking: {{ if .ExperimentTracking.MLflowURL }}mlflow {{.ExperimentTracking.MLflowURL}}{{ else }}{{.ExperimentTracking.URL}}{{ end }}
+{{end}}{{ if .CloudCredentials }}Cloud Credentials: {{cloudCredentials .PipelineInfo}}
+{{end}}Input:
 {{pipelineInput .PipelineInfo}}
 {{ if .GithookURL }}Githook URL: {{.GithookURL}} {{end}}
 Output Branch: {{.OutputBranch}}
 Transform:
-{{prettyTransform .Transform}}
-{{ if .Egress }}Egress: {{.Egress.URL}} {{end}}
+{{prettyTransform .Transform}}{{ if .ImageDigest }}
+Image Digest: {{.ImageDigest}}{{end}}
+{{ if .Egress }}Egress: {{.Egress.URL}}{{ if .Egress.SQLDatabase }}{{.Egress.SQLDatabase.URL}}{{end}}{{ if .Egress.Kafka }}kafka topic {{.Egress.Kafka.Topic}}{{end}} {{end}}
 {{if .RecentError}} Recent Error: {{.RecentError}} {{end}}
 Job Counts:
 {{jobCounts .JobCounts}}
-`)
+{{ if .EtcdPipelineInfo }}Spec Commit: {{.SpecCommit.ID}}
+Last Job State: {{jobState .LastJobState}}
+Parallelism: {{.EtcdPipelineInfo.Parallelism}}
+{{end}}`)
 	if err != nil {
 		return err
 	}
@@ -245,6 +327,15 @@ func PrintDetailedDatumInfo(w io.Writer, datumInfo *ppsclient.DatumInfo) {
 	fmt.Fprintf(w, "ID\t%s\n", datumInfo.Datum.ID)
 	fmt.Fprintf(w, "Job ID\t%s\n", datumInfo.Datum.Job.ID)
 	fmt.Fprintf(w, "State\t%s\n", datumInfo.State)
+	if datumInfo.Stats.Tries > 0 {
+		fmt.Fprintf(w, "Tries\t%d\n", datumInfo.Stats.Tries)
+	}
+	if datumInfo.Stats.FailureClass != ppsclient.FailureClass_FAILURE_UNCLASSIFIED {
+		fmt.Fprintf(w, "Failure Class\t%s\n", failureClass(datumInfo.Stats.FailureClass))
+	}
+	if datumInfo.Stats.DatumsChecked > 0 {
+		fmt.Fprintf(w, "Deterministic\t%t\n", datumInfo.Stats.DatumsNondeterministic == 0)
+	}
 	fmt.Fprintf(w, "Data Downloaded\t%s\n", pretty.Size(datumInfo.Stats.DownloadBytes))
 	fmt.Fprintf(w, "Data Uploaded\t%s\n", pretty.Size(datumInfo.Stats.UploadBytes))
 
@@ -292,6 +383,16 @@ func PrintDetailedDatumInfo(w io.Writer, datumInfo *ppsclient.DatumInfo) {
 	tw.Flush()
 }
 
+func failureClass(class ppsclient.FailureClass) string {
+	switch class {
+	case ppsclient.FailureClass_FAILURE_TRANSIENT:
+		return "transient"
+	case ppsclient.FailureClass_FAILURE_PERMANENT:
+		return "permanent"
+	}
+	return "-"
+}
+
 // PrintSecretInfo pretty-prints secret info.
 func PrintSecretInfo(w io.Writer, secretInfo *ppsclient.SecretInfo) {
 	fmt.Fprintf(w, "%s\t%s\t%s\t\n", secretInfo.Secret.Name, secretInfo.Type, pretty.Ago(secretInfo.CreationTimestamp))
@@ -336,10 +437,191 @@ func JobState(jobState ppsclient.JobState) string {
 		return color.New(color.FgGreen).SprintFunc()("success")
 	case ppsclient.JobState_JOB_KILLED:
 		return color.New(color.FgRed).SprintFunc()("killed")
+	case ppsclient.JobState_JOB_PARTIAL_SUCCESS:
+		return color.New(color.FgMagenta).SprintFunc()("partial success")
+	case ppsclient.JobState_JOB_SKIPPED:
+		return color.New(color.FgCyan).SprintFunc()("skipped")
+	}
+	return "-"
+}
+
+// PrintStuckBranch pretty-prints a stuck branch, along with what's blocking
+// it and how to unblock it.
+func PrintStuckBranch(w io.Writer, sb *ppsclient.StuckBranch, fullTimestamps bool) {
+	started := pretty.Ago(sb.Started)
+	if fullTimestamps {
+		started = sb.Started.String()
+	}
+	fmt.Fprintf(w, "%s@%s is stuck: its head %s was started %s\n",
+		sb.Branch.Repo.Name, sb.Branch.Name, sb.Head.ID, started)
+	for _, blocker := range sb.Blockers {
+		fmt.Fprintf(w, "  blocked by %s@%s", blocker.Commit.Repo.Name, blocker.Commit.ID)
+		if blocker.Branch != nil {
+			fmt.Fprintf(w, " (%s)", blocker.Branch.Name)
+		}
+		if blocker.Pipeline != nil {
+			fmt.Fprintf(w, ", written by pipeline %s [%s]", blocker.Pipeline.Name, pipelineState(blocker.PipelineState))
+		}
+		if blocker.Job != nil {
+			fmt.Fprintf(w, ", job %s [%s]", blocker.Job.ID, JobState(blocker.JobState))
+		}
+		fmt.Fprintf(w, "\n    %s\n", blocker.Reason)
+		if len(blocker.Remediations) > 0 {
+			fmt.Fprintf(w, "    try:\n")
+			for _, remediation := range blocker.Remediations {
+				fmt.Fprintf(w, "      %s\n", remediation)
+			}
+		}
+	}
+}
+
+// PrintReplay pretty-prints the result of replaying the job 'jobID': the
+// replay's job and output commit, and the files whose content differs from
+// the job's output.
+func PrintReplay(w io.Writer, jobID string, r *ppsclient.ReplayJobResponse) {
+	fmt.Fprintf(w, "replay of job %s: job %s [%s], output commit %s\n",
+		jobID, r.Job.ID, JobState(r.State), r.OutputCommit.ID)
+	switch {
+	case r.State != ppsclient.JobState_JOB_SUCCESS:
+		fmt.Fprintf(w, "  the replay didn't succeed: %s\n", r.Reason)
+	case r.Reproducible:
+		fmt.Fprintf(w, "  the replay's output is identical to the job's\n")
+	default:
+		fmt.Fprintf(w, "  %d files differ from the job's output:\n", len(r.Diffs))
+		for _, d := range r.Diffs {
+			switch {
+			case len(d.OriginalHash) == 0:
+				fmt.Fprintf(w, "    added     %s (%s)\n", d.Path, pretty.Size(d.ReplaySizeBytes))
+			case len(d.ReplayHash) == 0:
+				fmt.Fprintf(w, "    removed   %s (%s)\n", d.Path, pretty.Size(d.OriginalSizeBytes))
+			default:
+				fmt.Fprintf(w, "    modified  %s (%s -> %s)\n", d.Path, pretty.Size(d.OriginalSizeBytes), pretty.Size(d.ReplaySizeBytes))
+			}
+		}
+	}
+}
+
+// PrintImpact pretty-prints everything downstream of a commit, and how to
+// invalidate it
+func PrintImpact(w io.Writer, impact *ppsclient.Impact) {
+	fmt.Fprintf(w, "impact of %s@%s:\n", impact.Commit.Repo.Name, impact.Commit.ID)
+	if len(impact.Commits) == 0 {
+		fmt.Fprintf(w, "  no downstream commits\n")
+	} else {
+		fmt.Fprintf(w, "  %d downstream commits:\n", len(impact.Commits))
+	}
+	for _, ic := range impact.Commits {
+		fmt.Fprintf(w, "    %s@%s", ic.Commit.Repo.Name, ic.Commit.ID)
+		if ic.Branch != nil {
+			fmt.Fprintf(w, " (%s)", ic.Branch.Name)
+		}
+		if !ic.Finished {
+			fmt.Fprintf(w, " [unfinished]")
+		}
+		if ic.Pipeline != nil {
+			fmt.Fprintf(w, ", written by pipeline %s", ic.Pipeline.Name)
+		}
+		if ic.Job != nil {
+			fmt.Fprintf(w, ", job %s [%s]", ic.Job.ID, JobState(ic.JobState))
+		}
+		fmt.Fprintf(w, "\n")
+	}
+	if len(impact.Branches) > 0 {
+		var branches []string
+		for _, branch := range impact.Branches {
+			branches = append(branches, branch.Repo.Name+"@"+branch.Name)
+		}
+		fmt.Fprintf(w, "  branches: %s\n", strings.Join(branches, ", "))
+	}
+	if len(impact.Egresses) > 0 {
+		fmt.Fprintf(w, "  egressed to:\n")
+		for _, egress := range impact.Egresses {
+			fmt.Fprintf(w, "    %s, by job %s of pipeline %s (%s@%s)\n", egress.Destination,
+				egress.Job.ID, egress.Pipeline.Name, egress.Commit.Repo.Name, egress.Commit.ID)
+		}
+	}
+	if len(impact.Remediations) > 0 {
+		fmt.Fprintf(w, "  invalidate with:\n")
+		for _, remediation := range impact.Remediations {
+			fmt.Fprintf(w, "    %s\n", remediation)
+		}
+	}
+}
+
+// PrintGarbageCollectInfo pretty-prints the progress of the running garbage
+// collection, or the result of the last one
+func PrintGarbageCollectInfo(w io.Writer, info *ppsclient.GarbageCollectInfo, fullTimestamps bool) {
+	if info.State == ppsclient.GarbageCollectState_GC_NOT_RUN {
+		fmt.Fprintf(w, "garbage collection has never been run\n")
+		return
+	}
+	timestamp := func(ts *types.Timestamp) string {
+		if fullTimestamps {
+			return ts.String()
+		}
+		return pretty.Ago(ts)
+	}
+	fmt.Fprintf(w, "State: %s\n", gcState(info.State))
+	if info.Reason != "" {
+		fmt.Fprintf(w, "Reason: %s\n", info.Reason)
+	}
+	fmt.Fprintf(w, "Started: %s\n", timestamp(info.Started))
+	if info.Finished != nil {
+		fmt.Fprintf(w, "Finished: %s\n", timestamp(info.Finished))
+	}
+	if info.ObjectsEstimated > 0 {
+		fmt.Fprintf(w, "Objects Scanned: %d (of about %d)\n", info.ObjectsScanned, info.ObjectsEstimated)
+	} else {
+		fmt.Fprintf(w, "Objects Scanned: %d\n", info.ObjectsScanned)
+	}
+	fmt.Fprintf(w, "Objects Deleted: %d\n", info.ObjectsDeleted)
+	fmt.Fprintf(w, "Objects Spared: %d (written recently)\n", info.ObjectsSpared)
+	fmt.Fprintf(w, "Tags Deleted: %d\n", info.TagsDeleted)
+	fmt.Fprintf(w, "Bytes Reclaimed: %s\n", pretty.Size(info.BytesReclaimed))
+	if info.Remaining != nil {
+		fmt.Fprintf(w, "Estimated Time Remaining: %s\n", pretty.Duration(info.Remaining))
+	}
+}
+
+func gcState(state ppsclient.GarbageCollectState) string {
+	switch state {
+	case ppsclient.GarbageCollectState_GC_MARKING:
+		return color.New(color.FgYellow).SprintFunc()("marking")
+	case ppsclient.GarbageCollectState_GC_SWEEPING:
+		return color.New(color.FgYellow).SprintFunc()("sweeping")
+	case ppsclient.GarbageCollectState_GC_SUCCESS:
+		return color.New(color.FgGreen).SprintFunc()("success")
+	case ppsclient.GarbageCollectState_GC_FAILURE:
+		return color.New(color.FgRed).SprintFunc()("failure")
 	}
 	return "-"
 }
 
+// PrintFinding pretty-prints a problem found by Diagnose, along with how to
+// fix it.
+func PrintFinding(w io.Writer, finding *ppsclient.Finding) {
+	fmt.Fprintf(w, "[%s] %s\n", findingSeverity(finding.Severity), finding.Summary)
+	for _, detail := range finding.Details {
+		fmt.Fprintf(w, "    %s\n", detail)
+	}
+	if len(finding.Remediations) > 0 {
+		fmt.Fprintf(w, "    try:\n")
+		for _, remediation := range finding.Remediations {
+			fmt.Fprintf(w, "      %s\n", remediation)
+		}
+	}
+}
+
+func findingSeverity(severity ppsclient.FindingSeverity) string {
+	switch severity {
+	case ppsclient.FindingSeverity_FINDING_CRITICAL:
+		return color.New(color.FgRed).SprintFunc()("critical")
+	case ppsclient.FindingSeverity_FINDING_WARNING:
+		return color.New(color.FgYellow).SprintFunc()("warning")
+	}
+	return "info"
+}
+
 // Progress pretty prints the datum progress of a job.
 func Progress(ji *ppsclient.JobInfo) string {
 	if ji.DataRecovered != 0 {
@@ -348,6 +630,62 @@ func Progress(ji *ppsclient.JobInfo) string {
 	return fmt.Sprintf("%d + %d / %d", ji.DataProcessed, ji.DataSkipped, ji.DataTotal)
 }
 
+// InputCommits pretty prints a job's input commits as a comma-separated list
+// of repo@commit, e.g. "images@2b9c...,model@8f1e...". It returns "-" if the
+// job has no input commits.
+func InputCommits(commits []*pfsclient.Commit) string {
+	if len(commits) == 0 {
+		return "-"
+	}
+	var result []string
+	for _, commit := range commits {
+		result = append(result, fmt.Sprintf("%s@%s", commit.Repo.Name, commit.ID))
+	}
+	return strings.Join(result, ",")
+}
+
+// OutputDiff pretty prints a job's output diff as the number of files added,
+// changed and deleted, and the change in size, e.g. "+2 ~1 -0 (+1.5KiB)". It
+// returns "-" if the job's output commit hasn't been diffed.
+func OutputDiff(diff *ppsclient.OutputDiff) string {
+	if diff == nil {
+		return "-"
+	}
+	sign, delta := "+", diff.SizeDelta
+	if delta < 0 {
+		sign, delta = "-", -delta
+	}
+	return fmt.Sprintf("+%d ~%d -%d (%s%s)", diff.FilesAdded, diff.FilesChanged, diff.FilesDeleted, sign, pretty.Size(uint64(delta)))
+}
+
+// ProgressBar pretty prints a job's progress as a single line, with a bar
+// of the given width.
+func ProgressBar(p *ppsclient.JobProgress, width int) string {
+	done := p.DataProcessed + p.DataSkipped + p.DataFailed + p.DataRecovered
+	filled := 0
+	if p.DataTotal > 0 {
+		filled = int(int64(width) * done / p.DataTotal)
+	}
+	if filled > width {
+		filled = width
+	}
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", width-filled)
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %d / %d", bar, done, p.DataTotal)
+	if p.DataFailed != 0 {
+		fmt.Fprintf(&b, " (%d failed)", p.DataFailed)
+	}
+	fmt.Fprintf(&b, "  %.2f datums/s", p.Throughput)
+	if p.ETA != nil {
+		eta, err := types.DurationFromProto(p.ETA)
+		if err == nil {
+			fmt.Fprintf(&b, "  ETA %s", units.HumanDuration(eta))
+		}
+	}
+	fmt.Fprintf(&b, "  %s", JobState(p.State))
+	return b.String()
+}
+
 func pipelineState(pipelineState ppsclient.PipelineState) string {
 	switch pipelineState {
 	case ppsclient.PipelineState_PIPELINE_STARTING:
@@ -366,6 +704,14 @@ func pipelineState(pipelineState ppsclient.PipelineState) string {
 	return "-"
 }
 
+func pipelineReasonCode(code ppsclient.PipelineReasonCode) string {
+	switch code {
+	case ppsclient.PipelineReasonCode_PIPELINE_REASON_VERSION_SKEW:
+		return "version skew"
+	}
+	return "-"
+}
+
 func jobInput(jobInfo PrintableJobInfo) string {
 	if jobInfo.Input == nil {
 		return ""
@@ -377,6 +723,25 @@ func jobInput(jobInfo PrintableJobInfo) string {
 	return string(input) + "\n"
 }
 
+func inputConsistency(consistency *ppsclient.InputConsistency) string {
+	if consistency.Consistent {
+		return "consistent"
+	}
+	var buffer bytes.Buffer
+	buffer.WriteString("inconsistent")
+	for _, conflict := range consistency.Conflicts {
+		var commits []string
+		for _, commit := range conflict.Commits {
+			commits = append(commits, commit.ID)
+		}
+		fmt.Fprintf(&buffer, "\n  %s@%s resolves to commits %s", conflict.Branch.Repo.Name, conflict.Branch.Name, strings.Join(commits, ", "))
+	}
+	for _, name := range consistency.Unbound {
+		fmt.Fprintf(&buffer, "\n  input %q has no commit", name)
+	}
+	return buffer.String()
+}
+
 func workerStatus(jobInfo PrintableJobInfo) string {
 	var buffer bytes.Buffer
 	writer := ansiterm.NewTabWriter(&buffer, 20, 1, 3, ' ', 0)
@@ -405,9 +770,138 @@ func jobCounts(counts map[int32]int32) string {
 	for i := int32(ppsclient.JobState_JOB_STARTING); i <= int32(ppsclient.JobState_JOB_SUCCESS); i++ {
 		fmt.Fprintf(&buffer, "%s: %d\t", JobState(ppsclient.JobState(i)), counts[i])
 	}
+	if partial := counts[int32(ppsclient.JobState_JOB_PARTIAL_SUCCESS)]; partial > 0 {
+		fmt.Fprintf(&buffer, "%s: %d\t", JobState(ppsclient.JobState_JOB_PARTIAL_SUCCESS), partial)
+	}
+	if skipped := counts[int32(ppsclient.JobState_JOB_SKIPPED)]; skipped > 0 {
+		fmt.Fprintf(&buffer, "%s: %d\t", JobState(ppsclient.JobState_JOB_SKIPPED), skipped)
+	}
 	return buffer.String()
 }
 
+// stateHistory renders a pipeline's state transitions, one per line, with
+// their times in full if 'fullTimestamps' is set
+func stateHistory(history []*ppsclient.PipelineStateTransition, fullTimestamps bool) string {
+	var buffer bytes.Buffer
+	for _, transition := range history {
+		when := pretty.Ago(transition.Time)
+		if fullTimestamps {
+			when = transition.Time.String()
+		}
+		fmt.Fprintf(&buffer, "  %s: %s -> %s", when, pipelineState(transition.PreviousState), pipelineState(transition.State))
+		if transition.Reason != "" {
+			fmt.Fprintf(&buffer, " (%s)", transition.Reason)
+		}
+		buffer.WriteString("\n")
+	}
+	return buffer.String()
+}
+
+// standbyWake renders the total time and per-phase times of a pipeline's
+// wake from standby, one per line
+func standbyWake(wake *ppsclient.StandbyWake) string {
+	var buffer bytes.Buffer
+	if wake.FirstDatumStarted == nil {
+		buffer.WriteString("  in progress\n")
+	} else {
+		fmt.Fprintf(&buffer, "  total: %v", ppsclient.StandbyWakeLatency(wake))
+		if wake.AlarmExceeded {
+			alarm, _ := types.DurationFromProto(wake.Alarm)
+			fmt.Fprintf(&buffer, " (%s)", color.New(color.FgRed).Sprintf("more than standby_wake_alarm of %v", alarm))
+		}
+		buffer.WriteString("\n")
+	}
+	for _, phase := range ppsclient.StandbyWakePhases(wake) {
+		fmt.Fprintf(&buffer, "  %s\n", phase)
+	}
+	return buffer.String()
+}
+
+// datumBalance renders how evenly a job's datums were spread across its
+// workers, e.g. "4 workers, busiest 2 minutes (mean 90 seconds), tail 40
+// seconds"
+func datumBalance(balance *ppsclient.DatumBalance) string {
+	return fmt.Sprintf("%d workers, busiest %s (mean %s), tail %s", balance.Workers,
+		pretty.Duration(balance.WorkerTimeMax), pretty.Duration(balance.WorkerTimeMean), pretty.Duration(balance.Tail))
+}
+
+// downtimeWindows renders a pipeline's downtime windows, one per line
+func downtimeWindows(windows []*ppsclient.DowntimeWindow) string {
+	var buffer bytes.Buffer
+	for _, window := range windows {
+		duration, _ := types.DurationFromProto(window.Duration)
+		fmt.Fprintf(&buffer, "  %s for %v\n", window.Start, duration)
+	}
+	return buffer.String()
+}
+
+// budget renders a pipeline's budget and its spend this month
+func budget(budget *ppsclient.Budget, spend *ppsclient.BudgetSpend) string {
+	result := fmt.Sprintf("%v of %v spent this month (%v per worker-hour)",
+		spend.GetSpend(), budget.MonthlyLimit, budget.WorkerHourCost)
+	if spend.GetExceeded() {
+		result += ", exceeded: not starting new jobs until next month"
+	}
+	return result
+}
+
+// serviceAutoscaling renders a service's autoscaling range and its most
+// recent scaling decision
+func serviceAutoscaling(autoscaling *ppsclient.ServiceAutoscaling, status *ppsclient.ServiceAutoscalingStatus) string {
+	min := autoscaling.MinReplicas
+	if min == 0 {
+		min = 1
+	}
+	result := fmt.Sprintf("%d to %d workers", min, autoscaling.MaxReplicas)
+	if status == nil {
+		return result
+	}
+	result += fmt.Sprintf(", scaled to %d", status.Replicas)
+	if autoscaling.Prometheus != nil {
+		result += fmt.Sprintf(" (query result %v)", status.TriggerValue)
+	} else {
+		var measured []string
+		if autoscaling.TargetRequestsPerSecond > 0 {
+			measured = append(measured, fmt.Sprintf("%.1f requests/s", status.RequestsPerSecond))
+		}
+		if latency, err := types.DurationFromProto(status.Latency); status.Latency != nil && err == nil {
+			measured = append(measured, fmt.Sprintf("%v latency", latency))
+		}
+		if len(measured) > 0 {
+			result += " (" + strings.Join(measured, ", ") + ")"
+		}
+	}
+	if status.Updated != nil {
+		result += " " + pretty.Ago(status.Updated)
+	}
+	return result
+}
+
+// cloudCredentials renders the cloud identities of a pipeline's user code,
+// and the kubernetes service account that their IAM policies must trust
+func cloudCredentials(pipelineInfo *ppsclient.PipelineInfo) string {
+	var identities []string
+	if aws := pipelineInfo.CloudCredentials.AWS; aws != nil {
+		identities = append(identities, "aws role "+aws.RoleARN)
+	}
+	if gcp := pipelineInfo.CloudCredentials.GCP; gcp != nil {
+		identities = append(identities, "gcp service account "+gcp.ServiceAccount)
+	}
+	return fmt.Sprintf("%s (as service account %s)", strings.Join(identities, ", "),
+		ppsutil.PipelineResourceName(pipelineInfo.Pipeline.Name))
+}
+
+// labels renders the labels in 'metadata' as a kubernetes label selector would
+// match them, e.g. "env=prod,team=nlp"
+func labels(metadata *ppsclient.Metadata) string {
+	var result []string
+	for key, value := range metadata.GetLabels() {
+		result = append(result, key+"="+value)
+	}
+	sort.Strings(result)
+	return strings.Join(result, ",")
+}
+
 func prettyTransform(transform *ppsclient.Transform) (string, error) {
 	result, err := json.MarshalIndent(transform, "", "  ")
 	if err != nil {
@@ -435,6 +929,12 @@ func ShorthandInput(input *ppsclient.Input) string {
 			subInput = append(subInput, ShorthandInput(input))
 		}
 		return "(" + strings.Join(subInput, " ⋈ ") + ")"
+	case input.Group != nil:
+		var subInput []string
+		for _, input := range input.Group {
+			subInput = append(subInput, ShorthandInput(input))
+		}
+		return "group(" + strings.Join(subInput, ", ") + ")"
 	case input.Union != nil:
 		var subInput []string
 		for _, input := range input.Union {
@@ -449,15 +949,26 @@ func ShorthandInput(input *ppsclient.Input) string {
 
 var funcMap = template.FuncMap{
 	"pipelineState":        pipelineState,
+	"pipelineReasonCode":   pipelineReasonCode,
 	"jobState":             JobState,
 	"datumState":           datumState,
 	"workerStatus":         workerStatus,
 	"pipelineInput":        pipelineInput,
 	"jobInput":             jobInput,
+	"inputConsistency":     inputConsistency,
 	"prettyAgo":            pretty.Ago,
 	"prettyTimeDifference": pretty.TimeDifference,
 	"prettyDuration":       pretty.Duration,
 	"prettySize":           pretty.Size,
 	"jobCounts":            jobCounts,
 	"prettyTransform":      prettyTransform,
+	"labels":               labels,
+	"stateHistory":         stateHistory,
+	"standbyWake":          standbyWake,
+	"downtimeWindows":      downtimeWindows,
+	"budget":               budget,
+	"serviceAutoscaling":   serviceAutoscaling,
+	"cloudCredentials":     cloudCredentials,
+	"outputDiff":           OutputDiff,
+	"datumBalance":         datumBalance,
 }
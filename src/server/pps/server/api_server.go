@@ -14,28 +14,33 @@ import (
 	"strings"
 	"sync"
 	"time"
-	"unicode"
 
 	"github.com/golang/protobuf/ptypes"
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/auth"
 	"github.com/pachyderm/pachyderm/src/client/limit"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errcode"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
 	"github.com/pachyderm/pachyderm/src/client/pkg/tracing"
 	"github.com/pachyderm/pachyderm/src/client/pkg/tracing/extended"
 	"github.com/pachyderm/pachyderm/src/client/pps"
 	pfsServer "github.com/pachyderm/pachyderm/src/server/pfs"
+	ppsServer "github.com/pachyderm/pachyderm/src/server/pps"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ancestry"
 	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
 	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
 	"github.com/pachyderm/pachyderm/src/server/pkg/log"
 	"github.com/pachyderm/pachyderm/src/server/pkg/metrics"
+	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsdb"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/serviceenv"
+	"github.com/pachyderm/pachyderm/src/server/pkg/sqlegress"
+	"github.com/pachyderm/pachyderm/src/server/pkg/tracking"
 	txnenv "github.com/pachyderm/pachyderm/src/server/pkg/transactionenv"
 	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
 	"github.com/pachyderm/pachyderm/src/server/pkg/watch"
@@ -50,6 +55,7 @@ import (
 	"golang.org/x/net/context"
 
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/status"
 
 	opentracing "github.com/opentracing/opentracing-go"
 	v1 "k8s.io/api/core/v1"
@@ -72,11 +78,21 @@ var (
 )
 
 func newErrPipelineNotFound(pipeline string) error {
-	return fmt.Errorf("pipeline %v not found", pipeline)
+	return ppsServer.ErrPipelineNotFound{Pipeline: client.NewPipeline(pipeline)}
 }
 
 func newErrPipelineExists(pipeline string) error {
-	return fmt.Errorf("pipeline %v already exists", pipeline)
+	return ppsServer.ErrPipelineExists{Pipeline: client.NewPipeline(pipeline)}
+}
+
+// validationErr marks 'err', which was returned while validating a request,
+// as a Validation error, unless it came from another API (and so already has
+// a code)
+func validationErr(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return errcode.Wrap(errcode.Validation, err)
 }
 
 func newErrPipelineUpdate(pipeline string, err error) error {
@@ -104,6 +120,8 @@ type apiServer struct {
 	iamRole               string
 	imagePullSecret       string
 	noExposeDockerSocket  bool
+	pipelineDefaults      *pps.CreatePipelineRequest
+	imagePinning          pps.ImagePinning
 	reporter              *metrics.Reporter
 	monitorCancelsMu      sync.Mutex
 	monitorCancels        map[string]func()
@@ -112,6 +130,26 @@ type apiServer struct {
 	port                  uint16
 	httpPort              uint16
 	peerPort              uint16
+	artifactCachePort     uint16 // 0 if the artifact cache is disabled
+	// workerNetworkPolicy is true if pachd creates a network policy for
+	// every pipeline, rather than only for pipelines that set one
+	workerNetworkPolicy bool
+	// networkPolicyAllow are the CIDRs that every pipeline's network policy
+	// allows workers to reach
+	networkPolicyAllow []string
+	// orphanGCDryRun is true if the PPS master only logs the worker resources
+	// of pipelines that no longer exist, rather than deleting them
+	orphanGCDryRun bool
+	// pipelineCRD is true if the PPS master creates, updates and deletes
+	// pipelines to match the Pipeline resources in its namespace
+	pipelineCRD bool
+	// workerRPCCacheSize is the size in bytes of each worker's cache of pachd
+	// responses, or 0 if workers don't cache them
+	workerRPCCacheSize int64
+	// workerBlockSharingCacheSize is the size in bytes of each worker
+	// sidecar's cache of the blocks that it shares with the other workers on
+	// its node, or 0 if workers don't share blocks
+	workerBlockSharingCacheSize int64
 	// collections
 	pipelines col.Collection
 	jobs      col.Collection
@@ -162,6 +200,12 @@ func validateNames(names map[string]bool, input *pps.Input) error {
 				return err
 			}
 		}
+	case input.Group != nil:
+		for _, input := range input.Group {
+			if err := validateNames(names, input); err != nil {
+				return err
+			}
+		}
 	case input.Git != nil:
 		if names[input.Git.Name] {
 			return fmt.Errorf(`name "%s" was used more than once`, input.Git.Name)
@@ -194,6 +238,12 @@ func (a *apiServer) validateInput(pachClient *client.APIClient, pipelineName str
 				case len(input.Pfs.Glob) == 0:
 					return fmt.Errorf("input must specify a glob")
 				}
+				if err := validateGlob(input.Pfs); err != nil {
+					return err
+				}
+				if err := validateS3Input(input.Pfs); err != nil {
+					return err
+				}
 				// Note that input.Pfs.Commit is empty if a) this is a job b) one of
 				// the job pipeline's input branches has no commits yet
 				if job && input.Pfs.Commit != "" {
@@ -220,6 +270,12 @@ func (a *apiServer) validateInput(pachClient *client.APIClient, pipelineName str
 				}
 				set = true
 			}
+			if input.Group != nil {
+				if set {
+					return fmt.Errorf("multiple input types set")
+				}
+				set = true
+			}
 			if input.Union != nil {
 				if set {
 					return fmt.Errorf("multiple input types set")
@@ -508,7 +564,27 @@ func (a *apiServer) CreateJob(ctx context.Context, request *pps.CreateJobRequest
 	if request.Stats == nil {
 		request.Stats = &pps.ProcessStats{}
 	}
+	var inputMetadata []*pps.CommitMetadata
+	var replayOf *pps.Job
+	if request.OutputCommit != nil {
+		outputCommitInfo, err := pachClient.InspectCommit(request.OutputCommit.Repo.Name, request.OutputCommit.ID)
+		if err != nil {
+			return nil, err
+		}
+		inputMetadata, err = commitMetadata(pachClient, outputCommitInfo)
+		if err != nil {
+			return nil, err
+		}
+		if jobID, ok := outputCommitInfo.Metadata[ppsconsts.ReplayMetadataKey]; ok {
+			replayOf = client.NewJob(jobID)
+		}
+	}
 	_, err = col.NewSTM(ctx, a.env.GetEtcdClient(), func(stm col.STM) error {
+		// Jobs inherit their pipeline's labels
+		pipelinePtr := &pps.EtcdPipelineInfo{}
+		if err := a.pipelines.ReadWrite(stm).Get(request.Pipeline.Name, pipelinePtr); err != nil {
+			return err
+		}
 		jobPtr := &pps.EtcdJobInfo{
 			Job:           job,
 			OutputCommit:  request.OutputCommit,
@@ -523,6 +599,10 @@ func (a *apiServer) CreateJob(ctx context.Context, request *pps.CreateJobRequest
 			StatsCommit:   request.StatsCommit,
 			Started:       request.Started,
 			Finished:      request.Finished,
+			Labels:        pipelinePtr.Labels,
+			InputMetadata: inputMetadata,
+			SchemaVersion: ppsdb.SchemaVersion,
+			ReplayOf:      replayOf,
 		}
 		return ppsutil.UpdateJobState(a.pipelines.ReadWrite(stm), a.jobs.ReadWrite(stm), jobPtr, request.State, request.Reason)
 	})
@@ -532,6 +612,36 @@ func (a *apiServer) CreateJob(ctx context.Context, request *pps.CreateJobRequest
 	return job, nil
 }
 
+// commitMetadata returns the metadata of the commits in the provenance of
+// the output commit 'outputCommitInfo' that have any. Commits that the caller
+// can't read are skipped, as a pipeline can't necessarily read all of its
+// upstream repos.
+func commitMetadata(pachClient *client.APIClient, outputCommitInfo *pfs.CommitInfo) ([]*pps.CommitMetadata, error) {
+	var result []*pps.CommitMetadata
+	seen := make(map[string]bool)
+	for _, prov := range outputCommitInfo.Provenance {
+		key := path.Join(prov.Commit.Repo.Name, prov.Commit.ID)
+		if prov.Commit.Repo.Name == ppsconsts.SpecRepo || seen[key] {
+			continue
+		}
+		seen[key] = true
+		commitInfo, err := pachClient.InspectCommit(prov.Commit.Repo.Name, prov.Commit.ID)
+		if err != nil {
+			if auth.IsErrNotAuthorized(err) {
+				continue
+			}
+			return nil, err
+		}
+		if len(commitInfo.Metadata) > 0 {
+			result = append(result, &pps.CommitMetadata{
+				Commit:   commitInfo.Commit,
+				Metadata: commitInfo.Metadata,
+			})
+		}
+	}
+	return result, nil
+}
+
 // InspectJob implements the protobuf pps.InspectJob RPC
 func (a *apiServer) InspectJob(ctx context.Context, request *pps.InspectJobRequest) (response *pps.JobInfo, retErr error) {
 	func() { a.Log(request, nil, nil, 0) }()
@@ -554,17 +664,19 @@ func (a *apiServer) InspectJob(ctx context.Context, request *pps.InspectJobReque
 		if err != nil {
 			return nil, err
 		}
-		if err := a.listJob(pachClient, nil, ci.Commit, nil, -1, false, func(ji *pps.JobInfo) error {
+		// Only the job is read from the index here, as it's inspected below
+		jobPtr := &pps.EtcdJobInfo{}
+		if err := jobs.GetByIndex(ppsdb.JobsOutputIndex, ci.Commit, jobPtr, col.DefaultOptions, func(string) error {
 			if request.Job != nil {
 				return fmt.Errorf("internal error, more than 1 Job has output commit: %v (this is likely a bug)", request.OutputCommit)
 			}
-			request.Job = ji.Job
+			request.Job = jobPtr.Job
 			return nil
 		}); err != nil {
 			return nil, err
 		}
 		if request.Job == nil {
-			return nil, fmt.Errorf("job with output commit %s not found", request.OutputCommit.ID)
+			return nil, errcode.Errorf(errcode.NotFound, "job with output commit %s not found", request.OutputCommit.ID).WithResource(ppsServer.JobResource, "").WithReason(ppsServer.ReasonJobNotFound)
 		}
 	}
 
@@ -592,7 +704,7 @@ func (a *apiServer) InspectJob(ctx context.Context, request *pps.InspectJobReque
 					return nil, err
 				}
 				if ppsutil.IsTerminal(jobPtr.State) {
-					return a.jobInfoFromPtr(pachClient, jobPtr, true)
+					return a.jobInfoFromPtr(pachClient, jobPtr, true, nil)
 				}
 			}
 		}
@@ -602,7 +714,7 @@ func (a *apiServer) InspectJob(ctx context.Context, request *pps.InspectJobReque
 	if err := jobs.Get(request.Job.ID, jobPtr); err != nil {
 		return nil, err
 	}
-	jobInfo, err := a.jobInfoFromPtr(pachClient, jobPtr, true)
+	jobInfo, err := a.jobInfoFromPtr(pachClient, jobPtr, true, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -611,7 +723,7 @@ func (a *apiServer) InspectJob(ctx context.Context, request *pps.InspectJobReque
 	if jobInfo.State != pps.JobState_JOB_RUNNING {
 		return jobInfo, nil
 	}
-	workerPoolID := ppsutil.PipelineRcName(jobInfo.Pipeline.Name, jobInfo.PipelineVersion)
+	workerPoolID := jobWorkerPoolID(jobInfo)
 	workerStatus, err := workerpkg.Status(ctx, workerPoolID, a.env.GetEtcdClient(), a.etcdPrefix, a.workerGrpcPort)
 	if err != nil {
 		logrus.Errorf("failed to get worker status with err: %s", err.Error())
@@ -628,18 +740,114 @@ func (a *apiServer) InspectJob(ctx context.Context, request *pps.InspectJobReque
 	return jobInfo, nil
 }
 
+// JobProgress implements the protobuf pps.JobProgress RPC
+func (a *apiServer) JobProgress(request *pps.JobProgressRequest, resp pps.API_JobProgressServer) (retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	sent := 0
+	defer func(start time.Time) {
+		a.Log(request, fmt.Sprintf("stream containing %d JobProgress", sent), retErr, time.Since(start))
+	}(time.Now())
+	pachClient := a.env.GetPachClient(resp.Context())
+	ctx, err := checkLoggedIn(pachClient)
+	if err != nil {
+		return err
+	}
+	if request.Job == nil {
+		return fmt.Errorf("must specify a job")
+	}
+	watcher, err := a.jobs.ReadOnly(ctx).WatchOne(request.Job.ID)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	estimator := &progressEstimator{}
+	var jobPtr *pps.EtcdJobInfo
+	for {
+		select {
+		case ev, ok := <-watcher.Watch():
+			if !ok {
+				return fmt.Errorf("the stream for job updates closed unexpectedly")
+			}
+			switch ev.Type {
+			case watch.EventError:
+				return ev.Err
+			case watch.EventDelete:
+				return fmt.Errorf("job %s was deleted", request.Job.ID)
+			case watch.EventPut:
+				var jobID string
+				jobPtr = &pps.EtcdJobInfo{}
+				if err := ev.Unmarshal(&jobID, jobPtr); err != nil {
+					return err
+				}
+			}
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if jobPtr == nil {
+			continue // the job hasn't been read yet
+		}
+		if err := resp.Send(estimator.update(time.Now(), jobPtr)); err != nil {
+			return err
+		}
+		sent++
+		if ppsutil.IsTerminal(jobPtr.State) {
+			return nil
+		}
+	}
+}
+
 // listJob is the internal implementation of ListJob shared between ListJob and
 // ListJobStream. When ListJob is removed, this should be inlined into
 // ListJobStream.
 func (a *apiServer) listJob(pachClient *client.APIClient, pipeline *pps.Pipeline,
 	outputCommit *pfs.Commit, inputCommits []*pfs.Commit, history int64, full bool,
-	f func(*pps.JobInfo) error) error {
+	labelSelector string, f func(*pps.JobInfo) error) error {
+	_, err := a.listJobPage(pachClient, &pps.ListJobRequest{
+		Pipeline:      pipeline,
+		OutputCommit:  outputCommit,
+		InputCommit:   inputCommits,
+		History:       history,
+		Full:          full,
+		LabelSelector: labelSelector,
+	}, f)
+	return err
+}
+
+// listJobPage calls 'f' with each job that matches 'request', and returns the
+// token of the next page of jobs if request.PageSize is set (and 'f' was
+// called with a full page)
+func (a *apiServer) listJobPage(pachClient *client.APIClient, request *pps.ListJobRequest, f func(*pps.JobInfo) error) (string, error) {
+	pipeline, outputCommit, inputCommits := request.Pipeline, request.OutputCommit, request.InputCommit
+	filter, err := newLabelFilter(request.LabelSelector)
+	if err != nil {
+		return "", err
+	}
+	matchesJob, err := newJobFilter(request)
+	if err != nil {
+		return "", err
+	}
+	index, err := jobIndex(request, filter)
+	if err != nil {
+		return "", err
+	}
+	if request.PageSize < 0 {
+		return "", fmt.Errorf("page size cannot be negative")
+	}
+	cursor, err := col.ParseCursor(request.PageToken)
+	if err != nil {
+		return "", err
+	}
+	opts := &col.Options{Target: col.DefaultOptions.Target, Order: col.DefaultOptions.Order, Cursor: cursor}
 	authIsActive := true
 	me, err := pachClient.WhoAmI(pachClient.Ctx(), &auth.WhoAmIRequest{})
 	if auth.IsErrNotActivated(err) {
 		authIsActive = false
 	} else if err != nil {
-		return err
+		return "", err
 	}
 	if authIsActive && pipeline != nil {
 		// If 'pipeline is set, check that caller has access to the pipeline's
@@ -654,10 +862,10 @@ func (a *apiServer) listJob(pachClient *client.APIClient, pipeline *pps.Pipeline
 			Scope: auth.Scope_READER,
 		})
 		if err != nil {
-			return err
+			return "", err
 		}
 		if !resp.Authorized {
-			return &auth.ErrNotAuthorized{
+			return "", &auth.ErrNotAuthorized{
 				Subject:  me.Username,
 				Repo:     pipeline.Name,
 				Required: auth.Scope_READER,
@@ -667,29 +875,43 @@ func (a *apiServer) listJob(pachClient *client.APIClient, pipeline *pps.Pipeline
 	if outputCommit != nil {
 		outputCommit, err = a.resolveCommit(pachClient, outputCommit)
 		if err != nil {
-			return err
+			return "", err
 		}
 	}
 	for i, inputCommit := range inputCommits {
 		inputCommits[i], err = a.resolveCommit(pachClient, inputCommit)
 		if err != nil {
-			return err
+			return "", err
 		}
 	}
 	// specCommits holds the specCommits of pipelines that we're interested in
 	specCommits := make(map[string]bool)
-	if err := a.listPipelinePtr(pachClient, pipeline, history,
+	if err := a.listPipelinePtr(pachClient, pipeline, request.History, col.DefaultOptions,
 		func(ptr *pps.EtcdPipelineInfo) error {
 			specCommits[ptr.SpecCommit.ID] = true
 			return nil
 		}); err != nil {
-		return err
+		return "", err
 	}
+	pipelineInfos := make(pipelineInfoCache)
 	jobs := a.jobs.ReadOnly(pachClient.Ctx())
 	jobPtr := &pps.EtcdJobInfo{}
+	var sent int64
+	var nextPageToken string
 	_f := func(string) error {
-		jobInfo, err := a.jobInfoFromPtr(pachClient, jobPtr,
-			len(inputCommits) > 0 || full)
+		if ok, err := filter.matchesIndexValues(jobPtr.Labels); err != nil {
+			return err
+		} else if !ok {
+			return nil
+		}
+		// Filters that only need jobPtr are applied before jobInfoFromPtr,
+		// which reads the job's commits and spec
+		if ok, err := matchesJob(jobPtr); err != nil {
+			return err
+		} else if !ok {
+			return nil
+		}
+		jobInfo, err := a.jobInfoFromPtr(pachClient, jobPtr, request.Full, pipelineInfos)
 		if err != nil {
 			if isNotFoundErr(err) {
 				// This can happen if a user deletes an upstream commit and thereby
@@ -702,55 +924,152 @@ func (a *apiServer) listJob(pachClient *client.APIClient, pipeline *pps.Pipeline
 			}
 			return err
 		}
-		if len(inputCommits) > 0 {
-			found := make([]bool, len(inputCommits))
-			pps.VisitInput(jobInfo.Input, func(in *pps.Input) {
-				if in.Pfs != nil {
-					for i, inputCommit := range inputCommits {
-						if in.Pfs.Commit == inputCommit.ID {
-							found[i] = true
-						}
-					}
-				}
-			})
-			for _, found := range found {
-				if !found {
-					return nil
-				}
+		for _, inputCommit := range inputCommits {
+			if !readsCommit(jobInfo, inputCommit) {
+				return nil
 			}
 		}
 		if !specCommits[jobInfo.SpecCommit.ID] {
 			return nil
 		}
-		return f(jobInfo)
+		if err := f(jobInfo); err != nil {
+			return err
+		}
+		sent++
+		if sent == request.PageSize {
+			// 'cursor' points at this job, so the next page starts after it
+			nextPageToken = cursor.Token()
+			return errutil.ErrBreak
+		}
+		return nil
 	}
-	if pipeline != nil {
-		return jobs.GetByIndex(ppsdb.JobsPipelineIndex, pipeline, jobPtr, col.DefaultOptions, _f)
-	} else if outputCommit != nil {
-		return jobs.GetByIndex(ppsdb.JobsOutputIndex, outputCommit, jobPtr, col.DefaultOptions, _f)
-	} else {
-		return jobs.List(jobPtr, col.DefaultOptions, _f)
+	// The index only narrows down the jobs that are read, as each one is
+	// checked against every filter above
+	switch index {
+	case pps.JobIndex_JOB_INDEX_OUTPUT_COMMIT:
+		err = jobs.GetByIndex(ppsdb.JobsOutputIndex, outputCommit, jobPtr, opts, _f)
+	case pps.JobIndex_JOB_INDEX_PIPELINE:
+		err = jobs.GetByIndex(ppsdb.JobsPipelineIndex, pipeline, jobPtr, opts, _f)
+	case pps.JobIndex_JOB_INDEX_LABEL:
+		err = jobs.GetByIndex(ppsdb.JobsLabelIndex, filter.indexValue, jobPtr, opts, _f)
+	case pps.JobIndex_JOB_INDEX_STATE:
+		err = jobs.GetByIndex(ppsdb.JobsStateIndex, request.State[0], jobPtr, opts, _f)
+	case pps.JobIndex_JOB_INDEX_STARTED:
+		var from, to string
+		if from, to, err = ppsdb.StartedIndexRange(request.StartedAfter, request.StartedBefore); err != nil {
+			return "", err
+		}
+		err = jobs.GetByIndexRange(ppsdb.JobsStartedIndex, from, to, jobPtr, opts, _f)
+	default:
+		err = jobs.List(jobPtr, opts, _f)
+	}
+	if err != nil {
+		return "", err
+	}
+	return nextPageToken, nil
+}
+
+// newJobFilter returns a function that returns true if a job matches the
+// state and start time filters in 'request'
+func newJobFilter(request *pps.ListJobRequest) (func(*pps.EtcdJobInfo) (bool, error), error) {
+	var startedAfter, startedBefore time.Time
+	var err error
+	if request.StartedAfter != nil {
+		if startedAfter, err = types.TimestampFromProto(request.StartedAfter); err != nil {
+			return nil, err
+		}
+	}
+	if request.StartedBefore != nil {
+		if startedBefore, err = types.TimestampFromProto(request.StartedBefore); err != nil {
+			return nil, err
+		}
+	}
+	return func(jobPtr *pps.EtcdJobInfo) (bool, error) {
+		if len(request.State) > 0 {
+			found := false
+			for _, state := range request.State {
+				found = found || jobPtr.State == state
+			}
+			if !found {
+				return false, nil
+			}
+		}
+		if request.StartedAfter == nil && request.StartedBefore == nil {
+			return true, nil
+		}
+		if jobPtr.Started == nil {
+			return false, nil
+		}
+		started, err := types.TimestampFromProto(jobPtr.Started)
+		if err != nil {
+			return false, err
+		}
+		if request.StartedAfter != nil && started.Before(startedAfter) {
+			return false, nil
+		}
+		if request.StartedBefore != nil && !started.Before(startedBefore) {
+			return false, nil
+		}
+		return true, nil
+	}, nil
+}
+
+// pipelineInfoCache holds the pipeline specs read by jobInfoFromPtr, keyed by
+// spec commit ID, so that listing many jobs of the same pipeline version reads
+// its spec once. A nil pipelineInfoCache doesn't cache anything.
+type pipelineInfoCache map[string]*pps.PipelineInfo
+
+func (c pipelineInfoCache) get(pachClient *client.APIClient, ptr *pps.EtcdPipelineInfo) (*pps.PipelineInfo, error) {
+	if pipelineInfo, ok := c[ptr.SpecCommit.ID]; ok {
+		return pipelineInfo, nil
+	}
+	pipelineInfo, err := ppsutil.GetPipelineInfo(pachClient, ptr)
+	if err != nil {
+		return nil, err
+	}
+	if c != nil {
+		c[ptr.SpecCommit.ID] = pipelineInfo
 	}
+	return pipelineInfo, nil
 }
 
-func (a *apiServer) jobInfoFromPtr(pachClient *client.APIClient, jobPtr *pps.EtcdJobInfo, full bool) (*pps.JobInfo, error) {
+// jobInfoFromPtr returns the JobInfo of 'jobPtr'. Its input commits are always
+// filled in, while the fields copied from its pipeline's spec are only filled
+// in if 'full' is set.
+func (a *apiServer) jobInfoFromPtr(pachClient *client.APIClient, jobPtr *pps.EtcdJobInfo, full bool, pipelineInfos pipelineInfoCache) (*pps.JobInfo, error) {
 	result := &pps.JobInfo{
-		Job:           jobPtr.Job,
-		Pipeline:      jobPtr.Pipeline,
-		OutputRepo:    &pfs.Repo{Name: jobPtr.Pipeline.Name},
-		OutputCommit:  jobPtr.OutputCommit,
-		Restart:       jobPtr.Restart,
-		DataProcessed: jobPtr.DataProcessed,
-		DataSkipped:   jobPtr.DataSkipped,
-		DataTotal:     jobPtr.DataTotal,
-		DataFailed:    jobPtr.DataFailed,
-		DataRecovered: jobPtr.DataRecovered,
-		Stats:         jobPtr.Stats,
-		StatsCommit:   jobPtr.StatsCommit,
-		State:         jobPtr.State,
-		Reason:        jobPtr.Reason,
-		Started:       jobPtr.Started,
-		Finished:      jobPtr.Finished,
+		Job:            jobPtr.Job,
+		Pipeline:       jobPtr.Pipeline,
+		OutputRepo:     &pfs.Repo{Name: jobPtr.Pipeline.Name},
+		OutputCommit:   jobPtr.OutputCommit,
+		Restart:        jobPtr.Restart,
+		DataProcessed:  jobPtr.DataProcessed,
+		DataSkipped:    jobPtr.DataSkipped,
+		DataTotal:      jobPtr.DataTotal,
+		DataFailed:     jobPtr.DataFailed,
+		DataRecovered:  jobPtr.DataRecovered,
+		Stats:          jobPtr.Stats,
+		StatsCommit:    jobPtr.StatsCommit,
+		State:          jobPtr.State,
+		Reason:         jobPtr.Reason,
+		Started:        jobPtr.Started,
+		Finished:       jobPtr.Finished,
+		InputMetadata:  jobPtr.InputMetadata,
+		StandbyWake:    jobPtr.StandbyWake,
+		EstimatedCost:  jobPtr.EstimatedCost,
+		ReplayOf:       jobPtr.ReplayOf,
+		OutputDiff:     jobPtr.OutputDiff,
+		DatumBalance:   jobPtr.DatumBalance,
+		DatumDurations: jobPtr.DatumDurations,
+		Artifacts:      jobPtr.Artifacts,
+		WorkerPool:     jobPtr.WorkerPool,
+	}
+	if len(jobPtr.Labels) > 0 {
+		labels, err := ppsdb.ParseLabelIndexValues(jobPtr.Labels)
+		if err != nil {
+			return nil, err
+		}
+		result.Metadata = &pps.Metadata{Labels: labels}
 	}
 	commitInfo, err := pachClient.InspectCommit(jobPtr.OutputCommit.Repo.Name, jobPtr.OutputCommit.ID)
 	if err != nil {
@@ -758,7 +1077,7 @@ func (a *apiServer) jobInfoFromPtr(pachClient *client.APIClient, jobPtr *pps.Etc
 			if _, err := a.DeleteJob(pachClient.Ctx(), &pps.DeleteJobRequest{Job: jobPtr.Job}); err != nil {
 				return nil, err
 			}
-			return nil, fmt.Errorf("job %s not found", jobPtr.Job.ID)
+			return nil, ppsServer.ErrJobNotFound{Job: jobPtr.Job}
 		}
 		return nil, err
 	}
@@ -781,11 +1100,13 @@ func (a *apiServer) jobInfoFromPtr(pachClient *client.APIClient, jobPtr *pps.Etc
 	// was created, this prevents races between updating a pipeline and
 	// previous jobs running.
 	pipelinePtr.SpecCommit = specCommit
+	pipelineInfo, err := pipelineInfos.get(pachClient, pipelinePtr)
+	if err != nil {
+		return nil, err
+	}
+	jobInput := ppsutil.JobInput(pipelineInfo, commitInfo)
+	result.InputCommits = ppsutil.InputCommits(jobInput)
 	if full {
-		pipelineInfo, err := ppsutil.GetPipelineInfo(pachClient, pipelinePtr)
-		if err != nil {
-			return nil, err
-		}
 		result.Transform = pipelineInfo.Transform
 		result.PipelineVersion = pipelineInfo.Version
 		result.ParallelismSpec = pipelineInfo.ParallelismSpec
@@ -795,20 +1116,49 @@ func (a *apiServer) jobInfoFromPtr(pachClient *client.APIClient, jobPtr *pps.Etc
 		result.OutputBranch = pipelineInfo.OutputBranch
 		result.ResourceRequests = pipelineInfo.ResourceRequests
 		result.ResourceLimits = pipelineInfo.ResourceLimits
-		result.Input = ppsutil.JobInput(pipelineInfo, commitInfo)
+		result.Input = jobInput
+		result.InputConsistency = ppsutil.InputConsistency(pipelineInfo, commitInfo)
 		result.EnableStats = pipelineInfo.EnableStats
 		result.Salt = pipelineInfo.Salt
+		result.DatumCache = pipelineInfo.DatumCache
 		result.ChunkSpec = pipelineInfo.ChunkSpec
 		result.DatumTimeout = pipelineInfo.DatumTimeout
 		result.JobTimeout = pipelineInfo.JobTimeout
+		result.TimeoutPolicy = pipelineInfo.TimeoutPolicy
+		result.DatumRetry = pipelineInfo.DatumRetry
+		result.DatumOrder = pipelineInfo.DatumOrder
 		result.DatumTries = pipelineInfo.DatumTries
 		result.SchedulingSpec = pipelineInfo.SchedulingSpec
 		result.PodSpec = pipelineInfo.PodSpec
 		result.PodPatch = pipelineInfo.PodPatch
+		if pipelineInfo.Metadata != nil {
+			if result.Metadata == nil {
+				result.Metadata = &pps.Metadata{}
+			}
+			result.Metadata.Annotations = pipelineInfo.Metadata.Annotations
+		}
+		if jobPtr.ReplayOf != nil {
+			// Replays run with a salt of their own, and only write their output
+			// commit (see ReplayJob)
+			result.Salt = ppsutil.ReplaySalt(result.Salt, jobPtr.ReplayOf.ID)
+			result.EnableStats = false
+			result.Egress = nil
+		}
 	}
 	return result, nil
 }
 
+// readsCommit returns true if 'commit' is one of the input commits of
+// 'jobInfo'
+func readsCommit(jobInfo *pps.JobInfo, commit *pfs.Commit) bool {
+	for _, inputCommit := range jobInfo.InputCommits {
+		if inputCommit.Repo.Name == commit.Repo.Name && inputCommit.ID == commit.ID {
+			return true
+		}
+	}
+	return false
+}
+
 // ListJob implements the protobuf pps.ListJob RPC
 func (a *apiServer) ListJob(ctx context.Context, request *pps.ListJobRequest) (response *pps.JobInfos, retErr error) {
 	func() { a.Log(request, nil, nil, 0) }()
@@ -822,13 +1172,14 @@ func (a *apiServer) ListJob(ctx context.Context, request *pps.ListJobRequest) (r
 	}(time.Now())
 	pachClient := a.env.GetPachClient(ctx)
 	var jobInfos []*pps.JobInfo
-	if err := a.listJob(pachClient, request.Pipeline, request.OutputCommit, request.InputCommit, request.History, request.Full, func(ji *pps.JobInfo) error {
+	nextPageToken, err := a.listJobPage(pachClient, request, func(ji *pps.JobInfo) error {
 		jobInfos = append(jobInfos, ji)
 		return nil
-	}); err != nil {
+	})
+	if err != nil {
 		return nil, err
 	}
-	return &pps.JobInfos{JobInfo: jobInfos}, nil
+	return &pps.JobInfos{JobInfo: jobInfos, NextPageToken: nextPageToken}, nil
 }
 
 // ListJobStream implements the protobuf pps.ListJobStream RPC
@@ -839,13 +1190,90 @@ func (a *apiServer) ListJobStream(request *pps.ListJobRequest, resp pps.API_List
 		a.Log(request, fmt.Sprintf("stream containing %d JobInfos", sent), retErr, time.Since(start))
 	}(time.Now())
 	pachClient := a.env.GetPachClient(resp.Context())
-	return a.listJob(pachClient, request.Pipeline, request.OutputCommit, request.InputCommit, request.History, request.Full, func(ji *pps.JobInfo) error {
+	// The stream can't return the next page's token, so ListJob is used to
+	// page through jobs
+	_, err := a.listJobPage(pachClient, request, func(ji *pps.JobInfo) error {
 		if err := resp.Send(ji); err != nil {
 			return err
 		}
 		sent++
 		return nil
 	})
+	return err
+}
+
+// WatchJob implements the protobuf pps.WatchJob RPC
+func (a *apiServer) WatchJob(request *pps.ListJobRequest, resp pps.API_WatchJobServer) (retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	sent := 0
+	defer func(start time.Time) {
+		a.Log(request, fmt.Sprintf("stream containing %d JobInfos", sent), retErr, time.Since(start))
+	}(time.Now())
+	pachClient := a.env.GetPachClient(resp.Context())
+	ctx, err := checkLoggedIn(pachClient)
+	if err != nil {
+		return err
+	}
+	if len(request.InputCommit) > 0 || request.OutputCommit != nil {
+		return fmt.Errorf("WatchJob doesn't support filtering by input or output commit")
+	}
+	if request.PageSize != 0 || request.PageToken != "" {
+		return fmt.Errorf("WatchJob doesn't support paging")
+	}
+	filter, err := newLabelFilter(request.LabelSelector)
+	if err != nil {
+		return err
+	}
+	matchesJob, err := newJobFilter(request)
+	if err != nil {
+		return err
+	}
+	pipelineInfos := make(pipelineInfoCache)
+	watcher, err := a.jobs.ReadOnly(ctx).Watch()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	for {
+		ev, ok := <-watcher.Watch()
+		if !ok {
+			return fmt.Errorf("the stream for job updates closed unexpectedly")
+		}
+		switch ev.Type {
+		case watch.EventError:
+			return ev.Err
+		case watch.EventPut:
+			var jobID string
+			jobPtr := &pps.EtcdJobInfo{}
+			if err := ev.Unmarshal(&jobID, jobPtr); err != nil {
+				return err
+			}
+			if request.Pipeline != nil && request.Pipeline.Name != jobPtr.Pipeline.Name {
+				continue
+			}
+			if ok, err := filter.matchesIndexValues(jobPtr.Labels); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			if ok, err := matchesJob(jobPtr); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			jobInfo, err := a.jobInfoFromPtr(pachClient, jobPtr, request.Full, pipelineInfos)
+			if err != nil {
+				if isNotFoundErr(err) || auth.IsErrNotAuthorized(err) {
+					continue // see listJob
+				}
+				return err
+			}
+			if err := resp.Send(jobInfo); err != nil {
+				return err
+			}
+			sent++
+		}
+	}
 }
 
 // FlushJob implements the protobuf pps.FlushJob RPC
@@ -868,7 +1296,7 @@ func (a *apiServer) FlushJob(request *pps.FlushJobRequest, resp pps.API_FlushJob
 		var jis []*pps.JobInfo
 		// FlushJob passes -1 for history because we don't know which version
 		// of the pipeline created the output commit.
-		if err := a.listJob(pachClient, nil, ci.Commit, nil, -1, false, func(ji *pps.JobInfo) error {
+		if err := a.listJob(pachClient, nil, ci.Commit, nil, -1, false, "", func(ji *pps.JobInfo) error {
 			jis = append(jis, ji)
 			return nil
 		}); err != nil {
@@ -889,6 +1317,11 @@ func (a *apiServer) FlushJob(request *pps.FlushJobRequest, resp pps.API_FlushJob
 		// finished yet, so we block on its state as well.
 		ji, err := a.InspectJob(ctx, &pps.InspectJobRequest{Job: jis[0].Job, BlockState: true})
 		if err != nil {
+			// Empty jobs of pipelines with the empty job policy EMPTY_JOB_ELIDE
+			// are deleted once they finish
+			if err := a.jobs.ReadOnly(ctx).Get(jis[0].Job.ID, &pps.EtcdJobInfo{}); col.IsErrNotFound(err) {
+				return nil
+			}
 			return err
 		}
 		return resp.Send(ji)
@@ -957,7 +1390,7 @@ func (a *apiServer) RestartDatum(ctx context.Context, request *pps.RestartDatumR
 	if err != nil {
 		return nil, err
 	}
-	workerPoolID := ppsutil.PipelineRcName(jobInfo.Pipeline.Name, jobInfo.PipelineVersion)
+	workerPoolID := jobWorkerPoolID(jobInfo)
 	if err := workerpkg.Cancel(ctx, workerPoolID, a.env.GetEtcdClient(), a.etcdPrefix, a.workerGrpcPort, request.Job.ID, request.DataFilters); err != nil {
 		return nil, err
 	}
@@ -1012,7 +1445,7 @@ func (a *apiServer) listDatum(pachClient *client.APIClient, job *pps.Job, page,
 		return 0, 0, goerr.New("getPageBounds: unreachable code")
 	}
 
-	df, err := workerpkg.NewDatumIterator(pachClient, jobInfo.Input)
+	df, err := workerpkg.NewOrderedDatumIterator(pachClient, jobInfo.Input, jobInfo.DatumOrder, jobInfo.OutputCommit)
 	if err != nil {
 		return nil, err
 	}
@@ -1033,6 +1466,9 @@ func (a *apiServer) listDatum(pachClient *client.APIClient, job *pps.Job, page,
 		for i := start; i < end; i++ {
 			datum := df.DatumN(i) // flattened slice of *worker.Input to job
 			id := workerpkg.HashDatum(jobInfo.Pipeline.Name, jobInfo.Salt, datum)
+			if jobInfo.DatumCache {
+				id = workerpkg.HashCachedDatum(jobInfo.Pipeline.Name, jobInfo.Salt, jobInfo.PipelineVersion, datum)
+			}
 			datumInfo := &pps.DatumInfo{
 				Datum: &pps.Datum{
 					ID:  id,
@@ -1270,7 +1706,7 @@ func (a *apiServer) InspectDatum(ctx context.Context, request *pps.InspectDatumR
 	if jobInfo.StatsCommit == nil {
 		return nil, fmt.Errorf("job not finished, no stats output yet")
 	}
-	df, err := workerpkg.NewDatumIterator(pachClient, jobInfo.Input)
+	df, err := workerpkg.NewOrderedDatumIterator(pachClient, jobInfo.Input, jobInfo.DatumOrder, jobInfo.OutputCommit)
 	if err != nil {
 		return nil, err
 	}
@@ -1290,6 +1726,10 @@ func (a *apiServer) GetLogs(request *pps.GetLogsRequest, apiGetLogsServer pps.AP
 	defer func(start time.Time) { a.Log(request, nil, retErr, time.Since(start)) }(time.Now())
 	pachClient := a.env.GetPachClient(apiGetLogsServer.Context())
 	ctx := pachClient.Ctx() // pachClient will propagate auth info
+	filter, err := newLogFilter(request)
+	if err != nil {
+		return err
+	}
 
 	// Authorize request and get list of pods containing logs we're interested in
 	// (based on pipeline and job filters)
@@ -1341,7 +1781,7 @@ func (a *apiServer) GetLogs(request *pps.GetLogsRequest, apiGetLogsServer pps.AP
 				return err
 			}
 			if ci.Finished != nil {
-				return a.getLogsFromStats(pachClient, request, apiGetLogsServer, statsCommit)
+				return a.getLogsFromStats(pachClient, filter, apiGetLogsServer, statsCommit)
 			}
 		}
 
@@ -1360,6 +1800,18 @@ func (a *apiServer) GetLogs(request *pps.GetLogsRequest, apiGetLogsServer pps.AP
 	if len(pods) == 0 {
 		return fmt.Errorf("no pods belonging to the rc \"%s\" were found", rcName)
 	}
+	if request.WorkerID != "" {
+		var workerPods []v1.Pod
+		for _, pod := range pods {
+			if pod.ObjectMeta.Name == request.WorkerID {
+				workerPods = append(workerPods, pod)
+			}
+		}
+		if len(workerPods) == 0 {
+			return fmt.Errorf("worker \"%s\" does not belong to the rc \"%s\"", request.WorkerID, rcName)
+		}
+		pods = workerPods
+	}
 
 	// Spawn one goroutine per pod. Each goro writes its pod's logs to a channel
 	// and channels are read into the output server in a stable order.
@@ -1382,13 +1834,16 @@ func (a *apiServer) GetLogs(request *pps.GetLogsRequest, apiGetLogsServer pps.AP
 				if *tailLines <= 0 {
 					tailLines = nil
 				}
-				// Get full set of logs from pod i
+				// Get full set of logs from pod i. The stream is closed when
+				// ctx is done, so that following a pod's logs doesn't outlive
+				// the request.
 				stream, err := a.env.GetKubeClient().CoreV1().Pods(a.namespace).GetLogs(
 					pod.ObjectMeta.Name, &v1.PodLogOptions{
-						Container: containerName,
-						Follow:    request.Follow,
-						TailLines: tailLines,
-					}).Timeout(10 * time.Second).Stream()
+						Container:    containerName,
+						Follow:       request.Follow,
+						TailLines:    tailLines,
+						SinceSeconds: filter.sinceSeconds(),
+					}).Context(ctx).Timeout(10 * time.Second).Stream()
 				if err != nil {
 					return err
 				}
@@ -1404,6 +1859,9 @@ func (a *apiServer) GetLogs(request *pps.GetLogsRequest, apiGetLogsServer pps.AP
 					msg := new(pps.LogMessage)
 					if containerName == "pachd" {
 						msg.Message = scanner.Text()
+						if !filter.matches(msg) {
+							continue
+						}
 					} else {
 						logBytes := scanner.Bytes()
 						if err := jsonpb.Unmarshal(bytes.NewReader(logBytes), msg); err != nil {
@@ -1411,19 +1869,7 @@ func (a *apiServer) GetLogs(request *pps.GetLogsRequest, apiGetLogsServer pps.AP
 						}
 
 						// Filter out log lines that don't match on pipeline or job
-						if request.Pipeline != nil && request.Pipeline.Name != msg.PipelineName {
-							continue
-						}
-						if request.Job != nil && request.Job.ID != msg.JobID {
-							continue
-						}
-						if request.Datum != nil && request.Datum.ID != msg.DatumID {
-							continue
-						}
-						if request.Master != msg.Master {
-							continue
-						}
-						if !workerpkg.MatchDatum(request.DataFilters, msg.Data) {
+						if !filter.matchesWorker(msg) {
 							continue
 						}
 					}
@@ -1455,7 +1901,7 @@ func (a *apiServer) GetLogs(request *pps.GetLogsRequest, apiGetLogsServer pps.AP
 	return egErr
 }
 
-func (a *apiServer) getLogsFromStats(pachClient *client.APIClient, request *pps.GetLogsRequest, apiGetLogsServer pps.API_GetLogsServer, statsCommit *pfs.Commit) error {
+func (a *apiServer) getLogsFromStats(pachClient *client.APIClient, filter *logFilter, apiGetLogsServer pps.API_GetLogsServer, statsCommit *pfs.Commit) error {
 	pfsClient := pachClient.PfsAPIClient
 	fs, err := pfsClient.GlobFileStream(pachClient.Ctx(), &pfs.GlobFileRequest{
 		Commit:  statsCommit,
@@ -1491,19 +1937,7 @@ func (a *apiServer) getLogsFromStats(pachClient *client.APIClient, request *pps.
 				if err := jsonpb.Unmarshal(bytes.NewReader(logBytes), msg); err != nil {
 					continue
 				}
-				if request.Pipeline != nil && request.Pipeline.Name != msg.PipelineName {
-					continue
-				}
-				if request.Job != nil && request.Job.ID != msg.JobID {
-					continue
-				}
-				if request.Datum != nil && request.Datum.ID != msg.DatumID {
-					continue
-				}
-				if request.Master != msg.Master {
-					continue
-				}
-				if !workerpkg.MatchDatum(request.DataFilters, msg.Data) {
+				if !filter.matchesWorker(msg) {
 					continue
 				}
 
@@ -1527,12 +1961,8 @@ func (a *apiServer) validatePipelineRequest(request *pps.CreatePipelineRequest)
 	if request.Pipeline.Name == "" {
 		return goerr.New("invalid pipeline spec: request.Pipeline.Name cannot be empty")
 	}
-	if err := ancestry.ValidateName(request.Pipeline.Name); err != nil {
-		return fmt.Errorf("invalid pipeline name: %v", err)
-	}
-	if len(request.Pipeline.Name) > 63 {
-		return fmt.Errorf("pipeline name is %d characters long, but must have at most 63: %q",
-			len(request.Pipeline.Name), request.Pipeline.Name)
+	if err := validatePipelineName(request.Pipeline.Name); err != nil {
+		return err
 	}
 	// TODO(msteffen) eventually TFJob and Transform will be alternatives, but
 	// currently TFJob isn't supported
@@ -1552,20 +1982,18 @@ func (a *apiServer) validatePipeline(pachClient *client.APIClient, pipelineInfo
 	if pipelineInfo.Pipeline.Name == "" {
 		return goerr.New("invalid pipeline spec: Pipeline.Name cannot be empty")
 	}
-	if err := ancestry.ValidateName(pipelineInfo.Pipeline.Name); err != nil {
-		return fmt.Errorf("invalid pipeline name: %v", err)
-	}
-	first := rune(pipelineInfo.Pipeline.Name[0])
-	if !unicode.IsLetter(first) && !unicode.IsDigit(first) {
-		return fmt.Errorf("pipeline names must start with an alphanumeric character")
-	}
-	if len(pipelineInfo.Pipeline.Name) > 63 {
-		return fmt.Errorf("pipeline name is %d characters long, but must have at most 63: %q",
-			len(pipelineInfo.Pipeline.Name), pipelineInfo.Pipeline.Name)
+	if err := validatePipelineName(pipelineInfo.Pipeline.Name); err != nil {
+		return err
 	}
 	if err := validateTransform(pipelineInfo.Transform); err != nil {
 		return fmt.Errorf("invalid transform: %v", err)
 	}
+	if err := validateSandbox(pipelineInfo.Transform); err != nil {
+		return fmt.Errorf("invalid sandbox: %v", err)
+	}
+	if err := validateConfigMapKeys(pipelineInfo.Transform); err != nil {
+		return fmt.Errorf("invalid config maps: %v", err)
+	}
 	if err := a.validateInput(pachClient, pipelineInfo.Pipeline.Name, pipelineInfo.Input, false); err != nil {
 		return err
 	}
@@ -1605,33 +2033,193 @@ func (a *apiServer) validatePipeline(pachClient *client.APIClient, pipelineInfo
 			return err
 		}
 	}
-	if pipelineInfo.PodSpec != "" && !json.Valid([]byte(pipelineInfo.PodSpec)) {
-		return fmt.Errorf("malformed PodSpec")
+	if err := validateSchedulingSpec(pipelineInfo); err != nil {
+		return fmt.Errorf("invalid scheduling spec: %v", err)
 	}
-	if pipelineInfo.PodPatch != "" && !json.Valid([]byte(pipelineInfo.PodPatch)) {
-		return fmt.Errorf("malformed PodPatch")
+	if err := validateBackend(pipelineInfo); err != nil {
+		return fmt.Errorf("invalid backend: %v", err)
 	}
-	if pipelineInfo.Service != nil {
-		validServiceTypes := map[v1.ServiceType]bool{
-			v1.ServiceTypeClusterIP:    true,
-			v1.ServiceTypeLoadBalancer: true,
-			v1.ServiceTypeNodePort:     true,
-		}
-
-		if !validServiceTypes[v1.ServiceType(pipelineInfo.Service.Type)] {
-			return fmt.Errorf("the following service type %s is not allowed", pipelineInfo.Service.Type)
+	if pipelineInfo.Spill != nil {
+		if _, err := obj.ParseURL(pipelineInfo.Spill.URL); err != nil {
+			return fmt.Errorf("invalid spill URL: %v", err)
+		}
+	}
+	if egress := pipelineInfo.Egress; egress != nil && egress.SQLDatabase != nil && egress.Kafka != nil {
+		return fmt.Errorf("egress can't have both a sql_database and kafka")
+	}
+	if egress := pipelineInfo.Egress; egress != nil && egress.Kafka != nil {
+		if egress.URL != "" {
+			return fmt.Errorf("egress can't have both a URL and kafka")
+		}
+		if len(egress.Kafka.Brokers) == 0 || egress.Kafka.Topic == "" {
+			return fmt.Errorf("a kafka egress must specify brokers and a topic")
+		}
+		if format := egress.Kafka.Format; format != "" && format != workerpkg.KafkaFormatRaw && format != workerpkg.KafkaFormatLines {
+			return fmt.Errorf("invalid kafka egress format %q (must be %q or %q)", format, workerpkg.KafkaFormatRaw, workerpkg.KafkaFormatLines)
+		}
+	}
+	if egress := pipelineInfo.Egress; egress != nil && egress.SQLDatabase != nil {
+		if egress.URL != "" {
+			return fmt.Errorf("egress can't have both a URL and a sql_database")
+		}
+		if err := sqlegress.ValidateURL(egress.SQLDatabase.URL); err != nil {
+			return fmt.Errorf("invalid sql_database egress url: %v", err)
+		}
+		if secret := egress.SQLDatabase.Secret; secret != nil && (secret.Name == "" || secret.Key == "") {
+			return fmt.Errorf("sql_database egress secret must have a name and a key")
+		}
+	}
+	if err := validateMetadata(pipelineInfo.Metadata); err != nil {
+		return fmt.Errorf("invalid metadata: %v", err)
+	}
+	if err := validateNetworkPolicy(pipelineInfo.NetworkPolicy); err != nil {
+		return fmt.Errorf("invalid network policy: %v", err)
+	}
+	if err := validateEgressProxy(pipelineInfo.EgressProxy); err != nil {
+		return fmt.Errorf("invalid egress proxy: %v", err)
+	}
+	if err := validateCloudCredentials(pipelineInfo); err != nil {
+		return err
+	}
+	if err := validateScratchVolume(pipelineInfo.ScratchVolume); err != nil {
+		return fmt.Errorf("invalid scratch volume: %v", err)
+	}
+	if err := validateStreamOutput(pipelineInfo); err != nil {
+		return err
+	}
+	if err := validateS3(pipelineInfo); err != nil {
+		return err
+	}
+	if err := validateDatumProfiles(pipelineInfo); err != nil {
+		return fmt.Errorf("invalid datum profiles: %v", err)
+	}
+	if err := validateMaxConcurrentJobs(pipelineInfo); err != nil {
+		return fmt.Errorf("invalid max_concurrent_jobs: %v", err)
+	}
+	if err := validateGPUs(pipelineInfo); err != nil {
+		return err
+	}
+	if err := validateTimeoutPolicy(pipelineInfo); err != nil {
+		return err
+	}
+	if err := validateEmptyJobPolicy(pipelineInfo); err != nil {
+		return err
+	}
+	if err := validateDatumRetry(pipelineInfo); err != nil {
+		return fmt.Errorf("invalid datum_retry: %v", err)
+	}
+	if err := validateDatumOrder(pipelineInfo); err != nil {
+		return fmt.Errorf("invalid datum_order: %v", err)
+	}
+	if err := validateDeterminismCheck(pipelineInfo); err != nil {
+		return fmt.Errorf("invalid determinism_check: %v", err)
+	}
+	if trackingSpec := pipelineInfo.ExperimentTracking; trackingSpec != nil {
+		if pipelineInfo.Service != nil || pipelineInfo.Spout != nil {
+			return fmt.Errorf("experiment_tracking can't be set for services or spouts, whose jobs don't finish")
+		}
+		if err := tracking.Validate(trackingSpec); err != nil {
+			return fmt.Errorf("invalid experiment_tracking: %v", err)
+		}
+	}
+	if pipelineInfo.StandbyGracePeriod != nil {
+		if !pipelineInfo.Standby {
+			return fmt.Errorf("standby_grace_period can only be set on standby pipelines")
+		}
+		gracePeriod, err := types.DurationFromProto(pipelineInfo.StandbyGracePeriod)
+		if err != nil {
+			return fmt.Errorf("invalid standby_grace_period: %v", err)
+		}
+		if gracePeriod < 0 {
+			return fmt.Errorf("standby_grace_period must be non-negative")
+		}
+	}
+	if pipelineInfo.StandbyWakeAlarm != nil {
+		if !pipelineInfo.Standby {
+			return fmt.Errorf("standby_wake_alarm can only be set on standby pipelines")
+		}
+		alarm, err := types.DurationFromProto(pipelineInfo.StandbyWakeAlarm)
+		if err != nil {
+			return fmt.Errorf("invalid standby_wake_alarm: %v", err)
+		}
+		if alarm <= 0 {
+			return fmt.Errorf("standby_wake_alarm must be positive")
+		}
+	}
+	if len(pipelineInfo.DowntimeWindows) > 0 {
+		if pipelineInfo.Service != nil || pipelineInfo.Spout != nil {
+			return fmt.Errorf("downtime_windows can't be set for services or spouts, which don't run jobs")
+		}
+		if err := ppsutil.ValidateDowntimeWindows(pipelineInfo.DowntimeWindows); err != nil {
+			return err
+		}
+	}
+	if pipelineInfo.Budget != nil {
+		if pipelineInfo.Service != nil || pipelineInfo.Spout != nil {
+			return fmt.Errorf("budget can't be set for services or spouts, which don't run jobs")
+		}
+		if err := ppsutil.ValidateBudget(pipelineInfo.Budget); err != nil {
+			return err
+		}
+	}
+	if pipelineInfo.PodSpec != "" && !json.Valid([]byte(pipelineInfo.PodSpec)) {
+		return fmt.Errorf("malformed PodSpec")
+	}
+	if pipelineInfo.PodPatch != "" && !json.Valid([]byte(pipelineInfo.PodPatch)) {
+		return fmt.Errorf("malformed PodPatch")
+	}
+	if pipelineInfo.Service != nil {
+		validServiceTypes := map[v1.ServiceType]bool{
+			v1.ServiceTypeClusterIP:    true,
+			v1.ServiceTypeLoadBalancer: true,
+			v1.ServiceTypeNodePort:     true,
+		}
+
+		if !validServiceTypes[v1.ServiceType(pipelineInfo.Service.Type)] {
+			return fmt.Errorf("the following service type %s is not allowed", pipelineInfo.Service.Type)
+		}
+		if err := ppsutil.ValidateServiceAutoscaling(pipelineInfo.Service.Autoscaling); err != nil {
+			return err
 		}
 	}
 	if pipelineInfo.Spout != nil {
 		if pipelineInfo.EnableStats {
 			return fmt.Errorf("spouts are not allowed to have a stats branch")
 		}
+		if pipelineInfo.Spout.Service.GetAutoscaling() != nil || pipelineInfo.Service.GetAutoscaling() != nil {
+			return fmt.Errorf("spouts' services can't have autoscaling, as spouts run a single worker")
+		}
 		if pipelineInfo.Spout.Marker != "" {
 			// we need to make sure the marker name is also a valid file name, since it is used in file names
 			if err := hashtree.ValidatePath(pipelineInfo.Spout.Marker); err != nil || pipelineInfo.Spout.Marker == "out" {
 				return fmt.Errorf("the spout marker name must be a valid filename: %v", pipelineInfo.Spout.Marker)
 			}
 		}
+		if kafka := pipelineInfo.Spout.Kafka; kafka != nil {
+			if len(kafka.Brokers) == 0 || kafka.Topic == "" {
+				return fmt.Errorf("a kafka spout must specify brokers and a topic")
+			}
+			if kafka.Format != "" && kafka.Format != workerpkg.KafkaFormatRaw && kafka.Format != workerpkg.KafkaFormatLines {
+				return fmt.Errorf("invalid kafka spout format %q (must be %q or %q)", kafka.Format, workerpkg.KafkaFormatRaw, workerpkg.KafkaFormatLines)
+			}
+			if kafka.BatchSize < 0 {
+				return fmt.Errorf("kafka spout batch_size must be non-negative")
+			}
+			if kafka.BatchInterval != nil {
+				batchInterval, err := types.DurationFromProto(kafka.BatchInterval)
+				if err != nil {
+					return err
+				}
+				if batchInterval <= 0 {
+					return fmt.Errorf("kafka spout batch_interval must be positive")
+				}
+			}
+			// Kafka spouts are consumed by the worker, and don't run the
+			// pipeline's code
+			if pipelineInfo.Spout.Marker != "" || pipelineInfo.Spout.Service != nil {
+				return fmt.Errorf("a kafka spout can't have a marker or a service")
+			}
+		}
 	}
 	return nil
 }
@@ -1747,22 +2335,11 @@ func (a *apiServer) sudo(pachClient *client.APIClient, f func(*client.APIClient)
 // a user is updating a pipeline and the case where a user is creating a new
 // pipeline.
 func (a *apiServer) makePipelineInfoCommit(pachClient *client.APIClient, pipelineInfo *pps.PipelineInfo) (result *pfs.Commit, retErr error) {
-	pipelineName := pipelineInfo.Pipeline.Name
 	var commit *pfs.Commit
 	if err := a.sudo(pachClient, func(superUserClient *client.APIClient) error {
-		data, err := pipelineInfo.Marshal()
-		if err != nil {
-			return fmt.Errorf("could not marshal PipelineInfo: %v", err)
-		}
-		if _, err = superUserClient.PutFileOverwrite(ppsconsts.SpecRepo, pipelineName, ppsconsts.SpecFile, bytes.NewReader(data), 0); err != nil {
-			return err
-		}
-		branchInfo, err := superUserClient.InspectBranch(ppsconsts.SpecRepo, pipelineName)
-		if err != nil {
-			return err
-		}
-		commit = branchInfo.Head
-		return nil
+		var err error
+		commit, err = ppsutil.NewSpecStore(superUserClient).WritePipelineSpec(pipelineInfo)
+		return err
 	}); err != nil {
 		return nil, err
 	}
@@ -1908,9 +2485,12 @@ func (a *apiServer) CreatePipeline(ctx context.Context, request *pps.CreatePipel
 	metricsFn := metrics.ReportUserAction(ctx, a.reporter, "CreatePipeline")
 	defer func(start time.Time) { metricsFn(start, retErr) }(time.Now())
 
+	// Fill in any fields the spec doesn't set from the cluster's defaults
+	ppsutil.ApplyPipelineDefaults(request, a.pipelineDefaults)
+
 	// Validate request
 	if err := a.validatePipelineRequest(request); err != nil {
-		return nil, err
+		return nil, validationErr(err)
 	}
 
 	// Annotate current span with pipeline name
@@ -1930,39 +2510,72 @@ func (a *apiServer) CreatePipeline(ctx context.Context, request *pps.CreatePipel
 		request.Salt = uuid.NewWithoutDashes()
 	}
 	pipelineInfo := &pps.PipelineInfo{
-		Pipeline:         request.Pipeline,
-		Version:          1,
-		Transform:        request.Transform,
-		TFJob:            request.TFJob,
-		ParallelismSpec:  request.ParallelismSpec,
-		HashtreeSpec:     request.HashtreeSpec,
-		Input:            request.Input,
-		OutputBranch:     request.OutputBranch,
-		Egress:           request.Egress,
-		CreatedAt:        now(),
-		ResourceRequests: request.ResourceRequests,
-		ResourceLimits:   request.ResourceLimits,
-		Description:      request.Description,
-		CacheSize:        request.CacheSize,
-		EnableStats:      request.EnableStats,
-		Salt:             request.Salt,
-		MaxQueueSize:     request.MaxQueueSize,
-		Service:          request.Service,
-		Spout:            request.Spout,
-		ChunkSpec:        request.ChunkSpec,
-		DatumTimeout:     request.DatumTimeout,
-		JobTimeout:       request.JobTimeout,
-		Standby:          request.Standby,
-		DatumTries:       request.DatumTries,
-		SchedulingSpec:   request.SchedulingSpec,
-		PodSpec:          request.PodSpec,
-		PodPatch:         request.PodPatch,
+		Pipeline:           request.Pipeline,
+		Version:            1,
+		Transform:          request.Transform,
+		TFJob:              request.TFJob,
+		ParallelismSpec:    request.ParallelismSpec,
+		HashtreeSpec:       request.HashtreeSpec,
+		Input:              request.Input,
+		OutputBranch:       request.OutputBranch,
+		Egress:             request.Egress,
+		CreatedAt:          now(),
+		ResourceRequests:   request.ResourceRequests,
+		ResourceLimits:     request.ResourceLimits,
+		Description:        request.Description,
+		CacheSize:          request.CacheSize,
+		EnableStats:        request.EnableStats,
+		Salt:               request.Salt,
+		MaxQueueSize:       request.MaxQueueSize,
+		Service:            request.Service,
+		Spout:              request.Spout,
+		ChunkSpec:          request.ChunkSpec,
+		DatumTimeout:       request.DatumTimeout,
+		JobTimeout:         request.JobTimeout,
+		Standby:            request.Standby,
+		DatumTries:         request.DatumTries,
+		SchedulingSpec:     request.SchedulingSpec,
+		PodSpec:            request.PodSpec,
+		PodPatch:           request.PodPatch,
+		Backend:            request.Backend,
+		Spill:              request.Spill,
+		Metadata:           request.Metadata,
+		StandbyGracePeriod: request.StandbyGracePeriod,
+		StandbyWakeAlarm:   request.StandbyWakeAlarm,
+		DowntimeWindows:    request.DowntimeWindows,
+		Budget:             request.Budget,
+		DeterminismCheck:   request.DeterminismCheck,
+		ExperimentTracking: request.ExperimentTracking,
+		NetworkPolicy:      request.NetworkPolicy,
+		EgressProxy:        request.EgressProxy,
+		ScratchVolume:      request.ScratchVolume,
+		StreamOutput:       request.StreamOutput,
+		DatumProfiles:      request.DatumProfiles,
+		TimeoutPolicy:      request.TimeoutPolicy,
+		DatumRetry:         request.DatumRetry,
+		DatumOrder:         request.DatumOrder,
+		S3Out:              request.S3Out,
+		CloudCredentials:   request.CloudCredentials,
+		MaxConcurrentJobs:  request.MaxConcurrentJobs,
+		EmptyJobPolicy:     request.EmptyJobPolicy,
+		DatumCache:         request.DatumCache,
 	}
 	if err := setPipelineDefaults(pipelineInfo); err != nil {
 		return nil, err
 	}
 	// Validate final PipelineInfo (now that defaults have been populated)
 	if err := a.validatePipeline(pachClient, pipelineInfo); err != nil {
+		return nil, validationErr(err)
+	}
+	if err := a.validateImagePull(ctx, pipelineInfo.Transform); err != nil {
+		return nil, err
+	}
+	configMapsHash, err := a.configMapsHash(pipelineInfo.Transform)
+	if err != nil {
+		return nil, err
+	}
+	pipelineInfo.ConfigMapsHash = configMapsHash
+	if err := a.pinImage(ctx, pipelineInfo); err != nil {
 		return nil, err
 	}
 
@@ -2077,9 +2690,12 @@ func (a *apiServer) CreatePipeline(ctx context.Context, request *pps.CreatePipel
 				}
 				// Update pipelinePtr to point to new commit
 				pipelinePtr.SpecCommit = specCommit
-				pipelinePtr.State = pps.PipelineState_PIPELINE_STARTING
+				pipelinePtr.Labels = ppsdb.LabelIndexValues(pipelineInfo.Metadata.GetLabels())
+				pipelinePtr.Budget = pipelineInfo.Budget
+				ppsutil.SetPipelineState(&pipelinePtr, pps.PipelineState_PIPELINE_STARTING, "pipeline updated")
 				// Clear any failure reasons
 				pipelinePtr.Reason = ""
+				pipelinePtr.ReasonCode = pps.PipelineReasonCode_PIPELINE_REASON_UNKNOWN
 				return nil
 			})
 		}); err != nil {
@@ -2118,6 +2734,9 @@ func (a *apiServer) CreatePipeline(ctx context.Context, request *pps.CreatePipel
 			}
 		}
 	} else {
+		if err := a.checkResourceNames(ctx, pipelineName); err != nil {
+			return nil, validationErr(err)
+		}
 		// Create output repo, pipeline output, and stats
 		if _, err := pachClient.PfsAPIClient.CreateRepo(pachClient.Ctx(),
 			&pfs.CreateRepoRequest{
@@ -2152,9 +2771,12 @@ func (a *apiServer) CreatePipeline(ctx context.Context, request *pps.CreatePipel
 		// pipelinePtr will be written to etcd, pointing at 'commit'. May include an
 		// auth token
 		pipelinePtr := &pps.EtcdPipelineInfo{
-			SpecCommit: commit,
-			State:      pps.PipelineState_PIPELINE_STARTING,
+			SpecCommit:    commit,
+			Labels:        ppsdb.LabelIndexValues(pipelineInfo.Metadata.GetLabels()),
+			Budget:        pipelineInfo.Budget,
+			SchemaVersion: ppsdb.SchemaVersion,
 		}
+		ppsutil.SetPipelineState(pipelinePtr, pps.PipelineState_PIPELINE_STARTING, "")
 
 		// Generate pipeline's auth token & add pipeline to the ACLs of input/output
 		// repos
@@ -2231,6 +2853,109 @@ func (a *apiServer) CreatePipeline(ctx context.Context, request *pps.CreatePipel
 	return &types.Empty{}, nil
 }
 
+// InstantiateTemplate implements the protobuf pps.InstantiateTemplate RPC
+func (a *apiServer) InstantiateTemplate(ctx context.Context, request *pps.InstantiateTemplateRequest) (response *pps.InstantiateTemplateResponse, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	metricsFn := metrics.ReportUserAction(ctx, a.reporter, "InstantiateTemplate")
+	defer func(start time.Time) { metricsFn(start, retErr) }(time.Now())
+
+	if request.Template == nil || request.Template.Commit == nil {
+		return nil, fmt.Errorf("template must be set")
+	}
+	if len(request.Parameters) == 0 {
+		return nil, fmt.Errorf("at least one set of parameters must be given")
+	}
+	pachClient := a.env.GetPachClient(ctx)
+	var buf bytes.Buffer
+	file := request.Template
+	if err := pachClient.GetFile(file.Commit.Repo.Name, file.Commit.ID, file.Path, 0, 0, &buf); err != nil {
+		return nil, fmt.Errorf("could not read template %s@%s:%s: %v", file.Commit.Repo.Name, file.Commit.ID, file.Path, err)
+	}
+	// Render every instantiation before creating any pipelines, so that a bad
+	// set of parameters doesn't leave the pipelines half-created
+	var requests []*pps.CreatePipelineRequest
+	names := make(map[string]bool)
+	for i, params := range request.Parameters {
+		rendered, err := ppsutil.RenderPipelineTemplate(buf.Bytes(), params.Values)
+		if err != nil {
+			return nil, fmt.Errorf("parameter set %d: %v", i, err)
+		}
+		for _, r := range rendered {
+			if r.Pipeline == nil {
+				return nil, fmt.Errorf("parameter set %d: rendered pipeline has no name", i)
+			}
+			if names[r.Pipeline.Name] {
+				return nil, fmt.Errorf("parameter set %d: pipeline %q is rendered more than once", i, r.Pipeline.Name)
+			}
+			names[r.Pipeline.Name] = true
+			r.Update = request.Update
+			r.Reprocess = request.Reprocess
+			requests = append(requests, r)
+		}
+	}
+	response = &pps.InstantiateTemplateResponse{}
+	for _, r := range requests {
+		if _, err := a.CreatePipeline(ctx, r); err != nil {
+			return nil, fmt.Errorf("could not create pipeline %q: %v", r.Pipeline.Name, err)
+		}
+		response.Pipelines = append(response.Pipelines, r.Pipeline)
+	}
+	return response, nil
+}
+
+// CreatePipelines implements the protobuf pps.CreatePipelines RPC
+func (a *apiServer) CreatePipelines(ctx context.Context, request *pps.CreatePipelinesRequest) (response *errcode.BulkResponse, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	metricsFn := metrics.ReportUserAction(ctx, a.reporter, "CreatePipelines")
+	defer func(start time.Time) { metricsFn(start, retErr) }(time.Now())
+
+	atomic := request.Mode == errcode.BulkMode_ATOMIC
+	if atomic {
+		// Validate every pipeline before creating any of them, so that most
+		// bad requests don't need to be rolled back
+		names := make(map[string]bool)
+		for _, r := range request.Pipelines {
+			ppsutil.ApplyPipelineDefaults(r, a.pipelineDefaults)
+			if err := a.validatePipelineRequest(r); err != nil {
+				return nil, validationErr(err)
+			}
+			if names[r.Pipeline.Name] {
+				return nil, errcode.Errorf(errcode.Validation, "pipeline %q is in the request more than once", r.Pipeline.Name)
+			}
+			names[r.Pipeline.Name] = true
+		}
+	}
+	pachClient := a.env.GetPachClient(ctx)
+	response = &errcode.BulkResponse{}
+	var created []string // the pipelines created (not updated) by this request
+	for _, r := range request.Pipelines {
+		name := r.Pipeline.GetName()
+		_, inspectErr := a.inspectPipeline(pachClient, name)
+		_, err := a.CreatePipeline(ctx, r)
+		if err != nil && atomic {
+			// Delete the pipelines created so far, newest first, since later
+			// pipelines may take earlier ones as input. Pipelines that this
+			// request updated are left as they are.
+			for i := len(created) - 1; i >= 0; i-- {
+				if _, err := a.DeletePipeline(ctx, &pps.DeletePipelineRequest{
+					Pipeline: client.NewPipeline(created[i]),
+					Force:    true,
+				}); err != nil {
+					logrus.Errorf("could not delete pipeline %q while rolling back CreatePipelines: %v", created[i], err)
+				}
+			}
+			return nil, errcode.Errorf(errcode.Of(err), "could not create pipeline %q: %s", name, errcode.Message(err))
+		}
+		if err == nil && inspectErr != nil {
+			created = append(created, name)
+		}
+		response.Results = append(response.Results, errcode.NewItemResult(name, err))
+	}
+	return response, nil
+}
+
 // setPipelineDefaults sets the default values for a pipeline info
 func setPipelineDefaults(pipelineInfo *pps.PipelineInfo) error {
 	now := time.Now()
@@ -2301,7 +3026,25 @@ func (a *apiServer) InspectPipeline(ctx context.Context, request *pps.InspectPip
 	func() { a.Log(request, nil, nil, 0) }()
 	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
 	pachClient := a.env.GetPachClient(ctx)
-	return a.inspectPipeline(pachClient, request.Pipeline.Name)
+	pipelineInfo, err := a.inspectPipeline(pachClient, request.Pipeline.Name)
+	if err != nil || !request.Full {
+		return pipelineInfo, err
+	}
+	pipelinePtr := &pps.EtcdPipelineInfo{}
+	if err := a.pipelines.ReadOnly(ctx).Get(pipelineInfo.Pipeline.Name, pipelinePtr); err != nil {
+		return nil, err
+	}
+	// Never return the pipeline's auth token
+	pipelinePtr.AuthToken = ""
+	pipelineInfo.EtcdPipelineInfo = pipelinePtr
+	// Fill in defaults that were added after this pipeline was created, so
+	// that callers see the spec that workers actually run with
+	if pipelineInfo.Transform != nil {
+		if err := setPipelineDefaults(pipelineInfo); err != nil {
+			return nil, err
+		}
+	}
+	return pipelineInfo, nil
 }
 
 // inspectPipeline contains the functional implementation of InspectPipeline.
@@ -2319,7 +3062,7 @@ func (a *apiServer) inspectPipeline(pachClient *client.APIClient, name string) (
 	pipelinePtr := pps.EtcdPipelineInfo{}
 	if err := a.pipelines.ReadOnly(pachClient.Ctx()).Get(name, &pipelinePtr); err != nil {
 		if col.IsErrNotFound(err) {
-			return nil, fmt.Errorf("pipeline \"%s\" not found", name)
+			return nil, newErrPipelineNotFound(name)
 		}
 		return nil, err
 	}
@@ -2391,50 +3134,174 @@ func (a *apiServer) ListPipeline(ctx context.Context, request *pps.ListPipelineR
 		return nil, err
 	}
 	pipelineInfos := &pps.PipelineInfos{}
-	if err := a.listPipeline(pachClient, request, func(pi *pps.PipelineInfo) error {
+	pipelineInfos.NextPageToken, err = a.listPipeline(pachClient, request, func(pi *pps.PipelineInfo) error {
 		pipelineInfos.PipelineInfo = append(pipelineInfos.PipelineInfo, pi)
 		return nil
-	}); err != nil {
+	})
+	if err != nil {
 		return nil, err
 	}
 	return pipelineInfos, nil
 }
 
-func (a *apiServer) listPipeline(pachClient *client.APIClient, request *pps.ListPipelineRequest, f func(*pps.PipelineInfo) error) error {
-	return a.listPipelinePtr(pachClient, request.Pipeline, request.History,
-		func(ptr *pps.EtcdPipelineInfo) error {
-			pipelineInfo, err := ppsutil.GetPipelineInfo(pachClient, ptr)
+// WatchPipeline implements the protobuf pps.WatchPipeline RPC
+func (a *apiServer) WatchPipeline(request *pps.ListPipelineRequest, resp pps.API_WatchPipelineServer) (retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	sent := 0
+	defer func(start time.Time) {
+		a.Log(request, fmt.Sprintf("stream containing %d PipelineInfos", sent), retErr, time.Since(start))
+	}(time.Now())
+	pachClient := a.env.GetPachClient(resp.Context())
+	ctx, err := checkLoggedIn(pachClient)
+	if err != nil {
+		return err
+	}
+	if request.History != 0 {
+		return fmt.Errorf("WatchPipeline doesn't support history")
+	}
+	if request.PageSize != 0 || request.PageToken != "" {
+		return fmt.Errorf("WatchPipeline doesn't support paging")
+	}
+	filter, err := newLabelFilter(request.LabelSelector)
+	if err != nil {
+		return err
+	}
+	var watcher watch.Watcher
+	if request.Pipeline != nil {
+		watcher, err = a.pipelines.ReadOnly(ctx).WatchOne(request.Pipeline.Name)
+	} else {
+		watcher, err = a.pipelines.ReadOnly(ctx).Watch()
+	}
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	for {
+		ev, ok := <-watcher.Watch()
+		if !ok {
+			return fmt.Errorf("the stream for pipeline updates closed unexpectedly")
+		}
+		switch ev.Type {
+		case watch.EventError:
+			return ev.Err
+		case watch.EventPut:
+			var pipelineName string
+			pipelinePtr := &pps.EtcdPipelineInfo{}
+			if err := ev.Unmarshal(&pipelineName, pipelinePtr); err != nil {
+				return err
+			}
+			if ok, err := filter.matchesIndexValues(pipelinePtr.Labels); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			if !matchesPipelineState(request, pipelinePtr) {
+				continue
+			}
+			pipelineInfo, err := ppsutil.GetPipelineInfo(pachClient, pipelinePtr)
 			if err != nil {
+				if isNotFoundErr(err) {
+					continue // the pipeline is being deleted
+				}
+				return err
+			}
+			if err := resp.Send(pipelineInfo); err != nil {
 				return err
 			}
-			return f(pipelineInfo)
+			sent++
+		}
+	}
+}
+
+// listPipeline calls 'f' with each pipeline that matches 'request', and
+// returns the token of the next page of pipelines if request.PageSize is set
+// (and 'f' was called with a full page)
+func (a *apiServer) listPipeline(pachClient *client.APIClient, request *pps.ListPipelineRequest, f func(*pps.PipelineInfo) error) (string, error) {
+	filter, err := newLabelFilter(request.LabelSelector)
+	if err != nil {
+		return "", err
+	}
+	if request.PageSize < 0 {
+		return "", fmt.Errorf("page size cannot be negative")
+	}
+	if (request.PageSize != 0 || request.PageToken != "") && (request.Pipeline != nil || request.History != 0) {
+		return "", fmt.Errorf("paging can't be combined with history or a single pipeline")
+	}
+	cursor, err := col.ParseCursor(request.PageToken)
+	if err != nil {
+		return "", err
+	}
+	opts := &col.Options{Target: col.DefaultOptions.Target, Order: col.DefaultOptions.Order, Cursor: cursor}
+	var sent int64
+	var nextPageToken string
+	_f := func(ptr *pps.EtcdPipelineInfo) error {
+		if !matchesPipelineState(request, ptr) {
+			return nil
+		}
+		pipelineInfo, err := ppsutil.GetPipelineInfo(pachClient, ptr)
+		if err != nil {
+			return err
+		}
+		// Historical versions may have different labels than the current one,
+		// so the labels in the spec are checked (rather than ptr.Labels)
+		if !filter.matches(pipelineInfo.Metadata.GetLabels()) {
+			return nil
+		}
+		if err := f(pipelineInfo); err != nil {
+			return err
+		}
+		sent++
+		if sent == request.PageSize {
+			// 'cursor' points at this pipeline, so the next page starts after it
+			nextPageToken = cursor.Token()
+			return errutil.ErrBreak
+		}
+		return nil
+	}
+	if filter != nil && filter.indexValue != "" && request.Pipeline == nil && request.History == 0 {
+		// Only current versions are returned, so the label index (which has
+		// each pipeline's current labels) holds every match
+		ptr := &pps.EtcdPipelineInfo{}
+		err = a.pipelines.ReadOnly(pachClient.Ctx()).GetByIndex(ppsdb.PipelinesLabelIndex, filter.indexValue, ptr, opts, func(string) error {
+			return _f(ptr)
 		})
+	} else {
+		err = a.listPipelinePtr(pachClient, request.Pipeline, request.History, opts, _f)
+	}
+	if err != nil {
+		return "", err
+	}
+	return nextPageToken, nil
+}
+
+// matchesPipelineState returns true if 'request' doesn't filter pipelines by
+// state, or if 'ptr' is in one of the states it lists
+func matchesPipelineState(request *pps.ListPipelineRequest, ptr *pps.EtcdPipelineInfo) bool {
+	if len(request.State) == 0 {
+		return true
+	}
+	for _, state := range request.State {
+		if ptr.State == state {
+			return true
+		}
+	}
+	return false
 }
 
 // listPipelinePtr enumerates all PPS pipelines in etcd, filters them based on
 // 'request', and then calls 'f' on each value
 func (a *apiServer) listPipelinePtr(pachClient *client.APIClient,
-	pipeline *pps.Pipeline, history int64, f func(*pps.EtcdPipelineInfo) error) error {
+	pipeline *pps.Pipeline, history int64, opts *col.Options, f func(*pps.EtcdPipelineInfo) error) error {
 	p := &pps.EtcdPipelineInfo{}
+	specs := ppsutil.NewSpecStore(pachClient)
 	forEachPipeline := func() error {
-		for i := int64(0); i <= history || history == -1; i++ {
-			if err := f(p); err != nil {
-				return err
-			}
-			ci, err := pachClient.InspectCommit(ppsconsts.SpecRepo, p.SpecCommit.ID)
-			switch {
-			case err != nil:
-				return err
-			case ci.ParentCommit == nil:
-				return nil
-			default:
-				p.SpecCommit = ci.ParentCommit
-			}
-		}
-		return nil // shouldn't happen
+		return specs.ListSpecHistory(p.SpecCommit, history, func(specCommit *pfs.Commit) error {
+			p.SpecCommit = specCommit
+			return f(p)
+		})
 	}
 	if pipeline == nil {
-		if err := a.pipelines.ReadOnly(pachClient.Ctx()).List(p, col.DefaultOptions, func(string) error {
+		if err := a.pipelines.ReadOnly(pachClient.Ctx()).List(p, opts, func(string) error {
 			return forEachPipeline()
 		}); err != nil {
 			return err
@@ -2442,7 +3309,7 @@ func (a *apiServer) listPipelinePtr(pachClient *client.APIClient,
 	} else {
 		if err := a.pipelines.ReadOnly(pachClient.Ctx()).Get(pipeline.Name, p); err != nil {
 			if col.IsErrNotFound(err) {
-				return fmt.Errorf("pipeline \"%s\" not found", pipeline.Name)
+				return newErrPipelineNotFound(pipeline.Name)
 			}
 			return err
 		}
@@ -2904,8 +3771,13 @@ func (a *apiServer) CreateSecret(ctx context.Context, request *pps.CreateSecretR
 	defer func(start time.Time) { metricsFn(start, retErr) }(time.Now())
 
 	var s v1.Secret
-	err := json.Unmarshal(request.GetFile(), &s)
-	if err != nil {
+	if request.Registry != nil {
+		registrySecret, err := newRegistrySecret(request.Registry)
+		if err != nil {
+			return nil, err
+		}
+		s = *registrySecret
+	} else if err := json.Unmarshal(request.GetFile(), &s); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal secret: %v", err)
 	}
 
@@ -2920,7 +3792,7 @@ func (a *apiServer) CreateSecret(ctx context.Context, request *pps.CreateSecretR
 	labels["secret-source"] = "pachyderm-user"
 	s.SetLabels(labels)
 
-	if _, err = a.env.GetKubeClient().CoreV1().Secrets(a.namespace).Create(&s); err != nil {
+	if _, err := a.env.GetKubeClient().CoreV1().Secrets(a.namespace).Create(&s); err != nil {
 		return nil, fmt.Errorf("failed to create secret: %v", err)
 	}
 	return &types.Empty{}, nil
@@ -2963,6 +3835,7 @@ func (a *apiServer) InspectSecret(ctx context.Context, request *pps.InspectSecre
 			Seconds: creationTimestamp.GetSeconds(),
 			Nanos:   creationTimestamp.GetNanos(),
 		},
+		Rotated: secretRotated(secret),
 	}, nil
 }
 
@@ -2994,6 +3867,7 @@ func (a *apiServer) ListSecret(ctx context.Context, in *types.Empty) (response *
 				Seconds: creationTimestamp.GetSeconds(),
 				Nanos:   creationTimestamp.GetNanos(),
 			},
+			Rotated: secretRotated(&s),
 		})
 	}
 
@@ -3002,6 +3876,59 @@ func (a *apiServer) ListSecret(ctx context.Context, in *types.Empty) (response *
 	}, nil
 }
 
+// ListNames implements the protobuf pps.ListNames RPC
+func (a *apiServer) ListNames(ctx context.Context, request *pps.ListNamesRequest) (response *pps.ListNamesResponse, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	pachClient := a.env.GetPachClient(ctx)
+	ctx = pachClient.Ctx() // pachClient will propagate auth info
+
+	response = &pps.ListNamesResponse{}
+	add := func(name string) error {
+		response.Names = append(response.Names, name)
+		if request.Limit > 0 && int64(len(response.Names)) >= request.Limit {
+			return errutil.ErrBreak
+		}
+		return nil
+	}
+	var err error
+	switch request.Kind {
+	case pps.ListNamesRequest_REPO:
+		var repoInfos []*pfs.RepoInfo
+		if repoInfos, err = pachClient.ListRepo(); err == nil {
+			for _, repoInfo := range repoInfos {
+				if err = add(repoInfo.Repo.Name); err != nil {
+					break
+				}
+			}
+		}
+	case pps.ListNamesRequest_BRANCH:
+		if request.Repo == nil {
+			return nil, fmt.Errorf("must specify a repo to list branch names")
+		}
+		var branchInfos []*pfs.BranchInfo
+		if branchInfos, err = pachClient.ListBranch(request.Repo.Name); err == nil {
+			for _, branchInfo := range branchInfos {
+				if err = add(branchInfo.Branch.Name); err != nil {
+					break
+				}
+			}
+		}
+	case pps.ListNamesRequest_PIPELINE:
+		// Read pipeline names from etcd rather than calling ListPipeline, which
+		// reads each pipeline's spec from PFS
+		err = a.pipelines.ReadOnly(ctx).List(&pps.EtcdPipelineInfo{}, col.DefaultOptions, add)
+	case pps.ListNamesRequest_JOB:
+		err = a.jobs.ReadOnly(ctx).List(&pps.EtcdJobInfo{}, col.DefaultOptions, add)
+	default:
+		return nil, fmt.Errorf("unrecognized kind: %v", request.Kind)
+	}
+	if err != nil && err != errutil.ErrBreak {
+		return nil, err
+	}
+	return response, nil
+}
+
 // DeleteAll implements the protobuf pps.DeleteAll RPC
 func (a *apiServer) DeleteAll(ctx context.Context, request *types.Empty) (response *types.Empty, retErr error) {
 	func() { a.Log(request, nil, nil, 0) }()
@@ -3058,8 +3985,9 @@ type ActiveStat struct {
 }
 
 // CollectActiveObjectsAndTags collects all objects/tags that are not deleted
-// or eligible for garbage collection
-func CollectActiveObjectsAndTags(ctx context.Context, pachClient *client.APIClient, repoInfos []*pfs.RepoInfo, pipelineInfos []*pps.PipelineInfo, memoryAllowance int, storageRoot string) (*ActiveStat, error) {
+// or eligible for garbage collection. 'uploads' are the records of open upload
+// sessions, whose chunks aren't in any commit yet.
+func CollectActiveObjectsAndTags(ctx context.Context, pachClient *client.APIClient, repoInfos []*pfs.RepoInfo, pipelineInfos []*pps.PipelineInfo, uploads []*pfs.PutFileRecords, memoryAllowance int, storageRoot string) (*ActiveStat, error) {
 	if memoryAllowance == 0 {
 		memoryAllowance = defaultGCMemory
 	}
@@ -3081,6 +4009,13 @@ func CollectActiveObjectsAndTags(ctx context.Context, pachClient *client.APIClie
 			}
 		}
 	}
+	for _, records := range uploads {
+		for _, record := range records.Records {
+			if record.ObjectHash != "" {
+				addActiveObjects(&pfs.Object{Hash: record.ObjectHash})
+			}
+		}
+	}
 	// A helper function for adding objects that are actually hash trees,
 	// which in turn contain active objects.
 	addActiveTree := func(object *pfs.Object) error {
@@ -3100,6 +4035,36 @@ func CollectActiveObjectsAndTags(ctx context.Context, pachClient *client.APIClie
 		})
 	}
 
+	// A helper function for adding the objects of the files in an open
+	// commit, which aren't in a tree yet
+	var addOpenCommitDir func(commit *pfs.Commit, dir string) error
+	addOpenCommitDir = func(commit *pfs.Commit, dir string) error {
+		files, err := pachClient.PfsAPIClient.ListFileStream(ctx, &pfs.ListFileRequest{
+			File: &pfs.File{Commit: commit, Path: dir},
+			Full: true,
+		})
+		if err != nil {
+			return grpcutil.ScrubGRPC(err)
+		}
+		for fi, err := files.Recv(); err != io.EOF; fi, err = files.Recv() {
+			if err != nil {
+				if err := grpcutil.ScrubGRPC(err); !pfsServer.IsOutputCommitNotFinishedErr(err) {
+					return err
+				}
+				// Open output commits' files are in their jobs' datum trees,
+				// which are tagged
+				return nil
+			}
+			addActiveObjects(fi.Objects...)
+			if fi.FileType == pfs.FileType_DIR {
+				if err := addOpenCommitDir(commit, fi.File.Path); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
 	// Get all commit trees
 	limiter := limit.New(100)
 	var eg errgroup.Group
@@ -3124,6 +4089,9 @@ func CollectActiveObjectsAndTags(ctx context.Context, pachClient *client.APIClie
 				// (bryce) This needs some notion of active blockrefs since these trees do not use objects
 				addActiveObjects(ci.Trees...)
 				addActiveObjects(ci.Datums)
+				if ci.Finished == nil {
+					return addOpenCommitDir(ci.Commit, "/")
+				}
 				return addActiveTree(ci.Tree)
 			})
 		}
@@ -3132,6 +4100,16 @@ func CollectActiveObjectsAndTags(ctx context.Context, pachClient *client.APIClie
 		return nil, err
 	}
 
+	// Jobs' artifacts (see PutArtifact) aren't in any commit
+	if err := pachClient.ListJobF("", nil, nil, -1, false, func(jobInfo *pps.JobInfo) error {
+		for _, artifact := range jobInfo.Artifacts {
+			addActiveObjects(artifact.Object)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
 	eg = errgroup.Group{}
 	for _, pipelineInfo := range pipelineInfos {
 		tags, err := pachClient.ObjectAPIClient.ListTags(pachClient.Ctx(), &pfs.ListTagsRequest{
@@ -3165,124 +4143,6 @@ func CollectActiveObjectsAndTags(ctx context.Context, pachClient *client.APIClie
 	return result, nil
 }
 
-// GarbageCollect implements the protobuf pps.GarbageCollect RPC
-func (a *apiServer) GarbageCollect(ctx context.Context, request *pps.GarbageCollectRequest) (response *pps.GarbageCollectResponse, retErr error) {
-	func() { a.Log(request, nil, nil, 0) }()
-	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
-	pachClient := a.env.GetPachClient(ctx)
-	ctx, err := checkLoggedIn(pachClient)
-	if err != nil {
-		return nil, err
-	}
-	pipelineInfos, err := a.ListPipeline(ctx, &pps.ListPipelineRequest{})
-	if err != nil {
-		return nil, err
-	}
-
-	for _, pi := range pipelineInfos.PipelineInfo {
-		if pi.State != pps.PipelineState_PIPELINE_PAUSED && pi.State != pps.PipelineState_PIPELINE_FAILURE {
-			return nil, fmt.Errorf("all pipelines must be stopped to run garbage collection, pipeline: %s is not", pi.Pipeline.Name)
-		}
-		selector := fmt.Sprintf("pipelineName=%s", pi.Pipeline.Name)
-		pods, err := a.env.GetKubeClient().CoreV1().Pods(a.namespace).List(metav1.ListOptions{LabelSelector: selector})
-		if err != nil {
-			return nil, err
-		}
-		if len(pods.Items) != 0 {
-			return nil, fmt.Errorf("pipeline %s is paused, but still has running workers, this should resolve itself, if it doesn't you can manually delete them with kubectl delete", pi.Pipeline.Name)
-		}
-	}
-	ctx = pachClient.Ctx() // pachClient will propagate auth info
-	pfsClient := pachClient.PfsAPIClient
-	objClient := pachClient.ObjectAPIClient
-
-	// Get all repos
-	repoInfos, err := pfsClient.ListRepo(ctx, &pfs.ListRepoRequest{})
-	if err != nil {
-		return nil, err
-	}
-	specRepoInfo, err := pachClient.InspectRepo(ppsconsts.SpecRepo)
-	if err != nil {
-		return nil, err
-	}
-	activeStat, err := CollectActiveObjectsAndTags(ctx, pachClient, append(repoInfos.RepoInfo, specRepoInfo), pipelineInfos.PipelineInfo, int(request.MemoryBytes), a.storageRoot)
-	if err != nil {
-		return nil, err
-	}
-
-	// Iterate through all objects.  If they are not active, delete them.
-	objects, err := objClient.ListObjects(ctx, &pfs.ListObjectsRequest{})
-	if err != nil {
-		return nil, err
-	}
-
-	var objectsToDelete []*pfs.Object
-	deleteObjectsIfMoreThan := func(n int) error {
-		if len(objectsToDelete) > n {
-			if _, err := objClient.DeleteObjects(ctx, &pfs.DeleteObjectsRequest{
-				Objects: objectsToDelete,
-			}); err != nil {
-				return fmt.Errorf("error deleting objects: %v", err)
-			}
-			objectsToDelete = []*pfs.Object{}
-		}
-		return nil
-	}
-	for oi, err := objects.Recv(); err != io.EOF; oi, err = objects.Recv() {
-		if err != nil {
-			return nil, fmt.Errorf("error receiving objects from ListObjects: %v", err)
-		}
-		if !activeStat.Objects.TestString(oi.Object.Hash) {
-			objectsToDelete = append(objectsToDelete, oi.Object)
-		}
-		// Delete objects in batches
-		if err := deleteObjectsIfMoreThan(100); err != nil {
-			return nil, err
-		}
-	}
-	if err := deleteObjectsIfMoreThan(0); err != nil {
-		return nil, err
-	}
-
-	// Iterate through all tags.  If they are not active, delete them
-	tags, err := objClient.ListTags(ctx, &pfs.ListTagsRequest{})
-	if err != nil {
-		return nil, err
-	}
-	var tagsToDelete []*pfs.Tag
-	deleteTagsIfMoreThan := func(n int) error {
-		if len(tagsToDelete) > n {
-			if _, err := objClient.DeleteTags(ctx, &pfs.DeleteTagsRequest{
-				Tags: tagsToDelete,
-			}); err != nil {
-				return fmt.Errorf("error deleting tags: %v", err)
-			}
-			tagsToDelete = []*pfs.Tag{}
-		}
-		return nil
-	}
-	for resp, err := tags.Recv(); err != io.EOF; resp, err = tags.Recv() {
-		if err != nil {
-			return nil, fmt.Errorf("error receiving tags from ListTags: %v", err)
-		}
-		if !activeStat.Tags.TestString(resp.Tag.Name) {
-			tagsToDelete = append(tagsToDelete, resp.Tag)
-		}
-		if err := deleteTagsIfMoreThan(100); err != nil {
-			return nil, err
-		}
-	}
-	if err := deleteTagsIfMoreThan(0); err != nil {
-		return nil, err
-	}
-
-	if err := a.incrementGCGeneration(ctx); err != nil {
-		return nil, err
-	}
-
-	return &pps.GarbageCollectResponse{}, nil
-}
-
 // ActivateAuth implements the protobuf pps.ActivateAuth RPC
 func (a *apiServer) ActivateAuth(ctx context.Context, req *pps.ActivateAuthRequest) (resp *pps.ActivateAuthResponse, retErr error) {
 	func() { a.Log(req, nil, nil, 0) }()
@@ -3374,11 +4234,11 @@ func (a *apiServer) incrementGCGeneration(ctx context.Context) error {
 }
 
 func isAlreadyExistsErr(err error) bool {
-	return err != nil && strings.Contains(err.Error(), "already exists")
+	return errutil.IsAlreadyExistError(err)
 }
 
 func isNotFoundErr(err error) bool {
-	return err != nil && strings.Contains(err.Error(), "not found")
+	return errutil.IsNotFoundError(err)
 }
 
 func (a *apiServer) updatePipelineSpecCommit(pachClient *client.APIClient, pipelineName string, commit *pfs.Commit) error {
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/metrics"
+
+	"github.com/gogo/protobuf/types"
+)
+
+// validateArtifactName checks that 'name' can be used as the name of an
+// artifact, and as the name of the file that it's downloaded to
+func validateArtifactName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("must specify an artifact name")
+	case strings.ContainsAny(name, "/\\"):
+		return fmt.Errorf("artifact name %q can't contain slashes", name)
+	case name == "." || name == "..":
+		return fmt.Errorf("invalid artifact name %q", name)
+	}
+	return nil
+}
+
+// putArtifact returns 'artifacts', sorted by name, with 'artifact' in place
+// of the artifact with the same name if there is one
+func putArtifact(artifacts []*pps.Artifact, artifact *pps.Artifact) []*pps.Artifact {
+	i := sort.Search(len(artifacts), func(i int) bool {
+		return artifacts[i].Name >= artifact.Name
+	})
+	if i < len(artifacts) && artifacts[i].Name == artifact.Name {
+		artifacts[i] = artifact
+		return artifacts
+	}
+	artifacts = append(artifacts, nil)
+	copy(artifacts[i+1:], artifacts[i:])
+	artifacts[i] = artifact
+	return artifacts
+}
+
+// authorizeArtifactOp checks that the caller has 'scope' on the output repo
+// of 'jobPtr', which is what's required to read (READER) or attach (WRITER)
+// the job's artifacts
+func (a *apiServer) authorizeArtifactOp(pachClient *client.APIClient, jobPtr *pps.EtcdJobInfo, scope auth.Scope) error {
+	repo := jobPtr.OutputCommit.Repo.Name
+	resp, err := pachClient.Authorize(pachClient.Ctx(), &auth.AuthorizeRequest{
+		Repo:  repo,
+		Scope: scope,
+	})
+	if auth.IsErrNotActivated(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !resp.Authorized {
+		me, err := pachClient.WhoAmI(pachClient.Ctx(), &auth.WhoAmIRequest{})
+		if err != nil {
+			return err
+		}
+		return &auth.ErrNotAuthorized{
+			Subject:  me.Username,
+			Repo:     repo,
+			Required: scope,
+		}
+	}
+	return nil
+}
+
+// artifactReader reads the content of an artifact from a PutArtifact stream,
+// starting with the value of the stream's first request
+type artifactReader struct {
+	server pps.API_PutArtifactServer
+	buf    []byte
+}
+
+func (r *artifactReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		request, err := r.server.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = request.Value
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// PutArtifact implements the protobuf pps.PutArtifact RPC
+func (a *apiServer) PutArtifact(server pps.API_PutArtifactServer) (retErr error) {
+	request, err := server.Recv()
+	if err != nil {
+		return err
+	}
+	// Don't log the artifact's content
+	logRequest := &pps.PutArtifactRequest{Job: request.Job, Name: request.Name}
+	func() { a.Log(logRequest, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(logRequest, nil, retErr, time.Since(start)) }(time.Now())
+	metricsFn := metrics.ReportUserAction(server.Context(), a.reporter, "PutArtifact")
+	defer func(start time.Time) { metricsFn(start, retErr) }(time.Now())
+
+	pachClient := a.env.GetPachClient(server.Context())
+	ctx, err := checkLoggedIn(pachClient)
+	if err != nil {
+		return err
+	}
+	if request.Job == nil {
+		return fmt.Errorf("must specify a job")
+	}
+	if err := validateArtifactName(request.Name); err != nil {
+		return err
+	}
+	jobPtr := &pps.EtcdJobInfo{}
+	if err := a.jobs.ReadOnly(ctx).Get(request.Job.ID, jobPtr); err != nil {
+		return err
+	}
+	if err := a.authorizeArtifactOp(pachClient, jobPtr, auth.Scope_WRITER); err != nil {
+		return err
+	}
+
+	object, size, err := pachClient.PutObject(&artifactReader{server: server, buf: request.Value})
+	if err != nil {
+		return err
+	}
+	artifact := &pps.Artifact{
+		Name:      request.Name,
+		Object:    object,
+		SizeBytes: uint64(size),
+		Created:   types.TimestampNow(),
+	}
+	if _, err := col.NewSTM(ctx, a.env.GetEtcdClient(), func(stm col.STM) error {
+		jobPtr := &pps.EtcdJobInfo{}
+		return a.jobs.ReadWrite(stm).Update(request.Job.ID, jobPtr, func() error {
+			jobPtr.Artifacts = putArtifact(jobPtr.Artifacts, artifact)
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+	return server.SendAndClose(&types.Empty{})
+}
+
+// GetArtifact implements the protobuf pps.GetArtifact RPC
+func (a *apiServer) GetArtifact(request *pps.GetArtifactRequest, server pps.API_GetArtifactServer) (retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, nil, retErr, time.Since(start)) }(time.Now())
+	pachClient := a.env.GetPachClient(server.Context())
+	ctx, err := checkLoggedIn(pachClient)
+	if err != nil {
+		return err
+	}
+	if request.Job == nil {
+		return fmt.Errorf("must specify a job")
+	}
+	jobPtr := &pps.EtcdJobInfo{}
+	if err := a.jobs.ReadOnly(ctx).Get(request.Job.ID, jobPtr); err != nil {
+		return err
+	}
+	if err := a.authorizeArtifactOp(pachClient, jobPtr, auth.Scope_READER); err != nil {
+		return err
+	}
+	for _, artifact := range jobPtr.Artifacts {
+		if artifact.Name == request.Name {
+			return pachClient.GetObject(artifact.Object.Hash, grpcutil.NewStreamingBytesWriter(server))
+		}
+	}
+	return fmt.Errorf("job %s has no artifact %q", request.Job.ID, request.Name)
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestPutArtifact(t *testing.T) {
+	artifact := func(name, hash string) *pps.Artifact {
+		return &pps.Artifact{Name: name, Object: &pfs.Object{Hash: hash}}
+	}
+	var artifacts []*pps.Artifact
+	artifacts = putArtifact(artifacts, artifact("metrics.json", "1"))
+	artifacts = putArtifact(artifacts, artifact("confusion.png", "2"))
+	artifacts = putArtifact(artifacts, artifact("model_card.md", "3"))
+	// Putting an artifact with an existing name replaces it
+	artifacts = putArtifact(artifacts, artifact("metrics.json", "4"))
+	require.Equal(t, []*pps.Artifact{
+		artifact("confusion.png", "2"),
+		artifact("metrics.json", "4"),
+		artifact("model_card.md", "3"),
+	}, artifacts)
+}
+
+func TestValidateArtifactName(t *testing.T) {
+	require.NoError(t, validateArtifactName("metrics.json"))
+	require.NoError(t, validateArtifactName("model card v2"))
+	for _, name := range []string{"", ".", "..", "plots/roc.png", `plots\roc.png`} {
+		require.YesError(t, validateArtifactName(name), name)
+	}
+}
@@ -0,0 +1,382 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	prom_model "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
+)
+
+// autoscalingRequestTimeout bounds each request that measures a service's
+// traffic
+const autoscalingRequestTimeout = 5 * time.Second
+
+// autoscaleService scales the workers of the service in 'pipelineInfo' with
+// its traffic (see pps.ServiceAutoscaling), until 'ctx' is cancelled. Each
+// decision is written to the pipeline's EtcdPipelineInfo, and the PPS master
+// applies it to the pipeline's RC when it sees the change.
+func (a *apiServer) autoscaleService(ctx context.Context, pipelineInfo *pps.PipelineInfo) error {
+	autoscaling := pipelineInfo.Service.Autoscaling
+	interval, scaleDownDelay := ppsutil.AutoscalingIntervals(autoscaling)
+	scraper := &serviceScraper{}
+	// Like kubernetes' HPA, the service runs the most workers recommended in
+	// the last 'scaleDownDelay', so it's only scaled down once its traffic has
+	// stayed low
+	var recommendations []replicaRecommendation
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+		pipelinePtr := &pps.EtcdPipelineInfo{}
+		if err := a.pipelines.ReadOnly(ctx).Get(pipelineInfo.Pipeline.Name, pipelinePtr); err != nil {
+			return err
+		}
+		if pipelinePtr.State != pps.PipelineState_PIPELINE_RUNNING {
+			// Don't scale paused (or restarting) services, and measure their
+			// traffic afresh once they're running
+			scraper.reset()
+			recommendations = nil
+			continue
+		}
+		current := ppsutil.ServiceReplicas(autoscaling, pipelinePtr.ServiceAutoscaling)
+		sample, ok, err := a.measureService(ctx, pipelineInfo, scraper)
+		if err != nil {
+			log.Errorf("PPS master: error measuring the traffic of service %q: %v", pipelineInfo.Pipeline.Name, err)
+			continue
+		}
+		if !ok {
+			continue // the first scrape only sets a baseline for the rates
+		}
+		now := time.Now()
+		if recommendations == nil {
+			recommendations = append(recommendations, replicaRecommendation{at: now, replicas: current})
+		}
+		recommendations = append(recommendations, replicaRecommendation{
+			at:       now,
+			replicas: ppsutil.DesiredServiceReplicas(autoscaling, current, sample),
+		})
+		var replicas uint64
+		recommendations, replicas = stabilizeReplicas(recommendations, now.Add(-scaleDownDelay))
+		if replicas == current {
+			continue
+		}
+		log.Infof("PPS master: scaling service %q from %d to %d workers", pipelineInfo.Pipeline.Name, current, replicas)
+		if err := a.setServiceReplicas(ctx, pipelineInfo.Pipeline.Name, replicas, sample, now); err != nil {
+			log.Errorf("PPS master: error scaling service %q: %v", pipelineInfo.Pipeline.Name, err)
+		}
+	}
+}
+
+// replicaRecommendation is the number of workers that a service needed at a
+// point in time
+type replicaRecommendation struct {
+	at       time.Time
+	replicas uint64
+}
+
+// stabilizeReplicas drops the recommendations made before 'since' (keeping
+// the latest) and returns the rest, along with the most workers that they
+// recommend
+func stabilizeReplicas(recommendations []replicaRecommendation, since time.Time) ([]replicaRecommendation, uint64) {
+	for len(recommendations) > 1 && recommendations[0].at.Before(since) {
+		recommendations = recommendations[1:]
+	}
+	var replicas uint64
+	for _, r := range recommendations {
+		if r.replicas > replicas {
+			replicas = r.replicas
+		}
+	}
+	return recommendations, replicas
+}
+
+// setServiceReplicas records that the service 'pipeline' should run
+// 'replicas' workers, because of the traffic in 'sample'
+func (a *apiServer) setServiceReplicas(ctx context.Context, pipeline string, replicas uint64, sample ppsutil.AutoscalingSample, now time.Time) error {
+	updated, err := types.TimestampProto(now)
+	if err != nil {
+		return err
+	}
+	_, err = col.NewSTM(ctx, a.env.GetEtcdClient(), func(stm col.STM) error {
+		pipelines := a.pipelines.ReadWrite(stm)
+		pipelinePtr := &pps.EtcdPipelineInfo{}
+		if err := pipelines.Get(pipeline, pipelinePtr); err != nil {
+			return err
+		}
+		pipelinePtr.ServiceAutoscaling = &pps.ServiceAutoscalingStatus{
+			Replicas:          replicas,
+			RequestsPerSecond: sample.RequestsPerSecond,
+			TriggerValue:      sample.TriggerValue,
+			Updated:           updated,
+		}
+		if sample.Latency > 0 {
+			pipelinePtr.ServiceAutoscaling.Latency = types.DurationProto(sample.Latency)
+		}
+		pipelinePtr.Parallelism = replicas
+		return pipelines.Put(pipeline, pipelinePtr)
+	})
+	return err
+}
+
+// measureService measures the traffic of the service in 'pipelineInfo', with
+// its prometheus trigger or by scraping its workers. It returns false if the
+// traffic can't be measured yet, because 'scraper' has no earlier scrape to
+// compute rates from.
+func (a *apiServer) measureService(ctx context.Context, pipelineInfo *pps.PipelineInfo, scraper *serviceScraper) (ppsutil.AutoscalingSample, bool, error) {
+	autoscaling := pipelineInfo.Service.Autoscaling
+	if trigger := autoscaling.Prometheus; trigger != nil {
+		value, err := queryPrometheus(ctx, trigger)
+		if err != nil {
+			return ppsutil.AutoscalingSample{}, false, err
+		}
+		return ppsutil.AutoscalingSample{TriggerValue: value}, true, nil
+	}
+	pods, err := a.rcPods(ppsutil.PipelineRcName(pipelineInfo.Pipeline.Name, pipelineInfo.Version))
+	if err != nil {
+		return ppsutil.AutoscalingSample{}, false, err
+	}
+	metrics := make(map[string]podMetrics)
+	for _, pod := range pods {
+		if pod.Status.Phase != v1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+		m, err := scrapePod(ctx, metricsURL(pipelineInfo.Service, pod.Status.PodIP), autoscaling)
+		if err != nil {
+			// The pod may not be serving yet, or may be shutting down
+			log.Errorf("PPS master: error scraping metrics from %s: %v", pod.Name, err)
+			continue
+		}
+		metrics[pod.Name] = m
+	}
+	sample, ok := scraper.sample(metrics, time.Now())
+	return sample, ok, nil
+}
+
+// metricsURL returns the URL at which the user code of the service 'service'
+// serves metrics, in the worker pod with IP 'podIP'
+func metricsURL(service *pps.Service, podIP string) string {
+	port := service.Autoscaling.MetricsPort
+	if port == 0 {
+		port = service.InternalPort
+	}
+	path := service.Autoscaling.MetricsPath
+	if path == "" {
+		path = ppsutil.DefaultMetricsPath
+	}
+	return (&url.URL{Scheme: "http", Host: fmt.Sprintf("%s:%d", podIP, port), Path: path}).String()
+}
+
+// podMetrics are the cumulative metrics scraped from one of a service's
+// workers
+type podMetrics struct {
+	requests     float64
+	latencySum   float64
+	latencyCount float64
+}
+
+// scrapePod scrapes the metrics in 'autoscaling' from 'metricsURL'
+func scrapePod(ctx context.Context, metricsURL string, autoscaling *pps.ServiceAutoscaling) (podMetrics, error) {
+	data, err := autoscalingGet(ctx, metricsURL)
+	if err != nil {
+		return podMetrics{}, err
+	}
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(data))
+	if err != nil {
+		return podMetrics{}, fmt.Errorf("could not parse metrics: %v", err)
+	}
+	requestsMetric := autoscaling.RequestsMetric
+	if requestsMetric == "" {
+		requestsMetric = ppsutil.DefaultRequestsMetric
+	}
+	latencyMetric := autoscaling.LatencyMetric
+	if latencyMetric == "" {
+		latencyMetric = ppsutil.DefaultLatencyMetric
+	}
+	var result podMetrics
+	if autoscaling.TargetRequestsPerSecond > 0 {
+		family, ok := families[requestsMetric]
+		if !ok {
+			return podMetrics{}, fmt.Errorf("metric %q not found", requestsMetric)
+		}
+		for _, m := range family.Metric {
+			result.requests += m.GetCounter().GetValue() + m.GetUntyped().GetValue()
+		}
+	}
+	if autoscaling.TargetLatency != nil {
+		family, ok := families[latencyMetric]
+		if !ok {
+			return podMetrics{}, fmt.Errorf("metric %q not found", latencyMetric)
+		}
+		for _, m := range family.Metric {
+			switch family.GetType() {
+			case prom_model.MetricType_HISTOGRAM:
+				result.latencySum += m.GetHistogram().GetSampleSum()
+				result.latencyCount += float64(m.GetHistogram().GetSampleCount())
+			case prom_model.MetricType_SUMMARY:
+				result.latencySum += m.GetSummary().GetSampleSum()
+				result.latencyCount += float64(m.GetSummary().GetSampleCount())
+			default:
+				return podMetrics{}, fmt.Errorf("metric %q must be a histogram or a summary", latencyMetric)
+			}
+		}
+	}
+	return result, nil
+}
+
+// serviceScraper computes rates from successive scrapes of a service's
+// workers
+type serviceScraper struct {
+	last     map[string]podMetrics
+	lastTime time.Time
+}
+
+// sample returns the traffic since the previous scrape, given the metrics
+// 'metrics' (by pod name) scraped at 'now', or false if there was no previous
+// scrape. Pods that weren't in the previous scrape only set a baseline, and
+// counters that went down (because a worker restarted) count from zero.
+func (s *serviceScraper) sample(metrics map[string]podMetrics, now time.Time) (ppsutil.AutoscalingSample, bool) {
+	last, lastTime := s.last, s.lastTime
+	s.last, s.lastTime = metrics, now
+	if last == nil || !now.After(lastTime) {
+		return ppsutil.AutoscalingSample{}, false
+	}
+	var requests, latencySum, latencyCount float64
+	for pod, m := range metrics {
+		prev, ok := last[pod]
+		if !ok {
+			continue
+		}
+		if m.requests < prev.requests || m.latencyCount < prev.latencyCount {
+			prev = podMetrics{}
+		}
+		requests += m.requests - prev.requests
+		latencySum += m.latencySum - prev.latencySum
+		latencyCount += m.latencyCount - prev.latencyCount
+	}
+	sample := ppsutil.AutoscalingSample{RequestsPerSecond: requests / now.Sub(lastTime).Seconds()}
+	if latencyCount > 0 {
+		sample.Latency = time.Duration(latencySum / latencyCount * float64(time.Second))
+	}
+	return sample, true
+}
+
+// reset discards the previous scrape
+func (s *serviceScraper) reset() {
+	s.last = nil
+}
+
+// queryPrometheus returns the result of the query in 'trigger', summing the
+// values of a vector result
+func queryPrometheus(ctx context.Context, trigger *pps.PrometheusTrigger) (float64, error) {
+	u, err := url.Parse(trigger.ServerAddress)
+	if err != nil {
+		return 0, err
+	}
+	u.Path = u.Path + "/api/v1/query"
+	u.RawQuery = url.Values{"query": {trigger.Query}}.Encode()
+	data, err := autoscalingGet(ctx, u.String())
+	if err != nil {
+		return 0, err
+	}
+	return parsePrometheusResult(data)
+}
+
+// parsePrometheusResult parses the response to a Prometheus instant query,
+// which must be a scalar or a vector (whose values are summed)
+func parsePrometheusResult(data []byte) (float64, error) {
+	var response struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			ResultType string          `json:"resultType"`
+			Result     json.RawMessage `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return 0, fmt.Errorf("could not parse prometheus response: %v", err)
+	}
+	if response.Status != "success" {
+		return 0, fmt.Errorf("prometheus query failed: %s", response.Error)
+	}
+	var values [][]interface{}
+	switch response.Data.ResultType {
+	case "scalar":
+		var value []interface{}
+		if err := json.Unmarshal(response.Data.Result, &value); err != nil {
+			return 0, fmt.Errorf("could not parse prometheus scalar: %v", err)
+		}
+		values = append(values, value)
+	case "vector":
+		var vector []struct {
+			Value []interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(response.Data.Result, &vector); err != nil {
+			return 0, fmt.Errorf("could not parse prometheus vector: %v", err)
+		}
+		for _, sample := range vector {
+			values = append(values, sample.Value)
+		}
+	default:
+		return 0, fmt.Errorf("prometheus query must return a scalar or a vector, not a %s", response.Data.ResultType)
+	}
+	var result float64
+	for _, value := range values {
+		// Values are [<timestamp>, "<value>"]
+		if len(value) != 2 {
+			return 0, fmt.Errorf("malformed prometheus value %v", value)
+		}
+		s, ok := value[1].(string)
+		if !ok {
+			return 0, fmt.Errorf("malformed prometheus value %v", value)
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("malformed prometheus value %v: %v", value, err)
+		}
+		result += f
+	}
+	return result, nil
+}
+
+// autoscalingGet returns the body of a GET request to 'u'
+func autoscalingGet(ctx context.Context, u string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, autoscalingRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s: %s", u, resp.Status, bytes.TrimSpace(data))
+	}
+	return data, nil
+}
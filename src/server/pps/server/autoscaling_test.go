@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestScrapePod(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `# TYPE http_requests_total counter
+http_requests_total{code="200"} 90
+http_requests_total{code="500"} 10
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{le="+Inf"} 100
+http_request_duration_seconds_sum 5
+http_request_duration_seconds_count 100
+`)
+	}))
+	defer server.Close()
+
+	m, err := scrapePod(context.Background(), server.URL, &pps.ServiceAutoscaling{
+		MaxReplicas:             4,
+		TargetRequestsPerSecond: 10,
+		TargetLatency:           types.DurationProto(100 * time.Millisecond),
+	})
+	require.NoError(t, err)
+	require.Equal(t, podMetrics{requests: 100, latencySum: 5, latencyCount: 100}, m)
+
+	// Metrics that the service is scaled on must exist
+	_, err = scrapePod(context.Background(), server.URL, &pps.ServiceAutoscaling{
+		MaxReplicas: 4, TargetRequestsPerSecond: 10, RequestsMetric: "requests",
+	})
+	require.YesError(t, err)
+}
+
+func TestServiceScraper(t *testing.T) {
+	start := time.Now()
+	s := &serviceScraper{}
+	_, ok := s.sample(map[string]podMetrics{"a": {requests: 100, latencySum: 5, latencyCount: 100}}, start)
+	require.False(t, ok)
+
+	// "b" is new, so it only sets a baseline
+	sample, ok := s.sample(map[string]podMetrics{
+		"a": {requests: 300, latencySum: 25, latencyCount: 300},
+		"b": {requests: 1000, latencySum: 100, latencyCount: 1000},
+	}, start.Add(10*time.Second))
+	require.True(t, ok)
+	require.Equal(t, 20.0, sample.RequestsPerSecond)
+	require.Equal(t, 100*time.Millisecond, sample.Latency)
+
+	// "a" restarted, so its counters count from zero
+	sample, ok = s.sample(map[string]podMetrics{
+		"a": {requests: 50},
+		"b": {requests: 1150, latencySum: 100, latencyCount: 1000},
+	}, start.Add(20*time.Second))
+	require.True(t, ok)
+	require.Equal(t, 20.0, sample.RequestsPerSecond)
+	require.Equal(t, time.Duration(0), sample.Latency)
+}
+
+func TestStabilizeReplicas(t *testing.T) {
+	start := time.Now()
+	recommendations := []replicaRecommendation{
+		{at: start, replicas: 5},
+		{at: start.Add(time.Minute), replicas: 2},
+		{at: start.Add(2 * time.Minute), replicas: 3},
+	}
+	// The most recent 5 minutes still include the peak
+	recommendations, replicas := stabilizeReplicas(recommendations, start.Add(-3*time.Minute))
+	require.Equal(t, 3, len(recommendations))
+	require.Equal(t, uint64(5), replicas)
+	// Once it's older than that, the service scales down
+	recommendations, replicas = stabilizeReplicas(recommendations, start.Add(30*time.Second))
+	require.Equal(t, 2, len(recommendations))
+	require.Equal(t, uint64(3), replicas)
+	// The latest recommendation is always kept
+	recommendations, replicas = stabilizeReplicas(recommendations, start.Add(time.Hour))
+	require.Equal(t, 1, len(recommendations))
+	require.Equal(t, uint64(3), replicas)
+}
+
+func TestParsePrometheusResult(t *testing.T) {
+	value, err := parsePrometheusResult([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"pod":"a"},"value":[1586000000.1,"12.5"]},
+		{"metric":{"pod":"b"},"value":[1586000000.1,"7.5"]}]}}`))
+	require.NoError(t, err)
+	require.Equal(t, 20.0, value)
+
+	value, err = parsePrometheusResult([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1586000000.1,"3"]}}`))
+	require.NoError(t, err)
+	require.Equal(t, 3.0, value)
+
+	_, err = parsePrometheusResult([]byte(`{"status":"error","error":"parse error"}`))
+	require.YesError(t, err)
+	_, err = parsePrometheusResult([]byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`))
+	require.YesError(t, err)
+}
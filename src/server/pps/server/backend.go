@@ -0,0 +1,86 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// backendVolumeName is the name of the volume in which a pipeline's remote
+// kubeconfig is mounted (at client.PPSBackendMountPath)
+const backendVolumeName = "pach-backend"
+
+// validateBackend returns an error if the execution backend of 'pipelineInfo'
+// is invalid, or if the pipeline can't run on an external backend.
+func validateBackend(pipelineInfo *pps.PipelineInfo) error {
+	backend := pipelineInfo.Backend
+	if backend == nil {
+		return nil
+	}
+	if (backend.AWSBatch == nil) == (backend.Kubernetes == nil) {
+		return fmt.Errorf("exactly one of aws_batch or kubernetes must be set")
+	}
+	if backend.PachdAddress == "" {
+		return fmt.Errorf("pachd_address must be set")
+	}
+	if pipelineInfo.Spout != nil || pipelineInfo.Service != nil {
+		return fmt.Errorf("spouts and services cannot run on an external backend")
+	}
+	if batch := backend.AWSBatch; batch != nil {
+		if batch.Region == "" || batch.JobQueue == "" || batch.JobDefinition == "" {
+			return fmt.Errorf("aws_batch must have a region, job_queue and job_definition")
+		}
+	}
+	if kube := backend.Kubernetes; kube != nil {
+		if kube.KubeconfigSecret == "" {
+			return fmt.Errorf("kubernetes must have a kubeconfig_secret")
+		}
+	}
+	return nil
+}
+
+// backendEnvAndVolumes returns the env vars and volumes that give a pipeline's
+// workers the credentials for its execution backend, if it has one
+func (a *apiServer) backendEnvAndVolumes(backend *pps.ExecutionBackend) ([]v1.EnvVar, []v1.Volume, []v1.VolumeMount) {
+	if backend == nil {
+		return nil, nil, nil
+	}
+	// Remote kubernetes jobs copy the worker binary out of the worker image,
+	// just like the init container of local workers
+	env := []v1.EnvVar{{Name: client.PPSWorkerImageEnv, Value: a.workerImage}}
+	if batch := backend.AWSBatch; batch != nil && batch.CredentialsSecret != "" {
+		for _, key := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"} {
+			env = append(env, v1.EnvVar{
+				Name: key,
+				ValueFrom: &v1.EnvVarSource{
+					SecretKeyRef: &v1.SecretKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{
+							Name: batch.CredentialsSecret,
+						},
+						Key: key,
+					},
+				},
+			})
+		}
+	}
+	var volumes []v1.Volume
+	var volumeMounts []v1.VolumeMount
+	if kube := backend.Kubernetes; kube != nil {
+		volumes = append(volumes, v1.Volume{
+			Name: backendVolumeName,
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName: kube.KubeconfigSecret,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, v1.VolumeMount{
+			Name:      backendVolumeName,
+			MountPath: client.PPSBackendMountPath,
+		})
+	}
+	return env, volumes, volumeMounts
+}
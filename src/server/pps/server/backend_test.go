@@ -0,0 +1,38 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestValidateBackend(t *testing.T) {
+	pipelineInfo := func(backend *pps.ExecutionBackend) *pps.PipelineInfo {
+		return &pps.PipelineInfo{Transform: &pps.Transform{}, Backend: backend}
+	}
+	batch := &pps.AWSBatchBackend{Region: "us-west-2", JobQueue: "queue", JobDefinition: "def"}
+	kube := &pps.KubernetesBackend{KubeconfigSecret: "remote"}
+	require.NoError(t, validateBackend(pipelineInfo(nil)))
+	require.NoError(t, validateBackend(pipelineInfo(&pps.ExecutionBackend{PachdAddress: "pachd:30650", AWSBatch: batch})))
+	require.NoError(t, validateBackend(pipelineInfo(&pps.ExecutionBackend{PachdAddress: "pachd:30650", Kubernetes: kube})))
+
+	// Exactly one backend must be set, along with pachd's address
+	require.YesError(t, validateBackend(pipelineInfo(&pps.ExecutionBackend{PachdAddress: "pachd:30650"})))
+	require.YesError(t, validateBackend(pipelineInfo(&pps.ExecutionBackend{PachdAddress: "pachd:30650", AWSBatch: batch, Kubernetes: kube})))
+	require.YesError(t, validateBackend(pipelineInfo(&pps.ExecutionBackend{AWSBatch: batch})))
+
+	require.YesError(t, validateBackend(pipelineInfo(&pps.ExecutionBackend{
+		PachdAddress: "pachd:30650",
+		AWSBatch:     &pps.AWSBatchBackend{Region: "us-west-2"},
+	})))
+	require.YesError(t, validateBackend(pipelineInfo(&pps.ExecutionBackend{
+		PachdAddress: "pachd:30650",
+		Kubernetes:   &pps.KubernetesBackend{},
+	})))
+
+	// Spouts can't run on an external backend
+	spout := pipelineInfo(&pps.ExecutionBackend{PachdAddress: "pachd:30650", AWSBatch: batch})
+	spout.Spout = &pps.Spout{}
+	require.YesError(t, validateBackend(spout))
+}
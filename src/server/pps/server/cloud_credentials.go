@@ -0,0 +1,177 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
+
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// gcpServiceAccountAnnotation is the annotation with which GKE associates
+	// a kubernetes service account with a GCP service account
+	gcpServiceAccountAnnotation = "iam.gke.io/gcp-service-account"
+	// awsTokenAudience is the audience of the service account tokens that
+	// user code exchanges for AWS credentials
+	awsTokenAudience = "sts.amazonaws.com"
+	// cloudTokenVolumeName is the name of the volume that holds the service
+	// account token of pipelines with AWS credentials
+	cloudTokenVolumeName = "pach-cloud-token"
+
+	defaultTokenExpiration = time.Hour
+	// minTokenExpiration is the shortest expiration that k8s allows for a
+	// projected service account token
+	minTokenExpiration = 10 * time.Minute
+	maxTokenExpiration = 24 * time.Hour
+)
+
+var (
+	awsRoleARNRegex        = regexp.MustCompile(`^arn:aws[a-z-]*:iam::[0-9]{12}:role/[\w+=,.@/-]+$`)
+	gcpServiceAccountRegex = regexp.MustCompile(`^[a-z][a-z0-9-]{4,28}[a-z0-9]@[a-z0-9.-]+\.iam\.gserviceaccount\.com$`)
+	awsRegionRegex         = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-[0-9]+$`)
+)
+
+// validateCloudCredentials returns an error if the cloud_credentials of
+// 'pipelineInfo' are invalid
+func validateCloudCredentials(pipelineInfo *pps.PipelineInfo) error {
+	creds := pipelineInfo.CloudCredentials
+	if creds == nil {
+		return nil
+	}
+	if creds.AWS == nil && creds.GCP == nil {
+		return fmt.Errorf("cloud_credentials must set aws or gcp")
+	}
+	if pipelineInfo.Backend != nil {
+		return fmt.Errorf("cloud_credentials can't be set for pipelines with an external backend, whose user code doesn't run in worker pods")
+	}
+	if aws := creds.AWS; aws != nil {
+		if !awsRoleARNRegex.MatchString(aws.RoleARN) {
+			return fmt.Errorf("invalid aws role_arn %q (it should look like arn:aws:iam::123456789012:role/my-role)", aws.RoleARN)
+		}
+		if aws.Region != "" && !awsRegionRegex.MatchString(aws.Region) {
+			return fmt.Errorf("invalid aws region %q", aws.Region)
+		}
+		if aws.TokenExpiration != nil {
+			expiration, err := types.DurationFromProto(aws.TokenExpiration)
+			if err != nil {
+				return fmt.Errorf("invalid aws token_expiration: %v", err)
+			}
+			if expiration < minTokenExpiration || expiration > maxTokenExpiration {
+				return fmt.Errorf("aws token_expiration must be between %v and %v", minTokenExpiration, maxTokenExpiration)
+			}
+		}
+	}
+	if gcp := creds.GCP; gcp != nil && !gcpServiceAccountRegex.MatchString(gcp.ServiceAccount) {
+		return fmt.Errorf("invalid gcp service_account %q (it should look like name@project.iam.gserviceaccount.com)", gcp.ServiceAccount)
+	}
+	return nil
+}
+
+// workerServiceAccountName returns the name of the kubernetes service
+// account that the workers of the pipeline 'pipelineName' run as, if it has
+// cloud credentials. Like its network policy, a pipeline has one service
+// account across its versions, so that cloud IAM policies that trust it keep
+// working when the pipeline is updated.
+func workerServiceAccountName(pipelineName string) string {
+	return ppsutil.PipelineResourceName(pipelineName)
+}
+
+// workerServiceAccount returns the service account that the workers of
+// 'pipelineInfo' run as, annotated with its GCP service account. It isn't
+// annotated with its AWS role: EKS would then inject AWS credentials into
+// every container in the worker pods, including the storage sidecar, which
+// must keep using pachd's own credentials. Only the user code gets the
+// pipeline's AWS credentials (see cloudTokenVolume).
+func workerServiceAccount(pipelineInfo *pps.PipelineInfo) *v1.ServiceAccount {
+	pipelineName := pipelineInfo.Pipeline.Name
+	annotations := make(map[string]string)
+	if gcp := pipelineInfo.CloudCredentials.GCP; gcp != nil {
+		annotations[gcpServiceAccountAnnotation] = gcp.ServiceAccount
+	}
+	return &v1.ServiceAccount{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ServiceAccount",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: workerServiceAccountName(pipelineName),
+			Labels: map[string]string{
+				"suite":           suite,
+				"component":       "worker",
+				pipelineNameLabel: pipelineName,
+			},
+			Annotations: annotations,
+		},
+	}
+}
+
+// upsertServiceAccount creates or updates the service account of the
+// workers of 'pipelineInfo', if it has cloud credentials. Otherwise it
+// deletes the service account that an earlier version of the pipeline may
+// have had.
+func (a *apiServer) upsertServiceAccount(pipelineInfo *pps.PipelineInfo) error {
+	serviceAccounts := a.env.GetKubeClient().CoreV1().ServiceAccounts(a.namespace)
+	if pipelineInfo.CloudCredentials == nil {
+		err := serviceAccounts.Delete(workerServiceAccountName(pipelineInfo.Pipeline.Name), nil)
+		if err != nil && !isNotFoundErr(err) && !kerrors.IsForbidden(err) {
+			return fmt.Errorf("could not delete service account: %v", err)
+		}
+		return nil
+	}
+	serviceAccount := workerServiceAccount(pipelineInfo)
+	if _, err := serviceAccounts.Create(serviceAccount); err != nil {
+		if !isAlreadyExistsErr(err) {
+			return fmt.Errorf("could not create service account: %v", err)
+		}
+		existing, err := serviceAccounts.Get(serviceAccount.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("could not get service account: %v", err)
+		}
+		existing.Annotations = serviceAccount.Annotations
+		if _, err := serviceAccounts.Update(existing); err != nil {
+			return fmt.Errorf("could not update service account: %v", err)
+		}
+	}
+	return nil
+}
+
+// cloudTokenVolume returns the volume that holds the service account token
+// that the user code of a pipeline with the AWS credentials 'aws' exchanges
+// for temporary credentials, and its mount in the user container. The
+// kubelet refreshes the token before it expires.
+func cloudTokenVolume(aws *pps.AWSCredentials) (v1.Volume, v1.VolumeMount) {
+	expiration := defaultTokenExpiration
+	if aws.TokenExpiration != nil {
+		// validateCloudCredentials has checked that it's valid
+		expiration, _ = types.DurationFromProto(aws.TokenExpiration)
+	}
+	expirationSeconds := int64(expiration / time.Second)
+	volume := v1.Volume{
+		Name: cloudTokenVolumeName,
+		VolumeSource: v1.VolumeSource{
+			Projected: &v1.ProjectedVolumeSource{
+				Sources: []v1.VolumeProjection{{
+					ServiceAccountToken: &v1.ServiceAccountTokenProjection{
+						Audience:          awsTokenAudience,
+						ExpirationSeconds: &expirationSeconds,
+						Path:              client.PPSAWSTokenFile,
+					},
+				}},
+			},
+		},
+	}
+	mount := v1.VolumeMount{
+		Name:      cloudTokenVolumeName,
+		MountPath: client.PPSCloudTokenMountPath,
+		ReadOnly:  true,
+	}
+	return volume, mount
+}
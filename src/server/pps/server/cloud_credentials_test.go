@@ -0,0 +1,75 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestValidateCloudCredentials(t *testing.T) {
+	validate := func(creds *pps.CloudCredentials) error {
+		return validateCloudCredentials(&pps.PipelineInfo{CloudCredentials: creds})
+	}
+	require.NoError(t, validate(nil))
+	require.NoError(t, validate(&pps.CloudCredentials{
+		AWS: &pps.AWSCredentials{
+			RoleARN:         "arn:aws:iam::123456789012:role/pipelines/edges",
+			Region:          "us-west-2",
+			TokenExpiration: types.DurationProto(2 * time.Hour),
+		},
+		GCP: &pps.GCPCredentials{ServiceAccount: "pipeline-edges@my-project.iam.gserviceaccount.com"},
+	}))
+	for _, creds := range []*pps.CloudCredentials{
+		{},
+		{AWS: &pps.AWSCredentials{RoleARN: "edges"}},
+		{AWS: &pps.AWSCredentials{RoleARN: "arn:aws:iam::123456789012:user/edges"}},
+		{AWS: &pps.AWSCredentials{RoleARN: "arn:aws:iam::123456789012:role/edges", Region: "us west"}},
+		{AWS: &pps.AWSCredentials{RoleARN: "arn:aws:iam::123456789012:role/edges", TokenExpiration: types.DurationProto(time.Minute)}},
+		{AWS: &pps.AWSCredentials{RoleARN: "arn:aws:iam::123456789012:role/edges", TokenExpiration: types.DurationProto(48 * time.Hour)}},
+		{GCP: &pps.GCPCredentials{ServiceAccount: "edges@gmail.com"}},
+	} {
+		require.YesError(t, validate(creds))
+	}
+	// User code on external backends doesn't run in worker pods
+	require.YesError(t, validateCloudCredentials(&pps.PipelineInfo{
+		CloudCredentials: &pps.CloudCredentials{GCP: &pps.GCPCredentials{ServiceAccount: "pipeline-edges@my-project.iam.gserviceaccount.com"}},
+		Backend:          &pps.ExecutionBackend{},
+	}))
+}
+
+func TestWorkerServiceAccount(t *testing.T) {
+	pipelineInfo := &pps.PipelineInfo{
+		Pipeline: client.NewPipeline("My_Pipeline"),
+		CloudCredentials: &pps.CloudCredentials{
+			AWS: &pps.AWSCredentials{RoleARN: "arn:aws:iam::123456789012:role/edges"},
+			GCP: &pps.GCPCredentials{ServiceAccount: "pipeline-edges@my-project.iam.gserviceaccount.com"},
+		},
+	}
+	serviceAccount := workerServiceAccount(pipelineInfo)
+	require.Equal(t, workerServiceAccountName("My_Pipeline"), serviceAccount.Name)
+	require.Equal(t, "My_Pipeline", serviceAccount.Labels[pipelineNameLabel])
+	// EKS would inject the AWS role into every container, so it's only
+	// given to the user code
+	require.Equal(t, map[string]string{
+		gcpServiceAccountAnnotation: "pipeline-edges@my-project.iam.gserviceaccount.com",
+	}, serviceAccount.Annotations)
+}
+
+func TestCloudTokenVolume(t *testing.T) {
+	volume, mount := cloudTokenVolume(&pps.AWSCredentials{RoleARN: "arn:aws:iam::123456789012:role/edges"})
+	token := volume.Projected.Sources[0].ServiceAccountToken
+	require.Equal(t, awsTokenAudience, token.Audience)
+	require.Equal(t, int64(3600), *token.ExpirationSeconds)
+	require.Equal(t, client.PPSCloudTokenMountPath, mount.MountPath)
+	require.True(t, mount.ReadOnly)
+
+	volume, _ = cloudTokenVolume(&pps.AWSCredentials{
+		RoleARN:         "arn:aws:iam::123456789012:role/edges",
+		TokenExpiration: types.DurationProto(15 * time.Minute),
+	})
+	require.Equal(t, int64(900), *volume.Projected.Sources[0].ServiceAccountToken.ExpirationSeconds)
+}
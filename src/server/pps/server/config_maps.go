@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// configMapsVolumeName is the name of the projected volume through which
+	// workers read the current values of their pipeline's config map keys
+	configMapsVolumeName = "pach-config-maps"
+	// configMapPollInterval is how often the PPS master checks whether the
+	// config map keys that pipelines reprocess on have changed
+	configMapPollInterval = 30 * time.Second
+)
+
+// validateConfigMapKeys returns an error if the config map keys of
+// 'transform' are incomplete, or set the same env var as each other or as
+// its env or secrets. That the keys exist is checked by configMapsHash.
+func validateConfigMapKeys(transform *pps.Transform) error {
+	envVars := make(map[string]bool)
+	for name := range transform.Env {
+		envVars[name] = true
+	}
+	for _, secret := range transform.Secrets {
+		if secret.EnvVar != "" {
+			envVars[secret.EnvVar] = true
+		}
+	}
+	for _, key := range transform.ConfigMaps {
+		if key.Name == "" || key.Key == "" || key.EnvVar == "" {
+			return fmt.Errorf("config map keys must have a name, key and env_var")
+		}
+		if strings.Contains(key.EnvVar, "=") {
+			return fmt.Errorf("invalid env_var %q", key.EnvVar)
+		}
+		if envVars[key.EnvVar] {
+			return fmt.Errorf("env var %q is set more than once", key.EnvVar)
+		}
+		envVars[key.EnvVar] = true
+	}
+	return nil
+}
+
+// configMapsHash returns the hash of the current values of the config map
+// keys of 'transform' that have 'reprocess' set, or "" if none do. It returns
+// an error if any of its config map keys doesn't exist, so that a pipeline
+// that references a missing key fails at creation rather than when its
+// workers start.
+func (a *apiServer) configMapsHash(transform *pps.Transform) (string, error) {
+	if transform == nil || len(transform.ConfigMaps) == 0 {
+		return "", nil
+	}
+	configMaps := make(map[string]*v1.ConfigMap)
+	var values []string
+	for _, key := range transform.ConfigMaps {
+		configMap, ok := configMaps[key.Name]
+		if !ok {
+			var err error
+			configMap, err = a.env.GetKubeClient().CoreV1().ConfigMaps(a.namespace).Get(key.Name, metav1.GetOptions{})
+			if err != nil {
+				return "", fmt.Errorf("could not get config map %q: %v", key.Name, err)
+			}
+			configMaps[key.Name] = configMap
+		}
+		value, ok := configMap.Data[key.Key]
+		if !ok {
+			binaryValue, ok := configMap.BinaryData[key.Key]
+			if !ok {
+				return "", fmt.Errorf("config map %q has no key %q", key.Name, key.Key)
+			}
+			value = string(binaryValue)
+		}
+		if key.Reprocess {
+			values = append(values, fmt.Sprintf("%s/%s=%s", key.Name, key.Key, value))
+		}
+	}
+	if len(values) == 0 {
+		return "", nil
+	}
+	sort.Strings(values)
+	hash := sha256.New()
+	for _, value := range values {
+		hash.Write([]byte(value))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// configMapsVolume returns the projected volume (and its mount) that contains
+// each config map key in 'keys', at <config map name>/<key>. Kubernetes
+// updates the volume when the config maps change, so the worker reads the
+// keys' env vars from it before running the user code (see secretsVolume).
+// It returns nil if there are no keys.
+func configMapsVolume(keys []*pps.ConfigMapKey) (*v1.Volume, *v1.VolumeMount) {
+	items := make(map[string][]v1.KeyToPath)
+	var names []string
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		p := path.Join(key.Name, key.Key)
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		if _, ok := items[key.Name]; !ok {
+			names = append(names, key.Name)
+		}
+		items[key.Name] = append(items[key.Name], v1.KeyToPath{Key: key.Key, Path: p})
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	sort.Strings(names)
+	var sources []v1.VolumeProjection
+	for _, name := range names {
+		sources = append(sources, v1.VolumeProjection{
+			ConfigMap: &v1.ConfigMapProjection{
+				LocalObjectReference: v1.LocalObjectReference{Name: name},
+				Items:                items[name],
+			},
+		})
+	}
+	return &v1.Volume{
+		Name: configMapsVolumeName,
+		VolumeSource: v1.VolumeSource{
+			Projected: &v1.ProjectedVolumeSource{Sources: sources},
+		},
+	}, &v1.VolumeMount{
+		Name:      configMapsVolumeName,
+		MountPath: client.PPSConfigMapsMountPath,
+		ReadOnly:  true,
+	}
+}
+
+// reprocessOnConfigMapChanges updates (with reprocess) each pipeline whose
+// config map keys with 'reprocess' set have changed since its current
+// version was created, until 'ctx' is done. It runs in the PPS master, and
+// polls rather than watching config maps, as they change rarely and a
+// pipeline's keys may be spread across several of them.
+func (a *apiServer) reprocessOnConfigMapChanges(ctx context.Context, pachClient *client.APIClient) {
+	ticker := time.NewTicker(configMapPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		pipelineInfos, err := pachClient.ListPipeline()
+		if err != nil {
+			log.Errorf("PPS master: error listing pipelines to check their config maps: %v", err)
+			continue
+		}
+		for _, pipelineInfo := range pipelineInfos {
+			if !reprocessesOnConfigMaps(pipelineInfo.Transform) {
+				continue
+			}
+			hash, err := a.configMapsHash(pipelineInfo.Transform)
+			if err != nil {
+				log.Errorf("PPS master: error checking the config maps of pipeline %q: %v", pipelineInfo.Pipeline.Name, err)
+				continue
+			}
+			if hash == pipelineInfo.ConfigMapsHash {
+				continue
+			}
+			log.Infof("PPS master: config maps of pipeline %q changed, updating it with reprocess", pipelineInfo.Pipeline.Name)
+			request := ppsutil.PipelineReqFromInfo(pipelineInfo)
+			request.Update = true
+			request.Reprocess = true
+			if _, err := pachClient.PpsAPIClient.CreatePipeline(pachClient.Ctx(), request); err != nil {
+				log.Errorf("PPS master: error updating pipeline %q after its config maps changed: %v", pipelineInfo.Pipeline.Name, err)
+			}
+		}
+	}
+}
+
+// reprocessesOnConfigMaps returns true if any of the config map keys of
+// 'transform' have 'reprocess' set
+func reprocessesOnConfigMaps(transform *pps.Transform) bool {
+	if transform == nil {
+		return false
+	}
+	for _, key := range transform.ConfigMaps {
+		if key.Reprocess {
+			return true
+		}
+	}
+	return false
+}
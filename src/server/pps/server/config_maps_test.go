@@ -0,0 +1,53 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestValidateConfigMapKeys(t *testing.T) {
+	transform := &pps.Transform{
+		Env:     map[string]string{"MODE": "fast"},
+		Secrets: []*pps.SecretMount{{Name: "db", Key: "password", EnvVar: "DB_PASSWORD"}},
+		ConfigMaps: []*pps.ConfigMapKey{
+			{Name: "tunables", Key: "batch-size", EnvVar: "BATCH_SIZE"},
+			{Name: "tunables", Key: "threshold", EnvVar: "THRESHOLD", Reprocess: true},
+		},
+	}
+	require.NoError(t, validateConfigMapKeys(transform))
+	require.True(t, reprocessesOnConfigMaps(transform))
+
+	for _, key := range []*pps.ConfigMapKey{
+		{Name: "tunables", Key: "batch-size"},
+		{Name: "tunables", Key: "mode", EnvVar: "MODE"},
+		{Name: "tunables", Key: "password", EnvVar: "DB_PASSWORD"},
+		{Name: "tunables", Key: "other", EnvVar: "BATCH_SIZE"},
+	} {
+		invalid := *transform
+		invalid.ConfigMaps = append([]*pps.ConfigMapKey{key}, transform.ConfigMaps...)
+		require.YesError(t, validateConfigMapKeys(&invalid))
+	}
+}
+
+func TestConfigMapsVolume(t *testing.T) {
+	volume, mount := configMapsVolume(nil)
+	require.Nil(t, volume)
+	require.Nil(t, mount)
+
+	volume, mount = configMapsVolume([]*pps.ConfigMapKey{
+		{Name: "tunables", Key: "threshold", EnvVar: "THRESHOLD"},
+		{Name: "limits", Key: "rate", EnvVar: "RATE"},
+		{Name: "limits", Key: "rate", EnvVar: "RATE_LIMIT"},
+	})
+	require.Equal(t, client.PPSConfigMapsMountPath, mount.MountPath)
+	require.True(t, mount.ReadOnly)
+	sources := volume.Projected.Sources
+	require.Equal(t, 2, len(sources))
+	require.Equal(t, "limits", sources[0].ConfigMap.Name)
+	require.Equal(t, 1, len(sources[0].ConfigMap.Items))
+	require.Equal(t, "limits/rate", sources[0].ConfigMap.Items[0].Path)
+	require.Equal(t, "tunables", sources[1].ConfigMap.Name)
+}
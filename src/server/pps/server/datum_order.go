@@ -0,0 +1,47 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// validateDatumOrder returns an error if 'pipelineInfo' sets a datum_order
+// that its workers can't follow
+func validateDatumOrder(pipelineInfo *pps.PipelineInfo) error {
+	order := pipelineInfo.DatumOrder
+	if order == nil {
+		return nil
+	}
+	if pipelineInfo.Service != nil || pipelineInfo.Spout != nil {
+		return fmt.Errorf("datum_order can't be set for services or spouts, which don't process datums")
+	}
+	if order.By != pps.DatumOrderBy_DATUM_ORDER_COST && order.Cost != pps.DatumCost_DATUM_COST_SIZE {
+		return fmt.Errorf("cost can only be set for %s", pps.DatumOrderBy_DATUM_ORDER_COST)
+	}
+	if _, ok := pps.DatumCost_name[int32(order.Cost)]; !ok {
+		return fmt.Errorf("unrecognized cost %s", order.Cost)
+	}
+	if order.By != pps.DatumOrderBy_DATUM_ORDER_PRIORITY_FILE {
+		if order.PriorityInput != "" || order.PriorityFile != "" {
+			return fmt.Errorf("priority_input and priority_file can only be set for %s", pps.DatumOrderBy_DATUM_ORDER_PRIORITY_FILE)
+		}
+		if _, ok := pps.DatumOrderBy_name[int32(order.By)]; !ok {
+			return fmt.Errorf("unrecognized order %s", order.By)
+		}
+		return nil
+	}
+	if order.PriorityInput == "" || order.PriorityFile == "" {
+		return fmt.Errorf("%s requires priority_input and priority_file", order.By)
+	}
+	var found bool
+	pps.VisitInput(pipelineInfo.Input, func(input *pps.Input) {
+		if input.Pfs != nil && input.Pfs.Name == order.PriorityInput {
+			found = true
+		}
+	})
+	if !found {
+		return fmt.Errorf("priority_input %q isn't the name of one of the pipeline's pfs inputs", order.PriorityInput)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestValidateDatumOrder(t *testing.T) {
+	input := &pps.Input{Cross: []*pps.Input{
+		{Pfs: &pps.PFSInput{Name: "images", Repo: "images", Glob: "/*"}},
+		{Pfs: &pps.PFSInput{Name: "config", Repo: "config", Glob: "/"}},
+	}}
+	require.NoError(t, validateDatumOrder(&pps.PipelineInfo{Input: input}))
+	require.NoError(t, validateDatumOrder(&pps.PipelineInfo{
+		Input:      input,
+		DatumOrder: &pps.DatumOrder{By: pps.DatumOrderBy_DATUM_ORDER_LEXICAL, Reverse: true},
+	}))
+	require.NoError(t, validateDatumOrder(&pps.PipelineInfo{
+		Input: input,
+		DatumOrder: &pps.DatumOrder{
+			By:            pps.DatumOrderBy_DATUM_ORDER_PRIORITY_FILE,
+			PriorityInput: "config",
+			PriorityFile:  "/priorities",
+		},
+	}))
+
+	// priority files need an input to read them from
+	require.YesError(t, validateDatumOrder(&pps.PipelineInfo{
+		Input:      input,
+		DatumOrder: &pps.DatumOrder{By: pps.DatumOrderBy_DATUM_ORDER_PRIORITY_FILE, PriorityFile: "/priorities"},
+	}))
+	require.YesError(t, validateDatumOrder(&pps.PipelineInfo{
+		Input: input,
+		DatumOrder: &pps.DatumOrder{
+			By:            pps.DatumOrderBy_DATUM_ORDER_PRIORITY_FILE,
+			PriorityInput: "labels",
+			PriorityFile:  "/priorities",
+		},
+	}))
+	require.YesError(t, validateDatumOrder(&pps.PipelineInfo{
+		Input:      input,
+		DatumOrder: &pps.DatumOrder{By: pps.DatumOrderBy_DATUM_ORDER_SIZE, PriorityFile: "/priorities"},
+	}))
+	require.NoError(t, validateDatumOrder(&pps.PipelineInfo{
+		Input:      input,
+		DatumOrder: &pps.DatumOrder{By: pps.DatumOrderBy_DATUM_ORDER_COST, Cost: pps.DatumCost_DATUM_COST_DURATION},
+	}))
+	require.YesError(t, validateDatumOrder(&pps.PipelineInfo{
+		Input:      input,
+		DatumOrder: &pps.DatumOrder{By: pps.DatumOrderBy_DATUM_ORDER_SIZE, Cost: pps.DatumCost_DATUM_COST_DURATION},
+	}))
+	require.YesError(t, validateDatumOrder(&pps.PipelineInfo{
+		Service:    &pps.Service{},
+		DatumOrder: &pps.DatumOrder{By: pps.DatumOrderBy_DATUM_ORDER_SIZE},
+	}))
+}
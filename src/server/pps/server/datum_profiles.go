@@ -0,0 +1,247 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// datumProfileLabel is the label of the RCs that run the workers of a
+// pipeline's datum profiles, whose value is the profile's name. The
+// pipeline's own RC doesn't have it, which is how the PPS master tells them
+// apart.
+const datumProfileLabel = "datumProfile"
+
+// validateDatumProfiles returns an error if the datum profiles of
+// 'pipelineInfo' are invalid
+func validateDatumProfiles(pipelineInfo *pps.PipelineInfo) error {
+	if len(pipelineInfo.DatumProfiles) == 0 {
+		return nil
+	}
+	if pipelineInfo.Service != nil || pipelineInfo.Spout != nil {
+		return fmt.Errorf("services and spouts can't have datum profiles")
+	}
+	if pipelineInfo.Backend != nil {
+		return fmt.Errorf("pipelines with an execution backend can't have datum profiles")
+	}
+	names := make(map[string]bool)
+	sizes := make(map[int64]string)
+	for _, profile := range pipelineInfo.DatumProfiles {
+		if errs := validation.IsDNS1123Label(profile.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid name %q: %s", profile.Name, strings.Join(errs, "; "))
+		}
+		// The profile's name is part of the name of its RC
+		if len(profile.Name) > ppsutil.MaxDatumProfileNameLen {
+			return fmt.Errorf("profile name %q is %d characters long, but must have at most %d",
+				profile.Name, len(profile.Name), ppsutil.MaxDatumProfileNameLen)
+		}
+		if names[profile.Name] {
+			return fmt.Errorf("there's more than one profile named %q", profile.Name)
+		}
+		names[profile.Name] = true
+		if profile.MinSizeBytes <= 0 {
+			return fmt.Errorf("min_size_bytes of profile %q must be positive", profile.Name)
+		}
+		if other, ok := sizes[profile.MinSizeBytes]; ok {
+			return fmt.Errorf("profiles %q and %q have the same min_size_bytes", other, profile.Name)
+		}
+		sizes[profile.MinSizeBytes] = profile.Name
+		if _, _, err := datumProfileResources(profile, pipelineInfo.CacheSize); err != nil {
+			return fmt.Errorf("invalid resources for profile %q: %v", profile.Name, err)
+		}
+	}
+	return nil
+}
+
+// datumProfileResources returns the resource requests and limits of the
+// workers of the datum profile 'profile' (either of which may be nil)
+func datumProfileResources(profile *pps.DatumProfile, cacheSize string) (requests *v1.ResourceList, limits *v1.ResourceList, retErr error) {
+	if profile.ResourceRequests != nil {
+		var err error
+		requests, err = ppsutil.GetRequestsResourceListFromDatumProfile(profile, cacheSize)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not determine resource request: %v", err)
+		}
+	}
+	if profile.ResourceLimits != nil {
+		var err error
+		limits, err = ppsutil.GetLimitsResourceListFromDatumProfile(profile, cacheSize)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not determine resource limit: %v", err)
+		}
+	}
+	return requests, limits, nil
+}
+
+// datumProfileWorkerOptions returns the options for the RC of the datum
+// profile 'profile'. They're computed from scratch, rather than copied from
+// the pipeline RC's options, because workerPodSpec modifies its options.
+func (a *apiServer) datumProfileWorkerOptions(ptr *pps.EtcdPipelineInfo, pipelineInfo *pps.PipelineInfo, profile *pps.DatumProfile) (*workerOptions, error) {
+	options, err := a.getWorkerOptions(ptr, pipelineInfo)
+	if err != nil {
+		return nil, err
+	}
+	requests, limits, err := datumProfileResources(profile, pipelineInfo.CacheSize)
+	if err != nil {
+		return nil, err
+	}
+	options.rcName = ppsutil.DatumProfileRcName(pipelineInfo.Pipeline.Name, pipelineInfo.Version, profile.Name)
+	options.labels = labels(options.rcName)
+	options.labels[pipelineNameLabel] = pipelineInfo.Pipeline.Name
+	options.labels[datumProfileLabel] = profile.Name
+	options.workerEnv = append(options.workerEnv, v1.EnvVar{
+		Name:  client.PPSDatumProfileEnv,
+		Value: profile.Name,
+	})
+	// The profile's GPU (if any) replaces the pipeline's
+	if _, ok := pipelineInfo.Transform.Env[mpsThreadPercentageEnv]; !ok {
+		env := options.workerEnv[:0]
+		for _, e := range options.workerEnv {
+			if e.Name != mpsThreadPercentageEnv {
+				env = append(env, e)
+			}
+		}
+		options.workerEnv = append(env, gpuEnv(profile.ResourceRequests, profile.ResourceLimits)...)
+	}
+	options.resourceRequests = requests
+	options.resourceLimits = limits
+	return options, nil
+}
+
+// rcIsCurrent returns true if 'rc' was created from 'options', i.e. if it has
+// the same pachd version, spec commit and auth token
+func rcIsCurrent(rc *v1.ReplicationController, options *workerOptions) bool {
+	for _, annotation := range []string{pachVersionAnnotation, specCommitAnnotation, hashedAuthTokenAnnotation} {
+		if rc.ObjectMeta.Annotations[annotation] != options.annotations[annotation] {
+			return false
+		}
+	}
+	return true
+}
+
+// createDatumProfileRCs creates the RCs of the datum profiles of
+// 'pipelineInfo' (with no workers, like the pipeline's own RC), and deletes
+// the RCs of its old profiles and versions
+func (a *apiServer) createDatumProfileRCs(ptr *pps.EtcdPipelineInfo, pipelineInfo *pps.PipelineInfo) error {
+	current := make(map[string]*workerOptions)
+	for _, profile := range pipelineInfo.DatumProfiles {
+		options, err := a.datumProfileWorkerOptions(ptr, pipelineInfo, profile)
+		if err != nil {
+			return noValidOptionsErr{err}
+		}
+		current[options.rcName] = options
+	}
+	return a.syncExtraRCs(pipelineInfo.Pipeline.Name, datumProfileLabel, current)
+}
+
+// syncExtraRCs creates the RCs in 'current' (keyed by name) that don't exist
+// yet, and deletes the RCs of the pipeline 'pipelineName' that have the label
+// 'label' but aren't in 'current' or are stale. It manages the RCs that a
+// pipeline has besides its own, such as those of its datum profiles.
+func (a *apiServer) syncExtraRCs(pipelineName string, label string, current map[string]*workerOptions) error {
+	rcs := a.env.GetKubeClient().CoreV1().ReplicationControllers(a.namespace)
+	existing, err := rcs.List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s,%s", pipelineNameLabel, pipelineName, label),
+	})
+	if err != nil {
+		return fmt.Errorf("could not list RCs with label %q: %v", label, err)
+	}
+	var deleted []string
+	for i := range existing.Items {
+		rc := &existing.Items[i]
+		if options, ok := current[rc.Name]; ok && rcIsCurrent(rc, options) {
+			delete(current, rc.Name)
+			continue
+		}
+		if err := rcs.Delete(rc.Name, &metav1.DeleteOptions{OrphanDependents: &falseVal}); err != nil && !isNotFoundErr(err) {
+			return fmt.Errorf("could not delete RC %q: %v", rc.Name, err)
+		}
+		deleted = append(deleted, rc.Name)
+	}
+	for _, options := range current {
+		podSpec, err := a.workerPodSpec(options)
+		if err != nil {
+			return err
+		}
+		if _, err := rcs.Create(workerRc(options, podSpec)); err != nil {
+			if isAlreadyExistsErr(err) && len(deleted) > 0 {
+				// A stale RC with the same name is still being deleted. Return an
+				// error, so that the caller retries.
+				return fmt.Errorf("waiting for stale RCs %v to be deleted", deleted)
+			}
+			if !isAlreadyExistsErr(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scaleDatumProfiles sets the number of workers of each of the datum profiles
+// of op's pipeline to the profile's parallelism if 'up' is true, and to zero
+// otherwise.
+//
+// Like other functions in pipeline_controller.go, it takes responsibility for
+// failing op's pipeline if it can't update the profiles' RCs
+func (op *pipelineOp) scaleDatumProfiles(up bool) error {
+	kubeClient := op.apiServer.env.GetKubeClient()
+	for _, profile := range op.pipelineInfo.DatumProfiles {
+		replicas := int32(0)
+		if up {
+			parallelism, err := ppsutil.GetExpectedNumWorkers(op.pachClient.Ctx(), kubeClient, profile.ParallelismSpec)
+			if err != nil {
+				log.Errorf("PPS master: error getting number of workers for datum profile %q of %q (defaulting to 1 worker): %v", profile.Name, op.name, err)
+				parallelism = 1
+			}
+			replicas = int32(parallelism)
+		}
+		name := ppsutil.DatumProfileRcName(op.name, op.pipelineInfo.Version, profile.Name)
+		if err := op.scaleExtraRC(name, replicas, fmt.Sprintf("datum profile %q", profile.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scaleExtraRC sets the number of workers of the RC 'name', which is one of
+// the RCs that op's pipeline has besides its own (see syncExtraRCs), to
+// 'replicas'. 'what' describes the RC's workers in errors.
+//
+// Like scaleDatumProfiles, it takes responsibility for failing op's pipeline
+// if it can't update the RC
+func (op *pipelineOp) scaleExtraRC(name string, replicas int32, what string) error {
+	rcs := op.apiServer.env.GetKubeClient().CoreV1().ReplicationControllers(op.apiServer.namespace)
+	var errCount int
+	if err := backoff.RetryNotify(func() error {
+		rc, err := rcs.Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if rc.Spec.Replicas != nil && *rc.Spec.Replicas == replicas {
+			return nil
+		}
+		rc.Spec.Replicas = &replicas
+		_, err = rcs.Update(rc)
+		return err
+	}, backoff.NewInfiniteBackOff(), func(err error, d time.Duration) error {
+		if errCount++; errCount >= maxErrCount {
+			return err
+		}
+		log.Errorf("PPS master: error updating RC %q: %v; retrying in %v", name, err, d)
+		return nil
+	}); err != nil {
+		return op.failPipeline(fmt.Sprintf("failed to update RC of %s after %d attempts: %v",
+			what, errCount, err))
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
+)
+
+func TestValidateDatumProfiles(t *testing.T) {
+	pipelineInfo := func(profiles ...*pps.DatumProfile) *pps.PipelineInfo {
+		return &pps.PipelineInfo{DatumProfiles: profiles}
+	}
+	require.NoError(t, validateDatumProfiles(pipelineInfo()))
+	require.NoError(t, validateDatumProfiles(pipelineInfo(
+		&pps.DatumProfile{Name: "large", MinSizeBytes: 1 << 30, ResourceRequests: &pps.ResourceSpec{Memory: "16G"}},
+		&pps.DatumProfile{Name: "medium", MinSizeBytes: 1 << 20},
+	)))
+	require.YesError(t, validateDatumProfiles(pipelineInfo(&pps.DatumProfile{Name: "Large", MinSizeBytes: 1})))
+	require.YesError(t, validateDatumProfiles(pipelineInfo(&pps.DatumProfile{Name: "large"})))
+	// The profile's name has to fit in its RC's name
+	long := strings.Repeat("a", ppsutil.MaxDatumProfileNameLen)
+	require.NoError(t, validateDatumProfiles(pipelineInfo(&pps.DatumProfile{Name: long, MinSizeBytes: 1})))
+	require.YesError(t, validateDatumProfiles(pipelineInfo(&pps.DatumProfile{Name: long + "a", MinSizeBytes: 1})))
+	require.YesError(t, validateDatumProfiles(pipelineInfo(
+		&pps.DatumProfile{Name: "large", MinSizeBytes: 2},
+		&pps.DatumProfile{Name: "large", MinSizeBytes: 1},
+	)))
+	require.YesError(t, validateDatumProfiles(pipelineInfo(
+		&pps.DatumProfile{Name: "large", MinSizeBytes: 1},
+		&pps.DatumProfile{Name: "medium", MinSizeBytes: 1},
+	)))
+
+	service := pipelineInfo(&pps.DatumProfile{Name: "large", MinSizeBytes: 1})
+	service.Service = &pps.Service{}
+	require.YesError(t, validateDatumProfiles(service))
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// validateDeterminismCheck returns an error if 'pipelineInfo' sets a
+// determinism_check that's malformed, or that its workers can't run. A checked
+// datum is run twice with the same /pfs, so its inputs must be readable twice
+// and its output must still be in /pfs/out when the datum finishes.
+func validateDeterminismCheck(pipelineInfo *pps.PipelineInfo) error {
+	check := pipelineInfo.DeterminismCheck
+	if check == nil {
+		return nil
+	}
+	if check.Fraction <= 0 || check.Fraction > 1 {
+		return fmt.Errorf("fraction must be greater than 0 and at most 1, but is %v", check.Fraction)
+	}
+	switch {
+	case pipelineInfo.Service != nil || pipelineInfo.Spout != nil:
+		return fmt.Errorf("services and spouts don't process datums")
+	case pipelineInfo.Backend != nil:
+		return fmt.Errorf("pipelines with an execution backend can't be checked")
+	case pipelineInfo.S3Out:
+		return fmt.Errorf("pipelines with s3_out can't be checked, as they don't write their output to /pfs/out")
+	case pipelineInfo.StreamOutput:
+		return fmt.Errorf("pipelines that stream their output can't be checked")
+	}
+	var lazyInput string
+	pps.VisitInput(pipelineInfo.Input, func(input *pps.Input) {
+		if input.Pfs != nil && input.Pfs.Lazy && lazyInput == "" {
+			lazyInput = input.Pfs.Name
+		}
+	})
+	if lazyInput != "" {
+		return fmt.Errorf("input %q is lazy, so it can't be read twice", lazyInput)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestValidateDeterminismCheck(t *testing.T) {
+	input := &pps.Input{Cross: []*pps.Input{
+		{Pfs: &pps.PFSInput{Name: "images", Repo: "images", Glob: "/*"}},
+		{Pfs: &pps.PFSInput{Name: "config", Repo: "config", Glob: "/"}},
+	}}
+	check := &pps.DeterminismCheck{Fraction: 0.1}
+	require.NoError(t, validateDeterminismCheck(&pps.PipelineInfo{Input: input}))
+	require.NoError(t, validateDeterminismCheck(&pps.PipelineInfo{Input: input, DeterminismCheck: check}))
+	require.NoError(t, validateDeterminismCheck(&pps.PipelineInfo{
+		Input:            input,
+		DeterminismCheck: &pps.DeterminismCheck{Fraction: 1},
+	}))
+
+	require.YesError(t, validateDeterminismCheck(&pps.PipelineInfo{
+		Input:            input,
+		DeterminismCheck: &pps.DeterminismCheck{},
+	}))
+	require.YesError(t, validateDeterminismCheck(&pps.PipelineInfo{
+		Input:            input,
+		DeterminismCheck: &pps.DeterminismCheck{Fraction: 1.5},
+	}))
+	require.YesError(t, validateDeterminismCheck(&pps.PipelineInfo{Input: input, DeterminismCheck: check, StreamOutput: true}))
+	require.YesError(t, validateDeterminismCheck(&pps.PipelineInfo{Input: input, DeterminismCheck: check, S3Out: true}))
+	require.YesError(t, validateDeterminismCheck(&pps.PipelineInfo{Input: input, DeterminismCheck: check, Spout: &pps.Spout{}}))
+
+	lazy := &pps.Input{Cross: []*pps.Input{
+		{Pfs: &pps.PFSInput{Name: "images", Repo: "images", Glob: "/*", Lazy: true}},
+		{Pfs: &pps.PFSInput{Name: "config", Repo: "config", Glob: "/"}},
+	}}
+	require.YesError(t, validateDeterminismCheck(&pps.PipelineInfo{Input: lazy, DeterminismCheck: check}))
+}
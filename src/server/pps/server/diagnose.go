@@ -0,0 +1,513 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/client/version"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/metrics"
+	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
+	"golang.org/x/net/context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The names of the checks that Diagnose runs
+const (
+	checkVersionSkew   = "version_skew"
+	checkCrashLoops    = "crash_loops"
+	checkAuthTokens    = "auth_tokens"
+	checkEtcdSpace     = "etcd_space"
+	checkObjectStorage = "object_storage"
+	checkOrphans       = "orphaned_resources"
+	checkStandbyWake   = "standby_wake"
+	checkBudget        = "budget"
+)
+
+const (
+	// crashLoopRestarts is the number of times that a worker container can
+	// restart before Diagnose reports it, even if kubernetes isn't backing
+	// off from restarting it
+	crashLoopRestarts = 5
+	// maxPodEvents is the number of a crash-looping pod's most recent
+	// kubernetes events that Diagnose reports
+	maxPodEvents = 5
+	// tokenExpiryWarning is how soon a pipeline's auth token must expire for
+	// Diagnose to report it
+	tokenExpiryWarning = 24 * time.Hour
+	// etcdQuotaBytes is the size that etcd's database can grow to before etcd
+	// stops accepting writes (the --quota-backend-bytes that 'pachctl deploy'
+	// gives etcd)
+	etcdQuotaBytes = 8 << 30
+	// etcdSpaceWarning is the fraction of etcdQuotaBytes that etcd's database
+	// can use before Diagnose reports it
+	etcdSpaceWarning = 0.8
+	// objectProbePrefix is the object storage prefix under which Diagnose
+	// writes (and then deletes) its probe objects
+	objectProbePrefix = "pachyderm-doctor"
+)
+
+// sortFindings sorts 'findings' so that the most severe are first, keeping
+// findings of the same severity in the order that they were found
+func sortFindings(findings []*pps.Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity > findings[j].Severity
+	})
+}
+
+// minorVersion returns the major and minor parts of the version 'v', e.g.
+// "1.10" for "1.10.2-abc"
+func minorVersion(v string) string {
+	parts := strings.SplitN(v, ".", 3)
+	if len(parts) < 2 {
+		return v
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// imageTag returns the tag of the docker image 'image'
+func imageTag(image string) string {
+	if i := strings.LastIndex(image, ":"); i >= 0 && !strings.Contains(image[i:], "/") {
+		return image[i+1:]
+	}
+	return "latest"
+}
+
+// versionSkewFindings reports a client whose version ('clientVersion', which
+// may be "") has a different major or minor version than pachd's
+// ('pachdVersion'), and pipelines whose worker 'pods' weren't started with
+// pachd's 'workerImage'
+func versionSkewFindings(clientVersion, pachdVersion, workerImage string, pods []v1.Pod) []*pps.Finding {
+	var result []*pps.Finding
+	if clientVersion != "" && minorVersion(clientVersion) != minorVersion(pachdVersion) {
+		result = append(result, &pps.Finding{
+			Severity: pps.FindingSeverity_FINDING_WARNING,
+			Check:    checkVersionSkew,
+			Summary: fmt.Sprintf("the client's version %s doesn't match pachd's version %s, so some commands may fail",
+				clientVersion, pachdVersion),
+			Remediations: []string{fmt.Sprintf("install pachctl %s", pachdVersion)},
+		})
+	}
+	if err := workerImageSkew(workerImage, pachdVersion); err != nil {
+		result = append(result, &pps.Finding{
+			Severity:     pps.FindingSeverity_FINDING_CRITICAL,
+			Check:        checkVersionSkew,
+			Summary:      "pachd's worker image is from a different release than pachd, so pipelines fail to start",
+			Details:      []string{err.Error()},
+			Remediations: []string{fmt.Sprintf("redeploy pachd with the worker image pachyderm/worker:%s", releaseVersion(pachdVersion))},
+		})
+	}
+	wantTag := imageTag(workerImage)
+	skewed := make(map[string]string) // pipeline -> worker image tag
+	var pipelines []string
+	for _, pod := range pods {
+		pipeline := pod.Labels[pipelineNameLabel]
+		if pipeline == "" || len(pod.Spec.InitContainers) == 0 {
+			continue
+		}
+		tag := imageTag(pod.Spec.InitContainers[0].Image)
+		if tag == wantTag {
+			continue
+		}
+		if _, ok := skewed[pipeline]; !ok {
+			pipelines = append(pipelines, pipeline)
+		}
+		skewed[pipeline] = tag
+	}
+	sort.Strings(pipelines)
+	for _, pipeline := range pipelines {
+		result = append(result, &pps.Finding{
+			Severity: pps.FindingSeverity_FINDING_WARNING,
+			Check:    checkVersionSkew,
+			Summary: fmt.Sprintf("pipeline %s's workers run worker image %s, but pachd's worker image is %s",
+				pipeline, skewed[pipeline], wantTag),
+			Remediations: []string{fmt.Sprintf("pachctl extract pipeline %s | pachctl update pipeline", pipeline)},
+		})
+	}
+	return result
+}
+
+// crashLoopFindings reports the worker 'pods' that are crash looping, along
+// with their most recent kubernetes events (from 'events', which are keyed
+// by pod name)
+func crashLoopFindings(pods []v1.Pod, events map[string][]v1.Event) []*pps.Finding {
+	var result []*pps.Finding
+	for _, pod := range pods {
+		statuses := append(append([]v1.ContainerStatus(nil), pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			backingOff := status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff"
+			if !backingOff && status.RestartCount < crashLoopRestarts {
+				continue
+			}
+			pipeline := pod.Labels[pipelineNameLabel]
+			finding := &pps.Finding{
+				Severity: pps.FindingSeverity_FINDING_WARNING,
+				Check:    checkCrashLoops,
+				Summary: fmt.Sprintf("container %s of pipeline %s's worker %s has restarted %d times",
+					status.Name, pipeline, pod.Name, status.RestartCount),
+				Remediations: []string{
+					fmt.Sprintf("pachctl logs --pipeline %s", pipeline),
+					fmt.Sprintf("kubectl describe pod %s", pod.Name),
+				},
+			}
+			if backingOff {
+				finding.Severity = pps.FindingSeverity_FINDING_CRITICAL
+				finding.Summary += ", and is crash looping"
+			}
+			if terminated := status.LastTerminationState.Terminated; terminated != nil {
+				finding.Details = append(finding.Details, fmt.Sprintf("last exited with code %d (%s)", terminated.ExitCode, terminated.Reason))
+				if terminated.Reason == "OOMKilled" {
+					finding.Remediations = append(finding.Remediations,
+						fmt.Sprintf("raise pipeline %s's resource_limits.memory", pipeline))
+				}
+			}
+			podEvents := events[pod.Name]
+			sort.Slice(podEvents, func(i, j int) bool {
+				return podEvents[i].LastTimestamp.Before(&podEvents[j].LastTimestamp)
+			})
+			if len(podEvents) > maxPodEvents {
+				podEvents = podEvents[len(podEvents)-maxPodEvents:]
+			}
+			for _, event := range podEvents {
+				finding.Details = append(finding.Details, fmt.Sprintf("event %s: %s", event.Reason, event.Message))
+			}
+			result = append(result, finding)
+		}
+	}
+	return result
+}
+
+// authTokenFinding reports pipeline 'pipeline' if its auth token, which
+// WhoAmI says has 'ttl' seconds left (or <= 0 if it doesn't expire), has
+// expired (i.e. WhoAmI failed with 'err') or expires soon. It returns nil if
+// the token is fine.
+func authTokenFinding(pipeline string, ttl int64, err error) *pps.Finding {
+	recreate := fmt.Sprintf("recreate the pipeline to give it a new token, which deletes its output repo: "+
+		"pachctl extract pipeline %s > %s.json && pachctl delete pipeline %s && pachctl create pipeline -f %s.json",
+		pipeline, pipeline, pipeline, pipeline)
+	if auth.IsErrBadToken(err) {
+		return &pps.Finding{
+			Severity:     pps.FindingSeverity_FINDING_CRITICAL,
+			Check:        checkAuthTokens,
+			Summary:      fmt.Sprintf("pipeline %s's auth token has expired or been revoked, so its jobs can't read their inputs", pipeline),
+			Remediations: []string{recreate},
+		}
+	}
+	if expiry := time.Duration(ttl) * time.Second; ttl > 0 && expiry < tokenExpiryWarning {
+		return &pps.Finding{
+			Severity:     pps.FindingSeverity_FINDING_WARNING,
+			Check:        checkAuthTokens,
+			Summary:      fmt.Sprintf("pipeline %s's auth token expires in %v", pipeline, expiry),
+			Remediations: []string{"extend the token with the auth API's ExtendAuthToken", recreate},
+		}
+	}
+	return nil
+}
+
+// standbyWakeFinding reports a standby pipeline whose most recent wake
+// ('wake', which may be nil) took longer than its standby_wake_alarm. It
+// returns nil if the wake was fast enough.
+func standbyWakeFinding(pipeline string, wake *pps.StandbyWake) *pps.Finding {
+	if wake == nil || !wake.AlarmExceeded {
+		return nil
+	}
+	alarm, _ := types.DurationFromProto(wake.Alarm)
+	return &pps.Finding{
+		Severity: pps.FindingSeverity_FINDING_WARNING,
+		Check:    checkStandbyWake,
+		Summary: fmt.Sprintf("pipeline %s took %v to wake from standby for job %s, more than its standby_wake_alarm of %v",
+			pipeline, pps.StandbyWakeLatency(wake), wake.Job.GetID(), alarm),
+		Details: pps.StandbyWakePhases(wake),
+		Remediations: []string{
+			fmt.Sprintf("set pipeline %s's standby_grace_period to keep its workers running between jobs that arrive close together", pipeline),
+			"if starting workers is slow, pre-pull the pipeline's image onto nodes, or check whether the cluster is waiting for nodes to be added",
+		},
+	}
+}
+
+// budgetFinding reports a pipeline (whose etcd record is 'pipelinePtr') that
+// has exceeded its budget at 'now', and so isn't starting new jobs. It returns
+// nil if the pipeline is within its budget.
+func budgetFinding(pipeline string, pipelinePtr *pps.EtcdPipelineInfo, now time.Time) *pps.Finding {
+	reset, exceeded := ppsutil.BudgetExceeded(pipelinePtr, now)
+	if !exceeded {
+		return nil
+	}
+	return &pps.Finding{
+		Severity: pps.FindingSeverity_FINDING_WARNING,
+		Check:    checkBudget,
+		Summary: fmt.Sprintf("pipeline %s's estimated spend this month (%v) exceeds its monthly budget of %v, so it won't start new jobs until %v",
+			pipeline, pipelinePtr.BudgetSpend.Spend, pipelinePtr.Budget.MonthlyLimit, reset.Format(time.RFC3339)),
+		Remediations: []string{
+			fmt.Sprintf("if pipeline %s should keep running, raise its budget's monthly_limit and update it", pipeline),
+			"otherwise, check whether the pipeline's inputs (e.g. a cron input) are triggering more jobs than expected",
+		},
+	}
+}
+
+// etcdSpaceFindings reports etcd's database if it's full (i.e. 'noSpace', if
+// etcd has raised its NOSPACE alarm) or its size ('dbSize') is close to
+// 'quota'
+func etcdSpaceFindings(dbSize, quota int64, noSpace bool) []*pps.Finding {
+	remediations := []string{
+		"delete pipelines and jobs that are no longer needed",
+		"compact and defragment etcd (etcdctl compact <revision> && etcdctl defrag)",
+	}
+	if noSpace {
+		return []*pps.Finding{{
+			Severity: pps.FindingSeverity_FINDING_CRITICAL,
+			Check:    checkEtcdSpace,
+			Summary: fmt.Sprintf("etcd is out of space (its database is %d MiB), and is rejecting writes",
+				dbSize>>20),
+			Remediations: append(remediations, "disarm etcd's NOSPACE alarm once there's room (etcdctl alarm disarm)"),
+		}}
+	}
+	if float64(dbSize) >= etcdSpaceWarning*float64(quota) {
+		return []*pps.Finding{{
+			Severity: pps.FindingSeverity_FINDING_WARNING,
+			Check:    checkEtcdSpace,
+			Summary: fmt.Sprintf("etcd's database is %d MiB, %.0f%% of its %d MiB quota",
+				dbSize>>20, 100*float64(dbSize)/float64(quota), quota>>20),
+			Remediations: remediations,
+		}}
+	}
+	return nil
+}
+
+// probeObjectStorage writes the object 'name' with 'objClient', reads it
+// back and deletes it, and returns an error describing the first step that
+// failed
+func probeObjectStorage(ctx context.Context, objClient obj.Client, name string) error {
+	data := []byte("pachyderm doctor probe")
+	w, err := objClient.Writer(ctx, name)
+	if err != nil {
+		return fmt.Errorf("couldn't write: %v", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return fmt.Errorf("couldn't write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("couldn't write: %v", err)
+	}
+	r, err := objClient.Reader(ctx, name, 0, 0)
+	if err != nil {
+		return fmt.Errorf("couldn't read: %v", err)
+	}
+	read, err := ioutil.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return fmt.Errorf("couldn't read: %v", err)
+	}
+	if !bytes.Equal(read, data) {
+		return fmt.Errorf("read back %d bytes that differ from the %d bytes written", len(read), len(data))
+	}
+	if err := objClient.Delete(ctx, name); err != nil {
+		return fmt.Errorf("couldn't delete: %v", err)
+	}
+	return nil
+}
+
+// workerPods returns all of the cluster's pipeline worker pods
+func (a *apiServer) workerPods() ([]v1.Pod, error) {
+	podList, err := a.env.GetKubeClient().CoreV1().Pods(a.namespace).List(metav1.ListOptions{
+		LabelSelector: metav1.FormatLabelSelector(metav1.SetAsLabelSelector(map[string]string{
+			"suite":     suite,
+			"component": "worker",
+		})),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return podList.Items, nil
+}
+
+func (a *apiServer) diagnoseCrashLoops(pods []v1.Pod) ([]*pps.Finding, error) {
+	eventList, err := a.env.GetKubeClient().CoreV1().Events(a.namespace).List(metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=Pod",
+	})
+	if err != nil {
+		return nil, err
+	}
+	events := make(map[string][]v1.Event)
+	for _, event := range eventList.Items {
+		events[event.InvolvedObject.Name] = append(events[event.InvolvedObject.Name], event)
+	}
+	return crashLoopFindings(pods, events), nil
+}
+
+func (a *apiServer) diagnoseAuthTokens(ctx context.Context) ([]*pps.Finding, error) {
+	pachClient := a.env.GetPachClient(ctx)
+	if _, err := pachClient.WhoAmI(ctx, &auth.WhoAmIRequest{}); auth.IsErrNotActivated(err) {
+		return nil, nil // pipelines don't have tokens
+	}
+	var result []*pps.Finding
+	pipelinePtr := &pps.EtcdPipelineInfo{}
+	if err := a.pipelines.ReadOnly(ctx).List(pipelinePtr, col.DefaultOptions, func(pipeline string) error {
+		if pipelinePtr.AuthToken == "" {
+			return nil
+		}
+		var ttl int64
+		resp, err := pachClient.WithAuthToken(pipelinePtr.AuthToken).WhoAmI(ctx, &auth.WhoAmIRequest{})
+		if err == nil {
+			ttl = resp.TTL
+		} else if !auth.IsErrBadToken(err) {
+			return err
+		}
+		if finding := authTokenFinding(pipeline, ttl, err); finding != nil {
+			result = append(result, finding)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (a *apiServer) diagnoseStandbyWakes(ctx context.Context) ([]*pps.Finding, error) {
+	var result []*pps.Finding
+	pipelinePtr := &pps.EtcdPipelineInfo{}
+	if err := a.pipelines.ReadOnly(ctx).List(pipelinePtr, col.DefaultOptions, func(pipeline string) error {
+		if finding := standbyWakeFinding(pipeline, pipelinePtr.StandbyWake); finding != nil {
+			result = append(result, finding)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (a *apiServer) diagnoseBudgets(ctx context.Context) ([]*pps.Finding, error) {
+	var result []*pps.Finding
+	pipelinePtr := &pps.EtcdPipelineInfo{}
+	now := time.Now()
+	if err := a.pipelines.ReadOnly(ctx).List(pipelinePtr, col.DefaultOptions, func(pipeline string) error {
+		if finding := budgetFinding(pipeline, pipelinePtr, now); finding != nil {
+			result = append(result, finding)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (a *apiServer) diagnoseEtcdSpace(ctx context.Context) ([]*pps.Finding, error) {
+	etcdClient := a.env.GetEtcdClient()
+	alarms, err := etcdClient.AlarmList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var noSpace bool
+	for _, alarm := range alarms.Alarms {
+		if alarm.Alarm == etcdserverpb.AlarmType_NOSPACE {
+			noSpace = true
+		}
+	}
+	var dbSize int64
+	for _, endpoint := range etcdClient.Endpoints() {
+		status, err := etcdClient.Status(ctx, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if status.DbSize > dbSize {
+			dbSize = status.DbSize
+		}
+	}
+	return etcdSpaceFindings(dbSize, etcdQuotaBytes, noSpace), nil
+}
+
+func (a *apiServer) diagnoseObjectStorage(ctx context.Context) ([]*pps.Finding, error) {
+	remediations := []string{
+		"check the credentials in the pachyderm-storage-secret kubernetes secret",
+		"check that those credentials can write, read and delete objects in pachd's bucket",
+	}
+	objClient, err := obj.NewClientFromSecret(a.storageRoot)
+	if err == nil {
+		err = probeObjectStorage(ctx, objClient, path.Join(objectProbePrefix, uuid.NewWithoutDashes()))
+	}
+	if err != nil {
+		return []*pps.Finding{{
+			Severity:     pps.FindingSeverity_FINDING_CRITICAL,
+			Check:        checkObjectStorage,
+			Summary:      fmt.Sprintf("pachd can't use its object storage: %v", err),
+			Remediations: remediations,
+		}}, nil
+	}
+	return nil, nil
+}
+
+// Diagnose implements the protobuf pps.Diagnose RPC
+func (a *apiServer) Diagnose(ctx context.Context, request *pps.DiagnoseRequest) (response *pps.Diagnosis, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	metricsFn := metrics.ReportUserAction(ctx, a.reporter, "Diagnose")
+	defer func(start time.Time) { metricsFn(start, retErr) }(time.Now())
+
+	pachClient := a.env.GetPachClient(ctx)
+	ctx, err := checkLoggedIn(pachClient)
+	if err != nil {
+		return nil, err
+	}
+	pods, podsErr := a.workerPods()
+	checks := []struct {
+		name string
+		run  func() ([]*pps.Finding, error)
+	}{
+		{checkVersionSkew, func() ([]*pps.Finding, error) {
+			if podsErr != nil {
+				return nil, podsErr
+			}
+			return versionSkewFindings(request.ClientVersion, version.PrettyVersion(), a.workerImage, pods), nil
+		}},
+		{checkCrashLoops, func() ([]*pps.Finding, error) {
+			if podsErr != nil {
+				return nil, podsErr
+			}
+			return a.diagnoseCrashLoops(pods)
+		}},
+		{checkAuthTokens, func() ([]*pps.Finding, error) { return a.diagnoseAuthTokens(ctx) }},
+		{checkEtcdSpace, func() ([]*pps.Finding, error) { return a.diagnoseEtcdSpace(ctx) }},
+		{checkObjectStorage, func() ([]*pps.Finding, error) { return a.diagnoseObjectStorage(ctx) }},
+		{checkOrphans, func() ([]*pps.Finding, error) {
+			orphans, err := a.findOrphanedResources(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return orphanFindings(orphans, a.orphanGCDryRun), nil
+		}},
+		{checkStandbyWake, func() ([]*pps.Finding, error) { return a.diagnoseStandbyWakes(ctx) }},
+		{checkBudget, func() ([]*pps.Finding, error) { return a.diagnoseBudgets(ctx) }},
+	}
+	response = &pps.Diagnosis{}
+	for _, check := range checks {
+		response.Checks = append(response.Checks, check.name)
+		findings, err := check.run()
+		if err != nil {
+			// A check that can't run is itself worth reporting, but shouldn't
+			// hide what the other checks find
+			findings = append(findings, &pps.Finding{
+				Severity: pps.FindingSeverity_FINDING_WARNING,
+				Check:    check.name,
+				Summary:  fmt.Sprintf("the %s check couldn't run: %v", check.name, err),
+			})
+		}
+		response.Findings = append(response.Findings, findings...)
+	}
+	sortFindings(response.Findings)
+	return response, nil
+}
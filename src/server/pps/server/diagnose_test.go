@@ -0,0 +1,158 @@
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+	"golang.org/x/net/context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func workerPod(name, pipeline, workerImage string) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{pipelineNameLabel: pipeline},
+		},
+		Spec: v1.PodSpec{
+			InitContainers: []v1.Container{{Name: "init", Image: workerImage}},
+		},
+	}
+}
+
+func TestVersionSkewFindings(t *testing.T) {
+	pods := []v1.Pod{
+		workerPod("a", "edges", "pachyderm/worker:1.10.0"),
+		workerPod("b", "montage", "registry:5000/pachyderm/worker:1.9.3"),
+	}
+	findings := versionSkewFindings("1.10.1", "1.10.0", "pachyderm/worker:1.10.0", pods)
+	require.Equal(t, 1, len(findings))
+	require.Matches(t, "pipeline montage's workers run worker image 1.9.3", findings[0].Summary)
+
+	findings = versionSkewFindings("1.9.0", "1.10.0", "pachyderm/worker:1.10.0", pods[:1])
+	require.Equal(t, 1, len(findings))
+	require.Matches(t, "client's version 1.9.0", findings[0].Summary)
+
+	require.Equal(t, 0, len(versionSkewFindings("", "1.10.0", "pachyderm/worker:1.10.0", nil)))
+
+	findings = versionSkewFindings("", "1.10.0", "pachyderm/worker:1.9.3", nil)
+	require.Equal(t, 1, len(findings))
+	require.Equal(t, pps.FindingSeverity_FINDING_CRITICAL, findings[0].Severity)
+}
+
+func TestCrashLoopFindings(t *testing.T) {
+	pod := workerPod("pipeline-edges-v1-abcde", "edges", "pachyderm/worker:1.10.0")
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{{
+		Name:         "user",
+		RestartCount: 2,
+		State: v1.ContainerState{
+			Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+		},
+		LastTerminationState: v1.ContainerState{
+			Terminated: &v1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"},
+		},
+	}}
+	healthy := workerPod("pipeline-montage-v1-fghij", "montage", "pachyderm/worker:1.10.0")
+	healthy.Status.ContainerStatuses = []v1.ContainerStatus{{Name: "user", RestartCount: 1}}
+
+	now := time.Now()
+	var events []v1.Event
+	for i := 0; i < maxPodEvents+2; i++ {
+		events = append(events, v1.Event{
+			Reason:        "BackOff",
+			Message:       "Back-off restarting failed container",
+			LastTimestamp: metav1.NewTime(now.Add(time.Duration(i) * time.Second)),
+		})
+	}
+	findings := crashLoopFindings([]v1.Pod{pod, healthy}, map[string][]v1.Event{pod.Name: events})
+	require.Equal(t, 1, len(findings))
+	require.Equal(t, pps.FindingSeverity_FINDING_CRITICAL, findings[0].Severity)
+	require.Matches(t, "crash looping", findings[0].Summary)
+	require.Equal(t, "last exited with code 137 (OOMKilled)", findings[0].Details[0])
+	require.Equal(t, 1+maxPodEvents, len(findings[0].Details))
+	require.OneOfEquals(t, "raise pipeline edges's resource_limits.memory", findings[0].Remediations)
+}
+
+func TestAuthTokenFinding(t *testing.T) {
+	require.Nil(t, authTokenFinding("edges", 0, nil))
+	require.Nil(t, authTokenFinding("edges", int64((48*time.Hour).Seconds()), nil))
+	finding := authTokenFinding("edges", int64(time.Hour.Seconds()), nil)
+	require.Equal(t, pps.FindingSeverity_FINDING_WARNING, finding.Severity)
+	finding = authTokenFinding("edges", 0, auth.ErrBadToken)
+	require.Equal(t, pps.FindingSeverity_FINDING_CRITICAL, finding.Severity)
+}
+
+func TestEtcdSpaceFindings(t *testing.T) {
+	require.Equal(t, 0, len(etcdSpaceFindings(1<<30, 8<<30, false)))
+	findings := etcdSpaceFindings(7<<30, 8<<30, false)
+	require.Equal(t, 1, len(findings))
+	require.Equal(t, pps.FindingSeverity_FINDING_WARNING, findings[0].Severity)
+	findings = etcdSpaceFindings(1<<30, 8<<30, true)
+	require.Equal(t, pps.FindingSeverity_FINDING_CRITICAL, findings[0].Severity)
+}
+
+func TestProbeObjectStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "probe")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	objClient, err := obj.NewLocalClient(dir)
+	require.NoError(t, err)
+	require.NoError(t, probeObjectStorage(context.Background(), objClient, "probe"))
+	require.False(t, objClient.Exists(context.Background(), "probe"))
+}
+
+func TestSortFindings(t *testing.T) {
+	findings := []*pps.Finding{
+		{Severity: pps.FindingSeverity_FINDING_WARNING, Summary: "a"},
+		{Severity: pps.FindingSeverity_FINDING_CRITICAL, Summary: "b"},
+		{Severity: pps.FindingSeverity_FINDING_WARNING, Summary: "c"},
+	}
+	sortFindings(findings)
+	require.Equal(t, "b", findings[0].Summary)
+	require.Equal(t, "a", findings[1].Summary)
+	require.Equal(t, "c", findings[2].Summary)
+}
+
+func TestStandbyWakeFinding(t *testing.T) {
+	require.Nil(t, standbyWakeFinding("edges", nil))
+	wake := &pps.StandbyWake{
+		CommitSeen:        &types.Timestamp{Seconds: 1000},
+		WorkersScaled:     &types.Timestamp{Seconds: 1001},
+		WorkersReady:      &types.Timestamp{Seconds: 1200},
+		FirstDatumStarted: &types.Timestamp{Seconds: 1210},
+		Job:               &pps.Job{ID: "job"},
+		Alarm:             types.DurationProto(time.Minute),
+	}
+	require.Nil(t, standbyWakeFinding("edges", wake))
+	wake.AlarmExceeded = true
+	finding := standbyWakeFinding("edges", wake)
+	require.Equal(t, pps.FindingSeverity_FINDING_WARNING, finding.Severity)
+	require.Equal(t, checkStandbyWake, finding.Check)
+	require.Equal(t, 3, len(finding.Details))
+	require.Equal(t, "starting workers took 3m19s", finding.Details[1])
+}
+
+func TestBudgetFinding(t *testing.T) {
+	now := time.Date(2020, time.March, 10, 0, 0, 0, 0, time.UTC)
+	pipelinePtr := &pps.EtcdPipelineInfo{
+		Budget:      &pps.Budget{MonthlyLimit: 100, WorkerHourCost: 1},
+		BudgetSpend: &pps.BudgetSpend{Month: "2020-03", Spend: 50},
+	}
+	require.Nil(t, budgetFinding("cron", pipelinePtr, now))
+	pipelinePtr.BudgetSpend.Spend = 150
+	finding := budgetFinding("cron", pipelinePtr, now)
+	require.Equal(t, pps.FindingSeverity_FINDING_WARNING, finding.Severity)
+	require.Equal(t, checkBudget, finding.Check)
+	// The budget resets at the start of the next month
+	require.Nil(t, budgetFinding("cron", pipelinePtr, now.AddDate(0, 1, 0)))
+	require.Nil(t, budgetFinding("cron", &pps.EtcdPipelineInfo{}, now))
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pps/egressproxy"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// egressProxyContainerName is the name of the container that runs the egress
+// proxy in the worker pods of pipelines with an egress proxy
+const egressProxyContainerName = "egress-proxy"
+
+// validateEgressProxy returns an error if 'egressProxy' is invalid. A proxy
+// with no hosts is valid, and refuses every request.
+func validateEgressProxy(egressProxy *pps.EgressProxy) error {
+	if egressProxy == nil {
+		return nil
+	}
+	for _, host := range egressProxy.Hosts {
+		if err := egressproxy.ValidateHost(host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// egressProxyContainer returns the container that runs 'egressProxy' in a
+// pipeline's worker pods. It runs pachd in egress-proxy mode, which records
+// the requests that it refuses in the audit log.
+func (a *apiServer) egressProxyContainer(egressProxy *pps.EgressProxy, pipelineName string, pullPolicy string) v1.Container {
+	zeroQuantity := resource.MustParse("0")
+	return v1.Container{
+		Name:            egressProxyContainerName,
+		Image:           a.workerSidecarImage,
+		Command:         []string{"/pachd", "--mode", "egress-proxy"},
+		ImagePullPolicy: v1.PullPolicy(pullPolicy),
+		Env: []v1.EnvVar{{
+			Name:  "EGRESS_PROXY_HOSTS",
+			Value: strings.Join(egressProxy.Hosts, ","),
+		}, {
+			Name:  client.PPSPipelineNameEnv,
+			Value: pipelineName,
+		}, {
+			Name: client.PPSPodNameEnv,
+			ValueFrom: &v1.EnvVarSource{
+				FieldRef: &v1.ObjectFieldSelector{
+					APIVersion: "v1",
+					FieldPath:  "metadata.name",
+				},
+			},
+		}},
+		Resources: v1.ResourceRequirements{
+			Requests: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU:    zeroQuantity,
+				v1.ResourceMemory: zeroQuantity,
+			},
+		},
+	}
+}
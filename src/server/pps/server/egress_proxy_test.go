@@ -0,0 +1,30 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestValidateEgressProxy(t *testing.T) {
+	require.NoError(t, validateEgressProxy(nil))
+	require.NoError(t, validateEgressProxy(&pps.EgressProxy{}))
+	require.NoError(t, validateEgressProxy(&pps.EgressProxy{Hosts: []string{"pypi.org", "*.s3.amazonaws.com"}}))
+	require.YesError(t, validateEgressProxy(&pps.EgressProxy{Hosts: []string{"pypi.org", "https://pypi.org"}}))
+}
+
+func TestEgressProxyContainer(t *testing.T) {
+	a := &apiServer{workerSidecarImage: "pachyderm/pachd:1.10.0"}
+	container := a.egressProxyContainer(&pps.EgressProxy{Hosts: []string{"pypi.org", "*.s3.amazonaws.com"}}, "edges", "IfNotPresent")
+	require.Equal(t, egressProxyContainerName, container.Name)
+	require.Equal(t, "pachyderm/pachd:1.10.0", container.Image)
+	require.Equal(t, []string{"/pachd", "--mode", "egress-proxy"}, container.Command)
+	env := make(map[string]string)
+	for _, v := range container.Env {
+		env[v.Name] = v.Value
+	}
+	require.Equal(t, "pypi.org,*.s3.amazonaws.com", env["EGRESS_PROXY_HOSTS"])
+	require.Equal(t, "edges", env[client.PPSPipelineNameEnv])
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// validateEmptyJobPolicy returns an error if 'pipelineInfo' sets an empty job
+// policy that its workers can't follow
+func validateEmptyJobPolicy(pipelineInfo *pps.PipelineInfo) error {
+	switch pipelineInfo.EmptyJobPolicy {
+	case pps.EmptyJobPolicy_EMPTY_JOB_KEEP:
+		return nil
+	case pps.EmptyJobPolicy_EMPTY_JOB_SKIP, pps.EmptyJobPolicy_EMPTY_JOB_ELIDE:
+	default:
+		return fmt.Errorf("unrecognized empty_job_policy %s", pipelineInfo.EmptyJobPolicy)
+	}
+	if pipelineInfo.Service != nil || pipelineInfo.Spout != nil {
+		return fmt.Errorf("empty_job_policy can't be set for services or spouts, which don't process datums")
+	}
+	if pipelineInfo.Backend != nil {
+		return fmt.Errorf("empty_job_policy can't be set for pipelines with an execution backend")
+	}
+	if pipelineInfo.MaxConcurrentJobs > 1 {
+		// A concurrent job's parent commit may not be finished, so whether its
+		// output is unchanged isn't known
+		return fmt.Errorf("empty_job_policy can't be set for pipelines with max_concurrent_jobs")
+	}
+	return nil
+}
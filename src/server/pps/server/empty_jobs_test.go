@@ -0,0 +1,29 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestValidateEmptyJobPolicy(t *testing.T) {
+	require.NoError(t, validateEmptyJobPolicy(&pps.PipelineInfo{}))
+	require.NoError(t, validateEmptyJobPolicy(&pps.PipelineInfo{
+		EmptyJobPolicy: pps.EmptyJobPolicy_EMPTY_JOB_SKIP,
+	}))
+	require.NoError(t, validateEmptyJobPolicy(&pps.PipelineInfo{
+		EmptyJobPolicy: pps.EmptyJobPolicy_EMPTY_JOB_ELIDE,
+	}))
+	// Services have no datums to skip
+	require.NoError(t, validateEmptyJobPolicy(&pps.PipelineInfo{Service: &pps.Service{}}))
+	require.YesError(t, validateEmptyJobPolicy(&pps.PipelineInfo{
+		EmptyJobPolicy: pps.EmptyJobPolicy_EMPTY_JOB_SKIP,
+		Service:        &pps.Service{},
+	}))
+	require.YesError(t, validateEmptyJobPolicy(&pps.PipelineInfo{
+		EmptyJobPolicy:    pps.EmptyJobPolicy_EMPTY_JOB_ELIDE,
+		MaxConcurrentJobs: 2,
+	}))
+	require.YesError(t, validateEmptyJobPolicy(&pps.PipelineInfo{EmptyJobPolicy: 7}))
+}
@@ -0,0 +1,383 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	etcd "github.com/coreos/etcd/clientv3"
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/dlock"
+	"github.com/pachyderm/pachyderm/src/server/pkg/metrics"
+	"github.com/pachyderm/pachyderm/src/server/pkg/objgc"
+	"github.com/pachyderm/pachyderm/src/server/pkg/pfsdb"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
+
+	"golang.org/x/net/context"
+)
+
+const (
+	// gcLockPath is the path (under the PPS etcd prefix) of the lock that the
+	// running garbage collection holds
+	gcLockPath = "_gc_lock"
+	// gcInfoPath is the path (under the PPS etcd prefix) of the
+	// GarbageCollectInfo of the running or last garbage collection
+	gcInfoPath = "_gc_info"
+	// gcBatchSize is the number of objects or tags that GC sweeps at once.
+	// Its progress is saved after each batch.
+	gcBatchSize = 100
+)
+
+// GarbageCollect implements the protobuf pps.GarbageCollect RPC. Garbage
+// collection runs concurrently with writes: the mark phase finds the objects
+// and tags that are referenced by commits, open commits' files, open uploads
+// and datum tags, and the sweep deletes the rest in batches, except for those
+// that were written within the request's grace period and may be about to be
+// referenced (see objgc).
+func (a *apiServer) GarbageCollect(ctx context.Context, request *pps.GarbageCollectRequest) (response *pps.GarbageCollectResponse, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	metricsFn := metrics.ReportUserAction(ctx, a.reporter, "GarbageCollect")
+	defer func(start time.Time) { metricsFn(start, retErr) }(time.Now())
+
+	pachClient := a.env.GetPachClient(ctx)
+	ctx, err := checkLoggedIn(pachClient)
+	if err != nil {
+		return nil, err
+	}
+	gracePeriod, err := gcGracePeriod(request)
+	if err != nil {
+		return nil, err
+	}
+	etcdClient := a.env.GetEtcdClient()
+	lockPath := path.Join(a.etcdPrefix, gcLockPath)
+	holders, err := dlock.ListHolders(ctx, etcdClient, lockPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(holders) > 0 {
+		return nil, fmt.Errorf("garbage collection is already running; run 'pachctl garbage-collect --status' to see its progress")
+	}
+	gcLock := dlock.NewDLock(etcdClient, lockPath)
+	ctx, err = gcLock.Lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer gcLock.Unlock(ctx)
+	pachClient = pachClient.WithCtx(ctx)
+
+	last, err := a.getGCInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info := &pps.GarbageCollectInfo{
+		State:            pps.GarbageCollectState_GC_MARKING,
+		Started:          types.TimestampNow(),
+		ObjectsEstimated: last.ObjectsEstimated,
+	}
+	if last.State == pps.GarbageCollectState_GC_SUCCESS {
+		info.ObjectsEstimated = last.ObjectsScanned
+	}
+	if err := a.putGCInfo(ctx, info); err != nil {
+		return nil, err
+	}
+	defer func() {
+		info.State = pps.GarbageCollectState_GC_SUCCESS
+		if retErr != nil {
+			info.State = pps.GarbageCollectState_GC_FAILURE
+			info.Reason = retErr.Error()
+		}
+		info.Finished = types.TimestampNow()
+		// The request's context may be done, so the result is saved with a
+		// fresh one
+		if err := a.putGCInfo(context.Background(), info); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+
+	// Mark. Open uploads are listed before commits, so that an upload that's
+	// finished during the mark is either still open or already in a commit.
+	uploads, err := a.listUploadRecords(ctx, etcdClient)
+	if err != nil {
+		return nil, err
+	}
+	pipelineInfos, err := a.ListPipeline(ctx, &pps.ListPipelineRequest{})
+	if err != nil {
+		return nil, err
+	}
+	repoInfos, err := pachClient.PfsAPIClient.ListRepo(pachClient.Ctx(), &pfs.ListRepoRequest{})
+	if err != nil {
+		return nil, err
+	}
+	// Delete the commits that branches' retention policies don't keep, so
+	// that their objects are collected below
+	if err := trimHistory(pachClient, repoInfos.RepoInfo); err != nil {
+		return nil, err
+	}
+	specRepoInfo, err := pachClient.InspectRepo(ppsconsts.SpecRepo)
+	if err != nil {
+		return nil, err
+	}
+	activeStat, err := CollectActiveObjectsAndTags(pachClient.Ctx(), pachClient, append(repoInfos.RepoInfo, specRepoInfo), pipelineInfos.PipelineInfo, uploads, int(request.MemoryBytes), a.storageRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sweep
+	info.State = pps.GarbageCollectState_GC_SWEEPING
+	info.SweepStarted = types.TimestampNow()
+	if err := a.putGCInfo(ctx, info); err != nil {
+		return nil, err
+	}
+	collector, err := objgc.NewCollector(ctx, etcdClient)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := collector.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	if err := a.sweepObjects(pachClient, collector, gracePeriod, activeStat, info); err != nil {
+		return nil, err
+	}
+	if err := a.sweepTags(pachClient, collector, gracePeriod, activeStat, info); err != nil {
+		return nil, err
+	}
+	if err := a.incrementGCGeneration(ctx); err != nil {
+		return nil, err
+	}
+	return &pps.GarbageCollectResponse{}, nil
+}
+
+// listUploadRecords returns the records of PFS's open upload sessions, which
+// reference the chunks that have been uploaded so far
+func (a *apiServer) listUploadRecords(ctx context.Context, etcdClient *etcd.Client) ([]*pfs.PutFileRecords, error) {
+	var result []*pfs.PutFileRecords
+	records := &pfs.PutFileRecords{}
+	uploadRecords := pfsdb.UploadRecords(etcdClient, path.Join(a.env.EtcdPrefix, a.env.PFSEtcdPrefix))
+	if err := uploadRecords.ReadOnly(ctx).List(records, col.DefaultOptions, func(string) error {
+		result = append(result, proto.Clone(records).(*pfs.PutFileRecords))
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// gcGracePeriod returns the grace period of the garbage collection 'request'
+func gcGracePeriod(request *pps.GarbageCollectRequest) (time.Duration, error) {
+	if request.GracePeriod == nil {
+		return objgc.YoungTTL, nil
+	}
+	gracePeriod, err := types.DurationFromProto(request.GracePeriod)
+	if err != nil {
+		return 0, err
+	}
+	if gracePeriod < 0 || gracePeriod > objgc.YoungTTL {
+		return 0, fmt.Errorf("the grace period must be between 0 and %v, but it's %v", objgc.YoungTTL, gracePeriod)
+	}
+	return gracePeriod, nil
+}
+
+// sweepObjects deletes the objects that aren't in 'activeStat' and weren't
+// written within 'gracePeriod', updating and saving 'info' as it goes
+func (a *apiServer) sweepObjects(pachClient *client.APIClient, collector *objgc.Collector, gracePeriod time.Duration, activeStat *ActiveStat, info *pps.GarbageCollectInfo) error {
+	ctx := pachClient.Ctx()
+	objects, err := pachClient.ObjectAPIClient.ListObjects(ctx, &pfs.ListObjectsRequest{})
+	if err != nil {
+		return err
+	}
+	batch := make(map[string]*pfs.ObjectInfo)
+	sweep := func() error {
+		var keys []string
+		for key := range batch {
+			keys = append(keys, key)
+		}
+		condemned, err := collector.Condemn(ctx, keys, gracePeriod)
+		if err != nil {
+			return err
+		}
+		if len(condemned) > 0 {
+			var objectsToDelete []*pfs.Object
+			var size uint64
+			for _, key := range condemned {
+				objectsToDelete = append(objectsToDelete, batch[key].Object)
+				size += objectSize(batch[key])
+			}
+			if _, err := pachClient.ObjectAPIClient.DeleteObjects(ctx, &pfs.DeleteObjectsRequest{
+				Objects: objectsToDelete,
+			}); err != nil {
+				return fmt.Errorf("error deleting objects: %v", err)
+			}
+			if err := collector.Release(ctx, condemned); err != nil {
+				return err
+			}
+			info.ObjectsDeleted += int64(len(condemned))
+			info.BytesReclaimed += size
+		}
+		info.ObjectsSpared += int64(len(keys) - len(condemned))
+		batch = make(map[string]*pfs.ObjectInfo)
+		return a.putGCInfo(ctx, info)
+	}
+	for oi, err := objects.Recv(); err != io.EOF; oi, err = objects.Recv() {
+		if err != nil {
+			return fmt.Errorf("error receiving objects from ListObjects: %v", err)
+		}
+		info.ObjectsScanned++
+		if !activeStat.Objects.TestString(oi.Object.Hash) {
+			batch[objgc.ObjectKey(oi.Object)] = oi
+		}
+		if len(batch) >= gcBatchSize || info.ObjectsScanned%gcBatchSize == 0 {
+			if err := sweep(); err != nil {
+				return err
+			}
+		}
+	}
+	return sweep()
+}
+
+// sweepTags deletes the tags that aren't in 'activeStat' and weren't written
+// within 'gracePeriod', updating and saving 'info' as it goes
+func (a *apiServer) sweepTags(pachClient *client.APIClient, collector *objgc.Collector, gracePeriod time.Duration, activeStat *ActiveStat, info *pps.GarbageCollectInfo) error {
+	ctx := pachClient.Ctx()
+	tags, err := pachClient.ObjectAPIClient.ListTags(ctx, &pfs.ListTagsRequest{})
+	if err != nil {
+		return err
+	}
+	batch := make(map[string]*pfs.Tag)
+	sweep := func() error {
+		var keys []string
+		for key := range batch {
+			keys = append(keys, key)
+		}
+		condemned, err := collector.Condemn(ctx, keys, gracePeriod)
+		if err != nil {
+			return err
+		}
+		if len(condemned) > 0 {
+			var tagsToDelete []*pfs.Tag
+			for _, key := range condemned {
+				tagsToDelete = append(tagsToDelete, batch[key])
+			}
+			if _, err := pachClient.ObjectAPIClient.DeleteTags(ctx, &pfs.DeleteTagsRequest{
+				Tags: tagsToDelete,
+			}); err != nil {
+				return fmt.Errorf("error deleting tags: %v", err)
+			}
+			if err := collector.Release(ctx, condemned); err != nil {
+				return err
+			}
+			info.TagsDeleted += int64(len(condemned))
+		}
+		batch = make(map[string]*pfs.Tag)
+		return a.putGCInfo(ctx, info)
+	}
+	for resp, err := tags.Recv(); err != io.EOF; resp, err = tags.Recv() {
+		if err != nil {
+			return fmt.Errorf("error receiving tags from ListTags: %v", err)
+		}
+		if !activeStat.Tags.TestString(resp.Tag.Name) {
+			batch[objgc.TagKey(resp.Tag)] = resp.Tag
+		}
+		if len(batch) >= gcBatchSize {
+			if err := sweep(); err != nil {
+				return err
+			}
+		}
+	}
+	return sweep()
+}
+
+// objectSize returns the number of bytes that 'objectInfo' takes up
+func objectSize(objectInfo *pfs.ObjectInfo) uint64 {
+	if objectInfo.BlockRef == nil || objectInfo.BlockRef.Range == nil {
+		return 0
+	}
+	return objectInfo.BlockRef.Range.Upper - objectInfo.BlockRef.Range.Lower
+}
+
+// InspectGarbageCollect implements the protobuf pps.InspectGarbageCollect RPC
+func (a *apiServer) InspectGarbageCollect(ctx context.Context, request *pps.InspectGarbageCollectRequest) (response *pps.GarbageCollectInfo, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	pachClient := a.env.GetPachClient(ctx)
+	ctx, err := checkLoggedIn(pachClient)
+	if err != nil {
+		return nil, err
+	}
+	info, err := a.getGCInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if info.State != pps.GarbageCollectState_GC_MARKING && info.State != pps.GarbageCollectState_GC_SWEEPING {
+		return info, nil
+	}
+	// A garbage collection that's in progress but doesn't hold the lock was
+	// interrupted (e.g. because its pachd restarted)
+	holders, err := dlock.ListHolders(ctx, a.env.GetEtcdClient(), path.Join(a.etcdPrefix, gcLockPath))
+	if err != nil {
+		return nil, err
+	}
+	if len(holders) == 0 {
+		info.State = pps.GarbageCollectState_GC_FAILURE
+		info.Reason = "garbage collection was interrupted"
+		return info, nil
+	}
+	remaining, err := gcRemaining(info, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if remaining > 0 {
+		info.Remaining = types.DurationProto(remaining)
+	}
+	return info, nil
+}
+
+// gcRemaining estimates how long the sweep described by 'info' will take to
+// finish at 'now', from its progress so far and the number of objects that the
+// last garbage collection scanned. It returns 0 if there's no estimate.
+func gcRemaining(info *pps.GarbageCollectInfo, now time.Time) (time.Duration, error) {
+	if info.State != pps.GarbageCollectState_GC_SWEEPING || info.ObjectsScanned == 0 ||
+		info.ObjectsEstimated <= info.ObjectsScanned {
+		return 0, nil
+	}
+	sweepStarted, err := types.TimestampFromProto(info.SweepStarted)
+	if err != nil {
+		return 0, err
+	}
+	perObject := now.Sub(sweepStarted) / time.Duration(info.ObjectsScanned)
+	return perObject * time.Duration(info.ObjectsEstimated-info.ObjectsScanned), nil
+}
+
+// getGCInfo returns the GarbageCollectInfo of the running or last garbage
+// collection, which is empty if garbage collection has never been run
+func (a *apiServer) getGCInfo(ctx context.Context) (*pps.GarbageCollectInfo, error) {
+	resp, err := a.env.GetEtcdClient().Get(ctx, path.Join(a.etcdPrefix, gcInfoPath))
+	if err != nil {
+		return nil, err
+	}
+	info := &pps.GarbageCollectInfo{}
+	if len(resp.Kvs) > 0 {
+		if err := info.Unmarshal(resp.Kvs[0].Value); err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+func (a *apiServer) putGCInfo(ctx context.Context, info *pps.GarbageCollectInfo) error {
+	data, err := info.Marshal()
+	if err != nil {
+		return err
+	}
+	_, err = a.env.GetEtcdClient().Put(ctx, path.Join(a.etcdPrefix, gcInfoPath), string(data))
+	return err
+}
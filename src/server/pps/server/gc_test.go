@@ -0,0 +1,64 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/objgc"
+)
+
+func TestGCRemaining(t *testing.T) {
+	sweepStarted := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	sweepStartedProto, err := types.TimestampProto(sweepStarted)
+	require.NoError(t, err)
+	info := &pps.GarbageCollectInfo{
+		State:            pps.GarbageCollectState_GC_SWEEPING,
+		SweepStarted:     sweepStartedProto,
+		ObjectsScanned:   100,
+		ObjectsEstimated: 400,
+	}
+	// 100 objects took a minute, so the other 300 should take three
+	remaining, err := gcRemaining(info, sweepStarted.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 3*time.Minute, remaining)
+
+	// There's no estimate once the sweep has scanned more objects than the
+	// last garbage collection did
+	info.ObjectsScanned = 500
+	remaining, err = gcRemaining(info, sweepStarted.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), remaining)
+
+	// ...or while GC is marking
+	info.State = pps.GarbageCollectState_GC_MARKING
+	info.ObjectsScanned = 0
+	remaining, err = gcRemaining(info, sweepStarted.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), remaining)
+}
+
+func TestObjectSize(t *testing.T) {
+	require.Equal(t, uint64(0), objectSize(&pfs.ObjectInfo{}))
+	require.Equal(t, uint64(10), objectSize(&pfs.ObjectInfo{
+		BlockRef: &pfs.BlockRef{Range: &pfs.ByteRange{Lower: 5, Upper: 15}},
+	}))
+}
+
+func TestGCGracePeriod(t *testing.T) {
+	gracePeriod, err := gcGracePeriod(&pps.GarbageCollectRequest{})
+	require.NoError(t, err)
+	require.Equal(t, objgc.YoungTTL, gracePeriod)
+
+	gracePeriod, err = gcGracePeriod(&pps.GarbageCollectRequest{GracePeriod: types.DurationProto(0)})
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), gracePeriod)
+
+	_, err = gcGracePeriod(&pps.GarbageCollectRequest{GracePeriod: types.DurationProto(2 * objgc.YoungTTL)})
+	require.YesError(t, err)
+	_, err = gcGracePeriod(&pps.GarbageCollectRequest{GracePeriod: types.DurationProto(-time.Second)})
+	require.YesError(t, err)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/pps"
 	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/mtls"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsdb"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
 
@@ -58,12 +59,21 @@ func URLFromDomain(domain string) string {
 
 // RunGitHookServer starts the webhook server
 func RunGitHookServer(address string, etcdAddress string, etcdPrefix string) error {
-	c, err := client.NewFromAddress(address)
+	internalTLS, err := mtls.Internal()
+	if err != nil {
+		return err
+	}
+	options, err := mtls.PeerClientOptions()
+	if err != nil {
+		return err
+	}
+	c, err := client.NewFromAddress(address, options...)
 	if err != nil {
 		return err
 	}
 	etcdClient, err := etcd.New(etcd.Config{
 		Endpoints:          []string{etcdAddress},
+		TLS:                internalTLS.ClientTLSConfig(),
 		DialOptions:        client.DefaultDialOptions(),
 		MaxCallSendMsgSize: math.MaxInt32,
 		MaxCallRecvMsgSize: math.MaxInt32,
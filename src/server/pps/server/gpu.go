@@ -0,0 +1,81 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// mpsThreadPercentageEnv limits the share of a GPU's threads that a CUDA
+// process can use when the GPU is shared via NVIDIA MPS
+const mpsThreadPercentageEnv = "CUDA_MPS_ACTIVE_THREAD_PERCENTAGE"
+
+// validateGPU returns an error if 'gpu' (which may be nil) is invalid
+func validateGPU(gpu *pps.GPUSpec) error {
+	if gpu == nil || gpu.Fraction == 0 {
+		return nil
+	}
+	if gpu.Number != 0 {
+		return fmt.Errorf("only one of number and fraction can be set")
+	}
+	if gpu.Fraction < 0 || gpu.Fraction > 1 {
+		return fmt.Errorf("fraction must be greater than 0 and at most 1, but it's %v", gpu.Fraction)
+	}
+	if gpu.SharesPerGpu <= 0 {
+		return fmt.Errorf("shares_per_gpu must be set to a positive number with fraction")
+	}
+	return nil
+}
+
+// validateGPUs returns an error if any of the GPUs requested by
+// 'pipelineInfo' (for its own workers or those of its datum profiles) are
+// invalid
+func validateGPUs(pipelineInfo *pps.PipelineInfo) error {
+	type namedSpec struct {
+		name string
+		spec *pps.ResourceSpec
+	}
+	specs := []namedSpec{
+		{"resource_requests", pipelineInfo.ResourceRequests},
+		{"resource_limits", pipelineInfo.ResourceLimits},
+	}
+	for _, profile := range pipelineInfo.DatumProfiles {
+		specs = append(specs,
+			namedSpec{fmt.Sprintf("resource_requests of profile %q", profile.Name), profile.ResourceRequests},
+			namedSpec{fmt.Sprintf("resource_limits of profile %q", profile.Name), profile.ResourceLimits})
+	}
+	for _, s := range specs {
+		if s.spec == nil {
+			continue
+		}
+		if err := validateGPU(s.spec.Gpu); err != nil {
+			return fmt.Errorf("invalid gpu in %s: %v", s.name, err)
+		}
+	}
+	return nil
+}
+
+// gpuEnv returns the env vars that confine the user code of workers with the
+// resources 'requests' and 'limits' (either of which may be nil) to their
+// fraction of an NVIDIA GPU. It returns nothing if the workers don't request a
+// fraction of a GPU.
+func gpuEnv(requests, limits *pps.ResourceSpec) []v1.EnvVar {
+	var gpu *pps.GPUSpec
+	if limits != nil && limits.Gpu != nil {
+		gpu = limits.Gpu
+	} else if requests != nil && requests.Gpu != nil {
+		gpu = requests.Gpu
+	}
+	if gpu == nil || gpu.Fraction == 0 || !strings.HasPrefix(gpu.Type, "nvidia.com/") {
+		return nil
+	}
+	return []v1.EnvVar{{
+		Name:  mpsThreadPercentageEnv,
+		Value: strconv.Itoa(int(math.Ceil(gpu.Fraction * 100))),
+	}}
+}
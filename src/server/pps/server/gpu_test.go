@@ -0,0 +1,37 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestValidateGPUs(t *testing.T) {
+	withGPU := func(gpu *pps.GPUSpec) *pps.PipelineInfo {
+		return &pps.PipelineInfo{ResourceLimits: &pps.ResourceSpec{Gpu: gpu}}
+	}
+	require.NoError(t, validateGPUs(&pps.PipelineInfo{}))
+	require.NoError(t, validateGPUs(withGPU(&pps.GPUSpec{Type: "nvidia.com/gpu", Number: 1})))
+	require.NoError(t, validateGPUs(withGPU(&pps.GPUSpec{Type: "nvidia.com/gpu.shared", Fraction: 0.5, SharesPerGpu: 4})))
+	require.YesError(t, validateGPUs(withGPU(&pps.GPUSpec{Type: "nvidia.com/gpu.shared", Fraction: 0.5})))
+	require.YesError(t, validateGPUs(withGPU(&pps.GPUSpec{Type: "nvidia.com/gpu.shared", Fraction: 1.5, SharesPerGpu: 4})))
+	require.YesError(t, validateGPUs(withGPU(&pps.GPUSpec{Type: "nvidia.com/gpu.shared", Number: 1, Fraction: 0.5, SharesPerGpu: 4})))
+	require.YesError(t, validateGPUs(&pps.PipelineInfo{
+		DatumProfiles: []*pps.DatumProfile{{
+			Name:             "large",
+			ResourceRequests: &pps.ResourceSpec{Gpu: &pps.GPUSpec{Type: "nvidia.com/gpu.shared", Fraction: -1, SharesPerGpu: 4}},
+		}},
+	}))
+}
+
+func TestGPUEnv(t *testing.T) {
+	require.Equal(t, 0, len(gpuEnv(nil, nil)))
+	require.Equal(t, 0, len(gpuEnv(nil, &pps.ResourceSpec{Gpu: &pps.GPUSpec{Type: "nvidia.com/gpu", Number: 1}})))
+	require.Equal(t, 0, len(gpuEnv(nil, &pps.ResourceSpec{Gpu: &pps.GPUSpec{Type: "amd.com/gpu", Fraction: 0.5, SharesPerGpu: 2}})))
+
+	env := gpuEnv(&pps.ResourceSpec{Gpu: &pps.GPUSpec{Type: "nvidia.com/gpu.shared", Fraction: 0.25, SharesPerGpu: 4}}, nil)
+	require.Equal(t, 1, len(env))
+	require.Equal(t, mpsThreadPercentageEnv, env[0].Name)
+	require.Equal(t, "25", env[0].Value)
+}
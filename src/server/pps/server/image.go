@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/registry"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// parseImagePinning parses the cluster's image pinning policy (pachd's
+// IMAGE_PINNING setting).
+func parseImagePinning(policy string) (pps.ImagePinning, error) {
+	switch strings.ToLower(policy) {
+	case "", "none":
+		return pps.ImagePinning_IMAGE_PINNING_NONE, nil
+	case "pin":
+		return pps.ImagePinning_IMAGE_PINNING_PIN, nil
+	case "strict":
+		return pps.ImagePinning_IMAGE_PINNING_STRICT, nil
+	}
+	return 0, fmt.Errorf("invalid image pinning policy %q (must be one of \"none\", \"pin\" or \"strict\")", policy)
+}
+
+// pinImage resolves the tag of 'pipelineInfo's image to a digest, if the
+// pipeline or the cluster requires it, and records the digest in
+// 'pipelineInfo'.
+func (a *apiServer) pinImage(ctx context.Context, pipelineInfo *pps.PipelineInfo) error {
+	transform := pipelineInfo.Transform
+	if transform == nil {
+		return nil
+	}
+	pinning := transform.ImagePinning
+	if a.imagePinning > pinning {
+		pinning = a.imagePinning
+	}
+	if pinning == pps.ImagePinning_IMAGE_PINNING_NONE {
+		return nil
+	}
+	ref, err := registry.ParseReference(transform.Image)
+	if err != nil {
+		return err
+	}
+	if pinning == pps.ImagePinning_IMAGE_PINNING_STRICT && ref.IsFloating() {
+		return fmt.Errorf("image %q has a floating tag; pipelines in this cluster must use an image with a fixed tag or digest", transform.Image)
+	}
+	creds, err := a.registryCredentials(transform.ImagePullSecrets)
+	if err != nil {
+		return err
+	}
+	digest, err := registry.NewResolver().Digest(ctx, ref, creds)
+	if err != nil {
+		return fmt.Errorf("could not resolve the digest of image %q: %v", transform.Image, err)
+	}
+	pipelineInfo.ImageDigest = digest
+	return nil
+}
+
+// validateImagePull checks that 'transform's image can be pulled with its
+// image pull secrets, so that a pipeline that references private registry
+// credentials fails at creation rather than when its workers start. Pipelines
+// without image pull secrets aren't checked, as their images may come from a
+// registry that pachd can't reach.
+func (a *apiServer) validateImagePull(ctx context.Context, transform *pps.Transform) error {
+	if transform == nil || len(transform.ImagePullSecrets) == 0 {
+		return nil
+	}
+	ref, err := registry.ParseReference(transform.Image)
+	if err != nil {
+		return err
+	}
+	creds, err := a.registryCredentials(transform.ImagePullSecrets)
+	if err != nil {
+		return err
+	}
+	if err := registry.NewResolver().Check(ctx, ref, creds); err != nil {
+		return fmt.Errorf("image %q cannot be pulled with image pull secrets %v: %v", transform.Image, transform.ImagePullSecrets, err)
+	}
+	return nil
+}
+
+// newRegistrySecret returns a kubernetes image pull secret holding 'cred'.
+func newRegistrySecret(cred *pps.RegistryCredential) (*v1.Secret, error) {
+	if cred.Name == "" {
+		return nil, fmt.Errorf("registry secret must have a name")
+	}
+	if cred.Server == "" {
+		return nil, fmt.Errorf("registry secret must specify a server")
+	}
+	data, err := registry.DockerConfig(cred.Server, cred.Username, cred.Password, cred.Email)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: cred.Name},
+		Type:       v1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{v1.DockerConfigJsonKey: data},
+	}, nil
+}
+
+// registryCredentials reads the registry credentials in the cluster's and the
+// pipeline's image pull secrets.
+func (a *apiServer) registryCredentials(pullSecrets []string) (map[string]registry.Credential, error) {
+	result := make(map[string]registry.Credential)
+	if a.imagePullSecret != "" {
+		pullSecrets = append([]string{a.imagePullSecret}, pullSecrets...)
+	}
+	for _, name := range pullSecrets {
+		secret, err := a.env.GetKubeClient().CoreV1().Secrets(a.namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("could not read image pull secret %q: %v", name, err)
+		}
+		data, ok := secret.Data[v1.DockerConfigJsonKey]
+		if !ok {
+			// Secrets in the legacy format hold only the "auths" section
+			legacy, ok := secret.Data[v1.DockerConfigKey]
+			if !ok {
+				return nil, fmt.Errorf("image pull secret %q does not contain registry credentials (it has type %q)", name, secret.Type)
+			}
+			data = []byte(fmt.Sprintf(`{"auths": %s}`, legacy))
+		}
+		creds, err := registry.ParseDockerConfig(data)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse image pull secret %q: %v", name, err)
+		}
+		for domain, cred := range creds {
+			result[domain] = cred
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,231 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/metrics"
+	"golang.org/x/net/context"
+)
+
+// impactAnalyzer finds everything downstream of a commit. Like
+// stuckBranchAnalyzer, its lookups are funcs so that it can be tested without
+// a cluster.
+type impactAnalyzer struct {
+	// inspectCommit returns the CommitInfo of 'commit'
+	inspectCommit func(commit *pfs.Commit) (*pfs.CommitInfo, error)
+	// jobForCommit returns the job whose output commit is 'commit', including
+	// its egress, or nil
+	jobForCommit func(commit *pfs.Commit) (*pps.JobInfo, error)
+	// listBranch returns the branches of 'repo'
+	listBranch func(repo string) ([]*pfs.BranchInfo, error)
+	// pipelines are the cluster's pipelines, by name (which is also the name
+	// of their output repo)
+	pipelines map[string]*pps.PipelineInfo
+}
+
+// downstream returns the commits in the subvenance of 'ci', walking each
+// subvenance range from its upper commit back to its lower one. Downstream
+// commits' own subvenance is walked as well, though it's normally already in
+// 'ci's, and commits that the caller can't read are left out (along with the
+// rest of their range, as their parents can't be found).
+func (s *impactAnalyzer) downstream(ci *pfs.CommitInfo) ([]*pfs.CommitInfo, error) {
+	var result []*pfs.CommitInfo
+	seen := map[string]bool{commitKey(ci.Commit): true}
+	queue := []*pfs.CommitInfo{ci}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, subv := range next.Subvenance {
+			commit := subv.Upper
+			for commit != nil {
+				subvCI, err := s.inspectCommit(commit)
+				if err != nil {
+					if auth.IsErrNotAuthorized(err) {
+						break
+					}
+					return nil, err
+				}
+				if !seen[commitKey(commit)] {
+					seen[commitKey(commit)] = true
+					result = append(result, subvCI)
+					queue = append(queue, subvCI)
+				}
+				if subv.Lower == nil || commit.ID == subv.Lower.ID {
+					break
+				}
+				commit = subvCI.ParentCommit
+			}
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Commit.Repo.Name < result[j].Commit.Repo.Name
+	})
+	return result, nil
+}
+
+// impactedCommit returns the ImpactedCommit describing 'ci', along with the
+// job that wrote it, if any
+func (s *impactAnalyzer) impactedCommit(ci *pfs.CommitInfo) (*pps.ImpactedCommit, *pps.JobInfo, error) {
+	result := &pps.ImpactedCommit{
+		Commit:   ci.Commit,
+		Branch:   ci.Branch,
+		Finished: ci.Finished != nil,
+	}
+	pipelineInfo := s.pipelines[ci.Commit.Repo.Name]
+	if pipelineInfo == nil || ci.Branch == nil || ci.Branch.Name != pipelineInfo.OutputBranch {
+		// The commit isn't one of a pipeline's output commits
+		return result, nil, nil
+	}
+	result.Pipeline = pipelineInfo.Pipeline
+	jobInfo, err := s.jobForCommit(ci.Commit)
+	if err != nil {
+		return nil, nil, err
+	}
+	if jobInfo != nil {
+		result.Job = jobInfo.Job
+		result.JobState = jobInfo.State
+	}
+	return result, jobInfo, nil
+}
+
+// egressDestination describes where 'egress' sends output commits, or returns
+// "" if it doesn't send them anywhere
+func egressDestination(egress *pps.Egress) string {
+	switch {
+	case egress == nil:
+		return ""
+	case egress.SQLDatabase != nil:
+		return egress.SQLDatabase.URL
+	case egress.Kafka != nil:
+		return fmt.Sprintf("kafka://%s/%s", strings.Join(egress.Kafka.Brokers, ","), egress.Kafka.Topic)
+	}
+	return egress.URL
+}
+
+// impact returns the Impact of the commit 'ci'
+func (s *impactAnalyzer) impact(ci *pfs.CommitInfo) (*pps.Impact, error) {
+	downstream, err := s.downstream(ci)
+	if err != nil {
+		return nil, err
+	}
+	result := &pps.Impact{Commit: ci.Commit}
+	impacted := map[string]bool{commitKey(ci.Commit): true}
+	repos := []string{ci.Commit.Repo.Name}
+	seenRepos := map[string]bool{ci.Commit.Repo.Name: true}
+	var jobInfos []*pps.JobInfo
+	// 'ci' itself may be an output commit that its job egressed
+	for i, commitInfo := range append([]*pfs.CommitInfo{ci}, downstream...) {
+		impactedCommit, jobInfo, err := s.impactedCommit(commitInfo)
+		if err != nil {
+			return nil, err
+		}
+		if jobInfo != nil {
+			jobInfos = append(jobInfos, jobInfo)
+		}
+		if i == 0 {
+			continue
+		}
+		result.Commits = append(result.Commits, impactedCommit)
+		impacted[commitKey(commitInfo.Commit)] = true
+		if repo := commitInfo.Commit.Repo.Name; !seenRepos[repo] {
+			seenRepos[repo] = true
+			repos = append(repos, repo)
+		}
+	}
+
+	for _, repo := range repos {
+		branchInfos, err := s.listBranch(repo)
+		if err != nil {
+			if auth.IsErrNotAuthorized(err) {
+				continue
+			}
+			return nil, err
+		}
+		var branches []*pfs.Branch
+		for _, branchInfo := range branchInfos {
+			if branchInfo.Head != nil && impacted[commitKey(branchInfo.Head)] {
+				branches = append(branches, branchInfo.Branch)
+			}
+		}
+		sort.Slice(branches, func(i, j int) bool {
+			return branches[i].Name < branches[j].Name
+		})
+		result.Branches = append(result.Branches, branches...)
+	}
+
+	for _, jobInfo := range jobInfos {
+		switch jobInfo.State {
+		case pps.JobState_JOB_FAILURE, pps.JobState_JOB_KILLED, pps.JobState_JOB_SKIPPED:
+			continue // the job's output was never egressed
+		}
+		if destination := egressDestination(jobInfo.Egress); destination != "" {
+			result.Egresses = append(result.Egresses, &pps.ImpactedEgress{
+				Pipeline:    jobInfo.Pipeline,
+				Job:         jobInfo.Job,
+				Commit:      jobInfo.OutputCommit,
+				Destination: destination,
+			})
+		}
+	}
+
+	// Deleting an input commit deletes everything downstream of it, and
+	// reprocesses the new heads of its branches
+	if len(ci.Provenance) == 0 {
+		result.Remediations = append(result.Remediations,
+			fmt.Sprintf("pachctl delete commit %s", commitKey(ci.Commit)))
+	}
+	return result, nil
+}
+
+// InspectImpact implements the protobuf pps.InspectImpact RPC
+func (a *apiServer) InspectImpact(ctx context.Context, request *pps.InspectImpactRequest) (response *pps.Impact, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	metricsFn := metrics.ReportUserAction(ctx, a.reporter, "InspectImpact")
+	defer func(start time.Time) { metricsFn(start, retErr) }(time.Now())
+
+	pachClient := a.env.GetPachClient(ctx)
+	ctx, err := checkLoggedIn(pachClient)
+	if err != nil {
+		return nil, err
+	}
+	if request.Commit == nil || request.Commit.Repo == nil {
+		return nil, fmt.Errorf("must specify a commit")
+	}
+	ci, err := pachClient.InspectCommit(request.Commit.Repo.Name, request.Commit.ID)
+	if err != nil {
+		return nil, err
+	}
+	pipelineInfos, err := a.ListPipeline(ctx, &pps.ListPipelineRequest{})
+	if err != nil {
+		return nil, err
+	}
+	s := &impactAnalyzer{
+		inspectCommit: func(commit *pfs.Commit) (*pfs.CommitInfo, error) {
+			return pachClient.InspectCommit(commit.Repo.Name, commit.ID)
+		},
+		jobForCommit: func(commit *pfs.Commit) (*pps.JobInfo, error) {
+			var result *pps.JobInfo
+			if err := a.listJob(pachClient, nil, commit, nil, -1, true, "", func(ji *pps.JobInfo) error {
+				result = ji
+				return errutil.ErrBreak
+			}); err != nil && err != errutil.ErrBreak {
+				return nil, err
+			}
+			return result, nil
+		},
+		listBranch: pachClient.ListBranch,
+		pipelines:  make(map[string]*pps.PipelineInfo),
+	}
+	for _, pipelineInfo := range pipelineInfos.PipelineInfo {
+		s.pipelines[pipelineInfo.Pipeline.Name] = pipelineInfo
+	}
+	return s.impact(ci)
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+
+	"github.com/gogo/protobuf/types"
+)
+
+func TestImpact(t *testing.T) {
+	// images -> edges -> montage, where the bad images commit a is in the
+	// provenance of two edges commits (b1, and b2 from a change to the edges
+	// pipeline) and of montage's head c
+	commits := map[string]*pfs.CommitInfo{
+		"images@a": {
+			Commit: client.NewCommit("images", "a"),
+			Branch: client.NewBranch("images", "master"),
+			Subvenance: []*pfs.CommitRange{
+				{Lower: client.NewCommit("edges", "b1"), Upper: client.NewCommit("edges", "b2")},
+				{Lower: client.NewCommit("montage", "c"), Upper: client.NewCommit("montage", "c")},
+			},
+		},
+		"edges@b1": {
+			Commit:     client.NewCommit("edges", "b1"),
+			Branch:     client.NewBranch("edges", "master"),
+			Provenance: []*pfs.CommitProvenance{client.NewCommitProvenance("images", "master", "a")},
+			Finished:   types.TimestampNow(),
+			Subvenance: []*pfs.CommitRange{
+				{Lower: client.NewCommit("montage", "c"), Upper: client.NewCommit("montage", "c")},
+			},
+		},
+		"edges@b2": {
+			Commit:       client.NewCommit("edges", "b2"),
+			Branch:       client.NewBranch("edges", "master"),
+			ParentCommit: client.NewCommit("edges", "b1"),
+			Provenance:   []*pfs.CommitProvenance{client.NewCommitProvenance("images", "master", "a")},
+		},
+		"montage@c": {
+			Commit: client.NewCommit("montage", "c"),
+			Branch: client.NewBranch("montage", "master"),
+			Provenance: []*pfs.CommitProvenance{
+				client.NewCommitProvenance("edges", "master", "b1"),
+				client.NewCommitProvenance("images", "master", "a"),
+			},
+			Finished: types.TimestampNow(),
+		},
+	}
+	jobs := map[string]*pps.JobInfo{
+		"edges@b1": {
+			Job:          client.NewJob("j1"),
+			Pipeline:     client.NewPipeline("edges"),
+			OutputCommit: client.NewCommit("edges", "b1"),
+			State:        pps.JobState_JOB_SUCCESS,
+		},
+		"montage@c": {
+			Job:          client.NewJob("j3"),
+			Pipeline:     client.NewPipeline("montage"),
+			OutputCommit: client.NewCommit("montage", "c"),
+			State:        pps.JobState_JOB_SUCCESS,
+			Egress:       &pps.Egress{URL: "s3://bucket/montage"},
+		},
+	}
+	branches := map[string][]*pfs.BranchInfo{
+		"images": {
+			{Branch: client.NewBranch("images", "master"), Head: client.NewCommit("images", "a")},
+			{Branch: client.NewBranch("images", "staging"), Head: client.NewCommit("images", "0")},
+		},
+		"edges": {
+			{Branch: client.NewBranch("edges", "master"), Head: client.NewCommit("edges", "b2")},
+		},
+		"montage": {
+			{Branch: client.NewBranch("montage", "master"), Head: client.NewCommit("montage", "c")},
+		},
+	}
+	s := &impactAnalyzer{
+		inspectCommit: func(commit *pfs.Commit) (*pfs.CommitInfo, error) {
+			ci, ok := commits[commitKey(commit)]
+			if !ok {
+				return nil, fmt.Errorf("commit %s not found", commitKey(commit))
+			}
+			return ci, nil
+		},
+		jobForCommit: func(commit *pfs.Commit) (*pps.JobInfo, error) {
+			return jobs[commitKey(commit)], nil
+		},
+		listBranch: func(repo string) ([]*pfs.BranchInfo, error) {
+			return branches[repo], nil
+		},
+		pipelines: map[string]*pps.PipelineInfo{
+			"edges":   {Pipeline: client.NewPipeline("edges"), OutputBranch: "master"},
+			"montage": {Pipeline: client.NewPipeline("montage"), OutputBranch: "master"},
+		},
+	}
+
+	impact, err := s.impact(commits["images@a"])
+	require.NoError(t, err)
+	var impacted []string
+	for _, ic := range impact.Commits {
+		impacted = append(impacted, commitKey(ic.Commit))
+	}
+	require.Equal(t, []string{"edges@b2", "edges@b1", "montage@c"}, impacted)
+	// b2's job hasn't been created yet
+	require.Equal(t, "edges", impact.Commits[0].Pipeline.Name)
+	require.Nil(t, impact.Commits[0].Job)
+	require.False(t, impact.Commits[0].Finished)
+	require.Equal(t, "j1", impact.Commits[1].Job.ID)
+	require.Equal(t, pps.JobState_JOB_SUCCESS, impact.Commits[1].JobState)
+
+	var impactedBranches []string
+	for _, branch := range impact.Branches {
+		impactedBranches = append(impactedBranches, branch.Repo.Name+"@"+branch.Name)
+	}
+	require.Equal(t, []string{"images@master", "edges@master", "montage@master"}, impactedBranches)
+	require.Equal(t, 1, len(impact.Egresses))
+	require.Equal(t, "j3", impact.Egresses[0].Job.ID)
+	require.Equal(t, "s3://bucket/montage", impact.Egresses[0].Destination)
+	require.Equal(t, []string{"pachctl delete commit images@a"}, impact.Remediations)
+
+	// Output commits can't be deleted on their own, so there's no remediation
+	impact, err = s.impact(commits["edges@b1"])
+	require.NoError(t, err)
+	require.Equal(t, 1, len(impact.Commits))
+	require.Equal(t, "montage@c", commitKey(impact.Commits[0].Commit))
+	require.Equal(t, 0, len(impact.Remediations))
+}
+
+func TestEgressDestination(t *testing.T) {
+	require.Equal(t, "", egressDestination(nil))
+	require.Equal(t, "s3://bucket/dir", egressDestination(&pps.Egress{URL: "s3://bucket/dir"}))
+	require.Equal(t, "postgres://user@host:5432/db", egressDestination(&pps.Egress{
+		SQLDatabase: &pps.SQLDatabaseEgress{URL: "postgres://user@host:5432/db"},
+	}))
+	require.Equal(t, "kafka://a:9092,b:9092/events", egressDestination(&pps.Egress{
+		Kafka: &pps.KafkaEgress{Brokers: []string{"a:9092", "b:9092"}, Topic: "events"},
+	}))
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"github.com/pachyderm/pachyderm/src/server/pkg/mtls"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// internalTLSVolumeName is the name of the volume holding the internal cert of
+// worker pods, if internal TLS is enabled
+const internalTLSVolumeName = "pachyderm-internal-tls"
+
+// internalTLSVolume returns the volume and volume mount of the secret that
+// holds the internal cert of worker pods, which pachd keeps valid (see
+// mtls.RotateSecrets). It returns false if internal TLS is disabled.
+func internalTLSVolume() (v1.Volume, v1.VolumeMount, bool) {
+	internalTLS, err := mtls.Internal()
+	if err != nil || internalTLS == nil {
+		// pachd doesn't start if its internal TLS config can't be loaded
+		return v1.Volume{}, v1.VolumeMount{}, false
+	}
+	volume := v1.Volume{
+		Name: internalTLSVolumeName,
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName: mtls.WorkerSecretName,
+			},
+		},
+	}
+	mount := v1.VolumeMount{
+		Name:      internalTLSVolumeName,
+		MountPath: mtls.CertVolumePath,
+		ReadOnly:  true,
+	}
+	return volume, mount, true
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// jobIndex returns the index of the jobs collection that ListJob reads the
+// jobs matching 'request' from (see pps.JobIndex), given the request's label
+// filter 'filter'. It returns an error if the request picks an index that its
+// filters don't select a part of.
+func jobIndex(request *pps.ListJobRequest, filter *labelFilter) (pps.JobIndex, error) {
+	hasLabel := filter != nil && filter.indexValue != ""
+	hasStarted := request.StartedAfter != nil || request.StartedBefore != nil
+	switch request.Index {
+	case pps.JobIndex_JOB_INDEX_AUTO:
+		switch {
+		case request.OutputCommit != nil:
+			return pps.JobIndex_JOB_INDEX_OUTPUT_COMMIT, nil
+		case request.Pipeline != nil:
+			return pps.JobIndex_JOB_INDEX_PIPELINE, nil
+		case hasLabel:
+			return pps.JobIndex_JOB_INDEX_LABEL, nil
+		case len(request.State) == 1:
+			return pps.JobIndex_JOB_INDEX_STATE, nil
+		case hasStarted:
+			return pps.JobIndex_JOB_INDEX_STARTED, nil
+		}
+		return pps.JobIndex_JOB_INDEX_NONE, nil
+	case pps.JobIndex_JOB_INDEX_NONE:
+	case pps.JobIndex_JOB_INDEX_OUTPUT_COMMIT:
+		if request.OutputCommit == nil {
+			return 0, fmt.Errorf("the output commit index requires an output commit")
+		}
+	case pps.JobIndex_JOB_INDEX_PIPELINE:
+		if request.Pipeline == nil {
+			return 0, fmt.Errorf("the pipeline index requires a pipeline")
+		}
+	case pps.JobIndex_JOB_INDEX_LABEL:
+		if !hasLabel {
+			return 0, fmt.Errorf("the label index requires a label selector that requires a label to have a value (e.g. team=nlp)")
+		}
+	case pps.JobIndex_JOB_INDEX_STATE:
+		if len(request.State) != 1 {
+			return 0, fmt.Errorf("the state index requires exactly one state, but %d were given", len(request.State))
+		}
+	case pps.JobIndex_JOB_INDEX_STARTED:
+		if !hasStarted {
+			return 0, fmt.Errorf("the start time index requires started_after or started_before")
+		}
+	default:
+		return 0, fmt.Errorf("unknown job index %v", request.Index)
+	}
+	return request.Index, nil
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestJobIndex(t *testing.T) {
+	index := func(request *pps.ListJobRequest, selector string) (pps.JobIndex, error) {
+		filter, err := newLabelFilter(selector)
+		require.NoError(t, err)
+		return jobIndex(request, filter)
+	}
+	running := []pps.JobState{pps.JobState_JOB_RUNNING}
+	started := types.TimestampNow()
+
+	// The most selective filter's index is used by default
+	for _, c := range []struct {
+		request  *pps.ListJobRequest
+		selector string
+		expected pps.JobIndex
+	}{
+		{&pps.ListJobRequest{}, "", pps.JobIndex_JOB_INDEX_NONE},
+		{&pps.ListJobRequest{OutputCommit: client.NewCommit("p", "c"), Pipeline: client.NewPipeline("p")}, "", pps.JobIndex_JOB_INDEX_OUTPUT_COMMIT},
+		{&pps.ListJobRequest{Pipeline: client.NewPipeline("p"), State: running}, "team=nlp", pps.JobIndex_JOB_INDEX_PIPELINE},
+		{&pps.ListJobRequest{State: running}, "team=nlp", pps.JobIndex_JOB_INDEX_LABEL},
+		{&pps.ListJobRequest{State: running}, "team!=nlp", pps.JobIndex_JOB_INDEX_STATE},
+		{&pps.ListJobRequest{State: running, StartedAfter: started}, "", pps.JobIndex_JOB_INDEX_STATE},
+		{&pps.ListJobRequest{State: []pps.JobState{pps.JobState_JOB_RUNNING, pps.JobState_JOB_FAILURE}, StartedBefore: started}, "", pps.JobIndex_JOB_INDEX_STARTED},
+	} {
+		actual, err := index(c.request, c.selector)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, actual)
+	}
+
+	// An index can be picked if the request filters by its field
+	actual, err := index(&pps.ListJobRequest{
+		Pipeline: client.NewPipeline("p"),
+		State:    running,
+		Index:    pps.JobIndex_JOB_INDEX_STATE,
+	}, "")
+	require.NoError(t, err)
+	require.Equal(t, pps.JobIndex_JOB_INDEX_STATE, actual)
+	actual, err = index(&pps.ListJobRequest{Pipeline: client.NewPipeline("p"), Index: pps.JobIndex_JOB_INDEX_NONE}, "")
+	require.NoError(t, err)
+	require.Equal(t, pps.JobIndex_JOB_INDEX_NONE, actual)
+	_, err = index(&pps.ListJobRequest{Index: pps.JobIndex_JOB_INDEX_PIPELINE}, "")
+	require.YesError(t, err)
+	_, err = index(&pps.ListJobRequest{Index: pps.JobIndex_JOB_INDEX_LABEL}, "team!=nlp")
+	require.YesError(t, err)
+	_, err = index(&pps.ListJobRequest{
+		State: []pps.JobState{pps.JobState_JOB_RUNNING, pps.JobState_JOB_FAILURE},
+		Index: pps.JobIndex_JOB_INDEX_STATE,
+	}, "")
+	require.YesError(t, err)
+	_, err = index(&pps.ListJobRequest{Index: pps.JobIndex_JOB_INDEX_STARTED}, "")
+	require.YesError(t, err)
+	_, err = index(&pps.ListJobRequest{Index: pps.JobIndex(100)}, "")
+	require.YesError(t, err)
+}
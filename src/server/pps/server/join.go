@@ -0,0 +1,107 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+
+	glob "github.com/pachyderm/ohmyglob"
+	"github.com/pachyderm/ohmyglob/syntax"
+	"github.com/pachyderm/ohmyglob/syntax/ast"
+)
+
+// validateGlob returns an error if the glob of the PFS input 'input' doesn't
+// compile, or if its join_on or group_by refers to capture groups that the
+// glob doesn't have. Such references are replaced with "", so the datums of a
+// join or group would be matched on less than their whole key (or, if no
+// references are left, on nothing at all, which is a cross product or a
+// single group).
+func validateGlob(input *pps.PFSInput) error {
+	if _, err := glob.Compile(input.Glob, '/'); err != nil {
+		return fmt.Errorf("invalid glob %q: %v", input.Glob, err)
+	}
+	if input.JoinOn == "" && input.GroupBy == "" {
+		return nil
+	}
+	tree, err := syntax.Parse(input.Glob)
+	if err != nil {
+		return fmt.Errorf("invalid glob %q: %v", input.Glob, err)
+	}
+	groups := captureGroups(tree)
+	if err := validateReferences(input, "join_on", input.JoinOn, groups); err != nil {
+		return err
+	}
+	return validateReferences(input, "group_by", input.GroupBy, groups)
+}
+
+// validateReferences returns an error if 'template', the value of the field
+// 'field' of 'input', refers to no capture groups or to capture groups that
+// aren't among the 'groups' capture groups of the input's glob. An empty
+// template is valid.
+func validateReferences(input *pps.PFSInput, field, template string, groups int) error {
+	if template == "" {
+		return nil
+	}
+	refs := joinOnReferences(template)
+	if len(refs) == 0 {
+		return fmt.Errorf("%s %q of input %q doesn't refer to any of its glob's capture groups (e.g. \"$1\")",
+			field, template, input.Name)
+	}
+	for _, ref := range refs {
+		if ref < 1 || ref > groups {
+			return fmt.Errorf("%s %q of input %q refers to capture group $%d, but its glob %q has %d capture groups",
+				field, template, input.Name, ref, input.Glob, groups)
+		}
+	}
+	return nil
+}
+
+// captureGroups returns the number of capture groups in the glob 'node'.
+// Empty groups (e.g. "()") aren't compiled to capture groups, so they aren't
+// counted.
+func captureGroups(node *ast.Node) int {
+	var n int
+	if node.Kind == ast.KindCapture && len(node.Children) > 0 {
+		n++
+	}
+	for _, child := range node.Children {
+		n += captureGroups(child)
+	}
+	return n
+}
+
+// joinOnReferences returns the capture groups that the join_on (or group_by)
+// template 'template' refers to, as "$n" or "${n}" (see glob.Replace). "$$" is a
+// literal "$".
+func joinOnReferences(template string) []int {
+	var refs []int
+	for {
+		i := strings.Index(template, "$")
+		if i < 0 || i == len(template)-1 {
+			return refs
+		}
+		template = template[i+1:]
+		if template[0] == '$' {
+			template = template[1:]
+			continue
+		}
+		brace := template[0] == '{'
+		if brace {
+			template = template[1:]
+		}
+		end := 0
+		for end < len(template) && template[end] >= '0' && template[end] <= '9' {
+			end++
+		}
+		if end == 0 || (brace && (end == len(template) || template[end] != '}')) {
+			// malformed references are literal text
+			continue
+		}
+		if n, err := strconv.Atoi(template[:end]); err == nil {
+			refs = append(refs, n)
+		}
+		template = template[end:]
+	}
+}
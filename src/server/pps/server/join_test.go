@@ -0,0 +1,44 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestJoinOnReferences(t *testing.T) {
+	require.Equal(t, []int{1}, joinOnReferences("$1"))
+	require.Equal(t, []int{1, 2}, joinOnReferences("${1}-$2"))
+	require.Equal(t, 0, len(joinOnReferences("$$1")))
+	require.Equal(t, 0, len(joinOnReferences("${1")))
+	require.Equal(t, 0, len(joinOnReferences("id")))
+	require.Equal(t, []int{10}, joinOnReferences("$10$"))
+}
+
+func TestValidateGlob(t *testing.T) {
+	input := func(glob, joinOn string) *pps.PFSInput {
+		return &pps.PFSInput{Name: "images", Glob: glob, JoinOn: joinOn}
+	}
+	require.NoError(t, validateGlob(input("/*", "")))
+	require.NoError(t, validateGlob(input("/(*).png", "$1")))
+	require.NoError(t, validateGlob(input("/(*)/(*).png", "$2-${1}")))
+	require.NoError(t, validateGlob(input("/@(a|b)/(*).png", "$2")))
+	require.YesError(t, validateGlob(input("/[", "")))
+	// join_on must refer to the glob's capture groups
+	require.YesError(t, validateGlob(input("/*.png", "$1")))
+	require.YesError(t, validateGlob(input("/(*).png", "$2")))
+	require.YesError(t, validateGlob(input("/(*).png", "$0")))
+	require.YesError(t, validateGlob(input("/(*).png", "id")))
+}
+
+func TestValidateGlobGroupBy(t *testing.T) {
+	input := func(glob, groupBy string) *pps.PFSInput {
+		return &pps.PFSInput{Name: "logs", Glob: glob, GroupBy: groupBy}
+	}
+	require.NoError(t, validateGlob(input("/(*)-*.log", "$1")))
+	require.NoError(t, validateGlob(&pps.PFSInput{Name: "logs", Glob: "/(*)/(*).log", JoinOn: "$2", GroupBy: "$1"}))
+	require.YesError(t, validateGlob(input("/*.log", "$1")))
+	require.YesError(t, validateGlob(input("/(*).log", "host")))
+	require.YesError(t, validateGlob(&pps.PFSInput{Name: "logs", Glob: "/(*).log", JoinOn: "$1", GroupBy: "$2"}))
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"fmt"
+
+	kubelabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsdb"
+)
+
+// validateMetadata checks that the labels in 'metadata' are valid kubernetes
+// labels, so that they can be matched by label selectors
+func validateMetadata(metadata *pps.Metadata) error {
+	if metadata == nil {
+		return nil
+	}
+	for key, value := range metadata.Labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid label key %q: %v", key, errs[0])
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("invalid value for label %q: %v", key, errs[0])
+		}
+	}
+	return nil
+}
+
+// labelFilter selects pipelines and jobs whose labels match a kubernetes
+// label selector. A nil labelFilter matches everything.
+type labelFilter struct {
+	selector kubelabels.Selector
+	// indexValue is a value of the label indexes that every match has, or ""
+	// if the selector has no equality requirements
+	indexValue string
+}
+
+// newLabelFilter parses 'selector', and returns nil if it's empty
+func newLabelFilter(selector string) (*labelFilter, error) {
+	if selector == "" {
+		return nil, nil
+	}
+	s, err := kubelabels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector: %v", err)
+	}
+	f := &labelFilter{selector: s}
+	requirements, _ := s.Requirements()
+	for _, r := range requirements {
+		switch r.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			if values := r.Values(); values.Len() == 1 {
+				f.indexValue = ppsdb.LabelIndexValue(r.Key(), values.List()[0])
+			}
+		}
+		if f.indexValue != "" {
+			break
+		}
+	}
+	return f, nil
+}
+
+// matches returns true if 'l' matches f's selector
+func (f *labelFilter) matches(l map[string]string) bool {
+	return f == nil || f.selector.Matches(kubelabels.Set(l))
+}
+
+// matchesIndexValues returns true if the labels encoded in 'values' (see
+// ppsdb.LabelIndexValues) match f's selector
+func (f *labelFilter) matchesIndexValues(values []string) (bool, error) {
+	if f == nil {
+		return true, nil
+	}
+	l, err := ppsdb.ParseLabelIndexValues(values)
+	if err != nil {
+		return false, err
+	}
+	return f.matches(l), nil
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsdb"
+)
+
+func TestValidateMetadata(t *testing.T) {
+	require.NoError(t, validateMetadata(nil))
+	require.NoError(t, validateMetadata(&pps.Metadata{
+		Labels:      map[string]string{"team": "nlp", "example.com/env": "prod", "empty": ""},
+		Annotations: map[string]string{"anything goes": "here, too!"},
+	}))
+	require.YesError(t, validateMetadata(&pps.Metadata{Labels: map[string]string{"bad key": "x"}}))
+	require.YesError(t, validateMetadata(&pps.Metadata{Labels: map[string]string{"team": "a=b"}}))
+}
+
+func TestLabelFilter(t *testing.T) {
+	f, err := newLabelFilter("")
+	require.NoError(t, err)
+	require.Nil(t, f)
+	require.True(t, f.matches(nil))
+
+	_, err = newLabelFilter("team in (")
+	require.YesError(t, err)
+
+	f, err = newLabelFilter("env!=dev,example.com/team=nlp")
+	require.NoError(t, err)
+	require.Equal(t, ppsdb.LabelIndexValue("example.com/team", "nlp"), f.indexValue)
+	require.True(t, f.matches(map[string]string{"example.com/team": "nlp", "env": "prod"}))
+	require.False(t, f.matches(map[string]string{"example.com/team": "nlp", "env": "dev"}))
+	require.False(t, f.matches(map[string]string{"team": "nlp"}))
+
+	ok, err := f.matchesIndexValues(ppsdb.LabelIndexValues(map[string]string{"example.com/team": "nlp"}))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// Selectors without equality requirements can't use the label index
+	f, err = newLabelFilter("team,!deprecated")
+	require.NoError(t, err)
+	require.Equal(t, "", f.indexValue)
+	require.True(t, f.matches(map[string]string{"team": "cv"}))
+	require.False(t, f.matches(map[string]string{"team": "cv", "deprecated": "true"}))
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	workerpkg "github.com/pachyderm/pachyderm/src/server/worker"
+)
+
+// logFilter selects the log messages that GetLogs returns
+type logFilter struct {
+	request *pps.GetLogsRequest
+	pattern *regexp.Regexp // nil if request.Pattern is unset
+	since   time.Time      // zero if request.Since is unset
+}
+
+func newLogFilter(request *pps.GetLogsRequest) (*logFilter, error) {
+	f := &logFilter{request: request}
+	if request.Pattern != "" {
+		pattern, err := regexp.Compile(request.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %v", err)
+		}
+		f.pattern = pattern
+	}
+	if request.Since != nil {
+		since, err := types.DurationFromProto(request.Since)
+		if err != nil {
+			return nil, err
+		}
+		f.since = time.Now().Add(-since)
+	}
+	return f, nil
+}
+
+// sinceSeconds returns the value of PodLogOptions.SinceSeconds that fetches
+// the logs selected by f, or nil if all logs must be fetched
+func (f *logFilter) sinceSeconds() *int64 {
+	if f.since.IsZero() {
+		return nil
+	}
+	// Round up, as kubernetes only accepts whole seconds
+	seconds := int64(time.Since(f.since)/time.Second) + 1
+	return &seconds
+}
+
+// matches returns true if 'msg' (a log message from pachd, which is only
+// annotated with its text) passes f's filters
+func (f *logFilter) matches(msg *pps.LogMessage) bool {
+	if f.pattern != nil && !f.pattern.MatchString(msg.Message) {
+		return false
+	}
+	if !f.since.IsZero() && msg.Ts != nil {
+		ts, err := types.TimestampFromProto(msg.Ts)
+		if err == nil && ts.Before(f.since) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesWorker returns true if 'msg' (a log message from a worker) passes
+// f's filters
+func (f *logFilter) matchesWorker(msg *pps.LogMessage) bool {
+	request := f.request
+	if request.Pipeline != nil && request.Pipeline.Name != msg.PipelineName {
+		return false
+	}
+	if request.Job != nil && request.Job.ID != msg.JobID {
+		return false
+	}
+	if request.Datum != nil && request.Datum.ID != msg.DatumID {
+		return false
+	}
+	if request.WorkerID != "" && request.WorkerID != msg.WorkerID {
+		return false
+	}
+	if request.Master != msg.Master {
+		return false
+	}
+	if !workerpkg.MatchDatum(request.DataFilters, msg.Data) {
+		return false
+	}
+	return f.matches(msg)
+}
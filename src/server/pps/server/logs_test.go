@@ -0,0 +1,46 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestLogFilter(t *testing.T) {
+	now, err := types.TimestampProto(time.Now())
+	require.NoError(t, err)
+	old, err := types.TimestampProto(time.Now().Add(-2 * time.Hour))
+	require.NoError(t, err)
+	msg := func(worker, message string, ts *types.Timestamp) *pps.LogMessage {
+		return &pps.LogMessage{PipelineName: "edges", WorkerID: worker, Message: message, Ts: ts}
+	}
+
+	filter, err := newLogFilter(&pps.GetLogsRequest{Pipeline: client.NewPipeline("edges")})
+	require.NoError(t, err)
+	require.Nil(t, filter.sinceSeconds())
+	require.True(t, filter.matchesWorker(msg("w1", "anything", old)))
+	require.False(t, filter.matchesWorker(&pps.LogMessage{PipelineName: "montage"}))
+
+	filter, err = newLogFilter(&pps.GetLogsRequest{
+		Pipeline: client.NewPipeline("edges"),
+		Since:    types.DurationProto(time.Hour),
+		Pattern:  "err(or)?",
+		WorkerID: "w1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3601), *filter.sinceSeconds())
+	require.True(t, filter.matchesWorker(msg("w1", "an error occurred", now)))
+	require.False(t, filter.matchesWorker(msg("w1", "all good", now)))
+	require.False(t, filter.matchesWorker(msg("w2", "an error occurred", now)))
+	require.False(t, filter.matchesWorker(msg("w1", "an error occurred", old)))
+	// pachd's log messages have no timestamp, and rely on sinceSeconds
+	require.True(t, filter.matches(&pps.LogMessage{Message: "err"}))
+
+	_, err = newLogFilter(&pps.GetLogsRequest{Pattern: "("})
+	require.YesError(t, err)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/robfig/cron"
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kube_watch "k8s.io/apimachinery/pkg/watch"
 
@@ -32,7 +33,7 @@ import (
 )
 
 const (
-	masterLockPath = "_master_lock"
+	masterLockPath = ppsconsts.MasterLockPath
 )
 
 var (
@@ -63,6 +64,15 @@ func (a *apiServer) master() {
 		defer masterLock.Unlock(ctx)
 		kubeClient := a.env.GetKubeClient()
 
+		// Clean up the worker resources of deleted pipelines, until this
+		// process loses the master lock
+		go a.collectOrphans(ctx)
+		// Reprocess pipelines whose config map keys with 'reprocess' set change
+		go a.reprocessOnConfigMapChanges(ctx, pachClient)
+		if a.pipelineCRD {
+			go a.syncPipelineResources(ctx, pachClient)
+		}
+
 		log.Infof("PPS master: launching master process")
 
 		// TODO(msteffen) requestly only keys, since pipeline_controller.go reads
@@ -219,6 +229,34 @@ func (a *apiServer) deletePipelineResources(ctx context.Context, pipelineName st
 			}
 		}
 	}
+	serviceAccounts, err := kubeClient.CoreV1().ServiceAccounts(a.namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil && !kerrors.IsForbidden(err) {
+		return fmt.Errorf("could not list service accounts: %v", err)
+	}
+	if err == nil {
+		for _, serviceAccount := range serviceAccounts.Items {
+			if err := kubeClient.CoreV1().ServiceAccounts(a.namespace).Delete(serviceAccount.Name, opts); err != nil {
+				if !isNotFoundErr(err) {
+					return fmt.Errorf("could not delete service account %q: %v", serviceAccount.Name, err)
+				}
+			}
+		}
+	}
+	policies, err := kubeClient.NetworkingV1().NetworkPolicies(a.namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		if kerrors.IsForbidden(err) {
+			// pachd can't manage network policies, so it hasn't created any
+			return nil
+		}
+		return fmt.Errorf("could not list network policies: %v", err)
+	}
+	for _, policy := range policies.Items {
+		if err := kubeClient.NetworkingV1().NetworkPolicies(a.namespace).Delete(policy.Name, opts); err != nil {
+			if !isNotFoundErr(err) {
+				return fmt.Errorf("could not delete network policy %q: %v", policy.Name, err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -244,7 +282,7 @@ func (a *apiServer) setPipelineState(pachClient *client.APIClient, pipelineInfo
 		tracing.TagAnySpan(span, "err", retErr)
 		tracing.FinishAnySpan(span)
 	}()
-	parallelism, err := ppsutil.GetExpectedNumWorkers(a.env.GetKubeClient(), pipelineInfo.ParallelismSpec)
+	parallelism, err := ppsutil.GetExpectedNumWorkers(pachClient.Ctx(), a.env.GetKubeClient(), pipelineInfo.ParallelismSpec)
 	if err != nil {
 		return err
 	}
@@ -259,14 +297,75 @@ func (a *apiServer) setPipelineState(pachClient *client.APIClient, pipelineInfo
 		if pipelinePtr.State == pps.PipelineState_PIPELINE_FAILURE {
 			return nil
 		}
-		pipelinePtr.State = state
-		pipelinePtr.Reason = reason
+		ppsutil.SetPipelineState(pipelinePtr, state, reason)
 		pipelinePtr.Parallelism = uint64(parallelism)
+		if autoscaling := pipelineInfo.Service.GetAutoscaling(); autoscaling != nil {
+			pipelinePtr.Parallelism = ppsutil.ServiceReplicas(autoscaling, pipelinePtr.ServiceAutoscaling)
+		}
 		return pipelines.Put(pipelineInfo.Pipeline.Name, pipelinePtr)
 	})
 	return err
 }
 
+// startStandbyWake records that 'pipeline' is waking from standby, so that
+// the time taken by each phase of the wake can be recorded on the job that it
+// processes (see pps.StandbyWake)
+func (a *apiServer) startStandbyWake(pachClient *client.APIClient, pipeline string) error {
+	_, err := col.NewSTM(pachClient.Ctx(), a.env.GetEtcdClient(), func(stm col.STM) error {
+		pipelines := a.pipelines.ReadWrite(stm)
+		pipelinePtr := &pps.EtcdPipelineInfo{}
+		if err := pipelines.Get(pipeline, pipelinePtr); err != nil {
+			return err
+		}
+		if err := ppsutil.StartStandbyWake(pipelinePtr, time.Now()); err != nil {
+			return err
+		}
+		return pipelines.Put(pipeline, pipelinePtr)
+	})
+	return err
+}
+
+// waitForDowntimeWindows blocks until none of the downtime windows of the
+// pipeline in 'pipelineInfo' is open
+func waitForDowntimeWindows(ctx context.Context, pipelineInfo *pps.PipelineInfo) error {
+	for {
+		end, in, err := ppsutil.DowntimeWindowEnd(pipelineInfo.DowntimeWindows, time.Now())
+		if err != nil || !in {
+			return err
+		}
+		log.Printf("PPS master: pipeline %q is in a downtime window until %v; staying in standby", pipelineInfo.Pipeline.Name, end)
+		select {
+		case <-time.After(time.Until(end)):
+		case <-ctx.Done():
+			return context.DeadlineExceeded
+		}
+	}
+}
+
+// waitForBudget blocks until the pipeline in 'pipelineInfo' is within its
+// budget (see pps.Budget)
+func (a *apiServer) waitForBudget(ctx context.Context, pipelineInfo *pps.PipelineInfo) error {
+	if pipelineInfo.Budget == nil {
+		return nil
+	}
+	for {
+		pipelinePtr := &pps.EtcdPipelineInfo{}
+		if err := a.pipelines.ReadOnly(ctx).Get(pipelineInfo.Pipeline.Name, pipelinePtr); err != nil {
+			return err
+		}
+		reset, exceeded := ppsutil.BudgetExceeded(pipelinePtr, time.Now())
+		if !exceeded {
+			return nil
+		}
+		log.Printf("PPS master: pipeline %q has exceeded its monthly budget until %v; staying in standby", pipelineInfo.Pipeline.Name, reset)
+		select {
+		case <-time.After(time.Until(reset)):
+		case <-ctx.Done():
+			return context.DeadlineExceeded
+		}
+	}
+}
+
 func (a *apiServer) monitorPipeline(pachClient *client.APIClient, pipelineInfo *pps.PipelineInfo) {
 	log.Printf("PPS master: monitoring pipeline %q", pipelineInfo.Pipeline.Name)
 	// If this exits (e.g. b/c Standby is false, and pipeline has no cron inputs),
@@ -284,7 +383,22 @@ func (a *apiServer) monitorPipeline(pachClient *client.APIClient, pipelineInfo *
 			})
 		}
 	})
+	if pipelineInfo.Service.GetAutoscaling() != nil {
+		eg.Go(func() error {
+			return backoff.RetryNotify(func() error {
+				return a.autoscaleService(pachClient.Ctx(), pipelineInfo)
+			}, backoff.NewInfiniteBackOff(), notifyCtx(pachClient.Ctx(), "autoscaling"))
+		})
+	}
 	if pipelineInfo.Standby {
+		var gracePeriod time.Duration
+		if pipelineInfo.StandbyGracePeriod != nil {
+			var err error
+			gracePeriod, err = types.DurationFromProto(pipelineInfo.StandbyGracePeriod)
+			if err != nil {
+				log.Printf("error in monitorPipeline: invalid standby_grace_period: %v", err)
+			}
+		}
 		// Capacity 1 gives us a bit of buffer so we don't needlessly go into
 		// standby when SubscribeCommit takes too long to return.
 		ciChan := make(chan *pfs.CommitInfo, 1)
@@ -337,27 +451,33 @@ func (a *apiServer) monitorPipeline(pachClient *client.APIClient, pipelineInfo *
 							pachClient = oldPachClient.WithCtx(ctx)
 						}
 
+						// Stay in standby until any downtime window closes, and
+						// until the pipeline is within its budget, as the workers
+						// wouldn't start a job anyway
+						if err := waitForDowntimeWindows(pachClient.Ctx(), pipelineInfo); err != nil {
+							return err
+						}
+						if err := a.waitForBudget(pachClient.Ctx(), pipelineInfo); err != nil {
+							return err
+						}
+						if err := a.startStandbyWake(pachClient, pipelineInfo.Pipeline.Name); err != nil {
+							return err
+						}
 						if err := a.setPipelineState(pachClient, pipelineInfo, pps.PipelineState_PIPELINE_RUNNING, ""); err != nil {
 							return err
 						}
 
 						// Stay running while commits are available
-					running:
-						for {
+						if err := waitForStandby(pachClient.Ctx(), ci, ciChan, gracePeriod, func(ci *pfs.CommitInfo) error {
 							// Wait for the commit to be finished before blocking on the
 							// job because the job may not exist yet.
 							if _, err := pachClient.BlockCommit(ci.Commit.Repo.Name, ci.Commit.ID); err != nil {
 								return err
 							}
-							if _, err := pachClient.InspectJobOutputCommit(ci.Commit.Repo.Name, ci.Commit.ID, true); err != nil {
-								return err
-							}
-
-							select {
-							case ci = <-ciChan:
-							default:
-								break running
-							}
+							_, err := pachClient.InspectJobOutputCommit(ci.Commit.Repo.Name, ci.Commit.ID, true)
+							return err
+						}); err != nil {
+							return err
 						}
 
 						if err := a.setPipelineState(pachClient, pipelineInfo, pps.PipelineState_PIPELINE_STANDBY, ""); err != nil {
@@ -383,6 +503,35 @@ func (a *apiServer) monitorPipeline(pachClient *client.APIClient, pipelineInfo *
 	}
 }
 
+// waitForStandby returns once a pipeline in standby can go back into standby:
+// when 'wait' has returned for 'ci' and every commit that arrived on 'ciChan'
+// while it was running, and no new commit has arrived for 'gracePeriod'.
+// 'wait' blocks until a commit's job is finished.
+func waitForStandby(ctx context.Context, ci *pfs.CommitInfo, ciChan <-chan *pfs.CommitInfo, gracePeriod time.Duration, wait func(*pfs.CommitInfo) error) error {
+	for {
+		if err := wait(ci); err != nil {
+			return err
+		}
+		select {
+		case ci = <-ciChan:
+			continue
+		default:
+		}
+		if gracePeriod == 0 {
+			return nil
+		}
+		// Keep the workers warm for the grace period, in case more commits
+		// arrive soon
+		select {
+		case ci = <-ciChan:
+		case <-time.After(gracePeriod):
+			return nil
+		case <-ctx.Done():
+			return context.DeadlineExceeded
+		}
+	}
+}
+
 func (a *apiServer) getLatestCronTime(pachClient *client.APIClient, in *pps.Input) (time.Time, error) {
 	var latestTime time.Time
 	files, err := pachClient.ListFile(in.Cron.Repo, "master", "")
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// parseNetworkPolicyAllow parses the CIDRs that every pipeline's workers can
+// reach (pachd's WORKER_NETWORK_POLICY_ALLOW setting), which is a comma-
+// separated list
+func parseNetworkPolicyAllow(allow string) ([]string, error) {
+	var cidrs []string
+	for _, cidr := range strings.Split(allow, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, fmt.Errorf("invalid network policy CIDR %q: %v", cidr, err)
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	return cidrs, nil
+}
+
+// validateNetworkPolicy returns an error if 'policy' is invalid
+func validateNetworkPolicy(policy *pps.NetworkPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	for _, peer := range policy.Allow {
+		switch {
+		case peer.CIDR != "" && len(peer.PodLabels) > 0:
+			return fmt.Errorf("network peers can't set both cidr and pod_labels")
+		case peer.CIDR != "":
+			if _, _, err := net.ParseCIDR(peer.CIDR); err != nil {
+				return fmt.Errorf("invalid network peer CIDR %q: %v", peer.CIDR, err)
+			}
+		case len(peer.PodLabels) > 0:
+			if err := validateMetadata(&pps.Metadata{Labels: peer.PodLabels}); err != nil {
+				return fmt.Errorf("invalid network peer pod_labels: %v", err)
+			}
+		default:
+			return fmt.Errorf("network peers must set cidr or pod_labels")
+		}
+		for _, port := range peer.Ports {
+			if port < 1 || port > 65535 {
+				return fmt.Errorf("invalid network peer port %d", port)
+			}
+		}
+	}
+	return nil
+}
+
+// networkPolicyName returns the name of the network policy of the pipeline
+// 'pipelineName'. Unlike RCs, a pipeline has one network policy across its
+// versions, which is updated along with the pipeline.
+func networkPolicyName(pipelineName string) string {
+	return ppsutil.PipelineResourceName(pipelineName)
+}
+
+// legacyNetworkPolicyName returns the name that older versions of pachd gave
+// the network policy of the pipeline 'pipelineName', before names included a
+// hash of the pipeline name
+func legacyNetworkPolicyName(pipelineName string) string {
+	return fmt.Sprintf("pipeline-%s", strings.ToLower(strings.Replace(pipelineName, "_", "-", -1)))
+}
+
+// workerNetworkPolicy returns the network policy that restricts the
+// destinations that the workers of 'pipelineInfo' can reach to pachd, etcd,
+// the pipeline's other workers, DNS, the CIDRs in 'allow', and the peers in
+// the pipeline's own network policy
+func workerNetworkPolicy(pipelineInfo *pps.PipelineInfo, allow []string) *networkingv1.NetworkPolicy {
+	pipelineName := pipelineInfo.Pipeline.Name
+	podRule := func(labels map[string]string) networkingv1.NetworkPolicyEgressRule {
+		return networkingv1.NetworkPolicyEgressRule{
+			To: []networkingv1.NetworkPolicyPeer{{
+				PodSelector: &metav1.LabelSelector{MatchLabels: labels},
+			}},
+		}
+	}
+	tcp, udp := v1.ProtocolTCP, v1.ProtocolUDP
+	dnsPort := intstr.FromInt(53)
+	rules := []networkingv1.NetworkPolicyEgressRule{
+		podRule(map[string]string{"app": "pachd", "suite": suite}),
+		podRule(map[string]string{"app": "etcd", "suite": suite}),
+		podRule(map[string]string{pipelineNameLabel: pipelineName}),
+		{
+			// DNS, which may be served from another namespace
+			To: []networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: &metav1.LabelSelector{},
+			}},
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &udp, Port: &dnsPort},
+				{Protocol: &tcp, Port: &dnsPort},
+			},
+		},
+	}
+	for _, cidr := range allow {
+		rules = append(rules, networkingv1.NetworkPolicyEgressRule{
+			To: []networkingv1.NetworkPolicyPeer{{
+				IPBlock: &networkingv1.IPBlock{CIDR: cidr},
+			}},
+		})
+	}
+	if pipelineInfo.NetworkPolicy != nil {
+		for _, peer := range pipelineInfo.NetworkPolicy.Allow {
+			var rule networkingv1.NetworkPolicyEgressRule
+			if peer.CIDR != "" {
+				rule.To = []networkingv1.NetworkPolicyPeer{{
+					IPBlock: &networkingv1.IPBlock{CIDR: peer.CIDR},
+				}}
+			} else {
+				rule = podRule(peer.PodLabels)
+			}
+			for _, port := range peer.Ports {
+				port := intstr.FromInt(int(port))
+				rule.Ports = append(rule.Ports, networkingv1.NetworkPolicyPort{
+					Protocol: &tcp,
+					Port:     &port,
+				})
+			}
+			rules = append(rules, rule)
+		}
+	}
+	return &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: networkPolicyName(pipelineName),
+			Labels: map[string]string{
+				"suite":           suite,
+				"component":       "worker",
+				pipelineNameLabel: pipelineName,
+			},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{pipelineNameLabel: pipelineName},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress:      rules,
+		},
+	}
+}
+
+// upsertNetworkPolicy creates or updates the network policy of the workers
+// of 'pipelineInfo', if pachd creates network policies for every pipeline or
+// the pipeline has its own network policy. Otherwise it deletes the policy
+// that an earlier version of the pipeline may have had (pachd may not have
+// permission to manage network policies, if it's never created any).
+func (a *apiServer) upsertNetworkPolicy(pipelineInfo *pps.PipelineInfo) error {
+	policies := a.env.GetKubeClient().NetworkingV1().NetworkPolicies(a.namespace)
+	// Network policies are additive, so a policy that an older pachd created
+	// under the legacy name would keep allowing whatever it allowed
+	legacy, err := policies.Get(legacyNetworkPolicyName(pipelineInfo.Pipeline.Name), metav1.GetOptions{})
+	if err == nil && legacy.Labels[pipelineNameLabel] == pipelineInfo.Pipeline.Name {
+		if err := policies.Delete(legacy.Name, nil); err != nil && !isNotFoundErr(err) {
+			return fmt.Errorf("could not delete legacy network policy: %v", err)
+		}
+	} else if err != nil && !isNotFoundErr(err) && !kerrors.IsForbidden(err) {
+		return fmt.Errorf("could not get legacy network policy: %v", err)
+	}
+	if !a.workerNetworkPolicy && pipelineInfo.NetworkPolicy == nil {
+		err := policies.Delete(networkPolicyName(pipelineInfo.Pipeline.Name), nil)
+		if err != nil && !isNotFoundErr(err) && !kerrors.IsForbidden(err) {
+			return fmt.Errorf("could not delete network policy: %v", err)
+		}
+		return nil
+	}
+	policy := workerNetworkPolicy(pipelineInfo, a.networkPolicyAllow)
+	if _, err := policies.Create(policy); err != nil {
+		if !isAlreadyExistsErr(err) {
+			return fmt.Errorf("could not create network policy: %v", err)
+		}
+		existing, err := policies.Get(policy.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("could not get network policy: %v", err)
+		}
+		existing.Spec = policy.Spec
+		if _, err := policies.Update(existing); err != nil {
+			return fmt.Errorf("could not update network policy: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestParseNetworkPolicyAllow(t *testing.T) {
+	cidrs, err := parseNetworkPolicyAllow("")
+	require.NoError(t, err)
+	require.Equal(t, 0, len(cidrs))
+	cidrs, err = parseNetworkPolicyAllow("10.0.0.0/8, 52.216.0.0/15")
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.0/8", "52.216.0.0/15"}, cidrs)
+	_, err = parseNetworkPolicyAllow("10.0.0.0")
+	require.YesError(t, err)
+}
+
+func TestValidateNetworkPolicy(t *testing.T) {
+	require.NoError(t, validateNetworkPolicy(nil))
+	require.NoError(t, validateNetworkPolicy(&pps.NetworkPolicy{}))
+	require.NoError(t, validateNetworkPolicy(&pps.NetworkPolicy{
+		Allow: []*pps.NetworkPeer{
+			{CIDR: "10.0.0.0/16", Ports: []int32{443}},
+			{PodLabels: map[string]string{"app": "postgres"}, Ports: []int32{5432}},
+		},
+	}))
+	for _, peer := range []*pps.NetworkPeer{
+		{},
+		{CIDR: "10.0.0.0"},
+		{CIDR: "10.0.0.0/16", PodLabels: map[string]string{"app": "postgres"}},
+		{PodLabels: map[string]string{"app": "not a label value"}},
+		{CIDR: "10.0.0.0/16", Ports: []int32{0}},
+		{CIDR: "10.0.0.0/16", Ports: []int32{65536}},
+	} {
+		require.YesError(t, validateNetworkPolicy(&pps.NetworkPolicy{Allow: []*pps.NetworkPeer{peer}}))
+	}
+}
+
+func TestWorkerNetworkPolicy(t *testing.T) {
+	pipelineInfo := &pps.PipelineInfo{
+		Pipeline: client.NewPipeline("My_Pipeline"),
+		NetworkPolicy: &pps.NetworkPolicy{
+			Allow: []*pps.NetworkPeer{
+				{CIDR: "10.0.0.0/16", Ports: []int32{443, 8443}},
+				{PodLabels: map[string]string{"app": "postgres"}},
+			},
+		},
+	}
+	policy := workerNetworkPolicy(pipelineInfo, []string{"52.216.0.0/15"})
+	require.Equal(t, "pipeline-my-pipeline-f6d3d213", policy.Name)
+	require.Equal(t, "pipeline-my-pipeline", legacyNetworkPolicyName("My_Pipeline"))
+	require.Equal(t, "My_Pipeline", policy.Spec.PodSelector.MatchLabels[pipelineNameLabel])
+	// pachd, etcd, the pipeline's workers, DNS, the cluster's CIDR and the
+	// pipeline's two peers
+	rules := policy.Spec.Egress
+	require.Equal(t, 7, len(rules))
+	require.Equal(t, "pachd", rules[0].To[0].PodSelector.MatchLabels["app"])
+	require.Equal(t, "etcd", rules[1].To[0].PodSelector.MatchLabels["app"])
+	require.Equal(t, "My_Pipeline", rules[2].To[0].PodSelector.MatchLabels[pipelineNameLabel])
+	require.Equal(t, 53, rules[3].Ports[0].Port.IntValue())
+	require.Equal(t, "52.216.0.0/15", rules[4].To[0].IPBlock.CIDR)
+	require.Equal(t, 0, len(rules[4].Ports))
+	require.Equal(t, "10.0.0.0/16", rules[5].To[0].IPBlock.CIDR)
+	require.Equal(t, 2, len(rules[5].Ports))
+	require.Equal(t, 8443, rules[5].Ports[1].Port.IntValue())
+	require.Equal(t, "postgres", rules[6].To[0].PodSelector.MatchLabels["app"])
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// orphanGCInterval is how often the PPS master looks for the kubernetes
+// resources of pipelines that no longer exist
+const orphanGCInterval = 10 * time.Minute
+
+// orphan is a worker RC or service of a pipeline that no longer exists. These
+// are left behind if deleting a pipeline's resources fails.
+type orphan struct {
+	kind     string // "rc" or "service", as kubectl names them
+	name     string
+	pipeline string
+}
+
+func (o orphan) String() string {
+	return fmt.Sprintf("%s %s of pipeline %s", o.kind, o.name, o.pipeline)
+}
+
+// orphanOf returns the pipeline that the kubernetes object with 'meta'
+// belongs to, if the object is a pipeline's worker resource (i.e. it's named
+// per ppsutil.PipelineRcName and labelled with its pipeline) and its pipeline
+// isn't one of 'pipelines'
+func orphanOf(meta metav1.ObjectMeta, pipelines map[string]bool) (string, bool) {
+	pipeline, ok := meta.Labels[pipelineNameLabel]
+	if !ok || !strings.HasPrefix(meta.Name, "pipeline-") || pipelines[pipeline] {
+		return "", false
+	}
+	return pipeline, true
+}
+
+// findOrphans returns the 'rcs' and 'services' that belong to pipelines other
+// than 'pipelines', sorted by name
+func findOrphans(rcs []v1.ReplicationController, services []v1.Service, pipelines map[string]bool) []orphan {
+	var result []orphan
+	for _, rc := range rcs {
+		if pipeline, ok := orphanOf(rc.ObjectMeta, pipelines); ok {
+			result = append(result, orphan{kind: "rc", name: rc.Name, pipeline: pipeline})
+		}
+	}
+	for _, service := range services {
+		if pipeline, ok := orphanOf(service.ObjectMeta, pipelines); ok {
+			result = append(result, orphan{kind: "service", name: service.Name, pipeline: pipeline})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].name < result[j].name
+	})
+	return result
+}
+
+// orphanFindings reports 'orphans' for Diagnose. If 'dryRun' is set, the PPS
+// master only logs orphans, rather than deleting them.
+func orphanFindings(orphans []orphan, dryRun bool) []*pps.Finding {
+	if len(orphans) == 0 {
+		return nil
+	}
+	finding := &pps.Finding{
+		Severity: pps.FindingSeverity_FINDING_WARNING,
+		Check:    checkOrphans,
+		Summary: fmt.Sprintf("%d kubernetes resources belong to pipelines that no longer exist, and may still be using resources",
+			len(orphans)),
+	}
+	for _, o := range orphans {
+		finding.Details = append(finding.Details, o.String())
+	}
+	if dryRun {
+		for _, o := range orphans {
+			finding.Remediations = append(finding.Remediations, fmt.Sprintf("kubectl delete %s %s", o.kind, o.name))
+		}
+	} else {
+		finding.Remediations = []string{
+			fmt.Sprintf("none needed: pachd deletes them within %v", orphanGCInterval),
+		}
+	}
+	return []*pps.Finding{finding}
+}
+
+// findOrphanedResources returns the worker RCs and services of pipelines
+// that no longer exist
+func (a *apiServer) findOrphanedResources(ctx context.Context) ([]orphan, error) {
+	// List the kubernetes resources before the pipelines: a pipeline is
+	// written to etcd before its resources are created, so a resource that
+	// exists now belongs to a pipeline that is listed below, unless the
+	// pipeline has been deleted
+	kubeClient := a.env.GetKubeClient()
+	opts := metav1.ListOptions{LabelSelector: pipelineNameLabel}
+	rcs, err := kubeClient.CoreV1().ReplicationControllers(a.namespace).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list RCs: %v", err)
+	}
+	services, err := kubeClient.CoreV1().Services(a.namespace).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not list services: %v", err)
+	}
+	pipelines := make(map[string]bool)
+	if err := a.pipelines.ReadOnly(ctx).List(&pps.EtcdPipelineInfo{}, col.DefaultOptions, func(pipeline string) error {
+		pipelines[pipeline] = true
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return findOrphans(rcs.Items, services.Items, pipelines), nil
+}
+
+func (a *apiServer) deleteOrphan(o orphan) error {
+	kubeClient := a.env.GetKubeClient()
+	opts := &metav1.DeleteOptions{OrphanDependents: &falseVal}
+	var err error
+	switch o.kind {
+	case "rc":
+		err = kubeClient.CoreV1().ReplicationControllers(a.namespace).Delete(o.name, opts)
+	case "service":
+		err = kubeClient.CoreV1().Services(a.namespace).Delete(o.name, opts)
+	}
+	if err != nil && !isNotFoundErr(err) {
+		return err
+	}
+	return nil
+}
+
+// collectOrphans deletes the worker RCs and services of pipelines that no
+// longer exist every orphanGCInterval, until 'ctx' is cancelled. If pachd's
+// ORPHANED_WORKER_GC_DRY_RUN is set, it only logs them.
+func (a *apiServer) collectOrphans(ctx context.Context) {
+	ticker := time.NewTicker(orphanGCInterval)
+	defer ticker.Stop()
+	for {
+		orphans, err := a.findOrphanedResources(ctx)
+		if err != nil {
+			log.Errorf("PPS master: error finding orphaned worker resources: %v", err)
+		}
+		for _, o := range orphans {
+			if a.orphanGCDryRun {
+				log.Infof("PPS master: found orphaned %s (not deleting it, because of ORPHANED_WORKER_GC_DRY_RUN)", o)
+				continue
+			}
+			log.Infof("PPS master: deleting orphaned %s", o)
+			if err := a.deleteOrphan(o); err != nil {
+				log.Errorf("PPS master: error deleting orphaned %s: %v", o, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
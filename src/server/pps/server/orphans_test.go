@@ -0,0 +1,50 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func workerMeta(name, pipeline string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:   name,
+		Labels: map[string]string{pipelineNameLabel: pipeline},
+	}
+}
+
+func TestFindOrphans(t *testing.T) {
+	rcs := []v1.ReplicationController{
+		{ObjectMeta: workerMeta("pipeline-edges-v1", "edges")},
+		{ObjectMeta: workerMeta("pipeline-montage-v2", "montage")},
+		// not a worker RC, though it has the label
+		{ObjectMeta: workerMeta("etcd", "montage")},
+	}
+	services := []v1.Service{
+		{ObjectMeta: workerMeta("pipeline-montage-v2-user", "montage")},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pipeline-unlabelled"}},
+	}
+	orphans := findOrphans(rcs, services, map[string]bool{"edges": true})
+	require.Equal(t, 2, len(orphans))
+	require.Equal(t, orphan{kind: "rc", name: "pipeline-montage-v2", pipeline: "montage"}, orphans[0])
+	require.Equal(t, orphan{kind: "service", name: "pipeline-montage-v2-user", pipeline: "montage"}, orphans[1])
+
+	require.Equal(t, 0, len(findOrphans(rcs, services, map[string]bool{"edges": true, "montage": true})))
+}
+
+func TestOrphanFindings(t *testing.T) {
+	require.Equal(t, 0, len(orphanFindings(nil, false)))
+	orphans := []orphan{{kind: "rc", name: "pipeline-montage-v2", pipeline: "montage"}}
+	findings := orphanFindings(orphans, true)
+	require.Equal(t, 1, len(findings))
+	require.Equal(t, pps.FindingSeverity_FINDING_WARNING, findings[0].Severity)
+	require.Equal(t, "rc pipeline-montage-v2 of pipeline montage", findings[0].Details[0])
+	require.Equal(t, "kubectl delete rc pipeline-montage-v2", findings[0].Remediations[0])
+
+	findings = orphanFindings(orphans, false)
+	require.Matches(t, "pachd deletes them", findings[0].Remediations[0])
+}
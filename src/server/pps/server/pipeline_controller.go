@@ -9,6 +9,7 @@ import (
 
 	"github.com/pachyderm/pachyderm/src/client"
 
+	"github.com/gogo/protobuf/types"
 	opentracing "github.com/opentracing/opentracing-go"
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
@@ -20,6 +21,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/pps"
 	"github.com/pachyderm/pachyderm/src/client/version"
 	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
 )
 
@@ -97,6 +99,14 @@ func (a *apiServer) step(pachClient *client.APIClient, pipeline string, keyVer,
 		pachClient = pachClient.WithCtx(ctx) //lint:ignore SA4006 pachClient is unused but we want the right one in scope in case someone uses it below in the future
 	}
 
+	// Fail the pipeline if its workers would be from a different release than
+	// pachd, rather than letting them fail with RPC errors
+	if op.ptr.State != pps.PipelineState_PIPELINE_FAILURE {
+		if err := workerImageSkew(a.workerImage, version.PrettyVersion()); err != nil {
+			return op.failPipelineWithCode(pps.PipelineReasonCode_PIPELINE_REASON_VERSION_SKEW, err.Error())
+		}
+	}
+
 	// Bring 'pipeline' into the correct state by taking appropriate action
 	switch op.ptr.State {
 	case pps.PipelineState_PIPELINE_STARTING, pps.PipelineState_PIPELINE_RESTARTING:
@@ -227,7 +237,10 @@ func (op *pipelineOp) getRC(expectation rcExpectation) (retErr error) {
 
 	kubeClient := op.apiServer.env.GetKubeClient()
 	namespace := op.apiServer.namespace
-	selector := fmt.Sprintf("%s=%s", pipelineNameLabel, op.name)
+	// The RCs of the pipeline's datum profiles, worker pools and replays are
+	// managed separately (see createDatumProfileRCs, createWorkerPoolRCs and
+	// ReplayJob)
+	selector := fmt.Sprintf("%s=%s,!%s,!%s,!%s", pipelineNameLabel, op.name, datumProfileLabel, workerPoolLabel, replayLabel)
 
 	// count error types separately, so that this only errors if the pipeline is
 	// stuck and not changing
@@ -312,6 +325,7 @@ func (op *pipelineOp) rcIsFresh() bool {
 	rcPachVersion := op.rc.ObjectMeta.Annotations[pachVersionAnnotation]
 	rcAuthTokenHash := op.rc.ObjectMeta.Annotations[hashedAuthTokenAnnotation]
 	rcSpecCommit := op.rc.ObjectMeta.Annotations[specCommitAnnotation]
+	rcImage := rcWorkerImage(op.rc)
 	switch {
 	case rcAuthTokenHash != hashAuthToken(op.ptr.AuthToken):
 		log.Errorf("PPS master: auth token in %q is stale %s != %s",
@@ -325,6 +339,10 @@ func (op *pipelineOp) rcIsFresh() bool {
 		log.Errorf("PPS master: %q is using stale pachd v%s != current v%s",
 			op.name, rcPachVersion, version.PrettyVersion())
 		return false
+	case rcImage != "" && rcImage != op.apiServer.workerImage:
+		log.Errorf("PPS master: worker image in %q is stale %s != %s",
+			op.name, rcImage, op.apiServer.workerImage)
+		return false
 	case expectedName != "" && rcName != expectedName:
 		log.Errorf("PPS master: %q has an unexpected (likely stale) name %q != %q",
 			op.name, rcName, expectedName)
@@ -382,8 +400,8 @@ func (op *pipelineOp) createPipelineResources() error {
 }
 
 // startPipelineMonitor spawns a monitorPipeline() goro for this pipeline (if
-// one doesn't exist already), which manages standby and cron inputs, and
-// updates the the pipeline state.
+// one doesn't exist already), which manages standby, cron inputs and service
+// autoscaling, and updates the the pipeline state.
 // Note: this is called by every run through step(), so must be idempotent
 func (op *pipelineOp) startPipelineMonitor() {
 	op.apiServer.monitorCancelsMu.Lock()
@@ -530,20 +548,53 @@ func (op *pipelineOp) scaleUpPipeline() (retErr error) {
 
 	// compute target pipeline parallelism
 	kubeClient := op.apiServer.env.GetKubeClient()
-	parallelism, err := ppsutil.GetExpectedNumWorkers(kubeClient, op.pipelineInfo.ParallelismSpec)
+	parallelism, err := ppsutil.GetExpectedNumWorkers(op.pachClient.Ctx(), kubeClient, op.pipelineInfo.ParallelismSpec)
 	if err != nil {
 		log.Errorf("PPS master: error getting number of workers (defaulting to 1 worker): %v", err)
 		parallelism = 1
 	}
+	// Services with autoscaling run as many workers as their autoscaler chose
+	if autoscaling := op.pipelineInfo.Service.GetAutoscaling(); autoscaling != nil {
+		parallelism = int(ppsutil.ServiceReplicas(autoscaling, op.ptr.ServiceAutoscaling))
+	}
 
 	// update pipeline RC
-	return op.updateRC(func(rc *v1.ReplicationController) {
+	if err := op.updateRC(func(rc *v1.ReplicationController) {
 		if rc.Spec.Replicas != nil && *op.rc.Spec.Replicas == int32(parallelism) {
 			return // prior attempt succeeded
 		}
 		rc.Spec.Replicas = new(int32)
 		*rc.Spec.Replicas = int32(parallelism)
+	}); err != nil {
+		return err
+	}
+	if ppsutil.StandbyWakePending(op.ptr) && op.ptr.StandbyWake.WorkersScaled == nil {
+		if err := op.recordWorkersScaled(); err != nil {
+			log.Errorf("PPS master: error recording standby wake of %q: %v", op.name, err)
+		}
+	}
+	if err := op.scaleWorkerPools(int32(parallelism)); err != nil {
+		return err
+	}
+	return op.scaleDatumProfiles(true)
+}
+
+// recordWorkersScaled records that the workers of op's pipeline, which is
+// waking from standby, have been scaled up (see pps.StandbyWake)
+func (op *pipelineOp) recordWorkersScaled() error {
+	_, err := col.NewSTM(op.pachClient.Ctx(), op.apiServer.env.GetEtcdClient(), func(stm col.STM) error {
+		pipelines := op.apiServer.pipelines.ReadWrite(stm)
+		pipelinePtr := &pps.EtcdPipelineInfo{}
+		if err := pipelines.Get(op.name, pipelinePtr); err != nil {
+			return err
+		}
+		if !ppsutil.StandbyWakePending(pipelinePtr) || pipelinePtr.StandbyWake.WorkersScaled != nil {
+			return nil
+		}
+		pipelinePtr.StandbyWake.WorkersScaled = types.TimestampNow()
+		return pipelines.Put(op.name, pipelinePtr)
 	})
+	return err
 }
 
 // scaleDownPipeline edits the RC associated with op's pipeline & spins down the
@@ -563,12 +614,18 @@ func (op *pipelineOp) scaleDownPipeline() (retErr error) {
 		tracing.FinishAnySpan(span)
 	}()
 
-	return op.updateRC(func(rc *v1.ReplicationController) {
+	if err := op.updateRC(func(rc *v1.ReplicationController) {
 		if rc.Spec.Replicas != nil && *op.rc.Spec.Replicas == 0 {
 			return // prior attempt succeeded
 		}
 		rc.Spec.Replicas = &zero
-	})
+	}); err != nil {
+		return err
+	}
+	if err := op.scaleWorkerPools(0); err != nil {
+		return err
+	}
+	return op.scaleDatumProfiles(false)
 }
 
 // restartPipeline updates the RC/service associated with op's pipeline, and
@@ -603,6 +660,9 @@ func (op *pipelineOp) restartPipeline(reason string) error {
 			if err != nil && !isNotFoundErr(err) {
 				return fmt.Errorf("could not delete RC %q: %v", op.rc.Name, err)
 			}
+			if err := op.deleteStaleServices(op.rc.Name); err != nil {
+				return err
+			}
 		}
 		// create up-to-date RC
 		if err := op.createPipelineResources(); err != nil {
@@ -621,6 +681,33 @@ func (op *pipelineOp) restartPipeline(reason string) error {
 	return fmt.Errorf("restarting pipeline %q: %v", op.name, reason)
 }
 
+// deleteStaleServices deletes the services of op's stale RC 'rcName', if the
+// up-to-date RC has a different name (e.g. because the pipeline was updated,
+// or because the RC was created by a version of pachd that named RCs
+// differently), as the up-to-date RC's services are named after it.
+func (op *pipelineOp) deleteStaleServices(rcName string) error {
+	if op.pipelineInfo == nil || rcName == ppsutil.PipelineRcName(op.name, op.pipelineInfo.Version) {
+		return nil
+	}
+	kubeClient := op.apiServer.env.GetKubeClient()
+	services := kubeClient.CoreV1().Services(op.apiServer.namespace)
+	list, err := services.List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", pipelineNameLabel, op.name),
+	})
+	if err != nil {
+		return fmt.Errorf("could not list services: %v", err)
+	}
+	for _, service := range list.Items {
+		if service.Name != rcName && service.Name != rcName+"-user" {
+			continue
+		}
+		if err := services.Delete(service.Name, &metav1.DeleteOptions{OrphanDependents: &falseVal}); err != nil && !isNotFoundErr(err) {
+			return fmt.Errorf("could not delete service %q: %v", service.Name, err)
+		}
+	}
+	return nil
+}
+
 // failPipeline fails op's pipeline. failPipeline is an error-handling codepath,
 // so it's guaranteed to return an error (typically wrapping 'reason', though if
 // the restart process fails that error will take precendence) so that callers
@@ -638,3 +725,13 @@ func (op *pipelineOp) failPipeline(reason string) error {
 	}
 	return fmt.Errorf("failing pipeline %q: %v", op.name, reason)
 }
+
+// failPipelineWithCode is like failPipeline, but also records a code that
+// identifies the cause of the failure
+func (op *pipelineOp) failPipelineWithCode(code pps.PipelineReasonCode, reason string) error {
+	if err := ppsutil.FailPipelineWithCode(op.pachClient.Ctx(), op.apiServer.env.GetEtcdClient(),
+		op.apiServer.pipelines, op.name, code, reason); err != nil {
+		return fmt.Errorf("error failing pipeline %q: %v", op.name, err)
+	}
+	return fmt.Errorf("failing pipeline %q: %v", op.name, reason)
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kube_watch "k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
+)
+
+// pipelineCRDResource identifies the Pipeline custom resource, created by
+// 'pachctl deploy --pipeline-crd'
+var pipelineCRDResource = schema.GroupVersionResource{
+	Group:    client.PipelineCRDGroup,
+	Version:  client.PipelineCRDVersion,
+	Resource: client.PipelineCRDPlural,
+}
+
+// pipelineRequestFromResource converts the spec of a Pipeline resource, which
+// is a pipeline spec, into a CreatePipelineRequest. The pipeline's name
+// defaults to the resource's name and, if set, must match it.
+func pipelineRequestFromResource(obj *unstructured.Unstructured) (*pps.CreatePipelineRequest, error) {
+	spec, ok, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("Pipeline %q has no spec", obj.GetName())
+	}
+	specBytes, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	request, err := ppsutil.NewPipelineManifestReaderFromBytes(specBytes).NextCreatePipelineRequest()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("Pipeline %q has an empty spec", obj.GetName())
+		}
+		return nil, fmt.Errorf("malformed spec in Pipeline %q: %v", obj.GetName(), err)
+	}
+	if request.Pipeline == nil || request.Pipeline.Name == "" {
+		request.Pipeline = client.NewPipeline(obj.GetName())
+	} else if request.Pipeline.Name != obj.GetName() {
+		return nil, fmt.Errorf("the pipeline name in the spec of Pipeline %q (%q) must match the resource's name", obj.GetName(), request.Pipeline.Name)
+	}
+	return request, nil
+}
+
+// syncPipelineResources creates, updates and deletes pipelines as Pipeline
+// resources in pachd's namespace are created, modified and deleted, until
+// this process loses the master lock. Pipelines are created with
+// 'pachClient', which has no auth token, so if auth is active every Pipeline
+// resource reports an error in its status.
+func (a *apiServer) syncPipelineResources(ctx context.Context, pachClient *client.APIClient) {
+	resources := a.env.GetDynamicKubeClient().Resource(pipelineCRDResource).Namespace(a.namespace)
+	backoff.RetryNotify(func() error {
+		list, err := resources.List(metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for i := range list.Items {
+			a.syncPipelineResource(pachClient, resources, &list.Items[i])
+		}
+		watcher, err := resources.Watch(metav1.ListOptions{
+			ResourceVersion: list.GetResourceVersion(),
+		})
+		if err != nil {
+			return err
+		}
+		defer watcher.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case event, ok := <-watcher.ResultChan():
+				if !ok {
+					return fmt.Errorf("Pipeline resource watch closed")
+				}
+				obj, isUnstructured := event.Object.(*unstructured.Unstructured)
+				switch {
+				case event.Type == kube_watch.Error || !isUnstructured:
+					return fmt.Errorf("error watching Pipeline resources: %v", event.Object)
+				case event.Type == kube_watch.Added || event.Type == kube_watch.Modified:
+					a.syncPipelineResource(pachClient, resources, obj)
+				case event.Type == kube_watch.Deleted:
+					if !pipelineResourceSynced(obj) {
+						continue // the resource never created a pipeline
+					}
+					if err := pachClient.DeletePipeline(obj.GetName(), false); err != nil && !isNotFoundErr(err) {
+						log.Errorf("PPS master: error deleting pipeline for deleted Pipeline resource %q: %v", obj.GetName(), err)
+					}
+				}
+			}
+		}
+	}, backoff.NewInfiniteBackOff(), notifyCtx(ctx, "syncPipelineResources"))
+}
+
+// pipelineResourceSynced returns true if the pipeline described by 'obj' was
+// created or updated successfully at some point
+func pipelineResourceSynced(obj *unstructured.Unstructured) bool {
+	_, ok, _ := unstructured.NestedInt64(obj.Object, "status", "pipelineVersion")
+	return ok
+}
+
+// syncPipelineResource creates or updates the pipeline described by 'obj', if
+// its spec has changed since it was last synced, and records the result in
+// its status
+func (a *apiServer) syncPipelineResource(pachClient *client.APIClient, resources dynamic.ResourceInterface, obj *unstructured.Unstructured) {
+	observed, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if observed != 0 && observed == obj.GetGeneration() {
+		return // already synced
+	}
+	// pipelineVersion is kept if the spec can't be applied, as the pipeline
+	// created from the previous spec still exists
+	pipelineVersion, hasPipeline, _ := unstructured.NestedInt64(obj.Object, "status", "pipelineVersion")
+	err := func() error {
+		request, err := pipelineRequestFromResource(obj)
+		if err != nil {
+			return err
+		}
+		if _, err := pachClient.InspectPipeline(request.Pipeline.Name); err == nil {
+			request.Update = true
+		} else if !isNotFoundErr(err) {
+			return err
+		}
+		if _, err := pachClient.PpsAPIClient.CreatePipeline(pachClient.Ctx(), request); err != nil {
+			return err
+		}
+		pipelineInfo, err := pachClient.InspectPipeline(request.Pipeline.Name)
+		if err != nil {
+			return err
+		}
+		pipelineVersion, hasPipeline = int64(pipelineInfo.Version), true
+		return nil
+	}()
+	status := map[string]interface{}{
+		"observedGeneration": obj.GetGeneration(),
+	}
+	if err != nil {
+		log.Errorf("PPS master: error syncing Pipeline resource %q: %v", obj.GetName(), err)
+		status["error"] = err.Error()
+	}
+	if hasPipeline {
+		status["pipelineVersion"] = pipelineVersion
+	}
+	obj = obj.DeepCopy()
+	if err := unstructured.SetNestedMap(obj.Object, status, "status"); err != nil {
+		log.Errorf("PPS master: error setting status of Pipeline resource %q: %v", obj.GetName(), err)
+		return
+	}
+	if _, err := resources.UpdateStatus(obj, metav1.UpdateOptions{}); err != nil {
+		log.Errorf("PPS master: error updating status of Pipeline resource %q: %v", obj.GetName(), err)
+	}
+}
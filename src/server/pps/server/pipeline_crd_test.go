@@ -0,0 +1,58 @@
+package server
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func pipelineResource(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetName(name)
+	if spec != nil {
+		obj.Object["spec"] = spec
+	}
+	return obj
+}
+
+func TestPipelineRequestFromResource(t *testing.T) {
+	spec := map[string]interface{}{
+		"transform": map[string]interface{}{
+			"image": "ubuntu",
+			"cmd":   []interface{}{"cp", "-r", "/pfs/in", "/pfs/out"},
+		},
+		"input": map[string]interface{}{
+			"pfs": map[string]interface{}{"repo": "in", "glob": "/*"},
+		},
+		"parallelism_spec": map[string]interface{}{"constant": int64(2)},
+	}
+	request, err := pipelineRequestFromResource(pipelineResource("copy", spec))
+	require.NoError(t, err)
+	require.Equal(t, "copy", request.Pipeline.Name)
+	require.Equal(t, "ubuntu", request.Transform.Image)
+	require.Equal(t, "in", request.Input.Pfs.Repo)
+	require.Equal(t, uint64(2), request.ParallelismSpec.Constant)
+
+	spec["pipeline"] = map[string]interface{}{"name": "copy"}
+	_, err = pipelineRequestFromResource(pipelineResource("copy", spec))
+	require.NoError(t, err)
+	spec["pipeline"] = map[string]interface{}{"name": "other"}
+	_, err = pipelineRequestFromResource(pipelineResource("copy", spec))
+	require.YesError(t, err)
+
+	_, err = pipelineRequestFromResource(pipelineResource("copy", nil))
+	require.YesError(t, err)
+	_, err = pipelineRequestFromResource(pipelineResource("copy", map[string]interface{}{"transform": "ubuntu"}))
+	require.YesError(t, err)
+}
+
+func TestPipelineResourceSynced(t *testing.T) {
+	obj := pipelineResource("copy", nil)
+	require.False(t, pipelineResourceSynced(obj))
+	obj.Object["status"] = map[string]interface{}{"observedGeneration": int64(1), "error": "invalid spec"}
+	require.False(t, pipelineResourceSynced(obj))
+	obj.Object["status"] = map[string]interface{}{"observedGeneration": int64(2), "pipelineVersion": int64(1)}
+	require.True(t, pipelineResourceSynced(obj))
+}
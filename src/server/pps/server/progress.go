@@ -0,0 +1,70 @@
+package server
+
+import (
+	"time"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+const (
+	// progressWindow is the period over which JobProgress measures throughput
+	progressWindow = time.Minute
+	// progressInterval is how often JobProgress sends an update if the job's
+	// datum counts haven't changed
+	progressInterval = 5 * time.Second
+)
+
+type progressSample struct {
+	t    time.Time
+	done int64
+}
+
+// progressEstimator computes the throughput and ETA of a job from successive
+// observations of its datum counts
+type progressEstimator struct {
+	// samples holds the observations in progressWindow, plus the last one
+	// before it (which is the baseline for the throughput)
+	samples []progressSample
+}
+
+// update records the datum counts in 'jobPtr', observed at 'now', and returns
+// the job's progress
+func (e *progressEstimator) update(now time.Time, jobPtr *pps.EtcdJobInfo) *pps.JobProgress {
+	progress := &pps.JobProgress{
+		Job:           jobPtr.Job,
+		State:         jobPtr.State,
+		DataProcessed: jobPtr.DataProcessed,
+		DataSkipped:   jobPtr.DataSkipped,
+		DataFailed:    jobPtr.DataFailed,
+		DataRecovered: jobPtr.DataRecovered,
+		DataTotal:     jobPtr.DataTotal,
+	}
+	done := jobPtr.DataProcessed + jobPtr.DataSkipped + jobPtr.DataFailed + jobPtr.DataRecovered
+	if n := len(e.samples); n > 0 && done < e.samples[n-1].done {
+		// The job restarted, so older samples no longer apply
+		e.samples = nil
+	}
+	if len(e.samples) == 0 && jobPtr.Started != nil {
+		// Use the job's start as the baseline, so that the first update
+		// already has a throughput
+		if started, err := types.TimestampFromProto(jobPtr.Started); err == nil && started.Before(now) && done > 0 {
+			e.samples = append(e.samples, progressSample{t: started})
+		}
+	}
+	e.samples = append(e.samples, progressSample{t: now, done: done})
+	for len(e.samples) > 2 && !e.samples[1].t.After(now.Add(-progressWindow)) {
+		e.samples = e.samples[1:]
+	}
+
+	first := e.samples[0]
+	if elapsed := now.Sub(first.t); elapsed > 0 {
+		progress.Throughput = float64(done-first.done) / elapsed.Seconds()
+	}
+	if jobPtr.State == pps.JobState_JOB_RUNNING && progress.Throughput > 0 && done < jobPtr.DataTotal {
+		remaining := float64(jobPtr.DataTotal-done) / progress.Throughput
+		progress.ETA = types.DurationProto(time.Duration(remaining * float64(time.Second)))
+	}
+	return progress
+}
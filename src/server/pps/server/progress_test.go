@@ -0,0 +1,59 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestProgressEstimator(t *testing.T) {
+	start := time.Now()
+	started, err := types.TimestampProto(start)
+	require.NoError(t, err)
+	jobPtr := &pps.EtcdJobInfo{
+		Job:       client.NewJob("job"),
+		State:     pps.JobState_JOB_RUNNING,
+		DataTotal: 100,
+		Started:   started,
+	}
+	e := &progressEstimator{}
+
+	// No datums are finished, so there's no ETA
+	p := e.update(start, jobPtr)
+	require.Equal(t, 0.0, p.Throughput)
+	require.Nil(t, p.ETA)
+
+	// 20 datums in 10s (measured from the job's start, as this is a new
+	// estimator) leaves 80 datums at 2 datums/s
+	e = &progressEstimator{}
+	jobPtr.DataProcessed, jobPtr.DataSkipped = 15, 5
+	p = e.update(start.Add(10*time.Second), jobPtr)
+	require.Equal(t, 2.0, p.Throughput)
+	eta, err := types.DurationFromProto(p.ETA)
+	require.NoError(t, err)
+	require.Equal(t, 40*time.Second, eta)
+
+	// Throughput is measured over the last minute only
+	jobPtr.DataProcessed = 50
+	e.update(start.Add(70*time.Second), jobPtr)
+	jobPtr.DataProcessed = 65
+	p = e.update(start.Add(130*time.Second), jobPtr)
+	require.Equal(t, 0.25, p.Throughput)
+
+	// A restart resets the estimate
+	jobPtr.DataProcessed, jobPtr.DataSkipped = 0, 0
+	p = e.update(start.Add(140*time.Second), jobPtr)
+	require.Equal(t, 0.0, p.Throughput)
+	require.Nil(t, p.ETA)
+
+	// Finished jobs have no ETA
+	jobPtr.DataProcessed = 100
+	jobPtr.State = pps.JobState_JOB_SUCCESS
+	p = e.update(start.Add(150*time.Second), jobPtr)
+	require.Nil(t, p.ETA)
+}
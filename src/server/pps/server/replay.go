@@ -0,0 +1,261 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	pfsServer "github.com/pachyderm/pachyderm/src/server/pfs"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ancestry"
+	"github.com/pachyderm/pachyderm/src/server/pkg/metrics"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// replayLabel is the label of the RCs that run the workers of job replays
+// (see ReplayJob), whose value is the ID of the job being replayed. Like
+// datumProfileLabel, it keeps the PPS master from mistaking them for the
+// pipeline's own RC.
+const replayLabel = "replayOf"
+
+// jobWorkerPoolID returns the name of the RC whose workers process the job
+// 'jobInfo', which is a replay's RC if the job is a replay, and the RC of the
+// job's worker pool if its pipeline runs concurrent jobs
+func jobWorkerPoolID(jobInfo *pps.JobInfo) string {
+	if jobInfo.ReplayOf != nil {
+		return ppsutil.ReplayRcName(jobInfo.Pipeline.Name, jobInfo.PipelineVersion, jobInfo.ReplayOf.ID)
+	}
+	return ppsutil.WorkerPoolRcName(jobInfo.Pipeline.Name, jobInfo.PipelineVersion, jobInfo.WorkerPool)
+}
+
+// replayDiffs returns the files whose content differs between a job's output
+// commit and its replay's, ordered by path, given the result of diffing the
+// replay's commit (as the new commit) with the job's: 'newFiles' are the
+// replay's files that are new or changed, and 'oldFiles' are the job's files
+// that are changed or missing from the replay.
+func replayDiffs(newFiles []*pfs.FileInfo, oldFiles []*pfs.FileInfo) []*pps.ReplayDiff {
+	diffs := make(map[string]*pps.ReplayDiff)
+	diff := func(path string) *pps.ReplayDiff {
+		if d, ok := diffs[path]; ok {
+			return d
+		}
+		d := &pps.ReplayDiff{Path: path}
+		diffs[path] = d
+		return d
+	}
+	// Directories differ whenever the files in them do, so they're left out
+	for _, fileInfo := range oldFiles {
+		if fileInfo.FileType == pfs.FileType_FILE {
+			d := diff(fileInfo.File.Path)
+			d.OriginalHash, d.OriginalSizeBytes = fileInfo.Hash, fileInfo.SizeBytes
+		}
+	}
+	for _, fileInfo := range newFiles {
+		if fileInfo.FileType == pfs.FileType_FILE {
+			d := diff(fileInfo.File.Path)
+			d.ReplayHash, d.ReplaySizeBytes = fileInfo.Hash, fileInfo.SizeBytes
+		}
+	}
+	var result []*pps.ReplayDiff
+	for _, d := range diffs {
+		result = append(result, d)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+// createReplayRC creates the RC of the workers that replay 'job', which run
+// 'pipelineInfo' (the job's version of its pipeline), and returns the RC's
+// name
+func (a *apiServer) createReplayRC(ctx context.Context, ptr *pps.EtcdPipelineInfo, pipelineInfo *pps.PipelineInfo, job *pps.Job) (string, error) {
+	options, err := a.getWorkerOptions(ptr, pipelineInfo)
+	if err != nil {
+		return "", err
+	}
+	options.rcName = ppsutil.ReplayRcName(pipelineInfo.Pipeline.Name, pipelineInfo.Version, job.ID)
+	options.labels = labels(options.rcName)
+	options.labels[pipelineNameLabel] = pipelineInfo.Pipeline.Name
+	options.labels[replayLabel] = job.ID
+	options.workerEnv = append(options.workerEnv, v1.EnvVar{
+		Name:  client.PPSReplayJobEnv,
+		Value: job.ID,
+	})
+	parallelism, err := ppsutil.GetExpectedNumWorkers(ctx, a.env.GetKubeClient(), pipelineInfo.ParallelismSpec)
+	if err != nil {
+		return "", err
+	}
+	options.parallelism = int32(parallelism)
+	podSpec, err := a.workerPodSpec(options)
+	if err != nil {
+		return "", err
+	}
+	rcs := a.env.GetKubeClient().CoreV1().ReplicationControllers(a.namespace)
+	if _, err := rcs.Create(workerRc(options, podSpec)); err != nil {
+		if isAlreadyExistsErr(err) {
+			return "", fmt.Errorf("job %s is already being replayed", job.ID)
+		}
+		return "", err
+	}
+	return options.rcName, nil
+}
+
+// abandonReplay finishes the output commit 'commit' of a replay that didn't
+// complete (e.g. because the caller went away), and kills the replay's job if
+// it has one, as the replay's workers are deleted before they can. It acts
+// with the pipeline's auth token 'authToken', since the caller's context may
+// be done.
+func (a *apiServer) abandonReplay(authToken string, commit *pfs.Commit) error {
+	pachClient := a.env.GetPachClient(context.Background())
+	pachClient.SetAuthToken(authToken)
+	if _, err := pachClient.PfsAPIClient.FinishCommit(pachClient.Ctx(), &pfs.FinishCommitRequest{
+		Commit: commit,
+		Empty:  true,
+	}); err != nil && !pfsServer.IsCommitFinishedErr(err) {
+		return err
+	}
+	jobInfo, err := pachClient.InspectJobOutputCommit(commit.Repo.Name, commit.ID, false)
+	if err != nil {
+		if isNotFoundErr(err) {
+			return nil // the replay's job was never created
+		}
+		return err
+	}
+	if ppsutil.IsTerminal(jobInfo.State) {
+		return nil
+	}
+	_, err = pachClient.PpsAPIClient.UpdateJobState(pachClient.Ctx(), &pps.UpdateJobStateRequest{
+		Job:    jobInfo.Job,
+		State:  pps.JobState_JOB_KILLED,
+		Reason: "the replay was abandoned",
+	})
+	return err
+}
+
+// ReplayJob implements the protobuf pps.ReplayJob RPC
+func (a *apiServer) ReplayJob(ctx context.Context, request *pps.ReplayJobRequest) (response *pps.ReplayJobResponse, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	metricsFn := metrics.ReportUserAction(ctx, a.reporter, "ReplayJob")
+	defer func(start time.Time) { metricsFn(start, retErr) }(time.Now())
+
+	pachClient := a.env.GetPachClient(ctx)
+	ctx, err := checkLoggedIn(pachClient)
+	if err != nil {
+		return nil, err
+	}
+	if request.Job == nil {
+		return nil, fmt.Errorf("must specify a job")
+	}
+	jobInfo, err := a.InspectJob(ctx, &pps.InspectJobRequest{Job: request.Job})
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case jobInfo.ReplayOf != nil:
+		return nil, fmt.Errorf("job %s is a replay of job %s; replay that job instead", jobInfo.Job.ID, jobInfo.ReplayOf.ID)
+	case jobInfo.Service != nil || jobInfo.Spout != nil:
+		return nil, fmt.Errorf("jobs of services and spouts can't be replayed")
+	case jobInfo.State != pps.JobState_JOB_SUCCESS:
+		return nil, fmt.Errorf("job %s is %s, but only successful jobs can be replayed", jobInfo.Job.ID, jobStateName(jobInfo.State))
+	}
+	repo := jobInfo.OutputCommit.Repo.Name
+	branch := request.Branch
+	if branch == "" {
+		branch = "replay-" + jobInfo.Job.ID
+	}
+	if err := ancestry.ValidateName(branch); err != nil {
+		return nil, err
+	}
+	if branch == jobInfo.OutputBranch || branch == "stats" {
+		return nil, fmt.Errorf("can't put the replay on the pipeline's %q branch", branch)
+	}
+
+	// The replay's workers run the version of the pipeline (and so the image
+	// digest, if it's pinned) that the job ran with
+	pipelinePtr := &pps.EtcdPipelineInfo{}
+	if err := a.pipelines.ReadOnly(ctx).Get(jobInfo.Pipeline.Name, pipelinePtr); err != nil {
+		return nil, err
+	}
+	pipelinePtr.SpecCommit = jobInfo.SpecCommit
+	pipelineInfo, err := ppsutil.GetPipelineInfo(pachClient, pipelinePtr)
+	if err != nil {
+		return nil, err
+	}
+	if pipelineInfo.Backend != nil {
+		return nil, fmt.Errorf("jobs of pipelines with an execution backend can't be replayed")
+	}
+	originalCommitInfo, err := pachClient.InspectCommit(repo, jobInfo.OutputCommit.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The replay's output commit has the same provenance as the job's, so that
+	// the replay reads the same input commits. It's on no branch until it's
+	// finished, as the replay's branch has no provenance.
+	commit, err := pachClient.PfsAPIClient.StartCommit(ctx, &pfs.StartCommitRequest{
+		Parent:      client.NewCommit(repo, ""),
+		Provenance:  originalCommitInfo.Provenance,
+		Description: fmt.Sprintf("replay of job %s", jobInfo.Job.ID),
+		Metadata:    map[string]string{ppsconsts.ReplayMetadataKey: jobInfo.Job.ID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if retErr != nil {
+			if err := a.abandonReplay(pipelinePtr.AuthToken, commit); err != nil {
+				log.Errorf("error abandoning the replay of job %s: %v", jobInfo.Job.ID, err)
+			}
+		}
+	}()
+	rcName, err := a.createReplayRC(ctx, pipelinePtr, pipelineInfo, jobInfo.Job)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		rcs := a.env.GetKubeClient().CoreV1().ReplicationControllers(a.namespace)
+		if err := rcs.Delete(rcName, &metav1.DeleteOptions{OrphanDependents: &falseVal}); err != nil && !isNotFoundErr(err) && retErr == nil {
+			retErr = fmt.Errorf("could not delete RC %q: %v", rcName, err)
+		}
+	}()
+
+	if _, err := pachClient.PfsAPIClient.InspectCommit(ctx, &pfs.InspectCommitRequest{
+		Commit:     commit,
+		BlockState: pfs.CommitState_FINISHED,
+	}); err != nil {
+		return nil, err
+	}
+	replayInfo, err := pachClient.InspectJobOutputCommit(repo, commit.ID, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := pachClient.CreateBranch(repo, branch, commit.ID, nil); err != nil {
+		return nil, err
+	}
+	response = &pps.ReplayJobResponse{
+		Job:          replayInfo.Job,
+		State:        replayInfo.State,
+		Reason:       replayInfo.Reason,
+		OutputCommit: commit,
+	}
+	if replayInfo.State != pps.JobState_JOB_SUCCESS {
+		return response, nil
+	}
+	newFiles, oldFiles, err := pachClient.DiffFile(repo, commit.ID, "", repo, jobInfo.OutputCommit.ID, "", false)
+	if err != nil {
+		return nil, err
+	}
+	response.Diffs = replayDiffs(newFiles, oldFiles)
+	response.Reproducible = len(response.Diffs) == 0
+	return response, nil
+}
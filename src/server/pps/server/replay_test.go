@@ -0,0 +1,39 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestReplayDiffs(t *testing.T) {
+	fileInfo := func(commit, path string, hash string, fileType pfs.FileType) *pfs.FileInfo {
+		return &pfs.FileInfo{
+			File:      client.NewFile("out", commit, path),
+			FileType:  fileType,
+			Hash:      []byte(hash),
+			SizeBytes: uint64(len(hash)),
+		}
+	}
+	// The replay changed /b, added /c and left out /a
+	newFiles := []*pfs.FileInfo{
+		fileInfo("replay", "/", "r", pfs.FileType_DIR),
+		fileInfo("replay", "/c", "c1", pfs.FileType_FILE),
+		fileInfo("replay", "/b", "b2", pfs.FileType_FILE),
+	}
+	oldFiles := []*pfs.FileInfo{
+		fileInfo("original", "/", "o", pfs.FileType_DIR),
+		fileInfo("original", "/a", "a", pfs.FileType_FILE),
+		fileInfo("original", "/b", "b1", pfs.FileType_FILE),
+	}
+	require.Equal(t, []*pps.ReplayDiff{
+		{Path: "/a", OriginalHash: []byte("a"), OriginalSizeBytes: 1},
+		{Path: "/b", OriginalHash: []byte("b1"), OriginalSizeBytes: 2, ReplayHash: []byte("b2"), ReplaySizeBytes: 2},
+		{Path: "/c", ReplayHash: []byte("c1"), ReplaySizeBytes: 2},
+	}, replayDiffs(newFiles, oldFiles))
+
+	require.Equal(t, 0, len(replayDiffs(nil, nil)))
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ancestry"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// validatePipelineName returns an error if 'name' can't be the name of a
+// pipeline. Besides being a valid repo name (the pipeline's output repo has
+// the same name), it's the value of the pipelineNameLabel of the pipeline's
+// kubernetes resources, so it must be a valid label value.
+func validatePipelineName(name string) error {
+	if err := ancestry.ValidateName(name); err != nil {
+		return fmt.Errorf("invalid pipeline name: %v", err)
+	}
+	if len(name) > validation.LabelValueMaxLength {
+		return fmt.Errorf("pipeline name is %d characters long, but must have at most %d: %q",
+			len(name), validation.LabelValueMaxLength, name)
+	}
+	if !isAlphanumeric(name[0]) || !isAlphanumeric(name[len(name)-1]) {
+		return fmt.Errorf("invalid pipeline name %q: pipeline names must start and end with an alphanumeric character", name)
+	}
+	return nil
+}
+
+func isAlphanumeric(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// collidingPipeline returns the pipeline in 'pipelines', other than 'name'
+// itself, whose kubernetes resources would have the same names as those of
+// the pipeline 'name', if any. Resource names include a hash of the pipeline
+// name, so this only happens if two long names hash to the same value and
+// are the same once they're truncated.
+func collidingPipeline(name string, pipelines []string) (string, bool) {
+	// RC names are truncated further as the pipeline's version grows, so
+	// compare them at the largest version
+	rcName := ppsutil.PipelineRcName(name, math.MaxUint64)
+	resourceName := ppsutil.PipelineResourceName(name)
+	for _, other := range pipelines {
+		if other == name {
+			continue
+		}
+		if ppsutil.PipelineRcName(other, math.MaxUint64) == rcName ||
+			ppsutil.PipelineResourceName(other) == resourceName {
+			return other, true
+		}
+	}
+	return "", false
+}
+
+// checkResourceNames returns an error if the kubernetes resources of the new
+// pipeline 'name' would collide with those of an existing pipeline
+func (a *apiServer) checkResourceNames(ctx context.Context, name string) error {
+	var pipelines []string
+	if err := a.pipelines.ReadOnly(ctx).List(&pps.EtcdPipelineInfo{}, col.DefaultOptions, func(pipeline string) error {
+		pipelines = append(pipelines, pipeline)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if other, ok := collidingPipeline(name, pipelines); ok {
+		return fmt.Errorf("the kubernetes resources of pipeline %q would have the same names as those of pipeline %q; choose a different name",
+			name, other)
+	}
+	return nil
+}
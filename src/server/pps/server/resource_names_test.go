@@ -0,0 +1,38 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestValidatePipelineName(t *testing.T) {
+	require.NoError(t, validatePipelineName("edges"))
+	require.NoError(t, validatePipelineName("My_Pipe-2"))
+	require.NoError(t, validatePipelineName(strings.Repeat("a", 63)))
+	require.YesError(t, validatePipelineName(strings.Repeat("a", 64)))
+	require.YesError(t, validatePipelineName("edges.v2"))
+	require.YesError(t, validatePipelineName("_edges"))
+	require.YesError(t, validatePipelineName("-edges"))
+	// Label values must end with an alphanumeric character
+	require.YesError(t, validatePipelineName("edges_"))
+	require.YesError(t, validatePipelineName("edges-"))
+	require.YesError(t, validatePipelineName("__spec__"))
+}
+
+func TestCollidingPipeline(t *testing.T) {
+	pipelines := []string{"edges", "montage", "My_Pipe"}
+	_, ok := collidingPipeline("my-pipe", pipelines)
+	require.False(t, ok)
+	_, ok = collidingPipeline("edges", pipelines)
+	require.False(t, ok)
+
+	// These names hash to the same value, and are the same once they're
+	// truncated to fit in an RC name
+	a := strings.Repeat("a", 40) + "117054"
+	b := strings.Repeat("a", 40) + "161491"
+	other, ok := collidingPipeline(a, append(pipelines, b))
+	require.True(t, ok)
+	require.Equal(t, b, other)
+}
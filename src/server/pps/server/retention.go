@@ -0,0 +1,105 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+)
+
+// trimHistory deletes the commits that fall outside the retention policies of
+// the branches in 'repoInfos'. It's called by GarbageCollect before it
+// collects active objects, so that the objects referenced only by the deleted
+// commits are garbage collected.
+func trimHistory(pachClient *client.APIClient, repoInfos []*pfs.RepoInfo) error {
+	// heads maps each repo to the set of its branches' head commits
+	heads := make(map[string]map[string]bool)
+	var retained []*pfs.BranchInfo
+	for _, repoInfo := range repoInfos {
+		branchInfos, err := pachClient.ListBranch(repoInfo.Repo.Name)
+		if err != nil {
+			return err
+		}
+		heads[repoInfo.Repo.Name] = make(map[string]bool)
+		for _, branchInfo := range branchInfos {
+			if branchInfo.Head != nil {
+				heads[repoInfo.Repo.Name][branchInfo.Head.ID] = true
+			}
+			if branchInfo.Retention != nil && branchInfo.Head != nil {
+				retained = append(retained, branchInfo)
+			}
+		}
+	}
+	isHead := func(commit *pfs.Commit) bool {
+		return heads[commit.Repo.Name][commit.ID]
+	}
+
+	now := time.Now()
+	for _, branchInfo := range retained {
+		branch := branchInfo.Branch
+		var commitInfos []*pfs.CommitInfo
+		if err := pachClient.ListCommitF(branch.Repo.Name, branchInfo.Head.ID, "", 0, false, func(ci *pfs.CommitInfo) error {
+			commitInfos = append(commitInfos, ci)
+			return nil
+		}); err != nil {
+			return err
+		}
+		expired, err := expiredCommits(commitInfos, branchInfo.Retention, isHead, now)
+		if err != nil {
+			return fmt.Errorf("could not apply retention policy of %s@%s: %v", branch.Repo.Name, branch.Name, err)
+		}
+		for _, commit := range expired {
+			if err := pachClient.DeleteCommit(commit.Repo.Name, commit.ID); err != nil {
+				return fmt.Errorf("could not delete commit %s@%s: %v", commit.Repo.Name, commit.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// expiredCommits returns the commits in 'commitInfos' (a branch's history,
+// newest first) that 'policy' doesn't retain. Commits that are still open,
+// that have provenance, or that are (or provide provenance for) the head of a
+// branch are never expired, as deleting them would change what a branch
+// points to.
+func expiredCommits(commitInfos []*pfs.CommitInfo, policy *pfs.RetentionPolicy, isHead func(*pfs.Commit) bool, now time.Time) ([]*pfs.Commit, error) {
+	if policy == nil || (policy.History == 0 && policy.MaxAge == nil) {
+		return nil, nil
+	}
+	var maxAge time.Duration
+	if policy.MaxAge != nil {
+		var err error
+		maxAge, err = types.DurationFromProto(policy.MaxAge)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var result []*pfs.Commit
+nextCommit:
+	for i, ci := range commitInfos {
+		if policy.History > 0 && int64(i) < policy.History {
+			continue
+		}
+		if ci.Finished == nil || len(ci.Provenance) > 0 || isHead(ci.Commit) {
+			continue
+		}
+		if policy.MaxAge != nil {
+			finished, err := types.TimestampFromProto(ci.Finished)
+			if err != nil {
+				return nil, err
+			}
+			if now.Sub(finished) < maxAge {
+				continue
+			}
+		}
+		for _, subv := range ci.Subvenance {
+			if isHead(subv.Upper) {
+				continue nextCommit
+			}
+		}
+		result = append(result, ci.Commit)
+	}
+	return result, nil
+}
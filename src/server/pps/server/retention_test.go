@@ -0,0 +1,66 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestExpiredCommits(t *testing.T) {
+	now := time.Now()
+	// commitInfos is a history of 5 commits, newest first, finished 0..4 days ago
+	var commitInfos []*pfs.CommitInfo
+	for i := 0; i < 5; i++ {
+		finished, err := types.TimestampProto(now.Add(-time.Duration(i) * 24 * time.Hour))
+		require.NoError(t, err)
+		commitInfos = append(commitInfos, &pfs.CommitInfo{
+			Commit:   client.NewCommit("repo", fmt.Sprintf("c%d", i)),
+			Finished: finished,
+		})
+	}
+	noHeads := func(*pfs.Commit) bool { return false }
+	ids := func(commits []*pfs.Commit) []string {
+		var result []string
+		for _, c := range commits {
+			result = append(result, c.ID)
+		}
+		return result
+	}
+
+	expired, err := expiredCommits(commitInfos, nil, noHeads, now)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(expired))
+
+	expired, err = expiredCommits(commitInfos, &pfs.RetentionPolicy{History: 2}, noHeads, now)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c2", "c3", "c4"}, ids(expired))
+
+	maxAge := types.DurationProto(36 * time.Hour)
+	expired, err = expiredCommits(commitInfos, &pfs.RetentionPolicy{MaxAge: maxAge}, noHeads, now)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c2", "c3", "c4"}, ids(expired))
+
+	// A commit is kept if either field retains it
+	expired, err = expiredCommits(commitInfos, &pfs.RetentionPolicy{History: 3, MaxAge: maxAge}, noHeads, now)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c3", "c4"}, ids(expired))
+
+	// Branch heads, open commits, and commits that provide provenance for a
+	// branch head are never expired
+	commitInfos[2].Finished = nil
+	commitInfos[3].Subvenance = []*pfs.CommitRange{{
+		Lower: client.NewCommit("out", "o1"),
+		Upper: client.NewCommit("out", "o3"),
+	}}
+	isHead := func(c *pfs.Commit) bool {
+		return c.ID == "c4" || c.ID == "o3"
+	}
+	expired, err = expiredCommits(commitInfos, &pfs.RetentionPolicy{History: 1}, isHead, now)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c1"}, ids(expired))
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+
+	"github.com/gogo/protobuf/types"
+)
+
+// validateDatumRetry returns an error if 'pipelineInfo' sets a datum_retry
+// whose backoff can't be computed, or that classifies exit codes that the
+// pipeline accepts as permanent failures
+func validateDatumRetry(pipelineInfo *pps.PipelineInfo) error {
+	retry := pipelineInfo.DatumRetry
+	if retry == nil {
+		return nil
+	}
+	initial, err := types.DurationFromProto(retry.InitialBackoff)
+	if retry.InitialBackoff != nil && (err != nil || initial < 0) {
+		return fmt.Errorf("initial_backoff must be a non-negative duration")
+	}
+	max, err := types.DurationFromProto(retry.MaxBackoff)
+	if retry.MaxBackoff != nil && (err != nil || max < 0) {
+		return fmt.Errorf("max_backoff must be a non-negative duration")
+	}
+	if retry.MaxBackoff != nil && max < initial {
+		return fmt.Errorf("max_backoff (%v) must be at least initial_backoff (%v)", max, initial)
+	}
+	if retry.Multiplier != 0 && retry.Multiplier < 1 {
+		return fmt.Errorf("multiplier must be at least 1, but is %v", retry.Multiplier)
+	}
+	for _, code := range retry.PermanentExitCodes {
+		if code == 0 {
+			return fmt.Errorf("exit code 0 can't be a permanent failure")
+		}
+		if pipelineInfo.Transform != nil {
+			for _, accepted := range pipelineInfo.Transform.AcceptReturnCode {
+				if code == accepted {
+					return fmt.Errorf("exit code %d is both accepted and a permanent failure", code)
+				}
+			}
+		}
+	}
+	return nil
+}
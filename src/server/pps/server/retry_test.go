@@ -0,0 +1,44 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+
+	"github.com/gogo/protobuf/types"
+)
+
+func TestValidateDatumRetry(t *testing.T) {
+	require.NoError(t, validateDatumRetry(&pps.PipelineInfo{}))
+	require.NoError(t, validateDatumRetry(&pps.PipelineInfo{
+		Transform: &pps.Transform{AcceptReturnCode: []int64{1}},
+		DatumRetry: &pps.DatumRetry{
+			InitialBackoff:     types.DurationProto(time.Second),
+			MaxBackoff:         types.DurationProto(time.Minute),
+			Multiplier:         1.5,
+			PermanentExitCodes: []int64{2},
+		},
+	}))
+
+	require.YesError(t, validateDatumRetry(&pps.PipelineInfo{
+		DatumRetry: &pps.DatumRetry{InitialBackoff: types.DurationProto(-time.Second)},
+	}))
+	require.YesError(t, validateDatumRetry(&pps.PipelineInfo{
+		DatumRetry: &pps.DatumRetry{
+			InitialBackoff: types.DurationProto(time.Minute),
+			MaxBackoff:     types.DurationProto(time.Second),
+		},
+	}))
+	require.YesError(t, validateDatumRetry(&pps.PipelineInfo{
+		DatumRetry: &pps.DatumRetry{Multiplier: 0.5},
+	}))
+	require.YesError(t, validateDatumRetry(&pps.PipelineInfo{
+		DatumRetry: &pps.DatumRetry{PermanentExitCodes: []int64{0}},
+	}))
+	require.YesError(t, validateDatumRetry(&pps.PipelineInfo{
+		Transform:  &pps.Transform{AcceptReturnCode: []int64{2}},
+		DatumRetry: &pps.DatumRetry{PermanentExitCodes: []int64{2}},
+	}))
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// validateS3Input returns an error if 'input' is an S3 input but can't be.
+// S3 inputs are read through the job's S3 gateway, which serves the input's
+// whole commit as a bucket, so they're never split into datums or downloaded.
+func validateS3Input(input *pps.PFSInput) error {
+	if !input.S3 {
+		return nil
+	}
+	if input.Glob != "/" {
+		return fmt.Errorf("input %q is an S3 input, so its glob must be \"/\", but it's %q", input.Name, input.Glob)
+	}
+	if input.Lazy || input.EmptyFiles {
+		return fmt.Errorf("input %q is an S3 input, so it can't be lazy or have empty_files", input.Name)
+	}
+	return nil
+}
+
+// validateS3 returns an error if 'pipelineInfo' reads S3 inputs or writes its
+// output through the job's S3 gateway but can't. Only jobs run by the
+// pipeline's own workers have S3 gateways.
+func validateS3(pipelineInfo *pps.PipelineInfo) error {
+	if !pipelineInfo.S3Out && !pps.ContainsS3Inputs(pipelineInfo.Input) {
+		return nil
+	}
+	switch {
+	case pipelineInfo.Backend != nil:
+		return fmt.Errorf("pipelines with an execution backend can't use S3 inputs or s3_out")
+	case pipelineInfo.Spout != nil:
+		return fmt.Errorf("spouts can't use S3 inputs or s3_out")
+	case pipelineInfo.Service != nil:
+		return fmt.Errorf("services can't use S3 inputs or s3_out")
+	case pipelineInfo.S3Out && pipelineInfo.StreamOutput:
+		return fmt.Errorf("pipelines with s3_out can't stream their output, as they don't write it to /pfs/out")
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestValidateS3Input(t *testing.T) {
+	require.NoError(t, validateS3Input(&pps.PFSInput{Name: "in", Glob: "/*"}))
+	require.NoError(t, validateS3Input(&pps.PFSInput{Name: "in", Glob: "/", S3: true}))
+	require.YesError(t, validateS3Input(&pps.PFSInput{Name: "in", Glob: "/*", S3: true}))
+	require.YesError(t, validateS3Input(&pps.PFSInput{Name: "in", Glob: "/", S3: true, Lazy: true}))
+	require.YesError(t, validateS3Input(&pps.PFSInput{Name: "in", Glob: "/", S3: true, EmptyFiles: true}))
+}
+
+func TestValidateS3(t *testing.T) {
+	s3Input := &pps.Input{Pfs: &pps.PFSInput{Name: "in", Glob: "/", S3: true}}
+	require.NoError(t, validateS3(&pps.PipelineInfo{}))
+	require.NoError(t, validateS3(&pps.PipelineInfo{Input: s3Input}))
+	require.NoError(t, validateS3(&pps.PipelineInfo{S3Out: true}))
+	require.NoError(t, validateS3(&pps.PipelineInfo{
+		Input: &pps.Input{Cross: []*pps.Input{s3Input, {Pfs: &pps.PFSInput{Name: "other", Glob: "/*"}}}},
+		S3Out: true,
+	}))
+	require.YesError(t, validateS3(&pps.PipelineInfo{Input: s3Input, Spout: &pps.Spout{}}))
+	require.YesError(t, validateS3(&pps.PipelineInfo{Input: s3Input, Service: &pps.Service{}}))
+	require.YesError(t, validateS3(&pps.PipelineInfo{S3Out: true, Backend: &pps.ExecutionBackend{}}))
+	require.YesError(t, validateS3(&pps.PipelineInfo{S3Out: true, StreamOutput: true}))
+	// Spouts and services can still read ordinary inputs
+	require.NoError(t, validateS3(&pps.PipelineInfo{Spout: &pps.Spout{}}))
+}
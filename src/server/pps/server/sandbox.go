@@ -0,0 +1,70 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// appArmorAnnotationPrefix is the prefix of the pod annotations that set
+	// the AppArmor profile of each of the pod's containers
+	appArmorAnnotationPrefix = "container.apparmor.security.beta.kubernetes.io/"
+	// defaultAppArmorProfile is the AppArmor profile of sandboxed pipelines
+	// that don't set one
+	defaultAppArmorProfile = "runtime/default"
+)
+
+// sandboxCapabilities are the only capabilities that the user container of a
+// sandboxed pipeline has. The worker needs them to give the datums in /pfs to
+// the user that the user code runs as (CHOWN, DAC_OVERRIDE, FOWNER), to run
+// the user code as that user (SETUID, SETGID) and to stop it (KILL).
+var sandboxCapabilities = []v1.Capability{"CHOWN", "DAC_OVERRIDE", "FOWNER", "SETUID", "SETGID", "KILL"}
+
+// validateSandbox returns an error if the sandbox of 'transform' is invalid
+func validateSandbox(transform *pps.Transform) error {
+	if transform == nil || transform.Sandbox == nil {
+		return nil
+	}
+	switch profile := transform.Sandbox.ApparmorProfile; {
+	case profile == "", profile == defaultAppArmorProfile, profile == "unconfined":
+	case strings.HasPrefix(profile, "localhost/") && profile != "localhost/":
+	default:
+		return fmt.Errorf("invalid AppArmor profile %q (must be %q, \"unconfined\" or \"localhost/<profile>\")",
+			profile, defaultAppArmorProfile)
+	}
+	return nil
+}
+
+// sandboxAnnotations returns the pod annotations of the workers of a
+// pipeline with 'sandbox', which set the AppArmor profile of the user
+// container
+func sandboxAnnotations(sandbox *pps.Sandbox) map[string]string {
+	profile := sandbox.ApparmorProfile
+	if profile == "" {
+		profile = defaultAppArmorProfile
+	}
+	return map[string]string{
+		appArmorAnnotationPrefix + client.PPSWorkerUserContainerName: profile,
+	}
+}
+
+// sandboxSecurityContext returns the security context of the user container
+// of a sandboxed pipeline. The worker runs as root, so that it can run the
+// user code as another user, but only with sandboxCapabilities.
+func sandboxSecurityContext() *v1.SecurityContext {
+	root := int64(0)
+	allowPrivilegeEscalation := false
+	return &v1.SecurityContext{
+		RunAsUser: &root,
+		Capabilities: &v1.Capabilities{
+			Drop: []v1.Capability{"ALL"},
+			Add:  sandboxCapabilities,
+		},
+		AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+	}
+}
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestValidateSandbox(t *testing.T) {
+	require.NoError(t, validateSandbox(&pps.Transform{}))
+	for _, profile := range []string{"", "runtime/default", "unconfined", "localhost/pachyderm"} {
+		require.NoError(t, validateSandbox(&pps.Transform{Sandbox: &pps.Sandbox{ApparmorProfile: profile}}))
+	}
+	for _, profile := range []string{"localhost/", "pachyderm", "docker-default"} {
+		require.YesError(t, validateSandbox(&pps.Transform{Sandbox: &pps.Sandbox{ApparmorProfile: profile}}))
+	}
+}
+
+func TestSandboxAnnotations(t *testing.T) {
+	key := appArmorAnnotationPrefix + client.PPSWorkerUserContainerName
+	require.Equal(t, "runtime/default", sandboxAnnotations(&pps.Sandbox{})[key])
+	require.Equal(t, "localhost/pachyderm", sandboxAnnotations(&pps.Sandbox{ApparmorProfile: "localhost/pachyderm"})[key])
+}
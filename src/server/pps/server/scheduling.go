@@ -0,0 +1,111 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// linuxOS and windowsOS are the supported values of SchedulingSpec.OS
+	linuxOS   = "linux"
+	windowsOS = "windows"
+	// amd64Arch and arm64Arch are the supported values of SchedulingSpec.Arch
+	amd64Arch = "amd64"
+	arm64Arch = "arm64"
+	// osNodeLabel and archNodeLabel are the well-known labels that kubernetes
+	// sets to the operating system and CPU architecture of each node
+	osNodeLabel   = "kubernetes.io/os"
+	archNodeLabel = "kubernetes.io/arch"
+)
+
+// validateSchedulingSpec returns an error if the scheduling spec of
+// 'pipelineInfo' is invalid, or if it asks for a Windows node and the pipeline
+// uses features that aren't supported on Windows.
+func validateSchedulingSpec(pipelineInfo *pps.PipelineInfo) error {
+	spec := pipelineInfo.SchedulingSpec
+	if spec == nil {
+		return nil
+	}
+	switch spec.OS {
+	case "", linuxOS:
+	case windowsOS:
+		if pipelineInfo.Spout != nil {
+			return fmt.Errorf("spouts are not supported on windows")
+		}
+		if pipelineInfo.Transform != nil && pipelineInfo.Transform.User != "" {
+			return fmt.Errorf("transform.user is not supported on windows")
+		}
+		if pipelineInfo.Transform != nil && pipelineInfo.Transform.Sandbox != nil {
+			return fmt.Errorf("transform.sandbox is not supported on windows")
+		}
+		if spec.Arch != "" && spec.Arch != amd64Arch {
+			return fmt.Errorf("arch %q is not supported on windows", spec.Arch)
+		}
+	default:
+		return fmt.Errorf("invalid os %q, must be %q or %q", spec.OS, linuxOS, windowsOS)
+	}
+	switch spec.Arch {
+	case "", amd64Arch, arm64Arch:
+	default:
+		return fmt.Errorf("invalid arch %q, must be %q or %q", spec.Arch, amd64Arch, arm64Arch)
+	}
+	for label, value := range map[string]string{osNodeLabel: spec.OS, archNodeLabel: spec.Arch} {
+		if selected, ok := spec.NodeSelector[label]; ok && value != "" && selected != value {
+			return fmt.Errorf("node selector %s=%s conflicts with %q", label, selected, value)
+		}
+	}
+	for _, toleration := range spec.Tolerations {
+		switch v1.TolerationOperator(toleration.Operator) {
+		case "", v1.TolerationOpEqual:
+		case v1.TolerationOpExists:
+			if toleration.Value != "" {
+				return fmt.Errorf("toleration for %q cannot have a value with operator %q", toleration.Key, toleration.Operator)
+			}
+		default:
+			return fmt.Errorf("invalid toleration operator %q", toleration.Operator)
+		}
+		switch v1.TaintEffect(toleration.Effect) {
+		case "", v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute:
+		default:
+			return fmt.Errorf("invalid toleration effect %q", toleration.Effect)
+		}
+	}
+	return nil
+}
+
+// nodeSelector returns the node selector for the workers of a pipeline with
+// the scheduling spec 'spec', which includes the node labels that select the
+// pipeline's operating system and architecture, if set.
+func nodeSelector(spec *pps.SchedulingSpec) map[string]string {
+	if spec.OS == "" && spec.Arch == "" {
+		return spec.NodeSelector
+	}
+	result := make(map[string]string)
+	for k, v := range spec.NodeSelector {
+		result[k] = v
+	}
+	if spec.OS != "" {
+		result[osNodeLabel] = spec.OS
+	}
+	if spec.Arch != "" {
+		result[archNodeLabel] = spec.Arch
+	}
+	return result
+}
+
+// tolerations converts the tolerations in 'spec' to their k8s equivalents
+func tolerations(spec *pps.SchedulingSpec) []v1.Toleration {
+	var result []v1.Toleration
+	for _, toleration := range spec.Tolerations {
+		result = append(result, v1.Toleration{
+			Key:      toleration.Key,
+			Operator: v1.TolerationOperator(toleration.Operator),
+			Value:    toleration.Value,
+			Effect:   v1.TaintEffect(toleration.Effect),
+		})
+	}
+	return result
+}
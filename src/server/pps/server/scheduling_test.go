@@ -0,0 +1,59 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestValidateSchedulingSpec(t *testing.T) {
+	pipelineInfo := func(spec *pps.SchedulingSpec) *pps.PipelineInfo {
+		return &pps.PipelineInfo{Transform: &pps.Transform{}, SchedulingSpec: spec}
+	}
+	require.NoError(t, validateSchedulingSpec(pipelineInfo(nil)))
+	require.NoError(t, validateSchedulingSpec(pipelineInfo(&pps.SchedulingSpec{OS: "windows"})))
+	require.YesError(t, validateSchedulingSpec(pipelineInfo(&pps.SchedulingSpec{OS: "plan9"})))
+	require.YesError(t, validateSchedulingSpec(pipelineInfo(&pps.SchedulingSpec{
+		OS:           "windows",
+		NodeSelector: map[string]string{"kubernetes.io/os": "linux"},
+	})))
+	require.NoError(t, validateSchedulingSpec(pipelineInfo(&pps.SchedulingSpec{Arch: "arm64"})))
+	require.YesError(t, validateSchedulingSpec(pipelineInfo(&pps.SchedulingSpec{Arch: "sparc"})))
+	require.YesError(t, validateSchedulingSpec(pipelineInfo(&pps.SchedulingSpec{OS: "windows", Arch: "arm64"})))
+	require.YesError(t, validateSchedulingSpec(pipelineInfo(&pps.SchedulingSpec{
+		Arch:         "arm64",
+		NodeSelector: map[string]string{"kubernetes.io/arch": "amd64"},
+	})))
+
+	// Spouts and custom users aren't supported on windows
+	spout := pipelineInfo(&pps.SchedulingSpec{OS: "windows"})
+	spout.Spout = &pps.Spout{}
+	require.YesError(t, validateSchedulingSpec(spout))
+	user := pipelineInfo(&pps.SchedulingSpec{OS: "windows"})
+	user.Transform.User = "alice"
+	require.YesError(t, validateSchedulingSpec(user))
+
+	require.NoError(t, validateSchedulingSpec(pipelineInfo(&pps.SchedulingSpec{
+		Tolerations: []*pps.Toleration{{Key: "os", Operator: "Exists", Effect: "NoSchedule"}},
+	})))
+	require.YesError(t, validateSchedulingSpec(pipelineInfo(&pps.SchedulingSpec{
+		Tolerations: []*pps.Toleration{{Key: "os", Operator: "Exists", Value: "windows"}},
+	})))
+	require.YesError(t, validateSchedulingSpec(pipelineInfo(&pps.SchedulingSpec{
+		Tolerations: []*pps.Toleration{{Key: "os", Effect: "NoSleep"}},
+	})))
+}
+
+func TestNodeSelector(t *testing.T) {
+	spec := &pps.SchedulingSpec{NodeSelector: map[string]string{"pool": "gpu"}}
+	require.Equal(t, map[string]string{"pool": "gpu"}, nodeSelector(spec))
+	spec.OS, spec.Arch = "linux", "arm64"
+	require.Equal(t, map[string]string{
+		"pool":               "gpu",
+		"kubernetes.io/os":   "linux",
+		"kubernetes.io/arch": "arm64",
+	}, nodeSelector(spec))
+	// The pipeline's node selector is not modified
+	require.Equal(t, map[string]string{"pool": "gpu"}, spec.NodeSelector)
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// scratchVolumeName is the name of the volume in which workers store datums,
+// for pipelines with a scratch volume
+const scratchVolumeName = "pach-scratch"
+
+// validateScratchVolume returns an error if 'scratch' is invalid
+func validateScratchVolume(scratch *pps.ScratchVolume) error {
+	if scratch == nil {
+		return nil
+	}
+	if scratch.Capacity != "" {
+		if _, err := resource.ParseQuantity(scratch.Capacity); err != nil {
+			return fmt.Errorf("could not parse capacity %q: %v", scratch.Capacity, err)
+		}
+	}
+	if scratch.LocalSSDPath != "" && !path.IsAbs(scratch.LocalSSDPath) {
+		return fmt.Errorf("local_ssd_path must be an absolute path, but is %q", scratch.LocalSSDPath)
+	}
+	return nil
+}
+
+// validateStreamOutput returns an error if 'pipelineInfo' streams its output
+// but can't
+func validateStreamOutput(pipelineInfo *pps.PipelineInfo) error {
+	if !pipelineInfo.StreamOutput {
+		return nil
+	}
+	if pipelineInfo.Spout != nil {
+		return fmt.Errorf("spouts can't stream their output, as /pfs/out is a named pipe")
+	}
+	if isWindows(pipelineInfo.SchedulingSpec) {
+		return fmt.Errorf("Windows pipelines can't stream their output")
+	}
+	return nil
+}
+
+// scratchVolume returns the volume and the user container's volume mount
+// for 'scratch', which is mounted at the directory that the worker downloads
+// datums to. Several workers may run on the same node, so each one mounts its
+// own subdirectory of a local SSD.
+func scratchVolume(scratch *pps.ScratchVolume) (v1.Volume, v1.VolumeMount) {
+	volume := v1.Volume{Name: scratchVolumeName}
+	mount := v1.VolumeMount{
+		Name:      scratchVolumeName,
+		MountPath: filepath.Join(client.PPSInputPrefix, client.PPSScratchSpace),
+	}
+	if scratch.LocalSSDPath != "" {
+		hostPathType := v1.HostPathDirectoryOrCreate
+		volume.HostPath = &v1.HostPathVolumeSource{
+			Path: scratch.LocalSSDPath,
+			Type: &hostPathType,
+		}
+		mount.SubPathExpr = fmt.Sprintf("$(%s)", client.PPSPodNameEnv)
+		return volume, mount
+	}
+	volume.EmptyDir = &v1.EmptyDirVolumeSource{}
+	if scratch.Capacity != "" {
+		size := resource.MustParse(scratch.Capacity)
+		volume.EmptyDir.SizeLimit = &size
+	}
+	return volume, mount
+}
+
+// scratchRequests returns 'requests' (the user container's resource
+// requests) plus the ephemeral storage used by 'scratch'. A local SSD isn't
+// ephemeral storage, so it's not requested.
+func scratchRequests(requests v1.ResourceList, scratch *pps.ScratchVolume) v1.ResourceList {
+	if scratch.Capacity == "" || scratch.LocalSSDPath != "" {
+		return requests
+	}
+	result := make(v1.ResourceList)
+	for name, quantity := range requests {
+		result[name] = quantity
+	}
+	result[v1.ResourceEphemeralStorage] = resource.MustParse(scratch.Capacity)
+	return result
+}
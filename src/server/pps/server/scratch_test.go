@@ -0,0 +1,58 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestValidateScratchVolume(t *testing.T) {
+	require.NoError(t, validateScratchVolume(nil))
+	require.NoError(t, validateScratchVolume(&pps.ScratchVolume{}))
+	require.NoError(t, validateScratchVolume(&pps.ScratchVolume{Capacity: "100Gi"}))
+	require.NoError(t, validateScratchVolume(&pps.ScratchVolume{Capacity: "100Gi", LocalSSDPath: "/mnt/disks/ssd0"}))
+	require.YesError(t, validateScratchVolume(&pps.ScratchVolume{Capacity: "lots"}))
+	require.YesError(t, validateScratchVolume(&pps.ScratchVolume{LocalSSDPath: "mnt/disks/ssd0"}))
+}
+
+func TestValidateStreamOutput(t *testing.T) {
+	require.NoError(t, validateStreamOutput(&pps.PipelineInfo{}))
+	require.NoError(t, validateStreamOutput(&pps.PipelineInfo{StreamOutput: true}))
+	require.YesError(t, validateStreamOutput(&pps.PipelineInfo{StreamOutput: true, Spout: &pps.Spout{}}))
+	require.YesError(t, validateStreamOutput(&pps.PipelineInfo{
+		StreamOutput:   true,
+		SchedulingSpec: &pps.SchedulingSpec{OS: windowsOS},
+	}))
+}
+
+func TestScratchVolume(t *testing.T) {
+	volume, mount := scratchVolume(&pps.ScratchVolume{Capacity: "100Gi"})
+	require.Equal(t, scratchVolumeName, volume.Name)
+	require.NotNil(t, volume.EmptyDir)
+	require.Equal(t, "100Gi", volume.EmptyDir.SizeLimit.String())
+	require.Equal(t, "/pfs/.scratch", mount.MountPath)
+	require.Equal(t, "", mount.SubPathExpr)
+
+	volume, mount = scratchVolume(&pps.ScratchVolume{Capacity: "100Gi", LocalSSDPath: "/mnt/disks/ssd0"})
+	require.Nil(t, volume.EmptyDir)
+	require.NotNil(t, volume.HostPath)
+	require.Equal(t, "/mnt/disks/ssd0", volume.HostPath.Path)
+	require.Equal(t, "$("+client.PPSPodNameEnv+")", mount.SubPathExpr)
+}
+
+func TestScratchRequests(t *testing.T) {
+	requests := v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}
+	result := scratchRequests(requests, &pps.ScratchVolume{Capacity: "100Gi"})
+	require.Equal(t, 2, len(result))
+	ephemeral := result[v1.ResourceEphemeralStorage]
+	require.Equal(t, "100Gi", ephemeral.String())
+	require.Equal(t, 1, len(requests))
+
+	require.Equal(t, 1, len(scratchRequests(requests, &pps.ScratchVolume{})))
+	require.Equal(t, 1, len(scratchRequests(requests, &pps.ScratchVolume{Capacity: "100Gi", LocalSSDPath: "/mnt/disks/ssd0"})))
+}
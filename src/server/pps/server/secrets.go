@@ -0,0 +1,170 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/metrics"
+	"golang.org/x/net/context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// secretRotatedAnnotation is the annotation of a secret that records when
+	// RotateSecret last replaced its data
+	secretRotatedAnnotation = "pachyderm.io/rotated"
+	// secretsVolumeName is the name of the projected volume through which
+	// workers read the current values of the secrets that their pipeline
+	// exposes as env vars
+	secretsVolumeName = "pach-secrets"
+)
+
+// secretsVolume returns the projected volume (and its mount) that contains
+// each secret key in 'secrets' that's exposed to user code as an env var, at
+// <secret name>/<key>. Kubernetes updates the volume when the secrets change,
+// whereas the env vars set in the pod spec are fixed when the pod starts, so
+// the worker reads the env vars' values from the volume before running the
+// user code. It returns nil if no secrets are exposed as env vars.
+func secretsVolume(secrets []*pps.SecretMount) (*v1.Volume, *v1.VolumeMount) {
+	keys := make(map[string][]v1.KeyToPath)
+	var names []string
+	seen := make(map[string]bool)
+	for _, secret := range secrets {
+		if secret.EnvVar == "" {
+			continue
+		}
+		p := path.Join(secret.Name, secret.Key)
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		if _, ok := keys[secret.Name]; !ok {
+			names = append(names, secret.Name)
+		}
+		keys[secret.Name] = append(keys[secret.Name], v1.KeyToPath{Key: secret.Key, Path: p})
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	sort.Strings(names)
+	var sources []v1.VolumeProjection
+	for _, name := range names {
+		sources = append(sources, v1.VolumeProjection{
+			Secret: &v1.SecretProjection{
+				LocalObjectReference: v1.LocalObjectReference{Name: name},
+				Items:                keys[name],
+			},
+		})
+	}
+	return &v1.Volume{
+		Name: secretsVolumeName,
+		VolumeSource: v1.VolumeSource{
+			Projected: &v1.ProjectedVolumeSource{Sources: sources},
+		},
+	}, &v1.VolumeMount{
+		Name:      secretsVolumeName,
+		MountPath: client.PPSSecretsMountPath,
+		ReadOnly:  true,
+	}
+}
+
+// checkRotatedSecret returns an error if rotating the secret 'name' to
+// 'data' would remove a key that one of 'pipelineInfos' uses, as the
+// pipeline's workers would then fail to start or to run its user code
+func checkRotatedSecret(name string, data map[string][]byte, pipelineInfos []*pps.PipelineInfo) error {
+	var missing []string
+	for _, pipelineInfo := range pipelineInfos {
+		if pipelineInfo.Transform == nil {
+			continue
+		}
+		for _, secret := range pipelineInfo.Transform.Secrets {
+			if secret.Name != name || secret.EnvVar == "" {
+				continue
+			}
+			if _, ok := data[secret.Key]; !ok {
+				missing = append(missing, fmt.Sprintf("%q (used by pipeline %s)", secret.Key, pipelineInfo.Pipeline.Name))
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the new value of secret %q is missing keys: %s", name, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// secretRotated returns when 'secret' was last rotated, or nil if it never
+// was
+func secretRotated(secret *v1.Secret) *types.Timestamp {
+	rotated, err := time.Parse(time.RFC3339Nano, secret.Annotations[secretRotatedAnnotation])
+	if err != nil {
+		return nil
+	}
+	result, err := types.TimestampProto(rotated)
+	if err != nil {
+		return nil
+	}
+	return result
+}
+
+// RotateSecret implements the protobuf pps.RotateSecret RPC
+func (a *apiServer) RotateSecret(ctx context.Context, request *pps.RotateSecretRequest) (response *types.Empty, retErr error) {
+	// Don't log the request, which contains the secret's new value
+	func() { a.Log(request.Secret, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request.Secret, response, retErr, time.Since(start)) }(time.Now())
+	metricsFn := metrics.ReportUserAction(ctx, a.reporter, "RotateSecret")
+	defer func(start time.Time) { metricsFn(start, retErr) }(time.Now())
+
+	if request.Secret == nil || request.Secret.Name == "" {
+		return nil, fmt.Errorf("secret must be set")
+	}
+	name := request.Secret.Name
+	var s v1.Secret
+	if err := json.Unmarshal(request.File, &s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal secret: %v", err)
+	}
+	if s.Name != "" && s.Name != name {
+		return nil, fmt.Errorf("can't rotate secret %q to a secret named %q", name, s.Name)
+	}
+	data := make(map[string][]byte)
+	for k, v := range s.Data {
+		data[k] = v
+	}
+	for k, v := range s.StringData {
+		data[k] = []byte(v)
+	}
+
+	secrets := a.env.GetKubeClient().CoreV1().Secrets(a.namespace)
+	secret, err := secrets.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret: %v", err)
+	}
+	if secret.Labels["secret-source"] != "pachyderm-user" {
+		return nil, fmt.Errorf("secret %q wasn't created by pachyderm, so it can't be rotated", name)
+	}
+	pipelineInfos, err := a.ListPipeline(ctx, &pps.ListPipelineRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRotatedSecret(name, data, pipelineInfos.PipelineInfo); err != nil {
+		return nil, err
+	}
+	secret.Data = data
+	secret.StringData = nil
+	if secret.Annotations == nil {
+		secret.Annotations = make(map[string]string)
+	}
+	secret.Annotations[secretRotatedAnnotation] = time.Now().UTC().Format(time.RFC3339Nano)
+	if _, err := secrets.Update(secret); err != nil {
+		return nil, fmt.Errorf("failed to update secret: %v", err)
+	}
+	return &types.Empty{}, nil
+}
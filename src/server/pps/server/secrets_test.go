@@ -0,0 +1,43 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestSecretsVolume(t *testing.T) {
+	volume, mount := secretsVolume([]*pps.SecretMount{{Name: "certs", MountPath: "/certs"}})
+	require.Nil(t, volume)
+	require.Nil(t, mount)
+
+	volume, mount = secretsVolume([]*pps.SecretMount{
+		{Name: "s3", Key: "secret", EnvVar: "S3_SECRET"},
+		{Name: "db", Key: "password", EnvVar: "DB_PASSWORD"},
+		{Name: "db", Key: "password", EnvVar: "PGPASSWORD"},
+	})
+	require.Equal(t, client.PPSSecretsMountPath, mount.MountPath)
+	require.True(t, mount.ReadOnly)
+	sources := volume.Projected.Sources
+	require.Equal(t, 2, len(sources))
+	require.Equal(t, "db", sources[0].Secret.Name)
+	require.Equal(t, 1, len(sources[0].Secret.Items))
+	require.Equal(t, "db/password", sources[0].Secret.Items[0].Path)
+	require.Equal(t, "s3", sources[1].Secret.Name)
+}
+
+func TestCheckRotatedSecret(t *testing.T) {
+	pipelineInfos := []*pps.PipelineInfo{{
+		Pipeline: client.NewPipeline("etl"),
+		Transform: &pps.Transform{Secrets: []*pps.SecretMount{
+			{Name: "db", Key: "password", EnvVar: "DB_PASSWORD"},
+		}},
+	}}
+	require.NoError(t, checkRotatedSecret("db", map[string][]byte{"password": []byte("x")}, pipelineInfos))
+	require.NoError(t, checkRotatedSecret("other", nil, pipelineInfos))
+	err := checkRotatedSecret("db", map[string][]byte{"user": []byte("x")}, pipelineInfos)
+	require.YesError(t, err)
+	require.Matches(t, "used by pipeline etl", err.Error())
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/server/pkg/log"
 	"github.com/pachyderm/pachyderm/src/server/pkg/metrics"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsdb"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/serviceenv"
 	txnenv "github.com/pachyderm/pachyderm/src/server/pkg/transactionenv"
 )
@@ -30,37 +31,67 @@ func NewAPIServer(
 	iamRole string,
 	imagePullSecret string,
 	noExposeDockerSocket bool,
+	pipelineDefaults string,
+	imagePinning string,
 	reporter *metrics.Reporter,
 	workerUsesRoot bool,
 	workerGrpcPort uint16,
 	port uint16,
 	httpPort uint16,
 	peerPort uint16,
+	artifactCachePort uint16,
+	workerNetworkPolicy bool,
+	networkPolicyAllow string,
+	orphanGCDryRun bool,
+	pipelineCRD bool,
+	workerRPCCacheSize int64,
+	workerBlockSharingCacheSize int64,
 ) (APIServer, error) {
+	defaults, err := ppsutil.ParsePipelineDefaults([]byte(pipelineDefaults))
+	if err != nil {
+		return nil, err
+	}
+	pinning, err := parseImagePinning(imagePinning)
+	if err != nil {
+		return nil, err
+	}
+	allow, err := parseNetworkPolicyAllow(networkPolicyAllow)
+	if err != nil {
+		return nil, err
+	}
 	apiServer := &apiServer{
-		Logger:                log.NewLogger("pps.API"),
-		env:                   env,
-		txnEnv:                txnEnv,
-		etcdPrefix:            etcdPrefix,
-		namespace:             namespace,
-		workerImage:           workerImage,
-		workerSidecarImage:    workerSidecarImage,
-		workerImagePullPolicy: workerImagePullPolicy,
-		storageRoot:           storageRoot,
-		storageBackend:        storageBackend,
-		storageHostPath:       storageHostPath,
-		iamRole:               iamRole,
-		imagePullSecret:       imagePullSecret,
-		noExposeDockerSocket:  noExposeDockerSocket,
-		reporter:              reporter,
-		workerUsesRoot:        workerUsesRoot,
-		pipelines:             ppsdb.Pipelines(env.GetEtcdClient(), etcdPrefix),
-		jobs:                  ppsdb.Jobs(env.GetEtcdClient(), etcdPrefix),
-		monitorCancels:        make(map[string]func()),
-		workerGrpcPort:        workerGrpcPort,
-		port:                  port,
-		httpPort:              httpPort,
-		peerPort:              peerPort,
+		Logger:                      log.NewLogger("pps.API"),
+		env:                         env,
+		txnEnv:                      txnEnv,
+		etcdPrefix:                  etcdPrefix,
+		namespace:                   namespace,
+		workerImage:                 workerImage,
+		workerSidecarImage:          workerSidecarImage,
+		workerImagePullPolicy:       workerImagePullPolicy,
+		storageRoot:                 storageRoot,
+		storageBackend:              storageBackend,
+		storageHostPath:             storageHostPath,
+		iamRole:                     iamRole,
+		imagePullSecret:             imagePullSecret,
+		noExposeDockerSocket:        noExposeDockerSocket,
+		pipelineDefaults:            defaults,
+		imagePinning:                pinning,
+		reporter:                    reporter,
+		workerUsesRoot:              workerUsesRoot,
+		pipelines:                   ppsdb.Pipelines(env.GetEtcdClient(), etcdPrefix),
+		jobs:                        ppsdb.Jobs(env.GetEtcdClient(), etcdPrefix),
+		monitorCancels:              make(map[string]func()),
+		workerGrpcPort:              workerGrpcPort,
+		port:                        port,
+		httpPort:                    httpPort,
+		peerPort:                    peerPort,
+		artifactCachePort:           artifactCachePort,
+		workerNetworkPolicy:         workerNetworkPolicy,
+		networkPolicyAllow:          allow,
+		orphanGCDryRun:              orphanGCDryRun,
+		pipelineCRD:                 pipelineCRD,
+		workerRPCCacheSize:          workerRPCCacheSize,
+		workerBlockSharingCacheSize: workerBlockSharingCacheSize,
 	}
 	apiServer.validateKube()
 	go apiServer.master()
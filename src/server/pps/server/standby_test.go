@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+// standbyWaiter records the commits that waitForStandby waits on
+type standbyWaiter struct {
+	mu      sync.Mutex
+	commits []string
+}
+
+func (w *standbyWaiter) wait(ci *pfs.CommitInfo) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.commits = append(w.commits, ci.Commit.ID)
+	return nil
+}
+
+func (w *standbyWaiter) waited() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.commits...)
+}
+
+func commitInfo(id string) *pfs.CommitInfo {
+	return &pfs.CommitInfo{Commit: client.NewCommit("pipeline", id)}
+}
+
+func TestWaitForStandby(t *testing.T) {
+	ctx := context.Background()
+
+	// Without a grace period, the pipeline goes into standby as soon as its
+	// commits are processed
+	w := &standbyWaiter{}
+	ciChan := make(chan *pfs.CommitInfo, 1)
+	ciChan <- commitInfo("2")
+	require.NoError(t, waitForStandby(ctx, commitInfo("1"), ciChan, 0, w.wait))
+	require.Equal(t, []string{"1", "2"}, w.waited())
+
+	// Commits that arrive during the grace period are processed without going
+	// into standby, and the grace period starts over after each of them
+	w = &standbyWaiter{}
+	gracePeriod := 200 * time.Millisecond
+	done := make(chan error)
+	start := time.Now()
+	go func() {
+		done <- waitForStandby(ctx, commitInfo("1"), ciChan, gracePeriod, w.wait)
+	}()
+	time.Sleep(gracePeriod / 2)
+	ciChan <- commitInfo("2")
+	select {
+	case <-done:
+		t.Fatal("pipeline went into standby during its grace period")
+	case <-time.After(gracePeriod / 2):
+	}
+	require.Equal(t, []string{"1", "2"}, w.waited())
+
+	// Once the grace period expires, the pipeline goes into standby
+	require.NoError(t, <-done)
+	require.True(t, time.Since(start) >= 3*gracePeriod/2)
+	require.Equal(t, []string{"1", "2"}, w.waited())
+
+	// Canceling the monitor stops waiting
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		done <- waitForStandby(ctx, commitInfo("1"), ciChan, time.Hour, w.wait)
+	}()
+	cancel()
+	require.YesError(t, <-done)
+}
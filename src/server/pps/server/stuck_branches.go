@@ -0,0 +1,314 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/metrics"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
+	"golang.org/x/net/context"
+)
+
+// defaultStuckThreshold is how long a branch's head must have been
+// unfinished for ListStuckBranches to report the branch, if the request
+// doesn't say
+const defaultStuckThreshold = time.Hour
+
+// stuckBranchAnalyzer finds what's blocking stuck branches. Its lookups are
+// funcs so that it can be tested without a cluster.
+type stuckBranchAnalyzer struct {
+	// inspectCommit returns the CommitInfo of 'commit'
+	inspectCommit func(commit *pfs.Commit) (*pfs.CommitInfo, error)
+	// jobForCommit returns the job whose output commit is 'commit', or nil
+	jobForCommit func(commit *pfs.Commit) (*pps.JobInfo, error)
+	// pipelines are the cluster's pipelines, by name (which is also the name
+	// of their output repo)
+	pipelines map[string]*pps.PipelineInfo
+	// commits caches the results of inspectCommit, by commit key
+	commits map[string]*pfs.CommitInfo
+}
+
+func commitKey(commit *pfs.Commit) string {
+	return commit.Repo.Name + "@" + commit.ID
+}
+
+// commitInfo returns the (cached) CommitInfo of 'commit', or nil if the
+// caller isn't authorized to read it
+func (s *stuckBranchAnalyzer) commitInfo(commit *pfs.Commit) (*pfs.CommitInfo, error) {
+	key := commitKey(commit)
+	if ci, ok := s.commits[key]; ok {
+		return ci, nil
+	}
+	ci, err := s.inspectCommit(commit)
+	if err != nil && !auth.IsErrNotAuthorized(err) {
+		return nil, err
+	}
+	s.commits[key] = ci
+	return ci, nil
+}
+
+// unfinishedProvenance returns the unfinished commits in the provenance of
+// 'ci' (not counting spec commits or commits that the caller can't read)
+func (s *stuckBranchAnalyzer) unfinishedProvenance(ci *pfs.CommitInfo) ([]*pfs.CommitInfo, error) {
+	var result []*pfs.CommitInfo
+	seen := make(map[string]bool)
+	for _, prov := range ci.Provenance {
+		if prov.Commit.Repo.Name == ppsconsts.SpecRepo || seen[commitKey(prov.Commit)] {
+			continue
+		}
+		seen[commitKey(prov.Commit)] = true
+		provCI, err := s.commitInfo(prov.Commit)
+		if err != nil {
+			return nil, err
+		}
+		if provCI != nil && provCI.Finished == nil {
+			result = append(result, provCI)
+		}
+	}
+	return result, nil
+}
+
+// blockers returns the blockers of the unfinished commit 'ci': the unfinished
+// commits in its provenance whose own provenance is finished (as those are
+// where the hold-up is), or 'ci' itself if its provenance is all finished
+func (s *stuckBranchAnalyzer) blockers(ci *pfs.CommitInfo) ([]*pps.Blocker, error) {
+	unfinished, err := s.unfinishedProvenance(ci)
+	if err != nil {
+		return nil, err
+	}
+	var roots []*pfs.CommitInfo
+	for _, provCI := range unfinished {
+		provUnfinished, err := s.unfinishedProvenance(provCI)
+		if err != nil {
+			return nil, err
+		}
+		if len(provUnfinished) == 0 {
+			roots = append(roots, provCI)
+		}
+	}
+	if len(roots) == 0 {
+		roots = append(roots, ci)
+	}
+	var result []*pps.Blocker
+	for _, root := range roots {
+		blocker, err := s.blocker(root)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, blocker)
+	}
+	return result, nil
+}
+
+// blocker returns the Blocker describing the unfinished commit 'ci'
+func (s *stuckBranchAnalyzer) blocker(ci *pfs.CommitInfo) (*pps.Blocker, error) {
+	result := &pps.Blocker{
+		Commit:  ci.Commit,
+		Branch:  ci.Branch,
+		Started: ci.Started,
+	}
+	pipelineInfo := s.pipelines[ci.Commit.Repo.Name]
+	if pipelineInfo != nil && (ci.Branch == nil || ci.Branch.Name != pipelineInfo.OutputBranch) {
+		// The commit isn't one of the pipeline's output commits
+		pipelineInfo = nil
+	}
+	var jobInfo *pps.JobInfo
+	if pipelineInfo != nil {
+		result.Pipeline = pipelineInfo.Pipeline
+		result.PipelineState = pipelineInfo.State
+		var err error
+		jobInfo, err = s.jobForCommit(ci.Commit)
+		if err != nil {
+			return nil, err
+		}
+		if jobInfo != nil {
+			result.Job = jobInfo.Job
+			result.JobState = jobInfo.State
+		}
+	}
+	result.Reason, result.Remediations = diagnoseBlocker(ci, pipelineInfo, jobInfo)
+	return result, nil
+}
+
+// diagnoseBlocker explains why the commit 'ci' is unfinished, and suggests
+// pachctl commands that may unblock it. 'pipelineInfo' is the pipeline that
+// writes the commit and 'jobInfo' is the job that's writing it, either of
+// which may be nil.
+func diagnoseBlocker(ci *pfs.CommitInfo, pipelineInfo *pps.PipelineInfo, jobInfo *pps.JobInfo) (string, []string) {
+	commit := commitKey(ci.Commit)
+	finishCommit := fmt.Sprintf("pachctl finish commit %s", commit)
+	deleteCommit := fmt.Sprintf("pachctl delete commit %s", commit)
+	if pipelineInfo == nil {
+		return "the commit was started but never finished",
+			[]string{finishCommit, deleteCommit}
+	}
+	pipeline := pipelineInfo.Pipeline.Name
+	inspectPipeline := fmt.Sprintf("pachctl inspect pipeline %s", pipeline)
+	restartPipeline := fmt.Sprintf("pachctl stop pipeline %s && pachctl start pipeline %s", pipeline, pipeline)
+	if jobInfo == nil {
+		switch pipelineInfo.State {
+		case pps.PipelineState_PIPELINE_PAUSED:
+			return fmt.Sprintf("pipeline %s is stopped, so no job is processing the commit", pipeline),
+				[]string{fmt.Sprintf("pachctl start pipeline %s", pipeline)}
+		case pps.PipelineState_PIPELINE_FAILURE:
+			return fmt.Sprintf("pipeline %s failed: %s", pipeline, pipelineInfo.Reason),
+				[]string{inspectPipeline, fmt.Sprintf("pachctl update pipeline --file <fixed %s spec>", pipeline)}
+		case pps.PipelineState_PIPELINE_RESTARTING:
+			return fmt.Sprintf("pipeline %s is restarting after an error: %s", pipeline, pipelineInfo.Reason),
+				[]string{inspectPipeline, fmt.Sprintf("pachctl logs --pipeline=%s --master", pipeline)}
+		}
+		return fmt.Sprintf("no job of pipeline %s is processing the commit", pipeline),
+			[]string{restartPipeline, deleteCommit}
+	}
+	job := jobInfo.Job.ID
+	switch jobInfo.State {
+	case pps.JobState_JOB_STARTING, pps.JobState_JOB_RUNNING, pps.JobState_JOB_MERGING:
+		reason := fmt.Sprintf("job %s is still %s", job, jobStateName(jobInfo.State))
+		if jobInfo.DataFailed > 0 {
+			reason += fmt.Sprintf(", and %d of its datums have failed", jobInfo.DataFailed)
+		}
+		if pipelineInfo.State == pps.PipelineState_PIPELINE_RESTARTING {
+			reason += fmt.Sprintf(", and pipeline %s is restarting after an error: %s", pipeline, pipelineInfo.Reason)
+		}
+		return reason, []string{
+			fmt.Sprintf("pachctl logs --job=%s", job),
+			fmt.Sprintf("pachctl inspect job %s", job),
+			fmt.Sprintf("pachctl stop job %s", job),
+		}
+	}
+	return fmt.Sprintf("job %s is %s, but didn't finish its output commit", job, jobStateName(jobInfo.State)),
+		[]string{deleteCommit}
+}
+
+// jobStateName returns the name of 'state' as it's shown by pachctl (e.g.
+// "running" for JOB_RUNNING)
+func jobStateName(state pps.JobState) string {
+	switch state {
+	case pps.JobState_JOB_STARTING:
+		return "starting"
+	case pps.JobState_JOB_RUNNING:
+		return "running"
+	case pps.JobState_JOB_MERGING:
+		return "merging"
+	case pps.JobState_JOB_SUCCESS:
+		return "successful"
+	case pps.JobState_JOB_FAILURE:
+		return "failed"
+	case pps.JobState_JOB_KILLED:
+		return "killed"
+	case pps.JobState_JOB_PARTIAL_SUCCESS:
+		return "partially successful"
+	case pps.JobState_JOB_SKIPPED:
+		return "skipped"
+	}
+	return state.String()
+}
+
+// ListStuckBranches implements the protobuf pps.ListStuckBranches RPC
+func (a *apiServer) ListStuckBranches(ctx context.Context, request *pps.ListStuckBranchesRequest) (response *pps.StuckBranches, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	metricsFn := metrics.ReportUserAction(ctx, a.reporter, "ListStuckBranches")
+	defer func(start time.Time) { metricsFn(start, retErr) }(time.Now())
+
+	pachClient := a.env.GetPachClient(ctx)
+	ctx, err := checkLoggedIn(pachClient)
+	if err != nil {
+		return nil, err
+	}
+	threshold := defaultStuckThreshold
+	if request.Threshold != nil {
+		threshold, err = types.DurationFromProto(request.Threshold)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold: %v", err)
+		}
+		if threshold < 0 {
+			return nil, fmt.Errorf("threshold can't be negative")
+		}
+	}
+	pipelineInfos, err := a.ListPipeline(ctx, &pps.ListPipelineRequest{})
+	if err != nil {
+		return nil, err
+	}
+	s := &stuckBranchAnalyzer{
+		inspectCommit: func(commit *pfs.Commit) (*pfs.CommitInfo, error) {
+			return pachClient.InspectCommit(commit.Repo.Name, commit.ID)
+		},
+		jobForCommit: func(commit *pfs.Commit) (*pps.JobInfo, error) {
+			var result *pps.JobInfo
+			if err := a.listJob(pachClient, nil, commit, nil, -1, false, "", func(ji *pps.JobInfo) error {
+				result = ji
+				return errutil.ErrBreak
+			}); err != nil && err != errutil.ErrBreak {
+				return nil, err
+			}
+			return result, nil
+		},
+		pipelines: make(map[string]*pps.PipelineInfo),
+		commits:   make(map[string]*pfs.CommitInfo),
+	}
+	for _, pipelineInfo := range pipelineInfos.PipelineInfo {
+		s.pipelines[pipelineInfo.Pipeline.Name] = pipelineInfo
+	}
+
+	repoInfos, err := pachClient.ListRepo()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	response = &pps.StuckBranches{}
+	for _, repoInfo := range repoInfos {
+		repo := repoInfo.Repo.Name
+		if repo == ppsconsts.SpecRepo {
+			continue
+		}
+		branchInfos, err := pachClient.ListBranch(repo)
+		if err != nil {
+			if auth.IsErrNotAuthorized(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, branchInfo := range branchInfos {
+			if branchInfo.Head == nil {
+				continue
+			}
+			if s.pipelines[repo] != nil && branchInfo.Branch.Name == "stats" {
+				// A pipeline's stats branch is written alongside its output branch,
+				// so it's stuck if and only if the output branch is
+				continue
+			}
+			ci, err := s.commitInfo(branchInfo.Head)
+			if err != nil {
+				return nil, err
+			}
+			if ci == nil || ci.Finished != nil || ci.Started == nil {
+				continue
+			}
+			started, err := types.TimestampFromProto(ci.Started)
+			if err != nil {
+				return nil, err
+			}
+			if now.Sub(started) < threshold {
+				continue
+			}
+			blockers, err := s.blockers(ci)
+			if err != nil {
+				return nil, err
+			}
+			response.Branches = append(response.Branches, &pps.StuckBranch{
+				Branch:   client.NewBranch(repo, branchInfo.Branch.Name),
+				Head:     ci.Commit,
+				Started:  ci.Started,
+				Blockers: blockers,
+			})
+		}
+	}
+	return response, nil
+}
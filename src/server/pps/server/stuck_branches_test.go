@@ -0,0 +1,97 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+
+	"github.com/gogo/protobuf/types"
+)
+
+func TestStuckBranchBlockers(t *testing.T) {
+	// images -> edges -> montage, where the images commit was never finished
+	commits := map[string]*pfs.CommitInfo{
+		"images@a": {
+			Commit:  client.NewCommit("images", "a"),
+			Branch:  client.NewBranch("images", "master"),
+			Started: types.TimestampNow(),
+		},
+		"edges@b": {
+			Commit:     client.NewCommit("edges", "b"),
+			Branch:     client.NewBranch("edges", "master"),
+			Provenance: []*pfs.CommitProvenance{client.NewCommitProvenance("images", "master", "a")},
+		},
+		"montage@c": {
+			Commit: client.NewCommit("montage", "c"),
+			Branch: client.NewBranch("montage", "master"),
+			Provenance: []*pfs.CommitProvenance{
+				client.NewCommitProvenance("edges", "master", "b"),
+				client.NewCommitProvenance("images", "master", "a"),
+			},
+		},
+	}
+	s := &stuckBranchAnalyzer{
+		inspectCommit: func(commit *pfs.Commit) (*pfs.CommitInfo, error) {
+			ci, ok := commits[commitKey(commit)]
+			if !ok {
+				return nil, fmt.Errorf("commit %s not found", commitKey(commit))
+			}
+			return ci, nil
+		},
+		jobForCommit: func(commit *pfs.Commit) (*pps.JobInfo, error) {
+			return nil, nil
+		},
+		pipelines: map[string]*pps.PipelineInfo{
+			"edges":   {Pipeline: client.NewPipeline("edges"), OutputBranch: "master"},
+			"montage": {Pipeline: client.NewPipeline("montage"), OutputBranch: "master"},
+		},
+		commits: make(map[string]*pfs.CommitInfo),
+	}
+
+	blockers, err := s.blockers(commits["montage@c"])
+	require.NoError(t, err)
+	require.Equal(t, 1, len(blockers))
+	require.Equal(t, "images", blockers[0].Commit.Repo.Name)
+	require.Nil(t, blockers[0].Pipeline)
+	require.OneOfEquals(t, "pachctl finish commit images@a", blockers[0].Remediations)
+
+	// Once the images commit is finished, edges is the blocker
+	commits["images@a"].Finished = types.TimestampNow()
+	s.commits = make(map[string]*pfs.CommitInfo)
+	blockers, err = s.blockers(commits["montage@c"])
+	require.NoError(t, err)
+	require.Equal(t, 1, len(blockers))
+	require.Equal(t, "edges", blockers[0].Commit.Repo.Name)
+	require.Equal(t, "edges", blockers[0].Pipeline.Name)
+
+	// A commit whose provenance is finished is its own blocker
+	blockers, err = s.blockers(commits["edges@b"])
+	require.NoError(t, err)
+	require.Equal(t, 1, len(blockers))
+	require.Equal(t, "edges", blockers[0].Commit.Repo.Name)
+}
+
+func TestDiagnoseBlocker(t *testing.T) {
+	ci := &pfs.CommitInfo{Commit: client.NewCommit("edges", "b")}
+	pipelineInfo := &pps.PipelineInfo{Pipeline: client.NewPipeline("edges")}
+
+	pipelineInfo.State = pps.PipelineState_PIPELINE_PAUSED
+	reason, remediations := diagnoseBlocker(ci, pipelineInfo, nil)
+	require.Matches(t, "stopped", reason)
+	require.Equal(t, []string{"pachctl start pipeline edges"}, remediations)
+
+	pipelineInfo.State = pps.PipelineState_PIPELINE_RUNNING
+	jobInfo := &pps.JobInfo{Job: client.NewJob("j"), State: pps.JobState_JOB_RUNNING, DataFailed: 2}
+	reason, remediations = diagnoseBlocker(ci, pipelineInfo, jobInfo)
+	require.Matches(t, "job j is still running, and 2 of its datums have failed", reason)
+	require.OneOfEquals(t, "pachctl stop job j", remediations)
+
+	jobInfo.State = pps.JobState_JOB_FAILURE
+	reason, remediations = diagnoseBlocker(ci, pipelineInfo, jobInfo)
+	require.Matches(t, "didn't finish its output commit", reason)
+	require.Equal(t, []string{"pachctl delete commit edges@b"}, remediations)
+}
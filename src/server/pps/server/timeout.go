@@ -0,0 +1,33 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// validateTimeoutPolicy returns an error if 'pipelineInfo' sets a timeout
+// policy that has no effect or that its workers can't follow
+func validateTimeoutPolicy(pipelineInfo *pps.PipelineInfo) error {
+	switch pipelineInfo.TimeoutPolicy {
+	case pps.TimeoutPolicy_TIMEOUT_FAIL_JOB:
+		return nil
+	case pps.TimeoutPolicy_TIMEOUT_SKIP_DATUM:
+		if pipelineInfo.DatumTimeout == nil {
+			return fmt.Errorf("timeout_policy %s requires datum_timeout", pipelineInfo.TimeoutPolicy)
+		}
+	case pps.TimeoutPolicy_TIMEOUT_PARTIAL_SUCCESS:
+		if pipelineInfo.DatumTimeout == nil && pipelineInfo.JobTimeout == nil {
+			return fmt.Errorf("timeout_policy %s requires datum_timeout or job_timeout", pipelineInfo.TimeoutPolicy)
+		}
+	default:
+		return fmt.Errorf("unrecognized timeout_policy %s", pipelineInfo.TimeoutPolicy)
+	}
+	if pipelineInfo.Service != nil || pipelineInfo.Spout != nil {
+		return fmt.Errorf("timeout_policy can't be set for services or spouts, which don't process datums")
+	}
+	if pipelineInfo.Backend != nil {
+		return fmt.Errorf("timeout_policy can't be set for pipelines with an execution backend")
+	}
+	return nil
+}
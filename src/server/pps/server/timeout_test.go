@@ -0,0 +1,38 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+
+	"github.com/gogo/protobuf/types"
+)
+
+func TestValidateTimeoutPolicy(t *testing.T) {
+	timeout := types.DurationProto(time.Minute)
+	require.NoError(t, validateTimeoutPolicy(&pps.PipelineInfo{}))
+	require.NoError(t, validateTimeoutPolicy(&pps.PipelineInfo{
+		TimeoutPolicy: pps.TimeoutPolicy_TIMEOUT_SKIP_DATUM,
+		DatumTimeout:  timeout,
+	}))
+	require.NoError(t, validateTimeoutPolicy(&pps.PipelineInfo{
+		TimeoutPolicy: pps.TimeoutPolicy_TIMEOUT_PARTIAL_SUCCESS,
+		JobTimeout:    timeout,
+	}))
+
+	// skipping datums needs a datum timeout
+	require.YesError(t, validateTimeoutPolicy(&pps.PipelineInfo{
+		TimeoutPolicy: pps.TimeoutPolicy_TIMEOUT_SKIP_DATUM,
+		JobTimeout:    timeout,
+	}))
+	require.YesError(t, validateTimeoutPolicy(&pps.PipelineInfo{
+		TimeoutPolicy: pps.TimeoutPolicy_TIMEOUT_PARTIAL_SUCCESS,
+	}))
+	require.YesError(t, validateTimeoutPolicy(&pps.PipelineInfo{
+		TimeoutPolicy: pps.TimeoutPolicy_TIMEOUT_SKIP_DATUM,
+		DatumTimeout:  timeout,
+		Service:       &pps.Service{},
+	}))
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// releaseVersion returns the release that the version 'v' is from (e.g.
+// "1.10.0" for "1.10.0-rc1"), or "" if 'v' isn't from a release (e.g. it's
+// the tag of a development build)
+func releaseVersion(v string) string {
+	v = strings.SplitN(v, "-", 2)[0]
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	for _, part := range parts {
+		if _, err := strconv.Atoi(part); err != nil {
+			return ""
+		}
+	}
+	return v
+}
+
+// workerImageSkew returns an error if 'workerImage' is the worker image of a
+// release of Pachyderm other than pachd's ('pachdVersion'). Workers from
+// other releases fail with RPC errors when they talk to pachd. Images that
+// aren't tagged with a release can't be checked, and are assumed to match.
+func workerImageSkew(workerImage, pachdVersion string) error {
+	workerRelease := releaseVersion(imageTag(workerImage))
+	pachdRelease := releaseVersion(pachdVersion)
+	if workerRelease == "" || pachdRelease == "" || workerRelease == pachdRelease {
+		return nil
+	}
+	return fmt.Errorf("pachd's worker image %s is from Pachyderm %s, but pachd is version %s; "+
+		"redeploy pachd with the worker image from %s, then update the pipeline",
+		workerImage, workerRelease, pachdVersion, pachdRelease)
+}
+
+// rcWorkerImage returns the worker image that 'rc' starts its pods with, or
+// "" if it has none
+func rcWorkerImage(rc *v1.ReplicationController) string {
+	if rc.Spec.Template == nil {
+		return ""
+	}
+	for _, container := range rc.Spec.Template.Spec.InitContainers {
+		if container.Name == "init" {
+			return container.Image
+		}
+	}
+	return ""
+}
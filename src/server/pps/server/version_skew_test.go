@@ -0,0 +1,43 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestReleaseVersion(t *testing.T) {
+	require.Equal(t, "1.10.0", releaseVersion("1.10.0"))
+	require.Equal(t, "1.10.0", releaseVersion("1.10.0-rc1"))
+	require.Equal(t, "", releaseVersion("local"))
+	require.Equal(t, "", releaseVersion("latest"))
+	require.Equal(t, "", releaseVersion("1.10"))
+}
+
+func TestWorkerImageSkew(t *testing.T) {
+	require.NoError(t, workerImageSkew("pachyderm/worker:1.10.0", "1.10.0"))
+	require.NoError(t, workerImageSkew("pachyderm/worker:1.10.0", "1.10.0-5f3c2a"))
+	// development builds can't be checked
+	require.NoError(t, workerImageSkew("pachyderm/worker:local", "1.10.0"))
+	require.NoError(t, workerImageSkew("pachyderm/worker:1.9.3", "local"))
+
+	err := workerImageSkew("registry:5000/pachyderm/worker:1.9.3", "1.10.0")
+	require.YesError(t, err)
+	require.Matches(t, "from Pachyderm 1.9.3, but pachd is version 1.10.0", err.Error())
+}
+
+func TestRCWorkerImage(t *testing.T) {
+	require.Equal(t, "", rcWorkerImage(&v1.ReplicationController{}))
+	rc := &v1.ReplicationController{
+		Spec: v1.ReplicationControllerSpec{
+			Template: &v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{{Name: "init", Image: "pachyderm/worker:1.10.0"}},
+				},
+			},
+		},
+	}
+	require.Equal(t, "pachyderm/worker:1.10.0", rcWorkerImage(rc))
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// windowsImageSuffix is appended to the tag of the worker image to get the tag
+// of the equivalent Windows image, which contains the worker and pachd binaries
+// built for windows/amd64
+const windowsImageSuffix = "-windows"
+
+// isWindows returns true if 'spec' asks for the pipeline's workers to run on
+// Windows nodes
+func isWindows(spec *pps.SchedulingSpec) bool {
+	return spec != nil && spec.OS == windowsOS
+}
+
+// windowsPath converts an absolute path inside a linux worker container, e.g.
+// "/pfs", to the equivalent path inside a Windows container, e.g. "C:\pfs".
+func windowsPath(path string) string {
+	if len(path) >= 2 && path[1] == ':' {
+		return path // already a Windows path
+	}
+	return "C:" + strings.Replace(path, "/", `\`, -1)
+}
+
+// windowsImage returns the Windows equivalent of the worker image 'image'
+func windowsImage(image string) string {
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image + windowsImageSuffix
+	}
+	return image + ":latest" + windowsImageSuffix
+}
+
+// windowsPodSpec modifies 'podSpec', a worker pod spec generated for linux
+// nodes, so that it runs on Windows nodes instead (the Windows nodes
+// themselves are selected by nodeSelector). The init and sidecar
+// containers use the Windows worker image, and all volumes are mounted at the
+// equivalent Windows paths (so that datums are downloaded to C:\pfs).
+func windowsPodSpec(podSpec *v1.PodSpec, workerImage string) {
+	for i := range podSpec.InitContainers {
+		container := &podSpec.InitContainers[i]
+		container.Image = windowsImage(workerImage)
+		container.Command = []string{"cmd", "/c", `C:\pach\worker.cmd`}
+		container.VolumeMounts = windowsVolumeMounts(container.VolumeMounts)
+	}
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		switch container.Name {
+		case client.PPSWorkerUserContainerName:
+			container.Command = []string{`C:\pach-bin\worker.exe`}
+		case client.PPSWorkerSidecarContainerName:
+			container.Image = windowsImage(workerImage)
+			container.Command = []string{`C:\pach\pachd.exe`, "--mode", "sidecar"}
+		case egressProxyContainerName:
+			container.Image = windowsImage(workerImage)
+			container.Command = []string{`C:\pach\pachd.exe`, "--mode", "egress-proxy"}
+		}
+		container.VolumeMounts = windowsVolumeMounts(container.VolumeMounts)
+	}
+}
+
+func windowsVolumeMounts(mounts []v1.VolumeMount) []v1.VolumeMount {
+	result := make([]v1.VolumeMount, len(mounts))
+	for i, mount := range mounts {
+		mount.MountPath = windowsPath(mount.MountPath)
+		result[i] = mount
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestWindowsPodSpec(t *testing.T) {
+	require.Equal(t, `C:\pfs`, windowsPath(client.PPSInputPrefix))
+	require.Equal(t, `C:\pfs`, windowsPath(`C:\pfs`))
+	require.Equal(t, "pachyderm/worker:1.10.0-windows", windowsImage("pachyderm/worker:1.10.0"))
+	require.Equal(t, "localhost:5000/worker:latest-windows", windowsImage("localhost:5000/worker"))
+
+	mounts := []v1.VolumeMount{{Name: client.PPSWorkerVolume, MountPath: client.PPSInputPrefix}}
+	podSpec := v1.PodSpec{
+		InitContainers: []v1.Container{{Name: "init", VolumeMounts: mounts}},
+		Containers: []v1.Container{
+			{Name: client.PPSWorkerUserContainerName, Image: "user", VolumeMounts: mounts},
+			{Name: client.PPSWorkerSidecarContainerName, Image: "pachd"},
+		},
+	}
+	windowsPodSpec(&podSpec, "pachyderm/worker:1.10.0")
+	require.Equal(t, "pachyderm/worker:1.10.0-windows", podSpec.InitContainers[0].Image)
+	require.Equal(t, `C:\pfs`, podSpec.InitContainers[0].VolumeMounts[0].MountPath)
+	require.Equal(t, "user", podSpec.Containers[0].Image)
+	require.Equal(t, []string{`C:\pach-bin\worker.exe`}, podSpec.Containers[0].Command)
+	require.Equal(t, `C:\pfs`, podSpec.Containers[0].VolumeMounts[0].MountPath)
+	require.Equal(t, "pachyderm/worker:1.10.0-windows", podSpec.Containers[1].Image)
+	// The original mounts are not modified
+	require.Equal(t, client.PPSInputPrefix, mounts[0].MountPath)
+}
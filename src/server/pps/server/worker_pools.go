@@ -0,0 +1,98 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// workerPoolLabel is the label of the RCs that run the extra worker pools of
+// a pipeline with max_concurrent_jobs, whose value is the pool's number. Like
+// datumProfileLabel, it keeps the PPS master from mistaking them for the
+// pipeline's own RC, which runs its first pool.
+const workerPoolLabel = "workerPool"
+
+// maxConcurrentJobs is the largest max_concurrent_jobs that a pipeline may
+// have. Each concurrent job has a pool of workers of its own.
+const maxConcurrentJobs = 100
+
+// validateMaxConcurrentJobs returns an error if 'pipelineInfo' sets a
+// max_concurrent_jobs that's out of range, or that its jobs can't run with.
+// Concurrent jobs can't rely on the output of the jobs before them, which
+// s3_out pipelines start from, and each job's datums are only processed by
+// the workers of its pool, so datum profiles' workers would be left out.
+func validateMaxConcurrentJobs(pipelineInfo *pps.PipelineInfo) error {
+	if pipelineInfo.MaxConcurrentJobs <= 1 {
+		if pipelineInfo.MaxConcurrentJobs < 0 {
+			return fmt.Errorf("max_concurrent_jobs can't be negative")
+		}
+		return nil
+	}
+	switch {
+	case pipelineInfo.MaxConcurrentJobs > maxConcurrentJobs:
+		return fmt.Errorf("max_concurrent_jobs can be at most %d", maxConcurrentJobs)
+	case pipelineInfo.Service != nil || pipelineInfo.Spout != nil:
+		return fmt.Errorf("services and spouts can't run concurrent jobs")
+	case pipelineInfo.Backend != nil:
+		return fmt.Errorf("pipelines with an execution backend can't run concurrent jobs")
+	case pipelineInfo.S3Out:
+		return fmt.Errorf("pipelines with s3_out can't run concurrent jobs, as each job starts from the output of the job before it")
+	case len(pipelineInfo.DatumProfiles) > 0:
+		return fmt.Errorf("pipelines with datum profiles can't run concurrent jobs")
+	}
+	return nil
+}
+
+// workerPoolWorkerOptions returns the options for the RC of the worker pool
+// 'pool' (which is at least 2) of 'pipelineInfo'
+func (a *apiServer) workerPoolWorkerOptions(ptr *pps.EtcdPipelineInfo, pipelineInfo *pps.PipelineInfo, pool int64) (*workerOptions, error) {
+	options, err := a.getWorkerOptions(ptr, pipelineInfo)
+	if err != nil {
+		return nil, err
+	}
+	options.rcName = ppsutil.WorkerPoolRcName(pipelineInfo.Pipeline.Name, pipelineInfo.Version, pool)
+	options.labels = labels(options.rcName)
+	options.labels[pipelineNameLabel] = pipelineInfo.Pipeline.Name
+	options.labels[workerPoolLabel] = strconv.FormatInt(pool, 10)
+	options.workerEnv = append(options.workerEnv, v1.EnvVar{
+		Name:  client.PPSWorkerPoolEnv,
+		Value: strconv.FormatInt(pool, 10),
+	})
+	return options, nil
+}
+
+// createWorkerPoolRCs creates the RCs of the extra worker pools of
+// 'pipelineInfo' (with no workers, like the pipeline's own RC, which runs its
+// first pool), and deletes the RCs of its old pools and versions
+func (a *apiServer) createWorkerPoolRCs(ptr *pps.EtcdPipelineInfo, pipelineInfo *pps.PipelineInfo) error {
+	current := make(map[string]*workerOptions)
+	for pool := int64(2); pool <= pipelineInfo.MaxConcurrentJobs; pool++ {
+		options, err := a.workerPoolWorkerOptions(ptr, pipelineInfo, pool)
+		if err != nil {
+			return noValidOptionsErr{err}
+		}
+		current[options.rcName] = options
+	}
+	return a.syncExtraRCs(pipelineInfo.Pipeline.Name, workerPoolLabel, current)
+}
+
+// scaleWorkerPools sets the number of workers of each of the extra worker
+// pools of op's pipeline to 'parallelism' (the number of workers of the
+// pipeline's own RC), so that every job gets as many workers.
+//
+// Like scaleDatumProfiles, it takes responsibility for failing op's pipeline
+// if it can't update the pools' RCs
+func (op *pipelineOp) scaleWorkerPools(parallelism int32) error {
+	for pool := int64(2); pool <= op.pipelineInfo.MaxConcurrentJobs; pool++ {
+		name := ppsutil.WorkerPoolRcName(op.name, op.pipelineInfo.Version, pool)
+		if err := op.scaleExtraRC(name, parallelism, fmt.Sprintf("worker pool %d", pool)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
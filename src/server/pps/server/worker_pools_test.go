@@ -0,0 +1,26 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestValidateMaxConcurrentJobs(t *testing.T) {
+	require.NoError(t, validateMaxConcurrentJobs(&pps.PipelineInfo{}))
+	require.NoError(t, validateMaxConcurrentJobs(&pps.PipelineInfo{MaxConcurrentJobs: 1}))
+	require.NoError(t, validateMaxConcurrentJobs(&pps.PipelineInfo{MaxConcurrentJobs: 4}))
+	// Pipelines that run one job at a time may have anything else
+	require.NoError(t, validateMaxConcurrentJobs(&pps.PipelineInfo{MaxConcurrentJobs: 1, S3Out: true}))
+
+	require.YesError(t, validateMaxConcurrentJobs(&pps.PipelineInfo{MaxConcurrentJobs: -1}))
+	require.YesError(t, validateMaxConcurrentJobs(&pps.PipelineInfo{MaxConcurrentJobs: maxConcurrentJobs + 1}))
+	require.YesError(t, validateMaxConcurrentJobs(&pps.PipelineInfo{MaxConcurrentJobs: 4, Service: &pps.Service{}}))
+	require.YesError(t, validateMaxConcurrentJobs(&pps.PipelineInfo{MaxConcurrentJobs: 4, Spout: &pps.Spout{}}))
+	require.YesError(t, validateMaxConcurrentJobs(&pps.PipelineInfo{MaxConcurrentJobs: 4, S3Out: true}))
+	require.YesError(t, validateMaxConcurrentJobs(&pps.PipelineInfo{
+		MaxConcurrentJobs: 4,
+		DatumProfiles:     []*pps.DatumProfile{{Name: "large", MinSizeBytes: 1}},
+	}))
+}
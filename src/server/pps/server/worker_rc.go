@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 
 	jsonpatch "github.com/evanphx/json-patch"
@@ -16,7 +17,10 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/pkg/tracing"
 	"github.com/pachyderm/pachyderm/src/client/pps"
 	"github.com/pachyderm/pachyderm/src/client/version"
+	"github.com/pachyderm/pachyderm/src/server/pfs/artifactcache"
+	"github.com/pachyderm/pachyderm/src/server/pkg/blockshare"
 	"github.com/pachyderm/pachyderm/src/server/pkg/deploy/assets"
+	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
 	"github.com/pachyderm/pachyderm/src/server/worker"
 
@@ -53,6 +57,10 @@ type workerOptions struct {
 	schedulingSpec   *pps.SchedulingSpec // the SchedulingSpec for the pipeline
 	podSpec          string
 	podPatch         string
+	sandbox          *pps.Sandbox // restricts the privileges of the user code
+	egressProxy      *pps.EgressProxy
+	scratchVolume    *pps.ScratchVolume // where workers store datums
+	serviceAccount   string             // the service account that workers run as, if not the default
 
 	// Secrets that we mount in the worker container (e.g. for reading/writing to
 	// s3)
@@ -96,9 +104,16 @@ func (a *apiServer) workerPodSpec(options *workerOptions) (v1.PodSpec, error) {
 		return v1.PodSpec{}, err
 	}
 	sidecarEnv = append(sidecarEnv, storageEnvVars...)
+	// Workers and their sidecars read collections from wherever pachd stores
+	// them
+	sidecarEnv = append(sidecarEnv, assets.MetadataStoreEnvVars(a.env.MetadataStore)...)
+	if a.workerBlockSharingCacheSize > 0 {
+		sidecarEnv = append(sidecarEnv, blockSharingEnvVars(options.labels[pipelineNameLabel], a.workerBlockSharingCacheSize)...)
+	}
 	workerEnv := options.workerEnv
 	workerEnv = append(workerEnv, v1.EnvVar{Name: "PACH_ROOT", Value: a.storageRoot})
 	workerEnv = append(workerEnv, assets.GetSecretEnvVars(a.storageBackend)...)
+	workerEnv = append(workerEnv, assets.MetadataStoreEnvVars(a.env.MetadataStore)...)
 	// This only happens in local deployment.  We want the workers to be
 	// able to read from/write to the hostpath volume as well.
 	storageVolumeName := "pach-disk"
@@ -136,10 +151,23 @@ func (a *apiServer) workerPodSpec(options *workerOptions) (v1.PodSpec, error) {
 		sidecarVolumeMounts = append(sidecarVolumeMounts, emptyDirVolumeMount)
 		userVolumeMounts = append(userVolumeMounts, emptyDirVolumeMount)
 	}
+	if options.scratchVolume != nil {
+		scratchVolume, scratchMount := scratchVolume(options.scratchVolume)
+		options.volumes = append(options.volumes, scratchVolume)
+		userVolumeMounts = append(userVolumeMounts, scratchMount)
+	}
 	secretVolume, secretMount := assets.GetBackendSecretVolumeAndMount(a.storageBackend)
 	options.volumes = append(options.volumes, secretVolume)
 	sidecarVolumeMounts = append(sidecarVolumeMounts, secretMount)
 	userVolumeMounts = append(userVolumeMounts, secretMount)
+	// The worker and its sidecar authenticate to pachd, etcd and other workers
+	// with the worker pods' internal cert
+	tlsVolume, tlsMount, internalTLS := internalTLSVolume()
+	if internalTLS {
+		options.volumes = append(options.volumes, tlsVolume)
+		sidecarVolumeMounts = append(sidecarVolumeMounts, tlsMount)
+		userVolumeMounts = append(userVolumeMounts, tlsMount)
+	}
 
 	// Explicitly set CPU, MEM and DISK requests to zero because some cloud
 	// providers set their own defaults which are usually not what we want.
@@ -147,7 +175,10 @@ func (a *apiServer) workerPodSpec(options *workerOptions) (v1.PodSpec, error) {
 	memZeroQuantity := resource.MustParse("0M")
 	memSidecarQuantity := resource.MustParse(options.cacheSize)
 
-	if !a.noExposeDockerSocket {
+	windows := isWindows(options.schedulingSpec)
+	// Sandboxed user code can't reach the docker socket, which would give it
+	// root on the node
+	if !a.noExposeDockerSocket && !windows && options.sandbox == nil {
 		options.volumes = append(options.volumes, v1.Volume{
 			Name: "docker",
 			VolumeSource: v1.VolumeSource{
@@ -164,7 +195,7 @@ func (a *apiServer) workerPodSpec(options *workerOptions) (v1.PodSpec, error) {
 	zeroVal := int64(0)
 	workerImage := a.workerImage
 	var securityContext *v1.PodSecurityContext
-	if a.workerUsesRoot {
+	if a.workerUsesRoot && !windows {
 		securityContext = &v1.PodSecurityContext{RunAsUser: &zeroVal}
 	}
 	resp, err := a.env.GetPachClient(context.Background()).Enterprise.GetState(context.Background(), &enterprise.GetStateRequest{})
@@ -219,10 +250,26 @@ func (a *apiServer) workerPodSpec(options *workerOptions) (v1.PodSpec, error) {
 		ImagePullSecrets:              options.imagePullSecrets,
 		TerminationGracePeriodSeconds: &zeroVal,
 		SecurityContext:               securityContext,
+		ServiceAccountName:            options.serviceAccount,
+	}
+	if options.egressProxy != nil {
+		egressProxy := a.egressProxyContainer(options.egressProxy, options.labels[pipelineNameLabel], pullPolicy)
+		if internalTLS {
+			// The egress proxy writes to the audit log in etcd
+			egressProxy.VolumeMounts = append(egressProxy.VolumeMounts, tlsMount)
+		}
+		podSpec.Containers = append(podSpec.Containers, egressProxy)
 	}
 	if options.schedulingSpec != nil {
-		podSpec.NodeSelector = options.schedulingSpec.NodeSelector
+		podSpec.NodeSelector = nodeSelector(options.schedulingSpec)
 		podSpec.PriorityClassName = options.schedulingSpec.PriorityClassName
+		podSpec.Tolerations = tolerations(options.schedulingSpec)
+	}
+	if windows {
+		windowsPodSpec(&podSpec, workerImage)
+	}
+	if options.sandbox != nil {
+		podSpec.Containers[0].SecurityContext = sandboxSecurityContext()
 	}
 	resourceRequirements := v1.ResourceRequirements{
 		Requests: map[v1.ResourceName]resource.Quantity{
@@ -236,6 +283,9 @@ func (a *apiServer) workerPodSpec(options *workerOptions) (v1.PodSpec, error) {
 	if options.resourceLimits != nil {
 		resourceRequirements.Limits = *options.resourceLimits
 	}
+	if options.scratchVolume != nil {
+		resourceRequirements.Requests = scratchRequests(resourceRequirements.Requests, options.scratchVolume)
+	}
 	podSpec.Containers[0].Resources = resourceRequirements
 	if options.podSpec != "" || options.podPatch != "" {
 		jsonPodSpec, err := json.Marshal(&podSpec)
@@ -275,9 +325,48 @@ func getStorageEnvVars() ([]v1.EnvVar, error) {
 	if !ok {
 		return nil, fmt.Errorf("%s not found", assets.UploadConcurrencyLimitEnvVar)
 	}
-	return []v1.EnvVar{
+	envVars := []v1.EnvVar{
 		{Name: assets.UploadConcurrencyLimitEnvVar, Value: uploadConcurrencyLimit},
-	}, nil
+	}
+	// Workers retry, throttle and encrypt object storage requests like pachd
+	// does, and compress chunks with the same codec
+	for _, name := range []string{
+		obj.MaxAttemptsEnvVar,
+		obj.BackoffInitialEnvVar,
+		obj.BackoffMaxEnvVar,
+		obj.MaxConcurrencyEnvVar,
+		obj.RateLimitEnvVar,
+		obj.CircuitBreakerThresholdEnvVar,
+		obj.CircuitBreakerCooldownEnvVar,
+		obj.EncryptionKeyEnvVar,
+		obj.EncryptionKeyReuseEnvVar,
+		assets.CompressionEnvVar,
+	} {
+		if value, ok := os.LookupEnv(name); ok {
+			envVars = append(envVars, v1.EnvVar{Name: name, Value: value})
+		}
+	}
+	return envVars, nil
+}
+
+// blockSharingEnvVars returns the env vars that make a worker's sidecar share
+// blocks with the sidecars of the pipeline's other workers on its node (see
+// the blockshare package)
+func blockSharingEnvVars(pipelineName string, cacheSize int64) []v1.EnvVar {
+	fieldRef := func(fieldPath string) *v1.EnvVarSource {
+		return &v1.EnvVarSource{
+			FieldRef: &v1.ObjectFieldSelector{
+				APIVersion: "v1",
+				FieldPath:  fieldPath,
+			},
+		}
+	}
+	return []v1.EnvVar{
+		{Name: blockshare.CacheSizeEnvVar, Value: strconv.FormatInt(cacheSize, 10)},
+		{Name: client.PPSPipelineNameEnv, Value: pipelineName},
+		{Name: client.PPSWorkerIPEnv, ValueFrom: fieldRef("status.podIP")},
+		{Name: blockshare.NodeNameEnvVar, ValueFrom: fieldRef("spec.nodeName")},
+	}
 }
 
 // We don't want to expose pipeline auth tokens, so we hash it. This will be
@@ -315,7 +404,7 @@ func (a *apiServer) getWorkerOptions(ptr *pps.EtcdPipelineInfo, pipelineInfo *pp
 	rcName := ppsutil.PipelineRcName(pipelineName, pipelineVersion)
 	labels := labels(rcName)
 	labels[pipelineNameLabel] = pipelineName
-	userImage := transform.Image
+	userImage := ppsutil.PipelineImage(pipelineInfo)
 	if userImage == "" {
 		userImage = DefaultUserImage
 	}
@@ -378,6 +467,33 @@ func (a *apiServer) getWorkerOptions(ptr *pps.EtcdPipelineInfo, pipelineInfo *pp
 		Name:  client.PeerPortEnv,
 		Value: strconv.FormatUint(uint64(a.peerPort), 10),
 	})
+	if a.workerRPCCacheSize > 0 {
+		workerEnv = append(workerEnv, v1.EnvVar{
+			Name:  client.RPCCacheSizeEnv,
+			Value: strconv.FormatInt(a.workerRPCCacheSize, 10),
+		})
+	}
+	// Point package managers at the artifact cache (k8s expands the pachd
+	// service's host, which is set in every pod). Env vars set in the pipeline's
+	// transform take precedence.
+	if a.artifactCachePort != 0 {
+		cacheEnv := artifactcache.WorkerEnv("$(PACHD_SERVICE_HOST)", a.artifactCachePort)
+		var names []string
+		for name := range cacheEnv {
+			if _, ok := transform.Env[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			workerEnv = append(workerEnv, v1.EnvVar{Name: name, Value: cacheEnv[name]})
+		}
+	}
+	// Confine the user code to its fraction of a shared GPU, unless the
+	// pipeline's transform does so itself
+	if _, ok := transform.Env[mpsThreadPercentageEnv]; !ok {
+		workerEnv = append(workerEnv, gpuEnv(pipelineInfo.ResourceRequests, pipelineInfo.ResourceLimits)...)
+	}
 
 	var volumes []v1.Volume
 	var volumeMounts []v1.VolumeMount
@@ -411,6 +527,72 @@ func (a *apiServer) getWorkerOptions(ptr *pps.EtcdPipelineInfo, pipelineInfo *pp
 		}
 	}
 
+	if egress := pipelineInfo.Egress; egress != nil && egress.SQLDatabase != nil && egress.SQLDatabase.Secret != nil {
+		workerEnv = append(workerEnv, v1.EnvVar{
+			Name: client.PPSEgressSecretEnv,
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{
+						Name: egress.SQLDatabase.Secret.Name,
+					},
+					Key: egress.SQLDatabase.Secret.Key,
+				},
+			},
+		})
+	}
+
+	if tracking := pipelineInfo.ExperimentTracking; tracking != nil && tracking.Secret != nil {
+		workerEnv = append(workerEnv, v1.EnvVar{
+			Name: client.PPSTrackingSecretEnv,
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{
+						Name: tracking.Secret.Name,
+					},
+					Key: tracking.Secret.Key,
+				},
+			},
+		})
+	}
+
+	if volume, mount := secretsVolume(transform.Secrets); volume != nil {
+		volumes = append(volumes, *volume)
+		volumeMounts = append(volumeMounts, *mount)
+	}
+
+	for _, key := range transform.ConfigMaps {
+		workerEnv = append(workerEnv, v1.EnvVar{
+			Name: key.EnvVar,
+			ValueFrom: &v1.EnvVarSource{
+				ConfigMapKeyRef: &v1.ConfigMapKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{
+						Name: key.Name,
+					},
+					Key: key.Key,
+				},
+			},
+		})
+	}
+	if volume, mount := configMapsVolume(transform.ConfigMaps); volume != nil {
+		volumes = append(volumes, *volume)
+		volumeMounts = append(volumeMounts, *mount)
+	}
+
+	var serviceAccount string
+	if creds := pipelineInfo.CloudCredentials; creds != nil {
+		serviceAccount = workerServiceAccountName(pipelineName)
+		if creds.AWS != nil {
+			volume, mount := cloudTokenVolume(creds.AWS)
+			volumes = append(volumes, volume)
+			volumeMounts = append(volumeMounts, mount)
+		}
+	}
+
+	backendEnv, backendVolumes, backendVolumeMounts := a.backendEnvAndVolumes(pipelineInfo.Backend)
+	workerEnv = append(workerEnv, backendEnv...)
+	volumes = append(volumes, backendVolumes...)
+	volumeMounts = append(volumeMounts, backendVolumeMounts...)
+
 	volumes = append(volumes, v1.Volume{
 		Name: "pach-bin",
 		VolumeSource: v1.VolumeSource{
@@ -466,6 +648,11 @@ func (a *apiServer) getWorkerOptions(ptr *pps.EtcdPipelineInfo, pipelineInfo *pp
 			}
 		}
 	}
+	if transform.Sandbox != nil {
+		for k, v := range sandboxAnnotations(transform.Sandbox) {
+			annotations[k] = v
+		}
+	}
 
 	// Generate options for new RC
 	return &workerOptions{
@@ -485,6 +672,10 @@ func (a *apiServer) getWorkerOptions(ptr *pps.EtcdPipelineInfo, pipelineInfo *pp
 		schedulingSpec:   pipelineInfo.SchedulingSpec,
 		podSpec:          pipelineInfo.PodSpec,
 		podPatch:         pipelineInfo.PodPatch,
+		sandbox:          transform.Sandbox,
+		egressProxy:      pipelineInfo.EgressProxy,
+		scratchVolume:    pipelineInfo.ScratchVolume,
+		serviceAccount:   serviceAccount,
 	}, nil
 }
 
@@ -496,24 +687,10 @@ type noValidOptionsErr struct {
 	error
 }
 
-func (a *apiServer) createWorkerSvcAndRc(ctx context.Context, ptr *pps.EtcdPipelineInfo, pipelineInfo *pps.PipelineInfo) (retErr error) {
-	log.Infof("PPS master: upserting workers for %q", pipelineInfo.Pipeline.Name)
-	span, ctx := tracing.AddSpanToAnyExisting(ctx, "/pps.Master/CreateWorkerRC", //lint:ignore SA4006 ctx never used, but we want the right one in scope for future uses
-		"pipeline", pipelineInfo.Pipeline.Name)
-	defer func() {
-		tracing.TagAnySpan(span, "err", retErr)
-		tracing.FinishAnySpan(span)
-	}()
-
-	options, err := a.getWorkerOptions(ptr, pipelineInfo)
-	if err != nil {
-		return noValidOptionsErr{err}
-	}
-	podSpec, err := a.workerPodSpec(options)
-	if err != nil {
-		return err
-	}
-	rc := &v1.ReplicationController{
+// workerRc returns the RC that runs the workers described by 'options', in
+// pods with the spec 'podSpec'
+func workerRc(options *workerOptions, podSpec v1.PodSpec) *v1.ReplicationController {
+	return &v1.ReplicationController{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "ReplicationController",
 			APIVersion: "v1",
@@ -536,11 +713,40 @@ func (a *apiServer) createWorkerSvcAndRc(ctx context.Context, ptr *pps.EtcdPipel
 			},
 		},
 	}
-	if _, err := a.env.GetKubeClient().CoreV1().ReplicationControllers(a.namespace).Create(rc); err != nil {
+}
+
+func (a *apiServer) createWorkerSvcAndRc(ctx context.Context, ptr *pps.EtcdPipelineInfo, pipelineInfo *pps.PipelineInfo) (retErr error) {
+	log.Infof("PPS master: upserting workers for %q", pipelineInfo.Pipeline.Name)
+	span, ctx := tracing.AddSpanToAnyExisting(ctx, "/pps.Master/CreateWorkerRC", //lint:ignore SA4006 ctx never used, but we want the right one in scope for future uses
+		"pipeline", pipelineInfo.Pipeline.Name)
+	defer func() {
+		tracing.TagAnySpan(span, "err", retErr)
+		tracing.FinishAnySpan(span)
+	}()
+
+	options, err := a.getWorkerOptions(ptr, pipelineInfo)
+	if err != nil {
+		return noValidOptionsErr{err}
+	}
+	// Workers can't start until their service account exists
+	if err := a.upsertServiceAccount(pipelineInfo); err != nil {
+		return err
+	}
+	podSpec, err := a.workerPodSpec(options)
+	if err != nil {
+		return err
+	}
+	if _, err := a.env.GetKubeClient().CoreV1().ReplicationControllers(a.namespace).Create(workerRc(options, podSpec)); err != nil {
 		if !isAlreadyExistsErr(err) {
 			return err
 		}
 	}
+	if err := a.createDatumProfileRCs(ptr, pipelineInfo); err != nil {
+		return err
+	}
+	if err := a.createWorkerPoolRCs(ptr, pipelineInfo); err != nil {
+		return err
+	}
 	serviceAnnotations := map[string]string{
 		"prometheus.io/scrape": "true",
 		"prometheus.io/port":   strconv.Itoa(worker.PrometheusPort),
@@ -611,6 +817,10 @@ func (a *apiServer) createWorkerSvcAndRc(ctx context.Context, ptr *pps.EtcdPipel
 		}
 	}
 
+	if err := a.upsertNetworkPolicy(pipelineInfo); err != nil {
+		return err
+	}
+
 	// True if the pipeline has a git input
 	var hasGitInput bool
 	pps.VisitInput(pipelineInfo.Input, func(input *pps.Input) {
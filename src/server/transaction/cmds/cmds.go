@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/gogo/protobuf/jsonpb"
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
 	"github.com/pachyderm/pachyderm/src/client/transaction"
@@ -20,11 +19,9 @@ import (
 func Cmds() []*cobra.Command {
 	var commands []*cobra.Command
 
-	marshaller := &jsonpb.Marshaler{Indent: "  "}
-
-	raw := false
-	rawFlags := pflag.NewFlagSet("", pflag.ContinueOnError)
-	rawFlags.BoolVar(&raw, "raw", false, "disable pretty printing, print raw json")
+	// transaction commands have always printed raw resources in lowerCamelCase
+	output := &cmdutil.Output{}
+	outputFlags := output.Flags("output", "o")
 
 	fullTimestamps := false
 	fullTimestampsFlags := pflag.NewFlagSet("", pflag.ContinueOnError)
@@ -59,13 +56,17 @@ transaction' or cancelled with 'delete transaction'.`,
 				return err
 			}
 			defer c.Close()
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
 			transactions, err := c.ListTransaction()
 			if err != nil {
 				return err
 			}
-			if raw {
+			if e != nil {
 				for _, transaction := range transactions {
-					if err := marshaller.Marshal(os.Stdout, transaction); err != nil {
+					if err := e.EncodeProto(transaction); err != nil {
 						return err
 					}
 				}
@@ -73,12 +74,12 @@ transaction' or cancelled with 'delete transaction'.`,
 			}
 			writer := tabwriter.NewWriter(os.Stdout, pretty.TransactionHeader)
 			for _, transaction := range transactions {
-				pretty.PrintTransactionInfo(writer, transaction, fullTimestamps)
+				pretty.PrintTransactionInfo(writer, transaction, fullTimestamps || output.Wide())
 			}
 			return writer.Flush()
 		}),
 	}
-	listTransaction.Flags().AddFlagSet(rawFlags)
+	listTransaction.Flags().AddFlagSet(outputFlags)
 	listTransaction.Flags().AddFlagSet(fullTimestampsFlags)
 	commands = append(commands, cmdutil.CreateAlias(listTransaction, "list transaction"))
 
@@ -224,6 +225,10 @@ transaction' or cancelled with 'delete transaction'.`,
 				return err
 			}
 			defer c.Close()
+			e, err := output.Encoder(os.Stdout)
+			if err != nil {
+				return err
+			}
 
 			var txn *transaction.Transaction
 			if len(args) > 0 {
@@ -242,16 +247,16 @@ transaction' or cancelled with 'delete transaction'.`,
 			if info == nil {
 				return fmt.Errorf("transaction %s not found", txn.ID)
 			}
-			if raw {
-				return marshaller.Marshal(os.Stdout, info)
+			if e != nil {
+				return e.EncodeProto(info)
 			}
 			return pretty.PrintDetailedTransactionInfo(&pretty.PrintableTransactionInfo{
 				TransactionInfo: info,
-				FullTimestamps:  fullTimestamps,
+				FullTimestamps:  fullTimestamps || output.Wide(),
 			})
 		}),
 	}
-	inspectTransaction.Flags().AddFlagSet(rawFlags)
+	inspectTransaction.Flags().AddFlagSet(outputFlags)
 	inspectTransaction.Flags().AddFlagSet(fullTimestampsFlags)
 	commands = append(commands, cmdutil.CreateAlias(inspectTransaction, "inspect transaction"))
 
@@ -121,6 +121,10 @@ func sprintUpdateJobState(request *pps.UpdateJobStateRequest) string {
 			return "KILLED"
 		case pps.JobState_JOB_MERGING:
 			return "MERGING"
+		case pps.JobState_JOB_PARTIAL_SUCCESS:
+			return "PARTIAL_SUCCESS"
+		case pps.JobState_JOB_SKIPPED:
+			return "SKIPPED"
 		default:
 			return "<unknown state>"
 		}
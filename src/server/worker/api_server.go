@@ -55,6 +55,7 @@ import (
 	filesync "github.com/pachyderm/pachyderm/src/server/pkg/sync"
 	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
 	"github.com/pachyderm/pachyderm/src/server/pkg/watch"
+	"github.com/pachyderm/pachyderm/src/server/pps/egressproxy"
 )
 
 const (
@@ -66,9 +67,16 @@ const (
 	chunkPrefix       = "/chunk"
 	mergePrefix       = "/merge"
 	shardPrefix       = "/shard"
+	replayShardPrefix = "/replay_shard"
 	shardTTL          = 30
 	noShard           = int64(-1)
 	parentTreeBufSize = 50 * (1 << (10 * 2))
+	// The shards of the extra worker pools of pipelines with
+	// max_concurrent_jobs, whose workers only merge their pool's jobs
+	workerPoolShardPrefix = "/worker_pool_shard"
+	// maxInlineChunks is the largest number of chunks a job's plan can have
+	// before it's moved out of etcd and into object storage
+	maxInlineChunks = 10000
 )
 
 type ctxKey int
@@ -80,6 +88,7 @@ const (
 var (
 	errSpecialFile    = errors.New("cannot upload special file")
 	errDatumRecovered = errors.New("the datum errored, and the error was handled successfully")
+	errDatumTimedOut  = errors.New("the datum timed out, and was left out of the job's output")
 	statsTagSuffix    = "_stats"
 )
 
@@ -98,6 +107,19 @@ type APIServer struct {
 	// The k8s pod name of this worker
 	workerName string
 
+	// The datum profile whose datums this worker processes (see
+	// pps.DatumProfile), or "" if it's one of the pipeline's own workers
+	datumProfile string
+
+	// The ID of the job that this worker replays (see pps.ReplayJob), or "" if
+	// it's one of the pipeline's own workers
+	replayOf string
+
+	// The worker pool whose jobs this worker processes (see
+	// pps.EtcdJobInfo.worker_pool), or 0 if the pipeline runs one job at a
+	// time
+	workerPool int64
+
 	statusMu sync.Mutex
 
 	// The currently running job ID
@@ -112,6 +134,8 @@ type APIServer struct {
 	stats *pps.ProcessStats
 	// queueSize is the number of items enqueued
 	queueSize int64
+	// credits is the flow control pool for the chunk currently being processed
+	credits *creditPool
 
 	// The namespace in which pachyderm is deployed
 	namespace string
@@ -151,6 +175,8 @@ type APIServer struct {
 	chunkCache, chunkStatsCache *hashtree.MergeCache
 	// datumCache caches datum hashtrees during a job and can merge them (datumStatsCache applies to stats)
 	datumCache, datumStatsCache *hashtree.MergeCache
+	// datumTrees stores the hashtrees of individual datums between jobs
+	datumTrees datumTreeStore
 	// clients are the worker clients (used for the shuffle step by mergers)
 	clients map[string]Client
 }
@@ -326,6 +352,19 @@ func NewAPIServer(pachClient *client.APIClient, etcdClient *etcd.Client, etcdPre
 	}
 	defer tracing.FinishAnySpan(span)
 
+	replayOf := os.Getenv(client.PPSReplayJobEnv)
+	shardPath := path.Join(etcdPrefix, shardPrefix, pipelineInfo.Pipeline.Name)
+	if replayOf != "" {
+		pipelineInfo = replayPipelineInfo(pipelineInfo, replayOf)
+		shardPath = path.Join(etcdPrefix, replayShardPrefix, pipelineInfo.Pipeline.Name, replayOf)
+	}
+	workerPool, err := parseWorkerPool(pipelineInfo, os.Getenv(client.PPSWorkerPoolEnv))
+	if err != nil {
+		return nil, err
+	}
+	if workerPool > 1 {
+		shardPath = path.Join(etcdPrefix, workerPoolShardPrefix, pipelineInfo.Pipeline.Name, fmt.Sprint(workerPool))
+	}
 	server := &APIServer{
 		pachClient:   oldPachClient,
 		etcdClient:   etcdClient,
@@ -336,11 +375,14 @@ func NewAPIServer(pachClient *client.APIClient, etcdClient *etcd.Client, etcdPre
 			WorkerID:     os.Getenv(client.PPSPodNameEnv),
 		},
 		workerName:      workerName,
+		datumProfile:    os.Getenv(client.PPSDatumProfileEnv),
+		replayOf:        replayOf,
+		workerPool:      workerPool,
 		namespace:       namespace,
 		jobs:            ppsdb.Jobs(etcdClient, etcdPrefix),
 		pipelines:       ppsdb.Pipelines(etcdClient, etcdPrefix),
 		plans:           col.NewCollection(etcdClient, path.Join(etcdPrefix, planPrefix), nil, &Plan{}, nil, nil),
-		shards:          col.NewCollection(etcdClient, path.Join(etcdPrefix, shardPrefix, pipelineInfo.Pipeline.Name), nil, &ShardInfo{}, nil, nil),
+		shards:          col.NewCollection(etcdClient, shardPath, nil, &ShardInfo{}, nil, nil),
 		hashtreeStorage: hashtreeStorage,
 		claimedShard:    make(chan context.Context, 1),
 		shard:           noShard,
@@ -373,6 +415,11 @@ func NewAPIServer(pachClient *client.APIClient, etcdClient *etcd.Client, etcdPre
 	server.chunkStatsCache = hashtree.NewMergeCache(filepath.Join(root, "chunk", "stats"))
 	server.datumCache = hashtree.NewMergeCache(filepath.Join(root, "datum"))
 	server.datumStatsCache = hashtree.NewMergeCache(filepath.Join(root, "datum", "stats"))
+	datumTrees, err := newDatumTreeStore(pipelineInfo, hashtreeStorage)
+	if err != nil {
+		return nil, err
+	}
+	server.datumTrees = datumTrees
 	var noDocker bool
 	if _, err := os.Stat("/var/run/docker.sock"); err != nil {
 		noDocker = true
@@ -382,9 +429,9 @@ func NewAPIServer(pachClient *client.APIClient, etcdClient *etcd.Client, etcdPre
 		if err != nil {
 			return nil, err
 		}
-		image, err := docker.InspectImage(pipelineInfo.Transform.Image)
+		image, err := docker.InspectImage(ppsutil.PipelineImage(pipelineInfo))
 		if err != nil {
-			return nil, fmt.Errorf("error inspecting image %s: %+v", pipelineInfo.Transform.Image, err)
+			return nil, fmt.Errorf("error inspecting image %s: %+v", ppsutil.PipelineImage(pipelineInfo), err)
 		}
 		if pipelineInfo.Transform.User == "" {
 			pipelineInfo.Transform.User = image.Config.User
@@ -423,6 +470,11 @@ func NewAPIServer(pachClient *client.APIClient, etcdClient *etcd.Client, etcdPre
 			server.gid = &gid32
 		}
 	}
+	if pipelineInfo.Transform.Sandbox != nil && (server.uid == nil || *server.uid == 0) {
+		// Sandboxed user code never runs as root
+		nobody := uint32(nobodyID)
+		server.uid, server.gid = &nobody, &nobody
+	}
 	switch {
 	case pipelineInfo.Service != nil:
 		go server.master("service", server.serviceSpawner)
@@ -517,7 +569,7 @@ func (a *APIServer) downloadData(pachClient *client.APIClient, logger *taggedLog
 			logger.Logf("finished downloading data after %v", time.Since(start))
 		}
 	}(time.Now())
-	dir := filepath.Join(client.PPSInputPrefix, client.PPSScratchSpace, uuid.NewWithoutDashes())
+	dir := filepath.Join(inputPrefix, client.PPSScratchSpace, uuid.NewWithoutDashes())
 	// Create output directory (currently /pfs/out)
 	outPath := filepath.Join(dir, "out")
 	if a.pipelineInfo.Spout != nil {
@@ -561,6 +613,10 @@ func (a *APIServer) downloadData(pachClient *client.APIClient, logger *taggedLog
 			}
 			continue
 		}
+		if input.S3 {
+			// S3 inputs are read through the job's S3 gateway
+			continue
+		}
 		file := input.FileInfo.File
 		root := filepath.Join(dir, input.Name, file.Path)
 		var statsRoot string
@@ -582,26 +638,32 @@ func (a *APIServer) linkData(inputs []*Input, dir string) error {
 	if err != nil {
 		return err
 	}
+	linked := make(map[string]bool)
 	for _, input := range inputs {
+		// The datums of a group input can have several files from one input
+		if linked[input.Name] || input.S3 {
+			continue
+		}
+		linked[input.Name] = true
 		src := filepath.Join(dir, input.Name)
-		dst := filepath.Join(client.PPSInputPrefix, input.Name)
+		dst := filepath.Join(inputPrefix, input.Name)
 		if err := os.Symlink(src, dst); err != nil {
 			return err
 		}
 	}
 
 	if a.pipelineInfo.Spout != nil && a.pipelineInfo.Spout.Marker != "" {
-		err = os.Symlink(filepath.Join(dir, a.pipelineInfo.Spout.Marker), filepath.Join(client.PPSInputPrefix, a.pipelineInfo.Spout.Marker))
+		err = os.Symlink(filepath.Join(dir, a.pipelineInfo.Spout.Marker), filepath.Join(inputPrefix, a.pipelineInfo.Spout.Marker))
 		if err != nil {
 			return err
 		}
 	}
 
-	return os.Symlink(filepath.Join(dir, "out"), filepath.Join(client.PPSInputPrefix, "out"))
+	return os.Symlink(filepath.Join(dir, "out"), filepath.Join(inputPrefix, "out"))
 }
 
 func (a *APIServer) unlinkData(inputs []*Input) error {
-	dirs, err := ioutil.ReadDir(client.PPSInputPrefix)
+	dirs, err := ioutil.ReadDir(inputPrefix)
 	if err != nil {
 		return fmt.Errorf("ioutil.ReadDir: %v", err)
 	}
@@ -609,7 +671,7 @@ func (a *APIServer) unlinkData(inputs []*Input) error {
 		if d.Name() == client.PPSScratchSpace {
 			continue // don't delete scratch space
 		}
-		if err := os.RemoveAll(filepath.Join(client.PPSInputPrefix, d.Name())); err != nil {
+		if err := os.RemoveAll(filepath.Join(inputPrefix, d.Name())); err != nil {
 			return err
 		}
 	}
@@ -675,7 +737,10 @@ func (a *APIServer) runUserCode(ctx context.Context, logger *taggedLogger, envir
 	}
 
 	// Run user code
-	cmd := exec.CommandContext(ctx, a.pipelineInfo.Transform.Cmd[0], a.pipelineInfo.Transform.Cmd[1:]...)
+	cmd, err := a.userCmd(ctx, a.pipelineInfo.Transform.Cmd)
+	if err != nil {
+		return err
+	}
 	if a.pipelineInfo.Transform.Stdin != nil {
 		cmd.Stdin = strings.NewReader(strings.Join(a.pipelineInfo.Transform.Stdin, "\n") + "\n")
 	}
@@ -686,7 +751,7 @@ func (a *APIServer) runUserCode(ctx context.Context, logger *taggedLogger, envir
 		cmd.SysProcAttr = makeCmdCredentials(*a.uid, *a.gid)
 	}
 	cmd.Dir = a.pipelineInfo.Transform.WorkingDir
-	err := cmd.Start()
+	err = cmd.Start()
 	if err != nil {
 		return fmt.Errorf("error cmd.Start: %v", err)
 	}
@@ -714,6 +779,7 @@ func (a *APIServer) runUserCode(ctx context.Context, logger *taggedLogger, envir
 	// broken pipe errors.
 	if err != nil && !strings.Contains(err.Error(), "broken pipe") {
 		// (if err is an acceptable return code, don't return err)
+		exitCode := -1
 		if exiterr, ok := err.(*exec.ExitError); ok {
 			if status, ok := exiterr.Sys().(syscall.WaitStatus); ok {
 				for _, returnCode := range a.pipelineInfo.Transform.AcceptReturnCode {
@@ -721,9 +787,10 @@ func (a *APIServer) runUserCode(ctx context.Context, logger *taggedLogger, envir
 						return nil
 					}
 				}
+				exitCode = status.ExitStatus()
 			}
 		}
-		return fmt.Errorf("error cmd.WaitIO: %v", err)
+		return &userCodeExitError{exitCode: exitCode, err: err}
 	}
 	return nil
 }
@@ -739,7 +806,10 @@ func (a *APIServer) runUserErrorHandlingCode(ctx context.Context, logger *tagged
 		}
 	}(time.Now())
 
-	cmd := exec.CommandContext(ctx, a.pipelineInfo.Transform.ErrCmd[0], a.pipelineInfo.Transform.ErrCmd[1:]...)
+	cmd, err := a.userCmd(ctx, a.pipelineInfo.Transform.ErrCmd)
+	if err != nil {
+		return err
+	}
 	if a.pipelineInfo.Transform.ErrStdin != nil {
 		cmd.Stdin = strings.NewReader(strings.Join(a.pipelineInfo.Transform.ErrStdin, "\n") + "\n")
 	}
@@ -750,7 +820,7 @@ func (a *APIServer) runUserErrorHandlingCode(ctx context.Context, logger *tagged
 		cmd.SysProcAttr = makeCmdCredentials(*a.uid, *a.gid)
 	}
 	cmd.Dir = a.pipelineInfo.Transform.WorkingDir
-	err := cmd.Start()
+	err = cmd.Start()
 	if err != nil {
 		return fmt.Errorf("error cmd.Start: %v", err)
 	}
@@ -818,7 +888,7 @@ func (a *APIServer) reportUploadStats(start time.Time, stats *pps.ProcessStats,
 	}
 }
 
-func (a *APIServer) uploadOutput(pachClient *client.APIClient, dir string, tag string, logger *taggedLogger, inputs []*Input, stats *pps.ProcessStats, statsTree *hashtree.Ordered, datumIdx int64) (retErr error) {
+func (a *APIServer) uploadOutput(pachClient *client.APIClient, dir string, tag string, logger *taggedLogger, inputs []*Input, stats *pps.ProcessStats, statsTree *hashtree.Ordered, datumIdx int64, streamer *outputStreamer) (retErr error) {
 	defer a.reportUploadStats(time.Now(), stats, logger)
 	logger.Logf("starting to upload output")
 	defer func(start time.Time) {
@@ -886,13 +956,13 @@ func (a *APIServer) uploadOutput(pachClient *client.APIClient, dir string, tag s
 			if err != nil {
 				return err
 			}
-			if strings.HasPrefix(realPath, client.PPSInputPrefix) {
+			if strings.HasPrefix(realPath, inputPrefix) {
 				var pathWithInput string
 				var err error
 				if strings.HasPrefix(realPath, dir) {
 					pathWithInput, err = filepath.Rel(dir, realPath)
 				} else {
-					pathWithInput, err = filepath.Rel(client.PPSInputPrefix, realPath)
+					pathWithInput, err = filepath.Rel(inputPrefix, realPath)
 				}
 				if err == nil {
 					// We can only skip the upload if the real path is
@@ -956,6 +1026,15 @@ func (a *APIServer) uploadOutput(pachClient *client.APIClient, dir string, tag s
 				}
 			}
 		}
+		// If the file was uploaded when the user code closed it, and hasn't
+		// changed since, use that upload
+		if streamed := streamer.lookup(info); streamed != nil {
+			tree.PutFile(relPath, streamed.hash, streamed.size, streamed.node)
+			if statsTree != nil {
+				statsTree.PutFile(relPath, streamed.hash, streamed.size, streamed.node)
+			}
+			return nil
+		}
 		// Open local file that is being uploaded
 		f, err := os.Open(filePath)
 		if err != nil {
@@ -1016,22 +1095,14 @@ func (a *APIServer) uploadOutput(pachClient *client.APIClient, dir string, tag s
 	if _, err := putObjsClient.CloseAndRecv(); err != nil && err != io.EOF {
 		return err
 	}
+	stats.UploadBytes += streamer.finish()
 	// Serialize datum hashtree
 	b := &bytes.Buffer{}
 	if err := tree.Serialize(b); err != nil {
 		return err
 	}
 	// Write datum hashtree to object storage
-	w, err := pachClient.PutObjectAsync([]*pfs.Tag{client.NewTag(tag)})
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err := w.Close(); err != nil && retErr != nil {
-			retErr = err
-		}
-	}()
-	if _, err := w.Write(b.Bytes()); err != nil {
+	if err := a.datumTrees.put(pachClient, tag, b.Bytes()); err != nil {
 		return err
 	}
 	// Cache datum hashtree locally
@@ -1041,6 +1112,18 @@ func (a *APIServer) uploadOutput(pachClient *client.APIClient, dir string, tag s
 // HashDatum computes and returns the hash of datum + pipeline, with a
 // pipeline-specific prefix.
 func HashDatum(pipelineName string, pipelineSalt string, data []*Input) string {
+	return hashDatum(pipelineName, pipelineSalt, "", data)
+}
+
+// HashCachedDatum is like HashDatum, but for pipelines with a datum cache
+// (see pps.PipelineInfo.DatumCache), whose datums are only skipped by jobs of
+// the same version of the pipeline. The prefix is the same as HashDatum's, so
+// the datums' trees are kept by garbage collection.
+func HashCachedDatum(pipelineName string, pipelineSalt string, pipelineVersion uint64, data []*Input) string {
+	return hashDatum(pipelineName, pipelineSalt, fmt.Sprint(pipelineVersion), data)
+}
+
+func hashDatum(pipelineName string, pipelineSalt string, pipelineVersion string, data []*Input) string {
 	hash := sha256.New()
 	for _, datum := range data {
 		hash.Write([]byte(datum.Name))
@@ -1050,10 +1133,19 @@ func HashDatum(pipelineName string, pipelineSalt string, data []*Input) string {
 
 	hash.Write([]byte(pipelineName))
 	hash.Write([]byte(pipelineSalt))
+	hash.Write([]byte(pipelineVersion))
 
 	return client.DatumTagPrefix(pipelineSalt) + hex.EncodeToString(hash.Sum(nil))
 }
 
+// datumTag returns the tag of 'data' in this pipeline's jobs
+func (a *APIServer) datumTag(data []*Input) string {
+	if a.pipelineInfo.DatumCache {
+		return HashCachedDatum(a.pipelineInfo.Pipeline.Name, a.pipelineInfo.Salt, a.pipelineInfo.Version, data)
+	}
+	return HashDatum(a.pipelineInfo.Pipeline.Name, a.pipelineInfo.Salt, data)
+}
+
 // HashDatum15 computes and returns the hash of datum + pipeline for version <= 1.5.0, with a
 // pipeline-specific prefix.
 func HashDatum15(pipelineInfo *pps.PipelineInfo, data []*Input) (string, error) {
@@ -1100,6 +1192,7 @@ func (a *APIServer) Status(ctx context.Context, _ *types.Empty) (*pps.WorkerStat
 		Data:      a.datum(),
 		QueueSize: atomic.LoadInt64(&a.queueSize),
 	}
+	result.Credits = a.availableCredits()
 	return result, nil
 }
 
@@ -1142,14 +1235,43 @@ func (a *APIServer) datum() []*pps.InputFile {
 	return result
 }
 
-func (a *APIServer) userCodeEnv(jobID string, outputCommitID string, data []*Input) []string {
+func (a *APIServer) userCodeEnv(jobID string, outputCommitID string, data []*Input, inputMetadata []*pps.CommitMetadata) []string {
 	result := os.Environ()
 	for _, input := range data {
-		result = append(result, fmt.Sprintf("%s=%s", input.Name, filepath.Join(client.PPSInputPrefix, input.Name, input.FileInfo.File.Path)))
+		result = append(result, fmt.Sprintf("%s=%s", input.Name, filepath.Join(inputPrefix, input.Name, input.FileInfo.File.Path)))
 		result = append(result, fmt.Sprintf("%s_COMMIT=%s", input.Name, input.FileInfo.File.Commit.ID))
+		// The metadata of the input's commit, as a JSON object
+		for _, commitMetadata := range inputMetadata {
+			if commitMetadata.Commit.Repo.Name == input.FileInfo.File.Commit.Repo.Name &&
+				commitMetadata.Commit.ID == input.FileInfo.File.Commit.ID {
+				if metadata, err := json.Marshal(commitMetadata.Metadata); err == nil {
+					result = append(result, fmt.Sprintf("%s_METADATA=%s", input.Name, metadata))
+				}
+				break
+			}
+		}
+	}
+	// Pick up the current values of rotated secrets and changed config maps
+	if a.pipelineInfo.Transform != nil {
+		result = append(result, secretEnv(client.PPSSecretsMountPath, a.pipelineInfo.Transform.Secrets)...)
+		result = append(result, configMapEnv(client.PPSConfigMapsMountPath, a.pipelineInfo.Transform.ConfigMaps)...)
 	}
 	result = append(result, fmt.Sprintf("%s=%s", client.JobIDEnv, jobID))
 	result = append(result, fmt.Sprintf("%s=%s", client.OutputCommitIDEnv, outputCommitID))
+	result = append(result, fmt.Sprintf("%s=%s", client.DatumFailureFileEnv, datumFailureFile))
+	if usesS3(a.pipelineInfo) {
+		result = append(result, s3Env()...)
+	}
+	result = append(result, cloudCredentialsEnv(a.pipelineInfo, jobID)...)
+	if a.pipelineInfo.EgressProxy != nil {
+		// Send the user code's requests through the egress proxy, except for
+		// requests to pachd (e.g. its artifact cache)
+		var noProxy []string
+		if pachdHost := os.Getenv("PACHD_SERVICE_HOST"); pachdHost != "" {
+			noProxy = append(noProxy, pachdHost)
+		}
+		result = append(result, egressproxy.UserEnv(noProxy...)...)
+	}
 	return result
 }
 
@@ -1159,7 +1281,13 @@ type processResult struct {
 	datumsSkipped   int64
 	datumsRecovered int64
 	datumsFailed    int64
+	datumsTimedOut  int64
+	// recoveredDatums are the datums that weren't processed (because they
+	// were recovered or timed out), so later jobs don't skip them
 	recoveredDatums *pfs.Object
+	// datumDurations, if set, is how long each processed datum took (see
+	// DatumDurations)
+	datumDurations *pfs.Object
 }
 
 type processFunc func(low, high int64) (*processResult, error)
@@ -1176,7 +1304,13 @@ func (a *APIServer) acquireDatums(ctx context.Context, jobID string, plan *Plan,
 		complete = true
 		// Attempt to claim a chunk
 		low, high := int64(0), int64(0)
-		for _, high = range plan.Chunks {
+		for i := range plan.Chunks {
+			high = plan.Chunks[i]
+			if chunkProfile(plan, i) != a.datumProfile {
+				// Another pool of workers processes this chunk
+				low = high
+				continue
+			}
 			var chunkState ChunkState
 			if err := chunks.Claim(ctx, fmt.Sprint(high), &chunkState, func(ctx context.Context) error {
 				return a.processChunk(ctx, jobID, low, high, process)
@@ -1204,10 +1338,20 @@ func (a *APIServer) acquireDatums(ctx context.Context, jobID string, plan *Plan,
 }
 
 func (a *APIServer) processChunk(ctx context.Context, jobID string, low, high int64, process processFunc) error {
+	started, err := types.TimestampProto(time.Now())
+	if err != nil {
+		return err
+	}
 	processResult, err := process(low, high)
 	if err != nil {
 		return err
 	}
+	// The chunk's start and finish times measure how evenly the job's datums
+	// were spread across its workers (see datumBalance)
+	finished, err := types.TimestampProto(time.Now())
+	if err != nil {
+		return err
+	}
 	if _, err := col.NewSTM(ctx, a.etcdClient, func(stm col.STM) error {
 		jobs := a.jobs.ReadWrite(stm)
 		jobPtr := &pps.EtcdJobInfo{}
@@ -1215,7 +1359,7 @@ func (a *APIServer) processChunk(ctx context.Context, jobID string, low, high in
 			jobPtr.DataProcessed += processResult.datumsProcessed
 			jobPtr.DataSkipped += processResult.datumsSkipped
 			jobPtr.DataRecovered += processResult.datumsRecovered
-			jobPtr.DataFailed += processResult.datumsFailed
+			jobPtr.DataFailed += processResult.datumsFailed + processResult.datumsTimedOut
 			return nil
 		}); err != nil {
 			return err
@@ -1223,15 +1367,20 @@ func (a *APIServer) processChunk(ctx context.Context, jobID string, low, high in
 		chunks := a.chunks(jobID).ReadWrite(stm)
 		if processResult.failedDatumID != "" {
 			return chunks.Put(fmt.Sprint(high), &ChunkState{
-				State:   State_FAILED,
-				DatumID: processResult.failedDatumID,
-				Address: os.Getenv(client.PPSWorkerIPEnv),
+				State:    State_FAILED,
+				DatumID:  processResult.failedDatumID,
+				Address:  os.Getenv(client.PPSWorkerIPEnv),
+				Started:  started,
+				Finished: finished,
 			})
 		}
 		return chunks.Put(fmt.Sprint(high), &ChunkState{
 			State:           State_COMPLETE,
 			Address:         os.Getenv(client.PPSWorkerIPEnv),
 			RecoveredDatums: processResult.recoveredDatums,
+			Started:         started,
+			Finished:        finished,
+			DatumDurations:  processResult.datumDurations,
 		})
 	}); err != nil {
 		return err
@@ -1285,7 +1434,7 @@ func (a *APIServer) mergeDatums(jobCtx context.Context, pachClient *client.APICl
 								logger.Logf("error downloading chunk %v from worker at %v (%v), falling back on object storage", high, chunkState.Address, err)
 								tags := a.computeTags(df, low, high, skip, useParentHashTree)
 								// Download datum hashtrees from object storage if we run into an error getting them from the worker
-								if err := a.getChunkFromObjectStorage(ctx, pachClient, objClient, tags, high, failed); err != nil {
+								if err := a.getChunkFromObjectStorage(ctx, pachClient, tags, high, failed); err != nil {
 									return err
 								}
 							}
@@ -1428,7 +1577,7 @@ func (a *APIServer) computeTags(df DatumIterator, low, high int64, skip map[stri
 	var tags []*pfs.Tag
 	for i := low; i < high; i++ {
 		files := df.DatumN(int(i))
-		datumHash := HashDatum(a.pipelineInfo.Pipeline.Name, a.pipelineInfo.Salt, files)
+		datumHash := a.datumTag(files)
 		// Skip datum if it is in the parent hashtree and the parent hashtree is being used in the merge
 		if skip[datumHash] && useParentHashTree {
 			continue
@@ -1438,10 +1587,10 @@ func (a *APIServer) computeTags(df DatumIterator, low, high int64, skip map[stri
 	return tags
 }
 
-func (a *APIServer) getChunkFromObjectStorage(ctx context.Context, pachClient *client.APIClient, objClient obj.Client, tags []*pfs.Tag, id int64, failed bool) error {
+func (a *APIServer) getChunkFromObjectStorage(ctx context.Context, pachClient *client.APIClient, tags []*pfs.Tag, id int64, failed bool) error {
 	// Download, merge, and cache datum hashtrees for a chunk if it succeeded
 	if !failed {
-		ts, err := a.getHashtrees(ctx, pachClient, objClient, tags, hashtree.NewFilter(a.numShards, a.shard))
+		ts, err := a.getHashtrees(ctx, pachClient, tags, hashtree.NewFilter(a.numShards, a.shard))
 		if err != nil {
 			return err
 		}
@@ -1459,7 +1608,7 @@ func (a *APIServer) getChunkFromObjectStorage(ctx context.Context, pachClient *c
 		for _, tag := range tags {
 			statsTags = append(statsTags, client.NewTag(tag.Name+statsTagSuffix))
 		}
-		ts, err := a.getHashtrees(ctx, pachClient, objClient, statsTags, hashtree.NewFilter(a.numShards, a.shard))
+		ts, err := a.getHashtrees(ctx, pachClient, statsTags, hashtree.NewFilter(a.numShards, a.shard))
 		if err != nil {
 			return err
 		}
@@ -1523,13 +1672,22 @@ func (a *APIServer) getParentCommitInfo(ctx context.Context, pachClient *client.
 	if err != nil {
 		return nil, err
 	}
+	// The concurrent jobs of pipelines with max_concurrent_jobs don't wait for
+	// the jobs of earlier commits, and use the nearest ancestor that's
+	// finished (unfinished commits have no Trees)
+	blockState := pfs.CommitState_FINISHED
+	if a.pipelineInfo.MaxConcurrentJobs > 1 {
+		blockState = pfs.CommitState_STARTED
+	}
 	for commitInfo.ParentCommit != nil {
-		a.getWorkerLogger().Logf("blocking on parent commit %q before writing to output commit %q",
-			commitInfo.ParentCommit.ID, outputCommitID)
+		if blockState == pfs.CommitState_FINISHED {
+			a.getWorkerLogger().Logf("blocking on parent commit %q before writing to output commit %q",
+				commitInfo.ParentCommit.ID, outputCommitID)
+		}
 		parentCommitInfo, err := pachClient.PfsAPIClient.InspectCommit(ctx,
 			&pfs.InspectCommitRequest{
 				Commit:     commitInfo.ParentCommit,
-				BlockState: pfs.CommitState_FINISHED,
+				BlockState: blockState,
 			})
 		if err != nil {
 			return nil, err
@@ -1542,7 +1700,7 @@ func (a *APIServer) getParentCommitInfo(ctx context.Context, pachClient *client.
 	return nil, nil
 }
 
-func (a *APIServer) getHashtrees(ctx context.Context, pachClient *client.APIClient, objClient obj.Client, tags []*pfs.Tag, filter hashtree.Filter) ([]*hashtree.Reader, error) {
+func (a *APIServer) getHashtrees(ctx context.Context, pachClient *client.APIClient, tags []*pfs.Tag, filter hashtree.Filter) ([]*hashtree.Reader, error) {
 	limiter := limit.New(hashtree.DefaultMergeConcurrency)
 	var eg errgroup.Group
 	var mu sync.Mutex
@@ -1552,26 +1710,8 @@ func (a *APIServer) getHashtrees(ctx context.Context, pachClient *client.APIClie
 		limiter.Acquire()
 		eg.Go(func() (retErr error) {
 			defer limiter.Release()
-			// Get datum hashtree info
-			info, err := pachClient.InspectTag(ctx, tag)
-			if err != nil {
-				return err
-			}
-			path, err := obj.BlockPathFromEnv(info.BlockRef.Block)
-			if err != nil {
-				return err
-			}
 			// Read the full datum hashtree in memory
-			objR, err := objClient.Reader(ctx, path, 0, 0)
-			if err != nil {
-				return err
-			}
-			defer func() {
-				if err := objR.Close(); err != nil && retErr == nil {
-					retErr = err
-				}
-			}()
-			fullTree, err := ioutil.ReadAll(objR)
+			fullTree, err := a.datumTrees.get(pachClient.WithCtx(ctx), tag.Name)
 			if err != nil {
 				return err
 			}
@@ -1743,6 +1883,8 @@ func (a *APIServer) worker() {
 
 	// claim a shard if one is available or becomes available
 	go a.claimShard(a.pachClient.Ctx())
+	// tell the master how much datum data this worker has room for
+	go a.advertiseCredits(a.pachClient.Ctx())
 
 	// Process incoming jobs
 	backoff.RetryNotify(func() (retErr error) {
@@ -1782,6 +1924,10 @@ func (a *APIServer) worker() {
 				logger.Logf("skipping job %v as it is already in state %v", jobID, jobPtr.State)
 				continue
 			}
+			if jobPtr.ReplayOf.GetID() != a.replayOf {
+				// Replays (see pps.ReplayJob) are processed by their own workers
+				continue
+			}
 
 			// create new ctx for this job, and don't use retryCtx as the
 			// parent. Just because another job's etcd write failed doesn't
@@ -1814,16 +1960,44 @@ func (a *APIServer) worker() {
 						"version (%d), this should automatically resolve when the worker "+
 						"is updated", jobID, jobInfo.PipelineVersion, a.pipelineInfo.Version)
 				}
+				// The jobs of pipelines with max_concurrent_jobs are each
+				// processed by one of the pipeline's worker pools
+				if a.workerPool != 0 {
+					pool, err := a.waitForWorkerPool(jobCtx, jobID)
+					if err != nil {
+						if jobCtx.Err() == context.Canceled {
+							return nil // the job finished or was deleted
+						}
+						return err
+					}
+					if pool == 0 {
+						logger.Logf("skipping job %v as it finished before it was assigned a worker pool", jobID)
+						return nil
+					}
+					if pool != a.workerPool {
+						logger.Logf("skipping job %v as it's processed by worker pool %d", jobID, pool)
+						return nil
+					}
+				}
+				stopS3Gateway, err := a.serveJobS3Gateway(pachClient, jobInfo, logger)
+				if err != nil {
+					return err
+				}
+				defer stopS3Gateway()
 
 				// Read the chunks laid out by the master and create the datum factory
 				plan := &Plan{}
 				if err := a.plans.ReadOnly(jobCtx).GetBlock(jobInfo.Job.ID, plan); err != nil {
 					return fmt.Errorf("error reading job chunks: %v", err)
 				}
+				if err := loadPlan(pachClient, plan); err != nil {
+					return err
+				}
+				plan = claimPlan(plan)
 				var df DatumIterator
 				if err := logger.LogStep("creating datum iterator", func() error {
 					var err error
-					df, err = NewDatumIterator(pachClient, jobInfo.Input)
+					df, err = NewOrderedDatumIterator(pachClient, jobInfo.Input, jobInfo.DatumOrder, jobInfo.OutputCommit)
 					return err
 				}); err != nil {
 					return err
@@ -1837,7 +2011,9 @@ func (a *APIServer) worker() {
 					if err != nil {
 						return err
 					}
-					if parentCommitInfo != nil {
+					// The output of s3_out pipelines isn't in hashtrees, so
+					// their datums can't be skipped
+					if parentCommitInfo != nil && !a.pipelineInfo.S3Out {
 						var err error
 						skip, err = a.getDatumMap(jobCtx, pachClient, parentCommitInfo.Datums)
 						if err != nil {
@@ -1846,12 +2022,15 @@ func (a *APIServer) worker() {
 						var count int
 						for i := 0; i < df.Len(); i++ {
 							files := df.DatumN(i)
-							datumHash := HashDatum(a.pipelineInfo.Pipeline.Name, a.pipelineInfo.Salt, files)
+							datumHash := a.datumTag(files)
 							if skip[datumHash] {
 								count++
 							}
 						}
-						if len(skip) == count {
+						// Concurrent jobs merge every datum's own hashtree, as
+						// their parent may be finished by the time they merge,
+						// and so differ from the commit 'skip' came from
+						if len(skip) == count && a.pipelineInfo.MaxConcurrentJobs <= 1 {
 							useParentHashTree = true
 						}
 					}
@@ -1955,6 +2134,10 @@ func (a *APIServer) processDatums(pachClient *client.APIClient, logger *taggedLo
 		}
 	}()
 	ctx := pachClient.Ctx()
+	deadline, err := jobDeadline(jobInfo)
+	if err != nil {
+		return nil, err
+	}
 	objClient, err := obj.NewClientFromSecret(a.hashtreeStorage)
 	if err != nil {
 		return nil, err
@@ -1963,25 +2146,42 @@ func (a *APIServer) processDatums(pachClient *client.APIClient, logger *taggedLo
 	var statsMu sync.Mutex
 	result = &processResult{}
 	var eg errgroup.Group
-	limiter := limit.New(int(a.pipelineInfo.MaxQueueSize))
+	// Datums are queued as long as this worker has room for their inputs, and
+	// the queue is no longer than max_queue_size
+	credits := newCreditPool(func() int64 { return headroom(inputPrefix) }, a.pipelineInfo.MaxQueueSize)
+	func() {
+		a.statusMu.Lock()
+		defer a.statusMu.Unlock()
+		a.credits = credits
+	}()
+	defer func() {
+		a.statusMu.Lock()
+		defer a.statusMu.Unlock()
+		a.credits = nil
+	}()
 	var recoveredDatums []string
 	var recoverMu sync.Mutex
+	var durations *DatumDurations
+	if ordersByDuration(jobInfo.DatumOrder) {
+		durations = &DatumDurations{Seconds: make(map[string]float64)}
+	}
 	for i := low; i < high; i++ {
 		datumIdx := i
 
-		limiter.Acquire()
+		data := df.DatumN(int(datumIdx))
+		datumCredits := datumCredits(data)
+		credits.acquire(datumCredits)
 		atomic.AddInt64(&a.queueSize, 1)
 		eg.Go(func() (retErr error) {
-			defer limiter.Release()
+			defer credits.release(datumCredits)
 			defer atomic.AddInt64(&a.queueSize, -1)
 
-			data := df.DatumN(int(datumIdx))
 			logger, err := a.getTaggedLogger(pachClient, jobInfo.Job.ID, data, a.pipelineInfo.EnableStats)
 			if err != nil {
 				return err
 			}
 			// Hash inputs
-			tag := HashDatum(a.pipelineInfo.Pipeline.Name, a.pipelineInfo.Salt, data)
+			tag := a.datumTag(data)
 			if skip[tag] {
 				if !useParentHashTree {
 					if err := a.cacheHashtree(pachClient, tag, datumIdx); err != nil {
@@ -1992,7 +2192,7 @@ func (a *APIServer) processDatums(pachClient *client.APIClient, logger *taggedLo
 				logger.Logf("skipping datum")
 				return nil
 			}
-			if _, err := pachClient.InspectTag(ctx, client.NewTag(tag)); err == nil {
+			if a.datumTrees.exists(pachClient, tag) {
 				if err := a.cacheHashtree(pachClient, tag, datumIdx); err != nil {
 					return err
 				}
@@ -2031,13 +2231,22 @@ func (a *APIServer) processDatums(pachClient *client.APIClient, logger *taggedLo
 				}()
 			}
 
-			env := a.userCodeEnv(jobInfo.Job.ID, jobInfo.OutputCommit.ID, data)
+			env := a.userCodeEnv(jobInfo.Job.ID, jobInfo.OutputCommit.ID, data, jobInfo.InputMetadata)
+			retryBackoff, err := datumBackoff(jobInfo.DatumRetry)
+			if err != nil {
+				return err
+			}
 			var dir string
 			var failures int64
 			if err := backoff.RetryNotify(func() error {
 				if isDone(ctx) {
 					return ctx.Err() // timeout or cancelled job--don't run datum
 				}
+				if !deadline.IsZero() && time.Now().After(deadline) {
+					return errDatumTimedOut // the job timed out--skip the datum
+				}
+				subStats.Tries++
+				subStats.FailureClass = pps.FailureClass_FAILURE_UNCLASSIFIED
 				// Download input data
 				puller := filesync.NewPuller()
 				// TODO parent tag shouldn't be nil
@@ -2075,7 +2284,7 @@ func (a *APIServer) processDatums(pachClient *client.APIClient, logger *taggedLo
 					a.cancel = cancel
 					a.stats = stats
 				}()
-				if err := os.MkdirAll(client.PPSInputPrefix, 0777); err != nil {
+				if err := os.MkdirAll(inputPrefix, 0777); err != nil {
 					return err
 				}
 				if err := a.linkData(data, dir); err != nil {
@@ -2089,15 +2298,44 @@ func (a *APIServer) processDatums(pachClient *client.APIClient, logger *taggedLo
 				// If the pipeline spec set a custom user to execute the
 				// process, make sure `/pfs` and its content are owned by it
 				if a.uid != nil && a.gid != nil {
-					filepath.Walk("/pfs", func(name string, info os.FileInfo, err error) error {
+					filepath.Walk(inputPrefix, func(name string, info os.FileInfo, err error) error {
 						if err == nil {
 							err = os.Chown(name, int(*a.uid), int(*a.gid))
 						}
 						return err
 					})
 				}
-				if err := a.runUserCode(ctx, logger, env, subStats, jobInfo.DatumTimeout); err != nil {
-					if a.pipelineInfo.Transform.ErrCmd != nil && failures == jobInfo.DatumTries-1 {
+				// Upload output files as they're closed, if the pipeline streams
+				// its output
+				var streamer *outputStreamer
+				if a.pipelineInfo.StreamOutput {
+					streamer, err = startOutputStreamer(pachClient, filepath.Join(dir, "out"), logger)
+					if err != nil {
+						return fmt.Errorf("error startOutputStreamer: %v", err)
+					}
+					defer streamer.finish()
+				}
+				userCtx := ctx
+				if !deadline.IsZero() {
+					var cancelUserCode context.CancelFunc
+					userCtx, cancelUserCode = context.WithDeadline(ctx, deadline)
+					defer cancelUserCode()
+				}
+				// Clear the class of the previous try's failure, if any
+				if err := os.Remove(datumFailureFile); err != nil && !os.IsNotExist(err) {
+					return err
+				}
+				if err := a.runUserCode(userCtx, logger, env, subStats, jobInfo.DatumTimeout); err != nil {
+					if err == context.DeadlineExceeded && skipsTimedOutDatums(jobInfo) {
+						return errDatumTimedOut
+					}
+					exitCode := -1
+					if exitErr, ok := err.(*userCodeExitError); ok {
+						exitCode = exitErr.exitCode
+					}
+					subStats.FailureClass = classifyFailure(jobInfo.DatumRetry, exitCode, readDatumFailureFile())
+					permanent := subStats.FailureClass == pps.FailureClass_FAILURE_PERMANENT
+					if a.pipelineInfo.Transform.ErrCmd != nil && (failures == jobInfo.DatumTries-1 || permanent) {
 						if err = a.runUserErrorHandlingCode(ctx, logger, env, subStats, jobInfo.DatumTimeout); err != nil {
 							return fmt.Errorf("error runUserErrorHandlingCode: %v", err)
 						}
@@ -2105,6 +2343,20 @@ func (a *APIServer) processDatums(pachClient *client.APIClient, logger *taggedLo
 					}
 					return fmt.Errorf("error runUserCode: %v", err)
 				}
+				if checksDeterminism(a.pipelineInfo.DeterminismCheck, tag) {
+					deterministic, err := a.checkDeterminism(userCtx, logger, env, dir, subStats, jobInfo.DatumTimeout)
+					if err != nil {
+						if err == context.DeadlineExceeded && skipsTimedOutDatums(jobInfo) {
+							return errDatumTimedOut
+						}
+						return fmt.Errorf("error checkDeterminism: %v", err)
+					}
+					subStats.DatumsChecked = 1
+					subStats.DatumsNondeterministic = 0
+					if !deterministic {
+						subStats.DatumsNondeterministic = 1
+					}
+				}
 				// CleanUp is idempotent so we can call it however many times we want.
 				// The reason we are calling it here is that the puller could've
 				// encountered an error as it was lazily loading files, in which case
@@ -2118,13 +2370,18 @@ func (a *APIServer) processDatums(pachClient *client.APIClient, logger *taggedLo
 				}
 				atomic.AddUint64(&subStats.DownloadBytes, uint64(downSize))
 				a.reportDownloadSizeStats(float64(downSize), logger)
-				return a.uploadOutput(pachClient, dir, tag, logger, data, subStats, outputTree, datumIdx)
-			}, &backoff.ZeroBackOff{}, func(err error, d time.Duration) error {
+				// Stop streaming before the remaining output is uploaded
+				streamer.finish()
+				return a.uploadOutput(pachClient, dir, tag, logger, data, subStats, outputTree, datumIdx, streamer)
+			}, backoff.WithContext(retryBackoff, ctx), func(err error, d time.Duration) error {
 				if isDone(ctx) {
 					return ctx.Err() // timeout or cancelled job, err out and don't retry
 				}
 				failures++
-				if failures >= jobInfo.DatumTries {
+				// Datums that time out aren't retried if the job skips them, and
+				// datums that fail permanently aren't retried at all
+				if failures >= jobInfo.DatumTries || err == errDatumTimedOut ||
+					subStats.FailureClass == pps.FailureClass_FAILURE_PERMANENT {
 					logger.Logf("failed to process datum with error: %+v", err)
 					if statsTree != nil {
 						object, size, err := pachClient.PutObject(strings.NewReader(err.Error()))
@@ -2153,6 +2410,15 @@ func (a *APIServer) processDatums(pachClient *client.APIClient, logger *taggedLo
 				recoveredDatums = append(recoveredDatums, a.DatumID(data))
 				atomic.AddInt64(&result.datumsRecovered, 1)
 				return nil
+			} else if err == errDatumTimedOut {
+				// like recovered datums, datums that time out have no output,
+				// and aren't skipped by the next job
+				recoverMu.Lock()
+				defer recoverMu.Unlock()
+				recoveredDatums = append(recoveredDatums, a.DatumID(data))
+				atomic.AddInt64(&result.datumsTimedOut, 1)
+				logger.Logf("datum timed out, leaving it out of the job's output")
+				return nil
 			} else if err != nil {
 				result.failedDatumID = a.DatumID(data)
 				atomic.AddInt64(&result.datumsFailed, 1)
@@ -2163,6 +2429,11 @@ func (a *APIServer) processDatums(pachClient *client.APIClient, logger *taggedLo
 			if err := mergeStats(stats, subStats); err != nil {
 				logger.Logf("failed to merge Stats: %v", err)
 			}
+			if durations != nil {
+				if processTime, err := types.DurationFromProto(subStats.ProcessTime); err == nil {
+					durations.Seconds[datumKey(data)] = processTime.Seconds()
+				}
+			}
 			return nil
 		})
 	}
@@ -2186,6 +2457,17 @@ func (a *APIServer) processDatums(pachClient *client.APIClient, logger *taggedLo
 
 		result.recoveredDatums = recoveredDatumsObj
 	}
+	if durations != nil && len(durations.Seconds) > 0 {
+		data, err := durations.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		durationsObj, _, err := pachClient.PutObject(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		result.datumDurations = durationsObj
+	}
 
 	if _, err := col.NewSTM(ctx, a.etcdClient, func(stm col.STM) error {
 		jobs := a.jobs.ReadWrite(stm)
@@ -2204,7 +2486,7 @@ func (a *APIServer) processDatums(pachClient *client.APIClient, logger *taggedLo
 	}); err != nil {
 		return nil, err
 	}
-	result.datumsProcessed = high - low - result.datumsSkipped - result.datumsFailed - result.datumsRecovered
+	result.datumsProcessed = high - low - result.datumsSkipped - result.datumsFailed - result.datumsRecovered - result.datumsTimedOut
 	// Merge datum hashtrees into a chunk hashtree, then cache it.
 	if err := a.mergeChunk(logger, high, result); err != nil {
 		return nil, err
@@ -2213,22 +2495,22 @@ func (a *APIServer) processDatums(pachClient *client.APIClient, logger *taggedLo
 }
 
 func (a *APIServer) cacheHashtree(pachClient *client.APIClient, tag string, datumIdx int64) (retErr error) {
-	buf := &bytes.Buffer{}
-	if err := pachClient.GetTag(tag, buf); err != nil {
+	tree, err := a.datumTrees.get(pachClient, tag)
+	if err != nil {
 		return err
 	}
-	if err := a.datumCache.Put(datumIdx, buf); err != nil {
+	if err := a.datumCache.Put(datumIdx, bytes.NewReader(tree)); err != nil {
 		return err
 	}
 	if a.pipelineInfo.EnableStats {
-		buf.Reset()
-		if err := pachClient.GetTag(tag+statsTagSuffix, buf); err != nil {
+		statsTree, err := a.datumTrees.get(pachClient, tag+statsTagSuffix)
+		if err != nil {
 			// We are okay with not finding the stats hashtree.
 			// This allows users to enable stats on a pipeline
 			// with pre-existing jobs.
 			return nil
 		}
-		return a.datumStatsCache.Put(datumIdx, buf)
+		return a.datumStatsCache.Put(datumIdx, bytes.NewReader(statsTree))
 	}
 	return nil
 }
@@ -2288,16 +2570,7 @@ func (a *APIServer) writeStats(pachClient *client.APIClient, objClient obj.Clien
 		return err
 	}
 	// Write datum stats hashtree to object storage
-	objW, err := pachClient.PutObjectAsync([]*pfs.Tag{client.NewTag(tag + statsTagSuffix)})
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err := objW.Close(); err != nil && retErr == nil {
-			retErr = err
-		}
-	}()
-	if _, err := objW.Write(buf.Bytes()); err != nil {
+	if err := a.datumTrees.put(pachClient, tag+statsTagSuffix, buf.Bytes()); err != nil {
 		return err
 	}
 	// Cache datum stats hashtree locally
@@ -2318,6 +2591,8 @@ func mergeStats(x, y *pps.ProcessStats) error {
 	}
 	x.DownloadBytes += y.DownloadBytes
 	x.UploadBytes += y.UploadBytes
+	x.DatumsChecked += y.DatumsChecked
+	x.DatumsNondeterministic += y.DatumsNondeterministic
 	return nil
 }
 
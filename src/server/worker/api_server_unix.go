@@ -4,8 +4,13 @@ package worker
 
 import (
 	"syscall"
+
+	"github.com/pachyderm/pachyderm/src/client"
 )
 
+// inputPrefix is the directory that datums are downloaded to
+const inputPrefix = client.PPSInputPrefix
+
 // Mkfifo does not exist on Windows, so this is left unimplemented there, except for tests
 func createSpoutFifo(path string) error {
 	return syscall.Mkfifo(path, 0666)
@@ -19,3 +24,12 @@ func makeCmdCredentials(uid uint32, gid uint32) *syscall.SysProcAttr {
 		},
 	}
 }
+
+// diskHeadroom returns the free space on the filesystem containing 'dir'
+func diskHeadroom(dir string) (int64, bool) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, false
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), true
+}
@@ -5,9 +5,15 @@ package worker
 import (
 	"fmt"
 	"syscall"
+
+	"github.com/pachyderm/pachyderm/src/client"
 )
 
-// Note that these functions are stubs for windows and they are not meant to be used outside of tests
+// inputPrefix is the directory that datums are downloaded to
+const inputPrefix = client.PPSWindowsInputPrefix
+
+// Note that these functions are stubs for windows, so spouts and custom users
+// are not supported by pipelines that run on Windows nodes
 
 func createSpoutFifo(path string) error {
 	return fmt.Errorf("unimplemented on windows")
@@ -16,3 +22,7 @@ func createSpoutFifo(path string) error {
 func makeCmdCredentials(uid uint32, gid uint32) *syscall.SysProcAttr {
 	return nil
 }
+
+func diskHeadroom(dir string) (int64, bool) {
+	return 0, false
+}
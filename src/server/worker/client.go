@@ -11,6 +11,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/debug"
 	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/mtls"
 
 	etcd "github.com/coreos/etcd/clientv3"
 	"google.golang.org/grpc"
@@ -78,10 +79,14 @@ func Conns(ctx context.Context, pipelineRcName string, etcdClient *etcd.Client,
 	if err != nil {
 		return nil, err
 	}
+	internalTLS, err := mtls.Internal()
+	if err != nil {
+		return nil, err
+	}
 	var result []*grpc.ClientConn
 	for _, kv := range resp.Kvs {
 		conn, err := grpc.Dial(fmt.Sprintf("%s:%d", path.Base(string(kv.Key)), workerGrpcPort),
-			append(client.DefaultDialOptions(), grpc.WithInsecure())...)
+			append(client.DefaultDialOptions(), internalTLS.DialOption())...)
 		if err != nil {
 			return nil, err
 		}
@@ -125,8 +130,12 @@ func NewClient(address string) (Client, error) {
 	if err != nil {
 		return Client{}, err
 	}
+	internalTLS, err := mtls.Internal()
+	if err != nil {
+		return Client{}, err
+	}
 	conn, err := grpc.Dial(fmt.Sprintf("%s:%d", address, port),
-		append(client.DefaultDialOptions(), grpc.WithInsecure())...)
+		append(client.DefaultDialOptions(), internalTLS.DialOption())...)
 	if err != nil {
 		return Client{}, err
 	}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// maxRoleSessionName is the longest session name that STS accepts
+const maxRoleSessionName = 64
+
+// cloudCredentialsEnv returns the env vars (as NAME=value) that let the user
+// code of the job 'jobID' get the AWS credentials of 'pipelineInfo', if it
+// has any (GCP credentials come from the GKE metadata server, and need no env
+// vars). AWS SDKs exchange the token in client.PPSCloudTokenMountPath for
+// temporary credentials with STS, and refresh them before they expire. The
+// env vars are only set for user code, so that the worker itself keeps
+// using pachd's credentials for object storage.
+func cloudCredentialsEnv(pipelineInfo *pps.PipelineInfo, jobID string) []string {
+	aws := pipelineInfo.CloudCredentials.GetAWS()
+	if aws == nil {
+		return nil
+	}
+	result := []string{
+		fmt.Sprintf("AWS_ROLE_ARN=%s", aws.RoleARN),
+		fmt.Sprintf("AWS_WEB_IDENTITY_TOKEN_FILE=%s", filepath.Join(client.PPSCloudTokenMountPath, client.PPSAWSTokenFile)),
+		fmt.Sprintf("AWS_ROLE_SESSION_NAME=%s", roleSessionName(pipelineInfo.Pipeline.Name, jobID)),
+	}
+	if aws.Region != "" {
+		result = append(result,
+			fmt.Sprintf("AWS_REGION=%s", aws.Region),
+			fmt.Sprintf("AWS_DEFAULT_REGION=%s", aws.Region))
+	}
+	return result
+}
+
+// roleSessionName returns the STS session name of the AWS credentials of the
+// job 'jobID', <pipeline>-<job>, so that each job's requests can be told
+// apart in CloudTrail. Long pipeline names are truncated, so that the job ID
+// is always included. If 'jobID' is empty (for services and spouts), it's
+// just the pipeline's name.
+func roleSessionName(pipelineName string, jobID string) string {
+	if jobID == "" {
+		if len(pipelineName) > maxRoleSessionName {
+			pipelineName = pipelineName[:maxRoleSessionName]
+		}
+		return pipelineName
+	}
+	if max := maxRoleSessionName - len(jobID) - 1; len(pipelineName) > max {
+		pipelineName = pipelineName[:max]
+	}
+	return fmt.Sprintf("%s-%s", pipelineName, jobID)
+}
@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestCloudCredentialsEnv(t *testing.T) {
+	pipelineInfo := &pps.PipelineInfo{Pipeline: client.NewPipeline("edges")}
+	require.Equal(t, 0, len(cloudCredentialsEnv(pipelineInfo, "job")))
+	pipelineInfo.CloudCredentials = &pps.CloudCredentials{
+		GCP: &pps.GCPCredentials{ServiceAccount: "pipeline-edges@my-project.iam.gserviceaccount.com"},
+	}
+	require.Equal(t, 0, len(cloudCredentialsEnv(pipelineInfo, "job")))
+
+	pipelineInfo.CloudCredentials.AWS = &pps.AWSCredentials{
+		RoleARN: "arn:aws:iam::123456789012:role/edges",
+		Region:  "us-west-2",
+	}
+	require.Equal(t, []string{
+		"AWS_ROLE_ARN=arn:aws:iam::123456789012:role/edges",
+		"AWS_WEB_IDENTITY_TOKEN_FILE=/pach-cloud-token/aws",
+		"AWS_ROLE_SESSION_NAME=edges-job",
+		"AWS_REGION=us-west-2",
+		"AWS_DEFAULT_REGION=us-west-2",
+	}, cloudCredentialsEnv(pipelineInfo, "job"))
+}
+
+func TestRoleSessionName(t *testing.T) {
+	jobID := strings.Repeat("a", 32)
+	require.Equal(t, "edges-"+jobID, roleSessionName("edges", jobID))
+	require.Equal(t, "edges", roleSessionName("edges", ""))
+	// Long pipeline names are truncated to fit STS's limit
+	name := roleSessionName(strings.Repeat("p", 100), jobID)
+	require.Equal(t, maxRoleSessionName, len(name))
+	require.True(t, strings.HasSuffix(name, "-"+jobID))
+	require.Equal(t, maxRoleSessionName, len(roleSessionName(strings.Repeat("p", 100), "")))
+}
@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// configMapEnv returns the env vars (as NAME=value) that 'keys' expose to
+// user code, read from the projected volume at 'dir' (see
+// client.PPSConfigMapsMountPath), so that user code sees the config maps'
+// current values rather than the ones that they had when the worker started.
+// Keys that can't be read from the volume are left out, so that their values
+// from the pod spec are used instead.
+func configMapEnv(dir string, keys []*pps.ConfigMapKey) []string {
+	var result []string
+	for _, key := range keys {
+		value, err := ioutil.ReadFile(filepath.Join(dir, key.Name, key.Key))
+		if err != nil {
+			continue
+		}
+		result = append(result, fmt.Sprintf("%s=%s", key.EnvVar, value))
+	}
+	return result
+}
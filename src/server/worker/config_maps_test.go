@@ -0,0 +1,25 @@
+package worker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestConfigMapEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config-maps")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "tunables"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "tunables", "batch-size"), []byte("64"), 0644))
+
+	env := configMapEnv(dir, []*pps.ConfigMapKey{
+		{Name: "tunables", Key: "batch-size", EnvVar: "BATCH_SIZE"},
+		{Name: "tunables", Key: "threshold", EnvVar: "THRESHOLD"}, // not in the volume
+	})
+	require.Equal(t, []string{"BATCH_SIZE=64"}, env)
+}
@@ -0,0 +1,293 @@
+package worker
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	etcd "github.com/coreos/etcd/clientv3"
+	"golang.org/x/net/context"
+)
+
+const (
+	// creditHeadroomFraction is the fraction of a worker's free memory/disk
+	// that it's willing to fill with queued datums. The rest is left for the
+	// user code and for the hashtrees that the worker builds.
+	creditHeadroomFraction = 0.8
+
+	// creditPrefix is the etcd prefix under which workers advertise their
+	// credits to the master
+	creditPrefix = "/worker_credits"
+	// creditTTL is the TTL (in seconds) of a worker's advertised credits, so
+	// that the credits of workers that have gone away expire
+	creditTTL = 30
+	// creditAdvertiseInterval is how often workers advertise their credits
+	creditAdvertiseInterval = 10 * time.Second
+)
+
+// meminfoFile is the kernel's report of the host's memory, which bounds the
+// memory of worker containers that don't have a cgroup limit
+var meminfoFile = "/proc/meminfo"
+
+// cgroupMemoryFiles are the (limit, usage) files of the worker container's
+// memory cgroup, for cgroup v2 and v1 respectively.
+var cgroupMemoryFiles = [][2]string{
+	{"/sys/fs/cgroup/memory.max", "/sys/fs/cgroup/memory.current"},
+	{"/sys/fs/cgroup/memory/memory.limit_in_bytes", "/sys/fs/cgroup/memory/memory.usage_in_bytes"},
+}
+
+// creditPool implements credit-based flow control for the datums in a chunk.
+// Each queued datum holds credits equal to the size of its inputs, and a
+// datum is only queued once enough credits are free, so a worker never
+// downloads more data than it has room for. The pool's capacity is the
+// worker's memory/disk headroom, which is measured when the pool is created
+// and again whenever credits are released.
+type creditPool struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	// measure returns the worker's current headroom
+	measure func() int64
+	// capacity is the total number of credits in the pool
+	capacity int64
+	// inUse is the number of credits held by queued datums
+	inUse int64
+	// queued is the number of datums holding credits
+	queued int64
+	// maxQueued, if nonzero, caps 'queued' regardless of available credits
+	maxQueued int64
+}
+
+// newCreditPool returns a creditPool whose capacity is measured by 'measure',
+// which admits at most 'maxQueued' datums at once (or any number, if
+// maxQueued is 0).
+func newCreditPool(measure func() int64, maxQueued int64) *creditPool {
+	p := &creditPool{
+		measure:   measure,
+		capacity:  measure(),
+		maxQueued: maxQueued,
+	}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// acquire blocks until 'n' credits are available and then takes them. A datum
+// is always admitted when nothing else is queued, so that datums larger than
+// the pool's capacity can still be processed (one at a time).
+func (p *creditPool) acquire(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.queued > 0 && (p.inUse+n > p.capacity || (p.maxQueued > 0 && p.queued >= p.maxQueued)) {
+		p.cond.Wait()
+	}
+	p.inUse += n
+	p.queued++
+}
+
+// release returns 'n' credits, previously taken by acquire, to the pool, and
+// re-measures the pool's capacity. The headroom that's measured doesn't
+// include the memory/disk used by datums that are still queued, so their
+// credits are added back to it.
+func (p *creditPool) release(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inUse -= n
+	p.queued--
+	if free := p.measure(); free > math.MaxInt64-p.inUse {
+		p.capacity = math.MaxInt64
+	} else {
+		p.capacity = p.inUse + free
+	}
+	p.cond.Broadcast()
+}
+
+// available returns the number of credits that aren't held by a datum.
+func (p *creditPool) available() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.inUse > p.capacity {
+		return 0
+	}
+	return p.capacity - p.inUse
+}
+
+// datumCredits returns the number of credits needed to queue 'data', which is
+// the total size of its inputs (at least 1, so empty datums are still
+// counted).
+func datumCredits(data []*Input) int64 {
+	var result int64 = 1
+	for _, input := range data {
+		if input.FileInfo != nil && !input.Lazy {
+			result += int64(input.FileInfo.SizeBytes)
+		}
+	}
+	return result
+}
+
+// headroom returns the number of bytes of datum data that this worker can
+// queue, based on the free memory in its cgroup and the free space on the
+// disk backing 'dir'. If neither can be determined, there's no limit.
+func headroom(dir string) int64 {
+	result := int64(math.MaxInt64)
+	if free, ok := memoryHeadroom(); ok && free < result {
+		result = free
+	}
+	if free, ok := diskHeadroom(dir); ok && free < result {
+		result = free
+	}
+	if result == math.MaxInt64 {
+		return result
+	}
+	return int64(float64(result) * creditHeadroomFraction)
+}
+
+// memoryHeadroom returns the amount of memory that the worker container can
+// still allocate before hitting its cgroup limit or, if the container doesn't
+// have a memory limit, the memory available on the host. It returns false if
+// neither can be read.
+func memoryHeadroom() (int64, bool) {
+	for _, files := range cgroupMemoryFiles {
+		limit, err := readCgroupInt(files[0])
+		if err != nil {
+			continue
+		}
+		usage, err := readCgroupInt(files[1])
+		if err != nil {
+			continue
+		}
+		if limit <= usage {
+			return 0, true
+		}
+		return limit - usage, true
+	}
+	return hostMemoryAvailable()
+}
+
+// hostMemoryAvailable returns the MemAvailable field of /proc/meminfo, in
+// bytes, and false if it can't be read.
+func hostMemoryAvailable() (int64, bool) {
+	f, err := os.Open(meminfoFile)
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// e.g. "MemAvailable:    1234567 kB"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || fields[0] != "MemAvailable:" || fields[2] != "kB" {
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return kb * 1024, true
+	}
+	return 0, false
+}
+
+// readCgroupInt reads the integer in a cgroup file. Unlimited values ("max",
+// or the huge sentinel that cgroup v1 uses) are returned as errors, since
+// they don't impose a limit.
+func readCgroupInt(file string) (int64, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if v >= math.MaxInt64/2 {
+		return 0, strconv.ErrRange
+	}
+	return v, nil
+}
+
+// availableCredits returns the number of credits that this worker can still
+// queue: the available credits of the chunk that it's processing, or its
+// headroom if it isn't processing one. statusMu must be held.
+func (a *APIServer) availableCredits() int64 {
+	if a.credits != nil {
+		return a.credits.available()
+	}
+	return headroom(inputPrefix)
+}
+
+// advertiseCredits periodically records this worker's available credits in
+// etcd, where the master reads them to size the chunks it hands out (see
+// minAdvertisedCredits). It returns when 'ctx' is canceled.
+func (a *APIServer) advertiseCredits(ctx context.Context) {
+	key := path.Join(a.etcdPrefix, creditPrefix, a.pipelineInfo.Pipeline.Name, a.workerName)
+	for {
+		if err := func() error {
+			resp, err := a.etcdClient.Grant(ctx, creditTTL)
+			if err != nil {
+				return err
+			}
+			for {
+				credits := func() int64 {
+					a.statusMu.Lock()
+					defer a.statusMu.Unlock()
+					return a.availableCredits()
+				}()
+				if _, err := a.etcdClient.Put(ctx, key, fmt.Sprint(credits), etcd.WithLease(resp.ID)); err != nil {
+					return err
+				}
+				if _, err := a.etcdClient.KeepAliveOnce(ctx, resp.ID); err != nil {
+					return err
+				}
+				select {
+				case <-time.After(creditAdvertiseInterval):
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}(); err != nil && ctx.Err() == nil {
+			log.Printf("error advertising credits: %v", err)
+		}
+		select {
+		case <-time.After(creditAdvertiseInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// minAdvertisedCredits returns the fewest credits advertised by any of the
+// pipeline's workers, or 0 if none of them have advertised any.
+func (a *APIServer) minAdvertisedCredits(ctx context.Context) (int64, error) {
+	resp, err := a.etcdClient.Get(ctx, path.Join(a.etcdPrefix, creditPrefix, a.pipelineInfo.Pipeline.Name)+"/", etcd.WithPrefix())
+	if err != nil {
+		return 0, err
+	}
+	var values []string
+	for _, kv := range resp.Kvs {
+		values = append(values, string(kv.Value))
+	}
+	return minCredits(values), nil
+}
+
+// minCredits returns the smallest of the advertised credits 'values', ignoring
+// any that can't be parsed, or 0 if there are none.
+func minCredits(values []string) int64 {
+	var result int64
+	for _, value := range values {
+		credits, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || credits <= 0 {
+			continue
+		}
+		if result == 0 || credits < result {
+			result = credits
+		}
+	}
+	return result
+}
@@ -0,0 +1,149 @@
+package worker
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// fixedHeadroom returns a measure func for a creditPool that always reports
+// 'n' bytes of headroom
+func fixedHeadroom(n int64) func() int64 {
+	return func() int64 { return n }
+}
+
+func TestCreditPool(t *testing.T) {
+	p := newCreditPool(fixedHeadroom(100), 0)
+	p.acquire(60)
+	require.Equal(t, int64(40), p.available())
+
+	// A second large datum has to wait for the first one to be released
+	var acquired int32
+	done := make(chan struct{})
+	go func() {
+		p.acquire(60)
+		atomic.StoreInt32(&acquired, 1)
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, int32(0), atomic.LoadInt32(&acquired))
+	p.release(60)
+	<-done
+	p.release(60)
+
+	// Datums bigger than the pool are admitted when nothing else is queued
+	p.acquire(1000)
+	require.Equal(t, int64(0), p.available())
+	p.release(1000)
+	require.Equal(t, int64(100), p.available())
+}
+
+func TestCreditPoolRemeasure(t *testing.T) {
+	free := int64(100)
+	p := newCreditPool(func() int64 { return atomic.LoadInt64(&free) }, 0)
+	p.acquire(30)
+	p.acquire(30)
+	// The first datum's inputs were downloaded and the user code is using
+	// more memory, so there's less headroom once it's released
+	atomic.StoreInt64(&free, 20)
+	p.release(30)
+	require.Equal(t, int64(50), p.capacity)
+	require.Equal(t, int64(20), p.available())
+	p.release(30)
+	require.Equal(t, int64(20), p.available())
+
+	// Unknown headroom doesn't overflow
+	atomic.StoreInt64(&free, math.MaxInt64)
+	p.acquire(10)
+	p.acquire(10)
+	p.release(10)
+	require.Equal(t, int64(math.MaxInt64), p.capacity)
+}
+
+func TestCreditPoolMaxQueued(t *testing.T) {
+	p := newCreditPool(fixedHeadroom(100), 1)
+	p.acquire(1)
+	done := make(chan struct{})
+	go func() {
+		p.acquire(1)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("second datum should not be admitted while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+	p.release(1)
+	<-done
+}
+
+func TestDatumCredits(t *testing.T) {
+	data := []*Input{
+		{FileInfo: &pfs.FileInfo{SizeBytes: 10}},
+		{FileInfo: &pfs.FileInfo{SizeBytes: 20}, Lazy: true},
+		{FileInfo: &pfs.FileInfo{SizeBytes: 30}},
+	}
+	require.Equal(t, int64(41), datumCredits(data))
+	require.Equal(t, int64(1), datumCredits(nil))
+}
+
+func TestHostMemoryAvailable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "meminfo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(f string) { meminfoFile = f }(meminfoFile)
+	meminfoFile = filepath.Join(dir, "meminfo")
+	require.NoError(t, ioutil.WriteFile(meminfoFile, []byte(
+		"MemTotal:       16318784 kB\nMemFree:         1047412 kB\nMemAvailable:    8000000 kB\n"), 0644))
+	free, ok := hostMemoryAvailable()
+	require.True(t, ok)
+	require.Equal(t, int64(8000000*1024), free)
+
+	require.NoError(t, ioutil.WriteFile(meminfoFile, []byte("MemTotal:       16318784 kB\n"), 0644))
+	_, ok = hostMemoryAvailable()
+	require.False(t, ok)
+}
+
+func TestMinCredits(t *testing.T) {
+	require.Equal(t, int64(0), minCredits(nil))
+	require.Equal(t, int64(200), minCredits([]string{"300", "200", "garbage", "0", "1000"}))
+}
+
+func TestNewPlanMaxChunkBytes(t *testing.T) {
+	var inputs []*Input
+	for i := 0; i < 40; i++ {
+		size := uint64(10)
+		if i == 5 {
+			size = 50
+		}
+		inputs = append(inputs, &Input{FileInfo: &pfs.FileInfo{SizeBytes: size}})
+	}
+	df, err := newListDatumIterator(nil, inputs)
+	require.NoError(t, err)
+	// By default, 40 datums and a parallelism of 1 make chunks of 4 datums
+	var expected []int64
+	for i := int64(4); i <= 40; i += 4 {
+		expected = append(expected, i)
+	}
+	require.Equal(t, expected, newPlan(df, nil, 1, 1, 0).Chunks)
+	require.Equal(t, expected, newPlan(df, nil, 1, 1, 1000).Chunks)
+
+	// Chunks also end before they exceed the workers' credits, and a datum
+	// that's bigger than the credits gets a chunk of its own
+	expected = []int64{2, 4, 5, 6}
+	for i := int64(8); i <= 40; i += 2 {
+		expected = append(expected, i)
+	}
+	require.Equal(t, expected, newPlan(df, nil, 1, 1, 25).Chunks)
+
+	// Explicit chunk specs are left alone
+	require.Equal(t, []int64{20, 40}, newPlan(df, &pps.ChunkSpec{Number: 20}, 1, 1, 25).Chunks)
+}
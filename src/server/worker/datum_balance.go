@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// datumBalance measures how evenly the chunks in 'chunkStates' were spread
+// across the workers that processed them (see pps.DatumBalance). Chunks
+// without start and finish times (e.g. from workers that predate them) are
+// left out, and it returns nil if none have them.
+func datumBalance(chunkStates []*ChunkState) *pps.DatumBalance {
+	busy := make(map[string]time.Duration)
+	done := make(map[string]time.Time)
+	for _, chunkState := range chunkStates {
+		started, err := types.TimestampFromProto(chunkState.Started)
+		if err != nil {
+			continue
+		}
+		finished, err := types.TimestampFromProto(chunkState.Finished)
+		if err != nil {
+			continue
+		}
+		busy[chunkState.Address] += finished.Sub(started)
+		if finished.After(done[chunkState.Address]) {
+			done[chunkState.Address] = finished
+		}
+	}
+	if len(busy) == 0 {
+		return nil
+	}
+	var max, total time.Duration
+	for _, d := range busy {
+		total += d
+		if d > max {
+			max = d
+		}
+	}
+	// The tail runs from when the first worker finished its last chunk to when
+	// the last worker did
+	var first, last time.Time
+	for _, t := range done {
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+		if t.After(last) {
+			last = t
+		}
+	}
+	return &pps.DatumBalance{
+		Workers:        int64(len(busy)),
+		WorkerTimeMax:  types.DurationProto(max),
+		WorkerTimeMean: types.DurationProto(total / time.Duration(len(busy))),
+		Tail:           types.DurationProto(last.Sub(first)),
+	}
+}
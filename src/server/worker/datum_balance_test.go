@@ -0,0 +1,38 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestDatumBalance(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	chunk := func(address string, started, finished time.Duration) *ChunkState {
+		startedProto, err := types.TimestampProto(start.Add(started))
+		require.NoError(t, err)
+		finishedProto, err := types.TimestampProto(start.Add(finished))
+		require.NoError(t, err)
+		return &ChunkState{Address: address, Started: startedProto, Finished: finishedProto}
+	}
+	require.Nil(t, datumBalance(nil))
+	require.Nil(t, datumBalance([]*ChunkState{{Address: "a"}}))
+	// a is busy for 60s and finishes at 60s, b is busy for 30s and finishes at
+	// 40s, so b is idle for the last 20s of the job
+	require.Equal(t, &pps.DatumBalance{
+		Workers:        2,
+		WorkerTimeMax:  types.DurationProto(60 * time.Second),
+		WorkerTimeMean: types.DurationProto(45 * time.Second),
+		Tail:           types.DurationProto(20 * time.Second),
+	}, datumBalance([]*ChunkState{
+		chunk("a", 0, 20*time.Second),
+		chunk("b", 0, 10*time.Second),
+		chunk("b", 20*time.Second, 40*time.Second),
+		chunk("a", 20*time.Second, 60*time.Second),
+		{Address: "c"},
+	}))
+}
@@ -47,6 +47,10 @@ func newPFSDatumIterator(pachClient *client.APIClient, input *pps.PFSInput) (Dat
 	if err != nil {
 		return nil, err
 	}
+	g, err := glob.Compile(input.Glob, '/')
+	if err != nil {
+		return nil, err
+	}
 	for {
 		fileInfo, err := fs.Recv()
 		if err == io.EOF {
@@ -54,15 +58,17 @@ func newPFSDatumIterator(pachClient *client.APIClient, input *pps.PFSInput) (Dat
 		} else if err != nil {
 			return nil, err
 		}
-		g := glob.MustCompile(input.Glob, '/')
 		joinOn := g.Replace(fileInfo.File.Path, input.JoinOn)
+		groupBy := g.Replace(fileInfo.File.Path, input.GroupBy)
 		result.inputs = append(result.inputs, &Input{
 			FileInfo:   fileInfo,
 			JoinOn:     joinOn,
+			GroupBy:    groupBy,
 			Name:       input.Name,
 			Lazy:       input.Lazy,
 			Branch:     input.Branch,
 			EmptyFiles: input.EmptyFiles,
+			S3:         input.S3,
 		})
 	}
 	// We sort the inputs so that the order is deterministic. Note that it's
@@ -369,6 +375,71 @@ func (d *joinDatumIterator) DatumN(n int) []*Input {
 	return d.Datum()
 }
 
+type groupDatumIterator struct {
+	datums   [][]*Input
+	location int
+}
+
+func newGroupDatumIterator(pachClient *client.APIClient, group []*pps.Input) (DatumIterator, error) {
+	result := &groupDatumIterator{}
+	om := ordered_map.NewOrderedMap()
+
+	for _, input := range group {
+		datumIterator, err := NewDatumIterator(pachClient, input)
+		if err != nil {
+			return nil, err
+		}
+		for datumIterator.Next() {
+			for _, k := range datumIterator.Datum() {
+				var datum []*Input
+				if datumI, ok := om.Get(k.GroupBy); ok {
+					datum = datumI.([]*Input)
+				}
+				om.Set(k.GroupBy, append(datum, k))
+			}
+		}
+	}
+
+	iter := om.IterFunc()
+	for kv, ok := iter(); ok; kv, ok = iter() {
+		result.datums = append(result.datums, kv.Value.([]*Input))
+	}
+	result.location = -1
+	return result, nil
+}
+
+func (d *groupDatumIterator) Reset() {
+	d.location = -1
+}
+
+func (d *groupDatumIterator) Len() int {
+	return len(d.datums)
+}
+
+func (d *groupDatumIterator) Next() bool {
+	d.location++
+	return d.location < len(d.datums)
+}
+
+func (d *groupDatumIterator) Datum() []*Input {
+	var result []*Input
+	result = append(result, d.datums[d.location]...)
+	// A group's datum can have many inputs with the same name, so they're
+	// sorted by path as well to keep the datum's hash deterministic
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].FileInfo.File.Path < result[j].FileInfo.File.Path
+	})
+	return result
+}
+
+func (d *groupDatumIterator) DatumN(n int) []*Input {
+	d.location = n
+	return d.Datum()
+}
+
 type gitDatumIterator struct {
 	inputs   []*Input
 	location int
@@ -446,6 +517,8 @@ func NewDatumIterator(pachClient *client.APIClient, input *pps.Input) (DatumIter
 		return newCrossDatumIterator(pachClient, input.Cross)
 	case input.Join != nil:
 		return newJoinDatumIterator(pachClient, input.Join)
+	case input.Group != nil:
+		return newGroupDatumIterator(pachClient, input.Group)
 	case input.Cron != nil:
 		return newCronDatumIterator(pachClient, input.Cron)
 	case input.Git != nil:
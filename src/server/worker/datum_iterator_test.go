@@ -128,6 +128,27 @@ func TestDatumIterators(t *testing.T) {
 		"/foo42/foo24",
 		"/foo43/foo34",
 		"/foo44/foo44")
+
+	in10 := client.NewPFSInputOpts("a", dataRepo, "", "/foo(?)(?)", "", false)
+	in10.Pfs.Commit = commit.ID
+	in10.Pfs.GroupBy = "$2"
+	in11 := client.NewPFSInputOpts("b", dataRepo, "", "/foo(?)", "", false)
+	in11.Pfs.Commit = commit.ID
+	in11.Pfs.GroupBy = "$1"
+
+	group1, err := newGroupDatumIterator(c, []*pps.Input{in10, in11})
+	require.NoError(t, err)
+	validateDI(t, group1,
+		"/foo10/foo20/foo30/foo40/foo0",
+		"/foo11/foo21/foo31/foo41/foo1",
+		"/foo12/foo22/foo32/foo42/foo2",
+		"/foo13/foo23/foo33/foo43/foo3",
+		"/foo14/foo24/foo34/foo44/foo4",
+		"/foo15/foo25/foo35/foo45/foo5",
+		"/foo16/foo26/foo36/foo46/foo6",
+		"/foo17/foo27/foo37/foo47/foo7",
+		"/foo18/foo28/foo38/foo48/foo8",
+		"/foo19/foo29/foo39/foo49/foo9")
 }
 
 func benchmarkDatumIterators(j int, b *testing.B) {
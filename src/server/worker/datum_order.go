@@ -0,0 +1,349 @@
+package worker
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	glob "github.com/pachyderm/ohmyglob"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
+)
+
+// orderedDatumIterator returns the datums of another DatumIterator in a
+// different order
+type orderedDatumIterator struct {
+	iterator DatumIterator
+	// order[n] is the index in 'iterator' of this iterator's nth datum
+	order    []int
+	location int
+}
+
+// NewOrderedDatumIterator creates a datumIterator for an input, which
+// returns its datums in 'order' (if set). Every reader of a job's datums must
+// use the same order, since datums are identified by their index, so orders
+// that depend on the pipeline's history read it from the job that produced
+// the parent of the job's output commit, 'outputCommit'.
+func NewOrderedDatumIterator(pachClient *client.APIClient, input *pps.Input, order *pps.DatumOrder, outputCommit *pfs.Commit) (DatumIterator, error) {
+	iterator, err := NewDatumIterator(pachClient, input)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil || (order.By == pps.DatumOrderBy_DATUM_ORDER_DEFAULT && !order.Reverse) {
+		return iterator, nil
+	}
+	var priorities []*glob.Glob
+	if order.By == pps.DatumOrderBy_DATUM_ORDER_PRIORITY_FILE {
+		if priorities, err = readPriorityFile(pachClient, input, order); err != nil {
+			return nil, err
+		}
+	}
+	var durations *DatumDurations
+	if ordersByDuration(order) {
+		if durations, err = parentDatumDurations(pachClient, outputCommit); err != nil {
+			return nil, err
+		}
+	}
+	return newOrderedDatumIterator(iterator, order, priorities, durations), nil
+}
+
+func newOrderedDatumIterator(iterator DatumIterator, order *pps.DatumOrder, priorities []*glob.Glob, durations *DatumDurations) DatumIterator {
+	result := &orderedDatumIterator{
+		iterator: iterator,
+		order:    make([]int, iterator.Len()),
+	}
+	if order.By == pps.DatumOrderBy_DATUM_ORDER_DEFAULT {
+		// The default order is the underlying iterator's, so reversing it just
+		// means returning that iterator's datums backwards
+		for i := range result.order {
+			result.order[i] = len(result.order) - 1 - i
+		}
+		result.Reset()
+		return result
+	}
+	data := make([][]*Input, iterator.Len())
+	for i := range data {
+		data[i] = iterator.DatumN(i)
+		result.order[i] = i
+	}
+	var less func(i, j int) bool
+	if order.By == pps.DatumOrderBy_DATUM_ORDER_COST {
+		// Costs are estimated up front, as estimating them by duration
+		// depends on all of the datums
+		costs := datumCosts(data, order.Cost, durations)
+		less = func(i, j int) bool { return costs[i] > costs[j] }
+	} else {
+		dataLess := datumLess(order.By, priorities)
+		less = func(i, j int) bool { return dataLess(data[i], data[j]) }
+	}
+	// Sort stably, so that datums that 'order' doesn't distinguish keep their
+	// default order, even if it's reversed
+	sort.SliceStable(result.order, func(i, j int) bool {
+		if order.Reverse {
+			return less(result.order[j], result.order[i])
+		}
+		return less(result.order[i], result.order[j])
+	})
+	result.Reset()
+	return result
+}
+
+// datumLess returns a function that returns true if the datum 'x' comes
+// before the datum 'y' when ordering by 'by'
+func datumLess(by pps.DatumOrderBy, priorities []*glob.Glob) func(x, y []*Input) bool {
+	switch by {
+	case pps.DatumOrderBy_DATUM_ORDER_MODIFIED:
+		return func(x, y []*Input) bool {
+			return datumModified(x).Before(datumModified(y))
+		}
+	case pps.DatumOrderBy_DATUM_ORDER_SIZE:
+		return func(x, y []*Input) bool {
+			return datumSize(x) < datumSize(y)
+		}
+	case pps.DatumOrderBy_DATUM_ORDER_LEXICAL:
+		return func(x, y []*Input) bool {
+			for i := 0; i < len(x) && i < len(y); i++ {
+				if x[i].FileInfo.File.Path != y[i].FileInfo.File.Path {
+					return x[i].FileInfo.File.Path < y[i].FileInfo.File.Path
+				}
+			}
+			return len(x) < len(y)
+		}
+	case pps.DatumOrderBy_DATUM_ORDER_PRIORITY_FILE:
+		return func(x, y []*Input) bool {
+			return datumPriority(x, priorities) < datumPriority(y, priorities)
+		}
+	}
+	return func(x, y []*Input) bool { return false }
+}
+
+// datumModified returns when the newest of the commits that hold the files
+// of 'data' finished
+func datumModified(data []*Input) time.Time {
+	var result time.Time
+	for _, input := range data {
+		if modified, err := types.TimestampFromProto(input.FileInfo.Committed); err == nil && modified.After(result) {
+			result = modified
+		}
+	}
+	return result
+}
+
+func datumSize(data []*Input) uint64 {
+	var result uint64
+	for _, input := range data {
+		result += input.FileInfo.SizeBytes
+	}
+	return result
+}
+
+// datumCosts estimates the cost of each datum in 'data' (see pps.DatumCost).
+// Datums without a duration in 'durations' are estimated from their size, at
+// the rate (in seconds per byte) of the datums with one, or at one second per
+// byte if there are none, which orders them by size.
+func datumCosts(data [][]*Input, cost pps.DatumCost, durations *DatumDurations) []float64 {
+	result := make([]float64, len(data))
+	if cost != pps.DatumCost_DATUM_COST_DURATION || durations == nil {
+		for i, datum := range data {
+			result[i] = float64(datumSize(datum))
+		}
+		return result
+	}
+	var seconds, size float64
+	known := make([]bool, len(data))
+	for i, datum := range data {
+		if d, ok := durations.Seconds[datumKey(datum)]; ok {
+			result[i], known[i] = d, true
+			seconds += d
+			size += float64(datumSize(datum))
+		}
+	}
+	rate := 1.0
+	if seconds > 0 && size > 0 {
+		rate = seconds / size
+	}
+	for i, datum := range data {
+		if !known[i] {
+			result[i] = float64(datumSize(datum)) * rate
+		}
+	}
+	return result
+}
+
+// datumKey identifies a datum by the names of its inputs and the paths of its
+// files, rather than by their contents, so that a datum whose files change
+// still has the same key (and its duration can be estimated from the last
+// time it was processed)
+func datumKey(data []*Input) string {
+	var parts []string
+	for _, input := range data {
+		parts = append(parts, input.Name+":"+input.FileInfo.File.Path)
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// datumPriority returns the index of the first of 'priorities' that matches
+// any of the files of 'data', or len(priorities) if none do
+func datumPriority(data []*Input, priorities []*glob.Glob) int {
+	for i, priority := range priorities {
+		for _, input := range data {
+			if priority.Match(input.FileInfo.File.Path) {
+				return i
+			}
+		}
+	}
+	return len(priorities)
+}
+
+// readPriorityFile reads the glob patterns in the priority file of 'order'
+// from the commit of its priority input in 'input'. Blank lines and lines
+// that start with '#' are ignored.
+func readPriorityFile(pachClient *client.APIClient, input *pps.Input, order *pps.DatumOrder) ([]*glob.Glob, error) {
+	var pfsInput *pps.PFSInput
+	pps.VisitInput(input, func(input *pps.Input) {
+		if input.Pfs != nil && input.Pfs.Name == order.PriorityInput {
+			pfsInput = input.Pfs
+		}
+	})
+	if pfsInput == nil {
+		return nil, fmt.Errorf("priority_input %q isn't one of the job's inputs", order.PriorityInput)
+	}
+	if pfsInput.Commit == "" {
+		// the input hasn't been committed to yet, so it has no datums to order
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	if err := pachClient.GetFile(pfsInput.Repo, pfsInput.Commit, order.PriorityFile, 0, 0, &buf); err != nil {
+		return nil, fmt.Errorf("could not read priority file %s: %v", order.PriorityFile, err)
+	}
+	return parsePriorities(&buf)
+}
+
+// ordersByDuration returns true if 'order' orders datums by how long they
+// took to process, in which case jobs record their datums' durations
+func ordersByDuration(order *pps.DatumOrder) bool {
+	return order != nil && order.By == pps.DatumOrderBy_DATUM_ORDER_COST &&
+		order.Cost == pps.DatumCost_DATUM_COST_DURATION
+}
+
+// parentDatumDurations returns the datum durations recorded by the job whose
+// output commit is the parent of 'outputCommit', or nil if there's no such job
+// or it didn't record any (see pps.EtcdJobInfo.datum_durations)
+func parentDatumDurations(pachClient *client.APIClient, outputCommit *pfs.Commit) (*DatumDurations, error) {
+	if outputCommit == nil {
+		return nil, nil
+	}
+	commitInfo, err := pachClient.InspectCommit(outputCommit.Repo.Name, outputCommit.ID)
+	if err != nil {
+		return nil, err
+	}
+	if commitInfo.ParentCommit == nil {
+		return nil, nil
+	}
+	jobInfo, err := pachClient.InspectJobOutputCommit(commitInfo.ParentCommit.Repo.Name, commitInfo.ParentCommit.ID, false)
+	if err != nil {
+		if errutil.IsNotFoundError(err) {
+			// e.g. the parent commit was made by a job that has been deleted
+			return nil, nil
+		}
+		return nil, err
+	}
+	return readDatumDurations(pachClient, jobInfo.DatumDurations)
+}
+
+// readDatumDurations reads the DatumDurations in 'object', or returns nil if
+// 'object' is nil
+func readDatumDurations(pachClient *client.APIClient, object *pfs.Object) (*DatumDurations, error) {
+	if object == nil {
+		return nil, nil
+	}
+	data, err := pachClient.ReadObject(object.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("could not read datum durations: %v", err)
+	}
+	result := &DatumDurations{}
+	if err := result.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// mergeDatumDurations adds the datum durations recorded by the chunks in
+// 'chunkStates' to those recorded by the parent job of 'outputCommit', so that
+// datums that a job skips keep their durations, and returns an object
+// containing the result
+func mergeDatumDurations(pachClient *client.APIClient, outputCommit *pfs.Commit, chunkStates []*ChunkState) (*pfs.Object, error) {
+	result, err := parentDatumDurations(pachClient, outputCommit)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = &DatumDurations{}
+	}
+	if result.Seconds == nil {
+		result.Seconds = make(map[string]float64)
+	}
+	for _, chunkState := range chunkStates {
+		chunkDurations, err := readDatumDurations(pachClient, chunkState.DatumDurations)
+		if err != nil {
+			return nil, err
+		}
+		if chunkDurations == nil {
+			continue
+		}
+		for key, seconds := range chunkDurations.Seconds {
+			result.Seconds[key] = seconds
+		}
+	}
+	data, err := result.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	object, _, err := pachClient.PutObject(bytes.NewReader(data))
+	return object, err
+}
+
+func parsePriorities(buf *bytes.Buffer) ([]*glob.Glob, error) {
+	var result []*glob.Glob
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		g, err := glob.Compile(line, '/')
+		if err != nil {
+			return nil, fmt.Errorf("invalid priority pattern %q: %v", line, err)
+		}
+		result = append(result, g)
+	}
+	return result, scanner.Err()
+}
+
+func (d *orderedDatumIterator) Reset() {
+	d.location = -1
+}
+
+func (d *orderedDatumIterator) Len() int {
+	return len(d.order)
+}
+
+func (d *orderedDatumIterator) Datum() []*Input {
+	return d.iterator.DatumN(d.order[d.location])
+}
+
+func (d *orderedDatumIterator) DatumN(n int) []*Input {
+	return d.iterator.DatumN(d.order[n])
+}
+
+func (d *orderedDatumIterator) Next() bool {
+	d.location++
+	return d.location < len(d.order)
+}
@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+
+	"github.com/gogo/protobuf/types"
+)
+
+func orderInput(path string, size uint64, committed int64) *Input {
+	return &Input{
+		FileInfo: &pfs.FileInfo{
+			File:      client.NewFile("data", "master", path),
+			SizeBytes: size,
+			Committed: &types.Timestamp{Seconds: committed},
+		},
+		Name: "data",
+	}
+}
+
+func orderedPaths(t *testing.T, order *pps.DatumOrder, priorities string, durations *DatumDurations) []string {
+	iterator, err := newListDatumIterator(nil, []*Input{
+		orderInput("/2020-01-02", 30, 2),
+		orderInput("/2020-01-03", 10, 3),
+		orderInput("/2020-01-01", 20, 1),
+		orderInput("/latest", 20, 3),
+	})
+	require.NoError(t, err)
+	globs, err := parsePriorities(bytes.NewBufferString(priorities))
+	require.NoError(t, err)
+	ordered := newOrderedDatumIterator(iterator, order, globs, durations)
+	var result []string
+	for ordered.Next() {
+		result = append(result, ordered.Datum()[0].FileInfo.File.Path)
+	}
+	for i := 0; i < ordered.Len(); i++ {
+		require.Equal(t, result[i], ordered.DatumN(i)[0].FileInfo.File.Path)
+	}
+	return result
+}
+
+func TestOrderedDatumIterator(t *testing.T) {
+	require.Equal(t,
+		[]string{"/2020-01-01", "/2020-01-02", "/2020-01-03", "/latest"},
+		orderedPaths(t, &pps.DatumOrder{By: pps.DatumOrderBy_DATUM_ORDER_LEXICAL}, "", nil))
+	// datums from the same commit keep their default order, even if reversed
+	require.Equal(t,
+		[]string{"/2020-01-03", "/latest", "/2020-01-02", "/2020-01-01"},
+		orderedPaths(t, &pps.DatumOrder{By: pps.DatumOrderBy_DATUM_ORDER_MODIFIED, Reverse: true}, "", nil))
+	require.Equal(t,
+		[]string{"/2020-01-03", "/2020-01-01", "/latest", "/2020-01-02"},
+		orderedPaths(t, &pps.DatumOrder{By: pps.DatumOrderBy_DATUM_ORDER_SIZE}, "", nil))
+	require.Equal(t,
+		[]string{"/latest", "/2020-01-03", "/2020-01-02", "/2020-01-01"},
+		orderedPaths(t, &pps.DatumOrder{By: pps.DatumOrderBy_DATUM_ORDER_PRIORITY_FILE},
+			"# most important first\n/latest\n\n/2020-01-03\n", nil))
+	// the default order, reversed
+	require.Equal(t,
+		[]string{"/latest", "/2020-01-01", "/2020-01-03", "/2020-01-02"},
+		orderedPaths(t, &pps.DatumOrder{Reverse: true}, "", nil))
+	// most expensive first, by size or by duration, where datums without a
+	// duration are estimated at the rate of those with one (3.4s per byte)
+	require.Equal(t,
+		[]string{"/2020-01-02", "/2020-01-01", "/latest", "/2020-01-03"},
+		orderedPaths(t, &pps.DatumOrder{By: pps.DatumOrderBy_DATUM_ORDER_COST}, "", nil))
+	durations := &DatumDurations{Seconds: map[string]float64{
+		"data:/2020-01-03": 100,
+		"data:/2020-01-01": 2,
+	}}
+	require.Equal(t,
+		[]string{"/2020-01-02", "/2020-01-03", "/latest", "/2020-01-01"},
+		orderedPaths(t, &pps.DatumOrder{By: pps.DatumOrderBy_DATUM_ORDER_COST, Cost: pps.DatumCost_DATUM_COST_DURATION}, "", durations))
+	// without any durations, datums are ordered by size
+	require.Equal(t,
+		[]string{"/2020-01-02", "/2020-01-01", "/latest", "/2020-01-03"},
+		orderedPaths(t, &pps.DatumOrder{By: pps.DatumOrderBy_DATUM_ORDER_COST, Cost: pps.DatumCost_DATUM_COST_DURATION}, "", nil))
+}
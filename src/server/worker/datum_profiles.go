@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// datumProfile returns the name of the datum profile (of 'profiles') whose
+// workers process a datum whose input files total 'size' bytes, or "" if the
+// pipeline's own workers process it
+func datumProfile(profiles []*pps.DatumProfile, size int64) string {
+	var result string
+	var resultMin int64
+	for _, profile := range profiles {
+		if size >= profile.MinSizeBytes && profile.MinSizeBytes > resultMin {
+			result, resultMin = profile.Name, profile.MinSizeBytes
+		}
+	}
+	return result
+}
+
+// setChunkProfiles assigns each chunk of 'plan' to the datum profile (of
+// 'profiles') of its largest datum, so that the chunk's workers have enough
+// resources for all of its datums. Chunks aren't split by profile, so small
+// datums may be processed by the workers of a larger profile.
+func setChunkProfiles(plan *Plan, df DatumIterator, profiles []*pps.DatumProfile) {
+	if len(profiles) == 0 {
+		return
+	}
+	plan.ChunkProfiles = make([]string, len(plan.Chunks))
+	low := int64(0)
+	for i, high := range plan.Chunks {
+		var largest int64
+		for j := low; j < high; j++ {
+			var size int64
+			for _, input := range df.DatumN(int(j)) {
+				size += int64(input.FileInfo.SizeBytes)
+			}
+			if size > largest {
+				largest = size
+			}
+		}
+		plan.ChunkProfiles[i] = datumProfile(profiles, largest)
+		low = high
+	}
+}
+
+// chunkProfile returns the name of the datum profile whose workers process
+// the i'th chunk of 'plan', or "" if the pipeline's own workers process it
+func chunkProfile(plan *Plan, i int) string {
+	if i < len(plan.ChunkProfiles) {
+		return plan.ChunkProfiles[i]
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestDatumProfile(t *testing.T) {
+	profiles := []*pps.DatumProfile{
+		{Name: "large", MinSizeBytes: 1000},
+		{Name: "medium", MinSizeBytes: 100},
+	}
+	require.Equal(t, "", datumProfile(profiles, 0))
+	require.Equal(t, "", datumProfile(profiles, 99))
+	require.Equal(t, "medium", datumProfile(profiles, 100))
+	require.Equal(t, "medium", datumProfile(profiles, 999))
+	require.Equal(t, "large", datumProfile(profiles, 1000))
+	require.Equal(t, "", datumProfile(nil, 1000))
+}
+
+func TestSetChunkProfiles(t *testing.T) {
+	var inputs []*Input
+	for _, size := range []uint64{10, 10, 500, 10, 10, 10, 2000, 10} {
+		inputs = append(inputs, &Input{FileInfo: &pfs.FileInfo{SizeBytes: size}})
+	}
+	df, err := newListDatumIterator(nil, inputs)
+	require.NoError(t, err)
+	plan := newPlan(df, &pps.ChunkSpec{Number: 2}, 1, 1, 0)
+	require.Equal(t, []int64{2, 4, 6, 8}, plan.Chunks)
+
+	setChunkProfiles(plan, df, nil)
+	require.Equal(t, 0, len(plan.ChunkProfiles))
+	require.Equal(t, "", chunkProfile(plan, 0))
+
+	setChunkProfiles(plan, df, []*pps.DatumProfile{
+		{Name: "large", MinSizeBytes: 1000},
+		{Name: "medium", MinSizeBytes: 100},
+	})
+	require.Equal(t, []string{"", "medium", "", "large"}, plan.ChunkProfiles)
+}
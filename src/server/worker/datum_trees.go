@@ -0,0 +1,158 @@
+package worker
+
+import (
+	"io/ioutil"
+	"path"
+	"sync"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+)
+
+// datumTreeStore stores the hashtrees (and stats hashtrees) of individual
+// datums, keyed by datum tag. They're read when a job's output is merged, and
+// when later jobs skip datums that have already been processed.
+type datumTreeStore interface {
+	// put stores 'tree' under 'tag'
+	put(pachClient *client.APIClient, tag string, tree []byte) error
+	// get returns the tree stored under 'tag'
+	get(pachClient *client.APIClient, tag string) ([]byte, error)
+	// exists returns true if a tree is stored under 'tag'
+	exists(pachClient *client.APIClient, tag string) bool
+}
+
+// newDatumTreeStore returns the datumTreeStore for 'pipelineInfo', which
+// writes to the pipeline's spill location, if it has one, and otherwise to
+// PFS's object store
+func newDatumTreeStore(pipelineInfo *pps.PipelineInfo, hashtreeStorage string) (datumTreeStore, error) {
+	if pipelineInfo.Spill == nil {
+		return &tagStore{hashtreeStorage: hashtreeStorage}, nil
+	}
+	url, err := obj.ParseURL(pipelineInfo.Spill.URL)
+	if err != nil {
+		return nil, err
+	}
+	objClient, err := obj.NewClientFromURLAndSecret(url, false)
+	if err != nil {
+		return nil, err
+	}
+	return &spillStore{
+		objClient: objClient,
+		prefix:    path.Join(url.Object, pipelineInfo.Pipeline.Name),
+	}, nil
+}
+
+// tagStore stores datum trees as tagged objects in PFS's object store. Trees
+// are written through pachd, but read directly from object storage.
+type tagStore struct {
+	hashtreeStorage string
+
+	// objClient is initialized by the first call to get()
+	objClient   obj.Client
+	objClientMu sync.Mutex
+}
+
+func (s *tagStore) put(pachClient *client.APIClient, tag string, tree []byte) (retErr error) {
+	w, err := pachClient.PutObjectAsync([]*pfs.Tag{client.NewTag(tag)})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := w.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	_, err = w.Write(tree)
+	return err
+}
+
+func (s *tagStore) get(pachClient *client.APIClient, tag string) (_ []byte, retErr error) {
+	objClient, err := s.getObjClient()
+	if err != nil {
+		return nil, err
+	}
+	info, err := pachClient.InspectTag(pachClient.Ctx(), client.NewTag(tag))
+	if err != nil {
+		return nil, err
+	}
+	path, err := obj.BlockPathFromEnv(info.BlockRef.Block)
+	if err != nil {
+		return nil, err
+	}
+	r, err := objClient.Reader(pachClient.Ctx(), path, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := r.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	return ioutil.ReadAll(r)
+}
+
+func (s *tagStore) exists(pachClient *client.APIClient, tag string) bool {
+	_, err := pachClient.InspectTag(pachClient.Ctx(), client.NewTag(tag))
+	return err == nil
+}
+
+func (s *tagStore) getObjClient() (obj.Client, error) {
+	s.objClientMu.Lock()
+	defer s.objClientMu.Unlock()
+	if s.objClient == nil {
+		objClient, err := obj.NewClientFromSecret(s.hashtreeStorage)
+		if err != nil {
+			return nil, err
+		}
+		s.objClient = objClient
+	}
+	return s.objClient, nil
+}
+
+// spillStore stores datum trees in a pipeline's spill location (see
+// pps.Spill), at '<prefix>/<tag>'. Trees may be deleted from the spill
+// location at any time (e.g. by a lifecycle policy), in which case the datum
+// is no longer skipped by later jobs.
+type spillStore struct {
+	objClient obj.Client
+	prefix    string
+}
+
+func (s *spillStore) put(pachClient *client.APIClient, tag string, tree []byte) (retErr error) {
+	p := path.Join(s.prefix, tag)
+	if s.objClient.Exists(pachClient.Ctx(), p) {
+		// Object store writers may fail if the object exists, and datum trees
+		// with the same tag have the same content
+		return nil
+	}
+	w, err := s.objClient.Writer(pachClient.Ctx(), p)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := w.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	_, err = w.Write(tree)
+	return err
+}
+
+func (s *spillStore) get(pachClient *client.APIClient, tag string) (_ []byte, retErr error) {
+	r, err := s.objClient.Reader(pachClient.Ctx(), path.Join(s.prefix, tag), 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := r.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	return ioutil.ReadAll(r)
+}
+
+func (s *spillStore) exists(pachClient *client.APIClient, tag string) bool {
+	return s.objClient.Exists(pachClient.Ctx(), path.Join(s.prefix, tag))
+}
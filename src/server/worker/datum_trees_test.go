@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+)
+
+func TestSpillStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spill")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	objClient, err := obj.NewLocalClient(dir)
+	require.NoError(t, err)
+	store := &spillStore{objClient: objClient, prefix: "spill/pipeline"}
+	pachClient := &client.APIClient{}
+
+	require.False(t, store.exists(pachClient, "tag"))
+	_, err = store.get(pachClient, "tag")
+	require.YesError(t, err)
+
+	require.NoError(t, store.put(pachClient, "tag", []byte("tree")))
+	require.True(t, store.exists(pachClient, "tag"))
+	tree, err := store.get(pachClient, "tag")
+	require.NoError(t, err)
+	require.Equal(t, "tree", string(tree))
+
+	// Trees with the same tag have the same content, so rewriting a tree is a
+	// no-op rather than an error
+	require.NoError(t, store.put(pachClient, "tag", []byte("tree")))
+	require.False(t, store.exists(pachClient, "other"))
+}
@@ -0,0 +1,128 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// checksDeterminism returns true if the datum with tag 'tag' is in the sample
+// of datums that 'check' runs twice. The sample is picked by hashing the tag,
+// so that a datum that's retried is checked on every try.
+func checksDeterminism(check *pps.DeterminismCheck, tag string) bool {
+	if check == nil {
+		return false
+	}
+	if check.Fraction >= 1 {
+		return true
+	}
+	h := fnv.New64a()
+	h.Write([]byte(tag))
+	return float64(h.Sum64()) < check.Fraction*math.MaxUint64
+}
+
+// hashOutput returns a hash of the paths and contents of the files in the
+// output directory 'outPath'. Symlinks to directories (e.g. to inputs) are
+// hashed by their targets.
+func hashOutput(outPath string) ([]byte, error) {
+	hash := sha256.New()
+	if err := filepath.Walk(outPath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(outPath, filePath)
+		if err != nil {
+			return err
+		}
+		hash.Write([]byte(relPath))
+		hash.Write([]byte{0})
+		if info.IsDir() {
+			return nil
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Stat(filePath)
+			if err != nil {
+				return err
+			}
+			if target.IsDir() {
+				link, err := os.Readlink(filePath)
+				if err != nil {
+					return err
+				}
+				hash.Write([]byte(link))
+				hash.Write([]byte{0})
+				return nil
+			}
+		}
+		f, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(hash, f)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+// checkDeterminism runs the user code on the datum in 'dir' a second time,
+// after its first run succeeded, and returns whether both runs wrote the same
+// output. The second run's output is kept, unless the second run fails, in
+// which case the first run's output is restored and the datum counts as
+// nondeterministic.
+func (a *APIServer) checkDeterminism(ctx context.Context, logger *taggedLogger, environ []string, dir string, stats *pps.ProcessStats, rawDatumTimeout *types.Duration) (_ bool, retErr error) {
+	outPath := filepath.Join(dir, "out")
+	firstHash, err := hashOutput(outPath)
+	if err != nil {
+		return false, err
+	}
+	// Set the first run's output aside, so that the second run starts from
+	// an empty /pfs/out
+	firstPath := filepath.Join(dir, "out.first")
+	if err := os.Rename(outPath, firstPath); err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := os.RemoveAll(firstPath); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	if err := os.Mkdir(outPath, 0777); err != nil {
+		return false, err
+	}
+	if a.uid != nil && a.gid != nil {
+		if err := os.Chown(outPath, int(*a.uid), int(*a.gid)); err != nil {
+			return false, err
+		}
+	}
+	logger.Logf("running user code a second time to check that its output is deterministic")
+	if err := a.runUserCode(ctx, logger, environ, stats, rawDatumTimeout); err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		logger.Logf("the second run of the user code failed, keeping the first run's output: %v", err)
+		if err := os.RemoveAll(outPath); err != nil {
+			return false, err
+		}
+		return false, os.Rename(firstPath, outPath)
+	}
+	secondHash, err := hashOutput(outPath)
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(firstHash, secondHash) {
+		logger.Logf("nondeterministic datum: the user code's output differed between two runs (%x vs %x)", firstHash, secondHash)
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestChecksDeterminism(t *testing.T) {
+	require.False(t, checksDeterminism(nil, "tag"))
+	require.True(t, checksDeterminism(&pps.DeterminismCheck{Fraction: 1}, "tag"))
+
+	// Roughly 'fraction' of the datums are checked, and the same ones each time
+	check := &pps.DeterminismCheck{Fraction: 0.25}
+	checked := 0
+	for i := 0; i < 10000; i++ {
+		tag := fmt.Sprintf("tag-%d", i)
+		if checksDeterminism(check, tag) {
+			checked++
+			require.True(t, checksDeterminism(check, tag))
+		}
+	}
+	require.True(t, checked > 2000 && checked < 3000, "checked %d of 10000 datums", checked)
+}
+
+func TestHashOutput(t *testing.T) {
+	writeOutput := func(files map[string]string) string {
+		dir, err := ioutil.TempDir("", "out")
+		require.NoError(t, err)
+		for path, content := range files {
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0777))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, path), []byte(content), 0666))
+		}
+		return dir
+	}
+	hash := func(files map[string]string) []byte {
+		dir := writeOutput(files)
+		defer os.RemoveAll(dir)
+		h, err := hashOutput(dir)
+		require.NoError(t, err)
+		return h
+	}
+	files := map[string]string{"a": "foo", "dir/b": "bar"}
+	require.Equal(t, hash(files), hash(map[string]string{"a": "foo", "dir/b": "bar"}))
+	require.NotEqual(t, hash(files), hash(map[string]string{"a": "foo", "dir/b": "baz"}))
+	require.NotEqual(t, hash(files), hash(map[string]string{"a": "foo", "b": "bar"}))
+	require.NotEqual(t, hash(files), hash(map[string]string{"a": "foo"}))
+}
@@ -0,0 +1,17 @@
+package worker
+
+import (
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// isEmptyJob returns true if the job 'jobPtr' is empty (see pps.EmptyJobPolicy):
+// it didn't process, recover or fail any datums, and 'diff', the diff of its
+// output commit against its parent, is empty. If the diff couldn't be computed
+// ('diff' is nil), the job isn't considered empty.
+func isEmptyJob(jobPtr *pps.EtcdJobInfo, diff *pps.OutputDiff) bool {
+	if diff == nil {
+		return false
+	}
+	return jobPtr.DataProcessed == 0 && jobPtr.DataRecovered == 0 && jobPtr.DataFailed == 0 &&
+		diff.FilesAdded == 0 && diff.FilesChanged == 0 && diff.FilesDeleted == 0
+}
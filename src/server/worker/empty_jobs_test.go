@@ -0,0 +1,22 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestIsEmptyJob(t *testing.T) {
+	// Every datum was skipped, and the output is unchanged
+	jobPtr := &pps.EtcdJobInfo{DataSkipped: 10, DataTotal: 10}
+	require.True(t, isEmptyJob(jobPtr, &pps.OutputDiff{}))
+	require.True(t, isEmptyJob(&pps.EtcdJobInfo{}, &pps.OutputDiff{}))
+	require.False(t, isEmptyJob(jobPtr, nil))
+
+	// Datums were deleted from the input, so their output was removed
+	require.False(t, isEmptyJob(jobPtr, &pps.OutputDiff{FilesDeleted: 1, SizeDelta: -10}))
+	// A datum was processed, even though its output didn't change
+	jobPtr.DataProcessed = 1
+	require.False(t, isEmptyJob(jobPtr, &pps.OutputDiff{}))
+}
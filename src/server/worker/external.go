@@ -0,0 +1,228 @@
+package worker
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
+)
+
+const (
+	// externalPollInterval is how often the master checks on the jobs that
+	// it has submitted to an external execution backend
+	externalPollInterval = 10 * time.Second
+	// externalAuthTokenEnv is the env var that holds the pipeline's auth
+	// token in the jobs that an external execution backend runs
+	externalAuthTokenEnv = "PACH_AUTH_TOKEN"
+	// externalPachdAddressEnv is the env var that holds the address of pachd
+	// in the jobs that an external execution backend runs
+	externalPachdAddressEnv = "PACHD_ADDRESS"
+)
+
+// externalJobState is the state of a job run by an external execution backend
+type externalJobState int
+
+const (
+	externalJobRunning externalJobState = iota
+	externalJobSucceeded
+	externalJobFailed
+)
+
+// externalExecutor runs the worker binary (in chunk mode, see
+// RunExternalChunk) on an external execution backend
+type externalExecutor interface {
+	// submit starts a job called 'name' that runs the worker binary with the
+	// env vars in 'env', and returns the backend's ID for the job
+	submit(ctx context.Context, name string, env map[string]string) (string, error)
+	// status returns the state of the job 'id', and the reason it failed, if
+	// it did
+	status(ctx context.Context, id string) (externalJobState, string, error)
+	// delete stops the job 'id', if it's still running, and cleans up any
+	// resources that it holds in the backend
+	delete(ctx context.Context, id string) error
+}
+
+// newExternalExecutor returns an externalExecutor for 'backend'
+func newExternalExecutor(backend *pps.ExecutionBackend, pipelineInfo *pps.PipelineInfo) (externalExecutor, error) {
+	switch {
+	case backend.AWSBatch != nil:
+		return newBatchExecutor(backend.AWSBatch, pipelineInfo)
+	case backend.Kubernetes != nil:
+		return newKubeExecutor(backend.Kubernetes, pipelineInfo)
+	default:
+		return nil, fmt.Errorf("pipeline %s has no execution backend", pipelineInfo.Pipeline.Name)
+	}
+}
+
+// externalChunk is a chunk of datums that's been submitted to an external
+// execution backend
+type externalChunk struct {
+	id     string // the backend's ID for the job processing the chunk
+	datums int64
+	done   bool
+}
+
+// runExternalJob processes the datums in 'df' on the pipeline's execution
+// backend, rather than in the pipeline's workers. Each chunk of datums is
+// submitted to the backend as a separate job, which writes its outputs
+// directly to the job's output commit. Because the output commit is written
+// with PutFile, it's not built from the hashtrees of previous jobs, so no
+// datums are skipped, and the job's stats commit is left empty.
+func (a *APIServer) runExternalJob(pachClient *client.APIClient, jobInfo *pps.JobInfo, df DatumIterator, parallelism int, authToken string, logger *taggedLogger) (retErr error) {
+	ctx := pachClient.Ctx()
+	executor, err := newExternalExecutor(a.pipelineInfo.Backend, a.pipelineInfo)
+	if err != nil {
+		return err
+	}
+	jobID := jobInfo.Job.ID
+	var killed bool
+	if _, err := col.NewSTM(ctx, a.etcdClient, func(stm col.STM) error {
+		jobPtr := &pps.EtcdJobInfo{}
+		if err := a.jobs.ReadWrite(stm).Get(jobID, jobPtr); err != nil {
+			return err
+		}
+		if jobPtr.State == pps.JobState_JOB_KILLED {
+			killed = true
+			return nil
+		}
+		jobPtr.DataTotal = int64(df.Len())
+		jobPtr.DataProcessed = 0
+		return ppsutil.UpdateJobState(a.pipelines.ReadWrite(stm), a.jobs.ReadWrite(stm), jobPtr, pps.JobState_JOB_RUNNING, "")
+	}); err != nil || killed {
+		return err
+	}
+
+	// Clear any outputs written by a previous attempt at this job (the output
+	// commit is otherwise empty, as no files are inherited from its parent)
+	outputCommit := jobInfo.OutputCommit
+	if err := pachClient.DeleteFile(outputCommit.Repo.Name, outputCommit.ID, "/"); err != nil {
+		return err
+	}
+
+	// Submit every chunk of datums to the backend
+	var chunks []*externalChunk
+	defer func() {
+		// Clean up the jobs in the backend, even if 'ctx' has been cancelled
+		// (e.g. because this job was killed)
+		for _, chunk := range chunks {
+			if err := executor.delete(context.Background(), chunk.id); err != nil {
+				logger.Logf("error deleting external job %s: %v", chunk.id, err)
+			}
+		}
+	}()
+	plan := newPlan(df, jobInfo.ChunkSpec, parallelism, 1, 0)
+	low := int64(0)
+	for i, high := range plan.Chunks {
+		manifest := &ExternalChunk{
+			JobID:        jobID,
+			OutputCommit: outputCommit,
+			Transform:    a.pipelineInfo.Transform,
+		}
+		for j := low; j < high; j++ {
+			manifest.Datums = append(manifest.Datums, &ExternalDatum{Inputs: df.DatumN(int(j))})
+		}
+		data, err := manifest.Marshal()
+		if err != nil {
+			return err
+		}
+		object, _, err := pachClient.PutObject(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		id, err := executor.submit(ctx, fmt.Sprintf("pach-%s-%d", jobID, i), map[string]string{
+			client.PPSExternalChunkEnv: object.Hash,
+			externalPachdAddressEnv:    a.pipelineInfo.Backend.PachdAddress,
+			externalAuthTokenEnv:       authToken,
+		})
+		if err != nil {
+			return fmt.Errorf("could not submit chunk %d to external backend: %v", i, err)
+		}
+		logger.Logf("submitted chunk %d (datums %d-%d) as external job %s", i, low, high, id)
+		chunks = append(chunks, &externalChunk{id: id, datums: high - low})
+		low = high
+	}
+
+	// Wait for all of the chunks to finish, or for one of them to fail
+	var failure string
+	for remaining := len(chunks); remaining > 0 && failure == ""; {
+		select {
+		case <-time.After(externalPollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		var processed int64
+		for i, chunk := range chunks {
+			if chunk.done {
+				continue
+			}
+			state, reason, err := executor.status(ctx, chunk.id)
+			if err != nil {
+				return err
+			}
+			switch state {
+			case externalJobSucceeded:
+				chunk.done = true
+				processed += chunk.datums
+				remaining--
+			case externalJobFailed:
+				failure = fmt.Sprintf("chunk %d failed in external job %s: %s", i, chunk.id, reason)
+			}
+		}
+		if processed > 0 {
+			if _, err := col.NewSTM(ctx, a.etcdClient, func(stm col.STM) error {
+				jobPtr := &pps.EtcdJobInfo{}
+				return a.jobs.ReadWrite(stm).Update(jobID, jobPtr, func() error {
+					jobPtr.DataProcessed += processed
+					return nil
+				})
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if jobInfo.EnableStats {
+		if _, err := pachClient.PfsAPIClient.FinishCommit(ctx, &pfs.FinishCommitRequest{
+			Commit: jobInfo.StatsCommit,
+			Empty:  true,
+		}); err != nil && !pfsserver.IsCommitFinishedErr(err) {
+			return err
+		}
+	}
+	// As in waitJob, a failed job's output commit is finished only after its
+	// state is set, otherwise the job will be considered killed
+	if failure != "" {
+		if err := a.updateJobState(ctx, jobInfo, pps.JobState_JOB_FAILURE, failure); err != nil {
+			return err
+		}
+		if _, err := pachClient.PfsAPIClient.FinishCommit(ctx, &pfs.FinishCommitRequest{
+			Commit: outputCommit,
+			Empty:  true,
+		}); err != nil && !pfsserver.IsCommitFinishedErr(err) {
+			return err
+		}
+		return nil
+	}
+	if _, err := pachClient.PfsAPIClient.FinishCommit(ctx, &pfs.FinishCommitRequest{
+		Commit: outputCommit,
+	}); err != nil && !pfsserver.IsCommitFinishedErr(err) {
+		if pfsserver.IsCommitNotFoundErr(err) || pfsserver.IsCommitDeletedErr(err) {
+			<-ctx.Done() // the job is being deleted by waitJob's watcher
+			return nil
+		}
+		return err
+	}
+	if err := a.egress(pachClient, logger, jobInfo); err != nil {
+		reason := fmt.Sprintf("egress error: %v", err)
+		return a.updateJobState(ctx, jobInfo, pps.JobState_JOB_FAILURE, reason)
+	}
+	return a.updateJobState(ctx, jobInfo, pps.JobState_JOB_SUCCESS, "")
+}
@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/batch"
+	"golang.org/x/net/context"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// maxBatchAttempts is the maximum number of attempts that AWS Batch allows
+// in a job's retry strategy
+const maxBatchAttempts = 10
+
+// batchExecutor runs chunks as AWS Batch jobs. Credentials are read from the
+// environment (see pps.AWSBatchBackend.CredentialsSecret) or from the
+// worker's IAM role.
+type batchExecutor struct {
+	client   *batch.Batch
+	spec     *pps.AWSBatchBackend
+	attempts int64
+}
+
+func newBatchExecutor(spec *pps.AWSBatchBackend, pipelineInfo *pps.PipelineInfo) (*batchExecutor, error) {
+	sess, err := session.NewSession(&aws.Config{Region: aws.String(spec.Region)})
+	if err != nil {
+		return nil, fmt.Errorf("could not create AWS session: %v", err)
+	}
+	attempts := pipelineInfo.DatumTries
+	if attempts < 1 {
+		attempts = 1
+	} else if attempts > maxBatchAttempts {
+		attempts = maxBatchAttempts
+	}
+	return &batchExecutor{
+		client:   batch.New(sess),
+		spec:     spec,
+		attempts: attempts,
+	}, nil
+}
+
+func (e *batchExecutor) submit(ctx context.Context, name string, env map[string]string) (string, error) {
+	var names []string
+	for key := range env {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	var environment []*batch.KeyValuePair
+	for _, key := range names {
+		environment = append(environment, &batch.KeyValuePair{
+			Name:  aws.String(key),
+			Value: aws.String(env[key]),
+		})
+	}
+	resp, err := e.client.SubmitJobWithContext(ctx, &batch.SubmitJobInput{
+		JobName:       aws.String(name),
+		JobQueue:      aws.String(e.spec.JobQueue),
+		JobDefinition: aws.String(e.spec.JobDefinition),
+		ContainerOverrides: &batch.ContainerOverrides{
+			Command:     aws.StringSlice([]string{"/pach-bin/worker"}),
+			Environment: environment,
+		},
+		RetryStrategy: &batch.RetryStrategy{Attempts: aws.Int64(e.attempts)},
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(resp.JobId), nil
+}
+
+func (e *batchExecutor) status(ctx context.Context, id string) (externalJobState, string, error) {
+	resp, err := e.client.DescribeJobsWithContext(ctx, &batch.DescribeJobsInput{
+		Jobs: aws.StringSlice([]string{id}),
+	})
+	if err != nil {
+		return externalJobRunning, "", err
+	}
+	if len(resp.Jobs) != 1 {
+		return externalJobFailed, "job not found in AWS Batch", nil
+	}
+	job := resp.Jobs[0]
+	switch aws.StringValue(job.Status) {
+	case batch.JobStatusSucceeded:
+		return externalJobSucceeded, "", nil
+	case batch.JobStatusFailed:
+		return externalJobFailed, aws.StringValue(job.StatusReason), nil
+	default:
+		return externalJobRunning, "", nil
+	}
+}
+
+func (e *batchExecutor) delete(ctx context.Context, id string) error {
+	// Terminating a job that has already finished is a no-op
+	_, err := e.client.TerminateJobWithContext(ctx, &batch.TerminateJobInput{
+		JobId:  aws.String(id),
+		Reason: aws.String("the pachyderm job has finished"),
+	})
+	return err
+}
@@ -0,0 +1,153 @@
+package worker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/net/context"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kube "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// kubeExecutor runs chunks as kubernetes Jobs in a remote cluster. As with
+// local workers, the worker binary is copied into the pipeline's image by an
+// init container.
+type kubeExecutor struct {
+	client       *kube.Clientset
+	namespace    string
+	workerImage  string
+	pipelineInfo *pps.PipelineInfo
+}
+
+func newKubeExecutor(spec *pps.KubernetesBackend, pipelineInfo *pps.PipelineInfo) (*kubeExecutor, error) {
+	rules := &clientcmd.ClientConfigLoadingRules{
+		ExplicitPath: filepath.Join(client.PPSBackendMountPath, "config"),
+	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: spec.Context}
+	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+	restConfig, err := kubeConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not load kubeconfig for remote cluster: %v", err)
+	}
+	namespace := spec.Namespace
+	if namespace == "" {
+		if namespace, _, err = kubeConfig.Namespace(); err != nil {
+			return nil, err
+		}
+	}
+	kubeClient, err := kube.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &kubeExecutor{
+		client:       kubeClient,
+		namespace:    namespace,
+		workerImage:  os.Getenv(client.PPSWorkerImageEnv),
+		pipelineInfo: pipelineInfo,
+	}, nil
+}
+
+func (e *kubeExecutor) submit(ctx context.Context, name string, env map[string]string) (string, error) {
+	var names []string
+	for key := range env {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	var envVars []v1.EnvVar
+	for _, key := range names {
+		envVars = append(envVars, v1.EnvVar{Name: key, Value: env[key]})
+	}
+	transform := e.pipelineInfo.Transform
+	var imagePullSecrets []v1.LocalObjectReference
+	for _, secret := range transform.ImagePullSecrets {
+		imagePullSecrets = append(imagePullSecrets, v1.LocalObjectReference{Name: secret})
+	}
+	volumeMounts := []v1.VolumeMount{{
+		Name:      "pach-bin",
+		MountPath: "/pach-bin",
+	}, {
+		Name:      client.PPSWorkerVolume,
+		MountPath: client.PPSInputPrefix,
+	}}
+	// Datums are retried by the Job controller
+	backoffLimit := int32(e.pipelineInfo.DatumTries - 1)
+	if backoffLimit < 0 {
+		backoffLimit = 0
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				"app":      "pachyderm-external",
+				"pipeline": e.pipelineInfo.Pipeline.Name,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{{
+						Name:         "init",
+						Image:        e.workerImage,
+						Command:      []string{"/pach/worker.sh"},
+						VolumeMounts: volumeMounts[:1],
+					}},
+					Containers: []v1.Container{{
+						Name:         client.PPSWorkerUserContainerName,
+						Image:        transform.Image,
+						Command:      []string{"/pach-bin/worker"},
+						Env:          envVars,
+						VolumeMounts: volumeMounts,
+					}},
+					Volumes: []v1.Volume{{
+						Name:         "pach-bin",
+						VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+					}, {
+						Name:         client.PPSWorkerVolume,
+						VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+					}},
+					RestartPolicy:    v1.RestartPolicyNever,
+					ImagePullSecrets: imagePullSecrets,
+				},
+			},
+		},
+	}
+	if _, err := e.client.BatchV1().Jobs(e.namespace).Create(job); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func (e *kubeExecutor) status(ctx context.Context, id string) (externalJobState, string, error) {
+	job, err := e.client.BatchV1().Jobs(e.namespace).Get(id, metav1.GetOptions{})
+	if err != nil {
+		return externalJobRunning, "", err
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return externalJobSucceeded, "", nil
+		case batchv1.JobFailed:
+			return externalJobFailed, condition.Message, nil
+		}
+	}
+	return externalJobRunning, "", nil
+}
+
+func (e *kubeExecutor) delete(ctx context.Context, id string) error {
+	propagation := metav1.DeletePropagationBackground
+	return e.client.BatchV1().Jobs(e.namespace).Delete(id, &metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+	})
+}
@@ -0,0 +1,176 @@
+package worker
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+)
+
+// RunExternalChunk processes the chunk of datums stored in the object
+// 'chunkHash' (an ExternalChunk), and writes the outputs to the chunk's output
+// commit. It's run by the worker binary in the jobs that an external execution
+// backend starts, in place of the worker's gRPC server. Datums are processed
+// one at a time, like a worker with no parallelism, and any error fails the
+// whole chunk (it's retried by the backend).
+func RunExternalChunk(chunkHash string) error {
+	pachdAddress, err := grpcutil.ParsePachdAddress(os.Getenv(externalPachdAddressEnv))
+	if err != nil {
+		return err
+	}
+	var options []client.Option
+	if pachdAddress.Secured {
+		options = append(options, client.WithSystemCAs)
+	}
+	pachClient, err := client.NewFromAddress(pachdAddress.Hostname(), options...)
+	if err != nil {
+		return fmt.Errorf("could not connect to pachd at %s: %v", pachdAddress.Qualified(), err)
+	}
+	defer pachClient.Close()
+	pachClient.SetAuthToken(os.Getenv(externalAuthTokenEnv))
+
+	var buf bytes.Buffer
+	if err := pachClient.GetObject(chunkHash, &buf); err != nil {
+		return fmt.Errorf("could not read chunk %s: %v", chunkHash, err)
+	}
+	chunk := &ExternalChunk{}
+	if err := chunk.Unmarshal(buf.Bytes()); err != nil {
+		return err
+	}
+	for i, datum := range chunk.Datums {
+		log.Infof("processing datum %d of %d", i+1, len(chunk.Datums))
+		if err := runExternalDatum(pachClient, chunk, datum.Inputs); err != nil {
+			return fmt.Errorf("error processing datum %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// runExternalDatum downloads 'inputs' to /pfs, runs the chunk's transform and
+// uploads the contents of /pfs/out to the chunk's output commit
+func runExternalDatum(pachClient *client.APIClient, chunk *ExternalChunk, inputs []*Input) error {
+	// Clear the previous datum's inputs and outputs
+	entries, err := ioutil.ReadDir(client.PPSInputPrefix)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(client.PPSInputPrefix, entry.Name())); err != nil {
+			return err
+		}
+	}
+	outDir := filepath.Join(client.PPSInputPrefix, "out")
+	if err := os.MkdirAll(outDir, 0777); err != nil {
+		return err
+	}
+
+	for _, input := range inputs {
+		if err := downloadExternalInput(pachClient, input); err != nil {
+			return err
+		}
+	}
+
+	transform := chunk.Transform
+	cmd := exec.Command(transform.Cmd[0], transform.Cmd[1:]...)
+	if transform.Stdin != nil {
+		cmd.Stdin = strings.NewReader(strings.Join(transform.Stdin, "\n") + "\n")
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Dir = transform.WorkingDir
+	cmd.Env = externalUserCodeEnv(chunk, inputs)
+	if err := cmd.Run(); err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return err
+		}
+		status, ok := exitErr.Sys().(syscall.WaitStatus)
+		if !ok || !acceptReturnCode(transform.AcceptReturnCode, status.ExitStatus()) {
+			return fmt.Errorf("user code failed: %v", err)
+		}
+	}
+
+	// Upload the outputs (following symlinks, which are often used to
+	// output input files unchanged)
+	outputCommit := chunk.OutputCommit
+	return filepath.Walk(outDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(outDir, p)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = pachClient.PutFile(outputCommit.Repo.Name, outputCommit.ID, filepath.ToSlash(relPath), f)
+		return err
+	})
+}
+
+// downloadExternalInput downloads the files in 'input' to
+// /pfs/<input name>/<file path>
+func downloadExternalInput(pachClient *client.APIClient, input *Input) error {
+	file := input.FileInfo.File
+	return pachClient.Walk(file.Commit.Repo.Name, file.Commit.ID, file.Path, func(fileInfo *pfs.FileInfo) error {
+		localPath := filepath.Join(client.PPSInputPrefix, input.Name, fileInfo.File.Path)
+		if fileInfo.FileType == pfs.FileType_DIR {
+			return os.MkdirAll(localPath, 0777)
+		}
+		if err := os.MkdirAll(filepath.Dir(localPath), 0777); err != nil {
+			return err
+		}
+		f, err := os.Create(localPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if input.EmptyFiles {
+			return nil
+		}
+		return pachClient.GetFile(file.Commit.Repo.Name, file.Commit.ID, fileInfo.File.Path, 0, 0, f)
+	})
+}
+
+// externalUserCodeEnv returns the env of the user code, which is the same as
+// in a local worker (see userCodeEnv), minus the pipeline's auth token
+func externalUserCodeEnv(chunk *ExternalChunk, inputs []*Input) []string {
+	var result []string
+	for _, v := range os.Environ() {
+		if !strings.HasPrefix(v, externalAuthTokenEnv+"=") {
+			result = append(result, v)
+		}
+	}
+	for name, value := range chunk.Transform.Env {
+		result = append(result, fmt.Sprintf("%s=%s", name, value))
+	}
+	for _, input := range inputs {
+		result = append(result, fmt.Sprintf("%s=%s", input.Name, filepath.Join(client.PPSInputPrefix, input.Name, input.FileInfo.File.Path)))
+		result = append(result, fmt.Sprintf("%s_COMMIT=%s", input.Name, input.FileInfo.File.Commit.ID))
+	}
+	result = append(result, fmt.Sprintf("%s=%s", client.JobIDEnv, chunk.JobID))
+	result = append(result, fmt.Sprintf("%s=%s", client.OutputCommitIDEnv, chunk.OutputCommit.ID))
+	return result
+}
+
+func acceptReturnCode(codes []int64, code int) bool {
+	for _, c := range codes {
+		if int(c) == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+const (
+	// kafkaCommitHeader is the header of each message published by a Kafka
+	// egress that holds the ID of the output commit that it's from
+	kafkaCommitHeader = "pachyderm-commit"
+	// kafkaPathHeader is the header of each message published by a Kafka
+	// egress that holds the path of the file that it's from
+	kafkaPathHeader = "pachyderm-path"
+)
+
+// kafkaEgress publishes the files in the job's output commit to the topic of
+// the pipeline's Kafka egress
+func (a *APIServer) kafkaEgress(pachClient *client.APIClient, logger *taggedLogger, jobInfo *pps.JobInfo) error {
+	spec := jobInfo.Egress.Kafka
+	logger.Logf("Starting kafka egress for job (%v)", jobInfo)
+	start := time.Now()
+	writer := kafka.NewWriter(kafka.WriterConfig{
+		Brokers: spec.Brokers,
+		Topic:   spec.Topic,
+		// Messages from the same file have the same key, so hashing keeps
+		// them in order in one partition
+		Balancer: &kafka.Hash{},
+	})
+	defer writer.Close()
+	commit := jobInfo.OutputCommit
+	var published int
+	if err := pachClient.Walk(commit.Repo.Name, commit.ID, "/", func(fi *pfs.FileInfo) error {
+		if fi.FileType != pfs.FileType_FILE {
+			return nil
+		}
+		var buf bytes.Buffer
+		if err := pachClient.GetFile(commit.Repo.Name, commit.ID, fi.File.Path, 0, 0, &buf); err != nil {
+			return err
+		}
+		msgs, err := kafkaEgressMessages(spec.Format, commit.ID, fi.File.Path, buf.Bytes())
+		if err != nil {
+			return err
+		}
+		if len(msgs) == 0 {
+			return nil
+		}
+		if err := writer.WriteMessages(pachClient.Ctx(), msgs...); err != nil {
+			return fmt.Errorf("could not publish %s to kafka topic %q: %v", fi.File.Path, spec.Topic, err)
+		}
+		published += len(msgs)
+		return nil
+	}); err != nil {
+		return err
+	}
+	logger.Logf("Completed kafka egress for job (%v): published %d messages, duration (%v)", jobInfo, published, time.Since(start))
+	return nil
+}
+
+// kafkaEgressMessages returns the messages that the file at 'path' in the
+// commit 'commitID', which contains 'data', is published as, in 'format'
+func kafkaEgressMessages(format string, commitID string, path string, data []byte) ([]kafka.Message, error) {
+	headers := []kafka.Header{
+		{Key: kafkaCommitHeader, Value: []byte(commitID)},
+		{Key: kafkaPathHeader, Value: []byte(path)},
+	}
+	switch format {
+	case "", KafkaFormatRaw:
+		return []kafka.Message{{Key: []byte(path), Value: data, Headers: headers}}, nil
+	case KafkaFormatLines:
+		var msgs []kafka.Message
+		for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+			line = bytes.TrimSuffix(line, []byte("\n"))
+			if len(line) == 0 {
+				continue
+			}
+			msgs = append(msgs, kafka.Message{Key: []byte(path), Value: line, Headers: headers})
+		}
+		return msgs, nil
+	default:
+		return nil, fmt.Errorf("unrecognized kafka egress format %q", format)
+	}
+}
@@ -0,0 +1,33 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestKafkaEgressMessages(t *testing.T) {
+	msgs, err := kafkaEgressMessages("", "abc", "/dir/file", []byte("a\nb\n"))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(msgs))
+	require.Equal(t, "/dir/file", string(msgs[0].Key))
+	require.Equal(t, "a\nb\n", string(msgs[0].Value))
+	require.Equal(t, kafkaCommitHeader, msgs[0].Headers[0].Key)
+	require.Equal(t, "abc", string(msgs[0].Headers[0].Value))
+	require.Equal(t, "/dir/file", string(msgs[0].Headers[1].Value))
+
+	// Empty lines aren't published, and the last line needn't end in a newline
+	msgs, err = kafkaEgressMessages(KafkaFormatLines, "abc", "/file", []byte("a\n\nb"))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(msgs))
+	require.Equal(t, "a", string(msgs[0].Value))
+	require.Equal(t, "b", string(msgs[1].Value))
+	require.Equal(t, "/file", string(msgs[1].Key))
+
+	msgs, err = kafkaEgressMessages(KafkaFormatLines, "abc", "/file", nil)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(msgs))
+
+	_, err = kafkaEgressMessages("xml", "abc", "/file", nil)
+	require.YesError(t, err)
+}
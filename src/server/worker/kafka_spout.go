@@ -0,0 +1,209 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	kafka "github.com/segmentio/kafka-go"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
+)
+
+const (
+	// KafkaFormatRaw writes each message from a Kafka spout to its own file,
+	// and publishes each file from a Kafka egress as one message
+	KafkaFormatRaw = "raw"
+	// KafkaFormatLines appends the messages in each batch from a Kafka spout,
+	// one per line, to a single file, and publishes each line of each file
+	// from a Kafka egress as its own message
+	KafkaFormatLines = "lines"
+
+	defaultKafkaBatchSize     = 1000
+	defaultKafkaBatchInterval = 10 * time.Second
+)
+
+// kafkaFetcher is the part of kafka.Reader that fetchKafkaBatch uses
+type kafkaFetcher interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+}
+
+// kafkaFile is a file written to a Kafka spout's output repo
+type kafkaFile struct {
+	path string
+	data []byte
+}
+
+// runKafkaSpout consumes the spout's Kafka topic, and commits batches of
+// messages to the pipeline's output repo until 'ctx' is cancelled
+func (a *APIServer) runKafkaSpout(ctx context.Context, logger *taggedLogger) error {
+	spec := a.pipelineInfo.Spout.Kafka
+	batchSize, batchInterval, err := kafkaBatchLimits(spec)
+	if err != nil {
+		return err
+	}
+	group := spec.Group
+	if group == "" {
+		group = a.pipelineInfo.Pipeline.Name
+	}
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: spec.Brokers,
+		Topic:   spec.Topic,
+		GroupID: group,
+	})
+	defer reader.Close()
+
+	// batch holds messages that have been fetched but not yet committed. It
+	// outlives each retry, as the reader won't return them again.
+	var batch []kafka.Message
+	return backoff.RetryNotify(func() error {
+		for {
+			var err error
+			if batch, err = fetchKafkaBatch(ctx, reader, batch, batchSize, batchInterval); err != nil {
+				return err
+			}
+			if err := a.writeKafkaBatch(ctx, spec, batch); err != nil {
+				return err
+			}
+			if err := reader.CommitMessages(ctx, batch...); err != nil {
+				return err
+			}
+			logger.Logf("committed %d messages from kafka topic %q", len(batch), spec.Topic)
+			batch = nil
+		}
+	}, backoff.NewInfiniteBackOff(), func(err error, d time.Duration) error {
+		select {
+		case <-ctx.Done():
+			return err
+		default:
+			logger.Logf("error running kafka spout: %+v, retrying in: %+v", err, d)
+			return nil
+		}
+	})
+}
+
+// kafkaBatchLimits returns the maximum number of messages in each of the
+// spout's batches, and how long a batch waits for more messages
+func kafkaBatchLimits(spec *pps.Kafka) (int, time.Duration, error) {
+	batchSize := defaultKafkaBatchSize
+	if spec.BatchSize > 0 {
+		batchSize = int(spec.BatchSize)
+	}
+	batchInterval := defaultKafkaBatchInterval
+	if spec.BatchInterval != nil {
+		var err error
+		if batchInterval, err = types.DurationFromProto(spec.BatchInterval); err != nil {
+			return 0, 0, err
+		}
+	}
+	return batchSize, batchInterval, nil
+}
+
+// fetchKafkaBatch adds messages from 'reader' to 'batch' until it contains
+// 'size' messages, or 'interval' has passed since it was started (waiting
+// indefinitely for the first message if it's empty). If it fails, it returns
+// the messages fetched so far along with the error.
+func fetchKafkaBatch(ctx context.Context, reader kafkaFetcher, batch []kafka.Message, size int, interval time.Duration) ([]kafka.Message, error) {
+	if len(batch) == 0 {
+		m, err := reader.FetchMessage(ctx)
+		if err != nil {
+			return batch, err
+		}
+		batch = append(batch, m)
+	}
+	intervalCtx, cancel := context.WithTimeout(ctx, interval)
+	defer cancel()
+	for len(batch) < size {
+		m, err := reader.FetchMessage(intervalCtx)
+		if err != nil {
+			if intervalCtx.Err() != nil && ctx.Err() == nil {
+				break // the interval has passed
+			}
+			return batch, err
+		}
+		batch = append(batch, m)
+	}
+	return batch, nil
+}
+
+// kafkaBatchFiles returns the files that 'batch' is written to, in 'format'
+func kafkaBatchFiles(format string, topic string, batch []kafka.Message) ([]kafkaFile, error) {
+	switch format {
+	case "", KafkaFormatRaw:
+		var files []kafkaFile
+		for _, m := range batch {
+			files = append(files, kafkaFile{
+				path: path.Join("/", fmt.Sprint(m.Partition), fmt.Sprintf("%020d", m.Offset)),
+				data: m.Value,
+			})
+		}
+		return files, nil
+	case KafkaFormatLines:
+		var buf bytes.Buffer
+		for _, m := range batch {
+			buf.Write(m.Value)
+			if len(m.Value) == 0 || m.Value[len(m.Value)-1] != '\n' {
+				buf.WriteByte('\n')
+			}
+		}
+		return []kafkaFile{{path: path.Join("/", topic), data: buf.Bytes()}}, nil
+	default:
+		return nil, fmt.Errorf("unrecognized kafka spout format %q", format)
+	}
+}
+
+// writeKafkaBatch writes 'batch' to a new commit in the pipeline's output repo
+func (a *APIServer) writeKafkaBatch(ctx context.Context, spec *pps.Kafka, batch []kafka.Message) (retErr error) {
+	files, err := kafkaBatchFiles(spec.Format, spec.Topic, batch)
+	if err != nil {
+		return err
+	}
+	repo := a.pipelineInfo.Pipeline.Name
+	commit, err := a.pachClient.PfsAPIClient.StartCommit(ctx, &pfs.StartCommitRequest{
+		Parent:     client.NewCommit(repo, ""),
+		Branch:     a.pipelineInfo.OutputBranch,
+		Provenance: []*pfs.CommitProvenance{client.NewCommitProvenance(ppsconsts.SpecRepo, repo, a.pipelineInfo.SpecCommit.ID)},
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if retErr != nil {
+			// Don't finish the commit, so that a partial batch is never
+			// visible; the batch is written again to a new commit
+			a.pachClient.DeleteCommit(repo, commit.ID)
+			return
+		}
+		retErr = a.pachClient.FinishCommit(repo, commit.ID)
+	}()
+	pfc, err := a.pachClient.NewPutFileClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := pfc.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	// Each raw message has its own path, so overwriting it makes writing a
+	// message again (after a failure to commit its offset) idempotent
+	overwrite := a.pipelineInfo.Spout.Overwrite || spec.Format != KafkaFormatLines
+	for _, f := range files {
+		if overwrite {
+			_, err = pfc.PutFileOverwrite(repo, commit.ID, f.path, bytes.NewReader(f.data), 0)
+		} else {
+			_, err = pfc.PutFile(repo, commit.ID, f.path, bytes.NewReader(f.data))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
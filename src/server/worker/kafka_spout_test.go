@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+// fakeKafkaReader returns its messages in order, then blocks until the
+// context is done, or returns err if it's set
+type fakeKafkaReader struct {
+	msgs []kafka.Message
+	err  error
+}
+
+func (r *fakeKafkaReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	if len(r.msgs) > 0 {
+		m := r.msgs[0]
+		r.msgs = r.msgs[1:]
+		return m, nil
+	}
+	if r.err != nil {
+		return kafka.Message{}, r.err
+	}
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func kafkaMessages(n int) []kafka.Message {
+	var msgs []kafka.Message
+	for i := 0; i < n; i++ {
+		msgs = append(msgs, kafka.Message{Offset: int64(i), Value: []byte("msg")})
+	}
+	return msgs
+}
+
+func TestFetchKafkaBatch(t *testing.T) {
+	ctx := context.Background()
+
+	// The batch is cut at its size
+	reader := &fakeKafkaReader{msgs: kafkaMessages(5)}
+	batch, err := fetchKafkaBatch(ctx, reader, nil, 3, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(batch))
+	batch, err = fetchKafkaBatch(ctx, reader, nil, 3, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(batch))
+	require.Equal(t, int64(4), batch[1].Offset)
+
+	// The batch is cut when the interval passes
+	reader = &fakeKafkaReader{msgs: kafkaMessages(2)}
+	batch, err = fetchKafkaBatch(ctx, reader, nil, 10, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(batch))
+
+	// Messages fetched before an error are returned with it, and kept by the
+	// next call
+	reader = &fakeKafkaReader{msgs: kafkaMessages(2), err: errors.New("broken")}
+	batch, err = fetchKafkaBatch(ctx, reader, nil, 10, time.Minute)
+	require.YesError(t, err)
+	require.Equal(t, 2, len(batch))
+	reader = &fakeKafkaReader{msgs: kafkaMessages(1)}
+	batch, err = fetchKafkaBatch(ctx, reader, batch, 3, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(batch))
+
+	// Cancelling the context is an error, even mid-batch
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = fetchKafkaBatch(cancelCtx, &fakeKafkaReader{}, nil, 10, time.Minute)
+	require.YesError(t, err)
+}
+
+func TestKafkaBatchFiles(t *testing.T) {
+	batch := []kafka.Message{
+		{Partition: 0, Offset: 7, Value: []byte("a")},
+		{Partition: 1, Offset: 3, Value: []byte("b\n")},
+	}
+	files, err := kafkaBatchFiles("", "topic", batch)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(files))
+	require.Equal(t, "/0/00000000000000000007", files[0].path)
+	require.Equal(t, "a", string(files[0].data))
+	require.Equal(t, "/1/00000000000000000003", files[1].path)
+
+	files, err = kafkaBatchFiles(KafkaFormatLines, "topic", batch)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(files))
+	require.Equal(t, "/topic", files[0].path)
+	require.Equal(t, "a\nb\n", string(files[0].data))
+
+	_, err = kafkaBatchFiles("xml", "topic", batch)
+	require.YesError(t, err)
+}
@@ -9,7 +9,9 @@ import (
 	"log"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gogo/protobuf/jsonpb"
@@ -34,7 +36,7 @@ import (
 )
 
 const (
-	masterLockPath = "_master_worker_lock"
+	masterLockPath = ppsconsts.WorkerMasterLockPath
 )
 
 func (a *APIServer) getMasterLogger() *taggedLogger {
@@ -84,6 +86,11 @@ func (a *APIServer) master(masterType string, spawner func(*client.APIClient) er
 		}
 		defer masterLock.Unlock(ctx)
 		logger.Logf("Launching %v master process", masterType)
+		if a.replayOf == "" {
+			if err := a.recordWorkersReady(ctx); err != nil {
+				logger.Logf("error recording standby wake: %v", err)
+			}
+		}
 		return spawner(pachClient)
 	}, b, func(err error, d time.Duration) error {
 		if auth.IsErrNotAuthorized(err) {
@@ -97,8 +104,115 @@ func (a *APIServer) master(masterType string, spawner func(*client.APIClient) er
 	})
 }
 
+// recordWorkersReady records that this worker became the master of a pipeline
+// that is waking from standby, i.e. that the pipeline's workers are running
+// (see pps.StandbyWake)
+func (a *APIServer) recordWorkersReady(ctx context.Context) error {
+	_, err := col.NewSTM(ctx, a.etcdClient, func(stm col.STM) error {
+		pipelines := a.pipelines.ReadWrite(stm)
+		pipelinePtr := &pps.EtcdPipelineInfo{}
+		if err := pipelines.Get(a.pipelineInfo.Pipeline.Name, pipelinePtr); err != nil {
+			return err
+		}
+		if !ppsutil.StandbyWakePending(pipelinePtr) || pipelinePtr.StandbyWake.WorkersReady != nil {
+			return nil
+		}
+		pipelinePtr.StandbyWake.WorkersReady = types.TimestampNow()
+		return pipelines.Put(a.pipelineInfo.Pipeline.Name, pipelinePtr)
+	})
+	return err
+}
+
+// waitForDowntimeWindows blocks until none of the pipeline's downtime windows
+// is open, so that no new job starts during one. It returns true if it had to
+// wait.
+func (a *APIServer) waitForDowntimeWindows(ctx context.Context, logger *taggedLogger) (bool, error) {
+	var waited bool
+	for {
+		end, in, err := ppsutil.DowntimeWindowEnd(a.pipelineInfo.DowntimeWindows, time.Now())
+		if err != nil {
+			return waited, err
+		}
+		if !in {
+			return waited, nil
+		}
+		logger.Logf("pipeline is in a downtime window; not starting new jobs until %v", end)
+		waited = true
+		select {
+		case <-time.After(time.Until(end)):
+		case <-ctx.Done():
+			return waited, ctx.Err()
+		}
+	}
+}
+
+// waitForBudget blocks until the pipeline is within its budget, so that it
+// doesn't start new jobs once its estimated spend this month exceeds its
+// monthly limit. It returns true if it had to wait.
+func (a *APIServer) waitForBudget(ctx context.Context, logger *taggedLogger) (bool, error) {
+	if a.pipelineInfo.Budget == nil {
+		return false, nil
+	}
+	var waited bool
+	for {
+		pipelinePtr := &pps.EtcdPipelineInfo{}
+		if err := a.pipelines.ReadOnly(ctx).Get(a.pipelineInfo.Pipeline.Name, pipelinePtr); err != nil {
+			return waited, err
+		}
+		reset, exceeded := ppsutil.BudgetExceeded(pipelinePtr, time.Now())
+		if !exceeded {
+			return waited, nil
+		}
+		logger.Logf("warning: pipeline's estimated spend this month (%v) exceeds its monthly budget of %v; not starting new jobs until %v",
+			pipelinePtr.BudgetSpend.Spend, pipelinePtr.Budget.MonthlyLimit, reset)
+		waited = true
+		select {
+		case <-time.After(time.Until(reset)):
+		case <-ctx.Done():
+			return waited, ctx.Err()
+		}
+	}
+}
+
+// waitToStartJob blocks until the pipeline may start a new job, i.e. until
+// none of its downtime windows is open and it's within its budget. It returns
+// true if it had to wait.
+func (a *APIServer) waitToStartJob(ctx context.Context, logger *taggedLogger) (bool, error) {
+	var waited bool
+	for {
+		inWindow, err := a.waitForDowntimeWindows(ctx, logger)
+		if err != nil {
+			return waited, err
+		}
+		overBudget, err := a.waitForBudget(ctx, logger)
+		if err != nil {
+			return waited, err
+		}
+		if !inWindow && !overBudget {
+			return waited, nil
+		}
+		// Waiting for one may have put the pipeline in the other (e.g. its
+		// budget may reset during a downtime window), so check both again
+		waited = true
+	}
+}
+
 func (a *APIServer) jobSpawner(pachClient *client.APIClient) error {
 	logger := a.getMasterLogger()
+	// Pipelines with max_concurrent_jobs run each job in a free worker pool,
+	// rather than waiting for the job before it to finish. Their running jobs
+	// are stopped when the master is.
+	var pools *workerPools
+	var running sync.WaitGroup
+	if a.pipelineInfo.MaxConcurrentJobs > 1 {
+		pools = newWorkerPools(a.pipelineInfo.MaxConcurrentJobs)
+		ctx, cancel := context.WithCancel(pachClient.Ctx())
+		pachClient = pachClient.WithCtx(ctx)
+		defer func() {
+			cancel()
+			running.Wait()
+		}()
+	}
 	// Listen for new commits, and create jobs when they arrive
 	commitIter, err := pachClient.SubscribeCommit(a.pipelineInfo.Pipeline.Name, "",
 		client.NewCommitProvenance(ppsconsts.SpecRepo, a.pipelineInfo.Pipeline.Name, a.pipelineInfo.SpecCommit.ID),
@@ -115,6 +229,11 @@ func (a *APIServer) jobSpawner(pachClient *client.APIClient) error {
 		if commitInfo.Finished != nil {
 			continue
 		}
+		if commitInfo.Metadata[ppsconsts.ReplayMetadataKey] != a.replayOf {
+			// The commit is the output of a replay (see pps.ReplayJob), which
+			// only the replay's own workers process
+			continue
+		}
 		// Inspect the commit and check again if it has been finished (it may have
 		// been closed since it was queued, e.g. by StopPipeline or StopJob)
 		commitInfo, err = pachClient.InspectCommit(commitInfo.Commit.Repo.Name, commitInfo.Commit.ID)
@@ -156,7 +275,8 @@ func (a *APIServer) jobSpawner(pachClient *client.APIClient) error {
 						return fmt.Errorf("could not kill job with finished output commit: %v", err)
 					}
 				} else {
-					if err := a.updateJobState(pachClient.Ctx(), ji, pps.JobState_JOB_SUCCESS, ""); err != nil {
+					state, reason := finishedJobState(ji, ji.DataFailed)
+					if err := a.updateJobState(pachClient.Ctx(), ji, state, reason); err != nil {
 						return fmt.Errorf("could not mark job with finished output commit as successful: %v", err)
 					}
 				}
@@ -173,11 +293,31 @@ func (a *APIServer) jobSpawner(pachClient *client.APIClient) error {
 		if len(jobInfos) > 1 {
 			return fmt.Errorf("multiple jobs found for commit: %s/%s", commitInfo.Commit.Repo.Name, commitInfo.Commit.ID)
 		} else if len(jobInfos) < 1 {
+			if waited, err := a.waitToStartJob(pachClient.Ctx(), logger); err != nil {
+				return err
+			} else if waited {
+				// The commit may have been finished while the job waited (e.g. by
+				// StopPipeline), in which case no job is needed
+				commitInfo, err = pachClient.InspectCommit(commitInfo.Commit.Repo.Name, commitInfo.Commit.ID)
+				if err != nil {
+					return err
+				}
+				if commitInfo.Finished != nil {
+					continue
+				}
+			}
 			job, err := pachClient.CreateJob(a.pipelineInfo.Pipeline.Name, commitInfo.Commit, statsCommit)
 			if err != nil {
 				return err
 			}
 			logger.Logf("creating new job %q for output commit %q", job.ID, commitInfo.Commit.ID)
+			if consistency := ppsutil.InputConsistency(a.pipelineInfo, commitInfo); !consistency.Consistent {
+				var conflicts []string
+				for _, conflict := range consistency.Conflicts {
+					conflicts = append(conflicts, path.Join(conflict.Branch.Repo.Name, conflict.Branch.Name))
+				}
+				logger.Logf("warning: the inputs of job %q aren't a consistent snapshot (branches with several commits in its provenance: %v, inputs with no commit: %v)", job.ID, conflicts, consistency.Unbound)
+			}
 			// get jobInfo to look up spec commit, pipeline version, etc (if this
 			// worker is stale and about to be killed, the new job may have a newer
 			// pipeline version than the master. Or if the commit is stale, it may
@@ -232,9 +372,29 @@ func (a *APIServer) jobSpawner(pachClient *client.APIClient) error {
 		// Now that the jobInfo is persisted, wait until all input commits are
 		// ready, split the input datums into chunks and merge the results of
 		// chunks as they're processed
-		if err := a.waitJob(pachClient, jobInfo, logger); err != nil {
+		if pools == nil {
+			if err := a.waitJob(pachClient, jobInfo, logger); err != nil {
+				return err
+			}
+			continue
+		}
+		pool, err := pools.acquire(pachClient.Ctx(), jobInfo.WorkerPool)
+		if err != nil {
+			return err
+		}
+		if err := a.assignWorkerPool(pachClient.Ctx(), jobInfo.Job.ID, pool); err != nil {
+			pools.release(pool)
 			return err
 		}
+		logger.Logf("running job %q in worker pool %d", jobInfo.Job.ID, pool)
+		running.Add(1)
+		go func(jobInfo *pps.JobInfo) {
+			defer running.Done()
+			defer pools.release(pool)
+			if err := a.waitJob(pachClient, jobInfo, logger); err != nil {
+				logger.Logf("error waiting on job %q: %v", jobInfo.Job.ID, err)
+			}
+		}(jobInfo)
 	}
 }
 
@@ -247,6 +407,14 @@ func (a *APIServer) spoutSpawner(pachClient *client.APIClient) error {
 	if err != nil {
 		return fmt.Errorf("getTaggedLogger: %v", err)
 	}
+	if a.pipelineInfo.Spout.Kafka != nil {
+		// Kafka spouts are consumed by the worker, so there's no user code
+		// to run
+		if err := a.runKafkaSpout(ctx, logger); err != nil {
+			logger.Logf("error from runKafkaSpout: %+v", err)
+		}
+		return nil
+	}
 	puller := filesync.NewPuller()
 
 	if err := a.unlinkData(nil); err != nil {
@@ -326,7 +494,7 @@ func (a *APIServer) serviceSpawner(pachClient *client.APIClient) error {
 		if err != nil {
 			return err
 		}
-		if err := os.MkdirAll(client.PPSInputPrefix, 0666); err != nil {
+		if err := os.MkdirAll(inputPrefix, 0666); err != nil {
 			return err
 		}
 		if err := a.linkData(data, dir); err != nil {
@@ -401,7 +569,11 @@ func (a *APIServer) merges(jobID string) col.Collection {
 	return col.NewCollection(a.etcdClient, path.Join(a.etcdPrefix, mergePrefix, jobID), nil, &MergeState{}, nil, nil)
 }
 
-func newPlan(df DatumIterator, spec *pps.ChunkSpec, parallelism int, numHashtrees int64) *Plan {
+// newPlan splits the datums of 'df' into chunks according to 'spec'. If
+// 'spec' is unset, the datums are split into chunks of equal numbers of datums
+// and, if 'maxChunkBytes' is nonzero (see minAdvertisedCredits), a chunk is
+// also ended before its inputs exceed 'maxChunkBytes'.
+func newPlan(df DatumIterator, spec *pps.ChunkSpec, parallelism int, numHashtrees int64, maxChunkBytes int64) *Plan {
 	if spec == nil {
 		spec = &pps.ChunkSpec{}
 	}
@@ -410,9 +582,24 @@ func newPlan(df DatumIterator, spec *pps.ChunkSpec, parallelism int, numHashtree
 		if spec.Number == 0 {
 			spec.Number = 1
 		}
+	} else {
+		maxChunkBytes = 0
 	}
 	plan := &Plan{}
-	if spec.Number != 0 {
+	if spec.Number != 0 && maxChunkBytes > 0 {
+		low, size := 0, int64(0)
+		for i := 0; i < df.Len(); i++ {
+			datumSize := int64(0)
+			for _, input := range df.DatumN(i) {
+				datumSize += int64(input.FileInfo.SizeBytes)
+			}
+			if i > low && (int64(i-low) >= spec.Number || size+datumSize > maxChunkBytes) {
+				plan.Chunks = append(plan.Chunks, int64(i))
+				low, size = i, 0
+			}
+			size += datumSize
+		}
+	} else if spec.Number != 0 {
 		for i := spec.Number; i < int64(df.Len()); i += spec.Number {
 			plan.Chunks = append(plan.Chunks, int64(i))
 		}
@@ -424,6 +611,7 @@ func newPlan(df DatumIterator, spec *pps.ChunkSpec, parallelism int, numHashtree
 			}
 			if size > spec.SizeBytes {
 				plan.Chunks = append(plan.Chunks, int64(i))
+				size = 0
 			}
 		}
 	}
@@ -432,6 +620,96 @@ func newPlan(df DatumIterator, spec *pps.ChunkSpec, parallelism int, numHashtree
 	return plan
 }
 
+// externalizePlan moves the chunks of 'plan' into object storage if there are
+// too many of them to store in etcd. It's a no-op for small plans and for
+// plans that have already been externalized.
+func externalizePlan(pachClient *client.APIClient, plan *Plan) error {
+	if len(plan.Chunks) <= maxInlineChunks {
+		return nil
+	}
+	data, err := (&Chunks{Chunks: plan.Chunks, Profiles: plan.ChunkProfiles}).Marshal()
+	if err != nil {
+		return err
+	}
+	object, _, err := pachClient.PutObject(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("error uploading job plan: %v", err)
+	}
+	plan.ChunksObject = object
+	plan.Chunks = nil
+	plan.ChunkProfiles = nil
+	return nil
+}
+
+// loadPlan fills in the chunks of 'plan' if they were externalized by
+// externalizePlan.
+func loadPlan(pachClient *client.APIClient, plan *Plan) error {
+	if plan.ChunksObject == nil {
+		return nil
+	}
+	data, err := pachClient.ReadObject(plan.ChunksObject.Hash)
+	if err != nil {
+		return fmt.Errorf("error reading job plan: %v", err)
+	}
+	chunks := &Chunks{}
+	if err := chunks.Unmarshal(data); err != nil {
+		return err
+	}
+	plan.Chunks = chunks.Chunks
+	plan.ChunkProfiles = chunks.Profiles
+	return nil
+}
+
+// claimPlan returns the chunks of 'plan' that workers claim (and that each have
+// a ChunkState in etcd). If 'plan' has at most maxInlineChunks chunks (or
+// doesn't allow claim ranges), each chunk is claimed on its own and 'plan' is
+// returned. Otherwise, so that huge jobs don't have a key in etcd for each of
+// their chunks, consecutive chunks are claimed together as a range, and each
+// range is processed like a single chunk. Ranges don't mix chunks of
+// different datum profiles.
+func claimPlan(plan *Plan) *Plan {
+	if !plan.ClaimRanges || len(plan.Chunks) <= maxInlineChunks {
+		return plan
+	}
+	perRange := (len(plan.Chunks) + maxInlineChunks - 1) / maxInlineChunks
+	result := &Plan{Merges: plan.Merges}
+	n := 0
+	for i, high := range plan.Chunks {
+		n++
+		if i < len(plan.Chunks)-1 && n < perRange && chunkProfile(plan, i+1) == chunkProfile(plan, i) {
+			continue
+		}
+		result.Chunks = append(result.Chunks, high)
+		if len(plan.ChunkProfiles) > 0 {
+			result.ChunkProfiles = append(result.ChunkProfiles, chunkProfile(plan, i))
+		}
+		n = 0
+	}
+	return result
+}
+
+// finishStandbyWake records, if the pipeline is waking from standby, that the
+// job in 'jobPtr' is about to make its datums available to workers, which
+// completes the wake, and logs a warning if the wake exceeded the pipeline's
+// standby_wake_alarm. The caller writes 'jobPtr'.
+func (a *APIServer) finishStandbyWake(stm col.STM, jobPtr *pps.EtcdJobInfo, logger *taggedLogger) error {
+	pipelines := a.pipelines.ReadWrite(stm)
+	pipelinePtr := &pps.EtcdPipelineInfo{}
+	if err := pipelines.Get(jobPtr.Pipeline.Name, pipelinePtr); err != nil {
+		return err
+	}
+	wake, err := ppsutil.FinishStandbyWake(pipelinePtr, jobPtr, a.pipelineInfo.StandbyWakeAlarm, time.Now())
+	if err != nil || wake == nil {
+		return err
+	}
+	if wake.AlarmExceeded {
+		alarm, _ := types.DurationFromProto(wake.Alarm)
+		logger.Logf("warning: pipeline took %v to wake from standby for job %q, more than its standby_wake_alarm of %v",
+			pps.StandbyWakeLatency(wake), jobPtr.Job.ID, alarm)
+	}
+	return pipelines.Put(jobPtr.Pipeline.Name, pipelinePtr)
+}
+
 func (a *APIServer) failedInputs(ctx context.Context, jobInfo *pps.JobInfo) ([]string, error) {
 	var failedInputs []string
 	var vistErr error
@@ -501,7 +779,10 @@ func (a *APIServer) waitJob(pachClient *client.APIClient, jobInfo *pps.JobInfo,
 				}
 				return err
 			}
-			if commitInfo.Trees == nil {
+			// A commit with neither Trees nor a Tree was finished empty, i.e.
+			// the job was killed or failed (jobs that run on an external
+			// backend finish their output commit with a Tree)
+			if commitInfo.Trees == nil && commitInfo.Tree == nil {
 				defer cancel() // whether job state update succeeds or not, job is done
 				if _, err := col.NewSTM(ctx, a.etcdClient, func(stm col.STM) error {
 					// Read an up to date version of the jobInfo so that we
@@ -535,7 +816,9 @@ func (a *APIServer) waitJob(pachClient *client.APIClient, jobInfo *pps.JobInfo,
 			return nil // retry again
 		})
 	}()
-	if jobInfo.JobTimeout != nil {
+	// Jobs that commit their partial output when they time out are stopped by
+	// their workers (see jobDeadline) rather than killed
+	if jobInfo.JobTimeout != nil && jobInfo.TimeoutPolicy != pps.TimeoutPolicy_TIMEOUT_PARTIAL_SUCCESS {
 		startTime, err := types.TimestampFromProto(jobInfo.Started)
 		if err != nil {
 			return err
@@ -593,7 +876,7 @@ func (a *APIServer) waitJob(pachClient *client.APIClient, jobInfo *pps.JobInfo,
 		}
 		// Create a datum factory pointing at the job's inputs and split up the
 		// input data into chunks
-		df, err := NewDatumIterator(pachClient, jobInfo.Input)
+		df, err := NewOrderedDatumIterator(pachClient, jobInfo.Input, jobInfo.DatumOrder, jobInfo.OutputCommit)
 		if err != nil {
 			return err
 		}
@@ -602,15 +885,47 @@ func (a *APIServer) waitJob(pachClient *client.APIClient, jobInfo *pps.JobInfo,
 			return err
 		}
 		parallelism := int(pipelinePtr.Parallelism)
+		if a.pipelineInfo.Backend != nil {
+			return a.runExternalJob(pachClient, jobInfo, df, parallelism, pipelinePtr.AuthToken, logger)
+		}
 		numHashtrees, err := ppsutil.GetExpectedNumHashtrees(a.pipelineInfo.HashtreeSpec)
 		if err != nil {
 			return fmt.Errorf("error from GetExpectedNumHashtrees: %v", err)
 		}
+		// Resume the job's plan if it already has one (because this master is
+		// recovering from a crash). Otherwise make a new one: large plans are
+		// stored in object storage, and only a reference to them is written to
+		// etcd.
+		jobID := jobInfo.Job.ID
 		plan := &Plan{}
+		if err := a.plans.ReadOnly(ctx).Get(jobID, plan); col.IsErrNotFound(err) {
+			// Unless the pipeline has a chunk spec, each chunk fits in the
+			// credits that the pipeline's workers advertise
+			maxChunkBytes, err := a.minAdvertisedCredits(ctx)
+			if err != nil {
+				return err
+			}
+			plan = newPlan(df, jobInfo.ChunkSpec, parallelism, numHashtrees, maxChunkBytes)
+			setChunkProfiles(plan, df, a.pipelineInfo.DatumProfiles)
+			plan.ClaimRanges = true
+			if err := externalizePlan(pachClient, plan); err != nil {
+				return err
+			}
+			// The output commits of s3_out pipelines start with their
+			// parents' files, which the user code writes to directly, so
+			// clear them before the job's first run (but not when resuming,
+			// which would discard the output of chunks that are done)
+			if a.pipelineInfo.S3Out {
+				if err := pachClient.DeleteFile(jobInfo.OutputCommit.Repo.Name, jobInfo.OutputCommit.ID, "/"); err != nil {
+					return fmt.Errorf("could not clear the output commit: %v", err)
+				}
+			}
+		} else if err != nil {
+			return err
+		}
 		// Read the job document, and either resume (if we're recovering from a
 		// crash) or mark it running. Also write the input chunks calculated above
 		// into plansCol
-		jobID := jobInfo.Job.ID
 		if _, err := col.NewSTM(ctx, a.etcdClient, func(stm col.STM) error {
 			jobs := a.jobs.ReadWrite(stm)
 			jobPtr := &pps.EtcdJobInfo{}
@@ -621,18 +936,39 @@ func (a *APIServer) waitJob(pachClient *client.APIClient, jobInfo *pps.JobInfo,
 				return nil
 			}
 			jobPtr.DataTotal = int64(df.Len())
+			if err := a.finishStandbyWake(stm, jobPtr, logger); err != nil {
+				return err
+			}
 			if err := ppsutil.UpdateJobState(a.pipelines.ReadWrite(stm), a.jobs.ReadWrite(stm), jobPtr, pps.JobState_JOB_RUNNING, ""); err != nil {
 				return err
 			}
 			plansCol := a.plans.ReadWrite(stm)
-			if err := plansCol.Get(jobID, plan); err == nil {
+			existingPlan := &Plan{}
+			if err := plansCol.Get(jobID, existingPlan); err == nil {
+				plan = existingPlan
 				return nil
 			}
-			plan = newPlan(df, jobInfo.ChunkSpec, parallelism, numHashtrees)
 			return plansCol.Put(jobID, plan)
 		}); err != nil {
 			return err
 		}
+		if len(plan.Chunks) > maxInlineChunks {
+			// This job was planned before large plans were moved out of etcd,
+			// move it now. Workers that already read the plan still have the
+			// same chunks, so they're unaffected.
+			if err := externalizePlan(pachClient, plan); err != nil {
+				return err
+			}
+			if _, err := col.NewSTM(ctx, a.etcdClient, func(stm col.STM) error {
+				return a.plans.ReadWrite(stm).Put(jobID, plan)
+			}); err != nil {
+				return err
+			}
+		}
+		if err := loadPlan(pachClient, plan); err != nil {
+			return err
+		}
+		plan = claimPlan(plan)
 		defer func() {
 			if retErr == nil {
 				if _, err := col.NewSTM(ctx, a.etcdClient, func(stm col.STM) error {
@@ -649,6 +985,7 @@ func (a *APIServer) waitJob(pachClient *client.APIClient, jobInfo *pps.JobInfo,
 		chunks := a.chunks(jobInfo.Job.ID).ReadOnly(ctx)
 		var failedDatumID string
 		recoveredDatums := make(map[string]bool)
+		var chunkStates []*ChunkState
 		for _, high := range plan.Chunks {
 			chunkState := &ChunkState{}
 			if err := chunks.WatchOneF(fmt.Sprint(high), func(e *watch.Event) error {
@@ -660,6 +997,7 @@ func (a *APIServer) waitJob(pachClient *client.APIClient, jobInfo *pps.JobInfo,
 					return nil
 				}
 				if chunkState.State != State_RUNNING {
+					chunkStates = append(chunkStates, chunkState)
 					if chunkState.State == State_FAILED {
 						failedDatumID = chunkState.DatumID
 					} else if chunkState.State == State_COMPLETE {
@@ -729,6 +1067,9 @@ func (a *APIServer) waitJob(pachClient *client.APIClient, jobInfo *pps.JobInfo,
 			if err := a.updateJobState(ctx, jobInfo, pps.JobState_JOB_FAILURE, reason); err != nil {
 				return err
 			}
+			// Finishing the output commit ends the job's context, so the job
+			// is tracked first
+			a.trackJob(pachClient, logger, jobInfo.Job.ID)
 			if _, err = pachClient.PfsAPIClient.FinishCommit(ctx, &pfs.FinishCommitRequest{
 				Commit: jobInfo.OutputCommit,
 				Empty:  true,
@@ -742,7 +1083,7 @@ func (a *APIServer) waitJob(pachClient *client.APIClient, jobInfo *pps.JobInfo,
 		pbw := pbutil.NewWriter(buf)
 		for i := 0; i < df.Len(); i++ {
 			files := df.DatumN(i)
-			datumHash := HashDatum(a.pipelineInfo.Pipeline.Name, a.pipelineInfo.Salt, files)
+			datumHash := a.datumTag(files)
 			// recovered datums were not processed, and thus should not be skipped
 			if recoveredDatums[a.DatumID(files)] {
 				continue // so we won't write them to the processed datums object
@@ -755,13 +1096,19 @@ func (a *APIServer) waitJob(pachClient *client.APIClient, jobInfo *pps.JobInfo,
 		if err != nil {
 			return err
 		}
-		// Finish the job's output commit
-		_, err = pachClient.PfsAPIClient.FinishCommit(ctx, &pfs.FinishCommitRequest{
+		// Finish the job's output commit. The user code of s3_out pipelines
+		// writes to the commit directly, so its hashtrees are empty and the
+		// commit is finished from what was written to it instead.
+		finishRequest := &pfs.FinishCommitRequest{
 			Commit:    jobInfo.OutputCommit,
 			Trees:     trees,
 			SizeBytes: size,
 			Datums:    datums,
-		})
+		}
+		if a.pipelineInfo.S3Out {
+			finishRequest = &pfs.FinishCommitRequest{Commit: jobInfo.OutputCommit}
+		}
+		_, err = pachClient.PfsAPIClient.FinishCommit(ctx, finishRequest)
 		if err != nil && !pfsserver.IsCommitFinishedErr(err) {
 			if pfsserver.IsCommitNotFoundErr(err) || pfsserver.IsCommitDeletedErr(err) {
 				// output commit was deleted during e.g. FinishCommit, which means this job
@@ -772,12 +1119,67 @@ func (a *APIServer) waitJob(pachClient *client.APIClient, jobInfo *pps.JobInfo,
 			}
 			return err
 		}
+		// The output diff is only informational, so the job doesn't fail if it
+		// can't be computed (but then it isn't considered empty)
+		diff, err := outputDiff(pachClient, jobInfo.OutputCommit)
+		if err != nil {
+			logger.Logf("could not diff output commit %s: %v", jobInfo.OutputCommit.ID, err)
+		}
+		// Empty jobs of pipelines with an empty job policy are skipped or
+		// elided, and their unchanged output isn't egressed or tracked
+		var empty bool
+		if a.pipelineInfo.EmptyJobPolicy != pps.EmptyJobPolicy_EMPTY_JOB_KEEP {
+			jobPtr := &pps.EtcdJobInfo{}
+			if err := a.jobs.ReadOnly(ctx).Get(jobInfo.Job.ID, jobPtr); err != nil {
+				return err
+			}
+			empty = isEmptyJob(jobPtr, diff)
+		}
 		// Handle egress
-		if err := a.egress(pachClient, logger, jobInfo); err != nil {
-			reason := fmt.Sprintf("egress error: %v", err)
-			return a.updateJobState(ctx, jobInfo, pps.JobState_JOB_FAILURE, reason)
+		if !empty {
+			if err := a.egress(pachClient, logger, jobInfo); err != nil {
+				reason := fmt.Sprintf("egress error: %v", err)
+				return a.updateJobState(ctx, jobInfo, pps.JobState_JOB_FAILURE, reason)
+			}
+		}
+		// Likewise, if the datum durations can't be recorded, the next job
+		// orders its datums by size instead
+		var durations *pfs.Object
+		if ordersByDuration(jobInfo.DatumOrder) {
+			if durations, err = mergeDatumDurations(pachClient, jobInfo.OutputCommit, chunkStates); err != nil {
+				logger.Logf("could not record datum durations: %v", err)
+			}
+		}
+		_, err = col.NewSTM(ctx, a.etcdClient, func(stm col.STM) error {
+			jobs := a.jobs.ReadWrite(stm)
+			jobPtr := &pps.EtcdJobInfo{}
+			if err := jobs.Get(jobInfo.Job.ID, jobPtr); err != nil {
+				return err
+			}
+			jobPtr.OutputDiff = diff
+			jobPtr.DatumBalance = datumBalance(chunkStates)
+			jobPtr.DatumDurations = durations
+			if empty {
+				if a.pipelineInfo.EmptyJobPolicy == pps.EmptyJobPolicy_EMPTY_JOB_ELIDE {
+					// The output commit is kept, as PFS would start a new one for
+					// the same input commits if it were deleted
+					return a.deleteJob(stm, jobPtr)
+				}
+				return ppsutil.UpdateJobState(a.pipelines.ReadWrite(stm), jobs, jobPtr, pps.JobState_JOB_SKIPPED,
+					"the job didn't process any datums, and its output is unchanged")
+			}
+			state, reason := finishedJobState(jobInfo, jobPtr.DataFailed)
+			return ppsutil.UpdateJobState(a.pipelines.ReadWrite(stm), jobs, jobPtr, state, reason)
+		})
+		if err != nil {
+			return err
+		}
+		if empty {
+			logger.Logf("job %q is empty (empty job policy: %s)", jobInfo.Job.ID, a.pipelineInfo.EmptyJobPolicy)
+			return nil
 		}
-		return a.updateJobState(ctx, jobInfo, pps.JobState_JOB_SUCCESS, "")
+		a.trackJob(pachClient, logger, jobInfo.Job.ID)
+		return nil
 	}, backoff.NewInfiniteBackOff(), func(err error, d time.Duration) error {
 		logger.Logf("error in waitJob %v, retrying in %v", err, d)
 		select {
@@ -843,6 +1245,12 @@ func (a *APIServer) egress(pachClient *client.APIClient, logger *taggedLogger, j
 	pachClient.SetMaxConcurrentStreams(100)
 	var egressFailureCount int
 	return backoff.RetryNotify(func() (retErr error) {
+		if jobInfo.Egress != nil && jobInfo.Egress.SQLDatabase != nil {
+			return a.sqlEgress(pachClient, logger, jobInfo)
+		}
+		if jobInfo.Egress != nil && jobInfo.Egress.Kafka != nil {
+			return a.kafkaEgress(pachClient, logger, jobInfo)
+		}
 		if jobInfo.Egress != nil {
 			logger.Logf("Starting egress upload for job (%v)", jobInfo)
 			start := time.Now()
@@ -877,7 +1285,7 @@ func (a *APIServer) receiveSpout(ctx context.Context, logger *taggedLogger) erro
 			// this extra closure is so that we can scope the defer
 			if err := func() (retErr error) {
 				// open a read connection to the /pfs/out named pipe
-				out, err := os.Open("/pfs/out")
+				out, err := os.Open(filepath.Join(inputPrefix, "out"))
 				if err != nil {
 					return err
 				}
@@ -968,7 +1376,13 @@ func (a *APIServer) runService(ctx context.Context, logger *taggedLogger) error
 		if a.pipelineInfo.Spout != nil {
 			go a.receiveSpout(ctx, logger)
 		}
-		return a.runUserCode(ctx, logger, nil, &pps.ProcessStats{}, nil)
+		// Services and spouts aren't run per job, so their AWS credentials'
+		// session name is the pipeline's name
+		var environ []string
+		if env := cloudCredentialsEnv(a.pipelineInfo, ""); len(env) > 0 {
+			environ = append(os.Environ(), env...)
+		}
+		return a.runUserCode(ctx, logger, environ, &pps.ProcessStats{}, nil)
 	}, backoff.NewInfiniteBackOff(), func(err error, d time.Duration) error {
 		select {
 		case <-ctx.Done():
@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// outputDiff summarizes how the finished output commit 'commit' differs from
+// its parent (see pps.OutputDiff)
+func outputDiff(pachClient *client.APIClient, commit *pfs.Commit) (*pps.OutputDiff, error) {
+	result := &pps.OutputDiff{}
+	if err := pachClient.DiffFileF(commit.Repo.Name, commit.ID, "", "", "", "", false, false, func(fileDiff *pfs.FileDiff) error {
+		addFileDiff(result, fileDiff)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// addFileDiff adds 'fileDiff' to the summary 'diff'. Directories differ
+// whenever the files in them do, so they're left out.
+func addFileDiff(diff *pps.OutputDiff, fileDiff *pfs.FileDiff) {
+	newFile, oldFile := fileDiff.NewFile, fileDiff.OldFile
+	if newFile != nil && newFile.FileType != pfs.FileType_FILE {
+		newFile = nil
+	}
+	if oldFile != nil && oldFile.FileType != pfs.FileType_FILE {
+		oldFile = nil
+	}
+	switch {
+	case newFile != nil && oldFile != nil:
+		diff.FilesChanged++
+	case newFile != nil:
+		diff.FilesAdded++
+	case oldFile != nil:
+		diff.FilesDeleted++
+	}
+	if newFile != nil {
+		diff.SizeDelta += int64(newFile.SizeBytes)
+	}
+	if oldFile != nil {
+		diff.SizeDelta -= int64(oldFile.SizeBytes)
+	}
+}
@@ -0,0 +1,38 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestAddFileDiff(t *testing.T) {
+	fileInfo := func(path string, size uint64, fileType pfs.FileType) *pfs.FileInfo {
+		return &pfs.FileInfo{
+			File:      client.NewFile("out", "master", path),
+			FileType:  fileType,
+			SizeBytes: size,
+		}
+	}
+	diff := &pps.OutputDiff{}
+	for _, fileDiff := range []*pfs.FileDiff{
+		// /a was deleted, /b grew, /c was added, and /d was added as a file
+		// where there used to be a directory
+		{OldFile: fileInfo("/", 15, pfs.FileType_DIR), NewFile: fileInfo("/", 35, pfs.FileType_DIR)},
+		{OldFile: fileInfo("/a", 10, pfs.FileType_FILE)},
+		{OldFile: fileInfo("/b", 5, pfs.FileType_FILE), NewFile: fileInfo("/b", 20, pfs.FileType_FILE)},
+		{NewFile: fileInfo("/c", 12, pfs.FileType_FILE)},
+		{OldFile: fileInfo("/d", 0, pfs.FileType_DIR), NewFile: fileInfo("/d", 3, pfs.FileType_FILE)},
+	} {
+		addFileDiff(diff, fileDiff)
+	}
+	require.Equal(t, &pps.OutputDiff{
+		FilesAdded:   2,
+		FilesChanged: 1,
+		FilesDeleted: 1,
+		SizeDelta:    20,
+	}, diff)
+}
@@ -0,0 +1,141 @@
+package worker
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
+)
+
+// streamedFile is a file in a datum's output directory that the worker
+// uploaded when the user code closed it, before the datum finished
+type streamedFile struct {
+	// modTime is the mtime of the local copy after it was truncated
+	modTime time.Time
+	hash    []byte
+	size    int64
+	node    *hashtree.FileNodeProto
+}
+
+// outputStreamer uploads the files in a datum's output directory to object
+// storage as the user code closes them (for pipelines with stream_output),
+// and truncates the local copies, so that large outputs don't fill the
+// worker's scratch volume. uploadOutput then uses the streamed uploads of the
+// files that haven't changed since, rather than reading them again.
+type outputStreamer struct {
+	pachClient *client.APIClient
+	logger     *taggedLogger
+
+	mu sync.Mutex
+	// files are the streamed files, by file ID (see fileID), so that they're
+	// still found if the user code renames them
+	files       map[uint64]*streamedFile
+	uploadBytes uint64
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newOutputStreamer(pachClient *client.APIClient, logger *taggedLogger) *outputStreamer {
+	return &outputStreamer{
+		pachClient: pachClient,
+		logger:     logger,
+		files:      make(map[uint64]*streamedFile),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// upload uploads the file at 'path', which the user code has closed, and
+// truncates it
+func (s *outputStreamer) upload(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // the user code removed or renamed it already
+		}
+		return err
+	}
+	if !info.Mode().IsRegular() || info.Size() == 0 {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := pfs.NewHash()
+	object, size, err := s.pachClient.PutObject(io.TeeReader(f, h))
+	if err != nil {
+		return err
+	}
+	objectInfo, err := s.pachClient.InspectObject(object.Hash)
+	if err != nil {
+		return err
+	}
+	// Only truncate the file if the user code didn't write to it again while
+	// it was being uploaded (otherwise it's uploaded after the datum finishes)
+	after, err := os.Lstat(path)
+	if err != nil || after.Size() != size || !after.ModTime().Equal(info.ModTime()) {
+		return nil
+	}
+	if err := os.Truncate(path, 0); err != nil {
+		return err
+	}
+	if after, err = os.Lstat(path); err != nil {
+		return err
+	}
+	id, ok := fileID(after)
+	if !ok {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[id] = &streamedFile{
+		modTime: after.ModTime(),
+		hash:    h.Sum(nil),
+		size:    size,
+		node:    &hashtree.FileNodeProto{BlockRefs: []*pfs.BlockRef{objectInfo.BlockRef}},
+	}
+	s.uploadBytes += uint64(size)
+	return nil
+}
+
+// lookup returns the streamed upload of the file described by 'info', or nil
+// if the file wasn't streamed, or has been modified since it was truncated.
+// It's safe to call on a nil outputStreamer.
+func (s *outputStreamer) lookup(info os.FileInfo) *streamedFile {
+	if s == nil || info.Size() != 0 {
+		return nil
+	}
+	id, ok := fileID(info)
+	if !ok {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := s.files[id]
+	if f == nil || !info.ModTime().Equal(f.modTime) {
+		return nil
+	}
+	return f
+}
+
+// finish stops streaming files, waiting for the upload in progress (if any)
+// to complete, and returns the number of bytes that were uploaded. It's safe
+// to call more than once, and on a nil outputStreamer.
+func (s *outputStreamer) finish() uint64 {
+	if s == nil {
+		return 0
+	}
+	s.stopOnce.Do(func() { close(s.stop) })
+	<-s.done
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.uploadBytes
+}
@@ -0,0 +1,114 @@
+package worker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/pachyderm/pachyderm/src/client"
+
+	"golang.org/x/sys/unix"
+)
+
+// outputStreamPollMillis is how often the output streamer checks whether it's
+// been stopped, while waiting for files to be closed
+const outputStreamPollMillis = 100
+
+// startOutputStreamer starts an outputStreamer that watches the output
+// directory 'dir' (and its subdirectories) with inotify, and uploads each
+// file that's closed after being written
+func startOutputStreamer(pachClient *client.APIClient, dir string, logger *taggedLogger) (*outputStreamer, error) {
+	fd, err := unix.InotifyInit1(unix.IN_NONBLOCK | unix.IN_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize inotify: %v", err)
+	}
+	s := newOutputStreamer(pachClient, logger)
+	w := &outputWatcher{s: s, fd: fd, dirs: make(map[int32]string)}
+	if err := w.add(dir); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	go w.watch()
+	return s, nil
+}
+
+type outputWatcher struct {
+	s  *outputStreamer
+	fd int
+	// dirs are the watched directories, by watch descriptor
+	dirs map[int32]string
+}
+
+func (w *outputWatcher) add(dir string) error {
+	wd, err := unix.InotifyAddWatch(w.fd, dir, unix.IN_CLOSE_WRITE|unix.IN_CREATE)
+	if err != nil {
+		return fmt.Errorf("could not watch %s: %v", dir, err)
+	}
+	w.dirs[int32(wd)] = dir
+	return nil
+}
+
+func (w *outputWatcher) watch() {
+	defer close(w.s.done)
+	defer unix.Close(w.fd)
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		select {
+		case <-w.s.stop:
+			return
+		default:
+		}
+		fds := []unix.PollFd{{Fd: int32(w.fd), Events: unix.POLLIN}}
+		if n, err := unix.Poll(fds, outputStreamPollMillis); err == unix.EINTR || (err == nil && n == 0) {
+			continue
+		} else if err != nil {
+			w.s.logger.Logf("error waiting for output files to be closed, they'll be uploaded when the datum finishes: %v", err)
+			<-w.s.stop
+			return
+		}
+		n, err := unix.Read(w.fd, buf)
+		if err == unix.EAGAIN || err == unix.EINTR {
+			continue
+		} else if err != nil {
+			w.s.logger.Logf("error reading output file events, they'll be uploaded when the datum finishes: %v", err)
+			<-w.s.stop
+			return
+		}
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + unix.SizeofInotifyEvent
+			name := strings.TrimRight(string(buf[nameStart:nameStart+int(event.Len)]), "\x00")
+			offset = nameStart + int(event.Len)
+			dir, ok := w.dirs[event.Wd]
+			if !ok || name == "" {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			switch {
+			case event.Mask&unix.IN_ISDIR != 0 && event.Mask&unix.IN_CREATE != 0:
+				// Files that are closed before the new directory is watched are
+				// uploaded when the datum finishes
+				if err := w.add(path); err != nil {
+					w.s.logger.Logf("%v, its files will be uploaded when the datum finishes", err)
+				}
+			case event.Mask&unix.IN_CLOSE_WRITE != 0:
+				if err := w.s.upload(path); err != nil {
+					w.s.logger.Logf("could not stream %s, it will be uploaded when the datum finishes: %v", path, err)
+				}
+			}
+		}
+	}
+}
+
+// fileID returns an ID for the file described by 'info' (its inode number),
+// which is unchanged if the file is renamed
+func fileID(info os.FileInfo) (uint64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return stat.Ino, true
+}
@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+)
+
+func TestOutputStreamer(t *testing.T) {
+	c := getPachClient(t)
+	dir, err := ioutil.TempDir("", "out")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	s, err := startOutputStreamer(c, dir, &taggedLogger{marshaler: &jsonpb.Marshaler{}})
+	require.NoError(t, err)
+	defer s.finish()
+
+	// A file that's written and closed is uploaded and truncated, and is still
+	// found after it's renamed
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0777))
+	time.Sleep(2 * outputStreamPollMillis * time.Millisecond) // wait for "sub" to be watched
+	data := bytes.Repeat([]byte("foo"), 1000)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sub", "tmp"), data, 0666))
+	require.NoError(t, backoff.Retry(func() error {
+		info, err := os.Stat(filepath.Join(dir, "sub", "tmp"))
+		if err != nil {
+			return err
+		}
+		if s.lookup(info) == nil {
+			return os.ErrNotExist
+		}
+		return nil
+	}, backoff.NewTestingBackOff()))
+	require.NoError(t, os.Rename(filepath.Join(dir, "sub", "tmp"), filepath.Join(dir, "sub", "file")))
+
+	// A file that's written again after being truncated isn't found
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "changed"), data, 0666))
+	require.NoError(t, backoff.Retry(func() error {
+		info, err := os.Stat(filepath.Join(dir, "changed"))
+		if err != nil {
+			return err
+		}
+		if s.lookup(info) == nil {
+			return os.ErrNotExist
+		}
+		return nil
+	}, backoff.NewTestingBackOff()))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "changed"), []byte("bar"), 0666))
+
+	require.Equal(t, uint64(2*len(data)), s.finish())
+	info, err := os.Stat(filepath.Join(dir, "sub", "file"))
+	require.NoError(t, err)
+	f := s.lookup(info)
+	require.NotNil(t, f)
+	require.Equal(t, int64(len(data)), f.size)
+	h := pfs.NewHash()
+	h.Write(data)
+	require.Equal(t, h.Sum(nil), f.hash)
+	require.Equal(t, 1, len(f.node.BlockRefs))
+	blockRange := f.node.BlockRefs[0].Range
+	require.Equal(t, uint64(len(data)), blockRange.Upper-blockRange.Lower)
+
+	info, err = os.Stat(filepath.Join(dir, "changed"))
+	require.NoError(t, err)
+	require.Nil(t, s.lookup(info))
+}
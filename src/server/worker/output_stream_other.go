@@ -0,0 +1,21 @@
+// +build !linux
+
+package worker
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/pachyderm/pachyderm/src/client"
+)
+
+// startOutputStreamer isn't supported on this OS, and always returns an error
+func startOutputStreamer(pachClient *client.APIClient, dir string, logger *taggedLogger) (*outputStreamer, error) {
+	return nil, fmt.Errorf("streaming output isn't supported on %s", runtime.GOOS)
+}
+
+// fileID isn't supported on this OS, and always returns false
+func fileID(info os.FileInfo) (uint64, bool) {
+	return 0, false
+}
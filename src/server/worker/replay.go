@@ -0,0 +1,25 @@
+package worker
+
+import (
+	"github.com/gogo/protobuf/proto"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
+)
+
+// replayPipelineInfo returns the PipelineInfo that the workers replaying the
+// job 'replayOf' (see pps.ReplayJob) run with, given their pipeline's. The
+// replay's salt is its own, so that it doesn't skip the datums that the
+// pipeline has processed or contend for the pipeline's master lock. It has no
+// datum profiles or worker pools, as they have no workers of their own during
+// a replay, and keeps its job even if it's empty, as ReplayJob reports it.
+func replayPipelineInfo(pipelineInfo *pps.PipelineInfo, replayOf string) *pps.PipelineInfo {
+	result := proto.Clone(pipelineInfo).(*pps.PipelineInfo)
+	result.Salt = ppsutil.ReplaySalt(pipelineInfo.Salt, replayOf)
+	result.DatumProfiles = nil
+	result.MaxConcurrentJobs = 0
+	result.EmptyJobPolicy = pps.EmptyJobPolicy_EMPTY_JOB_KEEP
+	// Replays only write their output commit
+	result.EnableStats = false
+	result.Egress = nil
+	return result
+}
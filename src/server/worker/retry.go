@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+)
+
+// defaultBackoffMultiplier is the factor by which the wait between retries of
+// a failed datum grows, if the pipeline's datum_retry doesn't set one
+const defaultBackoffMultiplier = 2
+
+// datumFailureFile is the file to which user code can write the class of a
+// datum's failure. It's named by $PACH_DATUM_FAILURE_FILE.
+var datumFailureFile = filepath.Join(os.TempDir(), "pach-datum-failure")
+
+// userCodeExitError is returned by runUserCode when the user code exits with
+// a code that the pipeline doesn't accept
+type userCodeExitError struct {
+	exitCode int
+	err      error
+}
+
+func (e *userCodeExitError) Error() string {
+	return fmt.Sprintf("error cmd.WaitIO: %v", e.err)
+}
+
+// datumBackoff returns the backoff between retries of a failed datum that
+// 'retry' configures. Without a datum_retry, datums are retried immediately.
+func datumBackoff(retry *pps.DatumRetry) (backoff.BackOff, error) {
+	if retry == nil || retry.InitialBackoff == nil {
+		return &backoff.ZeroBackOff{}, nil
+	}
+	initial, err := types.DurationFromProto(retry.InitialBackoff)
+	if err != nil {
+		return nil, err
+	}
+	max := time.Duration(math.MaxInt64)
+	if retry.MaxBackoff != nil {
+		if max, err = types.DurationFromProto(retry.MaxBackoff); err != nil {
+			return nil, err
+		}
+	}
+	multiplier := retry.Multiplier
+	if multiplier == 0 {
+		multiplier = defaultBackoffMultiplier
+	}
+	b := &backoff.ExponentialBackOff{
+		InitialInterval:     initial,
+		RandomizationFactor: backoff.DefaultRandomizationFactor,
+		Multiplier:          multiplier,
+		MaxInterval:         max,
+		Clock:               backoff.SystemClock,
+	}
+	b.Reset()
+	return b, nil
+}
+
+// classifyFailure returns whether a datum whose user code exited with
+// 'exitCode' (or -1, if it didn't exit) failed transiently or permanently.
+// 'control' is what the user code wrote to the datum failure file, which
+// takes precedence over its exit code.
+func classifyFailure(retry *pps.DatumRetry, exitCode int, control string) pps.FailureClass {
+	switch strings.ToLower(strings.TrimSpace(control)) {
+	case "permanent":
+		return pps.FailureClass_FAILURE_PERMANENT
+	case "transient":
+		return pps.FailureClass_FAILURE_TRANSIENT
+	}
+	if retry != nil {
+		for _, code := range retry.PermanentExitCodes {
+			if int(code) == exitCode {
+				return pps.FailureClass_FAILURE_PERMANENT
+			}
+		}
+	}
+	return pps.FailureClass_FAILURE_TRANSIENT
+}
+
+// readDatumFailureFile returns what the user code wrote to the datum failure
+// file, if anything
+func readDatumFailureFile() string {
+	control, err := ioutil.ReadFile(datumFailureFile)
+	if err != nil {
+		return ""
+	}
+	return string(control)
+}
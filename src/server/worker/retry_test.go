@@ -0,0 +1,40 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+)
+
+func TestDatumBackoff(t *testing.T) {
+	b, err := datumBackoff(nil)
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), b.NextBackOff())
+
+	b, err = datumBackoff(&pps.DatumRetry{
+		InitialBackoff: types.DurationProto(time.Second),
+		MaxBackoff:     types.DurationProto(3 * time.Second),
+	})
+	require.NoError(t, err)
+	// each wait is within the randomization factor of 1s, 2s, then 3s
+	for _, interval := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second} {
+		d := b.NextBackOff()
+		delta := time.Duration(backoff.DefaultRandomizationFactor * float64(interval))
+		require.True(t, d >= interval-delta && d <= interval+delta, "%v not within %v of %v", d, delta, interval)
+	}
+}
+
+func TestClassifyFailure(t *testing.T) {
+	retry := &pps.DatumRetry{PermanentExitCodes: []int64{3}}
+	require.Equal(t, pps.FailureClass_FAILURE_TRANSIENT, classifyFailure(nil, 3, ""))
+	require.Equal(t, pps.FailureClass_FAILURE_TRANSIENT, classifyFailure(retry, 1, ""))
+	require.Equal(t, pps.FailureClass_FAILURE_PERMANENT, classifyFailure(retry, 3, ""))
+	// the failure file takes precedence over the exit code
+	require.Equal(t, pps.FailureClass_FAILURE_PERMANENT, classifyFailure(nil, 1, "permanent\n"))
+	require.Equal(t, pps.FailureClass_FAILURE_TRANSIENT, classifyFailure(retry, 3, "Transient"))
+	require.Equal(t, pps.FailureClass_FAILURE_PERMANENT, classifyFailure(retry, 3, "unknown"))
+}
@@ -0,0 +1,85 @@
+package worker
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pfs/s3"
+)
+
+// jobS3OutputBucket is the name of the bucket that the user code of s3_out
+// pipelines writes its output to. Inputs can't be named "out", so it doesn't
+// collide with an input bucket.
+const jobS3OutputBucket = "out"
+
+// usesS3 returns true if the user code of 'pipelineInfo' reads any of its
+// inputs or writes its output through the job's S3 gateway
+func usesS3(pipelineInfo *pps.PipelineInfo) bool {
+	return pipelineInfo.S3Out || pps.ContainsS3Inputs(pipelineInfo.Input)
+}
+
+// jobS3Buckets returns the buckets that the S3 gateway of 'jobInfo' serves:
+// a read-only bucket for each S3 input, named after the input and holding
+// the job's input commit, and, if the pipeline has s3_out, the write-only
+// output bucket, which is the job's output commit
+func jobS3Buckets(pipelineInfo *pps.PipelineInfo, jobInfo *pps.JobInfo) ([]*s3.Bucket, *s3.Bucket) {
+	var inputBuckets []*s3.Bucket
+	pps.VisitInput(jobInfo.Input, func(input *pps.Input) {
+		if input.Pfs == nil || !input.Pfs.S3 || input.Pfs.Commit == "" {
+			return
+		}
+		inputBuckets = append(inputBuckets, &s3.Bucket{
+			Name:   input.Pfs.Name,
+			Repo:   input.Pfs.Repo,
+			Commit: input.Pfs.Commit,
+		})
+	})
+	var outputBucket *s3.Bucket
+	if pipelineInfo.S3Out {
+		outputBucket = &s3.Bucket{
+			Name:   jobS3OutputBucket,
+			Repo:   jobInfo.OutputCommit.Repo.Name,
+			Commit: jobInfo.OutputCommit.ID,
+		}
+	}
+	return inputBuckets, outputBucket
+}
+
+// s3Env returns the env vars (as NAME=value) that tell user code where the
+// job's S3 gateway is
+func s3Env() []string {
+	return []string{
+		fmt.Sprintf("%s=localhost:%d", client.PPSS3EndpointEnv, client.PPSJobS3GatewayPort),
+		fmt.Sprintf("%s=0", client.PPSS3UseHTTPSEnv),
+	}
+}
+
+// serveJobS3Gateway starts the S3 gateway of 'jobInfo', if the pipeline uses
+// one, and returns a function that stops it. The gateway listens on
+// localhost, so only this worker's user code can reach it, and it makes
+// every request with 'pachClient' (i.e. with the pipeline's auth token).
+func (a *APIServer) serveJobS3Gateway(pachClient *client.APIClient, jobInfo *pps.JobInfo, logger *taggedLogger) (func(), error) {
+	if !usesS3(a.pipelineInfo) {
+		return func() {}, nil
+	}
+	inputBuckets, outputBucket := jobS3Buckets(a.pipelineInfo, jobInfo)
+	addr := fmt.Sprintf("localhost:%d", client.PPSJobS3GatewayPort)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not start the job's S3 gateway: %v", err)
+	}
+	server := s3.JobServer(addr, pachClient, inputBuckets, outputBucket)
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Logf("error from the job's S3 gateway: %v", err)
+		}
+	}()
+	return func() {
+		if err := server.Close(); err != nil {
+			logger.Logf("error stopping the job's S3 gateway: %v", err)
+		}
+	}, nil
+}
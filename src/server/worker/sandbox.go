@@ -0,0 +1,32 @@
+package worker
+
+import (
+	"context"
+	"os"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/exec"
+)
+
+// SandboxArg is the first argument of a worker process that runs sandboxed
+// user code (see RunSandbox)
+const SandboxArg = "sandbox"
+
+// nobodyID is the UID and GID that sandboxed user code runs as, if the
+// pipeline's user is root
+const nobodyID = 65534
+
+// userCmd returns the command that runs 'args' as the pipeline's user code.
+// If the pipeline is sandboxed, the command runs the worker binary (see
+// RunSandbox), which then runs 'args'.
+func (a *APIServer) userCmd(ctx context.Context, args []string) (*exec.Cmd, error) {
+	sandbox := a.pipelineInfo.Transform.Sandbox
+	if sandbox == nil || sandbox.Seccomp == pps.SeccompProfile_SECCOMP_UNCONFINED {
+		return exec.CommandContext(ctx, args[0], args[1:]...), nil
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return exec.CommandContext(ctx, self, append([]string{SandboxArg}, args...)...), nil
+}
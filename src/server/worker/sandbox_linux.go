@@ -0,0 +1,129 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// sandboxedSyscalls are the syscalls that Pachyderm's seccomp filter blocks.
+// They're all used to escape containers, to attack other processes or to
+// change the state of the whole node, and aren't needed by normal user code.
+var sandboxedSyscalls = []uintptr{
+	// mounts and namespaces
+	unix.SYS_MOUNT, unix.SYS_UMOUNT2, unix.SYS_PIVOT_ROOT, unix.SYS_CHROOT,
+	unix.SYS_UNSHARE, unix.SYS_SETNS, unix.SYS_OPEN_BY_HANDLE_AT,
+	unix.SYS_NAME_TO_HANDLE_AT,
+	// other processes
+	unix.SYS_PTRACE, unix.SYS_PROCESS_VM_READV, unix.SYS_PROCESS_VM_WRITEV,
+	unix.SYS_KCMP,
+	// the kernel
+	unix.SYS_KEXEC_LOAD, unix.SYS_INIT_MODULE, unix.SYS_FINIT_MODULE,
+	unix.SYS_DELETE_MODULE, unix.SYS_BPF, unix.SYS_PERF_EVENT_OPEN,
+	unix.SYS_USERFAULTFD, unix.SYS_KEYCTL, unix.SYS_ADD_KEY,
+	unix.SYS_REQUEST_KEY, unix.SYS_SYSLOG,
+	// node-wide state
+	unix.SYS_REBOOT, unix.SYS_SWAPON, unix.SYS_SWAPOFF, unix.SYS_ACCT,
+	unix.SYS_SETTIMEOFDAY, unix.SYS_CLOCK_SETTIME, unix.SYS_CLOCK_ADJTIME,
+	unix.SYS_ADJTIMEX, unix.SYS_QUOTACTL, unix.SYS_VHANGUP,
+}
+
+// auditArches are the seccomp architecture identifiers of the architectures
+// that the worker is built for. The filter kills processes that make
+// syscalls with any other calling convention, whose syscall numbers differ.
+var auditArches = map[string]uint32{
+	"amd64":   unix.AUDIT_ARCH_X86_64,
+	"arm64":   unix.AUDIT_ARCH_AARCH64,
+	"ppc64le": unix.AUDIT_ARCH_PPC64LE,
+	"s390x":   unix.AUDIT_ARCH_S390X,
+}
+
+const (
+	seccompRetAllow = 0x7fff0000
+	seccompRetErrno = 0x00050000
+	seccompRetKill  = 0x00000000
+
+	// the offsets of the syscall number and architecture in struct
+	// seccomp_data, which seccomp filters read
+	seccompDataNr   = 0
+	seccompDataArch = 4
+
+	// syscalls in the x32 ABI on x86_64 have this bit set
+	x32SyscallBit = 0x40000000
+)
+
+// RunSandbox replaces the current process with 'args', after making it
+// unable to gain privileges and applying Pachyderm's seccomp filter to it.
+// The worker runs sandboxed user code as "<worker> sandbox <cmd>...", as the
+// user that the code runs as. It only returns if 'args' can't be run.
+func RunSandbox(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no command to run in the sandbox")
+	}
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return err
+	}
+	if err := applySandbox(); err != nil {
+		return err
+	}
+	return syscall.Exec(path, args, os.Environ())
+}
+
+// applySandbox makes the current thread unable to gain privileges, and
+// applies Pachyderm's seccomp filter to it. The thread is locked to the
+// calling goroutine, since the filter only applies to this thread (and the
+// processes that it execs).
+func applySandbox() error {
+	runtime.LockOSThread()
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("could not set no_new_privs: %v", err)
+	}
+	filter, err := seccompFilter()
+	if err != nil {
+		return err
+	}
+	prog := unix.SockFprog{
+		Len:    uint16(len(filter)),
+		Filter: &filter[0],
+	}
+	if err := unix.Prctl(unix.PR_SET_SECCOMP, unix.SECCOMP_MODE_FILTER, uintptr(unsafe.Pointer(&prog)), 0, 0); err != nil {
+		return fmt.Errorf("could not apply seccomp filter: %v", err)
+	}
+	return nil
+}
+
+// seccompFilter returns the BPF program of Pachyderm's seccomp filter, which
+// fails the syscalls in sandboxedSyscalls with EPERM
+func seccompFilter() ([]unix.SockFilter, error) {
+	arch, ok := auditArches[runtime.GOARCH]
+	if !ok {
+		return nil, fmt.Errorf("seccomp filters aren't supported on %s", runtime.GOARCH)
+	}
+	stmt := func(code uint16, k uint32) unix.SockFilter {
+		return unix.SockFilter{Code: code, K: k}
+	}
+	jump := func(code uint16, k uint32, jt, jf uint8) unix.SockFilter {
+		return unix.SockFilter{Code: code, Jt: jt, Jf: jf, K: k}
+	}
+	filter := []unix.SockFilter{
+		stmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, seccompDataArch),
+		jump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, arch, 1, 0),
+		stmt(unix.BPF_RET|unix.BPF_K, seccompRetKill),
+		stmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, seccompDataNr),
+		jump(unix.BPF_JMP|unix.BPF_JGE|unix.BPF_K, x32SyscallBit, 0, 1),
+		stmt(unix.BPF_RET|unix.BPF_K, seccompRetErrno|uint32(unix.EPERM)),
+	}
+	for _, nr := range sandboxedSyscalls {
+		filter = append(filter,
+			jump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, uint32(nr), 0, 1),
+			stmt(unix.BPF_RET|unix.BPF_K, seccompRetErrno|uint32(unix.EPERM)))
+	}
+	return append(filter, stmt(unix.BPF_RET|unix.BPF_K, seccompRetAllow)), nil
+}
@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestSandbox(t *testing.T) {
+	if os.Getenv("PACH_TEST_SANDBOX") == "true" {
+		// In the process started below: syscalls that the filter blocks fail
+		// with EPERM, and others (e.g. getcwd) still work
+		if err := applySandbox(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := unix.Unshare(0); err != unix.EPERM {
+			fmt.Printf("expected EPERM from unshare, but got %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := os.Getwd(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSandbox$")
+	cmd.Env = append(os.Environ(), "PACH_TEST_SANDBOX=true")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+}
@@ -0,0 +1,13 @@
+// +build !linux
+
+package worker
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// RunSandbox isn't supported on this OS, and always returns an error
+func RunSandbox(args []string) error {
+	return fmt.Errorf("sandboxed user code isn't supported on %s", runtime.GOOS)
+}
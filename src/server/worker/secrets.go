@@ -0,0 +1,31 @@
+package worker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// secretEnv returns the env vars (as NAME=value) that 'secrets' expose to
+// user code, read from the projected volume at 'dir' (see
+// client.PPSSecretsMountPath). Kubernetes updates the volume when the secrets
+// are rotated, whereas the env vars set in the worker's pod spec keep the
+// values that the secrets had when the worker started. Secrets that can't be
+// read from the volume are left out, so that their values from the pod spec
+// are used instead.
+func secretEnv(dir string, secrets []*pps.SecretMount) []string {
+	var result []string
+	for _, secret := range secrets {
+		if secret.EnvVar == "" {
+			continue
+		}
+		value, err := ioutil.ReadFile(filepath.Join(dir, secret.Name, secret.Key))
+		if err != nil {
+			continue
+		}
+		result = append(result, fmt.Sprintf("%s=%s", secret.EnvVar, value))
+	}
+	return result
+}
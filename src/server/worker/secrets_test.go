@@ -0,0 +1,26 @@
+package worker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestSecretEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "db"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "db", "password"), []byte("rotated"), 0644))
+
+	env := secretEnv(dir, []*pps.SecretMount{
+		{Name: "db", Key: "password", EnvVar: "DB_PASSWORD"},
+		{Name: "db", Key: "user", EnvVar: "DB_USER"}, // not in the volume
+		{Name: "certs", MountPath: "/certs"},         // not an env var
+	})
+	require.Equal(t, []string{"DB_PASSWORD=rotated"}, env)
+}
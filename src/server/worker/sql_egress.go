@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/sqlegress"
+)
+
+// sqlEgress loads the job's output commit into the database of the pipeline's
+// SQL egress. The files under /<table>/ are loaded into <table>.
+func (a *APIServer) sqlEgress(pachClient *client.APIClient, logger *taggedLogger, jobInfo *pps.JobInfo) error {
+	spec := jobInfo.Egress.SQLDatabase
+	logger.Logf("Starting sql egress for job (%v)", jobInfo)
+	start := time.Now()
+	tables, err := sqlEgressTables(pachClient, jobInfo.OutputCommit)
+	if err != nil {
+		return err
+	}
+	loader, err := sqlegress.NewLoader(pachClient.Ctx(), spec.URL, os.Getenv(client.PPSEgressSecretEnv), spec.FileFormat)
+	if err != nil {
+		return err
+	}
+	defer loader.Close()
+	var names []string
+	for table := range tables {
+		names = append(names, table)
+	}
+	sort.Strings(names)
+	for _, table := range names {
+		loaded, err := loader.Load(pachClient.Ctx(), jobInfo.OutputCommit.ID, table, tables[table])
+		if err != nil {
+			return err
+		}
+		if !loaded {
+			logger.Logf("Skipping table %s, which output commit %s was already loaded into", table, jobInfo.OutputCommit.ID)
+		}
+	}
+	logger.Logf("Completed sql egress for job (%v), duration (%v)", jobInfo, time.Since(start))
+	return nil
+}
+
+// sqlEgressTables reads the files in 'commit' and groups them by the table
+// (top-level directory) that they're in
+func sqlEgressTables(pachClient *client.APIClient, commit *pfs.Commit) (map[string][]sqlegress.File, error) {
+	tables := make(map[string][]sqlegress.File)
+	if err := pachClient.Walk(commit.Repo.Name, commit.ID, "/", func(fi *pfs.FileInfo) error {
+		if fi.FileType != pfs.FileType_FILE {
+			return nil
+		}
+		parts := strings.SplitN(strings.TrimPrefix(fi.File.Path, "/"), "/", 2)
+		if len(parts) < 2 {
+			return fmt.Errorf("file %q must be in a directory named after the table that it's loaded into", fi.File.Path)
+		}
+		var buf bytes.Buffer
+		if err := pachClient.GetFile(commit.Repo.Name, commit.ID, fi.File.Path, 0, 0, &buf); err != nil {
+			return err
+		}
+		tables[parts[0]] = append(tables[parts[0]], sqlegress.File{Path: fi.File.Path, Data: buf.Bytes()})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
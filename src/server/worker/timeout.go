@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// skipsTimedOutDatums returns true if the datums of 'jobInfo' that time out
+// are left out of its output, rather than failing it
+func skipsTimedOutDatums(jobInfo *pps.JobInfo) bool {
+	return jobInfo.TimeoutPolicy == pps.TimeoutPolicy_TIMEOUT_SKIP_DATUM ||
+		jobInfo.TimeoutPolicy == pps.TimeoutPolicy_TIMEOUT_PARTIAL_SUCCESS
+}
+
+// jobDeadline returns the time after which workers stop processing the
+// datums of 'jobInfo' and leave them out of its output, or the zero time if
+// they never do. Only jobs with the timeout policy TIMEOUT_PARTIAL_SUCCESS
+// have a deadline; the master kills other jobs that reach their job_timeout.
+func jobDeadline(jobInfo *pps.JobInfo) (time.Time, error) {
+	if jobInfo.TimeoutPolicy != pps.TimeoutPolicy_TIMEOUT_PARTIAL_SUCCESS || jobInfo.JobTimeout == nil {
+		return time.Time{}, nil
+	}
+	started, err := types.TimestampFromProto(jobInfo.Started)
+	if err != nil {
+		return time.Time{}, err
+	}
+	timeout, err := types.DurationFromProto(jobInfo.JobTimeout)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return started.Add(timeout), nil
+}
+
+// finishedJobState returns the state (and the reason for it) of 'jobInfo'
+// once its output commit is finished, given that 'dataFailed' of its datums
+// failed. Datums only fail without failing the job if they timed out and the
+// job skips them.
+func finishedJobState(jobInfo *pps.JobInfo, dataFailed int64) (pps.JobState, string) {
+	if jobInfo.TimeoutPolicy == pps.TimeoutPolicy_TIMEOUT_PARTIAL_SUCCESS && dataFailed > 0 {
+		return pps.JobState_JOB_PARTIAL_SUCCESS, fmt.Sprintf("%d datums timed out, and were left out of the output", dataFailed)
+	}
+	return pps.JobState_JOB_SUCCESS, ""
+}
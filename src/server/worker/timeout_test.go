@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestJobDeadline(t *testing.T) {
+	started := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	startedProto, err := types.TimestampProto(started)
+	require.NoError(t, err)
+	jobInfo := &pps.JobInfo{
+		Started:    startedProto,
+		JobTimeout: types.DurationProto(time.Hour),
+	}
+
+	// Jobs that fail when they time out are killed by the master
+	deadline, err := jobDeadline(jobInfo)
+	require.NoError(t, err)
+	require.True(t, deadline.IsZero())
+
+	jobInfo.TimeoutPolicy = pps.TimeoutPolicy_TIMEOUT_PARTIAL_SUCCESS
+	deadline, err = jobDeadline(jobInfo)
+	require.NoError(t, err)
+	require.Equal(t, started.Add(time.Hour), deadline)
+}
+
+func TestFinishedJobState(t *testing.T) {
+	jobInfo := &pps.JobInfo{TimeoutPolicy: pps.TimeoutPolicy_TIMEOUT_SKIP_DATUM}
+	state, reason := finishedJobState(jobInfo, 2)
+	require.Equal(t, pps.JobState_JOB_SUCCESS, state)
+	require.Equal(t, "", reason)
+
+	jobInfo.TimeoutPolicy = pps.TimeoutPolicy_TIMEOUT_PARTIAL_SUCCESS
+	state, _ = finishedJobState(jobInfo, 0)
+	require.Equal(t, pps.JobState_JOB_SUCCESS, state)
+	state, reason = finishedJobState(jobInfo, 2)
+	require.Equal(t, pps.JobState_JOB_PARTIAL_SUCCESS, state)
+	require.Matches(t, "2 datums timed out", reason)
+}
@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"bytes"
+	"os"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+	"github.com/pachyderm/pachyderm/src/server/pkg/tracking"
+)
+
+// trackJob publishes a record of the finished job 'jobID' to the pipeline's
+// experiment tracker, if it has one. Tracking is informational, so the job
+// doesn't fail if the record can't be published.
+func (a *APIServer) trackJob(pachClient *client.APIClient, logger *taggedLogger, jobID string) {
+	spec := a.pipelineInfo.ExperimentTracking
+	if spec == nil {
+		return
+	}
+	if err := backoff.RetryNotify(func() error {
+		jobInfo, err := pachClient.InspectJob(jobID, false)
+		if err != nil {
+			return err
+		}
+		var metrics []byte
+		for _, artifact := range jobInfo.Artifacts {
+			if artifact.Name == tracking.MetricsArtifact(spec) {
+				var buf bytes.Buffer
+				if err := pachClient.GetArtifact(jobID, artifact.Name, &buf); err != nil {
+					return err
+				}
+				metrics = buf.Bytes()
+			}
+		}
+		record, err := runRecord(a.pipelineInfo, jobInfo, metrics)
+		if err != nil {
+			// Malformed metrics don't get better by retrying, so the job is
+			// published without them
+			logger.Logf("could not read the metrics of job %s: %v", jobID, err)
+		}
+		return tracking.Publish(pachClient.Ctx(), spec, os.Getenv(client.PPSTrackingSecretEnv), record)
+	}, backoff.NewExponentialBackOff(), func(err error, d time.Duration) error {
+		logger.Logf("could not publish job %s to the experiment tracker, retrying in %v: %v", jobID, d, err)
+		return nil
+	}); err != nil {
+		logger.Logf("giving up on publishing job %s to the experiment tracker: %v", jobID, err)
+	}
+}
+
+// runRecord returns the record of 'jobInfo' that's published to the
+// experiment tracker of 'pipelineInfo', with the metrics in 'metrics' (the
+// content of the job's metrics artifact, if it has one). If the metrics
+// can't be parsed, it returns the record without them, along with the error.
+func runRecord(pipelineInfo *pps.PipelineInfo, jobInfo *pps.JobInfo, metrics []byte) (*pps.RunRecord, error) {
+	experiment := pipelineInfo.ExperimentTracking.Experiment
+	if experiment == "" {
+		experiment = pipelineInfo.Pipeline.Name
+	}
+	record := &pps.RunRecord{
+		Job:             jobInfo.Job,
+		Pipeline:        jobInfo.Pipeline,
+		PipelineVersion: jobInfo.PipelineVersion,
+		Experiment:      experiment,
+		State:           jobInfo.State,
+		Started:         jobInfo.Started,
+		Finished:        jobInfo.Finished,
+		OutputCommit:    jobInfo.OutputCommit,
+		Params:          make(map[string]string),
+		Artifacts:       make(map[string]string),
+	}
+	for name, value := range pipelineInfo.Transform.Env {
+		record.Params[name] = value
+	}
+	record.Params["pachyderm.image"] = pipelineInfo.Transform.Image
+	for _, artifact := range jobInfo.Artifacts {
+		record.Artifacts[artifact.Name] = tracking.ArtifactLink(jobInfo.Job.ID, artifact.Name)
+	}
+	if metrics == nil {
+		return record, nil
+	}
+	var err error
+	if record.Metrics, err = tracking.ParseMetrics(metrics); err != nil {
+		return record, err
+	}
+	return record, nil
+}
@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestRunRecord(t *testing.T) {
+	pipelineInfo := &pps.PipelineInfo{
+		Pipeline: client.NewPipeline("train"),
+		Transform: &pps.Transform{
+			Image: "trainer:1.0",
+			Env:   map[string]string{"EPOCHS": "10"},
+		},
+		ExperimentTracking: &pps.ExperimentTracking{MLflowURL: "http://mlflow:5000"},
+	}
+	jobInfo := &pps.JobInfo{
+		Job:       client.NewJob("abc"),
+		Pipeline:  client.NewPipeline("train"),
+		State:     pps.JobState_JOB_SUCCESS,
+		Artifacts: []*pps.Artifact{{Name: "metrics.json"}, {Name: "roc.png"}},
+	}
+	record, err := runRecord(pipelineInfo, jobInfo, []byte(`{"auc": 0.9}`))
+	require.NoError(t, err)
+	// The experiment defaults to the pipeline's name
+	require.Equal(t, "train", record.Experiment)
+	require.Equal(t, map[string]string{"EPOCHS": "10", "pachyderm.image": "trainer:1.0"}, record.Params)
+	require.Equal(t, map[string]float64{"auc": 0.9}, record.Metrics)
+	require.Equal(t, map[string]string{
+		"metrics.json": "pachyderm://job/abc/artifact/metrics.json",
+		"roc.png":      "pachyderm://job/abc/artifact/roc.png",
+	}, record.Artifacts)
+
+	// Jobs with malformed metrics are still recorded
+	record, err = runRecord(pipelineInfo, jobInfo, []byte(`auc=0.9`))
+	require.YesError(t, err)
+	require.Equal(t, "abc", record.Job.ID)
+	require.Equal(t, 0, len(record.Metrics))
+}
@@ -0,0 +1,132 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/watch"
+)
+
+// parseWorkerPool returns the worker pool (see pps.EtcdJobInfo.worker_pool)
+// of a worker of 'pipelineInfo', given the value of its PPSWorkerPoolEnv. The
+// pipeline's own workers, which don't have it set, are its first pool, and
+// the workers of pipelines that run one job at a time are in no pool (0).
+func parseWorkerPool(pipelineInfo *pps.PipelineInfo, env string) (int64, error) {
+	if pipelineInfo.MaxConcurrentJobs <= 1 {
+		return 0, nil
+	}
+	if env == "" {
+		return 1, nil
+	}
+	pool, err := strconv.ParseInt(env, 10, 64)
+	if err != nil || pool < 1 {
+		return 0, fmt.Errorf("invalid worker pool %q", env)
+	}
+	return pool, nil
+}
+
+// workerPools tracks which of the worker pools of a pipeline with
+// max_concurrent_jobs are running a job, so that its master starts each job in
+// a pool of its own
+type workerPools struct {
+	mu   sync.Mutex
+	busy []bool // busy[i] is true if pool i+1 is running a job
+	// released is closed, and replaced, whenever a pool is released
+	released chan struct{}
+}
+
+func newWorkerPools(n int64) *workerPools {
+	return &workerPools{
+		busy:     make([]bool, n),
+		released: make(chan struct{}),
+	}
+}
+
+// acquire blocks until the pool 'pool' is free (or any pool, if 'pool' is 0 or
+// isn't one of the pipeline's pools), marks it busy and returns it. Resumed
+// jobs pass the pool that they already run in, as its workers may have
+// started processing them.
+func (p *workerPools) acquire(ctx context.Context, pool int64) (int64, error) {
+	if pool < 0 || pool > int64(len(p.busy)) {
+		pool = 0
+	}
+	for {
+		p.mu.Lock()
+		for i, busy := range p.busy {
+			if !busy && (pool == 0 || pool == int64(i+1)) {
+				p.busy[i] = true
+				p.mu.Unlock()
+				return int64(i + 1), nil
+			}
+		}
+		released := p.released
+		p.mu.Unlock()
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// release marks the pool 'pool' free, once its job is done
+func (p *workerPools) release(pool int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.busy[pool-1] = false
+	close(p.released)
+	p.released = make(chan struct{})
+}
+
+// assignWorkerPool records that the workers of pool 'pool' process the job
+// 'jobID'. The master does this before it plans the job, as workers wait for
+// it to learn whether the job is theirs.
+func (a *APIServer) assignWorkerPool(ctx context.Context, jobID string, pool int64) error {
+	_, err := col.NewSTM(ctx, a.etcdClient, func(stm col.STM) error {
+		jobs := a.jobs.ReadWrite(stm)
+		jobPtr := &pps.EtcdJobInfo{}
+		if err := jobs.Get(jobID, jobPtr); err != nil {
+			return err
+		}
+		if jobPtr.WorkerPool == pool {
+			return nil
+		}
+		jobPtr.WorkerPool = pool
+		return jobs.Put(jobID, jobPtr)
+	})
+	return err
+}
+
+// waitForWorkerPool blocks until the master has assigned the job 'jobID' to
+// one of the pipeline's worker pools, and returns the pool. It returns 0 if
+// the job finishes without being assigned one.
+func (a *APIServer) waitForWorkerPool(ctx context.Context, jobID string) (int64, error) {
+	var pool int64
+	if err := a.jobs.ReadOnly(ctx).WatchOneF(jobID, func(e *watch.Event) error {
+		switch e.Type {
+		case watch.EventError:
+			return e.Err
+		case watch.EventDelete:
+			return errutil.ErrBreak
+		}
+		var key string
+		jobPtr := &pps.EtcdJobInfo{}
+		if err := e.Unmarshal(&key, jobPtr); err != nil {
+			return err
+		}
+		if jobPtr.WorkerPool != 0 || ppsutil.IsTerminal(jobPtr.State) {
+			pool = jobPtr.WorkerPool
+			return errutil.ErrBreak
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	return pool, nil
+}
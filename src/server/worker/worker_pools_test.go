@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestParseWorkerPool(t *testing.T) {
+	pool, err := parseWorkerPool(&pps.PipelineInfo{}, "")
+	require.NoError(t, err)
+	require.Equal(t, int64(0), pool)
+
+	concurrent := &pps.PipelineInfo{MaxConcurrentJobs: 3}
+	pool, err = parseWorkerPool(concurrent, "")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), pool)
+	pool, err = parseWorkerPool(concurrent, "3")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), pool)
+	_, err = parseWorkerPool(concurrent, "0")
+	require.YesError(t, err)
+	_, err = parseWorkerPool(concurrent, "two")
+	require.YesError(t, err)
+}
+
+func TestWorkerPools(t *testing.T) {
+	ctx := context.Background()
+	pools := newWorkerPools(2)
+	first, err := pools.acquire(ctx, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), first)
+	// A resumed job gets the pool it ran in
+	second, err := pools.acquire(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), second)
+
+	// Once every pool is busy, jobs wait for one to be released
+	acquired := make(chan int64)
+	go func() {
+		pool, err := pools.acquire(ctx, 0)
+		if err == nil {
+			acquired <- pool
+		}
+	}()
+	select {
+	case pool := <-acquired:
+		t.Fatalf("acquired busy pool %d", pool)
+	case <-time.After(100 * time.Millisecond):
+	}
+	pools.release(second)
+	select {
+	case pool := <-acquired:
+		require.Equal(t, second, pool)
+	case <-time.After(10 * time.Second):
+		t.Fatal("pool wasn't acquired after it was released")
+	}
+
+	// Waiting stops when the master does
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = pools.acquire(cancelCtx, 0)
+	require.YesError(t, err)
+}
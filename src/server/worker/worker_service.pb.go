@@ -5,6 +5,7 @@ package worker
 
 import (
 	context "context"
+	encoding_binary "encoding/binary"
 	fmt "fmt"
 	_ "github.com/gogo/protobuf/gogoproto"
 	proto "github.com/gogo/protobuf/proto"
@@ -63,10 +64,12 @@ type Input struct {
 	ParentCommit         *pfs.Commit   `protobuf:"bytes,5,opt,name=parent_commit,json=parentCommit,proto3" json:"parent_commit,omitempty"`
 	Name                 string        `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
 	JoinOn               string        `protobuf:"bytes,8,opt,name=join_on,json=joinOn,proto3" json:"join_on,omitempty"`
+	GroupBy              string        `protobuf:"bytes,9,opt,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
 	Lazy                 bool          `protobuf:"varint,3,opt,name=lazy,proto3" json:"lazy,omitempty"`
 	Branch               string        `protobuf:"bytes,4,opt,name=branch,proto3" json:"branch,omitempty"`
 	GitURL               string        `protobuf:"bytes,6,opt,name=git_url,json=gitUrl,proto3" json:"git_url,omitempty"`
 	EmptyFiles           bool          `protobuf:"varint,7,opt,name=empty_files,json=emptyFiles,proto3" json:"empty_files,omitempty"`
+	S3                   bool          `protobuf:"varint,10,opt,name=s3,proto3" json:"s3,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
 	XXX_unrecognized     []byte        `json:"-"`
 	XXX_sizecache        int32         `json:"-"`
@@ -133,6 +136,13 @@ func (m *Input) GetJoinOn() string {
 	return ""
 }
 
+func (m *Input) GetGroupBy() string {
+	if m != nil {
+		return m.GroupBy
+	}
+	return ""
+}
+
 func (m *Input) GetLazy() bool {
 	if m != nil {
 		return m.Lazy
@@ -161,6 +171,13 @@ func (m *Input) GetEmptyFiles() bool {
 	return false
 }
 
+func (m *Input) GetS3() bool {
+	if m != nil {
+		return m.S3
+	}
+	return false
+}
+
 type CancelRequest struct {
 	JobID                string   `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
 	DataFilters          []string `protobuf:"bytes,1,rep,name=data_filters,json=dataFilters,proto3" json:"data_filters,omitempty"`
@@ -330,8 +347,14 @@ type ChunkState struct {
 	State   State  `protobuf:"varint,1,opt,name=state,proto3,enum=worker.State" json:"state,omitempty"`
 	DatumID string `protobuf:"bytes,2,opt,name=datum_id,json=datumId,proto3" json:"datum_id,omitempty"`
 	// The IP address of the worker who processed this chunk
-	Address              string      `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
-	RecoveredDatums      *pfs.Object `protobuf:"bytes,4,opt,name=recovered_datums,json=recoveredDatums,proto3" json:"recovered_datums,omitempty"`
+	Address         string      `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	RecoveredDatums *pfs.Object `protobuf:"bytes,4,opt,name=recovered_datums,json=recoveredDatums,proto3" json:"recovered_datums,omitempty"`
+	// When the worker started and finished processing this chunk
+	Started  *types.Timestamp `protobuf:"bytes,5,opt,name=started,proto3" json:"started,omitempty"`
+	Finished *types.Timestamp `protobuf:"bytes,6,opt,name=finished,proto3" json:"finished,omitempty"`
+	// datum_durations, if set, is an object containing how long each of the
+	// chunk's datums took to process (as a DatumDurations message)
+	DatumDurations       *pfs.Object `protobuf:"bytes,7,opt,name=datum_durations,json=datumDurations,proto3" json:"datum_durations,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
 	XXX_unrecognized     []byte      `json:"-"`
 	XXX_sizecache        int32       `json:"-"`
@@ -398,6 +421,77 @@ func (m *ChunkState) GetRecoveredDatums() *pfs.Object {
 	return nil
 }
 
+func (m *ChunkState) GetStarted() *types.Timestamp {
+	if m != nil {
+		return m.Started
+	}
+	return nil
+}
+
+func (m *ChunkState) GetFinished() *types.Timestamp {
+	if m != nil {
+		return m.Finished
+	}
+	return nil
+}
+
+func (m *ChunkState) GetDatumDurations() *pfs.Object {
+	if m != nil {
+		return m.DatumDurations
+	}
+	return nil
+}
+
+// DatumDurations is how long each of a pipeline's datums took to process, in
+// seconds, keyed by the datum's files (see datumKey). It's used to order
+// datums by their duration (see pps.DatumCost).
+type DatumDurations struct {
+	Seconds              map[string]float64 `protobuf:"bytes,1,rep,name=seconds,proto3" json:"seconds,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *DatumDurations) Reset()         { *m = DatumDurations{} }
+func (m *DatumDurations) String() string { return proto.CompactTextString(m) }
+func (*DatumDurations) ProtoMessage()    {}
+func (*DatumDurations) Descriptor() ([]byte, []int) {
+	return fileDescriptor_23ff4b5163b7daa7, []int{5}
+}
+func (m *DatumDurations) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DatumDurations) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DatumDurations.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DatumDurations) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DatumDurations.Merge(m, src)
+}
+func (m *DatumDurations) XXX_Size() int {
+	return m.Size()
+}
+func (m *DatumDurations) XXX_DiscardUnknown() {
+	xxx_messageInfo_DatumDurations.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DatumDurations proto.InternalMessageInfo
+
+func (m *DatumDurations) GetSeconds() map[string]float64 {
+	if m != nil {
+		return m.Seconds
+	}
+	return nil
+}
+
 type MergeState struct {
 	State                State       `protobuf:"varint,1,opt,name=state,proto3,enum=worker.State" json:"state,omitempty"`
 	Tree                 *pfs.Object `protobuf:"bytes,2,opt,name=tree,proto3" json:"tree,omitempty"`
@@ -413,7 +507,7 @@ func (m *MergeState) Reset()         { *m = MergeState{} }
 func (m *MergeState) String() string { return proto.CompactTextString(m) }
 func (*MergeState) ProtoMessage()    {}
 func (*MergeState) Descriptor() ([]byte, []int) {
-	return fileDescriptor_23ff4b5163b7daa7, []int{5}
+	return fileDescriptor_23ff4b5163b7daa7, []int{6}
 }
 func (m *MergeState) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -487,7 +581,7 @@ func (m *ShardInfo) Reset()         { *m = ShardInfo{} }
 func (m *ShardInfo) String() string { return proto.CompactTextString(m) }
 func (*ShardInfo) ProtoMessage()    {}
 func (*ShardInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_23ff4b5163b7daa7, []int{6}
+	return fileDescriptor_23ff4b5163b7daa7, []int{7}
 }
 func (m *ShardInfo) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -517,8 +611,21 @@ func (m *ShardInfo) XXX_DiscardUnknown() {
 var xxx_messageInfo_ShardInfo proto.InternalMessageInfo
 
 type Plan struct {
-	Chunks               []int64  `protobuf:"varint,1,rep,packed,name=chunks,proto3" json:"chunks,omitempty"`
-	Merges               int64    `protobuf:"varint,2,opt,name=merges,proto3" json:"merges,omitempty"`
+	Chunks []int64 `protobuf:"varint,1,rep,packed,name=chunks,proto3" json:"chunks,omitempty"`
+	Merges int64   `protobuf:"varint,2,opt,name=merges,proto3" json:"merges,omitempty"`
+	// chunks_object, if set, is an object containing the plan's chunks (as a
+	// Chunks message), in which case 'chunks' is empty. It's used for jobs with
+	// too many chunks to store in etcd.
+	ChunksObject *pfs.Object `protobuf:"bytes,3,opt,name=chunks_object,json=chunksObject,proto3" json:"chunks_object,omitempty"`
+	// chunk_profiles, if set, are the names of the datum profiles (see
+	// pps.DatumProfile) whose workers process each chunk, where "" is the
+	// pipeline's own workers
+	ChunkProfiles []string `protobuf:"bytes,4,rep,name=chunk_profiles,json=chunkProfiles,proto3" json:"chunk_profiles,omitempty"`
+	// claim_ranges, if set, lets workers claim the plan's chunks in ranges of
+	// consecutive chunks when there are too many of them to give each one a
+	// key in etcd. It's unset for plans made before chunks could be claimed in
+	// ranges, whose chunks may already have been claimed one at a time.
+	ClaimRanges          bool     `protobuf:"varint,5,opt,name=claim_ranges,json=claimRanges,proto3" json:"claim_ranges,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -528,7 +635,7 @@ func (m *Plan) Reset()         { *m = Plan{} }
 func (m *Plan) String() string { return proto.CompactTextString(m) }
 func (*Plan) ProtoMessage()    {}
 func (*Plan) Descriptor() ([]byte, []int) {
-	return fileDescriptor_23ff4b5163b7daa7, []int{7}
+	return fileDescriptor_23ff4b5163b7daa7, []int{8}
 }
 func (m *Plan) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -571,6 +678,203 @@ func (m *Plan) GetMerges() int64 {
 	return 0
 }
 
+func (m *Plan) GetChunksObject() *pfs.Object {
+	if m != nil {
+		return m.ChunksObject
+	}
+	return nil
+}
+
+func (m *Plan) GetChunkProfiles() []string {
+	if m != nil {
+		return m.ChunkProfiles
+	}
+	return nil
+}
+
+func (m *Plan) GetClaimRanges() bool {
+	if m != nil {
+		return m.ClaimRanges
+	}
+	return false
+}
+
+type Chunks struct {
+	Chunks               []int64  `protobuf:"varint,1,rep,packed,name=chunks,proto3" json:"chunks,omitempty"`
+	Profiles             []string `protobuf:"bytes,2,rep,name=profiles,proto3" json:"profiles,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Chunks) Reset()         { *m = Chunks{} }
+func (m *Chunks) String() string { return proto.CompactTextString(m) }
+func (*Chunks) ProtoMessage()    {}
+func (*Chunks) Descriptor() ([]byte, []int) {
+	return fileDescriptor_23ff4b5163b7daa7, []int{9}
+}
+func (m *Chunks) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Chunks) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Chunks.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Chunks) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Chunks.Merge(m, src)
+}
+func (m *Chunks) XXX_Size() int {
+	return m.Size()
+}
+func (m *Chunks) XXX_DiscardUnknown() {
+	xxx_messageInfo_Chunks.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Chunks proto.InternalMessageInfo
+
+func (m *Chunks) GetChunks() []int64 {
+	if m != nil {
+		return m.Chunks
+	}
+	return nil
+}
+
+func (m *Chunks) GetProfiles() []string {
+	if m != nil {
+		return m.Profiles
+	}
+	return nil
+}
+
+// ExternalChunk is a chunk of datums that's processed by an external
+// execution backend (see pps.ExecutionBackend). It's stored in object storage
+// and read by the worker binary running in the external job.
+type ExternalChunk struct {
+	JobID                string           `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	OutputCommit         *pfs.Commit      `protobuf:"bytes,2,opt,name=output_commit,json=outputCommit,proto3" json:"output_commit,omitempty"`
+	Transform            *pps.Transform   `protobuf:"bytes,3,opt,name=transform,proto3" json:"transform,omitempty"`
+	Datums               []*ExternalDatum `protobuf:"bytes,4,rep,name=datums,proto3" json:"datums,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *ExternalChunk) Reset()         { *m = ExternalChunk{} }
+func (m *ExternalChunk) String() string { return proto.CompactTextString(m) }
+func (*ExternalChunk) ProtoMessage()    {}
+func (*ExternalChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_23ff4b5163b7daa7, []int{10}
+}
+func (m *ExternalChunk) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ExternalChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ExternalChunk.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ExternalChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExternalChunk.Merge(m, src)
+}
+func (m *ExternalChunk) XXX_Size() int {
+	return m.Size()
+}
+func (m *ExternalChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExternalChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExternalChunk proto.InternalMessageInfo
+
+func (m *ExternalChunk) GetJobID() string {
+	if m != nil {
+		return m.JobID
+	}
+	return ""
+}
+
+func (m *ExternalChunk) GetOutputCommit() *pfs.Commit {
+	if m != nil {
+		return m.OutputCommit
+	}
+	return nil
+}
+
+func (m *ExternalChunk) GetTransform() *pps.Transform {
+	if m != nil {
+		return m.Transform
+	}
+	return nil
+}
+
+func (m *ExternalChunk) GetDatums() []*ExternalDatum {
+	if m != nil {
+		return m.Datums
+	}
+	return nil
+}
+
+type ExternalDatum struct {
+	Inputs               []*Input `protobuf:"bytes,1,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExternalDatum) Reset()         { *m = ExternalDatum{} }
+func (m *ExternalDatum) String() string { return proto.CompactTextString(m) }
+func (*ExternalDatum) ProtoMessage()    {}
+func (*ExternalDatum) Descriptor() ([]byte, []int) {
+	return fileDescriptor_23ff4b5163b7daa7, []int{11}
+}
+func (m *ExternalDatum) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ExternalDatum) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ExternalDatum.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ExternalDatum) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExternalDatum.Merge(m, src)
+}
+func (m *ExternalDatum) XXX_Size() int {
+	return m.Size()
+}
+func (m *ExternalDatum) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExternalDatum.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExternalDatum proto.InternalMessageInfo
+
+func (m *ExternalDatum) GetInputs() []*Input {
+	if m != nil {
+		return m.Inputs
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterEnum("worker.State", State_name, State_value)
 	proto.RegisterType((*Input)(nil), "worker.Input")
@@ -578,65 +882,92 @@ func init() {
 	proto.RegisterType((*CancelResponse)(nil), "worker.CancelResponse")
 	proto.RegisterType((*GetChunkRequest)(nil), "worker.GetChunkRequest")
 	proto.RegisterType((*ChunkState)(nil), "worker.ChunkState")
+	proto.RegisterType((*DatumDurations)(nil), "worker.DatumDurations")
+	proto.RegisterMapType((map[string]float64)(nil), "worker.DatumDurations.SecondsEntry")
 	proto.RegisterType((*MergeState)(nil), "worker.MergeState")
 	proto.RegisterType((*ShardInfo)(nil), "worker.ShardInfo")
 	proto.RegisterType((*Plan)(nil), "worker.Plan")
+	proto.RegisterType((*Chunks)(nil), "worker.Chunks")
+	proto.RegisterType((*ExternalChunk)(nil), "worker.ExternalChunk")
+	proto.RegisterType((*ExternalDatum)(nil), "worker.ExternalDatum")
 }
 
-func init() { proto.RegisterFile("server/worker/worker_service.proto", fileDescriptor_23ff4b5163b7daa7) }
+func init() {
+	proto.RegisterFile("server/worker/worker_service.proto", fileDescriptor_23ff4b5163b7daa7)
+}
 
 var fileDescriptor_23ff4b5163b7daa7 = []byte{
-	// 801 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xcd, 0x6e, 0xdb, 0x46,
-	0x10, 0x36, 0x2d, 0x89, 0x92, 0x46, 0xb6, 0xa3, 0x2e, 0x52, 0x87, 0x70, 0x50, 0x4b, 0x65, 0x80,
-	0x42, 0xf0, 0x81, 0x32, 0x5c, 0x34, 0x40, 0x2f, 0x05, 0x6a, 0xcb, 0x36, 0x54, 0xf8, 0x27, 0x58,
-	0xdb, 0x2d, 0xd0, 0x0b, 0xb1, 0x24, 0x47, 0x12, 0x1d, 0x8a, 0xcb, 0xee, 0x2e, 0x13, 0x38, 0xe7,
-	0xbe, 0x4d, 0x5f, 0xa2, 0xb7, 0xf6, 0xd8, 0x27, 0x30, 0x0a, 0x3d, 0x49, 0xb1, 0xbb, 0x64, 0x7e,
-	0xd4, 0x5e, 0x7a, 0x20, 0x38, 0xf3, 0xcd, 0xc7, 0x0f, 0x33, 0xb3, 0xdf, 0x12, 0x7c, 0x89, 0xe2,
-	0x0d, 0x8a, 0xf1, 0x5b, 0x2e, 0x5e, 0xbf, 0x7f, 0x85, 0x1a, 0x4c, 0x63, 0x0c, 0x0a, 0xc1, 0x15,
-	0x27, 0xae, 0x45, 0xf7, 0x9e, 0xc6, 0x59, 0x8a, 0xb9, 0x1a, 0x17, 0x33, 0xa9, 0x1f, 0x5b, 0xfd,
-	0x80, 0x16, 0x52, 0x3f, 0x35, 0x3a, 0xe7, 0x73, 0x6e, 0xc2, 0xb1, 0x8e, 0x2a, 0xf4, 0xf9, 0x9c,
-	0xf3, 0x79, 0x86, 0x63, 0x93, 0x45, 0xe5, 0x6c, 0x8c, 0xcb, 0x42, 0x3d, 0x54, 0xc5, 0xfd, 0xf5,
-	0xe2, 0x5b, 0xc1, 0x8a, 0x02, 0x45, 0x25, 0xe9, 0xff, 0xba, 0x09, 0xad, 0x69, 0x5e, 0x94, 0x8a,
-	0x1c, 0x40, 0x77, 0x96, 0x66, 0x18, 0xa6, 0xf9, 0x8c, 0x7b, 0xce, 0xd0, 0x19, 0xf5, 0x8e, 0xb6,
-	0x03, 0xdd, 0xd1, 0x59, 0x9a, 0xe1, 0x34, 0x9f, 0x71, 0xda, 0x99, 0x55, 0x11, 0x39, 0x84, 0xed,
-	0x82, 0x09, 0xcc, 0x55, 0x18, 0xf3, 0xe5, 0x32, 0x55, 0x5e, 0xcb, 0xf0, 0x7b, 0x86, 0x7f, 0x62,
-	0x20, 0xba, 0x65, 0x19, 0x36, 0x23, 0x04, 0x9a, 0x39, 0x5b, 0xa2, 0xb7, 0x39, 0x74, 0x46, 0x5d,
-	0x6a, 0x62, 0xf2, 0x0c, 0xda, 0xf7, 0x3c, 0xcd, 0x43, 0x9e, 0x7b, 0x1d, 0x03, 0xbb, 0x3a, 0xbd,
-	0xce, 0x35, 0x39, 0x63, 0xef, 0x1e, 0xbc, 0xc6, 0xd0, 0x19, 0x75, 0xa8, 0x89, 0xc9, 0x2e, 0xb8,
-	0x91, 0x60, 0x79, 0xbc, 0xf0, 0x9a, 0x96, 0x6b, 0x33, 0xf2, 0x02, 0xda, 0xf3, 0x54, 0x85, 0xa5,
-	0xc8, 0x3c, 0x57, 0x17, 0x8e, 0x61, 0xf5, 0x38, 0x70, 0xcf, 0x53, 0x75, 0x47, 0x2f, 0xa8, 0x3b,
-	0x4f, 0xd5, 0x9d, 0xc8, 0xc8, 0x00, 0x7a, 0x66, 0x29, 0xa1, 0x9e, 0x40, 0x7a, 0x6d, 0xa3, 0x0b,
-	0x06, 0xd2, 0xd3, 0x49, 0xff, 0x16, 0xb6, 0x4f, 0x58, 0x1e, 0x63, 0x46, 0xf1, 0x97, 0x12, 0xa5,
-	0x22, 0x43, 0x70, 0xef, 0x79, 0x14, 0xa6, 0x89, 0xed, 0xf8, 0xb8, 0xbb, 0x7a, 0x1c, 0xb4, 0x7e,
-	0xe0, 0xd1, 0x74, 0x42, 0x5b, 0xf7, 0x3c, 0x9a, 0x26, 0xe4, 0x4b, 0xd8, 0x4a, 0x98, 0x62, 0x5a,
-	0x52, 0xa1, 0x90, 0x9e, 0x33, 0x6c, 0x8c, 0xba, 0xb4, 0xa7, 0xb1, 0x33, 0x0b, 0xf9, 0x07, 0xb0,
-	0x53, 0xab, 0xca, 0x82, 0xe7, 0x12, 0x89, 0x07, 0x6d, 0x59, 0xc6, 0x31, 0x4a, 0x69, 0x56, 0xdc,
-	0xa1, 0x75, 0xea, 0x5f, 0xc2, 0x93, 0x73, 0x54, 0x27, 0x8b, 0x32, 0x7f, 0x5d, 0xf7, 0xb0, 0x03,
-	0x9b, 0x69, 0x62, 0x78, 0x0d, 0xba, 0x99, 0x26, 0xe4, 0x29, 0xb4, 0xe4, 0x82, 0x09, 0xdb, 0x52,
-	0x83, 0xda, 0xc4, 0xa0, 0x8a, 0x29, 0x59, 0x6d, 0xcb, 0x26, 0xfe, 0x6f, 0x0e, 0x80, 0x11, 0xbb,
-	0x51, 0x4c, 0x21, 0x79, 0x61, 0x49, 0x68, 0xd4, 0x76, 0x8e, 0xb6, 0x03, 0xeb, 0xbe, 0xc0, 0x54,
-	0xed, 0x37, 0x48, 0xbe, 0x82, 0x4e, 0xc2, 0x54, 0xb9, 0xfc, 0x30, 0x75, 0x6f, 0xf5, 0x38, 0x68,
-	0x4f, 0x34, 0x36, 0x9d, 0xd0, 0xb6, 0x29, 0x4e, 0x13, 0x3d, 0x04, 0x4b, 0x12, 0xa1, 0x87, 0x68,
-	0x98, 0xb3, 0xa8, 0x53, 0xf2, 0x12, 0xfa, 0x02, 0x63, 0xfe, 0x06, 0x05, 0x26, 0xa1, 0xa1, 0x4b,
-	0x73, 0x5c, 0xb5, 0x35, 0xae, 0xa3, 0x7b, 0x8c, 0x15, 0x7d, 0xf2, 0x9e, 0x64, 0xb4, 0xa5, 0xff,
-	0x87, 0x03, 0x70, 0x89, 0x62, 0x8e, 0xff, 0xa3, 0xdb, 0x01, 0x34, 0x95, 0x40, 0xeb, 0xa8, 0x35,
-	0x7d, 0x53, 0x20, 0x5f, 0x00, 0xc8, 0xf4, 0x1d, 0x86, 0xd1, 0x83, 0x42, 0xdb, 0x69, 0x93, 0x76,
-	0x35, 0x72, 0xac, 0x01, 0x72, 0x00, 0x60, 0x56, 0x15, 0x1a, 0x95, 0xff, 0xe8, 0xb2, 0x6b, 0xca,
-	0xb7, 0x5a, 0x6a, 0x04, 0x7d, 0xcb, 0xfd, 0x48, 0xb0, 0x65, 0x04, 0x77, 0x0c, 0x7e, 0x53, 0xab,
-	0xfa, 0x3d, 0xe8, 0xde, 0xe8, 0x63, 0xd1, 0xd7, 0xc4, 0x7f, 0x09, 0xcd, 0x57, 0x19, 0xcb, 0xb5,
-	0x77, 0x63, 0x7d, 0x16, 0xd6, 0x24, 0x0d, 0x5a, 0x65, 0x1a, 0x5f, 0xea, 0xa9, 0x65, 0x75, 0xa2,
-	0x55, 0x76, 0x10, 0x40, 0xcb, 0x2e, 0xa2, 0x07, 0x6d, 0x7a, 0x77, 0x75, 0x35, 0xbd, 0x3a, 0xef,
-	0x6f, 0x90, 0x2d, 0xe8, 0x9c, 0x5c, 0x5f, 0xbe, 0xba, 0x38, 0xbd, 0x3d, 0xed, 0x3b, 0x04, 0xc0,
-	0x3d, 0xfb, 0x7e, 0x7a, 0x71, 0x3a, 0xe9, 0x37, 0x8e, 0x7e, 0x77, 0xc0, 0xfd, 0xc9, 0xac, 0x88,
-	0x7c, 0x03, 0xae, 0xfe, 0xb4, 0x94, 0x64, 0x37, 0xb0, 0x57, 0x3f, 0xa8, 0xaf, 0x7e, 0x70, 0xaa,
-	0xfd, 0xbe, 0xf7, 0x59, 0xa0, 0x7f, 0x28, 0x96, 0x6e, 0xa9, 0xfe, 0x06, 0xf9, 0x16, 0x5c, 0xeb,
-	0x54, 0xf2, 0x79, 0xbd, 0xec, 0x4f, 0xee, 0xc3, 0xde, 0xee, 0x3a, 0x6c, 0x0d, 0xed, 0x6f, 0x90,
-	0x09, 0x74, 0x6a, 0xe3, 0x92, 0x67, 0x35, 0x6b, 0xcd, 0xca, 0x7b, 0xcf, 0xff, 0xd5, 0x8c, 0x59,
-	0xd7, 0x8f, 0x2c, 0x2b, 0xd1, 0xdf, 0x38, 0x74, 0x8e, 0xbf, 0xfb, 0x73, 0xb5, 0xef, 0xfc, 0xb5,
-	0xda, 0x77, 0xfe, 0x5e, 0xed, 0x3b, 0x3f, 0x1f, 0xce, 0x53, 0xb5, 0x28, 0xa3, 0x20, 0xe6, 0xcb,
-	0x71, 0xc1, 0xe2, 0xc5, 0x43, 0x82, 0xe2, 0xe3, 0x48, 0x8a, 0x78, 0xfc, 0xc9, 0x3f, 0x36, 0x72,
-	0x8d, 0xf0, 0xd7, 0xff, 0x04, 0x00, 0x00, 0xff, 0xff, 0xcf, 0x10, 0xdd, 0x9d, 0x7b, 0x05, 0x00,
+	// 1121 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x56, 0xdf, 0x6e, 0xe3, 0xc4,
+	0x17, 0xae, 0xf3, 0xc7, 0x49, 0x4e, 0x9a, 0x6c, 0x7e, 0xa3, 0xfd, 0x75, 0x4d, 0x56, 0x34, 0xc5,
+	0xd5, 0xa2, 0xaa, 0x82, 0xa4, 0x6a, 0x97, 0x0a, 0x56, 0x80, 0x44, 0x9b, 0xb4, 0x0a, 0xea, 0x3f,
+	0x4d, 0x5b, 0x90, 0xb8, 0xb1, 0x1c, 0x7b, 0x92, 0xb8, 0x75, 0x3c, 0x66, 0x66, 0xdc, 0x25, 0xfb,
+	0x0a, 0x3c, 0x0c, 0x12, 0x4f, 0xb0, 0x77, 0x70, 0x07, 0x4f, 0x50, 0xa1, 0x3c, 0x09, 0x9a, 0x19,
+	0x3b, 0x6d, 0xd3, 0x22, 0xc4, 0x45, 0xd4, 0x39, 0xdf, 0xf9, 0xe6, 0x9b, 0x73, 0x66, 0xce, 0x39,
+	0x2e, 0xd8, 0x9c, 0xb0, 0x1b, 0xc2, 0x3a, 0x6f, 0x29, 0xbb, 0x9e, 0xff, 0x71, 0x24, 0x18, 0x78,
+	0xa4, 0x1d, 0x33, 0x2a, 0x28, 0x32, 0x35, 0xda, 0x7c, 0xee, 0x85, 0x01, 0x89, 0x44, 0x27, 0x1e,
+	0x72, 0xf9, 0xd3, 0xde, 0x3b, 0x34, 0xe6, 0xf2, 0x97, 0xa1, 0x23, 0x3a, 0xa2, 0x6a, 0xd9, 0x91,
+	0xab, 0x14, 0x7d, 0x39, 0xa2, 0x74, 0x14, 0x92, 0x8e, 0xb2, 0x06, 0xc9, 0xb0, 0x43, 0x26, 0xb1,
+	0x98, 0xa6, 0xce, 0xd6, 0xa2, 0x53, 0x04, 0x13, 0xc2, 0x85, 0x3b, 0x89, 0x53, 0xc2, 0xea, 0x22,
+	0xe1, 0x2d, 0x73, 0xe3, 0x98, 0xb0, 0xf4, 0x4c, 0xfb, 0x97, 0x1c, 0x14, 0xfb, 0x51, 0x9c, 0x08,
+	0xb4, 0x09, 0x95, 0x61, 0x10, 0x12, 0x27, 0x88, 0x86, 0xd4, 0x32, 0xd6, 0x8c, 0x8d, 0xea, 0x76,
+	0xad, 0x2d, 0x43, 0x3e, 0x08, 0x42, 0xd2, 0x8f, 0x86, 0x14, 0x97, 0x87, 0xe9, 0x0a, 0x6d, 0x41,
+	0x2d, 0x76, 0x19, 0x89, 0x84, 0xe3, 0xd1, 0xc9, 0x24, 0x10, 0x56, 0x51, 0xf1, 0xab, 0x8a, 0xbf,
+	0xaf, 0x20, 0xbc, 0xac, 0x19, 0xda, 0x42, 0x08, 0x0a, 0x91, 0x3b, 0x21, 0x56, 0x6e, 0xcd, 0xd8,
+	0xa8, 0x60, 0xb5, 0x46, 0x2f, 0xa0, 0x74, 0x45, 0x83, 0xc8, 0xa1, 0x91, 0x55, 0x56, 0xb0, 0x29,
+	0xcd, 0xd3, 0x08, 0x7d, 0x00, 0xe5, 0x11, 0xa3, 0x49, 0xec, 0x0c, 0xa6, 0x56, 0x45, 0x79, 0x4a,
+	0xca, 0xde, 0x9b, 0x4a, 0x9d, 0xd0, 0x7d, 0x37, 0xb5, 0xf2, 0x6b, 0xc6, 0x46, 0x19, 0xab, 0x35,
+	0x5a, 0x01, 0x73, 0xc0, 0xdc, 0xc8, 0x1b, 0x5b, 0x05, 0x2d, 0xa3, 0x2d, 0xb4, 0x0e, 0xa5, 0x51,
+	0x20, 0x9c, 0x84, 0x85, 0x96, 0x29, 0x1d, 0x7b, 0x30, 0xbb, 0x6d, 0x99, 0x87, 0x81, 0xb8, 0xc4,
+	0x47, 0xd8, 0x1c, 0x05, 0xe2, 0x92, 0x85, 0xa8, 0x05, 0x55, 0x75, 0xa1, 0x8e, 0x4c, 0x8e, 0x5b,
+	0x25, 0xa5, 0x0b, 0x0a, 0x92, 0x89, 0x73, 0x54, 0x87, 0x1c, 0xdf, 0xb1, 0x40, 0xe1, 0x39, 0xbe,
+	0x63, 0x5f, 0x40, 0x6d, 0xdf, 0x8d, 0x3c, 0x12, 0x62, 0xf2, 0x63, 0x42, 0xb8, 0x40, 0x6b, 0x60,
+	0x5e, 0xd1, 0x81, 0x13, 0xf8, 0x3a, 0xb9, 0xbd, 0xca, 0xec, 0xb6, 0x55, 0xfc, 0x96, 0x0e, 0xfa,
+	0x5d, 0x5c, 0xbc, 0xa2, 0x83, 0xbe, 0x8f, 0x3e, 0x82, 0x65, 0xdf, 0x15, 0xae, 0x3c, 0x42, 0x10,
+	0xc6, 0x2d, 0x63, 0x2d, 0xbf, 0x51, 0xc1, 0x55, 0x89, 0x1d, 0x68, 0xc8, 0xde, 0x84, 0x7a, 0xa6,
+	0xca, 0x63, 0x1a, 0x71, 0x82, 0x2c, 0x28, 0xf1, 0xc4, 0xf3, 0x08, 0xe7, 0xea, 0x35, 0xca, 0x38,
+	0x33, 0xed, 0x63, 0x78, 0x76, 0x48, 0xc4, 0xfe, 0x38, 0x89, 0xae, 0xb3, 0x18, 0xea, 0x90, 0x0b,
+	0x7c, 0xc5, 0xcb, 0xe3, 0x5c, 0xe0, 0xa3, 0xe7, 0x50, 0xe4, 0x63, 0x97, 0xe9, 0x90, 0xf2, 0x58,
+	0x1b, 0x0a, 0x15, 0xae, 0xe0, 0xe9, 0xed, 0x69, 0xc3, 0xfe, 0x23, 0x07, 0xa0, 0xc4, 0xce, 0x85,
+	0x2b, 0x08, 0x5a, 0xd7, 0x24, 0xa2, 0xd4, 0xea, 0xdb, 0xb5, 0xb6, 0xae, 0xe4, 0xb6, 0xf2, 0xea,
+	0x3d, 0x04, 0x7d, 0x0c, 0x65, 0xdf, 0x15, 0xc9, 0xe4, 0x2e, 0xeb, 0xea, 0xec, 0xb6, 0x55, 0xea,
+	0x4a, 0xac, 0xdf, 0xc5, 0x25, 0xe5, 0xec, 0xfb, 0x32, 0x09, 0xd7, 0xf7, 0x19, 0xe1, 0xfa, 0xcc,
+	0x0a, 0xce, 0x4c, 0xb4, 0x0b, 0x0d, 0x46, 0x3c, 0x7a, 0x43, 0x18, 0xf1, 0x1d, 0x45, 0xe7, 0x56,
+	0xe1, 0x5e, 0x15, 0x9d, 0x0e, 0xae, 0x88, 0x27, 0xf0, 0xb3, 0x39, 0x49, 0x69, 0x73, 0xf4, 0x1a,
+	0x4a, 0x5c, 0xb8, 0x4c, 0x10, 0x3f, 0x2d, 0xba, 0x66, 0x5b, 0x97, 0x78, 0x3b, 0x2b, 0xf1, 0xf6,
+	0x45, 0xd6, 0x03, 0x38, 0xa3, 0xa2, 0x5d, 0x28, 0x0f, 0x83, 0x28, 0xe0, 0x63, 0xe2, 0x5b, 0xe6,
+	0xbf, 0x6e, 0x9b, 0x73, 0xd1, 0x6b, 0x78, 0xa6, 0xf3, 0xf4, 0x13, 0xe6, 0x8a, 0x80, 0x46, 0xba,
+	0x42, 0x16, 0x82, 0xac, 0x2b, 0x4e, 0x37, 0xa3, 0xd8, 0x3f, 0x1b, 0x50, 0xef, 0x3e, 0x80, 0xd0,
+	0x57, 0x50, 0xe2, 0xc4, 0xa3, 0x91, 0xaf, 0x5f, 0xbf, 0xba, 0xbd, 0x9e, 0xdd, 0xeb, 0x43, 0x62,
+	0xfb, 0x5c, 0xb3, 0x7a, 0x91, 0x60, 0x53, 0x9c, 0xed, 0x69, 0xbe, 0x81, 0xe5, 0xfb, 0x0e, 0xd4,
+	0x80, 0xfc, 0x35, 0x99, 0xaa, 0x27, 0xaa, 0x60, 0xb9, 0x94, 0x6f, 0x7b, 0xe3, 0x86, 0x89, 0xee,
+	0x30, 0x03, 0x6b, 0xe3, 0x4d, 0xee, 0x73, 0xc3, 0xfe, 0xcd, 0x00, 0x38, 0x26, 0x6c, 0x44, 0xfe,
+	0xc3, 0xfb, 0xb6, 0xa0, 0x20, 0x18, 0xd1, 0x62, 0x0b, 0xc9, 0x2a, 0x07, 0xfa, 0x10, 0x80, 0x07,
+	0xef, 0x88, 0x33, 0x98, 0x0a, 0xa2, 0xdf, 0xb6, 0x80, 0x2b, 0x12, 0xd9, 0x93, 0x00, 0xda, 0x04,
+	0x50, 0xc5, 0xe5, 0x28, 0x95, 0x27, 0xde, 0xb5, 0xa2, 0xdc, 0x17, 0x52, 0x6a, 0x03, 0x1a, 0x9a,
+	0x7b, 0x4f, 0xb0, 0xa8, 0x04, 0xeb, 0x0a, 0x3f, 0xcf, 0x54, 0xed, 0x2a, 0x54, 0xce, 0x65, 0x21,
+	0xcb, 0x19, 0x64, 0xff, 0x6a, 0x40, 0xe1, 0x2c, 0x74, 0x23, 0xd9, 0xfe, 0x9e, 0x2c, 0x5f, 0x7d,
+	0xb3, 0x79, 0x9c, 0x5a, 0x12, 0x9f, 0xc8, 0xb4, 0x79, 0xda, 0x04, 0xa9, 0x25, 0x87, 0x97, 0x66,
+	0x38, 0x54, 0xc5, 0x62, 0xe5, 0x1f, 0x87, 0xb7, 0xac, 0x19, 0xda, 0x42, 0xaf, 0xa0, 0xae, 0x6c,
+	0x27, 0x66, 0x54, 0x8f, 0x89, 0x82, 0xea, 0x60, 0xad, 0x73, 0x96, 0x82, 0xb2, 0xcd, 0xbd, 0xd0,
+	0x0d, 0x26, 0x0e, 0x73, 0xa3, 0x51, 0x9a, 0x44, 0x19, 0x57, 0x15, 0x86, 0x15, 0x64, 0x7f, 0x09,
+	0xe6, 0xfe, 0x3c, 0xba, 0x27, 0xa3, 0x6e, 0x42, 0x79, 0x7e, 0x4a, 0x4e, 0x9d, 0x32, 0xb7, 0xed,
+	0xf7, 0x06, 0xd4, 0x7a, 0x3f, 0x09, 0xc2, 0x22, 0x37, 0x54, 0x32, 0xf7, 0x66, 0x8f, 0xf1, 0x0f,
+	0xb3, 0x67, 0x0b, 0x6a, 0x34, 0x11, 0x71, 0x32, 0x1f, 0xd5, 0xb9, 0x27, 0x46, 0xb5, 0x66, 0x68,
+	0x0b, 0x7d, 0x02, 0x15, 0xc1, 0xdc, 0x88, 0x0f, 0x29, 0x9b, 0xa4, 0x77, 0x53, 0x6f, 0xcb, 0xaf,
+	0xd4, 0x45, 0x86, 0xe2, 0x3b, 0x02, 0xfa, 0x14, 0xcc, 0x79, 0xf7, 0xca, 0xba, 0xfe, 0x7f, 0x56,
+	0x4f, 0x59, 0xa0, 0xaa, 0xbe, 0x71, 0x4a, 0xb2, 0x77, 0xa1, 0xf6, 0xc0, 0x81, 0x5e, 0x81, 0x19,
+	0xc8, 0xef, 0x4f, 0xd6, 0x17, 0xf3, 0x7a, 0x54, 0x5f, 0x25, 0x9c, 0x3a, 0x37, 0xdb, 0x50, 0xd4,
+	0xe5, 0x5b, 0x85, 0x12, 0xbe, 0x3c, 0x39, 0xe9, 0x9f, 0x1c, 0x36, 0x96, 0xd0, 0x32, 0x94, 0xf7,
+	0x4f, 0x8f, 0xcf, 0x8e, 0x7a, 0x17, 0xbd, 0x86, 0x81, 0x00, 0xcc, 0x83, 0x6f, 0xfa, 0x47, 0xbd,
+	0x6e, 0x23, 0xbf, 0xfd, 0xde, 0x00, 0xf3, 0x7b, 0x25, 0x84, 0x3e, 0x03, 0x53, 0x6e, 0x4d, 0x38,
+	0x5a, 0x79, 0xd4, 0xf3, 0x3d, 0x39, 0xe7, 0x9b, 0xff, 0x53, 0xe9, 0x69, 0xba, 0xa6, 0xda, 0x4b,
+	0xe8, 0x0b, 0x30, 0xf5, 0x44, 0x46, 0xf3, 0x94, 0x1e, 0xcc, 0xfd, 0xe6, 0xca, 0x22, 0xac, 0x07,
+	0xb7, 0xbd, 0x84, 0xba, 0x50, 0xce, 0x06, 0x34, 0x7a, 0x91, 0xb1, 0x16, 0x46, 0x76, 0xf3, 0xe5,
+	0xa3, 0x60, 0x54, 0x91, 0x7f, 0x27, 0xfb, 0xd6, 0x5e, 0xda, 0x32, 0xf6, 0xbe, 0xfe, 0x7d, 0xb6,
+	0x6a, 0xfc, 0x39, 0x5b, 0x35, 0xfe, 0x9a, 0xad, 0x1a, 0x3f, 0x6c, 0x8d, 0x02, 0x31, 0x4e, 0x06,
+	0x6d, 0x8f, 0x4e, 0x3a, 0xb1, 0xeb, 0x8d, 0xa7, 0x3e, 0x61, 0xf7, 0x57, 0x9c, 0x79, 0x9d, 0x07,
+	0xff, 0x97, 0x0c, 0x4c, 0x25, 0xbc, 0xf3, 0xf7, 0x00, 0x61, 0x06, 0x83, 0x47, 0xaf, 0x08, 0x00,
 	0x00,
 }
 
@@ -844,6 +1175,23 @@ func (m *Input) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.S3 {
+		i--
+		if m.S3 {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x50
+	}
+	if len(m.GroupBy) > 0 {
+		i -= len(m.GroupBy)
+		copy(dAtA[i:], m.GroupBy)
+		i = encodeVarintWorkerService(dAtA, i, uint64(len(m.GroupBy)))
+		i--
+		dAtA[i] = 0x4a
+	}
 	if len(m.JoinOn) > 0 {
 		i -= len(m.JoinOn)
 		copy(dAtA[i:], m.JoinOn)
@@ -1070,6 +1418,42 @@ func (m *ChunkState) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.DatumDurations != nil {
+		{
+			size, err := m.DatumDurations.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintWorkerService(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x3a
+	}
+	if m.Finished != nil {
+		{
+			size, err := m.Finished.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintWorkerService(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x32
+	}
+	if m.Started != nil {
+		{
+			size, err := m.Started.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintWorkerService(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x2a
+	}
 	if m.RecoveredDatums != nil {
 		{
 			size, err := m.RecoveredDatums.MarshalToSizedBuffer(dAtA[:i])
@@ -1104,6 +1488,51 @@ func (m *ChunkState) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *DatumDurations) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DatumDurations) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DatumDurations) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Seconds) > 0 {
+		for k := range m.Seconds {
+			v := m.Seconds[k]
+			baseI := i
+			i -= 8
+			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(v))))
+			i--
+			dAtA[i] = 0x11
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintWorkerService(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintWorkerService(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *MergeState) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -1221,33 +1650,232 @@ func (m *Plan) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	if m.Merges != 0 {
-		i = encodeVarintWorkerService(dAtA, i, uint64(m.Merges))
+	if m.ClaimRanges {
 		i--
-		dAtA[i] = 0x10
-	}
-	if len(m.Chunks) > 0 {
-		dAtA7 := make([]byte, len(m.Chunks)*10)
-		var j6 int
+		if m.ClaimRanges {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.ChunkProfiles) > 0 {
+		for iNdEx := len(m.ChunkProfiles) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.ChunkProfiles[iNdEx])
+			copy(dAtA[i:], m.ChunkProfiles[iNdEx])
+			i = encodeVarintWorkerService(dAtA, i, uint64(len(m.ChunkProfiles[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if m.ChunksObject != nil {
+		{
+			size, err := m.ChunksObject.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintWorkerService(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Merges != 0 {
+		i = encodeVarintWorkerService(dAtA, i, uint64(m.Merges))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Chunks) > 0 {
+		dAtA11 := make([]byte, len(m.Chunks)*10)
+		var j10 int
+		for _, num1 := range m.Chunks {
+			num := uint64(num1)
+			for num >= 1<<7 {
+				dAtA11[j10] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j10++
+			}
+			dAtA11[j10] = uint8(num)
+			j10++
+		}
+		i -= j10
+		copy(dAtA[i:], dAtA11[:j10])
+		i = encodeVarintWorkerService(dAtA, i, uint64(j10))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Chunks) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Chunks) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Chunks) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Profiles) > 0 {
+		for iNdEx := len(m.Profiles) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Profiles[iNdEx])
+			copy(dAtA[i:], m.Profiles[iNdEx])
+			i = encodeVarintWorkerService(dAtA, i, uint64(len(m.Profiles[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.Chunks) > 0 {
+		dAtA13 := make([]byte, len(m.Chunks)*10)
+		var j12 int
 		for _, num1 := range m.Chunks {
 			num := uint64(num1)
 			for num >= 1<<7 {
-				dAtA7[j6] = uint8(uint64(num)&0x7f | 0x80)
+				dAtA13[j12] = uint8(uint64(num)&0x7f | 0x80)
 				num >>= 7
-				j6++
+				j12++
+			}
+			dAtA13[j12] = uint8(num)
+			j12++
+		}
+		i -= j12
+		copy(dAtA[i:], dAtA13[:j12])
+		i = encodeVarintWorkerService(dAtA, i, uint64(j12))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ExternalChunk) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ExternalChunk) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ExternalChunk) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Datums) > 0 {
+		for iNdEx := len(m.Datums) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Datums[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintWorkerService(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if m.Transform != nil {
+		{
+			size, err := m.Transform.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintWorkerService(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.OutputCommit != nil {
+		{
+			size, err := m.OutputCommit.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
 			}
-			dAtA7[j6] = uint8(num)
-			j6++
+			i -= size
+			i = encodeVarintWorkerService(dAtA, i, uint64(size))
 		}
-		i -= j6
-		copy(dAtA[i:], dAtA7[:j6])
-		i = encodeVarintWorkerService(dAtA, i, uint64(j6))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.JobID) > 0 {
+		i -= len(m.JobID)
+		copy(dAtA[i:], m.JobID)
+		i = encodeVarintWorkerService(dAtA, i, uint64(len(m.JobID)))
 		i--
 		dAtA[i] = 0xa
 	}
 	return len(dAtA) - i, nil
 }
 
+func (m *ExternalDatum) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ExternalDatum) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ExternalDatum) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Inputs) > 0 {
+		for iNdEx := len(m.Inputs) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Inputs[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintWorkerService(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintWorkerService(dAtA []byte, offset int, v uint64) int {
 	offset -= sovWorkerService(v)
 	base := offset
@@ -1295,6 +1923,13 @@ func (m *Input) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovWorkerService(uint64(l))
 	}
+	l = len(m.GroupBy)
+	if l > 0 {
+		n += 1 + l + sovWorkerService(uint64(l))
+	}
+	if m.S3 {
+		n += 2
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1380,6 +2015,38 @@ func (m *ChunkState) Size() (n int) {
 		l = m.RecoveredDatums.Size()
 		n += 1 + l + sovWorkerService(uint64(l))
 	}
+	if m.Started != nil {
+		l = m.Started.Size()
+		n += 1 + l + sovWorkerService(uint64(l))
+	}
+	if m.Finished != nil {
+		l = m.Finished.Size()
+		n += 1 + l + sovWorkerService(uint64(l))
+	}
+	if m.DatumDurations != nil {
+		l = m.DatumDurations.Size()
+		n += 1 + l + sovWorkerService(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *DatumDurations) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Seconds) > 0 {
+		for k, v := range m.Seconds {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovWorkerService(uint64(len(k))) + 1 + 8
+			n += mapEntrySize + 1 + sovWorkerService(uint64(mapEntrySize))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1443,6 +2110,92 @@ func (m *Plan) Size() (n int) {
 	if m.Merges != 0 {
 		n += 1 + sovWorkerService(uint64(m.Merges))
 	}
+	if m.ChunksObject != nil {
+		l = m.ChunksObject.Size()
+		n += 1 + l + sovWorkerService(uint64(l))
+	}
+	if len(m.ChunkProfiles) > 0 {
+		for _, s := range m.ChunkProfiles {
+			l = len(s)
+			n += 1 + l + sovWorkerService(uint64(l))
+		}
+	}
+	if m.ClaimRanges {
+		n += 2
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *Chunks) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Chunks) > 0 {
+		l = 0
+		for _, e := range m.Chunks {
+			l += sovWorkerService(uint64(e))
+		}
+		n += 1 + sovWorkerService(uint64(l)) + l
+	}
+	if len(m.Profiles) > 0 {
+		for _, s := range m.Profiles {
+			l = len(s)
+			n += 1 + l + sovWorkerService(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ExternalChunk) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.JobID)
+	if l > 0 {
+		n += 1 + l + sovWorkerService(uint64(l))
+	}
+	if m.OutputCommit != nil {
+		l = m.OutputCommit.Size()
+		n += 1 + l + sovWorkerService(uint64(l))
+	}
+	if m.Transform != nil {
+		l = m.Transform.Size()
+		n += 1 + l + sovWorkerService(uint64(l))
+	}
+	if len(m.Datums) > 0 {
+		for _, e := range m.Datums {
+			l = e.Size()
+			n += 1 + l + sovWorkerService(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ExternalDatum) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Inputs) > 0 {
+		for _, e := range m.Inputs {
+			l = e.Size()
+			n += 1 + l + sovWorkerService(uint64(l))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1724,23 +2477,75 @@ func (m *Input) Unmarshal(dAtA []byte) error {
 			}
 			m.JoinOn = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipWorkerService(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthWorkerService
-			}
-			if (iNdEx + skippy) < 0 {
-				return ErrInvalidLengthWorkerService
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GroupBy", wireType)
 			}
-			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
-			iNdEx += skippy
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorkerService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.GroupBy = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field S3", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorkerService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.S3 = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipWorkerService(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
 		}
 	}
 
@@ -2201,82 +3006,9 @@ func (m *ChunkState) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipWorkerService(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthWorkerService
-			}
-			if (iNdEx + skippy) < 0 {
-				return ErrInvalidLengthWorkerService
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *MergeState) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowWorkerService
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: MergeState: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: MergeState: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field State", wireType)
-			}
-			m.State = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowWorkerService
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.State |= State(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 2:
+		case 5:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Tree", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Started", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2303,35 +3035,16 @@ func (m *MergeState) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Tree == nil {
-				m.Tree = &pfs.Object{}
+			if m.Started == nil {
+				m.Started = &types.Timestamp{}
 			}
-			if err := m.Tree.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.Started.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 3:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field SizeBytes", wireType)
-			}
-			m.SizeBytes = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowWorkerService
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.SizeBytes |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 4:
+		case 6:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StatsTree", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Finished", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2358,18 +3071,18 @@ func (m *MergeState) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.StatsTree == nil {
-				m.StatsTree = &pfs.Object{}
+			if m.Finished == nil {
+				m.Finished = &types.Timestamp{}
 			}
-			if err := m.StatsTree.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.Finished.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 5:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StatsSizeBytes", wireType)
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DatumDurations", wireType)
 			}
-			m.StatsSizeBytes = 0
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowWorkerService
@@ -2379,65 +3092,28 @@ func (m *MergeState) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.StatsSizeBytes |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		default:
-			iNdEx = preIndex
-			skippy, err := skipWorkerService(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthWorkerService
 			}
-			if (iNdEx + skippy) < 0 {
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
 				return ErrInvalidLengthWorkerService
 			}
-			if (iNdEx + skippy) > l {
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *ShardInfo) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowWorkerService
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
+			if m.DatumDurations == nil {
+				m.DatumDurations = &pfs.Object{}
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
+			if err := m.DatumDurations.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: ShardInfo: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ShardInfo: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipWorkerService(dAtA[iNdEx:])
@@ -2463,7 +3139,7 @@ func (m *ShardInfo) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Plan) Unmarshal(dAtA []byte) error {
+func (m *DatumDurations) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2486,69 +3162,66 @@ func (m *Plan) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Plan: wiretype end group for non-group")
+			return fmt.Errorf("proto: DatumDurations: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Plan: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: DatumDurations: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType == 0 {
-				var v int64
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowWorkerService
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					v |= int64(b&0x7F) << shift
-					if b < 0x80 {
-						break
-					}
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Seconds", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorkerService
 				}
-				m.Chunks = append(m.Chunks, v)
-			} else if wireType == 2 {
-				var packedLen int
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowWorkerService
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Seconds == nil {
+				m.Seconds = make(map[string]float64)
+			}
+			var mapkey string
+			var mapvalue float64
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowWorkerService
 					}
 					if iNdEx >= l {
 						return io.ErrUnexpectedEOF
 					}
 					b := dAtA[iNdEx]
 					iNdEx++
-					packedLen |= int(b&0x7F) << shift
+					wire |= uint64(b&0x7F) << shift
 					if b < 0x80 {
 						break
 					}
 				}
-				if packedLen < 0 {
-					return ErrInvalidLengthWorkerService
-				}
-				postIndex := iNdEx + packedLen
-				if postIndex < 0 {
-					return ErrInvalidLengthWorkerService
-				}
-				if postIndex > l {
-					return io.ErrUnexpectedEOF
-				}
-				var elementCount int
-				var count int
-				for _, integer := range dAtA[iNdEx:postIndex] {
-					if integer < 128 {
-						count++
-					}
-				}
-				elementCount = count
-				if elementCount != 0 && len(m.Chunks) == 0 {
-					m.Chunks = make([]int64, 0, elementCount)
-				}
-				for iNdEx < postIndex {
-					var v int64
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
 					for shift := uint(0); ; shift += 7 {
 						if shift >= 64 {
 							return ErrIntOverflowWorkerService
@@ -2558,21 +3231,163 @@ func (m *Plan) Unmarshal(dAtA []byte) error {
 						}
 						b := dAtA[iNdEx]
 						iNdEx++
-						v |= int64(b&0x7F) << shift
+						stringLenmapkey |= uint64(b&0x7F) << shift
 						if b < 0x80 {
 							break
 						}
 					}
-					m.Chunks = append(m.Chunks, v)
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthWorkerService
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthWorkerService
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var mapvaluetemp uint64
+					if (iNdEx + 8) > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvaluetemp = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+					iNdEx += 8
+					mapvalue = math.Float64frombits(mapvaluetemp)
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipWorkerService(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if skippy < 0 {
+						return ErrInvalidLengthWorkerService
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Seconds[mapkey] = mapvalue
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipWorkerService(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MergeState) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowWorkerService
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MergeState: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MergeState: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field State", wireType)
+			}
+			m.State = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorkerService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.State |= State(b&0x7F) << shift
+				if b < 0x80 {
+					break
 				}
-			} else {
-				return fmt.Errorf("proto: wrong wireType = %d for field Chunks", wireType)
 			}
 		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tree", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorkerService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Tree == nil {
+				m.Tree = &pfs.Object{}
+			}
+			if err := m.Tree.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Merges", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field SizeBytes", wireType)
 			}
-			m.Merges = 0
+			m.SizeBytes = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowWorkerService
@@ -2582,7 +3397,62 @@ func (m *Plan) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Merges |= int64(b&0x7F) << shift
+				m.SizeBytes |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StatsTree", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorkerService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.StatsTree == nil {
+				m.StatsTree = &pfs.Object{}
+			}
+			if err := m.StatsTree.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StatsSizeBytes", wireType)
+			}
+			m.StatsSizeBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorkerService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.StatsSizeBytes |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
@@ -2612,6 +3482,739 @@ func (m *Plan) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *ShardInfo) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowWorkerService
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ShardInfo: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ShardInfo: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipWorkerService(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Plan) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowWorkerService
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Plan: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Plan: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType == 0 {
+				var v int64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowWorkerService
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= int64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Chunks = append(m.Chunks, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowWorkerService
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthWorkerService
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthWorkerService
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.Chunks) == 0 {
+					m.Chunks = make([]int64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v int64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowWorkerService
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= int64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Chunks = append(m.Chunks, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Chunks", wireType)
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Merges", wireType)
+			}
+			m.Merges = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorkerService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Merges |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChunksObject", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorkerService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ChunksObject == nil {
+				m.ChunksObject = &pfs.Object{}
+			}
+			if err := m.ChunksObject.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChunkProfiles", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorkerService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ChunkProfiles = append(m.ChunkProfiles, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClaimRanges", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorkerService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ClaimRanges = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipWorkerService(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Chunks) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowWorkerService
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Chunks: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Chunks: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType == 0 {
+				var v int64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowWorkerService
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= int64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Chunks = append(m.Chunks, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowWorkerService
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthWorkerService
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthWorkerService
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.Chunks) == 0 {
+					m.Chunks = make([]int64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v int64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowWorkerService
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= int64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Chunks = append(m.Chunks, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Chunks", wireType)
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Profiles", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorkerService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Profiles = append(m.Profiles, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipWorkerService(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ExternalChunk) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowWorkerService
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ExternalChunk: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ExternalChunk: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field JobID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorkerService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.JobID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OutputCommit", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorkerService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.OutputCommit == nil {
+				m.OutputCommit = &pfs.Commit{}
+			}
+			if err := m.OutputCommit.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Transform", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorkerService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Transform == nil {
+				m.Transform = &pps.Transform{}
+			}
+			if err := m.Transform.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Datums", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorkerService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Datums = append(m.Datums, &ExternalDatum{})
+			if err := m.Datums[len(m.Datums)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipWorkerService(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ExternalDatum) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowWorkerService
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ExternalDatum: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ExternalDatum: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Inputs", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowWorkerService
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Inputs = append(m.Inputs, &Input{})
+			if err := m.Inputs[len(m.Inputs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipWorkerService(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthWorkerService
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipWorkerService(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
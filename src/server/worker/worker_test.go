@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strings"
 	"sync"
 	"testing"
 
@@ -13,6 +14,7 @@ import (
 	etcd "github.com/coreos/etcd/clientv3"
 	"github.com/pachyderm/pachyderm/src/client"
 	pclient "github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
 	"github.com/pachyderm/pachyderm/src/client/pkg/require"
 	"github.com/pachyderm/pachyderm/src/client/pps"
 	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
@@ -62,6 +64,83 @@ func TestAcquireDatums(t *testing.T) {
 	}
 }
 
+func TestNewPlanSizeBytes(t *testing.T) {
+	var inputs []*Input
+	for i := 0; i < 10; i++ {
+		inputs = append(inputs, &Input{FileInfo: &pfs.FileInfo{SizeBytes: 10}})
+	}
+	df, err := newListDatumIterator(nil, inputs)
+	require.NoError(t, err)
+	plan := newPlan(df, &pps.ChunkSpec{SizeBytes: 25}, 1, 1, 0)
+	require.Equal(t, []int64{2, 5, 8, 10}, plan.Chunks)
+}
+
+func TestUserCodeEnvMetadata(t *testing.T) {
+	a := &APIServer{pipelineInfo: &pps.PipelineInfo{}}
+	data := []*Input{
+		{Name: "images", FileInfo: &pfs.FileInfo{File: client.NewFile("images", "a", "/1.png")}},
+		{Name: "labels", FileInfo: &pfs.FileInfo{File: client.NewFile("labels", "b", "/1.txt")}},
+	}
+	env := a.userCodeEnv("job", "out", data, []*pps.CommitMetadata{{
+		Commit:   client.NewCommit("images", "a"),
+		Metadata: map[string]string{"batch": "1234"},
+	}})
+	require.OneOfEquals(t, `images_METADATA={"batch":"1234"}`, env)
+	for _, e := range env {
+		require.False(t, strings.HasPrefix(e, "labels_METADATA="))
+	}
+}
+
+func TestDatumTag(t *testing.T) {
+	data := []*Input{{Name: "in", FileInfo: &pfs.FileInfo{File: client.NewFile("in", "a", "/1"), Hash: []byte("hash")}}}
+	a := &APIServer{pipelineInfo: &pps.PipelineInfo{Pipeline: client.NewPipeline("p"), Salt: "salt", Version: 1}}
+	tag := a.datumTag(data)
+	require.Equal(t, HashDatum("p", "salt", data), tag)
+
+	// Without a datum cache, datums are reused by later versions of the
+	// pipeline
+	a.pipelineInfo.Version = 2
+	require.Equal(t, tag, a.datumTag(data))
+
+	// With one, they're only reused by the same version, but their trees
+	// have the same prefix so they're kept by GC
+	a.pipelineInfo.DatumCache = true
+	cached := a.datumTag(data)
+	require.NotEqual(t, tag, cached)
+	require.True(t, strings.HasPrefix(cached, client.DatumTagPrefix("salt")))
+	require.Equal(t, cached, a.datumTag(data))
+	a.pipelineInfo.Version = 3
+	require.NotEqual(t, cached, a.datumTag(data))
+	require.Equal(t, HashCachedDatum("p", "salt", 3, data), a.datumTag(data))
+}
+
+func TestClaimPlan(t *testing.T) {
+	plan := &Plan{Merges: 1, ClaimRanges: true}
+	for i := int64(1); i <= 3*maxInlineChunks; i++ {
+		plan.Chunks = append(plan.Chunks, i)
+	}
+	// Huge plans are claimed in ranges of consecutive chunks
+	claims := claimPlan(plan)
+	require.Equal(t, maxInlineChunks, len(claims.Chunks))
+	require.Equal(t, int64(3), claims.Chunks[0])
+	require.Equal(t, int64(3*maxInlineChunks), claims.Chunks[len(claims.Chunks)-1])
+	require.Equal(t, int64(1), claims.Merges)
+
+	// Ranges end where the datum profile changes
+	plan.ChunkProfiles = make([]string, len(plan.Chunks))
+	plan.ChunkProfiles[4] = "large"
+	claims = claimPlan(plan)
+	require.Equal(t, []int64{3, 4, 5, 8}, claims.Chunks[:4])
+	require.Equal(t, []string{"", "", "large", ""}, claims.ChunkProfiles[:4])
+
+	// Plans made before claim ranges, and small plans, are claimed a chunk at
+	// a time
+	plan.ClaimRanges = false
+	require.Equal(t, plan, claimPlan(plan))
+	small := &Plan{Chunks: []int64{10, 20, 30}, ClaimRanges: true}
+	require.Equal(t, small, claimPlan(small))
+}
+
 var etcdClient *etcd.Client
 var etcdOnce sync.Once
 